@@ -0,0 +1,33 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AlertsHandler handles alert history endpoints
+type AlertsHandler struct {
+	legality *services.LegalityAlertService
+}
+
+// NewAlertsHandler creates a new alerts handler
+func NewAlertsHandler(legality *services.LegalityAlertService) *AlertsHandler {
+	return &AlertsHandler{legality: legality}
+}
+
+// Legality returns recorded ban and restriction changes for owned cards with
+// pagination, newest first, optionally filtered by format
+func (h *AlertsHandler) Legality(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+	format := c.Query("format")
+
+	changes, total, err := h.legality.List(c.RequestCtx(), params.Page, params.PageSize, format)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch legality alerts", "legality alert list query failed", err)
+	}
+
+	return utils.SendPaginated(c, changes, params.Page, params.PageSize, total)
+}
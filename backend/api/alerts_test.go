@@ -0,0 +1,80 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupAlertsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.LegalityChange{}, &models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewAlertsHandler(services.NewLegalityAlertService(db, services.NewNotificationService(db)))
+
+	app := fiber.New()
+	app.Get("/alerts/legality", handler.Legality)
+
+	return app, db
+}
+
+func TestAlertsLegality_FormatFilter(t *testing.T) {
+	app, db := setupAlertsTestApp(t)
+
+	for _, format := range []string{"modern", "pioneer"} {
+		change := models.LegalityChange{OracleID: "o1", CardName: "Card", Format: format, PreviousStatus: "legal", Status: "banned"}
+		if err := db.Create(&change).Error; err != nil {
+			t.Fatalf("failed to create change: %v", err)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/alerts/legality?format=pioneer", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result struct {
+		Data       []models.LegalityChange `json:"data"`
+		TotalItems int64                   `json:"total_items"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.TotalItems != 1 || len(result.Data) != 1 {
+		t.Fatalf("expected 1 pioneer alert, got total=%d len=%d", result.TotalItems, len(result.Data))
+	}
+	if result.Data[0].Format != "pioneer" {
+		t.Errorf("expected format pioneer, got %s", result.Data[0].Format)
+	}
+}
+
+func TestAlertsLegality_Empty(t *testing.T) {
+	app, _ := setupAlertsTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/alerts/legality", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+}
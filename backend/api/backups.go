@@ -0,0 +1,110 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// BackupHandler handles database backup HTTP requests
+type BackupHandler struct {
+	service *services.BackupService
+}
+
+// NewBackupHandler creates a new backup handler
+func NewBackupHandler(service *services.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// Create writes a backup of the live database and returns it
+func (h *BackupHandler) Create(c fiber.Ctx) error {
+	backup, err := h.service.Create(c.RequestCtx())
+	if err != nil {
+		if errors.Is(err, services.ErrBackupRunning) {
+			return utils.ReturnError(c, fiber.StatusConflict, "A backup or restore is already running")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create backup", "backup failed", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(backup)
+}
+
+// List returns the stored backups, newest first
+func (h *BackupHandler) List(c fiber.Ctx) error {
+	backups, err := h.service.List()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to list backups", "reading backups failed", err)
+	}
+	return c.JSON(backups)
+}
+
+// Download sends a stored backup as an attachment, with byte range support so an
+// interrupted download can resume
+func (h *BackupHandler) Download(c fiber.Ctx) error {
+	path, err := h.service.BackupPath(c.Params("name"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusNotFound, "backup not found")
+	}
+	return sendExportFile(c, path, filepath.Base(path), fiber.MIMEOctetStream)
+}
+
+// RestoreBackupRequest selects a stored backup to restore
+// tygo:export
+type RestoreBackupRequest struct {
+	Name       string `json:"name"`
+	CancelJobs bool   `json:"cancel_jobs"` // Cancel pending and running jobs instead of refusing
+}
+
+// Restore replaces the database with a stored backup named in a JSON body, or with a
+// multipart "file" upload (form field "cancel_jobs" set to "true" to cancel active jobs)
+func (h *BackupHandler) Restore(c fiber.Ctx) error {
+	var req RestoreBackupRequest
+	var path string
+	if fileHeader, err := c.FormFile("file"); err == nil {
+		req.Name = fileHeader.Filename
+		req.CancelJobs = c.FormValue("cancel_jobs") == "true"
+
+		upload, err := os.CreateTemp("", "showmycards-restore-*.db")
+		if err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to read uploaded backup", "creating temp file failed", err)
+		}
+		path = upload.Name()
+		upload.Close()
+		defer os.Remove(path)
+
+		if err := c.SaveFile(fileHeader, path); err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to read uploaded backup", "saving upload failed", err)
+		}
+	} else {
+		if err := c.Bind().Body(&req); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+		if req.Name == "" {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "name or an uploaded file is required")
+		}
+		if path, err = h.service.BackupPath(req.Name); err != nil {
+			return utils.ReturnError(c, fiber.StatusNotFound, "backup not found")
+		}
+	}
+
+	result, err := h.service.Restore(c.RequestCtx(), path, req.Name, req.CancelJobs)
+	switch {
+	case errors.Is(err, services.ErrInvalidBackup):
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, services.ErrJobsActive):
+		return utils.ReturnError(c, fiber.StatusConflict, err.Error()+"; wait for them or set cancel_jobs")
+	case errors.Is(err, services.ErrBackupRunning):
+		return utils.ReturnError(c, fiber.StatusConflict, "A backup or restore is already running")
+	case err != nil:
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to restore backup", "restore failed", err)
+	}
+	return c.JSON(result)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBackupTestApp(t *testing.T) (*fiber.App, *gorm.DB, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "database.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	databasetest.Migrate(t, db)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database instance: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	app := fiber.New()
+	handler := NewBackupHandler(services.NewBackupService(db, services.NewJobService(db), dataDir))
+	app.Post("/admin/backup", handler.Create)
+	app.Get("/admin/backups", handler.List)
+	app.Get("/admin/backups/:name", handler.Download)
+	app.Post("/admin/restore", handler.Restore)
+	return app, db, dataDir
+}
+
+func TestBackups_CreateAndList(t *testing.T) {
+	app, _, _ := setupBackupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	var created services.BackupInfo
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/admin/backups", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var backups []services.BackupInfo
+	if err := json.NewDecoder(resp.Body).Decode(&backups); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Name != created.Name || backups[0].Size == 0 {
+		t.Errorf("expected the new backup listed, got %+v", backups)
+	}
+}
+
+func TestBackups_Download(t *testing.T) {
+	app, _, _ := setupBackupTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var created services.BackupInfo
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/backups/"+created.Name, nil)
+	req.Header.Set(fiber.HeaderRange, "bytes=0-15")
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		t.Fatalf("expected status %d, got %d", http.StatusPartialContent, resp.StatusCode)
+	}
+	if disposition := resp.Header.Get(fiber.HeaderContentDisposition); !strings.Contains(disposition, created.Name) {
+		t.Errorf("expected the backup's file name, got %q", disposition)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "SQLite format 3\x00" {
+		t.Errorf("expected the start of a SQLite database, got %q", body)
+	}
+
+	for _, name := range []string{"showmycards-20200101-000000.000.db", "..%2Fdatabase.db"} {
+		resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/admin/backups/"+name, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status %d for %s, got %d", http.StatusNotFound, name, resp.StatusCode)
+		}
+	}
+}
+func TestBackups_Restore(t *testing.T) {
+	app, db, dataDir := setupBackupTestApp(t)
+	db.Create(&models.StorageLocation{Name: "Box 1", StorageType: models.Box})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/admin/backup", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var backup services.BackupInfo
+	if err := json.NewDecoder(resp.Body).Decode(&backup); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	db.Create(&models.StorageLocation{Name: "Box 2", StorageType: models.Box})
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(`{"name": "`+backup.Name+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result services.RestoreResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	var count int64
+	db.Model(&models.StorageLocation{}).Count(&count)
+	if count != 1 || result.SafetyBackup.Name == "" {
+		t.Errorf("expected the backup restored with a safety backup, got %d locations and %+v", count, result)
+	}
+
+	// Restoring an upload of the safety backup brings the second location back
+	data, err := os.ReadFile(filepath.Join(dataDir, "backups", result.SafetyBackup.Name))
+	if err != nil {
+		t.Fatalf("failed to read safety backup: %v", err)
+	}
+	resp = postRestoreUpload(t, app, "collection.db", data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	db.Model(&models.StorageLocation{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected the uploaded database restored, got %d locations", count)
+	}
+}
+
+func TestBackups_RestoreErrors(t *testing.T) {
+	app, _, _ := setupBackupTestApp(t)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"No name", `{}`, http.StatusBadRequest},
+		{"Unknown backup", `{"name": "showmycards-20260101-000000.000.db"}`, http.StatusNotFound},
+		{"Path outside backups", `{"name": "../database.db"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/restore", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+
+	resp := postRestoreUpload(t, app, "notes.db", []byte("not a database"))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an invalid upload rejected with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func postRestoreUpload(t *testing.T, app *fiber.App, filename string, data []byte) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/restore", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
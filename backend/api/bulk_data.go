@@ -4,22 +4,32 @@ import (
 	"backend/services"
 	"backend/utils"
 	"context"
+	"errors"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
 )
 
 // BulkDataHandler handles bulk data-related HTTP requests
 type BulkDataHandler struct {
-	service *services.BulkDataService
+	service    *services.BulkDataService
+	jobService *services.JobService
 }
 
 // NewBulkDataHandler creates a new bulk data handler
-func NewBulkDataHandler(service *services.BulkDataService) *BulkDataHandler {
-	return &BulkDataHandler{service: service}
+func NewBulkDataHandler(service *services.BulkDataService, jobService *services.JobService) *BulkDataHandler {
+	return &BulkDataHandler{service: service, jobService: jobService}
 }
 
-// TriggerImport triggers a bulk data download and import
+// TriggerImport triggers a bulk data download and import. Optional query params
+// batch_size and transaction_size override the import tuning settings for this job.
 func (h *BulkDataHandler) TriggerImport(c fiber.Ctx, appCtx context.Context) error {
+	tuning, err := services.ParseImportTuningOverrides(c.Query("batch_size"), c.Query("transaction_size"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
 	// Create a job for this import
 	job, err := h.service.CreateImportJob(appCtx)
 	if err != nil {
@@ -27,16 +37,52 @@ func (h *BulkDataHandler) TriggerImport(c fiber.Ctx, appCtx context.Context) err
 			"Failed to create import job", "job creation failed", err)
 	}
 
-	// Start the import in a goroutine (async)
-	go func() {
-		if err := h.service.DownloadAndImport(appCtx, job.ID); err != nil {
-			// Error is already logged and job is marked as failed in the service
-			return
-		}
-	}()
+	// Start the import in the background (async)
+	if err := h.jobService.Go(appCtx, job.ID, "bulk data import", func(ctx context.Context) error {
+		// Errors are logged and the job marked as failed by the service
+		_ = h.service.DownloadAndImportTuned(ctx, job.ID, tuning)
+		return nil
+	}); err != nil {
+		return startJobError(c, err)
+	}
 
 	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
 		"message": "Import job started",
 		"job_id":  job.ID,
 	})
 }
+
+// ResumeImport continues a cancelled or failed bulk import from its last committed batch
+func (h *BulkDataHandler) ResumeImport(c fiber.Ctx, appCtx context.Context) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job ID")
+	}
+	jobID := uint(id)
+
+	checkpoint, err := h.service.PrepareResume(c.RequestCtx(), jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "Job not found")
+		}
+		if errors.Is(err, services.ErrJobNotResumable) {
+			return utils.ReturnError(c, fiber.StatusConflict, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to resume import job", "job resume failed", err)
+	}
+
+	if err := h.jobService.Go(appCtx, jobID, "bulk data import resume", func(ctx context.Context) error {
+		// Errors are logged and the job marked as failed by the service
+		_ = h.service.ResumeImport(ctx, jobID, checkpoint)
+		return nil
+	}); err != nil {
+		return startJobError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message":    "Import job resumed",
+		"job_id":     jobID,
+		"checkpoint": checkpoint.Checkpoint,
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// CardImageHandler serves card images from the local cache
+type CardImageHandler struct {
+	service    *services.CardImageService
+	jobService *services.JobService
+}
+
+// NewCardImageHandler creates a new card image handler
+func NewCardImageHandler(service *services.CardImageService, jobService *services.JobService) *CardImageHandler {
+	return &CardImageHandler{service: service, jobService: jobService}
+}
+
+// GetCardImage returns a card's image, downloading it from Scryfall into the cache on
+// first request. The size query parameter is normal (default) or small.
+func (h *CardImageHandler) GetCardImage(c fiber.Ctx) error {
+	scryfallID := c.Params("scryfall_id")
+	if !services.ValidCardImageID(scryfallID) {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid scryfall_id")
+	}
+	size := services.CardImageSize(c.Query("size", string(services.CardImageNormal)))
+	if !size.Valid() {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "size must be normal or small")
+	}
+
+	path, err := h.service.Path(c.RequestCtx(), scryfallID, size)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "card not found")
+		}
+		if errors.Is(err, services.ErrCardImageUnavailable) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "card has no image in this size")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusBadGateway,
+			"Failed to fetch card image", "image download failed", err)
+	}
+
+	c.Set("Content-Type", "image/jpeg")
+	c.Set("Cache-Control", "public, max-age=86400")
+	return c.SendFile(path)
+}
+
+// Prefetch starts a background job that caches the image of every printing in the inventory
+func (h *CardImageHandler) Prefetch(c fiber.Ctx, appCtx context.Context) error {
+	job, err := h.service.CreatePrefetchJob(appCtx)
+	if err != nil {
+		if errors.Is(err, services.ErrImagePrefetchRunning) {
+			return utils.ReturnError(c, fiber.StatusConflict, "An image prefetch job is already running")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create image prefetch job", "job creation failed", err)
+	}
+
+	if err := h.jobService.Go(appCtx, job.ID, "image prefetch", func(ctx context.Context) error {
+		// Errors are logged and recorded on the job by the service
+		_ = h.service.Prefetch(ctx, job.ID)
+		return nil
+	}); err != nil {
+		return startJobError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(TriggerImportResponse{
+		Message: "Image prefetch job started",
+		JobID:   job.ID,
+	})
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupCardImageTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Card{}, &models.StorageLocation{}, &models.Inventory{}, &models.Setting{}, &models.Job{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	jobService := services.NewJobService(db)
+	handler := NewCardImageHandler(services.NewCardImageService(db, jobService, t.TempDir()), jobService)
+	appCtx := context.Background()
+
+	app := fiber.New()
+	app.Get("/images/cards/:scryfall_id", handler.GetCardImage)
+	app.Post("/images/prefetch", func(c fiber.Ctx) error {
+		return handler.Prefetch(c, appCtx)
+	})
+	return app, db
+}
+
+func TestCardImages_Get(t *testing.T) {
+	app, db := setupCardImageTestApp(t)
+
+	scryfall := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("image:" + r.URL.Path))
+	}))
+	defer scryfall.Close()
+
+	db.Create(&models.Card{ScryfallID: "bolt", OracleID: "oracle-bolt",
+		RawJSON: fmt.Sprintf(`{"image_uris": {"normal": "%[1]s/normal.jpg", "small": "%[1]s/small.jpg"}}`, scryfall.URL)})
+	db.Create(&models.Card{ScryfallID: "gone", OracleID: "oracle-gone",
+		RawJSON: fmt.Sprintf(`{"image_uris": {"normal": "%s/missing.jpg"}}`, scryfall.URL)})
+	db.Create(&models.Card{ScryfallID: "token", OracleID: "oracle-token", RawJSON: `{}`})
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		body   string
+	}{
+		{"Default size", "/images/cards/bolt", http.StatusOK, "image:/normal.jpg"},
+		{"Small", "/images/cards/bolt?size=small", http.StatusOK, "image:/small.jpg"},
+		{"Invalid size", "/images/cards/bolt?size=huge", http.StatusBadRequest, ""},
+		{"Invalid ID", "/images/cards/bolt.jpg", http.StatusBadRequest, ""},
+		{"Unknown card", "/images/cards/unknown", http.StatusNotFound, ""},
+		{"No image", "/images/cards/token", http.StatusNotFound, ""},
+		{"Download fails", "/images/cards/gone", http.StatusBadGateway, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+			if tt.body == "" {
+				return
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.body {
+				t.Errorf("expected body %q, got %q", tt.body, body)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "image/jpeg" {
+				t.Errorf("expected image/jpeg, got %s", ct)
+			}
+		})
+	}
+}
+
+func TestCardImages_PrefetchConflict(t *testing.T) {
+	app, db := setupCardImageTestApp(t)
+
+	db.Create(&models.Job{Type: models.JobTypeImagePrefetch, Status: models.JobStatusInProgress})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/images/prefetch", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
@@ -32,8 +32,40 @@ func BuildCardResult(card scryfall.Card) CardResult {
 		Finishes:        utils.ConvertEnumSliceToStrings(card.Finishes),
 		FrameEffects:    utils.ConvertEnumSliceToStrings(card.FrameEffects),
 		PromoTypes:      card.PromoTypes,
+		BorderColor:     card.BorderColor,
 		EDHRECRank:      card.EDHRECRank,
 		Prices:          BuildCardPrices(card.Prices),
 		ImageURI:        utils.ExtractCardImageURI(card),
 	}
 }
+
+// BuildCardExternalLinks extracts a card's marketplace (purchase) and reference (related)
+// links, keyed as in Scryfall's data. Links the card doesn't have are left out, and an
+// empty set of links is returned as nil.
+func BuildCardExternalLinks(card scryfall.Card) (purchase, related map[string]string) {
+	purchase = nonEmptyLinks(map[string]string{
+		"tcgplayer":   card.PurchaseURIs.TCGPlayer,
+		"cardmarket":  card.PurchaseURIs.CardMarket,
+		"cardhoarder": card.PurchaseURIs.CardHoarder,
+	})
+	related = nonEmptyLinks(map[string]string{
+		"gatherer":        card.RelatedURIs.Gatherer,
+		"tcgplayer_decks": card.RelatedURIs.TCGPlayerDecks,
+		"edhrec":          card.RelatedURIs.EDHREC,
+		"mtgtop8":         card.RelatedURIs.MTGTop8,
+	})
+	return purchase, related
+}
+
+// nonEmptyLinks drops links without a URL, returning nil when none are left
+func nonEmptyLinks(links map[string]string) map[string]string {
+	for name, uri := range links {
+		if uri == "" {
+			delete(links, name)
+		}
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	return links
+}
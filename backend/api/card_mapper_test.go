@@ -107,3 +107,28 @@ func TestBuildCardResult_EmptyCard(t *testing.T) {
 		t.Errorf("expected empty Name, got %q", result.Name)
 	}
 }
+
+func TestBuildCardExternalLinks(t *testing.T) {
+	card := scryfall.Card{
+		PurchaseURIs: scryfall.PurchaseURIs{TCGPlayer: "https://tcgplayer.example/1", CardMarket: "https://cardmarket.example/1"},
+		RelatedURIs:  scryfall.RelatedURIs{EDHREC: "https://edhrec.example/bolt"},
+	}
+
+	purchase, related := BuildCardExternalLinks(card)
+	if len(purchase) != 2 || purchase["tcgplayer"] != "https://tcgplayer.example/1" || purchase["cardmarket"] != "https://cardmarket.example/1" {
+		t.Errorf("unexpected purchase links: %v", purchase)
+	}
+	if _, ok := purchase["cardhoarder"]; ok {
+		t.Error("expected missing cardhoarder link to be left out")
+	}
+	if len(related) != 1 || related["edhrec"] != "https://edhrec.example/bolt" {
+		t.Errorf("unexpected related links: %v", related)
+	}
+}
+
+func TestBuildCardExternalLinks_EmptyCard(t *testing.T) {
+	purchase, related := BuildCardExternalLinks(scryfall.Card{})
+	if purchase != nil || related != nil {
+		t.Errorf("expected no links, got %v and %v", purchase, related)
+	}
+}
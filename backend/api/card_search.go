@@ -0,0 +1,206 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	scryfall "github.com/BlueMonday/go-scryfall"
+	"github.com/gofiber/fiber/v3"
+)
+
+// validSearchColors are the color letters accepted by the color filter
+var validSearchColors = []string{"w", "u", "b", "r", "g", "c"}
+
+// validSearchRarities are the rarities accepted by the rarity filter
+var validSearchRarities = []string{"common", "uncommon", "rare", "mythic", "special", "bonus"}
+
+// SearchLocal searches the locally imported card data instead of Scryfall, so search
+// works offline once bulk data has been imported.
+//
+// Query params:
+//   - q: matched against name, type line, oracle text, and set code
+//   - set: set code
+//   - color: color letters the card must all have (e.g. "ur"), or "c" for colorless
+//   - rarity: comma-separated rarities
+//   - cmc_min, cmc_max, price_min, price_max: numeric ranges (price is nonfoil USD)
+//   - page, page_size: pagination
+func (h *SearchHandler) SearchLocal(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	filters, err := parseCardSearchFilters(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if filters.IsEmpty() {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "a search term or at least one filter is required")
+	}
+
+	cards, total, err := services.NewCardSearchService(h.db).Search(c.RequestCtx(), filters, params.Page, params.PageSize)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to search cards", "local card search failed", err)
+	}
+
+	scryfallCards := make([]scryfall.Card, 0, len(cards))
+	for _, card := range cards {
+		scryfallCard, err := card.ToScryfallCard()
+		if err != nil {
+			slog.WarnContext(c.RequestCtx(), "skipping card with invalid JSON", "component", "search", "scryfall_id", card.ScryfallID, "error", err)
+			continue
+		}
+		scryfallCards = append(scryfallCards, scryfallCard)
+	}
+
+	return utils.SendPaginated(c, h.withInventory(c, scryfallCards), params.Page, params.PageSize, total)
+}
+
+// LookupCard finds one printing in the imported cards and returns it with the inventory
+// held for its oracle ID, so a scanner or companion app can identify and file a card in
+// a single request.
+//
+// Query params:
+//   - set, cn: set code and collector number, e.g. set=lea&cn=161 ("0161/295" is accepted)
+//   - name: card name, used when no collector number is given; set narrows it to that set
+func (h *SearchHandler) LookupCard(c fiber.Ctx) error {
+	lookup := services.CardLookup{
+		SetCode:         c.Query("set"),
+		CollectorNumber: c.Query("cn"),
+		Name:            c.Query("name"),
+	}
+	hasNumber := strings.TrimSpace(lookup.SetCode) != "" && strings.TrimSpace(lookup.CollectorNumber) != ""
+	if !hasNumber && strings.TrimSpace(lookup.Name) == "" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "set and cn, or name, are required")
+	}
+
+	card, err := services.LookupCard(c.RequestCtx(), h.db, lookup)
+	if errors.Is(err, services.ErrCardNotFound) {
+		return utils.ReturnError(c, fiber.StatusNotFound, "Card not found")
+	}
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to look up card", "card lookup failed", err)
+	}
+
+	scryfallCard, err := card.ToScryfallCard()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to read card data", "card has invalid JSON", err)
+	}
+	return c.JSON(h.withInventory(c, []scryfall.Card{scryfallCard})[0])
+}
+
+// parseCardSearchFilters reads and validates the local search query params
+func parseCardSearchFilters(c fiber.Ctx) (services.CardSearchFilters, error) {
+	filters := services.CardSearchFilters{
+		Query:   strings.TrimSpace(c.Query("q")),
+		SetCode: strings.ToLower(strings.TrimSpace(c.Query("set"))),
+	}
+
+	for _, color := range strings.Split(strings.ToLower(c.Query("color")), "") {
+		if color == "" {
+			continue
+		}
+		if !slices.Contains(validSearchColors, color) {
+			return filters, fmt.Errorf("invalid color %q", color)
+		}
+		filters.Colors = append(filters.Colors, color)
+	}
+	if slices.Contains(filters.Colors, "c") && len(filters.Colors) > 1 {
+		return filters, fmt.Errorf("colorless cannot be combined with other colors")
+	}
+
+	for _, rarity := range strings.Split(strings.ToLower(c.Query("rarity")), ",") {
+		rarity = strings.TrimSpace(rarity)
+		if rarity == "" {
+			continue
+		}
+		if !slices.Contains(validSearchRarities, rarity) {
+			return filters, fmt.Errorf("invalid rarity %q", rarity)
+		}
+		filters.Rarities = append(filters.Rarities, rarity)
+	}
+
+	ranges := []struct {
+		name   string
+		target **float64
+	}{
+		{"cmc_min", &filters.CMCMin},
+		{"cmc_max", &filters.CMCMax},
+		{"price_min", &filters.PriceMin},
+		{"price_max", &filters.PriceMax},
+	}
+	for _, r := range ranges {
+		value := c.Query(r.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return filters, fmt.Errorf("%s must be a non-negative number", r.name)
+		}
+		*r.target = &parsed
+	}
+
+	return filters, nil
+}
+
+// withInventory pairs each card with the inventory held for its oracle ID
+func (h *SearchHandler) withInventory(c fiber.Ctx, cards []scryfall.Card) []EnhancedCardResult {
+	// Collect all oracle_ids from search results
+	oracleIDs := make([]string, len(cards))
+	for i, card := range cards {
+		oracleIDs[i] = card.OracleID
+	}
+
+	// Query all inventory items matching these oracle_ids
+	var allInventory []models.Inventory
+	if len(oracleIDs) > 0 {
+		if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").
+			Where("oracle_id IN ?", oracleIDs).
+			Find(&allInventory).Error; err != nil {
+			slog.WarnContext(c.RequestCtx(), "inventory lookup failed", "component", "search", "error", err)
+		}
+	}
+
+	// Build a map of oracle_id -> inventory items for quick lookup
+	inventoryByOracle := make(map[string][]models.Inventory)
+	for _, inv := range allInventory {
+		inventoryByOracle[inv.OracleID] = append(inventoryByOracle[inv.OracleID], inv)
+	}
+
+	links := services.ExternalLinksEnabled(c.RequestCtx(), h.db)
+	results := make([]EnhancedCardResult, len(cards))
+	for i, card := range cards {
+		// Split inventory into this printing vs other printings
+		inventoryData := CardInventoryData{
+			ThisPrinting:   []models.Inventory{},
+			OtherPrintings: []models.Inventory{},
+			TotalQuantity:  0,
+		}
+
+		for _, inv := range inventoryByOracle[card.OracleID] {
+			inventoryData.TotalQuantity += inv.Quantity
+			if inv.ScryfallID == card.ID {
+				inventoryData.ThisPrinting = append(inventoryData.ThisPrinting, inv)
+			} else {
+				inventoryData.OtherPrintings = append(inventoryData.OtherPrintings, inv)
+			}
+		}
+
+		results[i] = EnhancedCardResult{
+			CardResult: BuildCardResult(card),
+			Inventory:  inventoryData,
+		}
+		if links {
+			results[i] = results[i].withExternalLinks(card)
+		}
+	}
+	return results
+}
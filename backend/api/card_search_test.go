@@ -0,0 +1,226 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupCardSearchTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"id":"bolt-m10","oracle_id":"oracle-bolt","name":"Lightning Bolt","set":"m10","type_line":"Instant","rarity":"common","cmc":1,"colors":["R"],"prices":{"usd":"1.50"}}`},
+		{ScryfallID: "ring-c21", OracleID: "oracle-ring", RawJSON: `{"id":"ring-c21","oracle_id":"oracle-ring","name":"Sol Ring","set":"c21","type_line":"Artifact","rarity":"uncommon","cmc":1,"colors":[],"prices":{"usd":"2.00"}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	handler := NewSearchHandler(nil, db, nil)
+
+	app := fiber.New()
+	app.Get("/cards/search", handler.SearchLocal)
+	app.Get("/cards/lookup", handler.LookupCard)
+
+	return app, db
+}
+
+func TestCardsSearchLocal(t *testing.T) {
+	app, db := setupCardSearchTestApp(t)
+
+	item := models.Inventory{ScryfallID: "bolt-other", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/cards/search?q=bolt&color=r&rarity=common,rare", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result utils.PaginatedResponse[EnhancedCardResult]
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.TotalItems != 1 || len(result.Data) != 1 {
+		t.Fatalf("expected 1 result, got total=%d len=%d", result.TotalItems, len(result.Data))
+	}
+
+	card := result.Data[0]
+	if card.Name != "Lightning Bolt" || card.SetCode != "m10" {
+		t.Errorf("unexpected card: %+v", card.CardResult)
+	}
+	if card.Inventory.TotalQuantity != 3 || len(card.Inventory.OtherPrintings) != 1 {
+		t.Errorf("expected 3 copies owned in another printing, got %+v", card.Inventory)
+	}
+}
+
+func TestCardsSearchLocal_Validation(t *testing.T) {
+	app, _ := setupCardSearchTestApp(t)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"No query or filters", "/cards/search"},
+		{"Invalid color", "/cards/search?color=x"},
+		{"Colorless with colors", "/cards/search?color=cr"},
+		{"Invalid rarity", "/cards/search?rarity=legendary"},
+		{"Invalid cmc", "/cards/search?cmc_min=abc"},
+		{"Negative price", "/cards/search?price_max=-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.url, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", fiber.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCardsLookup(t *testing.T) {
+	app, db := setupCardSearchTestApp(t)
+
+	card := models.Card{ScryfallID: "bolt-lea", OracleID: "oracle-bolt", RawJSON: `{"id":"bolt-lea","oracle_id":"oracle-bolt","name":"Lightning Bolt","set":"lea","collector_number":"161","released_at":"1993-08-05","type_line":"Instant","rarity":"common"}`}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+	items := []models.Inventory{
+		{ScryfallID: "bolt-lea", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 1},
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 2},
+	}
+	for _, item := range items {
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedSet    string
+	}{
+		{"Set and number", "set=lea&cn=161", fiber.StatusOK, "lea"},
+		{"Name and set", "name=lightning+bolt&set=lea", fiber.StatusOK, "lea"},
+		{"Name only", "name=Sol+Ring", fiber.StatusOK, "c21"},
+		{"Not imported", "set=lea&cn=232", fiber.StatusNotFound, ""},
+		{"Number without set", "cn=161", fiber.StatusBadRequest, ""},
+		{"Nothing", "", fiber.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/cards/lookup?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedStatus != fiber.StatusOK {
+				return
+			}
+
+			var result EnhancedCardResult
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.SetCode != tt.expectedSet {
+				t.Errorf("expected set %s, got %s", tt.expectedSet, result.SetCode)
+			}
+		})
+	}
+
+	// Inventory comes back with the card, split by printing
+	resp, _ := app.Test(httptest.NewRequest("GET", "/cards/lookup?set=lea&cn=161", nil))
+	var result EnhancedCardResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Inventory.TotalQuantity != 3 || len(result.Inventory.ThisPrinting) != 1 || len(result.Inventory.OtherPrintings) != 1 {
+		t.Errorf("expected 1 copy of this printing and 2 of another, got %+v", result.Inventory)
+	}
+}
+
+func TestSearch_ScryfallSyntaxAnsweredLocally(t *testing.T) {
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"id":"bolt-m10","oracle_id":"oracle-bolt","name":"Lightning Bolt","set":"m10","type_line":"Instant","oracle_text":"Lightning Bolt deals 3 damage to any target.","rarity":"common","cmc":1,"colors":["R"],"games":["paper","mtgo"],"prices":{"usd":"1.50"}}`},
+		{ScryfallID: "bolt-2ed", OracleID: "oracle-bolt", RawJSON: `{"id":"bolt-2ed","oracle_id":"oracle-bolt","name":"Lightning Bolt","set":"2ed","type_line":"Instant","oracle_text":"Lightning Bolt deals 3 damage to any target.","rarity":"common","cmc":1,"colors":["R"],"games":["paper"],"prices":{"usd":"40.00"}}`},
+		{ScryfallID: "helix-rav", OracleID: "oracle-helix", RawJSON: `{"id":"helix-rav","oracle_id":"oracle-helix","name":"Lightning Helix","set":"rav","type_line":"Instant","oracle_text":"Lightning Helix deals 3 damage to any target and you gain 3 life.","rarity":"uncommon","cmc":2,"colors":["R","W"],"games":["paper"],"prices":{"usd":"0.50"}}`},
+		{ScryfallID: "bolt-arena", OracleID: "oracle-arena-bolt", RawJSON: `{"id":"bolt-arena","oracle_id":"oracle-arena-bolt","name":"Lightning Bolt Alchemy","set":"ymid","type_line":"Instant","rarity":"rare","cmc":1,"colors":["R"],"games":["arena"],"prices":{"usd":null}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	// The Scryfall client is nil, so anything not answered locally would panic
+	handler := NewSearchHandler(nil, db, services.NewSettingsService(db))
+	app := fiber.New()
+	app.Get("/search", handler.Search)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		// The default game:paper search leaves out the Arena-only card, and one printing per card is listed
+		{"lightning t:instant", []string{"Lightning Bolt", "Lightning Helix"}},
+		{"c:rw", []string{"Lightning Helix"}},
+		{`o:"gain 3 life" r:uncommon`, []string{"Lightning Helix"}},
+		{"bolt set:2ed", []string{"Lightning Bolt"}},
+		{"bolt usd>10", []string{"Lightning Bolt"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/search?q="+url.QueryEscape(tt.query), nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+			}
+
+			var result SearchResponse
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.Source != "local" || result.HasMore {
+				t.Errorf("expected one local page, got source %q has_more %v", result.Source, result.HasMore)
+			}
+			if result.TotalCards != len(tt.expected) || len(result.Data) != len(tt.expected) {
+				t.Fatalf("expected %d results, got total=%d len=%d", len(tt.expected), result.TotalCards, len(result.Data))
+			}
+			for i, name := range tt.expected {
+				if result.Data[i].Name != name {
+					t.Errorf("result %d: expected %s, got %s", i, name, result.Data[i].Name)
+				}
+			}
+		})
+	}
+}
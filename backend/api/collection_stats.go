@@ -0,0 +1,159 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// collectionPriceBuckets are the per-copy price ranges of the price breakdown, by
+// upper bound in the preferred currency; copies at or above the last bound are "100+"
+var collectionPriceBuckets = []struct {
+	Key   string
+	Below float64
+}{
+	{"0-1", 1},
+	{"1-5", 5},
+	{"5-20", 20},
+	{"20-50", 50},
+	{"50-100", 100},
+}
+
+// Keys of the breakdown buckets that are not card attributes
+const (
+	collectionKeyUnknown   = "unknown"  // Card missing from the local card data
+	collectionKeyColorless = "C"        // Empty color identity
+	collectionKeyTopPrice  = "100+"     // At or above the last price bucket
+	collectionKeyUnpriced  = "unpriced" // No price in the preferred currency
+)
+
+// CollectionStatsBucket is one slice of a collection breakdown
+// tygo:export
+type CollectionStatsBucket struct {
+	Key   string  `json:"key"`
+	Cards int64   `json:"cards"` // Copies, summing inventory quantities
+	Value float64 `json:"value"` // Value of those copies in the response currency
+}
+
+// CollectionStats breaks the collection down by card attributes. Trashed items are
+// left out, and cards missing from the local card data fall in an "unknown" bucket.
+// tygo:export
+type CollectionStats struct {
+	Currency      models.Currency         `json:"currency"` // Currency the values are in (preferred_currency)
+	TotalCards    int64                   `json:"total_cards"`
+	TotalValue    float64                 `json:"total_value"`
+	ColorIdentity []CollectionStatsBucket `json:"color_identity"` // Keyed by WUBRG letters, "C" for colorless
+	Rarity        []CollectionStatsBucket `json:"rarity"`
+	Set           []CollectionStatsBucket `json:"set"`       // Keyed by set code
+	CardType      []CollectionStatsBucket `json:"card_type"` // Keyed by primary type of the front face
+	Price         []CollectionStatsBucket `json:"price"`     // Per-copy price ranges, cheapest first, including empty ones
+}
+
+// GetCollectionStats returns the collection's distribution by color identity, rarity,
+// set, card type and price, aggregated in SQL over the extracted card columns.
+// Every breakdown except price is ordered by number of copies, most first.
+func (h *DashboardHandler) GetCollectionStats(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+	currency := services.PreferredCurrency(ctx, h.db)
+	stats := CollectionStats{Currency: currency}
+
+	rows := collectionStatsRowsSQL(currency)
+	breakdowns := []struct {
+		name   string
+		key    string
+		target *[]CollectionStatsBucket
+	}{
+		{"color identity", knownCardKeySQL(fmt.Sprintf("CASE WHEN color_identity = '' THEN '%s' ELSE color_identity END", collectionKeyColorless)), &stats.ColorIdentity},
+		{"rarity", knownCardKeySQL("rarity"), &stats.Rarity},
+		{"set", knownCardKeySQL("set_code"), &stats.Set},
+		{"card type", knownCardKeySQL("card_type"), &stats.CardType},
+		{"price", collectionPriceKeySQL(), &stats.Price},
+	}
+	for _, breakdown := range breakdowns {
+		buckets, err := h.collectionBreakdown(ctx, rows, breakdown.key)
+		if err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to calculate collection statistics", "aggregating "+breakdown.name+" failed", err)
+		}
+		*breakdown.target = buckets
+	}
+
+	for _, bucket := range stats.Rarity {
+		stats.TotalCards += bucket.Cards
+		stats.TotalValue += bucket.Value
+	}
+	stats.Price = orderPriceBuckets(stats.Price)
+
+	return c.JSON(stats)
+}
+
+// collectionStatsRowsSQL selects each inventory row outside the trash with its card's
+// extracted columns and its per-copy price in currency
+func collectionStatsRowsSQL(currency models.Currency) string {
+	return fmt.Sprintf(`SELECT inventories.quantity AS quantity,
+			%s AS price,
+			cards.scryfall_id IS NULL AS unknown,
+			COALESCE(cards.color_identity, '') AS color_identity,
+			COALESCE(cards.rarity, '') AS rarity,
+			COALESCE(cards.set_code, '') AS set_code,
+			COALESCE(cards.card_type, '') AS card_type
+		FROM inventories
+		LEFT JOIN cards ON cards.scryfall_id = inventories.scryfall_id
+		WHERE inventories.deleted_at IS NULL`, inventoryPriceOrNullSQL(currency))
+}
+
+// knownCardKeySQL buckets rows by an expression, putting rows without card data or
+// an empty value under "unknown"
+func knownCardKeySQL(expression string) string {
+	return fmt.Sprintf("CASE WHEN unknown OR (%[1]s) = '' THEN '%[2]s' ELSE (%[1]s) END", expression, collectionKeyUnknown)
+}
+
+// collectionPriceKeySQL buckets rows by per-copy price
+func collectionPriceKeySQL() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CASE WHEN price IS NULL THEN '%s' ", collectionKeyUnpriced)
+	for _, bucket := range collectionPriceBuckets {
+		fmt.Fprintf(&b, "WHEN price < %g THEN '%s' ", bucket.Below, bucket.Key)
+	}
+	fmt.Fprintf(&b, "ELSE '%s' END", collectionKeyTopPrice)
+	return b.String()
+}
+
+// collectionBreakdown sums copies and value per key over the collection rows
+func (h *DashboardHandler) collectionBreakdown(ctx context.Context, rows, key string) ([]CollectionStatsBucket, error) {
+	buckets := []CollectionStatsBucket{}
+	err := h.db.WithContext(ctx).Raw(fmt.Sprintf(`SELECT %s AS key,
+			SUM(quantity) AS cards,
+			COALESCE(SUM(quantity * price), 0) AS value
+		FROM (%s)
+		GROUP BY 1
+		ORDER BY cards DESC, key ASC`, key, rows)).Scan(&buckets).Error
+	return buckets, err
+}
+
+// orderPriceBuckets returns every price bucket cheapest first, then unpriced, filling
+// in empty ones so charts keep a fixed axis
+func orderPriceBuckets(found []CollectionStatsBucket) []CollectionStatsBucket {
+	byKey := make(map[string]CollectionStatsBucket, len(found))
+	for _, bucket := range found {
+		byKey[bucket.Key] = bucket
+	}
+
+	keys := make([]string, 0, len(collectionPriceBuckets)+2)
+	for _, bucket := range collectionPriceBuckets {
+		keys = append(keys, bucket.Key)
+	}
+	keys = append(keys, collectionKeyTopPrice, collectionKeyUnpriced)
+
+	ordered := make([]CollectionStatsBucket, len(keys))
+	for i, key := range keys {
+		ordered[i] = byKey[key]
+		ordered[i].Key = key
+	}
+	return ordered
+}
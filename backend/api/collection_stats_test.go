@@ -0,0 +1,141 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupCollectionStatsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	app := fiber.New()
+	app.Get("/stats/collection", NewDashboardHandler(db).GetCollectionStats)
+	return app, db
+}
+
+// createStatsTestCard stores a card with the attributes the breakdowns read
+func createStatsTestCard(t *testing.T, db *gorm.DB, id, set, rarity, identity, typeLine, usd string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: id,
+		OracleID:   "oracle-" + id,
+		RawJSON: fmt.Sprintf(`{"id": %q, "name": %q, "set": %q, "rarity": %q, "color_identity": %s,
+			"type_line": %q, "prices": {"usd": %q}}`, id, id, set, rarity, identity, typeLine, usd),
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func getCollectionStats(t *testing.T, app *fiber.App) CollectionStats {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/stats/collection", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var stats CollectionStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return stats
+}
+
+// bucketSummary renders buckets as "key=cards" pairs in response order
+func bucketSummary(buckets []CollectionStatsBucket) string {
+	summary := ""
+	for i, bucket := range buckets {
+		if i > 0 {
+			summary += " "
+		}
+		summary += fmt.Sprintf("%s=%d", bucket.Key, bucket.Cards)
+	}
+	return summary
+}
+
+func TestGetCollectionStats(t *testing.T) {
+	app, db := setupCollectionStatsTestApp(t)
+	createStatsTestCard(t, db, "bolt", "lea", "common", `["R"]`, "Instant", "0.50")
+	createStatsTestCard(t, db, "helix", "rav", "uncommon", `["R","W"]`, "Instant", "2.00")
+	createStatsTestCard(t, db, "golem", "lea", "rare", `[]`, "Artifact Creature — Golem", "150.00")
+	createStatsTestCard(t, db, "scheme", "arc", "rare", `[]`, "Scheme", "")
+
+	for _, item := range []models.Inventory{
+		{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 4},
+		{ScryfallID: "helix", OracleID: "oracle-helix", Treatment: "nonfoil", Quantity: 2},
+		{ScryfallID: "golem", OracleID: "oracle-golem", Treatment: "nonfoil", Quantity: 1},
+		{ScryfallID: "scheme", OracleID: "oracle-scheme", Treatment: "nonfoil", Quantity: 1},
+		{ScryfallID: "missing", OracleID: "oracle-missing", Treatment: "nonfoil", Quantity: 3},
+	} {
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+	trashed := models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 10}
+	db.Create(&trashed)
+	db.Delete(&trashed)
+
+	stats := getCollectionStats(t, app)
+
+	if stats.Currency != models.CurrencyUSD {
+		t.Errorf("expected usd, got %q", stats.Currency)
+	}
+	if stats.TotalCards != 11 {
+		t.Errorf("expected 11 cards outside the trash, got %d", stats.TotalCards)
+	}
+	if stats.TotalValue != 156 {
+		t.Errorf("expected total value 156, got %v", stats.TotalValue)
+	}
+
+	tests := []struct {
+		name     string
+		buckets  []CollectionStatsBucket
+		expected string
+	}{
+		{"color identity", stats.ColorIdentity, "R=4 unknown=3 C=2 WR=2"},
+		{"rarity", stats.Rarity, "common=4 unknown=3 rare=2 uncommon=2"},
+		{"set", stats.Set, "lea=5 unknown=3 rav=2 arc=1"},
+		{"card type", stats.CardType, "instant=6 unknown=3 creature=1 other=1"},
+		{"price", stats.Price, "0-1=4 1-5=2 5-20=0 20-50=0 50-100=0 100+=1 unpriced=4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bucketSummary(tt.buckets); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	if stats.Set[0].Value != 152 {
+		t.Errorf("expected lea value 152, got %v", stats.Set[0].Value)
+	}
+}
+
+func TestGetCollectionStats_Empty(t *testing.T) {
+	app, _ := setupCollectionStatsTestApp(t)
+
+	stats := getCollectionStats(t, app)
+
+	if stats.TotalCards != 0 || len(stats.Rarity) != 0 {
+		t.Errorf("expected no cards, got %+v", stats)
+	}
+	if stats.ColorIdentity == nil {
+		t.Error("expected empty breakdowns to be empty lists, not null")
+	}
+	if len(stats.Price) != len(collectionPriceBuckets)+2 {
+		t.Errorf("expected every price bucket, got %s", bucketSummary(stats.Price))
+	}
+}
@@ -17,9 +17,3 @@ const (
 	// MaxBatchItems is the maximum number of items in a batch create operation
 	MaxBatchItems = 500
 )
-
-// Job constants
-const (
-	// DefaultJobRetentionDays is the default number of days to retain completed jobs
-	DefaultJobRetentionDays = 30
-)
@@ -2,8 +2,11 @@ package api
 
 import (
 	"backend/models"
+	"backend/services"
 	"backend/utils"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -11,7 +14,8 @@ import (
 
 // DashboardHandler handles dashboard endpoints
 type DashboardHandler struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache *services.DashboardCacheService
 }
 
 // NewDashboardHandler creates a new dashboard handler
@@ -19,9 +23,15 @@ func NewDashboardHandler(db *gorm.DB) *DashboardHandler {
 	return &DashboardHandler{db: db}
 }
 
-// calculateInventoryValue computes the total USD value of inventory items
-// using treatment-aware pricing via ParsePriceFromScryfall.
-func calculateInventoryValue(db *gorm.DB, items []models.Inventory) float64 {
+// SetCache serves dashboard stats from cache's snapshots, computing them only when
+// the data behind them has changed. Without a cache, stats are computed per request.
+func (h *DashboardHandler) SetCache(cache *services.DashboardCacheService) {
+	h.cache = cache
+}
+
+// calculateInventoryValue computes the total value of inventory items in currency
+// using treatment-aware pricing from the extracted card price columns.
+func calculateInventoryValue(db *gorm.DB, items []models.Inventory, currency models.Currency) float64 {
 	if len(items) == 0 {
 		return 0
 	}
@@ -36,8 +46,8 @@ func calculateInventoryValue(db *gorm.DB, items []models.Inventory) float64 {
 		}
 	}
 
-	// Batch fetch card data
-	scryfallCardMap, err := models.GetScryfallCardsByIDs(db, scryfallIDs)
+	// Batch fetch card prices
+	priceMap, err := models.GetCardPricesByIDs(db, scryfallIDs)
 	if err != nil {
 		slog.Warn("failed to fetch cards for inventory value calculation", "component", "dashboard", "error", err)
 		return 0
@@ -45,25 +55,52 @@ func calculateInventoryValue(db *gorm.DB, items []models.Inventory) float64 {
 
 	var totalValue float64
 	for _, item := range items {
-		if card, ok := scryfallCardMap[item.ScryfallID]; ok {
-			price := utils.ParsePriceFromScryfall(card.Prices, item.Treatment)
-			totalValue += price * float64(item.Quantity)
+		if prices, ok := priceMap[item.ScryfallID]; ok {
+			totalValue += prices.InCurrency(item.Treatment, currency) * float64(item.Quantity)
 		}
 	}
 	return totalValue
 }
 
+// acquisitionResult holds the cost and current value of inventory with a recorded acquisition price.
+type acquisitionResult struct {
+	cost  float64
+	value float64
+}
+
+// calculateAcquisitionGain compares what was paid for inventory items with their current value
+// in currency. Items without an acquired price are left out of both sides.
+func calculateAcquisitionGain(db *gorm.DB, items []models.Inventory, currency models.Currency) acquisitionResult {
+	var result acquisitionResult
+	acquired := make([]models.Inventory, 0, len(items))
+	for _, item := range items {
+		if item.AcquiredPrice != nil {
+			result.cost += *item.AcquiredPrice * float64(item.Quantity)
+			acquired = append(acquired, item)
+		}
+	}
+	result.value = calculateInventoryValue(db, acquired, currency)
+	return result
+}
+
 // DashboardStats represents the statistics for the dashboard
 // tygo:export
 type DashboardStats struct {
-	TotalInventoryCards      int64   `json:"total_inventory_cards"`       // Sum of inventory.quantity
-	TotalWishlistCards       int64   `json:"total_wishlist_cards"`        // Sum of list_item.collected_quantity
-	TotalCollectionValue     float64 `json:"total_collection_value"`      // Value from inventory
-	TotalCollectedFromLists  float64 `json:"total_collected_from_lists"`  // Value of cards collected from lists
-	TotalRemainingListsValue float64 `json:"total_remaining_lists_value"` // Value of cards still needed from lists
-	TotalStorageLocations    int64   `json:"total_storage_locations"`
-	TotalLists               int64   `json:"total_lists"`
-	UnassignedCards          int64   `json:"unassigned_cards"`
+	TotalInventoryCards      int64           `json:"total_inventory_cards"`       // Sum of inventory.quantity
+	TotalWishlistCards       int64           `json:"total_wishlist_cards"`        // Sum of list_item.collected_quantity
+	TotalCollectionValue     float64         `json:"total_collection_value"`      // Value from inventory
+	TotalCollectedFromLists  float64         `json:"total_collected_from_lists"`  // Value of cards collected from lists
+	TotalRemainingListsValue float64         `json:"total_remaining_lists_value"` // Value of cards still needed from lists
+	TotalAcquisitionCost     float64         `json:"total_acquisition_cost"`      // Amount paid for items with an acquired price
+	AcquiredItemsValue       float64         `json:"acquired_items_value"`        // Current value of those same items
+	TotalGainLoss            float64         `json:"total_gain_loss"`             // AcquiredItemsValue minus TotalAcquisitionCost
+	Currency                 models.Currency `json:"currency"`                    // Currency the values are in (preferred_currency)
+	TotalStorageLocations    int64           `json:"total_storage_locations"`
+	TotalLists               int64           `json:"total_lists"`
+	UnassignedCards          int64           `json:"unassigned_cards"`
+
+	// Widgets is the configured dashboard in display order; omitted when none is configured
+	Widgets []DashboardWidgetResult `json:"widgets,omitempty"`
 }
 
 // listValueResult holds the computed collected and remaining values for lists.
@@ -72,8 +109,8 @@ type listValueResult struct {
 	remaining float64
 }
 
-// calculateListValues computes the total collected and remaining values for all list items.
-func calculateListValues(db *gorm.DB, listItems []models.ListItem) listValueResult {
+// calculateListValues computes the total collected and remaining values for all list items in currency.
+func calculateListValues(db *gorm.DB, listItems []models.ListItem, currency models.Currency) listValueResult {
 	// Collect unique scryfall IDs from list items
 	scryfallIDs := make([]string, 0, len(listItems))
 	scryfallIDSet := make(map[string]bool)
@@ -84,8 +121,8 @@ func calculateListValues(db *gorm.DB, listItems []models.ListItem) listValueResu
 		}
 	}
 
-	// Batch fetch card data for price information
-	scryfallCardMap, err := models.GetScryfallCardsByIDs(db, scryfallIDs)
+	// Batch fetch card prices
+	priceMap, err := models.GetCardPricesByIDs(db, scryfallIDs)
 	if err != nil {
 		slog.Warn("failed to fetch cards for list value calculation", "component", "dashboard", "error", err)
 		return listValueResult{}
@@ -94,8 +131,8 @@ func calculateListValues(db *gorm.DB, listItems []models.ListItem) listValueResu
 	// Calculate collected and remaining values
 	var result listValueResult
 	for _, item := range listItems {
-		if scryfallCard, ok := scryfallCardMap[item.ScryfallID]; ok {
-			price := utils.ParsePriceFromScryfall(scryfallCard.Prices, item.Treatment)
+		if prices, ok := priceMap[item.ScryfallID]; ok {
+			price := prices.InCurrency(item.Treatment, currency)
 
 			result.collected += price * float64(item.CollectedQuantity)
 
@@ -119,76 +156,229 @@ func calculateListValues(db *gorm.DB, listItems []models.ListItem) listValueResu
 // - Total inventory value (calculated from card prices)
 // - Total collected from lists value (value of cards already collected from lists)
 // - Total remaining lists value (value of cards still needed to complete lists)
+// - Gain/loss of items with an acquired price (current value minus acquisition cost)
 // - Unassigned card count (inventory items without storage location)
+//
+// With dashboard_exclude_basic_lands on, basic lands are left out of every card count
+// and value (but not of the storage location and list counts).
+//
+// When dashboard widgets are configured, only the stats they display are returned
+// (the rest are left zero) and the widgets are returned in order with goal progress.
+// With a cache set, every stat is computed on a miss and stored for later requests;
+// otherwise only the displayed ones are computed.
 func (h *DashboardHandler) GetStats(c fiber.Ctx) error {
-	db := h.db.WithContext(c.RequestCtx())
+	ctx := c.RequestCtx()
+
+	var widgets []models.DashboardWidget
+	if err := h.db.WithContext(ctx).Order("position ASC, id ASC").Find(&widgets).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch dashboard widgets", "database query failed", err)
+	}
+	want := dashboardWidgetNeeds(widgets)
+
+	excludeBasicLands := services.BasicLandsExcluded(ctx, h.db, "dashboard_exclude_basic_lands")
+	currency := services.PreferredCurrency(ctx, h.db)
+
+	if h.cache == nil {
+		stats, err := h.computeStats(c, want, currency, excludeBasicLands)
+		if stats == nil {
+			return err
+		}
+		stats.Widgets = dashboardWidgetResults(widgets, *stats)
+		return c.JSON(stats)
+	}
+
 	var stats DashboardStats
+	key := services.DashboardStatsKey(currency, excludeBasicLands)
+	hit, err := h.cache.Load(ctx, key, &stats)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load cached dashboard stats", "component", "dashboard", "error", err)
+	}
+	if !hit {
+		// Read the generation first, so a write made while computing discards the result
+		generation := h.cache.Generation()
+		everything := func(models.DashboardWidgetType) bool { return true }
+		computed, err := h.computeStats(c, everything, currency, excludeBasicLands)
+		if computed == nil {
+			return err
+		}
+		stats = *computed
+		if err := h.cache.Store(ctx, key, generation, stats); err != nil {
+			slog.WarnContext(ctx, "failed to cache dashboard stats", "component", "dashboard", "error", err)
+		}
+	}
+
+	stats = wantedStats(stats, want)
+	stats.Widgets = dashboardWidgetResults(widgets, stats)
+	return c.JSON(stats)
+}
+
+// computeStats computes the stats want asks for, leaving the rest zero. When it
+// returns nil stats the error response has already been written and err should be
+// returned.
+func (h *DashboardHandler) computeStats(c fiber.Ctx, want func(models.DashboardWidgetType) bool, currency models.Currency, excludeBasicLands bool) (*DashboardStats, error) {
+	db := h.db.WithContext(c.RequestCtx())
+	stats := DashboardStats{Currency: currency}
+
+	// Card counts and values can leave out basic lands, which otherwise swamp them
+	cards := func(db *gorm.DB) *gorm.DB { return db }
+	if excludeBasicLands {
+		cards = models.ExcludeBasicLands
+	}
 
 	// Count total storage locations
-	var storageCount int64
-	if err := db.Model(&models.StorageLocation{}).Count(&storageCount).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to count storage locations", "database query failed", err)
+	if want(models.DashboardWidgetStorageLocations) {
+		var storageCount int64
+		if err := db.Model(&models.StorageLocation{}).Count(&storageCount).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to count storage locations", "database query failed", err)
+		}
+		stats.TotalStorageLocations = storageCount
 	}
-	stats.TotalStorageLocations = storageCount
 
 	// Count lists
-	var listsCount int64
-	if err := db.Model(&models.List{}).Count(&listsCount).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to count lists", "database query failed", err)
+	if want(models.DashboardWidgetLists) {
+		var listsCount int64
+		if err := db.Model(&models.List{}).Count(&listsCount).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to count lists", "database query failed", err)
+		}
+		stats.TotalLists = listsCount
 	}
-	stats.TotalLists = listsCount
 
 	// Sum total quantity of cards in inventory
-	var inventoryCards int64
-	if err := db.Model(&models.Inventory{}).
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&inventoryCards).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to sum card quantities", "database query failed", err)
+	if want(models.DashboardWidgetInventoryCards) {
+		var inventoryCards int64
+		if err := db.Model(&models.Inventory{}).Scopes(cards).
+			Select("COALESCE(SUM(quantity), 0)").
+			Scan(&inventoryCards).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to sum card quantities", "database query failed", err)
+		}
+		stats.TotalInventoryCards = inventoryCards
 	}
-	stats.TotalInventoryCards = inventoryCards
 
 	// Sum collected quantity of cards in lists
-	var listCards int64
-	if err := db.Model(&models.ListItem{}).
-		Select("COALESCE(SUM(collected_quantity), 0)").
-		Scan(&listCards).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to sum list card quantities", "database query failed", err)
+	if want(models.DashboardWidgetWishlistCards) {
+		var listCards int64
+		if err := db.Model(&models.ListItem{}).Scopes(cards).
+			Select("COALESCE(SUM(collected_quantity), 0)").
+			Scan(&listCards).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to sum list card quantities", "database query failed", err)
+		}
+		stats.TotalWishlistCards = listCards
 	}
-	stats.TotalWishlistCards = listCards
 
 	// Count unassigned cards (inventory items with null storage_location_id)
-	var unassignedCount int64
-	if err := db.Model(&models.Inventory{}).
-		Where("storage_location_id IS NULL").
-		Select("COALESCE(SUM(quantity), 0)").
-		Scan(&unassignedCount).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to count unassigned cards", "database query failed", err)
+	if want(models.DashboardWidgetUnassignedCards) {
+		var unassignedCount int64
+		if err := db.Model(&models.Inventory{}).Scopes(cards).
+			Where("storage_location_id IS NULL").
+			Select("COALESCE(SUM(quantity), 0)").
+			Scan(&unassignedCount).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to count unassigned cards", "database query failed", err)
+		}
+		stats.UnassignedCards = unassignedCount
 	}
-	stats.UnassignedCards = unassignedCount
 
-	// Calculate total collection value from inventory
-	var inventoryItems []models.Inventory
-	if err := db.Find(&inventoryItems).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to calculate collection value", "database query failed", err)
+	// Calculate total collection value and acquisition gain from inventory
+	if want(models.DashboardWidgetCollectionValue) || want(models.DashboardWidgetAcquisitionGain) {
+		var inventoryItems []models.Inventory
+		if err := db.Scopes(cards).Find(&inventoryItems).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to calculate collection value", "database query failed", err)
+		}
+		if want(models.DashboardWidgetCollectionValue) {
+			stats.TotalCollectionValue = calculateInventoryValue(db, inventoryItems, stats.Currency)
+		}
+		if want(models.DashboardWidgetAcquisitionGain) {
+			acquisition := calculateAcquisitionGain(db, inventoryItems, stats.Currency)
+			stats.TotalAcquisitionCost = acquisition.cost
+			stats.AcquiredItemsValue = acquisition.value
+			stats.TotalGainLoss = acquisition.value - acquisition.cost
+		}
 	}
-	stats.TotalCollectionValue = calculateInventoryValue(db, inventoryItems)
 
 	// Calculate total wishlist values (both collected and remaining)
-	var listItems []models.ListItem
-	if err := db.Find(&listItems).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to fetch list items", "database query failed", err)
+	if want(models.DashboardWidgetListValues) {
+		var listItems []models.ListItem
+		if err := db.Scopes(cards).Find(&listItems).Error; err != nil {
+			return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to fetch list items", "database query failed", err)
+		}
+
+		listValues := calculateListValues(db, listItems, stats.Currency)
+		stats.TotalCollectedFromLists = listValues.collected
+		stats.TotalRemainingListsValue = listValues.remaining
 	}
 
-	listValues := calculateListValues(db, listItems)
-	stats.TotalCollectedFromLists = listValues.collected
-	stats.TotalRemainingListsValue = listValues.remaining
+	return &stats, nil
+}
 
-	return c.JSON(stats)
+// wantedStats zeroes the stats want doesn't ask for, so a cached snapshot computed
+// for every widget matches what computing only the displayed ones returns
+func wantedStats(stats DashboardStats, want func(models.DashboardWidgetType) bool) DashboardStats {
+	if !want(models.DashboardWidgetStorageLocations) {
+		stats.TotalStorageLocations = 0
+	}
+	if !want(models.DashboardWidgetLists) {
+		stats.TotalLists = 0
+	}
+	if !want(models.DashboardWidgetInventoryCards) {
+		stats.TotalInventoryCards = 0
+	}
+	if !want(models.DashboardWidgetWishlistCards) {
+		stats.TotalWishlistCards = 0
+	}
+	if !want(models.DashboardWidgetUnassignedCards) {
+		stats.UnassignedCards = 0
+	}
+	if !want(models.DashboardWidgetCollectionValue) {
+		stats.TotalCollectionValue = 0
+	}
+	if !want(models.DashboardWidgetAcquisitionGain) {
+		stats.TotalAcquisitionCost = 0
+		stats.AcquiredItemsValue = 0
+		stats.TotalGainLoss = 0
+	}
+	if !want(models.DashboardWidgetListValues) {
+		stats.TotalCollectedFromLists = 0
+		stats.TotalRemainingListsValue = 0
+	}
+	return stats
+}
+
+const (
+	// defaultCountsHistoryDays is how many days of counts are returned when days is not given
+	defaultCountsHistoryDays = 90
+	// maxCountsHistoryDays caps the days query parameter
+	maxCountsHistoryDays = 365
+)
+
+// CountsHistoryResponse represents daily inventory totals for growth charts
+// tygo:export
+type CountsHistoryResponse struct {
+	Days   int                     `json:"days"`
+	Counts []models.InventoryCount `json:"counts"`
+}
+
+// GetCountsHistory returns recorded daily inventory totals, oldest first.
+// Query param days (default 90, max 365) limits how far back to look.
+func (h *DashboardHandler) GetCountsHistory(c fiber.Ctx) error {
+	days := fiber.Query[int](c, "days", defaultCountsHistoryDays)
+	if days < 1 || days > maxCountsHistoryDays {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("days must be between 1 and %d", maxCountsHistoryDays))
+	}
+
+	history := services.NewInventoryHistoryService(h.db)
+	counts, err := history.History(c.RequestCtx(), days, time.Now())
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch counts history", "inventory count query failed", err)
+	}
+
+	return c.JSON(CountsHistoryResponse{Days: days, Counts: counts})
 }
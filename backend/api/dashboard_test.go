@@ -2,10 +2,12 @@ package api
 
 import (
 	"backend/models"
+	"backend/services"
 	"encoding/json"
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/driver/sqlite"
@@ -20,13 +22,16 @@ func setupDashboardTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.StorageLocation{}, &models.List{}, &models.ListItem{}, &models.Inventory{}, &models.Card{}); err != nil {
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.List{}, &models.ListItem{}, &models.Inventory{}, &models.Card{}, &models.InventoryCount{}, &models.DashboardWidget{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
 	app := fiber.New()
 	handler := NewDashboardHandler(db)
 	app.Get("/dashboard", handler.GetStats)
+	app.Get("/dashboard/counts-history", handler.GetCountsHistory)
+	app.Get("/dashboard/widgets", handler.GetWidgets)
+	app.Put("/dashboard/widgets", handler.UpdateWidgets)
 
 	return app, db
 }
@@ -724,3 +729,256 @@ func TestDashboard_UnassignedCardsCount(t *testing.T) {
 		t.Errorf("expected 10 total inventory cards (3+5+2), got %d", stats.TotalInventoryCards)
 	}
 }
+
+// Counts history tests
+
+func TestDashboard_CountsHistory(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+
+	today := time.Now()
+	counts := []models.InventoryCount{
+		{Date: today.AddDate(0, 0, -40).Format(models.InventoryCountDateFormat), Entries: 1, Quantity: 1},
+		{Date: today.AddDate(0, 0, -1).Format(models.InventoryCountDateFormat), Entries: 2, Quantity: 5},
+		{Date: today.Format(models.InventoryCountDateFormat), Entries: 3, Quantity: 8},
+	}
+	for i := range counts {
+		if err := db.Create(&counts[i]).Error; err != nil {
+			t.Fatalf("failed to create count: %v", err)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/dashboard/counts-history?days=30", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result CountsHistoryResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.Days != 30 {
+		t.Errorf("expected days 30, got %d", result.Days)
+	}
+	if len(result.Counts) != 2 {
+		t.Fatalf("expected 2 counts within 30 days, got %d", len(result.Counts))
+	}
+	if result.Counts[0].Quantity != 5 || result.Counts[1].Quantity != 8 {
+		t.Errorf("expected counts oldest first, got %+v", result.Counts)
+	}
+}
+
+func TestDashboard_CountsHistory_InvalidDays(t *testing.T) {
+	app, _ := setupDashboardTestApp(t)
+
+	for _, days := range []string{"0", "366"} {
+		resp, err := app.Test(httptest.NewRequest("GET", "/dashboard/counts-history?days="+days, nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("days=%s: expected status %d, got %d", days, fiber.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestDashboard_PreferredCurrency(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+	db.Create(&models.Setting{Key: "preferred_currency", Value: "eur"})
+
+	db.Create(&models.Card{
+		ScryfallID: "card-1",
+		OracleID:   "oracle-1",
+		RawJSON:    `{"id": "card-1", "name": "Test Card", "prices": {"usd": "10.00", "eur": "8.00", "eur_foil": "20.00"}}`,
+	})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 2})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "foil", Quantity: 1})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/dashboard", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var stats DashboardStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if stats.Currency != models.CurrencyEUR {
+		t.Errorf("expected currency eur, got %q", stats.Currency)
+	}
+	// 8.00 * 2 + 20.00 * 1
+	if stats.TotalCollectionValue != 36.0 {
+		t.Errorf("expected collection value 36.00, got %f", stats.TotalCollectionValue)
+	}
+}
+
+func TestDashboard_AcquisitionGainLoss(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+
+	db.Create(&models.Card{
+		ScryfallID: "card-1",
+		OracleID:   "oracle-1",
+		RawJSON:    `{"id": "card-1", "name": "Test Card", "prices": {"usd": "10.00", "usd_foil": "25.00"}}`,
+	})
+	paid := 4.0
+	paidFoil := 30.0
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 3, AcquiredPrice: &paid})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "foil", Quantity: 1, AcquiredPrice: &paidFoil})
+	// No acquired price, so it only counts toward the collection value
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 2})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/dashboard", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var stats DashboardStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	// 4.00 * 3 + 30.00 * 1
+	if stats.TotalAcquisitionCost != 42.0 {
+		t.Errorf("expected acquisition cost 42.00, got %f", stats.TotalAcquisitionCost)
+	}
+	// 10.00 * 3 + 25.00 * 1
+	if stats.AcquiredItemsValue != 55.0 {
+		t.Errorf("expected acquired items value 55.00, got %f", stats.AcquiredItemsValue)
+	}
+	if stats.TotalGainLoss != 13.0 {
+		t.Errorf("expected gain of 13.00, got %f", stats.TotalGainLoss)
+	}
+	// 10.00 * 5 + 25.00 * 1
+	if stats.TotalCollectionValue != 75.0 {
+		t.Errorf("expected collection value 75.00, got %f", stats.TotalCollectionValue)
+	}
+}
+
+func TestDashboard_ExcludeBasicLands(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+
+	db.Create(&models.Card{
+		ScryfallID: "forest",
+		OracleID:   "oracle-forest",
+		RawJSON:    `{"id": "forest", "name": "Forest", "type_line": "Basic Land — Forest", "prices": {"usd": "0.10"}}`,
+	})
+	db.Create(&models.Card{
+		ScryfallID: "card-1",
+		OracleID:   "oracle-1",
+		RawJSON:    `{"id": "card-1", "name": "Test Card", "type_line": "Instant", "prices": {"usd": "2.00"}}`,
+	})
+	db.Create(&models.Inventory{ScryfallID: "forest", OracleID: "oracle-forest", Treatment: "nonfoil", Quantity: 500})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 3})
+	list := &models.List{Name: "Deck"}
+	db.Create(list)
+	db.Create(&models.ListItem{ListID: list.ID, ScryfallID: "forest", OracleID: "oracle-forest", Treatment: "nonfoil", DesiredQuantity: 20, CollectedQuantity: 10})
+	db.Create(&models.ListItem{ListID: list.ID, ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", DesiredQuantity: 4, CollectedQuantity: 1})
+
+	getStats := func() DashboardStats {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", "/dashboard", nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		var stats DashboardStats
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return stats
+	}
+
+	stats := getStats()
+	if stats.TotalInventoryCards != 503 || stats.UnassignedCards != 503 || stats.TotalWishlistCards != 11 {
+		t.Errorf("expected basics counted by default, got %+v", stats)
+	}
+
+	db.Create(&models.Setting{Key: "dashboard_exclude_basic_lands", Value: "true"})
+	stats = getStats()
+	if stats.TotalInventoryCards != 3 || stats.UnassignedCards != 3 || stats.TotalWishlistCards != 1 {
+		t.Errorf("expected basics left out of counts, got %+v", stats)
+	}
+	if stats.TotalCollectionValue != 6.0 || stats.TotalCollectedFromLists != 2.0 || stats.TotalRemainingListsValue != 6.0 {
+		t.Errorf("expected basics left out of values, got %+v", stats)
+	}
+	if stats.TotalLists != 1 {
+		t.Errorf("expected the list still counted, got %d", stats.TotalLists)
+	}
+}
+
+func TestDashboard_CachedStats(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+	if err := db.AutoMigrate(&models.DashboardStatsSnapshot{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate cache: %v", err)
+	}
+	cache := services.NewDashboardCacheService(db)
+	handler := NewDashboardHandler(db)
+	handler.SetCache(cache)
+	app.Get("/dashboard/cached", handler.GetStats)
+
+	db.Create(&models.StorageLocation{Name: "Box 1", StorageType: models.Box})
+	db.Create(&models.Card{
+		ScryfallID: "card-1",
+		OracleID:   "oracle-1",
+		RawJSON:    `{"id": "card-1", "name": "Test Card", "prices": {"usd": "2.00"}}`,
+	})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 3})
+
+	getStats := func() DashboardStats {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", "/dashboard/cached", nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		var stats DashboardStats
+		if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		return stats
+	}
+
+	stats := getStats()
+	if stats.TotalInventoryCards != 3 || stats.TotalCollectionValue != 6.0 {
+		t.Fatalf("expected 3 cards worth 6.00, got %d worth %f", stats.TotalInventoryCards, stats.TotalCollectionValue)
+	}
+
+	// Without Watch nothing invalidates, so later writes go unseen until Invalidate
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 2})
+	if stats := getStats(); stats.TotalInventoryCards != 3 {
+		t.Errorf("expected the cached 3 cards, got %d", stats.TotalInventoryCards)
+	}
+	cache.Invalidate()
+	if stats := getStats(); stats.TotalInventoryCards != 5 || stats.TotalCollectionValue != 10.0 {
+		t.Errorf("expected 5 cards worth 10.00 after invalidation, got %d worth %f", stats.TotalInventoryCards, stats.TotalCollectionValue)
+	}
+
+	// Snapshots hold every stat, but only the configured widgets' are returned
+	db.Create(&models.DashboardWidget{Type: models.DashboardWidgetInventoryCards})
+	stats = getStats()
+	if stats.TotalInventoryCards != 5 {
+		t.Errorf("expected 5 cards from the snapshot, got %d", stats.TotalInventoryCards)
+	}
+	if stats.TotalStorageLocations != 0 || stats.TotalCollectionValue != 0 {
+		t.Errorf("expected unconfigured stats left zero, got %d locations worth %f", stats.TotalStorageLocations, stats.TotalCollectionValue)
+	}
+	if len(stats.Widgets) != 1 {
+		t.Errorf("expected 1 widget, got %d", len(stats.Widgets))
+	}
+
+	// Changing the currency reads a separate snapshot
+	db.Create(&models.Setting{Key: "preferred_currency", Value: "eur"})
+	if stats := getStats(); stats.Currency != models.CurrencyEUR {
+		t.Errorf("expected eur stats, got %q", stats.Currency)
+	}
+}
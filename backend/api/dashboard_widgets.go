@@ -0,0 +1,155 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// maxDashboardWidgets caps how many widgets a dashboard can hold
+const maxDashboardWidgets = 50
+
+// DashboardGoalProgress reports how far a goal widget's metric is towards its target
+// tygo:export
+type DashboardGoalProgress struct {
+	Current    float64 `json:"current"`
+	Target     float64 `json:"target"`
+	Percentage int     `json:"percentage"` // Capped at 100
+}
+
+// DashboardWidgetResult is a configured widget as returned with the dashboard stats
+// tygo:export
+type DashboardWidgetResult struct {
+	models.DashboardWidget
+	Progress *DashboardGoalProgress `json:"progress,omitempty"` // Goal widgets only
+}
+
+// DashboardWidgetRequest describes one widget in an UpdateDashboardWidgetsRequest
+// tygo:export
+type DashboardWidgetRequest struct {
+	Type   models.DashboardWidgetType `json:"type"`
+	Label  string                     `json:"label,omitempty"`
+	Metric models.DashboardWidgetType `json:"metric,omitempty"` // Goal widgets only
+	Target float64                    `json:"target,omitempty"` // Goal widgets only
+}
+
+// UpdateDashboardWidgetsRequest replaces the dashboard with widgets in display order
+// tygo:export
+type UpdateDashboardWidgetsRequest struct {
+	Widgets []DashboardWidgetRequest `json:"widgets"`
+}
+
+// GetWidgets returns the configured dashboard widgets in display order. An empty
+// list means no dashboard is configured and every stat is computed.
+func (h *DashboardHandler) GetWidgets(c fiber.Ctx) error {
+	widgets := []models.DashboardWidget{}
+	if err := h.db.WithContext(c.RequestCtx()).Order("position ASC, id ASC").Find(&widgets).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch dashboard widgets", "database query failed", err)
+	}
+	return c.JSON(widgets)
+}
+
+// UpdateWidgets replaces the dashboard widgets with the request's list, positioned in
+// the order given. An empty list clears the configuration.
+func (h *DashboardHandler) UpdateWidgets(c fiber.Ctx) error {
+	var req UpdateDashboardWidgetsRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Widgets) > maxDashboardWidgets {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("a dashboard can hold at most %d widgets", maxDashboardWidgets))
+	}
+
+	widgets := make([]models.DashboardWidget, len(req.Widgets))
+	for i, w := range req.Widgets {
+		widgets[i] = models.DashboardWidget{
+			Position: i,
+			Type:     w.Type,
+			Label:    w.Label,
+			Metric:   w.Metric,
+			Target:   w.Target,
+		}
+		if err := widgets[i].ValidateDashboardWidget(h.db); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("widgets[%d]: %v", i, err))
+		}
+	}
+
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.DashboardWidget{}).Error; err != nil {
+			return err
+		}
+		if len(widgets) == 0 {
+			return nil
+		}
+		return tx.Create(&widgets).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save dashboard widgets", "database update failed", err)
+	}
+
+	return c.JSON(widgets)
+}
+
+// dashboardWidgetNeeds returns whether the stats behind a widget type should be
+// computed: all of them when no dashboard is configured, otherwise only those a
+// widget or goal displays
+func dashboardWidgetNeeds(widgets []models.DashboardWidget) func(models.DashboardWidgetType) bool {
+	if len(widgets) == 0 {
+		return func(models.DashboardWidgetType) bool { return true }
+	}
+
+	needed := make(map[models.DashboardWidgetType]bool, len(widgets))
+	for _, w := range widgets {
+		if w.Type == models.DashboardWidgetGoal {
+			needed[w.Metric] = true
+		} else {
+			needed[w.Type] = true
+		}
+	}
+	return func(t models.DashboardWidgetType) bool { return needed[t] }
+}
+
+// dashboardWidgetResults pairs the configured widgets with goal progress from stats
+func dashboardWidgetResults(widgets []models.DashboardWidget, stats DashboardStats) []DashboardWidgetResult {
+	if len(widgets) == 0 {
+		return nil
+	}
+
+	results := make([]DashboardWidgetResult, len(widgets))
+	for i, w := range widgets {
+		results[i] = DashboardWidgetResult{DashboardWidget: w}
+		if w.Type != models.DashboardWidgetGoal || w.Target <= 0 {
+			continue
+		}
+		current := goalMetricValue(w.Metric, stats)
+		results[i].Progress = &DashboardGoalProgress{
+			Current:    current,
+			Target:     w.Target,
+			Percentage: min(int(current/w.Target*100), 100),
+		}
+	}
+	return results
+}
+
+// goalMetricValue returns the stat a goal widget tracks
+func goalMetricValue(metric models.DashboardWidgetType, stats DashboardStats) float64 {
+	switch metric {
+	case models.DashboardWidgetStorageLocations:
+		return float64(stats.TotalStorageLocations)
+	case models.DashboardWidgetLists:
+		return float64(stats.TotalLists)
+	case models.DashboardWidgetInventoryCards:
+		return float64(stats.TotalInventoryCards)
+	case models.DashboardWidgetWishlistCards:
+		return float64(stats.TotalWishlistCards)
+	case models.DashboardWidgetCollectionValue:
+		return stats.TotalCollectionValue
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func putDashboardWidgets(t *testing.T, app *fiber.App, body string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/dashboard/widgets", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestDashboardWidgets_ReplaceAndList(t *testing.T) {
+	app, _ := setupDashboardTestApp(t)
+
+	resp := putDashboardWidgets(t, app, `{"widgets": [
+		{"type": "inventory_cards"},
+		{"type": "goal", "label": "1k cards", "metric": "inventory_cards", "target": 1000},
+		{"type": "lists"}
+	]}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Replacing drops the old configuration
+	resp = putDashboardWidgets(t, app, `{"widgets": [{"type": "lists"}, {"type": "unassigned_cards"}]}`)
+	resp.Body.Close()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard/widgets", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var widgets []models.DashboardWidget
+	if err := json.NewDecoder(resp.Body).Decode(&widgets); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(widgets) != 2 || widgets[0].Type != models.DashboardWidgetLists || widgets[1].Position != 1 {
+		t.Errorf("expected lists then unassigned_cards, got %+v", widgets)
+	}
+}
+
+func TestDashboardWidgets_Invalid(t *testing.T) {
+	app, _ := setupDashboardTestApp(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Malformed body", `{"widgets":`},
+		{"Unknown type", `{"widgets": [{"type": "weather"}]}`},
+		{"Goal without target", `{"widgets": [{"type": "goal", "metric": "lists"}]}`},
+		{"Goal on a multi-value widget", `{"widgets": [{"type": "goal", "metric": "list_values", "target": 5}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := putDashboardWidgets(t, app, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestDashboard_StatsWithWidgets(t *testing.T) {
+	app, db := setupDashboardTestApp(t)
+
+	db.Create(&models.StorageLocation{Name: "Box 1", StorageType: models.Box})
+	db.Create(&models.List{Name: "Wishlist"})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 250})
+
+	resp := putDashboardWidgets(t, app, `{"widgets": [
+		{"type": "goal", "metric": "inventory_cards", "target": 1000},
+		{"type": "lists"}
+	]}`)
+	resp.Body.Close()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/dashboard", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var stats DashboardStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if stats.TotalInventoryCards != 250 || stats.TotalLists != 1 {
+		t.Errorf("expected configured stats computed, got %d cards and %d lists", stats.TotalInventoryCards, stats.TotalLists)
+	}
+	if stats.TotalStorageLocations != 0 {
+		t.Errorf("expected storage locations left uncomputed, got %d", stats.TotalStorageLocations)
+	}
+
+	if len(stats.Widgets) != 2 {
+		t.Fatalf("expected 2 widgets, got %d", len(stats.Widgets))
+	}
+	progress := stats.Widgets[0].Progress
+	if progress == nil || progress.Current != 250 || progress.Target != 1000 || progress.Percentage != 25 {
+		t.Errorf("expected 25%% goal progress, got %+v", progress)
+	}
+	if stats.Widgets[1].Progress != nil {
+		t.Error("expected no progress on a stat widget")
+	}
+}
@@ -3,9 +3,12 @@ package api
 import (
 	"backend/models"
 	"backend/utils"
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,11 +23,16 @@ const CurrentExportVersion = 1
 // DataHandler handles data import and export endpoints
 type DataHandler struct {
 	db *gorm.DB
+	// passphrase decrypts encrypted imports; exports are encrypted by exports
+	passphrase string
+	// exports holds rendered export files so interrupted downloads can resume
+	exports *ExportFiles
 }
 
-// NewDataHandler creates a new data handler
-func NewDataHandler(db *gorm.DB) *DataHandler {
-	return &DataHandler{db: db}
+// NewDataHandler creates a new data handler.
+// When passphrase is non-empty, encrypted imports are decrypted with it.
+func NewDataHandler(db *gorm.DB, passphrase string, exports *ExportFiles) *DataHandler {
+	return &DataHandler{db: db, passphrase: passphrase, exports: exports}
 }
 
 // ExportData represents the full application data export
@@ -34,6 +42,7 @@ type ExportData struct {
 	ExportedAt       string                 `json:"exported_at"`
 	StorageLocations []ExportStorageLocation `json:"storage_locations"`
 	SortingRules     []ExportSortingRule     `json:"sorting_rules"`
+	NamedPredicates  []ExportNamedPredicate  `json:"named_predicates,omitempty"`
 	Inventory        []ExportInventoryItem   `json:"inventory"`
 	Lists            []ExportList            `json:"lists"`
 }
@@ -41,9 +50,11 @@ type ExportData struct {
 // ExportStorageLocation represents a storage location in export format
 // tygo:export
 type ExportStorageLocation struct {
-	RefID       uint               `json:"ref_id"`
-	Name        string             `json:"name"`
-	StorageType models.StorageType `json:"storage_type"`
+	RefID               uint               `json:"ref_id"`
+	Name                string             `json:"name"`
+	StorageType         models.StorageType `json:"storage_type"`
+	PhysicalDescription string             `json:"physical_description,omitempty"` // Photos are not exported
+	ParentRefID         *uint              `json:"parent_ref_id,omitempty"`
 }
 
 // ExportSortingRule represents a sorting rule in export format
@@ -56,23 +67,36 @@ type ExportSortingRule struct {
 	Enabled              bool   `json:"enabled"`
 }
 
+// ExportNamedPredicate represents a named predicate in export format
+// tygo:export
+type ExportNamedPredicate struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression"`
+	Description string `json:"description,omitempty"`
+}
+
 // ExportInventoryItem represents an inventory item in export format
 // tygo:export
 type ExportInventoryItem struct {
 	ScryfallID           string `json:"scryfall_id"`
 	OracleID             string `json:"oracle_id"`
 	Treatment            string `json:"treatment"`
-	Quantity             int    `json:"quantity"`
-	StorageLocationRefID *uint  `json:"storage_location_ref_id,omitempty"`
+	Quantity             int     `json:"quantity"`
+	StorageLocationRefID *uint   `json:"storage_location_ref_id,omitempty"`
+	SerialNumber         *string `json:"serial_number,omitempty"`
+	AcquiredPrice        *float64   `json:"acquired_price,omitempty"`
+	AcquiredAt           *time.Time `json:"acquired_at,omitempty"`
+	AcquiredFrom         string     `json:"acquired_from,omitempty"`
 }
 
 // ExportList represents a list with its items in export format
 // tygo:export
 type ExportList struct {
-	RefID       uint             `json:"ref_id"`
-	Name        string           `json:"name"`
-	Description string           `json:"description,omitempty"`
-	Items       []ExportListItem `json:"items"`
+	RefID              uint             `json:"ref_id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description,omitempty"`
+	AutoTrackInventory bool             `json:"auto_track_inventory,omitempty"`
+	Items              []ExportListItem `json:"items"`
 }
 
 // ExportListItem represents a list item in export format
@@ -90,6 +114,7 @@ type ExportListItem struct {
 type ImportResponse struct {
 	StorageLocationsCreated int      `json:"storage_locations_created"`
 	SortingRulesCreated     int      `json:"sorting_rules_created"`
+	NamedPredicatesCreated  int      `json:"named_predicates_created"`
 	InventoryItemsCreated   int      `json:"inventory_items_created"`
 	ListsCreated            int      `json:"lists_created"`
 	ListItemsCreated        int      `json:"list_items_created"`
@@ -100,6 +125,7 @@ type ImportResponse struct {
 func (h *DataHandler) Export(c fiber.Ctx) error {
 	var storageLocations []models.StorageLocation
 	var sortingRules []models.SortingRule
+	var namedPredicates []models.NamedPredicate
 	var inventory []models.Inventory
 	var lists []models.List
 
@@ -110,6 +136,9 @@ func (h *DataHandler) Export(c fiber.Ctx) error {
 		if err := tx.Find(&sortingRules).Error; err != nil {
 			return fmt.Errorf("sorting rules: %w", err)
 		}
+		if err := tx.Find(&namedPredicates).Error; err != nil {
+			return fmt.Errorf("named predicates: %w", err)
+		}
 		if err := tx.Find(&inventory).Error; err != nil {
 			return fmt.Errorf("inventory: %w", err)
 		}
@@ -127,9 +156,11 @@ func (h *DataHandler) Export(c fiber.Ctx) error {
 	exportLocations := make([]ExportStorageLocation, len(storageLocations))
 	for i, loc := range storageLocations {
 		exportLocations[i] = ExportStorageLocation{
-			RefID:       loc.ID,
-			Name:        loc.Name,
-			StorageType: loc.StorageType,
+			RefID:               loc.ID,
+			Name:                loc.Name,
+			StorageType:         loc.StorageType,
+			PhysicalDescription: loc.PhysicalDescription,
+			ParentRefID:         loc.ParentID,
 		}
 	}
 
@@ -144,6 +175,15 @@ func (h *DataHandler) Export(c fiber.Ctx) error {
 		}
 	}
 
+	exportPredicates := make([]ExportNamedPredicate, len(namedPredicates))
+	for i, p := range namedPredicates {
+		exportPredicates[i] = ExportNamedPredicate{
+			Name:        p.Name,
+			Expression:  p.Expression,
+			Description: p.Description,
+		}
+	}
+
 	exportInventory := make([]ExportInventoryItem, len(inventory))
 	for i, inv := range inventory {
 		exportInventory[i] = ExportInventoryItem{
@@ -151,6 +191,10 @@ func (h *DataHandler) Export(c fiber.Ctx) error {
 			OracleID:   inv.OracleID,
 			Treatment:  inv.Treatment,
 			Quantity:    inv.Quantity,
+			SerialNumber: inv.SerialNumber,
+			AcquiredPrice: inv.AcquiredPrice,
+			AcquiredAt:    inv.AcquiredAt,
+			AcquiredFrom:  inv.AcquiredFrom,
 		}
 		if inv.StorageLocationID != nil {
 			exportInventory[i].StorageLocationRefID = inv.StorageLocationID
@@ -170,33 +214,67 @@ func (h *DataHandler) Export(c fiber.Ctx) error {
 			}
 		}
 		exportLists[i] = ExportList{
-			RefID:       list.ID,
-			Name:        list.Name,
-			Description: list.Description,
-			Items:       items,
+			RefID:              list.ID,
+			Name:               list.Name,
+			Description:        list.Description,
+			AutoTrackInventory: list.AutoTrackInventory,
+			Items:              items,
 		}
 	}
 
 	data := ExportData{
 		Version:          CurrentExportVersion,
-		ExportedAt:       time.Now().UTC().Format(time.RFC3339),
 		StorageLocations: exportLocations,
 		SortingRules:     exportRules,
+		NamedPredicates:  exportPredicates,
 		Inventory:        exportInventory,
 		Lists:            exportLists,
 	}
 
-	filename := fmt.Sprintf("showmycards-export-%s.json", time.Now().UTC().Format("2006-01-02"))
-	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	// Fingerprint the content before stamping it, so unchanged data maps to
+	// the same rendered file and a resumed download gets identical bytes
+	fingerprint, err := exportFingerprint(data)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build export", "export marshal failed", err)
+	}
 
-	return c.JSON(data)
+	path, err := h.exports.file("export", fingerprint, ".json", func(w io.Writer) error {
+		data.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+		content, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("marshal export: %w", err)
+		}
+		_, err = w.Write(content)
+		return err
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build export", "export rendering failed", err)
+	}
+
+	filename := fmt.Sprintf("showmycards-export-%s.json", time.Now().UTC().Format("2006-01-02"))
+	return sendExportFile(c, path, filename, fiber.MIMEApplicationJSON)
 }
 
 // Import accepts exported JSON data and creates records additively
 func (h *DataHandler) Import(c fiber.Ctx) error {
+	body := c.Body()
+	if utils.IsEncrypted(body) {
+		if h.passphrase == "" {
+			return utils.ReturnError(c, fiber.StatusBadRequest,
+				"export is encrypted — set EXPORT_ENCRYPTION_PASSPHRASE to import it")
+		}
+		decrypted, err := utils.DecryptWithPassphrase(body, h.passphrase)
+		if err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		body = decrypted
+	}
+
 	// Parse raw JSON first for version checking and potential migration
 	var raw map[string]any
-	if err := json.Unmarshal(c.Body(), &raw); err != nil {
+	if err := json.Unmarshal(body, &raw); err != nil {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid JSON in request body")
 	}
 
@@ -223,7 +301,7 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 	// Apply migrations if needed (future-proofing)
 	if version < CurrentExportVersion {
 		if err := applyMigrations(raw, version); err != nil {
-			slog.Error("export data migration failed", "from_version", version, "to_version", CurrentExportVersion, "error", err)
+			slog.ErrorContext(c.RequestCtx(), "export data migration failed", "from_version", version, "to_version", CurrentExportVersion, "error", err)
 			return utils.ReturnError(c, fiber.StatusBadRequest,
 				fmt.Sprintf("failed to migrate export data from version %d to %d", version, CurrentExportVersion))
 		}
@@ -247,8 +325,9 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 		// 1. Storage Locations — created first because inventory and rules reference them
 		for _, loc := range data.StorageLocations {
 			newLoc := models.StorageLocation{
-				Name:        loc.Name,
-				StorageType: loc.StorageType,
+				Name:                loc.Name,
+				StorageType:         loc.StorageType,
+				PhysicalDescription: loc.PhysicalDescription,
 			}
 			if err := tx.Create(&newLoc).Error; err != nil {
 				return fmt.Errorf("failed to create storage location %q: %w", loc.Name, err)
@@ -257,8 +336,57 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 			response.StorageLocationsCreated++
 		}
 
-		// 2. Sorting Rules — reference storage locations via ref_id
-		for _, rule := range data.SortingRules {
+		// Nesting is restored once every location exists, since parents may come after children
+		for _, loc := range data.StorageLocations {
+			if loc.ParentRefID == nil {
+				continue
+			}
+			parentID, ok := storageRefMap[*loc.ParentRefID]
+			if !ok {
+				response.Warnings = append(response.Warnings,
+					fmt.Sprintf("storage location %q references unknown parent ref %d, imported at the top level",
+						loc.Name, *loc.ParentRefID))
+				continue
+			}
+			if err := tx.Model(&models.StorageLocation{}).Where("id = ?", storageRefMap[loc.RefID]).
+				UpdateColumn("parent_id", parentID).Error; err != nil {
+				return fmt.Errorf("failed to nest storage location %q: %w", loc.Name, err)
+			}
+		}
+
+		// 2. Named Predicates — created before rules so their expressions resolve
+		for _, p := range data.NamedPredicates {
+			var existing int64
+			if err := tx.Model(&models.NamedPredicate{}).Where("name = ?", p.Name).Count(&existing).Error; err != nil {
+				return fmt.Errorf("failed to check named predicate %q: %w", p.Name, err)
+			}
+			if existing > 0 {
+				response.Warnings = append(response.Warnings,
+					fmt.Sprintf("named predicate %q already exists, kept existing definition", p.Name))
+				continue
+			}
+			newPredicate := models.NamedPredicate{
+				Name:        p.Name,
+				Expression:  p.Expression,
+				Description: p.Description,
+			}
+			if err := tx.Create(&newPredicate).Error; err != nil {
+				return fmt.Errorf("failed to create named predicate %q: %w", p.Name, err)
+			}
+			response.NamedPredicatesCreated++
+		}
+
+		// 3. Sorting Rules — reference storage locations via ref_id. Priorities are
+		// unique, so imported rules keep their relative order after any existing rules.
+		nextPriority, err := nextRulePriority(tx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch highest rule priority: %w", err)
+		}
+		importedRules := slices.Clone(data.SortingRules)
+		slices.SortStableFunc(importedRules, func(a, b ExportSortingRule) int {
+			return cmp.Compare(a.Priority, b.Priority)
+		})
+		for _, rule := range importedRules {
 			newLocID, ok := storageRefMap[rule.StorageLocationRefID]
 			if !ok {
 				response.Warnings = append(response.Warnings,
@@ -268,7 +396,7 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 			}
 			newRule := models.SortingRule{
 				Name:              rule.Name,
-				Priority:          rule.Priority,
+				Priority:          nextPriority,
 				Expression:        rule.Expression,
 				StorageLocationID: newLocID,
 				Enabled:           rule.Enabled,
@@ -276,14 +404,16 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 			if err := tx.Create(&newRule).Error; err != nil {
 				return fmt.Errorf("failed to create sorting rule %q: %w", rule.Name, err)
 			}
+			nextPriority += sortingRulePriorityGap
 			response.SortingRulesCreated++
 		}
 
-		// 3. Lists + Items — items nested under their parent list
+		// 4. Lists + Items — items nested under their parent list
 		for _, list := range data.Lists {
 			newList := models.List{
-				Name:        list.Name,
-				Description: list.Description,
+				Name:               list.Name,
+				Description:        list.Description,
+				AutoTrackInventory: list.AutoTrackInventory,
 			}
 			if err := tx.Create(&newList).Error; err != nil {
 				return fmt.Errorf("failed to create list %q: %w", list.Name, err)
@@ -313,7 +443,7 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 			}
 		}
 
-		// 4. Inventory — reference storage locations via ref_id
+		// 5. Inventory — reference storage locations via ref_id
 		for _, inv := range data.Inventory {
 			var storageLocID *uint
 			if inv.StorageLocationRefID != nil {
@@ -331,6 +461,10 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 				Treatment:         inv.Treatment,
 				Quantity:          inv.Quantity,
 				StorageLocationID: storageLocID,
+				SerialNumber:      inv.SerialNumber,
+				AcquiredPrice:     inv.AcquiredPrice,
+				AcquiredAt:        inv.AcquiredAt,
+				AcquiredFrom:      inv.AcquiredFrom,
 			}
 			if err := tx.Create(&newInv).Error; err != nil {
 				if isDuplicateError(err) {
@@ -341,6 +475,9 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 				}
 				return fmt.Errorf("failed to create inventory item %s: %w", inv.ScryfallID, err)
 			}
+			if err := models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryCreatedEvent(newInv)}); err != nil {
+				return fmt.Errorf("failed to record history for inventory item %s: %w", inv.ScryfallID, err)
+			}
 			response.InventoryItemsCreated++
 		}
 
@@ -348,7 +485,7 @@ func (h *DataHandler) Import(c fiber.Ctx) error {
 	})
 
 	if err != nil {
-		slog.Error("import failed", "error", err)
+		slog.ErrorContext(c.RequestCtx(), "import failed", "error", err)
 		return utils.ReturnError(c, fiber.StatusInternalServerError,
 			"Import failed, all changes have been rolled back")
 	}
@@ -3,11 +3,15 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"backend/models"
+	"backend/utils"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/driver/sqlite"
@@ -16,6 +20,11 @@ import (
 
 func setupDataTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	t.Helper()
+	return setupDataTestAppWithPassphrase(t, "")
+}
+
+func setupDataTestAppWithPassphrase(t *testing.T, passphrase string) (*fiber.App, *gorm.DB) {
+	t.Helper()
 
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	if err != nil {
@@ -25,7 +34,9 @@ func setupDataTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	if err := db.AutoMigrate(
 		&models.StorageLocation{},
 		&models.Inventory{},
+		&models.InventoryEvent{},
 		&models.SortingRule{},
+		&models.NamedPredicate{},
 		&models.List{},
 		&models.ListItem{},
 	); err != nil {
@@ -33,7 +44,7 @@ func setupDataTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	}
 
 	app := fiber.New()
-	handler := NewDataHandler(db)
+	handler := NewDataHandler(db, passphrase, NewExportFiles(t.TempDir(), passphrase))
 
 	app.Get("/api/data/export", handler.Export)
 	app.Post("/api/data/import", handler.Import)
@@ -67,12 +78,15 @@ func seedTestData(t *testing.T, db *gorm.DB) {
 	}
 
 	// Create inventory
+	paid := 7.5
 	inv1 := models.Inventory{
 		ScryfallID:        "scry-001",
 		OracleID:          "oracle-001",
 		Treatment:         "nonfoil",
 		Quantity:          2,
 		StorageLocationID: &box.ID,
+		AcquiredPrice:     &paid,
+		AcquiredFrom:      "LGS",
 	}
 	if err := db.Create(&inv1).Error; err != nil {
 		t.Fatalf("failed to create inventory: %v", err)
@@ -240,6 +254,46 @@ func TestExport_StorageLocationRefIDs(t *testing.T) {
 
 // Import tests
 
+func TestImport_RulesAfterExisting(t *testing.T) {
+	app, db := setupDataTestApp(t)
+
+	location := models.StorageLocation{Name: "Existing Box", StorageType: models.Box}
+	db.Create(&location)
+	db.Create(&models.SortingRule{Name: "Existing", Priority: 1, Expression: "true", StorageLocationID: location.ID, Enabled: true})
+
+	importData := ExportData{
+		Version:          1,
+		ExportedAt:       "2026-01-01T00:00:00Z",
+		StorageLocations: []ExportStorageLocation{{RefID: 10, Name: "Imported Box", StorageType: models.Box}},
+		SortingRules: []ExportSortingRule{
+			{Name: "Later", Priority: 5, Expression: "true", StorageLocationRefID: 10, Enabled: true},
+			{Name: "Earlier", Priority: 1, Expression: "true", StorageLocationRefID: 10, Enabled: true},
+		},
+	}
+	body, _ := json.Marshal(importData)
+	req := httptest.NewRequest(http.MethodPost, "/api/data/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Imported rules keep their order, after the existing rule
+	var rules []models.SortingRule
+	db.Order("priority").Find(&rules)
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	if got := strings.Join(names, ","); got != "Existing,Earlier,Later" {
+		t.Errorf("expected rules Existing,Earlier,Later, got %s", got)
+	}
+}
+
 func TestImport_ValidData(t *testing.T) {
 	app, db := setupDataTestApp(t)
 
@@ -553,6 +607,9 @@ func TestImport_RoundTrip(t *testing.T) {
 	if importedInv.StorageLocationID == nil {
 		t.Error("expected imported scry-001 to have a storage location")
 	}
+	if importedInv.AcquiredPrice == nil || *importedInv.AcquiredPrice != 7.5 || importedInv.AcquiredFrom != "LGS" {
+		t.Errorf("expected acquisition details to round-trip, got price %v from %q", importedInv.AcquiredPrice, importedInv.AcquiredFrom)
+	}
 
 	var importedList models.List
 	freshDB.Preload("Items").Where("name = ?", "Commander Deck").First(&importedList)
@@ -666,3 +723,307 @@ func TestApplyMigrations_NoMigrationsNeeded(t *testing.T) {
 func uintPtr(v uint) *uint {
 	return &v
 }
+
+func TestImport_NamedPredicates(t *testing.T) {
+	app, db := setupDataTestApp(t)
+	db.Create(&models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.50"})
+
+	body, _ := json.Marshal(ExportData{
+		Version: CurrentExportVersion,
+		NamedPredicates: []ExportNamedPredicate{
+			{Name: "isBulk", Expression: "prices.usd < 0.25"},
+			{Name: "isStaple", Expression: "edhrec_rank < 500", Description: "Commander staples"},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/data/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("import request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result ImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode import response: %v", err)
+	}
+
+	if result.NamedPredicatesCreated != 1 {
+		t.Errorf("expected 1 named predicate created, got %d", result.NamedPredicatesCreated)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning for existing predicate, got %v", result.Warnings)
+	}
+
+	var existing models.NamedPredicate
+	db.Where("name = ?", "isBulk").First(&existing)
+	if existing.Expression != "prices.usd < 0.50" {
+		t.Errorf("expected existing predicate to be kept, got %q", existing.Expression)
+	}
+}
+
+// Encryption tests
+
+// encryptedTestConfig gives requests that derive an encryption key time for the
+// PBKDF2 iterations, which outlast Fiber's default one second test timeout under -race
+var encryptedTestConfig = fiber.TestConfig{Timeout: 30 * time.Second}
+
+func TestExportImport_Encrypted(t *testing.T) {
+	app, db := setupDataTestAppWithPassphrase(t, "nas-passphrase")
+	seedTestData(t, db)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/data/export", nil)
+	resp, err := app.Test(req, encryptedTestConfig)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); !strings.HasSuffix(disposition, `.json.enc"`) {
+		t.Errorf("expected .json.enc attachment, got %q", disposition)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if !utils.IsEncrypted(body) {
+		t.Fatal("expected export to be encrypted")
+	}
+	if bytes.Contains(body, []byte("Mythic Box")) {
+		t.Error("expected storage location names not to appear in plaintext")
+	}
+
+	// Importing into a fresh instance with the same passphrase succeeds
+	importApp, importDB := setupDataTestAppWithPassphrase(t, "nas-passphrase")
+	req = httptest.NewRequest(http.MethodPost, "/api/data/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	importResp, err := importApp.Test(req, encryptedTestConfig)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer importResp.Body.Close()
+
+	if importResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, importResp.StatusCode)
+	}
+	var count int64
+	importDB.Model(&models.StorageLocation{}).Count(&count)
+	if count == 0 {
+		t.Error("expected storage locations to be imported")
+	}
+}
+
+func TestImport_EncryptedWithoutPassphrase(t *testing.T) {
+	encrypted, err := utils.EncryptWithPassphrase([]byte(`{"version":1}`), "nas-passphrase")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{name: "No passphrase configured", passphrase: ""},
+		{name: "Wrong passphrase", passphrase: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := setupDataTestAppWithPassphrase(t, tt.passphrase)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/data/import", bytes.NewReader(encrypted))
+			resp, err := app.Test(req, encryptedTestConfig)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestExport_RangeRequests(t *testing.T) {
+	app, db := setupDataTestApp(t)
+	seedTestData(t, db)
+
+	get := func(headers map[string]string) (*http.Response, []byte) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/data/export", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		return resp, body
+	}
+
+	resp, full := get(nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("expected ETag header")
+	}
+	if resp.Header.Get("Accept-Ranges") != "bytes" {
+		t.Errorf("expected Accept-Ranges bytes, got %q", resp.Header.Get("Accept-Ranges"))
+	}
+
+	t.Run("Repeat download is byte-identical", func(t *testing.T) {
+		resp, body := get(nil)
+		if resp.Header.Get("ETag") != etag {
+			t.Errorf("expected ETag %s, got %s", etag, resp.Header.Get("ETag"))
+		}
+		if !bytes.Equal(body, full) {
+			t.Error("expected unchanged data to produce identical export bytes")
+		}
+	})
+
+	t.Run("Resume with matching If-Range", func(t *testing.T) {
+		resp, body := get(map[string]string{"Range": "bytes=10-", "If-Range": etag})
+		if resp.StatusCode != http.StatusPartialContent {
+			t.Fatalf("expected status %d, got %d", http.StatusPartialContent, resp.StatusCode)
+		}
+		if !bytes.Equal(body, full[10:]) {
+			t.Error("expected the remainder of the export")
+		}
+		if resp.Header.Get("Content-Range") == "" {
+			t.Error("expected Content-Range header")
+		}
+	})
+
+	t.Run("Stale If-Range sends the full export", func(t *testing.T) {
+		resp, body := get(map[string]string{"Range": "bytes=10-", "If-Range": `"stale"`})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		if !bytes.Equal(body, full) {
+			t.Error("expected the full export")
+		}
+	})
+
+	t.Run("Changed data gets a new ETag", func(t *testing.T) {
+		if err := db.Create(&models.StorageLocation{Name: "New Binder", StorageType: models.Binder}).Error; err != nil {
+			t.Fatalf("failed to create storage location: %v", err)
+		}
+		resp, _ := get(nil)
+		if resp.Header.Get("ETag") == etag {
+			t.Error("expected ETag to change with the data")
+		}
+	})
+}
+
+func TestExport_RestartDropsEarlierRenders(t *testing.T) {
+	_, db := setupDataTestApp(t)
+	seedTestData(t, db)
+	dataDir := t.TempDir()
+
+	export := func(passphrase string) []byte {
+		t.Helper()
+		app := fiber.New()
+		app.Get("/api/data/export", NewDataHandler(db, passphrase, NewExportFiles(dataDir, passphrase)).Export)
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/data/export", nil), encryptedTestConfig)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("failed to read body: %v", err)
+		}
+		return body
+	}
+
+	export("old-passphrase")
+	// After a restart with a new passphrase the unchanged data must not be served
+	// from the render encrypted with the old one
+	if _, err := utils.DecryptWithPassphrase(export("new-passphrase"), "new-passphrase"); err != nil {
+		t.Errorf("expected the export to decrypt with the new passphrase: %v", err)
+	}
+}
+
+func TestImport_NestedStorageLocations(t *testing.T) {
+	app, db := setupDataTestApp(t)
+
+	// Children listed before their parents still nest correctly
+	importData := ExportData{
+		Version:    1,
+		ExportedAt: "2026-01-01T00:00:00Z",
+		StorageLocations: []ExportStorageLocation{
+			{RefID: 3, Name: "Section", StorageType: models.Box, ParentRefID: uintPtr(2)},
+			{RefID: 2, Name: "Box", StorageType: models.Box, ParentRefID: uintPtr(1)},
+			{RefID: 1, Name: "Shelf", StorageType: models.Box},
+			{RefID: 4, Name: "Stray", StorageType: models.Box, ParentRefID: uintPtr(999)},
+		},
+	}
+
+	body, _ := json.Marshal(importData)
+	req := httptest.NewRequest(http.MethodPost, "/api/data/import", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result ImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected 1 warning for the unknown parent, got %v", result.Warnings)
+	}
+
+	parents := map[string]string{}
+	var locations []models.StorageLocation
+	db.Find(&locations)
+	names := map[uint]string{}
+	for _, loc := range locations {
+		names[loc.ID] = loc.Name
+	}
+	for _, loc := range locations {
+		if loc.ParentID != nil {
+			parents[loc.Name] = names[*loc.ParentID]
+		}
+	}
+	if parents["Section"] != "Box" || parents["Box"] != "Shelf" || len(parents) != 2 {
+		t.Errorf("unexpected nesting after import: %v", parents)
+	}
+
+	// And the nesting survives an export
+	exportResp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/data/export", nil))
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	var exported ExportData
+	if err := json.NewDecoder(exportResp.Body).Decode(&exported); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	nested := 0
+	for _, loc := range exported.StorageLocations {
+		if loc.ParentRefID != nil {
+			nested++
+		}
+	}
+	if nested != 2 {
+		t.Errorf("expected 2 nested locations in export, got %d", nested)
+	}
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// DecksHandler handles deck endpoints
+type DecksHandler struct {
+	db    *gorm.DB
+	decks *services.DeckService
+}
+
+// NewDecksHandler creates a new decks handler
+func NewDecksHandler(db *gorm.DB, decks *services.DeckService) *DecksHandler {
+	return &DecksHandler{db: db, decks: decks}
+}
+
+// List returns decks with pagination, ordered by name. Cards are not included.
+func (h *DecksHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.Deck{})
+	if format := c.Query("format"); format != "" {
+		query = query.Where("format = ?", format)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count decks", "database count failed", err)
+	}
+
+	var decks []models.Deck
+	if err := query.Order("name ASC").
+		Offset(utils.CalculateOffset(params.Page, params.PageSize)).
+		Limit(params.PageSize).
+		Find(&decks).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch decks", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, decks, params.Page, params.PageSize, total)
+}
+
+// Get returns a single deck with its cards
+func (h *DecksHandler) Get(c fiber.Ctx) error {
+	deck, err := h.findDeck(c)
+	if err != nil || deck == nil {
+		return err
+	}
+	return h.sendDeck(c, fiber.StatusOK, deck)
+}
+
+// DeckRequest represents the request body for creating or updating a deck
+// tygo:export
+type DeckRequest struct {
+	Name              string            `json:"name"`
+	Description       string            `json:"description"`
+	Format            models.DeckFormat `json:"format"`
+	CommanderOracleID string            `json:"commander_oracle_id"` // Commander formats only
+}
+
+// Create creates a new deck
+func (h *DecksHandler) Create(c fiber.Ctx) error {
+	var req DeckRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	deck := models.Deck{}
+	if ok, err := h.saveDeck(c, &deck, req); !ok {
+		return err
+	}
+	return h.sendDeck(c, fiber.StatusCreated, &deck)
+}
+
+// Update replaces a deck's name, description, format and commander. Cards are
+// kept; check legality again after changing the format.
+func (h *DecksHandler) Update(c fiber.Ctx) error {
+	deck, err := h.findDeck(c)
+	if err != nil || deck == nil {
+		return err
+	}
+
+	var req DeckRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if ok, err := h.saveDeck(c, deck, req); !ok {
+		return err
+	}
+	return h.sendDeck(c, fiber.StatusOK, deck)
+}
+
+// Delete deletes a deck and its cards
+func (h *DecksHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var deleted int64
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("deck_id = ?", id).Delete(&models.DeckCard{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Deck{}, id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete deck", "database delete failed", err)
+	}
+	if deleted == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "deck not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AddDeckCardRequest represents the request body for adding a card to a deck
+// tygo:export
+type AddDeckCardRequest struct {
+	OracleID string          `json:"oracle_id"`
+	Zone     models.DeckZone `json:"zone"`     // Defaults to mainboard
+	Quantity int             `json:"quantity"` // Defaults to 1
+}
+
+// AddCard adds copies of a card to a deck zone. Adding a card already in the zone
+// increases its quantity.
+func (h *DecksHandler) AddCard(c fiber.Ctx) error {
+	deck, err := h.findDeck(c)
+	if err != nil || deck == nil {
+		return err
+	}
+
+	var req AddDeckCardRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if req.Zone == "" {
+		req.Zone = models.DeckZoneMainboard
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	card := models.DeckCard{DeckID: deck.ID, OracleID: req.OracleID, Zone: req.Zone, Quantity: req.Quantity}
+	if err := card.ValidateDeckCard(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if ok, err := h.requireCard(c, req.OracleID, "card not found"); !ok {
+		return err
+	}
+
+	status := fiber.StatusCreated
+	var existing models.DeckCard
+	err = h.db.WithContext(c.RequestCtx()).
+		Where("deck_id = ? AND oracle_id = ? AND zone = ?", deck.ID, req.OracleID, req.Zone).
+		First(&existing).Error
+	switch {
+	case err == nil:
+		existing.Quantity += req.Quantity
+		card = existing
+		status = fiber.StatusOK
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch deck card", "database query failed", err)
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Save(&card).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to add card to deck", "database save failed", err)
+	}
+	return h.sendDeckCard(c, status, &card)
+}
+
+// UpdateDeckCardRequest represents the request body for updating a deck card
+// tygo:export
+type UpdateDeckCardRequest struct {
+	Zone     *models.DeckZone `json:"zone,omitempty"`
+	Quantity *int             `json:"quantity,omitempty"`
+}
+
+// UpdateCard changes a deck card's quantity or moves it to the other zone
+func (h *DecksHandler) UpdateCard(c fiber.Ctx) error {
+	card, err := h.findDeckCard(c)
+	if err != nil || card == nil {
+		return err
+	}
+
+	var req UpdateDeckCardRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if req.Zone != nil {
+		card.Zone = *req.Zone
+	}
+	if req.Quantity != nil {
+		card.Quantity = *req.Quantity
+	}
+
+	if err := card.ValidateDeckCard(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Save(card).Error; err != nil {
+		if isDuplicateError(err) {
+			return utils.ReturnError(c, fiber.StatusConflict, "card is already in that zone")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update deck card", "database update failed", err)
+	}
+	return h.sendDeckCard(c, fiber.StatusOK, card)
+}
+
+// RemoveCard removes a card from a deck
+func (h *DecksHandler) RemoveCard(c fiber.Ctx) error {
+	card, err := h.findDeckCard(c)
+	if err != nil || card == nil {
+		return err
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Delete(card).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to remove card from deck", "database delete failed", err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Legality checks a deck against the rules of its format
+func (h *DecksHandler) Legality(c fiber.Ctx) error {
+	deck, err := h.findDeck(c)
+	if err != nil || deck == nil {
+		return err
+	}
+
+	result, err := h.decks.Legality(c.RequestCtx(), *deck)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check deck legality", "legality check failed", err)
+	}
+	return c.JSON(result)
+}
+
+// Coverage reports how much of a deck the inventory covers
+func (h *DecksHandler) Coverage(c fiber.Ctx) error {
+	deck, err := h.findDeck(c)
+	if err != nil || deck == nil {
+		return err
+	}
+
+	result, err := h.decks.Coverage(c.RequestCtx(), *deck)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to calculate deck coverage", "coverage calculation failed", err)
+	}
+	return c.JSON(result)
+}
+
+// findDeck loads the deck named by the id path param with its cards. When it
+// returns a nil deck the error response has already been written and err should
+// be returned.
+func (h *DecksHandler) findDeck(c fiber.Ctx) (*models.Deck, error) {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var deck models.Deck
+	if err := h.db.WithContext(c.RequestCtx()).
+		Preload("Cards", func(db *gorm.DB) *gorm.DB { return db.Order("zone ASC, id ASC") }).
+		First(&deck, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "deck not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch deck", "database query failed", err)
+	}
+	return &deck, nil
+}
+
+// findDeckCard loads the card named by the card_id path param within the deck named
+// by the id path param. When it returns a nil card the error response has already
+// been written and err should be returned.
+func (h *DecksHandler) findDeckCard(c fiber.Ctx) (*models.DeckCard, error) {
+	deckID := fiber.Params[int](c, "id")
+	cardID := fiber.Params[int](c, "card_id")
+	if deckID == 0 || cardID == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var card models.DeckCard
+	if err := h.db.WithContext(c.RequestCtx()).
+		Where("id = ? AND deck_id = ?", cardID, deckID).
+		First(&card).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "deck card not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch deck card", "database query failed", err)
+	}
+	return &card, nil
+}
+
+// saveDeck applies a request to a new or existing deck and stores it. When it
+// returns false the error response has already been written.
+func (h *DecksHandler) saveDeck(c fiber.Ctx, deck *models.Deck, req DeckRequest) (bool, error) {
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateRequired(req.Name, "name"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Name, 255, "name"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Description, 1000, "description"))
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	deck.Name = req.Name
+	deck.Description = req.Description
+	deck.Format = req.Format
+	deck.CommanderOracleID = req.CommanderOracleID
+	if err := deck.ValidateDeck(h.db); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if deck.CommanderOracleID != "" {
+		if ok, err := h.requireCard(c, deck.CommanderOracleID, "commander not found"); !ok {
+			return false, err
+		}
+	}
+
+	// Omit cards so saving doesn't rewrite the preloaded association
+	if err := h.db.WithContext(c.RequestCtx()).Omit("Cards").Save(deck).Error; err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save deck", "database save failed", err)
+	}
+	return true, nil
+}
+
+// requireCard checks that a card with the oracle ID is in the local card data.
+// When it returns false the error response has already been written.
+func (h *DecksHandler) requireCard(c fiber.Ctx, oracleID, notFound string) (bool, error) {
+	var count int64
+	if err := h.db.WithContext(c.RequestCtx()).Model(&models.Card{}).
+		Where("oracle_id = ?", oracleID).
+		Limit(1).
+		Count(&count).Error; err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card", "database query failed", err)
+	}
+	if count == 0 {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, notFound)
+	}
+	return true, nil
+}
+
+// sendDeck responds with a deck, naming its cards
+func (h *DecksHandler) sendDeck(c fiber.Ctx, status int, deck *models.Deck) error {
+	oracleIDs := make([]string, len(deck.Cards))
+	for i, card := range deck.Cards {
+		oracleIDs[i] = card.OracleID
+	}
+	names, err := h.decks.CardNames(c.RequestCtx(), oracleIDs)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card names", "card name query failed", err)
+	}
+	for i := range deck.Cards {
+		deck.Cards[i].Name = names[deck.Cards[i].OracleID]
+	}
+	return c.Status(status).JSON(deck)
+}
+
+// sendDeckCard responds with a deck card and its name
+func (h *DecksHandler) sendDeckCard(c fiber.Ctx, status int, card *models.DeckCard) error {
+	names, err := h.decks.CardNames(c.RequestCtx(), []string{card.OracleID})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card names", "card name query failed", err)
+	}
+	card.Name = names[card.OracleID]
+	return c.Status(status).JSON(card)
+}
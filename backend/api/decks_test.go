@@ -0,0 +1,246 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupDecksTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	handler := NewDecksHandler(db, services.NewDeckService(db))
+
+	app := fiber.New()
+	app.Get("/decks", handler.List)
+	app.Get("/decks/:id", handler.Get)
+	app.Post("/decks", handler.Create)
+	app.Put("/decks/:id", handler.Update)
+	app.Delete("/decks/:id", handler.Delete)
+	app.Get("/decks/:id/legality", handler.Legality)
+	app.Get("/decks/:id/coverage", handler.Coverage)
+	app.Post("/decks/:id/cards", handler.AddCard)
+	app.Put("/decks/:id/cards/:card_id", handler.UpdateCard)
+	app.Delete("/decks/:id/cards/:card_id", handler.RemoveCard)
+
+	return app, db
+}
+
+// createDeckCard creates a printing of a card with the given legalities
+func createDeckCard(t *testing.T, db *gorm.DB, oracleID, name, typeLine, legalities string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: "print-" + oracleID,
+		OracleID:   oracleID,
+		RawJSON: fmt.Sprintf(`{"name":%q,"type_line":%q,"color_identity":["R"],"legalities":%s}`,
+			name, typeLine, legalities),
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func TestDecksCreate(t *testing.T) {
+	app, db := setupDecksTestApp(t)
+	createDeckCard(t, db, "krenko", "Krenko, Mob Boss", "Legendary Creature — Goblin Warrior", `{"commander":"legal"}`)
+
+	tests := []struct {
+		name     string
+		body     DeckRequest
+		expected int
+	}{
+		{"constructed", DeckRequest{Name: "Burn", Format: models.DeckFormatModern}, fiber.StatusCreated},
+		{"commander", DeckRequest{Name: "Goblins", Format: models.DeckFormatCommander, CommanderOracleID: "krenko"}, fiber.StatusCreated},
+		{"missing name", DeckRequest{Format: models.DeckFormatModern}, fiber.StatusBadRequest},
+		{"unknown format", DeckRequest{Name: "Burn", Format: "extended"}, fiber.StatusBadRequest},
+		{"commander outside commander format", DeckRequest{Name: "Burn", Format: models.DeckFormatModern, CommanderOracleID: "krenko"}, fiber.StatusBadRequest},
+		{"unknown commander", DeckRequest{Name: "Goblins", Format: models.DeckFormatCommander, CommanderOracleID: "nobody"}, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/decks", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+
+	status, body := sendPredicateRequest(t, app, "GET", "/decks?format=commander", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var page utils.PaginatedResponse[models.Deck]
+	if err := json.Unmarshal([]byte(body), &page); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if page.TotalItems != 1 || page.Data[0].Name != "Goblins" {
+		t.Errorf("expected only the commander deck, got %+v", page)
+	}
+}
+
+func TestDecksCards(t *testing.T) {
+	app, db := setupDecksTestApp(t)
+	createDeckCard(t, db, "bolt", "Lightning Bolt", "Instant", `{"modern":"legal"}`)
+	deck := models.Deck{Name: "Burn", Format: models.DeckFormatModern}
+	db.Create(&deck)
+	cardsPath := fmt.Sprintf("/decks/%d/cards", deck.ID)
+
+	status, body := sendPredicateRequest(t, app, "POST", cardsPath, AddDeckCardRequest{OracleID: "bolt", Quantity: 3})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", status, body)
+	}
+	var card models.DeckCard
+	if err := json.Unmarshal([]byte(body), &card); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if card.Zone != models.DeckZoneMainboard || card.Name != "Lightning Bolt" {
+		t.Errorf("expected a named mainboard card, got %+v", card)
+	}
+
+	// Adding the same card to the same zone increases its quantity
+	status, body = sendPredicateRequest(t, app, "POST", cardsPath, AddDeckCardRequest{OracleID: "bolt"})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if err := json.Unmarshal([]byte(body), &card); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if card.Quantity != 4 {
+		t.Errorf("expected quantity 4, got %d", card.Quantity)
+	}
+
+	for _, tt := range []struct {
+		name string
+		body AddDeckCardRequest
+	}{
+		{"unknown card", AddDeckCardRequest{OracleID: "nothing"}},
+		{"unknown zone", AddDeckCardRequest{OracleID: "bolt", Zone: "maybeboard"}},
+		{"negative quantity", AddDeckCardRequest{OracleID: "bolt", Quantity: -1}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", cardsPath, tt.body)
+			if status != fiber.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", status, body)
+			}
+		})
+	}
+
+	status, body = sendPredicateRequest(t, app, "POST", cardsPath, AddDeckCardRequest{OracleID: "bolt", Zone: models.DeckZoneSideboard})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", status, body)
+	}
+	var sideboard models.DeckCard
+	if err := json.Unmarshal([]byte(body), &sideboard); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+
+	// Moving the sideboard copy into the mainboard collides with the existing entry
+	mainboard := models.DeckZoneMainboard
+	status, _ = sendPredicateRequest(t, app, "PUT", fmt.Sprintf("%s/%d", cardsPath, sideboard.ID), UpdateDeckCardRequest{Zone: &mainboard})
+	if status != fiber.StatusConflict {
+		t.Errorf("expected 409, got %d", status)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("%s/%d", cardsPath, sideboard.ID), nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	status, _ = sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/decks/999/cards/%d", card.ID), nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for a card in another deck, got %d", status)
+	}
+
+	status, body = sendPredicateRequest(t, app, "GET", fmt.Sprintf("/decks/%d", deck.ID), nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var got models.Deck
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if len(got.Cards) != 1 || got.Cards[0].Quantity != 4 || got.Cards[0].Name != "Lightning Bolt" {
+		t.Errorf("expected 4 named Lightning Bolts, got %+v", got.Cards)
+	}
+}
+
+func TestDecksLegalityAndCoverage(t *testing.T) {
+	app, db := setupDecksTestApp(t)
+	createDeckCard(t, db, "bolt", "Lightning Bolt", "Instant", `{"modern":"legal"}`)
+	deck := models.Deck{Name: "Burn", Format: models.DeckFormatModern}
+	db.Create(&deck)
+	db.Create(&models.DeckCard{DeckID: deck.ID, OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 4})
+	db.Create(&models.Inventory{ScryfallID: "print-bolt", OracleID: "bolt", Treatment: "nonfoil", Quantity: 3})
+
+	status, body := sendPredicateRequest(t, app, "GET", fmt.Sprintf("/decks/%d/legality", deck.ID), nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var legality services.DeckLegality
+	if err := json.Unmarshal([]byte(body), &legality); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if legality.Legal || len(legality.Issues) != 1 || legality.Issues[0].Message != "Mainboard has 4 cards; Modern requires at least 60" {
+		t.Errorf("expected only a deck size issue, got %+v", legality)
+	}
+
+	status, body = sendPredicateRequest(t, app, "GET", fmt.Sprintf("/decks/%d/coverage", deck.ID), nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var coverage services.DeckCoverage
+	if err := json.Unmarshal([]byte(body), &coverage); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if coverage.TotalOwned != 3 || coverage.TotalMissing != 1 || coverage.Complete {
+		t.Errorf("expected 3 of 4 owned, got %+v", coverage)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "GET", "/decks/999/legality", nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+}
+
+func TestDecksUpdateAndDelete(t *testing.T) {
+	app, db := setupDecksTestApp(t)
+	deck := models.Deck{Name: "Burn", Format: models.DeckFormatModern}
+	db.Create(&deck)
+	db.Create(&models.DeckCard{DeckID: deck.ID, OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 4})
+	path := fmt.Sprintf("/decks/%d", deck.ID)
+
+	status, body := sendPredicateRequest(t, app, "PUT", path, DeckRequest{Name: "Burn", Format: models.DeckFormatLegacy})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var updated models.Deck
+	if err := json.Unmarshal([]byte(body), &updated); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if updated.Format != models.DeckFormatLegacy || len(updated.Cards) != 1 {
+		t.Errorf("expected a legacy deck keeping its cards, got %+v", updated)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", path, nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	var remaining int64
+	db.Model(&models.DeckCard{}).Where("deck_id = ?", deck.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("expected deck cards deleted, %d remain", remaining)
+	}
+	status, _ = sendPredicateRequest(t, app, "DELETE", path, nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+}
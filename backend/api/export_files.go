@@ -0,0 +1,194 @@
+package api
+
+import (
+	"backend/utils"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// exportDirName is the DATA_DIR subdirectory rendered downloads are kept in
+	exportDirName = "exports"
+	// encryptedExtension is appended to the names of encrypted renders
+	encryptedExtension = ".enc"
+)
+
+// ExportFiles keeps rendered downloads on disk between requests, so repeat and
+// resumed downloads are served from the same file with byte range support
+type ExportFiles struct {
+	dir string
+	// passphrase encrypts every render; empty disables encryption
+	passphrase string
+}
+
+// NewExportFiles creates the download store under dataDir. Renders left by an earlier
+// run are removed: they may have been encrypted with a passphrase that has since changed.
+// When passphrase is non-empty, renders are encrypted at rest with it.
+func NewExportFiles(dataDir, passphrase string) *ExportFiles {
+	dir := filepath.Join(dataDir, exportDirName)
+	if err := os.RemoveAll(dir); err != nil {
+		slog.Warn("failed to clear rendered exports", "component", "data", "path", dir, "error", err)
+	}
+	return &ExportFiles{dir: dir, passphrase: passphrase}
+}
+
+// path names the kind's render of a fingerprint
+func (f *ExportFiles) path(kind, fingerprint, extension string) string {
+	if f.passphrase != "" {
+		extension += encryptedExtension
+	}
+	return filepath.Join(f.dir, kind+"-"+fingerprint+extension)
+}
+
+// exportFingerprint identifies export content; it names the rendered file
+func exportFingerprint(v any) (string, error) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+// file returns the path of the kind's rendered download for a fingerprint, calling
+// render only when no file exists for it yet. Older renders of the kind are removed
+// so the directory holds at most one per kind.
+func (f *ExportFiles) file(kind, fingerprint, extension string, render func(w io.Writer) error) (string, error) {
+	path := f.path(kind, fingerprint, extension)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	tmp, _, err := f.renderTemp(render)
+	if err != nil {
+		return "", err
+	}
+	return f.store(tmp, path, kind)
+}
+
+// renderFile renders a download and returns its path, fingerprinted by its content. An
+// identical earlier render is served instead, so a resumed download gets the same bytes.
+func (f *ExportFiles) renderFile(kind, extension string, render func(w io.Writer) error) (string, error) {
+	tmp, sum, err := f.renderTemp(render)
+	if err != nil {
+		return "", err
+	}
+
+	path := f.path(kind, hex.EncodeToString(sum.Sum(nil)[:16]), extension)
+	if _, err := os.Stat(path); err == nil {
+		_ = os.Remove(tmp)
+		return path, nil
+	}
+	return f.store(tmp, path, kind)
+}
+
+// renderTemp writes a render to a temp file, so a concurrent download never sees a
+// partial one, hashing the content on the way. The hash is of the plaintext: an
+// encrypted render differs every time.
+func (f *ExportFiles) renderTemp(render func(w io.Writer) error) (string, hash.Hash, error) {
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("create export directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(f.dir, ".tmp-export-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("create export file: %w", err)
+	}
+
+	sum := sha256.New()
+	err = render(io.MultiWriter(tmp, sum))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && f.passphrase != "" {
+		err = encryptFile(tmp.Name(), f.passphrase)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("write export file: %w", err)
+	}
+	return tmp.Name(), sum, nil
+}
+
+// encryptFile replaces a file's content with its encryption under passphrase
+func encryptFile(path, passphrase string) error {
+	plaintext, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.EncryptWithPassphrase(plaintext, passphrase)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+	return os.WriteFile(path, encrypted, 0o644)
+}
+
+// store moves a finished render into place and removes the kind's earlier renders
+func (f *ExportFiles) store(tmp, path, kind string) (string, error) {
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("store export file: %w", err)
+	}
+	f.removeStale(path, kind)
+	return path, nil
+}
+
+// removeStale deletes earlier renders of a kind, logging rather than failing
+func (f *ExportFiles) removeStale(current, kind string) {
+	matches, err := filepath.Glob(filepath.Join(f.dir, kind+"-*"))
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		if match == current {
+			continue
+		}
+		if err := os.Remove(match); err != nil && !os.IsNotExist(err) {
+			slog.Warn("failed to remove stale export", "component", "data", "path", match, "error", err)
+		}
+	}
+}
+
+// sendExportFile serves a file as an attachment with byte range support, so clients
+// can resume an interrupted download with Range and If-Range. An encrypted file is
+// sent as binary data with .enc added to its name. The ETag changes whenever the file
+// is rewritten, even with the same content: encrypted renders of the same data
+// differ byte for byte.
+func sendExportFile(c fiber.Ctx, path, filename, contentType string) error {
+	if strings.HasSuffix(path, encryptedExtension) && !strings.HasSuffix(filename, encryptedExtension) {
+		filename += encryptedExtension
+		contentType = fiber.MIMEOctetStream
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	etag := `"` + filepath.Base(path) + "-" + strconv.FormatInt(stat.ModTime().UnixNano(), 36) + `"`
+
+	// A range only applies to the representation the client started downloading;
+	// if the file changed since, fall back to sending the whole file
+	if ifRange := c.Get(fiber.HeaderIfRange); ifRange != "" && ifRange != etag {
+		c.Request().Header.Del(fiber.HeaderRange)
+	}
+
+	if err := c.SendFile(path, fiber.SendFile{ByteRange: true}); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderAcceptRanges, "bytes")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	return nil
+}
@@ -1,32 +1,35 @@
 package api
 
 import (
+	"backend/models"
+	"backend/utils"
+	"context"
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
+// MinReadyDiskFreeBytes is the free space DATA_DIR needs for the server to report ready
+const MinReadyDiskFreeBytes = 100 << 20
+
 // HealthHandler handles health check endpoints
 type HealthHandler struct {
 	db      *gorm.DB
 	version string
+	dataDir string
 }
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler(db *gorm.DB, version string) *HealthHandler {
-	return &HealthHandler{db: db, version: version}
+func NewHealthHandler(db *gorm.DB, version, dataDir string) *HealthHandler {
+	return &HealthHandler{db: db, version: version, dataDir: dataDir}
 }
 
 // Check returns health status including database connectivity
 func (h *HealthHandler) Check(c fiber.Ctx) error {
-	dbStatus := "connected"
+	dbStatus := h.pingDatabase(c.RequestCtx())
 	httpStatus := fiber.StatusOK
-
-	sqlDB, err := h.db.DB()
-	if err != nil {
-		dbStatus = "disconnected"
-		httpStatus = fiber.StatusServiceUnavailable
-	} else if err := sqlDB.Ping(); err != nil {
-		dbStatus = "unreachable"
+	if dbStatus != "connected" {
 		httpStatus = fiber.StatusServiceUnavailable
 	}
 
@@ -43,3 +46,159 @@ func (h *HealthHandler) Check(c fiber.Ctx) error {
 		},
 	})
 }
+
+// Live reports that the process is up and serving requests. It touches nothing else,
+// so a slow database or full disk never gets the server restarted.
+func (h *HealthHandler) Live(c fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status":  "OK",
+		"version": h.version,
+	})
+}
+
+// ReadinessResponse is the body of GET /health/ready
+// tygo:export
+type ReadinessResponse struct {
+	Status  string          `json:"status"` // "ready" or "not_ready"
+	Version string          `json:"version"`
+	Checks  ReadinessChecks `json:"checks"`
+}
+
+// ReadinessChecks are the individual readiness checks. Only the database and disk
+// checks decide readiness; the others are reported for monitoring.
+// tygo:export
+type ReadinessChecks struct {
+	Database   DatabaseCheck   `json:"database"`
+	Disk       DiskCheck       `json:"disk"`
+	BulkImport BulkImportCheck `json:"bulk_import"`
+	Jobs       JobsCheck       `json:"jobs"`
+}
+
+// DatabaseCheck reports database connectivity
+// tygo:export
+type DatabaseCheck struct {
+	Status    string `json:"status"` // "connected", "disconnected" or "unreachable"
+	LatencyMs int64  `json:"latency_ms"`
+}
+
+// DiskCheck reports free space on the filesystem holding DATA_DIR
+// tygo:export
+type DiskCheck struct {
+	Status     string `json:"status"` // "ok", "low" or "unknown"
+	Path       string `json:"path"`
+	FreeBytes  uint64 `json:"free_bytes"`
+	TotalBytes uint64 `json:"total_bytes"`
+	Error      string `json:"error,omitempty"`
+}
+
+// BulkImportCheck reports the last successful bulk data import
+// tygo:export
+type BulkImportCheck struct {
+	LastSuccessAt *time.Time `json:"last_success_at"` // Null if no import has succeeded
+	AgeSeconds    *int64     `json:"age_seconds"`
+}
+
+// JobsCheck counts background jobs that haven't finished
+// tygo:export
+type JobsCheck struct {
+	Pending    int64 `json:"pending"`
+	InProgress int64 `json:"in_progress"`
+}
+
+// Ready reports whether the server can serve traffic: the database answers and
+// DATA_DIR has at least MinReadyDiskFreeBytes free. It returns 503 otherwise.
+func (h *HealthHandler) Ready(c fiber.Ctx) error {
+	ctx := c.RequestCtx()
+
+	response := ReadinessResponse{Status: "ready", Version: h.version}
+
+	started := time.Now()
+	response.Checks.Database.Status = h.pingDatabase(ctx)
+	response.Checks.Database.LatencyMs = time.Since(started).Milliseconds()
+
+	response.Checks.Disk = h.checkDisk()
+
+	// The rest only reads the database, so skip it when that's down
+	if response.Checks.Database.Status == "connected" {
+		response.Checks.BulkImport = h.checkBulkImport(ctx)
+		response.Checks.Jobs = h.countJobs(ctx)
+	}
+
+	if response.Checks.Database.Status != "connected" || response.Checks.Disk.Status == "low" {
+		response.Status = "not_ready"
+		return c.Status(fiber.StatusServiceUnavailable).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// pingDatabase returns "connected", or why the database can't be reached
+func (h *HealthHandler) pingDatabase(ctx context.Context) string {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return "disconnected"
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return "unreachable"
+	}
+	return "connected"
+}
+
+// checkDisk measures free space in DATA_DIR. A platform where it can't be measured
+// reports "unknown" rather than failing readiness.
+func (h *HealthHandler) checkDisk() DiskCheck {
+	check := DiskCheck{Status: "ok", Path: h.dataDir}
+	free, total, err := utils.DiskSpace(h.dataDir)
+	if err != nil {
+		check.Status = "unknown"
+		check.Error = err.Error()
+		return check
+	}
+	check.FreeBytes, check.TotalBytes = free, total
+	if free < MinReadyDiskFreeBytes {
+		check.Status = "low"
+	}
+	return check
+}
+
+// checkBulkImport finds when the last bulk data import completed, from job history or,
+// once job cleanup has removed that job, the bulk_data_last_update setting when the
+// last import succeeded
+func (h *HealthHandler) checkBulkImport(ctx context.Context) BulkImportCheck {
+	var check BulkImportCheck
+
+	var job models.Job
+	err := h.db.WithContext(ctx).
+		Where("type = ? AND status = ? AND completed_at IS NOT NULL", models.JobTypeBulkDataImport, models.JobStatusCompleted).
+		Order("completed_at DESC").
+		Limit(1).
+		Find(&job).Error
+	if err == nil && job.ID != 0 {
+		check.LastSuccessAt = job.CompletedAt
+	} else {
+		var settings []models.Setting
+		h.db.WithContext(ctx).Where("key IN ?", []string{"bulk_data_last_update", "bulk_data_last_update_status"}).Find(&settings)
+		values := make(map[string]string, len(settings))
+		for _, setting := range settings {
+			values[setting.Key] = setting.Value
+		}
+		if values["bulk_data_last_update_status"] == "success" {
+			if at, err := time.Parse(time.RFC3339, values["bulk_data_last_update"]); err == nil {
+				check.LastSuccessAt = &at
+			}
+		}
+	}
+
+	if check.LastSuccessAt != nil {
+		age := int64(time.Since(*check.LastSuccessAt).Seconds())
+		check.AgeSeconds = &age
+	}
+	return check
+}
+
+// countJobs counts pending and in-progress jobs
+func (h *HealthHandler) countJobs(ctx context.Context) JobsCheck {
+	var check JobsCheck
+	h.db.WithContext(ctx).Model(&models.Job{}).Where("status = ?", models.JobStatusPending).Count(&check.Pending)
+	h.db.WithContext(ctx).Model(&models.Job{}).Where("status = ?", models.JobStatusInProgress).Count(&check.InProgress)
+	return check
+}
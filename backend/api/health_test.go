@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/driver/sqlite"
@@ -18,7 +19,7 @@ func setupHealthTest(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to setup test db: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+	if err := db.AutoMigrate(&models.Setting{}, &models.Job{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 	return db
@@ -26,7 +27,7 @@ func setupHealthTest(t *testing.T) *gorm.DB {
 
 func TestHealth_Success(t *testing.T) {
 	db := setupHealthTest(t)
-	handler := NewHealthHandler(db, "test")
+	handler := NewHealthHandler(db, "test", t.TempDir())
 
 	app := fiber.New()
 	app.Get("/health", handler.Check)
@@ -71,3 +72,111 @@ func TestHealth_Success(t *testing.T) {
 		t.Errorf("expected database status 'connected', got '%s'", dbStatus)
 	}
 }
+
+func TestHealth_Live(t *testing.T) {
+	db := setupHealthTest(t)
+	handler := NewHealthHandler(db, "test", t.TempDir())
+
+	app := fiber.New()
+	app.Get("/health/live", handler.Live)
+
+	// Liveness must not depend on the database
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/live", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestHealth_Ready(t *testing.T) {
+	db := setupHealthTest(t)
+	handler := NewHealthHandler(db, "test", t.TempDir())
+
+	app := fiber.New()
+	app.Get("/health/ready", handler.Ready)
+
+	completedAt := time.Now().Add(-2 * time.Hour)
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusCompleted, CompletedAt: &completedAt})
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusPending})
+	db.Create(&models.Job{Type: models.JobTypeReindex, Status: models.JobStatusInProgress})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	var result ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Status != "ready" || result.Checks.Database.Status != "connected" {
+		t.Errorf("expected ready with a connected database, got %+v", result)
+	}
+	if result.Checks.Disk.Status != "ok" || result.Checks.Disk.TotalBytes == 0 {
+		t.Errorf("expected disk space to be measured, got %+v", result.Checks.Disk)
+	}
+	if result.Checks.Jobs.Pending != 1 || result.Checks.Jobs.InProgress != 1 {
+		t.Errorf("expected 1 pending and 1 in-progress job, got %+v", result.Checks.Jobs)
+	}
+	if age := result.Checks.BulkImport.AgeSeconds; age == nil || *age < 7190 || *age > 7300 {
+		t.Errorf("expected the import to be about 2 hours old, got %v", age)
+	}
+}
+
+func TestHealth_Ready_BulkImportFromSettings(t *testing.T) {
+	db := setupHealthTest(t)
+	handler := NewHealthHandler(db, "test", t.TempDir())
+
+	app := fiber.New()
+	app.Get("/health/ready", handler.Ready)
+
+	// Job cleanup removed the import's job, but the settings still record its success
+	db.Create(&models.Setting{Key: "bulk_data_last_update", Value: time.Now().Add(-time.Hour).Format(time.RFC3339)})
+	db.Create(&models.Setting{Key: "bulk_data_last_update_status", Value: "success"})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	var result ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Checks.BulkImport.LastSuccessAt == nil {
+		t.Error("expected the last success to come from settings")
+	}
+}
+
+func TestHealth_Ready_DatabaseDown(t *testing.T) {
+	db := setupHealthTest(t)
+	handler := NewHealthHandler(db, "test", t.TempDir())
+
+	app := fiber.New()
+	app.Get("/health/ready", handler.Ready)
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/health/ready", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", fiber.StatusServiceUnavailable, resp.StatusCode)
+	}
+	var result ReadinessResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Status != "not_ready" || result.Checks.Database.Status != "unreachable" {
+		t.Errorf("expected not_ready with an unreachable database, got %+v", result)
+	}
+}
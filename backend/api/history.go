@@ -0,0 +1,51 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// HistoryHandler handles inventory history endpoints
+type HistoryHandler struct {
+	events *services.InventoryEventService
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(events *services.InventoryEventService) *HistoryHandler {
+	return &HistoryHandler{events: events}
+}
+
+// List returns inventory events across the collection with pagination, newest first,
+// optionally filtered by event_type
+func (h *HistoryHandler) List(c fiber.Ctx) error {
+	eventType := models.InventoryEventType(c.Query("event_type"))
+	if eventType != "" && !eventType.Valid() {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid event_type")
+	}
+	return h.list(c, services.InventoryEventFilter{EventType: eventType})
+}
+
+// ForInventory returns the events for one inventory row with pagination, newest first.
+// History is kept after the row is deleted, so an unknown ID returns an empty page.
+func (h *HistoryHandler) ForInventory(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+	return h.list(c, services.InventoryEventFilter{InventoryID: uint(id)})
+}
+
+func (h *HistoryHandler) list(c fiber.Ctx, filter services.InventoryEventFilter) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	events, total, err := h.events.List(c.RequestCtx(), params.Page, params.PageSize, filter)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch inventory history", "inventory history query failed", err)
+	}
+
+	return utils.SendPaginated(c, events, params.Page, params.PageSize, total)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupHistoryTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	app, db := setupFullInventoryTestApp(t)
+	handler := NewHistoryHandler(services.NewInventoryEventService(db))
+	app.Get("/history", handler.List)
+	app.Get("/inventory/:id/history", handler.ForInventory)
+
+	return app, db
+}
+
+func fetchHistory(t *testing.T, app *fiber.App, url string) utils.PaginatedResponse[models.InventoryEvent] {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, url, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var page utils.PaginatedResponse[models.InventoryEvent]
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return page
+}
+
+func sendInventoryRequest(t *testing.T, app *fiber.App, method, url, body string) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		t.Fatalf("%s %s returned %d", method, url, resp.StatusCode)
+	}
+}
+
+func TestHistory_InventoryLifecycle(t *testing.T) {
+	app, db := setupHistoryTestApp(t)
+
+	binder := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	box := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&binder)
+	db.Create(&box)
+
+	sendInventoryRequest(t, app, http.MethodPost, "/inventory",
+		fmt.Sprintf(`{"scryfall_id": "card-1", "oracle_id": "oracle-1", "quantity": 2, "storage_location_id": %d}`, binder.ID))
+	var item models.Inventory
+	db.First(&item)
+
+	sendInventoryRequest(t, app, http.MethodPut, fmt.Sprintf("/inventory/%d", item.ID),
+		fmt.Sprintf(`{"quantity": 3, "storage_location_id": %d}`, box.ID))
+	// Editing other fields is not a history event
+	sendInventoryRequest(t, app, http.MethodPut, fmt.Sprintf("/inventory/%d", item.ID), `{"notes": "signed"}`)
+	sendInventoryRequest(t, app, http.MethodDelete, fmt.Sprintf("/inventory/%d", item.ID), "")
+
+	// History outlives the deleted row
+	page := fetchHistory(t, app, fmt.Sprintf("/inventory/%d/history", item.ID))
+	if page.TotalItems != 4 {
+		t.Fatalf("expected 4 events, got %d", page.TotalItems)
+	}
+
+	expected := []models.InventoryEventType{
+		models.InventoryEventDeleted,
+		models.InventoryEventMoved,
+		models.InventoryEventQuantityChanged,
+		models.InventoryEventCreated,
+	}
+	for i, eventType := range expected {
+		if page.Data[i].EventType != eventType {
+			t.Errorf("event %d: expected %s, got %s", i, eventType, page.Data[i].EventType)
+		}
+	}
+
+	moved := page.Data[1]
+	if *moved.OldStorageLocationID != binder.ID || *moved.NewStorageLocationID != box.ID {
+		t.Errorf("expected a move from %d to %d, got %+v", binder.ID, box.ID, moved)
+	}
+	changed := page.Data[2]
+	if *changed.OldQuantity != 2 || *changed.NewQuantity != 3 {
+		t.Errorf("expected quantity 2 -> 3, got %+v", changed)
+	}
+	if deleted := page.Data[0]; *deleted.OldQuantity != 3 || deleted.NewQuantity != nil {
+		t.Errorf("expected deletion of 3 copies, got %+v", deleted)
+	}
+}
+
+func TestHistory_BatchOperations(t *testing.T) {
+	app, db := setupHistoryTestApp(t)
+
+	box := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&box)
+	first := createTestInventoryItem(t, db, "card-1", 1, nil)
+	second := createTestInventoryItem(t, db, "card-2", 1, &box.ID)
+
+	// Only the row that actually changes location is recorded as moved
+	sendInventoryRequest(t, app, http.MethodPost, "/inventory/batch/move",
+		fmt.Sprintf(`{"ids": [%d, %d], "storage_location_id": %d}`, first.ID, second.ID, box.ID))
+	sendInventoryRequest(t, app, http.MethodDelete, "/inventory/batch",
+		fmt.Sprintf(`{"ids": [%d, %d]}`, first.ID, second.ID))
+
+	if page := fetchHistory(t, app, "/history?event_type=moved"); page.TotalItems != 1 || page.Data[0].InventoryID != first.ID {
+		t.Errorf("expected one move for item %d, got %+v", first.ID, page.Data)
+	}
+	if page := fetchHistory(t, app, "/history?event_type=deleted"); page.TotalItems != 2 {
+		t.Errorf("expected 2 deletions, got %d", page.TotalItems)
+	}
+	if page := fetchHistory(t, app, "/history?page_size=1"); page.TotalItems != 3 || len(page.Data) != 1 || page.TotalPages != 3 {
+		t.Errorf("expected 1 of 3 events per page, got %d of %d", len(page.Data), page.TotalItems)
+	}
+}
+
+func TestHistory_InvalidParams(t *testing.T) {
+	app, _ := setupHistoryTestApp(t)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"Unknown event type", "/history?event_type=renamed"},
+		{"Invalid inventory id", "/inventory/abc/history"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ImportDigestHandler serves the digests built after bulk data imports
+type ImportDigestHandler struct {
+	service *services.ImportDigestService
+}
+
+// NewImportDigestHandler creates a new import digest handler
+func NewImportDigestHandler(service *services.ImportDigestService) *ImportDigestHandler {
+	return &ImportDigestHandler{service: service}
+}
+
+// Get returns the digest of what a bulk import job changed for owned cards
+func (h *ImportDigestHandler) Get(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	report, err := h.service.Get(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "No digest for this job")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to retrieve digest", "digest query failed", err)
+	}
+
+	return c.JSON(report)
+}
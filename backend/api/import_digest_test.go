@@ -0,0 +1,92 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupImportDigestTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.ImportDigest{}, &models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewImportDigestHandler(services.NewImportDigestService(db, services.NewNotificationService(db)))
+
+	app := fiber.New()
+	app.Get("/jobs/:id/digest", handler.Get)
+
+	return app, db
+}
+
+func TestImportDigest_Get(t *testing.T) {
+	app, db := setupImportDigestTestApp(t)
+
+	content := `{"job_id":4,"currency":"usd","price_threshold_percent":20,"new_printings_count":1,"price_movers_count":0,` +
+		`"new_printings":[{"scryfall_id":"bolt-2x2","oracle_id":"oracle-bolt","name":"Lightning Bolt","set_code":"2x2"}],` +
+		`"price_movers":[],"legality_changes":[]}`
+	if err := db.Create(&models.ImportDigest{JobID: 4, NewPrintings: 1, Content: content}).Error; err != nil {
+		t.Fatalf("failed to create digest: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/jobs/4/digest", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	var report services.ImportDigestReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.JobID != 4 || len(report.NewPrintings) != 1 || report.NewPrintings[0].SetCode != "2x2" {
+		t.Errorf("unexpected digest: %+v", report)
+	}
+	if report.CreatedAt.IsZero() {
+		t.Error("expected created_at from the stored digest")
+	}
+}
+
+func TestImportDigest_GetErrors(t *testing.T) {
+	app, _ := setupImportDigestTestApp(t)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Invalid id", "/jobs/abc/digest", fiber.StatusBadRequest},
+		{"No digest", "/jobs/9/digest", fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.url, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package api
+
+import (
+	"backend/models"
+	"backend/realtime"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// IntakeSessionsHandler handles intake session endpoints
+type IntakeSessionsHandler struct {
+	db      *gorm.DB
+	service *services.IntakeSessionService
+	hub     *realtime.Hub
+}
+
+// NewIntakeSessionsHandler creates a new intake sessions handler
+func NewIntakeSessionsHandler(db *gorm.DB, service *services.IntakeSessionService, hub *realtime.Hub) *IntakeSessionsHandler {
+	return &IntakeSessionsHandler{db: db, service: service, hub: hub}
+}
+
+// IntakeSessionResponse is an intake session with its staged items and their totals
+// tygo:export
+type IntakeSessionResponse struct {
+	models.IntakeSession `tstype:",extends"`
+	Summary              services.IntakeSessionSummary `json:"summary"`
+}
+
+// CreateIntakeSessionRequest represents the request body for opening an intake session
+// tygo:export
+type CreateIntakeSessionRequest struct {
+	Name              string `json:"name,omitempty"`
+	StorageLocationID *uint  `json:"storage_location_id,omitempty"` // If nil, sorting rules place cards on commit
+}
+
+// AddIntakeItemsRequest represents the request body for staging cards in an intake session
+// tygo:export
+type AddIntakeItemsRequest struct {
+	Entries []services.BatchAddEntry `json:"entries"`
+}
+
+// AddIntakeItemsResponse represents the per-entry outcome of staging cards, and the session after it
+// tygo:export
+type AddIntakeItemsResponse struct {
+	Added   int                       `json:"added"`
+	Failed  int                       `json:"failed"`
+	Results []services.BatchAddResult `json:"results"`
+	Session IntakeSessionResponse     `json:"session"`
+}
+
+// List returns intake sessions, newest first, with pagination and an optional status filter
+func (h *IntakeSessionsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	var status *models.IntakeSessionStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := models.IntakeSessionStatus(statusStr)
+		if !s.Valid() {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "status must be one of: open, committed, aborted")
+		}
+		status = &s
+	}
+
+	sessions, total, err := h.service.List(c.RequestCtx(), params.Page, params.PageSize, status)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch intake sessions", "intake session list query failed", err)
+	}
+
+	return utils.SendPaginated(c, sessions, params.Page, params.PageSize, total)
+}
+
+// Get returns an intake session with its items and summary
+func (h *IntakeSessionsHandler) Get(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	session, err := h.service.Get(c.RequestCtx(), uint(id))
+	if err != nil {
+		return h.sessionError(c, err, "Failed to fetch intake session", "intake session query failed")
+	}
+	return h.respond(c, fiber.StatusOK, session)
+}
+
+// Create opens an intake session
+func (h *IntakeSessionsHandler) Create(c fiber.Ctx) error {
+	var req CreateIntakeSessionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if err := utils.ValidateMaxLength(req.Name, models.MaxIntakeSessionNameLength, "name"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if req.StorageLocationID != nil {
+		var location models.StorageLocation
+		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to validate storage location", "storage location lookup failed", err)
+		}
+	}
+
+	session := models.IntakeSession{Name: req.Name, StorageLocationID: req.StorageLocationID}
+	if err := h.service.Create(c.RequestCtx(), &session); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create intake session", "intake session insert failed", err)
+	}
+	return h.respond(c, fiber.StatusCreated, &session)
+}
+
+// AddItems stages cards in an open session, identified as for POST /inventory/batch/add
+func (h *IntakeSessionsHandler) AddItems(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req AddIntakeItemsRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if len(req.Entries) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "entries array is required")
+	}
+
+	results, err := h.service.AddItems(c.RequestCtx(), uint(id), req.Entries)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyBatchAddEntries) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		return h.sessionError(c, err, "Failed to add intake items", "intake item insert failed")
+	}
+
+	session, err := h.service.Get(c.RequestCtx(), uint(id))
+	if err != nil {
+		return h.sessionError(c, err, "Failed to reload intake session", "intake session query failed")
+	}
+	summary, err := h.service.Summarize(c.RequestCtx(), session)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to summarize intake session", "intake session summary failed", err)
+	}
+
+	response := AddIntakeItemsResponse{
+		Results: results,
+		Session: IntakeSessionResponse{IntakeSession: *session, Summary: summary},
+	}
+	for _, result := range results {
+		if result.Status == services.BatchAddStatusAdded {
+			response.Added++
+		} else {
+			response.Failed++
+		}
+	}
+	return c.JSON(response)
+}
+
+// RemoveItem unstages an item from an open session
+func (h *IntakeSessionsHandler) RemoveItem(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	itemID := fiber.Params[int](c, "item_id")
+	if id == 0 || itemID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.service.RemoveItem(c.RequestCtx(), uint(id), uint(itemID)); err != nil {
+		return h.sessionError(c, err, "Failed to remove intake item", "intake item delete failed")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Commit writes the session's staged items to inventory and closes it
+func (h *IntakeSessionsHandler) Commit(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	session, err := h.service.Commit(c.RequestCtx(), uint(id))
+	if err != nil {
+		return h.sessionError(c, err, "Failed to commit intake session", "intake session commit failed")
+	}
+
+	if len(session.Items) > 0 {
+		change := realtime.InventoryChange{}
+		for _, item := range session.Items {
+			change.IDs = append(change.IDs, *item.InventoryID)
+		}
+		h.hub.Publish(realtime.EventInventoryCreated, change)
+	}
+	return h.respond(c, fiber.StatusOK, session)
+}
+
+// Abort closes the session without writing its items to inventory
+func (h *IntakeSessionsHandler) Abort(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	session, err := h.service.Abort(c.RequestCtx(), uint(id))
+	if err != nil {
+		return h.sessionError(c, err, "Failed to abort intake session", "intake session abort failed")
+	}
+	return h.respond(c, fiber.StatusOK, session)
+}
+
+// respond sends a session with its summary
+func (h *IntakeSessionsHandler) respond(c fiber.Ctx, status int, session *models.IntakeSession) error {
+	summary, err := h.service.Summarize(c.RequestCtx(), session)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to summarize intake session", "intake session summary failed", err)
+	}
+	return c.Status(status).JSON(IntakeSessionResponse{IntakeSession: *session, Summary: summary})
+}
+
+// sessionError maps intake session service errors to responses
+func (h *IntakeSessionsHandler) sessionError(c fiber.Ctx, err error, userMsg, logMsg string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.ReturnError(c, fiber.StatusNotFound, "intake session or item not found")
+	case errors.Is(err, services.ErrIntakeSessionClosed):
+		return utils.ReturnError(c, fiber.StatusConflict, fmt.Sprintf("%s: session is no longer open", userMsg))
+	}
+	return utils.LogAndReturnError(c, fiber.StatusInternalServerError, userMsg, logMsg, err)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupIntakeSessionsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"id":"bolt-m10","name":"Lightning Bolt","set":"m10","collector_number":"146","prices":{"usd":"1.50"}}`},
+		{ScryfallID: "ring-c21", OracleID: "oracle-ring", RawJSON: `{"id":"ring-c21","name":"Sol Ring","set":"c21","collector_number":"263","prices":{"usd":"2.00"}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	handler := NewIntakeSessionsHandler(db, services.NewIntakeSessionService(db), nil)
+
+	app := fiber.New()
+	app.Get("/intake-sessions", handler.List)
+	app.Post("/intake-sessions", handler.Create)
+	app.Get("/intake-sessions/:id", handler.Get)
+	app.Post("/intake-sessions/:id/items", handler.AddItems)
+	app.Delete("/intake-sessions/:id/items/:item_id", handler.RemoveItem)
+	app.Post("/intake-sessions/:id/commit", handler.Commit)
+	app.Post("/intake-sessions/:id/abort", handler.Abort)
+
+	return app, db
+}
+
+func intakeRequest(t *testing.T, app *fiber.App, method, path string, body any, out any) int {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		reader = bytes.NewReader(payload)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if out != nil && resp.StatusCode < 300 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestIntakeSessions_Lifecycle(t *testing.T) {
+	app, db := setupIntakeSessionsTestApp(t)
+
+	location := models.StorageLocation{Name: "Intake Box", StorageType: models.Box}
+	db.Create(&location)
+
+	var session IntakeSessionResponse
+	status := intakeRequest(t, app, "POST", "/intake-sessions", CreateIntakeSessionRequest{Name: "Box of bulk", StorageLocationID: &location.ID}, &session)
+	if status != fiber.StatusCreated || session.Status != models.IntakeSessionOpen || session.Name != "Box of bulk" {
+		t.Fatalf("expected an open session, got %d %+v", status, session)
+	}
+	base := fmt.Sprintf("/intake-sessions/%d", session.ID)
+
+	var added AddIntakeItemsResponse
+	status = intakeRequest(t, app, "POST", base+"/items", AddIntakeItemsRequest{Entries: []services.BatchAddEntry{
+		{ScryfallID: "bolt-m10", Quantity: 4},
+		{SetCode: "c21", CollectorNumber: "263"},
+		{Name: "Black Lotus"},
+	}}, &added)
+	if status != fiber.StatusOK || added.Added != 2 || added.Failed != 1 {
+		t.Fatalf("expected 2 staged and 1 failed, got %d %+v", status, added)
+	}
+	if added.Session.Summary.Cards != 5 || added.Session.Summary.TotalValue != 8.0 || len(added.Session.Items) != 2 {
+		t.Errorf("unexpected session after staging: %+v", added.Session)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected staged cards to stay out of inventory, got %d rows", count)
+	}
+
+	if status := intakeRequest(t, app, "DELETE", fmt.Sprintf("%s/items/%d", base, added.Results[1].IntakeItemID), nil, nil); status != fiber.StatusNoContent {
+		t.Errorf("expected status %d removing an item, got %d", fiber.StatusNoContent, status)
+	}
+
+	var committed IntakeSessionResponse
+	status = intakeRequest(t, app, "POST", base+"/commit", nil, &committed)
+	if status != fiber.StatusOK || committed.Status != models.IntakeSessionCommitted {
+		t.Fatalf("expected a committed session, got %d %+v", status, committed)
+	}
+	if len(committed.Summary.Locations) != 1 || committed.Summary.Locations[0].Name != "Intake Box" || committed.Summary.Locations[0].Cards != 4 {
+		t.Errorf("expected 4 cards in the intake box, got %+v", committed.Summary.Locations)
+	}
+
+	var item models.Inventory
+	if err := db.First(&item).Error; err != nil {
+		t.Fatalf("expected an inventory row: %v", err)
+	}
+	if item.ScryfallID != "bolt-m10" || item.Quantity != 4 || item.StorageLocationID == nil || *item.StorageLocationID != location.ID {
+		t.Errorf("unexpected inventory row: %+v", item)
+	}
+
+	// A closed session can't change
+	if status := intakeRequest(t, app, "POST", base+"/commit", nil, nil); status != fiber.StatusConflict {
+		t.Errorf("expected status %d committing twice, got %d", fiber.StatusConflict, status)
+	}
+	if status := intakeRequest(t, app, "POST", base+"/items", AddIntakeItemsRequest{Entries: []services.BatchAddEntry{{Name: "Sol Ring"}}}, nil); status != fiber.StatusConflict {
+		t.Errorf("expected status %d adding to a committed session, got %d", fiber.StatusConflict, status)
+	}
+}
+
+func TestIntakeSessions_Abort(t *testing.T) {
+	app, db := setupIntakeSessionsTestApp(t)
+
+	var session IntakeSessionResponse
+	intakeRequest(t, app, "POST", "/intake-sessions", CreateIntakeSessionRequest{}, &session)
+	base := fmt.Sprintf("/intake-sessions/%d", session.ID)
+	intakeRequest(t, app, "POST", base+"/items", AddIntakeItemsRequest{Entries: []services.BatchAddEntry{{Name: "Sol Ring"}}}, nil)
+
+	var aborted IntakeSessionResponse
+	if status := intakeRequest(t, app, "POST", base+"/abort", nil, &aborted); status != fiber.StatusOK || aborted.Status != models.IntakeSessionAborted {
+		t.Fatalf("expected an aborted session, got %d %+v", status, aborted)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected an aborted session to leave inventory untouched, got %d rows", count)
+	}
+
+	var fetched IntakeSessionResponse
+	if status := intakeRequest(t, app, "GET", base, nil, &fetched); status != fiber.StatusOK || fetched.Summary.Items != 1 {
+		t.Errorf("expected the aborted session with its staged item, got %d %+v", status, fetched)
+	}
+}
+
+func TestIntakeSessions_Validation(t *testing.T) {
+	app, _ := setupIntakeSessionsTestApp(t)
+
+	missing := uint(999)
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           any
+		expectedStatus int
+	}{
+		{"Unknown location", "POST", "/intake-sessions", CreateIntakeSessionRequest{StorageLocationID: &missing}, fiber.StatusBadRequest},
+		{"Invalid status filter", "GET", "/intake-sessions?status=pending", nil, fiber.StatusBadRequest},
+		{"Unknown session", "GET", "/intake-sessions/999", nil, fiber.StatusNotFound},
+		{"Commit unknown session", "POST", "/intake-sessions/999/commit", nil, fiber.StatusNotFound},
+		{"No entries", "POST", "/intake-sessions/999/items", AddIntakeItemsRequest{}, fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := intakeRequest(t, app, tt.method, tt.path, tt.body, nil); status != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, status)
+			}
+		})
+	}
+}
@@ -2,12 +2,19 @@ package api
 
 import (
 	"backend/models"
+	"backend/realtime"
 	"backend/rules"
 	"backend/services"
 	"backend/utils"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	scryfall "github.com/BlueMonday/go-scryfall"
 	"github.com/gofiber/fiber/v3"
@@ -18,16 +25,47 @@ import (
 type InventoryHandler struct {
 	db          *gorm.DB
 	autoSortSvc *services.AutoSortService
+	undoSvc     *services.UndoService
+	jobService  *services.JobService
+	hub         *realtime.Hub
+	exports     *ExportFiles
 }
 
 // NewInventoryHandler creates a new inventory handler
-func NewInventoryHandler(db *gorm.DB, autoSortSvc *services.AutoSortService) *InventoryHandler {
+func NewInventoryHandler(db *gorm.DB, autoSortSvc *services.AutoSortService, undoSvc *services.UndoService) *InventoryHandler {
 	return &InventoryHandler{
 		db:          db,
 		autoSortSvc: autoSortSvc,
+		undoSvc:     undoSvc,
 	}
 }
 
+// SetHub publishes inventory changes to hub's WebSocket clients
+func (h *InventoryHandler) SetHub(hub *realtime.Hub) {
+	h.hub = hub
+}
+
+// SetJobService lets the handler run long operations, such as async resorts, as jobs
+func (h *InventoryHandler) SetJobService(jobService *services.JobService) {
+	h.jobService = jobService
+}
+
+// SetExportFiles serves inventory exports from exports, so interrupted downloads can resume
+func (h *InventoryHandler) SetExportFiles(exports *ExportFiles) {
+	h.exports = exports
+}
+
+// recordUndo stores the prior state of a batch operation and returns its operation ID
+// and undo token. Failing to record is logged but does not fail the operation itself.
+func (h *InventoryHandler) recordUndo(ctx context.Context, snapshot services.UndoSnapshot) (uint, string, *time.Time) {
+	receipt, err := h.undoSvc.Record(ctx, snapshot)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record undo snapshot", "component", "inventory", "operation", snapshot.Operation, "error", err)
+		return 0, "", nil
+	}
+	return receipt.OperationID, receipt.Token, &receipt.ExpiresAt
+}
+
 // List returns inventory items with pagination
 func (h *InventoryHandler) List(c fiber.Ctx) error {
 	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
@@ -38,18 +76,40 @@ func (h *InventoryHandler) List(c fiber.Ctx) error {
 
 	query := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{})
 
+	query, err := applyStandardLegalFilter(query, c.Query("standard_legal"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	filters, err := parsePrintFilters(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	query = filters.applyToInventory(query)
+	query = applyNoteFilter(query, c.Query("note_contains"))
+	query = applyTagFilter(query, c.Query("tag"))
+
 	if scryfallID != "" {
 		query = query.Where("scryfall_id = ?", scryfallID)
 	}
 
 	if storageLocationID != "" {
-		if storageLocationID == "null" {
+		if isUnassignedFilter(storageLocationID) {
 			query = query.Where("storage_location_id IS NULL")
 		} else {
 			if err := utils.ValidateNumericParam(storageLocationID, "storage_location_id"); err != nil {
 				return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
 			}
-			query = query.Where("storage_location_id = ?", storageLocationID)
+			if c.Query("include_descendants") == "true" {
+				locationIDs, err := locationWithDescendants(h.db.WithContext(c.RequestCtx()), storageLocationID)
+				if err != nil {
+					return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+						"Failed to fetch nested storage locations", "descendant lookup failed", err)
+				}
+				query = query.Where("storage_location_id IN ?", locationIDs)
+			} else {
+				query = query.Where("storage_location_id = ?", storageLocationID)
+			}
 		}
 	}
 
@@ -70,8 +130,22 @@ func (h *InventoryHandler) List(c fiber.Ctx) error {
 			"Failed to fetch inventory items", "database query failed", err)
 	}
 
-	response := utils.NewPaginatedResponse(items, params.Page, params.PageSize, total)
-	return c.JSON(response)
+	if err := annotateOnLoan(h.db.WithContext(c.RequestCtx()), items); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loan data", "on-loan query failed", err)
+	}
+	if err := annotateTags(h.db.WithContext(c.RequestCtx()), items); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch tags", "tag query failed", err)
+	}
+
+	return utils.SendPaginated(c, items, params.Page, params.PageSize, total)
+}
+
+// isUnassignedFilter reports whether a storage_location_id filter selects unassigned
+// items: the virtual unassigned location ID, or the legacy "null"
+func isUnassignedFilter(value string) bool {
+	return value == "null" || value == strconv.FormatUint(uint64(models.UnassignedLocationID), 10)
 }
 
 // Get returns a single inventory item by ID
@@ -89,7 +163,97 @@ func (h *InventoryHandler) Get(c fiber.Ctx) error {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch inventory item", "database query failed", err)
 	}
-	return c.JSON(item)
+
+	items := []models.Inventory{item}
+	if err := annotateOnLoan(h.db.WithContext(c.RequestCtx()), items); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loan data", "on-loan query failed", err)
+	}
+	if err := annotateTags(h.db.WithContext(c.RequestCtx()), items); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch tags", "tag query failed", err)
+	}
+	return c.JSON(items[0])
+}
+
+// annotateOnLoan fills in OnLoanQuantity for each item from active loans
+func annotateOnLoan(db *gorm.DB, items []models.Inventory) error {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	onLoan, err := models.GetOnLoanQuantities(db, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		items[i].OnLoanQuantity = onLoan[items[i].ID]
+	}
+	return nil
+}
+
+// standardLegalCardsSubquery selects cards printed in a current Standard set that are legal in Standard
+const standardLegalCardsSubquery = `SELECT scryfall_id FROM cards
+	WHERE set_code IN (SELECT code FROM sets WHERE standard_legal = 1)
+	AND json_extract(raw_json, '$.legalities.standard') = 'legal'`
+
+// applyStandardLegalFilter narrows an inventory query by the standard_legal query param ("true" or "false")
+func applyStandardLegalFilter(query *gorm.DB, value string) (*gorm.DB, error) {
+	switch value {
+	case "":
+		return query, nil
+	case "true":
+		return query.Where("scryfall_id IN (" + standardLegalCardsSubquery + ")"), nil
+	case "false":
+		return query.Where("scryfall_id NOT IN (" + standardLegalCardsSubquery + ")"), nil
+	default:
+		return nil, errors.New("standard_legal must be true or false")
+	}
+}
+
+// noteLikeEscaper escapes LIKE wildcards so note_contains matches text literally
+var noteLikeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// applyNoteFilter narrows an inventory query to items whose notes contain value, ignoring
+// case. An empty value leaves the query unchanged.
+func applyNoteFilter(query *gorm.DB, value string) *gorm.DB {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return query
+	}
+	return query.Where(`notes LIKE ? ESCAPE '\'`, "%"+noteLikeEscaper.Replace(value)+"%")
+}
+
+// applyTagFilter narrows an inventory query to items carrying every tag in value, a
+// comma-separated list of tag names. An empty value leaves the query unchanged.
+func applyTagFilter(query *gorm.DB, value string) *gorm.DB {
+	for _, name := range strings.Split(value, ",") {
+		if name = models.NormalizeTagName(name); name != "" {
+			query = query.Where(`inventories.id IN (SELECT inventory_tags.inventory_id FROM inventory_tags
+				JOIN tags ON tags.id = inventory_tags.tag_id WHERE tags.name = ?)`, name)
+		}
+	}
+	return query
+}
+
+// annotateTags fills in Tags for each item
+func annotateTags(db *gorm.DB, items []models.Inventory) error {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	tags, err := models.GetInventoryTagNames(db, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+	}
+	return nil
 }
 
 // CreateInventoryRequest represents the request body for creating an inventory item
@@ -97,11 +261,18 @@ type CreateInventoryRequest struct {
 	ScryfallID        string `json:"scryfall_id"`
 	OracleID          string `json:"oracle_id"`
 	Treatment         string `json:"treatment,omitempty"`
-	Quantity          int    `json:"quantity"`
-	StorageLocationID *uint  `json:"storage_location_id,omitempty"`
+	Quantity          int     `json:"quantity"`
+	StorageLocationID *uint   `json:"storage_location_id,omitempty"` // 0 keeps the item unassigned; nil applies sorting rules
+	SerialNumber      *string `json:"serial_number,omitempty"`       // For serialized printings; quantity must be 1
+	Notes             string  `json:"notes,omitempty"`
+	AcquiredPrice     *float64   `json:"acquired_price,omitempty"` // Price paid per copy, in the preferred currency
+	AcquiredAt        *time.Time `json:"acquired_at,omitempty"`
+	AcquiredFrom      string     `json:"acquired_from,omitempty"`
 }
 
-// Create creates a new inventory item
+// Create creates a new inventory item. With upsert=true the copies are added to an
+// existing row for the same printing, treatment, location and notes instead (200 rather
+// than 201); serialized copies and copies with acquisition details always get a new row.
 func (h *InventoryHandler) Create(c fiber.Ctx) error {
 	var req CreateInventoryRequest
 	if err := c.Bind().Body(&req); err != nil {
@@ -123,8 +294,18 @@ func (h *InventoryHandler) Create(c fiber.Ctx) error {
 		req.Quantity = 1
 	}
 
-	// Validate storage location exists if provided
-	if req.StorageLocationID != nil {
+	if req.SerialNumber != nil {
+		serial := strings.TrimSpace(*req.SerialNumber)
+		req.SerialNumber = &serial
+		if ok, err := h.checkSerialNumber(c, req.ScryfallID, serial, 0); !ok {
+			return err
+		}
+	}
+
+	// Validate storage location exists if provided; the unassigned location skips sorting rules
+	if req.StorageLocationID != nil && *req.StorageLocationID == models.UnassignedLocationID {
+		req.StorageLocationID = nil
+	} else if req.StorageLocationID != nil {
 		var location models.StorageLocation
 		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -135,11 +316,11 @@ func (h *InventoryHandler) Create(c fiber.Ctx) error {
 		}
 	} else {
 		// If no storage location provided, automatically evaluate sorting rules
-		slog.Info("evaluating sorting rules", "component", "inventory", "scryfall_id", req.ScryfallID)
+		slog.InfoContext(c.RequestCtx(), "evaluating sorting rules", "component", "inventory", "scryfall_id", req.ScryfallID)
 
-		locationID, err := h.autoSortSvc.DetermineStorageLocation(c.RequestCtx(), req.ScryfallID, req.Treatment)
+		locationID, err := h.autoSortSvc.DetermineStorageLocation(c.RequestCtx(), req.ScryfallID, req.Treatment, req.Notes, req.Quantity)
 		if err != nil {
-			slog.Debug("auto-sort did not assign location", "component", "inventory", "scryfall_id", req.ScryfallID, "error", err)
+			slog.DebugContext(c.RequestCtx(), "auto-sort did not assign location", "component", "inventory", "scryfall_id", req.ScryfallID, "error", err)
 		} else {
 			req.StorageLocationID = locationID
 		}
@@ -151,9 +332,40 @@ func (h *InventoryHandler) Create(c fiber.Ctx) error {
 		Treatment:         req.Treatment,
 		Quantity:          req.Quantity,
 		StorageLocationID: req.StorageLocationID,
+		SerialNumber:      req.SerialNumber,
+		Notes:             strings.TrimSpace(req.Notes),
+		AcquiredPrice:     req.AcquiredPrice,
+		AcquiredAt:        req.AcquiredAt,
+		AcquiredFrom:      strings.TrimSpace(req.AcquiredFrom),
+	}
+	if err := item.ValidateInventory(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
 	}
 
-	if err := h.db.WithContext(c.RequestCtx()).Create(&item).Error; err != nil {
+	upsert := c.Query("upsert") == "true"
+	merged := false
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if upsert {
+			existing, err := models.FindMergeableInventory(tx, item)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				before := *existing
+				existing.Quantity += item.Quantity
+				if err := tx.Save(existing).Error; err != nil {
+					return err
+				}
+				item, merged = *existing, true
+				return models.RecordInventoryEvents(tx, models.InventoryChangeEvents(before, item))
+			}
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryCreatedEvent(item)})
+	})
+	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to create inventory item", "database insert failed", err)
 	}
@@ -164,6 +376,11 @@ func (h *InventoryHandler) Create(c fiber.Ctx) error {
 			"Failed to reload inventory item", "database query failed", err)
 	}
 
+	if merged {
+		h.hub.Publish(realtime.EventInventoryUpdated, realtime.InventoryChange{IDs: []uint{item.ID}})
+		return c.JSON(item)
+	}
+	h.hub.Publish(realtime.EventInventoryCreated, realtime.InventoryChange{IDs: []uint{item.ID}})
 	return c.Status(fiber.StatusCreated).JSON(item)
 }
 
@@ -174,7 +391,14 @@ type UpdateInventoryRequest struct {
 	Treatment         *string `json:"treatment,omitempty"`
 	Quantity          *int    `json:"quantity,omitempty"`
 	StorageLocationID *uint   `json:"storage_location_id,omitempty"`
-	ClearStorage      bool    `json:"clear_storage,omitempty"`
+	ClearStorage      bool    `json:"clear_storage,omitempty"` // Same as storage_location_id 0 (unassigned)
+	SerialNumber      *string `json:"serial_number,omitempty"`
+	ClearSerial       bool    `json:"clear_serial,omitempty"`
+	Notes             *string `json:"notes,omitempty"` // Empty string clears the notes
+	AcquiredPrice     *float64   `json:"acquired_price,omitempty"`
+	AcquiredAt        *time.Time `json:"acquired_at,omitempty"`
+	AcquiredFrom      *string    `json:"acquired_from,omitempty"` // Empty string clears the source
+	ClearAcquisition  bool       `json:"clear_acquisition,omitempty"` // Clears price, date, and source
 }
 
 // Update updates an existing inventory item
@@ -193,13 +417,17 @@ func (h *InventoryHandler) Update(c fiber.Ctx) error {
 			"Failed to fetch inventory item", "database query failed", err)
 	}
 
+	before := item
+
 	var req UpdateInventoryRequest
 	if err := c.Bind().Body(&req); err != nil {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
 	}
 
 	if req.ScryfallID == nil && req.OracleID == nil && req.Treatment == nil &&
-		req.Quantity == nil && req.StorageLocationID == nil && !req.ClearStorage {
+		req.Quantity == nil && req.StorageLocationID == nil && !req.ClearStorage &&
+		req.SerialNumber == nil && !req.ClearSerial && req.Notes == nil &&
+		req.AcquiredPrice == nil && req.AcquiredAt == nil && req.AcquiredFrom == nil && !req.ClearAcquisition {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "at least one field must be provided for update")
 	}
 
@@ -216,9 +444,29 @@ func (h *InventoryHandler) Update(c fiber.Ctx) error {
 	if req.Quantity != nil {
 		item.Quantity = *req.Quantity
 	}
+	if req.Notes != nil {
+		item.Notes = strings.TrimSpace(*req.Notes)
+	}
+
+	// Handle acquisition updates
+	if req.ClearAcquisition {
+		item.AcquiredPrice = nil
+		item.AcquiredAt = nil
+		item.AcquiredFrom = ""
+	} else {
+		if req.AcquiredPrice != nil {
+			item.AcquiredPrice = req.AcquiredPrice
+		}
+		if req.AcquiredAt != nil {
+			item.AcquiredAt = req.AcquiredAt
+		}
+		if req.AcquiredFrom != nil {
+			item.AcquiredFrom = strings.TrimSpace(*req.AcquiredFrom)
+		}
+	}
 
 	// Handle storage location updates
-	if req.ClearStorage {
+	if req.ClearStorage || (req.StorageLocationID != nil && *req.StorageLocationID == models.UnassignedLocationID) {
 		item.StorageLocationID = nil
 	} else if req.StorageLocationID != nil {
 		// Validate storage location exists
@@ -233,7 +481,29 @@ func (h *InventoryHandler) Update(c fiber.Ctx) error {
 		item.StorageLocationID = req.StorageLocationID
 	}
 
-	if err := h.db.WithContext(c.RequestCtx()).Save(&item).Error; err != nil {
+	// Handle serial number updates
+	if req.ClearSerial {
+		item.SerialNumber = nil
+	} else if req.SerialNumber != nil {
+		serial := strings.TrimSpace(*req.SerialNumber)
+		item.SerialNumber = &serial
+	}
+	if item.SerialNumber != nil {
+		if ok, err := h.checkSerialNumber(c, item.ScryfallID, *item.SerialNumber, item.ID); !ok {
+			return err
+		}
+	}
+	if err := item.ValidateInventory(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		return models.RecordInventoryEvents(tx, models.InventoryChangeEvents(before, item))
+	})
+	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update inventory item", "database update failed", err)
 	}
@@ -244,6 +514,7 @@ func (h *InventoryHandler) Update(c fiber.Ctx) error {
 			"Failed to reload inventory item", "database query failed", err)
 	}
 
+	h.hub.Publish(realtime.EventInventoryUpdated, realtime.InventoryChange{IDs: []uint{item.ID}})
 	return c.JSON(item)
 }
 
@@ -254,20 +525,32 @@ func (h *InventoryHandler) Delete(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
 	}
 
-	result := h.db.WithContext(c.RequestCtx()).Delete(&models.Inventory{}, id)
-	if result.Error != nil {
+	var item models.Inventory
+	if err := h.db.WithContext(c.RequestCtx()).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "inventory item not found")
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to delete inventory item", "database delete failed", result.Error)
+			"Failed to fetch inventory item", "database query failed", err)
 	}
 
-	if result.RowsAffected == 0 {
-		return utils.ReturnError(c, fiber.StatusNotFound, "inventory item not found")
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Inventory{}, item.ID).Error; err != nil {
+			return err
+		}
+		return models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryDeletedEvent(item)})
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete inventory item", "database delete failed", err)
 	}
 
+	h.hub.Publish(realtime.EventInventoryDeleted, realtime.InventoryChange{IDs: []uint{uint(id)}})
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// InventoryCardsResponse represents paginated card results with inventory data
+// InventoryCardsResponse represents paginated card results with inventory data.
+// This is the legacy shape; API version 2 requests get a utils.Envelope instead.
 // tygo:export
 type InventoryCardsResponse struct {
 	Data       []EnhancedCardResult `json:"data"`
@@ -305,15 +588,51 @@ func (h *InventoryHandler) ListAsCards(c fiber.Ctx) error {
 
 	// Build query
 	query := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{})
-	if locationID == "null" {
+	if isUnassignedFilter(locationID) {
 		query = query.Where("storage_location_id IS NULL")
 	} else if locationID != "" {
 		if err := utils.ValidateNumericParam(locationID, "storage_location_id"); err != nil {
 			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
 		}
-		query = query.Where("storage_location_id = ?", locationID)
+		if c.Query("include_descendants") == "true" {
+			locationIDs, err := locationWithDescendants(h.db.WithContext(c.RequestCtx()), locationID)
+			if err != nil {
+				return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+					"Failed to fetch nested storage locations", "descendant lookup failed", err)
+			}
+			query = query.Where("storage_location_id IN ?", locationIDs)
+		} else {
+			query = query.Where("storage_location_id = ?", locationID)
+		}
+	}
+
+	query, err := applyStandardLegalFilter(query, c.Query("standard_legal"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	filters, err := parsePrintFilters(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	query = filters.applyToInventory(query)
+	query = applyNoteFilter(query, c.Query("note_contains"))
+	query = applyTagFilter(query, c.Query("tag"))
+
+	cardFilters, err := parseInventoryCardFilters(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
 	}
+	currency := services.PreferredCurrency(c.RequestCtx(), h.db)
+	query = cardFilters.apply(query, currency)
 
+	return sendInventoryCards(c, h.db, query, cardFilters.order(currency), params, true)
+}
+
+// sendInventoryCards responds with a page of the inventory rows matched by query in
+// the given order, grouped into enhanced card results. withLoans fills in each row's
+// on-loan quantity; shared pages leave who has borrowed what out.
+func sendInventoryCards(c fiber.Ctx, db *gorm.DB, query *gorm.DB, order string, params utils.PaginationParams, withLoans bool) error {
 	// Count total
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -326,13 +645,19 @@ func (h *InventoryHandler) ListAsCards(c fiber.Ctx) error {
 	offset := utils.CalculateOffset(params.Page, params.PageSize)
 	if err := query.
 		Preload("StorageLocation").
-		Order("created_at DESC").
+		Order(order).
 		Limit(params.PageSize).
 		Offset(offset).
 		Find(&inventoryItems).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch inventory items", "database query failed", err)
 	}
+	if withLoans {
+		if err := annotateOnLoan(db.WithContext(c.RequestCtx()), inventoryItems); err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to fetch loan data", "on-loan query failed", err)
+		}
+	}
 
 	// Group by Scryfall ID to fetch card data
 	scryfallIDs := make([]string, 0)
@@ -346,13 +671,14 @@ func (h *InventoryHandler) ListAsCards(c fiber.Ctx) error {
 	}
 
 	// Fetch and parse card data
-	scryfallCardMap, err := models.GetScryfallCardsByIDs(h.db.WithContext(c.RequestCtx()), scryfallIDs)
+	scryfallCardMap, err := models.GetScryfallCardsByIDs(db.WithContext(c.RequestCtx()), scryfallIDs)
 	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch card data", "cards query failed", err)
 	}
 
 	// Build enhanced card results using card data
+	links := services.ExternalLinksEnabled(c.RequestCtx(), db)
 	enhancedResults := make([]EnhancedCardResult, 0, len(scryfallIDs))
 	for _, scryfallID := range scryfallIDs {
 		scryfallCard, found := scryfallCardMap[scryfallID]
@@ -361,9 +687,16 @@ func (h *InventoryHandler) ListAsCards(c fiber.Ctx) error {
 		}
 
 		enhancedCard := buildEnhancedCardResult(scryfallCard, inventoryMap[scryfallID])
+		if links {
+			enhancedCard = enhancedCard.withExternalLinks(scryfallCard)
+		}
 		enhancedResults = append(enhancedResults, enhancedCard)
 	}
 
+	if utils.RequestAPIVersion(c) == utils.APIVersionEnvelope {
+		return c.JSON(utils.NewEnvelope(enhancedResults, params.Page, params.PageSize, total, nil))
+	}
+
 	// Calculate total pages
 	totalPages := utils.CalculateTotalPages(total, params.PageSize)
 
@@ -382,6 +715,7 @@ type ExistingPrintingInfo struct {
 	ScryfallID      string                  `json:"scryfall_id"`
 	Treatment       string                  `json:"treatment"`
 	Quantity        int                     `json:"quantity"`
+	OnLoanQuantity  int                     `json:"on_loan_quantity"` // Copies currently lent out
 	StorageLocation *models.StorageLocation `json:"storage_location,omitempty"`
 }
 
@@ -407,6 +741,10 @@ func (h *InventoryHandler) ByOracle(c fiber.Ctx) error {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch inventory items", "database query failed", err)
 	}
+	if err := annotateOnLoan(h.db.WithContext(c.RequestCtx()), items); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loan data", "on-loan query failed", err)
+	}
 
 	// Build response with printings and unique locations
 	printings := make([]ExistingPrintingInfo, 0, len(items))
@@ -417,6 +755,7 @@ func (h *InventoryHandler) ByOracle(c fiber.Ctx) error {
 			ScryfallID:      item.ScryfallID,
 			Treatment:       item.Treatment,
 			Quantity:        item.Quantity,
+			OnLoanQuantity:  item.OnLoanQuantity,
 			StorageLocation: item.StorageLocation,
 		})
 
@@ -455,13 +794,16 @@ func (h *InventoryHandler) GetUnassignedCount(c fiber.Ctx) error {
 // tygo:export
 type BatchMoveRequest struct {
 	IDs               []uint `json:"ids"`
-	StorageLocationID *uint  `json:"storage_location_id"`
+	StorageLocationID *uint  `json:"storage_location_id"` // nil or 0 unassigns
 }
 
 // BatchMoveResponse represents the response for batch move operations
 // tygo:export
 type BatchMoveResponse struct {
-	Updated int `json:"updated"`
+	Updated       int        `json:"updated"`
+	OperationID   uint       `json:"operation_id,omitempty"`
+	UndoToken     string     `json:"undo_token,omitempty"`
+	UndoExpiresAt *time.Time `json:"undo_expires_at,omitempty"`
 }
 
 // BatchMove moves multiple inventory items to a new storage location
@@ -480,7 +822,8 @@ func (h *InventoryHandler) BatchMove(c fiber.Ctx) error {
 			fmt.Sprintf("too many ids (max %d)", MaxBatchIDs))
 	}
 
-	// Validate storage location exists if provided
+	// Validate storage location exists if provided; nil and the unassigned location both unassign
+	req.StorageLocationID = models.StoredLocationID(req.StorageLocationID)
 	if req.StorageLocationID != nil {
 		var location models.StorageLocation
 		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
@@ -492,21 +835,60 @@ func (h *InventoryHandler) BatchMove(c fiber.Ctx) error {
 		}
 	}
 
+	// Capture the current locations so the move can be undone
+	var previous []models.Inventory
+	if err := h.db.WithContext(c.RequestCtx()).Where("id IN ?", req.IDs).Find(&previous).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch inventory items", "database query failed", err)
+	}
+
 	// Update all items in a single query
 	// Use UpdateColumn to skip BeforeUpdate hooks — this is a targeted column update
 	// that doesn't need full model validation (ScryfallID, OracleID, etc.)
-	result := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{}).
-		Where("id IN ?", req.IDs).
-		UpdateColumn("storage_location_id", req.StorageLocationID)
+	var events []models.InventoryEvent
+	for _, before := range previous {
+		after := before
+		after.StorageLocationID = req.StorageLocationID
+		events = append(events, models.InventoryChangeEvents(before, after)...)
+	}
 
-	if result.Error != nil {
+	var result *gorm.DB
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		result = tx.Model(&models.Inventory{}).
+			Where("id IN ?", req.IDs).
+			UpdateColumn("storage_location_id", req.StorageLocationID)
+		if result.Error != nil {
+			return result.Error
+		}
+		return models.RecordInventoryEvents(tx, events)
+	})
+	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to move inventory items", "database update failed", result.Error)
+			"Failed to move inventory items", "database update failed", err)
 	}
 
-	slog.Info("batch moved items", "component", "inventory", "count", result.RowsAffected, "storage_location_id", req.StorageLocationID)
+	slog.InfoContext(c.RequestCtx(), "batch moved items", "component", "inventory", "count", result.RowsAffected, "storage_location_id", req.StorageLocationID)
+	if result.RowsAffected > 0 {
+		h.hub.Publish(realtime.EventInventoryUpdated, realtime.InventoryChange{IDs: inventoryIDs(previous)})
+	}
 
-	return c.JSON(BatchMoveResponse{Updated: int(result.RowsAffected)})
+	response := BatchMoveResponse{Updated: int(result.RowsAffected)}
+	if len(previous) > 0 {
+		response.OperationID, response.UndoToken, response.UndoExpiresAt = h.recordUndo(c.RequestCtx(), services.UndoSnapshot{
+			Operation: services.UndoOperationBatchMove,
+			Rows:      previous,
+		})
+	}
+	return c.JSON(response)
+}
+
+// inventoryIDs returns the IDs of items, for change events
+func inventoryIDs(items []models.Inventory) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
 }
 
 // BatchDeleteRequest represents the request body for deleting multiple inventory items
@@ -518,7 +900,10 @@ type BatchDeleteRequest struct {
 // BatchDeleteResponse represents the response for batch delete operations
 // tygo:export
 type BatchDeleteResponse struct {
-	Deleted int `json:"deleted"`
+	Deleted       int        `json:"deleted"`
+	OperationID   uint       `json:"operation_id,omitempty"`
+	UndoToken     string     `json:"undo_token,omitempty"`
+	UndoExpiresAt *time.Time `json:"undo_expires_at,omitempty"`
 }
 
 // BatchDelete deletes multiple inventory items
@@ -537,21 +922,57 @@ func (h *InventoryHandler) BatchDelete(c fiber.Ctx) error {
 			fmt.Sprintf("too many ids (max %d)", MaxBatchIDs))
 	}
 
-	result := h.db.WithContext(c.RequestCtx()).Delete(&models.Inventory{}, req.IDs)
-	if result.Error != nil {
+	// Capture the rows (and loan lines that cascade with them) so the delete can be undone
+	var previous []models.Inventory
+	if err := h.db.WithContext(c.RequestCtx()).Where("id IN ?", req.IDs).Find(&previous).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to delete inventory items", "database delete failed", result.Error)
+			"Failed to fetch inventory items", "database query failed", err)
+	}
+	var loanItems []models.LoanItem
+	if err := h.db.WithContext(c.RequestCtx()).Where("inventory_id IN ?", req.IDs).Find(&loanItems).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loan items", "database query failed", err)
 	}
 
-	slog.Info("batch deleted items", "component", "inventory", "count", result.RowsAffected)
+	events := make([]models.InventoryEvent, 0, len(previous))
+	for _, item := range previous {
+		events = append(events, models.NewInventoryDeletedEvent(item))
+	}
 
-	return c.JSON(BatchDeleteResponse{Deleted: int(result.RowsAffected)})
+	var result *gorm.DB
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		result = tx.Delete(&models.Inventory{}, req.IDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		return models.RecordInventoryEvents(tx, events)
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete inventory items", "database delete failed", err)
+	}
+
+	slog.InfoContext(c.RequestCtx(), "batch deleted items", "component", "inventory", "count", result.RowsAffected)
+	if result.RowsAffected > 0 {
+		h.hub.Publish(realtime.EventInventoryDeleted, realtime.InventoryChange{IDs: inventoryIDs(previous)})
+	}
+
+	response := BatchDeleteResponse{Deleted: int(result.RowsAffected)}
+	if len(previous) > 0 {
+		response.OperationID, response.UndoToken, response.UndoExpiresAt = h.recordUndo(c.RequestCtx(), services.UndoSnapshot{
+			Operation: services.UndoOperationBatchDelete,
+			Rows:      previous,
+			LoanItems: loanItems,
+		})
+	}
+	return c.JSON(response)
 }
 
 // ResortRequest represents the request body for re-sorting inventory items
 // tygo:export
 type ResortRequest struct {
-	IDs []uint `json:"ids,omitempty"` // If empty, resort all items
+	IDs   []uint `json:"ids,omitempty"`   // If empty, resort all items
+	Async bool   `json:"async,omitempty"` // Run as a background job; the response is then the job's result
 }
 
 // ResortMovement represents a single card movement during resort
@@ -559,31 +980,58 @@ type ResortRequest struct {
 type ResortMovement struct {
 	CardName     string  `json:"card_name"`
 	Treatment    string  `json:"treatment"`
-	FromLocation *string `json:"from_location"` // nil means unassigned
-	ToLocation   *string `json:"to_location"`   // nil means unassigned
+	FromLocation *string `json:"from_location"`      // nil means unassigned
+	ToLocation   *string `json:"to_location"`        // nil means unassigned
+	Quantity     int     `json:"quantity,omitempty"` // Set when a row is split across locations
 }
 
 // ResortResponse represents the response for resort operations
 // tygo:export
 type ResortResponse struct {
-	Processed int               `json:"processed"`
-	Updated   int               `json:"updated"`
-	Errors    int               `json:"errors"`
-	Movements []ResortMovement  `json:"movements,omitempty"`
+	Processed     int              `json:"processed"`
+	Updated       int              `json:"updated"`
+	Errors        int              `json:"errors"`
+	Movements     []ResortMovement `json:"movements,omitempty"`
+	OperationID   uint             `json:"operation_id,omitempty"`
+	UndoToken     string           `json:"undo_token,omitempty"`
+	UndoExpiresAt *time.Time       `json:"undo_expires_at,omitempty"`
 }
 
+// ResortJobMetadata is stored in job.Metadata while an async resort runs
+// tygo:export
+type ResortJobMetadata struct {
+	Phase      string `json:"phase"` // "pending", "evaluating", "updating", "completed"
+	TotalItems int    `json:"total_items"`
+	Processed  int    `json:"processed"`
+}
+
+// resortBatchSize is how many inventory rows a resort evaluates at a time
+const resortBatchSize = 500
+
 // resortEvalResult holds the evaluation results for batch updating after resort
 type resortEvalResult struct {
 	processed int
 	errors    int
 	movements []ResortMovement
-	clearIDs  []uint            // items to unassign
-	moveMap   map[uint][]uint   // locationID -> []itemID
+	clearIDs  []uint          // items to unassign
+	moveMap   map[uint][]uint // locationID -> []itemID
+	splits    []resortSplit   // items divided across several locations
+}
+
+// resortSplit records an inventory row whose quantity is divided across locations.
+// The first placement is applied to the existing row; the rest become new rows.
+type resortSplit struct {
+	item       models.Inventory
+	placements []services.Placement
 }
 
 // evaluateResortItems evaluates sorting rules against each inventory item and
 // determines which items need to be moved or unassigned.
-func evaluateResortItems(items []models.Inventory, cardMap map[string]models.Card, sortingRules []models.SortingRule, evaluator *rules.Evaluator) resortEvalResult {
+// Location capacities are enforced against usage: a row goes to the first matching
+// location with room for it, then the overflow location if one is set. Rows no rule
+// matches go to the catch-all location if one is set. When split is true, rows that
+// would overflow are instead divided across those locations.
+func evaluateResortItems(items []models.Inventory, cardMap map[string]models.Card, sortingRules []models.SortingRule, evaluator *rules.Evaluator, usage map[uint]int, split bool, overflow, catchAll *models.StorageLocation) resortEvalResult {
 	result := resortEvalResult{
 		movements: make([]ResortMovement, 0),
 		clearIDs:  make([]uint, 0),
@@ -606,6 +1054,11 @@ func evaluateResortItems(items []models.Inventory, cardMap map[string]models.Car
 			result.errors++
 			continue
 		}
+		cardData["notes"] = item.Notes
+		if len(item.Tags) > 0 {
+			// Lets hasTag match the item's own tags as well as those added by tag rule groups
+			cardData["tags"] = item.Tags
+		}
 
 		cardName := ""
 		if name, ok := cardData["name"].(string); ok {
@@ -617,9 +1070,39 @@ func evaluateResortItems(items []models.Inventory, cardMap map[string]models.Car
 			fromLocation = &item.StorageLocation.Name
 		}
 
-		location, err := evaluator.EvaluateCardWithRules(cardData, sortingRules)
-		if err != nil {
-			// No matching rule — clear storage location if currently assigned
+		var location *models.StorageLocation
+		matches := services.WithOverflow(services.WithCatchAll(evaluator.MatchingLocations(cardData, sortingRules), catchAll), overflow)
+		if split {
+			placements := services.SplitPlacements(item.Quantity, matches, usage)
+			if len(placements) > 1 {
+				result.splits = append(result.splits, resortSplit{item: item, placements: placements})
+				for _, placement := range placements {
+					result.movements = append(result.movements, ResortMovement{
+						CardName:     cardName,
+						Treatment:    item.Treatment,
+						FromLocation: fromLocation,
+						ToLocation:   locationName(matches, placement.StorageLocationID),
+						Quantity:     placement.Quantity,
+					})
+				}
+				continue
+			}
+			// A single placement is an ordinary move, or an unassignment when no match has room
+			for i := range matches {
+				if len(placements) == 0 || (placements[0].StorageLocationID != nil && *placements[0].StorageLocationID == matches[i].ID) {
+					location = &matches[i]
+					break
+				}
+			}
+		} else {
+			location = services.PlaceWhole(item.Quantity, matches, usage)
+		}
+		if location != nil && location.ID == models.UnassignedLocationID {
+			location = nil
+		}
+
+		if location == nil {
+			// No matching rule (or no room left) — clear storage location if currently assigned
 			if item.StorageLocationID != nil {
 				result.clearIDs = append(result.clearIDs, item.ID)
 				result.movements = append(result.movements, ResortMovement{
@@ -647,9 +1130,62 @@ func evaluateResortItems(items []models.Inventory, cardMap map[string]models.Car
 	return result
 }
 
-// executeResortUpdates applies the resort evaluation results to the database in a single transaction.
-func executeResortUpdates(db *gorm.DB, eval resortEvalResult) (int, error) {
+// merge adds the evaluation of another batch of items to eval
+func (eval *resortEvalResult) merge(batch resortEvalResult) {
+	eval.processed += batch.processed
+	eval.errors += batch.errors
+	eval.movements = append(eval.movements, batch.movements...)
+	eval.clearIDs = append(eval.clearIDs, batch.clearIDs...)
+	for locationID, ids := range batch.moveMap {
+		eval.moveMap[locationID] = append(eval.moveMap[locationID], ids...)
+	}
+	eval.splits = append(eval.splits, batch.splits...)
+}
+
+// locationName looks up the name of one of the given locations, or nil for unassigned
+func locationName(locations []models.StorageLocation, locationID *uint) *string {
+	if locationID == nil {
+		return nil
+	}
+	for i := range locations {
+		if locations[i].ID == *locationID {
+			return &locations[i].Name
+		}
+	}
+	return nil
+}
+
+// changedItems returns the items whose location or quantity the evaluation will change
+func (eval resortEvalResult) changedItems(items []models.Inventory) []models.Inventory {
+	changed := make(map[uint]bool)
+	for _, id := range eval.clearIDs {
+		changed[id] = true
+	}
+	for _, ids := range eval.moveMap {
+		for _, id := range ids {
+			changed[id] = true
+		}
+	}
+	for _, split := range eval.splits {
+		changed[split.item.ID] = true
+	}
+
+	result := make([]models.Inventory, 0, len(changed))
+	for _, item := range items {
+		if changed[item.ID] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// executeResortUpdates applies the resort evaluation results to the database in a single transaction,
+// recording a resorted event for every changed or created row. items are the rows as they were
+// before the resort. Returns the number of rows updated or created and the IDs of rows created by splits.
+func executeResortUpdates(db *gorm.DB, items []models.Inventory, eval resortEvalResult) (int, []uint, error) {
 	updated := 0
+	var createdIDs []uint
+	events := eval.events(items)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		if len(eval.clearIDs) > 0 {
 			result := tx.Model(&models.Inventory{}).
@@ -670,36 +1206,191 @@ func executeResortUpdates(db *gorm.DB, eval resortEvalResult) (int, error) {
 			}
 			updated += int(result.RowsAffected)
 		}
-		return nil
+
+		for _, split := range eval.splits {
+			first := split.placements[0]
+			result := tx.Model(&models.Inventory{}).
+				Where("id = ?", split.item.ID).
+				UpdateColumns(map[string]interface{}{
+					"storage_location_id": first.StorageLocationID,
+					"quantity":            first.Quantity,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			updated += int(result.RowsAffected)
+
+			for _, placement := range split.placements[1:] {
+				extra := models.Inventory{
+					ScryfallID:        split.item.ScryfallID,
+					OracleID:          split.item.OracleID,
+					Treatment:         split.item.Treatment,
+					Quantity:          placement.Quantity,
+					StorageLocationID: placement.StorageLocationID,
+				}
+				if err := tx.Create(&extra).Error; err != nil {
+					return err
+				}
+				createdIDs = append(createdIDs, extra.ID)
+				events = append(events, models.NewInventoryResortedEvent(nil, extra))
+				updated++
+			}
+		}
+		return models.RecordInventoryEvents(tx, events)
 	})
-	return updated, err
+	return updated, createdIDs, err
+}
+
+// events builds a resorted event for each existing row the evaluation moves or splits
+func (eval resortEvalResult) events(items []models.Inventory) []models.InventoryEvent {
+	newLocations := make(map[uint]*uint)
+	for _, id := range eval.clearIDs {
+		newLocations[id] = nil
+	}
+	for locID, ids := range eval.moveMap {
+		for _, id := range ids {
+			newLocations[id] = &locID
+		}
+	}
+
+	var events []models.InventoryEvent
+	for _, item := range items {
+		if locationID, ok := newLocations[item.ID]; ok {
+			after := item
+			after.StorageLocationID = locationID
+			events = append(events, models.NewInventoryResortedEvent(&item, after))
+		}
+	}
+	for _, split := range eval.splits {
+		after := split.item
+		after.StorageLocationID = split.placements[0].StorageLocationID
+		after.Quantity = split.placements[0].Quantity
+		events = append(events, models.NewInventoryResortedEvent(&split.item, after))
+	}
+	return events
 }
 
-// Resort re-evaluates inventory items against sorting rules
-func (h *InventoryHandler) Resort(c fiber.Ctx) error {
+// Resort re-evaluates inventory items against sorting rules. With async set, the
+// resort runs as a background job instead and the response carries its ID.
+func (h *InventoryHandler) Resort(c fiber.Ctx, appCtx context.Context) error {
 	var req ResortRequest
 	if err := c.Bind().Body(&req); err != nil {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
 	}
 
+	if req.Async {
+		return h.startResortJob(c, appCtx, req.IDs)
+	}
+
+	response, err := h.resort(c.RequestCtx(), req.IDs, nil)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to resort inventory", "resort failed", err)
+	}
+	return c.JSON(response)
+}
+
+// resort re-evaluates the items with the given IDs, or every item when ids is empty,
+// against the sorting rules. Items are evaluated resortBatchSize at a time, calling
+// progress (when set) after each batch, and the changes are written in one transaction.
+func (h *InventoryHandler) resort(ctx context.Context, ids []uint, progress func(processed, total int)) (ResortResponse, error) {
 	// Build query for items to process (with current storage location preloaded)
-	query := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation")
-	if len(req.IDs) > 0 {
-		query = query.Where("id IN ?", req.IDs)
+	query := h.db.WithContext(ctx).Preload("StorageLocation")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
 	}
 
 	// Fetch all items to process
 	var items []models.Inventory
 	if err := query.Find(&items).Error; err != nil {
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to fetch inventory items", "database query failed", err)
+		return ResortResponse{}, fmt.Errorf("fetching inventory items: %w", err)
 	}
 
 	if len(items) == 0 {
-		return c.JSON(ResortResponse{Processed: 0, Updated: 0, Errors: 0, Movements: []ResortMovement{}})
+		return ResortResponse{Processed: 0, Updated: 0, Errors: 0, Movements: []ResortMovement{}}, nil
+	}
+
+	// Pre-fetch sorting rules once for every batch
+	var sortingRules []models.SortingRule
+	if err := h.db.WithContext(ctx).Where("enabled = ?", true).
+		Order("priority ASC").
+		Preload("StorageLocation").
+		Find(&sortingRules).Error; err != nil {
+		return ResortResponse{}, fmt.Errorf("fetching sorting rules: %w", err)
+	}
+
+	// Track how full each location is, excluding the items being re-sorted
+	// since they are about to be placed again
+	usage := make(map[uint]int)
+	if len(ids) > 0 {
+		var err error
+		usage, err = h.autoSortSvc.LocationUsage(ctx, ids)
+		if err != nil {
+			return ResortResponse{}, fmt.Errorf("fetching location usage: %w", err)
+		}
+	}
+	split := h.autoSortSvc.SplitEnabled(ctx)
+	overflow := h.autoSortSvc.OverflowLocation(ctx)
+	catchAll := h.autoSortSvc.CatchAllLocation(ctx)
+
+	// Evaluate each batch against sorting rules; usage carries over between batches
+	evaluator := rules.NewEvaluator(h.db)
+	evaluator.RecordTimings()
+	eval := resortEvalResult{
+		movements: make([]ResortMovement, 0),
+		clearIDs:  make([]uint, 0),
+		moveMap:   make(map[uint][]uint),
+	}
+	for batch := range slices.Chunk(items, resortBatchSize) {
+		if err := ctx.Err(); err != nil {
+			return ResortResponse{}, fmt.Errorf("resort cancelled: %w", err)
+		}
+
+		cardMap, err := models.GetCardsByIDs(h.db.WithContext(ctx), uniqueScryfallIDs(batch))
+		if err != nil {
+			return ResortResponse{}, fmt.Errorf("fetching card data: %w", err)
+		}
+		if err := annotateTags(h.db.WithContext(ctx), batch); err != nil {
+			return ResortResponse{}, fmt.Errorf("fetching tags: %w", err)
+		}
+		eval.merge(evaluateResortItems(batch, cardMap, sortingRules, evaluator, usage, split, overflow, catchAll))
+		if progress != nil {
+			progress(eval.processed, len(items))
+		}
+	}
+
+	// Performance figures are diagnostics only, so failing to save them doesn't fail the resort
+	if err := services.NewRulePerformanceService(h.db).Record(ctx, evaluator.Timings()); err != nil {
+		slog.WarnContext(ctx, "failed to record rule performance", "component", "resort", "error", err)
+	}
+
+	// Execute batch updates in a transaction
+	updated, createdIDs, err := executeResortUpdates(h.db.WithContext(ctx), items, eval)
+	if err != nil {
+		return ResortResponse{}, fmt.Errorf("updating inventory locations: %w", err)
 	}
 
-	// Get unique scryfall IDs to fetch card data
+	slog.InfoContext(ctx, "resort completed", "component", "resort", "processed", eval.processed, "updated", updated, "errors", eval.errors)
+	h.hub.Publish(realtime.EventResortCompleted, realtime.ResortChange{Processed: eval.processed, Updated: updated})
+
+	response := ResortResponse{
+		Processed: eval.processed,
+		Updated:   updated,
+		Errors:    eval.errors,
+		Movements: eval.movements,
+	}
+	if changed := eval.changedItems(items); len(changed) > 0 {
+		response.OperationID, response.UndoToken, response.UndoExpiresAt = h.recordUndo(ctx, services.UndoSnapshot{
+			Operation:  services.UndoOperationResort,
+			Rows:       changed,
+			CreatedIDs: createdIDs,
+		})
+	}
+	return response, nil
+}
+
+// uniqueScryfallIDs returns the distinct printings of items, in order of first appearance
+func uniqueScryfallIDs(items []models.Inventory) []string {
 	scryfallIDs := make([]string, 0)
 	seen := make(map[string]bool)
 	for _, item := range items {
@@ -708,41 +1399,152 @@ func (h *InventoryHandler) Resort(c fiber.Ctx) error {
 			seen[item.ScryfallID] = true
 		}
 	}
+	return scryfallIDs
+}
 
-	// Batch fetch all card data
-	cardMap, err := models.GetCardsByIDs(h.db.WithContext(c.RequestCtx()), scryfallIDs)
+// startResortJob creates a resort job, runs it in the background, and responds with its ID
+func (h *InventoryHandler) startResortJob(c fiber.Ctx, appCtx context.Context, ids []uint) error {
+	metadata, err := json.Marshal(ResortJobMetadata{Phase: "pending"})
 	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to fetch card data", "cards query failed", err)
+			"Failed to create resort job", "job metadata encoding failed", err)
 	}
-
-	// Pre-fetch sorting rules once for the entire batch
-	var sortingRules []models.SortingRule
-	if err := h.db.WithContext(c.RequestCtx()).Where("enabled = ?", true).
-		Order("priority ASC").
-		Preload("StorageLocation").
-		Find(&sortingRules).Error; err != nil {
+	job, err := h.jobService.Create(appCtx, models.JobTypeResort, string(metadata))
+	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to fetch sorting rules", "rules query failed", err)
+			"Failed to create resort job", "job creation failed", err)
 	}
 
-	// Evaluate each item against sorting rules
-	evaluator := rules.NewEvaluator(h.db)
-	eval := evaluateResortItems(items, cardMap, sortingRules, evaluator)
+	if err := h.jobService.Go(appCtx, job.ID, "resort", func(ctx context.Context) error {
+		// Errors are logged and recorded on the job
+		_ = h.runResortJob(ctx, job.ID, ids)
+		return nil
+	}); err != nil {
+		return startJobError(c, err)
+	}
 
-	// Execute batch updates in a transaction
-	updated, txErr := executeResortUpdates(h.db.WithContext(c.RequestCtx()), eval)
-	if txErr != nil {
+	return c.Status(fiber.StatusAccepted).JSON(TriggerImportResponse{
+		Message: "Resort job started",
+		JobID:   job.ID,
+	})
+}
+
+// runResortJob runs a resort for a job, keeping the job's metadata up to date with
+// its progress and storing the response, movements included, as the job's result
+func (h *InventoryHandler) runResortJob(ctx context.Context, jobID uint, ids []uint) error {
+	ctx, release := h.jobService.Cancellable(ctx, jobID)
+	defer release()
+
+	if err := h.jobService.Start(ctx, jobID); err != nil {
+		return fmt.Errorf("starting resort job: %w", err)
+	}
+
+	var metadata ResortJobMetadata
+	response, err := h.resort(ctx, ids, func(processed, total int) {
+		metadata.Phase, metadata.Processed, metadata.TotalItems = "evaluating", processed, total
+		if processed == total {
+			metadata.Phase = "updating"
+		}
+		h.updateResortJobMetadata(ctx, jobID, metadata)
+	})
+	if err == nil {
+		err = h.jobService.SaveResult(ctx, jobID, response)
+	}
+	if err != nil {
+		// The job's context may be cancelled, but recording the outcome still has to happen
+		cleanupCtx := context.WithoutCancel(ctx)
+		if failErr := h.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+			slog.ErrorContext(ctx, "failed to mark job as failed", "component", "resort", "job_id", jobID, "error", failErr)
+		}
+		return err
+	}
+
+	metadata.Phase, metadata.Processed, metadata.TotalItems = "completed", response.Processed, response.Processed
+	h.updateResortJobMetadata(ctx, jobID, metadata)
+	if err := h.jobService.Complete(ctx, jobID); err != nil {
+		return fmt.Errorf("completing resort job: %w", err)
+	}
+	return nil
+}
+
+func (h *InventoryHandler) updateResortJobMetadata(ctx context.Context, jobID uint, metadata ResortJobMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal job metadata", "component", "resort", "error", err)
+		return
+	}
+	if err := h.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
+		slog.WarnContext(ctx, "failed to update job metadata", "component", "resort", "error", err)
+	}
+}
+
+// ImportTextRequest represents the request body for importing a pasted card list
+// tygo:export
+type ImportTextRequest struct {
+	Text              string `json:"text"`
+	StorageLocationID *uint  `json:"storage_location_id,omitempty"` // If nil, sorting rules assign locations
+}
+
+// ImportTextResponse represents the per-line outcome of a pasted list import
+// tygo:export
+type ImportTextResponse struct {
+	Imported int                             `json:"imported"`
+	Failed   int                             `json:"failed"`
+	Results  []services.TextImportLineResult `json:"results"`
+}
+
+// ImportText creates inventory items from a pasted plain-text list,
+// one card per line (e.g. "4 Lightning Bolt (M10)")
+func (h *InventoryHandler) ImportText(c fiber.Ctx) error {
+	var req ImportTextRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if err := utils.ValidateRequired(req.Text, "text"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if req.StorageLocationID != nil {
+		var location models.StorageLocation
+		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to validate storage location", "storage location lookup failed", err)
+		}
+	}
+
+	importer := services.NewTextImportService(h.db, h.autoSortSvc)
+	results, err := importer.Import(c.RequestCtx(), req.Text, req.StorageLocationID)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyLines) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to update inventory locations", "resort transaction failed", txErr)
+			"Failed to import pasted list", "text import failed", err)
+	}
+
+	response := ImportTextResponse{Results: results}
+	for _, result := range results {
+		if result.Status == services.TextImportStatusImported {
+			response.Imported++
+		} else {
+			response.Failed++
+		}
 	}
 
-	slog.Info("resort completed", "component", "resort", "processed", eval.processed, "updated", updated, "errors", eval.errors)
+	slog.InfoContext(c.RequestCtx(), "imported pasted list", "component", "inventory", "imported", response.Imported, "failed", response.Failed)
+	if response.Imported > 0 {
+		change := realtime.InventoryChange{}
+		for _, result := range results {
+			if result.InventoryID != 0 {
+				change.IDs = append(change.IDs, result.InventoryID)
+			}
+		}
+		h.hub.Publish(realtime.EventInventoryCreated, change)
+	}
 
-	return c.JSON(ResortResponse{
-		Processed: eval.processed,
-		Updated:   updated,
-		Errors:    eval.errors,
-		Movements: eval.movements,
-	})
+	return c.JSON(response)
 }
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"backend/models"
+	"backend/realtime"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// BatchAddRequest represents the request body for adding many cards by identifier
+// tygo:export
+type BatchAddRequest struct {
+	Entries           []services.BatchAddEntry `json:"entries"`
+	StorageLocationID *uint                    `json:"storage_location_id,omitempty"` // If nil, sorting rules assign locations
+}
+
+// BatchAddResponse represents the per-entry outcome of a batch add
+// tygo:export
+type BatchAddResponse struct {
+	Added   int                       `json:"added"`
+	Failed  int                       `json:"failed"`
+	Results []services.BatchAddResult `json:"results"`
+}
+
+// BatchAdd creates inventory for up to services.MaxBatchAddEntries cards identified by
+// Scryfall ID, set and collector number, or name, so a stack of new purchases takes one
+// request rather than one per card
+func (h *InventoryHandler) BatchAdd(c fiber.Ctx) error {
+	var req BatchAddRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if len(req.Entries) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "entries array is required")
+	}
+
+	if req.StorageLocationID != nil {
+		var location models.StorageLocation
+		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to validate storage location", "storage location lookup failed", err)
+		}
+	}
+
+	results, err := services.NewBatchAddService(h.db).Add(c.RequestCtx(), req.Entries, req.StorageLocationID)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyBatchAddEntries) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to add cards", "batch add failed", err)
+	}
+
+	response := BatchAddResponse{Results: results}
+	change := realtime.InventoryChange{}
+	for _, result := range results {
+		if result.Status == services.BatchAddStatusAdded {
+			response.Added++
+			change.IDs = append(change.IDs, result.InventoryID)
+		} else {
+			response.Failed++
+		}
+	}
+
+	slog.InfoContext(c.RequestCtx(), "batch added cards", "component", "inventory", "added", response.Added, "failed", response.Failed)
+	if response.Added > 0 {
+		h.hub.Publish(realtime.EventInventoryCreated, change)
+	}
+
+	return c.JSON(response)
+}
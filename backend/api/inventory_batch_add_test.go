@@ -0,0 +1,105 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInventoryBatchAdd_AutoSort(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestCard(t, db, "lotus-id", "Black Lotus", "lea", "rare", "20000")
+	createTestSortingRule(t, db, "Cheap Cards", 1, "prices.usd < 5.0", location.ID)
+
+	body := `{"entries": [
+		{"scryfall_id": "bolt-id", "quantity": 4},
+		{"name": "black lotus", "treatment": "foil"},
+		{"name": "Missing Card"},
+		{"quantity": 2}
+	]}`
+	req := httptest.NewRequest(http.MethodPost, "/inventory/batch/add", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result BatchAddResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.Added != 2 || result.Failed != 2 {
+		t.Errorf("expected 2 added and 2 failed, got %d and %d", result.Added, result.Failed)
+	}
+	expected := []services.BatchAddStatus{
+		services.BatchAddStatusAdded, services.BatchAddStatusAdded, services.BatchAddStatusNotFound, services.BatchAddStatusInvalid,
+	}
+	if len(result.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %+v", len(expected), result.Results)
+	}
+	for i, status := range expected {
+		if result.Results[i].Index != i || result.Results[i].Status != status {
+			t.Errorf("entry %d: expected %s, got %+v", i, status, result.Results[i])
+		}
+	}
+
+	// The cheap card is sorted by the rule; the expensive one matches no rule
+	var bolt, lotus models.Inventory
+	db.First(&bolt, result.Results[0].InventoryID)
+	db.First(&lotus, result.Results[1].InventoryID)
+	if bolt.Quantity != 4 || bolt.Treatment != "nonfoil" || bolt.StorageLocationID == nil || *bolt.StorageLocationID != location.ID {
+		t.Errorf("expected 4 nonfoil bolts sorted into location %d, got %+v", location.ID, bolt)
+	}
+	if lotus.Quantity != 1 || lotus.Treatment != "foil" || lotus.StorageLocationID != nil {
+		t.Errorf("expected 1 unassigned foil lotus, got %+v", lotus)
+	}
+}
+
+func TestInventoryBatchAdd_Validation(t *testing.T) {
+	app, _ := setupInventoryTestAppWithRules(t)
+
+	tooMany := make([]string, services.MaxBatchAddEntries+1)
+	for i := range tooMany {
+		tooMany[i] = `{"name": "Lightning Bolt"}`
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Invalid JSON", `{`},
+		{"No entries", `{"entries": []}`},
+		{"Too many entries", fmt.Sprintf(`{"entries": [%s]}`, strings.Join(tooMany, ","))},
+		{"Unknown location", `{"entries": [{"name": "Lightning Bolt"}], "storage_location_id": 999}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/inventory/batch/add", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
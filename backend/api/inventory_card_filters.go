@@ -0,0 +1,200 @@
+package api
+
+import (
+	"backend/models"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// validIdentityColors are the letters accepted by the color_identity filter
+var validIdentityColors = []string{"w", "u", "b", "r", "g", "c"}
+
+// inventoryCardSortColumns maps the sort param of GET /inventory/cards to its ORDER BY
+// expression and default direction. Card attributes are correlated subqueries so rows
+// without card data still sort, after the rest.
+var inventoryCardSortColumns = map[string]struct {
+	expression string
+	desc       bool
+}{
+	"added": {"inventories.created_at", true},
+	"name":  {"(SELECT name FROM cards WHERE cards.scryfall_id = inventories.scryfall_id)", false},
+	"price": {"", true}, // Depends on the currency; see inventoryPriceSQL
+}
+
+// inventoryCardFilters holds the card attribute filters and sort order of
+// GET /inventory/cards, applied in SQL on the extracted card columns
+type inventoryCardFilters struct {
+	Name          string
+	SetCode       string
+	Rarities      []string
+	ColorIdentity []string // Letters the card's color identity must fall within; "c" for colorless only
+	Treatment     string
+	PriceMin      *float64
+	PriceMax      *float64
+	Sort          string
+	Descending    bool
+}
+
+// parseInventoryCardFilters reads and validates the name, set, rarity, color_identity,
+// treatment, price_min, price_max, sort, and order query params
+func parseInventoryCardFilters(c fiber.Ctx) (inventoryCardFilters, error) {
+	filters := inventoryCardFilters{
+		Name:      strings.TrimSpace(c.Query("name")),
+		SetCode:   strings.ToLower(strings.TrimSpace(c.Query("set"))),
+		Treatment: strings.ToLower(strings.TrimSpace(c.Query("treatment"))),
+		Sort:      c.Query("sort", "added"),
+	}
+	if filters.Treatment != "" && !printFilterValuePattern.MatchString(filters.Treatment) {
+		return filters, fmt.Errorf("invalid treatment")
+	}
+
+	for _, rarity := range strings.Split(strings.ToLower(c.Query("rarity")), ",") {
+		rarity = strings.TrimSpace(rarity)
+		if rarity == "" {
+			continue
+		}
+		if !slices.Contains(validSearchRarities, rarity) {
+			return filters, fmt.Errorf("invalid rarity %q", rarity)
+		}
+		filters.Rarities = append(filters.Rarities, rarity)
+	}
+
+	for _, color := range strings.Split(strings.ToLower(c.Query("color_identity")), "") {
+		if color == "" {
+			continue
+		}
+		if !slices.Contains(validIdentityColors, color) {
+			return filters, fmt.Errorf("invalid color_identity %q", color)
+		}
+		filters.ColorIdentity = append(filters.ColorIdentity, strings.ToUpper(color))
+	}
+	if slices.Contains(filters.ColorIdentity, "C") && len(filters.ColorIdentity) > 1 {
+		return filters, fmt.Errorf("colorless cannot be combined with other colors")
+	}
+
+	ranges := []struct {
+		name   string
+		target **float64
+	}{
+		{"price_min", &filters.PriceMin},
+		{"price_max", &filters.PriceMax},
+	}
+	for _, r := range ranges {
+		value := c.Query(r.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			return filters, fmt.Errorf("%s must be a non-negative number", r.name)
+		}
+		*r.target = &parsed
+	}
+
+	column, ok := inventoryCardSortColumns[filters.Sort]
+	if !ok {
+		return filters, fmt.Errorf("sort must be added, name, or price")
+	}
+	switch order := c.Query("order"); order {
+	case "":
+		filters.Descending = column.desc
+	case "asc", "desc":
+		filters.Descending = order == "desc"
+	default:
+		return filters, fmt.Errorf("order must be asc or desc")
+	}
+
+	return filters, nil
+}
+
+// apply narrows an inventory query to rows whose card matches the filters. Prices
+// are compared in currency, for each row's treatment.
+func (f inventoryCardFilters) apply(query *gorm.DB, currency models.Currency) *gorm.DB {
+	if f.Treatment != "" {
+		query = query.Where("inventories.treatment = ?", f.Treatment)
+	}
+
+	var conditions []string
+	var args []any
+	if f.Name != "" {
+		conditions = append(conditions, `name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+noteLikeEscaper.Replace(f.Name)+"%")
+	}
+	if f.SetCode != "" {
+		conditions = append(conditions, "set_code = ?")
+		args = append(args, f.SetCode)
+	}
+	if len(f.Rarities) > 0 {
+		conditions = append(conditions, "rarity IN ?")
+		args = append(args, f.Rarities)
+	}
+	if len(f.ColorIdentity) > 0 {
+		allowed := slices.DeleteFunc(slices.Clone(f.ColorIdentity), func(color string) bool { return color == "C" })
+		if len(allowed) == 0 {
+			conditions = append(conditions, "COALESCE(json_array_length(raw_json, '$.color_identity'), 0) = 0")
+		} else {
+			conditions = append(conditions, "NOT EXISTS (SELECT 1 FROM json_each(raw_json, '$.color_identity') WHERE value NOT IN ?)")
+			args = append(args, allowed)
+		}
+	}
+	if len(conditions) > 0 {
+		query = query.Where("inventories.scryfall_id IN (SELECT scryfall_id FROM cards WHERE "+strings.Join(conditions, " AND ")+")", args...)
+	}
+
+	if f.PriceMin != nil || f.PriceMax != nil {
+		price := inventoryPriceSQL(currency)
+		if f.PriceMin != nil {
+			query = query.Where(price+" >= ?", *f.PriceMin)
+		}
+		if f.PriceMax != nil {
+			query = query.Where(price+" <= ?", *f.PriceMax)
+		}
+	}
+	return query
+}
+
+// order returns the ORDER BY clause for the sort, with the newest row as tiebreaker
+func (f inventoryCardFilters) order(currency models.Currency) string {
+	expression := inventoryCardSortColumns[f.Sort].expression
+	if f.Sort == "price" {
+		expression = inventoryPriceSQL(currency)
+	}
+	direction := "ASC"
+	if f.Descending {
+		direction = "DESC"
+	}
+	if f.Sort == "added" {
+		return fmt.Sprintf("%s %s, inventories.id %s", expression, direction, direction)
+	}
+	return fmt.Sprintf("%s IS NULL, %s %s, inventories.created_at DESC, inventories.id DESC", expression, expression, direction)
+}
+
+// inventoryPriceSQL is a correlated subquery for the per-copy price of an inventory row
+// in currency, for its treatment. Mirrors models.CardPrices.InCurrency: a treatment
+// without a price falls back to the nonfoil price, other treatments try foil first, and
+// unpriced cards count as 0.
+func inventoryPriceSQL(currency models.Currency) string {
+	return fmt.Sprintf("COALESCE(%s, 0)", inventoryPriceOrNullSQL(currency))
+}
+
+// inventoryPriceOrNullSQL is inventoryPriceSQL without the fallback, so unpriced cards
+// are NULL
+func inventoryPriceOrNullSQL(currency models.Currency) string {
+	nonfoil, foil, etched := "price_usd", "price_usd_foil", "price_usd_etched"
+	switch currency {
+	case models.CurrencyEUR:
+		nonfoil, foil, etched = "price_eur", "price_eur_foil", "NULL"
+	case models.CurrencyTIX:
+		nonfoil, foil, etched = "price_tix", "NULL", "NULL"
+	}
+	return fmt.Sprintf(`(SELECT CASE inventories.treatment
+		WHEN 'nonfoil' THEN %[1]s
+		WHEN 'etched' THEN COALESCE(%[3]s, %[1]s)
+		ELSE COALESCE(%[2]s, %[1]s) END
+		FROM cards WHERE cards.scryfall_id = inventories.scryfall_id)`, nonfoil, foil, etched)
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupCardFiltersTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	app.Get("/inventory/cards", handler.ListAsCards)
+	return app, db
+}
+
+// createFilterTestCard stores a card with the attributes the filters read
+func createFilterTestCard(t *testing.T, db *gorm.DB, id, name, set, rarity, identity, usd, usdFoil string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: id,
+		OracleID:   "oracle-" + id,
+		RawJSON: fmt.Sprintf(`{"id": "%s", "name": "%s", "set": "%s", "rarity": "%s", "color_identity": %s,
+			"prices": {"usd": "%s", "usd_foil": "%s"}}`, id, name, set, rarity, identity, usd, usdFoil),
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func listCardNames(t *testing.T, app *fiber.App, query string, expectedStatus int) []string {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/cards?"+query, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("%s: expected status %d, got %d", query, expectedStatus, resp.StatusCode)
+	}
+	if expectedStatus != http.StatusOK {
+		return nil
+	}
+
+	var result InventoryCardsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	names := make([]string, 0, len(result.Data))
+	for _, card := range result.Data {
+		names = append(names, card.Name)
+	}
+	return names
+}
+
+func TestListAsCards_CardFilters(t *testing.T) {
+	app, db := setupCardFiltersTestApp(t)
+	createFilterTestCard(t, db, "bolt", "Lightning Bolt", "lea", "common", `["R"]`, "100.00", "")
+	createFilterTestCard(t, db, "helix", "Lightning Helix", "rav", "uncommon", `["R", "W"]`, "0.50", "8.00")
+	createFilterTestCard(t, db, "counterspell", "Counterspell", "lea", "uncommon", `["U"]`, "40.00", "")
+	createFilterTestCard(t, db, "sol-ring", "Sol Ring", "c21", "uncommon", `[]`, "1.50", "")
+
+	added := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, row := range []struct{ id, treatment string }{
+		{"bolt", "nonfoil"}, {"helix", "foil"}, {"counterspell", "nonfoil"}, {"sol-ring", "nonfoil"},
+	} {
+		item := models.Inventory{ScryfallID: row.id, OracleID: "oracle-" + row.id, Treatment: row.treatment, Quantity: 1}
+		item.CreatedAt = added.Add(time.Duration(i) * time.Hour)
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"Sol Ring", "Counterspell", "Lightning Helix", "Lightning Bolt"}},
+		{"name=lightning", []string{"Lightning Helix", "Lightning Bolt"}},
+		{"name=%25", nil}, // Wildcards match literally
+		{"set=LEA&sort=name", []string{"Counterspell", "Lightning Bolt"}},
+		{"rarity=uncommon,common&sort=name&order=desc", []string{"Sol Ring", "Lightning Helix", "Lightning Bolt", "Counterspell"}},
+		{"color_identity=r&sort=name", []string{"Lightning Bolt", "Sol Ring"}},
+		{"color_identity=rw&sort=name", []string{"Lightning Bolt", "Lightning Helix", "Sol Ring"}},
+		{"color_identity=c", []string{"Sol Ring"}},
+		{"treatment=foil", []string{"Lightning Helix"}},
+		// The foil Helix is priced at its foil price
+		{"price_min=5&price_max=50&sort=price", []string{"Counterspell", "Lightning Helix"}},
+		{"sort=price&order=asc", []string{"Sol Ring", "Lightning Helix", "Counterspell", "Lightning Bolt"}},
+		{"sort=added&order=asc", []string{"Lightning Bolt", "Lightning Helix", "Counterspell", "Sol Ring"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := listCardNames(t, app, tt.query, http.StatusOK)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	for _, query := range []string{
+		"rarity=legendary", "color_identity=x", "color_identity=cr", "price_min=-1",
+		"price_max=cheap", "sort=color", "order=up", "treatment=foil%20etched",
+	} {
+		listCardNames(t, app, query, http.StatusBadRequest)
+	}
+}
+
+func TestListAsCards_PriceInPreferredCurrency(t *testing.T) {
+	app, db := setupCardFiltersTestApp(t)
+	card := models.Card{
+		ScryfallID: "bolt",
+		OracleID:   "oracle-bolt",
+		RawJSON:    `{"id": "bolt", "name": "Lightning Bolt", "set": "lea", "prices": {"usd": "100.00", "eur": "5.00"}}`,
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+	if err := db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "etched", Quantity: 1}).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	if got := listCardNames(t, app, "price_max=10", http.StatusOK); len(got) != 0 {
+		t.Errorf("expected the USD price to exceed 10, got %v", got)
+	}
+	if err := services.NewSettingsService(db).Set(t.Context(), "preferred_currency", "eur"); err != nil {
+		t.Fatalf("failed to set currency: %v", err)
+	}
+	// Scryfall has no etched EUR price, so the nonfoil EUR price applies
+	if got := listCardNames(t, app, "price_max=10", http.StatusOK); len(got) != 1 {
+		t.Errorf("expected the EUR price within 10, got %v", got)
+	}
+}
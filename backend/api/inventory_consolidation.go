@@ -0,0 +1,78 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const (
+	// defaultConsolidationLimit and maxConsolidationLimit bound how many scattered
+	// printings a consolidation request returns
+	defaultConsolidationLimit = 100
+	maxConsolidationLimit     = 500
+)
+
+// ConsolidationSuggestionsResponse lists scattered printings and a move plan that
+// gathers each into its suggested location
+// tygo:export
+type ConsolidationSuggestionsResponse struct {
+	Suggestions []services.ConsolidationSuggestion `json:"suggestions"`
+	// Plan holds one request per target location, ready to send to POST /inventory/batch/move
+	Plan []BatchMoveRequest `json:"plan"`
+}
+
+// ConsolidationSuggestions finds printings spread across several storage locations
+// and suggests where to gather each, with a batch move plan to do it. Query params
+// min_locations (default 2) and limit (default 100, max 500).
+func (h *InventoryHandler) ConsolidationSuggestions(c fiber.Ctx) error {
+	minLocations := fiber.Query[int](c, "min_locations", 2)
+	if minLocations < 2 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "min_locations must be at least 2")
+	}
+	limit := fiber.Query[int](c, "limit", defaultConsolidationLimit)
+	if limit < 1 || limit > maxConsolidationLimit {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("limit must be between 1 and %d", maxConsolidationLimit))
+	}
+
+	suggestions, err := h.autoSortSvc.SuggestConsolidations(c.RequestCtx(), minLocations, limit)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to suggest consolidations", "consolidation failed", err)
+	}
+
+	return c.JSON(ConsolidationSuggestionsResponse{
+		Suggestions: suggestions,
+		Plan:        consolidationPlan(suggestions),
+	})
+}
+
+// consolidationPlan groups the suggested moves by target location in first-suggested
+// order, splitting requests that would exceed MaxBatchIDs
+func consolidationPlan(suggestions []services.ConsolidationSuggestion) []BatchMoveRequest {
+	var order []uint
+	idsByTarget := map[uint][]uint{}
+	for _, suggestion := range suggestions {
+		target := suggestion.Target.StorageLocationID
+		if _, ok := idsByTarget[target]; !ok {
+			order = append(order, target)
+		}
+		idsByTarget[target] = append(idsByTarget[target], suggestion.MoveIDs...)
+	}
+
+	plan := []BatchMoveRequest{}
+	for _, target := range order {
+		ids := idsByTarget[target]
+		for start := 0; start < len(ids); start += MaxBatchIDs {
+			locationID := target
+			plan = append(plan, BatchMoveRequest{
+				IDs:               ids[start:min(start+MaxBatchIDs, len(ids))],
+				StorageLocationID: &locationID,
+			})
+		}
+	}
+	return plan
+}
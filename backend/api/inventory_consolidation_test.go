@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestConsolidationSuggestions(t *testing.T) {
+	_, db := setupInventoryTestAppWithRules(t)
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	app.Get("/inventory/consolidation-suggestions", handler.ConsolidationSuggestions)
+
+	location := createTestStorageLocation(t, db)
+	createTestSortingRule(t, db, "Red cards", 1, `hasColor("R")`, location.ID)
+	other := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt", "Lightning Bolt", "lea", "common", "1.00")
+	createTestInventoryItem(t, db, "bolt", 1, &location.ID)
+	scattered := createTestInventoryItem(t, db, "bolt", 2, &other.ID)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/consolidation-suggestions", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result ConsolidationSuggestionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Suggestions) != 1 || result.Suggestions[0].Target.StorageLocationID != location.ID {
+		t.Fatalf("expected one suggestion targeting location %d, got %+v", location.ID, result.Suggestions)
+	}
+	if len(result.Plan) != 1 {
+		t.Fatalf("expected one batch move, got %+v", result.Plan)
+	}
+	move := result.Plan[0]
+	if move.StorageLocationID == nil || *move.StorageLocationID != location.ID || len(move.IDs) != 1 || move.IDs[0] != scattered.ID {
+		t.Errorf("expected row %d moved to %d, got %+v", scattered.ID, location.ID, move)
+	}
+}
+
+func TestConsolidationSuggestions_InvalidParams(t *testing.T) {
+	_, db := setupInventoryTestAppWithRules(t)
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	app.Get("/inventory/consolidation-suggestions", handler.ConsolidationSuggestions)
+
+	for _, query := range []string{"?min_locations=1", "?limit=0", "?limit=501"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/consolidation-suggestions"+query, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestConsolidationPlan(t *testing.T) {
+	suggestions := []services.ConsolidationSuggestion{
+		{Target: services.SuggestedLocation{StorageLocationID: 1}, MoveIDs: []uint{10, 11}},
+		{Target: services.SuggestedLocation{StorageLocationID: 2}, MoveIDs: []uint{20}},
+		{Target: services.SuggestedLocation{StorageLocationID: 1}, MoveIDs: []uint{12}},
+	}
+
+	plan := consolidationPlan(suggestions)
+	if len(plan) != 2 {
+		t.Fatalf("expected one move per target, got %+v", plan)
+	}
+	if *plan[0].StorageLocationID != 1 || len(plan[0].IDs) != 3 || *plan[1].StorageLocationID != 2 {
+		t.Errorf("unexpected plan: %+v", plan)
+	}
+}
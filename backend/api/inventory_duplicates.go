@@ -0,0 +1,296 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"cmp"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// duplicatesCSVHeader lists the columns of a duplicates export, one row per printing
+// and storage location
+var duplicatesCSVHeader = []string{
+	"name", "oracle_id", "total_quantity", "excess_quantity", "set_code", "collector_number",
+	"scryfall_id", "treatment", "storage_location", "quantity", "price", "value",
+}
+
+// DuplicateLocation is where copies of a duplicated printing are stored
+// tygo:export
+type DuplicateLocation struct {
+	StorageLocationID   *uint  `json:"storage_location_id,omitempty"` // nil for unassigned copies
+	StorageLocationName string `json:"storage_location_name,omitempty"`
+	Quantity            int    `json:"quantity"`
+}
+
+// DuplicatePrinting is one owned printing and treatment of a duplicated card
+// tygo:export
+type DuplicatePrinting struct {
+	ScryfallID      string              `json:"scryfall_id"`
+	SetCode         string              `json:"set_code"`
+	CollectorNumber string              `json:"collector_number"`
+	Treatment       string              `json:"treatment"`
+	Quantity        int                 `json:"quantity"`
+	Price           float64             `json:"price"` // Per copy, in the report's currency
+	Value           float64             `json:"value"`
+	Locations       []DuplicateLocation `json:"locations"`
+}
+
+// DuplicateCard is a card owned in more copies than the threshold, across all printings
+// tygo:export
+type DuplicateCard struct {
+	OracleID       string              `json:"oracle_id"`
+	Name           string              `json:"name"`
+	TotalQuantity  int                 `json:"total_quantity"`
+	ExcessQuantity int                 `json:"excess_quantity"` // Copies above the threshold
+	TotalValue     float64             `json:"total_value"`
+	Printings      []DuplicatePrinting `json:"printings"`
+}
+
+// DuplicatesResponse lists the cards owned in more copies than the threshold, the
+// candidates to trade away
+// tygo:export
+type DuplicatesResponse struct {
+	Threshold          int             `json:"threshold"`
+	Currency           models.Currency `json:"currency"` // Currency the values are in (preferred_currency)
+	ExcludesBasicLands bool            `json:"excludes_basic_lands"`
+	Cards              []DuplicateCard `json:"cards"`
+	TotalValue         float64         `json:"total_value"`
+}
+
+// duplicateRow is one inventory row of a duplicated card
+type duplicateRow struct {
+	OracleID            string
+	ScryfallID          string
+	Treatment           string
+	Quantity            int
+	StorageLocationID   *uint
+	Name                string
+	SetCode             string
+	CollectorNumber     string
+	StorageLocationName string
+}
+
+// Duplicates lists cards (by oracle ID) owned in more copies than the threshold query
+// param, which defaults to the duplicates_threshold setting, most copies first
+func (h *InventoryHandler) Duplicates(c fiber.Ctx) error {
+	report, err := h.duplicatesReport(c)
+	if err != nil || report == nil {
+		return err
+	}
+	return c.JSON(report)
+}
+
+// ExportDuplicates downloads the duplicates report as CSV (format query parameter; csv
+// is the only format), one row per printing and storage location
+func (h *InventoryHandler) ExportDuplicates(c fiber.Ctx) error {
+	if format := c.Query("format", "csv"); format != "csv" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("invalid format %q (expected csv)", format))
+	}
+
+	report, err := h.duplicatesReport(c)
+	if err != nil || report == nil {
+		return err
+	}
+
+	path, err := h.exports.renderFile("duplicates", ".csv", func(w io.Writer) error {
+		return writeDuplicatesCSV(w, report)
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to export duplicates", "duplicates export failed", err)
+	}
+
+	filename := fmt.Sprintf("duplicates-%s.csv", time.Now().UTC().Format(time.DateOnly))
+	return sendExportFile(c, path, filename, "text/csv; charset=utf-8")
+}
+
+// duplicatesReport builds the report for the request's threshold. When it returns a
+// nil report the error response has already been written and err should be returned.
+func (h *InventoryHandler) duplicatesReport(c fiber.Ctx) (*DuplicatesResponse, error) {
+	ctx := c.RequestCtx()
+
+	threshold := services.DuplicatesThreshold(ctx, h.db)
+	if raw := c.Query("threshold"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			return nil, utils.ReturnError(c, fiber.StatusBadRequest, "threshold must be a whole number of at least 1")
+		}
+		threshold = parsed
+	}
+	excludeBasics := services.BasicLandsExcluded(ctx, h.db, "duplicates_exclude_basic_lands")
+
+	owned := h.db.WithContext(ctx).Model(&models.Inventory{}).
+		Select("oracle_id").
+		Group("oracle_id").
+		Having("SUM(quantity) > ?", threshold)
+	if excludeBasics {
+		owned = owned.Scopes(models.ExcludeBasicLands)
+	}
+
+	query := h.db.WithContext(ctx).Table("inventories AS i").
+		Select(`i.oracle_id, i.scryfall_id, COALESCE(i.treatment, '') AS treatment, i.quantity, i.storage_location_id,
+			COALESCE(c.name, '') AS name,
+			COALESCE(c.set_code, '') AS set_code,
+			COALESCE(c.collector_number, '') AS collector_number,
+			COALESCE(sl.name, '') AS storage_location_name`).
+		Joins("LEFT JOIN cards c ON c.scryfall_id = i.scryfall_id").
+		Joins("LEFT JOIN storage_locations sl ON sl.id = i.storage_location_id").
+		// Every printing of a card shares its type line, so excluding basic lands above
+		// leaves out whole cards
+		Where("i.deleted_at IS NULL AND i.oracle_id IN (?)", owned)
+	var rows []duplicateRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch duplicates", "database query failed", err)
+	}
+
+	scryfallIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		scryfallIDs = append(scryfallIDs, row.ScryfallID)
+	}
+	prices, err := models.GetCardPricesByIDs(h.db.WithContext(ctx), scryfallIDs)
+	if err != nil {
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card prices", "price query failed", err)
+	}
+
+	currency := services.PreferredCurrency(ctx, h.db)
+	report := &DuplicatesResponse{
+		Threshold:          threshold,
+		Currency:           currency,
+		ExcludesBasicLands: excludeBasics,
+		Cards:              groupDuplicates(rows, prices, threshold, currency),
+	}
+	for _, card := range report.Cards {
+		report.TotalValue += card.TotalValue
+	}
+	return report, nil
+}
+
+// groupDuplicates groups inventory rows by card, printing and treatment, and storage
+// location. Cards come most copies first, their printings and locations likewise.
+func groupDuplicates(rows []duplicateRow, prices map[string]models.CardPrices, threshold int, currency models.Currency) []DuplicateCard {
+	type printingKey struct{ scryfallID, treatment string }
+
+	cards := make(map[string]*DuplicateCard)
+	printings := make(map[printingKey]*DuplicatePrinting)
+	printingsByCard := make(map[string][]printingKey)
+	for _, row := range rows {
+		card, ok := cards[row.OracleID]
+		if !ok {
+			card = &DuplicateCard{OracleID: row.OracleID, Name: row.Name}
+			cards[row.OracleID] = card
+		}
+		key := printingKey{row.ScryfallID, row.Treatment}
+		printing, ok := printings[key]
+		if !ok {
+			printing = &DuplicatePrinting{
+				ScryfallID:      row.ScryfallID,
+				SetCode:         row.SetCode,
+				CollectorNumber: row.CollectorNumber,
+				Treatment:       row.Treatment,
+				Price:           prices[row.ScryfallID].InCurrency(row.Treatment, currency),
+			}
+			printings[key] = printing
+			printingsByCard[row.OracleID] = append(printingsByCard[row.OracleID], key)
+		}
+
+		value := printing.Price * float64(row.Quantity)
+		card.TotalQuantity += row.Quantity
+		card.TotalValue += value
+		printing.Quantity += row.Quantity
+		printing.Value += value
+		printing.Locations = addDuplicateLocation(printing.Locations, row)
+	}
+
+	result := make([]DuplicateCard, 0, len(cards))
+	for oracleID, card := range cards {
+		card.ExcessQuantity = card.TotalQuantity - threshold
+		for _, key := range printingsByCard[oracleID] {
+			printing := printings[key]
+			slices.SortFunc(printing.Locations, func(a, b DuplicateLocation) int {
+				return cmp.Or(cmp.Compare(b.Quantity, a.Quantity), strings.Compare(a.StorageLocationName, b.StorageLocationName))
+			})
+			card.Printings = append(card.Printings, *printing)
+		}
+		slices.SortFunc(card.Printings, func(a, b DuplicatePrinting) int {
+			return cmp.Or(
+				cmp.Compare(b.Quantity, a.Quantity),
+				strings.Compare(a.SetCode, b.SetCode),
+				strings.Compare(a.CollectorNumber, b.CollectorNumber),
+				strings.Compare(a.Treatment, b.Treatment),
+			)
+		})
+		result = append(result, *card)
+	}
+	slices.SortFunc(result, func(a, b DuplicateCard) int {
+		return cmp.Or(
+			cmp.Compare(b.TotalQuantity, a.TotalQuantity),
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.OracleID, b.OracleID),
+		)
+	})
+	return result
+}
+
+// addDuplicateLocation adds a row's copies to the location already holding that
+// printing, or appends the location
+func addDuplicateLocation(locations []DuplicateLocation, row duplicateRow) []DuplicateLocation {
+	for i, location := range locations {
+		sameLocation := location.StorageLocationID == nil && row.StorageLocationID == nil ||
+			location.StorageLocationID != nil && row.StorageLocationID != nil && *location.StorageLocationID == *row.StorageLocationID
+		if sameLocation {
+			locations[i].Quantity += row.Quantity
+			return locations
+		}
+	}
+	return append(locations, DuplicateLocation{
+		StorageLocationID:   row.StorageLocationID,
+		StorageLocationName: row.StorageLocationName,
+		Quantity:            row.Quantity,
+	})
+}
+
+// writeDuplicatesCSV writes the report in duplicatesCSVHeader order; unassigned
+// copies have an empty storage_location
+func writeDuplicatesCSV(w io.Writer, report *DuplicatesResponse) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(duplicatesCSVHeader); err != nil {
+		return err
+	}
+	for _, card := range report.Cards {
+		for _, printing := range card.Printings {
+			price := strconv.FormatFloat(printing.Price, 'f', 2, 64)
+			for _, location := range printing.Locations {
+				record := []string{
+					card.Name,
+					card.OracleID,
+					strconv.Itoa(card.TotalQuantity),
+					strconv.Itoa(card.ExcessQuantity),
+					printing.SetCode,
+					printing.CollectorNumber,
+					printing.ScryfallID,
+					printing.Treatment,
+					location.StorageLocationName,
+					strconv.Itoa(location.Quantity),
+					price,
+					strconv.FormatFloat(printing.Price*float64(location.Quantity), 'f', 2, 64),
+				}
+				if err := writer.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
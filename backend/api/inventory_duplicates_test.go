@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupDuplicatesTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	_, db := setupInventoryTestAppWithRules(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), ""))
+	app.Get("/inventory/duplicates", handler.Duplicates)
+	app.Get("/inventory/duplicates/export", handler.ExportDuplicates)
+	return app, db
+}
+
+// createDuplicatesFixture owns 5 Lightning Bolts across two printings and locations,
+// 4 Counterspells, and 1 trashed Counterspell
+func createDuplicatesFixture(t *testing.T, db *gorm.DB) models.StorageLocation {
+	t.Helper()
+	createTestCard(t, db, "bolt-lea", "Lightning Bolt", "lea", "common", "100.00")
+	createTestCard(t, db, "bolt-m10", "Lightning Bolt", "m10", "common", "1.00")
+	createTestCard(t, db, "counterspell", "Counterspell", "lea", "uncommon", "50.00")
+
+	binder := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	if err := db.Create(&binder).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	rows := []models.Inventory{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3, StorageLocationID: &binder.ID},
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 1},
+		{ScryfallID: "bolt-lea", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 1, StorageLocationID: &binder.ID},
+		{ScryfallID: "counterspell", OracleID: "oracle-counterspell", Treatment: "nonfoil", Quantity: 4},
+		{ScryfallID: "counterspell", OracleID: "oracle-counterspell", Treatment: "nonfoil", Quantity: 1},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	if err := db.Delete(&rows[4]).Error; err != nil {
+		t.Fatalf("failed to trash inventory: %v", err)
+	}
+	return binder
+}
+
+func getDuplicates(t *testing.T, app *fiber.App, path string) DuplicatesResponse {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result DuplicatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return result
+}
+
+func TestDuplicates(t *testing.T) {
+	app, db := setupDuplicatesTestApp(t)
+	binder := createDuplicatesFixture(t, db)
+
+	result := getDuplicates(t, app, "/inventory/duplicates")
+	if result.Threshold != services.DefaultDuplicatesThreshold {
+		t.Errorf("expected the default threshold, got %d", result.Threshold)
+	}
+	// The trashed Counterspell doesn't count, leaving exactly 4
+	if len(result.Cards) != 1 {
+		t.Fatalf("expected only Lightning Bolt, got %+v", result.Cards)
+	}
+
+	bolt := result.Cards[0]
+	if bolt.Name != "Lightning Bolt" || bolt.TotalQuantity != 5 || bolt.ExcessQuantity != 1 {
+		t.Errorf("unexpected card: %+v", bolt)
+	}
+	if bolt.TotalValue != 104 || result.TotalValue != 104 {
+		t.Errorf("expected total value 104, got %v and %v", bolt.TotalValue, result.TotalValue)
+	}
+	if len(bolt.Printings) != 2 || bolt.Printings[0].ScryfallID != "bolt-m10" || bolt.Printings[0].Quantity != 4 {
+		t.Fatalf("expected the m10 printing first with 4 copies, got %+v", bolt.Printings)
+	}
+	locations := bolt.Printings[0].Locations
+	if len(locations) != 2 || locations[0].StorageLocationName != "Binder" || locations[0].Quantity != 3 ||
+		*locations[0].StorageLocationID != binder.ID || locations[1].StorageLocationID != nil || locations[1].Quantity != 1 {
+		t.Errorf("expected 3 in the binder and 1 unassigned, got %+v", locations)
+	}
+
+	if result := getDuplicates(t, app, "/inventory/duplicates?threshold=3"); len(result.Cards) != 2 || result.Cards[1].Name != "Counterspell" {
+		t.Errorf("expected both cards above a threshold of 3, got %+v", result.Cards)
+	}
+
+	if err := services.NewSettingsService(db).Set(t.Context(), "duplicates_threshold", "5"); err != nil {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	if result := getDuplicates(t, app, "/inventory/duplicates"); result.Threshold != 5 || len(result.Cards) != 0 {
+		t.Errorf("expected nothing above the threshold setting of 5, got %+v", result)
+	}
+
+	for _, path := range []string{"/inventory/duplicates?threshold=0", "/inventory/duplicates?threshold=many"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", path, http.StatusBadRequest, resp.StatusCode)
+		}
+	}
+}
+
+func TestDuplicates_ExcludeBasicLands(t *testing.T) {
+	app, db := setupDuplicatesTestApp(t)
+	if err := db.Create(&models.Card{
+		ScryfallID: "forest",
+		OracleID:   "oracle-forest",
+		RawJSON:    `{"id": "forest", "name": "Forest", "type_line": "Basic Land — Forest", "prices": {"usd": "0.10"}}`,
+	}).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+	if err := db.Create(&models.Inventory{ScryfallID: "forest", OracleID: "oracle-forest", Treatment: "nonfoil", Quantity: 40}).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	if result := getDuplicates(t, app, "/inventory/duplicates"); len(result.Cards) != 1 {
+		t.Fatalf("expected the forests listed, got %+v", result.Cards)
+	}
+	if err := services.NewSettingsService(db).Set(t.Context(), "duplicates_exclude_basic_lands", "true"); err != nil {
+		t.Fatalf("failed to set setting: %v", err)
+	}
+	if result := getDuplicates(t, app, "/inventory/duplicates"); len(result.Cards) != 0 || !result.ExcludesBasicLands {
+		t.Errorf("expected basic lands left out, got %+v", result)
+	}
+}
+
+func TestExportDuplicates(t *testing.T) {
+	app, db := setupDuplicatesTestApp(t)
+	createDuplicatesFixture(t, db)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/duplicates/export", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse CSV: %v", err)
+	}
+	// Header, then one row per printing and location
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %v", records)
+	}
+	want := []string{"Lightning Bolt", "oracle-bolt", "5", "1", "m10", "", "bolt-m10", "nonfoil", "Binder", "3", "1.00", "3.00"}
+	for i, value := range want {
+		if records[1][i] != value {
+			t.Errorf("column %s: expected %q, got %q", duplicatesCSVHeader[i], value, records[1][i])
+		}
+	}
+	if records[2][8] != "" || records[3][6] != "bolt-lea" {
+		t.Errorf("expected the unassigned copy then the lea printing, got %v", records[2:])
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/inventory/duplicates/export?format=xlsx", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown format, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestExportDuplicates_Encrypted(t *testing.T) {
+	_, db := setupDuplicatesTestApp(t)
+	createDuplicatesFixture(t, db)
+
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), "nas-passphrase"))
+	app.Get("/inventory/duplicates/export", handler.ExportDuplicates)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/duplicates/export", nil), encryptedTestConfig)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != fiber.MIMEOctetStream {
+		t.Errorf("expected an encrypted download, got content type %q", ct)
+	}
+	if cd := resp.Header.Get(fiber.HeaderContentDisposition); !strings.HasSuffix(cd, `.csv.enc"`) {
+		t.Errorf("expected a .csv.enc download, got %q", cd)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	plaintext, err := utils.DecryptWithPassphrase(body, "nas-passphrase")
+	if err != nil {
+		t.Fatalf("expected the export encrypted with the passphrase: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(plaintext)).ReadAll()
+	if err != nil || len(records) != 4 {
+		t.Errorf("expected 4 decrypted records, got %v (err %v)", records, err)
+	}
+}
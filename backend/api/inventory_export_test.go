@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryExportTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Loan{}, &models.LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), ""))
+
+	app := fiber.New()
+	app.Get("/inventory/export.ndjson", handler.ExportNDJSON)
+
+	return app, db
+}
+
+func TestInventoryExportNDJSON(t *testing.T) {
+	app, db := setupInventoryExportTestApp(t)
+
+	// Enough rows to span several batches
+	count := exportStreamBatchSize*2 + 3
+	items := make([]models.Inventory, count)
+	for i := range items {
+		items[i] = models.Inventory{ScryfallID: fmt.Sprintf("card-%d", i), OracleID: "oracle", Treatment: "nonfoil", Quantity: 1}
+	}
+	if err := db.CreateInBatches(&items, 200).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	db.Delete(&items[0])
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/export.ndjson", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/x-ndjson" {
+		t.Errorf("expected application/x-ndjson, got %q", contentType)
+	}
+
+	var lines int
+	var lastID uint
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var item models.Inventory
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v", lines+1, err)
+		}
+		if item.ID <= lastID {
+			t.Fatalf("expected ascending IDs, got %d after %d", item.ID, lastID)
+		}
+		if item.ID == items[0].ID {
+			t.Error("expected trashed rows to be left out")
+		}
+		lastID = item.ID
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("failed to read stream: %v", err)
+	}
+
+	if lines != count-1 {
+		t.Errorf("expected %d lines, got %d", count-1, lines)
+	}
+}
+
+func TestInventoryExportNDJSON_Empty(t *testing.T) {
+	app, _ := setupInventoryExportTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/export.ndjson", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	if scanner.Scan() {
+		t.Errorf("expected an empty body, got %q", scanner.Text())
+	}
+}
+
+func TestInventoryExportNDJSON_Encrypted(t *testing.T) {
+	_, db := setupInventoryExportTestApp(t)
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle", Treatment: "nonfoil", Quantity: 2})
+
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), "nas-passphrase"))
+	app := fiber.New()
+	app.Get("/inventory/export.ndjson", handler.ExportNDJSON)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/export.ndjson", nil), encryptedTestConfig)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if cd := resp.Header.Get(fiber.HeaderContentDisposition); cd != `attachment; filename="inventory.ndjson.enc"` {
+		t.Errorf("expected an encrypted download, got %q", cd)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	plaintext, err := utils.DecryptWithPassphrase(body, "nas-passphrase")
+	if err != nil {
+		t.Fatalf("expected the export encrypted with the passphrase: %v", err)
+	}
+	var item models.Inventory
+	if err := json.Unmarshal(plaintext, &item); err != nil || item.ScryfallID != "card-1" {
+		t.Errorf("expected the decrypted row, got %q (err %v)", plaintext, err)
+	}
+}
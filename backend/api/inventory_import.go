@@ -0,0 +1,116 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// InventoryImportHandler handles CSV collection imports
+type InventoryImportHandler struct {
+	db         *gorm.DB
+	service    *services.ImportService
+	jobService *services.JobService
+}
+
+// NewInventoryImportHandler creates a new inventory import handler
+func NewInventoryImportHandler(db *gorm.DB, service *services.ImportService, jobService *services.JobService) *InventoryImportHandler {
+	return &InventoryImportHandler{db: db, service: service, jobService: jobService}
+}
+
+// InventoryImportResponse is returned when a CSV import job is accepted
+// tygo:export
+type InventoryImportResponse struct {
+	JobID     uint                  `json:"job_id"`
+	Format    services.ImportFormat `json:"format"`
+	TotalRows int                   `json:"total_rows"`
+}
+
+// Import accepts a Moxfield, Deckbox, TCGPlayer, or Scryfall CSV export as a multipart
+// "file" upload and creates inventory from it in a background job.
+// Optional form fields: "format" (detected from the header when omitted),
+// "storage_location_id" (sorting rules assign locations when omitted),
+// "batch_size" and "transaction_size" (override the import tuning settings), and
+// "benchmark" ("true" runs the import and rolls it back, reporting throughput).
+func (h *InventoryImportHandler) Import(c fiber.Ctx, appCtx context.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "file is required")
+	}
+
+	var format services.ImportFormat
+	if value := c.FormValue("format"); value != "" {
+		format, err = services.ParseImportFormat(value)
+		if err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+	}
+
+	var storageLocationID *uint
+	if value := c.FormValue("storage_location_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || id == 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "invalid storage_location_id")
+		}
+		var location models.StorageLocation
+		if err := h.db.WithContext(c.RequestCtx()).First(&location, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to validate storage location", "storage location lookup failed", err)
+		}
+		storageLocationID = &location.ID
+	}
+
+	tuning, err := services.ParseImportTuningOverrides(c.FormValue("batch_size"), c.FormValue("transaction_size"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	benchmark := c.FormValue("benchmark") == "true"
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to read uploaded file", "opening upload failed", err)
+	}
+	defer file.Close()
+
+	// Parse up front so malformed files are rejected before a job is created
+	rows, format, err := services.ParseImportCSV(file, format)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if len(rows) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "CSV contains no rows")
+	}
+
+	job, err := h.service.CreateImportJob(appCtx, format, len(rows))
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create import job", "job creation failed", err)
+	}
+
+	// The worker pool logs a failure
+	if err := h.jobService.Go(appCtx, job.ID, "inventory import", func(ctx context.Context) error {
+		return h.service.Run(ctx, job.ID, format, rows, services.ImportOptions{
+			StorageLocationID: storageLocationID,
+			Tuning:            tuning,
+			Benchmark:         benchmark,
+		})
+	}); err != nil {
+		return startJobError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(InventoryImportResponse{
+		JobID:     job.ID,
+		Format:    format,
+		TotalRows: len(rows),
+	})
+}
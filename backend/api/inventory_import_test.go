@@ -0,0 +1,144 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryImportTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	// The import runs in a goroutine, so keep a single connection to the in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	databasetest.Migrate(t, db)
+
+	jobService := services.NewJobService(db)
+	service := services.NewImportService(db, jobService)
+	handler := NewInventoryImportHandler(db, service, jobService)
+
+	app := fiber.New()
+	app.Post("/inventory/import", func(c fiber.Ctx) error {
+		return handler.Import(c, context.Background())
+	})
+
+	return app, db
+}
+
+func newImportUploadRequest(t *testing.T, csv string, fields map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if csv != "" {
+		part, err := writer.CreateFormFile("file", "collection.csv")
+		if err != nil {
+			t.Fatalf("failed to create form file: %v", err)
+		}
+		part.Write([]byte(csv))
+	}
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/inventory/import", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestInventoryImport_Accepted(t *testing.T) {
+	app, db := setupInventoryImportTestApp(t)
+
+	card := models.Card{ScryfallID: "sol-ring-c21", OracleID: "oracle-sol-ring", RawJSON: `{"name":"Sol Ring","set":"c21","collector_number":"263"}`}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	req := newImportUploadRequest(t, "Count,Name,Edition,Collector Number\n2,Sol Ring,c21,263\n", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+
+	var result InventoryImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Format != services.ImportFormatMoxfield || result.TotalRows != 1 || result.JobID == 0 {
+		t.Errorf("unexpected response: %+v", result)
+	}
+
+	// Wait for the background job to finish
+	deadline := time.Now().Add(5 * time.Second)
+	var job models.Job
+	for time.Now().Before(deadline) {
+		db.First(&job, result.JobID)
+		if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != models.JobStatusCompleted {
+		t.Fatalf("expected job to complete, got %s", job.Status)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Where("scryfall_id = ?", "sol-ring-c21").Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 inventory item, got %d", count)
+	}
+}
+
+func TestInventoryImport_BadRequests(t *testing.T) {
+	app, _ := setupInventoryImportTestApp(t)
+
+	tests := []struct {
+		name   string
+		csv    string
+		fields map[string]string
+	}{
+		{name: "Missing file", csv: ""},
+		{name: "Unknown format", csv: "foo,bar\n1,2\n"},
+		{name: "Invalid format field", csv: "Count,Name,Edition\n1,Sol Ring,c21\n", fields: map[string]string{"format": "archidekt"}},
+		{name: "Header only", csv: "Count,Name,Edition\n"},
+		{name: "Unknown storage location", csv: "Count,Name,Edition\n1,Sol Ring,c21\n", fields: map[string]string{"storage_location_id": "999"}},
+		{name: "Batch size out of bounds", csv: "Count,Name,Edition\n1,Sol Ring,c21\n", fields: map[string]string{"batch_size": "100000"}},
+		{name: "Invalid transaction size", csv: "Count,Name,Edition\n1,Sol Ring,c21\n", fields: map[string]string{"transaction_size": "many"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(newImportUploadRequest(t, tt.csv, tt.fields))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
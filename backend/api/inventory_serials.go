@@ -0,0 +1,91 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// SerializedCard is one serialized copy in the registry, with its current value
+// tygo:export
+type SerializedCard struct {
+	InventoryID         uint    `json:"inventory_id"`
+	ScryfallID          string  `json:"scryfall_id"`
+	Name                string  `json:"name"`
+	SetCode             string  `json:"set_code"`
+	CollectorNumber     string  `json:"collector_number"`
+	Treatment           string  `json:"treatment"`
+	SerialNumber        string  `json:"serial_number"`
+	StorageLocationID   *uint   `json:"storage_location_id,omitempty"`
+	StorageLocationName string  `json:"storage_location_name,omitempty"`
+	Price               float64 `json:"price"` // USD for the copy's treatment
+}
+
+// SerializedRegistryResponse lists every serialized copy with the registry's total value
+// tygo:export
+type SerializedRegistryResponse struct {
+	Cards      []SerializedCard `json:"cards"`
+	TotalValue float64          `json:"total_value"`
+}
+
+// checkSerialNumber rejects a serial number already recorded for the printing.
+// When ok is false the error response has already been written and err should be returned.
+func (h *InventoryHandler) checkSerialNumber(c fiber.Ctx, scryfallID, serial string, excludeID uint) (bool, error) {
+	taken, err := models.SerialNumberTaken(h.db.WithContext(c.RequestCtx()), scryfallID, serial, excludeID)
+	if err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check serial number", "serial number lookup failed", err)
+	}
+	if taken {
+		return false, utils.ReturnError(c, fiber.StatusConflict, "serial number is already registered for this printing")
+	}
+	return true, nil
+}
+
+// Serialized returns the registry of serialized copies, ordered by card name and serial number
+func (h *InventoryHandler) Serialized(c fiber.Ctx) error {
+	var cards []SerializedCard
+	if err := h.db.WithContext(c.RequestCtx()).Raw(`
+		SELECT i.id AS inventory_id, i.scryfall_id, i.treatment, i.serial_number, i.storage_location_id,
+			COALESCE(c.name, '') AS name,
+			COALESCE(c.set_code, '') AS set_code,
+			COALESCE(c.collector_number, '') AS collector_number,
+			COALESCE(sl.name, '') AS storage_location_name
+		FROM inventories i
+		LEFT JOIN cards c ON c.scryfall_id = i.scryfall_id
+		LEFT JOIN storage_locations sl ON sl.id = i.storage_location_id
+		WHERE i.serial_number IS NOT NULL AND i.deleted_at IS NULL`).Scan(&cards).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch serialized cards", "database query failed", err)
+	}
+
+	scryfallIDs := make([]string, 0, len(cards))
+	for _, card := range cards {
+		scryfallIDs = append(scryfallIDs, card.ScryfallID)
+	}
+	prices, err := models.GetCardPricesByIDs(h.db.WithContext(c.RequestCtx()), scryfallIDs)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card prices", "price query failed", err)
+	}
+
+	response := SerializedRegistryResponse{Cards: make([]SerializedCard, 0, len(cards))}
+	for _, card := range cards {
+		card.Price = prices[card.ScryfallID].ForTreatment(card.Treatment)
+		response.TotalValue += card.Price
+		response.Cards = append(response.Cards, card)
+	}
+	slices.SortFunc(response.Cards, func(a, b SerializedCard) int {
+		return cmp.Or(
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.ScryfallID, b.ScryfallID),
+			strings.Compare(a.SerialNumber, b.SerialNumber),
+		)
+	})
+
+	return c.JSON(response)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupSerialTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	_, db := setupInventoryTestAppWithRules(t)
+
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	app.Get("/inventory/serialized", handler.Serialized)
+	app.Post("/inventory", handler.Create)
+	app.Put("/inventory/:id", handler.Update)
+	return app, db
+}
+
+func sendInventoryJSON(t *testing.T, app *fiber.App, method, path, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestCreate_SerialNumber(t *testing.T) {
+	app, db := setupSerialTestApp(t)
+	createTestCard(t, db, "serial-card", "Serialized Bolt", "sld", "mythic", "250.00")
+	createTestCard(t, db, "other-printing", "Serialized Bolt", "pbolt", "mythic", "100.00")
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"First copy", `{"scryfall_id": "serial-card", "oracle_id": "o", "serial_number": " 042/500 "}`, http.StatusCreated},
+		{"Duplicate serial", `{"scryfall_id": "serial-card", "oracle_id": "o", "serial_number": "042/500"}`, http.StatusConflict},
+		{"Same serial, other printing", `{"scryfall_id": "other-printing", "oracle_id": "o", "serial_number": "042/500"}`, http.StatusCreated},
+		{"More than one copy", `{"scryfall_id": "serial-card", "oracle_id": "o", "quantity": 2, "serial_number": "043/500"}`, http.StatusBadRequest},
+		{"Blank serial", `{"scryfall_id": "serial-card", "oracle_id": "o", "serial_number": "  "}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := sendInventoryJSON(t, app, http.MethodPost, "/inventory", tt.body)
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	var item models.Inventory
+	if err := db.Where("scryfall_id = ?", "serial-card").First(&item).Error; err != nil {
+		t.Fatalf("failed to load item: %v", err)
+	}
+	if item.SerialNumber == nil || *item.SerialNumber != "042/500" {
+		t.Errorf("expected trimmed serial 042/500, got %v", item.SerialNumber)
+	}
+}
+
+func TestUpdate_SerialNumber(t *testing.T) {
+	app, db := setupSerialTestApp(t)
+	createTestCard(t, db, "serial-card", "Serialized Bolt", "sld", "mythic", "250.00")
+
+	first := sendInventoryJSON(t, app, http.MethodPost, "/inventory", `{"scryfall_id": "serial-card", "oracle_id": "o", "serial_number": "001/500"}`)
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, first.StatusCode)
+	}
+	var second models.Inventory
+	resp := sendInventoryJSON(t, app, http.MethodPost, "/inventory", `{"scryfall_id": "serial-card", "oracle_id": "o"}`)
+	if err := json.NewDecoder(resp.Body).Decode(&second); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	path := fmt.Sprintf("/inventory/%d", second.ID)
+
+	if resp := sendInventoryJSON(t, app, http.MethodPut, path, `{"serial_number": "001/500"}`); resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d for a taken serial, got %d", http.StatusConflict, resp.StatusCode)
+	}
+	if resp := sendInventoryJSON(t, app, http.MethodPut, path, `{"serial_number": "002/500"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	// Re-saving the same row with its own serial is not a conflict
+	if resp := sendInventoryJSON(t, app, http.MethodPut, path, `{"serial_number": "002/500"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d when keeping the serial, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if resp := sendInventoryJSON(t, app, http.MethodPut, path, `{"quantity": 3}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d when adding copies to a serialized row, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	if resp := sendInventoryJSON(t, app, http.MethodPut, path, `{"clear_serial": true}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var reloaded models.Inventory
+	if err := db.First(&reloaded, second.ID).Error; err != nil {
+		t.Fatalf("failed to reload item: %v", err)
+	}
+	if reloaded.SerialNumber != nil {
+		t.Errorf("expected serial cleared, got %q", *reloaded.SerialNumber)
+	}
+}
+
+func TestSerialized(t *testing.T) {
+	app, db := setupSerialTestApp(t)
+	createTestCard(t, db, "serial-card", "Serialized Bolt", "sld", "mythic", "250.00")
+	createTestCard(t, db, "plain-card", "Lightning Bolt", "lea", "common", "1.00")
+
+	location := models.StorageLocation{Name: "Safe", StorageType: models.Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	for _, body := range []string{
+		fmt.Sprintf(`{"scryfall_id": "serial-card", "oracle_id": "o", "treatment": "nonfoil", "serial_number": "007/500", "storage_location_id": %d}`, location.ID),
+		`{"scryfall_id": "serial-card", "oracle_id": "o", "treatment": "nonfoil", "serial_number": "003/500"}`,
+		`{"scryfall_id": "plain-card", "oracle_id": "o", "treatment": "nonfoil", "quantity": 4}`,
+	} {
+		if resp := sendInventoryJSON(t, app, http.MethodPost, "/inventory", body); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/serialized", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result SerializedRegistryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Cards) != 2 {
+		t.Fatalf("expected 2 serialized cards, got %+v", result.Cards)
+	}
+	if result.Cards[0].SerialNumber != "003/500" || result.Cards[1].SerialNumber != "007/500" {
+		t.Errorf("expected cards ordered by serial, got %+v", result.Cards)
+	}
+	if result.Cards[1].Name != "Serialized Bolt" || result.Cards[1].SetCode != "sld" || result.Cards[1].StorageLocationName != "Safe" {
+		t.Errorf("unexpected registry entry: %+v", result.Cards[1])
+	}
+	if result.TotalValue != 500 {
+		t.Errorf("expected total value 500, got %v", result.TotalValue)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// UnassignedSuggestions returns a page of unassigned inventory items, each with the
+// location auto-sort would pick and alternative locations that still have room
+func (h *InventoryHandler) UnassignedSuggestions(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{}).Where("storage_location_id IS NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count unassigned inventory items", "count query failed", err)
+	}
+
+	var items []models.Inventory
+	offset := utils.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("id ASC").Offset(offset).Limit(params.PageSize).Find(&items).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch unassigned inventory items", "database query failed", err)
+	}
+
+	suggestions, err := h.autoSortSvc.SuggestLocations(c.RequestCtx(), items)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to suggest storage locations", "suggestion failed", err)
+	}
+
+	return utils.SendPaginated(c, suggestions, params.Page, params.PageSize, total)
+}
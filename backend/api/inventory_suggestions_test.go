@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestUnassignedSuggestions(t *testing.T) {
+	_, db := setupInventoryTestAppWithRules(t)
+	app := fiber.New()
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	app.Get("/inventory/unassigned/suggestions", handler.UnassignedSuggestions)
+
+	location := createTestStorageLocation(t, db)
+	rule := createTestSortingRule(t, db, "Red cards", 1, `hasColor("R")`, location.ID)
+	createTestCard(t, db, "bolt", "Lightning Bolt", "lea", "common", "1.00")
+	createTestInventoryItem(t, db, "bolt", 2, nil)
+	createTestInventoryItem(t, db, "bolt", 1, &location.ID)
+	createTestInventoryItem(t, db, "bolt", 1, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/inventory/unassigned/suggestions?page_size=1", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result utils.PaginatedResponse[services.StorageSuggestion]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.TotalItems != 2 {
+		t.Errorf("expected 2 unassigned items, got %d", result.TotalItems)
+	}
+	if len(result.Data) != 1 {
+		t.Fatalf("expected 1 suggestion on the page, got %d", len(result.Data))
+	}
+
+	got := result.Data[0]
+	if got.Name != "Lightning Bolt" || got.Quantity != 2 {
+		t.Errorf("expected first unassigned item, got %+v", got)
+	}
+	if got.Suggested == nil || got.Suggested.StorageLocationID != location.ID {
+		t.Fatalf("expected suggestion of location %d, got %+v", location.ID, got.Suggested)
+	}
+	if got.MatchedRuleID == nil || *got.MatchedRuleID != rule.ID {
+		t.Errorf("expected matched rule %d, got %v", rule.ID, got.MatchedRuleID)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,7 +10,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"backend/database/databasetest"
 	"backend/models"
 	"backend/services"
 	"backend/utils"
@@ -27,12 +30,12 @@ func setupInventoryTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}); err != nil {
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.InventoryEvent{}, &models.Loan{}, &models.LoanItem{}, &models.InventoryOperation{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
 	app := fiber.New()
-	handler := NewInventoryHandler(db, services.NewAutoSortService(db))
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
 
 	app.Get("/inventory", handler.List)
 	app.Get("/inventory/:id", handler.Get)
@@ -134,6 +137,46 @@ func TestInventoryList_FilterByScryfallID(t *testing.T) {
 	}
 }
 
+func TestInventoryList_FilterByNoteContains(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	signed := createTestInventoryItem(t, db, "card-1", 1, nil)
+	db.Model(&signed).UpdateColumn("notes", "Signed by Christopher Rush")
+	altered := createTestInventoryItem(t, db, "card-2", 1, nil)
+	db.Model(&altered).UpdateColumn("notes", "Altered art, 100% hand painted")
+	createTestInventoryItem(t, db, "card-3", 1, nil)
+
+	tests := []struct {
+		query    string
+		expected int64
+	}{
+		{"note_contains=signed", 1},
+		{"note_contains=ALTERED", 1},
+		{"note_contains=100%25", 1},
+		{"note_contains=%25", 1}, // Wildcards match literally
+		{"note_contains=_", 0},
+		{"note_contains=foil", 0},
+		{"note_contains=", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var result utils.PaginatedResponse[json.RawMessage]
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.TotalItems != tt.expected {
+				t.Errorf("expected %d items, got %d", tt.expected, result.TotalItems)
+			}
+		})
+	}
+}
+
 func TestInventoryList_FilterByStorageLocation(t *testing.T) {
 	app, db := setupInventoryTestApp(t)
 
@@ -160,6 +203,55 @@ func TestInventoryList_FilterByStorageLocation(t *testing.T) {
 	}
 }
 
+func TestInventoryList_FilterByStandardLegal(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	databasetest.Migrate(t, db)
+	db.Create(&models.Set{ScryfallID: "set-1", Code: "std", Name: "Standard Set", StandardLegal: true})
+	db.Create(&models.Card{ScryfallID: "card-1", RawJSON: `{"set":"std","legalities":{"standard":"legal"}}`})
+	db.Create(&models.Card{ScryfallID: "card-2", RawJSON: `{"set":"old","legalities":{"standard":"not_legal"}}`})
+
+	createTestInventoryItem(t, db, "card-1", 1, nil)
+	createTestInventoryItem(t, db, "card-2", 2, nil)
+
+	tests := []struct {
+		query    string
+		expected int64
+	}{
+		{query: "true", expected: 1},
+		{query: "false", expected: 1},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/inventory?standard_legal="+tt.query, nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+
+		var result utils.PaginatedResponse[json.RawMessage]
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		resp.Body.Close()
+
+		if result.TotalItems != tt.expected {
+			t.Errorf("standard_legal=%s: expected %d items, got %d", tt.query, tt.expected, result.TotalItems)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/inventory?standard_legal=yes", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
 func TestInventoryList_FilterByNullStorageLocation(t *testing.T) {
 	app, db := setupInventoryTestApp(t)
 
@@ -543,6 +635,185 @@ func TestInventoryUpdate_Success(t *testing.T) {
 	}
 }
 
+func TestInventoryUpdate_Notes(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	item := createTestInventoryItem(t, db, "test-card", 1, nil)
+
+	update := func(body string) models.Inventory {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/inventory/%d", item.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		var result models.Inventory
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+
+	if result := update(`{"notes": "  Signed  "}`); result.Notes != "Signed" {
+		t.Errorf("expected trimmed notes 'Signed', got %q", result.Notes)
+	}
+	if result := update(`{"quantity": 2}`); result.Notes != "Signed" {
+		t.Errorf("expected notes kept when not provided, got %q", result.Notes)
+	}
+	if result := update(`{"notes": ""}`); result.Notes != "" {
+		t.Errorf("expected notes cleared, got %q", result.Notes)
+	}
+}
+
+func TestInventoryCreate_WithAcquisition(t *testing.T) {
+	app, _ := setupInventoryTestApp(t)
+
+	body := `{
+		"scryfall_id": "test-card-123",
+		"oracle_id": "test-oracle-123",
+		"quantity": 2,
+		"acquired_price": 3.5,
+		"acquired_at": "2024-03-01T00:00:00Z",
+		"acquired_from": "  LGS  "
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/inventory", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var result models.Inventory
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.AcquiredPrice == nil || *result.AcquiredPrice != 3.5 {
+		t.Errorf("expected acquired_price 3.5, got %v", result.AcquiredPrice)
+	}
+	if result.AcquiredAt == nil || !result.AcquiredAt.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected acquired_at 2024-03-01, got %v", result.AcquiredAt)
+	}
+	if result.AcquiredFrom != "LGS" {
+		t.Errorf("expected trimmed acquired_from 'LGS', got %q", result.AcquiredFrom)
+	}
+}
+
+func TestInventoryCreate_NegativeAcquiredPrice(t *testing.T) {
+	app, _ := setupInventoryTestApp(t)
+
+	body := `{"scryfall_id": "test-card-123", "oracle_id": "test-oracle-123", "acquired_price": -1}`
+	req := httptest.NewRequest(http.MethodPost, "/inventory", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestInventoryCreate_Upsert(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+	location := createTestStorageLocation(t, db)
+
+	create := func(path, body string, expectedStatus int) models.Inventory {
+		t.Helper()
+		resp := sendInventoryJSON(t, app, http.MethodPost, path, body)
+		defer resp.Body.Close()
+		if resp.StatusCode != expectedStatus {
+			t.Fatalf("expected status %d, got %d", expectedStatus, resp.StatusCode)
+		}
+		var result models.Inventory
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+	body := fmt.Sprintf(`{"scryfall_id": "test-card", "oracle_id": "test-oracle", "treatment": "nonfoil", "quantity": 2, "storage_location_id": %d}`, location.ID)
+
+	first := create("/inventory?upsert=true", body, http.StatusCreated)
+	merged := create("/inventory?upsert=true", body, http.StatusOK)
+	if merged.ID != first.ID || merged.Quantity != 4 {
+		t.Errorf("expected row %d to hold 4 copies, got row %d with %d", first.ID, merged.ID, merged.Quantity)
+	}
+	if merged.StorageLocation == nil || merged.StorageLocation.ID != location.ID {
+		t.Errorf("expected the storage location preloaded, got %+v", merged.StorageLocation)
+	}
+
+	// Without the flag, and for copies with acquisition details, a new row is created
+	if plain := create("/inventory", body, http.StatusCreated); plain.ID == first.ID {
+		t.Error("expected a new row without upsert")
+	}
+	acquired := fmt.Sprintf(`{"scryfall_id": "test-card", "oracle_id": "test-oracle", "treatment": "nonfoil", "storage_location_id": %d, "acquired_price": 1.5}`, location.ID)
+	if lot := create("/inventory?upsert=true", acquired, http.StatusCreated); lot.ID == first.ID {
+		t.Error("expected copies with acquisition details in their own row")
+	}
+
+	var events []models.InventoryEvent
+	db.Where("inventory_id = ?", first.ID).Order("id").Find(&events)
+	if len(events) != 2 || events[1].EventType != models.InventoryEventQuantityChanged ||
+		*events[1].OldQuantity != 2 || *events[1].NewQuantity != 4 {
+		t.Errorf("expected created and quantity_changed events, got %+v", events)
+	}
+}
+
+func TestInventoryUpdate_Acquisition(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	item := createTestInventoryItem(t, db, "test-card", 1, nil)
+
+	update := func(body string) models.Inventory {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/inventory/%d", item.ID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+		var result models.Inventory
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+
+	result := update(`{"acquired_price": 12.25, "acquired_from": "Trade"}`)
+	if result.AcquiredPrice == nil || *result.AcquiredPrice != 12.25 || result.AcquiredFrom != "Trade" {
+		t.Errorf("expected acquisition to be set, got price %v from %q", result.AcquiredPrice, result.AcquiredFrom)
+	}
+
+	result = update(`{"acquired_at": "2024-05-01T00:00:00Z"}`)
+	if result.AcquiredAt == nil || result.AcquiredPrice == nil || result.AcquiredFrom != "Trade" {
+		t.Errorf("expected date set and other acquisition fields kept, got %+v", result)
+	}
+
+	result = update(`{"clear_acquisition": true}`)
+	if result.AcquiredPrice != nil || result.AcquiredAt != nil || result.AcquiredFrom != "" {
+		t.Errorf("expected acquisition cleared, got price %v at %v from %q", result.AcquiredPrice, result.AcquiredAt, result.AcquiredFrom)
+	}
+}
+
 func TestInventoryUpdate_PartialUpdate(t *testing.T) {
 	app, db := setupInventoryTestApp(t)
 
@@ -752,29 +1023,22 @@ func TestInventoryDelete_DoesNotDeleteStorageLocation(t *testing.T) {
 func setupInventoryTestAppWithRules(t *testing.T) (*fiber.App, *gorm.DB) {
 	t.Helper()
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("failed to connect to test database: %v", err)
-	}
-
-	if err := db.AutoMigrate(
-		&models.StorageLocation{},
-		&models.Inventory{},
-		&models.Card{},
-		&models.SortingRule{},
-	); err != nil {
-		t.Fatalf("failed to migrate test database: %v", err)
-	}
+	db := databasetest.Open(t)
 
 	app := fiber.New()
-	handler := NewInventoryHandler(db, services.NewAutoSortService(db))
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+	handler.SetJobService(services.NewJobService(db))
 
 	app.Get("/inventory", handler.List)
 	app.Get("/inventory/:id", handler.Get)
 	app.Post("/inventory", handler.Create)
 	app.Put("/inventory/:id", handler.Update)
 	app.Delete("/inventory/:id", handler.Delete)
-	app.Post("/inventory/resort", handler.Resort)
+	app.Post("/inventory/resort", func(c fiber.Ctx) error {
+		return handler.Resort(c, context.Background())
+	})
+	app.Post("/inventory/import-text", handler.ImportText)
+	app.Post("/inventory/batch/add", handler.BatchAdd)
 
 	return app, db
 }
@@ -1030,6 +1294,39 @@ func TestResort_RuleMatches_MovedToLocation(t *testing.T) {
 	}
 }
 
+func TestResort_MatchesNotes(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Signed", 1, `noteContains("signed")`, location.ID)
+
+	signed := createTestInventoryItem(t, db, "bolt-id", 1, nil)
+	db.Model(&signed).UpdateColumn("notes", "Signed at GP Vegas")
+	plain := createTestInventoryItem(t, db, "bolt-id", 1, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d]}`, signed.ID, plain.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var updated models.Inventory
+	db.First(&updated, signed.ID)
+	if updated.StorageLocationID == nil || *updated.StorageLocationID != location.ID {
+		t.Errorf("expected signed copy in location %d, got %v", location.ID, updated.StorageLocationID)
+	}
+	var unsigned models.Inventory
+	db.First(&unsigned, plain.ID)
+	if unsigned.StorageLocationID != nil {
+		t.Errorf("expected unsigned copy to stay unassigned, got %v", *unsigned.StorageLocationID)
+	}
+}
+
 func TestResort_NoMatchingRule_ClearsLocation(t *testing.T) {
 	app, db := setupInventoryTestAppWithRules(t)
 
@@ -1254,14 +1551,20 @@ func setupFullInventoryTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	if err := db.AutoMigrate(
 		&models.StorageLocation{},
 		&models.Inventory{},
+		&models.Tag{},
+		&models.InventoryTag{},
+		&models.InventoryEvent{},
 		&models.Card{},
 		&models.SortingRule{},
+		&models.Loan{},
+		&models.LoanItem{},
+		&models.InventoryOperation{},
 	); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
 	app := fiber.New()
-	handler := NewInventoryHandler(db, services.NewAutoSortService(db))
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
 
 	// Register all inventory routes matching server/inventory_routes.go
 	inventory := app.Group("/inventory")
@@ -1271,7 +1574,9 @@ func setupFullInventoryTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	inventory.Get("/by-oracle/:oracle_id", handler.ByOracle)
 	inventory.Post("/batch/move", handler.BatchMove)
 	inventory.Delete("/batch", handler.BatchDelete)
-	inventory.Post("/resort", handler.Resort)
+	inventory.Post("/resort", func(c fiber.Ctx) error {
+		return handler.Resort(c, context.Background())
+	})
 	inventory.Get("/:id", handler.Get)
 	inventory.Post("/", handler.Create)
 	inventory.Put("/:id", handler.Update)
@@ -1402,6 +1707,48 @@ func TestListAsCards_EnhancedCardFields(t *testing.T) {
 	}
 }
 
+func TestListAsCards_ExternalLinks(t *testing.T) {
+	app, db := setupFullInventoryTestApp(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+
+	createTestCardFromFixture(t, db,
+		"d573ef03-4730-45aa-93dd-e45ac1dbaf4a",
+		"4457ed35-7c10-48c8-9776-456485fdf070",
+		"card_lightning_bolt.json")
+	createTestInventoryItem(t, db, "d573ef03-4730-45aa-93dd-e45ac1dbaf4a", 1, nil)
+
+	fetch := func() EnhancedCardResult {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory/cards", nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var result InventoryCardsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 {
+			t.Fatalf("expected 1 card, got %d", len(result.Data))
+		}
+		return result.Data[0]
+	}
+
+	card := fetch()
+	if card.PurchaseURIs["cardmarket"] == "" || card.RelatedURIs["edhrec"] == "" {
+		t.Errorf("expected marketplace and reference links, got %v and %v", card.PurchaseURIs, card.RelatedURIs)
+	}
+
+	db.Create(&models.Setting{Key: "card_external_links", Value: "false"})
+	card = fetch()
+	if card.PurchaseURIs != nil || card.RelatedURIs != nil {
+		t.Errorf("expected no links with card_external_links off, got %v and %v", card.PurchaseURIs, card.RelatedURIs)
+	}
+}
+
 func TestListAsCards_Pagination(t *testing.T) {
 	app, db := setupFullInventoryTestApp(t)
 
@@ -1453,6 +1800,32 @@ func TestListAsCards_Pagination(t *testing.T) {
 	}
 }
 
+func TestListAsCards_EnvelopeVersion(t *testing.T) {
+	app, db := setupFullInventoryTestApp(t)
+
+	for i := 1; i <= 3; i++ {
+		id := fmt.Sprintf("card-%d", i)
+		createTestCard(t, db, id, fmt.Sprintf("Card %d", i), "tst", "common", "1.00")
+		createTestInventoryItem(t, db, id, 1, nil)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/inventory/cards?page_size=2", nil)
+	req.Header.Set(utils.APIVersionHeader, "2")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result utils.Envelope[EnhancedCardResult]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.TotalItems != 3 || result.TotalPages != 2 || result.PageSize != 2 || len(result.Items) != 2 {
+		t.Errorf("unexpected envelope: %d items of %d on %d pages", len(result.Items), result.TotalItems, result.TotalPages)
+	}
+}
+
 func TestListAsCards_FilterByStorageLocation(t *testing.T) {
 	app, db := setupFullInventoryTestApp(t)
 
@@ -1678,6 +2051,75 @@ func TestBatchMove_ClearLocation(t *testing.T) {
 	}
 }
 
+func TestInventory_UnassignedLocationID(t *testing.T) {
+	app, db := setupFullInventoryTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Everything", 1, "true", location.ID)
+	assigned := createTestInventoryItem(t, db, "card-1", 1, &location.ID)
+	moved := createTestInventoryItem(t, db, "card-2", 1, &location.ID)
+
+	// Creating with location 0 skips the sorting rules that would otherwise place it
+	sendInventoryRequest(t, app, http.MethodPost, "/inventory/", `{"scryfall_id": "bolt-id", "oracle_id": "oracle-bolt-id", "storage_location_id": 0}`)
+	var created models.Inventory
+	db.Where("scryfall_id = ?", "bolt-id").First(&created)
+	if created.StorageLocationID != nil {
+		t.Errorf("expected the new item unassigned, got location %d", *created.StorageLocationID)
+	}
+
+	sendInventoryRequest(t, app, http.MethodPut, fmt.Sprintf("/inventory/%d", assigned.ID), `{"storage_location_id": 0}`)
+	sendInventoryRequest(t, app, http.MethodPost, "/inventory/batch/move", fmt.Sprintf(`{"ids": [%d], "storage_location_id": 0}`, moved.ID))
+
+	for _, path := range []string{"/inventory/", "/inventory/cards"} {
+		req := httptest.NewRequest(http.MethodGet, path+"?storage_location_id=0", nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", path, http.StatusOK, resp.StatusCode)
+		}
+		if path == "/inventory/" {
+			var result utils.PaginatedResponse[models.Inventory]
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.TotalItems != 3 {
+				t.Errorf("expected 3 unassigned items, got %d", result.TotalItems)
+			}
+		}
+	}
+}
+
+func TestResort_UnassignedRule_ClearsLocation(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	box := createTestStorageLocation(t, db)
+	overflow := models.StorageLocation{Name: "Overflow Box", StorageType: models.Box}
+	db.Create(&overflow)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+	db.Create(&models.Setting{Key: "auto_sort_overflow_location_id", Value: fmt.Sprint(overflow.ID)})
+
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Leave commons", 1, "rarity == 'common'", models.UnassignedLocationID)
+	createTestSortingRule(t, db, "Everything", 2, "true", box.ID)
+	item := createTestInventoryItem(t, db, "bolt-id", 1, &box.ID)
+
+	sendInventoryRequest(t, app, http.MethodPost, "/inventory/resort", fmt.Sprintf(`{"ids": [%d]}`, item.ID))
+
+	// The unassigned rule wins over the lower-priority rule and the overflow location
+	var updated models.Inventory
+	db.First(&updated, item.ID)
+	if updated.StorageLocationID != nil {
+		t.Errorf("expected the item unassigned, got location %d", *updated.StorageLocationID)
+	}
+}
+
 func TestBatchMove_EmptyIDs(t *testing.T) {
 	app, _ := setupFullInventoryTestApp(t)
 
@@ -1904,3 +2346,343 @@ func TestBatchDelete_DoesNotAffectOtherItems(t *testing.T) {
 		t.Error("expected item2 to be deleted, but it still exists")
 	}
 }
+
+func TestResort_SplitAcrossLocations(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+	db.Create(&models.Setting{Key: "auto_sort_split_enabled", Value: "true"})
+
+	nearlyFull := models.StorageLocation{Name: "Commons Box", StorageType: models.Box, Capacity: 100}
+	overflow := models.StorageLocation{Name: "Overflow Box", StorageType: models.Box}
+	db.Create(&nearlyFull)
+	db.Create(&overflow)
+
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestCard(t, db, "filler-id", "Filler", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Commons", 1, "rarity == 'common'", nearlyFull.ID)
+	createTestSortingRule(t, db, "Overflow", 2, "true", overflow.ID)
+
+	createTestInventoryItem(t, db, "filler-id", 90, &nearlyFull.ID)
+	item := createTestInventoryItem(t, db, "bolt-id", 60, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d]}`, item.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result ResortResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Movements) != 2 {
+		t.Fatalf("expected 2 movements, got %d", len(result.Movements))
+	}
+
+	var rows []models.Inventory
+	db.Where("scryfall_id = ?", "bolt-id").Order("id").Find(&rows)
+	if len(rows) != 2 {
+		t.Fatalf("expected row split into 2, got %d", len(rows))
+	}
+	if rows[0].Quantity != 10 || *rows[0].StorageLocationID != nearlyFull.ID {
+		t.Errorf("expected 10 copies in %s, got %d in %v", nearlyFull.Name, rows[0].Quantity, *rows[0].StorageLocationID)
+	}
+	if rows[1].Quantity != 50 || *rows[1].StorageLocationID != overflow.ID {
+		t.Errorf("expected 50 copies in %s, got %d in %v", overflow.Name, rows[1].Quantity, *rows[1].StorageLocationID)
+	}
+
+	// Both halves of the split are recorded in the history
+	var events []models.InventoryEvent
+	db.Where("event_type = ?", models.InventoryEventResorted).Order("id").Find(&events)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 resorted events, got %d", len(events))
+	}
+	if events[0].InventoryID != item.ID || *events[0].OldQuantity != 60 || *events[0].NewQuantity != 10 {
+		t.Errorf("unexpected event for the original row: %+v", events[0])
+	}
+	if events[1].InventoryID != rows[1].ID || events[1].OldQuantity != nil || *events[1].NewStorageLocationID != overflow.ID {
+		t.Errorf("unexpected event for the split row: %+v", events[1])
+	}
+}
+
+func TestResort_SplitDisabled_SkipsFullLocation(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	small := models.StorageLocation{Name: "Small Box", StorageType: models.Box, Capacity: 10}
+	large := models.StorageLocation{Name: "Large Box", StorageType: models.Box, Capacity: 100}
+	db.Create(&small)
+	db.Create(&large)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Commons", 1, "rarity == 'common'", small.ID)
+	createTestSortingRule(t, db, "Everything", 2, "true", large.ID)
+	item := createTestInventoryItem(t, db, "bolt-id", 60, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d]}`, item.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	// The whole row moves to the next matching rule rather than overfilling the first
+	var updated models.Inventory
+	db.First(&updated, item.ID)
+	if updated.Quantity != 60 || updated.StorageLocationID == nil || *updated.StorageLocationID != large.ID {
+		t.Errorf("expected all 60 copies moved to %s, got %d in %v", large.Name, updated.Quantity, updated.StorageLocationID)
+	}
+}
+
+func TestResort_OverflowLocation(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+
+	full := models.StorageLocation{Name: "Rares Binder", StorageType: models.Binder, Capacity: 10}
+	overflow := models.StorageLocation{Name: "Overflow Box", StorageType: models.Box}
+	db.Create(&full)
+	db.Create(&overflow)
+	db.Create(&models.Setting{Key: "auto_sort_overflow_location_id", Value: fmt.Sprint(overflow.ID)})
+
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestCard(t, db, "other-id", "Unsorted", "lea", "uncommon", "0.25")
+	createTestSortingRule(t, db, "Commons", 1, "rarity == 'common'", full.ID)
+	item := createTestInventoryItem(t, db, "bolt-id", 20, nil)
+	unmatched := createTestInventoryItem(t, db, "other-id", 1, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d]}`, item.ID, unmatched.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result ResortResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Movements) != 1 || result.Movements[0].ToLocation == nil || *result.Movements[0].ToLocation != overflow.Name {
+		t.Errorf("expected one movement to %s, got %+v", overflow.Name, result.Movements)
+	}
+
+	var updated models.Inventory
+	db.First(&updated, item.ID)
+	if updated.StorageLocationID == nil || *updated.StorageLocationID != overflow.ID {
+		t.Errorf("expected copies moved to %s, got %v", overflow.Name, updated.StorageLocationID)
+	}
+
+	// Cards that match no rule are not sent to the overflow location
+	var untouched models.Inventory
+	db.First(&untouched, unmatched.ID)
+	if untouched.StorageLocationID != nil {
+		t.Errorf("expected unmatched card to stay unassigned, got %v", *untouched.StorageLocationID)
+	}
+}
+
+func TestResort_CatchAllLocation(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate settings: %v", err)
+	}
+
+	commons := models.StorageLocation{Name: "Commons Box", StorageType: models.Box}
+	catchAll := models.StorageLocation{Name: "Box Z", StorageType: models.Box}
+	db.Create(&commons)
+	db.Create(&catchAll)
+	db.Create(&models.Setting{Key: "auto_sort_catch_all_location_id", Value: fmt.Sprint(catchAll.ID)})
+
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestCard(t, db, "other-id", "Unsorted", "lea", "uncommon", "0.25")
+	createTestSortingRule(t, db, "Commons", 1, "rarity == 'common'", commons.ID)
+	item := createTestInventoryItem(t, db, "bolt-id", 1, nil)
+	// Previously sorted cards move to the catch-all instead of having their location cleared
+	unmatched := createTestInventoryItem(t, db, "other-id", 1, &commons.ID)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d]}`, item.ID, unmatched.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	var sorted, caught models.Inventory
+	db.First(&sorted, item.ID)
+	db.First(&caught, unmatched.ID)
+	if sorted.StorageLocationID == nil || *sorted.StorageLocationID != commons.ID {
+		t.Errorf("expected matched card in %s, got %v", commons.Name, sorted.StorageLocationID)
+	}
+	if caught.StorageLocationID == nil || *caught.StorageLocationID != catchAll.ID {
+		t.Errorf("expected unmatched card in %s, got %v", catchAll.Name, caught.StorageLocationID)
+	}
+}
+
+func TestResort_Async(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Cheap Cards", 1, "prices.usd < 5.0", location.ID)
+	// One more item than fits in a batch
+	items := make([]models.Inventory, resortBatchSize+1)
+	for i := range items {
+		items[i] = models.Inventory{ScryfallID: "bolt-id", OracleID: "oracle-bolt-id", Treatment: "normal", Quantity: 1}
+	}
+	if err := db.CreateInBatches(&items, 100).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(`{"async": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, resp.StatusCode)
+	}
+	var started TriggerImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&started); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if started.JobID == 0 {
+		t.Fatalf("expected a job ID, got %+v", started)
+	}
+
+	// Wait for the background job to finish
+	deadline := time.Now().Add(5 * time.Second)
+	var job models.Job
+	for time.Now().Before(deadline) {
+		db.First(&job, started.JobID)
+		if job.Status == models.JobStatusCompleted || job.Status == models.JobStatusFailed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != models.JobStatusCompleted || job.Type != models.JobTypeResort {
+		t.Fatalf("expected a completed resort job, got %s %s: %s", job.Type, job.Status, job.Error)
+	}
+
+	var metadata ResortJobMetadata
+	if err := json.Unmarshal([]byte(job.Metadata), &metadata); err != nil {
+		t.Fatalf("failed to decode job metadata: %v", err)
+	}
+	if metadata.Phase != "completed" || metadata.Processed != len(items) || metadata.TotalItems != len(items) {
+		t.Errorf("expected completed metadata for %d items, got %+v", len(items), metadata)
+	}
+
+	content, err := services.NewJobService(db).Result(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch job result: %v", err)
+	}
+	var result ResortResponse
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("failed to decode job result: %v", err)
+	}
+	if result.Processed != len(items) || result.Updated != len(items) || len(result.Movements) != len(items) {
+		t.Errorf("expected every item moved, got processed %d, updated %d, %d movements",
+			result.Processed, result.Updated, len(result.Movements))
+	}
+
+	var unsorted int64
+	db.Model(&models.Inventory{}).Where("storage_location_id IS NULL").Count(&unsorted)
+	if unsorted != 0 {
+		t.Errorf("expected every item sorted, %d left unassigned", unsorted)
+	}
+}
+
+// Import text tests
+
+func TestInventoryImportText_AutoSort(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Cheap Cards", 1, "prices.usd < 5.0", location.ID)
+
+	body := `{"text": "2 Lightning Bolt\n1 Missing Card"}`
+	req := httptest.NewRequest(http.MethodPost, "/inventory/import-text", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result ImportTextResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.Imported != 1 || result.Failed != 1 {
+		t.Errorf("expected 1 imported and 1 failed, got %d and %d", result.Imported, result.Failed)
+	}
+	if len(result.Results) != 2 || result.Results[1].Status != services.TextImportStatusNotFound {
+		t.Fatalf("expected second line to be not_found, got %+v", result.Results)
+	}
+
+	var item models.Inventory
+	if err := db.First(&item, result.Results[0].InventoryID).Error; err != nil {
+		t.Fatalf("failed to load imported item: %v", err)
+	}
+	if item.Quantity != 2 {
+		t.Errorf("expected quantity 2, got %d", item.Quantity)
+	}
+	if item.StorageLocationID == nil || *item.StorageLocationID != location.ID {
+		t.Errorf("expected item auto-sorted to location %d, got %v", location.ID, item.StorageLocationID)
+	}
+}
+
+func TestInventoryImportText_Validation(t *testing.T) {
+	app, _ := setupInventoryTestAppWithRules(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Empty text", body: `{"text": "  "}`},
+		{name: "Unknown storage location", body: `{"text": "Lightning Bolt", "storage_location_id": 999}`},
+		{name: "Invalid JSON", body: `{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/inventory/import-text", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"backend/realtime"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Trash returns deleted inventory items awaiting purge with pagination, most recently deleted first
+func (h *InventoryHandler) Trash(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	items, total, err := services.NewInventoryTrashService(h.db).List(c.RequestCtx(), params.Page, params.PageSize)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch trash", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, items, params.Page, params.PageSize, total)
+}
+
+// Restore brings a deleted inventory item back out of the trash
+func (h *InventoryHandler) Restore(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	item, err := services.NewInventoryTrashService(h.db).Restore(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "inventory item not found in trash")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to restore inventory item", "restore transaction failed", err)
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").First(item, item.ID).Error; err != nil {
+		slog.WarnContext(c.RequestCtx(), "failed to load restored item", "component", "inventory", "id", item.ID, "error", err)
+	}
+
+	slog.InfoContext(c.RequestCtx(), "restored inventory item from trash", "component", "inventory", "id", item.ID)
+	h.hub.Publish(realtime.EventInventoryCreated, realtime.InventoryChange{IDs: []uint{item.ID}})
+	return c.JSON(item)
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryTrashTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.InventoryEvent{}, &models.Loan{}, &models.LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+
+	app := fiber.New()
+	app.Get("/inventory/trash", handler.Trash)
+	app.Get("/inventory/:id", handler.Get)
+	app.Post("/inventory", handler.Create)
+	app.Delete("/inventory/:id", handler.Delete)
+	app.Post("/inventory/:id/restore", handler.Restore)
+
+	return app, db
+}
+
+func trashRequest(t *testing.T, app *fiber.App, method, url, body string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, url, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestInventoryTrash_DeleteAndRestore(t *testing.T) {
+	app, db := setupInventoryTrashTestApp(t)
+
+	location := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&location)
+	item := createTestInventoryItem(t, db, "card-1", 3, &location.ID)
+
+	resp := trashRequest(t, app, http.MethodDelete, fmt.Sprintf("/inventory/%d", item.ID), "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	// Deleted items are hidden from the collection but kept in the trash
+	resp = trashRequest(t, app, http.MethodGet, fmt.Sprintf("/inventory/%d", item.ID), "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for a trashed item, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp = trashRequest(t, app, http.MethodGet, "/inventory/trash", "")
+	var trash utils.PaginatedResponse[models.Inventory]
+	if err := json.NewDecoder(resp.Body).Decode(&trash); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if trash.TotalItems != 1 || trash.Data[0].ID != item.ID || !trash.Data[0].DeletedAt.Valid {
+		t.Fatalf("expected the deleted item in the trash, got %+v", trash)
+	}
+	if trash.Data[0].StorageLocation == nil || trash.Data[0].StorageLocation.Name != "Box" {
+		t.Errorf("expected the trashed item's location, got %+v", trash.Data[0].StorageLocation)
+	}
+
+	resp = trashRequest(t, app, http.MethodPost, fmt.Sprintf("/inventory/%d/restore", item.ID), "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var restored models.Inventory
+	if err := json.NewDecoder(resp.Body).Decode(&restored); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if restored.Quantity != 3 || restored.StorageLocation == nil || restored.DeletedAt.Valid {
+		t.Errorf("unexpected restored item: %+v", restored)
+	}
+
+	resp = trashRequest(t, app, http.MethodGet, fmt.Sprintf("/inventory/%d", item.ID), "")
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the restored item to be back, got status %d", resp.StatusCode)
+	}
+}
+
+func TestInventoryTrash_RestoreErrors(t *testing.T) {
+	app, db := setupInventoryTrashTestApp(t)
+
+	live := createTestInventoryItem(t, db, "card-1", 1, nil)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Invalid id", "/inventory/abc/restore", http.StatusBadRequest},
+		{"Unknown item", "/inventory/999/restore", http.StatusNotFound},
+		{"Not in trash", fmt.Sprintf("/inventory/%d/restore", live.ID), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestInventoryTrash_SerialHeldByTrashedItem(t *testing.T) {
+	app, db := setupInventoryTrashTestApp(t)
+
+	serial := "042/500"
+	item := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 1, SerialNumber: &serial}
+	db.Create(&item)
+	db.Delete(&item)
+
+	// The trashed copy keeps its serial so it can still be restored
+	body := `{"scryfall_id": "card-1", "oracle_id": "oracle-1", "quantity": 1, "serial_number": "042/500", "storage_location_id": 0}`
+	resp := trashRequest(t, app, http.MethodPost, "/inventory", body)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"backend/models"
 	"backend/services"
 	"backend/utils"
+	"context"
 	"errors"
+	"fmt"
+	"io"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -14,6 +18,7 @@ import (
 // JobsHandler handles job-related HTTP requests
 type JobsHandler struct {
 	service *services.JobService
+	exports *ExportFiles
 }
 
 // NewJobsHandler creates a new jobs handler
@@ -21,6 +26,11 @@ func NewJobsHandler(service *services.JobService) *JobsHandler {
 	return &JobsHandler{service: service}
 }
 
+// SetExportFiles serves CSV exports from exports, so interrupted downloads can resume
+func (h *JobsHandler) SetExportFiles(exports *ExportFiles) {
+	h.exports = exports
+}
+
 // GetAll retrieves all jobs with pagination and optional filtering
 func (h *JobsHandler) GetAll(c fiber.Ctx) error {
 	// Parse pagination parameters
@@ -51,8 +61,50 @@ func (h *JobsHandler) GetAll(c fiber.Ctx) error {
 			"Failed to retrieve jobs", "job list query failed", err)
 	}
 
-	response := utils.NewPaginatedResponse(jobs, params.Page, params.PageSize, total)
-	return c.JSON(response)
+	return utils.SendPaginated(c, jobs, params.Page, params.PageSize, total)
+}
+
+// Export downloads the job history as CSV (format query parameter; csv is the only
+// format), optionally filtered by type, status, and a since date (YYYY-MM-DD, UTC)
+func (h *JobsHandler) Export(c fiber.Ctx) error {
+	if format := c.Query("format", "csv"); format != "csv" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("invalid format %q (expected csv)", format))
+	}
+
+	var filter services.JobExportFilter
+	if typeStr := c.Query("type"); typeStr != "" {
+		t := models.JobType(typeStr)
+		if !t.Valid() {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job type")
+		}
+		filter.Type = &t
+	}
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := models.JobStatus(statusStr)
+		if !s.Valid() {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job status")
+		}
+		filter.Status = &s
+	}
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		since, err := time.Parse(time.DateOnly, sinceStr)
+		if err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "since must be a date in YYYY-MM-DD format")
+		}
+		filter.Since = &since
+	}
+
+	path, err := h.exports.renderFile("jobs", ".csv", func(w io.Writer) error {
+		_, err := h.service.ExportCSV(c.RequestCtx(), w, filter)
+		return err
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to export jobs", "job export failed", err)
+	}
+
+	filename := fmt.Sprintf("jobs-%s.csv", time.Now().UTC().Format(time.DateOnly))
+	return sendExportFile(c, path, filename, "text/csv; charset=utf-8")
 }
 
 // Get retrieves a single job by ID
@@ -74,16 +126,98 @@ func (h *JobsHandler) Get(c fiber.Ctx) error {
 	return c.JSON(job)
 }
 
-// Cleanup removes old jobs based on retention period
+// Result returns the stored result of a finished job, such as an async resort's
+// movements list
+func (h *JobsHandler) Result(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	result, err := h.service.Result(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "No result for this job")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to retrieve job result", "job result query failed", err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(result)
+}
+
+// Cancel stops a pending or running job
+func (h *JobsHandler) Cancel(c fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	job, err := h.service.Cancel(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "Job not found")
+		}
+		if errors.Is(err, services.ErrJobNotCancellable) {
+			return utils.ReturnError(c, fiber.StatusConflict, "Only pending or running jobs can be cancelled")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to cancel job", "job cancel failed", err)
+	}
+
+	return c.JSON(job)
+}
+
+// Retry runs a failed bulk or set data import again under the same job. The job runs
+// under appCtx, since it outlives the request.
+func (h *JobsHandler) Retry(c fiber.Ctx, appCtx context.Context) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid job ID")
+	}
+
+	job, err := h.service.Retry(c.RequestCtx(), appCtx, uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "Job not found")
+		}
+		if errors.Is(err, services.ErrJobNotRetryable) {
+			return utils.ReturnError(c, fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrWorkersStopped) {
+			return utils.ReturnError(c, fiber.StatusServiceUnavailable, "Server is shutting down")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to retry job", "job retry failed", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// startJobError responds to a job that couldn't be handed to the worker pool
+func startJobError(c fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrWorkersStopped) {
+		return utils.ReturnError(c, fiber.StatusServiceUnavailable, "Server is shutting down")
+	}
+	return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+		"Failed to start job", "job start failed", err)
+}
+
+// Cleanup removes old jobs under the configured retention policy. The retention_days
+// query param overrides the policy's default retention; per-type rules still apply.
 func (h *JobsHandler) Cleanup(c fiber.Ctx) error {
-	// Default to 30 days retention
-	retentionDays, _ := strconv.Atoi(c.Query("retention_days", strconv.Itoa(DefaultJobRetentionDays)))
+	policy := h.service.RetentionPolicy(c.RequestCtx())
+	if c.Query("retention_days") != "" {
+		policy.RetentionDays, _ = strconv.Atoi(c.Query("retention_days"))
+	}
+	retentionDays := policy.RetentionDays
 
 	if retentionDays < 1 {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "Retention days must be at least 1")
 	}
 
-	deletedCount, err := h.service.CleanupOldJobs(c.RequestCtx(), retentionDays)
+	deletedCount, err := h.service.ApplyRetention(c.RequestCtx(), policy)
 	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to cleanup jobs", "job cleanup failed", err)
@@ -3,10 +3,13 @@ package api
 import (
 	"backend/models"
 	"backend/services"
+	"backend/utils"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,16 +26,24 @@ func setupJobsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Job{}); err != nil {
+	if err := db.AutoMigrate(&models.Job{}, &models.JobResult{}, &models.Setting{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
 	jobService := services.NewJobService(db)
 	handler := NewJobsHandler(jobService)
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), ""))
 
 	app := fiber.New()
 	app.Get("/jobs", handler.GetAll)
+	app.Get("/jobs/export", handler.Export)
 	app.Get("/jobs/:id", handler.Get)
+	app.Get("/jobs/:id/result", handler.Result)
+	app.Post("/jobs/:id/cancel", handler.Cancel)
+	app.Delete("/jobs/cleanup", handler.Cleanup)
+	app.Post("/jobs/:id/retry", func(c fiber.Ctx) error {
+		return handler.Retry(c, context.Background())
+	})
 
 	return app, db
 }
@@ -564,3 +575,342 @@ func TestJobsList_OrderedByCreatedAtDesc(t *testing.T) {
 		t.Errorf("expected oldest job last, got metadata: %s", lastMetadata)
 	}
 }
+
+// Cancel tests
+
+func TestJobsCancel_Success(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	job := &models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusInProgress}
+	db.Create(job)
+
+	req := httptest.NewRequest("POST", "/jobs/"+strconv.Itoa(int(job.ID))+"/cancel", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status %d, got %d. Body: %s", fiber.StatusOK, resp.StatusCode, string(body))
+	}
+
+	var returnedJob models.Job
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &returnedJob); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if returnedJob.Status != models.JobStatusCancelled {
+		t.Errorf("expected status %s, got %s", models.JobStatusCancelled, returnedJob.Status)
+	}
+}
+
+func TestJobsCancel_Errors(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	completed := &models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusCompleted}
+	db.Create(completed)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"finished job", "/jobs/" + strconv.Itoa(int(completed.ID)) + "/cancel", fiber.StatusConflict},
+		{"not found", "/jobs/999/cancel", fiber.StatusNotFound},
+		{"invalid id", "/jobs/invalid/cancel", fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("POST", tt.path, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// Result tests
+
+func TestJobsResult(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	job := &models.Job{Type: models.JobTypeResort, Status: models.JobStatusCompleted}
+	db.Create(job)
+	db.Create(&models.JobResult{JobID: job.ID, Content: `{"processed":3}`})
+	pending := &models.Job{Type: models.JobTypeResort, Status: models.JobStatusPending}
+	db.Create(pending)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/jobs/"+strconv.Itoa(int(job.ID))+"/result", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != fiber.StatusOK || string(body) != `{"processed":3}` {
+		t.Errorf("expected the stored result, got %d: %s", resp.StatusCode, body)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Errorf("expected a JSON content type, got %q", contentType)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"no result yet", "/jobs/" + strconv.Itoa(int(pending.ID)) + "/result", fiber.StatusNotFound},
+		{"not found", "/jobs/999/result", fiber.StatusNotFound},
+		{"invalid id", "/jobs/invalid/result", fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// Export tests
+
+func TestJobsExport_CSV(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusFailed, Error: "download failed"})
+	db.Create(&models.Job{Type: models.JobTypeSetDataImport, Status: models.JobStatusCompleted})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/jobs/export?format=csv&status=failed", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+	if ct := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected a CSV content type, got %q", ct)
+	}
+	if cd := resp.Header.Get(fiber.HeaderContentDisposition); !strings.Contains(cd, `filename="jobs-`) {
+		t.Errorf("expected a jobs download, got %q", cd)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and 1 job, got %q", body)
+	}
+	if !strings.HasPrefix(lines[0], "id,type,status") || !strings.Contains(lines[1], "bulk_data_import,failed") ||
+		!strings.HasSuffix(lines[1], "download failed") {
+		t.Errorf("unexpected export: %q", body)
+	}
+}
+
+func TestJobsExport_RangeRequests(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusFailed, Error: "download failed"})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/jobs/export", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	full, _ := io.ReadAll(resp.Body)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	if etag == "" || resp.Header.Get(fiber.HeaderAcceptRanges) != "bytes" {
+		t.Fatalf("expected a resumable download, got ETag %q and Accept-Ranges %q", etag, resp.Header.Get(fiber.HeaderAcceptRanges))
+	}
+
+	req := httptest.NewRequest("GET", "/jobs/export", nil)
+	req.Header.Set(fiber.HeaderRange, "bytes=5-")
+	req.Header.Set(fiber.HeaderIfRange, etag)
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusPartialContent {
+		t.Fatalf("expected status %d, got %d", fiber.StatusPartialContent, resp.StatusCode)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != string(full[5:]) {
+		t.Errorf("expected the remainder of the export, got %q", body)
+	}
+}
+
+func TestJobsExport_Encrypted(t *testing.T) {
+	_, db := setupJobsTestApp(t)
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusFailed, Error: "download failed"})
+
+	handler := NewJobsHandler(services.NewJobService(db))
+	handler.SetExportFiles(NewExportFiles(t.TempDir(), "nas-passphrase"))
+	app := fiber.New()
+	app.Get("/jobs/export", handler.Export)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/jobs/export", nil), encryptedTestConfig)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if cd := resp.Header.Get(fiber.HeaderContentDisposition); !strings.HasSuffix(cd, `.csv.enc"`) {
+		t.Errorf("expected a .csv.enc download, got %q", cd)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	plaintext, err := utils.DecryptWithPassphrase(body, "nas-passphrase")
+	if err != nil {
+		t.Fatalf("expected the export encrypted with the passphrase: %v", err)
+	}
+	if !strings.HasPrefix(string(plaintext), "id,type,status") {
+		t.Errorf("unexpected export: %q", plaintext)
+	}
+}
+
+func TestJobsExport_Errors(t *testing.T) {
+	app, _ := setupJobsTestApp(t)
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"unknown format", "/jobs/export?format=json"},
+		{"unknown type", "/jobs/export?type=backup"},
+		{"unknown status", "/jobs/export?status=stuck"},
+		{"malformed since", "/jobs/export?since=March"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", fiber.StatusBadRequest, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// Cleanup tests
+
+func TestJobsCleanup_AppliesRetentionSettings(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	db.Create(&models.Setting{Key: "job_cleanup_keep_failed", Value: "true"})
+	failed := models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusFailed}
+	completed := models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusCompleted}
+	recent := models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusCompleted}
+	for _, job := range []*models.Job{&failed, &completed, &recent} {
+		db.Create(job)
+	}
+	db.Model(&models.Job{}).Where("id IN ?", []uint{failed.ID, completed.ID}).
+		Update("created_at", time.Now().AddDate(0, 0, -10))
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/jobs/cleanup?retention_days=7", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	var result struct {
+		DeletedCount  int64 `json:"deleted_count"`
+		RetentionDays int   `json:"retention_days"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.DeletedCount != 1 || result.RetentionDays != 7 {
+		t.Errorf("expected 1 job deleted at 7 days, got %+v", result)
+	}
+
+	var remaining []models.Job
+	db.Order("id").Find(&remaining)
+	if len(remaining) != 2 || remaining[0].ID != failed.ID || remaining[1].ID != recent.ID {
+		t.Errorf("expected the failed and recent jobs to remain, got %+v", remaining)
+	}
+}
+
+func TestJobsCleanup_InvalidRetentionDays(t *testing.T) {
+	app, _ := setupJobsTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("DELETE", "/jobs/cleanup?retention_days=0", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", fiber.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+// Retry tests
+
+func TestJobsRetry_FailedJob(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Job{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	jobService := services.NewJobService(db)
+	ran := make(chan uint, 1)
+	jobService.RegisterRetry(models.JobTypeSetDataImport, func(ctx context.Context, job *models.Job) error {
+		ran <- job.ID
+		return nil
+	})
+	handler := NewJobsHandler(jobService)
+	app := fiber.New()
+	app.Post("/jobs/:id/retry", func(c fiber.Ctx) error {
+		return handler.Retry(c, context.Background())
+	})
+
+	job := models.Job{Type: models.JobTypeSetDataImport, Status: models.JobStatusFailed, Error: "failed to list sets"}
+	db.Create(&job)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/jobs/"+strconv.Itoa(int(job.ID))+"/retry", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", fiber.StatusAccepted, resp.StatusCode)
+	}
+	var retried models.Job
+	if err := json.NewDecoder(resp.Body).Decode(&retried); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if retried.ID != job.ID || retried.Status != models.JobStatusPending {
+		t.Errorf("expected job %d back to pending, got %+v", job.ID, retried)
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the job to run again")
+	}
+}
+
+func TestJobsRetry_NotRetryable(t *testing.T) {
+	app, db := setupJobsTestApp(t)
+
+	completed := models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusCompleted}
+	db.Create(&completed)
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/jobs/" + strconv.Itoa(int(completed.ID)) + "/retry", fiber.StatusConflict},
+		{"/jobs/9999/retry", fiber.StatusNotFound},
+		{"/jobs/abc/retry", fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest("POST", tt.path, nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.path, tt.status, resp.StatusCode)
+		}
+	}
+}
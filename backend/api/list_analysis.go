@@ -0,0 +1,49 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Analyze suggests archetype tags for a list and reports its overlap with other lists,
+// flagging shared cards where the owned copies can't cover every list at once
+func (h *ListHandler) Analyze(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	analysis, err := services.NewListAnalysisService(h.db).Analyze(c.RequestCtx(), list.ID)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to analyze list", "list analysis failed", err)
+	}
+
+	return c.JSON(analysis)
+}
+
+// Contention lists cards wanted by several lists where the owned copies can't cover
+// all of them at once, with a suggested allocation of the owned copies per list
+func (h *ListHandler) Contention(c fiber.Ctx) error {
+	cards, err := services.NewListAnalysisService(h.db).Contention(c.RequestCtx())
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to analyze list contention", "list contention failed", err)
+	}
+
+	return c.JSON(cards)
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestListAnalyze(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+	handler := NewListHandler(db)
+	app.Get("/lists/:id/analysis", handler.Analyze)
+
+	createTestCardForList(t, db, "bolt-id", "Lightning Bolt", "1.00", "")
+	burn := createTestList(t, db, "Burn")
+	jund := createTestList(t, db, "Jund")
+	createTestListItem(t, db, burn.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 0)
+	createTestListItem(t, db, jund.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 2, 0)
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/lists/%d/analysis", burn.ID), nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result services.ListAnalysis
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.ListID != burn.ID {
+		t.Errorf("expected list id %d, got %d", burn.ID, result.ListID)
+	}
+	if len(result.Similar) != 1 || result.Similar[0].ListID != jund.ID {
+		t.Errorf("expected Jund as the only similar list, got %+v", result.Similar)
+	}
+	if len(result.SharedCards) != 1 || result.SharedCards[0].Needed != 6 || result.SharedCards[0].Name != "Lightning Bolt" {
+		t.Errorf("expected one shared card needing 6 copies, got %+v", result.SharedCards)
+	}
+}
+
+func TestListAnalyze_Validation(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+	handler := NewListHandler(db)
+	app.Get("/lists/:id/analysis", handler.Analyze)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected int
+	}{
+		{"Invalid id", "/lists/abc/analysis", fiber.StatusBadRequest},
+		{"Missing list", "/lists/999/analysis", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestListContention(t *testing.T) {
+	_, db := setupListTestAppWithCards(t)
+	handler := NewListHandler(db)
+	// Fresh app: the shared setup registers /lists/:id, which would shadow this route
+	app := fiber.New()
+	app.Get("/lists/contention", handler.Contention)
+
+	createTestCardForList(t, db, "bolt-id", "Lightning Bolt", "1.00", "")
+	burn := createTestList(t, db, "Burn")
+	jund := createTestList(t, db, "Jund")
+	createTestListItem(t, db, burn.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 0)
+	createTestListItem(t, db, jund.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 2, 0)
+	if err := db.Create(&models.Inventory{ScryfallID: "bolt-id", OracleID: "oracle-bolt-id", Treatment: "nonfoil", Quantity: 3}).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/lists/contention", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result []services.ContendedCard
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(result) != 1 || result[0].Name != "Lightning Bolt" || result[0].Shortfall != 3 {
+		t.Fatalf("expected Lightning Bolt short by 3, got %+v", result)
+	}
+	// Jund's 2 copies are filled before Burn's 4
+	for _, list := range result[0].Lists {
+		expected := map[uint]int{burn.ID: 1, jund.ID: 2}[list.ListID]
+		if list.Allocated != expected {
+			t.Errorf("list %s: expected %d allocated, got %d", list.ListName, expected, list.Allocated)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// CollectListItemRequest represents the request body for collecting a list item into inventory
+// tygo:export
+type CollectListItemRequest struct {
+	Quantity          int   `json:"quantity"`                      // Copies acquired; defaults to 1
+	StorageLocationID *uint `json:"storage_location_id,omitempty"` // Omit to auto-sort; 0 leaves them unassigned
+}
+
+// CollectListItemResponse returns the updated list item and the inventory row that received the copies
+// tygo:export
+type CollectListItemResponse struct {
+	Item      models.ListItem  `json:"item"`
+	Inventory models.Inventory `json:"inventory"`
+}
+
+// Collect records copies of a list item as acquired: it adds them to inventory,
+// merging into a matching row in the same location, and raises the item's collected
+// quantity (capped at desired) in the same transaction
+func (h *ListHandler) Collect(c fiber.Ctx) error {
+	listID := fiber.Params[int](c, "id")
+	if listID <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid list id")
+	}
+
+	itemID := fiber.Params[int](c, "item_id")
+	if itemID <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid item id")
+	}
+
+	var req CollectListItemRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+	if err := utils.ValidateNonNegative(req.Quantity, "quantity"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	ctx := c.RequestCtx()
+
+	var list models.List
+	if err := h.db.WithContext(ctx).First(&list, listID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	var item models.ListItem
+	if err := h.db.WithContext(ctx).Where("id = ? AND list_id = ?", itemID, listID).First(&item).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list item not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list item", "database query failed", err)
+	}
+
+	// Resolve the location before the transaction; auto-sort reads through its own handle
+	locationID := req.StorageLocationID
+	if locationID != nil && *locationID == models.UnassignedLocationID {
+		locationID = nil
+	} else if locationID != nil {
+		var location models.StorageLocation
+		if err := h.db.WithContext(ctx).First(&location, *locationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to validate storage location", "storage location lookup failed", err)
+		}
+	} else {
+		assigned, err := services.NewAutoSortService(h.db).DetermineStorageLocation(ctx, item.ScryfallID, item.Treatment, "", req.Quantity)
+		if err != nil {
+			slog.DebugContext(c.RequestCtx(), "auto-sort did not assign location", "component", "lists", "scryfall_id", item.ScryfallID, "error", err)
+		} else {
+			locationID = assigned
+		}
+	}
+
+	var inventory models.Inventory
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Merge into a plain row for the same printing and place; serialized and annotated copies stay separate
+		query := tx.Where("scryfall_id = ? AND treatment = ? AND serial_number IS NULL AND COALESCE(notes, '') = ''", item.ScryfallID, item.Treatment)
+		if locationID == nil {
+			query = query.Where("storage_location_id IS NULL")
+		} else {
+			query = query.Where("storage_location_id = ?", *locationID)
+		}
+
+		var existing models.Inventory
+		err := query.Order("id").Take(&existing).Error
+		switch {
+		case err == nil:
+			inventory = existing
+			inventory.Quantity += req.Quantity
+			if err := tx.Save(&inventory).Error; err != nil {
+				return err
+			}
+			if err := models.RecordInventoryEvents(tx, models.InventoryChangeEvents(existing, inventory)); err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			inventory = models.Inventory{
+				ScryfallID:        item.ScryfallID,
+				OracleID:          item.OracleID,
+				Treatment:         item.Treatment,
+				Quantity:          req.Quantity,
+				StorageLocationID: locationID,
+			}
+			if err := tx.Create(&inventory).Error; err != nil {
+				return err
+			}
+			if err := models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryCreatedEvent(inventory)}); err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		// Auto-tracked lists pick up the new copies from inventory below
+		if list.AutoTrackInventory {
+			return nil
+		}
+		item.CollectedQuantity = min(item.CollectedQuantity+req.Quantity, item.DesiredQuantity)
+		return tx.Save(&item).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to collect list item", "database update failed", err)
+	}
+
+	if list.AutoTrackInventory {
+		h.syncTrackedList(ctx, list)
+		if err := h.db.WithContext(ctx).First(&item, item.ID).Error; err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to reload list item", "database query failed", err)
+		}
+	}
+
+	if err := h.db.WithContext(ctx).Preload("StorageLocation").First(&inventory, inventory.ID).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to reload inventory item", "database query failed", err)
+	}
+
+	return c.JSON(CollectListItemResponse{Item: item, Inventory: inventory})
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListCollectTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&models.List{},
+		&models.ListItem{},
+		&models.Card{},
+		&models.StorageLocation{},
+		&models.SortingRule{},
+		&models.Inventory{},
+		&models.InventoryEvent{},
+		&models.Setting{},
+		&models.Loan{},
+		&models.LoanItem{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewListHandler(db)
+	app.Post("/lists/:id/items/:item_id/collect", handler.Collect)
+
+	return app, db
+}
+
+func collectListItem(t *testing.T, app *fiber.App, listID, itemID uint, body any) (*http.Response, CollectListItemResponse) {
+	t.Helper()
+
+	resp := sendListSyncRequest(t, app, http.MethodPost, fmt.Sprintf("/lists/%d/items/%d/collect", listID, itemID), body)
+	defer resp.Body.Close()
+
+	var result CollectListItemResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	return resp, result
+}
+
+func TestListCollect_AutoSortAndMerge(t *testing.T) {
+	app, db := setupListCollectTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Cheap Cards", 1, "prices.usd < 5.0", location.ID)
+
+	list := createTestList(t, db, "Burn")
+	item := createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 3, 0)
+
+	resp, first := collectListItem(t, app, list.ID, item.ID, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if first.Item.CollectedQuantity != 1 {
+		t.Errorf("expected collected 1, got %d", first.Item.CollectedQuantity)
+	}
+	if first.Inventory.Quantity != 1 || first.Inventory.StorageLocationID == nil || *first.Inventory.StorageLocationID != location.ID {
+		t.Errorf("expected 1 copy auto-sorted into location %d, got %+v", location.ID, first.Inventory)
+	}
+
+	// A second collect merges into the same row and caps collected at desired
+	resp, second := collectListItem(t, app, list.ID, item.ID, CollectListItemRequest{Quantity: 4})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if second.Inventory.ID != first.Inventory.ID || second.Inventory.Quantity != 5 {
+		t.Errorf("expected row %d to hold 5 copies, got row %d with %d", first.Inventory.ID, second.Inventory.ID, second.Inventory.Quantity)
+	}
+	if second.Item.CollectedQuantity != 3 {
+		t.Errorf("expected collected capped at 3, got %d", second.Item.CollectedQuantity)
+	}
+
+	var events []models.InventoryEvent
+	db.Order("id").Find(&events)
+	if len(events) != 2 || events[0].EventType != models.InventoryEventCreated || events[1].EventType != models.InventoryEventQuantityChanged {
+		t.Errorf("expected created then quantity_changed events, got %+v", events)
+	}
+}
+
+func TestListCollect_Unassigned(t *testing.T) {
+	app, db := setupListCollectTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Cheap Cards", 1, "prices.usd < 5.0", location.ID)
+
+	list := createTestList(t, db, "Burn")
+	item := createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 2, 0)
+
+	unassigned := models.UnassignedLocationID
+	resp, result := collectListItem(t, app, list.ID, item.ID, CollectListItemRequest{StorageLocationID: &unassigned})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if result.Inventory.StorageLocationID != nil {
+		t.Errorf("expected the copy to stay unassigned, got location %d", *result.Inventory.StorageLocationID)
+	}
+}
+
+func TestListCollect_AutoTrackedList(t *testing.T) {
+	app, db := setupListCollectTestApp(t)
+
+	list := models.List{Name: "Tracked", AutoTrackInventory: true}
+	db.Create(&list)
+	item := createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 1)
+	db.Create(&models.Inventory{ScryfallID: "bolt-id", OracleID: "oracle-bolt-id", Treatment: "nonfoil", Quantity: 1, Notes: "signed"})
+
+	resp, result := collectListItem(t, app, list.ID, item.ID, CollectListItemRequest{Quantity: 2})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if result.Inventory.Quantity != 2 || result.Inventory.Notes != "" {
+		t.Errorf("expected a new plain row with 2 copies, got %+v", result.Inventory)
+	}
+	if result.Item.CollectedQuantity != 3 {
+		t.Errorf("expected collected synced to 3 owned copies, got %d", result.Item.CollectedQuantity)
+	}
+}
+
+func TestListCollect_Errors(t *testing.T) {
+	app, db := setupListCollectTestApp(t)
+
+	list := createTestList(t, db, "Burn")
+	item := createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 2, 0)
+	other := createTestList(t, db, "Other")
+	missingLocation := uint(999)
+
+	tests := []struct {
+		name     string
+		listID   uint
+		itemID   uint
+		body     any
+		expected int
+	}{
+		{"List not found", 999, item.ID, nil, http.StatusNotFound},
+		{"Item in another list", other.ID, item.ID, nil, http.StatusNotFound},
+		{"Negative quantity", list.ID, item.ID, CollectListItemRequest{Quantity: -1}, http.StatusBadRequest},
+		{"Unknown location", list.ID, item.ID, CollectListItemRequest{StorageLocationID: &missingLocation}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := collectListItem(t, app, tt.listID, tt.itemID, tt.body)
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// DuplicateListRequest represents the optional request body for duplicating a list
+// tygo:export
+type DuplicateListRequest struct {
+	Name        string  `json:"name,omitempty"`        // Defaults to the source name with " (copy)" appended
+	Description *string `json:"description,omitempty"` // Defaults to the source description
+}
+
+// MergeListRequest represents the request body for merging one list into another
+// tygo:export
+type MergeListRequest struct {
+	SourceListID uint `json:"source_list_id"`
+	DeleteSource bool `json:"delete_source"` // Delete the source list once its items are merged
+}
+
+// MergeListResponse reports the merged list and how its items changed
+// tygo:export
+type MergeListResponse struct {
+	List   models.List `json:"list"`
+	Added  int         `json:"added"`  // Source items that were new to the list
+	Merged int         `json:"merged"` // Source items summed into an existing item
+}
+
+// Duplicate creates a new list with the same settings and items as an existing one,
+// so variant decks can start from a base list
+func (h *ListHandler) Duplicate(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req DuplicateListRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	ctx := c.RequestCtx()
+
+	var source models.List
+	if err := h.db.WithContext(ctx).Preload("Items").First(&source, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	list := models.List{
+		Name:               req.Name,
+		Description:        source.Description,
+		AutoTrackInventory: source.AutoTrackInventory,
+	}
+	if list.Name == "" {
+		list.Name = source.Name + " (copy)"
+	}
+	if req.Description != nil {
+		list.Description = *req.Description
+	}
+
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(list.Name, 255, "name"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(list.Description, 1000, "description"))
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		if len(source.Items) == 0 {
+			return nil
+		}
+
+		items := make([]models.ListItem, len(source.Items))
+		for i, item := range source.Items {
+			items[i] = models.ListItem{
+				ListID:            list.ID,
+				ScryfallID:        item.ScryfallID,
+				OracleID:          item.OracleID,
+				Treatment:         item.Treatment,
+				DesiredQuantity:   item.DesiredQuantity,
+				CollectedQuantity: item.CollectedQuantity,
+			}
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to duplicate list", "database insert failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(list)
+}
+
+// Merge adds another list's items to this one. Items for the same printing and
+// treatment are combined by summing desired and collected quantities; the rest are
+// added as new items.
+func (h *ListHandler) Merge(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req MergeListRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if req.SourceListID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "source_list_id is required")
+	}
+	if req.SourceListID == uint(id) {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "cannot merge a list into itself")
+	}
+
+	ctx := c.RequestCtx()
+
+	var list models.List
+	if err := h.db.WithContext(ctx).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	var source models.List
+	if err := h.db.WithContext(ctx).Preload("Items").First(&source, req.SourceListID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "source list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch source list", "database query failed", err)
+	}
+
+	response := MergeListResponse{}
+	err := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []models.ListItem
+		if err := tx.Where("list_id = ?", list.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byPrinting := make(map[string]*models.ListItem, len(existing))
+		for i := range existing {
+			byPrinting[existing[i].ScryfallID+"|"+existing[i].Treatment] = &existing[i]
+		}
+
+		for _, item := range source.Items {
+			if target, ok := byPrinting[item.ScryfallID+"|"+item.Treatment]; ok {
+				target.DesiredQuantity += item.DesiredQuantity
+				target.CollectedQuantity += item.CollectedQuantity
+				if err := tx.Save(target).Error; err != nil {
+					return err
+				}
+				response.Merged++
+				continue
+			}
+
+			added := models.ListItem{
+				ListID:            list.ID,
+				ScryfallID:        item.ScryfallID,
+				OracleID:          item.OracleID,
+				Treatment:         item.Treatment,
+				DesiredQuantity:   item.DesiredQuantity,
+				CollectedQuantity: item.CollectedQuantity,
+			}
+			if err := tx.Create(&added).Error; err != nil {
+				return err
+			}
+			byPrinting[added.ScryfallID+"|"+added.Treatment] = &added
+			response.Added++
+		}
+
+		if !req.DeleteSource {
+			return nil
+		}
+		if err := tx.Where("list_id = ?", source.ID).Delete(&models.ListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to merge lists", "database update failed", err)
+	}
+
+	// Summed collected quantities can overcount what is owned, so tracked lists recount
+	if list.AutoTrackInventory {
+		h.syncTrackedList(ctx, list)
+	}
+
+	response.List = list
+	return c.JSON(response)
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListCopyTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.Inventory{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewListHandler(db)
+	app.Post("/lists/:id/duplicate", handler.Duplicate)
+	app.Post("/lists/:id/merge", handler.Merge)
+
+	return app, db
+}
+
+func TestListDuplicate(t *testing.T) {
+	app, db := setupListCopyTestApp(t)
+
+	base := createTestList(t, db, "Burn")
+	createTestListItem(t, db, base.ID, "bolt-id", "oracle-bolt", "nonfoil", 4, 2)
+	createTestListItem(t, db, base.ID, "guide-id", "oracle-guide", "foil", 1, 0)
+
+	resp := sendListSyncRequest(t, app, http.MethodPost, fmt.Sprintf("/lists/%d/duplicate", base.ID), nil)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var list models.List
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.ID == base.ID || list.Name != "Burn (copy)" {
+		t.Errorf("expected a new list named %q, got %+v", "Burn (copy)", list)
+	}
+
+	var items []models.ListItem
+	db.Where("list_id = ?", list.ID).Order("scryfall_id").Find(&items)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 copied items, got %d", len(items))
+	}
+	if items[0].ScryfallID != "bolt-id" || items[0].DesiredQuantity != 4 || items[0].CollectedQuantity != 2 {
+		t.Errorf("unexpected copied item: %+v", items[0])
+	}
+
+	// The source list is untouched
+	var count int64
+	db.Model(&models.ListItem{}).Where("list_id = ?", base.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("expected source list to keep 2 items, got %d", count)
+	}
+}
+
+func TestListDuplicate_Name(t *testing.T) {
+	app, db := setupListCopyTestApp(t)
+
+	base := createTestList(t, db, "Burn")
+
+	resp := sendListSyncRequest(t, app, http.MethodPost, fmt.Sprintf("/lists/%d/duplicate", base.ID), map[string]any{"name": "Burn (budget)"})
+	defer resp.Body.Close()
+
+	var list models.List
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.Name != "Burn (budget)" {
+		t.Errorf("expected requested name, got %q", list.Name)
+	}
+}
+
+func TestListMerge(t *testing.T) {
+	app, db := setupListCopyTestApp(t)
+
+	target := createTestList(t, db, "Burn")
+	createTestListItem(t, db, target.ID, "bolt-id", "oracle-bolt", "nonfoil", 4, 2)
+	source := createTestList(t, db, "Sideboard")
+	createTestListItem(t, db, source.ID, "bolt-id", "oracle-bolt", "nonfoil", 2, 1)
+	createTestListItem(t, db, source.ID, "bolt-id", "oracle-bolt", "foil", 1, 0)
+	createTestListItem(t, db, source.ID, "pyro-id", "oracle-pyro", "nonfoil", 3, 0)
+
+	resp := sendListSyncRequest(t, app, http.MethodPost, fmt.Sprintf("/lists/%d/merge", target.ID),
+		map[string]any{"source_list_id": source.ID, "delete_source": true})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result MergeListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Added != 2 || result.Merged != 1 {
+		t.Errorf("expected 2 added and 1 merged, got %+v", result)
+	}
+
+	var bolt models.ListItem
+	db.Where("list_id = ? AND scryfall_id = ? AND treatment = ?", target.ID, "bolt-id", "nonfoil").First(&bolt)
+	if bolt.DesiredQuantity != 6 || bolt.CollectedQuantity != 3 {
+		t.Errorf("expected summed quantities 6/3, got %d/%d", bolt.DesiredQuantity, bolt.CollectedQuantity)
+	}
+
+	var count int64
+	db.Model(&models.ListItem{}).Where("list_id = ?", target.ID).Count(&count)
+	if count != 3 {
+		t.Errorf("expected 3 items after merge, got %d", count)
+	}
+	db.Model(&models.List{}).Where("id = ?", source.ID).Count(&count)
+	if count != 0 {
+		t.Error("expected source list deleted")
+	}
+}
+
+func TestListMerge_Errors(t *testing.T) {
+	app, db := setupListCopyTestApp(t)
+
+	list := createTestList(t, db, "Burn")
+
+	tests := []struct {
+		name   string
+		url    string
+		body   any
+		status int
+	}{
+		{"Missing source", fmt.Sprintf("/lists/%d/merge", list.ID), map[string]any{}, http.StatusBadRequest},
+		{"Merge into itself", fmt.Sprintf("/lists/%d/merge", list.ID), map[string]any{"source_list_id": list.ID}, http.StatusBadRequest},
+		{"Unknown source", fmt.Sprintf("/lists/%d/merge", list.ID), map[string]any{"source_list_id": 999}, http.StatusNotFound},
+		{"Unknown target", "/lists/999/merge", map[string]any{"source_list_id": list.ID}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := sendListSyncRequest(t, app, http.MethodPost, tt.url, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Export renders a list's items as a deck list other tools can import: MTGA or
+// Moxfield text, plain "qty name" text, or CSV (format query parameter, default arena)
+func (h *ListHandler) Export(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	format := services.ListExportArena
+	if value := c.Query("format"); value != "" {
+		parsed, err := services.ParseListExportFormat(value)
+		if err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		format = parsed
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := services.ExportList(c.RequestCtx(), h.db, list.ID, format, &buf); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to export list", "list export failed", err)
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s%s"`, listExportFilename(list), format.Extension()))
+	return c.Send(buf.Bytes())
+}
+
+// listExportFilename turns a list name into a safe download file name
+func listExportFilename(list models.List) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, strings.TrimSpace(list.Name))
+	name = strings.Trim(name, "-")
+	if name == "" {
+		return fmt.Sprintf("list-%d", list.ID)
+	}
+	return name
+}
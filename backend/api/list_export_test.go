@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/database/databasetest"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupListExportTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	app := fiber.New()
+	handler := NewListHandler(db)
+	app.Get("/lists/:id/export", handler.Export)
+
+	return app, db
+}
+
+func TestListExport(t *testing.T) {
+	app, db := setupListExportTestApp(t)
+
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	list := createTestList(t, db, "Burn: Budget")
+	createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 0)
+
+	tests := []struct {
+		query       string
+		contentType string
+		filename    string
+		body        string
+	}{
+		{"", "text/plain; charset=utf-8", "Burn--Budget.txt", "4 Lightning Bolt (LEA)\n"},
+		{"?format=txt", "text/plain; charset=utf-8", "Burn--Budget.txt", "4 Lightning Bolt\n"},
+		{"?format=csv", "text/csv; charset=utf-8", "Burn--Budget.csv", "count,name,set_code,collector_number,finish,scryfall_id\n4,Lightning Bolt,lea,,nonfoil,bolt-id\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run("format"+tt.query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/export%s", list.ID, tt.query), nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+			if got := resp.Header.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("expected content type %q, got %q", tt.contentType, got)
+			}
+			if got := resp.Header.Get("Content-Disposition"); got != fmt.Sprintf(`attachment; filename="%s"`, tt.filename) {
+				t.Errorf("unexpected content disposition %q", got)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.body {
+				t.Errorf("unexpected body %q", body)
+			}
+		})
+	}
+}
+
+func TestListExport_Errors(t *testing.T) {
+	app, db := setupListExportTestApp(t)
+
+	list := createTestList(t, db, "Burn")
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"Unknown format", fmt.Sprintf("/lists/%d/export?format=mtgo", list.ID), http.StatusBadRequest},
+		{"Unknown list", "/lists/999/export", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ParseListItemsRequest represents the request body for parsing a pasted deck list
+// tygo:export
+type ParseListItemsRequest struct {
+	Text string `json:"text"`
+}
+
+// ParseListItemsResponse reports how each line of a pasted deck list resolved
+// tygo:export
+type ParseListItemsResponse struct {
+	Matched    int                           `json:"matched"`
+	Ambiguous  int                           `json:"ambiguous"`
+	Unresolved int                           `json:"unresolved"`
+	Results    []services.DeckListLineResult `json:"results"`
+}
+
+// ParseItems resolves a plain-text deck list (e.g. "4 Lightning Bolt (LEA) 161") against
+// the local card database without adding anything to the list. Clients review the
+// matches, pick from ambiguous options, then add items with CreateItemsBatch.
+func (h *ListHandler) ParseItems(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	var req ParseListItemsRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if err := utils.ValidateRequired(req.Text, "text"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	results, err := services.ResolveDeckList(c.RequestCtx(), h.db, req.Text)
+	if err != nil {
+		if errors.Is(err, services.ErrTooManyLines) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to parse deck list", "deck list resolution failed", err)
+	}
+
+	response := ParseListItemsResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case services.DeckListLineMatched:
+			response.Matched++
+		case services.DeckListLineAmbiguous:
+			response.Ambiguous++
+		default:
+			response.Unresolved++
+		}
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestListParseItems(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+	handler := NewListHandler(db)
+	app.Post("/lists/:id/items/parse", handler.ParseItems)
+
+	list := createTestList(t, db, "Wishlist")
+	card := models.Card{ScryfallID: "bolt-lea", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"lea","collector_number":"161"}`}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	body := `{"text":"4 Lightning Bolt (LEA) 161\n1 Black Lotus"}`
+	req := httptest.NewRequest("POST", fmt.Sprintf("/lists/%d/items/parse", list.ID), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var result ParseListItemsResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.Matched != 1 || result.Unresolved != 1 {
+		t.Errorf("expected 1 matched and 1 unresolved, got %+v", result)
+	}
+	if result.Results[0].Status != services.DeckListLineMatched || result.Results[0].Match.ScryfallID != "bolt-lea" {
+		t.Errorf("unexpected first result: %+v", result.Results[0])
+	}
+
+	var count int64
+	db.Model(&models.ListItem{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected parsing to add no items, got %d", count)
+	}
+}
+
+func TestListParseItems_Validation(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+	handler := NewListHandler(db)
+	app.Post("/lists/:id/items/parse", handler.ParseItems)
+
+	list := createTestList(t, db, "Wishlist")
+
+	tests := []struct {
+		name           string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{"Missing list", "/lists/999/items/parse", `{"text":"1 Sol Ring"}`, fiber.StatusNotFound},
+		{"Empty text", fmt.Sprintf("/lists/%d/items/parse", list.ID), `{"text":""}`, fiber.StatusBadRequest},
+		{"Invalid body", fmt.Sprintf("/lists/%d/items/parse", list.ID), `{`, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// CreateListShareRequest represents the request body for inviting a collaborator to a list
+// tygo:export
+type CreateListShareRequest struct {
+	Collaborator string `json:"collaborator"`
+}
+
+// ListShares returns every invite created for a list, newest first
+func (h *ListHandler) ListShares(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).First(&models.List{}, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	shares := []models.ListShare{}
+	if err := h.db.WithContext(c.RequestCtx()).Where("list_id = ?", id).
+		Order("created_at DESC").Find(&shares).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list shares", "database query failed", err)
+	}
+
+	return c.JSON(shares)
+}
+
+// CreateShare creates an invite token that lets a named collaborator edit the list
+func (h *ListHandler) CreateShare(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req CreateListShareRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateRequired(req.Collaborator, "collaborator"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Collaborator, 100, "collaborator"))
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create share", "token generation failed", err)
+	}
+
+	share := models.ListShare{
+		ListID:       list.ID,
+		Token:        token,
+		Collaborator: req.Collaborator,
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Create(&share).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create share", "database insert failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(share)
+}
+
+// RevokeShare stops an invite token from being used. The share is kept so
+// existing change attribution still names the collaborator.
+func (h *ListHandler) RevokeShare(c fiber.Ctx) error {
+	listID := fiber.Params[int](c, "id")
+	if listID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid list id")
+	}
+
+	shareID := fiber.Params[int](c, "share_id")
+	if shareID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid share id")
+	}
+
+	var share models.ListShare
+	if err := h.db.WithContext(c.RequestCtx()).Where("id = ? AND list_id = ?", shareID, listID).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "share not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch share", "database query failed", err)
+	}
+
+	if share.IsActive() {
+		now := time.Now()
+		share.RevokedAt = &now
+		if err := h.db.WithContext(c.RequestCtx()).Save(&share).Error; err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to revoke share", "database update failed", err)
+		}
+	}
+
+	return c.JSON(share)
+}
+
+// SharedListResponse is what a collaborator sees when opening an invite
+// tygo:export
+type SharedListResponse struct {
+	List         models.List `json:"list"`
+	Collaborator string      `json:"collaborator"`
+}
+
+// GetShared returns the list behind an invite token
+func (h *ListHandler) GetShared(c fiber.Ctx) error {
+	share, ok, err := h.activeShare(c)
+	if !ok {
+		return err
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, share.ListID).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	return c.JSON(SharedListResponse{List: list, Collaborator: share.Collaborator})
+}
+
+// SharedListItems returns the items of the list behind an invite token
+func (h *ListHandler) SharedListItems(c fiber.Ctx) error {
+	share, ok, err := h.activeShare(c)
+	if !ok {
+		return err
+	}
+	return h.listItemsResponse(c, share.ListID)
+}
+
+// UpdateSharedItem updates a list item through an invite token, attributing
+// the change to the token's collaborator
+func (h *ListHandler) UpdateSharedItem(c fiber.Ctx) error {
+	itemID := fiber.Params[int](c, "item_id")
+	if itemID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid item id")
+	}
+
+	share, ok, err := h.activeShare(c)
+	if !ok {
+		return err
+	}
+	return h.updateListItem(c, share.ListID, uint(itemID), share.Collaborator)
+}
+
+// activeShare looks up the share for the :token route parameter.
+// When ok is false the error response has already been written and err should be returned.
+func (h *ListHandler) activeShare(c fiber.Ctx) (models.ListShare, bool, error) {
+	var share models.ListShare
+	if err := h.db.WithContext(c.RequestCtx()).Where("token = ?", c.Params("token")).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return share, false, utils.ReturnError(c, fiber.StatusNotFound, "share not found")
+		}
+		return share, false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch share", "database query failed", err)
+	}
+	if !share.IsActive() {
+		return share, false, utils.ReturnError(c, fiber.StatusNotFound, "share not found")
+	}
+	return share, true, nil
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListShareTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.ListShare{}, &models.Card{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewListHandler(db)
+
+	app.Put("/lists/:id/items/:item_id", handler.UpdateItem)
+	app.Get("/lists/:id/shares", handler.ListShares)
+	app.Post("/lists/:id/shares", handler.CreateShare)
+	app.Delete("/lists/:id/shares/:share_id", handler.RevokeShare)
+	app.Get("/shared/:token", handler.GetShared)
+	app.Get("/shared/:token/items", handler.SharedListItems)
+	app.Put("/shared/:token/items/:item_id", handler.UpdateSharedItem)
+
+	return app, db
+}
+
+func createTestListShare(t *testing.T, app *fiber.App, listID uint, collaborator string) models.ListShare {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"collaborator": %q}`, collaborator)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/lists/%d/shares", listID), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var share models.ListShare
+	if err := json.NewDecoder(resp.Body).Decode(&share); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return share
+}
+
+func TestListShares_CreateAndOpen(t *testing.T) {
+	app, db := setupListShareTestApp(t)
+	list := createTestList(t, db, "Deck Plan")
+
+	share := createTestListShare(t, app, list.ID, "Sam")
+	if share.Token == "" || share.Collaborator != "Sam" {
+		t.Fatalf("unexpected share: %+v", share)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/shared/"+share.Token, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result SharedListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.List.ID != list.ID || result.Collaborator != "Sam" {
+		t.Errorf("unexpected shared list response: %+v", result)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/shared/"+share.Token+"/items", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestListShares_CreateValidation(t *testing.T) {
+	app, db := setupListShareTestApp(t)
+	list := createTestList(t, db, "Deck Plan")
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		expected int
+	}{
+		{name: "Missing collaborator", path: fmt.Sprintf("/lists/%d/shares", list.ID), body: `{}`, expected: http.StatusBadRequest},
+		{name: "Unknown list", path: "/lists/999/shares", body: `{"collaborator": "Sam"}`, expected: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestListShares_UpdateSharedItem_Attribution(t *testing.T) {
+	app, db := setupListShareTestApp(t)
+	list := createTestList(t, db, "Deck Plan")
+	item := models.ListItem{ListID: list.ID, ScryfallID: "card-1", OracleID: "oracle-1", DesiredQuantity: 4}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create list item: %v", err)
+	}
+	share := createTestListShare(t, app, list.ID, "Sam")
+
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/shared/%s/items/%d", share.Token, item.ID),
+		bytes.NewBufferString(`{"collected_quantity": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var updated models.ListItem
+	db.First(&updated, item.ID)
+	if updated.CollectedQuantity != 2 || updated.LastEditedBy != "Sam" {
+		t.Errorf("expected collected 2 edited by Sam, got %d by %q", updated.CollectedQuantity, updated.LastEditedBy)
+	}
+
+	// An owner edit clears the attribution
+	req = httptest.NewRequest(http.MethodPut, fmt.Sprintf("/lists/%d/items/%d", list.ID, item.ID),
+		bytes.NewBufferString(`{"collected_quantity": 3}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := app.Test(req); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	db.First(&updated, item.ID)
+	if updated.LastEditedBy != "" {
+		t.Errorf("expected owner edit to clear attribution, got %q", updated.LastEditedBy)
+	}
+}
+
+func TestListShares_Revoke(t *testing.T) {
+	app, db := setupListShareTestApp(t)
+	list := createTestList(t, db, "Deck Plan")
+	other := createTestList(t, db, "Other")
+	item := models.ListItem{ListID: other.ID, ScryfallID: "card-1", OracleID: "oracle-1", DesiredQuantity: 1}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create list item: %v", err)
+	}
+	share := createTestListShare(t, app, list.ID, "Sam")
+
+	// A token only reaches items on its own list
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/shared/%s/items/%d", share.Token, item.ID),
+		bytes.NewBufferString(`{"collected_quantity": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/lists/%d/shares/%d", list.ID, share.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/shared/"+share.Token, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for revoked share, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	// Revoked shares stay listed so attribution remains readable
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/shares", list.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var shares []models.ListShare
+	if err := json.NewDecoder(resp.Body).Decode(&shares); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(shares) != 1 || shares[0].RevokedAt == nil {
+		t.Errorf("expected one revoked share, got %+v", shares)
+	}
+}
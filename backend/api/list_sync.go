@@ -0,0 +1,63 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ListSyncResponse reports how many list items a sync changed
+// tygo:export
+type ListSyncResponse struct {
+	Updated int `json:"updated"`
+}
+
+// Sync recomputes collected quantities for a list from owned inventory, whether or
+// not the list tracks inventory automatically
+func (h *ListHandler) Sync(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+
+	updated, err := services.NewListSyncService(h.db).SyncList(c.RequestCtx(), list.ID)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to sync list", "list sync failed", err)
+	}
+
+	return c.JSON(ListSyncResponse{Updated: updated})
+}
+
+// syncTrackedList brings an auto-tracked list's collected quantities up to date after
+// its items change. A failure is logged; the background sync will retry on the next
+// inventory change.
+func (h *ListHandler) syncTrackedList(ctx context.Context, list models.List) {
+	if _, err := services.NewListSyncService(h.db).SyncList(ctx, list.ID); err != nil {
+		slog.WarnContext(ctx, "failed to sync auto-tracked list", "component", "lists", "list_id", list.ID, "error", err)
+	}
+}
+
+// listItemIDs returns the IDs of items
+func listItemIDs(items []models.ListItem) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
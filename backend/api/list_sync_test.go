@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListSyncTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.StorageLocation{}, &models.Inventory{}, &models.Setting{},
+		&models.Loan{}, &models.LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewListHandler(db)
+
+	app.Put("/lists/:id", handler.Update)
+	app.Post("/lists/:id/sync", handler.Sync)
+	app.Put("/lists/:id/items/:item_id", handler.UpdateItem)
+
+	return app, db
+}
+
+func sendListSyncRequest(t *testing.T, app *fiber.App, method, url string, body any) *http.Response {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		reader = bytes.NewReader(payload)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestListSync_Endpoint(t *testing.T) {
+	app, db := setupListSyncTestApp(t)
+
+	list := createTestList(t, db, "Burn")
+	item := createTestListItem(t, db, list.ID, "bolt-m10", "oracle-bolt", "nonfoil", 4, 0)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 6})
+
+	resp := sendListSyncRequest(t, app, http.MethodPost, fmt.Sprintf("/lists/%d/sync", list.ID), nil)
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result ListSyncResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Updated != 1 {
+		t.Errorf("expected 1 item updated, got %d", result.Updated)
+	}
+
+	var synced models.ListItem
+	db.First(&synced, item.ID)
+	if synced.CollectedQuantity != 4 {
+		t.Errorf("expected collected capped at 4, got %d", synced.CollectedQuantity)
+	}
+}
+
+func TestListSync_Endpoint_Errors(t *testing.T) {
+	app, _ := setupListSyncTestApp(t)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Invalid id", "/lists/abc/sync", http.StatusBadRequest},
+		{"Not found", "/lists/999/sync", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := sendListSyncRequest(t, app, http.MethodPost, tt.url, nil)
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestListSync_EnableTracking(t *testing.T) {
+	app, db := setupListSyncTestApp(t)
+
+	list := createTestList(t, db, "Burn")
+	item := createTestListItem(t, db, list.ID, "bolt-m10", "oracle-bolt", "nonfoil", 4, 0)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 2})
+
+	enabled := true
+	resp := sendListSyncRequest(t, app, http.MethodPut, fmt.Sprintf("/lists/%d", list.ID), UpdateListRequest{Name: "Burn", AutoTrackInventory: &enabled})
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var updated models.List
+	json.NewDecoder(resp.Body).Decode(&updated)
+	if !updated.AutoTrackInventory {
+		t.Error("expected auto_track_inventory to be enabled")
+	}
+
+	var synced models.ListItem
+	db.First(&synced, item.ID)
+	if synced.CollectedQuantity != 2 {
+		t.Errorf("expected enabling tracking to sync collected to 2, got %d", synced.CollectedQuantity)
+	}
+}
+
+func TestListSync_TrackedItemUpdate(t *testing.T) {
+	app, db := setupListSyncTestApp(t)
+
+	list := models.List{Name: "Tracked", AutoTrackInventory: true}
+	db.Create(&list)
+	item := createTestListItem(t, db, list.ID, "bolt-m10", "oracle-bolt", "nonfoil", 4, 3)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3})
+
+	url := fmt.Sprintf("/lists/%d/items/%d", list.ID, item.ID)
+
+	collected := 1
+	resp := sendListSyncRequest(t, app, http.MethodPut, url, UpdateListItemRequest{CollectedQuantity: &collected})
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected manual collected edit to be rejected with %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	// Lowering desired below the owned count caps collected
+	desired := 2
+	resp = sendListSyncRequest(t, app, http.MethodPut, url, UpdateListItemRequest{DesiredQuantity: &desired})
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var updated models.ListItem
+	json.NewDecoder(resp.Body).Decode(&updated)
+	if updated.DesiredQuantity != 2 || updated.CollectedQuantity != 2 {
+		t.Errorf("expected 2/2, got desired %d collected %d", updated.DesiredQuantity, updated.CollectedQuantity)
+	}
+
+	// Raising it again picks the owned copies back up
+	desired = 5
+	resp = sendListSyncRequest(t, app, http.MethodPut, url, UpdateListItemRequest{DesiredQuantity: &desired})
+	defer resp.Body.Close()
+	json.NewDecoder(resp.Body).Decode(&updated)
+	if updated.CollectedQuantity != 3 {
+		t.Errorf("expected collected re-synced to 3, got %d", updated.CollectedQuantity)
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"backend/models"
+	"backend/services"
 	"backend/utils"
 	"context"
 	"errors"
@@ -31,6 +32,7 @@ type ListSummary struct {
 	UpdatedAt            string `json:"updated_at"`
 	Name                 string `json:"name"`
 	Description          string `json:"description"`
+	AutoTrackInventory   bool   `json:"auto_track_inventory"`
 	TotalItems           int    `json:"total_items"`
 	TotalCardsWanted     int    `json:"total_cards_wanted"`
 	TotalCardsCollected  int    `json:"total_cards_collected"`
@@ -67,6 +69,7 @@ func (h *ListHandler) List(c fiber.Ctx) error {
 			UpdatedAt:            list.UpdatedAt.Format(time.RFC3339),
 			Name:                 list.Name,
 			Description:          list.Description,
+			AutoTrackInventory:   list.AutoTrackInventory,
 			TotalItems:           len(list.Items),
 			TotalCardsWanted:     totalWanted,
 			TotalCardsCollected:  totalCollected,
@@ -99,8 +102,9 @@ func (h *ListHandler) Get(c fiber.Ctx) error {
 // CreateListRequest represents the request body for creating a list
 // tygo:export
 type CreateListRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	AutoTrackInventory bool   `json:"auto_track_inventory"`
 }
 
 // Create creates a new list
@@ -121,8 +125,9 @@ func (h *ListHandler) Create(c fiber.Ctx) error {
 	}
 
 	list := models.List{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:               req.Name,
+		Description:        req.Description,
+		AutoTrackInventory: req.AutoTrackInventory,
 	}
 
 	if err := h.db.WithContext(c.RequestCtx()).Create(&list).Error; err != nil {
@@ -136,8 +141,9 @@ func (h *ListHandler) Create(c fiber.Ctx) error {
 // UpdateListRequest represents the request body for updating a list
 // tygo:export
 type UpdateListRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	AutoTrackInventory *bool  `json:"auto_track_inventory,omitempty"`
 }
 
 // Update updates an existing list
@@ -166,12 +172,21 @@ func (h *ListHandler) Update(c fiber.Ctx) error {
 	}
 	// Allow empty description to clear it
 	list.Description = req.Description
+	startTracking := req.AutoTrackInventory != nil && *req.AutoTrackInventory && !list.AutoTrackInventory
+	if req.AutoTrackInventory != nil {
+		list.AutoTrackInventory = *req.AutoTrackInventory
+	}
 
 	if err := h.db.WithContext(c.RequestCtx()).Save(&list).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update list", "database update failed", err)
 	}
 
+	// Catch up with the inventory as soon as tracking is turned on
+	if startTracking {
+		h.syncTrackedList(c.RequestCtx(), list)
+	}
+
 	return c.JSON(list)
 }
 
@@ -226,6 +241,8 @@ type EnrichedListItem struct {
 	Treatment         string `json:"treatment"`
 	DesiredQuantity   int    `json:"desired_quantity"`
 	CollectedQuantity int    `json:"collected_quantity"`
+	LastEditedBy      string `json:"last_edited_by,omitempty"`
+	OwnedQuantity     int    `json:"owned_quantity"` // Inventory copies counted under the list match policy
 	// Enriched fields (populated from Scryfall API)
 	Name            string   `json:"name,omitempty"`
 	SetName         string   `json:"set_name,omitempty"`
@@ -236,9 +253,14 @@ type EnrichedListItem struct {
 	Finishes        []string `json:"finishes,omitempty"`
 	FrameEffects    []string `json:"frame_effects,omitempty"`
 	PromoTypes      []string `json:"promo_types,omitempty"`
+	// Included unless the card_external_links setting is off
+	PurchaseURIs map[string]string `json:"purchase_uris,omitempty"` // Marketplace links (tcgplayer, cardmarket, cardhoarder)
+	RelatedURIs  map[string]string `json:"related_uris,omitempty"`  // Reference links (gatherer, tcgplayer_decks, edhrec, mtgtop8)
 }
 
-// ListItemsResponse represents paginated list items with aggregate stats
+// ListItemsResponse represents paginated list items with aggregate stats.
+// This is the legacy shape; API version 2 requests get a utils.Envelope with the
+// stats under aggregates instead.
 // tygo:export
 type ListItemsResponse struct {
 	Data                []EnrichedListItem `json:"data"`
@@ -251,6 +273,7 @@ type ListItemsResponse struct {
 	CompletionPercent   int                `json:"completion_percent"`
 	TotalCollectedValue float64            `json:"total_collected_value"`
 	TotalRemainingValue float64            `json:"total_remaining_value"`
+	Currency            models.Currency    `json:"currency"` // Currency of the values and item prices
 }
 
 // ListItems returns all items for a list with pagination and enriched card data.
@@ -281,9 +304,14 @@ func (h *ListHandler) ListItems(c fiber.Ctx) error {
 			"Failed to fetch list", "database query failed", err)
 	}
 
+	return h.listItemsResponse(c, uint(id))
+}
+
+// listItemsResponse builds the paginated, enriched items response for a list
+// that is known to exist
+func (h *ListHandler) listItemsResponse(c fiber.Ctx, listID uint) error {
 	ctx := c.RequestCtx()
 	params := utils.ParsePaginationParams(c, DefaultCardsPageSize, MaxCardsPageSize)
-	listID := uint(id)
 
 	// Count total items
 	var total int64
@@ -299,14 +327,26 @@ func (h *ListHandler) ListItems(c fiber.Ctx) error {
 			"Failed to calculate stats", "aggregate query failed", err)
 	}
 
-	collectedValue, remainingValue := h.calculateListValue(ctx, listID)
+	currency := services.PreferredCurrency(ctx, h.db)
+	collectedValue, remainingValue := h.calculateListValue(ctx, listID, currency)
 
-	enrichedItems, err := h.enrichListItems(ctx, listID, params.Page, params.PageSize)
+	enrichedItems, err := h.enrichListItems(ctx, listID, params.Page, params.PageSize, currency)
 	if err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch list items", "database query failed", err)
 	}
 
+	if utils.RequestAPIVersion(c) == utils.APIVersionEnvelope {
+		return c.JSON(utils.NewEnvelope(enrichedItems, params.Page, params.PageSize, total, map[string]any{
+			"total_wanted":          stats.TotalWanted,
+			"total_collected":       stats.TotalCollected,
+			"completion_percent":    completionPercent,
+			"total_collected_value": collectedValue,
+			"total_remaining_value": remainingValue,
+			"currency":              currency,
+		}))
+	}
+
 	return c.JSON(ListItemsResponse{
 		Data:                enrichedItems,
 		Page:                params.Page,
@@ -318,6 +358,7 @@ func (h *ListHandler) ListItems(c fiber.Ctx) error {
 		CompletionPercent:   completionPercent,
 		TotalCollectedValue: collectedValue,
 		TotalRemainingValue: remainingValue,
+		Currency:            currency,
 	})
 }
 
@@ -344,11 +385,11 @@ func (h *ListHandler) calculateListStats(ctx context.Context, listID uint) (list
 	return stats, completionPercent, nil
 }
 
-// calculateListValue computes the total collected and remaining USD values for a list.
-func (h *ListHandler) calculateListValue(ctx context.Context, listID uint) (collectedValue, remainingValue float64) {
+// calculateListValue computes the total collected and remaining values for a list in currency.
+func (h *ListHandler) calculateListValue(ctx context.Context, listID uint, currency models.Currency) (collectedValue, remainingValue float64) {
 	var allListItems []models.ListItem
 	if err := h.db.WithContext(ctx).Where("list_id = ?", listID).Find(&allListItems).Error; err != nil {
-		slog.Warn("failed to fetch list items for value calculation", "component", "lists", "list_id", listID, "error", err)
+		slog.WarnContext(ctx, "failed to fetch list items for value calculation", "component", "lists", "list_id", listID, "error", err)
 		return 0, 0
 	}
 
@@ -361,27 +402,18 @@ func (h *ListHandler) calculateListValue(ctx context.Context, listID uint) (coll
 		return 0, 0
 	}
 
-	var allCards []models.Card
-	if err := h.db.WithContext(ctx).Where("scryfall_id IN ?", allScryfallIDs).Find(&allCards).Error; err != nil {
-		slog.Warn("failed to fetch cards for value calculation", "component", "lists", "list_id", listID, "error", err)
+	priceMap, err := models.GetCardPricesByIDs(h.db.WithContext(ctx), allScryfallIDs)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to fetch card prices for value calculation", "component", "lists", "list_id", listID, "error", err)
 		return 0, 0
 	}
 
-	allCardMap := make(map[string]models.Card, len(allCards))
-	for _, card := range allCards {
-		allCardMap[card.ScryfallID] = card
-	}
-
 	for _, item := range allListItems {
-		card, ok := allCardMap[item.ScryfallID]
+		prices, ok := priceMap[item.ScryfallID]
 		if !ok {
 			continue
 		}
-		scryfallCard, err := card.ToScryfallCard()
-		if err != nil {
-			continue
-		}
-		price := utils.ParsePriceFromScryfall(scryfallCard.Prices, item.Treatment)
+		price := prices.InCurrency(item.Treatment, currency)
 		collectedValue += price * float64(item.CollectedQuantity)
 		remaining := item.DesiredQuantity - item.CollectedQuantity
 		if remaining > 0 {
@@ -392,7 +424,7 @@ func (h *ListHandler) calculateListValue(ctx context.Context, listID uint) (coll
 }
 
 // enrichListItems fetches a page of list items and enriches them with card metadata.
-func (h *ListHandler) enrichListItems(ctx context.Context, listID uint, page, pageSize int) ([]EnrichedListItem, error) {
+func (h *ListHandler) enrichListItems(ctx context.Context, listID uint, page, pageSize int, currency models.Currency) ([]EnrichedListItem, error) {
 	var items []models.ListItem
 	offset := utils.CalculateOffset(page, pageSize)
 
@@ -413,9 +445,15 @@ func (h *ListHandler) enrichListItems(ctx context.Context, listID uint, page, pa
 
 	scryfallCardMap, err := models.GetScryfallCardsByIDs(h.db.WithContext(ctx), scryfallIDs)
 	if err != nil {
-		slog.Warn("failed to fetch card data for enrichment", "component", "lists", "error", err)
+		slog.WarnContext(ctx, "failed to fetch card data for enrichment", "component", "lists", "error", err)
+	}
+
+	owned, err := services.NewListMatchService(h.db).OwnedQuantities(ctx, items)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to match inventory to list items", "component", "lists", "error", err)
 	}
 
+	links := services.ExternalLinksEnabled(ctx, h.db)
 	enrichedItems := make([]EnrichedListItem, len(items))
 	for i, item := range items {
 		enrichedItem := EnrichedListItem{
@@ -428,6 +466,8 @@ func (h *ListHandler) enrichListItems(ctx context.Context, listID uint, page, pa
 			Treatment:         item.Treatment,
 			DesiredQuantity:   item.DesiredQuantity,
 			CollectedQuantity: item.CollectedQuantity,
+			LastEditedBy:      item.LastEditedBy,
+			OwnedQuantity:     owned[item.ID],
 		}
 
 		if scryfallCard, ok := scryfallCardMap[item.ScryfallID]; ok {
@@ -436,10 +476,13 @@ func (h *ListHandler) enrichListItems(ctx context.Context, listID uint, page, pa
 			enrichedItem.SetCode = scryfallCard.Set
 			enrichedItem.CollectorNumber = scryfallCard.CollectorNumber
 			enrichedItem.Rarity = string(scryfallCard.Rarity)
-			enrichedItem.CurrentPrice = utils.ParsePriceFromScryfall(scryfallCard.Prices, item.Treatment)
+			enrichedItem.CurrentPrice = utils.ParsePriceInCurrency(scryfallCard.Prices, item.Treatment, string(currency))
 			enrichedItem.Finishes = utils.ConvertEnumSliceToStrings(scryfallCard.Finishes)
 			enrichedItem.FrameEffects = utils.ConvertEnumSliceToStrings(scryfallCard.FrameEffects)
 			enrichedItem.PromoTypes = scryfallCard.PromoTypes
+			if links {
+				enrichedItem.PurchaseURIs, enrichedItem.RelatedURIs = BuildCardExternalLinks(scryfallCard)
+			}
 		}
 
 		enrichedItems[i] = enrichedItem
@@ -514,6 +557,13 @@ func (h *ListHandler) CreateItemsBatch(c fiber.Ctx) error {
 			"Failed to create list items", "database insert failed", err)
 	}
 
+	if list.AutoTrackInventory {
+		h.syncTrackedList(c.RequestCtx(), list)
+		if err := h.db.WithContext(c.RequestCtx()).Find(&items, listItemIDs(items)).Error; err != nil {
+			slog.WarnContext(c.RequestCtx(), "failed to reload synced list items", "component", "lists", "list_id", list.ID, "error", err)
+		}
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(items)
 }
 
@@ -536,6 +586,12 @@ func (h *ListHandler) UpdateItem(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid item id")
 	}
 
+	return h.updateListItem(c, uint(listID), uint(itemID), "")
+}
+
+// updateListItem applies an UpdateListItemRequest body to an item in a list,
+// attributing the change to editedBy (empty for the list owner)
+func (h *ListHandler) updateListItem(c fiber.Ctx, listID, itemID uint, editedBy string) error {
 	var item models.ListItem
 	if err := h.db.WithContext(c.RequestCtx()).Where("id = ? AND list_id = ?", itemID, listID).First(&item).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -554,18 +610,40 @@ func (h *ListHandler) UpdateItem(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "at least one field must be provided for update")
 	}
 
+	var list models.List
+	if err := h.db.WithContext(c.RequestCtx()).First(&list, listID).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch list", "database query failed", err)
+	}
+	if list.AutoTrackInventory && req.CollectedQuantity != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "collected_quantity is tracked from inventory for this list")
+	}
+
 	if req.DesiredQuantity != nil {
 		item.DesiredQuantity = *req.DesiredQuantity
+		if list.AutoTrackInventory {
+			// Re-synced below; capping first keeps the row valid when desired drops
+			item.CollectedQuantity = min(item.CollectedQuantity, max(item.DesiredQuantity, 0))
+		}
 	}
 	if req.CollectedQuantity != nil {
 		item.CollectedQuantity = *req.CollectedQuantity
 	}
+	item.LastEditedBy = editedBy
 
 	if err := h.db.WithContext(c.RequestCtx()).Save(&item).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update list item", "database update failed", err)
 	}
 
+	if list.AutoTrackInventory {
+		h.syncTrackedList(c.RequestCtx(), list)
+		var synced models.ListItem
+		if err := h.db.WithContext(c.RequestCtx()).First(&synced, item.ID).Error; err == nil {
+			item = synced
+		}
+	}
+
 	return c.JSON(item)
 }
 
@@ -8,7 +8,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"backend/database/databasetest"
 	"backend/models"
+	"backend/utils"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/driver/sqlite"
@@ -299,9 +301,7 @@ func setupListTestAppWithCards(t *testing.T) (*fiber.App, *gorm.DB) {
 
 	db.Exec("PRAGMA foreign_keys = ON")
 
-	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.Card{}); err != nil {
-		t.Fatalf("failed to migrate test database: %v", err)
-	}
+	databasetest.Migrate(t, db)
 
 	app := fiber.New()
 	handler := NewListHandler(db)
@@ -354,6 +354,35 @@ func createTestListItem(t *testing.T, db *gorm.DB, listID uint, scryfallID, orac
 	return item
 }
 
+func TestListItems_ExternalLinks(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+
+	createTestCardFromFixture(t, db,
+		"d573ef03-4730-45aa-93dd-e45ac1dbaf4a",
+		"4457ed35-7c10-48c8-9776-456485fdf070",
+		"card_lightning_bolt.json")
+	list := createTestList(t, db, "Burn")
+	createTestListItem(t, db, list.ID, "d573ef03-4730-45aa-93dd-e45ac1dbaf4a", "4457ed35-7c10-48c8-9776-456485fdf070", "nonfoil", 4, 0)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/items", list.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result ListItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Data) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(result.Data))
+	}
+	item := result.Data[0]
+	if item.PurchaseURIs["tcgplayer"] == "" || item.RelatedURIs["gatherer"] == "" {
+		t.Errorf("expected marketplace and reference links, got %v and %v", item.PurchaseURIs, item.RelatedURIs)
+	}
+}
+
 func TestListItems_ValueCalculation_BasicPrices(t *testing.T) {
 	app, db := setupListTestAppWithCards(t)
 
@@ -393,6 +422,116 @@ func TestListItems_ValueCalculation_BasicPrices(t *testing.T) {
 	}
 }
 
+func TestListItems_EnvelopeVersion(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+
+	createTestCardForList(t, db, "bolt-id", "Lightning Bolt", "2.00", "8.00")
+
+	list := createTestList(t, db, "My Deck")
+	createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 1)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/items?api_version=2", list.ID), nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result utils.Envelope[EnrichedListItem]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.TotalItems != 1 || len(result.Items) != 1 {
+		t.Errorf("expected 1 item, got %d of %d", len(result.Items), result.TotalItems)
+	}
+	if result.Aggregates["total_wanted"] != 4.0 || result.Aggregates["total_collected"] != 1.0 {
+		t.Errorf("expected wanted and collected totals in aggregates, got %v", result.Aggregates)
+	}
+	if result.Aggregates["total_remaining_value"] != 6.0 {
+		t.Errorf("expected total_remaining_value 6, got %v", result.Aggregates["total_remaining_value"])
+	}
+}
+
+func TestListItems_ValueCalculation_PreferredCurrency(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+	db.Create(&models.Setting{Key: "preferred_currency", Value: "tix"})
+
+	db.Create(&models.Card{
+		ScryfallID: "bolt-id",
+		OracleID:   "oracle-bolt-id",
+		RawJSON:    `{"id": "bolt-id", "name": "Lightning Bolt", "prices": {"usd": "2.00", "tix": "0.03"}}`,
+	})
+
+	list := createTestList(t, db, "MTGO Deck")
+	createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "foil", 4, 1)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/items", list.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result ListItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if result.Currency != models.CurrencyTIX {
+		t.Errorf("expected currency tix, got %q", result.Currency)
+	}
+	if result.TotalCollectedValue != 0.03 {
+		t.Errorf("expected total_collected_value 0.03, got %v", result.TotalCollectedValue)
+	}
+	if len(result.Data) != 1 || result.Data[0].CurrentPrice != 0.03 {
+		t.Errorf("expected item current_price 0.03, got %+v", result.Data)
+	}
+}
+
+func TestListItems_OwnedQuantity_MatchPolicy(t *testing.T) {
+	app, db := setupListTestAppWithCards(t)
+
+	createTestCardForList(t, db, "bolt-id", "Lightning Bolt", "2.00", "8.00")
+	list := createTestList(t, db, "My Deck")
+	createTestListItem(t, db, list.ID, "bolt-id", "oracle-bolt-id", "nonfoil", 4, 0)
+
+	// Two copies of the listed printing and one foil copy of another printing
+	for _, row := range []models.Inventory{
+		{ScryfallID: "bolt-id", OracleID: "oracle-bolt-id", Treatment: "nonfoil", Quantity: 2},
+		{ScryfallID: "bolt-other", OracleID: "oracle-bolt-id", Treatment: "foil", Quantity: 1},
+	} {
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	ownedQuantity := func() int {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/lists/%d/items", list.ID), nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var result ListItemsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 {
+			t.Fatalf("expected 1 item, got %d", len(result.Data))
+		}
+		return result.Data[0].OwnedQuantity
+	}
+
+	if owned := ownedQuantity(); owned != 2 {
+		t.Errorf("expected 2 owned copies under exact printing, got %d", owned)
+	}
+
+	db.Create(&models.Setting{Key: "list_match_policy", Value: "any_printing"})
+	if owned := ownedQuantity(); owned != 3 {
+		t.Errorf("expected 3 owned copies under any printing, got %d", owned)
+	}
+}
+
 func TestListItems_ValueCalculation_CardMissingFromDB(t *testing.T) {
 	app, db := setupListTestAppWithCards(t)
 
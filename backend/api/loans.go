@@ -0,0 +1,192 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// LoansHandler handles loan endpoints
+type LoansHandler struct {
+	service *services.LoanService
+}
+
+// NewLoansHandler creates a new loans handler
+func NewLoansHandler(service *services.LoanService) *LoansHandler {
+	return &LoansHandler{service: service}
+}
+
+// List returns loans with pagination and an optional status filter
+func (h *LoansHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	var status *services.LoanStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		s := services.LoanStatus(statusStr)
+		if !s.Valid() {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "status must be one of: active, returned, overdue")
+		}
+		status = &s
+	}
+
+	loans, total, err := h.service.List(c.RequestCtx(), params.Page, params.PageSize, status)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loans", "loan list query failed", err)
+	}
+
+	return utils.SendPaginated(c, loans, params.Page, params.PageSize, total)
+}
+
+// OverdueLoansResponse reports the active loans past their due date
+// tygo:export
+type OverdueLoansResponse struct {
+	Loans     []services.OverdueLoan `json:"loans"`
+	Borrowers int                    `json:"borrowers"` // Distinct borrowers with an overdue loan
+	Cards     int                    `json:"cards"`     // Copies out on overdue loans
+}
+
+// Overdue reports every active loan past its due date, most overdue first
+func (h *LoansHandler) Overdue(c fiber.Ctx) error {
+	loans, err := h.service.Overdue(c.RequestCtx(), time.Now())
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch overdue loans", "overdue loan query failed", err)
+	}
+
+	response := OverdueLoansResponse{Loans: loans}
+	borrowers := make(map[string]bool)
+	for _, loan := range loans {
+		borrowers[loan.Borrower] = true
+		response.Cards += loan.Cards
+	}
+	response.Borrowers = len(borrowers)
+	return c.JSON(response)
+}
+
+// Get returns a single loan by ID
+func (h *LoansHandler) Get(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	loan, err := h.service.Get(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "loan not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch loan", "loan query failed", err)
+	}
+
+	return c.JSON(loan)
+}
+
+// LoanItemRequest represents one inventory entry to include in a loan
+// tygo:export
+type LoanItemRequest struct {
+	InventoryID uint `json:"inventory_id"`
+	Quantity    int  `json:"quantity"`
+}
+
+// CreateLoanRequest represents the request body for lending cards
+// tygo:export
+type CreateLoanRequest struct {
+	Borrower string            `json:"borrower"`
+	DueDate  *time.Time        `json:"due_date,omitempty"`
+	Notes    string            `json:"notes,omitempty"`
+	Items    []LoanItemRequest `json:"items"`
+}
+
+// Create lends one or more inventory items to a borrower
+func (h *LoansHandler) Create(c fiber.Ctx) error {
+	var req CreateLoanRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateRequired(req.Borrower, "borrower"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Borrower, 255, "borrower"))
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if len(req.Items) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "items array is required")
+	}
+	if len(req.Items) > MaxBatchItems {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("too many items (max %d)", MaxBatchItems))
+	}
+
+	loan := models.Loan{
+		Borrower: req.Borrower,
+		DueDate:  req.DueDate,
+		Notes:    req.Notes,
+		Items:    make([]models.LoanItem, 0, len(req.Items)),
+	}
+	for i, item := range req.Items {
+		if item.InventoryID == 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("items[%d]: inventory_id is required", i))
+		}
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Quantity < 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("items[%d]: quantity must be positive", i))
+		}
+		loan.Items = append(loan.Items, models.LoanItem{
+			InventoryID: item.InventoryID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	if err := h.service.Lend(c.RequestCtx(), &loan); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return utils.ReturnError(c, fiber.StatusBadRequest, "inventory item not found")
+		case errors.Is(err, services.ErrInsufficientQuantity):
+			return utils.ReturnError(c, fiber.StatusConflict, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create loan", "loan insert failed", err)
+	}
+
+	created, err := h.service.Get(c.RequestCtx(), loan.ID)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to reload loan", "loan query failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Return marks a loan as returned
+func (h *LoansHandler) Return(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	loan, err := h.service.Return(c.RequestCtx(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return utils.ReturnError(c, fiber.StatusNotFound, "loan not found")
+		case errors.Is(err, services.ErrLoanAlreadyReturned):
+			return utils.ReturnError(c, fiber.StatusConflict, "loan has already been returned")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to return loan", "loan update failed", err)
+	}
+
+	return c.JSON(loan)
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLoansTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.Loan{}, &models.LoanItem{}, &models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewLoansHandler(services.NewLoanService(db, services.NewNotificationService(db)))
+	inventoryHandler := NewInventoryHandler(db, services.NewAutoSortService(db), services.NewUndoService(db))
+
+	app := fiber.New()
+	app.Get("/loans", handler.List)
+	app.Get("/loans/overdue", handler.Overdue)
+	app.Get("/loans/:id", handler.Get)
+	app.Post("/loans", handler.Create)
+	app.Post("/loans/:id/return", handler.Return)
+	app.Get("/inventory/by-oracle/:oracle_id", inventoryHandler.ByOracle)
+	app.Get("/inventory/:id", inventoryHandler.Get)
+
+	return app, db
+}
+
+func postLoan(t *testing.T, app *fiber.App, body interface{}) (int, []byte) {
+	t.Helper()
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/loans", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody
+}
+
+func TestLoansCreate_Success(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 3}
+	db.Create(&inv)
+
+	status, body := postLoan(t, app, CreateLoanRequest{
+		Borrower: "Alex",
+		Notes:    "for Friday night",
+		Items:    []LoanItemRequest{{InventoryID: inv.ID, Quantity: 2}},
+	})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+
+	var loan models.Loan
+	if err := json.Unmarshal(body, &loan); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if loan.Borrower != "Alex" {
+		t.Errorf("expected borrower Alex, got %s", loan.Borrower)
+	}
+	if len(loan.Items) != 1 || loan.Items[0].Quantity != 2 {
+		t.Errorf("expected one item with quantity 2, got %+v", loan.Items)
+	}
+
+	// Inventory is flagged as on loan
+	req := httptest.NewRequest("GET", fmt.Sprintf("/inventory/%d", inv.ID), nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	var item models.Inventory
+	respBody, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(respBody, &item); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if item.OnLoanQuantity != 2 {
+		t.Errorf("expected on_loan_quantity 2, got %d", item.OnLoanQuantity)
+	}
+	if item.Quantity != 3 {
+		t.Errorf("expected quantity to remain 3, got %d", item.Quantity)
+	}
+}
+
+func TestLoansCreate_Validation(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 1}
+	db.Create(&inv)
+
+	tests := []struct {
+		name     string
+		body     CreateLoanRequest
+		expected int
+	}{
+		{
+			name:     "missing borrower",
+			body:     CreateLoanRequest{Items: []LoanItemRequest{{InventoryID: inv.ID, Quantity: 1}}},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "no items",
+			body:     CreateLoanRequest{Borrower: "Alex"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "unknown inventory item",
+			body:     CreateLoanRequest{Borrower: "Alex", Items: []LoanItemRequest{{InventoryID: 999, Quantity: 1}}},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "more copies than owned",
+			body:     CreateLoanRequest{Borrower: "Alex", Items: []LoanItemRequest{{InventoryID: inv.ID, Quantity: 2}}},
+			expected: fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := postLoan(t, app, tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+}
+
+func TestLoansReturn(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 1}
+	db.Create(&inv)
+	loan := models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}}
+	db.Create(&loan)
+
+	url := fmt.Sprintf("/loans/%d/return", loan.ID)
+	resp, err := app.Test(httptest.NewRequest("POST", url, nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("POST", url, nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("expected status %d for second return, got %d", fiber.StatusConflict, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("POST", "/loans/999/return", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestLoansList_InvalidStatus(t *testing.T) {
+	app, _ := setupLoansTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans?status=bogus", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", fiber.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestLoansList_Active(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 2}
+	db.Create(&inv)
+	db.Create(&models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans?status=active", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if total, _ := result["total_items"].(float64); total != 1 {
+		t.Errorf("expected total_items 1, got %v", result["total_items"])
+	}
+}
+
+func TestLoansOverdue(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 5}
+	db.Create(&inv)
+	longAgo := time.Now().Add(-10 * 24 * time.Hour)
+	yesterday := time.Now().Add(-36 * time.Hour)
+	tomorrow := time.Now().Add(24 * time.Hour)
+	db.Create(&models.Loan{Borrower: "Sam", DueDate: &yesterday, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+	db.Create(&models.Loan{Borrower: "Alex", DueDate: &longAgo, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 2}}})
+	db.Create(&models.Loan{Borrower: "Sam", DueDate: &tomorrow, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/loans/overdue", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	var result OverdueLoansResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Loans) != 2 || result.Borrowers != 2 || result.Cards != 3 {
+		t.Fatalf("expected 2 overdue loans of 3 cards to 2 borrowers, got %+v", result)
+	}
+	if result.Loans[0].Borrower != "Alex" || result.Loans[0].DaysOverdue != 10 || result.Loans[1].DaysOverdue != 1 {
+		t.Errorf("expected the most overdue loan first, got %+v", result.Loans)
+	}
+}
+
+func TestLoansByOracle_FlagsOnLoanQuantity(t *testing.T) {
+	app, db := setupLoansTestApp(t)
+
+	inv := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 3}
+	db.Create(&inv)
+	db.Create(&models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 2}}})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/inventory/by-oracle/oracle-1", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	var result ByOracleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Printings) != 1 || result.Printings[0].OnLoanQuantity != 2 {
+		t.Errorf("expected 2 copies flagged on loan, got %+v", result.Printings)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// MaintenanceHandler handles database maintenance HTTP requests
+type MaintenanceHandler struct {
+	service    *services.MaintenanceService
+	jobService *services.JobService
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(service *services.MaintenanceService, jobService *services.JobService) *MaintenanceHandler {
+	return &MaintenanceHandler{service: service, jobService: jobService}
+}
+
+// Reindex starts a background job that rebuilds derived card columns, aggregates and indexes
+func (h *MaintenanceHandler) Reindex(c fiber.Ctx, appCtx context.Context) error {
+	job, err := h.service.CreateReindexJob(appCtx)
+	if err != nil {
+		if errors.Is(err, services.ErrReindexRunning) {
+			return utils.ReturnError(c, fiber.StatusConflict, "A reindex job is already running")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create reindex job", "job creation failed", err)
+	}
+
+	if err := h.jobService.Go(appCtx, job.ID, "reindex", func(ctx context.Context) error {
+		// Errors are logged and recorded on the job by the service
+		_ = h.service.Reindex(ctx, job.ID)
+		return nil
+	}); err != nil {
+		return startJobError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Reindex job started",
+		"job_id":  job.ID,
+	})
+}
+
+// Vacuum rebuilds the database file to reclaim free space
+func (h *MaintenanceHandler) Vacuum(c fiber.Ctx) error {
+	result, err := h.service.Vacuum(c.RequestCtx())
+	if err != nil {
+		return maintenanceError(c, "vacuum", err)
+	}
+	return c.JSON(result)
+}
+
+// Analyze refreshes the query planner's statistics
+func (h *MaintenanceHandler) Analyze(c fiber.Ctx) error {
+	result, err := h.service.Analyze(c.RequestCtx())
+	if err != nil {
+		return maintenanceError(c, "analyze", err)
+	}
+	return c.JSON(result)
+}
+
+// Integrity runs SQLite's integrity check. A failed check is still a 200; its result
+// lists the problems.
+func (h *MaintenanceHandler) Integrity(c fiber.Ctx) error {
+	result, err := h.service.IntegrityCheck(c.RequestCtx())
+	if err != nil {
+		return maintenanceError(c, "integrity check", err)
+	}
+	return c.JSON(result)
+}
+
+// maintenanceError writes the response for a failed database maintenance operation
+func maintenanceError(c fiber.Ctx, operation string, err error) error {
+	if errors.Is(err, services.ErrJobsActive) {
+		return utils.ReturnError(c, fiber.StatusConflict, err.Error()+"; wait for them to finish")
+	}
+	return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+		"Failed to run "+operation, "database maintenance failed", err)
+}
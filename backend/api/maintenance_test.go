@@ -0,0 +1,143 @@
+package api
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupMaintenanceTestApp(t *testing.T) (*fiber.App, *services.JobService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	// The reindex job runs in a goroutine, so keep a single connection to the in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	databasetest.Migrate(t, db)
+
+	jobService := services.NewJobService(db)
+	handler := NewMaintenanceHandler(services.NewMaintenanceService(db, jobService), jobService)
+	appCtx := context.Background()
+
+	app := fiber.New()
+	app.Post("/maintenance/reindex", func(c fiber.Ctx) error {
+		return handler.Reindex(c, appCtx)
+	})
+	app.Post("/admin/db/vacuum", handler.Vacuum)
+	app.Post("/admin/db/analyze", handler.Analyze)
+	app.Get("/admin/db/integrity", handler.Integrity)
+
+	return app, jobService
+}
+
+func TestMaintenanceReindex_Accepted(t *testing.T) {
+	app, jobService := setupMaintenanceTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/maintenance/reindex", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected status %d, got %d. Body: %s", fiber.StatusAccepted, resp.StatusCode, string(body))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	jobID, ok := result["job_id"].(float64)
+	if !ok || jobID <= 0 {
+		t.Fatalf("expected positive job_id, got %v", result["job_id"])
+	}
+
+	job, err := jobService.Get(context.Background(), uint(jobID))
+	if err != nil {
+		t.Fatalf("failed to get created job: %v", err)
+	}
+	if job.Type != models.JobTypeReindex {
+		t.Errorf("expected job type %s, got %s", models.JobTypeReindex, job.Type)
+	}
+}
+
+func TestMaintenanceReindex_ConflictWhileRunning(t *testing.T) {
+	app, jobService := setupMaintenanceTestApp(t)
+
+	// A job that is never run stays pending
+	if _, err := jobService.Create(context.Background(), models.JobTypeReindex, "{}"); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/maintenance/reindex", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("expected status %d, got %d", fiber.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestMaintenanceDatabaseOperations(t *testing.T) {
+	app, _ := setupMaintenanceTestApp(t)
+
+	for _, path := range []string{"/admin/db/vacuum", "/admin/db/analyze"} {
+		resp, err := app.Test(httptest.NewRequest("POST", path, nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Errorf("%s: expected status %d, got %d. Body: %s", path, fiber.StatusOK, resp.StatusCode, string(body))
+		}
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/admin/db/integrity", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	var result services.IntegrityCheckResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !result.OK {
+		t.Errorf("expected a clean integrity check, got %+v", result)
+	}
+}
+
+func TestMaintenanceDatabaseOperations_ConflictWhileJobsActive(t *testing.T) {
+	app, jobService := setupMaintenanceTestApp(t)
+
+	if _, err := jobService.Create(context.Background(), models.JobTypeBulkDataImport, "{}"); err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+
+	for _, req := range []struct{ method, path string }{
+		{"POST", "/admin/db/vacuum"},
+		{"POST", "/admin/db/analyze"},
+		{"GET", "/admin/db/integrity"},
+	} {
+		resp, err := app.Test(httptest.NewRequest(req.method, req.path, nil))
+		if err != nil {
+			t.Fatalf("failed to make request: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusConflict {
+			t.Errorf("%s: expected status %d, got %d", req.path, fiber.StatusConflict, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// NotificationsHandler handles notification endpoints
+type NotificationsHandler struct {
+	service *services.NotificationService
+}
+
+// NewNotificationsHandler creates a new notifications handler
+func NewNotificationsHandler(service *services.NotificationService) *NotificationsHandler {
+	return &NotificationsHandler{service: service}
+}
+
+// List returns notifications with pagination, optionally only unread ones
+func (h *NotificationsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+	unreadOnly := c.Query("unread") == "true"
+
+	notifications, total, err := h.service.List(c.RequestCtx(), params.Page, params.PageSize, unreadOnly)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch notifications", "notification list query failed", err)
+	}
+
+	return utils.SendPaginated(c, notifications, params.Page, params.PageSize, total)
+}
+
+// MarkRead marks a notification as read
+func (h *NotificationsHandler) MarkRead(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	notification, err := h.service.MarkRead(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "notification not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update notification", "notification update failed", err)
+	}
+
+	return c.JSON(notification)
+}
+
+// TestChannels sends a test message to every configured notification channel and
+// reports each one's outcome
+func (h *NotificationsHandler) TestChannels(c fiber.Ctx) error {
+	return c.JSON(h.service.TestChannels(c.RequestCtx()))
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupNotificationsTestApp(t *testing.T) (*fiber.App, *services.NotificationService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	service := services.NewNotificationService(db)
+	handler := NewNotificationsHandler(service)
+
+	app := fiber.New()
+	app.Get("/notifications", handler.List)
+	app.Put("/notifications/:id/read", handler.MarkRead)
+
+	return app, service
+}
+
+func TestNotificationsList_UnreadFilter(t *testing.T) {
+	app, service := setupNotificationsTestApp(t)
+	ctx := t.Context()
+
+	first, _ := service.Create(ctx, models.NotificationTypeLoanOverdue, "first", "")
+	if _, err := service.Create(ctx, models.NotificationTypeLoanOverdue, "second", ""); err != nil {
+		t.Fatalf("failed to create notification: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest("PUT", fmt.Sprintf("/notifications/%d/read", first.ID), nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected status %d, got %d", fiber.StatusOK, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/notifications?unread=true", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if total, _ := result["total_items"].(float64); total != 1 {
+		t.Errorf("expected 1 unread notification, got %v", result["total_items"])
+	}
+}
+
+func TestNotificationsMarkRead_NotFound(t *testing.T) {
+	app, _ := setupNotificationsTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("PUT", "/notifications/999/read", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, resp.StatusCode)
+	}
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Spec documents one route for the generated document. Routes registered on the app
+// without a Spec are still listed, with only their path parameters and a generic response.
+type Spec struct {
+	Method  string
+	Path    string // Fiber route path, e.g. /lists/:id/items
+	Summary string
+	Query   []Param
+	Request any // Zero value of the JSON body type; nil when the route takes no JSON body
+
+	// Response is the zero value of the JSON response type; nil for no documented body
+	Response any
+	// Status is the success status; 0 means 200
+	Status int
+	// RequestType and ResponseType document non-JSON bodies by content type
+	// (e.g. multipart/form-data, text/csv); such bodies are described as binary strings
+	RequestType  string
+	ResponseType string
+}
+
+// Param is a query parameter
+type Param struct {
+	Name        string
+	Type        string // JSON schema type; empty means string
+	Description string
+}
+
+// Query parameters shared by paginated list endpoints
+var paginationParams = []Param{
+	{Name: "page", Type: "integer", Description: "Page number, starting at 1"},
+	{Name: "page_size", Type: "integer", Description: "Items per page"},
+}
+
+// Build describes every route registered on app, using specs for details
+func Build(info Info, app *fiber.App, specs []Spec) *Document {
+	registry := newSchemaRegistry()
+	errorSchema := registry.schemaFor(utils.ErrorResponse{})
+
+	specByRoute := make(map[string]Spec, len(specs))
+	for _, spec := range specs {
+		specByRoute[routeKey(spec.Method, spec.Path)] = spec
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodOptions {
+			continue
+		}
+		path := normalizePath(route.Path)
+		spec := specByRoute[routeKey(route.Method, path)]
+		method := strings.ToLower(route.Method)
+
+		op := &Operation{
+			OperationID: operationID(route.Method, path),
+			Summary:     spec.Summary,
+			Tags:        []string{routeTag(path)},
+			Responses:   make(map[string]Response),
+		}
+		for _, name := range route.Params {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, param := range spec.Query {
+			schemaType := param.Type
+			if schemaType == "" {
+				schemaType = "string"
+			}
+			op.Parameters = append(op.Parameters, Parameter{
+				Name: param.Name, In: "query", Description: param.Description, Schema: &Schema{Type: schemaType},
+			})
+		}
+
+		switch {
+		case spec.RequestType != "":
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				spec.RequestType: {Schema: &Schema{Type: "string", Format: "binary"}},
+			}}
+		case spec.Request != nil:
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+				fiber.MIMEApplicationJSON: {Schema: registry.schemaFor(spec.Request)},
+			}}
+		}
+
+		status := spec.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		switch {
+		case spec.ResponseType != "":
+			success.Content = map[string]MediaType{spec.ResponseType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+		case spec.Response != nil:
+			success.Content = map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: registry.schemaFor(spec.Response)}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = Response{
+			Description: "Error",
+			Content:     map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: errorSchema}},
+		}
+
+		item, ok := doc.Paths[openAPIPath(path)]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[openAPIPath(path)] = item
+		}
+		item[method] = op
+	}
+
+	doc.Components.Schemas = registry.schemas
+	return doc
+}
+
+// MissingSpecs lists the routes registered on app that have no Spec, as "METHOD path"
+func MissingSpecs(app *fiber.App, specs []Spec) []string {
+	documented := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		documented[routeKey(spec.Method, spec.Path)] = true
+	}
+
+	var missing []string
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Method == fiber.MethodOptions {
+			continue
+		}
+		if key := routeKey(route.Method, normalizePath(route.Path)); !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	slices.Sort(missing)
+	return slices.Compact(missing)
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + normalizePath(path)
+}
+
+// normalizePath drops the trailing slash group root routes are registered with
+func normalizePath(path string) string {
+	if len(path) > 1 {
+		return strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// openAPIPath converts Fiber's :param segments to OpenAPI's {param}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// routeTag groups a route by its first path segment, skipping the /api prefix
+func routeTag(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) > 1 && segments[0] == "api" {
+		segments = segments[1:]
+	}
+	if segments[0] == "" {
+		return "root"
+	}
+	return segments[0]
+}
+
+// operationID builds a stable identifier from the method and path, e.g.
+// get_lists_id_items for GET /lists/:id/items
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.TrimPrefix(segment, ":")
+		segment = strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, segment)
+		if segment != "" {
+			id += "_" + segment
+		}
+	}
+	return id
+}
@@ -0,0 +1,127 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type testEmbedded struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testItem struct {
+	testEmbedded
+	Name    string    `json:"name"`
+	Parent  *testItem `json:"parent,omitempty"`
+	Count   int64     `json:"count,string"`
+	Note    *string   `json:"note"`
+	Ignored string    `json:"-"`
+	hidden  string
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	registry := newSchemaRegistry()
+
+	if ref := registry.schemaFor(testItem{}).Ref; ref != "#/components/schemas/TestItem" {
+		t.Fatalf("expected a reference to TestItem, got %q", ref)
+	}
+	item := registry.schemas["TestItem"]
+	if item == nil {
+		t.Fatal("expected TestItem registered")
+	}
+
+	tests := []struct {
+		property string
+		want     Schema
+	}{
+		{"id", Schema{Type: "integer"}},
+		{"created_at", Schema{Type: "string", Format: "date-time"}},
+		{"name", Schema{Type: "string"}},
+		{"parent", Schema{Ref: "#/components/schemas/TestItem"}},
+		{"count", Schema{Type: "string"}},
+		{"note", Schema{Type: "string", Nullable: true}},
+	}
+	for _, tt := range tests {
+		got := item.Properties[tt.property]
+		if got == nil || got.Ref != tt.want.Ref || got.Type != tt.want.Type || got.Format != tt.want.Format || got.Nullable != tt.want.Nullable {
+			t.Errorf("%s: expected %+v, got %+v", tt.property, tt.want, got)
+		}
+	}
+	if len(item.Properties) != len(tests) {
+		t.Errorf("expected %d properties, got %d", len(tests), len(item.Properties))
+	}
+
+	list := registry.schemaFor([]testItem{})
+	if list.Type != "array" || list.Items.Ref != "#/components/schemas/TestItem" {
+		t.Errorf("expected an array of TestItem, got %+v", list)
+	}
+}
+
+func TestSchemaName(t *testing.T) {
+	registry := newSchemaRegistry()
+
+	if ref := registry.schemaFor(paginated[models.Job]()).Ref; ref != "#/components/schemas/PaginatedResponseJob" {
+		t.Errorf("expected PaginatedResponseJob, got %q", ref)
+	}
+	if ref := registry.schemaFor(utils.ErrorResponse{}).Ref; ref != "#/components/schemas/ErrorResponse" {
+		t.Errorf("expected ErrorResponse, got %q", ref)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	app := fiber.New()
+	noop := func(c fiber.Ctx) error { return nil }
+	app.Get("/items/:id", noop)
+	app.Post("/items/:id/tags", noop)
+	app.Get("/api/undocumented", noop)
+
+	doc := Build(Info{Title: "Test", Version: "1"}, app, []Spec{
+		{Method: http.MethodGet, Path: "/items/:id", Summary: "Get an item",
+			Query: []Param{{Name: "verbose", Type: "boolean"}}, Response: testItem{}},
+		{Method: http.MethodPost, Path: "/items/:id/tags", Summary: "Tag an item",
+			Request: map[string]string{}, Status: http.StatusCreated},
+	})
+
+	get := doc.Paths["/items/{id}"]["get"]
+	if get == nil {
+		t.Fatal("expected GET /items/{id}")
+	}
+	if get.Summary != "Get an item" || get.Tags[0] != "items" || get.OperationID == "" {
+		t.Errorf("unexpected operation: %+v", get)
+	}
+	if len(get.Parameters) != 2 || get.Parameters[0].In != "path" || get.Parameters[1].Schema.Type != "boolean" {
+		t.Errorf("expected the path and query parameters, got %+v", get.Parameters)
+	}
+	if _, ok := get.Responses["default"]; !ok {
+		t.Error("expected a default error response")
+	}
+	if _, ok := doc.Components.Schemas["TestItem"]; !ok {
+		t.Error("expected TestItem in components")
+	}
+
+	post := doc.Paths["/items/{id}/tags"]["post"]
+	if post == nil || post.RequestBody == nil {
+		t.Fatal("expected POST /items/{id}/tags with a request body")
+	}
+	if _, ok := post.Responses["201"]; !ok {
+		t.Errorf("expected a 201 response, got %+v", post.Responses)
+	}
+
+	// Routes without a spec are still described, under their first non-api segment
+	undocumented := doc.Paths["/api/undocumented"]["get"]
+	if undocumented == nil || undocumented.Tags[0] != "undocumented" {
+		t.Errorf("expected the undocumented route listed, got %+v", undocumented)
+	}
+
+	missing := MissingSpecs(app, []Spec{{Method: http.MethodGet, Path: "/items/:id"}, {Method: http.MethodPost, Path: "/items/:id/tags"}})
+	if len(missing) != 1 || missing[0] != "GET /api/undocumented" {
+		t.Errorf("expected only the undocumented route missing, got %v", missing)
+	}
+}
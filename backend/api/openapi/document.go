@@ -0,0 +1,80 @@
+// Package openapi builds the OpenAPI 3 description of the HTTP API served at
+// /openapi.json. Paths come from the routes registered on the Fiber app, so every
+// endpoint is listed; summaries, parameters, and body schemas come from the
+// hand-maintained operation table in operations.go.
+package openapi
+
+// Version is the OpenAPI specification version the document follows
+const Version = "3.0.3"
+
+// Document is an OpenAPI 3 document, limited to the fields this API uses
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations on one path
+type PathItem map[string]*Operation
+
+// Operation describes one endpoint
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one response status of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body in one content type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds the named schemas referenced from operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is a JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
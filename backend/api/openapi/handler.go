@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sync"
+
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>ShowMyCards API</title>
+  <link rel="stylesheet" href="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+`
+
+// Handler serves the OpenAPI document for an app's routes
+type Handler struct {
+	app  *fiber.App
+	info Info
+
+	once sync.Once
+	doc  []byte
+	err  error
+}
+
+// NewHandler creates a handler describing app. The document is built on the first
+// request, once every route has been registered.
+func NewHandler(app *fiber.App, info Info) *Handler {
+	return &Handler{app: app, info: info}
+}
+
+// Document returns the OpenAPI document as JSON
+func (h *Handler) Document(c fiber.Ctx) error {
+	h.once.Do(func() {
+		h.doc, h.err = json.Marshal(Build(h.info, h.app, Operations()))
+	})
+	if h.err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build API description", "openapi encoding failed", h.err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Send(h.doc)
+}
+
+// SwaggerUI returns an interactive page for browsing the document
+func (h *Handler) SwaggerUI(c fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(swaggerUIPage)
+}
@@ -0,0 +1,461 @@
+package openapi
+
+import (
+	"net/http"
+
+	"backend/api"
+	"backend/models"
+	"backend/rules"
+	"backend/services"
+	"backend/utils"
+)
+
+// paginated is the legacy paginated response of T; clients sending the API version
+// 2 header get utils.Envelope instead
+func paginated[T any]() any {
+	return utils.PaginatedResponse[T]{}
+}
+
+// withPagination prepends the page and page_size parameters
+func withPagination(params ...Param) []Param {
+	return append(append([]Param{}, paginationParams...), params...)
+}
+
+// Query parameters shared by several endpoints
+var (
+	printFilterParams = []Param{
+		{Name: "promo_type", Description: "Scryfall promo type, e.g. galaxyfoil"},
+		{Name: "frame_effect", Description: "Scryfall frame effect, e.g. showcase"},
+		{Name: "border_color", Description: "Scryfall border color, e.g. borderless"},
+	}
+	inventoryFilterParams = append([]Param{
+		{Name: "storage_location_id", Description: "Location ID, or 0 / unassigned for cards without one"},
+		{Name: "include_descendants", Type: "boolean", Description: "Include locations nested under storage_location_id"},
+		{Name: "standard_legal", Type: "boolean", Description: "Only printings from Standard-legal sets"},
+		{Name: "note_contains", Description: "Case-insensitive substring of the item notes"},
+		{Name: "tag", Description: "Comma-separated tag names; items must carry every one"},
+	}, printFilterParams...)
+	inventoryCardParams = []Param{
+		{Name: "name", Description: "Case-insensitive substring of the card name"},
+		{Name: "set", Description: "Set code"},
+		{Name: "rarity", Description: "Comma-separated rarities"},
+		{Name: "color_identity", Description: "Colors the identity must fall within, e.g. wu, or c for colorless"},
+		{Name: "treatment", Description: "Finish, e.g. foil"},
+		{Name: "price_min", Type: "number", Description: "Per-copy price in the preferred currency"},
+		{Name: "price_max", Type: "number", Description: "Per-copy price in the preferred currency"},
+		{Name: "sort", Description: "added (default), name, or price"},
+		{Name: "order", Description: "asc or desc; defaults to desc for added and price, asc for name"},
+	}
+	transactionFilterParams = []Param{
+		{Name: "type", Description: "sale or trade"},
+		{Name: "counterparty", Description: "Case-insensitive substring of the counterparty"},
+		{Name: "from", Description: "First date included, YYYY-MM-DD"},
+		{Name: "to", Description: "Last date included, YYYY-MM-DD"},
+	}
+)
+
+// map[string]any documents endpoints that answer with small ad hoc objects
+type object = map[string]any
+
+// Operations documents every route the server registers. MissingSpecs reports routes
+// added without an entry here.
+func Operations() []Spec {
+	return []Spec{
+		// Health and realtime
+		{Method: http.MethodGet, Path: "/health", Summary: "Server and database health", Response: object{}},
+		{Method: http.MethodGet, Path: "/health/live", Summary: "Liveness probe", Response: object{}},
+		{Method: http.MethodGet, Path: "/health/ready", Summary: "Readiness probe with dependency details", Response: api.ReadinessResponse{}},
+		{Method: http.MethodGet, Path: "/ws", Summary: "WebSocket stream of inventory and job change events"},
+
+		// Dashboard
+		{Method: http.MethodGet, Path: "/api/dashboard/stats", Summary: "Collection statistics and configured widgets", Response: api.DashboardStats{}},
+		{Method: http.MethodGet, Path: "/stats/collection", Summary: "Collection breakdowns by color identity, rarity, set, card type and price",
+			Response: api.CollectionStats{}},
+		{Method: http.MethodGet, Path: "/api/dashboard/counts-history", Summary: "Daily inventory counts for growth charts",
+			Query: []Param{{Name: "days", Type: "integer"}}, Response: api.CountsHistoryResponse{}},
+		{Method: http.MethodGet, Path: "/api/dashboard/widgets", Summary: "Configured dashboard widgets", Response: []models.DashboardWidget{}},
+		{Method: http.MethodPut, Path: "/api/dashboard/widgets", Summary: "Replace the dashboard widgets",
+			Request: api.UpdateDashboardWidgetsRequest{}, Response: []models.DashboardWidget{}},
+
+		// Storage locations
+		{Method: http.MethodGet, Path: "/storage", Summary: "List storage locations", Query: withPagination(),
+			Response: paginated[models.StorageLocation]()},
+		{Method: http.MethodGet, Path: "/storage/with-counts", Summary: "Storage locations with card counts",
+			Query: []Param{{Name: "include_unassigned", Type: "boolean"}}, Response: []api.StorageLocationWithCount{}},
+		{Method: http.MethodGet, Path: "/storage/tree", Summary: "Storage locations as a nested tree", Response: []api.StorageLocationNode{}},
+		{Method: http.MethodGet, Path: "/storage/resolve", Summary: "Storage location named by a scanned label",
+			Query: []Param{{Name: "code", Description: "Scanned label URL, its path, or a location ID"}}, Response: models.StorageLocation{}},
+		{Method: http.MethodGet, Path: "/storage/:id", Summary: "Get a storage location", Response: models.StorageLocation{}},
+		{Method: http.MethodPost, Path: "/storage", Summary: "Create a storage location",
+			Request: api.CreateStorageRequest{}, Response: models.StorageLocation{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/storage/:id", Summary: "Update a storage location",
+			Request: api.CreateStorageRequest{}, Response: models.StorageLocation{}},
+		{Method: http.MethodDelete, Path: "/storage/:id", Summary: "Delete a storage location", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/storage/:id/move", Summary: "Move a storage location under another",
+			Request: api.MoveStorageRequest{}, Response: models.StorageLocation{}},
+		{Method: http.MethodGet, Path: "/storage/:id/binder-layout", Summary: "Binder page and pocket layout, as JSON or PDF",
+			Query: []Param{
+				{Name: "pockets", Type: "integer", Description: "Pockets per page"},
+				{Name: "sort", Description: "set (default), name, color, or price"},
+				{Name: "format", Description: "json (default) or pdf"},
+			}, Response: services.BinderLayout{}},
+		{Method: http.MethodGet, Path: "/storage/:id/label", Summary: "Printable label with a QR code linking to the contents",
+			Query: []Param{{Name: "format", Description: "pdf (default) or png"}}, ResponseType: "application/pdf"},
+		{Method: http.MethodGet, Path: "/storage/:id/photo", Summary: "Download the location photo", ResponseType: "image/*"},
+		{Method: http.MethodPut, Path: "/storage/:id/photo", Summary: "Upload the location photo (form field photo)",
+			RequestType: "multipart/form-data", Response: models.StorageLocation{}},
+		{Method: http.MethodDelete, Path: "/storage/:id/photo", Summary: "Delete the location photo", Status: http.StatusNoContent},
+
+		// Sorting rules and predicates
+		{Method: http.MethodGet, Path: "/sorting-rules", Summary: "List sorting rules by priority",
+			Query: withPagination(Param{Name: "enabled", Type: "boolean"}), Response: paginated[models.SortingRule]()},
+		{Method: http.MethodGet, Path: "/sorting-rules/performance", Summary: "Per-rule evaluation time from the last resort",
+			Response: services.RulePerformanceReport{}},
+		{Method: http.MethodGet, Path: "/sorting-rules/helpers", Summary: "Functions and operators available in rule expressions",
+			Response: []rules.Helper{}},
+		{Method: http.MethodGet, Path: "/sorting-rules/:id", Summary: "Get a sorting rule", Response: models.SortingRule{}},
+		{Method: http.MethodPost, Path: "/sorting-rules", Summary: "Create a sorting rule",
+			Request: api.CreateSortingRuleRequest{}, Response: models.SortingRule{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/sorting-rules/:id", Summary: "Update a sorting rule",
+			Request: api.UpdateSortingRuleRequest{}, Response: models.SortingRule{}},
+		{Method: http.MethodDelete, Path: "/sorting-rules/:id", Summary: "Delete a sorting rule", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/sorting-rules/batch/priorities", Summary: "Set the priorities of several rules",
+			Request: api.BatchUpdatePrioritiesRequest{}, Response: api.BatchUpdatePrioritiesResponse{}},
+		{Method: http.MethodPost, Path: "/sorting-rules/reorder", Summary: "Set the order of every rule at once",
+			Request: api.ReorderSortingRulesRequest{}, Response: api.MoveSortingRuleResponse{}},
+		{Method: http.MethodPost, Path: "/sorting-rules/:id/move", Summary: "Move a rule up or down the priority order",
+			Request: api.MoveSortingRuleRequest{}, Response: api.MoveSortingRuleResponse{}},
+		{Method: http.MethodPost, Path: "/sorting-rules/evaluate", Summary: "Find the location the rules pick for a card",
+			Request: api.EvaluateRequest{}, Response: api.EvaluateResponse{}},
+		{Method: http.MethodPost, Path: "/sorting-rules/validate", Summary: "Check that a rule expression compiles",
+			Request: api.ValidateExpressionRequest{}, Response: api.ValidateExpressionResponse{}},
+		{Method: http.MethodPost, Path: "/sorting-rules/test", Summary: "Test rules against sample cards",
+			Request: api.TestRulesRequest{}, Response: api.TestRulesResponse{}},
+		{Method: http.MethodGet, Path: "/predicates", Summary: "List named predicates", Query: withPagination(),
+			Response: paginated[models.NamedPredicate]()},
+		{Method: http.MethodGet, Path: "/predicates/:id", Summary: "Get a named predicate", Response: models.NamedPredicate{}},
+		{Method: http.MethodPost, Path: "/predicates", Summary: "Create a named predicate",
+			Request: api.CreatePredicateRequest{}, Response: models.NamedPredicate{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/predicates/:id", Summary: "Update a named predicate",
+			Request: api.UpdatePredicateRequest{}, Response: models.NamedPredicate{}},
+		{Method: http.MethodDelete, Path: "/predicates/:id", Summary: "Delete a named predicate", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/rule-groups", Summary: "List rule groups by priority",
+			Query: withPagination(Param{Name: "enabled", Type: "boolean"}), Response: paginated[models.RuleGroup]()},
+		{Method: http.MethodGet, Path: "/rule-groups/:id", Summary: "Get a rule group", Response: models.RuleGroup{}},
+		{Method: http.MethodPost, Path: "/rule-groups", Summary: "Create a rule group",
+			Request: api.CreateRuleGroupRequest{}, Response: models.RuleGroup{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/rule-groups/:id", Summary: "Update a rule group",
+			Request: api.UpdateRuleGroupRequest{}, Response: models.RuleGroup{}},
+		{Method: http.MethodDelete, Path: "/rule-groups/:id", Summary: "Delete a rule group", Status: http.StatusNoContent},
+
+		// Tags
+		{Method: http.MethodGet, Path: "/tags", Summary: "List tags by name with item counts",
+			Query: withPagination(), Response: paginated[models.Tag]()},
+		{Method: http.MethodGet, Path: "/tags/:id", Summary: "Get a tag", Response: models.Tag{}},
+		{Method: http.MethodPost, Path: "/tags", Summary: "Create a tag",
+			Request: api.TagRequest{}, Response: models.Tag{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/tags/:id", Summary: "Rename a tag",
+			Request: api.TagRequest{}, Response: models.Tag{}},
+		{Method: http.MethodDelete, Path: "/tags/:id", Summary: "Delete a tag, removing it from every item", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/inventory/:id/tags", Summary: "Tag an inventory row",
+			Request: api.AddInventoryTagRequest{}, Response: api.InventoryTagsResponse{}},
+		{Method: http.MethodDelete, Path: "/inventory/:id/tags/:tag_id", Summary: "Remove a tag from an inventory row", Status: http.StatusNoContent},
+
+		// Inventory
+		{Method: http.MethodGet, Path: "/inventory", Summary: "List inventory rows",
+			Query:    withPagination(append([]Param{{Name: "scryfall_id"}}, inventoryFilterParams...)...),
+			Response: paginated[models.Inventory]()},
+		{Method: http.MethodGet, Path: "/inventory/cards", Summary: "Inventory grouped into card results",
+			Query: withPagination(append(append([]Param{}, inventoryFilterParams...), inventoryCardParams...)...), Response: api.InventoryCardsResponse{}},
+		{Method: http.MethodGet, Path: "/inventory/unassigned/count", Summary: "Number of rows without a storage location",
+			Response: object{}},
+		{Method: http.MethodGet, Path: "/inventory/unassigned/suggestions", Summary: "Suggested locations for unassigned rows",
+			Query: withPagination(), Response: paginated[services.StorageSuggestion]()},
+		{Method: http.MethodGet, Path: "/inventory/consolidation-suggestions", Summary: "Printings scattered across locations, with a move plan",
+			Query:    []Param{{Name: "min_locations", Type: "integer"}, {Name: "limit", Type: "integer"}},
+			Response: api.ConsolidationSuggestionsResponse{}},
+		{Method: http.MethodGet, Path: "/inventory/serialized", Summary: "Registry of serial-numbered copies",
+			Response: api.SerializedRegistryResponse{}},
+		{Method: http.MethodGet, Path: "/inventory/duplicates", Summary: "Cards owned in more copies than a threshold",
+			Query:    []Param{{Name: "threshold", Type: "integer", Description: "Defaults to the duplicates_threshold setting"}},
+			Response: api.DuplicatesResponse{}},
+		{Method: http.MethodGet, Path: "/inventory/duplicates/export", Summary: "Download the duplicates report as CSV",
+			Query: []Param{
+				{Name: "format", Description: "csv (default)"},
+				{Name: "threshold", Type: "integer", Description: "Defaults to the duplicates_threshold setting"},
+			}, ResponseType: "text/csv"},
+		{Method: http.MethodGet, Path: "/inventory/trash", Summary: "Soft-deleted inventory rows", Query: withPagination(),
+			Response: paginated[models.Inventory]()},
+		{Method: http.MethodGet, Path: "/inventory/export.ndjson", Summary: "Export the inventory as newline-delimited JSON",
+			ResponseType: "application/x-ndjson"},
+		{Method: http.MethodGet, Path: "/inventory/by-oracle/:oracle_id", Summary: "Owned printings of a card",
+			Response: api.ByOracleResponse{}},
+		{Method: http.MethodPost, Path: "/inventory/batch/add", Summary: "Add up to 500 cards by Scryfall ID, set and collector number, or name",
+			Request: api.BatchAddRequest{}, Response: api.BatchAddResponse{}},
+		{Method: http.MethodPost, Path: "/inventory/batch/move", Summary: "Move rows to a storage location",
+			Request: api.BatchMoveRequest{}, Response: api.BatchMoveResponse{}},
+		{Method: http.MethodDelete, Path: "/inventory/batch", Summary: "Delete several rows",
+			Request: api.BatchDeleteRequest{}, Response: api.BatchDeleteResponse{}},
+		{Method: http.MethodPost, Path: "/inventory/resort", Summary: "Re-apply sorting rules to inventory (with async, start a resort job and respond 202 with its job_id)",
+			Request: api.ResortRequest{}, Response: api.ResortResponse{}},
+		{Method: http.MethodPost, Path: "/inventory/import-text", Summary: "Add cards from a plain-text list",
+			Request: api.ImportTextRequest{}, Response: api.ImportTextResponse{}},
+		{Method: http.MethodPost, Path: "/inventory/import", Summary: "Start a CSV import job (form fields file, format, storage_location_id)",
+			RequestType: "multipart/form-data", Response: api.InventoryImportResponse{}, Status: http.StatusAccepted},
+		{Method: http.MethodGet, Path: "/inventory/:id", Summary: "Get an inventory row", Response: models.Inventory{}},
+		{Method: http.MethodPost, Path: "/inventory", Summary: "Add cards to the inventory",
+			Query:   []Param{{Name: "upsert", Type: "boolean", Description: "Add the copies to a matching row (200) instead of creating one"}},
+			Request: api.CreateInventoryRequest{}, Response: models.Inventory{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/inventory/:id", Summary: "Update an inventory row",
+			Request: api.UpdateInventoryRequest{}, Response: models.Inventory{}},
+		{Method: http.MethodDelete, Path: "/inventory/:id", Summary: "Move an inventory row to the trash", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/inventory/:id/restore", Summary: "Restore a row from the trash", Response: models.Inventory{}},
+
+		// History and undo
+		{Method: http.MethodGet, Path: "/history", Summary: "Inventory change history",
+			Query: withPagination(Param{Name: "event_type"}), Response: paginated[models.InventoryEvent]()},
+		{Method: http.MethodGet, Path: "/inventory/:id/history", Summary: "Change history of one inventory row",
+			Query: withPagination(), Response: paginated[models.InventoryEvent]()},
+		{Method: http.MethodPost, Path: "/undo/:token", Summary: "Undo a batch operation by its undo token", Response: services.UndoResult{}},
+		{Method: http.MethodGet, Path: "/operations", Summary: "Recorded batch operations", Query: withPagination(),
+			Response: paginated[models.InventoryOperation]()},
+		{Method: http.MethodPost, Path: "/operations/:id/undo", Summary: "Undo a recorded batch operation", Response: services.UndoResult{}},
+
+		// Decks
+		{Method: http.MethodGet, Path: "/decks", Summary: "List decks by name",
+			Query:    withPagination(Param{Name: "format", Description: "standard, pioneer, modern, legacy, vintage, pauper, or commander"}),
+			Response: paginated[models.Deck]()},
+		{Method: http.MethodGet, Path: "/decks/:id", Summary: "Get a deck with its cards", Response: models.Deck{}},
+		{Method: http.MethodPost, Path: "/decks", Summary: "Create a deck",
+			Request: api.DeckRequest{}, Response: models.Deck{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/decks/:id", Summary: "Update a deck",
+			Request: api.DeckRequest{}, Response: models.Deck{}},
+		{Method: http.MethodDelete, Path: "/decks/:id", Summary: "Delete a deck and its cards", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/decks/:id/legality", Summary: "Check a deck against its format's rules",
+			Response: services.DeckLegality{}},
+		{Method: http.MethodGet, Path: "/decks/:id/coverage", Summary: "How much of a deck the inventory covers",
+			Response: services.DeckCoverage{}},
+		{Method: http.MethodPost, Path: "/decks/:id/cards", Summary: "Add copies of a card to a deck",
+			Request: api.AddDeckCardRequest{}, Response: models.DeckCard{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/decks/:id/cards/:card_id", Summary: "Change a deck card's quantity or zone",
+			Request: api.UpdateDeckCardRequest{}, Response: models.DeckCard{}},
+		{Method: http.MethodDelete, Path: "/decks/:id/cards/:card_id", Summary: "Remove a card from a deck", Status: http.StatusNoContent},
+
+		// Lists
+		{Method: http.MethodGet, Path: "/lists", Summary: "Lists with summary statistics", Response: []api.ListSummary{}},
+		{Method: http.MethodGet, Path: "/lists/contention", Summary: "Cards wanted by more lists than copies owned",
+			Response: []services.ContendedCard{}},
+		{Method: http.MethodGet, Path: "/lists/:id", Summary: "Get a list", Response: models.List{}},
+		{Method: http.MethodPost, Path: "/lists", Summary: "Create a list",
+			Request: api.CreateListRequest{}, Response: models.List{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/lists/:id", Summary: "Update a list", Request: api.UpdateListRequest{}, Response: models.List{}},
+		{Method: http.MethodDelete, Path: "/lists/:id", Summary: "Delete a list", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/lists/:id/analysis", Summary: "Archetype suggestions and overlap with other lists",
+			Response: services.ListAnalysis{}},
+		{Method: http.MethodGet, Path: "/lists/:id/export", Summary: "Download the list as a deck list",
+			Query: []Param{{Name: "format", Description: "arena (default), moxfield, txt, or csv"}}, ResponseType: "text/plain"},
+		{Method: http.MethodPost, Path: "/lists/:id/sync", Summary: "Set collected quantities from the inventory", Response: api.ListSyncResponse{}},
+		{Method: http.MethodPost, Path: "/lists/:id/duplicate", Summary: "Copy a list and its items",
+			Request: api.DuplicateListRequest{}, Response: models.List{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/lists/:id/merge", Summary: "Merge another list's items into this one",
+			Request: api.MergeListRequest{}, Response: api.MergeListResponse{}},
+		{Method: http.MethodGet, Path: "/lists/:id/items", Summary: "List items with prices and totals",
+			Query: withPagination(), Response: api.ListItemsResponse{}},
+		{Method: http.MethodPost, Path: "/lists/:id/items/batch", Summary: "Add items to a list",
+			Request: api.CreateItemsBatchRequest{}, Response: []models.ListItem{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/lists/:id/items/parse", Summary: "Parse a pasted deck list into items",
+			Request: api.ParseListItemsRequest{}, Response: api.ParseListItemsResponse{}},
+		{Method: http.MethodPut, Path: "/lists/:id/items/:item_id", Summary: "Update a list item",
+			Request: api.UpdateListItemRequest{}, Response: models.ListItem{}},
+		{Method: http.MethodPost, Path: "/lists/:id/items/:item_id/collect", Summary: "Collect a list item into the inventory",
+			Request: api.CollectListItemRequest{}, Response: api.CollectListItemResponse{}},
+		{Method: http.MethodDelete, Path: "/lists/:id/items/:item_id", Summary: "Remove an item from a list", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/lists/:id/shares", Summary: "Collaborator invites for a list", Response: []models.ListShare{}},
+		{Method: http.MethodPost, Path: "/lists/:id/shares", Summary: "Invite a collaborator to edit a list",
+			Request: api.CreateListShareRequest{}, Response: models.ListShare{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/lists/:id/shares/:share_id", Summary: "Revoke a collaborator invite", Response: models.ListShare{}},
+		{Method: http.MethodGet, Path: "/shared/:token", Summary: "Open a collaborator invite", Response: api.SharedListResponse{}},
+		{Method: http.MethodGet, Path: "/shared/:token/items", Summary: "Items of the list behind an invite",
+			Query: withPagination(), Response: api.ListItemsResponse{}},
+		{Method: http.MethodPut, Path: "/shared/:token/items/:item_id", Summary: "Update a list item as the collaborator",
+			Request: api.UpdateListItemRequest{}, Response: models.ListItem{}},
+
+		// Read-only share links
+		{Method: http.MethodGet, Path: "/share-links", Summary: "List share links",
+			Query:    []Param{{Name: "list_id", Type: "integer"}, {Name: "storage_location_id", Type: "integer"}},
+			Response: []models.ShareLink{}},
+		{Method: http.MethodPost, Path: "/share-links", Summary: "Create a read-only share link",
+			Request: api.CreateShareLinkRequest{}, Response: models.ShareLink{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/share-links/:id", Summary: "Revoke a share link", Response: models.ShareLink{}},
+		{Method: http.MethodGet, Path: "/share/:token", Summary: "Open a share link", Response: api.SharedViewResponse{}},
+		{Method: http.MethodGet, Path: "/share/:token/items", Summary: "Shared list items or storage location cards",
+			Query: withPagination()},
+
+		// Loans and notifications
+		{Method: http.MethodGet, Path: "/loans", Summary: "List loans",
+			Query: withPagination(Param{Name: "status", Description: "active, overdue, or returned"}), Response: paginated[models.Loan]()},
+		{Method: http.MethodGet, Path: "/loans/overdue", Summary: "Active loans past their due date, most overdue first",
+			Response: api.OverdueLoansResponse{}},
+		{Method: http.MethodGet, Path: "/loans/:id", Summary: "Get a loan", Response: models.Loan{}},
+		{Method: http.MethodPost, Path: "/loans", Summary: "Lend cards to someone",
+			Request: api.CreateLoanRequest{}, Response: models.Loan{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/loans/:id/return", Summary: "Mark a loan returned", Response: models.Loan{}},
+		{Method: http.MethodGet, Path: "/intake-sessions", Summary: "List intake sessions",
+			Query: withPagination(Param{Name: "status", Description: "open, committed, or aborted"}), Response: paginated[models.IntakeSession]()},
+		{Method: http.MethodPost, Path: "/intake-sessions", Summary: "Open an intake session",
+			Request: api.CreateIntakeSessionRequest{}, Response: api.IntakeSessionResponse{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/intake-sessions/:id", Summary: "Get an intake session with its staged items and summary",
+			Response: api.IntakeSessionResponse{}},
+		{Method: http.MethodPost, Path: "/intake-sessions/:id/items", Summary: "Stage cards by Scryfall ID, set and collector number, or name",
+			Request: api.AddIntakeItemsRequest{}, Response: api.AddIntakeItemsResponse{}},
+		{Method: http.MethodDelete, Path: "/intake-sessions/:id/items/:item_id", Summary: "Remove a staged card", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Path: "/intake-sessions/:id/commit", Summary: "Write the staged cards to inventory",
+			Response: api.IntakeSessionResponse{}},
+		{Method: http.MethodPost, Path: "/intake-sessions/:id/abort", Summary: "Discard the staged cards",
+			Response: api.IntakeSessionResponse{}},
+		{Method: http.MethodGet, Path: "/transactions", Summary: "List sales and trades",
+			Query: withPagination(transactionFilterParams...), Response: paginated[models.Transaction]()},
+		{Method: http.MethodPost, Path: "/transactions", Summary: "Record a sale or trade, taking its cards out of inventory",
+			Request: api.CreateTransactionRequest{}, Response: models.Transaction{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/transactions/report", Summary: "Proceeds and profit/loss against acquisition price, per currency",
+			Query: transactionFilterParams, Response: api.TransactionReportResponse{}},
+		{Method: http.MethodGet, Path: "/transactions/:id", Summary: "Get a sale or trade", Response: models.Transaction{}},
+		{Method: http.MethodGet, Path: "/notifications", Summary: "List notifications",
+			Query: withPagination(Param{Name: "unread", Type: "boolean"}), Response: paginated[models.Notification]()},
+		{Method: http.MethodPut, Path: "/notifications/:id/read", Summary: "Mark a notification read", Response: models.Notification{}},
+		{Method: http.MethodPost, Path: "/notifications/channels/test", Summary: "Send a test message to every configured notification channel",
+			Response: []services.ChannelTestResult{}},
+		{Method: http.MethodGet, Path: "/alerts/legality", Summary: "Ban and restriction changes for owned cards",
+			Query: withPagination(Param{Name: "format"}), Response: paginated[models.LegalityChange]()},
+		{Method: http.MethodGet, Path: "/alerts/value", Summary: "List value alerts by name",
+			Query: withPagination(), Response: paginated[models.ValueAlert]()},
+		{Method: http.MethodGet, Path: "/alerts/value/:id", Summary: "Get a value alert", Response: models.ValueAlert{}},
+		{Method: http.MethodPost, Path: "/alerts/value", Summary: "Create a price change or collection value alert",
+			Request: api.ValueAlertRequest{}, Response: models.ValueAlert{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/alerts/value/:id", Summary: "Replace a value alert's settings",
+			Request: api.ValueAlertRequest{}, Response: models.ValueAlert{}},
+		{Method: http.MethodDelete, Path: "/alerts/value/:id", Summary: "Delete a value alert", Status: http.StatusNoContent},
+
+		// Webhooks
+		{Method: http.MethodGet, Path: "/webhooks", Summary: "List webhooks by name",
+			Query: withPagination(), Response: paginated[models.Webhook]()},
+		{Method: http.MethodGet, Path: "/webhooks/:id", Summary: "Get a webhook", Response: models.Webhook{}},
+		{Method: http.MethodPost, Path: "/webhooks", Summary: "Create a webhook for job, bulk import and inventory events",
+			Request: api.WebhookRequest{}, Response: models.Webhook{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/webhooks/:id", Summary: "Replace a webhook's settings",
+			Request: api.WebhookRequest{}, Response: models.Webhook{}},
+		{Method: http.MethodDelete, Path: "/webhooks/:id", Summary: "Delete a webhook and its delivery log", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", Summary: "A webhook's deliveries, newest first",
+			Query: withPagination(), Response: paginated[models.WebhookDelivery]()},
+		{Method: http.MethodPost, Path: "/webhooks/:id/test", Summary: "Send a ping delivery to a webhook",
+			Response: models.WebhookDelivery{}, Status: http.StatusAccepted},
+
+		// Search and sets
+		{Method: http.MethodGet, Path: "/search", Summary: "Search Scryfall, with owned inventory per card",
+			Query:    append([]Param{{Name: "q", Description: "Scryfall search query (required)"}, {Name: "page", Type: "integer"}}, printFilterParams...),
+			Response: api.SearchResponse{}},
+		{Method: http.MethodGet, Path: "/search/autocomplete", Summary: "Card name suggestions",
+			Query: []Param{{Name: "q"}}, Response: api.AutocompleteResponse{}},
+		{Method: http.MethodGet, Path: "/cards/search", Summary: "Search the local card database",
+			Query: withPagination(
+				Param{Name: "q", Description: "Card name substring"},
+				Param{Name: "set"},
+				Param{Name: "color", Description: "Colors as letters, e.g. wu, or c for colorless"},
+				Param{Name: "rarity", Description: "Comma-separated rarities"},
+				Param{Name: "cmc_min", Type: "number"},
+				Param{Name: "cmc_max", Type: "number"},
+				Param{Name: "price_min", Type: "number"},
+				Param{Name: "price_max", Type: "number"},
+			), Response: paginated[api.EnhancedCardResult]()},
+		{Method: http.MethodGet, Path: "/cards/lookup", Summary: "Find one imported printing with its inventory, for scanning",
+			Query: []Param{
+				{Name: "set"},
+				{Name: "cn", Description: "Collector number; used with set"},
+				{Name: "name", Description: "Card name, when no collector number is given"},
+			}, Response: api.EnhancedCardResult{}},
+		{Method: http.MethodGet, Path: "/cards/:id", Summary: "Get a card by Scryfall ID", Response: api.EnhancedCardResult{}},
+		{Method: http.MethodGet, Path: "/sets", Summary: "List sets",
+			Query:    withPagination(Param{Name: "standard_legal", Type: "boolean"}, Param{Name: "preview", Type: "boolean"}),
+			Response: paginated[models.Set]()},
+		{Method: http.MethodGet, Path: "/sets/id/:id", Summary: "Get a set by Scryfall ID", Response: models.Set{}},
+		{Method: http.MethodGet, Path: "/sets/code/:code", Summary: "Get a set by code", Response: models.Set{}},
+		{Method: http.MethodGet, Path: "/sets/code/:code/icon", Summary: "Set symbol", ResponseType: "image/svg+xml"},
+		{Method: http.MethodGet, Path: "/sets/completion", Summary: "Completion of every set with an owned card",
+			Response: []services.SetCompletion{}},
+		{Method: http.MethodGet, Path: "/sets/code/:code/completion", Summary: "Owned versus total printings of a set, by rarity",
+			Response: services.SetCompletion{}},
+		{Method: http.MethodPost, Path: "/sets/code/:code/generate-list", Summary: "Create a list of the set's missing cards",
+			Request: api.GenerateSetListRequest{}, Response: models.List{}, Status: http.StatusCreated},
+		{Method: http.MethodPost, Path: "/sets/preview-cards/:id", Summary: "Fetch a preview card from Scryfall", Response: models.Card{}},
+		{Method: http.MethodPost, Path: "/sets/import", Summary: "Start a set data import job",
+			Response: api.TriggerImportResponse{}, Status: http.StatusAccepted},
+
+		// Card images
+		{Method: http.MethodGet, Path: "/images/cards/:scryfall_id", Summary: "Card image, cached locally from Scryfall",
+			Query: []Param{{Name: "size", Description: "normal (default) or small"}}, ResponseType: "image/jpeg"},
+		{Method: http.MethodPost, Path: "/images/prefetch", Summary: "Start a job caching images of inventory cards",
+			Response: api.TriggerImportResponse{}, Status: http.StatusAccepted},
+
+		// Settings, jobs, and data
+		{Method: http.MethodGet, Path: "/api/settings", Summary: "All settings", Response: map[string]string{}},
+		{Method: http.MethodPut, Path: "/api/settings", Summary: "Update several settings",
+			Request: map[string]string{}, Response: object{}},
+		{Method: http.MethodGet, Path: "/api/settings/:key", Summary: "Get a setting", Response: object{}},
+		{Method: http.MethodPut, Path: "/api/settings/:key", Summary: "Update a setting",
+			Request: struct {
+				Value string `json:"value"`
+			}{}, Response: object{}},
+		{Method: http.MethodGet, Path: "/api/scheduler/tasks", Summary: "Scheduled tasks and their next runs",
+			Response: []api.ScheduledTaskInfo{}},
+		{Method: http.MethodPost, Path: "/api/scheduler/tasks/:name/run", Summary: "Run a scheduled task now",
+			Response: object{}, Status: http.StatusAccepted},
+		{Method: http.MethodGet, Path: "/api/jobs", Summary: "List background jobs",
+			Query: withPagination(Param{Name: "type"}, Param{Name: "status"}), Response: paginated[models.Job]()},
+		{Method: http.MethodGet, Path: "/api/jobs/export", Summary: "Download the job history as CSV",
+			Query: []Param{
+				{Name: "format", Description: "csv (default)"},
+				{Name: "type"},
+				{Name: "status"},
+				{Name: "since", Description: "YYYY-MM-DD, UTC"},
+			}, ResponseType: "text/csv"},
+		{Method: http.MethodGet, Path: "/api/jobs/:id", Summary: "Get a job", Response: models.Job{}},
+		{Method: http.MethodGet, Path: "/api/jobs/:id/digest", Summary: "What a bulk import changed for owned cards",
+			Response: services.ImportDigestReport{}},
+		{Method: http.MethodGet, Path: "/api/jobs/:id/result", Summary: "The stored result of a finished job, such as an async resort",
+			Response: object{}},
+		{Method: http.MethodPost, Path: "/api/jobs/:id/cancel", Summary: "Cancel a pending or running job", Response: models.Job{}},
+		{Method: http.MethodPost, Path: "/api/jobs/:id/retry", Summary: "Run a failed bulk or set data import again",
+			Response: models.Job{}, Status: http.StatusAccepted},
+		{Method: http.MethodDelete, Path: "/api/jobs/cleanup", Summary: "Delete old jobs",
+			Query: []Param{{Name: "retention_days", Type: "integer"}}, Response: object{}},
+		{Method: http.MethodPost, Path: "/api/bulk-data/import", Summary: "Start a Scryfall bulk data import job",
+			Query:    []Param{{Name: "batch_size", Type: "integer"}, {Name: "transaction_size", Type: "integer"}},
+			Response: object{}, Status: http.StatusAccepted},
+		{Method: http.MethodPost, Path: "/api/bulk-data/import/:id/resume", Summary: "Resume an interrupted bulk data import",
+			Response: object{}, Status: http.StatusAccepted},
+		{Method: http.MethodPost, Path: "/api/maintenance/reindex", Summary: "Start a reindex job",
+			Response: object{}, Status: http.StatusAccepted},
+		{Method: http.MethodPost, Path: "/api/admin/db/vacuum", Summary: "Rebuild the database file to reclaim free space",
+			Response: services.DatabaseMaintenanceResult{}},
+		{Method: http.MethodPost, Path: "/api/admin/db/analyze", Summary: "Refresh query planner statistics",
+			Response: services.DatabaseMaintenanceResult{}},
+		{Method: http.MethodGet, Path: "/api/admin/db/integrity", Summary: "Run the SQLite integrity check",
+			Response: services.IntegrityCheckResult{}},
+		{Method: http.MethodPost, Path: "/api/admin/backup", Summary: "Back up the database",
+			Response: services.BackupInfo{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/admin/backups", Summary: "Stored backups, newest first",
+			Response: []services.BackupInfo{}},
+		{Method: http.MethodGet, Path: "/api/admin/backups/:name", Summary: "Download a stored backup (supports Range requests)",
+			ResponseType: "application/octet-stream"},
+		{Method: http.MethodPost, Path: "/api/admin/restore", Summary: "Replace the database with a stored or uploaded backup",
+			Request: api.RestoreBackupRequest{}, Response: services.RestoreResult{}},
+		{Method: http.MethodGet, Path: "/api/data/export", Summary: "Download a full data export", Response: api.ExportData{}},
+		{Method: http.MethodPost, Path: "/api/data/import", Summary: "Restore a data export",
+			Request: api.ExportData{}, Response: api.ImportResponse{}},
+
+		// API description
+		{Method: http.MethodGet, Path: "/openapi.json", Summary: "This OpenAPI document", Response: object{}},
+		{Method: http.MethodGet, Path: "/docs", Summary: "Swagger UI for this document, when SWAGGER_UI=true",
+			ResponseType: "text/html"},
+	}
+}
@@ -0,0 +1,187 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// schemaRegistry turns Go types into schemas, registering each named struct once
+// under components/schemas and referring to it from everywhere else
+type schemaRegistry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{schemas: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// schemaFor returns the schema of the value's type as encoding/json would write it
+func (r *schemaRegistry) schemaFor(value any) *Schema {
+	return r.schema(reflect.TypeOf(value))
+}
+
+func (r *schemaRegistry) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		s = &Schema{}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		s = &Schema{Ref: "#/components/schemas/" + r.register(t)}
+	case t.Kind() == reflect.Struct:
+		s = r.structSchema(t)
+	default:
+		s = r.basicSchema(t)
+	}
+
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (r *schemaRegistry) basicSchema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schema(t.Elem())}
+	}
+	// Interfaces and anything else can hold any JSON value
+	return &Schema{}
+}
+
+// register adds a named struct to components/schemas and returns its name
+func (r *schemaRegistry) register(t reflect.Type) string {
+	if name, ok := r.names[t]; ok {
+		return name
+	}
+
+	name := schemaName(t, false)
+	if _, taken := r.schemas[name]; taken {
+		name = schemaName(t, true)
+	}
+	r.names[t] = name
+
+	// Types with their own JSON encoding are described as free-form values
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		r.schemas[name] = &Schema{}
+		return name
+	}
+
+	// Reserve the name before walking fields so recursive types terminate
+	r.schemas[name] = &Schema{Type: "object"}
+	r.schemas[name] = r.structSchema(t)
+	return name
+}
+
+// structSchema describes a struct's JSON object, flattening embedded structs the way
+// encoding/json does
+func (r *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *schemaRegistry) addFields(s *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				r.addFields(s, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := r.schema(field.Type)
+		if strings.Contains(opts, "string") && fieldSchema.Ref == "" {
+			fieldSchema = &Schema{Type: "string", Nullable: fieldSchema.Nullable}
+		}
+		s.Properties[name] = fieldSchema
+	}
+}
+
+// schemaName derives a component name from a type, e.g. "Job" for models.Job or
+// "PaginatedResponseJob" for utils.PaginatedResponse[models.Job]. qualified prefixes
+// the package name to tell apart types that share a name.
+func schemaName(t reflect.Type, qualified bool) string {
+	name := t.Name()
+	if qualified {
+		pkg := t.PkgPath()
+		if i := strings.LastIndex(pkg, "/"); i >= 0 {
+			pkg = pkg[i+1:]
+		}
+		name = pkg + "." + name
+	}
+
+	// Generic instantiations name their type arguments with full package paths
+	var b strings.Builder
+	upper := true
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '[' || r == ']' || r == ',' }) {
+		if i := strings.LastIndex(part, "."); i >= 0 && b.Len() > 0 {
+			part = part[i+1:]
+		}
+		for _, c := range part {
+			if !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+				upper = true
+				continue
+			}
+			if upper {
+				c = unicode.ToUpper(c)
+				upper = false
+			}
+			b.WriteRune(c)
+		}
+		upper = true
+	}
+	return b.String()
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"backend/models"
+	"backend/rules"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// PredicatesHandler handles named predicate endpoints
+type PredicatesHandler struct {
+	db *gorm.DB
+}
+
+// NewPredicatesHandler creates a new named predicates handler
+func NewPredicatesHandler(db *gorm.DB) *PredicatesHandler {
+	return &PredicatesHandler{db: db}
+}
+
+// List returns named predicates with pagination, ordered by name
+func (h *PredicatesHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.NamedPredicate{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count predicates", "database count failed", err)
+	}
+
+	var predicates []models.NamedPredicate
+	offset := utils.CalculateOffset(params.Page, params.PageSize)
+	if err := query.Order("name ASC").
+		Offset(offset).
+		Limit(params.PageSize).
+		Find(&predicates).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch predicates", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, predicates, params.Page, params.PageSize, total)
+}
+
+// Get returns a single named predicate by ID
+func (h *PredicatesHandler) Get(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var predicate models.NamedPredicate
+	if err := h.db.WithContext(c.RequestCtx()).First(&predicate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "predicate not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch predicate", "database query failed", err)
+	}
+	return c.JSON(predicate)
+}
+
+// CreatePredicateRequest represents the request body for creating a named predicate
+// tygo:export
+type CreatePredicateRequest struct {
+	Name        string `json:"name"`
+	Expression  string `json:"expression"`
+	Description string `json:"description,omitempty"`
+}
+
+// Create creates a new named predicate
+func (h *PredicatesHandler) Create(c fiber.Ctx) error {
+	var req CreatePredicateRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateRequired(req.Name, "name"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Name, 100, "name"))
+	validationErrors = append(validationErrors, utils.ValidateRequired(req.Expression, "expression"))
+
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	predicate := models.NamedPredicate{
+		Name:        req.Name,
+		Expression:  req.Expression,
+		Description: req.Description,
+	}
+	if err := predicate.ValidateNamedPredicate(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	var existing int64
+	if err := h.db.WithContext(c.RequestCtx()).Model(&models.NamedPredicate{}).
+		Where("name = ?", req.Name).Count(&existing).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check predicate name", "database count failed", err)
+	}
+	if existing > 0 {
+		return utils.ReturnError(c, fiber.StatusConflict, "a predicate with this name already exists")
+	}
+
+	evaluator := rules.NewEvaluator(h.db.WithContext(c.RequestCtx()))
+	if err := evaluator.ValidatePredicate(req.Name, req.Expression); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Create(&predicate).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create predicate", "database insert failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(predicate)
+}
+
+// UpdatePredicateRequest represents the request body for updating a named predicate
+// tygo:export
+type UpdatePredicateRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Expression  *string `json:"expression,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// Update updates an existing named predicate
+func (h *PredicatesHandler) Update(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var predicate models.NamedPredicate
+	if err := h.db.WithContext(c.RequestCtx()).First(&predicate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "predicate not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch predicate", "database query failed", err)
+	}
+
+	var req UpdatePredicateRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Name != nil && *req.Name != predicate.Name {
+		// Renaming would break every expression that calls the old name
+		users, err := h.predicateUsers(c, predicate.Name)
+		if err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to check predicate references", "database query failed", err)
+		}
+		if len(users) > 0 {
+			return utils.ReturnError(c, fiber.StatusConflict,
+				fmt.Sprintf("cannot rename predicate referenced by: %v", users))
+		}
+		predicate.Name = *req.Name
+	}
+	if req.Expression != nil {
+		predicate.Expression = *req.Expression
+	}
+	if req.Description != nil {
+		predicate.Description = *req.Description
+	}
+
+	if err := predicate.ValidateNamedPredicate(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	evaluator := rules.NewEvaluator(h.db.WithContext(c.RequestCtx()))
+	if err := evaluator.ValidatePredicate(predicate.Name, predicate.Expression); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Save(&predicate).Error; err != nil {
+		if isDuplicateError(err) {
+			return utils.ReturnError(c, fiber.StatusConflict, "a predicate with this name already exists")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update predicate", "database update failed", err)
+	}
+
+	return c.JSON(predicate)
+}
+
+// Delete deletes a named predicate that is not referenced by any rule or predicate
+func (h *PredicatesHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var predicate models.NamedPredicate
+	if err := h.db.WithContext(c.RequestCtx()).First(&predicate, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "predicate not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch predicate", "database query failed", err)
+	}
+
+	users, err := h.predicateUsers(c, predicate.Name)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check predicate references", "database query failed", err)
+	}
+	if len(users) > 0 {
+		return utils.ReturnError(c, fiber.StatusConflict,
+			fmt.Sprintf("cannot delete predicate referenced by: %v", users))
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Delete(&predicate).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete predicate", "database delete failed", err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// predicateUsers returns descriptions of the sorting rules, rule groups, and other predicates
+// whose expressions call the named predicate directly
+func (h *PredicatesHandler) predicateUsers(c fiber.Ctx, name string) ([]string, error) {
+	users := []string{}
+
+	var sortingRules []models.SortingRule
+	if err := h.db.WithContext(c.RequestCtx()).Where("expression LIKE ?", "%predicate(%").Find(&sortingRules).Error; err != nil {
+		return nil, err
+	}
+	for _, rule := range sortingRules {
+		if slices.Contains(rules.ReferencedPredicates(rule.Expression), name) {
+			users = append(users, "rule "+rule.Name)
+		}
+	}
+
+	var groups []models.RuleGroup
+	if err := h.db.WithContext(c.RequestCtx()).Where("expressions LIKE ?", "%predicate(%").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		for _, expression := range group.Expressions {
+			if slices.Contains(rules.ReferencedPredicates(expression), name) {
+				users = append(users, "rule group "+group.Name)
+				break
+			}
+		}
+	}
+
+	var predicates []models.NamedPredicate
+	if err := h.db.WithContext(c.RequestCtx()).Where("name <> ? AND expression LIKE ?", name, "%predicate(%").Find(&predicates).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range predicates {
+		if slices.Contains(rules.ReferencedPredicates(p.Expression), name) {
+			users = append(users, "predicate "+p.Name)
+		}
+	}
+
+	return users, nil
+}
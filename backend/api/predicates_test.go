@@ -0,0 +1,220 @@
+package api
+
+import (
+	"backend/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupPredicatesTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.SortingRule{}, &models.RuleGroup{}, &models.NamedPredicate{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewPredicatesHandler(db)
+
+	app := fiber.New()
+	app.Get("/predicates", handler.List)
+	app.Get("/predicates/:id", handler.Get)
+	app.Post("/predicates", handler.Create)
+	app.Put("/predicates/:id", handler.Update)
+	app.Delete("/predicates/:id", handler.Delete)
+
+	return app, db
+}
+
+func sendPredicateRequest(t *testing.T, app *fiber.App, method, url string, body interface{}) (int, []byte) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		payload, _ := json.Marshal(body)
+		reader = bytes.NewReader(payload)
+	}
+	req := httptest.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, respBody
+}
+
+func TestPredicatesCreate(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+	db.Create(&models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"})
+
+	tests := []struct {
+		name     string
+		body     CreatePredicateRequest
+		expected int
+	}{
+		{
+			name:     "valid",
+			body:     CreatePredicateRequest{Name: "isEDHStaple", Expression: "edhrec_rank < 500"},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name:     "references existing predicate",
+			body:     CreatePredicateRequest{Name: "isBulkCommon", Expression: "predicate('isBulk') && rarity == 'common'"},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name:     "duplicate name",
+			body:     CreatePredicateRequest{Name: "isBulk", Expression: "true"},
+			expected: fiber.StatusConflict,
+		},
+		{
+			name:     "invalid name",
+			body:     CreatePredicateRequest{Name: "is bulk", Expression: "true"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "invalid expression",
+			body:     CreatePredicateRequest{Name: "broken", Expression: "rarity =="},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "unknown reference",
+			body:     CreatePredicateRequest{Name: "dangling", Expression: "predicate('nope')"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "missing expression",
+			body:     CreatePredicateRequest{Name: "empty"},
+			expected: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/predicates", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+}
+
+func TestPredicatesUpdate_RejectsCycle(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+
+	base := models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"}
+	db.Create(&base)
+	db.Create(&models.NamedPredicate{Name: "isBulkCommon", Expression: "predicate('isBulk') && rarity == 'common'"})
+
+	expression := "predicate('isBulkCommon')"
+	status, body := sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/predicates/%d", base.ID),
+		UpdatePredicateRequest{Expression: &expression})
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected status %d, got %d: %s", fiber.StatusBadRequest, status, body)
+	}
+
+	expression = "prices.usd < 0.5"
+	status, body = sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/predicates/%d", base.ID),
+		UpdatePredicateRequest{Expression: &expression})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+
+	var updated models.NamedPredicate
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if updated.Expression != expression {
+		t.Errorf("expected expression %q, got %q", expression, updated.Expression)
+	}
+}
+
+func TestPredicatesUpdate_RenameReferenced(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+
+	location := models.StorageLocation{Name: "Bulk Box", StorageType: models.Box}
+	db.Create(&location)
+	base := models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"}
+	db.Create(&base)
+	db.Create(&models.SortingRule{Name: "Bulk", Expression: "predicate('isBulk')", StorageLocationID: location.ID, Enabled: true})
+
+	name := "isCheap"
+	status, body := sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/predicates/%d", base.ID),
+		UpdatePredicateRequest{Name: &name})
+	if status != fiber.StatusConflict {
+		t.Errorf("expected status %d, got %d: %s", fiber.StatusConflict, status, body)
+	}
+}
+
+func TestPredicatesDelete(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+
+	used := models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"}
+	db.Create(&used)
+	db.Create(&models.NamedPredicate{Name: "isBulkCommon", Expression: "predicate('isBulk') && rarity == 'common'"})
+	unused := models.NamedPredicate{Name: "isRare", Expression: "rarity == 'rare'"}
+	db.Create(&unused)
+
+	status, _ := sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/predicates/%d", used.ID), nil)
+	if status != fiber.StatusConflict {
+		t.Errorf("expected status %d for referenced predicate, got %d", fiber.StatusConflict, status)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/predicates/%d", unused.ID), nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected status %d, got %d", fiber.StatusNoContent, status)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", "/predicates/999", nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+}
+
+func TestPredicatesDelete_UsedByRuleGroup(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+
+	used := models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"}
+	db.Create(&used)
+	db.Create(&models.RuleGroup{Name: "Bulk", Operator: models.RuleGroupAnd,
+		Expressions: []string{"rarity == 'common'", "predicate('isBulk')"}, Action: models.RuleGroupSkip, Enabled: true})
+
+	status, body := sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/predicates/%d", used.ID), nil)
+	if status != fiber.StatusConflict {
+		t.Errorf("expected status %d for a predicate used by a rule group, got %d: %s", fiber.StatusConflict, status, body)
+	}
+}
+
+func TestPredicatesList(t *testing.T) {
+	app, db := setupPredicatesTestApp(t)
+	db.Create(&models.NamedPredicate{Name: "zeta", Expression: "true"})
+	db.Create(&models.NamedPredicate{Name: "alpha", Expression: "true"})
+
+	status, body := sendPredicateRequest(t, app, "GET", "/predicates", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, status)
+	}
+
+	var result struct {
+		Data []models.NamedPredicate `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(result.Data) != 2 || result.Data[0].Name != "alpha" {
+		t.Errorf("expected predicates ordered by name starting with alpha, got %+v", result.Data)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// printFilterValuePattern restricts print filter values to Scryfall's lowercase identifiers
+var printFilterValuePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// printFilters holds the optional promo_type, frame_effect, and border_color query params
+// used to narrow search and inventory listings by print treatment
+type printFilters struct {
+	PromoType   string
+	FrameEffect string
+	BorderColor string
+}
+
+// parsePrintFilters reads and validates the print treatment query params
+func parsePrintFilters(c fiber.Ctx) (printFilters, error) {
+	filters := printFilters{
+		PromoType:   strings.ToLower(strings.TrimSpace(c.Query("promo_type"))),
+		FrameEffect: strings.ToLower(strings.TrimSpace(c.Query("frame_effect"))),
+		BorderColor: strings.ToLower(strings.TrimSpace(c.Query("border_color"))),
+	}
+
+	params := []struct{ name, value string }{
+		{"promo_type", filters.PromoType},
+		{"frame_effect", filters.FrameEffect},
+		{"border_color", filters.BorderColor},
+	}
+	for _, param := range params {
+		if param.value != "" && !printFilterValuePattern.MatchString(param.value) {
+			return printFilters{}, fmt.Errorf("invalid %s", param.name)
+		}
+	}
+
+	return filters, nil
+}
+
+// applyToInventory narrows an inventory query to items whose card matches the filters.
+// promo_types and frame_effects are arrays, which no single-column index can answer,
+// and a side table of them would have to be rewritten on every bulk data upsert. So
+// instead of scanning every card's JSON, those filters look up each candidate item's
+// own card by primary key; the cost grows with the collection, not the card database.
+func (f printFilters) applyToInventory(query *gorm.DB) *gorm.DB {
+	if f.PromoType != "" {
+		query = query.Where(`EXISTS (SELECT 1 FROM cards, json_each(cards.raw_json, '$.promo_types')
+			WHERE cards.scryfall_id = inventories.scryfall_id AND json_each.value = ?)`, f.PromoType)
+	}
+	if f.FrameEffect != "" {
+		query = query.Where(`EXISTS (SELECT 1 FROM cards, json_each(cards.raw_json, '$.frame_effects')
+			WHERE cards.scryfall_id = inventories.scryfall_id AND json_each.value = ?)`, f.FrameEffect)
+	}
+	if f.BorderColor != "" {
+		// Matches the idx_cards_border_color expression index
+		query = query.Where(`scryfall_id IN (SELECT scryfall_id FROM cards
+			WHERE json_extract(raw_json, '$.border_color') = ?)`, f.BorderColor)
+	}
+	return query
+}
+
+// scryfallQuery returns the filters as Scryfall search syntax, e.g. "is:serialized border:borderless"
+func (f printFilters) scryfallQuery() string {
+	var terms []string
+	if f.PromoType != "" {
+		terms = append(terms, "is:"+f.PromoType)
+	}
+	if f.FrameEffect != "" {
+		terms = append(terms, "frame:"+f.FrameEffect)
+	}
+	if f.BorderColor != "" {
+		terms = append(terms, "border:"+f.BorderColor)
+	}
+	return strings.Join(terms, " ")
+}
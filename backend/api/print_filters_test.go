@@ -0,0 +1,130 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func TestParsePrintFilters(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expected      printFilters
+		expectedQuery string
+		expectError   bool
+	}{
+		{name: "No filters", url: "/", expected: printFilters{}, expectedQuery: ""},
+		{
+			name:          "All filters",
+			url:           "/?promo_type=serialized&frame_effect=extendedart&border_color=Borderless",
+			expected:      printFilters{PromoType: "serialized", FrameEffect: "extendedart", BorderColor: "borderless"},
+			expectedQuery: "is:serialized frame:extendedart border:borderless",
+		},
+		{name: "Invalid value", url: "/?border_color=black%20OR%20is:foil", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			var got printFilters
+			var parseErr error
+			app.Get("/", func(c fiber.Ctx) error {
+				got, parseErr = parsePrintFilters(c)
+				return nil
+			})
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if tt.expectError {
+				if parseErr == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if parseErr != nil {
+				t.Fatalf("expected no error, got %v", parseErr)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+			if got.scryfallQuery() != tt.expectedQuery {
+				t.Errorf("expected query %q, got %q", tt.expectedQuery, got.scryfallQuery())
+			}
+		})
+	}
+}
+
+func TestInventoryList_FilterByPrintTreatment(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	if err := db.AutoMigrate(&models.Card{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	db.Create(&models.Card{ScryfallID: "card-1", RawJSON: `{"border_color":"borderless","promo_types":["serialized"],"frame_effects":[]}`})
+	db.Create(&models.Card{ScryfallID: "card-2", RawJSON: `{"border_color":"black","promo_types":[],"frame_effects":["extendedart"]}`})
+
+	createTestInventoryItem(t, db, "card-1", 1, nil)
+	createTestInventoryItem(t, db, "card-2", 1, nil)
+
+	tests := []struct {
+		query    string
+		expected int64
+	}{
+		{query: "border_color=borderless", expected: 1},
+		{query: "promo_type=serialized", expected: 1},
+		{query: "frame_effect=extendedart", expected: 1},
+		{query: "frame_effect=showcase", expected: 0},
+		{query: "border_color=black&frame_effect=extendedart", expected: 1},
+	}
+
+	for _, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory?"+tt.query, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+
+		var result utils.PaginatedResponse[json.RawMessage]
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		resp.Body.Close()
+
+		if result.TotalItems != tt.expected {
+			t.Errorf("%s: expected %d items, got %d", tt.query, tt.expected, result.TotalItems)
+		}
+	}
+}
+
+func TestPrintFilters_LookUpCardsByPrimaryKey(t *testing.T) {
+	_, db := setupInventoryTestApp(t)
+	if err := db.AutoMigrate(&models.Card{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	filters := printFilters{PromoType: "serialized", FrameEffect: "extendedart"}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return filters.applyToInventory(tx.Model(&models.Inventory{})).Find(&[]models.Inventory{})
+	})
+
+	var plan []struct{ Detail string }
+	if err := db.Raw("EXPLAIN QUERY PLAN " + sql).Scan(&plan).Error; err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	for _, step := range plan {
+		if strings.HasPrefix(step.Detail, "SCAN cards") {
+			t.Errorf("expected cards to be looked up by primary key, got %q", step.Detail)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"backend/models"
+	"backend/rules"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// RuleGroupsHandler handles rule group endpoints
+type RuleGroupsHandler struct {
+	db *gorm.DB
+}
+
+// NewRuleGroupsHandler creates a new rule groups handler
+func NewRuleGroupsHandler(db *gorm.DB) *RuleGroupsHandler {
+	return &RuleGroupsHandler{db: db}
+}
+
+// List returns rule groups with pagination, ordered by priority
+func (h *RuleGroupsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.RuleGroup{})
+	if enabled := c.Query("enabled"); enabled != "" {
+		if enabled != "true" && enabled != "false" {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "enabled must be 'true' or 'false'")
+		}
+		query = query.Where("enabled = ?", enabled == "true")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count rule groups", "database count failed", err)
+	}
+
+	var groups []models.RuleGroup
+	if err := query.Order("priority ASC, id ASC").
+		Preload("StorageLocation").
+		Offset(utils.CalculateOffset(params.Page, params.PageSize)).
+		Limit(params.PageSize).
+		Find(&groups).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch rule groups", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, groups, params.Page, params.PageSize, total)
+}
+
+// Get returns a single rule group by ID
+func (h *RuleGroupsHandler) Get(c fiber.Ctx) error {
+	group, err := h.findGroup(c)
+	if err != nil || group == nil {
+		return err
+	}
+	return c.JSON(group)
+}
+
+// CreateRuleGroupRequest represents the request body for creating a rule group
+// tygo:export
+type CreateRuleGroupRequest struct {
+	Name              string   `json:"name"`
+	Priority          int      `json:"priority"`
+	Operator          string   `json:"operator,omitempty"` // and (default) or or
+	Expressions       []string `json:"expressions"`
+	Action            string   `json:"action"`                        // assign, tag, or skip
+	StorageLocationID *uint    `json:"storage_location_id,omitempty"` // Required to assign; 0 leaves matching cards unassigned
+	Tag               string   `json:"tag,omitempty"`                 // Required to tag
+	Enabled           *bool    `json:"enabled,omitempty"`
+}
+
+// Create creates a new rule group
+func (h *RuleGroupsHandler) Create(c fiber.Ctx) error {
+	var req CreateRuleGroupRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	group := models.RuleGroup{
+		Name:              strings.TrimSpace(req.Name),
+		Priority:          req.Priority,
+		Operator:          models.RuleGroupOperator(req.Operator),
+		Expressions:       req.Expressions,
+		Action:            models.RuleGroupAction(req.Action),
+		StorageLocationID: req.StorageLocationID,
+		Tag:               strings.TrimSpace(req.Tag),
+		Enabled:           true,
+	}
+	if group.Operator == "" {
+		group.Operator = models.RuleGroupAnd
+	}
+	if req.Enabled != nil {
+		group.Enabled = *req.Enabled
+	}
+	if ok, err := h.checkGroup(c, &group); !ok {
+		return err
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).Create(&group).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create rule group", "database insert failed", err)
+	}
+
+	return h.sendGroup(c, fiber.StatusCreated, group.ID)
+}
+
+// UpdateRuleGroupRequest represents the request body for updating a rule group
+// tygo:export
+type UpdateRuleGroupRequest struct {
+	Name              *string   `json:"name,omitempty"`
+	Priority          *int      `json:"priority,omitempty"`
+	Operator          *string   `json:"operator,omitempty"`
+	Expressions       *[]string `json:"expressions,omitempty"`
+	Action            *string   `json:"action,omitempty"`
+	StorageLocationID *uint     `json:"storage_location_id,omitempty"`
+	Tag               *string   `json:"tag,omitempty"`
+	Enabled           *bool     `json:"enabled,omitempty"`
+}
+
+// Update updates an existing rule group (partial updates supported)
+func (h *RuleGroupsHandler) Update(c fiber.Ctx) error {
+	group, err := h.findGroup(c)
+	if err != nil || group == nil {
+		return err
+	}
+
+	var req UpdateRuleGroupRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	if req.Name != nil {
+		group.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Priority != nil {
+		group.Priority = *req.Priority
+	}
+	if req.Operator != nil {
+		group.Operator = models.RuleGroupOperator(*req.Operator)
+	}
+	if req.Expressions != nil {
+		group.Expressions = *req.Expressions
+	}
+	if req.Action != nil {
+		group.Action = models.RuleGroupAction(*req.Action)
+	}
+	if req.StorageLocationID != nil {
+		group.StorageLocationID = req.StorageLocationID
+	}
+	if req.Tag != nil {
+		group.Tag = strings.TrimSpace(*req.Tag)
+	}
+	if req.Enabled != nil {
+		group.Enabled = *req.Enabled
+	}
+	if ok, err := h.checkGroup(c, group); !ok {
+		return err
+	}
+
+	// Drop the preloaded location so Save doesn't write it back
+	group.StorageLocation = nil
+	if err := h.db.WithContext(c.RequestCtx()).Save(group).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update rule group", "database update failed", err)
+	}
+
+	return h.sendGroup(c, fiber.StatusOK, group.ID)
+}
+
+// Delete deletes a rule group
+func (h *RuleGroupsHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	result := h.db.WithContext(c.RequestCtx()).Delete(&models.RuleGroup{}, id)
+	if result.Error != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete rule group", "database delete failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "rule group not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findGroup loads the group named by the id path param. When it returns a nil group
+// the error response has already been written and err should be returned.
+func (h *RuleGroupsHandler) findGroup(c fiber.Ctx) (*models.RuleGroup, error) {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var group models.RuleGroup
+	if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "rule group not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch rule group", "database query failed", err)
+	}
+	return &group, nil
+}
+
+// checkGroup validates a group about to be saved: its shape, every expression, and
+// for assign groups the target location. Fields the action doesn't use are cleared.
+// When it returns false the error response has already been written.
+func (h *RuleGroupsHandler) checkGroup(c fiber.Ctx, group *models.RuleGroup) (bool, error) {
+	if group.Action != models.RuleGroupAssign {
+		group.StorageLocationID = nil
+	}
+	if group.Action != models.RuleGroupTag {
+		group.Tag = ""
+	}
+	if err := utils.CombineErrors([]error{
+		utils.ValidateMaxLength(group.Name, 255, "name"),
+		utils.ValidateMaxLength(group.Tag, 100, "tag"),
+		group.ValidateRuleGroup(h.db),
+	}); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	evaluator := rules.NewEvaluator(h.db.WithContext(c.RequestCtx()))
+	for i, expression := range group.Expressions {
+		if err := evaluator.ValidateExpression(expression); err != nil {
+			return false, utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("expression %d: %v", i+1, err))
+		}
+	}
+
+	if group.Action == models.RuleGroupAssign {
+		return checkRuleTarget(c, h.db, *group.StorageLocationID)
+	}
+	return true, nil
+}
+
+// sendGroup responds with the saved group, reloaded with its storage location
+func (h *RuleGroupsHandler) sendGroup(c fiber.Ctx, status int, id uint) error {
+	var group models.RuleGroup
+	if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").First(&group, id).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to reload rule group", "database query failed", err)
+	}
+	return c.Status(status).JSON(group)
+}
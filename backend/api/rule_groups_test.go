@@ -0,0 +1,244 @@
+package api
+
+import (
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRuleGroupsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.RuleGroup{}, &models.NamedPredicate{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewRuleGroupsHandler(db)
+
+	app := fiber.New()
+	app.Get("/rule-groups", handler.List)
+	app.Get("/rule-groups/:id", handler.Get)
+	app.Post("/rule-groups", handler.Create)
+	app.Put("/rule-groups/:id", handler.Update)
+	app.Delete("/rule-groups/:id", handler.Delete)
+
+	return app, db
+}
+
+func TestRuleGroupsCreate(t *testing.T) {
+	app, db := setupRuleGroupsTestApp(t)
+	location := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(&location)
+	missing := uint(999)
+	unassigned := models.UnassignedLocationID
+
+	tests := []struct {
+		name     string
+		body     CreateRuleGroupRequest
+		expected int
+	}{
+		{
+			name: "assign",
+			body: CreateRuleGroupRequest{Name: "Old rares", Expressions: []string{"rarity == 'rare'", "set == 'lea'"},
+				Action: "assign", StorageLocationID: &location.ID},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name: "assign unassigned",
+			body: CreateRuleGroupRequest{Name: "Leave", Expressions: []string{"true"},
+				Action: "assign", StorageLocationID: &unassigned},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name: "tag",
+			body: CreateRuleGroupRequest{Name: "Bulk", Operator: "or", Expressions: []string{"prices.usd < 1", "rarity == 'common'"},
+				Action: "tag", Tag: "bulk"},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name:     "skip",
+			body:     CreateRuleGroupRequest{Name: "Tokens", Expressions: []string{"layout == 'token'"}, Action: "skip"},
+			expected: fiber.StatusCreated,
+		},
+		{
+			name: "missing location",
+			body: CreateRuleGroupRequest{Name: "Lost", Expressions: []string{"true"},
+				Action: "assign", StorageLocationID: &missing},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "assign without location",
+			body:     CreateRuleGroupRequest{Name: "Nowhere", Expressions: []string{"true"}, Action: "assign"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "tag without tag",
+			body:     CreateRuleGroupRequest{Name: "Untagged", Expressions: []string{"true"}, Action: "tag"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "unknown operator",
+			body:     CreateRuleGroupRequest{Name: "Xor", Operator: "xor", Expressions: []string{"true"}, Action: "skip"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "no expressions",
+			body:     CreateRuleGroupRequest{Name: "Empty", Action: "skip"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "invalid expression",
+			body:     CreateRuleGroupRequest{Name: "Broken", Expressions: []string{"true", "rarity =="}, Action: "skip"},
+			expected: fiber.StatusBadRequest,
+		},
+		{
+			name:     "unknown predicate",
+			body:     CreateRuleGroupRequest{Name: "Dangling", Expressions: []string{"predicate('nope')"}, Action: "skip"},
+			expected: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/rule-groups", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+}
+
+func TestRuleGroupsCreate_Defaults(t *testing.T) {
+	app, _ := setupRuleGroupsTestApp(t)
+
+	status, body := sendPredicateRequest(t, app, "POST", "/rule-groups",
+		CreateRuleGroupRequest{Name: " Tokens ", Expressions: []string{"layout == 'token'"}, Action: "skip", Tag: "ignored"})
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusCreated, status, body)
+	}
+
+	var group models.RuleGroup
+	if err := json.Unmarshal(body, &group); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if group.Name != "Tokens" || group.Operator != models.RuleGroupAnd || !group.Enabled || group.Tag != "" {
+		t.Errorf("expected a trimmed, enabled AND group without a tag, got %+v", group)
+	}
+}
+
+func TestRuleGroupsUpdate(t *testing.T) {
+	app, db := setupRuleGroupsTestApp(t)
+	location := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(&location)
+	group := models.RuleGroup{Name: "Bulk", Operator: models.RuleGroupOr, Expressions: []string{"true"},
+		Action: models.RuleGroupTag, Tag: "bulk", Enabled: true}
+	db.Create(&group)
+
+	// Switching to assign drops the tag and needs a location
+	action := "assign"
+	status, body := sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/rule-groups/%d", group.ID),
+		UpdateRuleGroupRequest{Action: &action})
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected status %d, got %d: %s", fiber.StatusBadRequest, status, body)
+	}
+
+	expressions := []string{"rarity == 'common'", "prices.usd < 1"}
+	status, body = sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/rule-groups/%d", group.ID),
+		UpdateRuleGroupRequest{Action: &action, StorageLocationID: &location.ID, Expressions: &expressions})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+
+	var updated models.RuleGroup
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if updated.Action != models.RuleGroupAssign || updated.Tag != "" || !slices.Equal(updated.Expressions, expressions) {
+		t.Errorf("expected an assign group with the new expressions, got %+v", updated)
+	}
+	if updated.StorageLocation == nil || updated.StorageLocation.ID != location.ID {
+		t.Errorf("expected the group's storage location, got %+v", updated.StorageLocation)
+	}
+
+	// Switching away from assign clears the location
+	action = "skip"
+	status, body = sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/rule-groups/%d", group.ID),
+		UpdateRuleGroupRequest{Action: &action})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+	var stored models.RuleGroup
+	db.First(&stored, group.ID)
+	if stored.StorageLocationID != nil {
+		t.Errorf("expected the location to be cleared, got %d", *stored.StorageLocationID)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "PUT", "/rule-groups/999", UpdateRuleGroupRequest{Action: &action})
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+}
+
+func TestRuleGroupsListAndDelete(t *testing.T) {
+	app, db := setupRuleGroupsTestApp(t)
+	late := models.RuleGroup{Name: "Late", Priority: 5, Operator: models.RuleGroupAnd, Expressions: []string{"true"},
+		Action: models.RuleGroupSkip, Enabled: true}
+	db.Create(&late)
+	early := models.RuleGroup{Name: "Early", Priority: 1, Operator: models.RuleGroupAnd, Expressions: []string{"true"},
+		Action: models.RuleGroupSkip, Enabled: true}
+	db.Create(&early)
+	db.Model(&early).Update("enabled", false)
+
+	listNames := func(query string) []string {
+		t.Helper()
+		status, body := sendPredicateRequest(t, app, "GET", "/rule-groups"+query, nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("expected status %d, got %d", fiber.StatusOK, status)
+		}
+		var result struct {
+			Data []models.RuleGroup `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		var names []string
+		for _, group := range result.Data {
+			names = append(names, group.Name)
+		}
+		return names
+	}
+
+	if names := listNames(""); !slices.Equal(names, []string{"Early", "Late"}) {
+		t.Errorf("expected groups in priority order, got %v", names)
+	}
+	if names := listNames("?enabled=true"); !slices.Equal(names, []string{"Late"}) {
+		t.Errorf("expected only the enabled group, got %v", names)
+	}
+	if status, _ := sendPredicateRequest(t, app, "GET", "/rule-groups?enabled=yes", nil); status != fiber.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", fiber.StatusBadRequest, status)
+	}
+
+	status, _ := sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/rule-groups/%d", late.ID), nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected status %d, got %d", fiber.StatusNoContent, status)
+	}
+	status, _ = sendPredicateRequest(t, app, "GET", fmt.Sprintf("/rule-groups/%d", late.ID), nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+	status, _ = sendPredicateRequest(t, app, "DELETE", "/rule-groups/999", nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+}
@@ -1,10 +1,13 @@
 package api
 
 import (
-	"backend/models"
 	"backend/services"
+	"backend/utils"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -13,126 +16,136 @@ import (
 // ScheduledTaskInfo represents information about a scheduled task
 // tygo:export
 type ScheduledTaskInfo struct {
-	Name          string     `json:"name"`
-	Type          string     `json:"type"` // "bulk_data_update" | "job_cleanup"
-	Enabled       bool       `json:"enabled"`
-	Schedule      string     `json:"schedule"` // e.g., "03:00 daily"
-	NextRun       time.Time  `json:"next_run"`
-	LastRun       *time.Time `json:"last_run,omitempty"`
-	LastJobID     *uint      `json:"last_job_id,omitempty"`
-	LastJobStatus *string    `json:"last_job_status,omitempty"`
+	Name            string     `json:"name"` // Display name
+	Type            string     `json:"type"` // Task name, e.g. "bulk_data_update"; used to run it
+	Enabled         bool       `json:"enabled"`
+	Schedule        string     `json:"schedule"` // e.g., "03:00 daily", "every 6h"
+	IntervalSeconds int64      `json:"interval_seconds"`
+	TimeOfDay       string     `json:"time_of_day,omitempty"` // "HH:MM" in the scheduler time zone; empty runs whenever the interval has elapsed
+	Cron            string     `json:"cron,omitempty"`        // Cron expression replacing the interval and time of day, if set
+	NextRun         time.Time  `json:"next_run"`              // In the scheduler time zone
+	Timezone        string     `json:"timezone"`              // IANA name of the zone the schedule is read in
+	Running         bool       `json:"running"`
+	LastRun         *time.Time `json:"last_run,omitempty"`
+	LastJobID       *uint      `json:"last_job_id,omitempty"`
+	LastJobStatus   *string    `json:"last_job_status,omitempty"`
 }
 
 // SchedulerHandler handles scheduler-related API requests
 type SchedulerHandler struct {
-	settingsService *services.SettingsService
-	jobService      *services.JobService
+	scheduler  *services.Scheduler
+	jobService *services.JobService
 }
 
 // NewSchedulerHandler creates a new scheduler handler
-func NewSchedulerHandler(settingsService *services.SettingsService, jobService *services.JobService) *SchedulerHandler {
+func NewSchedulerHandler(scheduler *services.Scheduler, jobService *services.JobService) *SchedulerHandler {
 	return &SchedulerHandler{
-		settingsService: settingsService,
-		jobService:      jobService,
+		scheduler:  scheduler,
+		jobService: jobService,
 	}
 }
 
-// GetScheduledTasks returns information about all scheduled tasks
+// GetScheduledTasks returns information about all scheduled tasks, in the order they
+// were registered
 func (h *SchedulerHandler) GetScheduledTasks(c fiber.Ctx) error {
 	tasks := []ScheduledTaskInfo{}
-
-	// Bulk Data Update Task
-	bulkDataTask := h.getBulkDataTaskInfo(c.RequestCtx())
-	tasks = append(tasks, bulkDataTask)
-
-	// Job Cleanup Task
-	cleanupTask := h.getJobCleanupTaskInfo()
-	tasks = append(tasks, cleanupTask)
-
+	for _, status := range h.scheduler.Tasks(c.RequestCtx()) {
+		tasks = append(tasks, h.taskInfo(c.RequestCtx(), status))
+	}
 	return c.JSON(tasks)
 }
 
-// getBulkDataTaskInfo returns info about the bulk data update task
-func (h *SchedulerHandler) getBulkDataTaskInfo(ctx context.Context) ScheduledTaskInfo {
-	// Check if auto-update is enabled
-	enabled := h.settingsService.GetBool(ctx, "bulk_data_auto_update", false)
-
-	// Get configured update time
-	updateTime, err := h.settingsService.Get(ctx, "bulk_data_update_time")
-	if err != nil || updateTime == "" {
-		updateTime = "03:00" // Default to 3 AM
+// RunTask starts a task now, whether or not it is enabled or due. The task runs under
+// appCtx, since it outlives the request.
+func (h *SchedulerHandler) RunTask(c fiber.Ctx, appCtx context.Context) error {
+	name := c.Params("name")
+	if err := h.scheduler.RunTask(appCtx, name); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTaskNotFound):
+			return utils.ReturnError(c, fiber.StatusNotFound, "scheduled task not found")
+		case errors.Is(err, services.ErrTaskRunning):
+			return utils.ReturnError(c, fiber.StatusConflict, "Task is already running")
+		case errors.Is(err, services.ErrWorkersStopped):
+			return utils.ReturnError(c, fiber.StatusServiceUnavailable, "Server is shutting down")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to run task", "task run failed", err)
 	}
 
-	// Calculate next run time
-	nextRun := calculateNextRun(updateTime)
-
-	// Get last job
-	lastJob, err := h.jobService.GetLastJobByType(ctx, models.JobTypeBulkDataImport)
-	if err != nil {
-		slog.Warn("failed to get last bulk data job", "component", "scheduler", "error", err)
-	}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Task started",
+		"task":    name,
+	})
+}
 
+// taskInfo describes a task, with its latest job when it starts jobs
+func (h *SchedulerHandler) taskInfo(ctx context.Context, status services.TaskStatus) ScheduledTaskInfo {
 	task := ScheduledTaskInfo{
-		Name:     "Bulk Data Auto-Update",
-		Type:     "bulk_data_update",
-		Enabled:  enabled,
-		Schedule: updateTime + " daily",
-		NextRun:  nextRun,
+		Name:            status.Task.Label,
+		Type:            status.Task.Name,
+		Enabled:         status.Enabled,
+		Schedule:        describeSchedule(status.Task.Interval, status.TimeOfDay, status.Cron),
+		IntervalSeconds: int64(status.Task.Interval.Seconds()),
+		TimeOfDay:       status.TimeOfDay,
+		Cron:            status.Cron,
+		NextRun:         status.NextRun,
+		Timezone:        status.Location.String(),
+		Running:         status.Running,
+		LastRun:         status.LastRun,
+	}
+	if status.Task.JobType == "" {
+		return task
 	}
 
-	// Add last job info if it exists
+	lastJob, err := h.jobService.GetLastJobByType(ctx, status.Task.JobType)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get last task job", "component", "scheduler", "task", status.Task.Name, "error", err)
+	}
 	if lastJob != nil {
 		task.LastJobID = &lastJob.ID
 
-		if lastJob.CompletedAt != nil {
-			task.LastRun = lastJob.CompletedAt
-		} else if lastJob.StartedAt != nil {
-			task.LastRun = lastJob.StartedAt
+		// Jobs started by hand count as runs too
+		if task.LastRun == nil {
+			if lastJob.CompletedAt != nil {
+				task.LastRun = lastJob.CompletedAt
+			} else if lastJob.StartedAt != nil {
+				task.LastRun = lastJob.StartedAt
+			}
 		}
 
 		statusStr := string(lastJob.Status)
 		task.LastJobStatus = &statusStr
 	}
-
 	return task
 }
 
-// getJobCleanupTaskInfo returns info about the job cleanup task
-func (h *SchedulerHandler) getJobCleanupTaskInfo() ScheduledTaskInfo {
-	// Job cleanup is always enabled and runs at midnight
-	cleanupTime := "00:00"
-	nextRun := calculateNextRun(cleanupTime)
-
-	task := ScheduledTaskInfo{
-		Name:     "Job History Cleanup",
-		Type:     "job_cleanup",
-		Enabled:  true,
-		Schedule: cleanupTime + " daily",
-		NextRun:  nextRun,
+// describeSchedule renders a task's interval and time of day, e.g. "03:00 daily",
+// "03:00 every 14 days" or "every 6h", or its cron expression, e.g. "cron 0 3 * * 0"
+func describeSchedule(interval time.Duration, timeOfDay, cron string) string {
+	if cron != "" {
+		return "cron " + cron
 	}
 
-	// Note: Job cleanup doesn't create a Job record, so no last job info
-
-	return task
-}
-
-// calculateNextRun calculates the next run time for a given schedule (HH:MM format)
-func calculateNextRun(schedule string) time.Time {
-	// Parse schedule (format: "HH:MM")
-	targetTime, err := time.Parse("15:04", schedule)
-	if err != nil {
-		// Fallback to midnight if parsing fails
-		targetTime, _ = time.Parse("15:04", "00:00")
+	var every string
+	switch days := interval / (24 * time.Hour); {
+	case interval%(24*time.Hour) != 0:
+		// Drop the zero units Duration.String pads with, e.g. "6h0m0s" to "6h"
+		text := interval.String()
+		if strings.HasSuffix(text, "m0s") {
+			text = strings.TrimSuffix(text, "0s")
+		}
+		if strings.HasSuffix(text, "h0m") {
+			text = strings.TrimSuffix(text, "0m")
+		}
+		every = "every " + text
+	case days == 1:
+		every = "daily"
+	default:
+		every = fmt.Sprintf("every %d days", days)
 	}
 
-	now := time.Now()
-	next := time.Date(now.Year(), now.Month(), now.Day(),
-		targetTime.Hour(), targetTime.Minute(), 0, 0, now.Location())
-
-	// If time already passed today, schedule for tomorrow
-	if next.Before(now) || next.Equal(now) {
-		next = next.AddDate(0, 0, 1)
+	if timeOfDay == "" {
+		return every
 	}
-
-	return next
+	return timeOfDay + " " + every
 }
@@ -8,13 +8,14 @@ import (
 	"io"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-func setupSchedulerTestApp(t *testing.T) (*fiber.App, *services.SettingsService, *services.JobService, *gorm.DB) {
+func setupSchedulerTestApp(t *testing.T, tasks ...services.ScheduledTask) (*fiber.App, *services.SettingsService, *services.JobService, *gorm.DB) {
 	t.Helper()
 
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
@@ -29,10 +30,17 @@ func setupSchedulerTestApp(t *testing.T) (*fiber.App, *services.SettingsService,
 	settingsService := services.NewSettingsService(db)
 	jobService := services.NewJobService(db)
 
-	handler := NewSchedulerHandler(settingsService, jobService)
+	scheduler := services.NewScheduler(nil, nil, jobService, settingsService)
+	for _, task := range tasks {
+		scheduler.AddTask(task)
+	}
+	handler := NewSchedulerHandler(scheduler, jobService)
 
 	app := fiber.New()
 	app.Get("/scheduler/tasks", handler.GetScheduledTasks)
+	app.Post("/scheduler/tasks/:name/run", func(c fiber.Ctx) error {
+		return handler.RunTask(c, context.Background())
+	})
 
 	return app, settingsService, jobService, db
 }
@@ -58,8 +66,8 @@ func TestScheduler_GetScheduledTasks_Success(t *testing.T) {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
 
-	if len(tasks) != 2 {
-		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(tasks))
 	}
 
 	// Verify bulk data update task
@@ -80,8 +88,22 @@ func TestScheduler_GetScheduledTasks_Success(t *testing.T) {
 		t.Errorf("expected schedule '03:00 daily', got '%s'", bulkDataTask.Schedule)
 	}
 
+	if bulkDataTask.IntervalSeconds != 24*60*60 || bulkDataTask.TimeOfDay != "03:00" {
+		t.Errorf("expected a daily 03:00 interval, got %d seconds at '%s'", bulkDataTask.IntervalSeconds, bulkDataTask.TimeOfDay)
+	}
+
+	// Verify set data update task
+	setDataTask := tasks[1]
+	if setDataTask.Type != "set_data_update" {
+		t.Errorf("expected task type 'set_data_update', got '%s'", setDataTask.Type)
+	}
+
+	if setDataTask.Schedule != "02:30 every 14 days" {
+		t.Errorf("expected schedule '02:30 every 14 days', got '%s'", setDataTask.Schedule)
+	}
+
 	// Verify job cleanup task
-	cleanupTask := tasks[1]
+	cleanupTask := tasks[2]
 	if cleanupTask.Name != "Job History Cleanup" {
 		t.Errorf("expected task name 'Job History Cleanup', got '%s'", cleanupTask.Name)
 	}
@@ -209,3 +231,185 @@ func TestScheduler_GetScheduledTasks_NextRunCalculation(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduler_GetScheduledTasks_Timezone(t *testing.T) {
+	app, settingsService, _, _ := setupSchedulerTestApp(t)
+	settingsService.Set(context.Background(), "scheduler_timezone", "Asia/Tokyo")
+
+	req := httptest.NewRequest("GET", "/scheduler/tasks", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var tasks []ScheduledTaskInfo
+	json.Unmarshal(body, &tasks)
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	for _, task := range tasks {
+		if task.Timezone != "Asia/Tokyo" {
+			t.Errorf("task '%s': expected timezone Asia/Tokyo, got '%s'", task.Name, task.Timezone)
+		}
+		if _, offset := task.NextRun.Zone(); offset != 9*60*60 {
+			t.Errorf("task '%s': expected next_run in Tokyo time, got %v", task.Name, task.NextRun)
+		}
+	}
+
+	// Bulk data runs at 03:00 Tokyo time
+	if local := tasks[0].NextRun.In(tokyo); local.Hour() != 3 || local.Minute() != 0 {
+		t.Errorf("expected next_run at 03:00 Tokyo time, got %v", local)
+	}
+}
+
+func TestScheduler_GetScheduledTasks_AddedTask(t *testing.T) {
+	app, _, _, _ := setupSchedulerTestApp(t, services.ScheduledTask{
+		Name:     "test_task",
+		Label:    "Test Task",
+		Interval: 6 * time.Hour,
+		Run:      func(ctx context.Context) error { return nil },
+	})
+
+	req := httptest.NewRequest("GET", "/scheduler/tasks", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	var tasks []ScheduledTaskInfo
+	json.Unmarshal(body, &tasks)
+
+	if len(tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %d", len(tasks))
+	}
+	task := tasks[3]
+	if task.Name != "Test Task" || task.Type != "test_task" || task.Schedule != "every 6h" || !task.Enabled {
+		t.Errorf("unexpected task %+v", task)
+	}
+	if task.TimeOfDay != "" || task.IntervalSeconds != 6*60*60 {
+		t.Errorf("expected a 6 hour interval without a time of day, got %+v", task)
+	}
+	// Never run, so due at the next check
+	if time.Until(task.NextRun) > time.Minute {
+		t.Errorf("expected a never-run task to be due now, got %v", task.NextRun)
+	}
+}
+
+func TestScheduler_RunTask(t *testing.T) {
+	ran := make(chan struct{})
+	release := make(chan struct{})
+	app, _, _, _ := setupSchedulerTestApp(t, services.ScheduledTask{
+		Name:     "test_task",
+		Interval: 24 * time.Hour,
+		Run: func(ctx context.Context) error {
+			close(ran)
+			<-release
+			return nil
+		},
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/scheduler/tasks/test_task/run", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", fiber.StatusAccepted, resp.StatusCode)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the task to run")
+	}
+
+	// Still running, so a second trigger conflicts
+	resp, err = app.Test(httptest.NewRequest("POST", "/scheduler/tasks/test_task/run", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("expected status %d, got %d", fiber.StatusConflict, resp.StatusCode)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/scheduler/tasks", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	var tasks []ScheduledTaskInfo
+	json.NewDecoder(resp.Body).Decode(&tasks)
+	close(release)
+
+	task := tasks[len(tasks)-1]
+	if !task.Running || task.LastRun == nil {
+		t.Errorf("expected the task to be running with a last run, got %+v", task)
+	}
+	if until := time.Until(task.NextRun); until < 23*time.Hour {
+		t.Errorf("expected the next run a day after the manual one, got %v", task.NextRun)
+	}
+}
+
+func TestScheduler_RunTask_NotFound(t *testing.T) {
+	app, _, _, _ := setupSchedulerTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/scheduler/tasks/missing/run", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestDescribeSchedule(t *testing.T) {
+	tests := []struct {
+		interval  time.Duration
+		timeOfDay string
+		cron      string
+		expected  string
+	}{
+		{24 * time.Hour, "03:00", "", "03:00 daily"},
+		{14 * 24 * time.Hour, "03:00", "", "03:00 every 14 days"},
+		{6 * time.Hour, "", "", "every 6h"},
+		{90 * time.Minute, "", "", "every 1h30m"},
+		{24 * time.Hour, "", "", "daily"},
+		{24 * time.Hour, "03:00", "0 3 * * 0", "cron 0 3 * * 0"},
+	}
+	for _, tt := range tests {
+		if got := describeSchedule(tt.interval, tt.timeOfDay, tt.cron); got != tt.expected {
+			t.Errorf("describeSchedule(%v, %q, %q): expected %q, got %q", tt.interval, tt.timeOfDay, tt.cron, tt.expected, got)
+		}
+	}
+}
+
+func TestScheduler_GetScheduledTasks_Cron(t *testing.T) {
+	app, settingsService, _, _ := setupSchedulerTestApp(t)
+	ctx := context.Background()
+	settingsService.Set(ctx, "scheduler_timezone", "UTC")
+	settingsService.Set(ctx, "bulk_data_update_cron", "0 3 * * 0")
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/scheduler/tasks", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+	var tasks []ScheduledTaskInfo
+	if err := json.NewDecoder(resp.Body).Decode(&tasks); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	bulkDataTask := tasks[0]
+	if bulkDataTask.Cron != "0 3 * * 0" {
+		t.Errorf("expected cron '0 3 * * 0', got %q", bulkDataTask.Cron)
+	}
+	if bulkDataTask.Schedule != "cron 0 3 * * 0" {
+		t.Errorf("expected schedule 'cron 0 3 * * 0', got %q", bulkDataTask.Schedule)
+	}
+	next := bulkDataTask.NextRun.UTC()
+	if next.Weekday() != time.Sunday || next.Hour() != 3 || next.Minute() != 0 {
+		t.Errorf("expected next run on a Sunday at 03:00, got %v", next)
+	}
+
+	if tasks[1].Cron != "" {
+		t.Errorf("expected set data task without cron, got %q", tasks[1].Cron)
+	}
+}
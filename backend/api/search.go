@@ -29,6 +29,10 @@ func NewSearchHandler(client *scryfall.Client, db *gorm.DB, settingsService *ser
 	}
 }
 
+// LocalSearchPageSize matches Scryfall's page size, so search pages the same whichever
+// answers it
+const LocalSearchPageSize = 175
+
 // SearchResponse wraps search results with pagination metadata
 // tygo:export
 type SearchResponse struct {
@@ -36,6 +40,7 @@ type SearchResponse struct {
 	Page       int                  `json:"page"`
 	TotalCards int                  `json:"total_cards"`
 	HasMore    bool                 `json:"has_more"`
+	Source     string               `json:"source,omitempty"` // "local" or "scryfall", whichever answered
 }
 
 // CardPrices represents card pricing information
@@ -63,6 +68,7 @@ type CardResult struct {
 	Finishes        []string   `json:"finishes"`
 	FrameEffects    []string   `json:"frame_effects,omitempty"`
 	PromoTypes      []string   `json:"promo_types,omitempty"`
+	BorderColor     string     `json:"border_color,omitempty"`
 	EDHRECRank      *int       `json:"edhrec_rank,omitempty"`
 	Prices          CardPrices `json:"prices"`
 }
@@ -75,14 +81,25 @@ type CardInventoryData struct {
 	TotalQuantity  int                `json:"total_quantity"`
 }
 
-// EnhancedCardResult represents a card with inventory information
+// EnhancedCardResult represents a card with inventory information. The purchase and
+// related links are included unless the card_external_links setting is off.
 // tygo:export
 type EnhancedCardResult struct {
-	CardResult `tstype:",extends"`
-	Inventory  CardInventoryData `json:"inventory"`
+	CardResult   `tstype:",extends"`
+	Inventory    CardInventoryData `json:"inventory"`
+	PurchaseURIs map[string]string `json:"purchase_uris,omitempty"` // Marketplace links (tcgplayer, cardmarket, cardhoarder)
+	RelatedURIs  map[string]string `json:"related_uris,omitempty"`  // Reference links (gatherer, tcgplayer_decks, edhrec, mtgtop8)
+}
+
+// withExternalLinks adds the card's purchase and related links to a result
+func (r EnhancedCardResult) withExternalLinks(card goscryfall.Card) EnhancedCardResult {
+	r.PurchaseURIs, r.RelatedURIs = BuildCardExternalLinks(card)
+	return r
 }
 
-// Search searches for cards by query string
+// Search searches for cards by query string in Scryfall's syntax. Queries using only
+// syntax ParseScryfallQuery understands are answered from the imported cards; anything
+// else, or a query with no local matches, goes to Scryfall.
 func (h *SearchHandler) Search(c fiber.Ctx) error {
 	query := c.Query("q")
 
@@ -95,16 +112,24 @@ func (h *SearchHandler) Search(c fiber.Ctx) error {
 		page = 1
 	}
 
+	filters, err := parsePrintFilters(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if filterQuery := filters.scryfallQuery(); filterQuery != "" {
+		query = query + " " + filterQuery
+	}
+
 	// Get search settings
 	defaultSearch, err := h.settingsService.Get(c.RequestCtx(), "scryfall_default_search")
 	if err != nil {
-		slog.Warn("failed to get scryfall_default_search setting", "component", "search", "error", err)
+		slog.WarnContext(c.RequestCtx(), "failed to get scryfall_default_search setting", "component", "search", "error", err)
 		defaultSearch = ""
 	}
 
 	uniqueModeStr, err := h.settingsService.Get(c.RequestCtx(), "scryfall_unique_mode")
 	if err != nil {
-		slog.Warn("failed to get scryfall_unique_mode setting", "component", "search", "error", err)
+		slog.WarnContext(c.RequestCtx(), "failed to get scryfall_unique_mode setting", "component", "search", "error", err)
 		uniqueModeStr = "cards"
 	}
 
@@ -123,10 +148,14 @@ func (h *SearchHandler) Search(c fiber.Ctx) error {
 	case "prints":
 		uniqueMode = goscryfall.UniqueModePrints
 	default:
-		slog.Warn("unknown unique mode, defaulting to cards", "component", "search", "unique_mode", uniqueModeStr)
+		slog.WarnContext(c.RequestCtx(), "unknown unique mode, defaulting to cards", "component", "search", "unique_mode", uniqueModeStr)
 		uniqueMode = goscryfall.UniqueModeCards // default to cards
 	}
 
+	if response, ok := h.searchLocally(c, query, page, uniqueMode); ok {
+		return c.JSON(response)
+	}
+
 	// Search with options
 	result, err := h.client.SearchWithOptions(c.RequestCtx(), query, scryfall.SearchOptions{
 		Page:       page,
@@ -136,67 +165,61 @@ func (h *SearchHandler) Search(c fiber.Ctx) error {
 		return utils.HandleScryfallError(c, err, "failed to search cards")
 	}
 
-	// Collect all oracle_ids from search results
-	oracleIDs := make([]string, len(result.Cards))
-	for i, card := range result.Cards {
-		oracleIDs[i] = card.OracleID
-	}
-
-	// Query all inventory items matching these oracle_ids
-	var allInventory []models.Inventory
-	if len(oracleIDs) > 0 {
-		if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").
-			Where("oracle_id IN ?", oracleIDs).
-			Find(&allInventory).Error; err != nil {
-			slog.Warn("inventory lookup failed", "component", "search", "error", err)
-		}
-	}
-
-	// Build a map of oracle_id -> inventory items for quick lookup
-	inventoryByOracle := make(map[string][]models.Inventory)
-	for _, inv := range allInventory {
-		inventoryByOracle[inv.OracleID] = append(inventoryByOracle[inv.OracleID], inv)
-	}
-
-	// Build enhanced results
-	cards := make([]EnhancedCardResult, len(result.Cards))
-	for i, card := range result.Cards {
-		cardResult := BuildCardResult(card)
-
-		// Split inventory into this printing vs other printings
-		inventoryData := CardInventoryData{
-			ThisPrinting:   []models.Inventory{},
-			OtherPrintings: []models.Inventory{},
-			TotalQuantity:  0,
-		}
-
-		if inventory, ok := inventoryByOracle[card.OracleID]; ok {
-			for _, inv := range inventory {
-				inventoryData.TotalQuantity += inv.Quantity
-				if inv.ScryfallID == card.ID {
-					inventoryData.ThisPrinting = append(inventoryData.ThisPrinting, inv)
-				} else {
-					inventoryData.OtherPrintings = append(inventoryData.OtherPrintings, inv)
-				}
-			}
-		}
-
-		cards[i] = EnhancedCardResult{
-			CardResult: cardResult,
-			Inventory:  inventoryData,
-		}
-	}
+	cards := h.withInventory(c, result.Cards)
 
 	response := SearchResponse{
 		Data:       cards,
 		Page:       result.Page,
 		TotalCards: result.TotalCards,
 		HasMore:    result.HasMore,
+		Source:     "scryfall",
 	}
 
 	return c.JSON(response)
 }
 
+// searchLocally answers a Scryfall-syntax query from the imported cards. It reports
+// false when the query uses unsupported syntax, nothing matches, or the search fails,
+// leaving the query to Scryfall.
+func (h *SearchHandler) searchLocally(c fiber.Ctx, query string, page int, uniqueMode goscryfall.UniqueMode) (SearchResponse, bool) {
+	ctx := c.RequestCtx()
+	filters, err := services.ParseScryfallQuery(query)
+	if err != nil {
+		slog.DebugContext(ctx, "searching Scryfall", "component", "search", "reason", err)
+		return SearchResponse{}, false
+	}
+	// Art and prints both list every printing locally
+	filters.UniqueCards = uniqueMode == goscryfall.UniqueModeCards
+
+	cards, total, err := services.NewCardSearchService(h.db).Search(ctx, filters, page, LocalSearchPageSize)
+	if err != nil {
+		slog.WarnContext(ctx, "local search failed, searching Scryfall", "component", "search", "error", err)
+		return SearchResponse{}, false
+	}
+	if total == 0 {
+		// Possibly newer than the last bulk import; Scryfall has the final word
+		return SearchResponse{}, false
+	}
+
+	scryfallCards := make([]goscryfall.Card, 0, len(cards))
+	for _, card := range cards {
+		scryfallCard, err := card.ToScryfallCard()
+		if err != nil {
+			slog.WarnContext(ctx, "skipping card with invalid JSON", "component", "search", "scryfall_id", card.ScryfallID, "error", err)
+			continue
+		}
+		scryfallCards = append(scryfallCards, scryfallCard)
+	}
+
+	return SearchResponse{
+		Data:       h.withInventory(c, scryfallCards),
+		Page:       page,
+		TotalCards: int(total),
+		HasMore:    int64(page*LocalSearchPageSize) < total,
+		Source:     "local",
+	}, true
+}
+
 // GetCard retrieves a single card by Scryfall ID with inventory data
 func (h *SearchHandler) GetCard(c fiber.Ctx) error {
 	cardID := c.Params("id")
@@ -223,7 +246,7 @@ func (h *SearchHandler) GetCard(c fiber.Ctx) error {
 	if err := h.db.WithContext(c.RequestCtx()).Preload("StorageLocation").
 		Where("oracle_id = ?", card.OracleID).
 		Find(&inventory).Error; err != nil {
-		slog.Warn("inventory lookup failed", "component", "search", "error", err)
+		slog.WarnContext(c.RequestCtx(), "inventory lookup failed", "component", "search", "error", err)
 	}
 
 	for _, inv := range inventory {
@@ -235,10 +258,14 @@ func (h *SearchHandler) GetCard(c fiber.Ctx) error {
 		}
 	}
 
-	return c.JSON(EnhancedCardResult{
+	result := EnhancedCardResult{
 		CardResult: cardResult,
 		Inventory:  inventoryData,
-	})
+	}
+	if services.ExternalLinksEnabled(c.RequestCtx(), h.db) {
+		result = result.withExternalLinks(card)
+	}
+	return c.JSON(result)
 }
 
 // AutocompleteResponse represents card name autocomplete suggestions
@@ -257,7 +284,7 @@ func (h *SearchHandler) Autocomplete(c fiber.Ctx) error {
 
 	result, err := h.client.Autocomplete(c.RequestCtx(), query)
 	if err != nil {
-		slog.Warn("autocomplete failed", "component", "search", "error", err)
+		slog.WarnContext(c.RequestCtx(), "autocomplete failed", "component", "search", "error", err)
 		return c.JSON(AutocompleteResponse{Suggestions: []string{}})
 	}
 
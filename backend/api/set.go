@@ -6,27 +6,34 @@ import (
 	"backend/utils"
 	"context"
 	"errors"
-	"log/slog"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	goscryfall "github.com/BlueMonday/go-scryfall"
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
+// setListTreatments are the finishes a generated set list can be limited to
+var setListTreatments = []string{"nonfoil", "foil", "etched"}
+
 // SetHandler handles set endpoints
 type SetHandler struct {
 	db             *gorm.DB
 	setDataService *services.SetDataService
+	jobService     *services.JobService
 	dataDir        string
 }
 
 // NewSetHandler creates a new set handler
-func NewSetHandler(db *gorm.DB, setDataService *services.SetDataService, dataDir string) *SetHandler {
+func NewSetHandler(db *gorm.DB, setDataService *services.SetDataService, jobService *services.JobService, dataDir string) *SetHandler {
 	return &SetHandler{
 		db:             db,
 		setDataService: setDataService,
+		jobService:     jobService,
 		dataDir:        dataDir,
 	}
 }
@@ -35,21 +42,33 @@ func NewSetHandler(db *gorm.DB, setDataService *services.SetDataService, dataDir
 func (h *SetHandler) List(c fiber.Ctx) error {
 	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
 
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.Set{})
+	for _, flag := range []string{"standard_legal", "preview"} {
+		switch c.Query(flag) {
+		case "":
+		case "true":
+			query = query.Where(flag+" = ?", true)
+		case "false":
+			query = query.Where(flag+" = ?", false)
+		default:
+			return utils.ReturnError(c, fiber.StatusBadRequest, flag+" must be true or false")
+		}
+	}
+
 	var total int64
-	if err := h.db.WithContext(c.RequestCtx()).Model(&models.Set{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to count sets", "database count failed", err)
 	}
 
 	var sets []models.Set
 	offset := utils.CalculateOffset(params.Page, params.PageSize)
-	if err := h.db.WithContext(c.RequestCtx()).Order("released_at DESC, name ASC").Offset(offset).Limit(params.PageSize).Find(&sets).Error; err != nil {
+	if err := query.Order("released_at DESC, name ASC").Offset(offset).Limit(params.PageSize).Find(&sets).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch sets", "database query failed", err)
 	}
 
-	response := utils.NewPaginatedResponse(sets, params.Page, params.PageSize, total)
-	return c.JSON(response)
+	return utils.SendPaginated(c, sets, params.Page, params.PageSize, total)
 }
 
 // GetByID returns a single set by Scryfall ID
@@ -88,6 +107,94 @@ func (h *SetHandler) GetByCode(c fiber.Ctx) error {
 	return c.JSON(set)
 }
 
+// Completion returns how many of a set's printings are owned, in total and by rarity
+func (h *SetHandler) Completion(c fiber.Ctx) error {
+	code := c.Params("code")
+	if code == "" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid code")
+	}
+
+	completion, err := h.setDataService.Completion(c.RequestCtx(), code)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "set not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to compute set completion", "completion query failed", err)
+	}
+	return c.JSON(completion)
+}
+
+// CompletionSummary returns the completion of every set with an owned card, most complete first
+func (h *SetHandler) CompletionSummary(c fiber.Ctx) error {
+	summary, err := h.setDataService.CompletionSummary(c.RequestCtx())
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to compute set completion", "completion query failed", err)
+	}
+	return c.JSON(summary)
+}
+
+// GenerateSetListRequest represents the optional request body for generating a list
+// of a set's missing cards
+// tygo:export
+type GenerateSetListRequest struct {
+	Name               string   `json:"name,omitempty"` // Defaults to "<set name> (missing)"
+	Description        string   `json:"description,omitempty"`
+	Rarities           []string `json:"rarities,omitempty"`  // e.g. ["rare", "mythic"]; all rarities when empty
+	Treatment          string   `json:"treatment,omitempty"` // nonfoil, foil or etched; any finish when empty
+	AutoTrackInventory bool     `json:"auto_track_inventory"`
+}
+
+// GenerateList creates a list holding one of every printing in the set that isn't owned
+func (h *SetHandler) GenerateList(c fiber.Ctx) error {
+	code := c.Params("code")
+	if code == "" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid code")
+	}
+
+	var req GenerateSetListRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().Body(&req); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+		}
+	}
+
+	var validationErrors []error
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Name, 255, "name"))
+	validationErrors = append(validationErrors, utils.ValidateMaxLength(req.Description, 1000, "description"))
+	for _, rarity := range req.Rarities {
+		if !services.ValidRarity(rarity) {
+			validationErrors = append(validationErrors, fmt.Errorf("unknown rarity %q", rarity))
+		}
+	}
+	if req.Treatment != "" && !slices.Contains(setListTreatments, req.Treatment) {
+		validationErrors = append(validationErrors, errors.New("treatment must be nonfoil, foil or etched"))
+	}
+	if err := utils.CombineErrors(validationErrors); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	list, err := h.setDataService.GenerateMissingList(c.RequestCtx(), code, services.SetListOptions{
+		Name:               req.Name,
+		Description:        req.Description,
+		Rarities:           req.Rarities,
+		Treatment:          req.Treatment,
+		AutoTrackInventory: req.AutoTrackInventory,
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "set not found")
+		}
+		if errors.Is(err, services.ErrNoMissingCards) {
+			return utils.ReturnError(c, fiber.StatusConflict, "every matching card in the set is already owned")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to generate list", "list generation failed", err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(list)
+}
+
 // GetIcon returns the SVG icon for a set
 func (h *SetHandler) GetIcon(c fiber.Ctx) error {
 	code := c.Params("code")
@@ -119,6 +226,29 @@ func (h *SetHandler) GetIcon(c fiber.Ctx) error {
 	return c.SendFile(iconPath)
 }
 
+// FetchPreviewCard fetches a spoiled card of a preview set from Scryfall and stores it,
+// so it can be added to lists before bulk data includes the set
+func (h *SetHandler) FetchPreviewCard(c fiber.Ctx) error {
+	id := c.Params("id")
+	if id == "" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	card, err := h.setDataService.FetchPreviewCard(c.RequestCtx(), id)
+	if err != nil {
+		if errors.Is(err, services.ErrNotPreviewCard) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		var scryfallErr *goscryfall.Error
+		if errors.As(err, &scryfallErr) {
+			return utils.HandleScryfallError(c, scryfallErr, "Failed to fetch preview card")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to store preview card", "preview card fetch failed", err)
+	}
+	return c.JSON(card)
+}
+
 // TriggerImportResponse represents the response from triggering an import
 // tygo:export
 type TriggerImportResponse struct {
@@ -134,12 +264,12 @@ func (h *SetHandler) TriggerImport(c fiber.Ctx, appCtx context.Context) error {
 			"Failed to create import job", "job creation failed", err)
 	}
 
-	// Run import in background
-	go func() {
-		if err := h.setDataService.DownloadAndImport(appCtx, job.ID); err != nil {
-			slog.Error("set data import failed", "job_id", job.ID, "error", err)
-		}
-	}()
+	// Run import in background; the worker pool logs a failure
+	if err := h.jobService.Go(appCtx, job.ID, "set data import", func(ctx context.Context) error {
+		return h.setDataService.DownloadAndImport(ctx, job.ID)
+	}); err != nil {
+		return startJobError(c, err)
+	}
 
 	return c.Status(fiber.StatusAccepted).JSON(TriggerImportResponse{
 		Message: "Set data import started",
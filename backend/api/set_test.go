@@ -1,33 +1,28 @@
 package api
 
 import (
+	"backend/database/databasetest"
 	"backend/models"
 	"backend/scryfall"
 	"backend/services"
+	"backend/utils"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gofiber/fiber/v3"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func setupSetTestApp(t *testing.T) (*fiber.App, *gorm.DB, string) {
 	t.Helper()
 
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	if err != nil {
-		t.Fatalf("failed to connect to test database: %v", err)
-	}
-
-	if err := db.AutoMigrate(&models.Set{}, &models.Job{}, &models.Setting{}); err != nil {
-		t.Fatalf("failed to migrate test database: %v", err)
-	}
+	db := databasetest.Open(t)
 
 	dataDir := t.TempDir()
 
@@ -47,7 +42,7 @@ func setupSetTestApp(t *testing.T) (*fiber.App, *gorm.DB, string) {
 		t.Fatalf("failed to create scryfall client: %v", err)
 	}
 	setDataService := services.NewSetDataService(db, jobService, settingsService, scryfallClient, dataDir)
-	handler := NewSetHandler(db, setDataService, dataDir)
+	handler := NewSetHandler(db, setDataService, jobService, dataDir)
 
 	app := fiber.New()
 	sets := app.Group("/sets")
@@ -55,6 +50,9 @@ func setupSetTestApp(t *testing.T) (*fiber.App, *gorm.DB, string) {
 	sets.Get("/id/:id", handler.GetByID)
 	sets.Get("/code/:code", handler.GetByCode)
 	sets.Get("/code/:code/icon", handler.GetIcon)
+	sets.Get("/completion", handler.CompletionSummary)
+	sets.Get("/code/:code/completion", handler.Completion)
+	sets.Post("/code/:code/generate-list", handler.GenerateList)
 
 	return app, db, dataDir
 }
@@ -97,6 +95,85 @@ func TestSetList_WithData(t *testing.T) {
 	}
 }
 
+func TestSetList_StandardLegalFilter(t *testing.T) {
+	app, db, _ := setupSetTestApp(t)
+
+	db.Create(&models.Set{ScryfallID: "set-1", Code: "std", Name: "Standard Set", StandardLegal: true})
+	db.Create(&models.Set{ScryfallID: "set-2", Code: "old", Name: "Rotated Set"})
+
+	req := httptest.NewRequest(http.MethodGet, "/sets/?standard_legal=true", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result utils.PaginatedResponse[models.Set]
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.Data) != 1 || result.Data[0].Code != "std" {
+		t.Errorf("expected only set std, got %v", result.Data)
+	}
+}
+
+func TestSetList_StandardLegalFilter_Invalid(t *testing.T) {
+	app, _, _ := setupSetTestApp(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/sets/?standard_legal=maybe", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestSetList_PreviewFilter(t *testing.T) {
+	app, db, _ := setupSetTestApp(t)
+
+	db.Create(&models.Set{ScryfallID: "set-1", Code: "upc", Name: "Upcoming Set", Preview: true})
+	db.Create(&models.Set{ScryfallID: "set-2", Code: "old", Name: "Released Set"})
+
+	for query, expected := range map[string]string{"true": "upc", "false": "old"} {
+		req := httptest.NewRequest(http.MethodGet, "/sets/?preview="+query, nil)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+		}
+
+		var result utils.PaginatedResponse[models.Set]
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 || result.Data[0].Code != expected {
+			t.Errorf("preview=%s: expected only set %s, got %v", query, expected, result.Data)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/sets/?preview=maybe", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
 func TestSetGetByID_Found(t *testing.T) {
 	app, db, _ := setupSetTestApp(t)
 
@@ -245,3 +322,106 @@ func TestSetGetIcon_PathTraversalDotDot(t *testing.T) {
 		t.Error("path traversal attempt should not succeed with 200")
 	}
 }
+
+func TestSetCompletion(t *testing.T) {
+	app, db, _ := setupSetTestApp(t)
+
+	db.Create(&models.Set{ScryfallID: "set-tst", Code: "tst", Name: "Test Set"})
+	db.Create(&models.Card{ScryfallID: "tst-1", OracleID: "oracle-1", Rarity: "rare", RawJSON: `{"set": "tst"}`})
+	db.Create(&models.Card{ScryfallID: "tst-2", OracleID: "oracle-2", Rarity: "common", RawJSON: `{"set": "tst"}`})
+	db.Create(&models.Inventory{ScryfallID: "tst-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 1})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/sets/code/tst/completion", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var completion services.SetCompletion
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if completion.TotalCards != 2 || completion.OwnedCards != 1 || len(completion.Rarities) != 2 {
+		t.Errorf("unexpected completion: %+v", completion)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/sets/completion", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	var summary []services.SetCompletion
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(summary) != 1 || summary[0].SetCode != "tst" || summary[0].Percentage != 50 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/sets/code/zzz/completion", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestSetGenerateList(t *testing.T) {
+	app, db, _ := setupSetTestApp(t)
+
+	db.Create(&models.Set{ScryfallID: "set-tst", Code: "tst", Name: "Test Set"})
+	db.Create(&models.Card{ScryfallID: "tst-1", OracleID: "oracle-1", Rarity: "rare", CollectorNumber: "1",
+		RawJSON: `{"set": "tst", "finishes": ["nonfoil", "foil"]}`})
+	db.Create(&models.Card{ScryfallID: "tst-2", OracleID: "oracle-2", Rarity: "common", CollectorNumber: "2",
+		RawJSON: `{"set": "tst", "finishes": ["nonfoil"]}`})
+	db.Create(&models.Inventory{ScryfallID: "tst-2", OracleID: "oracle-2", Treatment: "nonfoil", Quantity: 1})
+
+	post := func(url, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := post("/sets/code/tst/generate-list", `{"treatment": "foil", "auto_track_inventory": true}`)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	var list models.List
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if list.Name != "Test Set (missing)" || !list.AutoTrackInventory || len(list.Items) != 1 || list.Items[0].ScryfallID != "tst-1" {
+		t.Errorf("unexpected list: %+v", list)
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		body   string
+		status int
+	}{
+		{"Unknown rarity", "/sets/code/tst/generate-list", `{"rarities": ["legendary"]}`, http.StatusBadRequest},
+		{"Unknown treatment", "/sets/code/tst/generate-list", `{"treatment": "glossy"}`, http.StatusBadRequest},
+		{"Nothing missing", "/sets/code/tst/generate-list", `{"rarities": ["common"]}`, http.StatusConflict},
+		{"Unknown set", "/sets/code/zzz/generate-list", ``, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(tt.url, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -67,6 +67,10 @@ func (h *SettingsHandler) Update(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
 
+	if err := services.ValidateSettingValue(key, req.Value); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
 	if err := h.service.Set(c.RequestCtx(), key, req.Value); err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update setting", "setting update failed", err)
@@ -88,11 +92,14 @@ func (h *SettingsHandler) UpdateBulk(c fiber.Ctx) error {
 
 	// Validate keys against whitelist
 	validKeys := services.ValidSettingKeys()
-	for key := range req {
+	for key, value := range req {
 		if !validKeys[key] {
 			return utils.ReturnError(c, fiber.StatusBadRequest,
 				fmt.Sprintf("invalid setting key: %s", key))
 		}
+		if err := services.ValidateSettingValue(key, value); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
 	}
 
 	if err := h.service.SetBulk(c.RequestCtx(), req); err != nil {
@@ -347,3 +347,37 @@ func TestSettingsUpdateBulk_PartialUpdate(t *testing.T) {
 		t.Errorf("expected bulk_data_update_time='03:00' (unchanged), got '%s'", value3)
 	}
 }
+
+func TestSettingsUpdate_InvalidTimezone(t *testing.T) {
+	app, service := setupSettingsTestApp(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"Single unknown zone", "PUT", "/settings/scheduler_timezone", `{"value": "Eastern"}`, fiber.StatusBadRequest},
+		{"Bulk unknown zone", "PUT", "/settings", `{"scheduler_timezone": "Eastern"}`, fiber.StatusBadRequest},
+		{"Single valid zone", "PUT", "/settings/scheduler_timezone", `{"value": "America/New_York"}`, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("failed to make request: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	value, _ := service.Get(context.Background(), "scheduler_timezone")
+	if value != "America/New_York" {
+		t.Errorf("expected only the valid zone to be stored, got %q", value)
+	}
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ShareLinkHandler handles read-only share links for lists and storage locations
+type ShareLinkHandler struct {
+	db *gorm.DB
+}
+
+// NewShareLinkHandler creates a new share link handler
+func NewShareLinkHandler(db *gorm.DB) *ShareLinkHandler {
+	return &ShareLinkHandler{db: db}
+}
+
+// CreateShareLinkRequest represents the request body for creating a share link.
+// Exactly one of ListID and StorageLocationID must be set.
+// tygo:export
+type CreateShareLinkRequest struct {
+	ListID            *uint  `json:"list_id,omitempty"`
+	StorageLocationID *uint  `json:"storage_location_id,omitempty"`
+	Label             string `json:"label,omitempty"`
+}
+
+// SharedViewResponse is what opening a share link shows: the shared list or
+// storage location, whose items are served by GET /share/:token/items
+// tygo:export
+type SharedViewResponse struct {
+	Target          models.ShareLinkTarget  `json:"target"`
+	Label           string                  `json:"label,omitempty"`
+	List            *models.List            `json:"list,omitempty"`
+	StorageLocation *models.StorageLocation `json:"storage_location,omitempty"`
+}
+
+// newShareToken returns a random token for a share URL
+func newShareToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// List returns share links, newest first, optionally filtered by list_id or storage_location_id
+func (h *ShareLinkHandler) List(c fiber.Ctx) error {
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.ShareLink{})
+	if listID := c.Query("list_id"); listID != "" {
+		if err := utils.ValidateNumericParam(listID, "list_id"); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		query = query.Where("list_id = ?", listID)
+	}
+	if locationID := c.Query("storage_location_id"); locationID != "" {
+		if err := utils.ValidateNumericParam(locationID, "storage_location_id"); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		query = query.Where("storage_location_id = ?", locationID)
+	}
+
+	links := []models.ShareLink{}
+	if err := query.Order("created_at DESC, id DESC").Find(&links).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch share links", "database query failed", err)
+	}
+
+	return c.JSON(links)
+}
+
+// Create creates a share link giving anyone with its token a read-only view of a
+// list or storage location
+func (h *ShareLinkHandler) Create(c fiber.Ctx) error {
+	var req CreateShareLinkRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if (req.ListID == nil) == (req.StorageLocationID == nil) {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "exactly one of list_id and storage_location_id must be set")
+	}
+	if err := utils.ValidateMaxLength(req.Label, 100, "label"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.RequestCtx()
+	if req.ListID != nil {
+		if err := h.db.WithContext(ctx).First(&models.List{}, *req.ListID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusNotFound, "list not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to fetch list", "database query failed", err)
+		}
+	} else {
+		if err := h.db.WithContext(ctx).First(&models.StorageLocation{}, *req.StorageLocationID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+			}
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to fetch storage location", "database query failed", err)
+		}
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create share link", "token generation failed", err)
+	}
+
+	link := models.ShareLink{
+		Token:             token,
+		Label:             req.Label,
+		ListID:            req.ListID,
+		StorageLocationID: req.StorageLocationID,
+	}
+	if err := h.db.WithContext(ctx).Create(&link).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to create share link", "database insert failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+// Revoke stops a share link from being used. The link is kept so it still shows
+// in the list of links with its revocation time.
+func (h *ShareLinkHandler) Revoke(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var link models.ShareLink
+	if err := h.db.WithContext(c.RequestCtx()).First(&link, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "share link not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch share link", "database query failed", err)
+	}
+
+	if link.IsActive() {
+		now := time.Now()
+		link.RevokedAt = &now
+		if err := h.db.WithContext(c.RequestCtx()).Save(&link).Error; err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to revoke share link", "database update failed", err)
+		}
+	}
+
+	return c.JSON(link)
+}
+
+// GetShared returns the list or storage location behind a share link token
+func (h *ShareLinkHandler) GetShared(c fiber.Ctx) error {
+	link, ok, err := h.activeLink(c)
+	if !ok {
+		return err
+	}
+
+	resp := SharedViewResponse{Target: link.Target(), Label: link.Label}
+	if link.ListID != nil {
+		resp.List = &models.List{}
+		err = h.db.WithContext(c.RequestCtx()).First(resp.List, *link.ListID).Error
+	} else {
+		resp.StorageLocation = &models.StorageLocation{}
+		err = h.db.WithContext(c.RequestCtx()).First(resp.StorageLocation, *link.StorageLocationID).Error
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "share link not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch shared view", "database query failed", err)
+	}
+
+	return c.JSON(resp)
+}
+
+// SharedItems returns the contents behind a share link token: a list's items as
+// GET /lists/:id/items returns them, or the cards in a storage location and the
+// locations nested under it as GET /inventory/cards returns them
+func (h *ShareLinkHandler) SharedItems(c fiber.Ctx) error {
+	link, ok, err := h.activeLink(c)
+	if !ok {
+		return err
+	}
+
+	if link.ListID != nil {
+		return NewListHandler(h.db).listItemsResponse(c, *link.ListID)
+	}
+
+	db := h.db.WithContext(c.RequestCtx())
+	descendants, err := models.StorageLocationDescendantIDs(db, *link.StorageLocationID)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch nested storage locations", "descendant lookup failed", err)
+	}
+	locationIDs := append([]uint{*link.StorageLocationID}, descendants...)
+
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, DefaultCardsPageSize)
+	query := db.Model(&models.Inventory{}).Where("storage_location_id IN ?", locationIDs)
+	return sendInventoryCards(c, h.db, query, "created_at DESC", params, false)
+}
+
+// activeLink looks up the share link for the :token route parameter.
+// When ok is false the error response has already been written and err should be returned.
+func (h *ShareLinkHandler) activeLink(c fiber.Ctx) (models.ShareLink, bool, error) {
+	var link models.ShareLink
+	if err := h.db.WithContext(c.RequestCtx()).Where("token = ?", c.Params("token")).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return link, false, utils.ReturnError(c, fiber.StatusNotFound, "share link not found")
+		}
+		return link, false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch share link", "database query failed", err)
+	}
+	if !link.IsActive() {
+		return link, false, utils.ReturnError(c, fiber.StatusNotFound, "share link not found")
+	}
+	return link, true, nil
+}
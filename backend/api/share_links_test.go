@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupShareLinkTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.Card{}, &models.StorageLocation{},
+		&models.Inventory{}, &models.ShareLink{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	app := fiber.New()
+	handler := NewShareLinkHandler(db)
+
+	app.Get("/share-links", handler.List)
+	app.Post("/share-links", handler.Create)
+	app.Delete("/share-links/:id", handler.Revoke)
+	app.Get("/share/:token", handler.GetShared)
+	app.Get("/share/:token/items", handler.SharedItems)
+
+	return app, db
+}
+
+func postShareLink(t *testing.T, app *fiber.App, body string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/share-links", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func createTestShareLink(t *testing.T, app *fiber.App, body string) models.ShareLink {
+	t.Helper()
+
+	resp := postShareLink(t, app, body)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var link models.ShareLink
+	if err := json.NewDecoder(resp.Body).Decode(&link); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return link
+}
+
+func TestShareLinks_SharedList(t *testing.T) {
+	app, db := setupShareLinkTestApp(t)
+	list := createTestList(t, db, "Wishlist")
+	createTestListItem(t, db, list.ID, "bolt", "oracle-bolt", "nonfoil", 4, 1)
+
+	link := createTestShareLink(t, app, fmt.Sprintf(`{"list_id": %d, "label": "For trades"}`, list.ID))
+	if link.Token == "" || link.ListID == nil || *link.ListID != list.ID || link.StorageLocationID != nil {
+		t.Fatalf("unexpected share link: %+v", link)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/share/"+link.Token, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var view SharedViewResponse
+	if err := json.NewDecoder(resp.Body).Decode(&view); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if view.Target != models.ShareLinkTargetList || view.Label != "For trades" || view.List == nil || view.List.ID != list.ID {
+		t.Errorf("unexpected shared view: %+v", view)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/share/"+link.Token+"/items", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var items ListItemsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if items.TotalItems != 1 || items.TotalWanted != 4 {
+		t.Errorf("expected the list's one item, got %+v", items)
+	}
+}
+
+func TestShareLinks_StorageLocation(t *testing.T) {
+	app, db := setupShareLinkTestApp(t)
+	binder := models.StorageLocation{Name: "Trade Binder", StorageType: models.Binder}
+	db.Create(&binder)
+	page := models.StorageLocation{Name: "Rares", StorageType: models.Binder, ParentID: &binder.ID}
+	db.Create(&page)
+	other := createTestLocation(t, db, models.Box)
+
+	createTestCard(t, db, "bolt", "Lightning Bolt", "m10", "common", "1.00")
+	createTestCard(t, db, "ring", "Sol Ring", "c21", "uncommon", "2.00")
+	createTestCard(t, db, "path", "Swords to Plowshares", "ema", "uncommon", "3.00")
+	createTestInventoryItem(t, db, "bolt", 2, &binder.ID)
+	createTestInventoryItem(t, db, "ring", 1, &page.ID)
+	createTestInventoryItem(t, db, "path", 1, &other.ID)
+
+	link := createTestShareLink(t, app, fmt.Sprintf(`{"storage_location_id": %d}`, binder.ID))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/share/"+link.Token, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var view SharedViewResponse
+	if err := json.NewDecoder(resp.Body).Decode(&view); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if view.Target != models.ShareLinkTargetStorageLocation || view.StorageLocation == nil || view.StorageLocation.Name != "Trade Binder" {
+		t.Errorf("unexpected shared view: %+v", view)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/share/"+link.Token+"/items", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var cards InventoryCardsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&cards); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if cards.TotalCards != 2 {
+		t.Errorf("expected the binder's cards and its nested location's, got %d", cards.TotalCards)
+	}
+	for _, card := range cards.Data {
+		if card.ID == "path" {
+			t.Error("expected cards in other locations to stay private")
+		}
+	}
+}
+
+func TestShareLinks_Revoke(t *testing.T) {
+	app, db := setupShareLinkTestApp(t)
+	list := createTestList(t, db, "Wishlist")
+	link := createTestShareLink(t, app, fmt.Sprintf(`{"list_id": %d}`, list.ID))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/share-links/%d", link.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	for _, path := range []string{"/share/" + link.Token, "/share/" + link.Token + "/items"} {
+		resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: expected status %d for a revoked link, got %d", path, http.StatusNotFound, resp.StatusCode)
+		}
+	}
+
+	// Revoked links stay listed
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/share-links?list_id=%d", list.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var links []models.ShareLink
+	if err := json.NewDecoder(resp.Body).Decode(&links); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(links) != 1 || links[0].RevokedAt == nil {
+		t.Errorf("expected the revoked link listed, got %+v", links)
+	}
+}
+
+func TestShareLinks_CreateValidation(t *testing.T) {
+	app, db := setupShareLinkTestApp(t)
+	list := createTestList(t, db, "Wishlist")
+	location := createTestLocation(t, db, models.Box)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"No target", `{"label": "Mine"}`, http.StatusBadRequest},
+		{"Both targets", fmt.Sprintf(`{"list_id": %d, "storage_location_id": %d}`, list.ID, location.ID), http.StatusBadRequest},
+		{"Long label", fmt.Sprintf(`{"list_id": %d, "label": %q}`, list.ID, strings.Repeat("a", 101)), http.StatusBadRequest},
+		{"Unknown list", `{"list_id": 999}`, http.StatusNotFound},
+		{"Unknown storage location", `{"storage_location_id": 999}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postShareLink(t, app, tt.body)
+			resp.Body.Close()
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestShareLinks_UnknownToken(t *testing.T) {
+	app, _ := setupShareLinkTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/share/nope", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
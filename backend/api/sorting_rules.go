@@ -3,9 +3,13 @@ package api
 import (
 	"backend/models"
 	"backend/rules"
+	"backend/services"
 	"backend/utils"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -53,8 +57,7 @@ func (h *SortingRulesHandler) List(c fiber.Ctx) error {
 			"Failed to fetch sorting rules", "database query failed", err)
 	}
 
-	response := utils.NewPaginatedResponse(rules, params.Page, params.PageSize, total)
-	return c.JSON(response)
+	return utils.SendPaginated(c, rules, params.Page, params.PageSize, total)
 }
 
 // Get returns a single sorting rule by ID
@@ -78,9 +81,9 @@ func (h *SortingRulesHandler) Get(c fiber.Ctx) error {
 // CreateSortingRuleRequest represents the request body for creating a sorting rule
 type CreateSortingRuleRequest struct {
 	Name              string `json:"name"`
-	Priority          int    `json:"priority"`
+	Priority          *int   `json:"priority,omitempty"` // Defaults to after the last rule
 	Expression        string `json:"expression"`
-	StorageLocationID uint   `json:"storage_location_id"`
+	StorageLocationID *uint  `json:"storage_location_id"` // 0 leaves matching cards unassigned
 	Enabled           *bool  `json:"enabled,omitempty"`
 }
 
@@ -106,14 +109,26 @@ func (h *SortingRulesHandler) Create(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid expression: "+err.Error())
 	}
 
-	// Validate storage location exists
-	var location models.StorageLocation
-	if err := h.db.WithContext(c.RequestCtx()).First(&location, req.StorageLocationID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+	if req.StorageLocationID == nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "storage_location_id is required")
+	}
+	if ok, err := checkRuleTarget(c, h.db, *req.StorageLocationID); !ok {
+		return err
+	}
+
+	var priority int
+	if req.Priority != nil {
+		priority = *req.Priority
+		if ok, err := checkPriorityFree(c, h.db, priority, 0); !ok {
+			return err
 		}
-		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to validate storage location", "storage location lookup failed", err)
+	} else {
+		next, err := nextRulePriority(h.db.WithContext(c.RequestCtx()))
+		if err != nil {
+			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+				"Failed to create sorting rule", "priority lookup failed", err)
+		}
+		priority = next
 	}
 
 	enabled := true
@@ -123,13 +138,16 @@ func (h *SortingRulesHandler) Create(c fiber.Ctx) error {
 
 	rule := models.SortingRule{
 		Name:              req.Name,
-		Priority:          req.Priority,
+		Priority:          priority,
 		Expression:        req.Expression,
-		StorageLocationID: req.StorageLocationID,
+		StorageLocationID: *req.StorageLocationID,
 		Enabled:           enabled,
 	}
 
 	if err := h.db.WithContext(c.RequestCtx()).Create(&rule).Error; err != nil {
+		if isDuplicateError(err) {
+			return utils.ReturnError(c, fiber.StatusConflict, fmt.Sprintf("priority %d is already used by another rule", rule.Priority))
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to create sorting rule", "database insert failed", err)
 	}
@@ -143,6 +161,50 @@ func (h *SortingRulesHandler) Create(c fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(rule)
 }
 
+// checkPriorityFree rejects a priority already held by a rule other than exceptID,
+// since priorities are unique. When it returns false the error response has already
+// been written.
+func checkPriorityFree(c fiber.Ctx, db *gorm.DB, priority int, exceptID uint) (bool, error) {
+	var holder models.SortingRule
+	err := db.WithContext(c.RequestCtx()).Where("priority = ? AND id <> ?", priority, exceptID).First(&holder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check rule priority", "priority lookup failed", err)
+	}
+	return false, utils.ReturnError(c, fiber.StatusConflict,
+		fmt.Sprintf("priority %d is already used by rule %q", priority, holder.Name))
+}
+
+// nextRulePriority returns the priority that places a new rule after every other rule
+func nextRulePriority(tx *gorm.DB) (int, error) {
+	var last int
+	if err := tx.Model(&models.SortingRule{}).Select("COALESCE(MAX(priority), 0)").Scan(&last).Error; err != nil {
+		return 0, err
+	}
+	return last + sortingRulePriorityGap, nil
+}
+
+// checkRuleTarget validates the target location of a rule or rule group, which is
+// either an existing storage location or the virtual unassigned location. When it
+// returns false the error response has already been written.
+func checkRuleTarget(c fiber.Ctx, db *gorm.DB, locationID uint) (bool, error) {
+	if locationID == models.UnassignedLocationID {
+		return true, nil
+	}
+	var location models.StorageLocation
+	if err := db.WithContext(c.RequestCtx()).First(&location, locationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
+		}
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to validate storage location", "storage location lookup failed", err)
+	}
+	return true, nil
+}
+
 // UpdateSortingRuleRequest represents the request body for updating a sorting rule
 type UpdateSortingRuleRequest struct {
 	Name              *string `json:"name,omitempty"`
@@ -178,20 +240,17 @@ func (h *SortingRulesHandler) Update(c fiber.Ctx) error {
 		rule.Name = *req.Name
 	}
 	if req.Priority != nil {
+		if ok, err := checkPriorityFree(c, h.db, *req.Priority, rule.ID); !ok {
+			return err
+		}
 		rule.Priority = *req.Priority
 	}
 	if req.Expression != nil {
 		rule.Expression = *req.Expression
 	}
 	if req.StorageLocationID != nil {
-		// Validate storage location exists
-		var location models.StorageLocation
-		if err := h.db.WithContext(c.RequestCtx()).First(&location, *req.StorageLocationID).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return utils.ReturnError(c, fiber.StatusBadRequest, "storage location not found")
-			}
-			return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-				"Failed to validate storage location", "database query failed", err)
+		if ok, err := checkRuleTarget(c, h.db, *req.StorageLocationID); !ok {
+			return err
 		}
 		rule.StorageLocationID = *req.StorageLocationID
 	}
@@ -200,6 +259,9 @@ func (h *SortingRulesHandler) Update(c fiber.Ctx) error {
 	}
 
 	if err := h.db.WithContext(c.RequestCtx()).Save(&rule).Error; err != nil {
+		if isDuplicateError(err) {
+			return utils.ReturnError(c, fiber.StatusConflict, fmt.Sprintf("priority %d is already used by another rule", rule.Priority))
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update sorting rule", "database update failed", err)
 	}
@@ -262,6 +324,9 @@ func (h *SortingRulesHandler) Evaluate(c fiber.Ctx) error {
 	if req.Treatment != "" {
 		cardData["treatment"] = req.Treatment
 	}
+	if _, ok := cardData["notes"]; !ok {
+		cardData["notes"] = ""
+	}
 
 	// Convert price strings to numbers for expression evaluation
 	// Scryfall returns prices as strings (e.g., "83.73"), but expressions need numbers
@@ -299,6 +364,111 @@ func (h *SortingRulesHandler) Evaluate(c fiber.Ctx) error {
 	})
 }
 
+// TestRulesRequest represents the request body for testing rules against a stored card
+type TestRulesRequest struct {
+	ScryfallID string `json:"scryfall_id"`
+	Treatment  string `json:"treatment,omitempty"` // Defaults to nonfoil
+}
+
+// RuleTestResult reports whether a single rule or rule group matched the tested card
+type RuleTestResult struct {
+	RuleID          uint   `json:"rule_id"`            // 0 for a rule group
+	GroupID         *uint  `json:"group_id,omitempty"` // Set for a rule group
+	Action          string `json:"action,omitempty"`   // The group's action (assign, tag, or skip)
+	Name            string `json:"name"`
+	Priority        int    `json:"priority"`
+	Expression      string `json:"expression"`
+	Matched         bool   `json:"matched"`
+	Error           string `json:"error,omitempty"` // Set when the expression failed to evaluate
+	StorageLocation string `json:"storage_location"`
+}
+
+// TestRulesResponse lists every enabled rule's and rule group's result in priority
+// order, and where the card ends up
+type TestRulesResponse struct {
+	ScryfallID   string                  `json:"scryfall_id"`
+	Treatment    string                  `json:"treatment"`
+	Rules        []RuleTestResult        `json:"rules"`
+	Destination  *models.StorageLocation `json:"destination,omitempty"` // First matching rule's location; nil when nothing matches
+	MatchedRule  *uint                   `json:"matched_rule_id,omitempty"`
+	MatchedGroup *uint                   `json:"matched_group_id,omitempty"` // Set instead of matched_rule_id when an assign group matched first
+}
+
+// Test evaluates a stored card against every enabled rule, reporting each rule's
+// outcome rather than stopping at the first match
+func (h *SortingRulesHandler) Test(c fiber.Ctx) error {
+	var req TestRulesRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if err := utils.ValidateRequired(req.ScryfallID, "scryfall_id"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.Treatment == "" {
+		req.Treatment = "nonfoil"
+	}
+
+	var card models.Card
+	if err := h.db.WithContext(c.RequestCtx()).First(&card, "scryfall_id = ?", req.ScryfallID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "card not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch card", "database query failed", err)
+	}
+
+	cardData, err := rules.RawJSONToRuleData(card.RawJSON, req.Treatment)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to read card data", "card conversion failed", err)
+	}
+
+	var enabledRules []models.SortingRule
+	if err := h.db.WithContext(c.RequestCtx()).Where("enabled = ?", true).
+		Order("priority ASC").
+		Preload("StorageLocation").
+		Find(&enabledRules).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch sorting rules", "database query failed", err)
+	}
+
+	response := TestRulesResponse{
+		ScryfallID: req.ScryfallID,
+		Treatment:  req.Treatment,
+		Rules:      make([]RuleTestResult, 0, len(enabledRules)),
+	}
+	for _, trace := range rules.NewEvaluator(h.db).TraceCard(cardData, enabledRules) {
+		result := RuleTestResult{
+			RuleID:          trace.Rule.ID,
+			Name:            trace.Rule.Name,
+			Priority:        trace.Rule.Priority,
+			Expression:      trace.Rule.Expression,
+			Matched:         trace.Matched,
+			StorageLocation: trace.Rule.StorageLocation.Name,
+		}
+		if trace.Group != nil {
+			result.GroupID = &trace.Group.ID
+			result.Action = string(trace.Group.Action)
+		}
+		if trace.Err != nil {
+			result.Error = trace.Err.Error()
+		}
+		if trace.Assigns() && response.Destination == nil {
+			location := trace.Rule.StorageLocation
+			response.Destination = &location
+			if trace.Group != nil {
+				response.MatchedGroup = &trace.Group.ID
+			} else {
+				ruleID := trace.Rule.ID
+				response.MatchedRule = &ruleID
+			}
+		}
+		response.Rules = append(response.Rules, result)
+	}
+
+	return c.JSON(response)
+}
+
 // ValidateExpressionRequest represents the request body for validating an expression
 type ValidateExpressionRequest struct {
 	Expression string `json:"expression"`
@@ -360,6 +530,19 @@ func (h *SortingRulesHandler) BatchUpdatePriorities(c fiber.Ctx) error {
 		return utils.ReturnError(c, fiber.StatusBadRequest, "updates array cannot be empty")
 	}
 
+	priorities := make(map[uint]int, len(req.Updates))
+	taken := make(map[int]bool, len(req.Updates))
+	for _, update := range req.Updates {
+		if _, ok := priorities[update.ID]; ok {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("rule %d is listed more than once", update.ID))
+		}
+		if taken[update.Priority] {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("priority %d is given to more than one rule", update.Priority))
+		}
+		priorities[update.ID] = update.Priority
+		taken[update.Priority] = true
+	}
+
 	// Use a transaction to ensure all updates succeed or all fail
 	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
 		for _, update := range req.Updates {
@@ -371,19 +554,17 @@ func (h *SortingRulesHandler) BatchUpdatePriorities(c fiber.Ctx) error {
 				}
 				return fmt.Errorf("failed to fetch sorting rule %d: %w", update.ID, err)
 			}
-
-			// Update priority
-			if err := tx.Model(&rule).Update("priority", update.Priority).Error; err != nil {
-				return fmt.Errorf("failed to update priority for rule %d: %w", update.ID, err)
-			}
 		}
-		return nil
+		return setRulePriorities(tx, priorities)
 	})
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return utils.ReturnError(c, fiber.StatusNotFound, err.Error())
 		}
+		if isDuplicateError(err) {
+			return utils.ReturnError(c, fiber.StatusConflict, "priorities collide with rules not in the update")
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update priorities", "batch priority update failed", err)
 	}
@@ -392,3 +573,242 @@ func (h *SortingRulesHandler) BatchUpdatePriorities(c fiber.Ctx) error {
 		UpdatedCount: len(req.Updates),
 	})
 }
+
+// sortingRulePriorityGap is the spacing used when priorities are renumbered,
+// leaving room for later moves to slot in without touching other rules
+const sortingRulePriorityGap = 10
+
+// setRulePriorities gives each rule in priorities its new priority. Priorities are
+// unique, so the rules first step aside to temporary priorities above any in use,
+// letting them swap places without colliding partway through.
+func setRulePriorities(tx *gorm.DB, priorities map[uint]int) error {
+	if len(priorities) == 0 {
+		return nil
+	}
+
+	var top int
+	if err := tx.Model(&models.SortingRule{}).Select("COALESCE(MAX(priority), 0)").Scan(&top).Error; err != nil {
+		return fmt.Errorf("failed to fetch highest priority: %w", err)
+	}
+	ids := slices.Sorted(maps.Keys(priorities))
+	for _, id := range ids {
+		top = max(top, priorities[id])
+	}
+
+	for i, id := range ids {
+		if err := tx.Model(&models.SortingRule{}).Where("id = ?", id).
+			UpdateColumn("priority", top+i+1).Error; err != nil {
+			return fmt.Errorf("failed to update priority for rule %d: %w", id, err)
+		}
+	}
+	for _, id := range ids {
+		if err := tx.Model(&models.SortingRule{}).Where("id = ?", id).
+			UpdateColumns(map[string]interface{}{"priority": priorities[id], "updated_at": time.Now()}).Error; err != nil {
+			return fmt.Errorf("failed to update priority for rule %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// MoveSortingRuleRequest represents the request body for moving a rule.
+// Exactly one of Before or After must be set to the ID of another rule.
+type MoveSortingRuleRequest struct {
+	Before *uint `json:"before,omitempty"`
+	After  *uint `json:"after,omitempty"`
+}
+
+// MoveSortingRuleResponse returns every rule in its new evaluation order
+type MoveSortingRuleResponse struct {
+	Rules []models.SortingRule `json:"rules"`
+}
+
+// planRuleMove works out the priority changes needed to place a rule directly
+// before or after the anchor rule. rules must be in evaluation order. The moved
+// rule takes a priority in the gap between its new neighbours when there is one;
+// otherwise every rule is renumbered with sortingRulePriorityGap spacing.
+func planRuleMove(rules []models.SortingRule, movedID, anchorID uint, after bool) map[uint]int {
+	order := make([]models.SortingRule, 0, len(rules))
+	var moved models.SortingRule
+	for _, rule := range rules {
+		if rule.ID == movedID {
+			moved = rule
+			continue
+		}
+		order = append(order, rule)
+	}
+
+	position := 0
+	for i, rule := range order {
+		if rule.ID == anchorID {
+			position = i
+			if after {
+				position++
+			}
+			break
+		}
+	}
+	order = append(order[:position], append([]models.SortingRule{moved}, order[position:]...)...)
+
+	if len(order) == 1 {
+		return map[uint]int{}
+	}
+
+	// Try to slot into the gap between the new neighbours. Priorities stay
+	// positive, so a rule moved to the front needs room above zero.
+	lower := 0
+	if position > 0 {
+		lower = order[position-1].Priority
+	}
+	if position == len(order)-1 {
+		return map[uint]int{movedID: lower + sortingRulePriorityGap}
+	}
+	if upper := order[position+1].Priority; upper-lower > 1 {
+		return map[uint]int{movedID: lower + (upper-lower)/2}
+	}
+
+	// No room: renumber everything, only touching rules whose priority changes
+	updates := map[uint]int{}
+	for i, rule := range order {
+		priority := (i + 1) * sortingRulePriorityGap
+		if rule.Priority != priority {
+			updates[rule.ID] = priority
+		}
+	}
+	return updates
+}
+
+// Move places a rule directly before or after another rule, renumbering
+// priorities server-side so clients never have to manage collisions
+func (h *SortingRulesHandler) Move(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req MoveSortingRuleRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if (req.Before == nil) == (req.After == nil) {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "exactly one of before or after is required")
+	}
+	anchorID, after := req.Before, false
+	if req.After != nil {
+		anchorID, after = req.After, true
+	}
+	if *anchorID == uint(id) {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "a rule cannot be moved relative to itself")
+	}
+
+	var rules []models.SortingRule
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Order("priority ASC, id ASC").Find(&rules).Error; err != nil {
+			return fmt.Errorf("failed to fetch sorting rules: %w", err)
+		}
+
+		byID := make(map[uint]models.SortingRule, len(rules))
+		for _, rule := range rules {
+			byID[rule.ID] = rule
+		}
+		for _, ruleID := range []uint{uint(id), *anchorID} {
+			if _, ok := byID[ruleID]; !ok {
+				return fmt.Errorf("sorting rule with id %d not found: %w", ruleID, gorm.ErrRecordNotFound)
+			}
+		}
+
+		if err := setRulePriorities(tx, planRuleMove(rules, uint(id), *anchorID, after)); err != nil {
+			return err
+		}
+
+		return tx.Order("priority ASC, id ASC").Preload("StorageLocation").Find(&rules).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to move sorting rule", "sorting rule move failed", err)
+	}
+
+	return c.JSON(MoveSortingRuleResponse{Rules: rules})
+}
+
+// ReorderSortingRulesRequest lists every sorting rule's ID in the new evaluation order
+type ReorderSortingRulesRequest struct {
+	RuleIDs []uint `json:"rule_ids"`
+}
+
+// Reorder rewrites every rule's priority from a complete ordering in one transaction,
+// so a drag-and-drop reorder never passes through colliding priorities
+func (h *SortingRulesHandler) Reorder(c fiber.Ctx) error {
+	var req ReorderSortingRulesRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if len(req.RuleIDs) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "rule_ids cannot be empty")
+	}
+
+	var rules []models.SortingRule
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Find(&rules).Error; err != nil {
+			return fmt.Errorf("failed to fetch sorting rules: %w", err)
+		}
+
+		current := make(map[uint]int, len(rules))
+		for _, rule := range rules {
+			current[rule.ID] = rule.Priority
+		}
+		priorities := make(map[uint]int, len(req.RuleIDs))
+		for i, ruleID := range req.RuleIDs {
+			if _, ok := current[ruleID]; !ok {
+				return fmt.Errorf("sorting rule with id %d not found: %w", ruleID, gorm.ErrRecordNotFound)
+			}
+			if _, ok := priorities[ruleID]; ok {
+				return fmt.Errorf("%w: rule %d is listed more than once", errInvalidReorder, ruleID)
+			}
+			if priority := (i + 1) * sortingRulePriorityGap; current[ruleID] != priority {
+				priorities[ruleID] = priority
+			}
+		}
+		if len(req.RuleIDs) != len(rules) {
+			return fmt.Errorf("%w: expected all %d rules, got %d", errInvalidReorder, len(rules), len(req.RuleIDs))
+		}
+
+		if err := setRulePriorities(tx, priorities); err != nil {
+			return err
+		}
+		return tx.Order("priority ASC").Preload("StorageLocation").Find(&rules).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, errInvalidReorder) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to reorder sorting rules", "sorting rule reorder failed", err)
+	}
+
+	return c.JSON(MoveSortingRuleResponse{Rules: rules})
+}
+
+// errInvalidReorder marks a reorder request that doesn't list every rule exactly once
+var errInvalidReorder = errors.New("rule_ids must list every sorting rule exactly once")
+
+// Performance reports how long each rule took to evaluate during the most recent
+// resort, flagging rules whose average time per card is over the slow threshold
+func (h *SortingRulesHandler) Performance(c fiber.Ctx) error {
+	report, err := services.NewRulePerformanceService(h.db).Report(c.RequestCtx())
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch rule performance", "performance query failed", err)
+	}
+	return c.JSON(report)
+}
+
+// Helpers lists the functions and operators rule expressions can use, with examples
+func (h *SortingRulesHandler) Helpers(c fiber.Ctx) error {
+	return c.JSON(rules.Helpers)
+}
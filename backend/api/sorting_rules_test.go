@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"backend/models"
+	"backend/rules"
+	"backend/services"
 	"backend/utils"
 
 	"github.com/gofiber/fiber/v3"
@@ -32,10 +34,14 @@ func setupSortingRulesTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	handler := NewSortingRulesHandler(db)
 
 	app.Get("/sorting-rules", handler.List)
+	app.Get("/sorting-rules/helpers", handler.Helpers)
 	app.Get("/sorting-rules/:id", handler.Get)
 	app.Post("/sorting-rules", handler.Create)
 	app.Put("/sorting-rules/:id", handler.Update)
 	app.Delete("/sorting-rules/:id", handler.Delete)
+	app.Post("/sorting-rules/batch/priorities", handler.BatchUpdatePriorities)
+	app.Post("/sorting-rules/reorder", handler.Reorder)
+	app.Post("/sorting-rules/:id/move", handler.Move)
 
 	return app, db
 }
@@ -406,6 +412,47 @@ func TestSortingRulesCreate_InvalidStorageLocation(t *testing.T) {
 	}
 }
 
+func TestSortingRulesCreate_UnassignedTarget(t *testing.T) {
+	app, _ := setupSortingRulesTestApp(t)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"Unassigned location", `{"name": "Bulk", "priority": 1, "expression": "true", "storage_location_id": 0}`, http.StatusCreated},
+		{"Missing location", `{"name": "Bulk", "priority": 1, "expression": "true"}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sorting-rules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Fatalf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+			if tt.expected != http.StatusCreated {
+				return
+			}
+
+			var rule models.SortingRule
+			if err := json.NewDecoder(resp.Body).Decode(&rule); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if rule.StorageLocationID != models.UnassignedLocationID || rule.StorageLocation.Name != models.UnassignedLocationName {
+				t.Errorf("expected a rule targeting the unassigned location, got %+v", rule)
+			}
+		})
+	}
+}
+
 func TestSortingRulesCreate_EmptyName(t *testing.T) {
 	app, db := setupSortingRulesTestApp(t)
 
@@ -724,3 +771,502 @@ func TestSortingRulesDelete_DoesNotDeleteStorageLocation(t *testing.T) {
 		t.Errorf("expected storage location to remain, got count %d", count)
 	}
 }
+
+// Move endpoint tests
+
+func moveSortingRule(t *testing.T, app *fiber.App, id uint, body string) (*http.Response, MoveSortingRuleResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/sorting-rules/%d/move", id), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result MoveSortingRuleResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	return resp, result
+}
+
+func ruleNames(rules []models.SortingRule) []string {
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+func TestSortingRulesMove_UsesGap(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 10, "true", location.ID)
+	createTestRule(t, db, "Second", 20, "true", location.ID)
+	third := createTestRule(t, db, "Third", 30, "true", location.ID)
+
+	resp, result := moveSortingRule(t, app, third.ID, fmt.Sprintf(`{"after": %d}`, first.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if got := fmt.Sprint(ruleNames(result.Rules)); got != "[First Third Second]" {
+		t.Errorf("expected order [First Third Second], got %s", got)
+	}
+	// Only the moved rule changes when there is room between its neighbours
+	if result.Rules[0].Priority != 10 || result.Rules[1].Priority != 15 || result.Rules[2].Priority != 20 {
+		t.Errorf("expected priorities 10, 15, 20, got %d, %d, %d",
+			result.Rules[0].Priority, result.Rules[1].Priority, result.Rules[2].Priority)
+	}
+}
+
+func TestSortingRulesMove_ToFront(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 10, "true", location.ID)
+	second := createTestRule(t, db, "Second", 20, "true", location.ID)
+
+	resp, result := moveSortingRule(t, app, second.ID, fmt.Sprintf(`{"before": %d}`, first.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := fmt.Sprint(ruleNames(result.Rules)); got != "[Second First]" {
+		t.Errorf("expected order [Second First], got %s", got)
+	}
+	if result.Rules[0].Priority != 5 {
+		t.Errorf("expected priority 5, got %d", result.Rules[0].Priority)
+	}
+}
+
+func TestSortingRulesMove_RenumbersWithoutGap(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 1, "true", location.ID)
+	createTestRule(t, db, "Second", 2, "true", location.ID)
+	third := createTestRule(t, db, "Third", 5, "true", location.ID)
+
+	// First and Second are adjacent, so there is no room between them
+	resp, result := moveSortingRule(t, app, third.ID, fmt.Sprintf(`{"after": %d}`, first.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := fmt.Sprint(ruleNames(result.Rules)); got != "[First Third Second]" {
+		t.Errorf("expected order [First Third Second], got %s", got)
+	}
+	for i, rule := range result.Rules {
+		if expected := (i + 1) * sortingRulePriorityGap; rule.Priority != expected {
+			t.Errorf("expected %s to have priority %d, got %d", rule.Name, expected, rule.Priority)
+		}
+	}
+}
+
+func TestSortingRulesMove_ToEnd(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 1, "true", location.ID)
+	second := createTestRule(t, db, "Second", 2, "true", location.ID)
+
+	resp, result := moveSortingRule(t, app, first.ID, fmt.Sprintf(`{"after": %d}`, second.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := fmt.Sprint(ruleNames(result.Rules)); got != "[Second First]" {
+		t.Errorf("expected order [Second First], got %s", got)
+	}
+	if result.Rules[1].Priority != 2+sortingRulePriorityGap {
+		t.Errorf("expected priority %d, got %d", 2+sortingRulePriorityGap, result.Rules[1].Priority)
+	}
+}
+
+func TestSortingRulesMove_Errors(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	rule := createTestRule(t, db, "Rule", 1, "true", location.ID)
+	other := createTestRule(t, db, "Other", 2, "true", location.ID)
+
+	tests := []struct {
+		name     string
+		id       uint
+		body     string
+		expected int
+	}{
+		{"Missing anchor", rule.ID, `{}`, http.StatusBadRequest},
+		{"Both anchors", rule.ID, fmt.Sprintf(`{"before": %d, "after": %d}`, other.ID, other.ID), http.StatusBadRequest},
+		{"Relative to itself", rule.ID, fmt.Sprintf(`{"before": %d}`, rule.ID), http.StatusBadRequest},
+		{"Unknown rule", 999, fmt.Sprintf(`{"before": %d}`, other.ID), http.StatusNotFound},
+		{"Unknown anchor", rule.ID, `{"after": 999}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := moveSortingRule(t, app, tt.id, tt.body)
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+// Test endpoint tests
+
+// postRuleOrder sends a reorder or batch priorities request to path
+func postRuleOrder(t *testing.T, app *fiber.App, path, body string) (*http.Response, MoveSortingRuleResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result MoveSortingRuleResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	return resp, result
+}
+
+func TestSortingRulesReorder(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 10, "true", location.ID)
+	second := createTestRule(t, db, "Second", 20, "true", location.ID)
+	third := createTestRule(t, db, "Third", 30, "true", location.ID)
+
+	// Reversing swaps First and Third through priorities both already hold
+	resp, result := postRuleOrder(t, app, "/sorting-rules/reorder",
+		fmt.Sprintf(`{"rule_ids": [%d, %d, %d]}`, third.ID, second.ID, first.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if got := fmt.Sprint(ruleNames(result.Rules)); got != "[Third Second First]" {
+		t.Errorf("expected order [Third Second First], got %s", got)
+	}
+	for i, rule := range result.Rules {
+		if expected := (i + 1) * sortingRulePriorityGap; rule.Priority != expected {
+			t.Errorf("expected %s to have priority %d, got %d", rule.Name, expected, rule.Priority)
+		}
+	}
+}
+
+func TestSortingRulesReorder_Errors(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 10, "true", location.ID)
+	second := createTestRule(t, db, "Second", 20, "true", location.ID)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"empty", `{"rule_ids": []}`, http.StatusBadRequest},
+		{"missing a rule", fmt.Sprintf(`{"rule_ids": [%d]}`, second.ID), http.StatusBadRequest},
+		{"listed twice", fmt.Sprintf(`{"rule_ids": [%d, %d, %d]}`, second.ID, first.ID, second.ID), http.StatusBadRequest},
+		{"unknown rule", fmt.Sprintf(`{"rule_ids": [%d, %d, 999]}`, second.ID, first.ID), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, _ := postRuleOrder(t, app, "/sorting-rules/reorder", tt.body)
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+
+	// A failed reorder leaves priorities untouched
+	var rule models.SortingRule
+	db.First(&rule, second.ID)
+	if rule.Priority != 20 {
+		t.Errorf("expected priority 20, got %d", rule.Priority)
+	}
+}
+
+func TestSortingRulesBatchPriorities_Swap(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestRule(t, db, "First", 1, "true", location.ID)
+	second := createTestRule(t, db, "Second", 2, "true", location.ID)
+	createTestRule(t, db, "Third", 3, "true", location.ID)
+
+	resp, _ := postRuleOrder(t, app, "/sorting-rules/batch/priorities",
+		fmt.Sprintf(`{"updates": [{"id": %d, "priority": 2}, {"id": %d, "priority": 1}]}`, first.ID, second.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var rule models.SortingRule
+	db.First(&rule, first.ID)
+	if rule.Priority != 2 {
+		t.Errorf("expected First to have priority 2, got %d", rule.Priority)
+	}
+
+	// Colliding with each other or with a rule left out of the update is rejected
+	resp, _ = postRuleOrder(t, app, "/sorting-rules/batch/priorities",
+		fmt.Sprintf(`{"updates": [{"id": %d, "priority": 5}, {"id": %d, "priority": 5}]}`, first.ID, second.ID))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+	resp, _ = postRuleOrder(t, app, "/sorting-rules/batch/priorities",
+		fmt.Sprintf(`{"updates": [{"id": %d, "priority": 3}]}`, first.ID))
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d, got %d", http.StatusConflict, resp.StatusCode)
+	}
+}
+
+func TestSortingRules_UniquePriority(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestRule(t, db, "First", 10, "true", location.ID)
+	second := createTestRule(t, db, "Second", 20, "true", location.ID)
+
+	send := func(method, path, body string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := send(http.MethodPost, "/sorting-rules",
+		fmt.Sprintf(`{"name": "Taken", "priority": 10, "expression": "true", "storage_location_id": %d}`, location.ID))
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d creating a rule on a taken priority, got %d", http.StatusConflict, resp.StatusCode)
+	}
+	resp = send(http.MethodPut, fmt.Sprintf("/sorting-rules/%d", second.ID), `{"priority": 10}`)
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("expected status %d moving a rule onto a taken priority, got %d", http.StatusConflict, resp.StatusCode)
+	}
+	resp = send(http.MethodPut, fmt.Sprintf("/sorting-rules/%d", second.ID), `{"priority": 20, "name": "Renamed"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d keeping a rule's own priority, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Without a priority, a new rule goes after the last one
+	resp = send(http.MethodPost, "/sorting-rules",
+		fmt.Sprintf(`{"name": "Last", "expression": "true", "storage_location_id": %d}`, location.ID))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+	var created models.SortingRule
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.Priority != 20+sortingRulePriorityGap {
+		t.Errorf("expected priority %d, got %d", 20+sortingRulePriorityGap, created.Priority)
+	}
+}
+
+func TestSortingRulesTest(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+	if err := db.AutoMigrate(&models.Card{}); err != nil {
+		t.Fatalf("failed to migrate cards: %v", err)
+	}
+	handler := NewSortingRulesHandler(db)
+	app.Post("/sorting-rules/test", handler.Test)
+
+	card := models.Card{
+		ScryfallID: "bolt-id",
+		OracleID:   "bolt-oracle",
+		RawJSON:    `{"id": "bolt-id", "name": "Lightning Bolt", "rarity": "common", "prices": {"usd": "2.00", "usd_foil": "15.00"}}`,
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	location := createTestStorageLocation(t, db)
+	createTestRule(t, db, "Foils", 1, "treatment == 'foil'", location.ID)
+	createTestRule(t, db, "Commons", 2, "rarity == 'common'", location.ID)
+	createTestRule(t, db, "Everything", 3, "true", location.ID)
+	disabled := createTestRule(t, db, "Disabled", 4, "true", location.ID)
+	db.Model(&disabled).Update("enabled", false)
+
+	tests := []struct {
+		name          string
+		body          string
+		expectedRules []bool
+		expectedName  string
+	}{
+		{"Defaults to nonfoil", `{"scryfall_id": "bolt-id"}`, []bool{false, true, true}, "Commons"},
+		{"Foil treatment", `{"scryfall_id": "bolt-id", "treatment": "foil"}`, []bool{true, true, true}, "Foils"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sorting-rules/test", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			var result TestRulesResponse
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+
+			if len(result.Rules) != len(tt.expectedRules) {
+				t.Fatalf("expected %d enabled rules, got %d", len(tt.expectedRules), len(result.Rules))
+			}
+			for i, matched := range tt.expectedRules {
+				if result.Rules[i].Matched != matched {
+					t.Errorf("rule %q: expected matched %v, got %v", result.Rules[i].Name, matched, result.Rules[i].Matched)
+				}
+			}
+			if result.Destination == nil || result.Destination.ID != location.ID {
+				t.Errorf("expected destination %d, got %+v", location.ID, result.Destination)
+			}
+			if result.MatchedRule == nil || result.Rules[0].Name == "" {
+				t.Fatal("expected a matched rule")
+			}
+			for _, rule := range result.Rules {
+				if rule.RuleID == *result.MatchedRule && rule.Name != tt.expectedName {
+					t.Errorf("expected matched rule %q, got %q", tt.expectedName, rule.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSortingRulesTest_Errors(t *testing.T) {
+	app, db := setupSortingRulesTestApp(t)
+	if err := db.AutoMigrate(&models.Card{}); err != nil {
+		t.Fatalf("failed to migrate cards: %v", err)
+	}
+	handler := NewSortingRulesHandler(db)
+	app.Post("/sorting-rules/test", handler.Test)
+
+	tests := []struct {
+		name     string
+		body     string
+		expected int
+	}{
+		{"Missing scryfall_id", `{}`, http.StatusBadRequest},
+		{"Unknown card", `{"scryfall_id": "missing"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sorting-rules/test", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestPerformance_AfterResort(t *testing.T) {
+	inventoryApp, db := setupInventoryTestAppWithRules(t)
+	if err := db.AutoMigrate(&models.RulePerformance{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	app := fiber.New()
+	app.Get("/sorting-rules/performance", NewSortingRulesHandler(db).Performance)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestCard(t, db, "lotus-id", "Black Lotus", "lea", "rare", "20000")
+	rares := createTestSortingRule(t, db, "Rares", 1, "rarity == 'rare'", location.ID)
+	createTestSortingRule(t, db, "Cheap Cards", 2, "prices.usd < 5.0", location.ID)
+	createTestInventoryItem(t, db, "bolt-id", 1, nil)
+	createTestInventoryItem(t, db, "lotus-id", 1, nil)
+
+	// Before any resort there is nothing to report
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/sorting-rules/performance", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var report services.RulePerformanceReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.RecordedAt != nil || len(report.Rules) != 0 {
+		t.Errorf("expected an empty report before resorting, got %+v", report)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/inventory/resort", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	if resp, err := inventoryApp.Test(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("resort failed: %v", err)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/sorting-rules/performance", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if report.RecordedAt == nil {
+		t.Error("expected the resort time to be reported")
+	}
+
+	// Both cards reach the first rule; only the bolt falls through to the second,
+	// since the lotus already matched the same location
+	evaluations := map[uint]int64{}
+	for _, entry := range report.Rules {
+		evaluations[entry.RuleID] = entry.Evaluations
+	}
+	if len(report.Rules) != 2 || evaluations[rares.ID] != 2 {
+		t.Errorf("expected 2 rules with the first evaluated twice, got %+v", report.Rules)
+	}
+}
+
+func TestSortingRulesHelpers(t *testing.T) {
+	app, _ := setupSortingRulesTestApp(t)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/sorting-rules/helpers", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var helpers []rules.Helper
+	if err := json.NewDecoder(resp.Body).Decode(&helpers); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	names := make(map[string]bool, len(helpers))
+	for _, helper := range helpers {
+		names[helper.Name] = true
+	}
+	for _, name := range []string{"nameMatches", "typeContains", "oracleContains", "in"} {
+		if !names[name] {
+			t.Errorf("expected %s documented, got %+v", name, helpers)
+		}
+	}
+}
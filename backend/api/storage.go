@@ -4,6 +4,7 @@ package api
 
 import (
 	"backend/models"
+	"backend/services"
 	"backend/utils"
 	"errors"
 	"fmt"
@@ -16,12 +17,14 @@ import (
 
 // StorageHandler handles storage location endpoints
 type StorageHandler struct {
-	db *gorm.DB
+	db      *gorm.DB
+	dataDir string
 }
 
-// NewStorageHandler creates a new storage handler
-func NewStorageHandler(db *gorm.DB) *StorageHandler {
-	return &StorageHandler{db: db}
+// NewStorageHandler creates a new storage handler.
+// Location photos are stored under dataDir/storage-photos.
+func NewStorageHandler(db *gorm.DB, dataDir string) *StorageHandler {
+	return &StorageHandler{db: db, dataDir: dataDir}
 }
 
 // List returns storage locations with pagination
@@ -41,8 +44,7 @@ func (h *StorageHandler) List(c fiber.Ctx) error {
 			"Failed to fetch storage locations", "database query failed", err)
 	}
 
-	response := utils.NewPaginatedResponse(locations, params.Page, params.PageSize, total)
-	return c.JSON(response)
+	return utils.SendPaginated(c, locations, params.Page, params.PageSize, total)
 }
 
 // Get returns a single storage location by ID
@@ -65,8 +67,11 @@ func (h *StorageHandler) Get(c fiber.Ctx) error {
 
 // CreateStorageRequest represents the request body for creating a storage location
 type CreateStorageRequest struct {
-	Name        string             `json:"name"`
-	StorageType models.StorageType `json:"storage_type"`
+	Name                string             `json:"name"`
+	StorageType         models.StorageType `json:"storage_type"`
+	Capacity            *int               `json:"capacity,omitempty"` // 0 means unlimited
+	PhysicalDescription *string            `json:"physical_description,omitempty"`
+	ParentID            *uint              `json:"parent_id,omitempty"` // Create only; use POST /storage/:id/move to re-parent
 }
 
 // Create creates a new storage location
@@ -84,6 +89,25 @@ func (h *StorageHandler) Create(c fiber.Ctx) error {
 		Name:        req.Name,
 		StorageType: req.StorageType,
 	}
+	if req.Capacity != nil {
+		if err := utils.ValidateNonNegative(*req.Capacity, "capacity"); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		location.Capacity = *req.Capacity
+	}
+	if req.PhysicalDescription != nil {
+		location.PhysicalDescription = *req.PhysicalDescription
+	}
+	if req.ParentID != nil {
+		if ok, err := h.validateParent(c, 0, *req.ParentID); !ok {
+			return err
+		}
+		location.ParentID = req.ParentID
+	}
+
+	if err := location.ValidateStorageLocation(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
 
 	if err := h.db.WithContext(c.RequestCtx()).Create(&location).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
@@ -126,6 +150,24 @@ func (h *StorageHandler) Update(c fiber.Ctx) error {
 		}
 		location.StorageType = req.StorageType
 	}
+
+	// Update capacity if provided
+	if req.Capacity != nil {
+		if err := utils.ValidateNonNegative(*req.Capacity, "capacity"); err != nil {
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		location.Capacity = *req.Capacity
+	}
+
+	// Update physical description if provided (empty string clears it)
+	if req.PhysicalDescription != nil {
+		location.PhysicalDescription = *req.PhysicalDescription
+	}
+
+	if err := location.ValidateStorageLocation(h.db); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
 	if err := h.db.WithContext(c.RequestCtx()).Save(&location).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to update storage location", "database update failed", err)
@@ -159,25 +201,50 @@ func (h *StorageHandler) Delete(c fiber.Ctx) error {
 			"Failed to check sorting rule references", "database count failed", err)
 	}
 
+	// Check for rule group references
+	var groupCount int64
+	if err := h.db.WithContext(c.RequestCtx()).Model(&models.RuleGroup{}).
+		Where("storage_location_id = ?", id).
+		Count(&groupCount).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to check rule group references", "database count failed", err)
+	}
+
 	// Prevent deletion if there are references
-	if inventoryCount > 0 || ruleCount > 0 {
+	if inventoryCount > 0 || ruleCount > 0 || groupCount > 0 {
 		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
-			"error":              fmt.Sprintf("Cannot delete storage location: %d inventory items and %d sorting rules reference this location", inventoryCount, ruleCount),
+			"error": fmt.Sprintf("Cannot delete storage location: %d inventory items, %d sorting rules, and %d rule groups reference this location",
+				inventoryCount, ruleCount, groupCount),
 			"inventory_count":    inventoryCount,
 			"sorting_rule_count": ruleCount,
+			"rule_group_count":   groupCount,
 		})
 	}
 
-	result := h.db.WithContext(c.RequestCtx()).Delete(&models.StorageLocation{}, id)
-	if result.Error != nil {
+	var location models.StorageLocation
+	if err := h.db.WithContext(c.RequestCtx()).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		}
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
-			"Failed to delete storage location", "database delete failed", result.Error)
+			"Failed to fetch storage location", "database query failed", err)
 	}
 
-	if result.RowsAffected == 0 {
-		return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+	// Nested locations move up to the deleted location's parent
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.StorageLocation{}).Where("parent_id = ?", location.ID).
+			UpdateColumn("parent_id", location.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&location).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete storage location", "database delete failed", err)
 	}
 
+	h.removePhotoFile(location.PhotoFilename)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -189,13 +256,21 @@ type StorageLocationWithCount struct {
 	UpdatedAt   string             `json:"updated_at"`
 	Name        string             `json:"name"`
 	StorageType models.StorageType `json:"storage_type"`
-	CardCount   int                `json:"card_count"`   // Sum of quantities
-	ItemCount   int                `json:"item_count"`   // Count of distinct records
-	TotalValue  float64            `json:"total_value"`  // USD total value
+	Capacity    int                `json:"capacity"`    // 0 means unlimited
+	CardCount   int                `json:"card_count"`  // Sum of quantities
+	ItemCount   int                `json:"item_count"`  // Count of distinct records
+	TotalValue  float64            `json:"total_value"` // Total value in the preferred currency
+
+	PhysicalDescription string `json:"physical_description,omitempty"`
+	PhotoFilename       string `json:"photo_filename,omitempty"`
 }
 
-// ListWithCounts returns all storage locations with card counts, item counts, and total values
+// ListWithCounts returns all storage locations with card counts, item counts, and total values.
+// With include_unassigned=true the virtual unassigned location (ID 0) is appended
+// with the totals of items that have no location.
 func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
+	includeUnassigned := c.Query("include_unassigned") == "true"
+
 	// Step 1: Get all storage locations
 	var locations []models.StorageLocation
 	if err := h.db.WithContext(c.RequestCtx()).Order("storage_type ASC, id ASC").Find(&locations).Error; err != nil {
@@ -209,12 +284,14 @@ func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
 		ItemCount         int  `gorm:"column:item_count"`
 		CardCount         int  `gorm:"column:card_count"`
 	}
+	// Unassigned items are grouped under the virtual location ID 0
 	var counts []locationCount
-	if err := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{}).
-		Select("storage_location_id, COUNT(*) as item_count, SUM(quantity) as card_count").
-		Where("storage_location_id IS NOT NULL").
-		Group("storage_location_id").
-		Scan(&counts).Error; err != nil {
+	countQuery := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{}).
+		Select("COALESCE(storage_location_id, 0) AS storage_location_id, COUNT(*) as item_count, SUM(quantity) as card_count")
+	if !includeUnassigned {
+		countQuery = countQuery.Where("storage_location_id IS NOT NULL")
+	}
+	if err := countQuery.Group("COALESCE(storage_location_id, 0)").Scan(&counts).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to aggregate inventory counts", "query failed", err)
 	}
@@ -223,11 +300,13 @@ func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
 		countMap[lc.StorageLocationID] = lc
 	}
 
-	// Step 3: Fetch only assigned inventory for value calculation
+	// Step 3: Fetch only assigned inventory (plus unassigned if requested) for value calculation
 	var assignedInventory []models.Inventory
-	if err := h.db.WithContext(c.RequestCtx()).
-		Where("storage_location_id IS NOT NULL").
-		Find(&assignedInventory).Error; err != nil {
+	inventoryQuery := h.db.WithContext(c.RequestCtx())
+	if !includeUnassigned {
+		inventoryQuery = inventoryQuery.Where("storage_location_id IS NOT NULL")
+	}
+	if err := inventoryQuery.Find(&assignedInventory).Error; err != nil {
 		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
 			"Failed to fetch assigned inventory", "query failed", err)
 	}
@@ -236,8 +315,11 @@ func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
 	inventoryByLocation := make(map[uint][]models.Inventory)
 	scryfallIDSet := make(map[string]bool)
 	for _, inv := range assignedInventory {
-		inventoryByLocation[*inv.StorageLocationID] = append(
-			inventoryByLocation[*inv.StorageLocationID], inv)
+		locationID := models.UnassignedLocationID
+		if inv.StorageLocationID != nil {
+			locationID = *inv.StorageLocationID
+		}
+		inventoryByLocation[locationID] = append(inventoryByLocation[locationID], inv)
 		scryfallIDSet[inv.ScryfallID] = true
 	}
 
@@ -245,21 +327,24 @@ func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
 	for id := range scryfallIDSet {
 		scryfallIDs = append(scryfallIDs, id)
 	}
-	scryfallCardMap, err := models.GetScryfallCardsByIDs(h.db.WithContext(c.RequestCtx()), scryfallIDs)
+	priceMap, err := models.GetCardPricesByIDs(h.db.WithContext(c.RequestCtx()), scryfallIDs)
 	if err != nil {
-		slog.Warn("failed to fetch some cards", "component", "storage", "error", err)
+		slog.WarnContext(c.RequestCtx(), "failed to fetch card prices", "component", "storage", "error", err)
 	}
+	currency := services.PreferredCurrency(c.RequestCtx(), h.db)
 
 	// Step 5: Build results with counts and values
+	if includeUnassigned {
+		locations = append(locations, models.UnassignedLocation())
+	}
 	results := make([]StorageLocationWithCount, len(locations))
 	for i, location := range locations {
 		lc := countMap[location.ID]
 		totalValue := 0.0
 
 		for _, item := range inventoryByLocation[location.ID] {
-			if scryfallCard, ok := scryfallCardMap[item.ScryfallID]; ok {
-				price := utils.ParsePriceFromScryfall(scryfallCard.Prices, item.Treatment)
-				totalValue += price * float64(item.Quantity)
+			if prices, ok := priceMap[item.ScryfallID]; ok {
+				totalValue += prices.InCurrency(item.Treatment, currency) * float64(item.Quantity)
 			}
 		}
 
@@ -269,9 +354,13 @@ func (h *StorageHandler) ListWithCounts(c fiber.Ctx) error {
 			UpdatedAt:   location.UpdatedAt.Format(time.RFC3339),
 			Name:        location.Name,
 			StorageType: location.StorageType,
+			Capacity:    location.Capacity,
 			CardCount:   lc.CardCount,
 			ItemCount:   lc.ItemCount,
 			TotalValue:  totalValue,
+
+			PhysicalDescription: location.PhysicalDescription,
+			PhotoFilename:       location.PhotoFilename,
 		}
 	}
 
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// BinderLayout arranges a binder's contents into pages and pockets.
+// Query params: pockets (9 or 12, default 9), sort (set, name, color, or price;
+// default set), and format ("pdf" for a printable document, JSON otherwise).
+func (h *StorageHandler) BinderLayout(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	pockets := fiber.Query[int](c, "pockets", services.DefaultBinderPockets)
+	sortBy := services.BinderSort(c.Query("sort", string(services.BinderSortSet)))
+
+	format := c.Query("format", "json")
+	if format != "json" && format != "pdf" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "format must be json or pdf")
+	}
+
+	layout, err := services.BuildBinderLayout(c.RequestCtx(), h.db, uint(id), pockets, sortBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		case errors.Is(err, services.ErrNotBinder),
+			errors.Is(err, services.ErrInvalidBinderPockets),
+			errors.Is(err, services.ErrInvalidBinderSort):
+			return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build binder layout", "binder layout failed", err)
+	}
+
+	if format == "pdf" {
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="binder-%d-layout.pdf"`, layout.StorageLocationID))
+		return c.Send(layout.PDF())
+	}
+	return c.JSON(layout)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupBinderLayoutTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	handler := NewStorageHandler(db, t.TempDir())
+
+	app := fiber.New()
+	app.Get("/storage/:id/binder-layout", handler.BinderLayout)
+
+	return app, db
+}
+
+func TestStorageBinderLayout_JSON(t *testing.T) {
+	app, db := setupBinderLayoutTestApp(t)
+
+	binder := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(&binder)
+	db.Create(&models.Card{ScryfallID: "card-1", OracleID: "oracle-1", RawJSON: `{"id":"card-1","name":"Sol Ring","set":"c21","collector_number":"263"}`})
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 13, StorageLocationID: &binder.ID})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/binder-layout?pockets=12&sort=name", binder.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var layout services.BinderLayout
+	if err := json.NewDecoder(resp.Body).Decode(&layout); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if layout.PocketsPerPage != 12 || layout.Sort != services.BinderSortName || layout.TotalCards != 13 || len(layout.Pages) != 2 {
+		t.Errorf("unexpected layout: %d pockets, sort %s, %d cards on %d pages",
+			layout.PocketsPerPage, layout.Sort, layout.TotalCards, len(layout.Pages))
+	}
+}
+
+func TestStorageBinderLayout_PDF(t *testing.T) {
+	app, db := setupBinderLayoutTestApp(t)
+
+	binder := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(&binder)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/binder-layout?format=pdf", binder.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/pdf" {
+		t.Errorf("expected application/pdf, got %q", contentType)
+	}
+	if disposition := resp.Header.Get("Content-Disposition"); disposition == "" {
+		t.Error("expected a Content-Disposition header")
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !bytes.HasPrefix(body, []byte("%PDF-")) {
+		t.Error("expected a PDF body")
+	}
+}
+
+func TestStorageBinderLayout_Errors(t *testing.T) {
+	app, db := setupBinderLayoutTestApp(t)
+
+	binder := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(&binder)
+	box := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&box)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Invalid id", "/storage/abc/binder-layout", http.StatusBadRequest},
+		{"Not found", "/storage/999/binder-layout", http.StatusNotFound},
+		{"Not a binder", fmt.Sprintf("/storage/%d/binder-layout", box.ID), http.StatusBadRequest},
+		{"Unsupported pockets", fmt.Sprintf("/storage/%d/binder-layout?pockets=16", binder.ID), http.StatusBadRequest},
+		{"Unknown sort", fmt.Sprintf("/storage/%d/binder-layout?sort=rarity", binder.ID), http.StatusBadRequest},
+		{"Unknown format", fmt.Sprintf("/storage/%d/binder-layout?format=csv", binder.ID), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
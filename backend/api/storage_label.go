@@ -0,0 +1,84 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Label renders a printable label for a storage location: its name, summary stats,
+// and a QR code linking to its contents view.
+// Query params: format ("pdf", the default, or "png").
+func (h *StorageHandler) Label(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	format := c.Query("format", "pdf")
+	if format != "pdf" && format != "png" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "format must be pdf or png")
+	}
+
+	// Links point at the configured web app address, or this server when none is set
+	baseURL := services.AppBaseURL(c.RequestCtx(), h.db)
+	if baseURL == "" {
+		baseURL = c.BaseURL()
+	}
+
+	label, err := services.BuildStorageLabel(c.RequestCtx(), h.db, uint(id), baseURL)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build storage label", "storage label failed", err)
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if format == "png" {
+		body, err = label.PNG()
+		contentType = "image/png"
+	} else {
+		body, err = label.PDF()
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrQRCodeTooLong) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "app_base_url is too long to fit in a QR code")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to render storage label", "storage label render failed", err)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="storage-%d-label.%s"`, label.StorageLocationID, format))
+	return c.Send(body)
+}
+
+// ResolveLabel returns the storage location a scanned label names.
+// Query params: code (the scanned label URL, its path, or a location ID).
+func (h *StorageHandler) ResolveLabel(c fiber.Ctx) error {
+	id, err := services.ResolveStorageLabelCode(c.Query("code"))
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	var location models.StorageLocation
+	if err := h.db.WithContext(c.RequestCtx()).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to resolve label", "storage label resolve failed", err)
+	}
+	return c.JSON(location)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupStorageLabelTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Card{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewStorageHandler(db, t.TempDir())
+
+	app := fiber.New()
+	app.Get("/storage/resolve", handler.ResolveLabel)
+	app.Get("/storage/:id/label", handler.Label)
+
+	return app, db
+}
+
+func TestStorageLabel_Formats(t *testing.T) {
+	app, db := setupStorageLabelTestApp(t)
+
+	box := models.StorageLocation{Name: "Bulk", StorageType: models.Box}
+	db.Create(&box)
+
+	tests := []struct {
+		query       string
+		contentType string
+		magic       []byte
+		filename    string
+	}{
+		{"", "application/pdf", []byte("%PDF-"), "storage-1-label.pdf"},
+		{"?format=png", "image/png", []byte("\x89PNG"), "storage-1-label.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/label%s", box.ID, tt.query), nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != tt.contentType {
+				t.Errorf("expected %s, got %q", tt.contentType, contentType)
+			}
+			if disposition := resp.Header.Get("Content-Disposition"); !bytes.Contains([]byte(disposition), []byte(tt.filename)) {
+				t.Errorf("expected filename %s, got %q", tt.filename, disposition)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if !bytes.HasPrefix(body, tt.magic) {
+				t.Errorf("expected a %s body", tt.contentType)
+			}
+		})
+	}
+}
+
+func TestStorageLabel_AppBaseURL(t *testing.T) {
+	app, db := setupStorageLabelTestApp(t)
+
+	box := models.StorageLocation{Name: "Bulk", StorageType: models.Box}
+	db.Create(&box)
+	db.Create(&models.Setting{Key: "app_base_url", Value: "http://192.168.1.20:5173/"})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/label", box.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if !bytes.Contains(body, []byte("(http://192.168.1.20:5173/inventory/1) Tj")) {
+		t.Error("expected the label to link to the configured app address")
+	}
+}
+
+func TestStorageLabel_Errors(t *testing.T) {
+	app, db := setupStorageLabelTestApp(t)
+
+	box := models.StorageLocation{Name: "Bulk", StorageType: models.Box}
+	db.Create(&box)
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Invalid id", "/storage/abc/label", http.StatusBadRequest},
+		{"Not found", "/storage/999/label", http.StatusNotFound},
+		{"Unknown format", fmt.Sprintf("/storage/%d/label?format=svg", box.ID), http.StatusBadRequest},
+		{"Unrecognized code", "/storage/resolve?code=" + url.QueryEscape("http://cards.local/cards/1"), http.StatusBadRequest},
+		{"Missing code", "/storage/resolve", http.StatusBadRequest},
+		{"Unknown location", "/storage/resolve?code=" + url.QueryEscape("http://cards.local/inventory/999"), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestStorageLabel_Resolve(t *testing.T) {
+	app, db := setupStorageLabelTestApp(t)
+
+	box := models.StorageLocation{Name: "Bulk", StorageType: models.Box}
+	db.Create(&box)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/storage/resolve?code="+url.QueryEscape("http://cards.local/inventory/1"), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var location models.StorageLocation
+	if err := json.NewDecoder(resp.Body).Decode(&location); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if location.ID != box.ID || location.Name != "Bulk" {
+		t.Errorf("unexpected location %+v", location)
+	}
+}
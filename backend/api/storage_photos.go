@@ -0,0 +1,170 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// MaxStoragePhotoSize caps the size of an uploaded storage location photo
+const MaxStoragePhotoSize = 10 * 1024 * 1024
+
+// storagePhotoExtensions maps accepted image content types to file extensions
+var storagePhotoExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// photoDir is where storage location photos are kept
+func (h *StorageHandler) photoDir() string {
+	return filepath.Join(h.dataDir, "storage-photos")
+}
+
+// removePhotoFile deletes a stored photo, logging rather than failing on errors
+func (h *StorageHandler) removePhotoFile(filename string) {
+	if filename == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(h.photoDir(), filename)); err != nil && !os.IsNotExist(err) {
+		slog.Warn("failed to remove storage photo", "component", "storage", "file", filename, "error", err)
+	}
+}
+
+// findLocation loads the storage location for the :id route parameter.
+// When ok is false the error response has already been written and err should be returned.
+func (h *StorageHandler) findLocation(c fiber.Ctx) (models.StorageLocation, bool, error) {
+	var location models.StorageLocation
+
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return location, false, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	if err := h.db.WithContext(c.RequestCtx()).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return location, false, utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		}
+		return location, false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch storage location", "database query failed", err)
+	}
+	return location, true, nil
+}
+
+// UploadPhoto stores a photo of the physical location from a multipart "photo"
+// field, replacing any previous photo. JPEG, PNG, GIF, and WebP are accepted.
+func (h *StorageHandler) UploadPhoto(c fiber.Ctx) error {
+	location, ok, err := h.findLocation(c)
+	if !ok {
+		return err
+	}
+
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "photo is required")
+	}
+	if fileHeader.Size > MaxStoragePhotoSize {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("photo too large (max %d MB)", MaxStoragePhotoSize/(1024*1024)))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to read uploaded photo", "opening upload failed", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxStoragePhotoSize+1))
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to read uploaded photo", "reading upload failed", err)
+	}
+
+	// Trust the content, not the client-supplied file name or header
+	ext, ok := storagePhotoExtensions[http.DetectContentType(data)]
+	if !ok {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "photo must be a JPEG, PNG, GIF, or WebP image")
+	}
+
+	if err := os.MkdirAll(h.photoDir(), 0o755); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save photo", "creating photo directory failed", err)
+	}
+
+	filename := fmt.Sprintf("%d%s", location.ID, ext)
+	tmpPath := filepath.Join(h.photoDir(), filename+".tmp")
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save photo", "writing photo failed", err)
+	}
+	if err := os.Rename(tmpPath, filepath.Join(h.photoDir(), filename)); err != nil {
+		os.Remove(tmpPath)
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save photo", "renaming photo failed", err)
+	}
+
+	if location.PhotoFilename != filename {
+		h.removePhotoFile(location.PhotoFilename)
+	}
+
+	location.PhotoFilename = filename
+	if err := h.db.WithContext(c.RequestCtx()).Save(&location).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update storage location", "database update failed", err)
+	}
+
+	return c.JSON(location)
+}
+
+// GetPhoto returns the photo of a storage location
+func (h *StorageHandler) GetPhoto(c fiber.Ctx) error {
+	location, ok, err := h.findLocation(c)
+	if !ok {
+		return err
+	}
+
+	if location.PhotoFilename == "" {
+		return utils.ReturnError(c, fiber.StatusNotFound, "photo not found")
+	}
+
+	photoPath := filepath.Join(h.photoDir(), location.PhotoFilename)
+	if _, err := os.Stat(photoPath); os.IsNotExist(err) {
+		return utils.ReturnError(c, fiber.StatusNotFound, "photo not found")
+	}
+
+	c.Set("Cache-Control", "no-cache")
+	return c.SendFile(photoPath)
+}
+
+// DeletePhoto removes the photo of a storage location
+func (h *StorageHandler) DeletePhoto(c fiber.Ctx) error {
+	location, ok, err := h.findLocation(c)
+	if !ok {
+		return err
+	}
+
+	if location.PhotoFilename == "" {
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+
+	previous := location.PhotoFilename
+	location.PhotoFilename = ""
+	if err := h.db.WithContext(c.RequestCtx()).Save(&location).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to update storage location", "database update failed", err)
+	}
+	h.removePhotoFile(previous)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
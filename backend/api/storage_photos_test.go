@@ -0,0 +1,161 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"backend/models"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// testPNG is the 8-byte PNG signature followed by an IHDR chunk header, enough for content sniffing
+var testPNG = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+func setupStoragePhotoTestApp(t *testing.T) (*fiber.App, *gorm.DB, string) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.SortingRule{}, &models.RuleGroup{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	dataDir := t.TempDir()
+	handler := NewStorageHandler(db, dataDir)
+
+	app := fiber.New()
+	app.Delete("/storage/:id", handler.Delete)
+	app.Get("/storage/:id/photo", handler.GetPhoto)
+	app.Put("/storage/:id/photo", handler.UploadPhoto)
+	app.Delete("/storage/:id/photo", handler.DeletePhoto)
+
+	return app, db, dataDir
+}
+
+func newPhotoUploadRequest(t *testing.T, locationID uint, data []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("photo", "shelf.png")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/storage/%d/photo", locationID), &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestStoragePhoto_UploadGetDelete(t *testing.T) {
+	app, db, dataDir := setupStoragePhotoTestApp(t)
+	location := createTestLocation(t, db, models.Box)
+
+	resp, err := app.Test(newPhotoUploadRequest(t, location.ID, testPNG))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result models.StorageLocation
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expectedFile := fmt.Sprintf("%d.png", location.ID)
+	if result.PhotoFilename != expectedFile {
+		t.Errorf("expected photo filename %q, got %q", expectedFile, result.PhotoFilename)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/photo", location.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "image/png" {
+		t.Errorf("expected content type image/png, got %q", contentType)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/storage/%d/photo", location.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "storage-photos", expectedFile)); !os.IsNotExist(err) {
+		t.Error("expected photo file to be removed")
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, fmt.Sprintf("/storage/%d/photo", location.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestStoragePhoto_RejectsNonImage(t *testing.T) {
+	app, db, _ := setupStoragePhotoTestApp(t)
+	location := createTestLocation(t, db, models.Box)
+
+	resp, err := app.Test(newPhotoUploadRequest(t, location.ID, []byte("<html>not an image</html>")))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestStoragePhoto_LocationNotFound(t *testing.T) {
+	app, _, _ := setupStoragePhotoTestApp(t)
+
+	resp, err := app.Test(newPhotoUploadRequest(t, 999, testPNG))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestStoragePhoto_RemovedWithLocation(t *testing.T) {
+	app, db, dataDir := setupStoragePhotoTestApp(t)
+	location := createTestLocation(t, db, models.Box)
+
+	if _, err := app.Test(newPhotoUploadRequest(t, location.ID, testPNG)); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/storage/%d", location.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	if _, err := os.Stat(filepath.Join(dataDir, "storage-photos", fmt.Sprintf("%d.png", location.ID))); !os.IsNotExist(err) {
+		t.Error("expected photo file to be removed with the location")
+	}
+}
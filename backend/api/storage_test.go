@@ -24,12 +24,12 @@ func setupTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.SortingRule{}); err != nil {
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.SortingRule{}, &models.RuleGroup{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
 	app := fiber.New()
-	handler := NewStorageHandler(db)
+	handler := NewStorageHandler(db, t.TempDir())
 
 	app.Get("/storage", handler.List)
 	app.Get("/storage/:id", handler.Get)
@@ -303,6 +303,48 @@ func TestCreate_InvalidType(t *testing.T) {
 	}
 }
 
+func TestCreate_WithCapacity(t *testing.T) {
+	app, _ := setupTestApp(t)
+
+	tests := []struct {
+		name             string
+		body             string
+		expectedStatus   int
+		expectedCapacity int
+	}{
+		{name: "Capacity set", body: `{"name": "Bulk Box", "storage_type": "Box", "capacity": 800}`, expectedStatus: http.StatusCreated, expectedCapacity: 800},
+		{name: "Negative capacity", body: `{"name": "Bulk Box", "storage_type": "Box", "capacity": -5}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/storage", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+			if tt.expectedStatus != http.StatusCreated {
+				return
+			}
+
+			var result models.StorageLocation
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.Capacity != tt.expectedCapacity {
+				t.Errorf("expected capacity %d, got %d", tt.expectedCapacity, result.Capacity)
+			}
+		})
+	}
+}
+
 func TestCreate_InvalidJSON(t *testing.T) {
 	app, _ := setupTestApp(t)
 
@@ -588,3 +630,84 @@ func TestDelete_WithBothReferences(t *testing.T) {
 		t.Errorf("expected location to still exist, got count %d", count)
 	}
 }
+
+func TestUpdate_PhysicalDescription(t *testing.T) {
+	app, db := setupTestApp(t)
+	location := createTestLocation(t, db, models.Box)
+
+	body := `{"physical_description": "Hall closet, top shelf, red box"}`
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/storage/%d", location.ID), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result models.StorageLocation
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.PhysicalDescription != "Hall closet, top shelf, red box" {
+		t.Errorf("expected physical description to be saved, got %q", result.PhysicalDescription)
+	}
+	if result.Name != location.Name {
+		t.Errorf("expected name to be unchanged, got %q", result.Name)
+	}
+}
+
+func TestListWithCounts_IncludeUnassigned(t *testing.T) {
+	_, db := setupTestApp(t)
+	if err := db.AutoMigrate(&models.Card{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	app := fiber.New()
+	app.Get("/storage/with-counts", NewStorageHandler(db, t.TempDir()).ListWithCounts)
+
+	box := createTestLocation(t, db, models.Box)
+	db.Create(&models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 2, StorageLocationID: &box.ID})
+	db.Create(&models.Inventory{ScryfallID: "card-2", OracleID: "oracle-2", Quantity: 3})
+	db.Create(&models.Inventory{ScryfallID: "card-3", OracleID: "oracle-3", Quantity: 4})
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Real locations only", "/storage/with-counts", 1},
+		{"With unassigned", "/storage/with-counts?include_unassigned=true", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var locations []StorageLocationWithCount
+			if err := json.NewDecoder(resp.Body).Decode(&locations); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(locations) != tt.expected {
+				t.Fatalf("expected %d locations, got %d", tt.expected, len(locations))
+			}
+			if locations[0].ID != box.ID || locations[0].CardCount != 2 {
+				t.Errorf("unexpected box totals: %+v", locations[0])
+			}
+			if tt.expected == 2 {
+				unassigned := locations[1]
+				if unassigned.ID != models.UnassignedLocationID || unassigned.Name != models.UnassignedLocationName ||
+					unassigned.CardCount != 7 || unassigned.ItemCount != 2 {
+					t.Errorf("unexpected unassigned totals: %+v", unassigned)
+				}
+			}
+		})
+	}
+}
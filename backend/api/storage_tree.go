@@ -0,0 +1,185 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// StorageLocationNode is a storage location with the locations nested inside it
+// tygo:export
+type StorageLocationNode struct {
+	models.StorageLocation
+	CardCount      int                   `json:"card_count"`       // Cards directly in this location
+	TotalCardCount int                   `json:"total_card_count"` // Cards in this location and every nested one
+	Children       []StorageLocationNode `json:"children"`
+}
+
+// MoveStorageRequest represents the request body for moving a storage location subtree
+type MoveStorageRequest struct {
+	ParentID *uint `json:"parent_id"` // nil moves the location to the top level
+}
+
+// Tree returns every storage location arranged by nesting, with card counts
+func (h *StorageHandler) Tree(c fiber.Ctx) error {
+	var locations []models.StorageLocation
+	if err := h.db.WithContext(c.RequestCtx()).Order("name ASC, id ASC").Find(&locations).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch storage locations", "query failed", err)
+	}
+
+	var counts []struct {
+		StorageLocationID uint
+		CardCount         int
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Model(&models.Inventory{}).
+		Select("storage_location_id, SUM(quantity) AS card_count").
+		Where("storage_location_id IS NOT NULL").
+		Group("storage_location_id").
+		Scan(&counts).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to aggregate inventory counts", "query failed", err)
+	}
+	countMap := make(map[uint]int, len(counts))
+	for _, row := range counts {
+		countMap[row.StorageLocationID] = row.CardCount
+	}
+
+	return c.JSON(buildStorageTree(locations, countMap))
+}
+
+// buildStorageTree nests locations under their parents. Locations whose parent no
+// longer exists are treated as top-level so they never disappear from the tree.
+func buildStorageTree(locations []models.StorageLocation, counts map[uint]int) []StorageLocationNode {
+	exists := make(map[uint]bool, len(locations))
+	for _, location := range locations {
+		exists[location.ID] = true
+	}
+
+	children := map[uint][]models.StorageLocation{}
+	var roots []models.StorageLocation
+	for _, location := range locations {
+		if location.ParentID != nil && exists[*location.ParentID] {
+			children[*location.ParentID] = append(children[*location.ParentID], location)
+		} else {
+			roots = append(roots, location)
+		}
+	}
+
+	visited := map[uint]bool{}
+	var build func(location models.StorageLocation) StorageLocationNode
+	build = func(location models.StorageLocation) StorageLocationNode {
+		visited[location.ID] = true
+		node := StorageLocationNode{
+			StorageLocation: location,
+			CardCount:       counts[location.ID],
+			TotalCardCount:  counts[location.ID],
+			Children:        []StorageLocationNode{},
+		}
+		for _, child := range children[location.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			childNode := build(child)
+			node.TotalCardCount += childNode.TotalCardCount
+			node.Children = append(node.Children, childNode)
+		}
+		return node
+	}
+
+	tree := make([]StorageLocationNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree
+}
+
+// Move re-parents a storage location, carrying everything nested inside it along
+func (h *StorageHandler) Move(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var req MoveStorageRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var location models.StorageLocation
+	if err := h.db.WithContext(c.RequestCtx()).First(&location, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "storage location not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch storage location", "database query failed", err)
+	}
+
+	if req.ParentID != nil {
+		if ok, err := h.validateParent(c, location.ID, *req.ParentID); !ok {
+			return err
+		}
+	}
+
+	// UpdateColumn: the other fields are unchanged, so there is nothing to validate
+	if err := h.db.WithContext(c.RequestCtx()).Model(&location).UpdateColumn("parent_id", req.ParentID).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to move storage location", "database update failed", err)
+	}
+	location.ParentID = req.ParentID
+
+	slog.InfoContext(c.RequestCtx(), "moved storage location", "component", "storage", "storage_location_id", location.ID, "parent_id", req.ParentID)
+	return c.JSON(location)
+}
+
+// validateParent checks that parentID exists and is neither id nor nested inside it.
+// When ok is false the error response has already been written and err should be returned.
+func (h *StorageHandler) validateParent(c fiber.Ctx, id, parentID uint) (bool, error) {
+	if parentID == id {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, "a storage location cannot be its own parent")
+	}
+
+	var parent models.StorageLocation
+	if err := h.db.WithContext(c.RequestCtx()).First(&parent, parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, utils.ReturnError(c, fiber.StatusBadRequest, "parent storage location not found")
+		}
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to validate parent location", "parent lookup failed", err)
+	}
+
+	// New locations have nothing nested inside them yet
+	if id == 0 {
+		return true, nil
+	}
+	descendants, err := models.StorageLocationDescendantIDs(h.db.WithContext(c.RequestCtx()), id)
+	if err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to validate parent location", "descendant lookup failed", err)
+	}
+	if slices.Contains(descendants, parentID) {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, "cannot move a storage location inside one of its own nested locations")
+	}
+	return true, nil
+}
+
+// locationWithDescendants parses a storage_location_id filter and returns it along
+// with every location nested under it, for filters spanning a whole subtree
+func locationWithDescendants(db *gorm.DB, locationID string) ([]uint, error) {
+	id, err := strconv.ParseUint(locationID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing storage_location_id: %w", err)
+	}
+	descendants, err := models.StorageLocationDescendantIDs(db, uint(id))
+	if err != nil {
+		return nil, err
+	}
+	return append([]uint{uint(id)}, descendants...), nil
+}
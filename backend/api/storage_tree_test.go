@@ -0,0 +1,261 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+func setupStorageTreeTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+	app, db := setupTestApp(t)
+	handler := NewStorageHandler(db, t.TempDir())
+	app.Get("/tree", handler.Tree)
+	app.Post("/storage/:id/move", handler.Move)
+	return app, db
+}
+
+func createNestedLocation(t *testing.T, db *gorm.DB, name string, parentID *uint) models.StorageLocation {
+	t.Helper()
+	location := models.StorageLocation{Name: name, StorageType: models.Box, ParentID: parentID}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	return location
+}
+
+func moveLocation(t *testing.T, app *fiber.App, id uint, body string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/storage/%d/move", id), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestTree(t *testing.T) {
+	app, db := setupStorageTreeTestApp(t)
+
+	shelf := createNestedLocation(t, db, "Shelf", nil)
+	box := createNestedLocation(t, db, "Box", &shelf.ID)
+	section := createNestedLocation(t, db, "Section", &box.ID)
+	createNestedLocation(t, db, "Binder", nil)
+
+	createTestInventoryItem(t, db, "a", 3, &shelf.ID)
+	createTestInventoryItem(t, db, "b", 5, &section.ID)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/tree", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var tree []StorageLocationNode
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(tree) != 2 || tree[0].Name != "Binder" || tree[1].Name != "Shelf" {
+		t.Fatalf("expected Binder and Shelf at the top level, got %+v", tree)
+	}
+
+	root := tree[1]
+	if root.CardCount != 3 || root.TotalCardCount != 8 {
+		t.Errorf("expected shelf to hold 3 cards directly and 8 in total, got %d and %d", root.CardCount, root.TotalCardCount)
+	}
+	if len(root.Children) != 1 || root.Children[0].ID != box.ID {
+		t.Fatalf("expected box nested under shelf, got %+v", root.Children)
+	}
+	if len(root.Children[0].Children) != 1 || root.Children[0].Children[0].ID != section.ID {
+		t.Errorf("expected section nested under box, got %+v", root.Children[0].Children)
+	}
+	if root.Children[0].TotalCardCount != 5 {
+		t.Errorf("expected box total of 5, got %d", root.Children[0].TotalCardCount)
+	}
+}
+
+func TestBuildStorageTree_OrphanedAndCyclicLocations(t *testing.T) {
+	missing := uint(99)
+	one, two := uint(1), uint(2)
+	locations := []models.StorageLocation{
+		{BaseModel: models.BaseModel{ID: 1}, Name: "A", ParentID: &two},
+		{BaseModel: models.BaseModel{ID: 2}, Name: "B", ParentID: &one},
+		{BaseModel: models.BaseModel{ID: 3}, Name: "Orphan", ParentID: &missing},
+	}
+
+	tree := buildStorageTree(locations, map[uint]int{})
+	if len(tree) != 1 || tree[0].Name != "Orphan" {
+		t.Errorf("expected only the orphan at the top level, got %+v", tree)
+	}
+}
+
+func TestMove(t *testing.T) {
+	app, db := setupStorageTreeTestApp(t)
+
+	shelf := createNestedLocation(t, db, "Shelf", nil)
+	box := createNestedLocation(t, db, "Box", nil)
+	section := createNestedLocation(t, db, "Section", &box.ID)
+
+	resp := moveLocation(t, app, box.ID, fmt.Sprintf(`{"parent_id": %d}`, shelf.ID))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	descendants, err := models.StorageLocationDescendantIDs(db, shelf.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch descendants: %v", err)
+	}
+	if len(descendants) != 2 || descendants[0] != box.ID || descendants[1] != section.ID {
+		t.Errorf("expected box and section under shelf, got %v", descendants)
+	}
+
+	// Moving back to the top level
+	resp = moveLocation(t, app, box.ID, `{"parent_id": null}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var moved models.StorageLocation
+	if err := db.First(&moved, box.ID).Error; err != nil {
+		t.Fatalf("failed to reload box: %v", err)
+	}
+	if moved.ParentID != nil {
+		t.Errorf("expected box at the top level, got parent %d", *moved.ParentID)
+	}
+}
+
+func TestMove_Validation(t *testing.T) {
+	app, db := setupStorageTreeTestApp(t)
+
+	box := createNestedLocation(t, db, "Box", nil)
+	section := createNestedLocation(t, db, "Section", &box.ID)
+
+	tests := []struct {
+		name     string
+		id       uint
+		body     string
+		expected int
+	}{
+		{"Own parent", box.ID, fmt.Sprintf(`{"parent_id": %d}`, box.ID), http.StatusBadRequest},
+		{"Into own descendant", box.ID, fmt.Sprintf(`{"parent_id": %d}`, section.ID), http.StatusBadRequest},
+		{"Unknown parent", box.ID, `{"parent_id": 999}`, http.StatusBadRequest},
+		{"Unknown location", 999, `{"parent_id": null}`, http.StatusNotFound},
+		{"Invalid body", box.ID, `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := moveLocation(t, app, tt.id, tt.body)
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestCreate_WithParent(t *testing.T) {
+	app, db := setupStorageTreeTestApp(t)
+	shelf := createNestedLocation(t, db, "Shelf", nil)
+
+	body := fmt.Sprintf(`{"name": "Box", "storage_type": "Box", "parent_id": %d}`, shelf.ID)
+	req := httptest.NewRequest(http.MethodPost, "/storage", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var created models.StorageLocation
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ParentID == nil || *created.ParentID != shelf.ID {
+		t.Errorf("expected parent %d, got %v", shelf.ID, created.ParentID)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/storage", bytes.NewBufferString(`{"name": "Box", "storage_type": "Box", "parent_id": 999}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status %d for unknown parent, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestDelete_ReparentsChildren(t *testing.T) {
+	app, db := setupStorageTreeTestApp(t)
+
+	shelf := createNestedLocation(t, db, "Shelf", nil)
+	box := createNestedLocation(t, db, "Box", &shelf.ID)
+	section := createNestedLocation(t, db, "Section", &box.ID)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/storage/%d", box.ID), nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	var reloaded models.StorageLocation
+	if err := db.First(&reloaded, section.ID).Error; err != nil {
+		t.Fatalf("failed to reload section: %v", err)
+	}
+	if reloaded.ParentID == nil || *reloaded.ParentID != shelf.ID {
+		t.Errorf("expected section moved up to shelf, got parent %v", reloaded.ParentID)
+	}
+}
+
+func TestInventoryList_IncludeDescendants(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+
+	shelf := createNestedLocation(t, db, "Shelf", nil)
+	box := createNestedLocation(t, db, "Box", &shelf.ID)
+	other := createNestedLocation(t, db, "Other", nil)
+
+	createTestInventoryItem(t, db, "on-shelf", 1, &shelf.ID)
+	createTestInventoryItem(t, db, "in-box", 1, &box.ID)
+	createTestInventoryItem(t, db, "elsewhere", 1, &other.ID)
+
+	tests := []struct {
+		query    string
+		expected int64
+	}{
+		{fmt.Sprintf("storage_location_id=%d", shelf.ID), 1},
+		{fmt.Sprintf("storage_location_id=%d&include_descendants=true", shelf.ID), 2},
+		{fmt.Sprintf("storage_location_id=%d&include_descendants=true", box.ID), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/inventory?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+			var result utils.PaginatedResponse[models.Inventory]
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if result.TotalItems != tt.expected {
+				t.Errorf("expected %d items, got %d", tt.expected, result.TotalItems)
+			}
+		})
+	}
+}
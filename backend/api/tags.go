@@ -0,0 +1,310 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// TagsHandler handles tag endpoints and tagging inventory items
+type TagsHandler struct {
+	db *gorm.DB
+}
+
+// NewTagsHandler creates a new tags handler
+func NewTagsHandler(db *gorm.DB) *TagsHandler {
+	return &TagsHandler{db: db}
+}
+
+// List returns tags with pagination, ordered by name, with how many items carry each
+func (h *TagsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.Tag{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count tags", "database count failed", err)
+	}
+
+	var tags []models.Tag
+	if err := query.Order("name ASC").
+		Offset(utils.CalculateOffset(params.Page, params.PageSize)).
+		Limit(params.PageSize).
+		Find(&tags).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch tags", "database query failed", err)
+	}
+
+	if err := h.annotateItemCounts(c, tags); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count tagged items", "item count query failed", err)
+	}
+
+	return utils.SendPaginated(c, tags, params.Page, params.PageSize, total)
+}
+
+// Get returns a single tag by ID
+func (h *TagsHandler) Get(c fiber.Ctx) error {
+	tag, err := h.findTag(c, fiber.Params[int](c, "id"))
+	if err != nil || tag == nil {
+		return err
+	}
+	return h.sendTag(c, fiber.StatusOK, tag)
+}
+
+// TagRequest represents the request body for creating or renaming a tag
+// tygo:export
+type TagRequest struct {
+	Name string `json:"name"` // Trimmed and lower-cased
+}
+
+// Create creates a new tag
+func (h *TagsHandler) Create(c fiber.Ctx) error {
+	var req TagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	tag := models.Tag{Name: models.NormalizeTagName(req.Name)}
+	if ok, err := h.saveTag(c, &tag); !ok {
+		return err
+	}
+	return h.sendTag(c, fiber.StatusCreated, &tag)
+}
+
+// Update renames a tag. Rule expressions calling hasTag with the old name are not
+// rewritten.
+func (h *TagsHandler) Update(c fiber.Ctx) error {
+	tag, err := h.findTag(c, fiber.Params[int](c, "id"))
+	if err != nil || tag == nil {
+		return err
+	}
+
+	var req TagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	tag.Name = models.NormalizeTagName(req.Name)
+	if ok, err := h.saveTag(c, tag); !ok {
+		return err
+	}
+	return h.sendTag(c, fiber.StatusOK, tag)
+}
+
+// Delete deletes a tag, removing it from every item that carries it
+func (h *TagsHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var deleted int64
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", id).Delete(&models.InventoryTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Tag{}, id)
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete tag", "database delete failed", err)
+	}
+	if deleted == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "tag not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// AddInventoryTagRequest represents the request body for tagging an inventory item
+// tygo:export
+type AddInventoryTagRequest struct {
+	TagID uint `json:"tag_id"`
+}
+
+// InventoryTagsResponse lists the tags on an inventory item after a change
+// tygo:export
+type InventoryTagsResponse struct {
+	InventoryID uint     `json:"inventory_id"`
+	Tags        []string `json:"tags"`
+}
+
+// AddToItem tags an inventory item. Tagging an item that already carries the tag
+// changes nothing.
+func (h *TagsHandler) AddToItem(c fiber.Ctx) error {
+	item, err := h.findItem(c)
+	if err != nil || item == nil {
+		return err
+	}
+
+	var req AddInventoryTagRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+	if req.TagID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "tag_id is required")
+	}
+
+	var tag models.Tag
+	if err := h.db.WithContext(c.RequestCtx()).First(&tag, req.TagID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusBadRequest, "tag not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch tag", "database query failed", err)
+	}
+
+	link := models.InventoryTag{InventoryID: item.ID, TagID: tag.ID}
+	if err := h.db.WithContext(c.RequestCtx()).
+		Where(models.InventoryTag{InventoryID: item.ID, TagID: tag.ID}).
+		FirstOrCreate(&link).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to tag inventory item", "database insert failed", err)
+	}
+
+	return h.sendItemTags(c, item.ID)
+}
+
+// RemoveFromItem removes a tag from an inventory item
+func (h *TagsHandler) RemoveFromItem(c fiber.Ctx) error {
+	item, err := h.findItem(c)
+	if err != nil || item == nil {
+		return err
+	}
+	tagID := fiber.Params[int](c, "tag_id")
+	if tagID == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid tag id")
+	}
+
+	result := h.db.WithContext(c.RequestCtx()).
+		Where("inventory_id = ? AND tag_id = ?", item.ID, tagID).
+		Delete(&models.InventoryTag{})
+	if result.Error != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to untag inventory item", "database delete failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "inventory item does not have this tag")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findTag loads a tag by ID. When it returns a nil tag the error response has
+// already been written and err should be returned.
+func (h *TagsHandler) findTag(c fiber.Ctx, id int) (*models.Tag, error) {
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var tag models.Tag
+	if err := h.db.WithContext(c.RequestCtx()).First(&tag, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "tag not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch tag", "database query failed", err)
+	}
+	return &tag, nil
+}
+
+// findItem loads the inventory item named by the id path param. When it returns a
+// nil item the error response has already been written and err should be returned.
+func (h *TagsHandler) findItem(c fiber.Ctx) (*models.Inventory, error) {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var item models.Inventory
+	if err := h.db.WithContext(c.RequestCtx()).First(&item, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "inventory item not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch inventory item", "database query failed", err)
+	}
+	return &item, nil
+}
+
+// saveTag validates and stores a new or renamed tag. When it returns false the error
+// response has already been written.
+func (h *TagsHandler) saveTag(c fiber.Ctx, tag *models.Tag) (bool, error) {
+	if err := tag.ValidateTag(h.db); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Save(tag).Error; err != nil {
+		if isDuplicateError(err) {
+			return false, utils.ReturnError(c, fiber.StatusConflict, "a tag with this name already exists")
+		}
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save tag", "database save failed", err)
+	}
+	return true, nil
+}
+
+// sendTag responds with a tag and its item count
+func (h *TagsHandler) sendTag(c fiber.Ctx, status int, tag *models.Tag) error {
+	tags := []models.Tag{*tag}
+	if err := h.annotateItemCounts(c, tags); err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count tagged items", "item count query failed", err)
+	}
+	return c.Status(status).JSON(tags[0])
+}
+
+// sendItemTags responds with the names of the tags on an inventory item
+func (h *TagsHandler) sendItemTags(c fiber.Ctx, inventoryID uint) error {
+	names, err := models.GetInventoryTagNames(h.db.WithContext(c.RequestCtx()), []uint{inventoryID})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch inventory tags", "tag query failed", err)
+	}
+	tags := names[inventoryID]
+	if tags == nil {
+		tags = []string{}
+	}
+	return c.JSON(InventoryTagsResponse{InventoryID: inventoryID, Tags: tags})
+}
+
+// annotateItemCounts fills in ItemCount for each tag, counting items outside the trash
+func (h *TagsHandler) annotateItemCounts(c fiber.Ctx, tags []models.Tag) error {
+	ids := make([]uint, len(tags))
+	for i, tag := range tags {
+		ids[i] = tag.ID
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	type row struct {
+		TagID uint
+		Count int64
+	}
+	var rows []row
+	if err := h.db.WithContext(c.RequestCtx()).Table("inventory_tags").
+		Select("inventory_tags.tag_id AS tag_id, COUNT(*) AS count").
+		Joins("JOIN inventories ON inventories.id = inventory_tags.inventory_id AND inventories.deleted_at IS NULL").
+		Where("inventory_tags.tag_id IN ?", ids).
+		Group("inventory_tags.tag_id").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, r := range rows {
+		counts[r.TagID] = r.Count
+	}
+	for i := range tags {
+		tags[i].ItemCount = counts[tags[i].ID]
+	}
+	return nil
+}
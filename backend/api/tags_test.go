@@ -0,0 +1,278 @@
+package api
+
+import (
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTagsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewTagsHandler(db)
+
+	app := fiber.New()
+	app.Get("/tags", handler.List)
+	app.Get("/tags/:id", handler.Get)
+	app.Post("/tags", handler.Create)
+	app.Put("/tags/:id", handler.Update)
+	app.Delete("/tags/:id", handler.Delete)
+	app.Post("/inventory/:id/tags", handler.AddToItem)
+	app.Delete("/inventory/:id/tags/:tag_id", handler.RemoveFromItem)
+
+	return app, db
+}
+
+func TestTagsCreate(t *testing.T) {
+	app, db := setupTagsTestApp(t)
+	db.Create(&models.Tag{Name: "for-trade"})
+
+	tests := []struct {
+		name     string
+		body     TagRequest
+		expected int
+	}{
+		{"valid", TagRequest{Name: "commander-staple"}, fiber.StatusCreated},
+		{"normalized", TagRequest{Name: " Reserved_List "}, fiber.StatusCreated},
+		{"empty", TagRequest{Name: "  "}, fiber.StatusBadRequest},
+		{"spaces", TagRequest{Name: "commander staple"}, fiber.StatusBadRequest},
+		{"duplicate ignoring case", TagRequest{Name: "For-Trade"}, fiber.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/tags", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+
+	var stored models.Tag
+	if err := db.Where("name = ?", "reserved_list").First(&stored).Error; err != nil {
+		t.Errorf("expected the tag name trimmed and lower-cased: %v", err)
+	}
+}
+
+func TestTagsUpdate(t *testing.T) {
+	app, db := setupTagsTestApp(t)
+	trade := models.Tag{Name: "trade"}
+	db.Create(&trade)
+	db.Create(&models.Tag{Name: "keep"})
+
+	status, body := sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/tags/%d", trade.ID), TagRequest{Name: "For-Trade"})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+	var renamed models.Tag
+	if err := json.Unmarshal(body, &renamed); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if renamed.Name != "for-trade" {
+		t.Errorf("expected the renamed tag, got %+v", renamed)
+	}
+
+	if status, _ := sendPredicateRequest(t, app, "PUT", fmt.Sprintf("/tags/%d", trade.ID), TagRequest{Name: "keep"}); status != fiber.StatusConflict {
+		t.Errorf("expected status %d, got %d", fiber.StatusConflict, status)
+	}
+	if status, _ := sendPredicateRequest(t, app, "PUT", "/tags/999", TagRequest{Name: "other"}); status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+}
+
+func TestTagsItemTagging(t *testing.T) {
+	app, db := setupTagsTestApp(t)
+	staple := models.Tag{Name: "commander-staple"}
+	db.Create(&staple)
+	item := createTestInventoryItem(t, db, "sol-ring", 1, nil)
+	trashed := createTestInventoryItem(t, db, "mana-crypt", 1, nil)
+	db.Create(&models.InventoryTag{InventoryID: trashed.ID, TagID: staple.ID})
+	db.Delete(&trashed)
+
+	// Tagging twice leaves one tag on the item
+	for range 2 {
+		status, body := sendPredicateRequest(t, app, "POST", fmt.Sprintf("/inventory/%d/tags", item.ID), AddInventoryTagRequest{TagID: staple.ID})
+		if status != fiber.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+		}
+		var result InventoryTagsResponse
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		if !slices.Equal(result.Tags, []string{"commander-staple"}) {
+			t.Errorf("expected the item tagged once, got %v", result.Tags)
+		}
+	}
+
+	// The trashed item isn't counted
+	status, body := sendPredicateRequest(t, app, "GET", fmt.Sprintf("/tags/%d", staple.ID), nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+	var tag models.Tag
+	if err := json.Unmarshal(body, &tag); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if tag.ItemCount != 1 {
+		t.Errorf("expected 1 tagged item, got %d", tag.ItemCount)
+	}
+
+	errorTests := []struct {
+		name     string
+		method   string
+		path     string
+		body     interface{}
+		expected int
+	}{
+		{"unknown item", "POST", "/inventory/999/tags", AddInventoryTagRequest{TagID: staple.ID}, fiber.StatusNotFound},
+		{"trashed item", "POST", fmt.Sprintf("/inventory/%d/tags", trashed.ID), AddInventoryTagRequest{TagID: staple.ID}, fiber.StatusNotFound},
+		{"missing tag id", "POST", fmt.Sprintf("/inventory/%d/tags", item.ID), AddInventoryTagRequest{}, fiber.StatusBadRequest},
+		{"unknown tag", "POST", fmt.Sprintf("/inventory/%d/tags", item.ID), AddInventoryTagRequest{TagID: 999}, fiber.StatusBadRequest},
+		{"untag unknown tag", "DELETE", fmt.Sprintf("/inventory/%d/tags/999", item.ID), nil, fiber.StatusNotFound},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, body := sendPredicateRequest(t, app, tt.method, tt.path, tt.body); status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/inventory/%d/tags/%d", item.ID, staple.ID), nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected status %d, got %d", fiber.StatusNoContent, status)
+	}
+	var remaining int64
+	db.Model(&models.InventoryTag{}).Where("inventory_id = ?", item.ID).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("expected the item untagged, %d tags left", remaining)
+	}
+}
+
+func TestTagsListAndDelete(t *testing.T) {
+	app, db := setupTagsTestApp(t)
+	trade := models.Tag{Name: "trade"}
+	staple := models.Tag{Name: "commander-staple"}
+	db.Create(&trade)
+	db.Create(&staple)
+	item := createTestInventoryItem(t, db, "sol-ring", 1, nil)
+	db.Create(&models.InventoryTag{InventoryID: item.ID, TagID: trade.ID})
+
+	status, body := sendPredicateRequest(t, app, "GET", "/tags", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d", fiber.StatusOK, status)
+	}
+	var result struct {
+		Data []models.Tag `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(result.Data) != 2 || result.Data[0].Name != "commander-staple" || result.Data[1].ItemCount != 1 {
+		t.Errorf("expected tags by name with item counts, got %+v", result.Data)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", fmt.Sprintf("/tags/%d", trade.ID), nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected status %d, got %d", fiber.StatusNoContent, status)
+	}
+	var links int64
+	db.Model(&models.InventoryTag{}).Count(&links)
+	if links != 0 {
+		t.Errorf("expected the tag removed from items, %d links left", links)
+	}
+	if status, _ := sendPredicateRequest(t, app, "DELETE", "/tags/999", nil); status != fiber.StatusNotFound {
+		t.Errorf("expected status %d, got %d", fiber.StatusNotFound, status)
+	}
+}
+
+func TestInventoryList_FilterByTag(t *testing.T) {
+	app, db := setupInventoryTestApp(t)
+	trade := models.Tag{Name: "trade"}
+	staple := models.Tag{Name: "commander-staple"}
+	db.Create(&trade)
+	db.Create(&staple)
+	both := createTestInventoryItem(t, db, "sol-ring", 1, nil)
+	tradeOnly := createTestInventoryItem(t, db, "bolt", 1, nil)
+	createTestInventoryItem(t, db, "untagged", 1, nil)
+	db.Create(&models.InventoryTag{InventoryID: both.ID, TagID: trade.ID})
+	db.Create(&models.InventoryTag{InventoryID: both.ID, TagID: staple.ID})
+	db.Create(&models.InventoryTag{InventoryID: tradeOnly.ID, TagID: trade.ID})
+
+	listIDs := func(query string) []uint {
+		t.Helper()
+		status, body := sendPredicateRequest(t, app, "GET", "/inventory"+query, nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+		}
+		var result struct {
+			Data []models.Inventory `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+		var ids []uint
+		for _, item := range result.Data {
+			ids = append(ids, item.ID)
+			if item.ID == both.ID && !slices.Equal(item.Tags, []string{"commander-staple", "trade"}) {
+				t.Errorf("expected the item's tags in the response, got %v", item.Tags)
+			}
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	if ids := listIDs("?tag=trade"); !slices.Equal(ids, []uint{both.ID, tradeOnly.ID}) {
+		t.Errorf("expected both trade items, got %v", ids)
+	}
+	if ids := listIDs("?tag=Trade,commander-staple"); !slices.Equal(ids, []uint{both.ID}) {
+		t.Errorf("expected only the item with both tags, got %v", ids)
+	}
+	if ids := listIDs("?tag=missing"); len(ids) != 0 {
+		t.Errorf("expected no items for an unknown tag, got %v", ids)
+	}
+}
+
+func TestResort_MatchesItemTags(t *testing.T) {
+	app, db := setupInventoryTestAppWithRules(t)
+
+	location := createTestStorageLocation(t, db)
+	createTestCard(t, db, "bolt-id", "Lightning Bolt", "lea", "common", "0.25")
+	createTestSortingRule(t, db, "Staples", 1, "hasTag('commander-staple')", location.ID)
+	staple := models.Tag{Name: "commander-staple"}
+	db.Create(&staple)
+
+	tagged := createTestInventoryItem(t, db, "bolt-id", 1, nil)
+	untagged := createTestInventoryItem(t, db, "bolt-id", 1, nil)
+	db.Create(&models.InventoryTag{InventoryID: tagged.ID, TagID: staple.ID})
+
+	status, body := sendPredicateRequest(t, app, "POST", "/inventory/resort", ResortRequest{})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", fiber.StatusOK, status, body)
+	}
+
+	var sorted, unsorted models.Inventory
+	db.First(&sorted, tagged.ID)
+	db.First(&unsorted, untagged.ID)
+	if sorted.StorageLocationID == nil || *sorted.StorageLocationID != location.ID {
+		t.Errorf("expected the tagged item in location %d, got %v", location.ID, sorted.StorageLocationID)
+	}
+	if unsorted.StorageLocationID != nil {
+		t.Errorf("expected the untagged item left unassigned, got %d", *unsorted.StorageLocationID)
+	}
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"backend/models"
+	"backend/realtime"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// TransactionsHandler handles sale and trade endpoints
+type TransactionsHandler struct {
+	service *services.TransactionService
+	hub     *realtime.Hub
+}
+
+// NewTransactionsHandler creates a new transactions handler
+func NewTransactionsHandler(service *services.TransactionService, hub *realtime.Hub) *TransactionsHandler {
+	return &TransactionsHandler{service: service, hub: hub}
+}
+
+// TransactionItemRequest represents copies of one inventory entry sold or traded away
+// tygo:export
+type TransactionItemRequest struct {
+	InventoryID uint    `json:"inventory_id"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"` // Sale price, or agreed trade value, per copy
+}
+
+// CreateTransactionRequest represents the request body for recording a sale or trade
+// tygo:export
+type CreateTransactionRequest struct {
+	Type         models.TransactionType   `json:"type"`
+	Counterparty string                   `json:"counterparty,omitempty"`
+	Date         *time.Time               `json:"date,omitempty"`     // Defaults to now
+	Currency     models.Currency          `json:"currency,omitempty"` // Defaults to the preferred currency
+	Notes        string                   `json:"notes,omitempty"`
+	Items        []TransactionItemRequest `json:"items"`
+}
+
+// TransactionReportResponse totals the matching transactions per currency
+// tygo:export
+type TransactionReportResponse struct {
+	Reports []services.TransactionReport `json:"reports"`
+}
+
+// List returns transactions, most recent first, with pagination and filters
+func (h *TransactionsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	transactions, total, err := h.service.List(c.RequestCtx(), params.Page, params.PageSize, filter)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch transactions", "transaction list query failed", err)
+	}
+
+	return utils.SendPaginated(c, transactions, params.Page, params.PageSize, total)
+}
+
+// Get returns a single transaction by ID
+func (h *TransactionsHandler) Get(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	transaction, err := h.service.Get(c.RequestCtx(), uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, "transaction not found")
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch transaction", "transaction query failed", err)
+	}
+
+	return c.JSON(transaction)
+}
+
+// Create records a sale or trade, taking its copies out of inventory
+func (h *TransactionsHandler) Create(c fiber.Ctx) error {
+	var req CreateTransactionRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	req.Counterparty = strings.TrimSpace(req.Counterparty)
+	if !req.Type.Valid() {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "type must be one of: sale, trade")
+	}
+	if err := utils.ValidateMaxLength(req.Counterparty, models.MaxCounterpartyLength, "counterparty"); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if req.Currency != "" && !req.Currency.Valid() {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "currency must be one of: usd, eur, tix")
+	}
+
+	if len(req.Items) == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "items array is required")
+	}
+	if len(req.Items) > MaxBatchItems {
+		return utils.ReturnError(c, fiber.StatusBadRequest,
+			fmt.Sprintf("too many items (max %d)", MaxBatchItems))
+	}
+
+	transaction := models.Transaction{
+		Type:         req.Type,
+		Counterparty: req.Counterparty,
+		Currency:     req.Currency,
+		Notes:        req.Notes,
+		Items:        make([]models.TransactionItem, 0, len(req.Items)),
+	}
+	if req.Date != nil {
+		transaction.Date = *req.Date
+	}
+	for i, item := range req.Items {
+		if item.InventoryID == 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("items[%d]: inventory_id is required", i))
+		}
+		if item.Quantity == 0 {
+			item.Quantity = 1
+		}
+		if item.Quantity < 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("items[%d]: quantity must be positive", i))
+		}
+		if item.UnitPrice < 0 {
+			return utils.ReturnError(c, fiber.StatusBadRequest, fmt.Sprintf("items[%d]: unit_price cannot be negative", i))
+		}
+		transaction.Items = append(transaction.Items, models.TransactionItem{
+			InventoryID: item.InventoryID,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+		})
+	}
+
+	sold, err := h.service.Record(c.RequestCtx(), &transaction)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return utils.ReturnError(c, fiber.StatusBadRequest, "inventory item not found")
+		case errors.Is(err, services.ErrInsufficientSaleQuantity):
+			return utils.ReturnError(c, fiber.StatusConflict, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to record transaction", "transaction insert failed", err)
+	}
+
+	if len(sold.Updated) > 0 {
+		h.hub.Publish(realtime.EventInventoryUpdated, realtime.InventoryChange{IDs: sold.Updated})
+	}
+	if len(sold.Removed) > 0 {
+		h.hub.Publish(realtime.EventInventoryDeleted, realtime.InventoryChange{IDs: sold.Removed})
+	}
+
+	created, err := h.service.Get(c.RequestCtx(), transaction.ID)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to reload transaction", "transaction query failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Report returns proceeds and profit/loss against acquisition price for the
+// matching transactions, per currency
+func (h *TransactionsHandler) Report(c fiber.Ctx) error {
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	reports, err := h.service.Report(c.RequestCtx(), filter)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to build transaction report", "transaction report query failed", err)
+	}
+
+	return c.JSON(TransactionReportResponse{Reports: reports})
+}
+
+// parseTransactionFilter reads the type, counterparty, from and to query parameters.
+// from and to are inclusive dates in YYYY-MM-DD format.
+func parseTransactionFilter(c fiber.Ctx) (services.TransactionFilter, error) {
+	filter := services.TransactionFilter{Counterparty: strings.TrimSpace(c.Query("counterparty"))}
+	if typeStr := c.Query("type"); typeStr != "" {
+		t := models.TransactionType(typeStr)
+		if !t.Valid() {
+			return filter, errors.New("type must be one of: sale, trade")
+		}
+		filter.Type = &t
+	}
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err := time.ParseInLocation(time.DateOnly, fromStr, time.Local)
+		if err != nil {
+			return filter, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		filter.From = &from
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		to, err := time.ParseInLocation(time.DateOnly, toStr, time.Local)
+		if err != nil {
+			return filter, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	return filter, nil
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTransactionsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.InventoryEvent{}, &models.InventoryTag{},
+		&models.Loan{}, &models.LoanItem{}, &models.Setting{}, &models.Transaction{}, &models.TransactionItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewTransactionsHandler(services.NewTransactionService(db), nil)
+
+	app := fiber.New()
+	app.Get("/transactions", handler.List)
+	app.Post("/transactions", handler.Create)
+	app.Get("/transactions/report", handler.Report)
+	app.Get("/transactions/:id", handler.Get)
+
+	return app, db
+}
+
+func TestTransactions_RecordSaleAndReport(t *testing.T) {
+	app, db := setupTransactionsTestApp(t)
+
+	paid := 2.0
+	item := models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "foil", Quantity: 3, AcquiredPrice: &paid}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	var created models.Transaction
+	status := intakeRequest(t, app, "POST", "/transactions", CreateTransactionRequest{
+		Type:         models.TransactionSale,
+		Counterparty: "  Card Shop  ",
+		Items:        []TransactionItemRequest{{InventoryID: item.ID, Quantity: 2, UnitPrice: 5}},
+	}, &created)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d", status)
+	}
+	if created.Counterparty != "Card Shop" || created.Currency != models.CurrencyUSD || len(created.Items) != 1 {
+		t.Fatalf("unexpected transaction %+v", created)
+	}
+	if created.Items[0].Treatment != "foil" || created.Items[0].ScryfallID != "bolt-m10" {
+		t.Errorf("expected the printing copied from inventory, got %+v", created.Items[0])
+	}
+
+	var remaining models.Inventory
+	db.First(&remaining, item.ID)
+	if remaining.Quantity != 1 {
+		t.Errorf("expected 1 copy left, got %d", remaining.Quantity)
+	}
+
+	// More copies than are left
+	status = intakeRequest(t, app, "POST", "/transactions", CreateTransactionRequest{
+		Type:  models.TransactionTrade,
+		Items: []TransactionItemRequest{{InventoryID: item.ID, Quantity: 2}},
+	}, nil)
+	if status != fiber.StatusConflict {
+		t.Errorf("expected 409, got %d", status)
+	}
+
+	var fetched models.Transaction
+	if status := intakeRequest(t, app, "GET", fmt.Sprintf("/transactions/%d", created.ID), nil, &fetched); status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+
+	var report TransactionReportResponse
+	if status := intakeRequest(t, app, "GET", "/transactions/report?type=sale", nil, &report); status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(report.Reports) != 1 || report.Reports[0].Proceeds != 10 || report.Reports[0].ProfitLoss != 6 {
+		t.Errorf("unexpected report %+v", report.Reports)
+	}
+
+	var page struct {
+		Data       []models.Transaction `json:"data"`
+		TotalItems int64                `json:"total_items"`
+	}
+	if status := intakeRequest(t, app, "GET", "/transactions?counterparty=shop", nil, &page); status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(page.Data) != 1 {
+		t.Errorf("expected 1 transaction, got %d", len(page.Data))
+	}
+}
+
+func TestTransactions_Validation(t *testing.T) {
+	app, _ := setupTransactionsTestApp(t)
+
+	tests := []struct {
+		name   string
+		body   CreateTransactionRequest
+		status int
+	}{
+		{"invalid type", CreateTransactionRequest{Type: "gift", Items: []TransactionItemRequest{{InventoryID: 1}}}, fiber.StatusBadRequest},
+		{"no items", CreateTransactionRequest{Type: models.TransactionSale}, fiber.StatusBadRequest},
+		{"negative price", CreateTransactionRequest{Type: models.TransactionSale, Items: []TransactionItemRequest{{InventoryID: 1, UnitPrice: -1}}}, fiber.StatusBadRequest},
+		{"invalid currency", CreateTransactionRequest{Type: models.TransactionSale, Currency: "gbp", Items: []TransactionItemRequest{{InventoryID: 1}}}, fiber.StatusBadRequest},
+		{"missing inventory", CreateTransactionRequest{Type: models.TransactionSale, Items: []TransactionItemRequest{{InventoryID: 99}}}, fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := intakeRequest(t, app, "POST", "/transactions", tt.body, nil); status != tt.status {
+				t.Errorf("expected %d, got %d", tt.status, status)
+			}
+		})
+	}
+
+	if status := intakeRequest(t, app, "GET", "/transactions/report?from=yesterday", nil, nil); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for a bad date, got %d", status)
+	}
+	if status := intakeRequest(t, app, "GET", "/transactions/42", nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404, got %d", status)
+	}
+}
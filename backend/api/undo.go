@@ -0,0 +1,74 @@
+package api
+
+import (
+	"backend/services"
+	"backend/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// UndoHandler handles undo token and recorded operation endpoints
+type UndoHandler struct {
+	service *services.UndoService
+}
+
+// NewUndoHandler creates a new undo handler
+func NewUndoHandler(service *services.UndoService) *UndoHandler {
+	return &UndoHandler{service: service}
+}
+
+// Redeem reverts the batch operation recorded under the token
+func (h *UndoHandler) Redeem(c fiber.Ctx) error {
+	token := c.Params("token")
+	if token == "" {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid token")
+	}
+
+	result, err := h.service.Redeem(c.RequestCtx(), token)
+	if err != nil {
+		if errors.Is(err, services.ErrUndoTokenNotFound) {
+			return utils.ReturnError(c, fiber.StatusNotFound, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to undo operation", "undo transaction failed", err)
+	}
+
+	return c.JSON(result)
+}
+
+// ListOperations returns recorded batch operations with pagination, newest first
+func (h *UndoHandler) ListOperations(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	operations, total, err := h.service.List(c.RequestCtx(), params.Page, params.PageSize)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch operations", "operation query failed", err)
+	}
+
+	return utils.SendPaginated(c, operations, params.Page, params.PageSize, total)
+}
+
+// UndoOperation reverts a recorded batch operation by ID, after its undo token has expired
+func (h *UndoHandler) UndoOperation(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id <= 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	result, err := h.service.Undo(c.RequestCtx(), uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return utils.ReturnError(c, fiber.StatusNotFound, "operation not found")
+		case errors.Is(err, services.ErrOperationAlreadyUndone):
+			return utils.ReturnError(c, fiber.StatusConflict, err.Error())
+		}
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to undo operation", "undo transaction failed", err)
+	}
+
+	return c.JSON(result)
+}
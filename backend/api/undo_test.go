@@ -0,0 +1,265 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/models"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUndoTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(
+		&models.StorageLocation{},
+		&models.Inventory{},
+		&models.Tag{},
+		&models.InventoryTag{},
+		&models.InventoryEvent{},
+		&models.Card{},
+		&models.SortingRule{},
+		&models.Loan{},
+		&models.LoanItem{},
+		&models.InventoryOperation{},
+	); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	undoSvc := services.NewUndoService(db)
+	inventoryHandler := NewInventoryHandler(db, services.NewAutoSortService(db), undoSvc)
+	undoHandler := NewUndoHandler(undoSvc)
+
+	app := fiber.New()
+	app.Post("/inventory/batch/move", inventoryHandler.BatchMove)
+	app.Delete("/inventory/batch", inventoryHandler.BatchDelete)
+	app.Post("/undo/:token", undoHandler.Redeem)
+	app.Get("/operations", undoHandler.ListOperations)
+	app.Post("/operations/:id/undo", undoHandler.UndoOperation)
+
+	return app, db
+}
+
+func redeemUndoToken(t *testing.T, app *fiber.App, token string) *http.Response {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/undo/"+token, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+func TestUndo_BatchDelete(t *testing.T) {
+	app, db := setupUndoTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	item := createTestInventoryItem(t, db, "card-1", 3, &location.ID)
+	loan := models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: item.ID, Quantity: 1}}}
+	if err := db.Create(&loan).Error; err != nil {
+		t.Fatalf("failed to create loan: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"ids": [%d]}`, item.ID)
+	req := httptest.NewRequest(http.MethodDelete, "/inventory/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	var deleted BatchDeleteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&deleted); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if deleted.UndoToken == "" || deleted.UndoExpiresAt == nil {
+		t.Fatal("expected an undo token with an expiry")
+	}
+
+	// Simulate the loan line cascading away with the inventory row
+	db.Where("inventory_id = ?", item.ID).Delete(&models.LoanItem{})
+
+	resp = redeemUndoToken(t, app, deleted.UndoToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var result services.UndoResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Operation != services.UndoOperationBatchDelete || result.Restored != 1 {
+		t.Errorf("unexpected undo result: %+v", result)
+	}
+
+	var restored models.Inventory
+	if err := db.First(&restored, item.ID).Error; err != nil {
+		t.Fatalf("expected item %d to be restored: %v", item.ID, err)
+	}
+	if restored.Quantity != 3 || restored.StorageLocationID == nil || *restored.StorageLocationID != location.ID {
+		t.Errorf("restored item does not match original: %+v", restored)
+	}
+
+	var loanItems int64
+	db.Model(&models.LoanItem{}).Where("inventory_id = ?", item.ID).Count(&loanItems)
+	if loanItems != 1 {
+		t.Errorf("expected loan item to be restored, got %d", loanItems)
+	}
+}
+
+func TestUndo_BatchMove(t *testing.T) {
+	app, db := setupUndoTestApp(t)
+
+	from := createTestStorageLocation(t, db)
+	to := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	if err := db.Create(&to).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	moved := createTestInventoryItem(t, db, "card-1", 1, &from.ID)
+	unassigned := createTestInventoryItem(t, db, "card-2", 1, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d], "storage_location_id": %d}`, moved.ID, unassigned.ID, to.ID)
+	req := httptest.NewRequest(http.MethodPost, "/inventory/batch/move", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	var result BatchMoveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	resp = redeemUndoToken(t, app, result.UndoToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var item models.Inventory
+	db.First(&item, moved.ID)
+	if item.StorageLocationID == nil || *item.StorageLocationID != from.ID {
+		t.Errorf("expected item to return to location %d, got %v", from.ID, item.StorageLocationID)
+	}
+	var other models.Inventory
+	db.First(&other, unassigned.ID)
+	if other.StorageLocationID != nil {
+		t.Errorf("expected item to be unassigned again, got %v", *other.StorageLocationID)
+	}
+
+	// Tokens are single use
+	resp = redeemUndoToken(t, app, result.UndoToken)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestUndo_UnknownToken(t *testing.T) {
+	app, _ := setupUndoTestApp(t)
+
+	resp := redeemUndoToken(t, app, "does-not-exist")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestUndo_OperationByID(t *testing.T) {
+	app, db := setupUndoTestApp(t)
+
+	location := createTestStorageLocation(t, db)
+	first := createTestInventoryItem(t, db, "card-1", 2, &location.ID)
+	second := createTestInventoryItem(t, db, "card-2", 1, nil)
+
+	body := fmt.Sprintf(`{"ids": [%d, %d]}`, first.ID, second.ID)
+	req := httptest.NewRequest(http.MethodDelete, "/inventory/batch", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+
+	var deleted BatchDeleteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&deleted); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if deleted.OperationID == 0 {
+		t.Fatal("expected the operation to be recorded")
+	}
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/operations", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var listed struct {
+		Data       []models.InventoryOperation `json:"data"`
+		TotalItems int64                       `json:"total_items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listed); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if listed.TotalItems != 1 || listed.Data[0].ID != deleted.OperationID || listed.Data[0].ItemCount != 2 {
+		t.Errorf("unexpected operations: %+v", listed)
+	}
+
+	undoURL := fmt.Sprintf("/operations/%d/undo", deleted.OperationID)
+	resp, err = app.Test(httptest.NewRequest(http.MethodPost, undoURL, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	var result services.UndoResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.OperationID != deleted.OperationID || result.Restored != 2 {
+		t.Errorf("unexpected undo result: %+v", result)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected both rows restored, got %d", count)
+	}
+
+	// The operation's token no longer applies once it has been undone by ID
+	resp = redeemUndoToken(t, app, deleted.UndoToken)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status %d for the token, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		expected int
+	}{
+		{"Already undone", undoURL, http.StatusConflict},
+		{"Unknown operation", "/operations/999/undo", http.StatusNotFound},
+		{"Invalid id", "/operations/abc/undo", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, tt.url, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ValueAlertsHandler handles value alert endpoints
+type ValueAlertsHandler struct {
+	db *gorm.DB
+}
+
+// NewValueAlertsHandler creates a new value alerts handler
+func NewValueAlertsHandler(db *gorm.DB) *ValueAlertsHandler {
+	return &ValueAlertsHandler{db: db}
+}
+
+// List returns value alerts with pagination, ordered by name
+func (h *ValueAlertsHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.ValueAlert{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count value alerts", "database count failed", err)
+	}
+
+	var alerts []models.ValueAlert
+	if err := query.Order("name ASC, id ASC").
+		Offset(utils.CalculateOffset(params.Page, params.PageSize)).
+		Limit(params.PageSize).
+		Find(&alerts).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch value alerts", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, alerts, params.Page, params.PageSize, total)
+}
+
+// Get returns a single value alert by ID
+func (h *ValueAlertsHandler) Get(c fiber.Ctx) error {
+	alert, err := h.findAlert(c)
+	if err != nil || alert == nil {
+		return err
+	}
+	return c.JSON(alert)
+}
+
+// ValueAlertRequest represents the request body for creating or replacing a value alert
+// tygo:export
+type ValueAlertRequest struct {
+	Name       string                     `json:"name"`
+	Condition  models.ValueAlertCondition `json:"condition"`
+	Threshold  float64                    `json:"threshold"`             // Percent for card_price_change (negative for drops), amount otherwise
+	WindowDays int                        `json:"window_days,omitempty"` // card_price_change only; defaults to 7
+	Enabled    *bool                      `json:"enabled,omitempty"`     // Defaults to true
+	WebhookURL string                     `json:"webhook_url,omitempty"`
+}
+
+// apply copies the request onto alert, filling in defaults
+func (r ValueAlertRequest) apply(alert *models.ValueAlert) {
+	alert.Name = strings.TrimSpace(r.Name)
+	alert.Condition = r.Condition
+	alert.Threshold = r.Threshold
+	alert.WindowDays = 0
+	if r.Condition == models.ValueAlertCardPriceChange {
+		alert.WindowDays = r.WindowDays
+		if alert.WindowDays == 0 {
+			alert.WindowDays = models.DefaultValueAlertWindowDays
+		}
+	}
+	alert.Enabled = r.Enabled == nil || *r.Enabled
+	alert.WebhookURL = strings.TrimSpace(r.WebhookURL)
+}
+
+// Create creates a new value alert
+func (h *ValueAlertsHandler) Create(c fiber.Ctx) error {
+	var req ValueAlertRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var alert models.ValueAlert
+	req.apply(&alert)
+	if ok, err := h.saveAlert(c, &alert); !ok {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(alert)
+}
+
+// Update replaces a value alert's settings. Its last checked value and trigger time
+// are kept.
+func (h *ValueAlertsHandler) Update(c fiber.Ctx) error {
+	alert, err := h.findAlert(c)
+	if err != nil || alert == nil {
+		return err
+	}
+
+	var req ValueAlertRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	req.apply(alert)
+	if ok, err := h.saveAlert(c, alert); !ok {
+		return err
+	}
+	return c.JSON(alert)
+}
+
+// Delete deletes a value alert. Notifications it raised are kept.
+func (h *ValueAlertsHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	result := h.db.WithContext(c.RequestCtx()).Delete(&models.ValueAlert{}, id)
+	if result.Error != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete value alert", "database delete failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "value alert not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// findAlert loads the value alert named by the id path param. When it returns a nil
+// alert the error response has already been written and err should be returned.
+func (h *ValueAlertsHandler) findAlert(c fiber.Ctx) (*models.ValueAlert, error) {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var alert models.ValueAlert
+	if err := h.db.WithContext(c.RequestCtx()).First(&alert, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "value alert not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch value alert", "database query failed", err)
+	}
+	return &alert, nil
+}
+
+// saveAlert validates and stores a new or updated alert. When it returns false the
+// error response has already been written.
+func (h *ValueAlertsHandler) saveAlert(c fiber.Ctx, alert *models.ValueAlert) (bool, error) {
+	if err := alert.ValidateValueAlert(h.db); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Save(alert).Error; err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save value alert", "database save failed", err)
+	}
+	return true, nil
+}
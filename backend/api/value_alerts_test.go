@@ -0,0 +1,125 @@
+package api
+
+import (
+	"backend/models"
+	"backend/utils"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupValueAlertsTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.ValueAlert{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	handler := NewValueAlertsHandler(db)
+
+	app := fiber.New()
+	app.Get("/alerts/value", handler.List)
+	app.Get("/alerts/value/:id", handler.Get)
+	app.Post("/alerts/value", handler.Create)
+	app.Put("/alerts/value/:id", handler.Update)
+	app.Delete("/alerts/value/:id", handler.Delete)
+
+	return app, db
+}
+
+func TestValueAlertsCreate(t *testing.T) {
+	app, _ := setupValueAlertsTestApp(t)
+	disabled := false
+
+	tests := []struct {
+		name     string
+		body     ValueAlertRequest
+		expected int
+	}{
+		{"price gain", ValueAlertRequest{Name: "Spikes", Condition: models.ValueAlertCardPriceChange, Threshold: 20}, fiber.StatusCreated},
+		{"disabled value floor", ValueAlertRequest{Name: "Floor", Condition: models.ValueAlertCollectionValueBelow, Threshold: 500, Enabled: &disabled}, fiber.StatusCreated},
+		{"missing name", ValueAlertRequest{Condition: models.ValueAlertCardPriceChange, Threshold: 20}, fiber.StatusBadRequest},
+		{"unknown condition", ValueAlertRequest{Name: "X", Condition: "set_completed", Threshold: 20}, fiber.StatusBadRequest},
+		{"window too long", ValueAlertRequest{Name: "X", Condition: models.ValueAlertCardPriceChange, Threshold: 20, WindowDays: 365}, fiber.StatusBadRequest},
+		{"bad webhook", ValueAlertRequest{Name: "X", Condition: models.ValueAlertCollectionValueAbove, Threshold: 20, WebhookURL: "not a url"}, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/alerts/value", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+
+	status, body := sendPredicateRequest(t, app, "GET", "/alerts/value", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var page utils.PaginatedResponse[models.ValueAlert]
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if page.TotalItems != 2 {
+		t.Fatalf("expected 2 alerts, got %+v", page)
+	}
+	// Ordered by name; defaults filled in
+	floor, spikes := page.Data[0], page.Data[1]
+	if floor.Enabled || floor.WindowDays != 0 {
+		t.Errorf("expected a disabled floor without a window, got %+v", floor)
+	}
+	if !spikes.Enabled || spikes.WindowDays != models.DefaultValueAlertWindowDays {
+		t.Errorf("expected an enabled 7-day price alert, got %+v", spikes)
+	}
+}
+
+func TestValueAlertsUpdateAndDelete(t *testing.T) {
+	app, db := setupValueAlertsTestApp(t)
+	lastValue := 750.0
+	alert := models.ValueAlert{Name: "Milestone", Condition: models.ValueAlertCollectionValueAbove, Threshold: 1000, Enabled: true, LastValue: &lastValue}
+	db.Create(&alert)
+	path := fmt.Sprintf("/alerts/value/%d", alert.ID)
+
+	status, body := sendPredicateRequest(t, app, "PUT", path, ValueAlertRequest{
+		Name: "Milestone", Condition: models.ValueAlertCollectionValueAbove, Threshold: 2000, WebhookURL: "https://example.com/hook",
+	})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var updated models.ValueAlert
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if updated.Threshold != 2000 || updated.WebhookURL != "https://example.com/hook" {
+		t.Errorf("expected the new threshold and webhook, got %+v", updated)
+	}
+	if updated.LastValue == nil || *updated.LastValue != 750 {
+		t.Errorf("expected the last checked value kept, got %v", updated.LastValue)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "PUT", path, ValueAlertRequest{Name: "Milestone", Condition: models.ValueAlertCollectionValueAbove})
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for a zero threshold, got %d", status)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", path, nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	for _, method := range []string{"GET", "DELETE"} {
+		status, _ = sendPredicateRequest(t, app, method, path, nil)
+		if status != fiber.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", method, status)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// WebhooksHandler handles webhook endpoints
+type WebhooksHandler struct {
+	db      *gorm.DB
+	service *services.WebhookService
+}
+
+// NewWebhooksHandler creates a new webhooks handler
+func NewWebhooksHandler(db *gorm.DB, service *services.WebhookService) *WebhooksHandler {
+	return &WebhooksHandler{db: db, service: service}
+}
+
+// List returns webhooks with pagination, ordered by name
+func (h *WebhooksHandler) List(c fiber.Ctx) error {
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+
+	query := h.db.WithContext(c.RequestCtx()).Model(&models.Webhook{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to count webhooks", "database count failed", err)
+	}
+
+	var webhooks []models.Webhook
+	if err := query.Order("name ASC, id ASC").
+		Offset(utils.CalculateOffset(params.Page, params.PageSize)).
+		Limit(params.PageSize).
+		Find(&webhooks).Error; err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch webhooks", "database query failed", err)
+	}
+
+	return utils.SendPaginated(c, webhooks, params.Page, params.PageSize, total)
+}
+
+// Get returns a single webhook by ID
+func (h *WebhooksHandler) Get(c fiber.Ctx) error {
+	webhook, err := h.findWebhook(c)
+	if err != nil || webhook == nil {
+		return err
+	}
+	return c.JSON(webhook)
+}
+
+// WebhookRequest represents the request body for creating or replacing a webhook
+// tygo:export
+type WebhookRequest struct {
+	Name    string                `json:"name"`
+	URL     string                `json:"url"`
+	Secret  *string               `json:"secret,omitempty"` // Signs deliveries; omit on update to keep the current one, "" to stop signing
+	Events  []models.WebhookEvent `json:"events"`
+	Enabled *bool                 `json:"enabled,omitempty"` // Defaults to true
+}
+
+// apply copies the request onto webhook, filling in defaults
+func (r WebhookRequest) apply(webhook *models.Webhook) {
+	webhook.Name = strings.TrimSpace(r.Name)
+	webhook.URL = strings.TrimSpace(r.URL)
+	if r.Secret != nil {
+		webhook.Secret = *r.Secret
+	}
+	webhook.Events = r.Events
+	webhook.Enabled = r.Enabled == nil || *r.Enabled
+}
+
+// Create creates a new webhook
+func (h *WebhooksHandler) Create(c fiber.Ctx) error {
+	var req WebhookRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	var webhook models.Webhook
+	req.apply(&webhook)
+	if ok, err := h.saveWebhook(c, &webhook); !ok {
+		return err
+	}
+	return c.Status(fiber.StatusCreated).JSON(webhook)
+}
+
+// Update replaces a webhook's settings. Its delivery log is kept.
+func (h *WebhooksHandler) Update(c fiber.Ctx) error {
+	webhook, err := h.findWebhook(c)
+	if err != nil || webhook == nil {
+		return err
+	}
+
+	var req WebhookRequest
+	if err := c.Bind().Body(&req); err != nil {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid request body")
+	}
+
+	req.apply(webhook)
+	if ok, err := h.saveWebhook(c, webhook); !ok {
+		return err
+	}
+	return c.JSON(webhook)
+}
+
+// Delete deletes a webhook along with its delivery log
+func (h *WebhooksHandler) Delete(c fiber.Ctx) error {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var deleted int64
+	err := h.db.WithContext(c.RequestCtx()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Webhook{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error
+	})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to delete webhook", "database delete failed", err)
+	}
+	if deleted == 0 {
+		return utils.ReturnError(c, fiber.StatusNotFound, "webhook not found")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Deliveries returns a webhook's delivery log with pagination, newest first
+func (h *WebhooksHandler) Deliveries(c fiber.Ctx) error {
+	webhook, err := h.findWebhook(c)
+	if err != nil || webhook == nil {
+		return err
+	}
+
+	params := utils.ParsePaginationParams(c, utils.DefaultPageSize, utils.MaxPageSize)
+	deliveries, total, err := h.service.Deliveries(c.RequestCtx(), webhook.ID, params.Page, params.PageSize)
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch webhook deliveries", "delivery list query failed", err)
+	}
+
+	return utils.SendPaginated(c, deliveries, params.Page, params.PageSize, total)
+}
+
+// Test sends a ping event to a webhook, enabled or not, and returns the queued
+// delivery. Its outcome shows up in the delivery log.
+func (h *WebhooksHandler) Test(c fiber.Ctx) error {
+	webhook, err := h.findWebhook(c)
+	if err != nil || webhook == nil {
+		return err
+	}
+
+	delivery, err := h.service.Send(c.RequestCtx(), webhook, models.WebhookEventPing, fiber.Map{"webhook_id": webhook.ID})
+	if err != nil {
+		return utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to send test delivery", "webhook test failed", err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+// findWebhook loads the webhook named by the id path param. When it returns a nil
+// webhook the error response has already been written and err should be returned.
+func (h *WebhooksHandler) findWebhook(c fiber.Ctx) (*models.Webhook, error) {
+	id := fiber.Params[int](c, "id")
+	if id == 0 {
+		return nil, utils.ReturnError(c, fiber.StatusBadRequest, "invalid id")
+	}
+
+	var webhook models.Webhook
+	if err := h.db.WithContext(c.RequestCtx()).First(&webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ReturnError(c, fiber.StatusNotFound, "webhook not found")
+		}
+		return nil, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to fetch webhook", "database query failed", err)
+	}
+	return &webhook, nil
+}
+
+// saveWebhook validates and stores a new or updated webhook. When it returns false the
+// error response has already been written.
+func (h *WebhooksHandler) saveWebhook(c fiber.Ctx, webhook *models.Webhook) (bool, error) {
+	if err := webhook.ValidateWebhook(h.db); err != nil {
+		return false, utils.ReturnError(c, fiber.StatusBadRequest, err.Error())
+	}
+	if err := h.db.WithContext(c.RequestCtx()).Save(webhook).Error; err != nil {
+		return false, utils.LogAndReturnError(c, fiber.StatusInternalServerError,
+			"Failed to save webhook", "database save failed", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"backend/models"
+	"backend/services"
+	"backend/utils"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupWebhooksTestApp(t *testing.T) (*fiber.App, *gorm.DB, *services.WebhookService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	// Test deliveries are written from another goroutine
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	service := services.NewWebhookService(db)
+	handler := NewWebhooksHandler(db, service)
+
+	app := fiber.New()
+	app.Get("/webhooks", handler.List)
+	app.Get("/webhooks/:id", handler.Get)
+	app.Post("/webhooks", handler.Create)
+	app.Put("/webhooks/:id", handler.Update)
+	app.Delete("/webhooks/:id", handler.Delete)
+	app.Get("/webhooks/:id/deliveries", handler.Deliveries)
+	app.Post("/webhooks/:id/test", handler.Test)
+
+	return app, db, service
+}
+
+func TestWebhooksCreate(t *testing.T) {
+	app, _, _ := setupWebhooksTestApp(t)
+	secret := "s3cret"
+	events := []models.WebhookEvent{models.WebhookEventBulkImportFailed}
+
+	tests := []struct {
+		name     string
+		body     WebhookRequest
+		expected int
+	}{
+		{"signed", WebhookRequest{Name: "Home", URL: "http://homeassistant.local/api/webhook/x", Secret: &secret, Events: events}, fiber.StatusCreated},
+		{"missing events", WebhookRequest{Name: "Home", URL: "https://example.com"}, fiber.StatusBadRequest},
+		{"unknown event", WebhookRequest{Name: "Home", URL: "https://example.com", Events: []models.WebhookEvent{"card.created"}}, fiber.StatusBadRequest},
+		{"bad url", WebhookRequest{Name: "Home", URL: "example.com", Events: events}, fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := sendPredicateRequest(t, app, "POST", "/webhooks", tt.body)
+			if status != tt.expected {
+				t.Errorf("expected status %d, got %d: %s", tt.expected, status, body)
+			}
+		})
+	}
+
+	status, body := sendPredicateRequest(t, app, "GET", "/webhooks", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	if strings.Contains(string(body), secret) {
+		t.Errorf("expected the secret not to be returned, got %s", body)
+	}
+	var page utils.PaginatedResponse[models.Webhook]
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if page.TotalItems != 1 || !page.Data[0].HasSecret || !page.Data[0].Enabled {
+		t.Errorf("expected one enabled signed webhook, got %+v", page)
+	}
+}
+
+func TestWebhooksUpdateKeepsSecret(t *testing.T) {
+	app, db, _ := setupWebhooksTestApp(t)
+	webhook := models.Webhook{Name: "Home", URL: "https://example.com", Secret: "s3cret",
+		Events: []models.WebhookEvent{models.WebhookEventJobFailed}, Enabled: true}
+	db.Create(&webhook)
+	path := fmt.Sprintf("/webhooks/%d", webhook.ID)
+
+	disabled := false
+	status, body := sendPredicateRequest(t, app, "PUT", path, WebhookRequest{
+		Name: "Home", URL: "https://example.com/v2", Events: []models.WebhookEvent{models.WebhookEventJobCompleted}, Enabled: &disabled,
+	})
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var stored models.Webhook
+	db.First(&stored, webhook.ID)
+	if stored.Secret != "s3cret" || stored.Enabled || stored.URL != "https://example.com/v2" {
+		t.Errorf("expected the secret kept and the rest replaced, got %+v", stored)
+	}
+
+	cleared := ""
+	sendPredicateRequest(t, app, "PUT", path, WebhookRequest{Name: "Home", URL: "https://example.com", Secret: &cleared,
+		Events: []models.WebhookEvent{models.WebhookEventJobCompleted}})
+	db.First(&stored, webhook.ID)
+	if stored.HasSecret {
+		t.Error("expected an empty secret to stop signing")
+	}
+}
+
+func TestWebhooksTestAndDeliveries(t *testing.T) {
+	app, db, service := setupWebhooksTestApp(t)
+	var event string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get(services.WebhookEventHeader)
+	}))
+	defer receiver.Close()
+
+	webhook := models.Webhook{Name: "Home", URL: receiver.URL, Events: []models.WebhookEvent{models.WebhookEventJobFailed}}
+	db.Create(&webhook)
+	path := fmt.Sprintf("/webhooks/%d", webhook.ID)
+
+	// Disabled webhooks can still be tested
+	status, body := sendPredicateRequest(t, app, "POST", path+"/test", nil)
+	if status != fiber.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", status, body)
+	}
+	service.Wait()
+	if event != "ping" {
+		t.Errorf("expected a ping delivery, got %q", event)
+	}
+
+	status, body = sendPredicateRequest(t, app, "GET", path+"/deliveries", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", status, body)
+	}
+	var page utils.PaginatedResponse[models.WebhookDelivery]
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if page.TotalItems != 1 || page.Data[0].Status != models.WebhookDeliveryDelivered {
+		t.Errorf("expected one delivered ping, got %+v", page)
+	}
+
+	status, _ = sendPredicateRequest(t, app, "DELETE", path, nil)
+	if status != fiber.StatusNoContent {
+		t.Errorf("expected 204, got %d", status)
+	}
+	var remaining int64
+	db.Model(&models.WebhookDelivery{}).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("expected the delivery log deleted with the webhook, got %d", remaining)
+	}
+	for _, route := range []string{path, path + "/deliveries"} {
+		if status, _ := sendPredicateRequest(t, app, "GET", route, nil); status != fiber.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", route, status)
+		}
+	}
+}
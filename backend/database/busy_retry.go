@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// Busy retry defaults. SQLite's own busy timeout covers short waits; these retries
+// absorb the longer lock holds of bulk import transactions.
+const (
+	DefaultBusyRetryAttempts  = 4
+	DefaultBusyRetryBaseDelay = 100 * time.Millisecond
+
+	// BusyRetryAfterSeconds is the Retry-After hint sent when contention persists
+	BusyRetryAfterSeconds = 5
+)
+
+// ErrDatabaseBusy is returned once a write has exhausted its busy retries
+var ErrDatabaseBusy = errors.New("database is busy")
+
+// IsBusy reports whether err is a SQLITE_BUSY or SQLITE_LOCKED error, or wraps ErrDatabaseBusy
+func IsBusy(err error) bool {
+	if errors.Is(err, ErrDatabaseBusy) {
+		return true
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+// BusyRetry is a GORM plugin that retries writes rejected with SQLITE_BUSY or
+// SQLITE_LOCKED using exponential backoff. Only operations that hold no lock when
+// they fail are retried: autocommit statements and transaction begins. Statements
+// inside a transaction are not, since the transaction as a whole may need to restart;
+// opening transactions with _txlock=immediate moves their contention to the begin.
+type BusyRetry struct {
+	Attempts  int
+	BaseDelay time.Duration
+}
+
+// Name implements gorm.Plugin
+func (BusyRetry) Name() string {
+	return "busy_retry"
+}
+
+// Initialize implements gorm.Plugin by wrapping the connection pool
+func (p BusyRetry) Initialize(db *gorm.DB) error {
+	if p.Attempts <= 0 {
+		p.Attempts = DefaultBusyRetryAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultBusyRetryBaseDelay
+	}
+
+	pool := &busyRetryPool{ConnPool: db.ConnPool, policy: p}
+	db.ConnPool = pool
+	db.Statement.ConnPool = pool
+	return nil
+}
+
+// do runs fn until it succeeds, fails with a non-busy error, runs out of attempts,
+// or ctx is cancelled. Exhausted retries are reported as ErrDatabaseBusy.
+func (p BusyRetry) do(ctx context.Context, fn func() error) error {
+	delay := p.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) {
+			return err
+		}
+		if attempt >= p.Attempts {
+			return fmt.Errorf("%w after %d attempts: %w", ErrDatabaseBusy, attempt, err)
+		}
+
+		slog.Warn("database busy, retrying", "component", "database", "attempt", attempt, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// busyRetryPool wraps the underlying *sql.DB with busy retries
+type busyRetryPool struct {
+	gorm.ConnPool
+	policy BusyRetry
+}
+
+// ExecContext retries autocommit writes
+func (p *busyRetryPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := p.policy.do(ctx, func() error {
+		var err error
+		result, err = p.ConnPool.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// BeginTx retries transaction begins, returning a plain *sql.Tx so statements
+// inside the transaction bypass the wrapper
+func (p *busyRetryPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	beginner, ok := p.ConnPool.(gorm.TxBeginner)
+	if !ok {
+		return nil, gorm.ErrInvalidTransaction
+	}
+
+	var tx *sql.Tx
+	err := p.policy.do(ctx, func() error {
+		var err error
+		tx, err = beginner.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+// GetDBConn implements gorm.GetDBConnector so db.DB() keeps working
+func (p *busyRetryPool) GetDBConn() (*sql.DB, error) {
+	switch pool := p.ConnPool.(type) {
+	case *sql.DB:
+		return pool, nil
+	case gorm.GetDBConnector:
+		return pool.GetDBConn()
+	}
+	return nil, gorm.ErrInvalidDB
+}
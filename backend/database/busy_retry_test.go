@@ -0,0 +1,192 @@
+package database
+
+import (
+	"backend/models"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// flakyPool fails ExecContext with err for the first failures calls
+type flakyPool struct {
+	gorm.ConnPool
+	failures int
+	err      error
+	calls    int
+}
+
+func (p *flakyPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		return nil, p.err
+	}
+	return nil, nil
+}
+
+func TestIsBusy(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"Busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"Locked", sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{"Wrapped busy", fmt.Errorf("insert failed: %w", sqlite3.Error{Code: sqlite3.ErrBusy}), true},
+		{"Exhausted retries", fmt.Errorf("create failed: %w", ErrDatabaseBusy), true},
+		{"Constraint", sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{"Other", errors.New("disk full"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBusy(tt.err); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBusyRetryPool_ExecContext(t *testing.T) {
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	policy := BusyRetry{Attempts: 3, BaseDelay: time.Millisecond}
+
+	tests := []struct {
+		name          string
+		failures      int
+		err           error
+		expectedCalls int
+		expectBusy    bool
+		expectErr     bool
+	}{
+		{"Succeeds first time", 0, busy, 1, false, false},
+		{"Succeeds after retries", 2, busy, 3, false, false},
+		{"Exhausts retries", 5, busy, 3, true, true},
+		{"Non-busy error not retried", 5, errors.New("constraint failed"), 1, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &flakyPool{failures: tt.failures, err: tt.err}
+			pool := &busyRetryPool{ConnPool: inner, policy: policy}
+
+			_, err := pool.ExecContext(context.Background(), "UPDATE cards SET name = ?", "x")
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+			if tt.expectBusy && !errors.Is(err, ErrDatabaseBusy) {
+				t.Errorf("expected ErrDatabaseBusy, got %v", err)
+			}
+			if inner.calls != tt.expectedCalls {
+				t.Errorf("expected %d calls, got %d", tt.expectedCalls, inner.calls)
+			}
+		})
+	}
+}
+
+func TestBusyRetryPool_ContextCancelled(t *testing.T) {
+	inner := &flakyPool{failures: 10, err: sqlite3.Error{Code: sqlite3.ErrBusy}}
+	pool := &busyRetryPool{ConnPool: inner, policy: BusyRetry{Attempts: 10, BaseDelay: time.Hour}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := pool.ExecContext(ctx, "DELETE FROM cards")
+	if !IsBusy(err) {
+		t.Errorf("expected the busy error to be returned, got %v", err)
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected 1 call before cancellation, got %d", inner.calls)
+	}
+}
+
+// openContendedDB opens a GORM connection with BusyRetry and a second raw connection
+// holding the write lock on the same file
+func openContendedDB(t *testing.T) (*gorm.DB, *sql.Tx) {
+	t.Helper()
+
+	dbPath := filepath.Join(t.TempDir(), "busy.db")
+	db, err := gorm.Open(sqlite.Open(dbPath+"?_busy_timeout=10&_txlock=immediate"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.Use(BusyRetry{Attempts: 5, BaseDelay: 20 * time.Millisecond}); err != nil {
+		t.Fatalf("failed to register busy retry: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database instance: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&models.StorageLocation{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	locker, err := sql.Open("sqlite3", dbPath+"?_txlock=immediate")
+	if err != nil {
+		t.Fatalf("failed to open locking connection: %v", err)
+	}
+	t.Cleanup(func() { locker.Close() })
+
+	lock, err := locker.Begin()
+	if err != nil {
+		t.Fatalf("failed to take write lock: %v", err)
+	}
+	t.Cleanup(func() { lock.Rollback() })
+
+	return db, lock
+}
+
+func TestBusyRetry_WaitsForLock(t *testing.T) {
+	db, lock := openContendedDB(t)
+
+	// Release the lock partway through the retries
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Rollback()
+	}()
+
+	location := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("expected create to succeed once the lock was released, got %v", err)
+	}
+
+	if err := db.Exec("UPDATE storage_locations SET name = ? WHERE id = ?", "Box", location.ID).Error; err != nil {
+		t.Errorf("expected autocommit update to succeed, got %v", err)
+	}
+}
+
+func TestBusyRetry_PersistentContention(t *testing.T) {
+	db, _ := openContendedDB(t)
+
+	location := models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	err := db.Create(&location).Error
+	if !errors.Is(err, ErrDatabaseBusy) {
+		t.Fatalf("expected ErrDatabaseBusy, got %v", err)
+	}
+}
+
+func TestConnectionDSN(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"data/cards.db", "data/cards.db?_busy_timeout=1000&_txlock=immediate"},
+		{"file:cards.db?cache=shared", "file:cards.db?cache=shared&_busy_timeout=1000&_txlock=immediate"},
+	}
+
+	for _, tt := range tests {
+		if got := connectionDSN(tt.path); got != tt.expected {
+			t.Errorf("connectionDSN(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+}
@@ -1,5 +1,5 @@
 // Package database provides database connection and migration management for SQLite.
-// It handles database lifecycle, custom migrations, and GORM configuration.
+// It handles database lifecycle, versioned migrations, and GORM configuration.
 package database
 
 import (
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"backend/models"
@@ -21,8 +22,22 @@ type Client struct {
 	DB *gorm.DB
 }
 
-// NewClient creates a new database client
+// NewClient opens the database and applies any pending migrations
 func NewClient(dbPath string) (*Client, error) {
+	client, err := Open(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(client.DB); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return client, nil
+}
+
+// Open connects to the database without migrating it
+func Open(dbPath string) (*Client, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -30,13 +45,18 @@ func NewClient(dbPath string) (*Client, error) {
 	}
 
 	// Connect to database with silent logger (only logs errors)
-	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
+	db, err := gorm.Open(sqlite.Open(connectionDSN(dbPath)), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Error),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Retry writes that collide with long-running import transactions
+	if err := db.Use(BusyRetry{}); err != nil {
+		return nil, fmt.Errorf("failed to register busy retry: %w", err)
+	}
+
 	// Configure connection pool
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -48,16 +68,20 @@ func NewClient(dbPath string) (*Client, error) {
 	sqlDB.SetMaxIdleConns(1)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Run migrations
-	if err := migrate(db); err != nil {
-		sqlDB.Close()
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
 	slog.Info("connected to database", "path", dbPath)
 	return &Client{DB: db}, nil
 }
 
+// connectionDSN appends the driver options to dbPath. Transactions take the write
+// lock when they begin so busy errors surface where BusyRetry can safely retry them.
+func connectionDSN(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + "_busy_timeout=1000&_txlock=immediate"
+}
+
 // Close closes the database connection
 func (c *Client) Close() error {
 	sqlDB, err := c.DB.DB()
@@ -68,19 +92,35 @@ func (c *Client) Close() error {
 	return sqlDB.Close()
 }
 
-// migrate runs database migrations
-func migrate(db *gorm.DB) error {
-	// Run auto-migrations for all models
+// autoMigrateBaseline is the baseline migration: it creates the tables that existed
+// when versioned migrations were introduced and brings databases from before them up
+// to the same schema. Tables added since are created by their own migrations only, so
+// fresh and upgraded databases take the same route; never add a model here.
+func autoMigrateBaseline(db *gorm.DB) error {
+	// Run auto-migrations for the baseline models
 	if err := db.AutoMigrate(
 		&models.StorageLocation{},
 		&models.SortingRule{},
+		&models.NamedPredicate{},
 		&models.Inventory{},
+		&models.InventoryEvent{},
+		&models.InventoryOperation{},
 		&models.List{},
 		&models.ListItem{},
+		&models.ListShare{},
+		&models.ShareLink{},
 		&models.Setting{},
 		&models.Job{},
 		&models.Card{},
 		&models.Set{},
+		&models.Loan{},
+		&models.LoanItem{},
+		&models.Notification{},
+		&models.LegalityChange{},
+		&models.ImportDigest{},
+		&models.InventoryCount{},
+		&models.RulePerformance{},
+		&models.DashboardWidget{},
 	); err != nil {
 		return fmt.Errorf("auto-migrate failed: %w", err)
 	}
@@ -158,7 +198,94 @@ func customMigrations(db *gorm.DB) error {
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_cards_set_code ON cards(set_code)").Error; err != nil {
 		return fmt.Errorf("failed to create set_code index: %w", err)
 	}
+	// Expression index backing the border_color print filter
+	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_cards_border_color ON cards(json_extract(raw_json, '$.border_color'))").Error; err != nil {
+		return fmt.Errorf("failed to create border_color index: %w", err)
+	}
+	// Rarity and cmc used to be expression indexes over raw_json; they now index the extracted columns
+	for _, column := range []string{"rarity", "cmc"} {
+		if err := replaceExpressionIndex(db, "idx_cards_"+column, column); err != nil {
+			return err
+		}
+	}
 
+	if err := backfillCardColumns(db); err != nil {
+		return err
+	}
+	if err := backfillCardCurrencyColumns(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replaceExpressionIndex recreates a legacy json_extract index on the cards column of the same name
+func replaceExpressionIndex(db *gorm.DB, indexName, column string) error {
+	var definition string
+	if err := db.Raw("SELECT COALESCE(MAX(sql), '') FROM sqlite_master WHERE type='index' AND name=?", indexName).Scan(&definition).Error; err != nil {
+		return fmt.Errorf("failed to read %s definition: %w", indexName, err)
+	}
+	if definition != "" && !strings.Contains(definition, "json_extract") {
+		return nil
+	}
+
+	if err := db.Exec("DROP INDEX IF EXISTS " + indexName).Error; err != nil {
+		return fmt.Errorf("failed to drop %s: %w", indexName, err)
+	}
+	if err := db.Exec(fmt.Sprintf("CREATE INDEX %s ON cards(%s)", indexName, column)).Error; err != nil {
+		return fmt.Errorf("failed to create %s: %w", indexName, err)
+	}
+	return nil
+}
+
+// backfillCardColumns fills the extracted card columns for rows imported before they existed.
+// New imports populate them directly, so only rows with no rarity at all are touched.
+func backfillCardColumns(db *gorm.DB) error {
+	result := db.Exec(`
+		UPDATE cards SET
+			collector_number = COALESCE(json_extract(raw_json, '$.collector_number'), ''),
+			rarity = COALESCE(json_extract(raw_json, '$.rarity'), ''),
+			cmc = COALESCE(json_extract(raw_json, '$.cmc'), 0),
+			colors = (
+				SELECT COALESCE(group_concat(value, ''), '') FROM (
+					SELECT value FROM json_each(COALESCE(
+						json_extract(raw_json, '$.colors'),
+						json_extract(raw_json, '$.card_faces[0].colors'),
+						'[]'))
+					WHERE instr('WUBRG', value) > 0
+					ORDER BY instr('WUBRG', value)
+				)
+			),
+			price_usd = CAST(json_extract(raw_json, '$.prices.usd') AS REAL),
+			price_usd_foil = CAST(json_extract(raw_json, '$.prices.usd_foil') AS REAL),
+			price_usd_etched = CAST(json_extract(raw_json, '$.prices.usd_etched') AS REAL)
+		WHERE rarity IS NULL
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill card columns: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("backfilled extracted card columns", "rows", result.RowsAffected)
+	}
 	return nil
 }
 
+// backfillCardCurrencyColumns fills the EUR and tix price columns, which were added after
+// the USD ones. Rows already holding either price, or whose data has neither, are skipped.
+func backfillCardCurrencyColumns(db *gorm.DB) error {
+	result := db.Exec(`
+		UPDATE cards SET
+			price_eur = CAST(json_extract(raw_json, '$.prices.eur') AS REAL),
+			price_eur_foil = CAST(json_extract(raw_json, '$.prices.eur_foil') AS REAL),
+			price_tix = CAST(json_extract(raw_json, '$.prices.tix') AS REAL)
+		WHERE price_eur IS NULL AND price_tix IS NULL
+			AND (json_extract(raw_json, '$.prices.eur') IS NOT NULL OR json_extract(raw_json, '$.prices.tix') IS NOT NULL)
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill card currency columns: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("backfilled card currency columns", "rows", result.RowsAffected)
+	}
+	return nil
+}
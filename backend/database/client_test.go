@@ -4,6 +4,7 @@ import (
 	"backend/models"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -218,6 +219,10 @@ func TestCustomMigrations_Indexes(t *testing.T) {
 	expectedIndexes := []string{
 		"idx_cards_name",
 		"idx_cards_set_code",
+		"idx_cards_rarity",
+		"idx_cards_cmc",
+		"idx_cards_collector_number",
+		"idx_cards_price_usd",
 	}
 
 	for _, indexName := range expectedIndexes {
@@ -249,9 +254,10 @@ func TestCustomMigrations_DropsLegacyTable(t *testing.T) {
 		t.Fatal("expected legacy table to exist before migration")
 	}
 
+	forgetMigrations(t, client)
 	client.Close()
 
-	// Run migrations again (simulating app restart)
+	// Run migrations again (simulating an upgrade from before versioned migrations)
 	client, err = NewClient(dbPath)
 	if err != nil {
 		t.Fatalf("failed to create client: %v", err)
@@ -494,3 +500,62 @@ func TestMigrate_SetNullDeletes(t *testing.T) {
 		t.Errorf("expected storage_location_id to be NULL after storage deletion, got %v", *loadedInventory.StorageLocationID)
 	}
 }
+
+func TestCustomMigrations_BackfillsCardColumns(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	client, err := NewClient(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// Simulate a card imported before the extracted columns existed
+	if err := client.DB.Exec(`INSERT INTO cards (scryfall_id, oracle_id, raw_json)
+		VALUES ('legacy-id', 'legacy-oracle', ?)`,
+		`{"name": "Lightning Helix", "collector_number": "213", "rarity": "uncommon", "cmc": 2,
+			"colors": ["W", "R"], "prices": {"usd": "0.50", "usd_foil": "2.00", "usd_etched": null}}`).Error; err != nil {
+		t.Fatalf("failed to insert legacy card: %v", err)
+	}
+	// Point the rarity index back at raw_json, as older databases had it
+	if err := client.DB.Exec("DROP INDEX idx_cards_rarity").Error; err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+	if err := client.DB.Exec("CREATE INDEX idx_cards_rarity ON cards(json_extract(raw_json, '$.rarity'))").Error; err != nil {
+		t.Fatalf("failed to create legacy index: %v", err)
+	}
+	forgetMigrations(t, client)
+	client.Close()
+
+	client, err = NewClient(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen client: %v", err)
+	}
+	defer client.Close()
+
+	var card models.Card
+	if err := client.DB.First(&card, "scryfall_id = ?", "legacy-id").Error; err != nil {
+		t.Fatalf("failed to load card: %v", err)
+	}
+	if card.CollectorNumber != "213" || card.Rarity != "uncommon" || card.CMC != 2 {
+		t.Errorf("unexpected columns: collector_number=%q rarity=%q cmc=%v", card.CollectorNumber, card.Rarity, card.CMC)
+	}
+	if card.Colors != "WR" {
+		t.Errorf("expected colors 'WR', got '%s'", card.Colors)
+	}
+	if card.PriceUSD == nil || *card.PriceUSD != 0.50 {
+		t.Errorf("expected price_usd 0.50, got %v", card.PriceUSD)
+	}
+	if card.PriceUSDFoil == nil || *card.PriceUSDFoil != 2.00 {
+		t.Errorf("expected price_usd_foil 2.00, got %v", card.PriceUSDFoil)
+	}
+	if card.PriceUSDEtched != nil {
+		t.Errorf("expected no etched price, got %v", *card.PriceUSDEtched)
+	}
+
+	var definition string
+	client.DB.Raw("SELECT sql FROM sqlite_master WHERE type='index' AND name='idx_cards_rarity'").Scan(&definition)
+	if strings.Contains(definition, "json_extract") {
+		t.Errorf("expected rarity index on the column, got %s", definition)
+	}
+}
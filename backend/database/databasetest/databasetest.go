@@ -0,0 +1,41 @@
+// Package databasetest provides databases for tests that need the real schema
+package databasetest
+
+import (
+	"testing"
+
+	"backend/database"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Open returns an in-memory database with every migration applied, closed when the
+// test ends
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	Migrate(t, db)
+	return db
+}
+
+// Migrate applies every migration to db. Tests that touch cards need this rather than
+// AutoMigrate: the generated name and set_code columns and the card indexes are
+// created by the migrations, not by the models.
+func Migrate(t testing.TB, db *gorm.DB) {
+	t.Helper()
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+}
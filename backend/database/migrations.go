@@ -0,0 +1,443 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownMigration means the database has migrations applied that this version of
+	// the app doesn't know, i.e. it was last run by a newer version
+	ErrUnknownMigration = errors.New("database has unknown migrations applied")
+	// ErrIrreversibleMigration means a rollback reached a migration with no Down step
+	ErrIrreversibleMigration = errors.New("migration cannot be rolled back")
+)
+
+// Migration is one versioned schema change. Migrations run in the order they are
+// listed in migrations, each in its own transaction, and are recorded by ID in
+// schema_migrations once applied.
+type Migration struct {
+	ID          string // Sortable and never reused, e.g. "0002_rename_loans_borrower"
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error // nil if the migration cannot be reverted
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	ID        string `gorm:"primaryKey"`
+	AppliedAt time.Time
+}
+
+// MigrationState reports whether a migration has been applied to a database
+type MigrationState struct {
+	ID          string
+	Description string
+	AppliedAt   *time.Time // nil while pending
+	Known       bool       // false for a migration applied by a newer version
+}
+
+// migrations is the ordered schema history. Append new migrations to the end and
+// never edit one that has shipped.
+//
+// The baseline creates its tables from the current models, so later migrations that
+// change those tables must tolerate a schema that already matches them: guard
+// additions with HasColumn, and rename columns with renameColumn. New tables get a
+// migration of their own rather than a place in the baseline.
+var migrations = []Migration{
+	{
+		ID:          "0001_baseline",
+		Description: "Create the schema from the models and upgrade databases that predate versioned migrations",
+		Up:          autoMigrateBaseline,
+	},
+	{
+		ID:          "0002_create_rule_groups",
+		Description: "Create rule_groups for sorting rule groups with AND/OR composition",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.RuleGroup{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.RuleGroup{})
+		},
+	},
+	{
+		ID:          "0003_unique_sorting_rule_priorities",
+		Description: "Renumber sorting rules that share a priority and make priorities unique",
+		Up:          uniqueSortingRulePriorities,
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_sorting_rules_priority").Error; err != nil {
+				return err
+			}
+			return tx.Exec("CREATE INDEX idx_sorting_rules_priority ON sorting_rules(priority)").Error
+		},
+	},
+	{
+		ID:          "0004_create_job_results",
+		Description: "Create job_results for results of background jobs such as async resorts",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.JobResult{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.JobResult{})
+		},
+	},
+	{
+		ID:          "0005_create_tags",
+		Description: "Create tags and inventory_tags for tagging inventory items",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Tag{}, &models.InventoryTag{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.InventoryTag{}, &models.Tag{})
+		},
+	},
+	{
+		ID:          "0006_create_decks",
+		Description: "Create decks and deck_cards for format-aware deck building",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Deck{}, &models.DeckCard{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.DeckCard{}, &models.Deck{})
+		},
+	},
+	{
+		ID:          "0007_card_identity_and_type_columns",
+		Description: "Extract color identity and primary card type into card columns for collection statistics",
+		Up:          addCardIdentityAndTypeColumns,
+		Down: func(tx *gorm.DB) error {
+			for _, column := range []string{"color_identity", "card_type"} {
+				if err := tx.Exec("DROP INDEX IF EXISTS idx_cards_" + column).Error; err != nil {
+					return err
+				}
+				if err := tx.Exec("ALTER TABLE cards DROP COLUMN " + column).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		ID:          "0008_create_dashboard_stats_snapshots",
+		Description: "Create dashboard_stats_snapshots for cached dashboard stats",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.DashboardStatsSnapshot{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.DashboardStatsSnapshot{})
+		},
+	},
+	{
+		ID:          "0009_create_value_alerts",
+		Description: "Create value_alerts and the price_snapshots they measure price changes against",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ValueAlert{}, &models.PriceSnapshot{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.PriceSnapshot{}, &models.ValueAlert{})
+		},
+	},
+	{
+		ID:          "0010_create_webhooks",
+		Description: "Create webhooks and their delivery log",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.WebhookDelivery{}, &models.Webhook{})
+		},
+	},
+	{
+		ID:          "0011_create_intake_sessions",
+		Description: "Create intake_sessions and the items they stage until commit",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.IntakeSession{}, &models.IntakeSessionItem{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.IntakeSessionItem{}, &models.IntakeSession{})
+		},
+	},
+	{
+		ID:          "0012_create_transactions",
+		Description: "Create transactions and transaction_items for sales and trades",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Transaction{}, &models.TransactionItem{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&models.TransactionItem{}, &models.Transaction{})
+		},
+	},
+	{
+		ID:          "0013_card_name_lookup_indexes",
+		Description: "Index card names and front face names case-insensitively for lookups by name",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE INDEX IF NOT EXISTS idx_cards_name_nocase ON cards(name COLLATE NOCASE)").Error; err != nil {
+				return err
+			}
+			return tx.Exec("CREATE INDEX IF NOT EXISTS idx_cards_front_face_name ON cards(json_extract(raw_json, '$.card_faces[0].name') COLLATE NOCASE)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, index := range []string{"idx_cards_name_nocase", "idx_cards_front_face_name"} {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// Migrate applies every pending migration in order. It refuses to touch a database
+// that has migrations applied which this version doesn't know.
+func Migrate(db *gorm.DB) error {
+	return runMigrations(db, migrations)
+}
+
+// Rollback reverts the most recently applied steps migrations, newest first, and
+// returns the IDs it reverted
+func Rollback(db *gorm.DB, steps int) ([]string, error) {
+	return rollbackMigrations(db, migrations, steps)
+}
+
+// MigrationStatus lists every known migration in order, followed by any applied
+// migration this version doesn't know
+func MigrationStatus(db *gorm.DB) ([]MigrationState, error) {
+	return migrationStatus(db, migrations)
+}
+
+// IsKnownMigration reports whether id is one of this version's migrations
+func IsKnownMigration(id string) bool {
+	for _, m := range migrations {
+		if m.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func runMigrations(db *gorm.DB, list []Migration) error {
+	states, err := migrationStatus(db, list)
+	if err != nil {
+		return err
+	}
+	if unknown := unknownMigrations(states); len(unknown) > 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownMigration, strings.Join(unknown, ", "))
+	}
+
+	for i, m := range list {
+		if states[i].AppliedAt != nil {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{ID: m.ID, AppliedAt: time.Now().UTC()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", m.ID, err)
+		}
+		slog.Info("applied migration", "id", m.ID)
+	}
+	return nil
+}
+
+func rollbackMigrations(db *gorm.DB, list []Migration, steps int) ([]string, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var applied []SchemaMigration
+	if err := db.Order("id DESC").Limit(steps).Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	byID := make(map[string]Migration, len(list))
+	for _, m := range list {
+		byID[m.ID] = m
+	}
+
+	var reverted []string
+	for _, record := range applied {
+		m, ok := byID[record.ID]
+		if !ok {
+			return reverted, fmt.Errorf("%w: %s", ErrUnknownMigration, record.ID)
+		}
+		if m.Down == nil {
+			return reverted, fmt.Errorf("%w: %s", ErrIrreversibleMigration, m.ID)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, "id = ?", m.ID).Error
+		})
+		if err != nil {
+			return reverted, fmt.Errorf("rolling back migration %s failed: %w", m.ID, err)
+		}
+		slog.Info("rolled back migration", "id", m.ID)
+		reverted = append(reverted, m.ID)
+	}
+	return reverted, nil
+}
+
+func migrationStatus(db *gorm.DB, list []Migration) ([]MigrationState, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var applied []SchemaMigration
+	if err := db.Order("id").Find(&applied).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	appliedAt := make(map[string]time.Time, len(applied))
+	for _, record := range applied {
+		appliedAt[record.ID] = record.AppliedAt
+	}
+
+	states := make([]MigrationState, 0, len(list))
+	for _, m := range list {
+		state := MigrationState{ID: m.ID, Description: m.Description, Known: true}
+		if at, ok := appliedAt[m.ID]; ok {
+			state.AppliedAt = &at
+			delete(appliedAt, m.ID)
+		}
+		states = append(states, state)
+	}
+	for _, record := range applied {
+		if _, unknown := appliedAt[record.ID]; unknown {
+			at := record.AppliedAt
+			states = append(states, MigrationState{ID: record.ID, AppliedAt: &at})
+		}
+	}
+	return states, nil
+}
+
+// unknownMigrations returns the IDs of applied migrations missing from the list
+func unknownMigrations(states []MigrationState) []string {
+	var ids []string
+	for _, state := range states {
+		if !state.Known {
+			ids = append(ids, state.ID)
+		}
+	}
+	return ids
+}
+
+// renameColumn renames a column in a way that is safe to run against any database
+// the baseline produced. A database upgraded from before versioned migrations may
+// already hold the new column, added empty by the baseline, next to the old one; the
+// old values are then copied across and the old column dropped. Nothing happens when
+// the old column is already gone. The arguments must be trusted constants.
+func renameColumn(tx *gorm.DB, table, from, to string) error {
+	cols, err := tableColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	if !cols[from] {
+		return nil
+	}
+
+	if !cols[to] {
+		if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, from, to)).Error; err != nil {
+			return fmt.Errorf("failed to rename %s.%s: %w", table, from, err)
+		}
+		return nil
+	}
+
+	if err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = COALESCE(%s, %s)", table, to, from, to)).Error; err != nil {
+		return fmt.Errorf("failed to copy %s.%s into %s: %w", table, from, to, err)
+	}
+	if err := tx.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, from)).Error; err != nil {
+		return fmt.Errorf("failed to drop %s.%s: %w", table, from, err)
+	}
+	return nil
+}
+
+// uniqueSortingRulePriorities replaces the plain priority index with a unique one.
+// When rules share a priority, every rule is first renumbered in evaluation order
+// (priority, then ID) with a spacing of 10, as the API renumbers rules.
+func uniqueSortingRulePriorities(tx *gorm.DB) error {
+	var shared int64
+	if err := tx.Raw("SELECT COUNT(*) FROM (SELECT priority FROM sorting_rules GROUP BY priority HAVING COUNT(*) > 1)").
+		Scan(&shared).Error; err != nil {
+		return fmt.Errorf("failed to check sorting rule priorities: %w", err)
+	}
+
+	if shared > 0 {
+		var ids []uint
+		if err := tx.Table("sorting_rules").Order("priority, id").Pluck("id", &ids).Error; err != nil {
+			return fmt.Errorf("failed to read sorting rules: %w", err)
+		}
+		for i, id := range ids {
+			if err := tx.Exec("UPDATE sorting_rules SET priority = ? WHERE id = ?", (i+1)*10, id).Error; err != nil {
+				return fmt.Errorf("failed to renumber sorting rule %d: %w", id, err)
+			}
+		}
+	}
+
+	if err := tx.Exec("DROP INDEX IF EXISTS idx_sorting_rules_priority").Error; err != nil {
+		return err
+	}
+	return tx.Exec("CREATE UNIQUE INDEX idx_sorting_rules_priority ON sorting_rules(priority)").Error
+}
+
+// addCardIdentityAndTypeColumns adds the color_identity and card_type columns to
+// cards and fills them for cards imported before they existed, classifying type
+// lines the way models.PrimaryCardType does
+func addCardIdentityAndTypeColumns(tx *gorm.DB) error {
+	for _, field := range []string{"ColorIdentity", "CardType"} {
+		if !tx.Migrator().HasColumn(&models.Card{}, field) {
+			if err := tx.Migrator().AddColumn(&models.Card{}, field); err != nil {
+				return fmt.Errorf("failed to add cards.%s: %w", field, err)
+			}
+		}
+		if !tx.Migrator().HasIndex(&models.Card{}, field) {
+			if err := tx.Migrator().CreateIndex(&models.Card{}, field); err != nil {
+				return fmt.Errorf("failed to index cards.%s: %w", field, err)
+			}
+		}
+	}
+
+	frontTypeLine := `LOWER(CASE WHEN instr(type_line, ' // ') > 0
+		THEN substr(type_line, 1, instr(type_line, ' // ') - 1) ELSE type_line END)`
+	var cases strings.Builder
+	for _, cardType := range models.CardTypes {
+		fmt.Fprintf(&cases, "WHEN instr(%s, '%s') > 0 THEN '%s' ", frontTypeLine, cardType, cardType)
+	}
+
+	result := tx.Exec(fmt.Sprintf(`
+		UPDATE cards SET
+			color_identity = (
+				SELECT COALESCE(group_concat(value, ''), '') FROM (
+					SELECT value FROM json_each(COALESCE(json_extract(raw_json, '$.color_identity'), '[]'))
+					WHERE instr('WUBRG', value) > 0
+					ORDER BY instr('WUBRG', value)
+				)
+			),
+			card_type = (
+				SELECT CASE %s ELSE '%s' END FROM (
+					SELECT COALESCE(json_extract(raw_json, '$.type_line'),
+						json_extract(raw_json, '$.card_faces[0].type_line'), '') AS type_line
+				)
+			)
+		WHERE card_type IS NULL OR card_type = ''
+	`, cases.String(), models.CardTypeOther))
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill card identity and type: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		slog.Info("backfilled card identity and type columns", "rows", result.RowsAffected)
+	}
+	return nil
+}
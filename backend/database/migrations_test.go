@@ -0,0 +1,388 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"backend/models"
+
+	"gorm.io/gorm"
+)
+
+// forgetMigrations drops the migration history, leaving the database as it was before
+// versioned migrations so the next open runs the baseline again
+func forgetMigrations(t *testing.T, client *Client) {
+	t.Helper()
+
+	if err := client.DB.Exec("DROP TABLE schema_migrations").Error; err != nil {
+		t.Fatalf("failed to drop schema_migrations: %v", err)
+	}
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	client, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client.DB
+}
+
+// testMigrations creates a widgets table and then adds a colour column to it
+func testMigrations() []Migration {
+	return []Migration{
+		{
+			ID: "0001_widgets",
+			Up: func(tx *gorm.DB) error {
+				return tx.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Exec("DROP TABLE widgets").Error
+			},
+		},
+		{
+			ID: "0002_widget_colour",
+			Up: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE widgets ADD COLUMN colour TEXT").Error
+			},
+			Down: func(tx *gorm.DB) error {
+				return tx.Exec("ALTER TABLE widgets DROP COLUMN colour").Error
+			},
+		},
+	}
+}
+
+func TestMigrations_IDsOrderedAndUnique(t *testing.T) {
+	for i, m := range migrations {
+		if m.ID == "" || m.Up == nil {
+			t.Errorf("migration %d needs an ID and an Up step", i)
+		}
+		if i > 0 && m.ID <= migrations[i-1].ID {
+			t.Errorf("migration %s must sort after %s", m.ID, migrations[i-1].ID)
+		}
+	}
+}
+
+func TestRunMigrations_AppliesOnceInOrder(t *testing.T) {
+	db := openTestDB(t)
+	list := testMigrations()
+
+	if err := runMigrations(db, list); err != nil {
+		t.Fatalf("runMigrations failed: %v", err)
+	}
+	// A second run has nothing to do; re-running 0002 would fail on the duplicate column
+	if err := runMigrations(db, list); err != nil {
+		t.Fatalf("second runMigrations failed: %v", err)
+	}
+
+	cols, err := tableColumns(db, "widgets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cols["colour"] {
+		t.Error("expected the colour column to exist")
+	}
+
+	states, err := migrationStatus(db, list)
+	if err != nil {
+		t.Fatalf("migrationStatus failed: %v", err)
+	}
+	for _, state := range states {
+		if state.AppliedAt == nil || !state.Known {
+			t.Errorf("expected %s applied and known, got %+v", state.ID, state)
+		}
+	}
+}
+
+func TestRunMigrations_FailureRollsBack(t *testing.T) {
+	db := openTestDB(t)
+	list := append(testMigrations(), Migration{
+		ID: "0003_broken",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("ALTER TABLE widgets ADD COLUMN size INTEGER").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	})
+
+	err := runMigrations(db, list)
+	if err == nil || !strings.Contains(err.Error(), "0003_broken") {
+		t.Fatalf("expected the failing migration named in the error, got %v", err)
+	}
+
+	cols, _ := tableColumns(db, "widgets")
+	if cols["size"] {
+		t.Error("expected the failed migration's change rolled back")
+	}
+	states, _ := migrationStatus(db, list)
+	if states[1].AppliedAt == nil || states[2].AppliedAt != nil {
+		t.Errorf("expected only the first two migrations recorded, got %+v", states)
+	}
+}
+
+func TestRunMigrations_RefusesUnknownMigrations(t *testing.T) {
+	db := openTestDB(t)
+	if err := runMigrations(db, testMigrations()); err != nil {
+		t.Fatalf("runMigrations failed: %v", err)
+	}
+
+	// An older version only knows the first migration
+	err := runMigrations(db, testMigrations()[:1])
+	if !errors.Is(err, ErrUnknownMigration) {
+		t.Fatalf("expected ErrUnknownMigration, got %v", err)
+	}
+
+	states, _ := migrationStatus(db, testMigrations()[:1])
+	if len(states) != 2 || states[1].ID != "0002_widget_colour" || states[1].Known {
+		t.Errorf("expected the unknown migration listed last, got %+v", states)
+	}
+}
+
+func TestRollbackMigrations(t *testing.T) {
+	db := openTestDB(t)
+	list := testMigrations()
+	if err := runMigrations(db, list); err != nil {
+		t.Fatalf("runMigrations failed: %v", err)
+	}
+
+	reverted, err := rollbackMigrations(db, list, 1)
+	if err != nil {
+		t.Fatalf("rollbackMigrations failed: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0] != "0002_widget_colour" {
+		t.Errorf("expected the newest migration reverted, got %v", reverted)
+	}
+	cols, _ := tableColumns(db, "widgets")
+	if cols["colour"] {
+		t.Error("expected the colour column dropped")
+	}
+
+	// Re-applying after a rollback runs the migration again
+	if err := runMigrations(db, list); err != nil {
+		t.Fatalf("runMigrations after rollback failed: %v", err)
+	}
+	cols, _ = tableColumns(db, "widgets")
+	if !cols["colour"] {
+		t.Error("expected the colour column restored")
+	}
+
+	if _, err := rollbackMigrations(db, list, 0); err == nil {
+		t.Error("expected an error for zero steps")
+	}
+}
+
+func TestRollbackMigrations_Irreversible(t *testing.T) {
+	db := openTestDB(t)
+	list := testMigrations()
+	list[0].Down = nil
+	if err := runMigrations(db, list); err != nil {
+		t.Fatalf("runMigrations failed: %v", err)
+	}
+
+	reverted, err := rollbackMigrations(db, list, 2)
+	if !errors.Is(err, ErrIrreversibleMigration) {
+		t.Fatalf("expected ErrIrreversibleMigration, got %v", err)
+	}
+	if len(reverted) != 1 {
+		t.Errorf("expected the reversible migration reverted before stopping, got %v", reverted)
+	}
+}
+
+func TestRollback_Baseline(t *testing.T) {
+	client, err := NewClient(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	// Later migrations roll back, stopping at the baseline
+	reverted, err := Rollback(client.DB, len(migrations))
+	if !errors.Is(err, ErrIrreversibleMigration) {
+		t.Errorf("expected the baseline to be irreversible, got %v", err)
+	}
+	if len(reverted) != len(migrations)-1 {
+		t.Errorf("expected every migration after the baseline reverted, got %v", reverted)
+	}
+}
+
+// TestMigrate_UpgradesFromWebhooks upgrades a database last migrated by a version
+// whose newest migration was 0010, as an existing install would be
+func TestMigrate_UpgradesFromWebhooks(t *testing.T) {
+	db := openTestDB(t)
+	upgradeFrom := slices.IndexFunc(migrations, func(m Migration) bool { return m.ID == "0010_create_webhooks" })
+	if err := runMigrations(db, migrations[:upgradeFrom+1]); err != nil {
+		t.Fatalf("migrating to 0010 failed: %v", err)
+	}
+
+	tables := []string{"intake_sessions", "intake_session_items", "transactions", "transaction_items"}
+	for _, table := range tables {
+		if db.Migrator().HasTable(table) {
+			t.Fatalf("expected %s to be created after 0010", table)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	for _, table := range tables {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("expected the upgrade to create %s", table)
+		}
+	}
+}
+
+// TestMigrate_BaselineLeavesLaterTables checks tables added after the baseline come
+// from their own migrations, so a fresh database runs them like an upgraded one does
+func TestMigrate_BaselineLeavesLaterTables(t *testing.T) {
+	db := openTestDB(t)
+	if err := runMigrations(db, migrations[:1]); err != nil {
+		t.Fatalf("running the baseline failed: %v", err)
+	}
+
+	later := []any{
+		&models.RuleGroup{}, &models.JobResult{}, &models.Tag{}, &models.InventoryTag{}, &models.Deck{}, &models.DeckCard{},
+		&models.DashboardStatsSnapshot{}, &models.ValueAlert{}, &models.PriceSnapshot{}, &models.Webhook{}, &models.WebhookDelivery{},
+		&models.IntakeSession{}, &models.IntakeSessionItem{}, &models.Transaction{}, &models.TransactionItem{},
+	}
+	for _, model := range later {
+		if db.Migrator().HasTable(model) {
+			t.Errorf("expected the baseline not to create the %T table", model)
+		}
+	}
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	for _, model := range later {
+		if !db.Migrator().HasTable(model) {
+			t.Errorf("expected a later migration to create the %T table", model)
+		}
+	}
+}
+
+func TestRenameColumn(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		rows   string
+		want   string // Comma-separated borrower values by id
+	}{
+		{"Old column only", "id INTEGER PRIMARY KEY, lender TEXT", "(1, 'ann'), (2, 'bob')", "ann,bob"},
+		{"Both columns after a baseline upgrade", "id INTEGER PRIMARY KEY, lender TEXT, borrower TEXT", "(1, 'ann', NULL), (2, 'bob', NULL)", "ann,bob"},
+		{"Already renamed", "id INTEGER PRIMARY KEY, borrower TEXT", "(1, 'ann'), (2, 'bob')", "ann,bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			if err := db.Exec("CREATE TABLE people (" + tt.schema + ")").Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Exec("INSERT INTO people VALUES " + tt.rows).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			// Running twice shows the rename is safe to repeat
+			for range 2 {
+				if err := renameColumn(db, "people", "lender", "borrower"); err != nil {
+					t.Fatalf("renameColumn failed: %v", err)
+				}
+			}
+
+			cols, _ := tableColumns(db, "people")
+			if cols["lender"] || !cols["borrower"] {
+				t.Errorf("expected only the borrower column, got %v", cols)
+			}
+			var values []string
+			db.Raw("SELECT borrower FROM people ORDER BY id").Scan(&values)
+			if got := strings.Join(values, ","); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUniqueSortingRulePriorities(t *testing.T) {
+	db := openTestDB(t)
+	// A table as it was before priorities were unique
+	for _, statement := range []string{
+		"CREATE TABLE sorting_rules (id INTEGER PRIMARY KEY, priority INTEGER NOT NULL)",
+		"CREATE INDEX idx_sorting_rules_priority ON sorting_rules(priority)",
+		"INSERT INTO sorting_rules VALUES (1, 5), (2, 1), (3, 5), (4, 3)",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := uniqueSortingRulePriorities(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	// Evaluation order is kept, with ties broken by ID
+	var priorities []int
+	db.Raw("SELECT priority FROM sorting_rules ORDER BY id").Scan(&priorities)
+	if got := fmt.Sprint(priorities); got != "[30 10 40 20]" {
+		t.Errorf("expected priorities [30 10 40 20], got %s", got)
+	}
+	if err := db.Exec("INSERT INTO sorting_rules VALUES (5, 10)").Error; err == nil {
+		t.Error("expected a duplicate priority to be rejected")
+	}
+
+	// Distinct priorities are left alone
+	db.Exec("UPDATE sorting_rules SET priority = 7 WHERE id = 1")
+	if err := uniqueSortingRulePriorities(db); err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+	var priority int
+	db.Raw("SELECT priority FROM sorting_rules WHERE id = 1").Scan(&priority)
+	if priority != 7 {
+		t.Errorf("expected priority 7 to be kept, got %d", priority)
+	}
+}
+
+func TestAddCardIdentityAndTypeColumns(t *testing.T) {
+	db := openTestDB(t)
+	// A cards table as it was before the columns existed
+	for _, statement := range []string{
+		"CREATE TABLE cards (scryfall_id TEXT PRIMARY KEY, oracle_id TEXT, raw_json TEXT NOT NULL)",
+		`INSERT INTO cards VALUES
+			('elf', 'o1', '{"type_line": "Legendary Creature — Elf", "color_identity": ["G", "W"]}'),
+			('saga', 'o2', '{"card_faces": [{"type_line": "Enchantment — Saga"}, {"type_line": "Enchantment Creature — God"}]}'),
+			('wastes', 'o3', '{"type_line": "Basic Land", "color_identity": []}'),
+			('scheme', 'o4', '{"type_line": "Scheme"}')`,
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Running twice shows the migration tolerates columns that already exist
+	for range 2 {
+		if err := addCardIdentityAndTypeColumns(db); err != nil {
+			t.Fatalf("migration failed: %v", err)
+		}
+	}
+
+	var rows []struct {
+		ScryfallID    string
+		ColorIdentity string
+		CardType      string
+	}
+	db.Raw("SELECT scryfall_id, color_identity, card_type FROM cards ORDER BY scryfall_id").Scan(&rows)
+	got := make([]string, len(rows))
+	for i, row := range rows {
+		got[i] = row.ScryfallID + "=" + row.ColorIdentity + "/" + row.CardType
+	}
+	want := "elf=WG/creature saga=/enchantment scheme=/other wastes=/land"
+	if strings.Join(got, " ") != want {
+		t.Errorf("expected %q, got %q", want, strings.Join(got, " "))
+	}
+}
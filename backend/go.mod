@@ -7,6 +7,7 @@ require (
 	github.com/TwiN/gocache/v2 v2.4.0
 	github.com/expr-lang/expr v1.17.8
 	github.com/gofiber/fiber/v3 v3.1.0
+	github.com/mattn/go-sqlite3 v1.14.32
 	gorm.io/gorm v1.31.1
 )
 
@@ -18,7 +19,6 @@ require (
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
 	github.com/philhofer/fwd v1.2.0 // indirect
 	github.com/tinylib/msgp v1.6.3 // indirect
 	go.uber.org/ratelimit v0.3.1 // indirect
@@ -7,11 +7,16 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
+	// Embed the zone database so scheduler_timezone works in images without tzdata
+	_ "time/tzdata"
 
 	"backend/database"
+	"backend/models"
 	"backend/scryfall"
 	"backend/server"
 	"backend/services"
+	"backend/utils"
 	"backend/version"
 )
 
@@ -30,9 +35,9 @@ func parseLogLevel(s string) slog.Level {
 
 func main() {
 	logLevel := parseLogLevel(os.Getenv("LOG_LEVEL"))
-	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	slog.SetDefault(slog.New(utils.NewRequestIDLogHandler(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 		Level: logLevel,
-	})))
+	}))))
 
 	slog.Info("starting showmycards", "version", version.Version)
 
@@ -41,17 +46,31 @@ func main() {
 	if dbPath == "" {
 		dbPath = "./data/database.db"
 	}
-	dbClient, err := database.NewClient(dbPath)
+	dbClient, err := database.Open(dbPath)
 	if err != nil {
 		slog.Error("failed to initialize database", "error", err)
 		os.Exit(1)
 	}
+
+	// `showmycards migrate ...` manages the schema and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		code := runMigrateCommand(dbClient.DB, os.Args[2:], os.Stdout)
+		dbClient.Close()
+		os.Exit(code)
+	}
+
 	defer func() {
 		if err := dbClient.Close(); err != nil {
 			slog.Error("error closing database", "error", err)
 		}
 	}()
 
+	// Bring the schema up to date before any service touches it
+	if err := database.Migrate(dbClient.DB); err != nil {
+		slog.Error("failed to run migrations", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize Scryfall client
 	scryfallClient, err := scryfall.NewClient()
 	if err != nil {
@@ -71,6 +90,20 @@ func main() {
 	jobService := services.NewJobService(dbClient.DB)
 	bulkDataService := services.NewBulkDataService(dbClient.DB, jobService, settingsService)
 	setDataService := services.NewSetDataService(dbClient.DB, jobService, settingsService, scryfallClient, dataDir)
+	notificationService := services.NewNotificationService(dbClient.DB)
+	loanService := services.NewLoanService(dbClient.DB, notificationService)
+	inventoryHistoryService := services.NewInventoryHistoryService(dbClient.DB)
+	inventoryTrashService := services.NewInventoryTrashService(dbClient.DB)
+	valueAlertService := services.NewValueAlertService(dbClient.DB, notificationService)
+	backupService := services.NewBackupService(dbClient.DB, jobService, dataDir)
+	backupService.SetPassphrase(os.Getenv("EXPORT_ENCRYPTION_PASSPHRASE"))
+	cardImageService := services.NewCardImageService(dbClient.DB, jobService, dataDir)
+	webhookService := services.NewWebhookService(dbClient.DB)
+	jobService.SetWebhooks(webhookService)
+	failureAlerts := services.NewFailureAlertService(dbClient.DB, notificationService)
+	jobService.SetFailureAlerts(failureAlerts)
+	workers := services.NewWorkerPool(services.DefaultWorkerPoolSize)
+	jobService.SetWorkers(workers)
 
 	// Check database version compatibility
 	if err := version.CheckAndUpdate(context.Background(), settingsService); err != nil {
@@ -90,6 +123,17 @@ func main() {
 		slog.Info("cancelled stale jobs from previous run", "count", cancelledCount)
 	}
 
+	// Serve dashboard stats from snapshots, recomputed only after the data behind them
+	// changes. Import transactions can outlast the settle delay, so imports also
+	// invalidate once they complete.
+	dashboardCache := services.NewDashboardCacheService(dbClient.DB)
+	if err := dashboardCache.Watch(ctx); err != nil {
+		slog.Warn("failed to watch writes for dashboard cache, computing stats per request", "error", err)
+		dashboardCache = nil
+	} else {
+		bulkDataService.OnImportComplete(dashboardCache.Invalidate)
+	}
+
 	// Trigger initial bulk data import if no data exists
 	if err := bulkDataService.TriggerInitialImport(ctx); err != nil {
 		slog.Warn("failed to trigger initial import", "error", err)
@@ -101,9 +145,72 @@ func main() {
 	}
 
 	// Initialize server with database, scryfall clients, and services
-	srv := server.NewServer(ctx, dbClient, scryfallClient, settingsService, jobService, bulkDataService, setDataService, dataDir)
+	srv := server.NewServer(ctx, dbClient, scryfallClient, settingsService, jobService, bulkDataService, setDataService, loanService, notificationService, backupService, cardImageService, dashboardCache, webhookService, dataDir)
+
+	// Keep auto-tracked lists in step with inventory changes from every handler and service
+	if err := services.NewListSyncService(dbClient.DB).Watch(ctx); err != nil {
+		slog.Warn("failed to watch inventory for list sync", "error", err)
+	}
 
 	scheduler := services.NewScheduler(bulkDataService, setDataService, jobService, settingsService)
+	scheduler.SetFailureAlerts(failureAlerts)
+	scheduler.AddTask(services.ScheduledTask{
+		Name:     "loan_overdue_check",
+		Label:    "Overdue Loan Check",
+		Interval: 6 * time.Hour,
+		Run:      loanService.RunOverdueCheck,
+	})
+	// Hourly so each day's count reflects the collection late in that day
+	scheduler.AddTask(services.ScheduledTask{
+		Name:     "inventory_count_snapshot",
+		Label:    "Inventory Count Snapshot",
+		Interval: time.Hour,
+		Run:      inventoryHistoryService.RunDailyCount,
+	})
+	// Records the day's owned prices before checking, so it also builds price history
+	scheduler.AddTask(services.ScheduledTask{
+		Name:     "value_alert_check",
+		Label:    "Value Alert Check",
+		Interval: 6 * time.Hour,
+		Run:      valueAlertService.RunCheck,
+	})
+	scheduler.AddTask(services.ScheduledTask{
+		Name:     "inventory_trash_purge",
+		Label:    "Inventory Trash Purge",
+		Interval: 24 * time.Hour,
+		Run:      inventoryTrashService.RunPurge,
+	})
+	scheduler.AddTask(services.ScheduledTask{
+		Name:              "database_backup",
+		Label:             "Database Backup",
+		Interval:          24 * time.Hour,
+		TimeOfDay:         "backup_time",
+		EnabledSettingKey: "backup_auto_enabled",
+		LastRunSettingKey: "backup_last_run",
+		CronSettingKey:    "backup_cron",
+		Run:               backupService.RunScheduledBackup,
+	})
+	scheduler.AddTask(services.ScheduledTask{
+		Name:              "card_image_prefetch",
+		Label:             "Card Image Prefetch",
+		Interval:          24 * time.Hour,
+		EnabledSettingKey: "card_image_prefetch_enabled",
+		LastRunSettingKey: "card_image_prefetch_last_run",
+		JobType:           models.JobTypeImagePrefetch,
+		Run:               cardImageService.RunScheduledPrefetch,
+	})
+	// Reclaims the space repeated bulk imports leave behind in the database file
+	scheduler.AddTask(services.ScheduledTask{
+		Name:              "database_vacuum",
+		Label:             "Database Vacuum",
+		Interval:          30 * 24 * time.Hour,
+		TimeOfDay:         "03:00",
+		EnabledSettingKey: "db_vacuum_auto_enabled",
+		LastRunSettingKey: "db_vacuum_last_run",
+		CronSettingKey:    "db_vacuum_cron",
+		Run:               services.NewMaintenanceService(dbClient.DB, jobService).RunScheduledVacuum,
+	})
+	srv.SetScheduler(scheduler)
 	scheduler.Start(ctx)
 	defer scheduler.Stop()
 
@@ -125,4 +232,13 @@ func main() {
 		slog.Error("server failed to start", "error", err)
 		os.Exit(1)
 	}
+
+	// Let running jobs see the cancellation and record their outcome before the
+	// database closes
+	cancel()
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer drainCancel()
+	if err := workers.Shutdown(drainCtx); err != nil {
+		slog.Warn("background jobs did not finish before shutdown", "error", err)
+	}
 }
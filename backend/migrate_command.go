@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"backend/database"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "usage: showmycards migrate [status | up | down [steps]]"
+
+// runMigrateCommand runs `showmycards migrate` against db and returns the exit code.
+// status lists each migration, up applies pending ones, and down reverts the latest
+// steps (default 1).
+func runMigrateCommand(db *gorm.DB, args []string, out io.Writer) int {
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch {
+	case command == "status" && len(args) <= 1:
+		states, err := database.MigrationStatus(db)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		for _, state := range states {
+			status := "pending"
+			if state.AppliedAt != nil {
+				status = "applied " + state.AppliedAt.Format(time.RFC3339)
+			}
+			if !state.Known {
+				status += " (unknown to this version)"
+			}
+			fmt.Fprintf(out, "%-40s %s\n", state.ID, status)
+		}
+		return 0
+
+	case command == "up" && len(args) == 1:
+		if err := database.Migrate(db); err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		fmt.Fprintln(out, "database is up to date")
+		return 0
+
+	case command == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				fmt.Fprintln(out, migrateUsage)
+				return 2
+			}
+			steps = n
+		}
+		reverted, err := database.Rollback(db, steps)
+		for _, id := range reverted {
+			fmt.Fprintf(out, "rolled back %s\n", id)
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return 1
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(out, "no migrations to roll back")
+		}
+		return 0
+	}
+
+	fmt.Fprintln(out, migrateUsage)
+	return 2
+}
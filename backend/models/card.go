@@ -1,10 +1,14 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
 
 	scryfall "github.com/BlueMonday/go-scryfall"
@@ -57,6 +61,134 @@ type Card struct {
 	// Use "-" tag to exclude from AutoMigrate entirely
 	Name    string `gorm:"-" json:"name"`
 	SetCode string `gorm:"-" json:"set_code"`
+
+	// Extracted columns, populated from the card data on import so hot paths
+	// (value calculations, filters) don't need to unmarshal RawJSON
+	CollectorNumber string   `gorm:"type:varchar(20);index" json:"collector_number"`
+	Rarity          string   `gorm:"type:varchar(20);index" json:"rarity"`
+	CMC             float64  `gorm:"index" json:"cmc"`
+	Colors          string   `gorm:"type:varchar(5)" json:"colors"`               // Color letters in WUBRG order, e.g. "WR"; empty for colorless
+	ColorIdentity   string   `gorm:"type:varchar(5);index" json:"color_identity"` // Color identity letters in WUBRG order; empty for colorless
+	CardType        string   `gorm:"type:varchar(20);index" json:"card_type"`     // Primary type of the front face, see PrimaryCardType
+	PriceUSD        *float64 `gorm:"index" json:"price_usd,omitempty"`
+	PriceUSDFoil    *float64 `json:"price_usd_foil,omitempty"`
+	PriceUSDEtched  *float64 `json:"price_usd_etched,omitempty"`
+	PriceEUR        *float64 `json:"price_eur,omitempty"`
+	PriceEURFoil    *float64 `json:"price_eur_foil,omitempty"`
+	PriceTix        *float64 `json:"price_tix,omitempty"`
+
+	// ContentHash fingerprints RawJSON so incremental bulk updates can skip unchanged
+	// cards; Scryfall card objects carry no per-card modification time
+	ContentHash string `gorm:"type:varchar(64)" json:"-"`
+}
+
+// CardContentHash returns the fingerprint stored in Card.ContentHash for a card's raw JSON
+func CardContentHash(rawJSON string) string {
+	sum := sha256.Sum256([]byte(rawJSON))
+	return hex.EncodeToString(sum[:])
+}
+
+// colorOrder is the canonical WUBRG order used for Card.Colors
+const colorOrder = "WUBRG"
+
+// normalizeColors joins color letters in WUBRG order, ignoring anything else
+func normalizeColors(colors []string) string {
+	var b strings.Builder
+	for _, color := range colorOrder {
+		if slices.Contains(colors, string(color)) {
+			b.WriteRune(color)
+		}
+	}
+	return b.String()
+}
+
+// CardTypeOther is the primary card type of cards with none of CardTypes
+const CardTypeOther = "other"
+
+// CardTypes are the types PrimaryCardType looks for, in precedence order: an
+// "Artifact Creature" is a creature and an "Artifact Land" a land
+var CardTypes = []string{"creature", "planeswalker", "battle", "land", "instant", "sorcery", "artifact", "enchantment"}
+
+// PrimaryCardType classifies a type line by the front face's most significant type,
+// e.g. "Legendary Creature — Elf // Forest" is a creature
+func PrimaryCardType(typeLine string) string {
+	front, _, _ := strings.Cut(strings.ToLower(typeLine), " // ")
+	for _, cardType := range CardTypes {
+		if strings.Contains(front, cardType) {
+			return cardType
+		}
+	}
+	return CardTypeOther
+}
+
+// parsePrice converts a Scryfall price string to a number; empty or invalid prices are nil
+func parsePrice(price string) *float64 {
+	if price == "" {
+		return nil
+	}
+	value, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// cardColumnData is the subset of Scryfall card JSON copied into extracted columns
+type cardColumnData struct {
+	CollectorNumber string   `json:"collector_number"`
+	Rarity          string   `json:"rarity"`
+	CMC             float64  `json:"cmc"`
+	Colors          []string `json:"colors"`
+	ColorIdentity   []string `json:"color_identity"`
+	TypeLine        string   `json:"type_line"`
+	CardFaces       []struct {
+		Colors   []string `json:"colors"`
+		TypeLine string   `json:"type_line"`
+	} `json:"card_faces"`
+	Prices struct {
+		USD       string `json:"usd"`
+		USDFoil   string `json:"usd_foil"`
+		USDEtched string `json:"usd_etched"`
+		EUR       string `json:"eur"`
+		EURFoil   string `json:"eur_foil"`
+		Tix       string `json:"tix"`
+	} `json:"prices"`
+}
+
+// apply copies the extracted fields onto a card
+func (d cardColumnData) apply(c *Card) {
+	colors := d.Colors
+	// Multi-faced cards carry colors on their faces
+	if colors == nil && len(d.CardFaces) > 0 {
+		colors = d.CardFaces[0].Colors
+	}
+	typeLine := d.TypeLine
+	if typeLine == "" && len(d.CardFaces) > 0 {
+		typeLine = d.CardFaces[0].TypeLine
+	}
+
+	c.CollectorNumber = d.CollectorNumber
+	c.Rarity = d.Rarity
+	c.CMC = d.CMC
+	c.Colors = normalizeColors(colors)
+	c.ColorIdentity = normalizeColors(d.ColorIdentity)
+	c.CardType = PrimaryCardType(typeLine)
+	c.PriceUSD = parsePrice(d.Prices.USD)
+	c.PriceUSDFoil = parsePrice(d.Prices.USDFoil)
+	c.PriceUSDEtched = parsePrice(d.Prices.USDEtched)
+	c.PriceEUR = parsePrice(d.Prices.EUR)
+	c.PriceEURFoil = parsePrice(d.Prices.EURFoil)
+	c.PriceTix = parsePrice(d.Prices.Tix)
+}
+
+// PopulateColumns fills the extracted columns from RawJSON
+func (c *Card) PopulateColumns() error {
+	var data cardColumnData
+	if err := json.Unmarshal([]byte(c.RawJSON), &data); err != nil {
+		return fmt.Errorf("parsing card %s: %w", c.ScryfallID, err)
+	}
+	data.apply(c)
+	return nil
 }
 
 // TableName specifies the table name for the Card model
@@ -76,9 +208,21 @@ func (c *Card) ValidateCard(tx *gorm.DB) error {
 	return nil
 }
 
-// BeforeCreate validates the card before creation
+// BeforeCreate validates the card before creation and fills the extracted
+// columns when the caller only provided RawJSON
 func (c *Card) BeforeCreate(tx *gorm.DB) error {
-	return c.ValidateCard(tx)
+	if err := c.ValidateCard(tx); err != nil {
+		return err
+	}
+	if c.Rarity == "" && c.CollectorNumber == "" {
+		if err := c.PopulateColumns(); err != nil {
+			slog.Warn("failed to extract card columns", "scryfall_id", c.ScryfallID, "error", err)
+		}
+	}
+	if c.ContentHash == "" {
+		c.ContentHash = CardContentHash(c.RawJSON)
+	}
+	return nil
 }
 
 // BeforeUpdate validates the card before update
@@ -104,11 +248,42 @@ func FromScryfallCard(scryfallCard scryfall.Card) (*Card, error) {
 		return nil, err
 	}
 
-	return &Card{
+	card := &Card{
 		ScryfallID: scryfallCard.ID,
 		OracleID:   scryfallCard.OracleID,
 		RawJSON:    cleanRawJSON(string(rawJSON)),
-	}, nil
+	}
+	card.ContentHash = CardContentHash(card.RawJSON)
+
+	data := cardColumnData{
+		CollectorNumber: scryfallCard.CollectorNumber,
+		Rarity:          string(scryfallCard.Rarity),
+		CMC:             scryfallCard.CMC,
+		TypeLine:        scryfallCard.TypeLine,
+	}
+	for _, color := range scryfallCard.Colors {
+		data.Colors = append(data.Colors, string(color))
+	}
+	if len(scryfallCard.Colors) == 0 && len(scryfallCard.CardFaces) > 0 {
+		for _, color := range scryfallCard.CardFaces[0].Colors {
+			data.Colors = append(data.Colors, string(color))
+		}
+	}
+	for _, color := range scryfallCard.ColorIdentity {
+		data.ColorIdentity = append(data.ColorIdentity, string(color))
+	}
+	if data.TypeLine == "" && len(scryfallCard.CardFaces) > 0 {
+		data.TypeLine = scryfallCard.CardFaces[0].TypeLine
+	}
+	data.Prices.USD = scryfallCard.Prices.USD
+	data.Prices.USDFoil = scryfallCard.Prices.USDFoil
+	data.Prices.USDEtched = scryfallCard.Prices.USDEtched
+	data.Prices.EUR = scryfallCard.Prices.EUR
+	data.Prices.EURFoil = scryfallCard.Prices.EURFoil
+	data.Prices.Tix = scryfallCard.Prices.Tix
+	data.apply(card)
+
+	return card, nil
 }
 
 // GetCardsByIDs fetches multiple cards by their Scryfall IDs and returns them as a map
@@ -149,3 +324,115 @@ func GetScryfallCardsByIDs(db *gorm.DB, scryfallIDs []string) (map[string]scryfa
 	}
 	return result, nil
 }
+
+// IsBasicLand reports whether a type line is a basic land's, e.g. "Basic Land — Forest",
+// "Basic Snow Land — Island" or Wastes' "Basic Land". Only the front face of a
+// multi-faced card counts.
+func IsBasicLand(typeLine string) bool {
+	front, _, _ := strings.Cut(typeLine, "//")
+	supertypes, _, _ := strings.Cut(front, "—")
+	fields := strings.Fields(supertypes)
+	return slices.Contains(fields, "Basic") && slices.Contains(fields, "Land")
+}
+
+// basicLandIDsQuery selects the printings of basic lands, matching IsBasicLand
+const basicLandIDsQuery = `SELECT scryfall_id FROM cards WHERE json_extract(raw_json, '$.type_line') LIKE 'Basic %Land%'`
+
+// ExcludeBasicLands is a scope leaving out rows whose scryfall_id is a basic land
+// printing, for tables such as inventories and list_items
+func ExcludeBasicLands(db *gorm.DB) *gorm.DB {
+	return db.Where("scryfall_id NOT IN (" + basicLandIDsQuery + ")")
+}
+
+// Currency is a currency Scryfall reports card prices in
+// tygo:export
+type Currency string
+
+const (
+	CurrencyUSD Currency = "usd"
+	CurrencyEUR Currency = "eur"
+	CurrencyTIX Currency = "tix" // MTGO event tickets
+)
+
+// Valid checks if the currency is one Scryfall prices cards in
+func (c Currency) Valid() bool {
+	switch c {
+	case CurrencyUSD, CurrencyEUR, CurrencyTIX:
+		return true
+	default:
+		return false
+	}
+}
+
+// CardPrices holds a card's extracted prices
+type CardPrices struct {
+	ScryfallID     string
+	PriceUSD       *float64
+	PriceUSDFoil   *float64
+	PriceUSDEtched *float64
+	PriceEUR       *float64
+	PriceEURFoil   *float64
+	PriceTix       *float64
+}
+
+// ForTreatment returns the USD price for a treatment, falling back to the nonfoil
+// price when the treatment has none. Mirrors utils.ParsePriceFromScryfall.
+func (p CardPrices) ForTreatment(treatment string) float64 {
+	return p.InCurrency(treatment, CurrencyUSD)
+}
+
+// InCurrency returns the price for a treatment in the given currency, falling back to
+// the nonfoil price when the treatment has none. Scryfall has no etched EUR price and
+// a single tix price per card. Mirrors utils.ParsePriceInCurrency.
+func (p CardPrices) InCurrency(treatment string, currency Currency) float64 {
+	var nonfoil, foil, etched *float64
+	switch currency {
+	case CurrencyEUR:
+		nonfoil, foil = p.PriceEUR, p.PriceEURFoil
+	case CurrencyTIX:
+		nonfoil = p.PriceTix
+	default:
+		nonfoil, foil, etched = p.PriceUSD, p.PriceUSDFoil, p.PriceUSDEtched
+	}
+
+	var price *float64
+	switch treatment {
+	case "foil":
+		price = foil
+	case "etched":
+		price = etched
+	case "nonfoil":
+		price = nonfoil
+	default:
+		// For other treatments (glossy, etc.), try foil first
+		price = foil
+	}
+	if price != nil {
+		return *price
+	}
+	if treatment != "nonfoil" && nonfoil != nil {
+		return *nonfoil
+	}
+	return 0.0
+}
+
+// cardPriceBatchSize keeps IN clauses well under SQLite's bound parameter limit
+const cardPriceBatchSize = 5000
+
+// GetCardPricesByIDs fetches only the price columns for the given cards, keyed by Scryfall ID
+func GetCardPricesByIDs(db *gorm.DB, scryfallIDs []string) (map[string]CardPrices, error) {
+	result := make(map[string]CardPrices, len(scryfallIDs))
+	for batch := range slices.Chunk(scryfallIDs, cardPriceBatchSize) {
+		var prices []CardPrices
+		if err := db.Model(&Card{}).
+			Select("scryfall_id", "price_usd", "price_usd_foil", "price_usd_etched", "price_eur", "price_eur_foil", "price_tix").
+			Where("scryfall_id IN ?", batch).
+			Scan(&prices).Error; err != nil {
+			return nil, fmt.Errorf("fetching card prices: %w", err)
+		}
+		for _, p := range prices {
+			result[p.ScryfallID] = p
+		}
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -473,6 +474,44 @@ func TestCard_GetCardsByIDs(t *testing.T) {
 }
 
 // LOW-VALUE: Tests a one-liner that returns a constant string. Verifies Go works, not business logic.
+func TestCard_ExcludeBasicLands(t *testing.T) {
+	db := setupCardTestDB(t)
+	if err := db.AutoMigrate(&Inventory{}); err != nil {
+		t.Fatalf("failed to migrate inventory: %v", err)
+	}
+
+	cards := []struct {
+		id       string
+		typeLine string
+		basic    bool
+	}{
+		{"forest", "Basic Land — Forest", true},
+		{"snow-island", "Basic Snow Land — Island", true},
+		{"dryad-arbor", "Land Creature — Forest Dryad", false},
+		{"bolt", "Instant", false},
+	}
+	for _, card := range cards {
+		if IsBasicLand(card.typeLine) != card.basic {
+			t.Errorf("%s: expected IsBasicLand %v", card.typeLine, card.basic)
+		}
+		rawJSON := fmt.Sprintf(`{"type_line": %q}`, card.typeLine)
+		if err := db.Create(&Card{ScryfallID: card.id, OracleID: "oracle-" + card.id, RawJSON: rawJSON}).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+		if err := db.Create(&Inventory{ScryfallID: card.id, OracleID: "oracle-" + card.id, Quantity: 1}).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	var ids []string
+	if err := db.Model(&Inventory{}).Scopes(ExcludeBasicLands).Order("scryfall_id").Pluck("scryfall_id", &ids).Error; err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if strings.Join(ids, ",") != "bolt,dryad-arbor" {
+		t.Errorf("expected only the non-basic cards, got %v", ids)
+	}
+}
+
 func TestCard_TableName(t *testing.T) {
 	card := Card{}
 	tableName := card.TableName()
@@ -573,3 +612,239 @@ func TestCard_MalformedPreviewedAtHandling(t *testing.T) {
 		t.Errorf("expected name 'Test Card', got '%s'", scryfallCard.Name)
 	}
 }
+
+func TestCard_FromScryfallCard_ExtractedColumns(t *testing.T) {
+	scryfallCard := scryfall.Card{
+		ID:              "test-id",
+		OracleID:        "oracle-id",
+		Name:            "Boros Charm",
+		CollectorNumber: "211",
+		Rarity:          "uncommon",
+		CMC:             2,
+		Colors:          []scryfall.Color{scryfall.ColorRed, scryfall.ColorWhite},
+		ColorIdentity:   []scryfall.Color{scryfall.ColorRed, scryfall.ColorWhite},
+		TypeLine:        "Instant",
+		Prices:          scryfall.Prices{USD: "0.45", USDFoil: "1.20"},
+	}
+
+	card, err := FromScryfallCard(scryfallCard)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if card.CollectorNumber != "211" {
+		t.Errorf("expected collector number '211', got '%s'", card.CollectorNumber)
+	}
+	if card.Rarity != "uncommon" {
+		t.Errorf("expected rarity 'uncommon', got '%s'", card.Rarity)
+	}
+	if card.CMC != 2 {
+		t.Errorf("expected cmc 2, got %v", card.CMC)
+	}
+	if card.Colors != "WR" {
+		t.Errorf("expected colors in WUBRG order 'WR', got '%s'", card.Colors)
+	}
+	if card.ColorIdentity != "WR" {
+		t.Errorf("expected color identity in WUBRG order 'WR', got '%s'", card.ColorIdentity)
+	}
+	if card.CardType != "instant" {
+		t.Errorf("expected card type 'instant', got '%s'", card.CardType)
+	}
+	if card.PriceUSD == nil || *card.PriceUSD != 0.45 {
+		t.Errorf("expected price_usd 0.45, got %v", card.PriceUSD)
+	}
+	if card.PriceUSDFoil == nil || *card.PriceUSDFoil != 1.20 {
+		t.Errorf("expected price_usd_foil 1.20, got %v", card.PriceUSDFoil)
+	}
+	if card.PriceUSDEtched != nil {
+		t.Errorf("expected no etched price, got %v", *card.PriceUSDEtched)
+	}
+	if card.ContentHash != CardContentHash(card.RawJSON) {
+		t.Errorf("expected content hash of raw JSON, got %q", card.ContentHash)
+	}
+}
+
+func TestCardContentHash(t *testing.T) {
+	first := CardContentHash(`{"id": "a", "prices": {"usd": "1.00"}}`)
+	if len(first) != 64 {
+		t.Errorf("expected 64 hex characters, got %d", len(first))
+	}
+	if first != CardContentHash(`{"id": "a", "prices": {"usd": "1.00"}}`) {
+		t.Error("expected identical JSON to hash the same")
+	}
+	if first == CardContentHash(`{"id": "a", "prices": {"usd": "1.25"}}`) {
+		t.Error("expected a price change to change the hash")
+	}
+}
+
+func TestCard_BeforeCreate_PopulatesColumnsFromRawJSON(t *testing.T) {
+	db := setupCardTestDB(t)
+
+	card := &Card{
+		ScryfallID: "dfc-id",
+		OracleID:   "dfc-oracle",
+		RawJSON: `{"name": "Delver of Secrets // Insectile Aberration", "collector_number": "51",
+			"rarity": "common", "cmc": 1, "color_identity": ["U"],
+			"card_faces": [{"colors": ["U"], "type_line": "Creature — Human Wizard"}, {"colors": ["U"], "type_line": "Creature — Human Insect"}],
+			"prices": {"usd": "0.10", "usd_etched": "2.50"}}`,
+	}
+	if err := db.Create(card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	var stored Card
+	if err := db.First(&stored, "scryfall_id = ?", "dfc-id").Error; err != nil {
+		t.Fatalf("failed to load card: %v", err)
+	}
+	if stored.Rarity != "common" || stored.CollectorNumber != "51" || stored.CMC != 1 {
+		t.Errorf("unexpected extracted columns: rarity=%q collector_number=%q cmc=%v", stored.Rarity, stored.CollectorNumber, stored.CMC)
+	}
+	if stored.Colors != "U" {
+		t.Errorf("expected face colors 'U', got '%s'", stored.Colors)
+	}
+	if stored.ColorIdentity != "U" || stored.CardType != "creature" {
+		t.Errorf("expected identity 'U' and front face type 'creature', got %q and %q", stored.ColorIdentity, stored.CardType)
+	}
+	if stored.PriceUSDEtched == nil || *stored.PriceUSDEtched != 2.50 {
+		t.Errorf("expected etched price 2.50, got %v", stored.PriceUSDEtched)
+	}
+	if stored.PriceUSDFoil != nil {
+		t.Errorf("expected no foil price, got %v", *stored.PriceUSDFoil)
+	}
+}
+
+func TestCardPrices_ForTreatment(t *testing.T) {
+	usd, foil, etched := 1.0, 5.0, 3.0
+
+	tests := []struct {
+		name      string
+		prices    CardPrices
+		treatment string
+		expected  float64
+	}{
+		{"nonfoil", CardPrices{PriceUSD: &usd, PriceUSDFoil: &foil}, "nonfoil", 1.0},
+		{"foil", CardPrices{PriceUSD: &usd, PriceUSDFoil: &foil}, "foil", 5.0},
+		{"etched", CardPrices{PriceUSD: &usd, PriceUSDEtched: &etched}, "etched", 3.0},
+		{"other treatment uses foil", CardPrices{PriceUSD: &usd, PriceUSDFoil: &foil}, "glossy", 5.0},
+		{"foil falls back to nonfoil", CardPrices{PriceUSD: &usd}, "foil", 1.0},
+		{"nonfoil does not fall back", CardPrices{PriceUSDFoil: &foil}, "nonfoil", 0.0},
+		{"no prices", CardPrices{}, "foil", 0.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prices.ForTreatment(tt.treatment); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCardPrices_InCurrency(t *testing.T) {
+	usd, eur, eurFoil, tix := 2.0, 1.8, 7.5, 0.05
+	prices := CardPrices{PriceUSD: &usd, PriceEUR: &eur, PriceEURFoil: &eurFoil, PriceTix: &tix}
+
+	tests := []struct {
+		name      string
+		treatment string
+		currency  Currency
+		expected  float64
+	}{
+		{"usd", "nonfoil", CurrencyUSD, 2.0},
+		{"eur nonfoil", "nonfoil", CurrencyEUR, 1.8},
+		{"eur foil", "foil", CurrencyEUR, 7.5},
+		{"eur etched falls back to nonfoil", "etched", CurrencyEUR, 1.8},
+		{"tix ignores treatment", "foil", CurrencyTIX, 0.05},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prices.InCurrency(tt.treatment, tt.currency); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCurrency_Valid(t *testing.T) {
+	for _, currency := range []Currency{CurrencyUSD, CurrencyEUR, CurrencyTIX} {
+		if !currency.Valid() {
+			t.Errorf("expected %q to be valid", currency)
+		}
+	}
+	for _, currency := range []Currency{"", "gbp", "USD"} {
+		if currency.Valid() {
+			t.Errorf("expected %q to be invalid", currency)
+		}
+	}
+}
+
+func TestCard_GetCardPricesByIDs(t *testing.T) {
+	db := setupCardTestDB(t)
+
+	cards := []*Card{
+		{ScryfallID: "id-1", OracleID: "oracle-1", RawJSON: `{"name": "Card 1", "rarity": "rare", "prices": {"usd": "2.00", "usd_foil": "4.00", "eur": "1.75", "tix": "0.10"}}`},
+		{ScryfallID: "id-2", OracleID: "oracle-2", RawJSON: `{"name": "Card 2", "rarity": "common", "prices": {"usd": null}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	prices, err := GetCardPricesByIDs(db, []string{"id-1", "id-2", "missing"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prices) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(prices))
+	}
+	if got := prices["id-1"].ForTreatment("foil"); got != 4.0 {
+		t.Errorf("expected id-1 foil price 4.00, got %v", got)
+	}
+	if got := prices["id-1"].InCurrency("nonfoil", CurrencyEUR); got != 1.75 {
+		t.Errorf("expected id-1 EUR price 1.75, got %v", got)
+	}
+	if got := prices["id-1"].InCurrency("nonfoil", CurrencyTIX); got != 0.10 {
+		t.Errorf("expected id-1 tix price 0.10, got %v", got)
+	}
+	if prices["id-2"].PriceUSD != nil {
+		t.Errorf("expected no price for id-2, got %v", *prices["id-2"].PriceUSD)
+	}
+
+	empty, err := GetCardPricesByIDs(db, nil)
+	if err != nil {
+		t.Fatalf("expected no error for empty IDs, got %v", err)
+	}
+	if len(empty) != 0 {
+		t.Errorf("expected empty map, got %d entries", len(empty))
+	}
+}
+
+func TestPrimaryCardType(t *testing.T) {
+	tests := []struct {
+		typeLine string
+		expected string
+	}{
+		{"Legendary Creature — Elf Druid", "creature"},
+		{"Artifact Creature — Golem", "creature"},
+		{"Artifact Land", "land"},
+		{"Basic Land — Forest", "land"},
+		{"Legendary Planeswalker — Jace", "planeswalker"},
+		{"Kindred Instant — Faerie", "instant"},
+		{"Enchantment — Aura", "enchantment"},
+		{"Sorcery // Sorcery", "sorcery"},
+		{"Enchantment — Saga // Enchantment Creature — Human", "enchantment"},
+		{"Token Creature — Goblin", "creature"},
+		{"Conspiracy", "other"},
+		{"", "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typeLine, func(t *testing.T) {
+			if got := PrimaryCardType(tt.typeLine); got != tt.expected {
+				t.Errorf("PrimaryCardType(%q) = %q, expected %q", tt.typeLine, got, tt.expected)
+			}
+		})
+	}
+}
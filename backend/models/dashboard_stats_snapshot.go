@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// DashboardStatsSnapshot holds JSON-encoded dashboard stats computed for one variant
+// of the dashboard settings, so the dashboard doesn't recompute them on every load.
+// A snapshot is only served while its Generation matches the cache's current one.
+// tygo:export
+type DashboardStatsSnapshot struct {
+	BaseModel
+	Key        string `gorm:"type:varchar(50);not null;uniqueIndex" json:"key"` // Currency and basic land exclusion, e.g. "usd:all"
+	Generation uint64 `gorm:"not null" json:"generation"`                       // Cache generation the stats were computed in
+	Stats      string `gorm:"type:text;not null" json:"-"`
+}
+
+func (s *DashboardStatsSnapshot) ValidateDashboardStatsSnapshot(tx *gorm.DB) error {
+	if s.Key == "" {
+		return errors.New("key cannot be empty")
+	}
+	if s.Stats == "" {
+		return errors.New("stats cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the snapshot before creating a record
+func (s *DashboardStatsSnapshot) BeforeCreate(tx *gorm.DB) error {
+	return s.ValidateDashboardStatsSnapshot(tx)
+}
+
+// BeforeUpdate validates the snapshot before updating a record
+func (s *DashboardStatsSnapshot) BeforeUpdate(tx *gorm.DB) error {
+	return s.ValidateDashboardStatsSnapshot(tx)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// DashboardWidgetType identifies a dashboard widget and the stats it needs
+// tygo:export
+type DashboardWidgetType string
+
+const (
+	DashboardWidgetStorageLocations DashboardWidgetType = "storage_locations"
+	DashboardWidgetLists            DashboardWidgetType = "lists"
+	DashboardWidgetInventoryCards   DashboardWidgetType = "inventory_cards"
+	DashboardWidgetWishlistCards    DashboardWidgetType = "wishlist_cards"
+	DashboardWidgetUnassignedCards  DashboardWidgetType = "unassigned_cards"
+	DashboardWidgetCollectionValue  DashboardWidgetType = "collection_value"
+	DashboardWidgetListValues       DashboardWidgetType = "list_values"      // Collected and remaining list values
+	DashboardWidgetAcquisitionGain  DashboardWidgetType = "acquisition_gain" // Acquisition cost, current value, and gain/loss
+	DashboardWidgetGoal             DashboardWidgetType = "goal"             // Progress of Metric towards Target
+)
+
+// Valid reports whether t is a known widget type
+func (t DashboardWidgetType) Valid() bool {
+	switch t {
+	case DashboardWidgetStorageLocations, DashboardWidgetLists, DashboardWidgetInventoryCards,
+		DashboardWidgetWishlistCards, DashboardWidgetUnassignedCards, DashboardWidgetCollectionValue,
+		DashboardWidgetListValues, DashboardWidgetAcquisitionGain, DashboardWidgetGoal:
+		return true
+	}
+	return false
+}
+
+// GoalMetric reports whether t is a single number a goal widget can track
+func (t DashboardWidgetType) GoalMetric() bool {
+	switch t {
+	case DashboardWidgetStorageLocations, DashboardWidgetLists, DashboardWidgetInventoryCards,
+		DashboardWidgetWishlistCards, DashboardWidgetCollectionValue:
+		return true
+	}
+	return false
+}
+
+// DashboardWidget is one entry in the configured dashboard, shown in Position order.
+// Metric and Target are only used by goal widgets.
+// tygo:export
+type DashboardWidget struct {
+	BaseModel
+	Position int                 `gorm:"not null;index" json:"position"`
+	Type     DashboardWidgetType `gorm:"type:varchar(30);not null" json:"type"`
+	Label    string              `gorm:"type:varchar(255)" json:"label,omitempty"`
+	Metric   DashboardWidgetType `gorm:"type:varchar(30)" json:"metric,omitempty"`
+	Target   float64             `json:"target,omitempty"`
+}
+
+func (w *DashboardWidget) ValidateDashboardWidget(tx *gorm.DB) error {
+	if !w.Type.Valid() {
+		return errors.New("widget type is not valid")
+	}
+	if w.Position < 0 {
+		return errors.New("position cannot be negative")
+	}
+	if w.Type == DashboardWidgetGoal {
+		if !w.Metric.GoalMetric() {
+			return errors.New("goal metric is not valid")
+		}
+		if w.Target <= 0 {
+			return errors.New("goal target must be greater than 0")
+		}
+	} else if w.Metric != "" || w.Target != 0 {
+		return errors.New("metric and target are only allowed on goal widgets")
+	}
+	return nil
+}
+
+// BeforeCreate validates the widget before creating a record
+func (w *DashboardWidget) BeforeCreate(tx *gorm.DB) error {
+	return w.ValidateDashboardWidget(tx)
+}
+
+// BeforeUpdate validates the widget before updating a record
+func (w *DashboardWidget) BeforeUpdate(tx *gorm.DB) error {
+	return w.ValidateDashboardWidget(tx)
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDashboardWidget_ValidateDashboardWidget(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&DashboardWidget{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		widget   *DashboardWidget
+		errorMsg string
+	}{
+		{"Valid Stat", &DashboardWidget{Type: DashboardWidgetInventoryCards}, ""},
+		{"Valid Goal", &DashboardWidget{Position: 1, Type: DashboardWidgetGoal, Metric: DashboardWidgetCollectionValue, Target: 500}, ""},
+		{"Invalid - Unknown Type", &DashboardWidget{Type: "weather"}, "widget type is not valid"},
+		{"Invalid - Negative Position", &DashboardWidget{Position: -1, Type: DashboardWidgetLists}, "position cannot be negative"},
+		{"Invalid - Goal Metric", &DashboardWidget{Type: DashboardWidgetGoal, Metric: DashboardWidgetListValues, Target: 10}, "goal metric is not valid"},
+		{"Invalid - Goal Target", &DashboardWidget{Type: DashboardWidgetGoal, Metric: DashboardWidgetInventoryCards}, "goal target must be greater than 0"},
+		{"Invalid - Target On Stat", &DashboardWidget{Type: DashboardWidgetLists, Target: 10}, "metric and target are only allowed on goal widgets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.widget).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// DeckFormat is the format a deck is built for. Values match Scryfall legality keys.
+// tygo:export
+type DeckFormat string
+
+const (
+	DeckFormatStandard  DeckFormat = "standard"
+	DeckFormatPioneer   DeckFormat = "pioneer"
+	DeckFormatModern    DeckFormat = "modern"
+	DeckFormatLegacy    DeckFormat = "legacy"
+	DeckFormatVintage   DeckFormat = "vintage"
+	DeckFormatPauper    DeckFormat = "pauper"
+	DeckFormatCommander DeckFormat = "commander"
+)
+
+// Valid checks if the deck format is one of the defined values
+func (f DeckFormat) Valid() bool {
+	switch f {
+	case DeckFormatStandard, DeckFormatPioneer, DeckFormatModern, DeckFormatLegacy,
+		DeckFormatVintage, DeckFormatPauper, DeckFormatCommander:
+		return true
+	}
+	return false
+}
+
+// HasCommander reports whether decks in the format are built around a commander
+func (f DeckFormat) HasCommander() bool {
+	return f == DeckFormatCommander
+}
+
+// DeckZone is the part of a deck a card is played from
+// tygo:export
+type DeckZone string
+
+const (
+	DeckZoneMainboard DeckZone = "mainboard"
+	DeckZoneSideboard DeckZone = "sideboard"
+)
+
+// Valid checks if the deck zone is one of the defined values
+func (z DeckZone) Valid() bool {
+	return z == DeckZoneMainboard || z == DeckZoneSideboard
+}
+
+// Deck is a deck built for a specific format. Unlike a List it knows its format,
+// its commander and which cards are in the mainboard or sideboard, so it can be
+// checked for legality.
+// tygo:export
+type Deck struct {
+	BaseModel
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description,omitempty"`
+	Format      DeckFormat `gorm:"type:varchar(20);not null" json:"format"`
+	// CommanderOracleID is the deck's commander; only set for commander formats.
+	// The commander is not listed among the cards.
+	CommanderOracleID string `gorm:"type:varchar(255)" json:"commander_oracle_id,omitempty"`
+
+	// Relationship - cards in this deck
+	Cards []DeckCard `gorm:"foreignKey:DeckID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"cards,omitempty"`
+}
+
+func (d *Deck) ValidateDeck(tx *gorm.DB) error {
+	if d.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if !d.Format.Valid() {
+		return errors.New("format must be one of: standard, pioneer, modern, legacy, vintage, pauper, commander")
+	}
+	if d.CommanderOracleID != "" && !d.Format.HasCommander() {
+		return errors.New("only commander decks can have a commander")
+	}
+	return nil
+}
+
+// BeforeCreate validates the deck before creating a record
+func (d *Deck) BeforeCreate(tx *gorm.DB) error {
+	return d.ValidateDeck(tx)
+}
+
+// BeforeUpdate validates the deck before updating a record
+func (d *Deck) BeforeUpdate(tx *gorm.DB) error {
+	return d.ValidateDeck(tx)
+}
+
+// DeckCard is a card in a deck. Cards are tracked by oracle ID since any printing
+// can be played.
+// tygo:export
+type DeckCard struct {
+	BaseModel
+	DeckID   uint     `gorm:"not null;index;uniqueIndex:idx_deck_card_zone" json:"deck_id"`
+	OracleID string   `gorm:"type:varchar(255);not null;index;uniqueIndex:idx_deck_card_zone" json:"oracle_id"`
+	Zone     DeckZone `gorm:"type:varchar(20);not null;uniqueIndex:idx_deck_card_zone" json:"zone"`
+	Quantity int      `gorm:"not null;default:1" json:"quantity"`
+
+	// Name is the card's name (computed, not stored)
+	Name string `gorm:"-" json:"name,omitempty"`
+
+	// Relationship
+	Deck *Deck `gorm:"foreignKey:DeckID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (dc *DeckCard) ValidateDeckCard(tx *gorm.DB) error {
+	if dc.DeckID == 0 {
+		return errors.New("deck_id cannot be empty")
+	}
+	if dc.OracleID == "" {
+		return errors.New("oracle_id cannot be empty")
+	}
+	if !dc.Zone.Valid() {
+		return errors.New("zone must be one of: mainboard, sideboard")
+	}
+	if dc.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	return nil
+}
+
+// BeforeCreate validates the deck card before creating a record
+func (dc *DeckCard) BeforeCreate(tx *gorm.DB) error {
+	return dc.ValidateDeckCard(tx)
+}
+
+// BeforeUpdate validates the deck card before updating a record
+func (dc *DeckCard) BeforeUpdate(tx *gorm.DB) error {
+	return dc.ValidateDeckCard(tx)
+}
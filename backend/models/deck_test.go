@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDeckTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Deck{}, &DeckCard{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestDeck_ValidateDeck(t *testing.T) {
+	db := setupDeckTestDB(t)
+
+	tests := []struct {
+		name     string
+		deck     *Deck
+		errorMsg string
+	}{
+		{"Valid Constructed", &Deck{Name: "Burn", Format: DeckFormatModern}, ""},
+		{"Valid Commander", &Deck{Name: "Goblins", Format: DeckFormatCommander, CommanderOracleID: "krenko"}, ""},
+		{"Invalid - Empty Name", &Deck{Format: DeckFormatModern}, "name cannot be empty"},
+		{"Invalid - Unknown Format", &Deck{Name: "Burn", Format: "extended"}, "format must be one of: standard, pioneer, modern, legacy, vintage, pauper, commander"},
+		{"Invalid - Commander Outside Commander Format", &Deck{Name: "Burn", Format: DeckFormatModern, CommanderOracleID: "krenko"}, "only commander decks can have a commander"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.deck).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestDeckCard_ValidateDeckCard(t *testing.T) {
+	db := setupDeckTestDB(t)
+	deck := Deck{Name: "Burn", Format: DeckFormatModern}
+	if err := db.Create(&deck).Error; err != nil {
+		t.Fatalf("failed to create deck: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		card     *DeckCard
+		errorMsg string
+	}{
+		{"Valid", &DeckCard{DeckID: deck.ID, OracleID: "bolt", Zone: DeckZoneMainboard, Quantity: 4}, ""},
+		{"Invalid - No Deck", &DeckCard{OracleID: "bolt", Zone: DeckZoneMainboard, Quantity: 1}, "deck_id cannot be empty"},
+		{"Invalid - No Oracle ID", &DeckCard{DeckID: deck.ID, Zone: DeckZoneMainboard, Quantity: 1}, "oracle_id cannot be empty"},
+		{"Invalid - Unknown Zone", &DeckCard{DeckID: deck.ID, OracleID: "bolt", Zone: "maybeboard", Quantity: 1}, "zone must be one of: mainboard, sideboard"},
+		{"Invalid - Zero Quantity", &DeckCard{DeckID: deck.ID, OracleID: "bolt", Zone: DeckZoneSideboard}, "quantity must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.card).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
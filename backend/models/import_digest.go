@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ImportDigest summarises what a bulk data import changed for the owned collection.
+// The counts are kept as columns for listing; Content holds the JSON-encoded details.
+// tygo:export
+type ImportDigest struct {
+	BaseModel
+	JobID           uint   `gorm:"not null;uniqueIndex" json:"job_id"`
+	NewPrintings    int    `gorm:"not null;default:0" json:"new_printings"`
+	PriceMovers     int    `gorm:"not null;default:0" json:"price_movers"`
+	LegalityChanges int    `gorm:"not null;default:0" json:"legality_changes"`
+	Content         string `gorm:"type:text;not null" json:"-"`
+}
+
+func (d *ImportDigest) ValidateImportDigest(tx *gorm.DB) error {
+	if d.JobID == 0 {
+		return errors.New("job_id cannot be empty")
+	}
+	if d.Content == "" {
+		return errors.New("content cannot be empty")
+	}
+	if d.NewPrintings < 0 || d.PriceMovers < 0 || d.LegalityChanges < 0 {
+		return errors.New("counts cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the digest before creating a record
+func (d *ImportDigest) BeforeCreate(tx *gorm.DB) error {
+	return d.ValidateImportDigest(tx)
+}
+
+// BeforeUpdate validates the digest before updating a record
+func (d *ImportDigest) BeforeUpdate(tx *gorm.DB) error {
+	return d.ValidateImportDigest(tx)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestImportDigest_ValidateImportDigest(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&ImportDigest{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		digest   *ImportDigest
+		errorMsg string
+	}{
+		{"Valid Digest", &ImportDigest{JobID: 1, NewPrintings: 2, Content: "{}"}, ""},
+		{"Invalid - Missing JobID", &ImportDigest{Content: "{}"}, "job_id cannot be empty"},
+		{"Invalid - Missing Content", &ImportDigest{JobID: 2}, "content cannot be empty"},
+		{"Invalid - Negative Count", &ImportDigest{JobID: 3, PriceMovers: -1, Content: "{}"}, "counts cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.digest).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
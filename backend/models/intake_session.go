@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxIntakeSessionNameLength caps the name given to an intake session
+const MaxIntakeSessionNameLength = 255
+
+// IntakeSessionStatus is the lifecycle state of an intake session
+type IntakeSessionStatus string
+
+const (
+	IntakeSessionOpen      IntakeSessionStatus = "open"
+	IntakeSessionCommitted IntakeSessionStatus = "committed"
+	IntakeSessionAborted   IntakeSessionStatus = "aborted"
+)
+
+// Valid checks if the intake session status is valid
+func (s IntakeSessionStatus) Valid() bool {
+	switch s {
+	case IntakeSessionOpen, IntakeSessionCommitted, IntakeSessionAborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// IntakeSession stages cards being processed, e.g. a box of new purchases. Its items
+// are only written to inventory when the session is committed; aborting it discards them.
+// tygo:export
+type IntakeSession struct {
+	BaseModel
+	Name   string              `gorm:"type:varchar(255)" json:"name"`
+	Status IntakeSessionStatus `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	// StorageLocationID is where committed cards go; nil applies sorting rules to each
+	StorageLocationID *uint      `json:"storage_location_id,omitempty"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty"` // When the session was committed or aborted
+
+	// Relationship
+	Items []IntakeSessionItem `gorm:"foreignKey:IntakeSessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// IsOpen reports whether items can still be added to or removed from the session
+func (s *IntakeSession) IsOpen() bool {
+	return s.Status == IntakeSessionOpen
+}
+
+func (s *IntakeSession) ValidateIntakeSession(tx *gorm.DB) error {
+	if len(s.Name) > MaxIntakeSessionNameLength {
+		return fmt.Errorf("name cannot exceed %d characters", MaxIntakeSessionNameLength)
+	}
+	if s.Status != "" && !s.Status.Valid() {
+		return fmt.Errorf("invalid status %q", s.Status)
+	}
+	return nil
+}
+
+// BeforeCreate validates the intake session before creating a record
+func (s *IntakeSession) BeforeCreate(tx *gorm.DB) error {
+	return s.ValidateIntakeSession(tx)
+}
+
+// BeforeUpdate validates the intake session before updating a record
+func (s *IntakeSession) BeforeUpdate(tx *gorm.DB) error {
+	return s.ValidateIntakeSession(tx)
+}
+
+// IntakeSessionItem is a printing staged in an intake session. InventoryID and
+// StorageLocationID are filled in when the session is committed.
+// tygo:export
+type IntakeSessionItem struct {
+	BaseModel
+	IntakeSessionID   uint   `gorm:"not null;index" json:"intake_session_id"`
+	ScryfallID        string `gorm:"type:varchar(255);not null" json:"scryfall_id"`
+	OracleID          string `gorm:"type:varchar(255);not null" json:"oracle_id"`
+	Name              string `gorm:"type:varchar(255)" json:"name"`
+	SetCode           string `gorm:"type:varchar(20)" json:"set_code"`
+	Treatment         string `gorm:"type:varchar(100)" json:"treatment"`
+	Quantity          int    `gorm:"not null;default:1" json:"quantity"`
+	InventoryID       *uint  `json:"inventory_id,omitempty"`
+	StorageLocationID *uint  `json:"storage_location_id,omitempty"`
+}
+
+func (i *IntakeSessionItem) ValidateIntakeSessionItem(tx *gorm.DB) error {
+	if i.IntakeSessionID == 0 {
+		return errors.New("intake_session_id must be set")
+	}
+	if i.ScryfallID == "" {
+		return errors.New("scryfall_id cannot be empty")
+	}
+	if i.OracleID == "" {
+		return errors.New("oracle_id cannot be empty")
+	}
+	if i.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	return nil
+}
+
+// BeforeCreate validates the intake session item before creating a record
+func (i *IntakeSessionItem) BeforeCreate(tx *gorm.DB) error {
+	return i.ValidateIntakeSessionItem(tx)
+}
+
+// BeforeUpdate validates the intake session item before updating a record
+func (i *IntakeSessionItem) BeforeUpdate(tx *gorm.DB) error {
+	return i.ValidateIntakeSessionItem(tx)
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupIntakeSessionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&IntakeSession{}, &IntakeSessionItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestIntakeSessionStatus_Valid(t *testing.T) {
+	for _, status := range []IntakeSessionStatus{IntakeSessionOpen, IntakeSessionCommitted, IntakeSessionAborted} {
+		if !status.Valid() {
+			t.Errorf("expected %q to be valid", status)
+		}
+	}
+	if IntakeSessionStatus("pending").Valid() {
+		t.Error("expected pending to be invalid")
+	}
+}
+
+func TestIntakeSession_Validate(t *testing.T) {
+	db := setupIntakeSessionTestDB(t)
+
+	session := IntakeSession{Name: "Booster box"}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.Status != IntakeSessionOpen || !session.IsOpen() {
+		t.Errorf("expected a new session to be open, got %q", session.Status)
+	}
+
+	if err := db.Create(&IntakeSession{Name: strings.Repeat("a", MaxIntakeSessionNameLength+1)}).Error; err == nil {
+		t.Error("expected an error for an overlong name")
+	}
+	if err := db.Create(&IntakeSession{Status: "pending"}).Error; err == nil {
+		t.Error("expected an error for an invalid status")
+	}
+}
+
+func TestIntakeSessionItem_Validate(t *testing.T) {
+	db := setupIntakeSessionTestDB(t)
+
+	tests := []struct {
+		name        string
+		item        IntakeSessionItem
+		expectError bool
+	}{
+		{"Valid", IntakeSessionItem{IntakeSessionID: 1, ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 2}, false},
+		{"Missing session", IntakeSessionItem{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 1}, true},
+		{"Missing scryfall_id", IntakeSessionItem{IntakeSessionID: 1, OracleID: "oracle-1", Quantity: 1}, true},
+		{"Missing oracle_id", IntakeSessionItem{IntakeSessionID: 1, ScryfallID: "card-1", Quantity: 1}, true},
+		{"Zero quantity", IntakeSessionItem{IntakeSessionID: 1, ScryfallID: "card-1", OracleID: "oracle-1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(&tt.item).Error
+			if tt.expectError && err == nil {
+				t.Error("expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -2,19 +2,50 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// MaxSerialNumberLength caps the serial number recorded for a serialized printing
+const MaxSerialNumberLength = 50
+
+// MaxInventoryNotesLength caps the free-text notes on an inventory row
+const MaxInventoryNotesLength = 1000
+
+// MaxAcquiredFromLength caps where an inventory row was acquired from
+const MaxAcquiredFromLength = 255
+
 // Inventory represents a card in the collection
 // tygo:export
 type Inventory struct {
 	BaseModel
-	ScryfallID        string `gorm:"type:varchar(255);not null;index" json:"scryfall_id"`
+	ScryfallID        string `gorm:"type:varchar(255);not null;index;uniqueIndex:idx_inventory_serial" json:"scryfall_id"`
 	OracleID          string `gorm:"type:varchar(255);not null;index;index:idx_oracle_storage" json:"oracle_id"`
 	Treatment         string `gorm:"type:varchar(100)" json:"treatment"`
 	Quantity          int    `gorm:"not null;default:1" json:"quantity"`
 	StorageLocationID *uint  `gorm:"index;index:idx_oracle_storage" json:"storage_location_id,omitempty"`
+	// SerialNumber identifies one copy of a serialized printing (e.g. "042/500"); unique per printing.
+	// Rows with a serial number always hold exactly one copy.
+	SerialNumber *string `gorm:"type:varchar(50);uniqueIndex:idx_inventory_serial" json:"serial_number,omitempty"`
+	// Notes records physical attributes Scryfall doesn't know about (e.g. "signed", "altered art")
+	Notes string `gorm:"type:text" json:"notes,omitempty"`
+	// AcquiredPrice is the price paid per copy, in the preferred currency
+	AcquiredPrice *float64 `json:"acquired_price,omitempty"`
+	// AcquiredAt is when the copies were acquired
+	AcquiredAt *time.Time `json:"acquired_at,omitempty"`
+	// AcquiredFrom records the seller or source (e.g. "LGS", "TCGplayer", "trade")
+	AcquiredFrom string `gorm:"type:varchar(255)" json:"acquired_from,omitempty"`
+	// DeletedAt is set while the row is in the trash; trashed rows are hidden from
+	// queries until restored or purged
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+
+	// OnLoanQuantity is the number of copies currently lent out (computed, not stored)
+	OnLoanQuantity int `gorm:"-" json:"on_loan_quantity"`
+	// Tags are the names of the tags on the item (computed, not stored)
+	Tags []string `gorm:"-" json:"tags,omitempty"`
 
 	// Relationship
 	StorageLocation *StorageLocation `gorm:"foreignKey:StorageLocationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"storage_location,omitempty"`
@@ -30,9 +61,69 @@ func (i *Inventory) ValidateInventory(tx *gorm.DB) error {
 	if i.Quantity < 0 {
 		return errors.New("quantity cannot be negative")
 	}
+	if len(i.Notes) > MaxInventoryNotesLength {
+		return fmt.Errorf("notes cannot exceed %d characters", MaxInventoryNotesLength)
+	}
+	if i.AcquiredPrice != nil && *i.AcquiredPrice < 0 {
+		return errors.New("acquired_price cannot be negative")
+	}
+	if len(i.AcquiredFrom) > MaxAcquiredFromLength {
+		return fmt.Errorf("acquired_from cannot exceed %d characters", MaxAcquiredFromLength)
+	}
+	if i.SerialNumber != nil {
+		if strings.TrimSpace(*i.SerialNumber) == "" {
+			return errors.New("serial_number cannot be blank")
+		}
+		if len(*i.SerialNumber) > MaxSerialNumberLength {
+			return fmt.Errorf("serial_number cannot exceed %d characters", MaxSerialNumberLength)
+		}
+		if i.Quantity != 1 {
+			return errors.New("serialized cards must have a quantity of 1")
+		}
+	}
 	return nil
 }
 
+// SerialNumberTaken reports whether another inventory row already records serial
+// for the printing. excludeID skips the row being updated (0 for new rows).
+func SerialNumberTaken(db *gorm.DB, scryfallID, serial string, excludeID uint) (bool, error) {
+	var count int64
+	// Trashed rows keep their serial until purged, so they still hold it
+	if err := db.Unscoped().Model(&Inventory{}).
+		Where("scryfall_id = ? AND serial_number = ? AND id != ?", scryfallID, serial, excludeID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("checking serial number: %w", err)
+	}
+	return count > 0, nil
+}
+
+// FindMergeableInventory returns the row a new item's copies can be added to: same
+// printing, treatment, storage location and notes, with no serial number or acquisition
+// details. Returns nil when there is none, or when the item itself is serialized or
+// carries acquisition details, since those copies belong in their own row.
+func FindMergeableInventory(db *gorm.DB, item Inventory) (*Inventory, error) {
+	if item.SerialNumber != nil || item.AcquiredPrice != nil || item.AcquiredAt != nil || item.AcquiredFrom != "" {
+		return nil, nil
+	}
+
+	query := db.Where("scryfall_id = ? AND COALESCE(treatment, '') = ? AND COALESCE(notes, '') = ?", item.ScryfallID, item.Treatment, item.Notes).
+		Where("serial_number IS NULL AND acquired_price IS NULL AND acquired_at IS NULL AND COALESCE(acquired_from, '') = ''")
+	if item.StorageLocationID == nil {
+		query = query.Where("storage_location_id IS NULL")
+	} else {
+		query = query.Where("storage_location_id = ?", *item.StorageLocationID)
+	}
+
+	var rows []Inventory
+	if err := query.Order("id").Limit(1).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("finding mergeable inventory: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return &rows[0], nil
+}
+
 // BeforeCreate validates the inventory before creating a record
 func (i *Inventory) BeforeCreate(tx *gorm.DB) error {
 	return i.ValidateInventory(tx)
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryCountDateFormat is the layout of InventoryCount.Date
+const InventoryCountDateFormat = time.DateOnly
+
+// InventoryCount is the size of the collection on one day, kept for growth charts
+// tygo:export
+type InventoryCount struct {
+	BaseModel
+	Date     string `gorm:"type:varchar(10);not null;uniqueIndex" json:"date"` // YYYY-MM-DD
+	Entries  int64  `gorm:"not null;default:0" json:"entries"`                 // Number of inventory rows
+	Quantity int64  `gorm:"not null;default:0" json:"quantity"`                // Sum of inventory quantities
+}
+
+func (ic *InventoryCount) ValidateInventoryCount(tx *gorm.DB) error {
+	if _, err := time.Parse(InventoryCountDateFormat, ic.Date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+	if ic.Entries < 0 || ic.Quantity < 0 {
+		return errors.New("counts cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the inventory count before creating a record
+func (ic *InventoryCount) BeforeCreate(tx *gorm.DB) error {
+	return ic.ValidateInventoryCount(tx)
+}
+
+// BeforeUpdate validates the inventory count before updating a record
+func (ic *InventoryCount) BeforeUpdate(tx *gorm.DB) error {
+	return ic.ValidateInventoryCount(tx)
+}
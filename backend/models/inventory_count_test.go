@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryCountTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&InventoryCount{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestInventoryCount_ValidateInventoryCount(t *testing.T) {
+	db := setupInventoryCountTestDB(t)
+
+	tests := []struct {
+		name        string
+		count       *InventoryCount
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Count",
+			count:       &InventoryCount{Date: "2026-01-31", Entries: 10, Quantity: 25},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Bad Date",
+			count:       &InventoryCount{Date: "31/01/2026"},
+			expectError: true,
+			errorMsg:    "date must be in YYYY-MM-DD format",
+		},
+		{
+			name:        "Invalid - Negative Quantity",
+			count:       &InventoryCount{Date: "2026-01-31", Quantity: -1},
+			expectError: true,
+			errorMsg:    "counts cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.count.ValidateInventoryCount(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// InventoryEventType identifies what happened to an inventory row
+// tygo:export
+type InventoryEventType string
+
+const (
+	InventoryEventCreated         InventoryEventType = "created"
+	InventoryEventQuantityChanged InventoryEventType = "quantity_changed"
+	InventoryEventMoved           InventoryEventType = "moved"
+	InventoryEventResorted        InventoryEventType = "resorted" // Moved, or split into a new row, by a resort
+	InventoryEventDeleted         InventoryEventType = "deleted"  // Moved to the trash
+	InventoryEventRestored        InventoryEventType = "restored" // Restored from the trash
+	InventoryEventSold            InventoryEventType = "sold"     // Copies sold or traded away
+)
+
+// Valid reports whether t is a known event type
+func (t InventoryEventType) Valid() bool {
+	switch t {
+	case InventoryEventCreated, InventoryEventQuantityChanged, InventoryEventMoved,
+		InventoryEventResorted, InventoryEventDeleted, InventoryEventRestored, InventoryEventSold:
+		return true
+	}
+	return false
+}
+
+// InventoryEvent records one change to an inventory row for the collection history.
+// InventoryID has no foreign key so a row's history outlives the row itself.
+// Old values are nil for creations and new values are nil for deletions.
+// tygo:export
+type InventoryEvent struct {
+	BaseModel
+	InventoryID          uint               `gorm:"not null;index" json:"inventory_id"`
+	ScryfallID           string             `gorm:"type:varchar(255);not null" json:"scryfall_id"`
+	Treatment            string             `gorm:"type:varchar(100)" json:"treatment"`
+	EventType            InventoryEventType `gorm:"type:varchar(30);not null;index" json:"event_type"`
+	OldQuantity          *int               `json:"old_quantity,omitempty"`
+	NewQuantity          *int               `json:"new_quantity,omitempty"`
+	OldStorageLocationID *uint              `json:"old_storage_location_id,omitempty"`
+	NewStorageLocationID *uint              `json:"new_storage_location_id,omitempty"`
+}
+
+func (e *InventoryEvent) ValidateInventoryEvent(tx *gorm.DB) error {
+	if e.InventoryID == 0 {
+		return errors.New("inventory_id cannot be empty")
+	}
+	if e.ScryfallID == "" {
+		return errors.New("scryfall_id cannot be empty")
+	}
+	if !e.EventType.Valid() {
+		return errors.New("event_type is not valid")
+	}
+	return nil
+}
+
+// BeforeCreate validates the event before creating a record
+func (e *InventoryEvent) BeforeCreate(tx *gorm.DB) error {
+	return e.ValidateInventoryEvent(tx)
+}
+
+// newInventoryEvent starts an event of the given type for item
+func newInventoryEvent(item Inventory, eventType InventoryEventType) InventoryEvent {
+	return InventoryEvent{
+		InventoryID: item.ID,
+		ScryfallID:  item.ScryfallID,
+		Treatment:   item.Treatment,
+		EventType:   eventType,
+	}
+}
+
+// NewInventoryCreatedEvent records a row being added to the collection
+func NewInventoryCreatedEvent(item Inventory) InventoryEvent {
+	event := newInventoryEvent(item, InventoryEventCreated)
+	event.NewQuantity = &item.Quantity
+	event.NewStorageLocationID = item.StorageLocationID
+	return event
+}
+
+// NewInventoryDeletedEvent records a row being removed from the collection
+func NewInventoryDeletedEvent(item Inventory) InventoryEvent {
+	event := newInventoryEvent(item, InventoryEventDeleted)
+	event.OldQuantity = &item.Quantity
+	event.OldStorageLocationID = item.StorageLocationID
+	return event
+}
+
+// NewInventoryRestoredEvent records a row coming back out of the trash
+func NewInventoryRestoredEvent(item Inventory) InventoryEvent {
+	event := newInventoryEvent(item, InventoryEventRestored)
+	event.NewQuantity = &item.Quantity
+	event.NewStorageLocationID = item.StorageLocationID
+	return event
+}
+
+// NewInventorySoldEvent records copies of a row leaving in a sale or trade; remaining
+// is 0 when the whole row went
+func NewInventorySoldEvent(item Inventory, remaining int) InventoryEvent {
+	event := newInventoryEvent(item, InventoryEventSold)
+	event.OldQuantity = &item.Quantity
+	event.NewQuantity = &remaining
+	event.OldStorageLocationID = item.StorageLocationID
+	return event
+}
+
+// NewInventoryResortedEvent records a resort placing after. before is nil for rows
+// a resort created by splitting another row across locations.
+func NewInventoryResortedEvent(before *Inventory, after Inventory) InventoryEvent {
+	event := newInventoryEvent(after, InventoryEventResorted)
+	if before != nil {
+		event.OldQuantity = &before.Quantity
+		event.OldStorageLocationID = before.StorageLocationID
+	}
+	event.NewQuantity = &after.Quantity
+	event.NewStorageLocationID = after.StorageLocationID
+	return event
+}
+
+// InventoryChangeEvents compares a row before and after an update and returns a
+// quantity_changed and/or moved event; other field changes are not recorded
+func InventoryChangeEvents(before, after Inventory) []InventoryEvent {
+	var events []InventoryEvent
+	if before.Quantity != after.Quantity {
+		event := newInventoryEvent(after, InventoryEventQuantityChanged)
+		event.OldQuantity = &before.Quantity
+		event.NewQuantity = &after.Quantity
+		events = append(events, event)
+	}
+	if !sameLocation(before.StorageLocationID, after.StorageLocationID) {
+		event := newInventoryEvent(after, InventoryEventMoved)
+		event.OldStorageLocationID = before.StorageLocationID
+		event.NewStorageLocationID = after.StorageLocationID
+		events = append(events, event)
+	}
+	return events
+}
+
+// sameLocation reports whether two optional storage location IDs are equal
+func sameLocation(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// RecordInventoryEvents stores events using db, which should be the transaction
+// that made the changes so the history never disagrees with the inventory
+func RecordInventoryEvents(db *gorm.DB, events []InventoryEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return db.Create(&events).Error
+}
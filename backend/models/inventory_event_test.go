@@ -0,0 +1,108 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryEventTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&InventoryEvent{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestInventoryEvent_ValidateInventoryEvent(t *testing.T) {
+	db := setupInventoryEventTestDB(t)
+
+	tests := []struct {
+		name     string
+		event    *InventoryEvent
+		errorMsg string
+	}{
+		{"Valid Event", &InventoryEvent{InventoryID: 1, ScryfallID: "s1", EventType: InventoryEventCreated}, ""},
+		{"Invalid - Missing InventoryID", &InventoryEvent{ScryfallID: "s1", EventType: InventoryEventCreated}, "inventory_id cannot be empty"},
+		{"Invalid - Missing ScryfallID", &InventoryEvent{InventoryID: 1, EventType: InventoryEventCreated}, "scryfall_id cannot be empty"},
+		{"Invalid - Unknown Type", &InventoryEvent{InventoryID: 1, ScryfallID: "s1", EventType: "renamed"}, "event_type is not valid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.event.ValidateInventoryEvent(db)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestInventoryChangeEvents(t *testing.T) {
+	binder, box := uint(1), uint(2)
+	before := Inventory{BaseModel: BaseModel{ID: 7}, ScryfallID: "s1", Quantity: 2, StorageLocationID: &binder}
+
+	tests := []struct {
+		name     string
+		after    Inventory
+		expected []InventoryEventType
+	}{
+		{"No change", before, nil},
+		{"Quantity", Inventory{BaseModel: before.BaseModel, ScryfallID: "s1", Quantity: 4, StorageLocationID: &binder}, []InventoryEventType{InventoryEventQuantityChanged}},
+		{"Location", Inventory{BaseModel: before.BaseModel, ScryfallID: "s1", Quantity: 2, StorageLocationID: &box}, []InventoryEventType{InventoryEventMoved}},
+		{"Unassigned", Inventory{BaseModel: before.BaseModel, ScryfallID: "s1", Quantity: 2}, []InventoryEventType{InventoryEventMoved}},
+		{"Both", Inventory{BaseModel: before.BaseModel, ScryfallID: "s1", Quantity: 1, StorageLocationID: &box}, []InventoryEventType{InventoryEventQuantityChanged, InventoryEventMoved}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := InventoryChangeEvents(before, tt.after)
+			if len(events) != len(tt.expected) {
+				t.Fatalf("expected %d events, got %d", len(tt.expected), len(events))
+			}
+			for i, event := range events {
+				if event.EventType != tt.expected[i] || event.InventoryID != 7 {
+					t.Errorf("unexpected event %d: %+v", i, event)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordInventoryEvents(t *testing.T) {
+	db := setupInventoryEventTestDB(t)
+
+	if err := RecordInventoryEvents(db, nil); err != nil {
+		t.Fatalf("expected no error for no events, got %v", err)
+	}
+
+	location := uint(3)
+	item := Inventory{BaseModel: BaseModel{ID: 5}, ScryfallID: "s1", Treatment: "foil", Quantity: 2, StorageLocationID: &location}
+	events := []InventoryEvent{NewInventoryCreatedEvent(item), NewInventoryDeletedEvent(item)}
+	if err := RecordInventoryEvents(db, events); err != nil {
+		t.Fatalf("failed to record events: %v", err)
+	}
+
+	var stored []InventoryEvent
+	db.Order("id").Find(&stored)
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(stored))
+	}
+	if created := stored[0]; created.OldQuantity != nil || *created.NewQuantity != 2 || *created.NewStorageLocationID != 3 || created.Treatment != "foil" {
+		t.Errorf("unexpected created event: %+v", created)
+	}
+	if deleted := stored[1]; deleted.NewQuantity != nil || *deleted.OldQuantity != 2 || *deleted.OldStorageLocationID != 3 {
+		t.Errorf("unexpected deleted event: %+v", deleted)
+	}
+}
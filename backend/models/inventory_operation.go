@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InventoryOperation is a batch inventory change (batch move, batch delete, or resort)
+// with the state needed to undo it. Snapshot holds the JSON-encoded rows as they were
+// before the operation, so it can be reverted long after its undo token has expired.
+// tygo:export
+type InventoryOperation struct {
+	BaseModel
+	Operation string     `gorm:"type:varchar(30);not null;index" json:"operation"`
+	ItemCount int        `gorm:"not null;default:0" json:"item_count"` // Rows the operation changed
+	Snapshot  string     `gorm:"type:text;not null" json:"-"`
+	UndoneAt  *time.Time `json:"undone_at,omitempty"`
+}
+
+func (o *InventoryOperation) ValidateInventoryOperation(tx *gorm.DB) error {
+	if o.Operation == "" {
+		return errors.New("operation cannot be empty")
+	}
+	if o.Snapshot == "" {
+		return errors.New("snapshot cannot be empty")
+	}
+	if o.ItemCount < 0 {
+		return errors.New("item_count cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the operation before creating a record
+func (o *InventoryOperation) BeforeCreate(tx *gorm.DB) error {
+	return o.ValidateInventoryOperation(tx)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestInventoryOperation_ValidateInventoryOperation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&InventoryOperation{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		operation *InventoryOperation
+		errorMsg  string
+	}{
+		{"Valid Operation", &InventoryOperation{Operation: "batch_move", ItemCount: 2, Snapshot: "{}"}, ""},
+		{"Invalid - Missing Operation", &InventoryOperation{Snapshot: "{}"}, "operation cannot be empty"},
+		{"Invalid - Missing Snapshot", &InventoryOperation{Operation: "batch_move"}, "snapshot cannot be empty"},
+		{"Invalid - Negative ItemCount", &InventoryOperation{Operation: "batch_move", ItemCount: -1, Snapshot: "{}"}, "item_count cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.operation).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
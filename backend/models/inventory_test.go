@@ -1,7 +1,9 @@
 package models
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -336,3 +338,146 @@ func TestInventory_Treatments(t *testing.T) {
 		})
 	}
 }
+
+func TestInventory_SerialNumber(t *testing.T) {
+	serial := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		inventory Inventory
+		wantErr   bool
+	}{
+		{"Single serialized copy", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, SerialNumber: serial("042/500")}, false},
+		{"Blank serial", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, SerialNumber: serial("  ")}, true},
+		{"Too long", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, SerialNumber: serial(strings.Repeat("9", MaxSerialNumberLength+1))}, true},
+		{"Several copies", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 2, SerialNumber: serial("042/500")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.inventory.ValidateInventory(nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInventory_NotesLength(t *testing.T) {
+	item := Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, Notes: strings.Repeat("a", MaxInventoryNotesLength)}
+	if err := item.ValidateInventory(nil); err != nil {
+		t.Errorf("expected notes at the limit to be valid, got %v", err)
+	}
+
+	item.Notes += "a"
+	if err := item.ValidateInventory(nil); err == nil {
+		t.Error("expected notes over the limit to be rejected")
+	}
+}
+
+func TestInventory_Acquisition(t *testing.T) {
+	price := 4.5
+	negative := -1.0
+	acquiredAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		item    Inventory
+		wantErr bool
+	}{
+		{"No acquisition details", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1}, false},
+		{"Full acquisition details", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, AcquiredPrice: &price, AcquiredAt: &acquiredAt, AcquiredFrom: "LGS"}, false},
+		{"Negative price", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, AcquiredPrice: &negative}, true},
+		{"Source too long", Inventory{ScryfallID: "s", OracleID: "o", Quantity: 1, AcquiredFrom: strings.Repeat("a", MaxAcquiredFromLength+1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.item.ValidateInventory(nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateInventory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInventory_SerialNumberUniquePerPrinting(t *testing.T) {
+	db := setupInventoryTestDB(t)
+	serial := "042/500"
+
+	if err := db.Create(&Inventory{ScryfallID: "s1", OracleID: "o", Quantity: 1, SerialNumber: &serial}).Error; err != nil {
+		t.Fatalf("failed to create serialized copy: %v", err)
+	}
+	if err := db.Create(&Inventory{ScryfallID: "s1", OracleID: "o", Quantity: 1, SerialNumber: &serial}).Error; err == nil {
+		t.Error("expected a duplicate serial on the same printing to be rejected")
+	}
+	if err := db.Create(&Inventory{ScryfallID: "s2", OracleID: "o", Quantity: 1, SerialNumber: &serial}).Error; err != nil {
+		t.Errorf("expected the same serial on another printing to be allowed: %v", err)
+	}
+	// Any number of unserialized rows may share a printing
+	for range 2 {
+		if err := db.Create(&Inventory{ScryfallID: "s1", OracleID: "o", Quantity: 3}).Error; err != nil {
+			t.Errorf("failed to create unserialized row: %v", err)
+		}
+	}
+
+	taken, err := SerialNumberTaken(db, "s1", serial, 0)
+	if err != nil || !taken {
+		t.Errorf("expected serial to be taken, got %v (err %v)", taken, err)
+	}
+	var existing Inventory
+	db.Where("scryfall_id = ? AND serial_number = ?", "s1", serial).First(&existing)
+	taken, err = SerialNumberTaken(db, "s1", serial, existing.ID)
+	if err != nil || taken {
+		t.Errorf("expected the row's own serial not to count, got %v (err %v)", taken, err)
+	}
+}
+
+func TestFindMergeableInventory(t *testing.T) {
+	db := setupInventoryTestDB(t)
+	location := StorageLocation{Name: "Box", StorageType: Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	price := 2.5
+	serial := "001/100"
+	rows := []Inventory{
+		{ScryfallID: "s1", OracleID: "o", Treatment: "nonfoil", Quantity: 2, StorageLocationID: &location.ID},
+		{ScryfallID: "s1", OracleID: "o", Treatment: "nonfoil", Quantity: 1},
+		{ScryfallID: "s1", OracleID: "o", Treatment: "foil", Quantity: 1, StorageLocationID: &location.ID, Notes: "signed"},
+		{ScryfallID: "s2", OracleID: "o", Treatment: "nonfoil", Quantity: 1, AcquiredPrice: &price},
+		{ScryfallID: "s3", OracleID: "o", Treatment: "nonfoil", Quantity: 1, SerialNumber: &serial},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		item Inventory
+		want uint // 0 when no row matches
+	}{
+		{"Same location", Inventory{ScryfallID: "s1", Treatment: "nonfoil", StorageLocationID: &location.ID}, rows[0].ID},
+		{"Both unassigned", Inventory{ScryfallID: "s1", Treatment: "nonfoil"}, rows[1].ID},
+		{"Same notes", Inventory{ScryfallID: "s1", Treatment: "foil", StorageLocationID: &location.ID, Notes: "signed"}, rows[2].ID},
+		{"Different notes", Inventory{ScryfallID: "s1", Treatment: "foil", StorageLocationID: &location.ID}, 0},
+		{"Different treatment", Inventory{ScryfallID: "s1", Treatment: "etched"}, 0},
+		{"Row has acquisition details", Inventory{ScryfallID: "s2", Treatment: "nonfoil"}, 0},
+		{"Item has acquisition details", Inventory{ScryfallID: "s1", Treatment: "nonfoil", AcquiredFrom: "LGS"}, 0},
+		{"Serialized row", Inventory{ScryfallID: "s3", Treatment: "nonfoil"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := FindMergeableInventory(db, tt.item)
+			if err != nil {
+				t.Fatalf("FindMergeableInventory failed: %v", err)
+			}
+			var got uint
+			if found != nil {
+				got = found.ID
+			}
+			if got != tt.want {
+				t.Errorf("expected row %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
@@ -14,14 +14,18 @@ import (
 type JobType string
 
 const (
-	JobTypeBulkDataImport JobType = "bulk_data_import"
-	JobTypeSetDataImport  JobType = "set_data_import"
+	JobTypeBulkDataImport  JobType = "bulk_data_import"
+	JobTypeSetDataImport   JobType = "set_data_import"
+	JobTypeInventoryImport JobType = "inventory_import"
+	JobTypeReindex         JobType = "maintenance_reindex"
+	JobTypeImagePrefetch   JobType = "card_image_prefetch"
+	JobTypeResort          JobType = "inventory_resort"
 )
 
 // Valid checks if the job type is valid
 func (jt JobType) Valid() bool {
 	switch jt {
-	case JobTypeBulkDataImport, JobTypeSetDataImport:
+	case JobTypeBulkDataImport, JobTypeSetDataImport, JobTypeInventoryImport, JobTypeReindex, JobTypeImagePrefetch, JobTypeResort:
 		return true
 	default:
 		return false
@@ -61,12 +65,15 @@ const (
 	JobStatusCompleted  JobStatus = "completed"
 	JobStatusFailed     JobStatus = "failed"
 	JobStatusCancelled  JobStatus = "cancelled"
+
+	// JobStatusInterrupted is a job stopped by shutdown, resumed on the next start
+	JobStatusInterrupted JobStatus = "interrupted"
 )
 
 // Valid checks if the job status is valid
 func (js JobStatus) Valid() bool {
 	switch js {
-	case JobStatusPending, JobStatusInProgress, JobStatusCompleted, JobStatusFailed, JobStatusCancelled:
+	case JobStatusPending, JobStatusInProgress, JobStatusCompleted, JobStatusFailed, JobStatusCancelled, JobStatusInterrupted:
 		return true
 	default:
 		return false
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// JobResult holds the JSON-encoded outcome of a finished job, for jobs whose result
+// is too large for the job's metadata (such as a resort's movements list)
+// tygo:export
+type JobResult struct {
+	BaseModel
+	JobID   uint   `gorm:"not null;uniqueIndex" json:"job_id"`
+	Content string `gorm:"type:text;not null" json:"-"`
+}
+
+func (r *JobResult) ValidateJobResult(tx *gorm.DB) error {
+	if r.JobID == 0 {
+		return errors.New("job_id cannot be empty")
+	}
+	if r.Content == "" {
+		return errors.New("content cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the result before creating a record
+func (r *JobResult) BeforeCreate(tx *gorm.DB) error {
+	return r.ValidateJobResult(tx)
+}
+
+// BeforeUpdate validates the result before updating a record
+func (r *JobResult) BeforeUpdate(tx *gorm.DB) error {
+	return r.ValidateJobResult(tx)
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestJobResult_ValidateJobResult(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&JobResult{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		result   *JobResult
+		errorMsg string
+	}{
+		{"Valid Result", &JobResult{JobID: 1, Content: "{}"}, ""},
+		{"Invalid - Missing JobID", &JobResult{Content: "{}"}, "job_id cannot be empty"},
+		{"Invalid - Missing Content", &JobResult{JobID: 2}, "content cannot be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.result).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
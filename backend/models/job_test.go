@@ -27,6 +27,10 @@ func TestJobType_Valid(t *testing.T) {
 	}{
 		{"BulkDataImport", JobTypeBulkDataImport, true},
 		{"SetDataImport", JobTypeSetDataImport, true},
+		{"InventoryImport", JobTypeInventoryImport, true},
+		{"Reindex", JobTypeReindex, true},
+		{"ImagePrefetch", JobTypeImagePrefetch, true},
+		{"Resort", JobTypeResort, true},
 		{"Empty", JobType(""), false},
 		{"InvalidType", JobType("invalid_type"), false},
 		{"CaseSensitive", JobType("Bulk_Data_Import"), false},
@@ -53,6 +57,7 @@ func TestJobStatus_Valid(t *testing.T) {
 		{"Completed", JobStatusCompleted, true},
 		{"Failed", JobStatusFailed, true},
 		{"Cancelled", JobStatusCancelled, true},
+		{"Interrupted", JobStatusInterrupted, true},
 		{"Empty", JobStatus(""), false},
 		{"InvalidStatus", JobStatus("running"), false},
 		{"CaseSensitive", JobStatus("Pending"), false},
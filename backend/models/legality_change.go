@@ -0,0 +1,36 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// LegalityChange records an owned card's status changing in a format between bulk imports
+// tygo:export
+type LegalityChange struct {
+	BaseModel
+	OracleID       string `gorm:"type:varchar(255);not null;index" json:"oracle_id"`
+	CardName       string `gorm:"type:varchar(255);not null" json:"card_name"`
+	Format         string `gorm:"type:varchar(50);not null;index" json:"format"`
+	PreviousStatus string `gorm:"type:varchar(20);not null" json:"previous_status"`
+	Status         string `gorm:"type:varchar(20);not null" json:"status"`
+}
+
+func (lc *LegalityChange) ValidateLegalityChange(tx *gorm.DB) error {
+	if lc.OracleID == "" {
+		return errors.New("oracle_id cannot be empty")
+	}
+	if lc.Format == "" {
+		return errors.New("format cannot be empty")
+	}
+	if lc.Status == "" || lc.PreviousStatus == "" {
+		return errors.New("status and previous_status must be set")
+	}
+	return nil
+}
+
+// BeforeCreate validates the legality change before creating a record
+func (lc *LegalityChange) BeforeCreate(tx *gorm.DB) error {
+	return lc.ValidateLegalityChange(tx)
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLegalityChangeTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&LegalityChange{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestLegalityChange_ValidateLegalityChange(t *testing.T) {
+	db := setupLegalityChangeTestDB(t)
+
+	tests := []struct {
+		name        string
+		change      *LegalityChange
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Change",
+			change:      &LegalityChange{OracleID: "o1", CardName: "Card", Format: "modern", PreviousStatus: "legal", Status: "banned"},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Empty OracleID",
+			change:      &LegalityChange{CardName: "Card", Format: "modern", PreviousStatus: "legal", Status: "banned"},
+			expectError: true,
+			errorMsg:    "oracle_id cannot be empty",
+		},
+		{
+			name:        "Invalid - Empty Format",
+			change:      &LegalityChange{OracleID: "o1", CardName: "Card", PreviousStatus: "legal", Status: "banned"},
+			expectError: true,
+			errorMsg:    "format cannot be empty",
+		},
+		{
+			name:        "Invalid - Missing PreviousStatus",
+			change:      &LegalityChange{OracleID: "o1", CardName: "Card", Format: "modern", Status: "banned"},
+			expectError: true,
+			errorMsg:    "status and previous_status must be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change.ValidateLegalityChange(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
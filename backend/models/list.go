@@ -12,6 +12,9 @@ type List struct {
 	BaseModel
 	Name        string `gorm:"type:varchar(255);not null" json:"name"`
 	Description string `gorm:"type:text" json:"description,omitempty"`
+	// AutoTrackInventory keeps items' CollectedQuantity in step with owned inventory
+	// instead of being edited by hand
+	AutoTrackInventory bool `gorm:"not null;default:false" json:"auto_track_inventory"`
 
 	// Relationship - items in this list
 	Items []ListItem `gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
@@ -16,6 +16,7 @@ type ListItem struct {
 	Treatment         string `gorm:"type:varchar(100);uniqueIndex:idx_list_card_treatment" json:"treatment"`
 	DesiredQuantity   int    `gorm:"not null;default:1" json:"desired_quantity"`
 	CollectedQuantity int    `gorm:"not null;default:0" json:"collected_quantity"`
+	LastEditedBy      string `gorm:"type:varchar(100)" json:"last_edited_by,omitempty"` // Collaborator name; empty when edited by the owner
 
 	// Relationship
 	List *List `gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"list,omitempty"`
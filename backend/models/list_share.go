@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListShare is an invite token that lets a named collaborator edit a list
+// tygo:export
+type ListShare struct {
+	BaseModel
+	ListID       uint       `gorm:"not null;index" json:"list_id"`
+	Token        string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token"`
+	Collaborator string     `gorm:"type:varchar(100);not null" json:"collaborator"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+
+	// Relationship
+	List *List `gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"list,omitempty"`
+}
+
+func (ls *ListShare) ValidateListShare(tx *gorm.DB) error {
+	if ls.ListID == 0 {
+		return errors.New("list_id must be set")
+	}
+	if ls.Token == "" {
+		return errors.New("token cannot be empty")
+	}
+	if ls.Collaborator == "" {
+		return errors.New("collaborator cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the list share before creating a record
+func (ls *ListShare) BeforeCreate(tx *gorm.DB) error {
+	return ls.ValidateListShare(tx)
+}
+
+// BeforeUpdate validates the list share before updating a record
+func (ls *ListShare) BeforeUpdate(tx *gorm.DB) error {
+	return ls.ValidateListShare(tx)
+}
+
+// IsActive reports whether the share can still be used
+func (ls *ListShare) IsActive() bool {
+	return ls.RevokedAt == nil
+}
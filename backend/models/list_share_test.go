@@ -0,0 +1,67 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListShare_ValidateListShare(t *testing.T) {
+	db := setupListTestDB(t)
+
+	tests := []struct {
+		name        string
+		share       *ListShare
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Share",
+			share:       &ListShare{ListID: 1, Token: "abc", Collaborator: "Sam"},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Missing ListID",
+			share:       &ListShare{Token: "abc", Collaborator: "Sam"},
+			expectError: true,
+			errorMsg:    "list_id must be set",
+		},
+		{
+			name:        "Invalid - Empty Token",
+			share:       &ListShare{ListID: 1, Collaborator: "Sam"},
+			expectError: true,
+			errorMsg:    "token cannot be empty",
+		},
+		{
+			name:        "Invalid - Empty Collaborator",
+			share:       &ListShare{ListID: 1, Token: "abc"},
+			expectError: true,
+			errorMsg:    "collaborator cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.share.ValidateListShare(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestListShare_IsActive(t *testing.T) {
+	now := time.Now()
+
+	if !(&ListShare{}).IsActive() {
+		t.Error("expected share without revoked_at to be active")
+	}
+	if (&ListShare{RevokedAt: &now}).IsActive() {
+		t.Error("expected revoked share to be inactive")
+	}
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loan represents a set of inventory items lent to someone
+// tygo:export
+type Loan struct {
+	BaseModel
+	Borrower          string     `gorm:"type:varchar(255);not null;index" json:"borrower"`
+	DueDate           *time.Time `gorm:"index" json:"due_date,omitempty"`
+	Notes             string     `gorm:"type:text" json:"notes"`
+	ReturnedAt        *time.Time `gorm:"index" json:"returned_at,omitempty"`
+	OverdueNotifiedAt *time.Time `json:"overdue_notified_at,omitempty"`
+
+	// Relationship
+	Items []LoanItem `gorm:"foreignKey:LoanID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// IsActive reports whether the loan has not yet been returned
+func (l *Loan) IsActive() bool {
+	return l.ReturnedAt == nil
+}
+
+// IsOverdue reports whether the loan is still out past its due date
+func (l *Loan) IsOverdue(now time.Time) bool {
+	return l.IsActive() && l.DueDate != nil && l.DueDate.Before(now)
+}
+
+func (l *Loan) ValidateLoan(tx *gorm.DB) error {
+	if l.Borrower == "" {
+		return errors.New("borrower cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the loan before creating a record
+func (l *Loan) BeforeCreate(tx *gorm.DB) error {
+	return l.ValidateLoan(tx)
+}
+
+// BeforeUpdate validates the loan before updating a record
+func (l *Loan) BeforeUpdate(tx *gorm.DB) error {
+	return l.ValidateLoan(tx)
+}
+
+// LoanItem represents a quantity of one inventory entry included in a loan
+// tygo:export
+type LoanItem struct {
+	BaseModel
+	LoanID      uint `gorm:"not null;index" json:"loan_id"`
+	InventoryID uint `gorm:"not null;index" json:"inventory_id"`
+	Quantity    int  `gorm:"not null;default:1" json:"quantity"`
+
+	// Relationship
+	Inventory *Inventory `gorm:"foreignKey:InventoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"inventory,omitempty"`
+}
+
+func (li *LoanItem) ValidateLoanItem(tx *gorm.DB) error {
+	if li.InventoryID == 0 {
+		return errors.New("inventory_id must be set")
+	}
+	if li.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	return nil
+}
+
+// BeforeCreate validates the loan item before creating a record
+func (li *LoanItem) BeforeCreate(tx *gorm.DB) error {
+	return li.ValidateLoanItem(tx)
+}
+
+// BeforeUpdate validates the loan item before updating a record
+func (li *LoanItem) BeforeUpdate(tx *gorm.DB) error {
+	return li.ValidateLoanItem(tx)
+}
+
+// ActiveLoanQuantities is a subquery of the quantity currently lent out per inventory
+// ID, with inventory_id and quantity columns. Join it into queries that should only
+// count copies on hand.
+func ActiveLoanQuantities(db *gorm.DB) *gorm.DB {
+	return db.Table("loan_items").
+		Select("loan_items.inventory_id AS inventory_id, SUM(loan_items.quantity) AS quantity").
+		Joins("JOIN loans ON loans.id = loan_items.loan_id").
+		Where("loans.returned_at IS NULL").
+		Group("loan_items.inventory_id")
+}
+
+// GetOnLoanQuantities returns the quantity currently lent out for each of the
+// given inventory IDs. Inventory items with nothing on loan are omitted.
+func GetOnLoanQuantities(db *gorm.DB, inventoryIDs []uint) (map[uint]int, error) {
+	result := make(map[uint]int)
+	if len(inventoryIDs) == 0 {
+		return result, nil
+	}
+
+	type row struct {
+		InventoryID uint
+		Quantity    int
+	}
+	var rows []row
+	if err := ActiveLoanQuantities(db).
+		Where("loan_items.inventory_id IN ?", inventoryIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		result[r.InventoryID] = r.Quantity
+	}
+	return result, nil
+}
@@ -0,0 +1,177 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLoanTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Inventory{}, &StorageLocation{}, &Loan{}, &LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestLoan_ValidateLoan(t *testing.T) {
+	db := setupLoanTestDB(t)
+
+	tests := []struct {
+		name        string
+		loan        *Loan
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Loan",
+			loan:        &Loan{Borrower: "Alex"},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Empty Borrower",
+			loan:        &Loan{Borrower: ""},
+			expectError: true,
+			errorMsg:    "borrower cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.loan.ValidateLoan(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoanItem_ValidateLoanItem(t *testing.T) {
+	db := setupLoanTestDB(t)
+
+	tests := []struct {
+		name        string
+		item        *LoanItem
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Item",
+			item:        &LoanItem{InventoryID: 1, Quantity: 2},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Missing InventoryID",
+			item:        &LoanItem{Quantity: 1},
+			expectError: true,
+			errorMsg:    "inventory_id must be set",
+		},
+		{
+			name:        "Invalid - Zero Quantity",
+			item:        &LoanItem{InventoryID: 1, Quantity: 0},
+			expectError: true,
+			errorMsg:    "quantity must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.item.ValidateLoanItem(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoan_IsOverdue(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-24 * time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name     string
+		loan     Loan
+		expected bool
+	}{
+		{name: "No due date", loan: Loan{}, expected: false},
+		{name: "Due in future", loan: Loan{DueDate: &future}, expected: false},
+		{name: "Past due", loan: Loan{DueDate: &past}, expected: true},
+		{name: "Past due but returned", loan: Loan{DueDate: &past, ReturnedAt: &now}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loan.IsOverdue(now); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGetOnLoanQuantities(t *testing.T) {
+	db := setupLoanTestDB(t)
+
+	inv1 := Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 4}
+	inv2 := Inventory{ScryfallID: "card-2", OracleID: "oracle-2", Quantity: 2}
+	db.Create(&inv1)
+	db.Create(&inv2)
+
+	active := Loan{Borrower: "Alex", Items: []LoanItem{
+		{InventoryID: inv1.ID, Quantity: 2},
+	}}
+	db.Create(&active)
+
+	another := Loan{Borrower: "Sam", Items: []LoanItem{
+		{InventoryID: inv1.ID, Quantity: 1},
+	}}
+	db.Create(&another)
+
+	returnedAt := time.Now()
+	returned := Loan{Borrower: "Jo", ReturnedAt: &returnedAt, Items: []LoanItem{
+		{InventoryID: inv2.ID, Quantity: 2},
+	}}
+	db.Create(&returned)
+
+	result, err := GetOnLoanQuantities(db, []uint{inv1.ID, inv2.ID})
+	if err != nil {
+		t.Fatalf("GetOnLoanQuantities failed: %v", err)
+	}
+
+	if result[inv1.ID] != 3 {
+		t.Errorf("expected 3 copies of inventory %d on loan, got %d", inv1.ID, result[inv1.ID])
+	}
+	if _, ok := result[inv2.ID]; ok {
+		t.Errorf("expected returned loan to be excluded, got %d", result[inv2.ID])
+	}
+}
+
+func TestGetOnLoanQuantities_EmptyIDs(t *testing.T) {
+	db := setupLoanTestDB(t)
+
+	result, err := GetOnLoanQuantities(db, nil)
+	if err != nil {
+		t.Fatalf("GetOnLoanQuantities failed: %v", err)
+	}
+	if len(result) != 0 {
+		t.Errorf("expected empty result, got %v", result)
+	}
+}
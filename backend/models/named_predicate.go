@@ -0,0 +1,45 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// predicateNamePattern restricts predicate names to identifiers so they can be
+// referenced unambiguously as predicate('name') inside rule expressions
+var predicateNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NamedPredicate is a reusable boolean sub-expression that sorting rules can
+// reference by name with predicate('name')
+// tygo:export
+type NamedPredicate struct {
+	BaseModel
+	Name        string `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Expression  string `gorm:"type:text;not null" json:"expression"`
+	Description string `gorm:"type:text" json:"description"`
+}
+
+func (p *NamedPredicate) ValidateNamedPredicate(tx *gorm.DB) error {
+	if p.Name == "" {
+		return errors.New("predicate name cannot be empty")
+	}
+	if !predicateNamePattern.MatchString(p.Name) {
+		return errors.New("predicate name must start with a letter or underscore and contain only letters, digits, and underscores")
+	}
+	if p.Expression == "" {
+		return errors.New("predicate expression cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the predicate before creating a record
+func (p *NamedPredicate) BeforeCreate(tx *gorm.DB) error {
+	return p.ValidateNamedPredicate(tx)
+}
+
+// BeforeUpdate validates the predicate before updating a record
+func (p *NamedPredicate) BeforeUpdate(tx *gorm.DB) error {
+	return p.ValidateNamedPredicate(tx)
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNamedPredicate_ValidateNamedPredicate(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&NamedPredicate{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		predicate   *NamedPredicate
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:      "Valid Predicate",
+			predicate: &NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"},
+		},
+		{
+			name:      "Valid - Underscore Name",
+			predicate: &NamedPredicate{Name: "_edh_staple2", Expression: "edhrec_rank < 500"},
+		},
+		{
+			name:        "Invalid - Empty Name",
+			predicate:   &NamedPredicate{Expression: "true"},
+			expectError: true,
+			errorMsg:    "predicate name cannot be empty",
+		},
+		{
+			name:        "Invalid - Name With Spaces",
+			predicate:   &NamedPredicate{Name: "is bulk", Expression: "true"},
+			expectError: true,
+			errorMsg:    "predicate name must start with a letter or underscore and contain only letters, digits, and underscores",
+		},
+		{
+			name:        "Invalid - Name Starting With Digit",
+			predicate:   &NamedPredicate{Name: "1bulk", Expression: "true"},
+			expectError: true,
+			errorMsg:    "predicate name must start with a letter or underscore and contain only letters, digits, and underscores",
+		},
+		{
+			name:        "Invalid - Empty Expression",
+			predicate:   &NamedPredicate{Name: "isBulk"},
+			expectError: true,
+			errorMsg:    "predicate expression cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.predicate.ValidateNamedPredicate(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationType represents the kind of event a notification describes
+// tygo:export
+type NotificationType string
+
+const (
+	NotificationTypeLoanOverdue    NotificationType = "loan_overdue"
+	NotificationTypeLegalityChange NotificationType = "legality_change"
+	NotificationTypeImportDigest   NotificationType = "import_digest"
+	NotificationTypeValueAlert     NotificationType = "value_alert"
+	NotificationTypeTaskFailure    NotificationType = "task_failure"
+)
+
+// Valid checks if the notification type is valid
+func (nt NotificationType) Valid() bool {
+	switch nt {
+	case NotificationTypeLoanOverdue, NotificationTypeLegalityChange, NotificationTypeImportDigest, NotificationTypeValueAlert,
+		NotificationTypeTaskFailure:
+		return true
+	default:
+		return false
+	}
+}
+
+// Notification is a message raised by a background check for the user to review
+// tygo:export
+type Notification struct {
+	BaseModel
+	Type    NotificationType `gorm:"type:varchar(50);not null;index" json:"type"`
+	Title   string           `gorm:"type:varchar(255);not null" json:"title"`
+	Message string           `gorm:"type:text" json:"message"`
+	ReadAt  *time.Time       `gorm:"index" json:"read_at,omitempty"`
+}
+
+func (n *Notification) ValidateNotification(tx *gorm.DB) error {
+	if !n.Type.Valid() {
+		return errors.New("invalid notification type")
+	}
+	if n.Title == "" {
+		return errors.New("title cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the notification before creating a record
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	return n.ValidateNotification(tx)
+}
+
+// BeforeUpdate validates the notification before updating a record
+func (n *Notification) BeforeUpdate(tx *gorm.DB) error {
+	return n.ValidateNotification(tx)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PriceSnapshotDateFormat is the layout of PriceSnapshot.Date
+const PriceSnapshotDateFormat = time.DateOnly
+
+// PriceSnapshot is an owned printing's price on one day, kept so value alerts can
+// measure price changes over time
+// tygo:export
+type PriceSnapshot struct {
+	BaseModel
+	Date       string   `gorm:"type:varchar(10);not null;uniqueIndex:idx_price_snapshot" json:"date"` // YYYY-MM-DD
+	ScryfallID string   `gorm:"type:varchar(255);not null;uniqueIndex:idx_price_snapshot" json:"scryfall_id"`
+	Treatment  string   `gorm:"type:varchar(100);not null;uniqueIndex:idx_price_snapshot" json:"treatment"`
+	Currency   Currency `gorm:"type:varchar(3);not null;uniqueIndex:idx_price_snapshot" json:"currency"`
+	Price      float64  `gorm:"not null" json:"price"`
+}
+
+func (ps *PriceSnapshot) ValidatePriceSnapshot(tx *gorm.DB) error {
+	if _, err := time.Parse(PriceSnapshotDateFormat, ps.Date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+	if ps.ScryfallID == "" {
+		return errors.New("scryfall_id cannot be empty")
+	}
+	if !ps.Currency.Valid() {
+		return errors.New("invalid currency")
+	}
+	if ps.Price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the snapshot before creating a record
+func (ps *PriceSnapshot) BeforeCreate(tx *gorm.DB) error {
+	return ps.ValidatePriceSnapshot(tx)
+}
+
+// BeforeUpdate validates the snapshot before updating a record
+func (ps *PriceSnapshot) BeforeUpdate(tx *gorm.DB) error {
+	return ps.ValidatePriceSnapshot(tx)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestPriceSnapshot_ValidatePriceSnapshot(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&PriceSnapshot{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		snapshot *PriceSnapshot
+		errorMsg string
+	}{
+		{"Valid Snapshot", &PriceSnapshot{Date: "2024-05-01", ScryfallID: "bolt", Treatment: "foil", Currency: CurrencyUSD, Price: 2.5}, ""},
+		{"Invalid - Bad Date", &PriceSnapshot{Date: "05/01/2024", ScryfallID: "bolt", Currency: CurrencyUSD}, "date must be in YYYY-MM-DD format"},
+		{"Invalid - Missing ScryfallID", &PriceSnapshot{Date: "2024-05-01", Currency: CurrencyUSD}, "scryfall_id cannot be empty"},
+		{"Invalid - Unknown Currency", &PriceSnapshot{Date: "2024-05-01", ScryfallID: "bolt", Currency: "gbp"}, "invalid currency"},
+		{"Invalid - Negative Price", &PriceSnapshot{Date: "2024-05-01", ScryfallID: "bolt", Currency: CurrencyEUR, Price: -1}, "price cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.snapshot).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
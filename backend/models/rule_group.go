@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// RuleGroupOperator combines the expressions of a rule group
+// tygo:export
+type RuleGroupOperator string
+
+const (
+	RuleGroupAnd RuleGroupOperator = "and" // Every expression must match
+	RuleGroupOr  RuleGroupOperator = "or"  // Any expression may match
+)
+
+// RuleGroupAction is what happens to a card matching a rule group
+// tygo:export
+type RuleGroupAction string
+
+const (
+	RuleGroupAssign RuleGroupAction = "assign" // Place the card in StorageLocationID, like a sorting rule
+	RuleGroupTag    RuleGroupAction = "tag"    // Give the card Tag for later rules and groups, then fall through
+	RuleGroupSkip   RuleGroupAction = "skip"   // Stop evaluating; later rules and groups don't apply
+)
+
+// RuleGroup combines several expressions with AND or OR and applies an action to the
+// cards that match. Enabled groups are evaluated together with the sorting rules in
+// priority order, after rules of the same priority.
+// tygo:export
+type RuleGroup struct {
+	BaseModel
+	Name        string            `gorm:"type:varchar(255);not null" json:"name"`
+	Priority    int               `gorm:"not null;index" json:"priority"`
+	Operator    RuleGroupOperator `gorm:"type:varchar(10);not null;default:and" json:"operator"`
+	Expressions []string          `gorm:"type:text;not null;serializer:json" json:"expressions"`
+	Action      RuleGroupAction   `gorm:"type:varchar(10);not null" json:"action"`
+	// StorageLocationID is the target of assign groups; UnassignedLocationID leaves matching cards unassigned
+	StorageLocationID *uint  `gorm:"index" json:"storage_location_id,omitempty"`
+	Tag               string `gorm:"type:varchar(100)" json:"tag,omitempty"` // Set on tag groups
+	Enabled           bool   `gorm:"default:true;not null" json:"enabled"`
+
+	// Relationship
+	StorageLocation *StorageLocation `gorm:"foreignKey:StorageLocationID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"storage_location,omitempty"`
+}
+
+// ValidateRuleGroup checks the group has a name, expressions, and what its action needs
+func (g *RuleGroup) ValidateRuleGroup(tx *gorm.DB) error {
+	if g.Name == "" {
+		return errors.New("rule group name cannot be empty")
+	}
+	if g.Operator != RuleGroupAnd && g.Operator != RuleGroupOr {
+		return errors.New("rule group operator must be and or or")
+	}
+	if len(g.Expressions) == 0 {
+		return errors.New("rule group needs at least one expression")
+	}
+	for _, expression := range g.Expressions {
+		if expression == "" {
+			return errors.New("rule group expressions cannot be empty")
+		}
+	}
+	switch g.Action {
+	case RuleGroupAssign:
+		if g.StorageLocationID == nil {
+			return errors.New("assign rule groups need a storage location")
+		}
+	case RuleGroupTag:
+		if g.Tag == "" {
+			return errors.New("tag rule groups need a tag")
+		}
+	case RuleGroupSkip:
+	default:
+		return errors.New("rule group action must be assign, tag, or skip")
+	}
+	return nil
+}
+
+// AfterFind fills in the virtual location for groups that leave cards unassigned,
+// which have no storage location row to preload
+func (g *RuleGroup) AfterFind(tx *gorm.DB) error {
+	if g.StorageLocationID != nil && *g.StorageLocationID == UnassignedLocationID {
+		location := UnassignedLocation()
+		g.StorageLocation = &location
+	}
+	return nil
+}
+
+// BeforeCreate validates the group before creating a record
+func (g *RuleGroup) BeforeCreate(tx *gorm.DB) error {
+	return g.ValidateRuleGroup(tx)
+}
+
+// BeforeUpdate validates the group before updating a record
+func (g *RuleGroup) BeforeUpdate(tx *gorm.DB) error {
+	return g.ValidateRuleGroup(tx)
+}
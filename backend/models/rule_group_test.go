@@ -0,0 +1,140 @@
+package models
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRuleGroupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&StorageLocation{}, &RuleGroup{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestRuleGroup_ValidateRuleGroup(t *testing.T) {
+	db := setupRuleGroupTestDB(t)
+	location := uint(1)
+
+	tests := []struct {
+		name     string
+		group    RuleGroup
+		errorMsg string
+	}{
+		{
+			name:  "Valid assign group",
+			group: RuleGroup{Name: "Old rares", Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: RuleGroupAssign, StorageLocationID: &location},
+		},
+		{
+			name:  "Valid tag group",
+			group: RuleGroup{Name: "Bulk", Operator: RuleGroupOr, Expressions: []string{"true", "false"}, Action: RuleGroupTag, Tag: "bulk"},
+		},
+		{
+			name:  "Valid skip group",
+			group: RuleGroup{Name: "Tokens", Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: RuleGroupSkip},
+		},
+		{
+			name:     "Empty name",
+			group:    RuleGroup{Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: RuleGroupSkip},
+			errorMsg: "rule group name cannot be empty",
+		},
+		{
+			name:     "Unknown operator",
+			group:    RuleGroup{Name: "Group", Operator: "xor", Expressions: []string{"true"}, Action: RuleGroupSkip},
+			errorMsg: "rule group operator must be and or or",
+		},
+		{
+			name:     "No expressions",
+			group:    RuleGroup{Name: "Group", Operator: RuleGroupAnd, Action: RuleGroupSkip},
+			errorMsg: "rule group needs at least one expression",
+		},
+		{
+			name:     "Empty expression",
+			group:    RuleGroup{Name: "Group", Operator: RuleGroupAnd, Expressions: []string{"true", ""}, Action: RuleGroupSkip},
+			errorMsg: "rule group expressions cannot be empty",
+		},
+		{
+			name:     "Assign without location",
+			group:    RuleGroup{Name: "Group", Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: RuleGroupAssign},
+			errorMsg: "assign rule groups need a storage location",
+		},
+		{
+			name:     "Tag without tag",
+			group:    RuleGroup{Name: "Group", Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: RuleGroupTag},
+			errorMsg: "tag rule groups need a tag",
+		},
+		{
+			name:     "Unknown action",
+			group:    RuleGroup{Name: "Group", Operator: RuleGroupAnd, Expressions: []string{"true"}, Action: "move"},
+			errorMsg: "rule group action must be assign, tag, or skip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.ValidateRuleGroup(db)
+			if tt.errorMsg == "" && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.errorMsg != "" && (err == nil || err.Error() != tt.errorMsg) {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestRuleGroup_StoresExpressions(t *testing.T) {
+	db := setupRuleGroupTestDB(t)
+
+	group := RuleGroup{
+		Name:        "Old rares",
+		Operator:    RuleGroupAnd,
+		Expressions: []string{`rarity == "rare"`, `set in ["lea", "leb"]`},
+		Action:      RuleGroupSkip,
+		Enabled:     true,
+	}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	var stored RuleGroup
+	if err := db.First(&stored, group.ID).Error; err != nil {
+		t.Fatalf("failed to fetch group: %v", err)
+	}
+	if !slices.Equal(stored.Expressions, group.Expressions) {
+		t.Errorf("expected expressions %v, got %v", group.Expressions, stored.Expressions)
+	}
+}
+
+func TestRuleGroup_AfterFind_UnassignedLocation(t *testing.T) {
+	db := setupRuleGroupTestDB(t)
+
+	unassigned := UnassignedLocationID
+	group := RuleGroup{
+		Name:              "Leave unsorted",
+		Operator:          RuleGroupAnd,
+		Expressions:       []string{"true"},
+		Action:            RuleGroupAssign,
+		StorageLocationID: &unassigned,
+		Enabled:           true,
+	}
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("failed to create group: %v", err)
+	}
+
+	var stored RuleGroup
+	if err := db.Preload("StorageLocation").First(&stored, group.ID).Error; err != nil {
+		t.Fatalf("failed to fetch group: %v", err)
+	}
+	if stored.StorageLocation == nil || stored.StorageLocation.Name != UnassignedLocationName {
+		t.Errorf("expected the virtual unassigned location, got %+v", stored.StorageLocation)
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// RulePerformance is how long a sorting rule took to evaluate during the most recent resort.
+// The table is replaced on each resort, so UpdatedAt says when the figures were taken.
+// tygo:export
+type RulePerformance struct {
+	BaseModel
+	SortingRuleID uint  `gorm:"not null;uniqueIndex" json:"sorting_rule_id"`
+	Evaluations   int64 `gorm:"not null;default:0" json:"evaluations"`  // Cards the rule was evaluated against
+	TotalMicros   int64 `gorm:"not null;default:0" json:"total_micros"` // Time spent across all evaluations
+	MaxMicros     int64 `gorm:"not null;default:0" json:"max_micros"`   // Slowest single evaluation
+}
+
+func (rp *RulePerformance) ValidateRulePerformance(tx *gorm.DB) error {
+	if rp.SortingRuleID == 0 {
+		return errors.New("sorting_rule_id is required")
+	}
+	if rp.Evaluations < 0 || rp.TotalMicros < 0 || rp.MaxMicros < 0 {
+		return errors.New("performance figures cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the rule performance before creating a record
+func (rp *RulePerformance) BeforeCreate(tx *gorm.DB) error {
+	return rp.ValidateRulePerformance(tx)
+}
+
+// BeforeUpdate validates the rule performance before updating a record
+func (rp *RulePerformance) BeforeUpdate(tx *gorm.DB) error {
+	return rp.ValidateRulePerformance(tx)
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRulePerformanceTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&RulePerformance{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestRulePerformance_ValidateRulePerformance(t *testing.T) {
+	db := setupRulePerformanceTestDB(t)
+
+	tests := []struct {
+		name        string
+		performance *RulePerformance
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Performance",
+			performance: &RulePerformance{SortingRuleID: 1, Evaluations: 100, TotalMicros: 5000, MaxMicros: 200},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Missing Rule",
+			performance: &RulePerformance{Evaluations: 1},
+			expectError: true,
+			errorMsg:    "sorting_rule_id is required",
+		},
+		{
+			name:        "Invalid - Negative Time",
+			performance: &RulePerformance{SortingRuleID: 1, TotalMicros: -1},
+			expectError: true,
+			errorMsg:    "performance figures cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.performance.ValidateRulePerformance(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRulePerformance_UniquePerRule(t *testing.T) {
+	db := setupRulePerformanceTestDB(t)
+
+	if err := db.Create(&RulePerformance{SortingRuleID: 1, Evaluations: 1}).Error; err != nil {
+		t.Fatalf("failed to create performance record: %v", err)
+	}
+	if err := db.Create(&RulePerformance{SortingRuleID: 1, Evaluations: 2}).Error; err == nil {
+		t.Error("expected a second record for the same rule to be rejected")
+	}
+}
@@ -11,6 +11,10 @@ type Set struct {
 	Digital       bool    `gorm:"type:boolean" json:"digital"`
 	IconFilename  string  `gorm:"type:varchar(255)" json:"icon_filename"`
 	ParentSetCode string  `gorm:"type:varchar(10)" json:"parent_set_code"`
+	StandardLegal bool    `gorm:"index;not null;default:false" json:"standard_legal"`
+	// Preview marks an upcoming set whose cards are not all in bulk data yet;
+	// its spoiled cards are fetched individually on demand
+	Preview bool `gorm:"index;not null;default:false" json:"preview"`
 }
 
 func (Set) TableName() string {
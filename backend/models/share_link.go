@@ -0,0 +1,76 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShareLinkTarget identifies what a share link exposes
+// tygo:export
+type ShareLinkTarget string
+
+const (
+	ShareLinkTargetList            ShareLinkTarget = "list"
+	ShareLinkTargetStorageLocation ShareLinkTarget = "storage_location"
+)
+
+// ShareLink is a revocable token giving a read-only view of one list or storage
+// location, e.g. a wishlist or trade binder posted to friends. Exactly one of
+// ListID and StorageLocationID is set.
+// tygo:export
+type ShareLink struct {
+	BaseModel
+	Token             string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token"`
+	Label             string     `gorm:"type:varchar(100)" json:"label,omitempty"`
+	ListID            *uint      `gorm:"index" json:"list_id,omitempty"`
+	StorageLocationID *uint      `gorm:"index" json:"storage_location_id,omitempty"`
+	RevokedAt         *time.Time `json:"revoked_at,omitempty"`
+
+	// Relationships
+	List            *List            `gorm:"foreignKey:ListID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"list,omitempty"`
+	StorageLocation *StorageLocation `gorm:"foreignKey:StorageLocationID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"storage_location,omitempty"`
+}
+
+func (sl *ShareLink) ValidateShareLink(tx *gorm.DB) error {
+	if sl.Token == "" {
+		return errors.New("token cannot be empty")
+	}
+	if len(sl.Label) > 100 {
+		return errors.New("label cannot exceed 100 characters")
+	}
+	if (sl.ListID == nil) == (sl.StorageLocationID == nil) {
+		return errors.New("exactly one of list_id and storage_location_id must be set")
+	}
+	if sl.ListID != nil && *sl.ListID == 0 {
+		return errors.New("list_id must be set")
+	}
+	if sl.StorageLocationID != nil && *sl.StorageLocationID == 0 {
+		return errors.New("storage_location_id must be set")
+	}
+	return nil
+}
+
+// BeforeCreate validates the share link before creating a record
+func (sl *ShareLink) BeforeCreate(tx *gorm.DB) error {
+	return sl.ValidateShareLink(tx)
+}
+
+// BeforeUpdate validates the share link before updating a record
+func (sl *ShareLink) BeforeUpdate(tx *gorm.DB) error {
+	return sl.ValidateShareLink(tx)
+}
+
+// Target reports whether the link exposes a list or a storage location
+func (sl *ShareLink) Target() ShareLinkTarget {
+	if sl.ListID != nil {
+		return ShareLinkTargetList
+	}
+	return ShareLinkTargetStorageLocation
+}
+
+// IsActive reports whether the link can still be used
+func (sl *ShareLink) IsActive() bool {
+	return sl.RevokedAt == nil
+}
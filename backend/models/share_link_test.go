@@ -0,0 +1,93 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShareLink_ValidateShareLink(t *testing.T) {
+	db := setupListTestDB(t)
+	listID, locationID, zero := uint(1), uint(2), uint(0)
+
+	tests := []struct {
+		name        string
+		link        *ShareLink
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name:        "Valid List Link",
+			link:        &ShareLink{Token: "abc", ListID: &listID},
+			expectError: false,
+		},
+		{
+			name:        "Valid Storage Location Link",
+			link:        &ShareLink{Token: "abc", Label: "Trade binder", StorageLocationID: &locationID},
+			expectError: false,
+		},
+		{
+			name:        "Invalid - Empty Token",
+			link:        &ShareLink{ListID: &listID},
+			expectError: true,
+			errorMsg:    "token cannot be empty",
+		},
+		{
+			name:        "Invalid - Long Label",
+			link:        &ShareLink{Token: "abc", Label: strings.Repeat("a", 101), ListID: &listID},
+			expectError: true,
+			errorMsg:    "label cannot exceed 100 characters",
+		},
+		{
+			name:        "Invalid - No Target",
+			link:        &ShareLink{Token: "abc"},
+			expectError: true,
+			errorMsg:    "exactly one of list_id and storage_location_id must be set",
+		},
+		{
+			name:        "Invalid - Both Targets",
+			link:        &ShareLink{Token: "abc", ListID: &listID, StorageLocationID: &locationID},
+			expectError: true,
+			errorMsg:    "exactly one of list_id and storage_location_id must be set",
+		},
+		{
+			name:        "Invalid - Zero ListID",
+			link:        &ShareLink{Token: "abc", ListID: &zero},
+			expectError: true,
+			errorMsg:    "list_id must be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.link.ValidateShareLink(db)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error %q, got %q", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestShareLink_TargetAndIsActive(t *testing.T) {
+	id := uint(1)
+	now := time.Now()
+
+	if target := (&ShareLink{ListID: &id}).Target(); target != ShareLinkTargetList {
+		t.Errorf("expected list target, got %s", target)
+	}
+	if target := (&ShareLink{StorageLocationID: &id}).Target(); target != ShareLinkTargetStorageLocation {
+		t.Errorf("expected storage location target, got %s", target)
+	}
+	if !(&ShareLink{}).IsActive() {
+		t.Error("expected link without revoked_at to be active")
+	}
+	if (&ShareLink{RevokedAt: &now}).IsActive() {
+		t.Error("expected revoked link to be inactive")
+	}
+}
@@ -11,9 +11,9 @@ import (
 type SortingRule struct {
 	BaseModel
 	Name              string `gorm:"type:varchar(255);not null" json:"name"`
-	Priority          int    `gorm:"not null;index" json:"priority"`
+	Priority          int    `gorm:"not null;uniqueIndex" json:"priority"`
 	Expression        string `gorm:"type:text;not null" json:"expression"`
-	StorageLocationID uint   `gorm:"not null;index" json:"storage_location_id"`
+	StorageLocationID uint   `gorm:"not null;index" json:"storage_location_id"` // UnassignedLocationID leaves matching cards unassigned
 	Enabled           bool   `gorm:"default:true;not null" json:"enabled"`
 
 	// Relationship
@@ -27,8 +27,14 @@ func (r *SortingRule) ValidateSortingRule(tx *gorm.DB) error {
 	if r.Expression == "" {
 		return errors.New("rule expression cannot be empty")
 	}
-	if r.StorageLocationID == 0 {
-		return errors.New("storage location ID must be set")
+	return nil
+}
+
+// AfterFind fills in the virtual location for rules that leave cards unassigned,
+// which have no storage location row to preload
+func (r *SortingRule) AfterFind(tx *gorm.DB) error {
+	if r.StorageLocationID == UnassignedLocationID {
+		r.StorageLocation = UnassignedLocation()
 	}
 	return nil
 }
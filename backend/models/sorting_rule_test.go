@@ -75,16 +75,15 @@ func TestSortingRule_ValidateSortingRule(t *testing.T) {
 			errorMsg:    "rule expression cannot be empty",
 		},
 		{
-			name: "Invalid - Zero StorageLocationID",
+			name: "Valid - Unassigned StorageLocationID",
 			rule: &SortingRule{
 				Name:              "Test Rule",
 				Priority:          1,
 				Expression:        "true",
-				StorageLocationID: 0,
+				StorageLocationID: UnassignedLocationID,
 				Enabled:           true,
 			},
-			expectError: true,
-			errorMsg:    "storage location ID must be set",
+			expectError: false,
 		},
 	}
 
@@ -229,12 +228,12 @@ func TestSortingRule_BeforeUpdate(t *testing.T) {
 		},
 	}
 
-	for _, tt := range tests {
+	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a fresh rule for each test
+			// Create a fresh rule for each test, with a priority of its own
 			testRule := &SortingRule{
 				Name:              "Original Name",
-				Priority:          1,
+				Priority:          i + 1,
 				Expression:        "true",
 				StorageLocationID: storage.ID,
 				Enabled:           true,
@@ -299,6 +298,23 @@ func TestSortingRule_WithStorageLocation(t *testing.T) {
 	}
 }
 
+func TestSortingRule_UnassignedTarget(t *testing.T) {
+	db := setupSortingRuleTestDB(t)
+
+	rule := &SortingRule{Name: "Bulk", Priority: 1, Expression: "true", StorageLocationID: UnassignedLocationID}
+	if err := db.Create(rule).Error; err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+
+	var loadedRule SortingRule
+	if err := db.Preload("StorageLocation").First(&loadedRule, rule.ID).Error; err != nil {
+		t.Fatalf("failed to load rule: %v", err)
+	}
+	if loadedRule.StorageLocation.ID != UnassignedLocationID || loadedRule.StorageLocation.Name != UnassignedLocationName {
+		t.Errorf("expected the virtual unassigned location, got %+v", loadedRule.StorageLocation)
+	}
+}
+
 func TestSortingRule_DefaultEnabled(t *testing.T) {
 	db := setupSortingRuleTestDB(t)
 
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 
 	"gorm.io/gorm"
 )
@@ -25,11 +26,44 @@ func (s StorageType) IsValid() bool {
 	}
 }
 
+// UnassignedLocationID is the virtual storage location holding inventory that has
+// no location. APIs accept it wherever a location ID is expected (filters, move
+// targets, rule targets); inventory rows store it as a NULL storage_location_id.
+const UnassignedLocationID uint = 0
+
+// UnassignedLocationName is the display name of the virtual unassigned location
+const UnassignedLocationName = "Unassigned"
+
+// UnassignedLocation returns the virtual unassigned location. It has no capacity limit.
+func UnassignedLocation() StorageLocation {
+	return StorageLocation{BaseModel: BaseModel{ID: UnassignedLocationID}, Name: UnassignedLocationName}
+}
+
+// StoredLocationID converts a location ID from an API request to its stored form,
+// mapping the virtual unassigned location to nil
+func StoredLocationID(id *uint) *uint {
+	if id == nil || *id == UnassignedLocationID {
+		return nil
+	}
+	return id
+}
+
+// MaxPhysicalDescriptionLength caps the freeform description of a storage location
+const MaxPhysicalDescriptionLength = 2000
+
 // tygo:export
 type StorageLocation struct {
 	BaseModel
 	Name        string      `gorm:"type:varchar(255);not null" json:"name"`
 	StorageType StorageType `gorm:"type:varchar(50);not null;check:storage_type IN ('Box', 'Binder')" json:"storage_type"`
+	// Capacity is the number of cards the location holds; 0 means unlimited
+	Capacity int `gorm:"not null;default:0" json:"capacity"`
+	// PhysicalDescription says where the location actually is (e.g. "top shelf, blue box")
+	PhysicalDescription string `gorm:"type:text" json:"physical_description,omitempty"`
+	// PhotoFilename is the uploaded photo's file name within the data directory's storage-photos folder
+	PhotoFilename string `gorm:"type:varchar(255)" json:"photo_filename,omitempty"`
+	// ParentID nests the location inside another (e.g. a section inside a box on a shelf); nil for top-level locations
+	ParentID *uint `gorm:"index" json:"parent_id,omitempty"`
 }
 
 func (s *StorageLocation) ValidateStorageLocation(tx *gorm.DB) error {
@@ -39,6 +73,15 @@ func (s *StorageLocation) ValidateStorageLocation(tx *gorm.DB) error {
 	if !s.StorageType.IsValid() {
 		return errors.New("invalid storage type")
 	}
+	if s.Capacity < 0 {
+		return errors.New("capacity cannot be negative")
+	}
+	if len(s.PhysicalDescription) > MaxPhysicalDescriptionLength {
+		return fmt.Errorf("physical_description cannot exceed %d characters", MaxPhysicalDescriptionLength)
+	}
+	if s.ParentID != nil && s.ID != 0 && *s.ParentID == s.ID {
+		return errors.New("a storage location cannot be its own parent")
+	}
 	return nil
 }
 
@@ -51,3 +94,20 @@ func (s *StorageLocation) BeforeCreate(tx *gorm.DB) error {
 func (s *StorageLocation) BeforeUpdate(tx *gorm.DB) error {
 	return s.ValidateStorageLocation(tx)
 }
+
+// StorageLocationDescendantIDs returns the IDs of every location nested under id,
+// at any depth, not including id itself
+func StorageLocationDescendantIDs(db *gorm.DB, id uint) ([]uint, error) {
+	var ids []uint
+	// UNION (not UNION ALL) stops the recursion if the data ever contains a cycle
+	if err := db.Raw(`
+		WITH RECURSIVE descendants(id) AS (
+			SELECT id FROM storage_locations WHERE parent_id = ?
+			UNION
+			SELECT sl.id FROM storage_locations sl JOIN descendants d ON sl.parent_id = d.id
+		)
+		SELECT id FROM descendants ORDER BY id`, id).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("fetching descendant locations: %w", err)
+	}
+	return ids, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 
 	"gorm.io/driver/sqlite"
@@ -42,6 +43,20 @@ func TestStorageType_IsValid(t *testing.T) {
 	}
 }
 
+func TestStoredLocationID(t *testing.T) {
+	unassigned, box := UnassignedLocationID, uint(4)
+
+	if StoredLocationID(nil) != nil {
+		t.Error("expected nil to stay nil")
+	}
+	if StoredLocationID(&unassigned) != nil {
+		t.Error("expected the unassigned location to be stored as nil")
+	}
+	if got := StoredLocationID(&box); got == nil || *got != box {
+		t.Errorf("expected location %d, got %v", box, got)
+	}
+}
+
 func TestStorageLocation_ValidateStorageLocation(t *testing.T) {
 	db := setupStorageTestDB(t)
 
@@ -73,6 +88,23 @@ func TestStorageLocation_ValidateStorageLocation(t *testing.T) {
 			expectError: true,
 			errorMsg:    "invalid storage type",
 		},
+		{
+			name:        "Valid Capacity",
+			storage:     &StorageLocation{Name: "Bulk Box", StorageType: Box, Capacity: 800},
+			expectError: false,
+		},
+		{
+			name:        "Negative Capacity",
+			storage:     &StorageLocation{Name: "Bulk Box", StorageType: Box, Capacity: -1},
+			expectError: true,
+			errorMsg:    "capacity cannot be negative",
+		},
+		{
+			name:        "Physical Description Too Long",
+			storage:     &StorageLocation{Name: "Bulk Box", StorageType: Box, PhysicalDescription: strings.Repeat("a", MaxPhysicalDescriptionLength+1)},
+			expectError: true,
+			errorMsg:    "physical_description cannot exceed 2000 characters",
+		},
 		{
 			name:        "Both Invalid",
 			storage:     &StorageLocation{Name: "", StorageType: StorageType("Invalid")},
@@ -326,3 +358,61 @@ func TestStorageLocation_UniqueNames(t *testing.T) {
 		t.Errorf("expected 2 storage locations with duplicate name, found %d", count)
 	}
 }
+
+func TestStorageLocation_OwnParent(t *testing.T) {
+	db := setupStorageTestDB(t)
+
+	location := StorageLocation{Name: "Box", StorageType: Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+
+	location.ParentID = &location.ID
+	if err := db.Save(&location).Error; err == nil {
+		t.Error("expected an error when a location is its own parent")
+	}
+}
+
+func TestStorageLocationDescendantIDs(t *testing.T) {
+	db := setupStorageTestDB(t)
+
+	create := func(name string, parentID *uint) StorageLocation {
+		t.Helper()
+		location := StorageLocation{Name: name, StorageType: Box, ParentID: parentID}
+		if err := db.Create(&location).Error; err != nil {
+			t.Fatalf("failed to create location: %v", err)
+		}
+		return location
+	}
+
+	shelf := create("Shelf", nil)
+	box := create("Box", &shelf.ID)
+	section := create("Section", &box.ID)
+	create("Other", nil)
+
+	ids, err := StorageLocationDescendantIDs(db, shelf.ID)
+	if err != nil {
+		t.Fatalf("StorageLocationDescendantIDs failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != box.ID || ids[1] != section.ID {
+		t.Errorf("expected [%d %d], got %v", box.ID, section.ID, ids)
+	}
+
+	ids, err = StorageLocationDescendantIDs(db, section.ID)
+	if err != nil {
+		t.Fatalf("StorageLocationDescendantIDs failed: %v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("expected no descendants for a leaf, got %v", ids)
+	}
+
+	// A cycle written directly to the database must not recurse forever
+	db.Model(&StorageLocation{}).Where("id = ?", shelf.ID).UpdateColumn("parent_id", section.ID)
+	ids, err = StorageLocationDescendantIDs(db, shelf.ID)
+	if err != nil {
+		t.Fatalf("StorageLocationDescendantIDs failed: %v", err)
+	}
+	if len(ids) != 3 {
+		t.Errorf("expected 3 ids including shelf itself through the cycle, got %v", ids)
+	}
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxTagNameLength caps the name of a tag
+const MaxTagNameLength = 100
+
+// tagNamePattern keeps tag names usable both in the comma-separated tag filter and
+// inside rule expressions as hasTag('name')
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Tag is a user-defined label for grouping inventory items independently of where
+// they are stored (e.g. "commander-staple", "for-trade")
+// tygo:export
+type Tag struct {
+	BaseModel
+	Name string `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+
+	// ItemCount is the number of inventory items carrying the tag (computed, not stored)
+	ItemCount int64 `gorm:"-" json:"item_count"`
+}
+
+// NormalizeTagName trims a tag name and lower-cases it, so names differing only in
+// case refer to the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func (t *Tag) ValidateTag(tx *gorm.DB) error {
+	if t.Name == "" {
+		return errors.New("tag name cannot be empty")
+	}
+	if len(t.Name) > MaxTagNameLength {
+		return errors.New("tag name cannot exceed 100 characters")
+	}
+	if !tagNamePattern.MatchString(t.Name) {
+		return errors.New("tag name must start with a lowercase letter or digit and contain only lowercase letters, digits, hyphens, and underscores")
+	}
+	return nil
+}
+
+// BeforeCreate validates the tag before creating a record
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	return t.ValidateTag(tx)
+}
+
+// BeforeUpdate validates the tag before updating a record
+func (t *Tag) BeforeUpdate(tx *gorm.DB) error {
+	return t.ValidateTag(tx)
+}
+
+// InventoryTag attaches a tag to an inventory item
+// tygo:export
+type InventoryTag struct {
+	BaseModel
+	InventoryID uint `gorm:"not null;uniqueIndex:idx_inventory_tag" json:"inventory_id"`
+	TagID       uint `gorm:"not null;uniqueIndex:idx_inventory_tag;index" json:"tag_id"`
+
+	// Relationships
+	Inventory *Inventory `gorm:"foreignKey:InventoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Tag       *Tag       `gorm:"foreignKey:TagID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"tag,omitempty"`
+}
+
+func (it *InventoryTag) ValidateInventoryTag(tx *gorm.DB) error {
+	if it.InventoryID == 0 {
+		return errors.New("inventory_id cannot be empty")
+	}
+	if it.TagID == 0 {
+		return errors.New("tag_id cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the inventory tag before creating a record
+func (it *InventoryTag) BeforeCreate(tx *gorm.DB) error {
+	return it.ValidateInventoryTag(tx)
+}
+
+// BeforeUpdate validates the inventory tag before updating a record
+func (it *InventoryTag) BeforeUpdate(tx *gorm.DB) error {
+	return it.ValidateInventoryTag(tx)
+}
+
+// GetInventoryTagNames returns the names of the tags on each of the given inventory
+// IDs, in name order. Inventory items without tags are omitted.
+func GetInventoryTagNames(db *gorm.DB, inventoryIDs []uint) (map[uint][]string, error) {
+	result := make(map[uint][]string)
+	if len(inventoryIDs) == 0 {
+		return result, nil
+	}
+
+	type row struct {
+		InventoryID uint
+		Name        string
+	}
+	var rows []row
+	if err := db.Table("inventory_tags").
+		Select("inventory_tags.inventory_id AS inventory_id, tags.name AS name").
+		Joins("JOIN tags ON tags.id = inventory_tags.tag_id").
+		Where("inventory_tags.inventory_id IN ?", inventoryIDs).
+		Order("tags.name").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		result[r.InventoryID] = append(result[r.InventoryID], r.Name)
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTagTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Inventory{}, &Tag{}, &InventoryTag{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestTag_ValidateTag(t *testing.T) {
+	db := setupTagTestDB(t)
+
+	tests := []struct {
+		name     string
+		tag      *Tag
+		errorMsg string
+	}{
+		{"Valid Tag", &Tag{Name: "commander-staple"}, ""},
+		{"Valid With Digits And Underscore", &Tag{Name: "2024_trade"}, ""},
+		{"Invalid - Empty", &Tag{}, "tag name cannot be empty"},
+		{"Invalid - Too Long", &Tag{Name: strings.Repeat("a", MaxTagNameLength+1)}, "tag name cannot exceed 100 characters"},
+		{"Invalid - Uppercase", &Tag{Name: "Staple"}, "tag name must start with a lowercase letter or digit and contain only lowercase letters, digits, hyphens, and underscores"},
+		{"Invalid - Comma", &Tag{Name: "a,b"}, "tag name must start with a lowercase letter or digit and contain only lowercase letters, digits, hyphens, and underscores"},
+		{"Invalid - Leading Hyphen", &Tag{Name: "-trade"}, "tag name must start with a lowercase letter or digit and contain only lowercase letters, digits, hyphens, and underscores"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.tag).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeTagName(t *testing.T) {
+	if got := NormalizeTagName("  Commander-Staple "); got != "commander-staple" {
+		t.Errorf("expected commander-staple, got %q", got)
+	}
+}
+
+func TestInventoryTag_ValidateInventoryTag(t *testing.T) {
+	db := setupTagTestDB(t)
+
+	if err := db.Create(&InventoryTag{TagID: 1}).Error; err == nil || err.Error() != "inventory_id cannot be empty" {
+		t.Errorf("expected a missing inventory_id error, got %v", err)
+	}
+	if err := db.Create(&InventoryTag{InventoryID: 1}).Error; err == nil || err.Error() != "tag_id cannot be empty" {
+		t.Errorf("expected a missing tag_id error, got %v", err)
+	}
+}
+
+func TestGetInventoryTagNames(t *testing.T) {
+	db := setupTagTestDB(t)
+
+	trade := Tag{Name: "trade"}
+	staple := Tag{Name: "commander-staple"}
+	db.Create(&trade)
+	db.Create(&staple)
+	db.Create(&InventoryTag{InventoryID: 1, TagID: trade.ID})
+	db.Create(&InventoryTag{InventoryID: 1, TagID: staple.ID})
+	db.Create(&InventoryTag{InventoryID: 2, TagID: trade.ID})
+
+	names, err := GetInventoryTagNames(db, []uint{1, 2, 3})
+	if err != nil {
+		t.Fatalf("GetInventoryTagNames failed: %v", err)
+	}
+	if !slices.Equal(names[1], []string{"commander-staple", "trade"}) {
+		t.Errorf("expected item 1's tags in name order, got %v", names[1])
+	}
+	if !slices.Equal(names[2], []string{"trade"}) {
+		t.Errorf("expected item 2 tagged trade, got %v", names[2])
+	}
+	if _, ok := names[3]; ok {
+		t.Errorf("expected untagged items to be omitted, got %v", names[3])
+	}
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxCounterpartyLength caps the name recorded for the other side of a transaction
+const MaxCounterpartyLength = 255
+
+// TransactionType is how cards left the collection
+type TransactionType string
+
+const (
+	TransactionSale  TransactionType = "sale"
+	TransactionTrade TransactionType = "trade"
+)
+
+// Valid checks if the transaction type is valid
+func (t TransactionType) Valid() bool {
+	switch t {
+	case TransactionSale, TransactionTrade:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transaction records cards sold or traded away. It is the financial record of where
+// the cards went, kept after the inventory rows they came from are gone.
+// tygo:export
+type Transaction struct {
+	BaseModel
+	Type         TransactionType `gorm:"type:varchar(20);not null;index" json:"type"`
+	Counterparty string          `gorm:"type:varchar(255);index" json:"counterparty"`
+	Date         time.Time       `gorm:"not null;index" json:"date"`
+	// Currency of the item prices; the preferred currency when the transaction was recorded
+	Currency Currency `gorm:"type:varchar(3);not null" json:"currency"`
+	Notes    string   `gorm:"type:text" json:"notes"`
+
+	// Relationship
+	Items []TransactionItem `gorm:"foreignKey:TransactionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+func (t *Transaction) ValidateTransaction(tx *gorm.DB) error {
+	if !t.Type.Valid() {
+		return fmt.Errorf("invalid type %q", t.Type)
+	}
+	if len(t.Counterparty) > MaxCounterpartyLength {
+		return fmt.Errorf("counterparty cannot exceed %d characters", MaxCounterpartyLength)
+	}
+	if t.Date.IsZero() {
+		return errors.New("date must be set")
+	}
+	if t.Currency == "" {
+		return errors.New("currency cannot be empty")
+	}
+	return nil
+}
+
+// BeforeCreate validates the transaction before creating a record
+func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
+	return t.ValidateTransaction(tx)
+}
+
+// BeforeUpdate validates the transaction before updating a record
+func (t *Transaction) BeforeUpdate(tx *gorm.DB) error {
+	return t.ValidateTransaction(tx)
+}
+
+// TransactionItem records copies of one inventory row that left in a transaction.
+// InventoryID has no foreign key, and the printing and acquisition price are copied
+// from the row, so the record outlives the row itself.
+// tygo:export
+type TransactionItem struct {
+	BaseModel
+	TransactionID     uint    `gorm:"not null;index" json:"transaction_id"`
+	InventoryID       uint    `gorm:"not null;index" json:"inventory_id"`
+	ScryfallID        string  `gorm:"type:varchar(255);not null;index" json:"scryfall_id"`
+	OracleID          string  `gorm:"type:varchar(255);not null" json:"oracle_id"`
+	Treatment         string  `gorm:"type:varchar(100)" json:"treatment"`
+	Quantity          int     `gorm:"not null;default:1" json:"quantity"`
+	StorageLocationID *uint   `json:"storage_location_id,omitempty"`        // Where the copies were kept
+	UnitPrice         float64 `gorm:"not null;default:0" json:"unit_price"` // Sale price, or agreed trade value, per copy
+	// AcquiredPrice is what was paid per copy, when the inventory row recorded it
+	AcquiredPrice *float64 `json:"acquired_price,omitempty"`
+}
+
+func (ti *TransactionItem) ValidateTransactionItem(tx *gorm.DB) error {
+	if ti.InventoryID == 0 {
+		return errors.New("inventory_id must be set")
+	}
+	if ti.ScryfallID == "" {
+		return errors.New("scryfall_id cannot be empty")
+	}
+	if ti.OracleID == "" {
+		return errors.New("oracle_id cannot be empty")
+	}
+	if ti.Quantity < 1 {
+		return errors.New("quantity must be at least 1")
+	}
+	if ti.UnitPrice < 0 {
+		return errors.New("unit_price cannot be negative")
+	}
+	return nil
+}
+
+// BeforeCreate validates the transaction item before creating a record
+func (ti *TransactionItem) BeforeCreate(tx *gorm.DB) error {
+	return ti.ValidateTransactionItem(tx)
+}
+
+// BeforeUpdate validates the transaction item before updating a record
+func (ti *TransactionItem) BeforeUpdate(tx *gorm.DB) error {
+	return ti.ValidateTransactionItem(tx)
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTransactionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Transaction{}, &TransactionItem{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+func TestTransactionType_Valid(t *testing.T) {
+	for _, transactionType := range []TransactionType{TransactionSale, TransactionTrade} {
+		if !transactionType.Valid() {
+			t.Errorf("expected %q to be valid", transactionType)
+		}
+	}
+	if TransactionType("gift").Valid() {
+		t.Error("expected gift to be invalid")
+	}
+}
+
+func TestTransaction_ValidateTransaction(t *testing.T) {
+	db := setupTransactionTestDB(t)
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		transaction *Transaction
+		errorMsg    string
+	}{
+		{
+			name:        "Valid Sale",
+			transaction: &Transaction{Type: TransactionSale, Date: now, Currency: CurrencyUSD},
+		},
+		{
+			name:        "Invalid - Type",
+			transaction: &Transaction{Type: "gift", Date: now, Currency: CurrencyUSD},
+			errorMsg:    `invalid type "gift"`,
+		},
+		{
+			name:        "Invalid - Counterparty Too Long",
+			transaction: &Transaction{Type: TransactionTrade, Counterparty: strings.Repeat("a", MaxCounterpartyLength+1), Date: now, Currency: CurrencyUSD},
+			errorMsg:    "counterparty cannot exceed 255 characters",
+		},
+		{
+			name:        "Invalid - No Date",
+			transaction: &Transaction{Type: TransactionSale, Currency: CurrencyUSD},
+			errorMsg:    "date must be set",
+		},
+		{
+			name:        "Invalid - No Currency",
+			transaction: &Transaction{Type: TransactionSale, Date: now},
+			errorMsg:    "currency cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.transaction.ValidateTransaction(db)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			} else if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestTransactionItem_ValidateTransactionItem(t *testing.T) {
+	db := setupTransactionTestDB(t)
+
+	valid := TransactionItem{TransactionID: 1, InventoryID: 2, ScryfallID: "card", OracleID: "oracle", Quantity: 1, UnitPrice: 1.5}
+	if err := valid.ValidateTransactionItem(db); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	invalid := valid
+	invalid.Quantity = 0
+	if err := invalid.ValidateTransactionItem(db); err == nil {
+		t.Error("expected error for zero quantity")
+	}
+
+	invalid = valid
+	invalid.UnitPrice = -1
+	if err := invalid.ValidateTransactionItem(db); err == nil {
+		t.Error("expected error for negative unit price")
+	}
+
+	invalid = valid
+	invalid.InventoryID = 0
+	if err := invalid.ValidateTransactionItem(db); err == nil {
+		t.Error("expected error for missing inventory_id")
+	}
+}
+
+func TestTransaction_CascadeDeletesItems(t *testing.T) {
+	db := setupTransactionTestDB(t)
+
+	transaction := Transaction{
+		Type:     TransactionSale,
+		Date:     time.Now(),
+		Currency: CurrencyUSD,
+		Items:    []TransactionItem{{InventoryID: 1, ScryfallID: "card", OracleID: "oracle", Quantity: 2, UnitPrice: 3}},
+	}
+	if err := db.Create(&transaction).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if transaction.Items[0].TransactionID != transaction.ID {
+		t.Errorf("expected item to belong to transaction %d, got %d", transaction.ID, transaction.Items[0].TransactionID)
+	}
+}
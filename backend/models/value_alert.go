@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxValueAlertWindowDays caps how far back a price change alert looks, matching how
+// long price snapshots are kept
+const MaxValueAlertWindowDays = 90
+
+// DefaultValueAlertWindowDays is the window of a price change alert when none is given
+const DefaultValueAlertWindowDays = 7
+
+// ValueAlertCondition is what a value alert watches for
+// tygo:export
+type ValueAlertCondition string
+
+const (
+	// ValueAlertCardPriceChange fires when an owned printing's price moved by at least
+	// Threshold percent over WindowDays: a gain for a positive threshold, a drop for a
+	// negative one
+	ValueAlertCardPriceChange ValueAlertCondition = "card_price_change"
+	// ValueAlertCollectionValueAbove fires when the collection value rises to Threshold or more
+	ValueAlertCollectionValueAbove ValueAlertCondition = "collection_value_above"
+	// ValueAlertCollectionValueBelow fires when the collection value falls below Threshold
+	ValueAlertCollectionValueBelow ValueAlertCondition = "collection_value_below"
+)
+
+// Valid checks if the condition is one of the known conditions
+func (c ValueAlertCondition) Valid() bool {
+	switch c {
+	case ValueAlertCardPriceChange, ValueAlertCollectionValueAbove, ValueAlertCollectionValueBelow:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCollectionValue reports whether the condition watches the total collection value
+func (c ValueAlertCondition) IsCollectionValue() bool {
+	return c == ValueAlertCollectionValueAbove || c == ValueAlertCollectionValueBelow
+}
+
+// ValueAlert is a user-defined price or collection value condition, checked by the
+// value_alert_check scheduler task. Each time it fires it raises a value_alert
+// notification and, when WebhookURL is set, posts the notification there.
+// tygo:export
+type ValueAlert struct {
+	BaseModel
+	Name       string              `gorm:"type:varchar(255);not null" json:"name"`
+	Condition  ValueAlertCondition `gorm:"type:varchar(30);not null" json:"condition"`
+	Threshold  float64             `gorm:"not null" json:"threshold"`   // Percent for card_price_change, amount in preferred_currency otherwise
+	WindowDays int                 `gorm:"not null" json:"window_days"` // Days a price change is measured over (card_price_change only)
+	Enabled    bool                `gorm:"not null" json:"enabled"`
+	WebhookURL string              `gorm:"type:varchar(2048)" json:"webhook_url,omitempty"`
+
+	LastValue       *float64   `json:"last_value,omitempty"`        // Collection value at the last check (collection value conditions)
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"` // When the alert last fired
+}
+
+func (a *ValueAlert) ValidateValueAlert(tx *gorm.DB) error {
+	if a.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	if !a.Condition.Valid() {
+		return errors.New("condition must be one of: card_price_change, collection_value_above, collection_value_below")
+	}
+
+	if a.Condition == ValueAlertCardPriceChange {
+		if a.Threshold == 0 {
+			return errors.New("threshold must be a non-zero percentage")
+		}
+		if a.WindowDays < 1 || a.WindowDays > MaxValueAlertWindowDays {
+			return fmt.Errorf("window_days must be between 1 and %d", MaxValueAlertWindowDays)
+		}
+	} else if a.Threshold <= 0 {
+		return errors.New("threshold must be greater than 0")
+	}
+
+	if a.WebhookURL != "" {
+		u, err := url.Parse(a.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhook_url must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// BeforeCreate validates the alert before creating a record
+func (a *ValueAlert) BeforeCreate(tx *gorm.DB) error {
+	return a.ValidateValueAlert(tx)
+}
+
+// BeforeUpdate validates the alert before updating a record
+func (a *ValueAlert) BeforeUpdate(tx *gorm.DB) error {
+	return a.ValidateValueAlert(tx)
+}
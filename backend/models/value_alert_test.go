@@ -0,0 +1,49 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestValueAlert_ValidateValueAlert(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&ValueAlert{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		alert    *ValueAlert
+		errorMsg string
+	}{
+		{"Valid Price Gain", &ValueAlert{Name: "Spikes", Condition: ValueAlertCardPriceChange, Threshold: 20, WindowDays: 7}, ""},
+		{"Valid Price Drop", &ValueAlert{Name: "Crashes", Condition: ValueAlertCardPriceChange, Threshold: -30, WindowDays: 30}, ""},
+		{"Valid Value Above With Webhook", &ValueAlert{Name: "Milestone", Condition: ValueAlertCollectionValueAbove, Threshold: 1000, WebhookURL: "https://example.com/hook"}, ""},
+		{"Invalid - Empty Name", &ValueAlert{Condition: ValueAlertCollectionValueBelow, Threshold: 10}, "name cannot be empty"},
+		{"Invalid - Unknown Condition", &ValueAlert{Name: "X", Condition: "card_rarity", Threshold: 10}, "condition must be one of: card_price_change, collection_value_above, collection_value_below"},
+		{"Invalid - Zero Percentage", &ValueAlert{Name: "X", Condition: ValueAlertCardPriceChange, WindowDays: 7}, "threshold must be a non-zero percentage"},
+		{"Invalid - Window Too Long", &ValueAlert{Name: "X", Condition: ValueAlertCardPriceChange, Threshold: 20, WindowDays: 91}, "window_days must be between 1 and 90"},
+		{"Invalid - Negative Value Threshold", &ValueAlert{Name: "X", Condition: ValueAlertCollectionValueAbove, Threshold: -5}, "threshold must be greater than 0"},
+		{"Invalid - Webhook Scheme", &ValueAlert{Name: "X", Condition: ValueAlertCollectionValueAbove, Threshold: 5, WebhookURL: "ftp://example.com"}, "webhook_url must be an http or https URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.alert).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEvent is a kind of event a webhook can subscribe to
+// tygo:export
+type WebhookEvent string
+
+const (
+	WebhookEventJobCompleted        WebhookEvent = "job.completed"
+	WebhookEventJobFailed           WebhookEvent = "job.failed"
+	WebhookEventBulkImportCompleted WebhookEvent = "bulk_import.completed"
+	WebhookEventBulkImportFailed    WebhookEvent = "bulk_import.failed"
+	WebhookEventInventoryBulkChange WebhookEvent = "inventory.bulk_change"
+	// WebhookEventPing is only sent by POST /webhooks/:id/test and can't be subscribed to
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+// Valid checks if the event is one a webhook can subscribe to
+func (e WebhookEvent) Valid() bool {
+	switch e {
+	case WebhookEventJobCompleted, WebhookEventJobFailed, WebhookEventBulkImportCompleted,
+		WebhookEventBulkImportFailed, WebhookEventInventoryBulkChange:
+		return true
+	default:
+		return false
+	}
+}
+
+// Webhook is a URL that receives signed POSTs when subscribed events happen. When
+// Secret is set each body is signed with HMAC-SHA256 in the X-ShowMyCards-Signature
+// header.
+// tygo:export
+type Webhook struct {
+	BaseModel
+	Name    string         `gorm:"type:varchar(255);not null" json:"name"`
+	URL     string         `gorm:"type:varchar(2048);not null" json:"url"`
+	Secret  string         `gorm:"type:varchar(255)" json:"-"` // Write-only
+	Events  []WebhookEvent `gorm:"type:text;not null;serializer:json" json:"events"`
+	Enabled bool           `gorm:"not null" json:"enabled"`
+
+	HasSecret bool `gorm:"-" json:"has_secret"` // Whether deliveries are signed
+}
+
+func (w *Webhook) ValidateWebhook(tx *gorm.DB) error {
+	if w.Name == "" {
+		return errors.New("name cannot be empty")
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an http or https URL")
+	}
+	if len(w.Events) == 0 {
+		return errors.New("events cannot be empty")
+	}
+	for _, event := range w.Events {
+		if !event.Valid() {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+// Subscribed reports whether the webhook wants event
+func (w *Webhook) Subscribed(event WebhookEvent) bool {
+	return slices.Contains(w.Events, event)
+}
+
+// BeforeCreate validates the webhook before creating a record
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	return w.ValidateWebhook(tx)
+}
+
+// BeforeUpdate validates the webhook before updating a record
+func (w *Webhook) BeforeUpdate(tx *gorm.DB) error {
+	return w.ValidateWebhook(tx)
+}
+
+// AfterFind fills in HasSecret
+func (w *Webhook) AfterFind(tx *gorm.DB) error {
+	w.HasSecret = w.Secret != ""
+	return nil
+}
+
+// AfterSave fills in HasSecret
+func (w *Webhook) AfterSave(tx *gorm.DB) error {
+	w.HasSecret = w.Secret != ""
+	return nil
+}
+
+// WebhookDeliveryStatus is the outcome of a webhook delivery
+// tygo:export
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending" // Still being attempted
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Every attempt failed
+)
+
+// WebhookDelivery is one event sent to a webhook, with the outcome of its last attempt
+// tygo:export
+type WebhookDelivery struct {
+	BaseModel
+	WebhookID   uint                  `gorm:"not null;index" json:"webhook_id"`
+	Event       WebhookEvent          `gorm:"type:varchar(50);not null" json:"event"`
+	Payload     string                `gorm:"type:text;not null" json:"payload"` // JSON body as sent
+	Status      WebhookDeliveryStatus `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts    int                   `gorm:"not null;default:0" json:"attempts"`
+	StatusCode  int                   `json:"status_code,omitempty"` // HTTP status of the last attempt, if it got a response
+	Error       string                `gorm:"type:text" json:"error,omitempty"`
+	DeliveredAt *time.Time            `json:"delivered_at,omitempty"`
+}
+
+func (d *WebhookDelivery) ValidateWebhookDelivery(tx *gorm.DB) error {
+	if d.WebhookID == 0 {
+		return errors.New("webhook_id cannot be empty")
+	}
+	if d.Event == "" {
+		return errors.New("event cannot be empty")
+	}
+	switch d.Status {
+	case WebhookDeliveryPending, WebhookDeliveryDelivered, WebhookDeliveryFailed:
+	default:
+		return errors.New("invalid delivery status")
+	}
+	return nil
+}
+
+// BeforeCreate validates the delivery before creating a record
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	return d.ValidateWebhookDelivery(tx)
+}
+
+// BeforeUpdate validates the delivery before updating a record
+func (d *WebhookDelivery) BeforeUpdate(tx *gorm.DB) error {
+	return d.ValidateWebhookDelivery(tx)
+}
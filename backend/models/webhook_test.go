@@ -0,0 +1,73 @@
+package models
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestWebhook_ValidateWebhook(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Webhook{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	jobEvents := []WebhookEvent{WebhookEventJobCompleted, WebhookEventJobFailed}
+	tests := []struct {
+		name     string
+		webhook  *Webhook
+		errorMsg string
+	}{
+		{"Valid", &Webhook{Name: "Home", URL: "http://homeassistant.local:8123/api/webhook/cards", Events: jobEvents}, ""},
+		{"Invalid - Empty Name", &Webhook{URL: "https://example.com", Events: jobEvents}, "name cannot be empty"},
+		{"Invalid - Relative URL", &Webhook{Name: "X", URL: "/hook", Events: jobEvents}, "url must be an http or https URL"},
+		{"Invalid - No Events", &Webhook{Name: "X", URL: "https://example.com"}, "events cannot be empty"},
+		{"Invalid - Ping Event", &Webhook{Name: "X", URL: "https://example.com", Events: []WebhookEvent{WebhookEventPing}}, `unknown event "ping"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Create(tt.webhook).Error
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error %q, got %v", tt.errorMsg, err)
+			}
+		})
+	}
+}
+
+func TestWebhook_HasSecret(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&Webhook{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	webhook := Webhook{Name: "Signed", URL: "https://example.com", Secret: "s3cret", Events: []WebhookEvent{WebhookEventBulkImportFailed}}
+	if err := db.Create(&webhook).Error; err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	if !webhook.HasSecret {
+		t.Error("expected has_secret after create")
+	}
+
+	var loaded Webhook
+	db.First(&loaded, webhook.ID)
+	if !loaded.HasSecret || loaded.Secret != "s3cret" {
+		t.Errorf("expected the secret loaded and flagged, got %+v", loaded)
+	}
+	if !loaded.Subscribed(WebhookEventBulkImportFailed) || loaded.Subscribed(WebhookEventJobFailed) {
+		t.Errorf("unexpected subscriptions %v", loaded.Events)
+	}
+}
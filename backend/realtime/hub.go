@@ -0,0 +1,252 @@
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Event types pushed to clients
+const (
+	EventInventoryCreated = "inventory.created"
+	EventInventoryUpdated = "inventory.updated"
+	EventInventoryDeleted = "inventory.deleted"
+	EventResortCompleted  = "inventory.resorted"
+	EventJobUpdated       = "job.updated"
+)
+
+const (
+	// clientBufferSize is how many events may queue for a client before it is dropped
+	clientBufferSize = 64
+
+	// writeTimeout bounds each frame write so a stalled client cannot block its writer
+	writeTimeout = 10 * time.Second
+
+	// pingInterval is how often clients are pinged; they must answer within pongTimeout
+	pingInterval = 30 * time.Second
+	pongTimeout  = 2 * pingInterval
+)
+
+// Event is a change notification sent to every connected client
+// tygo:export
+type Event struct {
+	Type string    `json:"type"`
+	Data any       `json:"data,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// InventoryChange identifies the inventory rows an event refers to
+// tygo:export
+type InventoryChange struct {
+	IDs []uint `json:"ids"`
+}
+
+// ResortChange summarises a completed resort
+// tygo:export
+type ResortChange struct {
+	Processed int `json:"processed"`
+	Updated   int `json:"updated"`
+}
+
+// JobChange is the new status of a background job
+// tygo:export
+type JobChange struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+}
+
+// client is one connected WebSocket
+type client struct {
+	conn net.Conn
+	send chan []byte
+	once sync.Once
+}
+
+// close stops the client's writer; the connection itself is closed when its handler returns
+func (c *client) close() {
+	c.once.Do(func() { close(c.send) })
+}
+
+// Hub broadcasts change events to connected WebSocket clients. A nil *Hub is valid
+// and drops everything, so services and handlers can publish unconditionally.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*client]struct{}
+	closed  bool
+}
+
+// NewHub creates a hub with no clients
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*client]struct{})}
+}
+
+// Publish sends an event to every client. Clients too slow to keep up are disconnected
+// rather than allowed to hold up the caller; they reconnect and refetch.
+func (h *Hub) Publish(eventType string, data any) {
+	if h == nil {
+		return
+	}
+
+	message, err := json.Marshal(Event{Type: eventType, Data: data, At: time.Now()})
+	if err != nil {
+		slog.Warn("failed to encode realtime event", "component", "realtime", "type", eventType, "error", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		select {
+		case c.send <- message:
+		default:
+			slog.Warn("dropping slow realtime client", "component", "realtime", "remote", c.conn.RemoteAddr())
+			delete(h.clients, c)
+			c.close()
+		}
+	}
+}
+
+// ClientCount returns the number of connected clients
+func (h *Hub) ClientCount() int {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Close disconnects every client and refuses new ones
+func (h *Hub) Close() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		delete(h.clients, c)
+		c.close()
+	}
+}
+
+// Handler upgrades the request to a WebSocket and streams events until the client leaves
+func (h *Hub) Handler(c fiber.Ctx) error {
+	if !headerContainsToken(c.Get(fiber.HeaderConnection), "upgrade") ||
+		!headerContainsToken(c.Get(fiber.HeaderUpgrade), "websocket") {
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{"error": "websocket upgrade required"})
+	}
+	if c.Get("Sec-WebSocket-Version") != "13" {
+		c.Set("Sec-WebSocket-Version", "13")
+		return c.Status(fiber.StatusUpgradeRequired).JSON(fiber.Map{"error": "unsupported websocket version"})
+	}
+	key := c.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "missing Sec-WebSocket-Key"})
+	}
+
+	c.Status(fiber.StatusSwitchingProtocols)
+	c.Set(fiber.HeaderUpgrade, "websocket")
+	c.Set(fiber.HeaderConnection, "Upgrade")
+	c.Set("Sec-WebSocket-Accept", acceptKey(key))
+	c.RequestCtx().Hijack(func(conn net.Conn) {
+		h.serve(conn)
+	})
+	return nil
+}
+
+// serve registers the connection and runs its reader until it disconnects. fasthttp
+// closes the connection once this returns.
+func (h *Hub) serve(conn net.Conn) {
+	cl := &client{conn: conn, send: make(chan []byte, clientBufferSize)}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.clients[cl] = struct{}{}
+	h.mu.Unlock()
+
+	var writeMu sync.Mutex
+	write := func(opcode byte, payload []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+			return err
+		}
+		return writeFrame(conn, opcode, payload)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.writeLoop(cl, write)
+		close(done)
+		// Unblock the reader so serve can return
+		_ = conn.SetReadDeadline(time.Now())
+	}()
+
+	h.readLoop(conn, write, done)
+
+	h.mu.Lock()
+	delete(h.clients, cl)
+	h.mu.Unlock()
+	cl.close()
+	<-done
+}
+
+// writeLoop sends queued events and periodic pings until the client is closed or a write fails
+func (h *Hub) writeLoop(cl *client, write func(byte, []byte) error) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-cl.send:
+			if !ok {
+				_ = write(opClose, nil)
+				return
+			}
+			if err := write(opText, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := write(opPing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLoop answers pings and watches for the client closing or going silent. Messages
+// from the client carry no meaning on this push-only channel and are ignored.
+func (h *Hub) readLoop(conn net.Conn, write func(byte, []byte) error, done <-chan struct{}) {
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(pongTimeout)); err != nil {
+			return
+		}
+		// Checked after extending the deadline so the writer's final deadline always wins
+		select {
+		case <-done:
+			return
+		default:
+		}
+		opcode, payload, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case opPing:
+			if err := write(opPong, payload); err != nil {
+				return
+			}
+		case opClose:
+			_ = write(opClose, payload)
+			return
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// startHubServer serves hub on a real listener, since hijacking needs a network connection
+func startHubServer(t *testing.T, hub *Hub) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	app := fiber.New()
+	app.Get("/ws", hub.Handler)
+	go app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+	t.Cleanup(func() {
+		hub.Close()
+		app.Shutdown()
+	})
+	return ln.Addr().String()
+}
+
+// dialHub performs the WebSocket handshake and returns the connection and its reader
+func dialHub(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: %s\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n", addr)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read handshake: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected status 101, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected accept key %q", got)
+	}
+	return conn, reader
+}
+
+// waitForClients waits until the hub has registered n clients
+func waitForClients(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for hub.ClientCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d clients, have %d", n, hub.ClientCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHub_BroadcastsToEveryClient(t *testing.T) {
+	hub := NewHub()
+	addr := startHubServer(t, hub)
+
+	_, first := dialHub(t, addr)
+	_, second := dialHub(t, addr)
+	waitForClients(t, hub, 2)
+
+	hub.Publish(EventInventoryUpdated, InventoryChange{IDs: []uint{7}})
+
+	for i, reader := range []*bufio.Reader{first, second} {
+		opcode, payload, err := readServerFrame(reader)
+		if err != nil || opcode != opText {
+			t.Fatalf("client %d: expected text frame, got opcode %d, err %v", i, opcode, err)
+		}
+		var event struct {
+			Type string          `json:"type"`
+			Data InventoryChange `json:"data"`
+		}
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("client %d: invalid event %s: %v", i, payload, err)
+		}
+		if event.Type != EventInventoryUpdated || len(event.Data.IDs) != 1 || event.Data.IDs[0] != 7 {
+			t.Errorf("client %d: unexpected event %s", i, payload)
+		}
+	}
+}
+
+func TestHub_AnswersPingAndClose(t *testing.T) {
+	hub := NewHub()
+	addr := startHubServer(t, hub)
+
+	conn, reader := dialHub(t, addr)
+	waitForClients(t, hub, 1)
+
+	if err := writeClientFrame(conn, opPing, []byte("hi")); err != nil {
+		t.Fatalf("failed to send ping: %v", err)
+	}
+	opcode, payload, err := readServerFrame(reader)
+	if err != nil || opcode != opPong || string(payload) != "hi" {
+		t.Fatalf("expected pong echoing the ping, got opcode %d %q, err %v", opcode, payload, err)
+	}
+
+	if err := writeClientFrame(conn, opClose, nil); err != nil {
+		t.Fatalf("failed to send close: %v", err)
+	}
+	if opcode, _, err := readServerFrame(reader); err != nil || opcode != opClose {
+		t.Fatalf("expected close reply, got opcode %d, err %v", opcode, err)
+	}
+	waitForClients(t, hub, 0)
+}
+
+func TestHub_CloseDisconnectsClients(t *testing.T) {
+	hub := NewHub()
+	addr := startHubServer(t, hub)
+
+	_, reader := dialHub(t, addr)
+	waitForClients(t, hub, 1)
+
+	hub.Close()
+	if opcode, _, err := readServerFrame(reader); err != nil || opcode != opClose {
+		t.Fatalf("expected close frame, got opcode %d, err %v", opcode, err)
+	}
+	if hub.ClientCount() != 0 {
+		t.Errorf("expected no clients after close, got %d", hub.ClientCount())
+	}
+}
+
+func TestHub_RequiresUpgrade(t *testing.T) {
+	app := fiber.New()
+	app.Get("/ws", NewHub().Handler)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected int
+	}{
+		{"Plain GET", nil, fiber.StatusUpgradeRequired},
+		{"Old version", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "8", "Sec-WebSocket-Key": "x"}, fiber.StatusUpgradeRequired},
+		{"Missing key", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Sec-WebSocket-Version": "13"}, fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/ws", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHub_NilIsNoOp(t *testing.T) {
+	var hub *Hub
+	hub.Publish(EventJobUpdated, JobChange{ID: 1, Status: "completed"})
+	hub.Close()
+	if hub.ClientCount() != 0 {
+		t.Error("expected nil hub to have no clients")
+	}
+}
+
+func TestEvent_JSON(t *testing.T) {
+	data, err := json.Marshal(Event{Type: EventJobUpdated, Data: JobChange{ID: 3, Status: "failed"}})
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"type":"job.updated","data":{"id":3,"status":"failed"}`) {
+		t.Errorf("unexpected encoding %s", data)
+	}
+}
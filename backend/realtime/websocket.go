@@ -0,0 +1,111 @@
+package realtime
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// websocketGUID is appended to the client key when computing Sec-WebSocket-Accept (RFC 6455 §1.3)
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxClientFrameSize bounds frames read from clients; the channel is push-only, so
+// clients only send control frames and the occasional keep-alive message
+const maxClientFrameSize = 64 * 1024
+
+// Frame opcodes (RFC 6455 §5.2)
+const (
+	opText  byte = 0x1
+	opClose byte = 0x8
+	opPing  byte = 0x9
+	opPong  byte = 0xA
+)
+
+// errFrameTooLarge is returned when a client frame exceeds maxClientFrameSize
+var errFrameTooLarge = errors.New("websocket frame too large")
+
+// acceptKey computes the Sec-WebSocket-Accept header for a client's Sec-WebSocket-Key
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// headerContainsToken reports whether a comma-separated header contains token, ignoring case
+func headerContainsToken(header, token string) bool {
+	for part := range strings.SplitSeq(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFrame writes a single unfragmented, unmasked frame, as servers must send
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode // FIN
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// readFrame reads one frame from a client and returns its opcode and unmasked payload
+func readFrame(r io.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	if !masked {
+		return 0, nil, fmt.Errorf("client frame is not masked")
+	}
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientFrameSize {
+		return 0, nil, errFrameTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,131 @@
+package realtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// writeClientFrame writes a masked frame, as browsers send
+func writeClientFrame(w io.Writer, opcode byte, payload []byte) error {
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	header := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, 0x80|byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 0x80|126)
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header = append(header, 0x80|127)
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	header = append(header, mask[:]...)
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	_, err := w.Write(append(header, masked...))
+	return err
+}
+
+// readServerFrame reads an unmasked frame, as the server sends
+func readServerFrame(r io.Reader) (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	_, err := io.ReadFull(r, payload)
+	return header[0] & 0x0F, payload, err
+}
+
+func TestAcceptKey(t *testing.T) {
+	// Example handshake from RFC 6455 §1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key %q", got)
+	}
+}
+
+func TestHeaderContainsToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		want   bool
+	}{
+		{"Upgrade", "upgrade", true},
+		{"keep-alive, Upgrade", "upgrade", true},
+		{"keep-alive", "upgrade", false},
+		{"", "upgrade", false},
+	}
+	for _, tt := range tests {
+		if got := headerContainsToken(tt.header, tt.token); got != tt.want {
+			t.Errorf("headerContainsToken(%q, %q) = %v, want %v", tt.header, tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFrame_Lengths(t *testing.T) {
+	for _, size := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+		payload := bytes.Repeat([]byte("x"), size)
+		var buf bytes.Buffer
+		if err := writeFrame(&buf, opText, payload); err != nil {
+			t.Fatalf("size %d: write failed: %v", size, err)
+		}
+		if buf.Bytes()[0] != 0x81 {
+			t.Errorf("size %d: expected FIN text frame, got %#x", size, buf.Bytes()[0])
+		}
+		if buf.Bytes()[1]&0x80 != 0 {
+			t.Errorf("size %d: server frames must not be masked", size)
+		}
+		opcode, got, err := readServerFrame(&buf)
+		if err != nil || opcode != opText || len(got) != size {
+			t.Errorf("size %d: round trip gave opcode %d, %d bytes, err %v", size, opcode, len(got), err)
+		}
+	}
+}
+
+func TestReadFrame_Unmasks(t *testing.T) {
+	for _, size := range []int{5, 200, 70000} {
+		payload := bytes.Repeat([]byte("hello"), size/5)
+		var buf bytes.Buffer
+		if err := writeClientFrame(&buf, opPing, payload); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+		opcode, got, err := readFrame(&buf)
+		if size > maxClientFrameSize {
+			if !errors.Is(err, errFrameTooLarge) {
+				t.Errorf("size %d: expected frame too large, got %v", size, err)
+			}
+			continue
+		}
+		if err != nil || opcode != opPing || !bytes.Equal(got, payload) {
+			t.Errorf("size %d: got opcode %d, payload %q, err %v", size, opcode, got, err)
+		}
+	}
+}
+
+func TestReadFrame_RejectsUnmasked(t *testing.T) {
+	var buf bytes.Buffer
+	writeFrame(&buf, opText, []byte("hi"))
+	if _, _, err := readFrame(&buf); err == nil {
+		t.Error("expected unmasked client frame to be rejected")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	scryfall "github.com/BlueMonday/go-scryfall"
 )
@@ -21,6 +23,22 @@ func ScryfallCardToRuleData(card scryfall.Card, treatment string) (map[string]in
 	cardData["type_line"] = card.TypeLine
 	cardData["oracle_text"] = card.OracleText
 	cardData["mana_cost"] = card.ManaCost
+
+	// Double-faced cards keep their type lines and rules text on the faces
+	if len(card.CardFaces) > 0 {
+		typeLines := make([]string, 0, len(card.CardFaces))
+		oracleTexts := make([]string, 0, len(card.CardFaces))
+		for _, face := range card.CardFaces {
+			typeLines = append(typeLines, face.TypeLine)
+			oracleTexts = append(oracleTexts, getString(face.OracleText))
+		}
+		if card.TypeLine == "" {
+			cardData["type_line"] = strings.Join(typeLines, faceSeparator)
+		}
+		if card.OracleText == "" {
+			cardData["oracle_text"] = strings.Join(oracleTexts, faceSeparator)
+		}
+	}
 	cardData["cmc"] = card.CMC
 	cardData["layout"] = string(card.Layout)
 	cardData["promo"] = card.Promo
@@ -36,6 +54,10 @@ func ScryfallCardToRuleData(card scryfall.Card, treatment string) (map[string]in
 	cardData["border_color"] = string(card.BorderColor)
 	cardData["collector_number"] = card.CollectorNumber
 	cardData["artist"] = getString(card.Artist)
+	cardData["released_at"] = ""
+	if !card.ReleasedAt.IsZero() {
+		cardData["released_at"] = card.ReleasedAt.Format(time.DateOnly)
+	}
 
 	// Power/toughness (nullable strings)
 	cardData["power"] = getString(card.Power)
@@ -54,6 +76,22 @@ func ScryfallCardToRuleData(card scryfall.Card, treatment string) (map[string]in
 	cardData["keywords"] = card.Keywords
 	cardData["finishes"] = card.Finishes
 	cardData["promo_types"] = card.PromoTypes
+	frameEffects := make([]string, len(card.FrameEffects))
+	for i, effect := range card.FrameEffects {
+		frameEffects[i] = string(effect)
+	}
+	cardData["frame_effects"] = frameEffects
+
+	// Format legalities (e.g., legalities.standard == "legal")
+	cardData["legalities"] = map[string]interface{}{
+		"standard":  string(card.Legalities.Standard),
+		"pioneer":   string(card.Legalities.Pioneer),
+		"modern":    string(card.Legalities.Modern),
+		"legacy":    string(card.Legalities.Legacy),
+		"vintage":   string(card.Legalities.Vintage),
+		"pauper":    string(card.Legalities.Pauper),
+		"commander": string(card.Legalities.Commander),
+	}
 
 	// EDHREC rank (nullable int)
 	if card.EDHRECRank != nil {
@@ -72,12 +110,16 @@ func ScryfallCardToRuleData(card scryfall.Card, treatment string) (map[string]in
 	prices["tix"] = parsePriceString(card.Prices.Tix)
 	cardData["prices"] = prices
 
-	// Inventory-specific field
+	// Inventory-specific fields; callers evaluating a stored item fill in its notes
 	cardData["treatment"] = treatment
+	cardData["notes"] = ""
 
 	return cardData, nil
 }
 
+// faceSeparator joins the text of a card's faces, as Scryfall does in card names
+const faceSeparator = " // "
+
 // getString safely extracts a string from a pointer, returning empty string if nil
 func getString(s *string) string {
 	if s == nil {
@@ -118,6 +160,13 @@ func RawJSONToRuleData(rawJSON string, treatment string) (map[string]interface{}
 	cardData["oracle_text"] = getStringFromJSON(jsonData, "oracle_text")
 	cardData["mana_cost"] = getStringFromJSON(jsonData, "mana_cost")
 	cardData["cmc"] = getFloatFromJSON(jsonData, "cmc")
+
+	// Double-faced cards keep their type lines and rules text on the faces
+	for _, key := range []string{"type_line", "oracle_text"} {
+		if cardData[key] == "" {
+			cardData[key] = getFaceStringsFromJSON(jsonData, key)
+		}
+	}
 	cardData["layout"] = getStringFromJSON(jsonData, "layout")
 	cardData["promo"] = getBoolFromJSON(jsonData, "promo")
 	cardData["reprint"] = getBoolFromJSON(jsonData, "reprint")
@@ -131,6 +180,7 @@ func RawJSONToRuleData(rawJSON string, treatment string) (map[string]interface{}
 	cardData["frame"] = getStringFromJSON(jsonData, "frame")
 	cardData["border_color"] = getStringFromJSON(jsonData, "border_color")
 	cardData["collector_number"] = getStringFromJSON(jsonData, "collector_number")
+	cardData["released_at"] = getStringFromJSON(jsonData, "released_at")
 	cardData["artist"] = getStringFromJSON(jsonData, "artist")
 	cardData["power"] = getStringFromJSON(jsonData, "power")
 	cardData["toughness"] = getStringFromJSON(jsonData, "toughness")
@@ -142,6 +192,18 @@ func RawJSONToRuleData(rawJSON string, treatment string) (map[string]interface{}
 	cardData["keywords"] = getArrayFromJSON(jsonData, "keywords")
 	cardData["finishes"] = getArrayFromJSON(jsonData, "finishes")
 	cardData["promo_types"] = getArrayFromJSON(jsonData, "promo_types")
+	cardData["frame_effects"] = getArrayFromJSON(jsonData, "frame_effects")
+
+	// Format legalities
+	legalities := make(map[string]interface{})
+	if legalitiesData, ok := jsonData["legalities"].(map[string]interface{}); ok {
+		for format, value := range legalitiesData {
+			if str, ok := value.(string); ok {
+				legalities[format] = str
+			}
+		}
+	}
+	cardData["legalities"] = legalities
 
 	// EDHREC rank
 	cardData["edhrec_rank"] = getIntFromJSON(jsonData, "edhrec_rank")
@@ -158,8 +220,9 @@ func RawJSONToRuleData(rawJSON string, treatment string) (map[string]interface{}
 	}
 	cardData["prices"] = prices
 
-	// Inventory-specific field
+	// Inventory-specific fields; callers evaluating a stored item fill in its notes
 	cardData["treatment"] = treatment
+	cardData["notes"] = ""
 
 	return cardData, nil
 }
@@ -179,6 +242,22 @@ func getStringFromMap(data map[string]interface{}, key string) string {
 	return ""
 }
 
+// getFaceStringsFromJSON joins a string field across a card's faces, or returns
+// empty string for single-faced cards
+func getFaceStringsFromJSON(data map[string]interface{}, key string) string {
+	faces, ok := data["card_faces"].([]interface{})
+	if !ok {
+		return ""
+	}
+	values := make([]string, 0, len(faces))
+	for _, face := range faces {
+		if faceData, ok := face.(map[string]interface{}); ok {
+			values = append(values, getStringFromMap(faceData, key))
+		}
+	}
+	return strings.Join(values, faceSeparator)
+}
+
 func getBoolFromJSON(data map[string]interface{}, key string) bool {
 	if val, ok := data[key].(bool); ok {
 		return val
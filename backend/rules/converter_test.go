@@ -268,7 +268,8 @@ func TestRawJSONToRuleData_ArrayFields(t *testing.T) {
 		"color_identity": ["R", "G", "W", "U"],
 		"keywords": ["landfall"],
 		"finishes": ["nonfoil", "foil"],
-		"promo_types": ["boosterfun"]
+		"promo_types": ["boosterfun"],
+		"frame_effects": ["extendedart"]
 	}`
 
 	cardData, err := RawJSONToRuleData(rawJSON, "nonfoil")
@@ -295,6 +296,34 @@ func TestRawJSONToRuleData_ArrayFields(t *testing.T) {
 	if len(promoTypes) != 1 || promoTypes[0] != "boosterfun" {
 		t.Errorf("expected promo_types [boosterfun], got %v", promoTypes)
 	}
+
+	frameEffects := cardData["frame_effects"].([]interface{})
+	if len(frameEffects) != 1 || frameEffects[0] != "extendedart" {
+		t.Errorf("expected frame_effects [extendedart], got %v", frameEffects)
+	}
+}
+
+func TestRawJSONToRuleData_Legalities(t *testing.T) {
+	rawJSON := `{
+		"name": "Lightning Bolt",
+		"legalities": {"standard": "not_legal", "modern": "legal", "pauper": "legal"}
+	}`
+
+	cardData, err := RawJSONToRuleData(rawJSON, "nonfoil")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	legalities, ok := cardData["legalities"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected legalities to be map[string]interface{}, got %T", cardData["legalities"])
+	}
+	if legalities["standard"] != "not_legal" {
+		t.Errorf("expected legalities.standard 'not_legal', got %v", legalities["standard"])
+	}
+	if legalities["modern"] != "legal" {
+		t.Errorf("expected legalities.modern 'legal', got %v", legalities["modern"])
+	}
 }
 
 func TestScryfallCardToRuleData_FullCard(t *testing.T) {
@@ -424,6 +453,40 @@ func TestScryfallCardToRuleData_NilPointerFields(t *testing.T) {
 	}
 }
 
+func TestCardToRuleData_CardFaces(t *testing.T) {
+	frontText, backText := "Transform it.", "Flying"
+	card := scryfall.Card{
+		Name:     "Delver of Secrets // Insectile Aberration",
+		TypeLine: "Creature — Human Wizard // Creature — Human Insect",
+		CardFaces: []scryfall.CardFace{
+			{TypeLine: "Creature — Human Wizard", OracleText: &frontText},
+			{TypeLine: "Creature — Human Insect", OracleText: &backText},
+		},
+	}
+
+	cardData, err := ScryfallCardToRuleData(card, "nonfoil")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The card's own type line is kept; the faces fill in the missing rules text
+	if cardData["type_line"] != card.TypeLine {
+		t.Errorf("expected type_line %q, got %q", card.TypeLine, cardData["type_line"])
+	}
+	if cardData["oracle_text"] != "Transform it. // Flying" {
+		t.Errorf("expected joined oracle_text, got %q", cardData["oracle_text"])
+	}
+
+	cardData, err = RawJSONToRuleData(`{"name": "Reversible", "card_faces": [
+		{"type_line": "Legendary Creature", "oracle_text": "Haste"},
+		{"type_line": "Legendary Creature", "oracle_text": ""}]}`, "nonfoil")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cardData["type_line"] != "Legendary Creature // Legendary Creature" || cardData["oracle_text"] != "Haste // " {
+		t.Errorf("expected text joined from the faces, got %q and %q", cardData["type_line"], cardData["oracle_text"])
+	}
+}
+
 func TestScryfallCardToRuleData_PriceParsing(t *testing.T) {
 	card := scryfall.Card{
 		Name: "Test Card",
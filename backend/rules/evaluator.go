@@ -6,8 +6,13 @@ import (
 	"backend/models"
 	"context"
 	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +20,63 @@ import (
 // A new Evaluator should be created per request; it is not safe for concurrent use.
 type Evaluator struct {
 	db *gorm.DB
+
+	// predicates caches named predicate expressions by name; loaded on first use
+	predicates map[string]string
+
+	// standardSets caches the codes of sets currently legal in Standard; loaded on first use
+	standardSets map[string]bool
+
+	// groups caches the enabled rule groups in priority order; loaded on first use
+	groups []models.RuleGroup
+
+	// patterns caches the compiled regular expressions of nameMatches by pattern
+	patterns map[string]*regexp.Regexp
+
+	// timings accumulates evaluation time per rule ID; nil unless RecordTimings was called
+	timings map[uint]*RuleTiming
+}
+
+// RuleTiming is the time spent evaluating one rule across many cards
+type RuleTiming struct {
+	Evaluations int
+	Total       time.Duration
+	Max         time.Duration
+}
+
+// RecordTimings makes MatchingLocations track how long each rule takes to evaluate
+func (e *Evaluator) RecordTimings() {
+	e.timings = make(map[uint]*RuleTiming)
+}
+
+// Timings returns the evaluation time recorded per rule ID since RecordTimings was called
+func (e *Evaluator) Timings() map[uint]RuleTiming {
+	result := make(map[uint]RuleTiming, len(e.timings))
+	for id, timing := range e.timings {
+		result[id] = *timing
+	}
+	return result
+}
+
+// timeRule evaluates a rule's expression, recording its duration when timings are enabled
+func (e *Evaluator) timeRule(rule models.SortingRule, cardData map[string]interface{}) (bool, error) {
+	if e.timings == nil {
+		return e.evaluateExpression(rule.Expression, cardData)
+	}
+
+	start := time.Now()
+	matches, err := e.evaluateExpression(rule.Expression, cardData)
+	elapsed := time.Since(start)
+
+	timing, ok := e.timings[rule.ID]
+	if !ok {
+		timing = &RuleTiming{}
+		e.timings[rule.ID] = timing
+	}
+	timing.Evaluations++
+	timing.Total += elapsed
+	timing.Max = max(timing.Max, elapsed)
+	return matches, err
 }
 
 // NewEvaluator creates a new rule evaluator
@@ -38,20 +100,97 @@ func (e *Evaluator) EvaluateCard(ctx context.Context, cardData map[string]interf
 // EvaluateCardWithRules evaluates a card against the provided rules and returns the matching storage location.
 // Use this for batch operations to avoid re-fetching rules on every call.
 func (e *Evaluator) EvaluateCardWithRules(cardData map[string]interface{}, rules []models.SortingRule) (*models.StorageLocation, error) {
-	for _, rule := range rules {
-		matches, err := e.evaluateExpression(rule.Expression, cardData)
-		if err != nil {
+	for _, step := range e.steps(rules) {
+		matches, err := e.evaluateStep(step, cardData)
+		if err != nil || !matches {
 			continue
 		}
 
-		if matches {
-			return &rule.StorageLocation, nil
+		switch step.action() {
+		case models.RuleGroupTag:
+			cardData = withTag(cardData, step.group.Tag)
+		case models.RuleGroupSkip:
+			return nil, fmt.Errorf("card skipped by rule group %q", step.group.Name)
+		default:
+			return &step.rule.StorageLocation, nil
 		}
 	}
 
 	return nil, fmt.Errorf("no matching rule found for card")
 }
 
+// MatchingLocations returns the storage locations of every rule the card matches,
+// in rule priority order with duplicates removed. Used to spill over into
+// lower-priority locations when the first match is full. A matching rule that
+// targets the unassigned location ends the list with the virtual unassigned
+// location, since lower-priority rules no longer apply. Enabled rule groups take
+// part in the same order: assign groups match like rules, tag groups tag the card
+// for the rules after them, and a matching skip group ends the list.
+func (e *Evaluator) MatchingLocations(cardData map[string]interface{}, rules []models.SortingRule) []models.StorageLocation {
+	var locations []models.StorageLocation
+	seen := make(map[uint]bool)
+	for _, step := range e.steps(rules) {
+		if step.action() == models.RuleGroupAssign && seen[step.rule.StorageLocationID] {
+			continue
+		}
+		matches, err := e.evaluateStep(step, cardData)
+		if err != nil || !matches {
+			continue
+		}
+
+		switch step.action() {
+		case models.RuleGroupTag:
+			cardData = withTag(cardData, step.group.Tag)
+			continue
+		case models.RuleGroupSkip:
+			return locations
+		}
+		if step.rule.StorageLocationID == models.UnassignedLocationID {
+			return append(locations, models.UnassignedLocation())
+		}
+		seen[step.rule.StorageLocationID] = true
+		locations = append(locations, step.rule.StorageLocation)
+	}
+	return locations
+}
+
+// RuleTrace records how one rule or rule group evaluated against a card
+type RuleTrace struct {
+	Rule    models.SortingRule // For a group, its name, priority, combined expression, and target
+	Group   *models.RuleGroup  // Set when the trace is of a rule group
+	Matched bool
+	Err     error
+}
+
+// Assigns reports whether the trace matched and places the card in Rule's location,
+// as rules and assign groups do
+func (t RuleTrace) Assigns() bool {
+	return t.Matched && (t.Group == nil || t.Group.Action == models.RuleGroupAssign)
+}
+
+// TraceCard evaluates a card against every rule and enabled rule group in order,
+// without stopping at the first match, so callers can see why a card lands where it
+// does. Tag groups tag the card for later steps; the trace ends at a matching skip group.
+func (e *Evaluator) TraceCard(cardData map[string]interface{}, rules []models.SortingRule) []RuleTrace {
+	steps := e.steps(rules)
+	traces := make([]RuleTrace, 0, len(steps))
+	for _, step := range steps {
+		matches, err := e.evaluateStep(step, cardData)
+		trace := RuleTrace{Rule: step.rule, Group: step.group, Matched: err == nil && matches, Err: err}
+		traces = append(traces, trace)
+		if !trace.Matched {
+			continue
+		}
+		switch step.action() {
+		case models.RuleGroupTag:
+			cardData = withTag(cardData, step.group.Tag)
+		case models.RuleGroupSkip:
+			return traces
+		}
+	}
+	return traces
+}
+
 // EvaluateExpression evaluates a single expression against card data
 func (e *Evaluator) EvaluateExpression(expression string, cardData map[string]interface{}) (bool, error) {
 	return e.evaluateExpression(expression, cardData)
@@ -63,12 +202,22 @@ func (e *Evaluator) evaluateExpression(expression string, cardData map[string]in
 		return false, fmt.Errorf("expression cannot be empty")
 	}
 
-	// Add helper functions to the environment
-	env := make(map[string]interface{})
-	for k, v := range cardData {
-		env[k] = v
+	expression, err := e.expandPredicates(expression)
+	if err != nil {
+		return false, err
+	}
+
+	if strings.Contains(expression, "isStandardLegal") {
+		if err := e.loadStandardSets(); err != nil {
+			return false, err
+		}
 	}
 
+	// Start from the field defaults so absent fields read as empty rather than undefined
+	env := make(map[string]interface{}, len(cardFieldDefaults)+len(cardData))
+	maps.Copy(env, cardFieldDefaults)
+	maps.Copy(env, cardData)
+
 	// Add helper functions
 	env["hasColor"] = func(color string) bool {
 		return hasColor(cardData, color)
@@ -85,9 +234,53 @@ func (e *Evaluator) evaluateExpression(expression string, cardData map[string]in
 	env["isColor"] = func(colors ...string) bool {
 		return isColor(cardData, colors...)
 	}
+	env["isSerialized"] = func() bool {
+		return isSerialized(cardData)
+	}
+	env["isExtendedArt"] = func() bool {
+		return isExtendedArt(cardData)
+	}
+	env["isBorderless"] = func() bool {
+		return isBorderless(cardData)
+	}
+	env["isStandardLegal"] = func() bool {
+		return isStandardLegal(cardData, e.standardSets)
+	}
+	env["isBasicLand"] = func() bool {
+		return isBasicLand(cardData)
+	}
+	env["noteContains"] = func(text string) bool {
+		return noteContains(cardData, text)
+	}
+	env["nameMatches"] = func(pattern string) (bool, error) {
+		return e.nameMatches(cardData, pattern)
+	}
+	env["typeContains"] = func(text string) bool {
+		return textContains(cardData, "type_line", text)
+	}
+	env["oracleContains"] = func(text string) bool {
+		return textContains(cardData, "oracle_text", text)
+	}
+	env["priceUSD"] = func() float64 {
+		return priceUSD(cardData)
+	}
+	env["isReserveList"] = func() bool {
+		return isReserveList(cardData)
+	}
+	env["releasedBefore"] = func(date string) (bool, error) {
+		return releasedBefore(cardData, date)
+	}
+	env["ageYears"] = func() int {
+		return ageYears(cardData, time.Now())
+	}
+	env["hasTag"] = func(tag string) bool {
+		return hasTag(cardData, tag)
+	}
 
 	// Compile the expression
-	program, err := expr.Compile(expression, expr.Env(env), expr.AsBool())
+	// Names outside the environment evaluate to nil; validation still rejects them
+	program, err := expr.Compile(expression, expr.Env(env), expr.AsBool(),
+		expr.AllowUndefinedVariables(), expr.Patch(nilSafeComparisons{}))
 	if err != nil {
 		return false, fmt.Errorf("failed to compile expression: %w", err)
 	}
@@ -107,6 +300,54 @@ func (e *Evaluator) evaluateExpression(expression string, cardData map[string]in
 	return result, nil
 }
 
+// loadPredicates fetches all named predicates into the evaluator's cache
+func (e *Evaluator) loadPredicates() error {
+	if e.predicates != nil {
+		return nil
+	}
+
+	var predicates []models.NamedPredicate
+	if err := e.db.Find(&predicates).Error; err != nil {
+		return fmt.Errorf("failed to fetch named predicates: %w", err)
+	}
+
+	e.predicates = make(map[string]string, len(predicates))
+	for _, p := range predicates {
+		e.predicates[p.Name] = p.Expression
+	}
+	return nil
+}
+
+// loadStandardSets fetches the codes of sets currently flagged as Standard-legal
+func (e *Evaluator) loadStandardSets() error {
+	if e.standardSets != nil {
+		return nil
+	}
+
+	var codes []string
+	if err := e.db.Model(&models.Set{}).Where("standard_legal = ?", true).Pluck("code", &codes).Error; err != nil {
+		return fmt.Errorf("failed to fetch standard-legal sets: %w", err)
+	}
+
+	e.standardSets = make(map[string]bool, len(codes))
+	for _, code := range codes {
+		e.standardSets[code] = true
+	}
+	return nil
+}
+
+// expandPredicates substitutes predicate('name') calls with their definitions.
+// Expressions without predicate calls are returned unchanged without touching the database.
+func (e *Evaluator) expandPredicates(expression string) (string, error) {
+	if !strings.Contains(expression, "predicate(") {
+		return expression, nil
+	}
+	if err := e.loadPredicates(); err != nil {
+		return "", err
+	}
+	return ExpandPredicates(expression, e.predicates)
+}
+
 // Helper functions for rule expressions
 // These check color_identity which works correctly for both single-faced and double-faced cards
 
@@ -199,8 +440,310 @@ func isColor(cardData map[string]interface{}, targetColors ...string) bool {
 	return false
 }
 
+// isStandardLegal checks if a card is a printing from a current Standard set
+// and is itself legal (not banned) in Standard
+// Usage: isStandardLegal()
+func isStandardLegal(cardData map[string]interface{}, standardSets map[string]bool) bool {
+	legalities, ok := cardData["legalities"].(map[string]interface{})
+	if !ok || legalities["standard"] != "legal" {
+		return false
+	}
+	set, _ := cardData["set"].(string)
+	return standardSets[set]
+}
+
+// Print treatment helpers
+// These classify printings by promo type, frame effect, and border color
+
+// containsString checks if a card data array field contains the target value
+// Handles both decoded JSON arrays ([]interface{}) and typed string slices
+func containsString(value interface{}, target string) bool {
+	switch values := value.(type) {
+	case []interface{}:
+		for _, v := range values {
+			if str, ok := v.(string); ok && str == target {
+				return true
+			}
+		}
+	case []string:
+		for _, str := range values {
+			if str == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isSerialized checks if a card is a serial-numbered printing
+// Usage: isSerialized()
+func isSerialized(cardData map[string]interface{}) bool {
+	return containsString(cardData["promo_types"], "serialized")
+}
+
+// isExtendedArt checks if a card has the extended art frame effect
+// Usage: isExtendedArt()
+func isExtendedArt(cardData map[string]interface{}) bool {
+	return containsString(cardData["frame_effects"], "extendedart")
+}
+
+// isBorderless checks if a card is printed without a border
+// Usage: isBorderless()
+func isBorderless(cardData map[string]interface{}) bool {
+	return cardData["border_color"] == "borderless"
+}
+
+// isBasicLand checks if a card is a basic land, including snow-covered basics and Wastes
+// Usage: isBasicLand()
+func isBasicLand(cardData map[string]interface{}) bool {
+	typeLine, _ := cardData["type_line"].(string)
+	return models.IsBasicLand(typeLine)
+}
+
+// noteContains checks if an inventory item's notes contain text, ignoring case
+// Usage: noteContains("signed")
+func noteContains(cardData map[string]interface{}, text string) bool {
+	notes, ok := cardData["notes"].(string)
+	if !ok || text == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(notes), strings.ToLower(text))
+}
+
+// Card text helpers
+// Double-faced cards carry the type lines and rules text of all faces (see RawJSONToRuleData)
+
+// textContains checks if a card text field contains text, ignoring case
+// Usage: typeContains("Legendary") or oracleContains("flying")
+func textContains(cardData map[string]interface{}, field, text string) bool {
+	value, ok := cardData[field].(string)
+	if !ok || text == "" {
+		return false
+	}
+	return strings.Contains(strings.ToLower(value), strings.ToLower(text))
+}
+
+// nameMatches checks a card's name against a regular expression (Go RE2 syntax), ignoring case.
+// Double-faced cards are named after both faces, e.g. "Delver of Secrets // Insectile Aberration".
+// Usage: nameMatches("^(Plains|Island)$")
+func (e *Evaluator) nameMatches(cardData map[string]interface{}, pattern string) (bool, error) {
+	re, ok := e.patterns[pattern]
+	if !ok {
+		var err error
+		re, err = compileNamePattern(pattern)
+		if err != nil {
+			return false, err
+		}
+		if e.patterns == nil {
+			e.patterns = make(map[string]*regexp.Regexp)
+		}
+		e.patterns[pattern] = re
+	}
+	name, _ := cardData["name"].(string)
+	return re.MatchString(name), nil
+}
+
+// compileNamePattern compiles a nameMatches pattern to match case-insensitively
+func compileNamePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nameMatches pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// Price and date helpers
+// Cards without a price or release date never fail an expression
+
+// priceUSD returns the USD price for the item's treatment, falling back to the nonfoil
+// price like models.CardPrices.InCurrency, or 0 when the card has no USD price
+// Usage: priceUSD() >= 10
+func priceUSD(cardData map[string]interface{}) float64 {
+	prices, _ := cardData["prices"].(map[string]interface{})
+	price := func(field string) (float64, bool) {
+		value, ok := prices[field].(float64)
+		return value, ok
+	}
+
+	treatment, _ := cardData["treatment"].(string)
+	switch treatment {
+	case "", "nonfoil":
+	case "etched":
+		if value, ok := price("usd_etched"); ok {
+			return value
+		}
+		fallthrough
+	default:
+		if value, ok := price("usd_foil"); ok {
+			return value
+		}
+	}
+	value, _ := price("usd")
+	return value
+}
+
+// isReserveList checks if a card is on the Reserved List
+// Usage: isReserveList()
+func isReserveList(cardData map[string]interface{}) bool {
+	reserved, _ := cardData["reserved"].(bool)
+	return reserved
+}
+
+// parseReleaseDate parses a YYYY-MM-DD date as Scryfall formats released_at
+func parseReleaseDate(date string) (time.Time, error) {
+	parsed, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+	}
+	return parsed, nil
+}
+
+// releasedBefore checks if a printing was released before a YYYY-MM-DD date; false
+// when its release date is unknown
+// Usage: releasedBefore("2003-01-01")
+func releasedBefore(cardData map[string]interface{}, date string) (bool, error) {
+	before, err := parseReleaseDate(date)
+	if err != nil {
+		return false, err
+	}
+	releasedAt, _ := cardData["released_at"].(string)
+	released, err := time.Parse(time.DateOnly, releasedAt)
+	if err != nil {
+		return false, nil
+	}
+	return released.Before(before), nil
+}
+
+// ageYears returns the whole years since a printing was released, or 0 when its
+// release date is unknown
+// Usage: ageYears() >= 25
+func ageYears(cardData map[string]interface{}, now time.Time) int {
+	releasedAt, _ := cardData["released_at"].(string)
+	released, err := time.Parse(time.DateOnly, releasedAt)
+	if err != nil || released.After(now) {
+		return 0
+	}
+	years := now.Year() - released.Year()
+	if now.Month() < released.Month() || now.Month() == released.Month() && now.Day() < released.Day() {
+		years--
+	}
+	return years
+}
+
+// literalArgChecks validate the literal string argument of helpers that can only
+// reject it at evaluation time
+var literalArgChecks = map[string]func(string) error{
+	"nameMatches": func(pattern string) error {
+		_, err := compileNamePattern(pattern)
+		return err
+	},
+	"releasedBefore": func(date string) error {
+		_, err := parseReleaseDate(date)
+		return err
+	},
+}
+
+// literalArgChecker records the first helper call whose literal argument is invalid,
+// so validation catches it before any card is evaluated
+type literalArgChecker struct {
+	err error
+}
+
+// Visit implements ast.Visitor
+func (l *literalArgChecker) Visit(node *ast.Node) {
+	call, ok := (*node).(*ast.CallNode)
+	if !ok || l.err != nil || len(call.Arguments) != 1 {
+		return
+	}
+	callee, ok := call.Callee.(*ast.IdentifierNode)
+	if !ok {
+		return
+	}
+	check, ok := literalArgChecks[callee.Value]
+	if !ok {
+		return
+	}
+	if arg, ok := call.Arguments[0].(*ast.StringNode); ok {
+		l.err = check(arg.Value)
+	}
+}
+
+// nilSafeComparisons rewrites ordering comparisons against a map field, such as
+// prices.usd < 5.0, to be false when the field is missing (a card without a USD price)
+// rather than failing the whole expression
+type nilSafeComparisons struct{}
+
+// Visit implements ast.Visitor
+func (nilSafeComparisons) Visit(node *ast.Node) {
+	binary, ok := (*node).(*ast.BinaryNode)
+	if !ok {
+		return
+	}
+	switch binary.Operator {
+	case "<", "<=", ">", ">=":
+	default:
+		return
+	}
+
+	var cond ast.Node
+	for _, side := range []ast.Node{binary.Left, binary.Right} {
+		if _, ok := side.(*ast.MemberNode); !ok {
+			continue
+		}
+		check := &ast.BinaryNode{Operator: "!=", Left: side, Right: &ast.NilNode{}}
+		if cond == nil {
+			cond = check
+		} else {
+			cond = &ast.BinaryNode{Operator: "&&", Left: cond, Right: check}
+		}
+	}
+	if cond != nil {
+		ast.Patch(node, &ast.ConditionalNode{Cond: cond, Exp1: binary, Exp2: &ast.BoolNode{Value: false}})
+	}
+}
+
 // ValidateExpression validates an expression without evaluating it
 func (e *Evaluator) ValidateExpression(expression string) error {
+	if err := checkExpressionComplexity(expression); err != nil {
+		return err
+	}
+
+	expanded, err := e.expandPredicates(expression)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+
+	return compileWithSampleEnv(expanded)
+}
+
+// ValidatePredicate validates a named predicate definition as if it were saved,
+// checking that it compiles, that every predicate it references exists, and that
+// it does not introduce a reference cycle.
+func (e *Evaluator) ValidatePredicate(name, expression string) error {
+	if err := checkExpressionComplexity(expression); err != nil {
+		return err
+	}
+
+	if err := e.loadPredicates(); err != nil {
+		return err
+	}
+
+	candidate := make(map[string]string, len(e.predicates)+1)
+	for k, v := range e.predicates {
+		candidate[k] = v
+	}
+	candidate[name] = expression
+
+	expanded, err := ExpandPredicates(fmt.Sprintf("predicate('%s')", name), candidate)
+	if err != nil {
+		return fmt.Errorf("invalid expression: %w", err)
+	}
+
+	return compileWithSampleEnv(expanded)
+}
+
+// checkExpressionComplexity enforces length and nesting limits on a raw expression
+func checkExpressionComplexity(expression string) error {
 	if expression == "" {
 		return fmt.Errorf("expression cannot be empty")
 	}
@@ -226,55 +769,75 @@ func (e *Evaluator) ValidateExpression(expression string) error {
 		return fmt.Errorf("expression too complex (max 20 levels of nesting)")
 	}
 
-	// Try to compile with a comprehensive sample environment matching Scryfall API + inventory fields
-	sampleEnv := map[string]interface{}{
-		// Price fields
-		"prices": map[string]interface{}{
-			"usd":        0.0,
-			"usd_foil":   0.0,
-			"usd_etched": 0.0,
-			"eur":        0.0,
-			"eur_foil":   0.0,
-			"tix":        0.0,
-		},
-		// Card properties
-		"name":             "",
-		"rarity":           "",
-		"set":              "",
-		"set_name":         "",
-		"set_type":         "",
-		"type_line":        "",
-		"oracle_text":      "",
-		"mana_cost":        "",
-		"cmc":              0.0,
-		"power":            "",
-		"toughness":        "",
-		"colors":           []string{},
-		"color_identity":   []string{},
-		"keywords":         []string{},
-		"finishes":         []string{},
-		"promo_types":      []string{},
-		"edhrec_rank":      0,
-		"artist":           "",
-		"collector_number": "",
-		"frame":            "",
-		"border_color":     "",
-		"layout":           "",
-		"reserved":         false,
-		"foil":             false,
-		"nonfoil":          false,
-		"oversized":        false,
-		"promo":            false,
-		"reprint":          false,
-		"digital":          false,
-		"full_art":         false,
-		"textless":         false,
-		"booster":          false,
-
-		// Inventory-specific fields
-		"treatment": "", // "foil", "nonfoil", "etched", etc.
-		"quantity":  0,
+	return nil
+}
 
+// cardFieldDefaults are the values rule expressions see for the Scryfall and inventory
+// fields a card's data lacks, so a rule on an absent field simply doesn't match
+// instead of failing to compile
+var cardFieldDefaults = map[string]interface{}{
+	// Price fields; a card without prices has none to compare
+	"prices": map[string]interface{}{},
+	// Card properties
+	"name":             "",
+	"rarity":           "",
+	"set":              "",
+	"set_name":         "",
+	"set_type":         "",
+	"type_line":        "",
+	"oracle_text":      "",
+	"mana_cost":        "",
+	"cmc":              0.0,
+	"power":            "",
+	"toughness":        "",
+	"colors":           []string{},
+	"color_identity":   []string{},
+	"keywords":         []string{},
+	"finishes":         []string{},
+	"promo_types":      []string{},
+	"frame_effects":    []string{},
+	"legalities":       map[string]interface{}{},
+	"edhrec_rank":      0,
+	"artist":           "",
+	"collector_number": "",
+	"released_at":      "",
+	"loyalty":          "",
+	"frame":            "",
+	"border_color":     "",
+	"layout":           "",
+	"reserved":         false,
+	"foil":             false,
+	"nonfoil":          false,
+	"oversized":        false,
+	"promo":            false,
+	"reprint":          false,
+	"digital":          false,
+	"full_art":         false,
+	"textless":         false,
+	"booster":          false,
+
+	// Inventory-specific fields
+	"treatment": "", // "foil", "nonfoil", "etched", etc.
+	"quantity":  0,
+	"notes":     "",
+	"tags":      []string{}, // The item's tags, plus those added by tag rule groups
+}
+
+// compileWithSampleEnv compiles an expression against a representative card environment
+func compileWithSampleEnv(expression string) error {
+	// Try to compile with a comprehensive sample environment matching Scryfall API + inventory fields
+	sampleEnv := make(map[string]interface{}, len(cardFieldDefaults))
+	maps.Copy(sampleEnv, cardFieldDefaults)
+	// Typed sample prices, so price comparisons type-check
+	sampleEnv["prices"] = map[string]interface{}{
+		"usd":        0.0,
+		"usd_foil":   0.0,
+		"usd_etched": 0.0,
+		"eur":        0.0,
+		"eur_foil":   0.0,
+		"tix":        0.0,
+	}
+	maps.Copy(sampleEnv, map[string]interface{}{
 		// Helper functions
 		"hasColor": func(color string) bool {
 			return false
@@ -291,12 +854,58 @@ func (e *Evaluator) ValidateExpression(expression string) error {
 		"isColor": func(colors ...string) bool {
 			return false
 		},
-	}
+		"isSerialized": func() bool {
+			return false
+		},
+		"isExtendedArt": func() bool {
+			return false
+		},
+		"isBorderless": func() bool {
+			return false
+		},
+		"isStandardLegal": func() bool {
+			return false
+		},
+		"isBasicLand": func() bool {
+			return false
+		},
+		"noteContains": func(text string) bool {
+			return false
+		},
+		"nameMatches": func(pattern string) (bool, error) {
+			return false, nil
+		},
+		"typeContains": func(text string) bool {
+			return false
+		},
+		"oracleContains": func(text string) bool {
+			return false
+		},
+		"priceUSD": func() float64 {
+			return 0
+		},
+		"isReserveList": func() bool {
+			return false
+		},
+		"releasedBefore": func(date string) (bool, error) {
+			return false, nil
+		},
+		"ageYears": func() int {
+			return 0
+		},
+		"hasTag": func(tag string) bool {
+			return false
+		},
+	})
 
-	_, err := expr.Compile(expression, expr.Env(sampleEnv), expr.AsBool())
+	literals := &literalArgChecker{}
+	_, err := expr.Compile(expression, expr.Env(sampleEnv), expr.AsBool(), expr.Patch(literals), expr.Patch(nilSafeComparisons{}))
 	if err != nil {
 		return fmt.Errorf("invalid expression: %w", err)
 	}
+	if literals.err != nil {
+		return fmt.Errorf("invalid expression: %w", literals.err)
+	}
 
 	return nil
 }
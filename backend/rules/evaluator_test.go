@@ -4,6 +4,7 @@ import (
 	"backend/models"
 	"context"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -17,7 +18,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.StorageLocation{}, &models.SortingRule{}); err != nil {
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.SortingRule{}, &models.RuleGroup{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 
@@ -200,9 +201,28 @@ func TestEvaluateExpression_MissingField(t *testing.T) {
 
 	cardData := map[string]interface{}{}
 
-	_, err := evaluator.EvaluateExpression("prices.usd < 5.0", cardData)
-	if err == nil {
-		t.Error("expected error for missing field")
+	// Missing fields read as empty, so the rules don't match rather than erroring
+	for _, expression := range []string{
+		"prices.usd < 5.0",
+		"prices.usd >= 0 || 5.0 > prices.eur",
+		"legalities.modern == 'legal'",
+		"set_type == 'core'",
+		"len(colors) > 0",
+		"quantity > 1",
+		"unknown_field == 'x'",
+	} {
+		result, err := evaluator.EvaluateExpression(expression, cardData)
+		if err != nil {
+			t.Errorf("%s: expected no error for missing field, got %v", expression, err)
+		}
+		if result {
+			t.Errorf("%s: expected no match for missing field", expression)
+		}
+	}
+
+	// Validation still catches names no card has
+	if err := evaluator.ValidateExpression("unknown_field == 'x'"); err == nil {
+		t.Error("expected validation to reject an unknown field")
 	}
 }
 
@@ -291,6 +311,41 @@ func TestEvaluateCard_FirstMatchWins(t *testing.T) {
 	}
 }
 
+func TestTraceCard_EvaluatesEveryRule(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	location := createTestLocation(t, db)
+	rules := []models.SortingRule{
+		createTestRule(t, db, "Cheap", 1, "prices.usd < 10.0", location.ID, true),
+		createTestRule(t, db, "Mythic", 2, "rarity == 'mythic'", location.ID, true),
+		createTestRule(t, db, "Broken", 3, "prices.usd <", location.ID, true),
+		createTestRule(t, db, "Catch-all", 4, "true", location.ID, true),
+	}
+
+	cardData := map[string]interface{}{
+		"rarity": "rare",
+		"prices": map[string]interface{}{
+			"usd": 5.0,
+		},
+	}
+
+	traces := evaluator.TraceCard(cardData, rules)
+	if len(traces) != 4 {
+		t.Fatalf("expected 4 traces, got %d", len(traces))
+	}
+
+	expected := []bool{true, false, false, true}
+	for i, trace := range traces {
+		if trace.Matched != expected[i] {
+			t.Errorf("rule %q: expected matched %v, got %v", trace.Rule.Name, expected[i], trace.Matched)
+		}
+	}
+	if traces[2].Err == nil {
+		t.Error("expected an error for the invalid expression")
+	}
+}
+
 func TestEvaluateCard_SkipsDisabledRules(t *testing.T) {
 	db := setupTestDB(t)
 	evaluator := NewEvaluator(db)
@@ -905,3 +960,419 @@ func TestValidateExpression_IsColorHelper(t *testing.T) {
 		t.Errorf("expected isColor with 5 colors to be valid, got error: %v", err)
 	}
 }
+
+func TestHelperFunction_IsStandardLegal(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&models.Set{}); err != nil {
+		t.Fatalf("failed to migrate sets: %v", err)
+	}
+	db.Create(&models.Set{ScryfallID: "set-1", Code: "std", Name: "Standard Set", StandardLegal: true})
+	db.Create(&models.Set{ScryfallID: "set-2", Code: "old", Name: "Rotated Set"})
+
+	evaluator := NewEvaluator(db)
+
+	tests := []struct {
+		name     string
+		set      string
+		standard string
+		expected bool
+	}{
+		{name: "Legal card in standard set", set: "std", standard: "legal", expected: true},
+		{name: "Banned card in standard set", set: "std", standard: "banned", expected: false},
+		{name: "Reprint in rotated set", set: "old", standard: "legal", expected: false},
+		{name: "Not legal card in rotated set", set: "old", standard: "not_legal", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardData := map[string]interface{}{
+				"set":        tt.set,
+				"legalities": map[string]interface{}{"standard": tt.standard},
+			}
+
+			result, err := evaluator.EvaluateExpression("isStandardLegal()", cardData)
+			if err != nil {
+				t.Fatalf("evaluation failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestValidateExpression_IsStandardLegalHelper(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	if err := evaluator.ValidateExpression("isStandardLegal() && legalities.pioneer == 'legal'"); err != nil {
+		t.Errorf("expected isStandardLegal expression to be valid, got error: %v", err)
+	}
+}
+
+func TestHelperFunction_PrintTreatments(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	cardData := map[string]interface{}{
+		"border_color":  "borderless",
+		"promo_types":   []interface{}{"serialized", "boosterfun"},
+		"frame_effects": []interface{}{"inverted"},
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: "isSerialized()", expected: true},
+		{expression: "isBorderless()", expected: true},
+		{expression: "isExtendedArt()", expected: false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluator.EvaluateExpression(tt.expression, cardData)
+		if err != nil {
+			t.Fatalf("evaluation of %s failed: %v", tt.expression, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expression, tt.expected, result)
+		}
+	}
+}
+
+func TestHelperFunction_IsBasicLand(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	tests := []struct {
+		typeLine string
+		expected bool
+	}{
+		{typeLine: "Basic Land — Forest", expected: true},
+		{typeLine: "Basic Snow Land — Island", expected: true},
+		{typeLine: "Basic Land", expected: true},
+		{typeLine: "Land — Forest Island", expected: false},
+		{typeLine: "Legendary Land", expected: false},
+		{typeLine: "Creature — Elf Druid", expected: false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluator.EvaluateExpression("isBasicLand()", map[string]interface{}{"type_line": tt.typeLine})
+		if err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("%q: expected %v, got %v", tt.typeLine, tt.expected, result)
+		}
+	}
+
+	if err := evaluator.ValidateExpression("!isBasicLand() && prices.usd < 1"); err != nil {
+		t.Errorf("expected isBasicLand expression to be valid, got error: %v", err)
+	}
+}
+
+func TestHelperFunction_PrintTreatments_TypedSlices(t *testing.T) {
+	cardData := map[string]interface{}{
+		"promo_types":   []string{"serialized"},
+		"frame_effects": []string{"extendedart"},
+	}
+
+	if !isSerialized(cardData) {
+		t.Error("expected isSerialized to handle []string promo_types")
+	}
+	if !isExtendedArt(cardData) {
+		t.Error("expected isExtendedArt to handle []string frame_effects")
+	}
+}
+
+func TestHelperFunction_NoteContains(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	cardData := map[string]interface{}{"notes": "Signed by the artist, GP Vegas 2019"}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: `noteContains("signed")`, expected: true},
+		{expression: `noteContains("ARTIST")`, expected: true},
+		{expression: `noteContains("altered")`, expected: false},
+		{expression: `noteContains("")`, expected: false},
+		{expression: `notes contains "GP"`, expected: true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluator.EvaluateExpression(tt.expression, cardData)
+		if err != nil {
+			t.Fatalf("evaluation of %s failed: %v", tt.expression, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expression, tt.expected, result)
+		}
+	}
+
+	if err := evaluator.ValidateExpression(`noteContains("signed") || notes == ""`); err != nil {
+		t.Errorf("expected notes expression to be valid, got error: %v", err)
+	}
+}
+
+func TestHelperFunction_TextHelpers(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	cardData, err := RawJSONToRuleData(`{
+		"name": "Delver of Secrets // Insectile Aberration",
+		"set": "isd",
+		"card_faces": [
+			{"type_line": "Creature — Human Wizard", "oracle_text": "At the beginning of your upkeep, look at the top card of your library."},
+			{"type_line": "Creature — Human Insect", "oracle_text": "Flying"}
+		]
+	}`, "nonfoil")
+	if err != nil {
+		t.Fatalf("failed to convert card: %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: `nameMatches("^delver of")`, expected: true},
+		{expression: `nameMatches("// Insectile")`, expected: true},
+		{expression: `nameMatches("^Insectile")`, expected: false},
+		{expression: `typeContains("human insect")`, expected: true},
+		{expression: `typeContains("Legendary")`, expected: false},
+		{expression: `typeContains("")`, expected: false},
+		{expression: `oracleContains("FLYING")`, expected: true},
+		{expression: `oracleContains("trample")`, expected: false},
+		{expression: `set in ["isd", "dka"]`, expected: true},
+		{expression: `set in ['lea', 'leb']`, expected: false},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluator.EvaluateExpression(tt.expression, cardData)
+		if err != nil {
+			t.Fatalf("evaluation of %s failed: %v", tt.expression, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expression, tt.expected, result)
+		}
+	}
+
+	// A pattern built at evaluation time can only fail then
+	if _, err := evaluator.EvaluateExpression(`nameMatches("(" + set)`, cardData); err == nil {
+		t.Error("expected an invalid pattern to fail evaluation")
+	}
+}
+
+func TestValidateExpression_TextHelpers(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	valid := []string{
+		`nameMatches("^(Plains|Island)$") || typeContains("Legendary")`,
+		`oracleContains("flying") && set in ['lea', 'leb']`,
+		`nameMatches("(" + set)`, // Not a literal, so checked at evaluation
+	}
+	for _, expression := range valid {
+		if err := evaluator.ValidateExpression(expression); err != nil {
+			t.Errorf("expected %s to be valid, got error: %v", expression, err)
+		}
+	}
+
+	invalid := []string{
+		`nameMatches("[unclosed")`,
+		`rarity == "rare" && nameMatches("*foil")`,
+		`nameMatches(1)`,
+		`typeContains()`,
+	}
+	for _, expression := range invalid {
+		if err := evaluator.ValidateExpression(expression); err == nil {
+			t.Errorf("expected %s to be rejected", expression)
+		}
+	}
+}
+
+func TestHelperFunction_PriceAndDateHelpers(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	cardData, err := RawJSONToRuleData(`{
+		"name": "Black Lotus",
+		"reserved": true,
+		"released_at": "1993-08-05",
+		"prices": {"usd": "20000.00", "usd_foil": null, "eur": null}
+	}`, "foil")
+	if err != nil {
+		t.Fatalf("failed to convert card: %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		// Foil copies fall back to the nonfoil price when there is no foil price
+		{expression: `priceUSD() == 20000`, expected: true},
+		{expression: `isReserveList()`, expected: true},
+		{expression: `releasedBefore("2003-01-01")`, expected: true},
+		{expression: `releasedBefore("1993-08-05")`, expected: false},
+		{expression: `ageYears() >= 30`, expected: true},
+		// Missing prices make the comparison false instead of failing the expression
+		{expression: `prices.eur < 5.0`, expected: false},
+		{expression: `5.0 <= prices.usd_foil`, expected: false},
+		{expression: `prices.eur > 5.0 || prices.usd > 5.0`, expected: true},
+		{expression: `prices.eur == nil`, expected: true},
+	}
+
+	for _, tt := range tests {
+		result, err := evaluator.EvaluateExpression(tt.expression, cardData)
+		if err != nil {
+			t.Fatalf("evaluation of %s failed: %v", tt.expression, err)
+		}
+		if result != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.expression, tt.expected, result)
+		}
+	}
+
+	// Cards without prices or a release date don't fail
+	bare, err := RawJSONToRuleData(`{"name": "Unknown"}`, "nonfoil")
+	if err != nil {
+		t.Fatalf("failed to convert card: %v", err)
+	}
+	result, err := evaluator.EvaluateExpression(`priceUSD() == 0 && ageYears() == 0 && !releasedBefore("2003-01-01") && !(prices.usd < 1)`, bare)
+	if err != nil || !result {
+		t.Errorf("expected a bare card to match safely, got %v, %v", result, err)
+	}
+
+	if err := evaluator.ValidateExpression(`releasedBefore("Jan 2003")`); err == nil {
+		t.Error("expected an invalid date to be rejected")
+	}
+	if err := evaluator.ValidateExpression(`isReserveList() && releasedBefore("1995-01-01") && priceUSD() > 100`); err != nil {
+		t.Errorf("expected expression to be valid, got error: %v", err)
+	}
+}
+
+func TestAgeYears(t *testing.T) {
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		releasedAt string
+		expected   int
+	}{
+		{releasedAt: "2020-03-01", expected: 4},
+		{releasedAt: "2020-03-02", expected: 3},
+		{releasedAt: "2024-02-29", expected: 0},
+		{releasedAt: "2025-01-01", expected: 0}, // Spoiled but unreleased
+		{releasedAt: "", expected: 0},
+	}
+	for _, tt := range tests {
+		if got := ageYears(map[string]interface{}{"released_at": tt.releasedAt}, now); got != tt.expected {
+			t.Errorf("%q: expected %d, got %d", tt.releasedAt, tt.expected, got)
+		}
+	}
+}
+
+func TestHelpers_MatchEnvironment(t *testing.T) {
+	documented := make(map[string]bool, len(Helpers))
+	for _, helper := range Helpers {
+		if documented[helper.Name] {
+			t.Errorf("helper %s documented twice", helper.Name)
+		}
+		documented[helper.Name] = true
+		if helper.Signature == "" || helper.Description == "" || helper.Example == "" {
+			t.Errorf("helper %s is missing documentation: %+v", helper.Name, helper)
+		}
+		if err := compileWithSampleEnv(helper.Example); err != nil && helper.Name != "predicate" {
+			t.Errorf("example for %s doesn't compile: %v", helper.Name, err)
+		}
+	}
+
+	// Every function the evaluator provides is documented
+	for _, name := range []string{"hasColor", "isMonoColor", "isMultiColor", "isColorless", "isColor",
+		"isSerialized", "isExtendedArt", "isBorderless", "isStandardLegal", "isBasicLand",
+		"noteContains", "nameMatches", "typeContains", "oracleContains", "priceUSD", "isReserveList",
+		"releasedBefore", "ageYears", "hasTag"} {
+		if !documented[name] {
+			t.Errorf("helper %s is not documented", name)
+		}
+	}
+}
+
+func TestMatchingLocations(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	first := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Name: "First"}
+	second := models.StorageLocation{BaseModel: models.BaseModel{ID: 2}, Name: "Second"}
+	sortingRules := []models.SortingRule{
+		{Expression: "rarity == 'common'", StorageLocationID: 1, StorageLocation: first},
+		{Expression: "rarity == 'rare'", StorageLocationID: 2, StorageLocation: second},
+		{Expression: "true", StorageLocationID: 2, StorageLocation: second},
+		{Expression: "true", StorageLocationID: 1, StorageLocation: first},
+	}
+
+	locations := evaluator.MatchingLocations(map[string]interface{}{"rarity": "common"}, sortingRules)
+	if len(locations) != 2 {
+		t.Fatalf("expected 2 distinct locations, got %d", len(locations))
+	}
+	if locations[0].ID != 1 || locations[1].ID != 2 {
+		t.Errorf("expected locations in priority order [1 2], got [%d %d]", locations[0].ID, locations[1].ID)
+	}
+}
+
+func TestMatchingLocations_UnassignedRule(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	first := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Name: "First"}
+	sortingRules := []models.SortingRule{
+		{Expression: "rarity == 'rare'", StorageLocationID: 1, StorageLocation: first},
+		{Expression: "rarity == 'common'", StorageLocationID: models.UnassignedLocationID},
+		{Expression: "true", StorageLocationID: 1, StorageLocation: first},
+	}
+
+	// Rules below an unassigned rule no longer apply
+	locations := evaluator.MatchingLocations(map[string]interface{}{"rarity": "common"}, sortingRules)
+	if len(locations) != 1 || locations[0].ID != models.UnassignedLocationID || locations[0].Name != models.UnassignedLocationName {
+		t.Errorf("expected only the unassigned location, got %+v", locations)
+	}
+
+	locations = evaluator.MatchingLocations(map[string]interface{}{"rarity": "rare"}, sortingRules)
+	if len(locations) != 1 || locations[0].ID != 1 {
+		t.Errorf("expected only location 1, got %+v", locations)
+	}
+}
+
+func TestMatchingLocations_RecordTimings(t *testing.T) {
+	db := setupTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	first := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Name: "First"}
+	second := models.StorageLocation{BaseModel: models.BaseModel{ID: 2}, Name: "Second"}
+	sortingRules := []models.SortingRule{
+		{BaseModel: models.BaseModel{ID: 10}, Expression: "rarity == 'common'", StorageLocationID: 1, StorageLocation: first},
+		{BaseModel: models.BaseModel{ID: 11}, Expression: "true", StorageLocationID: 2, StorageLocation: second},
+		{BaseModel: models.BaseModel{ID: 12}, Expression: "true", StorageLocationID: 1, StorageLocation: first},
+	}
+
+	// Nothing is recorded until timings are enabled
+	evaluator.MatchingLocations(map[string]interface{}{"rarity": "common"}, sortingRules)
+	if len(evaluator.Timings()) != 0 {
+		t.Fatalf("expected no timings before RecordTimings, got %v", evaluator.Timings())
+	}
+
+	evaluator.RecordTimings()
+	evaluator.MatchingLocations(map[string]interface{}{"rarity": "common"}, sortingRules)
+	evaluator.MatchingLocations(map[string]interface{}{"rarity": "rare"}, sortingRules)
+
+	timings := evaluator.Timings()
+	if timings[10].Evaluations != 2 || timings[11].Evaluations != 2 {
+		t.Errorf("expected rules 10 and 11 evaluated twice, got %+v", timings)
+	}
+	// Rule 12 is skipped for the common card since its location already matched
+	if timings[12].Evaluations != 1 {
+		t.Errorf("expected rule 12 evaluated once, got %d", timings[12].Evaluations)
+	}
+	if timings[10].Total <= 0 || timings[10].Max > timings[10].Total {
+		t.Errorf("unexpected durations for rule 10: %+v", timings[10])
+	}
+}
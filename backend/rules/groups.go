@@ -0,0 +1,129 @@
+package rules
+
+import (
+	"backend/models"
+	"cmp"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ruleStep is a sorting rule or an enabled rule group, in evaluation order
+type ruleStep struct {
+	// rule is the sorting rule, or for a group a rule carrying its name, priority,
+	// combined expression, and target location
+	rule  models.SortingRule
+	group *models.RuleGroup
+}
+
+// action is what a match of the step does; sorting rules always assign
+func (s ruleStep) action() models.RuleGroupAction {
+	if s.group == nil {
+		return models.RuleGroupAssign
+	}
+	return s.group.Action
+}
+
+// loadGroups fetches the enabled rule groups into the evaluator's cache. Groups are
+// an optional layer over the rules, so a failed lookup is logged and evaluation goes
+// on with the rules alone.
+func (e *Evaluator) loadGroups() []models.RuleGroup {
+	if e.groups != nil {
+		return e.groups
+	}
+
+	e.groups = []models.RuleGroup{}
+	if e.db == nil {
+		return e.groups
+	}
+	if err := e.db.Where("enabled = ?", true).
+		Order("priority ASC, id ASC").
+		Preload("StorageLocation").
+		Find(&e.groups).Error; err != nil {
+		slog.Warn("failed to fetch rule groups, evaluating rules only", "component", "rules", "error", err)
+		e.groups = []models.RuleGroup{}
+	}
+	return e.groups
+}
+
+// steps merges the rules with the enabled rule groups by priority, keeping each
+// side's order and putting rules before groups of the same priority
+func (e *Evaluator) steps(rules []models.SortingRule) []ruleStep {
+	groups := e.loadGroups()
+	steps := make([]ruleStep, 0, len(rules)+len(groups))
+	for _, rule := range rules {
+		steps = append(steps, ruleStep{rule: rule})
+	}
+	for i := range groups {
+		steps = append(steps, ruleStep{rule: groupRule(groups[i]), group: &groups[i]})
+	}
+	// Rules come first, so the stable sort keeps them ahead of groups on a tie
+	slices.SortStableFunc(steps, func(a, b ruleStep) int {
+		return cmp.Compare(a.rule.Priority, b.rule.Priority)
+	})
+	return steps
+}
+
+// groupRule describes a rule group as the sorting rule it behaves like
+func groupRule(group models.RuleGroup) models.SortingRule {
+	operator := " && "
+	if group.Operator == models.RuleGroupOr {
+		operator = " || "
+	}
+	parts := make([]string, len(group.Expressions))
+	for i, expression := range group.Expressions {
+		parts[i] = "(" + expression + ")"
+	}
+
+	rule := models.SortingRule{
+		Name:       group.Name,
+		Priority:   group.Priority,
+		Expression: strings.Join(parts, operator),
+		Enabled:    group.Enabled,
+	}
+	if group.StorageLocationID != nil {
+		rule.StorageLocationID = *group.StorageLocationID
+	}
+	if group.StorageLocation != nil {
+		rule.StorageLocation = *group.StorageLocation
+	}
+	return rule
+}
+
+// evaluateStep evaluates a rule's expression, or a group's expressions combined
+// with its operator
+func (e *Evaluator) evaluateStep(step ruleStep, cardData map[string]interface{}) (bool, error) {
+	if step.group == nil {
+		return e.timeRule(step.rule, cardData)
+	}
+
+	// AND stops at the first expression that doesn't match, OR at the first that does
+	matchAny := step.group.Operator == models.RuleGroupOr
+	for _, expression := range step.group.Expressions {
+		matches, err := e.evaluateExpression(expression, cardData)
+		if err != nil {
+			return false, err
+		}
+		if matches == matchAny {
+			return matchAny, nil
+		}
+	}
+	return !matchAny, nil
+}
+
+// withTag returns a copy of the card data with tag added to its tags, leaving the
+// caller's card data untouched
+func withTag(cardData map[string]interface{}, tag string) map[string]interface{} {
+	tagged := maps.Clone(cardData)
+	tags, _ := cardData["tags"].([]string)
+	tagged["tags"] = append(slices.Clone(tags), tag)
+	return tagged
+}
+
+// hasTag checks if the inventory item carries a tag, or a tag rule group earlier in
+// the order tagged the card
+// Usage: hasTag("bulk")
+func hasTag(cardData map[string]interface{}, tag string) bool {
+	return containsString(cardData["tags"], tag)
+}
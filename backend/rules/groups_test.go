@@ -0,0 +1,224 @@
+package rules
+
+import (
+	"backend/models"
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func createTestGroup(t *testing.T, db *gorm.DB, group models.RuleGroup) models.RuleGroup {
+	t.Helper()
+	if group.Operator == "" {
+		group.Operator = models.RuleGroupAnd
+	}
+	group.Enabled = true
+	if err := db.Create(&group).Error; err != nil {
+		t.Fatalf("failed to create test group: %v", err)
+	}
+	return group
+}
+
+func TestEvaluateCard_GroupOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		operator models.RuleGroupOperator
+		card     map[string]interface{}
+		want     bool
+	}{
+		{"and, all match", models.RuleGroupAnd, map[string]interface{}{"rarity": "rare", "set": "lea"}, true},
+		{"and, one misses", models.RuleGroupAnd, map[string]interface{}{"rarity": "rare", "set": "m10"}, false},
+		{"or, one matches", models.RuleGroupOr, map[string]interface{}{"rarity": "common", "set": "lea"}, true},
+		{"or, none match", models.RuleGroupOr, map[string]interface{}{"rarity": "common", "set": "m10"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			location := createTestLocation(t, db)
+			createTestGroup(t, db, models.RuleGroup{
+				Name:              "Old rares",
+				Priority:          1,
+				Operator:          tt.operator,
+				Expressions:       []string{`rarity == "rare"`, `set == "lea"`},
+				Action:            models.RuleGroupAssign,
+				StorageLocationID: &location.ID,
+			})
+
+			result, err := NewEvaluator(db).EvaluateCard(context.Background(), tt.card)
+			if tt.want && (err != nil || result.ID != location.ID) {
+				t.Errorf("expected location %d, got %v (err %v)", location.ID, result, err)
+			}
+			if !tt.want && err == nil {
+				t.Errorf("expected no match, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestEvaluateCard_GroupTagFallsThrough(t *testing.T) {
+	db := setupTestDB(t)
+	bulk := createTestLocation(t, db)
+	other := createTestLocation(t, db)
+
+	createTestGroup(t, db, models.RuleGroup{
+		Name:        "Cheap",
+		Priority:    1,
+		Operator:    models.RuleGroupOr,
+		Expressions: []string{"prices.usd < 1", `rarity == "common"`},
+		Action:      models.RuleGroupTag,
+		Tag:         "bulk",
+	})
+	createTestRule(t, db, "Bulk", 2, `hasTag("bulk")`, bulk.ID, true)
+	createTestRule(t, db, "Everything", 3, "true", other.ID, true)
+
+	evaluator := NewEvaluator(db)
+	card := map[string]interface{}{"rarity": "common", "prices": map[string]interface{}{"usd": 5.0}}
+	result, err := evaluator.EvaluateCard(context.Background(), card)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if result.ID != bulk.ID {
+		t.Errorf("expected the tagged card in location %d, got %d", bulk.ID, result.ID)
+	}
+	if _, ok := card["tags"]; ok {
+		t.Error("expected the caller's card data to be left untouched")
+	}
+
+	result, err = evaluator.EvaluateCard(context.Background(), map[string]interface{}{"rarity": "mythic"})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if result.ID != other.ID {
+		t.Errorf("expected the untagged card in location %d, got %d", other.ID, result.ID)
+	}
+}
+
+func TestEvaluateCard_GroupSkip(t *testing.T) {
+	db := setupTestDB(t)
+	location := createTestLocation(t, db)
+
+	createTestRule(t, db, "Mythics", 1, `rarity == "mythic"`, location.ID, true)
+	createTestGroup(t, db, models.RuleGroup{
+		Name:        "Leave tokens",
+		Priority:    2,
+		Expressions: []string{`layout == "token"`},
+		Action:      models.RuleGroupSkip,
+	})
+	createTestRule(t, db, "Everything", 3, "true", location.ID, true)
+
+	_, err := NewEvaluator(db).EvaluateCard(context.Background(), map[string]interface{}{"layout": "token"})
+	if err == nil || !strings.Contains(err.Error(), "Leave tokens") {
+		t.Errorf("expected the skip group to stop evaluation, got %v", err)
+	}
+
+	// Rules before the skip group still apply
+	result, err := NewEvaluator(db).EvaluateCard(context.Background(), map[string]interface{}{"layout": "token", "rarity": "mythic"})
+	if err != nil || result.ID != location.ID {
+		t.Errorf("expected location %d, got %v (err %v)", location.ID, result, err)
+	}
+}
+
+func TestEvaluateCard_GroupAfterRuleOfSamePriority(t *testing.T) {
+	db := setupTestDB(t)
+	ruleLocation := createTestLocation(t, db)
+	groupLocation := createTestLocation(t, db)
+
+	createTestGroup(t, db, models.RuleGroup{
+		Name:              "Group",
+		Priority:          1,
+		Expressions:       []string{"true"},
+		Action:            models.RuleGroupAssign,
+		StorageLocationID: &groupLocation.ID,
+	})
+	createTestRule(t, db, "Rule", 1, "true", ruleLocation.ID, true)
+
+	result, err := NewEvaluator(db).EvaluateCard(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if result.ID != ruleLocation.ID {
+		t.Errorf("expected the rule to win the tie, got location %d", result.ID)
+	}
+}
+
+func TestTraceCard_Groups(t *testing.T) {
+	db := setupTestDB(t)
+	location := createTestLocation(t, db)
+
+	createTestGroup(t, db, models.RuleGroup{
+		Name:        "Foils",
+		Priority:    1,
+		Expressions: []string{`treatment == "foil"`},
+		Action:      models.RuleGroupTag,
+		Tag:         "foil",
+	})
+	createTestGroup(t, db, models.RuleGroup{
+		Name:        "Skip foil commons",
+		Priority:    2,
+		Expressions: []string{`hasTag("foil")`, `rarity == "common"`},
+		Action:      models.RuleGroupSkip,
+	})
+	rules := []models.SortingRule{createTestRule(t, db, "Everything", 3, "true", location.ID, true)}
+
+	evaluator := NewEvaluator(db)
+	traces := evaluator.TraceCard(map[string]interface{}{"treatment": "foil", "rarity": "common"}, rules)
+	if len(traces) != 2 {
+		t.Fatalf("expected the trace to end at the skip group, got %d traces", len(traces))
+	}
+	if traces[0].Group == nil || !traces[0].Matched || traces[0].Assigns() {
+		t.Errorf("expected a matching tag group that doesn't assign, got %+v", traces[0])
+	}
+	if !traces[1].Matched || traces[1].Rule.Expression != `(hasTag("foil")) && (rarity == "common")` {
+		t.Errorf("expected the skip group to match with its combined expression, got %+v", traces[1])
+	}
+
+	traces = evaluator.TraceCard(map[string]interface{}{"treatment": "nonfoil", "rarity": "common"}, rules)
+	if len(traces) != 3 || !traces[2].Assigns() {
+		t.Errorf("expected the rule to assign after both groups, got %+v", traces)
+	}
+}
+
+func TestMatchingLocations_GroupSkip(t *testing.T) {
+	db := setupTestDB(t)
+	createTestGroup(t, db, models.RuleGroup{
+		Name:        "Skip",
+		Priority:    2,
+		Expressions: []string{"true"},
+		Action:      models.RuleGroupSkip,
+	})
+
+	first := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Name: "First"}
+	second := models.StorageLocation{BaseModel: models.BaseModel{ID: 2}, Name: "Second"}
+	sortingRules := []models.SortingRule{
+		{Priority: 1, Expression: "true", StorageLocationID: 1, StorageLocation: first},
+		{Priority: 3, Expression: "true", StorageLocationID: 2, StorageLocation: second},
+	}
+
+	locations := NewEvaluator(db).MatchingLocations(map[string]interface{}{}, sortingRules)
+	if len(locations) != 1 || locations[0].ID != 1 {
+		t.Errorf("expected only location 1 before the skip group, got %+v", locations)
+	}
+}
+
+func TestEvaluateCard_DisabledGroupIgnored(t *testing.T) {
+	db := setupTestDB(t)
+	location := createTestLocation(t, db)
+
+	group := createTestGroup(t, db, models.RuleGroup{
+		Name:        "Skip",
+		Priority:    1,
+		Expressions: []string{"true"},
+		Action:      models.RuleGroupSkip,
+	})
+	if err := db.Model(&group).Update("enabled", false).Error; err != nil {
+		t.Fatalf("failed to disable group: %v", err)
+	}
+	createTestRule(t, db, "Everything", 2, "true", location.ID, true)
+
+	result, err := NewEvaluator(db).EvaluateCard(context.Background(), map[string]interface{}{})
+	if err != nil || result.ID != location.ID {
+		t.Errorf("expected location %d, got %v (err %v)", location.ID, result, err)
+	}
+}
@@ -0,0 +1,141 @@
+package rules
+
+// Helper documents a function or operator available in rule expressions
+type Helper struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"`
+	Example     string `json:"example"`
+}
+
+// Helpers lists what rule expressions can use beyond plain field comparisons, in the
+// order the rule editor shows them. Keep it in step with the helpers evaluateExpression
+// provides.
+var Helpers = []Helper{
+	{
+		Name:        "hasColor",
+		Signature:   "hasColor(color)",
+		Description: "The card's color identity includes the color (W, U, B, R, or G)",
+		Example:     `hasColor("G")`,
+	},
+	{
+		Name:        "isColor",
+		Signature:   "isColor(colors...)",
+		Description: "The card's colors or color identity are exactly the given colors",
+		Example:     `isColor("W", "U")`,
+	},
+	{
+		Name:        "isMonoColor",
+		Signature:   "isMonoColor()",
+		Description: "The card's color identity is exactly one color",
+		Example:     `isMonoColor() && hasColor("R")`,
+	},
+	{
+		Name:        "isMultiColor",
+		Signature:   "isMultiColor()",
+		Description: "The card's color identity has two or more colors",
+		Example:     "isMultiColor()",
+	},
+	{
+		Name:        "isColorless",
+		Signature:   "isColorless()",
+		Description: "The card's color identity is empty",
+		Example:     `isColorless() && !typeContains("Land")`,
+	},
+	{
+		Name:        "nameMatches",
+		Signature:   "nameMatches(pattern)",
+		Description: "The card's name matches a regular expression (RE2 syntax), ignoring case. Double-faced cards are named after both faces, joined by \" // \".",
+		Example:     `nameMatches("^Lightning ")`,
+	},
+	{
+		Name:        "typeContains",
+		Signature:   "typeContains(text)",
+		Description: "The card's type line contains the text, ignoring case. Checks every face of double-faced cards.",
+		Example:     `typeContains("Legendary Creature")`,
+	},
+	{
+		Name:        "oracleContains",
+		Signature:   "oracleContains(text)",
+		Description: "The card's rules text contains the text, ignoring case. Checks every face of double-faced cards.",
+		Example:     `oracleContains("flying")`,
+	},
+	{
+		Name:        "priceUSD",
+		Signature:   "priceUSD()",
+		Description: "The USD price for the item's treatment, falling back to the nonfoil price; 0 when the card has no USD price. Comparisons on prices fields are false when the price is missing.",
+		Example:     "priceUSD() >= 10",
+	},
+	{
+		Name:        "isReserveList",
+		Signature:   "isReserveList()",
+		Description: "The card is on the Reserved List",
+		Example:     "isReserveList()",
+	},
+	{
+		Name:        "releasedBefore",
+		Signature:   "releasedBefore(date)",
+		Description: "The printing was released before a YYYY-MM-DD date; false when its release date is unknown",
+		Example:     `releasedBefore("2003-01-01")`,
+	},
+	{
+		Name:        "ageYears",
+		Signature:   "ageYears()",
+		Description: "Whole years since the printing was released; 0 when its release date is unknown",
+		Example:     "ageYears() >= 25",
+	},
+	{
+		Name:        "isBasicLand",
+		Signature:   "isBasicLand()",
+		Description: "The card is a basic land, including snow-covered basics and Wastes",
+		Example:     "isBasicLand()",
+	},
+	{
+		Name:        "isStandardLegal",
+		Signature:   "isStandardLegal()",
+		Description: "The printing is from a set currently in Standard and the card isn't banned there",
+		Example:     "isStandardLegal()",
+	},
+	{
+		Name:        "isSerialized",
+		Signature:   "isSerialized()",
+		Description: "The printing is serial-numbered",
+		Example:     "isSerialized()",
+	},
+	{
+		Name:        "isExtendedArt",
+		Signature:   "isExtendedArt()",
+		Description: "The printing has the extended art frame",
+		Example:     `isExtendedArt() && treatment == "foil"`,
+	},
+	{
+		Name:        "isBorderless",
+		Signature:   "isBorderless()",
+		Description: "The printing has no border",
+		Example:     "isBorderless()",
+	},
+	{
+		Name:        "noteContains",
+		Signature:   "noteContains(text)",
+		Description: "The inventory item's notes contain the text, ignoring case",
+		Example:     `noteContains("signed")`,
+	},
+	{
+		Name:        "hasTag",
+		Signature:   "hasTag(tag)",
+		Description: "A tag rule group earlier in the priority order tagged the card",
+		Example:     `hasTag("bulk") && rarity == "common"`,
+	},
+	{
+		Name:        "predicate",
+		Signature:   "predicate(name)",
+		Description: "The card matches a saved named predicate",
+		Example:     `predicate('expensive') && rarity == "mythic"`,
+	},
+	{
+		Name:        "in",
+		Signature:   "value in [values...]",
+		Description: "The field equals one of the listed values; works with any field, such as set codes or rarities",
+		Example:     `set in ["lea", "leb", "2ed"]`,
+	},
+}
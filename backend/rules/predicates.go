@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// MaxExpandedExpressionLength caps the size of an expression after named
+// predicates are substituted, guarding against exponential blow-up when
+// predicates reference each other many times.
+const MaxExpandedExpressionLength = 10000
+
+var (
+	// ErrUnknownPredicate is returned when an expression references a predicate that does not exist
+	ErrUnknownPredicate = errors.New("unknown predicate")
+
+	// ErrPredicateCycle is returned when predicates reference each other in a loop
+	ErrPredicateCycle = errors.New("predicate cycle detected")
+)
+
+// predicateCallPattern matches predicate('name') and predicate("name")
+var predicateCallPattern = regexp.MustCompile(`predicate\(\s*(?:'([A-Za-z_][A-Za-z0-9_]*)'|"([A-Za-z_][A-Za-z0-9_]*)")\s*\)`)
+
+// predicateCallName extracts the predicate name from a predicateCallPattern match
+func predicateCallName(match string) string {
+	groups := predicateCallPattern.FindStringSubmatch(match)
+	if groups[1] != "" {
+		return groups[1]
+	}
+	return groups[2]
+}
+
+// ReferencedPredicates returns the distinct predicate names an expression calls directly
+func ReferencedPredicates(expression string) []string {
+	names := []string{}
+	for _, match := range predicateCallPattern.FindAllString(expression, -1) {
+		name := predicateCallName(match)
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ExpandPredicates replaces every predicate('name') call in the expression with
+// the parenthesised body of that predicate, recursively.
+// Usage: ExpandPredicates("predicate('isBulk') && set == 'dmu'", map[string]string{"isBulk": "prices.usd < 0.25"})
+func ExpandPredicates(expression string, predicates map[string]string) (string, error) {
+	expanded, err := expandPredicates(expression, predicates, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(expanded) > MaxExpandedExpressionLength {
+		return "", fmt.Errorf("expression too long after expanding predicates (max %d characters)", MaxExpandedExpressionLength)
+	}
+	return expanded, nil
+}
+
+func expandPredicates(expression string, predicates map[string]string, stack []string) (string, error) {
+	var expandErr error
+	result := predicateCallPattern.ReplaceAllStringFunc(expression, func(match string) string {
+		if expandErr != nil {
+			return match
+		}
+
+		name := predicateCallName(match)
+		if slices.Contains(stack, name) {
+			expandErr = fmt.Errorf("%w: %s", ErrPredicateCycle, strings.Join(append(slices.Clone(stack), name), " -> "))
+			return match
+		}
+
+		body, ok := predicates[name]
+		if !ok {
+			expandErr = fmt.Errorf("%w: %s", ErrUnknownPredicate, name)
+			return match
+		}
+
+		expanded, err := expandPredicates(body, predicates, append(slices.Clone(stack), name))
+		if err != nil {
+			expandErr = err
+			return match
+		}
+		if len(expanded) > MaxExpandedExpressionLength {
+			expandErr = fmt.Errorf("predicate %s too long after expansion (max %d characters)", name, MaxExpandedExpressionLength)
+			return match
+		}
+		return "(" + expanded + ")"
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return result, nil
+}
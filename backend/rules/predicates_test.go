@@ -0,0 +1,216 @@
+package rules
+
+import (
+	"backend/models"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestExpandPredicates(t *testing.T) {
+	predicates := map[string]string{
+		"isBulk":     "prices.usd < 0.25",
+		"isCheapRed": `predicate("isBulk") && hasColor("R")`,
+		"cycleA":     "predicate('cycleB')",
+		"cycleB":     "predicate('cycleA')",
+		"self":       "predicate('self') || true",
+	}
+
+	tests := []struct {
+		name        string
+		expression  string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:       "No predicates",
+			expression: "rarity == 'common'",
+			expected:   "rarity == 'common'",
+		},
+		{
+			name:       "Single quoted",
+			expression: "predicate('isBulk') && rarity == 'common'",
+			expected:   "(prices.usd < 0.25) && rarity == 'common'",
+		},
+		{
+			name:       "Double quoted with spaces",
+			expression: `predicate( "isBulk" )`,
+			expected:   "(prices.usd < 0.25)",
+		},
+		{
+			name:       "Nested",
+			expression: "predicate('isCheapRed')",
+			expected:   `((prices.usd < 0.25) && hasColor("R"))`,
+		},
+		{
+			name:        "Unknown predicate",
+			expression:  "predicate('missing')",
+			expectedErr: ErrUnknownPredicate,
+		},
+		{
+			name:        "Mutual cycle",
+			expression:  "predicate('cycleA')",
+			expectedErr: ErrPredicateCycle,
+		},
+		{
+			name:        "Self reference",
+			expression:  "predicate('self')",
+			expectedErr: ErrPredicateCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExpandPredicates(tt.expression, predicates)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestExpandPredicates_TooLong(t *testing.T) {
+	// Each level doubles the expansion size
+	predicates := map[string]string{"p0": strings.Repeat("x", 100) + " == ''"}
+	for i := 1; i <= 10; i++ {
+		prev := fmt.Sprintf("p%d", i-1)
+		predicates[fmt.Sprintf("p%d", i)] = fmt.Sprintf("predicate('%s') || predicate('%s')", prev, prev)
+	}
+
+	_, err := ExpandPredicates("predicate('p10')", predicates)
+	if err == nil {
+		t.Error("expected error for oversized expansion")
+	}
+}
+
+func TestReferencedPredicates(t *testing.T) {
+	refs := ReferencedPredicates(`predicate('a') && predicate("b") || predicate('a')`)
+	if len(refs) != 2 || refs[0] != "a" || refs[1] != "b" {
+		t.Errorf("expected [a b], got %v", refs)
+	}
+
+	if refs := ReferencedPredicates("rarity == 'rare'"); len(refs) != 0 {
+		t.Errorf("expected no references, got %v", refs)
+	}
+}
+
+func setupPredicateTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.SortingRule{}, &models.NamedPredicate{}); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+
+	db.Create(&models.NamedPredicate{Name: "isBulk", Expression: "prices.usd < 0.25"})
+	return db
+}
+
+func TestEvaluateExpression_WithPredicate(t *testing.T) {
+	db := setupPredicateTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	cardData := map[string]interface{}{
+		"rarity": "common",
+		"prices": map[string]interface{}{"usd": 0.10},
+	}
+
+	result, err := evaluator.EvaluateExpression("predicate('isBulk') && rarity == 'common'", cardData)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if !result {
+		t.Error("expected expression to evaluate to true")
+	}
+
+	if _, err := evaluator.EvaluateExpression("predicate('missing')", cardData); err == nil {
+		t.Error("expected error for unknown predicate")
+	}
+}
+
+func TestEvaluateCard_RuleUsingPredicate(t *testing.T) {
+	db := setupPredicateTestDB(t)
+	location := createTestLocation(t, db)
+	createTestRule(t, db, "Bulk", 1, "predicate('isBulk')", location.ID, true)
+
+	evaluator := NewEvaluator(db)
+	cardData := map[string]interface{}{
+		"prices": map[string]interface{}{"usd": 0.05},
+	}
+
+	result, err := evaluator.EvaluateCard(t.Context(), cardData)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if result.ID != location.ID {
+		t.Errorf("expected location %d, got %d", location.ID, result.ID)
+	}
+}
+
+func TestValidateExpression_WithPredicate(t *testing.T) {
+	db := setupPredicateTestDB(t)
+	evaluator := NewEvaluator(db)
+
+	if err := evaluator.ValidateExpression("predicate('isBulk') && rarity == 'common'"); err != nil {
+		t.Errorf("expected valid expression, got %v", err)
+	}
+	if err := evaluator.ValidateExpression("predicate('missing')"); !errors.Is(err, ErrUnknownPredicate) {
+		t.Errorf("expected ErrUnknownPredicate, got %v", err)
+	}
+}
+
+func TestValidatePredicate(t *testing.T) {
+	db := setupPredicateTestDB(t)
+	db.Create(&models.NamedPredicate{Name: "usesBulk", Expression: "predicate('isBulk') && rarity == 'common'"})
+
+	tests := []struct {
+		name        string
+		predName    string
+		expression  string
+		expectedErr error
+		expectError bool
+	}{
+		{name: "Valid new predicate", predName: "isRare", expression: "rarity == 'rare'"},
+		{name: "Valid reference", predName: "isBulkRare", expression: "predicate('isBulk') && rarity == 'rare'"},
+		{name: "Invalid syntax", predName: "broken", expression: "rarity ==", expectError: true},
+		{name: "Non-boolean", predName: "cardName", expression: "name", expectError: true},
+		{name: "Unknown reference", predName: "dangling", expression: "predicate('nope')", expectedErr: ErrUnknownPredicate},
+		{name: "Introduces cycle", predName: "isBulk", expression: "predicate('usesBulk')", expectedErr: ErrPredicateCycle},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewEvaluator(db).ValidatePredicate(tt.predName, tt.expression)
+			switch {
+			case tt.expectedErr != nil:
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+			case tt.expectError:
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+			default:
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			}
+		})
+	}
+}
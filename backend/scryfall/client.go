@@ -2,6 +2,7 @@ package scryfall
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -15,6 +16,9 @@ const (
 
 	// DefaultAPITimeout is the default timeout for Scryfall API calls
 	DefaultAPITimeout = 30 * time.Second
+
+	// ResponseCacheTTL is how long search, autocomplete and set list responses are reused
+	ResponseCacheTTL = 5 * time.Minute
 )
 
 // ScryfallAPI defines the interface for Scryfall API operations
@@ -25,20 +29,24 @@ type ScryfallAPI interface {
 	AutocompleteCard(ctx context.Context, s string) ([]string, error)
 }
 
-// Client wraps the Scryfall API client with caching
+// Client wraps the Scryfall API client with caching and the shared rate limiter
 type Client struct {
-	api   ScryfallAPI
-	cache *gocache.Cache
+	api     ScryfallAPI
+	cache   *gocache.Cache
+	limiter *Limiter
 }
 
 // NewClient creates a new Scryfall client with caching
 func NewClient() (*Client, error) {
-	api, err := scryfall.NewClient()
+	// The shared limiter replaces go-scryfall's own, which only paces a single client
+	api, err := scryfall.NewClient(scryfall.WithLimiter(nil))
 	if err != nil {
 		return nil, err
 	}
 
-	return newClientWithAPI(api), nil
+	client := newClientWithAPI(api)
+	client.limiter = sharedLimiter
+	return client, nil
 }
 
 // newClientWithAPI creates a client with a specific API implementation (for testing)
@@ -57,6 +65,27 @@ func (c *Client) Close() {
 	c.cache.StopJanitor()
 }
 
+// Wait blocks until the shared rate limiter allows another request to Scryfall. Callers
+// making their own requests to Scryfall, such as icon downloads, use it to stay under
+// the limit with everything else. A nil *Client doesn't wait.
+func (c *Client) Wait(ctx context.Context) error {
+	if c == nil {
+		return ctx.Err()
+	}
+	return c.limiter.Wait(ctx)
+}
+
+// cached returns the response cached under key, if it is still fresh
+func cached[T any](c *Client, key string) (T, bool) {
+	if value, ok := c.cache.Get(key); ok {
+		if response, ok := value.(T); ok {
+			return response, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 // SearchResult contains paginated search results
 type SearchResult struct {
 	Cards      []scryfall.Card
@@ -78,7 +107,7 @@ func (c *Client) Search(ctx context.Context, query string, page int) (SearchResu
 }
 
 // SearchWithOptions searches Scryfall for cards with custom options.
-// Results are cached by their Scryfall ID.
+// Results are cached by their Scryfall ID, and the whole response for ResponseCacheTTL.
 func (c *Client) SearchWithOptions(ctx context.Context, query string, opts SearchOptions) (SearchResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
@@ -87,6 +116,14 @@ func (c *Client) SearchWithOptions(ctx context.Context, query string, opts Searc
 		opts.Page = 1
 	}
 
+	key := fmt.Sprintf("search:%s:%d:%s", opts.UniqueMode, opts.Page, query)
+	if response, ok := cached[SearchResult](c, key); ok {
+		return response, nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return SearchResult{}, err
+	}
+
 	searchOpts := scryfall.SearchCardsOptions{
 		Page: opts.Page,
 	}
@@ -107,28 +144,57 @@ func (c *Client) SearchWithOptions(ctx context.Context, query string, opts Searc
 		c.cache.SetWithTTL(card.ID, card, CacheTTL)
 	}
 
-	return SearchResult{
+	response := SearchResult{
 		Cards:      result.Cards,
 		TotalCards: result.TotalCards,
 		HasMore:    result.HasMore,
 		Page:       opts.Page,
-	}, nil
+	}
+	c.cache.SetWithTTL(key, response, ResponseCacheTTL)
+	return response, nil
 }
 
-// ListSets retrieves all sets from Scryfall.
+// ListSets retrieves all sets from Scryfall, reusing the list for ResponseCacheTTL.
 func (c *Client) ListSets(ctx context.Context) ([]scryfall.Set, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
-	return c.api.ListSets(ctx)
+	const key = "sets"
+	if sets, ok := cached[[]scryfall.Set](c, key); ok {
+		return sets, nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	sets, err := c.api.ListSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.SetWithTTL(key, sets, ResponseCacheTTL)
+	return sets, nil
 }
 
-// Autocomplete returns card name suggestions for a partial query.
+// Autocomplete returns card name suggestions for a partial query, reusing them for
+// ResponseCacheTTL.
 func (c *Client) Autocomplete(ctx context.Context, query string) ([]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
 	defer cancel()
 
-	return c.api.AutocompleteCard(ctx, query)
+	key := "autocomplete:" + query
+	if names, ok := cached[[]string](c, key); ok {
+		return names, nil
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	names, err := c.api.AutocompleteCard(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.SetWithTTL(key, names, ResponseCacheTTL)
+	return names, nil
 }
 
 // GetByID retrieves a card by its Scryfall ID.
@@ -145,6 +211,9 @@ func (c *Client) GetByID(ctx context.Context, id string) (scryfall.Card, error)
 		slog.Warn("cache type mismatch, refetching", "component", "scryfall", "id", id)
 	}
 
+	if err := c.limiter.Wait(ctx); err != nil {
+		return scryfall.Card{}, err
+	}
 	startTime := time.Now()
 
 	// Fetch from API
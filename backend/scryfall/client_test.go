@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/BlueMonday/go-scryfall"
 )
@@ -307,3 +308,80 @@ func TestGetByID_CachesResult(t *testing.T) {
 		t.Errorf("expected API to be called once, was called %d times", callCount)
 	}
 }
+
+func TestSearch_CachesResponse(t *testing.T) {
+	calls := 0
+	mock := &mockAPI{
+		searchFunc: func(ctx context.Context, query string, opts scryfall.SearchCardsOptions) (scryfall.CardListResponse, error) {
+			calls++
+			return scryfall.CardListResponse{Cards: []scryfall.Card{{ID: "card-1"}}, TotalCards: 1}, nil
+		},
+	}
+
+	client := newClientWithAPI(mock)
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		result, err := client.Search(context.Background(), "bolt", 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.TotalCards != 1 {
+			t.Errorf("expected 1 card, got %d", result.TotalCards)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected a repeated search to reuse the response, got %d API calls", calls)
+	}
+
+	// Another page is another request
+	if _, err := client.Search(context.Background(), "bolt", 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 API calls, got %d", calls)
+	}
+}
+
+func TestSearch_DoesNotCacheErrors(t *testing.T) {
+	calls := 0
+	mock := &mockAPI{
+		searchFunc: func(ctx context.Context, query string, opts scryfall.SearchCardsOptions) (scryfall.CardListResponse, error) {
+			calls++
+			return scryfall.CardListResponse{}, errors.New("api error")
+		},
+	}
+
+	client := newClientWithAPI(mock)
+	defer client.Close()
+
+	client.Search(context.Background(), "bolt", 1)
+	client.Search(context.Background(), "bolt", 1)
+	if calls != 2 {
+		t.Errorf("expected a failed search to be retried, got %d API calls", calls)
+	}
+}
+
+func TestClient_WaitsForLimiter(t *testing.T) {
+	client := newClientWithAPI(&mockAPI{})
+	defer client.Close()
+	client.limiter = NewLimiter(1)
+
+	// The first request takes the only slot this second
+	if _, err := client.GetByID(context.Background(), "card-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetByID(ctx, "card-2"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second request to wait past its deadline, got %v", err)
+	}
+}
+
+func TestClient_Wait_Nil(t *testing.T) {
+	var client *Client
+	if err := client.Wait(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package scryfall
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRequestsPerSecond is Scryfall's requested ceiling for API requests
+const DefaultRequestsPerSecond = 10
+
+// sharedLimiter paces every Client in the process, so parallel imports and on-demand
+// lookups stay under the ceiling together
+var sharedLimiter = NewLimiter(DefaultRequestsPerSecond)
+
+// Limiter spaces requests evenly at a fixed rate. A nil *Limiter doesn't limit.
+type Limiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time // Earliest time the next request may start
+}
+
+// NewLimiter creates a limiter allowing perSecond requests a second
+func NewLimiter(perSecond int) *Limiter {
+	return &Limiter{interval: time.Second / time.Duration(max(perSecond, 1))}
+}
+
+// Wait blocks until a request may start, or returns ctx's error if it ends first
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
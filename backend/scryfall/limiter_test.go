@@ -0,0 +1,44 @@
+package scryfall
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiter_SpacesRequests(t *testing.T) {
+	limiter := NewLimiter(100) // 10ms apart
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// The first request starts immediately, the other four 10ms apart
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected at least 40ms for 5 requests, took %v", elapsed)
+	}
+}
+
+func TestLimiter_WaitCancelled(t *testing.T) {
+	limiter := NewLimiter(1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := limiter.Wait(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	var limiter *Limiter
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// AlertRoutes registers alert history and value alert routes
+func AlertRoutes(app *fiber.App, db *gorm.DB, legality *services.LegalityAlertService) {
+	handler := api.NewAlertsHandler(legality)
+	valueAlerts := api.NewValueAlertsHandler(db)
+
+	alerts := app.Group("/alerts")
+	alerts.Get("/legality", handler.Legality)
+	alerts.Get("/value", valueAlerts.List)
+	alerts.Get("/value/:id", valueAlerts.Get)
+	alerts.Post("/value", valueAlerts.Create)
+	alerts.Put("/value/:id", valueAlerts.Update)
+	alerts.Delete("/value/:id", valueAlerts.Delete)
+}
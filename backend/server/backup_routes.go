@@ -0,0 +1,19 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// BackupRoutes registers database backup routes
+func BackupRoutes(app *fiber.App, service *services.BackupService) {
+	handler := api.NewBackupHandler(service)
+
+	admin := app.Group("/api/admin")
+	admin.Post("/backup", handler.Create)
+	admin.Get("/backups", handler.List)
+	admin.Get("/backups/:name", handler.Download)
+	admin.Post("/restore", handler.Restore)
+}
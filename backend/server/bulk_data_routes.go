@@ -9,11 +9,14 @@ import (
 )
 
 // BulkDataRoutes registers bulk data-related routes
-func BulkDataRoutes(app *fiber.App, service *services.BulkDataService, appCtx context.Context) {
-	handler := api.NewBulkDataHandler(service)
+func BulkDataRoutes(app *fiber.App, service *services.BulkDataService, jobService *services.JobService, appCtx context.Context) {
+	handler := api.NewBulkDataHandler(service, jobService)
 
 	bulkData := app.Group("/api/bulk-data")
 	bulkData.Post("/import", func(c fiber.Ctx) error {
 		return handler.TriggerImport(c, appCtx)
 	})
+	bulkData.Post("/import/:id/resume", func(c fiber.Ctx) error {
+		return handler.ResumeImport(c, appCtx)
+	})
 }
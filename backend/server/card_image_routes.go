@@ -0,0 +1,20 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// CardImageRoutes registers the card image cache routes
+func CardImageRoutes(app *fiber.App, service *services.CardImageService, jobService *services.JobService, appCtx context.Context) {
+	handler := api.NewCardImageHandler(service, jobService)
+
+	images := app.Group("/images")
+	images.Get("/cards/:scryfall_id", handler.GetCardImage)
+	images.Post("/prefetch", func(c fiber.Ctx) error {
+		return handler.Prefetch(c, appCtx)
+	})
+}
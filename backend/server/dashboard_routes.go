@@ -2,13 +2,19 @@ package server
 
 import (
 	"backend/api"
+	"backend/services"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
 // DashboardRoutes registers dashboard-related routes
-func DashboardRoutes(app *fiber.App, db *gorm.DB) {
+func DashboardRoutes(app *fiber.App, db *gorm.DB, cache *services.DashboardCacheService) {
 	handler := api.NewDashboardHandler(db)
+	handler.SetCache(cache)
 	app.Get("/api/dashboard/stats", handler.GetStats)
+	app.Get("/stats/collection", handler.GetCollectionStats)
+	app.Get("/api/dashboard/counts-history", handler.GetCountsHistory)
+	app.Get("/api/dashboard/widgets", handler.GetWidgets)
+	app.Put("/api/dashboard/widgets", handler.UpdateWidgets)
 }
@@ -2,14 +2,15 @@ package server
 
 import (
 	"backend/api"
+	"os"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
 // DataRoutes registers data import and export routes
-func DataRoutes(app *fiber.App, db *gorm.DB) {
-	handler := api.NewDataHandler(db)
+func DataRoutes(app *fiber.App, db *gorm.DB, exports *api.ExportFiles) {
+	handler := api.NewDataHandler(db, os.Getenv("EXPORT_ENCRYPTION_PASSPHRASE"), exports)
 
 	data := app.Group("/api/data")
 	data.Get("/export", handler.Export)
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// DeckRoutes registers deck routes
+func DeckRoutes(app *fiber.App, db *gorm.DB) {
+	handler := api.NewDecksHandler(db, services.NewDeckService(db))
+
+	decks := app.Group("/decks")
+	decks.Get("/", handler.List)
+	decks.Get("/:id", handler.Get)
+	decks.Post("/", handler.Create)
+	decks.Put("/:id", handler.Update)
+	decks.Delete("/:id", handler.Delete)
+	decks.Get("/:id/legality", handler.Legality)
+	decks.Get("/:id/coverage", handler.Coverage)
+	decks.Post("/:id/cards", handler.AddCard)
+	decks.Put("/:id/cards/:card_id", handler.UpdateCard)
+	decks.Delete("/:id/cards/:card_id", handler.RemoveCard)
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// HistoryRoutes registers inventory history routes
+func HistoryRoutes(app *fiber.App, events *services.InventoryEventService) {
+	handler := api.NewHistoryHandler(events)
+
+	app.Get("/history", handler.List)
+	app.Get("/inventory/:id/history", handler.ForInventory)
+}
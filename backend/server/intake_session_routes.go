@@ -0,0 +1,24 @@
+package server
+
+import (
+	"backend/api"
+	"backend/realtime"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// IntakeSessionRoutes registers intake session routes
+func IntakeSessionRoutes(app *fiber.App, db *gorm.DB, hub *realtime.Hub) {
+	handler := api.NewIntakeSessionsHandler(db, services.NewIntakeSessionService(db), hub)
+
+	sessions := app.Group("/intake-sessions")
+	sessions.Get("/", handler.List)
+	sessions.Post("/", handler.Create)
+	sessions.Get("/:id", handler.Get)
+	sessions.Post("/:id/items", handler.AddItems)
+	sessions.Delete("/:id/items/:item_id", handler.RemoveItem)
+	sessions.Post("/:id/commit", handler.Commit)
+	sessions.Post("/:id/abort", handler.Abort)
+}
@@ -2,27 +2,48 @@ package server
 
 import (
 	"backend/api"
+	"backend/realtime"
 	"backend/services"
+	"context"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
 )
 
 // InventoryRoutes registers inventory routes
-func InventoryRoutes(app *fiber.App, db *gorm.DB) {
+func InventoryRoutes(app *fiber.App, db *gorm.DB, undoSvc *services.UndoService, jobService *services.JobService, hub *realtime.Hub, exports *api.ExportFiles, appCtx context.Context) {
 	autoSortSvc := services.NewAutoSortService(db)
-	handler := api.NewInventoryHandler(db, autoSortSvc)
+	handler := api.NewInventoryHandler(db, autoSortSvc, undoSvc)
+	handler.SetHub(hub)
+	handler.SetJobService(jobService)
+	handler.SetExportFiles(exports)
+	importHandler := api.NewInventoryImportHandler(db, services.NewImportService(db, jobService), jobService)
 
 	inventory := app.Group("/inventory")
 	inventory.Get("/", handler.List)
 	inventory.Get("/cards", handler.ListAsCards)
 	inventory.Get("/unassigned/count", handler.GetUnassignedCount)
+	inventory.Get("/unassigned/suggestions", handler.UnassignedSuggestions)
+	inventory.Get("/consolidation-suggestions", handler.ConsolidationSuggestions)
+	inventory.Get("/serialized", handler.Serialized)
+	inventory.Get("/duplicates", handler.Duplicates)
+	inventory.Get("/duplicates/export", handler.ExportDuplicates)
+	inventory.Get("/trash", handler.Trash)
+	inventory.Get("/export.ndjson", handler.ExportNDJSON)
 	inventory.Get("/by-oracle/:oracle_id", handler.ByOracle)
+	inventory.Post("/batch/add", handler.BatchAdd)
 	inventory.Post("/batch/move", handler.BatchMove)
 	inventory.Delete("/batch", handler.BatchDelete)
-	inventory.Post("/resort", handler.Resort)
+	inventory.Post("/resort", func(c fiber.Ctx) error {
+		return handler.Resort(c, appCtx)
+	})
+	inventory.Post("/import-text", handler.ImportText)
+	inventory.Post("/import", func(c fiber.Ctx) error {
+		return importHandler.Import(c, appCtx)
+	})
 	inventory.Get("/:id", handler.Get)
 	inventory.Post("/", handler.Create)
 	inventory.Put("/:id", handler.Update)
 	inventory.Delete("/:id", handler.Delete)
+	inventory.Post("/:id/restore", handler.Restore)
 }
@@ -3,16 +3,26 @@ package server
 import (
 	"backend/api"
 	"backend/services"
+	"context"
 
 	"github.com/gofiber/fiber/v3"
 )
 
 // JobsRoutes registers job-related routes
-func JobsRoutes(app *fiber.App, service *services.JobService) {
+func JobsRoutes(app *fiber.App, service *services.JobService, digests *services.ImportDigestService, exports *api.ExportFiles, appCtx context.Context) {
 	handler := api.NewJobsHandler(service)
+	handler.SetExportFiles(exports)
+	digestHandler := api.NewImportDigestHandler(digests)
 
 	jobs := app.Group("/api/jobs")
 	jobs.Get("/", handler.GetAll)
+	jobs.Get("/export", handler.Export)
 	jobs.Get("/:id", handler.Get)
+	jobs.Get("/:id/digest", digestHandler.Get)
+	jobs.Get("/:id/result", handler.Result)
+	jobs.Post("/:id/cancel", handler.Cancel)
+	jobs.Post("/:id/retry", func(c fiber.Ctx) error {
+		return handler.Retry(c, appCtx)
+	})
 	jobs.Delete("/cleanup", handler.Cleanup)
 }
@@ -13,14 +13,33 @@ func ListRoutes(app *fiber.App, db *gorm.DB) {
 
 	lists := app.Group("/lists")
 	lists.Get("/", handler.List)
+	lists.Get("/contention", handler.Contention)
 	lists.Get("/:id", handler.Get)
 	lists.Post("/", handler.Create)
 	lists.Put("/:id", handler.Update)
 	lists.Delete("/:id", handler.Delete)
 
+	lists.Get("/:id/analysis", handler.Analyze)
+	lists.Get("/:id/export", handler.Export)
+	lists.Post("/:id/sync", handler.Sync)
+	lists.Post("/:id/duplicate", handler.Duplicate)
+	lists.Post("/:id/merge", handler.Merge)
+
 	// List item routes
 	lists.Get("/:id/items", handler.ListItems)
 	lists.Post("/:id/items/batch", handler.CreateItemsBatch)
+	lists.Post("/:id/items/parse", handler.ParseItems)
 	lists.Put("/:id/items/:item_id", handler.UpdateItem)
+	lists.Post("/:id/items/:item_id/collect", handler.Collect)
 	lists.Delete("/:id/items/:item_id", handler.DeleteItem)
+
+	// Collaborator invites
+	lists.Get("/:id/shares", handler.ListShares)
+	lists.Post("/:id/shares", handler.CreateShare)
+	lists.Delete("/:id/shares/:share_id", handler.RevokeShare)
+
+	shared := app.Group("/shared")
+	shared.Get("/:token", handler.GetShared)
+	shared.Get("/:token/items", handler.SharedListItems)
+	shared.Put("/:token/items/:item_id", handler.UpdateSharedItem)
 }
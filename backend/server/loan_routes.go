@@ -0,0 +1,21 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// LoanRoutes registers loan routes
+func LoanRoutes(app *fiber.App, service *services.LoanService) {
+	handler := api.NewLoansHandler(service)
+
+	loans := app.Group("/loans")
+	loans.Get("/", handler.List)
+	// Registered before /:id so "overdue" is not taken as a loan ID
+	loans.Get("/overdue", handler.Overdue)
+	loans.Get("/:id", handler.Get)
+	loans.Post("/", handler.Create)
+	loans.Post("/:id/return", handler.Return)
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// MaintenanceRoutes registers database maintenance routes
+func MaintenanceRoutes(app *fiber.App, service *services.MaintenanceService, jobService *services.JobService, appCtx context.Context) {
+	handler := api.NewMaintenanceHandler(service, jobService)
+
+	maintenance := app.Group("/api/maintenance")
+	maintenance.Post("/reindex", func(c fiber.Ctx) error {
+		return handler.Reindex(c, appCtx)
+	})
+
+	db := app.Group("/api/admin/db")
+	db.Post("/vacuum", handler.Vacuum)
+	db.Post("/analyze", handler.Analyze)
+	db.Get("/integrity", handler.Integrity)
+}
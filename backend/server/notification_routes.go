@@ -0,0 +1,18 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// NotificationRoutes registers notification routes
+func NotificationRoutes(app *fiber.App, service *services.NotificationService) {
+	handler := api.NewNotificationsHandler(service)
+
+	notifications := app.Group("/notifications")
+	notifications.Get("/", handler.List)
+	notifications.Put("/:id/read", handler.MarkRead)
+	notifications.Post("/channels/test", handler.TestChannels)
+}
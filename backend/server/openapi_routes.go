@@ -0,0 +1,22 @@
+package server
+
+import (
+	"backend/api/openapi"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// OpenAPIRoutes serves the API description at /openapi.json and, when swaggerUI is
+// set, a Swagger UI page at /docs. Register it after every other route.
+func OpenAPIRoutes(app *fiber.App, version string, swaggerUI bool) {
+	handler := openapi.NewHandler(app, openapi.Info{
+		Title:       "ShowMyCards API",
+		Version:     version,
+		Description: "Card collection management API. There is no authentication; every endpoint is open to whoever can reach the server.",
+	})
+
+	app.Get("/openapi.json", handler.Document)
+	if swaggerUI {
+		app.Get("/docs", handler.SwaggerUI)
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"backend/api/openapi"
+	"backend/database"
+	"backend/scryfall"
+	"backend/services"
+)
+
+func setupTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	dbClient, err := database.NewClient(filepath.Join(dataDir, "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	t.Cleanup(func() { dbClient.Close() })
+
+	scryfallClient, err := scryfall.NewClient()
+	if err != nil {
+		t.Fatalf("failed to create scryfall client: %v", err)
+	}
+	t.Cleanup(scryfallClient.Close)
+
+	db := dbClient.DB
+	settings := services.NewSettingsService(db)
+	jobs := services.NewJobService(db)
+	notifications := services.NewNotificationService(db)
+	s := NewServer(context.Background(), dbClient, scryfallClient, settings, jobs,
+		services.NewBulkDataService(db, jobs, settings),
+		services.NewSetDataService(db, jobs, settings, scryfallClient, dataDir),
+		services.NewLoanService(db, notifications), notifications, services.NewBackupService(db, jobs, dataDir),
+		services.NewCardImageService(db, jobs, dataDir), services.NewDashboardCacheService(db),
+		services.NewWebhookService(db), dataDir)
+	s.setupRoutes()
+	return s
+}
+
+func TestOpenAPI_EveryRouteDocumented(t *testing.T) {
+	s := setupTestServer(t)
+
+	if missing := openapi.MissingSpecs(s.app, openapi.Operations()); len(missing) > 0 {
+		t.Errorf("routes without an entry in openapi.Operations: %v", missing)
+	}
+}
+
+func TestOpenAPI_Document(t *testing.T) {
+	s := setupTestServer(t)
+
+	resp, err := s.app.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	var doc openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if doc.OpenAPI != openapi.Version || doc.Info.Title == "" {
+		t.Errorf("unexpected document header: %s %+v", doc.OpenAPI, doc.Info)
+	}
+
+	op := doc.Paths["/lists/{id}/items"]["get"]
+	if op == nil {
+		t.Fatal("expected GET /lists/{id}/items in the document")
+	}
+	if len(op.Parameters) == 0 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" {
+		t.Errorf("expected the id path parameter first, got %+v", op.Parameters)
+	}
+	if ref := op.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/ListItemsResponse" {
+		t.Errorf("expected the items response schema, got %q", ref)
+	}
+	if _, ok := doc.Components.Schemas["EnrichedListItem"]; !ok {
+		t.Error("expected referenced schemas in components")
+	}
+
+	// Swagger UI is off unless SWAGGER_UI is set
+	resp, err = s.app.Test(httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected /docs to be off by default, got %d", resp.StatusCode)
+	}
+}
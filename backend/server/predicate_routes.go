@@ -0,0 +1,20 @@
+package server
+
+import (
+	"backend/api"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// PredicateRoutes registers named predicate routes
+func PredicateRoutes(app *fiber.App, db *gorm.DB) {
+	handler := api.NewPredicatesHandler(db)
+
+	predicates := app.Group("/predicates")
+	predicates.Get("/", handler.List)
+	predicates.Get("/:id", handler.Get)
+	predicates.Post("/", handler.Create)
+	predicates.Put("/:id", handler.Update)
+	predicates.Delete("/:id", handler.Delete)
+}
@@ -0,0 +1,12 @@
+package server
+
+import (
+	"backend/realtime"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RealtimeRoutes registers the WebSocket change feed
+func RealtimeRoutes(app *fiber.App, hub *realtime.Hub) {
+	app.Get("/ws", hub.Handler)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"backend/utils"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// maxRequestIDLength bounds incoming X-Request-ID values that are kept
+const maxRequestIDLength = 64
+
+// requestLogger assigns each request an ID (keeping a valid incoming X-Request-ID, e.g.
+// from a reverse proxy), returns it in the response header, and logs the method, path,
+// status and duration once the request is done. Service logs written with the request
+// context carry the same request_id.
+func requestLogger() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		start := time.Now()
+
+		id := c.Get(utils.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		utils.SetRequestID(c, id)
+		c.Set(utils.RequestIDHeader, id)
+
+		// Run the error handler here, as Fiber's logger does, so the logged status is final
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(c.RequestCtx(), level, "request",
+			"component", "http",
+			"method", c.Method(),
+			"path", c.Path(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds())
+		return nil
+	}
+}
+
+// newRequestID returns a random 16-character hex ID
+func newRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short IDs of letters, digits, '-', '_' and '.', so a client
+// cannot inject arbitrary text into logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
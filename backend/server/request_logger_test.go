@@ -0,0 +1,113 @@
+package server
+
+import (
+	"backend/utils"
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// captureLogs routes the default logger into a buffer for the rest of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(utils.NewRequestIDLogHandler(slog.NewTextHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func setupRequestLoggerApp() *fiber.App {
+	app := fiber.New()
+	app.Use(requestLogger())
+	app.Get("/ok", func(c fiber.Ctx) error {
+		// Stands in for a service logging with the context it was handed
+		slog.InfoContext(c.RequestCtx(), "service work")
+		return c.SendString("ok")
+	})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return errors.New("boom")
+	})
+	return app
+}
+
+func TestRequestLogger_CorrelatesLogs(t *testing.T) {
+	logs := captureLogs(t)
+	app := setupRequestLoggerApp()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	id := resp.Header.Get(utils.RequestIDHeader)
+	if len(id) != 16 {
+		t.Fatalf("expected a generated request ID, got %q", id)
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a service line and a request line, got %q", logs.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "request_id="+id) {
+			t.Errorf("expected request_id=%s in %q", id, line)
+		}
+	}
+	for _, want := range []string{"msg=request", "method=GET", "path=/ok", "status=200", "duration_ms="} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected %q in %q", want, lines[1])
+		}
+	}
+}
+
+func TestRequestLogger_IncomingID(t *testing.T) {
+	captureLogs(t)
+	app := setupRequestLoggerApp()
+
+	tests := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{"Valid", "proxy-id_1.2", true},
+		{"Invalid characters", "bad id\nlevel=ERROR", false},
+		{"Too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+			req.Header.Set(utils.RequestIDHeader, tt.incoming)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if got := resp.Header.Get(utils.RequestIDHeader); (got == tt.incoming) != tt.kept || got == "" {
+				t.Errorf("incoming %q: got request ID %q", tt.incoming, got)
+			}
+		})
+	}
+}
+
+func TestRequestLogger_ErrorStatus(t *testing.T) {
+	logs := captureLogs(t)
+	app := setupRequestLoggerApp()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if !strings.Contains(logs.String(), "level=ERROR msg=request") || !strings.Contains(logs.String(), "status=500") {
+		t.Errorf("expected the failed request logged as an error with its final status, got %q", logs.String())
+	}
+}
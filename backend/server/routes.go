@@ -8,7 +8,9 @@ import (
 )
 
 // HealthRoutes registers health check routes
-func HealthRoutes(app *fiber.App, db *gorm.DB, version string) {
-	handler := api.NewHealthHandler(db, version)
+func HealthRoutes(app *fiber.App, db *gorm.DB, version, dataDir string) {
+	handler := api.NewHealthHandler(db, version, dataDir)
 	app.Get("/health", handler.Check)
+	app.Get("/health/live", handler.Live)
+	app.Get("/health/ready", handler.Ready)
 }
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"backend/api"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// RuleGroupRoutes registers rule group routes
+func RuleGroupRoutes(app *fiber.App, db *gorm.DB) {
+	handler := api.NewRuleGroupsHandler(db)
+
+	groups := app.Group("/rule-groups")
+	groups.Get("/", handler.List)
+	groups.Get("/:id", handler.Get)
+	groups.Post("/", handler.Create)
+	groups.Put("/:id", handler.Update)
+	groups.Delete("/:id", handler.Delete)
+}
@@ -8,8 +8,11 @@ import (
 
 // RegisterSchedulerRoutes registers scheduler-related routes
 func (s *Server) RegisterSchedulerRoutes(app *fiber.App) {
-	schedulerHandler := api.NewSchedulerHandler(s.settingsService, s.jobService)
+	schedulerHandler := api.NewSchedulerHandler(s.scheduler, s.jobService)
 
 	scheduler := app.Group("/api/scheduler")
 	scheduler.Get("/tasks", schedulerHandler.GetScheduledTasks)
+	scheduler.Post("/tasks/:name/run", func(c fiber.Ctx) error {
+		return schedulerHandler.RunTask(c, s.appCtx)
+	})
 }
@@ -15,5 +15,8 @@ func SearchRoutes(app *fiber.App, client *scryfall.Client, db *gorm.DB, settings
 
 	app.Get("/search", handler.Search)
 	app.Get("/search/autocomplete", handler.Autocomplete)
+	// Registered before /cards/:id so "search" and "lookup" are not taken as card IDs
+	app.Get("/cards/search", handler.SearchLocal)
+	app.Get("/cards/lookup", handler.LookupCard)
 	app.Get("/cards/:id", handler.GetCard)
 }
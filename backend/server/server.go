@@ -1,9 +1,12 @@
 package server
 
 import (
+	"backend/api"
 	"backend/database"
+	"backend/realtime"
 	"backend/scryfall"
 	"backend/services"
+	"backend/utils"
 	"backend/version"
 	"context"
 	"fmt"
@@ -15,7 +18,6 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
-	"github.com/gofiber/fiber/v3/middleware/logger"
 )
 
 // Server holds the main application components
@@ -27,19 +29,27 @@ type Server struct {
 	jobService      *services.JobService
 	bulkDataService *services.BulkDataService
 	setDataService  *services.SetDataService
+	loanService     *services.LoanService
+	notificationSvc *services.NotificationService
+	backupService   *services.BackupService
+	cardImages      *services.CardImageService
+	dashboardCache  *services.DashboardCacheService
+	webhooks        *services.WebhookService
+	scheduler       *services.Scheduler
+	hub             *realtime.Hub
 	dataDir         string
 	appCtx          context.Context
 }
 
 // NewServer creates a new server instance
-func NewServer(appCtx context.Context, dbClient *database.Client, scryfallClient *scryfall.Client, settingsService *services.SettingsService, jobService *services.JobService, bulkDataService *services.BulkDataService, setDataService *services.SetDataService, dataDir string) *Server {
+func NewServer(appCtx context.Context, dbClient *database.Client, scryfallClient *scryfall.Client, settingsService *services.SettingsService, jobService *services.JobService, bulkDataService *services.BulkDataService, setDataService *services.SetDataService, loanService *services.LoanService, notificationService *services.NotificationService, backupService *services.BackupService, cardImageService *services.CardImageService, dashboardCache *services.DashboardCacheService, webhookService *services.WebhookService, dataDir string) *Server {
 	app := fiber.New(fiber.Config{
 		BodyLimit:    50 * 1024 * 1024, // 50MB — raised from 4MB for /data/import (fasthttp enforces globally)
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 		ErrorHandler: func(c fiber.Ctx, err error) error {
-			slog.Error("request failed", "method", c.Method(), "path", c.Path(), "error", err)
+			slog.ErrorContext(c.RequestCtx(), "request failed", "method", c.Method(), "path", c.Path(), "error", err)
 			code := fiber.StatusInternalServerError
 			if e, ok := err.(*fiber.Error); ok {
 				code = e.Code
@@ -51,7 +61,7 @@ func NewServer(appCtx context.Context, dbClient *database.Client, scryfallClient
 	})
 
 	// Middleware
-	app.Use(logger.New())
+	app.Use(requestLogger())
 	// Default allows the standard SvelteKit dev server origin.
 	// Override via ALLOWED_ORIGINS env var (comma-separated) for production deployments.
 	allowedOrigins := []string{"http://localhost:5173"}
@@ -65,11 +75,16 @@ func NewServer(appCtx context.Context, dbClient *database.Client, scryfallClient
 		}
 	}
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: allowedOrigins,
-		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders: []string{"Content-Type"},
+		AllowOrigins:  allowedOrigins,
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Content-Type", utils.APIVersionHeader, utils.RequestIDHeader},
+		ExposeHeaders: []string{utils.RequestIDHeader},
 	}))
 
+	// Pushes inventory and job changes to open browser tabs
+	hub := realtime.NewHub()
+	jobService.SetHub(hub)
+
 	return &Server{
 		app:             app,
 		db:              dbClient,
@@ -78,11 +93,24 @@ func NewServer(appCtx context.Context, dbClient *database.Client, scryfallClient
 		jobService:      jobService,
 		bulkDataService: bulkDataService,
 		setDataService:  setDataService,
+		loanService:     loanService,
+		notificationSvc: notificationService,
+		backupService:   backupService,
+		cardImages:      cardImageService,
+		dashboardCache:  dashboardCache,
+		webhooks:        webhookService,
+		hub:             hub,
 		dataDir:         dataDir,
 		appCtx:          appCtx,
 	}
 }
 
+// SetScheduler exposes the scheduler's tasks through the scheduler routes. It must be
+// called before Start.
+func (s *Server) SetScheduler(scheduler *services.Scheduler) {
+	s.scheduler = scheduler
+}
+
 // Start initializes and starts the server
 func (s *Server) Start() error {
 	// Setup routes
@@ -103,21 +131,47 @@ func (s *Server) Start() error {
 
 // Close shuts down the server gracefully
 func (s *Server) Close() error {
+	// Hijacked WebSocket connections aren't closed by Shutdown
+	s.hub.Close()
 	return s.app.Shutdown()
 }
 
 func (s *Server) setupRoutes() {
-	HealthRoutes(s.app, s.db.DB, version.Version)
-	DashboardRoutes(s.app, s.db.DB)
-	StorageRoutes(s.app, s.db.DB)
+	// Undo snapshots are shared between the inventory batch endpoints and /undo
+	undoSvc := services.NewUndoService(s.db.DB)
+	undoSvc.SetWebhooks(s.webhooks)
+	// Rendered downloads are shared by the data, inventory and job exports
+	exports := api.NewExportFiles(s.dataDir, os.Getenv("EXPORT_ENCRYPTION_PASSPHRASE"))
+
+	HealthRoutes(s.app, s.db.DB, version.Version, s.dataDir)
+	DashboardRoutes(s.app, s.db.DB, s.dashboardCache)
+	StorageRoutes(s.app, s.db.DB, s.dataDir)
 	SortingRulesRoutes(s.app, s.db.DB)
-	InventoryRoutes(s.app, s.db.DB)
+	PredicateRoutes(s.app, s.db.DB)
+	RuleGroupRoutes(s.app, s.db.DB)
+	TagRoutes(s.app, s.db.DB)
+	InventoryRoutes(s.app, s.db.DB, undoSvc, s.jobService, s.hub, exports, s.appCtx)
 	ListRoutes(s.app, s.db.DB)
+	DeckRoutes(s.app, s.db.DB)
+	ShareLinkRoutes(s.app, s.db.DB)
 	SearchRoutes(s.app, s.scryfall, s.db.DB, s.settingsService)
 	SettingsRoutes(s.app, s.settingsService)
-	JobsRoutes(s.app, s.jobService)
-	DataRoutes(s.app, s.db.DB)
-	BulkDataRoutes(s.app, s.bulkDataService, s.appCtx)
-	SetRoutes(s.app, s.db.DB, s.setDataService, s.dataDir, s.appCtx)
+	JobsRoutes(s.app, s.jobService, services.NewImportDigestService(s.db.DB, s.notificationSvc), exports, s.appCtx)
+	DataRoutes(s.app, s.db.DB, exports)
+	BulkDataRoutes(s.app, s.bulkDataService, s.jobService, s.appCtx)
+	MaintenanceRoutes(s.app, services.NewMaintenanceService(s.db.DB, s.jobService), s.jobService, s.appCtx)
+	BackupRoutes(s.app, s.backupService)
+	SetRoutes(s.app, s.db.DB, s.setDataService, s.jobService, s.dataDir, s.appCtx)
+	CardImageRoutes(s.app, s.cardImages, s.jobService, s.appCtx)
+	LoanRoutes(s.app, s.loanService)
+	IntakeSessionRoutes(s.app, s.db.DB, s.hub)
+	TransactionRoutes(s.app, s.db.DB, s.hub)
+	NotificationRoutes(s.app, s.notificationSvc)
+	AlertRoutes(s.app, s.db.DB, services.NewLegalityAlertService(s.db.DB, s.notificationSvc))
+	UndoRoutes(s.app, undoSvc)
+	HistoryRoutes(s.app, services.NewInventoryEventService(s.db.DB))
+	WebhookRoutes(s.app, s.db.DB, s.webhooks)
+	RealtimeRoutes(s.app, s.hub)
 	s.RegisterSchedulerRoutes(s.app)
+	OpenAPIRoutes(s.app, version.Version, os.Getenv("SWAGGER_UI") == "true")
 }
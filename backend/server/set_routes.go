@@ -10,14 +10,18 @@ import (
 )
 
 // SetRoutes registers set routes
-func SetRoutes(app *fiber.App, db *gorm.DB, setDataService *services.SetDataService, dataDir string, appCtx context.Context) {
-	handler := api.NewSetHandler(db, setDataService, dataDir)
+func SetRoutes(app *fiber.App, db *gorm.DB, setDataService *services.SetDataService, jobService *services.JobService, dataDir string, appCtx context.Context) {
+	handler := api.NewSetHandler(db, setDataService, jobService, dataDir)
 
 	sets := app.Group("/sets")
 	sets.Get("/", handler.List)
+	sets.Get("/completion", handler.CompletionSummary)
 	sets.Get("/id/:id", handler.GetByID)
 	sets.Get("/code/:code", handler.GetByCode)
 	sets.Get("/code/:code/icon", handler.GetIcon)
+	sets.Get("/code/:code/completion", handler.Completion)
+	sets.Post("/code/:code/generate-list", handler.GenerateList)
+	sets.Post("/preview-cards/:id", handler.FetchPreviewCard)
 	sets.Post("/import", func(c fiber.Ctx) error {
 		return handler.TriggerImport(c, appCtx)
 	})
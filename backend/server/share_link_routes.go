@@ -0,0 +1,22 @@
+package server
+
+import (
+	"backend/api"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ShareLinkRoutes registers read-only share link routes
+func ShareLinkRoutes(app *fiber.App, db *gorm.DB) {
+	handler := api.NewShareLinkHandler(db)
+
+	links := app.Group("/share-links")
+	links.Get("/", handler.List)
+	links.Post("/", handler.Create)
+	links.Delete("/:id", handler.Revoke)
+
+	share := app.Group("/share")
+	share.Get("/:token", handler.GetShared)
+	share.Get("/:token/items", handler.SharedItems)
+}
@@ -13,6 +13,8 @@ func SortingRulesRoutes(app *fiber.App, db *gorm.DB) {
 
 	rules := app.Group("/sorting-rules")
 	rules.Get("/", handler.List)
+	rules.Get("/performance", handler.Performance)
+	rules.Get("/helpers", handler.Helpers)
 	rules.Get("/:id", handler.Get)
 	rules.Post("/", handler.Create)
 	rules.Put("/:id", handler.Update)
@@ -20,8 +22,11 @@ func SortingRulesRoutes(app *fiber.App, db *gorm.DB) {
 
 	// Batch operations
 	rules.Post("/batch/priorities", handler.BatchUpdatePriorities)
+	rules.Post("/reorder", handler.Reorder)
+	rules.Post("/:id/move", handler.Move)
 
 	// Evaluation endpoints
 	rules.Post("/evaluate", handler.Evaluate)
 	rules.Post("/validate", handler.ValidateExpression)
+	rules.Post("/test", handler.Test)
 }
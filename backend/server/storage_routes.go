@@ -8,14 +8,23 @@ import (
 )
 
 // StorageRoutes registers storage location routes
-func StorageRoutes(app *fiber.App, db *gorm.DB) {
-	handler := api.NewStorageHandler(db)
+func StorageRoutes(app *fiber.App, db *gorm.DB, dataDir string) {
+	handler := api.NewStorageHandler(db, dataDir)
 
 	storage := app.Group("/storage")
 	storage.Get("/", handler.List)
 	storage.Get("/with-counts", handler.ListWithCounts)
+	storage.Get("/tree", handler.Tree)
+	// Registered before /:id so "resolve" is not taken as a location ID
+	storage.Get("/resolve", handler.ResolveLabel)
 	storage.Get("/:id", handler.Get)
 	storage.Post("/", handler.Create)
 	storage.Put("/:id", handler.Update)
 	storage.Delete("/:id", handler.Delete)
+	storage.Post("/:id/move", handler.Move)
+	storage.Get("/:id/binder-layout", handler.BinderLayout)
+	storage.Get("/:id/label", handler.Label)
+	storage.Get("/:id/photo", handler.GetPhoto)
+	storage.Put("/:id/photo", handler.UploadPhoto)
+	storage.Delete("/:id/photo", handler.DeletePhoto)
 }
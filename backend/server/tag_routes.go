@@ -0,0 +1,23 @@
+package server
+
+import (
+	"backend/api"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// TagRoutes registers tag routes and the routes tagging inventory items
+func TagRoutes(app *fiber.App, db *gorm.DB) {
+	handler := api.NewTagsHandler(db)
+
+	tags := app.Group("/tags")
+	tags.Get("/", handler.List)
+	tags.Get("/:id", handler.Get)
+	tags.Post("/", handler.Create)
+	tags.Put("/:id", handler.Update)
+	tags.Delete("/:id", handler.Delete)
+
+	app.Post("/inventory/:id/tags", handler.AddToItem)
+	app.Delete("/inventory/:id/tags/:tag_id", handler.RemoveFromItem)
+}
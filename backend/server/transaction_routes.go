@@ -0,0 +1,22 @@
+package server
+
+import (
+	"backend/api"
+	"backend/realtime"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// TransactionRoutes registers sale and trade routes
+func TransactionRoutes(app *fiber.App, db *gorm.DB, hub *realtime.Hub) {
+	handler := api.NewTransactionsHandler(services.NewTransactionService(db), hub)
+
+	transactions := app.Group("/transactions")
+	transactions.Get("/", handler.List)
+	transactions.Post("/", handler.Create)
+	// Registered before /:id so "report" is not taken as a transaction ID
+	transactions.Get("/report", handler.Report)
+	transactions.Get("/:id", handler.Get)
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// UndoRoutes registers undo token and recorded operation routes
+func UndoRoutes(app *fiber.App, service *services.UndoService) {
+	handler := api.NewUndoHandler(service)
+
+	undo := app.Group("/undo")
+	undo.Post("/:token", handler.Redeem)
+
+	operations := app.Group("/operations")
+	operations.Get("/", handler.ListOperations)
+	operations.Post("/:id/undo", handler.UndoOperation)
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"backend/api"
+	"backend/services"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// WebhookRoutes registers webhook routes
+func WebhookRoutes(app *fiber.App, db *gorm.DB, service *services.WebhookService) {
+	handler := api.NewWebhooksHandler(db, service)
+
+	webhooks := app.Group("/webhooks")
+	webhooks.Get("/", handler.List)
+	webhooks.Get("/:id", handler.Get)
+	webhooks.Post("/", handler.Create)
+	webhooks.Put("/:id", handler.Update)
+	webhooks.Delete("/:id", handler.Delete)
+	webhooks.Get("/:id/deliveries", handler.Deliveries)
+	webhooks.Post("/:id/test", handler.Test)
+}
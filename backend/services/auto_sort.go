@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"gorm.io/gorm"
 )
@@ -21,8 +22,12 @@ func NewAutoSortService(db *gorm.DB) *AutoSortService {
 }
 
 // DetermineStorageLocation evaluates sorting rules for a card and returns the
-// matched storage location ID, or nil if no rule matches or the card is not found.
-func (s *AutoSortService) DetermineStorageLocation(ctx context.Context, scryfallID, treatment string) (*uint, error) {
+// storage location ID for quantity copies, or nil if no rule matches or the card
+// is not found. Cards no rule matches go to the configured catch-all location, if
+// any. Locations without room for every copy are skipped in favour of the next
+// matching rule, then the configured overflow location. A rule targeting the
+// unassigned location returns nil without an error.
+func (s *AutoSortService) DetermineStorageLocation(ctx context.Context, scryfallID, treatment, notes string, quantity int) (*uint, error) {
 	var card models.Card
 	if err := s.db.WithContext(ctx).Where("scryfall_id = ?", scryfallID).First(&card).Error; err != nil {
 		return nil, fmt.Errorf("card lookup failed: %w", err)
@@ -32,14 +37,36 @@ func (s *AutoSortService) DetermineStorageLocation(ctx context.Context, scryfall
 	if err != nil {
 		return nil, fmt.Errorf("card data conversion failed: %w", err)
 	}
+	cardData["notes"] = notes
+
+	var sortingRules []models.SortingRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).
+		Order("priority ASC").
+		Preload("StorageLocation").
+		Find(&sortingRules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sorting rules: %w", err)
+	}
 
 	evaluator := rules.NewEvaluator(s.db)
-	location, err := evaluator.EvaluateCard(ctx, cardData)
+	matches := WithCatchAll(evaluator.MatchingLocations(cardData, sortingRules), s.CatchAllLocation(ctx))
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no matching rule found for card")
+	}
+
+	usage, err := s.LocationUsage(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("no matching rule: %w", err)
+		return nil, err
+	}
+	location := PlaceWhole(max(quantity, 1), WithOverflow(matches, s.OverflowLocation(ctx)), usage)
+	if location == nil {
+		return nil, fmt.Errorf("no matching location has room for %d cards", quantity)
+	}
+	if location.ID == models.UnassignedLocationID {
+		slog.InfoContext(ctx, "card matched rule leaving it unassigned", "component", "auto_sort", "scryfall_id", scryfallID)
+		return nil, nil
 	}
 
-	slog.Info("card matched rule, assigning to storage location",
+	slog.InfoContext(ctx, "card matched rule, assigning to storage location",
 		"component", "auto_sort",
 		"scryfall_id", scryfallID,
 		"storage_location_id", location.ID,
@@ -48,3 +75,145 @@ func (s *AutoSortService) DetermineStorageLocation(ctx context.Context, scryfall
 
 	return &location.ID, nil
 }
+
+// OverflowLocation returns the location that takes cards whose matching locations
+// are all full, or nil when none is configured or it no longer exists
+func (s *AutoSortService) OverflowLocation(ctx context.Context) *models.StorageLocation {
+	return s.settingLocation(ctx, "auto_sort_overflow_location_id")
+}
+
+// CatchAllLocation returns the location that takes cards no rule matches, acting as
+// an implicit lowest-priority rule, or nil when none is configured or it no longer exists
+func (s *AutoSortService) CatchAllLocation(ctx context.Context) *models.StorageLocation {
+	return s.settingLocation(ctx, "auto_sort_catch_all_location_id")
+}
+
+// settingLocation loads the storage location whose ID is stored in a setting
+func (s *AutoSortService) settingLocation(ctx context.Context, key string) *models.StorageLocation {
+	settings := settingsStore(s.db)
+	id := settings.GetInt(ctx, key, 0)
+	if id <= 0 {
+		return nil
+	}
+
+	var location models.StorageLocation
+	if err := s.db.WithContext(ctx).First(&location, id).Error; err != nil {
+		slog.WarnContext(ctx, "configured location not found, ignoring", "component", "auto_sort", "setting", key, "storage_location_id", id, "error", err)
+		return nil
+	}
+	return &location
+}
+
+// WithCatchAll gives cards that matched no rule the catch-all location as their only
+// match. Cards that matched some rule, including one leaving them unassigned, keep
+// their matches.
+func WithCatchAll(matches []models.StorageLocation, catchAll *models.StorageLocation) []models.StorageLocation {
+	if catchAll == nil || len(matches) > 0 {
+		return matches
+	}
+	return []models.StorageLocation{*catchAll}
+}
+
+// WithOverflow appends the overflow location to a card's matching locations as a
+// last resort. Cards that matched no rule stay unmatched, and cards that matched
+// a rule leaving them unassigned never overflow.
+func WithOverflow(matches []models.StorageLocation, overflow *models.StorageLocation) []models.StorageLocation {
+	if overflow == nil || len(matches) == 0 {
+		return matches
+	}
+	for _, location := range matches {
+		if location.ID == overflow.ID || location.ID == models.UnassignedLocationID {
+			return matches
+		}
+	}
+	return append(slices.Clip(matches), *overflow)
+}
+
+// PlaceWhole returns the first location with room for every copy (capacity 0 means
+// unlimited), or nil when none has. usage is updated in place for the chosen location.
+func PlaceWhole(quantity int, matches []models.StorageLocation, usage map[uint]int) *models.StorageLocation {
+	for i, location := range matches {
+		if location.Capacity > 0 && location.Capacity-usage[location.ID] < quantity {
+			continue
+		}
+		usage[location.ID] += quantity
+		return &matches[i]
+	}
+	return nil
+}
+
+// Placement is a portion of an inventory row's quantity assigned to a storage location.
+// A nil StorageLocationID means the copies could not be placed and stay unassigned.
+type Placement struct {
+	StorageLocationID *uint
+	Quantity          int
+}
+
+// SplitEnabled reports whether rows may be split across locations when capacity would overflow
+func (s *AutoSortService) SplitEnabled(ctx context.Context) bool {
+	settings := settingsStore(s.db)
+	return settings.GetBool(ctx, "auto_sort_split_enabled", false)
+}
+
+// LocationUsage returns the number of cards currently stored in each location,
+// ignoring the given inventory rows (typically the rows about to be re-sorted)
+func (s *AutoSortService) LocationUsage(ctx context.Context, excludeIDs []uint) (map[uint]int, error) {
+	type locationUsage struct {
+		StorageLocationID uint `gorm:"column:storage_location_id"`
+		CardCount         int  `gorm:"column:card_count"`
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Select("storage_location_id, SUM(quantity) AS card_count").
+		Where("storage_location_id IS NOT NULL")
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+
+	var rows []locationUsage
+	if err := query.Group("storage_location_id").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("aggregating location usage: %w", err)
+	}
+
+	usage := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		usage[row.StorageLocationID] = row.CardCount
+	}
+	return usage, nil
+}
+
+// SplitPlacements distributes quantity across the matched locations in priority
+// order, filling each up to its capacity (0 means unlimited). Copies that fit
+// nowhere, or reach the virtual unassigned location, are returned as a final
+// unassigned placement. usage is updated in place so consecutive calls account
+// for cards already placed.
+func SplitPlacements(quantity int, matches []models.StorageLocation, usage map[uint]int) []Placement {
+	var placements []Placement
+	remaining := quantity
+
+	for _, location := range matches {
+		if remaining == 0 || location.ID == models.UnassignedLocationID {
+			break
+		}
+
+		take := remaining
+		if location.Capacity > 0 {
+			free := location.Capacity - usage[location.ID]
+			if free <= 0 {
+				continue
+			}
+			take = min(free, remaining)
+		}
+
+		locationID := location.ID
+		placements = append(placements, Placement{StorageLocationID: &locationID, Quantity: take})
+		usage[location.ID] += take
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		placements = append(placements, Placement{StorageLocationID: nil, Quantity: remaining})
+	}
+
+	return placements
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"backend/models"
 	"context"
+	"fmt"
 	"testing"
 
 	"gorm.io/driver/sqlite"
@@ -15,7 +16,7 @@ func setupAutoSortTestDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("failed to connect to test database: %v", err)
 	}
-	if err := db.AutoMigrate(&models.Card{}, &models.SortingRule{}, &models.StorageLocation{}); err != nil {
+	if err := db.AutoMigrate(&models.Card{}, &models.SortingRule{}, &models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.Setting{}); err != nil {
 		t.Fatalf("failed to migrate test database: %v", err)
 	}
 	return db
@@ -67,7 +68,7 @@ func TestAutoSort_DetermineStorageLocation_RuleMatches(t *testing.T) {
 	service := NewAutoSortService(db)
 	ctx := context.Background()
 
-	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil")
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -87,7 +88,7 @@ func TestAutoSort_DetermineStorageLocation_CardNotFound(t *testing.T) {
 	service := NewAutoSortService(db)
 	ctx := context.Background()
 
-	locationID, err := service.DetermineStorageLocation(ctx, "nonexistent-card-id", "nonfoil")
+	locationID, err := service.DetermineStorageLocation(ctx, "nonexistent-card-id", "nonfoil", "", 1)
 	if err == nil {
 		t.Error("expected error for nonexistent card, got nil")
 	}
@@ -134,7 +135,7 @@ func TestAutoSort_DetermineStorageLocation_NoMatchingRule(t *testing.T) {
 	service := NewAutoSortService(db)
 	ctx := context.Background()
 
-	locationID, err := service.DetermineStorageLocation(ctx, blueCard.ScryfallID, "nonfoil")
+	locationID, err := service.DetermineStorageLocation(ctx, blueCard.ScryfallID, "nonfoil", "", 1)
 	if err == nil {
 		t.Error("expected error when no rule matches, got nil")
 	}
@@ -204,7 +205,7 @@ func TestAutoSort_DetermineStorageLocation_DisabledRuleSkipped(t *testing.T) {
 	service := NewAutoSortService(db)
 	ctx := context.Background()
 
-	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil")
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -272,7 +273,7 @@ func TestAutoSort_DetermineStorageLocation_PriorityOrder(t *testing.T) {
 	service := NewAutoSortService(db)
 	ctx := context.Background()
 
-	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil")
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -283,3 +284,303 @@ func TestAutoSort_DetermineStorageLocation_PriorityOrder(t *testing.T) {
 		t.Errorf("expected highest priority storage ID %d, got %d", highPrioStorage.ID, *locationID)
 	}
 }
+
+func TestSplitPlacements(t *testing.T) {
+	nearlyFull := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Capacity: 100}
+	overflow := models.StorageLocation{BaseModel: models.BaseModel{ID: 2}, Capacity: 30}
+	unlimited := models.StorageLocation{BaseModel: models.BaseModel{ID: 3}}
+
+	tests := []struct {
+		name     string
+		quantity int
+		matches  []models.StorageLocation
+		usage    map[uint]int
+		expected []Placement
+	}{
+		{
+			name:     "Fits in first match",
+			quantity: 5,
+			matches:  []models.StorageLocation{nearlyFull, overflow},
+			usage:    map[uint]int{1: 90},
+			expected: []Placement{{StorageLocationID: &nearlyFull.ID, Quantity: 5}},
+		},
+		{
+			name:     "Spills into next match",
+			quantity: 60,
+			matches:  []models.StorageLocation{nearlyFull, overflow, unlimited},
+			usage:    map[uint]int{1: 90},
+			expected: []Placement{
+				{StorageLocationID: &nearlyFull.ID, Quantity: 10},
+				{StorageLocationID: &overflow.ID, Quantity: 30},
+				{StorageLocationID: &unlimited.ID, Quantity: 20},
+			},
+		},
+		{
+			name:     "Skips full location",
+			quantity: 4,
+			matches:  []models.StorageLocation{nearlyFull, overflow},
+			usage:    map[uint]int{1: 100},
+			expected: []Placement{{StorageLocationID: &overflow.ID, Quantity: 4}},
+		},
+		{
+			name:     "Remainder left unassigned",
+			quantity: 40,
+			matches:  []models.StorageLocation{overflow},
+			usage:    map[uint]int{},
+			expected: []Placement{
+				{StorageLocationID: &overflow.ID, Quantity: 30},
+				{StorageLocationID: nil, Quantity: 10},
+			},
+		},
+		{
+			name:     "Unassigned rule stops placement",
+			quantity: 40,
+			matches:  []models.StorageLocation{overflow, models.UnassignedLocation(), unlimited},
+			usage:    map[uint]int{},
+			expected: []Placement{
+				{StorageLocationID: &overflow.ID, Quantity: 30},
+				{StorageLocationID: nil, Quantity: 10},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitPlacements(tt.quantity, tt.matches, tt.usage)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %d placements, got %d: %+v", len(tt.expected), len(got), got)
+			}
+			for i, want := range tt.expected {
+				if got[i].Quantity != want.Quantity {
+					t.Errorf("placement %d: expected quantity %d, got %d", i, want.Quantity, got[i].Quantity)
+				}
+				if (want.StorageLocationID == nil) != (got[i].StorageLocationID == nil) ||
+					(want.StorageLocationID != nil && *want.StorageLocationID != *got[i].StorageLocationID) {
+					t.Errorf("placement %d: expected location %v, got %v", i, want.StorageLocationID, got[i].StorageLocationID)
+				}
+			}
+		})
+	}
+}
+
+func TestAutoSort_LocationUsage(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	if err := db.AutoMigrate(&models.Inventory{}); err != nil {
+		t.Fatalf("failed to migrate inventory: %v", err)
+	}
+	service := NewAutoSortService(db)
+
+	location := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&location)
+
+	kept := models.Inventory{ScryfallID: "a", OracleID: "a", Quantity: 3, StorageLocationID: &location.ID}
+	excluded := models.Inventory{ScryfallID: "b", OracleID: "b", Quantity: 7, StorageLocationID: &location.ID}
+	db.Create(&kept)
+	db.Create(&excluded)
+
+	usage, err := service.LocationUsage(context.Background(), []uint{excluded.ID})
+	if err != nil {
+		t.Fatalf("LocationUsage failed: %v", err)
+	}
+	if usage[location.ID] != 3 {
+		t.Errorf("expected usage 3, got %d", usage[location.ID])
+	}
+}
+
+func TestAutoSort_DetermineStorageLocation_SkipsFullLocation(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	service := NewAutoSortService(db)
+	ctx := context.Background()
+
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Model(storage).Update("capacity", 10)
+	db.Create(&models.Inventory{ScryfallID: "filler", OracleID: "filler", Quantity: 8, StorageLocationID: &storage.ID})
+
+	fallback := &models.StorageLocation{Name: "Fallback Box", StorageType: models.Box}
+	db.Create(fallback)
+	db.Create(&models.SortingRule{Name: "Everything", Priority: 2, Expression: "true", StorageLocationID: fallback.ID, Enabled: true})
+
+	// Two copies still fit in the first match
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *locationID != storage.ID {
+		t.Errorf("expected location %d, got %d", storage.ID, *locationID)
+	}
+
+	// Three copies fall through to the next matching rule
+	locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 3)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *locationID != fallback.ID {
+		t.Errorf("expected fallback location %d, got %d", fallback.ID, *locationID)
+	}
+}
+
+func TestAutoSort_DetermineStorageLocation_OverflowLocation(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	service := NewAutoSortService(db)
+	ctx := context.Background()
+
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Model(storage).Update("capacity", 1)
+
+	// With every match full and no overflow configured, the card stays unassigned
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 4)
+	if err == nil || locationID != nil {
+		t.Fatalf("expected no location, got %v", locationID)
+	}
+
+	overflow := &models.StorageLocation{Name: "Overflow", StorageType: models.Box}
+	db.Create(overflow)
+	db.Create(&models.Setting{Key: "auto_sort_overflow_location_id", Value: fmt.Sprint(overflow.ID)})
+
+	locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 4)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if *locationID != overflow.ID {
+		t.Errorf("expected overflow location %d, got %d", overflow.ID, *locationID)
+	}
+}
+
+func TestAutoSort_DetermineStorageLocation_CatchAllLocation(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	service := NewAutoSortService(db)
+	ctx := context.Background()
+
+	card, storage, rule := setupAutoSortTestData(t, db)
+
+	catchAll := &models.StorageLocation{Name: "Box Z", StorageType: models.Box}
+	db.Create(catchAll)
+	db.Create(&models.Setting{Key: "auto_sort_catch_all_location_id", Value: fmt.Sprint(catchAll.ID)})
+
+	// A matching rule still wins over the catch-all
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
+	if err != nil || locationID == nil || *locationID != storage.ID {
+		t.Fatalf("expected rule location %d, got %v (%v)", storage.ID, locationID, err)
+	}
+
+	db.Model(rule).UpdateColumn("enabled", false)
+	locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
+	if err != nil || locationID == nil || *locationID != catchAll.ID {
+		t.Fatalf("expected catch-all location %d, got %v (%v)", catchAll.ID, locationID, err)
+	}
+
+	// A full catch-all leaves the card unassigned like any full location
+	db.Model(catchAll).Update("capacity", 1)
+	db.Create(&models.Inventory{ScryfallID: card.ScryfallID, OracleID: card.OracleID, Treatment: "nonfoil", Quantity: 1, StorageLocationID: &catchAll.ID})
+	if locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1); err == nil || locationID != nil {
+		t.Errorf("expected no location once the catch-all is full, got %v", locationID)
+	}
+}
+
+func TestPlaceWhole(t *testing.T) {
+	small := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}, Capacity: 10}
+	unlimited := models.StorageLocation{BaseModel: models.BaseModel{ID: 2}}
+	matches := []models.StorageLocation{small, unlimited}
+
+	usage := map[uint]int{1: 5}
+	if got := PlaceWhole(5, matches, usage); got == nil || got.ID != 1 {
+		t.Errorf("expected exact fit in location 1, got %v", got)
+	}
+	if usage[1] != 10 {
+		t.Errorf("expected usage updated to 10, got %d", usage[1])
+	}
+	if got := PlaceWhole(1, matches, usage); got == nil || got.ID != 2 {
+		t.Errorf("expected full location skipped, got %v", got)
+	}
+	if got := PlaceWhole(1, []models.StorageLocation{small}, usage); got != nil {
+		t.Errorf("expected no placement, got %v", got)
+	}
+}
+
+func TestWithOverflow(t *testing.T) {
+	box := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}}
+	overflow := &models.StorageLocation{BaseModel: models.BaseModel{ID: 2}}
+
+	if got := WithOverflow(nil, overflow); len(got) != 0 {
+		t.Errorf("expected unmatched cards to get no overflow, got %v", got)
+	}
+	if got := WithOverflow([]models.StorageLocation{box}, overflow); len(got) != 2 || got[1].ID != 2 {
+		t.Errorf("expected overflow appended, got %v", got)
+	}
+	if got := WithOverflow([]models.StorageLocation{box, *overflow}, overflow); len(got) != 2 {
+		t.Errorf("expected overflow not duplicated, got %v", got)
+	}
+	if got := WithOverflow([]models.StorageLocation{box, models.UnassignedLocation()}, overflow); len(got) != 2 {
+		t.Errorf("expected no overflow after an unassigned rule, got %v", got)
+	}
+}
+
+func TestWithCatchAll(t *testing.T) {
+	box := models.StorageLocation{BaseModel: models.BaseModel{ID: 1}}
+	catchAll := &models.StorageLocation{BaseModel: models.BaseModel{ID: 2}}
+
+	if got := WithCatchAll(nil, catchAll); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("expected unmatched cards to get the catch-all, got %v", got)
+	}
+	if got := WithCatchAll([]models.StorageLocation{box}, catchAll); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("expected matched cards to keep their matches, got %v", got)
+	}
+	if got := WithCatchAll([]models.StorageLocation{models.UnassignedLocation()}, catchAll); len(got) != 1 || got[0].ID != models.UnassignedLocationID {
+		t.Errorf("expected an unassigned rule to win over the catch-all, got %v", got)
+	}
+	if got := WithCatchAll(nil, nil); len(got) != 0 {
+		t.Errorf("expected no catch-all when none is set, got %v", got)
+	}
+}
+
+func TestAutoSort_DetermineStorageLocation_UnassignedRule(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	service := NewAutoSortService(db)
+	ctx := context.Background()
+
+	card, _, rule := setupAutoSortTestData(t, db)
+	leaveUnassigned := &models.SortingRule{Name: "Leave cheap cards", Priority: 0, Expression: "true", StorageLocationID: models.UnassignedLocationID, Enabled: true}
+	if err := db.Create(leaveUnassigned).Error; err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
+	if err != nil || locationID != nil {
+		t.Errorf("expected the card left unassigned without error, got %v, %v", locationID, err)
+	}
+
+	// Once the higher-priority rule is gone the card is placed as usual
+	db.Delete(leaveUnassigned)
+	locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
+	if err != nil || locationID == nil || *locationID != rule.StorageLocationID {
+		t.Errorf("expected location %d, got %v, %v", rule.StorageLocationID, locationID, err)
+	}
+}
+
+func TestAutoSort_DetermineStorageLocation_MatchesNotes(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+
+	signed := &models.StorageLocation{Name: "Signed Binder", StorageType: models.Binder}
+	db.Create(signed)
+	db.Create(&models.SortingRule{Name: "Signed", Priority: 0, Expression: `noteContains("signed")`, StorageLocationID: signed.ID, Enabled: true})
+
+	service := NewAutoSortService(db)
+	ctx := context.Background()
+
+	locationID, err := service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "Signed in silver", 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if locationID == nil || *locationID != signed.ID {
+		t.Errorf("expected signed binder %d, got %v", signed.ID, locationID)
+	}
+
+	locationID, err = service.DetermineStorageLocation(ctx, card.ScryfallID, "nonfoil", "", 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if locationID == nil || *locationID != storage.ID {
+		t.Errorf("expected unsigned copy in %d, got %v", storage.ID, locationID)
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"backend/utils"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultBackupRetentionCount is how many backups are kept unless backup_retention_count says otherwise
+	DefaultBackupRetentionCount = 7
+
+	backupDirName       = "backups"
+	backupFilePrefix    = "showmycards-"
+	backupFileSuffix    = ".db"
+	backupEncryptedExt  = ".enc"     // Appended to backups encrypted with EXPORT_ENCRYPTION_PASSPHRASE
+	backupPartialSuffix = ".partial" // Appended while a backup is being written
+	backupTimeLayout    = "20060102-150405.000"
+)
+
+// ErrBackupRunning is returned when a backup or restore is requested while another is running
+var ErrBackupRunning = errors.New("a backup or restore is already running")
+
+// BackupInfo describes a backup file in the backups directory
+// tygo:export
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"` // Bytes
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupService writes consistent copies of the live database into DATA_DIR/backups
+// and restores them
+type BackupService struct {
+	db         *gorm.DB
+	jobs       *JobService
+	dir        string
+	passphrase string     // Encrypts new backups and decrypts encrypted ones; empty writes plain SQLite files
+	running    sync.Mutex // Held while a backup or restore runs
+}
+
+// NewBackupService creates a backup service storing backups under dataDir
+func NewBackupService(db *gorm.DB, jobService *JobService, dataDir string) *BackupService {
+	return &BackupService{db: db, jobs: jobService, dir: filepath.Join(dataDir, backupDirName)}
+}
+
+// SetPassphrase encrypts backups written from now on with passphrase, and lets
+// encrypted backups be restored
+func (s *BackupService) SetPassphrase(passphrase string) {
+	s.passphrase = passphrase
+}
+
+// Create writes a backup with VACUUM INTO, which reads the database in one transaction,
+// so the copy is consistent even while the scheduler or handlers are writing. The file
+// is written under a partial name and renamed once complete; with a passphrase set it
+// is encrypted first and named with .enc. Older backups beyond backup_retention_count
+// are then removed.
+func (s *BackupService) Create(ctx context.Context) (*BackupInfo, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBackupRunning
+	}
+	defer s.running.Unlock()
+	return s.create(ctx)
+}
+
+// create is Create for callers already holding the running lock
+func (s *BackupService) create(ctx context.Context) (*BackupInfo, error) {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating backup directory: %w", err)
+	}
+
+	// Names must be unique; backups taken within the same millisecond move to the next
+	suffix := backupFileSuffix
+	if s.passphrase != "" {
+		suffix += backupEncryptedExt
+	}
+	createdAt := time.Now().UTC()
+	var name, path string
+	for {
+		name = backupFilePrefix + createdAt.Format(backupTimeLayout) + suffix
+		path = filepath.Join(s.dir, name)
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		createdAt = createdAt.Add(time.Millisecond)
+	}
+	partial := path + backupPartialSuffix
+
+	// VACUUM INTO refuses to overwrite, so clear any leftover from an interrupted run
+	_ = os.Remove(partial)
+	if err := s.db.WithContext(ctx).Exec("VACUUM INTO ?", partial).Error; err != nil {
+		_ = os.Remove(partial)
+		return nil, fmt.Errorf("writing backup: %w", err)
+	}
+	if s.passphrase != "" {
+		if err := encryptBackup(partial, s.passphrase); err != nil {
+			_ = os.Remove(partial)
+			return nil, fmt.Errorf("encrypting backup: %w", err)
+		}
+	}
+	if err := os.Rename(partial, path); err != nil {
+		_ = os.Remove(partial)
+		return nil, fmt.Errorf("finalizing backup: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	backup := &BackupInfo{Name: name, Size: stat.Size(), CreatedAt: createdAt}
+	slog.InfoContext(ctx, "created database backup", "component", "backup", "name", name, "size", backup.Size)
+
+	if _, err := s.prune(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to prune old backups", "component", "backup", "error", err)
+	}
+	return backup, nil
+}
+
+// List returns the completed backups, newest first
+func (s *BackupService) List() ([]BackupInfo, error) {
+	backups := []BackupInfo{}
+
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return backups, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading backup directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		createdAt, ok := parseBackupName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	slices.SortFunc(backups, func(a, b BackupInfo) int {
+		return cmp.Compare(b.Name, a.Name)
+	})
+	return backups, nil
+}
+
+// RunScheduledBackup is the scheduled task entry point for Create
+func (s *BackupService) RunScheduledBackup(ctx context.Context) error {
+	if _, err := s.Create(ctx); err != nil {
+		return fmt.Errorf("scheduled backup: %w", err)
+	}
+
+	settings := settingsStore(s.db)
+	if err := settings.SetTime(ctx, "backup_last_run", time.Now()); err != nil {
+		slog.WarnContext(ctx, "failed to persist backup_last_run", "component", "backup", "error", err)
+	}
+	return nil
+}
+
+// prune removes the oldest backups beyond the backup_retention_count setting
+func (s *BackupService) prune(ctx context.Context) (int, error) {
+	settings := settingsStore(s.db)
+	keep := settings.GetInt(ctx, "backup_retention_count", DefaultBackupRetentionCount)
+	if keep < 1 {
+		keep = DefaultBackupRetentionCount
+	}
+
+	backups, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, backup := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(s.dir, backup.Name)); err != nil {
+			return removed, fmt.Errorf("removing backup %s: %w", backup.Name, err)
+		}
+		removed++
+	}
+	if removed > 0 {
+		slog.InfoContext(ctx, "pruned old backups", "component", "backup", "removed", removed, "retention_count", keep)
+	}
+	return removed, nil
+}
+
+// encryptBackup replaces a backup file's content with its encryption under passphrase.
+// The whole database is held in memory while it is encrypted.
+func encryptBackup(path, passphrase string) error {
+	plaintext, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	encrypted, err := utils.EncryptWithPassphrase(plaintext, passphrase)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, encrypted, 0o600)
+}
+
+// parseBackupName returns when a backup was taken from its file name, and whether the
+// name is a completed backup's
+func parseBackupName(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, backupFilePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	stamp = strings.TrimSuffix(stamp, backupEncryptedExt)
+	if stamp, ok = strings.CutSuffix(stamp, backupFileSuffix); !ok {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(backupTimeLayout, stamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return createdAt, true
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"backend/database"
+	"backend/models"
+	"backend/utils"
+	"backend/version"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	// ErrInvalidBackup is wrapped by the reasons a file cannot be restored
+	ErrInvalidBackup = errors.New("invalid backup")
+	// ErrBackupNotFound is returned when a named backup doesn't exist
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrJobsActive is returned when a restore or database maintenance would run under
+	// pending or running jobs
+	ErrJobsActive = errors.New("jobs are pending or running")
+)
+
+// jobDrainTimeout bounds how long a restore waits for cancelled jobs to stop
+const jobDrainTimeout = 30 * time.Second
+
+// sqliteHeader starts every SQLite database file
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// backupRequiredTables must exist for a file to be taken as a ShowMyCards database
+var backupRequiredTables = []string{"settings", "storage_locations", "inventories", "lists"}
+
+// RestoreResult reports a completed restore
+// tygo:export
+type RestoreResult struct {
+	Restored      string     `json:"restored"`      // Backup name, or the uploaded file's name
+	SafetyBackup  BackupInfo `json:"safety_backup"` // Backup of the database as it was before the restore
+	CancelledJobs int        `json:"cancelled_jobs"`
+}
+
+// BackupPath returns the path of a stored backup by name, or ErrBackupNotFound. Only
+// names List would return are accepted, so the name cannot escape the backups directory.
+func (s *BackupService) BackupPath(name string) (string, error) {
+	if _, ok := parseBackupName(name); !ok || filepath.Base(name) != name {
+		return "", ErrBackupNotFound
+	}
+	path := filepath.Join(s.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", ErrBackupNotFound
+	}
+	return path, nil
+}
+
+// Restore replaces the live database's contents with the backup at path, labelled
+// for the result by label. An encrypted backup is decrypted with the configured
+// passphrase into a temporary file first. The backup must pass ValidateBackup. Pending or running
+// jobs are cancelled, and their work waited for, when cancelJobs is set, otherwise
+// they fail the restore with ErrJobsActive. The current database is backed up first, so a restore can be undone
+// by restoring that backup.
+//
+// The copy uses SQLite's online backup API into the open connection, so every handler
+// and service keeps its handle and sees the restored data; the single connection is
+// held for the whole copy. Migrations and the version check run afterwards, bringing
+// an older backup's schema up to date.
+func (s *BackupService) Restore(ctx context.Context, path, label string, cancelJobs bool) (*RestoreResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBackupRunning
+	}
+	defer s.running.Unlock()
+
+	path, cleanup, err := s.decryptBackup(path)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := ValidateBackup(ctx, path); err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{Restored: label}
+	cancelled, err := s.quiesceJobs(ctx, cancelJobs)
+	if err != nil {
+		return nil, err
+	}
+	result.CancelledJobs = cancelled
+
+	safety, err := s.create(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("backing up the current database: %w", err)
+	}
+	result.SafetyBackup = *safety
+
+	if err := s.copyFrom(ctx, path); err != nil {
+		return nil, fmt.Errorf("restoring %s (the previous database is in %s): %w", label, safety.Name, err)
+	}
+
+	if err := database.Migrate(s.db.WithContext(ctx)); err != nil {
+		return nil, fmt.Errorf("migrating restored database: %w", err)
+	}
+	// Seeds settings added since the backup was taken
+	settings := NewSettingsService(s.db)
+	if err := version.CheckAndUpdate(ctx, settings); err != nil {
+		return nil, fmt.Errorf("recording version in restored database: %w", err)
+	}
+
+	slog.InfoContext(ctx, "restored database backup", "component", "backup", "restored", label,
+		"safety_backup", safety.Name, "cancelled_jobs", cancelled)
+	return result, nil
+}
+
+// decryptBackup returns the path of a plain copy of an encrypted backup, and a function
+// removing it; an unencrypted backup is returned as is
+func (s *BackupService) decryptBackup(path string) (string, func(), error) {
+	noop := func() {}
+	header, err := readBackupHeader(path)
+	if err != nil {
+		return "", noop, err
+	}
+	if !utils.IsEncrypted(header) {
+		return path, noop, nil
+	}
+	if s.passphrase == "" {
+		return "", noop, fmt.Errorf("%w: backup is encrypted — set EXPORT_ENCRYPTION_PASSPHRASE to restore it", ErrInvalidBackup)
+	}
+
+	encrypted, err := os.ReadFile(path)
+	if err != nil {
+		return "", noop, fmt.Errorf("reading backup: %w", err)
+	}
+	plaintext, err := utils.DecryptWithPassphrase(encrypted, s.passphrase)
+	if err != nil {
+		return "", noop, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+
+	// Kept beside the backups rather than in the system temp dir, which may be too small
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", noop, fmt.Errorf("creating backup directory: %w", err)
+	}
+	file, err := os.CreateTemp(s.dir, ".restore-*.db")
+	if err != nil {
+		return "", noop, fmt.Errorf("decrypting backup: %w", err)
+	}
+	cleanup := func() { _ = os.Remove(file.Name()) }
+	_, err = file.Write(plaintext)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", noop, fmt.Errorf("decrypting backup: %w", err)
+	}
+	return file.Name(), cleanup, nil
+}
+
+// readBackupHeader reads the first bytes of a backup file, enough to tell a SQLite
+// database from an encrypted backup
+func readBackupHeader(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening backup: %w", err)
+	}
+	defer file.Close()
+	header := make([]byte, len(sqliteHeader))
+	n, err := io.ReadFull(file, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading backup: %w", err)
+	}
+	return header[:n], nil
+}
+
+// ValidateBackup checks that path holds an intact ShowMyCards database written by this
+// version of the app or an older one. Failures wrap ErrInvalidBackup.
+func ValidateBackup(ctx context.Context, path string) error {
+	header, err := readBackupHeader(path)
+	if err != nil {
+		return err
+	}
+	if utils.IsEncrypted(header) {
+		return fmt.Errorf("%w: backup is encrypted and must be decrypted first", ErrInvalidBackup)
+	}
+	if !bytes.Equal(header, sqliteHeader) {
+		return fmt.Errorf("%w: not a SQLite database", ErrInvalidBackup)
+	}
+
+	db, err := openBackup(path)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRowContext(ctx, "PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, integrity)
+	}
+
+	for _, table := range backupRequiredTables {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: not a ShowMyCards database (no %s table)", ErrInvalidBackup, table)
+		}
+	}
+
+	// A backup from a newer version may have a schema this version can't handle
+	var stored string
+	err = db.QueryRowContext(ctx, "SELECT value FROM settings WHERE key = 'app_version'").Scan(&stored)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if stored != "" && stored != "dev" && version.Version != "dev" {
+		if cmp, err := version.Compare(stored, version.Version); err == nil && cmp > 0 {
+			return fmt.Errorf("%w: written by version %s, newer than the running %s", ErrInvalidBackup, stored, version.Version)
+		}
+	}
+
+	// Likewise for migrations this version doesn't know, which dev builds don't catch above.
+	// Backups from before versioned migrations have no schema_migrations table.
+	var hasMigrations int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'").Scan(&hasMigrations); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if hasMigrations == 0 {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if !database.IsKnownMigration(id) {
+			return fmt.Errorf("%w: has migration %s, which is newer than the running version", ErrInvalidBackup, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return nil
+}
+
+// quiesceJobs cancels pending and running jobs when allowed and waits for their work
+// to stop, returning how many were cancelled, or fails with ErrJobsActive
+func (s *BackupService) quiesceJobs(ctx context.Context, cancelJobs bool) (int, error) {
+	var active []models.Job
+	if err := s.db.WithContext(ctx).
+		Where("status IN ?", []models.JobStatus{models.JobStatusPending, models.JobStatusInProgress}).
+		Find(&active).Error; err != nil {
+		return 0, fmt.Errorf("checking for active jobs: %w", err)
+	}
+	if len(active) == 0 {
+		return 0, nil
+	}
+	if !cancelJobs {
+		return 0, fmt.Errorf("%w: %d active", ErrJobsActive, len(active))
+	}
+
+	ids := make([]uint, len(active))
+	for i, job := range active {
+		if _, err := s.jobs.Cancel(ctx, job.ID); err != nil && !errors.Is(err, ErrJobNotCancellable) {
+			return 0, fmt.Errorf("cancelling job %d: %w", job.ID, err)
+		}
+		ids[i] = job.ID
+	}
+
+	// Cancelled work stops at its next check; until then it could write into the restored database
+	waitCtx, cancel := context.WithTimeout(ctx, jobDrainTimeout)
+	defer cancel()
+	if err := s.jobs.WaitStopped(waitCtx, ids...); err != nil {
+		return 0, fmt.Errorf("%w: cancelled jobs did not stop: %v", ErrJobsActive, err)
+	}
+	return len(active), nil
+}
+
+// copyFrom overwrites the live database with the one at path through the SQLite backup API
+func (s *BackupService) copyFrom(ctx context.Context, path string) error {
+	source, err := openBackup(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	sourceConn, err := source.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer sourceConn.Close()
+
+	liveDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	liveConn, err := liveDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer liveConn.Close()
+
+	return liveConn.Raw(func(live any) error {
+		return sourceConn.Raw(func(src any) error {
+			dest, ok := live.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected database driver %T", live)
+			}
+			backup, err := dest.Backup("main", src.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// openBackup opens a database file read-only
+func openBackup(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", "file:"+(&url.URL{Path: path}).EscapedPath()+"?mode=ro")
+}
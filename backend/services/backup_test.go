@@ -0,0 +1,374 @@
+package services
+
+import (
+	"backend/database"
+	"backend/database/databasetest"
+	"backend/models"
+	"backend/utils"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBackupTest(t *testing.T) (*gorm.DB, *BackupService, string) {
+	t.Helper()
+
+	dataDir := t.TempDir()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "database.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	databasetest.Migrate(t, db)
+	// Restores copy into the one connection the app uses
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database instance: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db, NewBackupService(db, NewJobService(db), dataDir), dataDir
+}
+
+func TestBackupService_Create(t *testing.T) {
+	db, service, dataDir := setupBackupTest(t)
+	ctx := context.Background()
+	db.Create(&models.StorageLocation{Name: "Box 1", StorageType: models.Box})
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if backup.Size == 0 || backup.CreatedAt.IsZero() {
+		t.Errorf("unexpected backup: %+v", backup)
+	}
+
+	// The backup is a complete database of its own
+	copyDB, err := gorm.Open(sqlite.Open(filepath.Join(dataDir, "backups", backup.Name)), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	var count int64
+	copyDB.Model(&models.StorageLocation{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected the storage location in the backup, got %d rows", count)
+	}
+
+	backups, err := service.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 1 || backups[0].Name != backup.Name || backups[0].Size != backup.Size {
+		t.Errorf("expected the backup listed, got %+v", backups)
+	}
+}
+
+func TestBackupService_Retention(t *testing.T) {
+	db, service, dataDir := setupBackupTest(t)
+	ctx := context.Background()
+	db.Create(&models.Setting{Key: "backup_retention_count", Value: "2"})
+
+	var names []string
+	for range 3 {
+		backup, err := service.Create(ctx)
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		names = append(names, backup.Name)
+	}
+	// Unrelated and unfinished files are neither listed nor pruned
+	os.WriteFile(filepath.Join(dataDir, "backups", "notes.txt"), []byte("keep"), 0o644)
+	os.WriteFile(filepath.Join(dataDir, "backups", names[0]+backupPartialSuffix), []byte("partial"), 0o644)
+
+	backups, err := service.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != names[2] || backups[1].Name != names[1] {
+		t.Errorf("expected the two newest backups, newest first, got %+v", backups)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "backups", "notes.txt")); err != nil {
+		t.Errorf("expected unrelated files left alone: %v", err)
+	}
+}
+
+func TestBackupService_Running(t *testing.T) {
+	_, service, _ := setupBackupTest(t)
+
+	service.running.Lock()
+	defer service.running.Unlock()
+	if _, err := service.Create(context.Background()); !errors.Is(err, ErrBackupRunning) {
+		t.Errorf("expected ErrBackupRunning, got %v", err)
+	}
+}
+
+func TestBackupService_RunScheduledBackup(t *testing.T) {
+	db, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+
+	service.RunScheduledBackup(ctx)
+
+	backups, err := service.List()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected one backup, got %d (%v)", len(backups), err)
+	}
+	lastRun, err := settingsStore(db).GetTime(ctx, "backup_last_run")
+	if err != nil || lastRun == nil {
+		t.Errorf("expected backup_last_run recorded, got %v (%v)", lastRun, err)
+	}
+}
+
+func TestBackupService_ListEmpty(t *testing.T) {
+	_, service, _ := setupBackupTest(t)
+
+	backups, err := service.List()
+	if err != nil || backups == nil || len(backups) != 0 {
+		t.Errorf("expected an empty list before any backup, got %v (%v)", backups, err)
+	}
+}
+
+func TestBackupService_Restore(t *testing.T) {
+	db, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+	db.Create(&models.StorageLocation{Name: "Box 1", StorageType: models.Box})
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	db.Create(&models.StorageLocation{Name: "Box 2", StorageType: models.Box})
+
+	path, err := service.BackupPath(backup.Name)
+	if err != nil {
+		t.Fatalf("BackupPath failed: %v", err)
+	}
+	result, err := service.Restore(ctx, path, backup.Name, false)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.Restored != backup.Name || result.SafetyBackup.Name == "" || result.SafetyBackup.Name == backup.Name {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	// The same handle sees the restored contents
+	var names []string
+	db.Model(&models.StorageLocation{}).Order("id").Pluck("name", &names)
+	if len(names) != 1 || names[0] != "Box 1" {
+		t.Errorf("expected only the backed up location, got %v", names)
+	}
+
+	// The safety backup holds the database as it was before
+	safetyPath, err := service.BackupPath(result.SafetyBackup.Name)
+	if err != nil {
+		t.Fatalf("expected the safety backup stored: %v", err)
+	}
+	if _, err := service.Restore(ctx, safetyPath, result.SafetyBackup.Name, false); err != nil {
+		t.Fatalf("Restore of safety backup failed: %v", err)
+	}
+	var count int64
+	db.Model(&models.StorageLocation{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected both locations back, got %d", count)
+	}
+}
+
+func TestBackupService_Encrypted(t *testing.T) {
+	db, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+	service.SetPassphrase("nas-passphrase")
+	db.Create(&models.StorageLocation{Name: "Mythic Box", StorageType: models.Box})
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if !strings.HasSuffix(backup.Name, ".db.enc") {
+		t.Errorf("expected an encrypted backup name, got %s", backup.Name)
+	}
+	path, err := service.BackupPath(backup.Name)
+	if err != nil {
+		t.Fatalf("BackupPath failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read backup: %v", err)
+	}
+	if !utils.IsEncrypted(content) || bytes.Contains(content, []byte("Mythic Box")) {
+		t.Fatal("expected the backup to be encrypted")
+	}
+	db.Create(&models.StorageLocation{Name: "Box 2", StorageType: models.Box})
+
+	for _, passphrase := range []string{"", "other"} {
+		service.SetPassphrase(passphrase)
+		if _, err := service.Restore(ctx, path, backup.Name, false); !errors.Is(err, ErrInvalidBackup) {
+			t.Errorf("expected ErrInvalidBackup with passphrase %q, got %v", passphrase, err)
+		}
+	}
+
+	service.SetPassphrase("nas-passphrase")
+	if _, err := service.Restore(ctx, path, backup.Name, false); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	var names []string
+	db.Model(&models.StorageLocation{}).Order("id").Pluck("name", &names)
+	if len(names) != 1 || names[0] != "Mythic Box" {
+		t.Errorf("expected only the backed up location, got %v", names)
+	}
+
+	// The decrypted copy doesn't outlive the restore
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".restore-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("expected the decrypted copy removed, got %v", leftovers)
+	}
+}
+
+func TestBackupService_RestoreActiveJobs(t *testing.T) {
+	db, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	path, _ := service.BackupPath(backup.Name)
+	db.Create(&models.Job{Type: models.JobTypeBulkDataImport, Status: models.JobStatusInProgress})
+
+	if _, err := service.Restore(ctx, path, backup.Name, false); !errors.Is(err, ErrJobsActive) {
+		t.Fatalf("expected ErrJobsActive, got %v", err)
+	}
+	result, err := service.Restore(ctx, path, backup.Name, true)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if result.CancelledJobs != 1 {
+		t.Errorf("expected 1 cancelled job, got %d", result.CancelledJobs)
+	}
+}
+
+func TestBackupService_RestoreWaitsForCancelledJobs(t *testing.T) {
+	_, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	path, _ := service.BackupPath(backup.Name)
+
+	job, err := service.jobs.Create(ctx, models.JobTypeReindex, "{}")
+	if err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	started := make(chan struct{})
+	var stopped atomic.Bool
+	err = service.jobs.Go(ctx, job.ID, "test", func(ctx context.Context) error {
+		ctx, release := service.jobs.Cancellable(ctx, job.ID)
+		defer release()
+		close(started)
+		<-ctx.Done()
+		// Work still in flight after the cancel, such as finishing a batch
+		time.Sleep(50 * time.Millisecond)
+		stopped.Store(true)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to start job: %v", err)
+	}
+	<-started
+
+	if _, err := service.Restore(ctx, path, backup.Name, true); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !stopped.Load() {
+		t.Error("expected the restore to wait for the cancelled job to stop")
+	}
+}
+
+func TestBackupService_RestoreInvalid(t *testing.T) {
+	_, service, dataDir := setupBackupTest(t)
+	ctx := context.Background()
+
+	notSQLite := filepath.Join(dataDir, "notes.db")
+	os.WriteFile(notSQLite, []byte("not a database at all"), 0o644)
+
+	otherPath := filepath.Join(dataDir, "other.db")
+	other, err := gorm.Open(sqlite.Open(otherPath), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create other database: %v", err)
+	}
+	other.Exec("CREATE TABLE things (id INTEGER)")
+	if sqlDB, err := other.DB(); err == nil {
+		sqlDB.Close()
+	}
+
+	for _, path := range []string{notSQLite, otherPath} {
+		if _, err := service.Restore(ctx, path, filepath.Base(path), false); !errors.Is(err, ErrInvalidBackup) {
+			t.Errorf("%s: expected ErrInvalidBackup, got %v", filepath.Base(path), err)
+		}
+	}
+
+	backups, _ := service.List()
+	if len(backups) != 0 {
+		t.Errorf("expected no safety backup for a rejected restore, got %d", len(backups))
+	}
+}
+
+func TestBackupService_RestoreMigrations(t *testing.T) {
+	db, service, _ := setupBackupTest(t)
+	ctx := context.Background()
+
+	backup, err := service.Create(ctx)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	path, _ := service.BackupPath(backup.Name)
+
+	backupDB, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer func() {
+		if sqlDB, err := backupDB.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// A migration from a newer version makes the backup unusable here
+	backupDB.Create(&database.SchemaMigration{ID: "9999_future"})
+	if err := ValidateBackup(ctx, path); !errors.Is(err, ErrInvalidBackup) {
+		t.Errorf("expected ErrInvalidBackup for an unknown migration, got %v", err)
+	}
+
+	// A backup from before versioned migrations is restored and migrated
+	backupDB.Exec("DROP TABLE schema_migrations")
+	if _, err := service.Restore(ctx, path, backup.Name, false); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	states, err := database.MigrationStatus(db)
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			t.Errorf("expected %s applied after restore", state.ID)
+		}
+	}
+}
+
+func TestBackupService_BackupPath(t *testing.T) {
+	_, service, _ := setupBackupTest(t)
+
+	for _, name := range []string{"", "../database.db", "showmycards-20260101-000000.000.db", "notes.txt"} {
+		if _, err := service.BackupPath(name); !errors.Is(err, ErrBackupNotFound) {
+			t.Errorf("%q: expected ErrBackupNotFound, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxBatchAddEntries caps the number of entries accepted in a single batch add
+const MaxBatchAddEntries = 500
+
+// ErrTooManyBatchAddEntries is returned when a batch add exceeds MaxBatchAddEntries
+var ErrTooManyBatchAddEntries = fmt.Errorf("batch exceeds %d entries", MaxBatchAddEntries)
+
+// BatchAddStatus is the outcome of a single batch add entry
+type BatchAddStatus string
+
+const (
+	BatchAddStatusAdded    BatchAddStatus = "added"
+	BatchAddStatusNotFound BatchAddStatus = "not_found"
+	BatchAddStatusInvalid  BatchAddStatus = "invalid"
+)
+
+// BatchAddEntry identifies a card to add by Scryfall ID, by set and collector number,
+// or by name (optionally within a set), in that order of precedence
+// tygo:export
+type BatchAddEntry struct {
+	ScryfallID      string `json:"scryfall_id,omitempty"`
+	SetCode         string `json:"set_code,omitempty"`
+	CollectorNumber string `json:"collector_number,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Quantity        int    `json:"quantity,omitempty"`  // Defaults to 1
+	Treatment       string `json:"treatment,omitempty"` // Defaults to nonfoil
+}
+
+// BatchAddResult reports what happened to a single batch add entry
+// tygo:export
+type BatchAddResult struct {
+	Index             int            `json:"index"`
+	Status            BatchAddStatus `json:"status"`
+	Quantity          int            `json:"quantity,omitempty"`
+	Name              string         `json:"name,omitempty"`
+	SetCode           string         `json:"set_code,omitempty"`
+	ScryfallID        string         `json:"scryfall_id,omitempty"`
+	InventoryID       uint           `json:"inventory_id,omitempty"`
+	StorageLocationID *uint          `json:"storage_location_id,omitempty"`
+	IntakeItemID      uint           `json:"intake_item_id,omitempty"` // Staged item, when adding to an intake session
+	Error             string         `json:"error,omitempty"`
+}
+
+// BatchAddService creates inventory for many identified cards at once
+type BatchAddService struct {
+	db *gorm.DB
+}
+
+// NewBatchAddService creates a new batch add service
+func NewBatchAddService(db *gorm.DB) *BatchAddService {
+	return &BatchAddService{db: db}
+}
+
+// Add resolves every entry against the local card database in a single query, then
+// creates inventory for the resolved entries in one transaction. Entries that are
+// invalid or match no card are reported without stopping the rest; results are in
+// entry order. When storageLocationID is nil, sorting rules decide where each card goes.
+func (s *BatchAddService) Add(ctx context.Context, entries []BatchAddEntry, storageLocationID *uint) ([]BatchAddResult, error) {
+	results, matches, err := resolveBatchAddEntries(ctx, s.db, entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	items := make([]models.Inventory, len(matches))
+	for i, match := range matches {
+		items[i] = models.Inventory{
+			ScryfallID:        match.card.ScryfallID,
+			OracleID:          match.card.OracleID,
+			Treatment:         match.entry.Treatment,
+			Quantity:          match.entry.Quantity,
+			StorageLocationID: storageLocationID,
+		}
+	}
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createSortedInventory(ctx, tx, items)
+	}); err != nil {
+		return nil, fmt.Errorf("creating inventory: %w", err)
+	}
+
+	for i, match := range matches {
+		results[match.index].Status = BatchAddStatusAdded
+		results[match.index].InventoryID = items[i].ID
+		results[match.index].StorageLocationID = items[i].StorageLocationID
+	}
+	return results, nil
+}
+
+// batchAddMatch is a batch add entry resolved to a printing
+type batchAddMatch struct {
+	index int
+	entry BatchAddEntry
+	card  textImportCandidate
+}
+
+// resolveBatchAddEntries normalizes and validates entries, then resolves the valid ones
+// against the local card database in a single query. Results for invalid and unmatched
+// entries carry their status; the rest are returned as matches, with their result
+// filled in except for the status.
+func resolveBatchAddEntries(ctx context.Context, db *gorm.DB, entries []BatchAddEntry) ([]BatchAddResult, []batchAddMatch, error) {
+	if len(entries) > MaxBatchAddEntries {
+		return nil, nil, ErrTooManyBatchAddEntries
+	}
+
+	entries = slices.Clone(entries)
+	results := make([]BatchAddResult, len(entries))
+	for i := range entries {
+		entry := normalizeBatchAddEntry(entries[i])
+		entries[i] = entry
+		results[i] = BatchAddResult{Index: i, Quantity: entry.Quantity, Name: entry.Name, SetCode: entry.SetCode, ScryfallID: entry.ScryfallID}
+		if err := validateBatchAddEntry(entry); err != nil {
+			results[i].Status = BatchAddStatusInvalid
+			results[i].Error = err.Error()
+		}
+	}
+
+	candidates, err := loadBatchAddCandidates(ctx, db, entries, results)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matches []batchAddMatch
+	for i, entry := range entries {
+		if results[i].Status != "" {
+			continue
+		}
+		card, ok := candidates.pick(entry)
+		if !ok {
+			results[i].Status = BatchAddStatusNotFound
+			results[i].Error = "no matching card found"
+			continue
+		}
+		results[i].Name = card.Name
+		results[i].SetCode = card.SetCode
+		results[i].ScryfallID = card.ScryfallID
+		matches = append(matches, batchAddMatch{index: i, entry: entry, card: card})
+	}
+	return results, matches, nil
+}
+
+// createSortedInventory creates items inside tx, placing those without a location by
+// sorting rules evaluated in the same transaction, so copies placed earlier in the
+// batch count toward each location's capacity
+func createSortedInventory(ctx context.Context, tx *gorm.DB, items []models.Inventory) error {
+	autoSortSvc := NewAutoSortService(tx)
+	events := make([]models.InventoryEvent, 0, len(items))
+	for i := range items {
+		item := &items[i]
+		if item.StorageLocationID == nil {
+			locationID, err := autoSortSvc.DetermineStorageLocation(ctx, item.ScryfallID, item.Treatment, item.Notes, item.Quantity)
+			if err != nil {
+				slog.DebugContext(ctx, "auto-sort did not assign location", "component", "batch_add", "scryfall_id", item.ScryfallID, "error", err)
+			} else {
+				item.StorageLocationID = locationID
+			}
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		events = append(events, models.NewInventoryCreatedEvent(*item))
+	}
+	return models.RecordInventoryEvents(tx, events)
+}
+
+// normalizeBatchAddEntry trims the identifiers and fills in the default quantity and treatment
+func normalizeBatchAddEntry(entry BatchAddEntry) BatchAddEntry {
+	entry.ScryfallID = strings.TrimSpace(entry.ScryfallID)
+	entry.SetCode = strings.ToLower(strings.TrimSpace(entry.SetCode))
+	entry.CollectorNumber = normalizeCollectorNumber(entry.CollectorNumber)
+	entry.Name = strings.TrimSpace(entry.Name)
+	entry.Treatment = strings.TrimSpace(entry.Treatment)
+	if entry.Quantity == 0 {
+		entry.Quantity = 1
+	}
+	if entry.Treatment == "" {
+		entry.Treatment = "nonfoil"
+	}
+	return entry
+}
+
+// validateBatchAddEntry checks an entry carries an identifier and a sensible quantity
+func validateBatchAddEntry(entry BatchAddEntry) error {
+	if entry.Quantity < 0 {
+		return errors.New("quantity cannot be negative")
+	}
+	if entry.CollectorNumber != "" && entry.SetCode == "" {
+		return errors.New("collector_number requires set_code")
+	}
+	if entry.ScryfallID == "" && entry.CollectorNumber == "" && entry.Name == "" {
+		return errors.New("scryfall_id, set_code and collector_number, or name is required")
+	}
+	return nil
+}
+
+// batchAddCandidates indexes the printings loaded for a batch by each kind of identifier
+type batchAddCandidates struct {
+	byID     map[string]textImportCandidate
+	byNumber map[string]textImportCandidate
+	byName   map[string][]textImportCandidate
+}
+
+// pick chooses the printing for an entry: its Scryfall ID, then its set and collector
+// number, then the newest paper printing with its name
+func (c batchAddCandidates) pick(entry BatchAddEntry) (textImportCandidate, bool) {
+	if entry.ScryfallID != "" {
+		candidate, ok := c.byID[entry.ScryfallID]
+		return candidate, ok
+	}
+	if entry.CollectorNumber != "" {
+		candidate, ok := c.byNumber[entry.SetCode+"|"+entry.CollectorNumber]
+		return candidate, ok
+	}
+	return pickTextImportPrinting(c.byName[strings.ToLower(entry.Name)], TextImportLine{Name: entry.Name, SetCode: entry.SetCode})
+}
+
+// loadBatchAddCandidates fetches every printing any valid entry could refer to in one query
+func loadBatchAddCandidates(ctx context.Context, db *gorm.DB, entries []BatchAddEntry, results []BatchAddResult) (batchAddCandidates, error) {
+	candidates := batchAddCandidates{
+		byID:     make(map[string]textImportCandidate),
+		byNumber: make(map[string]textImportCandidate),
+		byName:   make(map[string][]textImportCandidate),
+	}
+
+	var ids, setCodes, numbers, names []string
+	for i, entry := range entries {
+		switch {
+		case results[i].Status != "":
+		case entry.ScryfallID != "":
+			ids = append(ids, entry.ScryfallID)
+		case entry.CollectorNumber != "":
+			if !slices.Contains(setCodes, entry.SetCode) {
+				setCodes = append(setCodes, entry.SetCode)
+			}
+			numbers = append(numbers, entry.SetCode+"|"+entry.CollectorNumber)
+		default:
+			names = append(names, strings.ToLower(entry.Name))
+		}
+	}
+
+	// Only the identifier kinds in the batch are queried, each through an index
+	var conditions []string
+	var args []any
+	if len(ids) > 0 {
+		conditions = append(conditions, "scryfall_id IN ?")
+		args = append(args, ids)
+	}
+	if len(numbers) > 0 {
+		conditions = append(conditions, "(set_code IN ? AND set_code || '|' || collector_number IN ?)")
+		args = append(args, setCodes, numbers)
+	}
+	if len(names) > 0 {
+		conditions = append(conditions, "("+cardNameMatchSQL+")")
+		args = append(args, names, names)
+	}
+	if len(conditions) == 0 {
+		return candidates, nil
+	}
+
+	var cards []models.Card
+	if err := db.WithContext(ctx).
+		Where(strings.Join(conditions, " OR "), args...).
+		Find(&cards).Error; err != nil {
+		return candidates, fmt.Errorf("looking up cards: %w", err)
+	}
+
+	for _, card := range cards {
+		candidate, ok := newTextImportCandidate(card)
+		if !ok || candidate.OracleID == "" {
+			continue
+		}
+		candidates.byID[candidate.ScryfallID] = candidate
+		candidates.byNumber[candidate.SetCode+"|"+candidate.CollectorNumber] = candidate
+		for _, key := range []string{strings.ToLower(candidate.Name), strings.ToLower(candidate.FrontFaceName)} {
+			if key != "" {
+				candidates.byName[key] = append(candidates.byName[key], candidate)
+			}
+		}
+	}
+	return candidates, nil
+}
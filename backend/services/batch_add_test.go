@@ -0,0 +1,123 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupBatchAddTest(t *testing.T) (*BatchAddService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","collector_number":"146","released_at":"2009-07-17"}`},
+		{ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"2x2","collector_number":"117","released_at":"2022-07-08"}`},
+		{ScryfallID: "bolt-digital", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"prm","collector_number":"1","released_at":"2024-01-01","digital":true}`},
+		{ScryfallID: "delver", OracleID: "oracle-delver", RawJSON: `{"name":"Delver of Secrets // Insectile Aberration","set":"isd","collector_number":"51","card_faces":[{"name":"Delver of Secrets"},{"name":"Insectile Aberration"}]}`},
+		{ScryfallID: "token", RawJSON: `{"name":"Goblin","set":"tm10","collector_number":"5"}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	return NewBatchAddService(db), db
+}
+
+func TestBatchAddService_Add(t *testing.T) {
+	service, db := setupBatchAddTest(t)
+	ctx := context.Background()
+
+	location := models.StorageLocation{Name: "Intake", StorageType: models.Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+
+	entries := []BatchAddEntry{
+		{ScryfallID: "bolt-m10", Quantity: 3},
+		{SetCode: "2X2", CollectorNumber: "0117", Treatment: "foil"},
+		{Name: "Lightning Bolt"},
+		{Name: "Lightning Bolt", SetCode: "m10"},
+		{Name: "delver of secrets"},
+		{ScryfallID: "missing"},
+		{SetCode: "m10", CollectorNumber: "999"},
+		{ScryfallID: "token"},
+		{CollectorNumber: "146"},
+		{Name: "Lightning Bolt", Quantity: -1},
+		{},
+	}
+	results, err := service.Add(ctx, entries, &location.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		status     BatchAddStatus
+		scryfallID string
+	}{
+		{BatchAddStatusAdded, "bolt-m10"},
+		{BatchAddStatusAdded, "bolt-2x2"},
+		{BatchAddStatusAdded, "bolt-2x2"},
+		{BatchAddStatusAdded, "bolt-m10"},
+		{BatchAddStatusAdded, "delver"},
+		{BatchAddStatusNotFound, "missing"},
+		{BatchAddStatusNotFound, ""},
+		{BatchAddStatusNotFound, "token"}, // No oracle ID, so it can't be inventoried
+		{BatchAddStatusInvalid, ""},
+		{BatchAddStatusInvalid, ""},
+		{BatchAddStatusInvalid, ""},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Index != i || got.Status != want.status || got.ScryfallID != want.scryfallID {
+			t.Errorf("entry %d: expected %s %q, got %+v", i, want.status, want.scryfallID, got)
+		}
+		if (got.Status == BatchAddStatusAdded) != (got.InventoryID != 0) {
+			t.Errorf("entry %d: expected an inventory ID only when added, got %+v", i, got)
+		}
+	}
+
+	var items []models.Inventory
+	db.Order("id").Find(&items)
+	if len(items) != 5 {
+		t.Fatalf("expected 5 inventory rows, got %d", len(items))
+	}
+	if items[0].Quantity != 3 || items[0].Treatment != "nonfoil" || items[1].Quantity != 1 || items[1].Treatment != "foil" {
+		t.Errorf("unexpected quantities or treatments: %+v %+v", items[0], items[1])
+	}
+	for _, item := range items {
+		if item.StorageLocationID == nil || *item.StorageLocationID != location.ID {
+			t.Errorf("expected item %d in the chosen location, got %v", item.ID, item.StorageLocationID)
+		}
+	}
+
+	var events int64
+	db.Model(&models.InventoryEvent{}).Count(&events)
+	if events != 5 {
+		t.Errorf("expected 5 created events, got %d", events)
+	}
+
+	// The caller's entries are left as they were
+	if entries[1].SetCode != "2X2" || entries[2].Quantity != 0 {
+		t.Errorf("expected entries to be left unchanged, got %+v %+v", entries[1], entries[2])
+	}
+}
+
+func TestBatchAddService_Add_TooMany(t *testing.T) {
+	service, _ := setupBatchAddTest(t)
+
+	entries := make([]BatchAddEntry, MaxBatchAddEntries+1)
+	if _, err := service.Add(context.Background(), entries, nil); !errors.Is(err, ErrTooManyBatchAddEntries) {
+		t.Errorf("expected ErrTooManyBatchAddEntries, got %v", err)
+	}
+}
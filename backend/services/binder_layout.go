@@ -0,0 +1,237 @@
+package services
+
+import (
+	"backend/models"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultBinderPockets is the page size used when none is requested
+const DefaultBinderPockets = 9
+
+// binderGrids maps supported pockets per page to their rows and columns
+var binderGrids = map[int][2]int{
+	9:  {3, 3},
+	12: {3, 4},
+}
+
+// BinderSort orders the cards laid out in a binder
+// tygo:export
+type BinderSort string
+
+const (
+	BinderSortSet   BinderSort = "set"   // Set code, then collector number
+	BinderSortName  BinderSort = "name"  // Card name
+	BinderSortColor BinderSort = "color" // WUBRG, then multicolor, then colorless
+	BinderSortPrice BinderSort = "price" // Most valuable first
+)
+
+// Valid reports whether s is a supported sort order
+func (s BinderSort) Valid() bool {
+	switch s {
+	case BinderSortSet, BinderSortName, BinderSortColor, BinderSortPrice:
+		return true
+	}
+	return false
+}
+
+var (
+	// ErrNotBinder is returned when a layout is requested for a location that is not a binder
+	ErrNotBinder = errors.New("storage location is not a binder")
+	// ErrInvalidBinderPockets is returned for an unsupported pockets-per-page value
+	ErrInvalidBinderPockets = errors.New("pockets must be 9 or 12")
+	// ErrInvalidBinderSort is returned for an unknown sort order
+	ErrInvalidBinderSort = errors.New("sort must be set, name, color, or price")
+)
+
+// BinderPocket is one card copy placed in a pocket
+// tygo:export
+type BinderPocket struct {
+	Position        int     `json:"position"` // 1-based, left to right then top to bottom
+	Row             int     `json:"row"`
+	Column          int     `json:"column"`
+	InventoryID     uint    `json:"inventory_id"`
+	ScryfallID      string  `json:"scryfall_id"`
+	Name            string  `json:"name"`
+	SetCode         string  `json:"set_code"`
+	CollectorNumber string  `json:"collector_number"`
+	Treatment       string  `json:"treatment"`
+	Price           float64 `json:"price"`
+}
+
+// BinderPage is one side of a binder sheet
+// tygo:export
+type BinderPage struct {
+	Number  int            `json:"number"`
+	Pockets []BinderPocket `json:"pockets"`
+}
+
+// BinderLayout arranges a binder's contents into pages and pockets
+// tygo:export
+type BinderLayout struct {
+	StorageLocationID   uint            `json:"storage_location_id"`
+	StorageLocationName string          `json:"storage_location_name"`
+	PocketsPerPage      int             `json:"pockets_per_page"`
+	Rows                int             `json:"rows"`
+	Columns             int             `json:"columns"`
+	Sort                BinderSort      `json:"sort"`
+	Currency            models.Currency `json:"currency"`
+	TotalCards          int             `json:"total_cards"`
+	Pages               []BinderPage    `json:"pages"`
+}
+
+// binderRow is an inventory row in the binder with the card fields the layout needs
+type binderRow struct {
+	InventoryID     uint
+	ScryfallID      string
+	Treatment       string
+	Quantity        int
+	Name            string
+	SetCode         string
+	CollectorNumber string
+	Colors          string
+	Price           float64
+}
+
+// BuildBinderLayout lays out the cards stored in a binder, one pocket per copy, in
+// pages of pocketsPerPage. Prices are in the preferred currency.
+func BuildBinderLayout(ctx context.Context, db *gorm.DB, locationID uint, pocketsPerPage int, sortBy BinderSort) (*BinderLayout, error) {
+	grid, ok := binderGrids[pocketsPerPage]
+	if !ok {
+		return nil, ErrInvalidBinderPockets
+	}
+	if !sortBy.Valid() {
+		return nil, ErrInvalidBinderSort
+	}
+
+	var location models.StorageLocation
+	if err := db.WithContext(ctx).First(&location, locationID).Error; err != nil {
+		return nil, err
+	}
+	if location.StorageType != models.Binder {
+		return nil, ErrNotBinder
+	}
+
+	var rows []binderRow
+	if err := db.WithContext(ctx).Raw(`
+		SELECT i.id AS inventory_id, i.scryfall_id, i.treatment, i.quantity,
+			COALESCE(c.name, '') AS name,
+			COALESCE(c.set_code, '') AS set_code,
+			COALESCE(c.collector_number, '') AS collector_number,
+			COALESCE(c.colors, '') AS colors
+		FROM inventories i
+		LEFT JOIN cards c ON c.scryfall_id = i.scryfall_id
+		WHERE i.storage_location_id = ? AND i.deleted_at IS NULL`, location.ID).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("loading binder contents: %w", err)
+	}
+
+	currency := PreferredCurrency(ctx, db)
+	scryfallIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		scryfallIDs = append(scryfallIDs, row.ScryfallID)
+	}
+	prices, err := models.GetCardPricesByIDs(db.WithContext(ctx), scryfallIDs)
+	if err != nil {
+		return nil, fmt.Errorf("loading binder prices: %w", err)
+	}
+	for i := range rows {
+		rows[i].Price = prices[rows[i].ScryfallID].InCurrency(rows[i].Treatment, currency)
+	}
+	sortBinderRows(rows, sortBy)
+
+	layout := &BinderLayout{
+		StorageLocationID:   location.ID,
+		StorageLocationName: location.Name,
+		PocketsPerPage:      pocketsPerPage,
+		Rows:                grid[0],
+		Columns:             grid[1],
+		Sort:                sortBy,
+		Currency:            currency,
+		Pages:               []BinderPage{},
+	}
+	for _, row := range rows {
+		for range row.Quantity {
+			index := layout.TotalCards % pocketsPerPage
+			if index == 0 {
+				layout.Pages = append(layout.Pages, BinderPage{Number: len(layout.Pages) + 1})
+			}
+			page := &layout.Pages[len(layout.Pages)-1]
+			page.Pockets = append(page.Pockets, BinderPocket{
+				Position:        index + 1,
+				Row:             index/layout.Columns + 1,
+				Column:          index%layout.Columns + 1,
+				InventoryID:     row.InventoryID,
+				ScryfallID:      row.ScryfallID,
+				Name:            row.Name,
+				SetCode:         row.SetCode,
+				CollectorNumber: row.CollectorNumber,
+				Treatment:       row.Treatment,
+				Price:           row.Price,
+			})
+			layout.TotalCards++
+		}
+	}
+
+	return layout, nil
+}
+
+// sortBinderRows orders rows for the layout; ties fall back to name, printing and
+// inventory ID so the layout is stable between requests
+func sortBinderRows(rows []binderRow, sortBy BinderSort) {
+	slices.SortStableFunc(rows, func(a, b binderRow) int {
+		var primary int
+		switch sortBy {
+		case BinderSortSet:
+			primary = cmp.Or(strings.Compare(a.SetCode, b.SetCode), compareCollectorNumbers(a.CollectorNumber, b.CollectorNumber))
+		case BinderSortColor:
+			primary = cmp.Compare(colorRank(a.Colors), colorRank(b.Colors))
+		case BinderSortPrice:
+			primary = cmp.Compare(b.Price, a.Price)
+		}
+		return cmp.Or(
+			primary,
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.SetCode, b.SetCode),
+			compareCollectorNumbers(a.CollectorNumber, b.CollectorNumber),
+			strings.Compare(a.Treatment, b.Treatment),
+			cmp.Compare(a.InventoryID, b.InventoryID),
+		)
+	})
+}
+
+// compareCollectorNumbers orders collector numbers numerically, so "9" comes before
+// "10"; suffixed numbers such as "10a" follow their base number
+func compareCollectorNumbers(a, b string) int {
+	numA, restA := splitCollectorNumber(a)
+	numB, restB := splitCollectorNumber(b)
+	return cmp.Or(cmp.Compare(numA, numB), strings.Compare(restA, restB))
+}
+
+// splitCollectorNumber splits a collector number into its leading number and the rest
+func splitCollectorNumber(number string) (int, string) {
+	end := 0
+	for end < len(number) && number[end] >= '0' && number[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(number[:end])
+	return n, number[end:]
+}
+
+// colorRank places mono-colored cards in WUBRG order, then multicolor, then colorless
+func colorRank(colors string) int {
+	switch len(colors) {
+	case 0:
+		return 6
+	case 1:
+		return strings.Index("WUBRG", colors)
+	default:
+		return 5
+	}
+}
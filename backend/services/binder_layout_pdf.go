@@ -0,0 +1,140 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Printable layout geometry in PDF points (US Letter with half-inch margins)
+const (
+	pdfPageWidth  = 612
+	pdfPageHeight = 792
+	pdfMargin     = 36
+	pdfTitleSize  = 14
+	pdfTextSize   = 9
+)
+
+// PDF renders the layout as a printable document with one page per binder page.
+// Each pocket shows its position, card name, printing, and treatment; unused
+// pockets on the last page are left blank.
+func (l *BinderLayout) PDF() []byte {
+	pages := l.Pages
+	if len(pages) == 0 {
+		// An empty binder still prints a blank grid
+		pages = []BinderPage{{Number: 1}}
+	}
+
+	// Object numbers: 1 catalog, 2 page tree, 3 font, then a page and its content stream per page
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"", // Page tree, filled in once the page objects are numbered
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(pages))
+	for _, page := range pages {
+		content := l.pdfPageContent(page, len(pages))
+		pageObj := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+	return assemblePDF(objects)
+}
+
+// assemblePDF writes numbered objects, the first being the catalog, as a PDF file
+// with its cross-reference table
+func assemblePDF(objects []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// pdfPageContent draws the title and pocket grid for one binder page
+func (l *BinderLayout) pdfPageContent(page BinderPage, totalPages int) string {
+	var b strings.Builder
+
+	title := fmt.Sprintf("%s - page %d of %d", l.StorageLocationName, page.Number, totalPages)
+	writePDFText(&b, pdfMargin, pdfPageHeight-pdfMargin-pdfTitleSize, pdfTitleSize, title)
+
+	gridTop := float64(pdfPageHeight - pdfMargin - pdfTitleSize*2)
+	cellWidth := float64(pdfPageWidth-2*pdfMargin) / float64(l.Columns)
+	cellHeight := (gridTop - pdfMargin) / float64(l.Rows)
+	// Helvetica averages about half an em per character
+	maxChars := int((cellWidth - 8) / (pdfTextSize * 0.5))
+
+	for row := range l.Rows {
+		for column := range l.Columns {
+			x := pdfMargin + float64(column)*cellWidth
+			y := gridTop - float64(row+1)*cellHeight
+			fmt.Fprintf(&b, "%.2f %.2f %.2f %.2f re S\n", x, y, cellWidth, cellHeight)
+		}
+	}
+
+	for _, pocket := range page.Pockets {
+		x := pdfMargin + float64(pocket.Column-1)*cellWidth + 4
+		top := gridTop - float64(pocket.Row-1)*cellHeight
+		lines := []string{
+			fmt.Sprintf("#%d", pocket.Position),
+			pocket.Name,
+			strings.TrimSpace(strings.ToUpper(pocket.SetCode) + " " + pocket.CollectorNumber),
+			pocket.Treatment,
+		}
+		for i, line := range lines {
+			writePDFText(&b, x, top-float64(i+1)*(pdfTextSize+3), pdfTextSize, truncatePDFText(line, maxChars))
+		}
+	}
+
+	return b.String()
+}
+
+// writePDFText draws one line of text with its baseline at (x, y)
+func writePDFText(b *strings.Builder, x, y float64, size int, text string) {
+	fmt.Fprintf(b, "BT /F1 %d Tf %.2f %.2f Td (%s) Tj ET\n", size, x, y, escapePDFText(text))
+}
+
+// truncatePDFText shortens text to maxChars, marking the cut with "..."
+func truncatePDFText(text string, maxChars int) string {
+	runes := []rune(text)
+	if len(runes) <= maxChars || maxChars < 4 {
+		return text
+	}
+	return string(runes[:maxChars-3]) + "..."
+}
+
+// escapePDFText encodes text as a WinAnsi PDF string literal body. Characters outside
+// Latin-1 have no glyph in the standard font and are replaced with "?".
+func escapePDFText(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
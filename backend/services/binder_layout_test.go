@@ -0,0 +1,210 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupBinderLayoutTest(t *testing.T) (*gorm.DB, models.StorageLocation) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	binder := models.StorageLocation{Name: "Trade Binder", StorageType: models.Binder}
+	if err := db.Create(&binder).Error; err != nil {
+		t.Fatalf("failed to create binder: %v", err)
+	}
+	return db, binder
+}
+
+func createBinderTestCard(t *testing.T, db *gorm.DB, binderID uint, id, name, set, number, colors, price string, quantity int) {
+	t.Helper()
+
+	rawJSON := fmt.Sprintf(`{"id":%q,"name":%q,"set":%q,"collector_number":%q,"colors":%s,"prices":{"usd":%q}}`,
+		id, name, set, number, colors, price)
+	if err := db.Create(&models.Card{ScryfallID: id, OracleID: "oracle-" + id, RawJSON: rawJSON}).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+	item := models.Inventory{ScryfallID: id, OracleID: "oracle-" + id, Treatment: "nonfoil", Quantity: quantity, StorageLocationID: &binderID}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+}
+
+func pocketNames(layout *BinderLayout) []string {
+	var names []string
+	for _, page := range layout.Pages {
+		for _, pocket := range page.Pockets {
+			names = append(names, pocket.Name)
+		}
+	}
+	return names
+}
+
+func TestBuildBinderLayout_Pages(t *testing.T) {
+	db, binder := setupBinderLayoutTest(t)
+	ctx := context.Background()
+
+	createBinderTestCard(t, db, binder.ID, "a", "Lightning Bolt", "m10", "146", `["R"]`, "2.00", 4)
+	createBinderTestCard(t, db, binder.ID, "b", "Counterspell", "mh2", "267", `["U"]`, "1.00", 7)
+
+	layout, err := BuildBinderLayout(ctx, db, binder.ID, 9, BinderSortSet)
+	if err != nil {
+		t.Fatalf("BuildBinderLayout failed: %v", err)
+	}
+
+	if layout.TotalCards != 11 || len(layout.Pages) != 2 {
+		t.Fatalf("expected 11 cards on 2 pages, got %d cards on %d pages", layout.TotalCards, len(layout.Pages))
+	}
+	if len(layout.Pages[0].Pockets) != 9 || len(layout.Pages[1].Pockets) != 2 {
+		t.Errorf("expected 9 + 2 pockets, got %d + %d", len(layout.Pages[0].Pockets), len(layout.Pages[1].Pockets))
+	}
+	if layout.Rows != 3 || layout.Columns != 3 {
+		t.Errorf("expected a 3x3 grid, got %dx%d", layout.Rows, layout.Columns)
+	}
+
+	// m10 sorts before mh2, so the four Bolts come first
+	last := layout.Pages[0].Pockets[8]
+	if last.Position != 9 || last.Row != 3 || last.Column != 3 || last.Name != "Counterspell" {
+		t.Errorf("unexpected last pocket on page 1: %+v", last)
+	}
+	if first := layout.Pages[0].Pockets[0]; first.Name != "Lightning Bolt" || first.Price != 2.0 {
+		t.Errorf("unexpected first pocket: %+v", first)
+	}
+	if next := layout.Pages[1].Pockets[0]; next.Position != 1 || next.Row != 1 || next.Column != 1 {
+		t.Errorf("expected page 2 to restart at position 1, got %+v", next)
+	}
+
+	twelve, err := BuildBinderLayout(ctx, db, binder.ID, 12, BinderSortSet)
+	if err != nil {
+		t.Fatalf("BuildBinderLayout failed: %v", err)
+	}
+	if len(twelve.Pages) != 1 || twelve.Rows != 3 || twelve.Columns != 4 {
+		t.Errorf("expected a single 3x4 page, got %d pages of %dx%d", len(twelve.Pages), twelve.Rows, twelve.Columns)
+	}
+	if pocket := twelve.Pages[0].Pockets[4]; pocket.Row != 2 || pocket.Column != 1 {
+		t.Errorf("expected position 5 at row 2 column 1, got row %d column %d", pocket.Row, pocket.Column)
+	}
+}
+
+func TestBuildBinderLayout_Sort(t *testing.T) {
+	db, binder := setupBinderLayoutTest(t)
+	ctx := context.Background()
+
+	createBinderTestCard(t, db, binder.ID, "a", "Sol Ring", "c21", "263", `[]`, "1.50", 1)
+	createBinderTestCard(t, db, binder.ID, "b", "Lightning Bolt", "c21", "9", `["R"]`, "2.00", 1)
+	createBinderTestCard(t, db, binder.ID, "c", "Niv-Mizzet", "c21", "10", `["U","R"]`, "0.50", 1)
+	createBinderTestCard(t, db, binder.ID, "d", "Swords to Plowshares", "c21", "10a", `["W"]`, "3.00", 1)
+
+	tests := []struct {
+		sort     BinderSort
+		expected []string
+	}{
+		{BinderSortSet, []string{"Lightning Bolt", "Niv-Mizzet", "Swords to Plowshares", "Sol Ring"}},
+		{BinderSortName, []string{"Lightning Bolt", "Niv-Mizzet", "Sol Ring", "Swords to Plowshares"}},
+		{BinderSortColor, []string{"Swords to Plowshares", "Lightning Bolt", "Niv-Mizzet", "Sol Ring"}},
+		{BinderSortPrice, []string{"Swords to Plowshares", "Lightning Bolt", "Sol Ring", "Niv-Mizzet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.sort), func(t *testing.T) {
+			layout, err := BuildBinderLayout(ctx, db, binder.ID, 9, tt.sort)
+			if err != nil {
+				t.Fatalf("BuildBinderLayout failed: %v", err)
+			}
+			if names := pocketNames(layout); !slices.Equal(names, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestBuildBinderLayout_Errors(t *testing.T) {
+	db, binder := setupBinderLayoutTest(t)
+	ctx := context.Background()
+
+	box := models.StorageLocation{Name: "Bulk Box", StorageType: models.Box}
+	if err := db.Create(&box).Error; err != nil {
+		t.Fatalf("failed to create box: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		locationID uint
+		pockets    int
+		sort       BinderSort
+		expected   error
+	}{
+		{"Not a binder", box.ID, 9, BinderSortSet, ErrNotBinder},
+		{"Unsupported pockets", binder.ID, 16, BinderSortSet, ErrInvalidBinderPockets},
+		{"Unknown sort", binder.ID, 9, "rarity", ErrInvalidBinderSort},
+		{"Missing location", 999, 9, BinderSortSet, gorm.ErrRecordNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BuildBinderLayout(ctx, db, tt.locationID, tt.pockets, tt.sort); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestBinderLayout_PDF(t *testing.T) {
+	db, binder := setupBinderLayoutTest(t)
+
+	createBinderTestCard(t, db, binder.ID, "a", "Lim-Dûl's Vault (Promo)", "all", "1", `["U","B"]`, "5.00", 10)
+
+	layout, err := BuildBinderLayout(context.Background(), db, binder.ID, 9, BinderSortSet)
+	if err != nil {
+		t.Fatalf("BuildBinderLayout failed: %v", err)
+	}
+
+	pdf := layout.PDF()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("expected a complete PDF document")
+	}
+	if !bytes.Contains(pdf, []byte("/Count 2")) {
+		t.Error("expected two pages for ten cards in a 9-pocket binder")
+	}
+	if !bytes.Contains(pdf, []byte(`(Lim-D\373l's Vault \(Promo\)) Tj`)) {
+		t.Error("expected the card name escaped in WinAnsi encoding")
+	}
+	if !strings.Contains(string(pdf), "Trade Binder - page 2 of 2") {
+		t.Error("expected a page title")
+	}
+
+	// The xref offset must point at the cross-reference table
+	var startXref int
+	tail := pdf[bytes.LastIndex(pdf, []byte("startxref")):]
+	if _, err := fmt.Sscanf(string(tail), "startxref\n%d", &startXref); err != nil {
+		t.Fatalf("failed to read startxref: %v", err)
+	}
+	if !bytes.HasPrefix(pdf[startXref:], []byte("xref\n")) {
+		t.Error("startxref does not point at the xref table")
+	}
+}
+
+func TestBinderLayout_PDF_Empty(t *testing.T) {
+	layout := &BinderLayout{StorageLocationName: "Empty", Rows: 3, Columns: 3}
+	if pdf := layout.PDF(); !bytes.Contains(pdf, []byte("/Count 1")) {
+		t.Error("expected an empty binder to print one blank page")
+	}
+}
+
+func TestCompareCollectorNumbers(t *testing.T) {
+	numbers := []string{"10a", "100", "9", "10", "★1"}
+	slices.SortFunc(numbers, compareCollectorNumbers)
+	if expected := []string{"★1", "9", "10", "10a", "100"}; !slices.Equal(numbers, expected) {
+		t.Errorf("expected %v, got %v", expected, numbers)
+	}
+}
@@ -7,10 +7,12 @@ import (
 	"backend/version"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
 	scryfall "github.com/BlueMonday/go-scryfall"
@@ -19,9 +21,6 @@ import (
 )
 
 const (
-	// BulkDataBatchSize is the number of cards processed in each database insert
-	BulkDataBatchSize = 1000
-
 	// BulkDataMaxFailureRate is the maximum acceptable failure rate for bulk imports
 	// Real-world Scryfall data has ~0.1-0.5% failures due to incomplete card data
 	// If failures exceed this threshold, the job is marked as failed
@@ -29,26 +28,54 @@ const (
 
 	// BulkDataTypeAllCards is the Scryfall bulk data type for all cards
 	BulkDataTypeAllCards = "all_cards"
+
+	// BulkDataModeIncremental skips files already imported and only writes cards whose
+	// content changed; BulkDataModeFull rewrites every card. Set via bulk_data_update_mode.
+	BulkDataModeIncremental = "incremental"
+	BulkDataModeFull        = "full"
 )
 
+// ErrJobNotResumable is returned when resuming a job that isn't a stopped bulk import with a checkpoint
+var ErrJobNotResumable = errors.New("only a cancelled, failed or interrupted bulk data import with a checkpoint can be resumed")
+
 // BulkDataService handles bulk data download and import
 type BulkDataService struct {
 	db              *gorm.DB
 	jobService      *JobService
 	settingsService *SettingsService
+	standardService *StandardLegalityService
+	legalityAlerts  *LegalityAlertService
+	importDigests   *ImportDigestService
+	onImportDone    []func()     // Called after every import run, successful or not
 	httpClient      *http.Client // short-lived API requests
 	downloadClient  *http.Client // long-running bulk downloads
+	retryBackoff    RetryBackoff // Wait between automatic retries of a transient failure
 }
 
 // NewBulkDataService creates a new bulk data service
 func NewBulkDataService(db *gorm.DB, jobService *JobService, settingsService *SettingsService) *BulkDataService {
-	return &BulkDataService{
+	service := &BulkDataService{
 		db:              db,
 		jobService:      jobService,
 		settingsService: settingsService,
+		standardService: NewStandardLegalityService(db),
+		legalityAlerts:  NewLegalityAlertService(db, NewNotificationService(db)),
+		importDigests:   NewImportDigestService(db, NewNotificationService(db)),
 		httpClient:      &http.Client{Timeout: 30 * time.Second},
 		downloadClient:  &http.Client{Timeout: 30 * time.Minute},
+		retryBackoff:    DefaultRetryBackoff,
+	}
+	if jobService != nil {
+		jobService.RegisterRetry(models.JobTypeBulkDataImport, service.retryImport)
+		jobService.RegisterResume(models.JobTypeBulkDataImport, service.resumeInterrupted)
 	}
+	return service
+}
+
+// OnImportComplete registers fn to be called after every import run, including ones
+// that fail partway, since cards already written stay changed
+func (s *BulkDataService) OnImportComplete(fn func()) {
+	s.onImportDone = append(s.onImportDone, fn)
 }
 
 // CreateImportJob creates a new job for bulk data import
@@ -74,11 +101,11 @@ func (s *BulkDataService) TriggerInitialImport(ctx context.Context) error {
 	}
 
 	if hasData {
-		slog.Info("bulk data already exists, skipping initial import")
+		slog.InfoContext(ctx, "bulk data already exists, skipping initial import")
 		return nil
 	}
 
-	slog.Info("no bulk data found, triggering initial import")
+	slog.InfoContext(ctx, "no bulk data found, triggering initial import")
 
 	// Create and start import job
 	job, err := s.CreateImportJob(ctx)
@@ -86,24 +113,22 @@ func (s *BulkDataService) TriggerInitialImport(ctx context.Context) error {
 		return fmt.Errorf("failed to create initial import job: %w", err)
 	}
 
-	slog.Info("initial import job created", "job_id", job.ID)
+	slog.InfoContext(ctx, "initial import job created", "job_id", job.ID)
 
 	// Record that an import was just triggered so the scheduler's catch-up
 	// doesn't create a duplicate job before this one finishes.
 	if err := s.settingsService.SetTime(ctx, "bulk_data_last_update", time.Now()); err != nil {
-		slog.Warn("failed to record initial import time", "error", err)
+		slog.WarnContext(ctx, "failed to record initial import time", "error", err)
 	}
 
 	// Run import in background with context
-	go func() {
+	return s.jobService.Go(ctx, job.ID, "initial bulk data import", func(ctx context.Context) error {
 		if err := s.DownloadAndImport(ctx, job.ID); err != nil {
-			slog.Error("initial bulk data import failed", "error", err)
-		} else {
-			slog.Info("initial bulk data import completed successfully")
+			return err
 		}
-	}()
-
-	return nil
+		slog.InfoContext(ctx, "initial bulk data import completed successfully")
+		return nil
+	})
 }
 
 // BulkDataInfo represents a bulk data file from Scryfall's API
@@ -135,10 +160,129 @@ type JobMetadata struct {
 	FailedCards     int      `json:"failed_cards"`
 	FailureExamples []string `json:"failure_examples"` // First 10 failures, max 100 chars each
 	Phase           string   `json:"phase"`            // "downloading", "importing", "completed"
+
+	// Resume state: cards read from DownloadURI whose batches are committed. A resumed
+	// import re-reads the same file and skips this many cards.
+	DownloadURI string `json:"download_uri,omitempty"`
+	Checkpoint  int    `json:"checkpoint,omitempty"`
+
+	// Mode is BulkDataModeIncremental or BulkDataModeFull; in incremental mode
+	// UnchangedCards counts processed cards that matched what was already stored
+	Mode            string `json:"mode,omitempty"`
+	UnchangedCards  int    `json:"unchanged_cards,omitempty"`
+	SourceUpdatedAt string `json:"source_updated_at,omitempty"` // Bulk file's updated_at, recorded on success
+
+	// Import tuning the job runs with; a resumed import keeps it
+	BatchSize       int `json:"batch_size,omitempty"`
+	TransactionSize int `json:"transaction_size,omitempty"`
+
+	// Automatic retries after transient failures, oldest first
+	Retries []JobRetry `json:"retries,omitempty"`
+}
+
+// cardHashLookupBatchSize keeps content hash lookups under SQLite's bound parameter limit
+const cardHashLookupBatchSize = 5000
+
+// cardUpsertColumns are overwritten when an imported card already exists
+var cardUpsertColumns = []string{
+	"raw_json", "oracle_id", "collector_number", "rarity", "cmc", "colors", "color_identity", "card_type",
+	"price_usd", "price_usd_foil", "price_usd_etched", "price_eur", "price_eur_foil", "price_tix", "content_hash",
 }
 
 // DownloadAndImport downloads and imports bulk data from Scryfall with context support
 func (s *BulkDataService) DownloadAndImport(ctx context.Context, jobID uint) error {
+	return s.runImport(ctx, jobID, JobMetadata{})
+}
+
+// DownloadAndImportTuned is DownloadAndImport with this job's non-zero overrides of the
+// import_batch_size and import_transaction_size settings
+func (s *BulkDataService) DownloadAndImportTuned(ctx context.Context, jobID uint, overrides ImportTuning) error {
+	return s.runImport(ctx, jobID, JobMetadata{BatchSize: overrides.BatchSize, TransactionSize: overrides.TransactionSize})
+}
+
+// PrepareResume checks that a bulk import was stopped with a checkpoint and claims it,
+// moving it back to pending so it can't be resumed twice. It returns ErrJobNotResumable
+// for any other job.
+func (s *BulkDataService) PrepareResume(ctx context.Context, jobID uint) (JobMetadata, error) {
+	job, err := s.jobService.Get(ctx, jobID)
+	if err != nil {
+		return JobMetadata{}, err
+	}
+	if job.Type != models.JobTypeBulkDataImport ||
+		(job.Status != models.JobStatusCancelled && job.Status != models.JobStatusFailed && job.Status != models.JobStatusInterrupted) {
+		return JobMetadata{}, ErrJobNotResumable
+	}
+
+	var checkpoint JobMetadata
+	if err := json.Unmarshal([]byte(job.Metadata), &checkpoint); err != nil || checkpoint.DownloadURI == "" || checkpoint.Checkpoint == 0 {
+		return JobMetadata{}, ErrJobNotResumable
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ?", jobID, job.Status).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusPending,
+			"error":        "",
+			"completed_at": nil,
+		})
+	if result.Error != nil {
+		return JobMetadata{}, fmt.Errorf("claiming job %d for resume: %w", jobID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Resumed by another request in the meantime
+		return JobMetadata{}, ErrJobNotResumable
+	}
+	return checkpoint, nil
+}
+
+// ResumeImport continues a bulk import claimed by PrepareResume from its checkpoint, under the same job
+func (s *BulkDataService) ResumeImport(ctx context.Context, jobID uint, checkpoint JobMetadata) error {
+	slog.InfoContext(ctx, "resuming bulk data import", "job_id", jobID, "checkpoint", checkpoint.Checkpoint)
+	return s.runImport(ctx, jobID, checkpoint)
+}
+
+// retryImport runs a failed import again from the start, with the tuning it ran with
+func (s *BulkDataService) retryImport(ctx context.Context, job *models.Job) error {
+	var previous JobMetadata
+	if err := json.Unmarshal([]byte(job.Metadata), &previous); err != nil {
+		previous = JobMetadata{}
+	}
+	slog.InfoContext(ctx, "retrying bulk data import", "job_id", job.ID)
+	return s.runImport(ctx, job.ID, JobMetadata{BatchSize: previous.BatchSize, TransactionSize: previous.TransactionSize})
+}
+
+// resumeInterrupted continues an import interrupted by shutdown from its last committed
+// batch, or from the start with the same tuning if it hadn't committed one
+func (s *BulkDataService) resumeInterrupted(ctx context.Context, job *models.Job) error {
+	var checkpoint JobMetadata
+	if err := json.Unmarshal([]byte(job.Metadata), &checkpoint); err != nil {
+		checkpoint = JobMetadata{}
+	}
+	if checkpoint.DownloadURI == "" || checkpoint.Checkpoint == 0 {
+		checkpoint = JobMetadata{BatchSize: checkpoint.BatchSize, TransactionSize: checkpoint.TransactionSize, Retries: checkpoint.Retries}
+	}
+	return s.ResumeImport(ctx, job.ID, checkpoint)
+}
+
+// retryCheckpoint returns where an automatic retry picks up: the job's last committed
+// batch when it got as far as downloading, or the start otherwise
+func (s *BulkDataService) retryCheckpoint(ctx context.Context, jobID uint, previous JobMetadata) JobMetadata {
+	job, err := s.jobService.Get(ctx, jobID)
+	if err != nil {
+		return previous
+	}
+	var latest JobMetadata
+	if err := json.Unmarshal([]byte(job.Metadata), &latest); err != nil || latest.DownloadURI == "" {
+		return previous
+	}
+	return latest
+}
+
+// runImport runs a bulk import job, starting after checkpoint.Checkpoint cards
+func (s *BulkDataService) runImport(ctx context.Context, jobID uint, checkpoint JobMetadata) error {
+	ctx, release := s.jobService.Cancellable(ctx, jobID)
+	defer release()
+
 	// Update job status to in progress
 	if err := s.jobService.Start(ctx, jobID); err != nil {
 		return fmt.Errorf("failed to start job: %w", err)
@@ -146,21 +290,63 @@ func (s *BulkDataService) DownloadAndImport(ctx context.Context, jobID uint) err
 
 	// Update settings to show import is in progress
 	if err := s.settingsService.Set(ctx, "bulk_data_last_update_status", "in_progress"); err != nil {
-		slog.Warn("failed to update status setting", "error", err)
+		slog.WarnContext(ctx, "failed to update status setting", "error", err)
+	}
+
+	// Capture owned cards' legalities so bans in the new data can be detected
+	legalitiesBefore, snapshotErr := s.legalityAlerts.Snapshot(ctx)
+	if snapshotErr != nil {
+		slog.WarnContext(ctx, "failed to snapshot owned card legalities", "error", snapshotErr)
+	}
+	// and their printings and prices for the post-import digest
+	digestBefore, digestErr := s.importDigests.Snapshot(ctx)
+	if digestErr != nil {
+		slog.WarnContext(ctx, "failed to snapshot owned cards for the import digest", "error", digestErr)
+	}
+
+	// Perform the download and import with context, retrying transient failures from
+	// the last committed batch
+	err := retryTransient(ctx, s.settingsService, s.retryBackoff, jobID, func() error {
+		return s.downloadAndImportInternal(ctx, jobID, checkpoint)
+	}, func(retry JobRetry) {
+		next := s.retryCheckpoint(ctx, jobID, checkpoint)
+		next.Phase = "waiting_to_retry"
+		next.Retries = append(checkpoint.Retries, retry)
+		s.updateJobMetadata(ctx, jobID, next)
+		checkpoint = next
+	})
+	for _, fn := range s.onImportDone {
+		fn()
 	}
+	if err != nil {
+		// The job's context may be cancelled, but recording the outcome still has to happen
+		cleanupCtx := context.WithoutCancel(ctx)
+		status := "failed"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+			// Stopped by shutdown rather than Cancel, so resume it from its checkpoint on
+			// the next start
+			interrupted, interruptErr := s.jobService.Interrupt(cleanupCtx, jobID, err.Error())
+			if interruptErr != nil {
+				slog.ErrorContext(ctx, "failed to mark job as interrupted", "job_id", jobID, "error", interruptErr)
+			}
+			if interrupted {
+				status = "interrupted"
+			}
+		}
 
-	// Perform the download and import with context
-	if err := s.downloadAndImportInternal(ctx, jobID); err != nil {
-		// Mark job as failed
-		if failErr := s.jobService.Fail(ctx, jobID, err.Error()); failErr != nil {
-			slog.Error("failed to mark job as failed", "job_id", jobID, "error", failErr)
+		// Mark job as failed (a job cancelled via Cancel keeps its cancelled status)
+		if status != "interrupted" {
+			if failErr := s.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+				slog.ErrorContext(ctx, "failed to mark job as failed", "job_id", jobID, "error", failErr)
+			}
 		}
 		// Update settings to show failure
-		if setErr := s.settingsService.Set(ctx, "bulk_data_last_update_status", "failed"); setErr != nil {
-			slog.Warn("failed to update status setting", "key", "bulk_data_last_update_status", "error", setErr)
+		if setErr := s.settingsService.Set(cleanupCtx, "bulk_data_last_update_status", status); setErr != nil {
+			slog.WarnContext(ctx, "failed to update status setting", "key", "bulk_data_last_update_status", "error", setErr)
 		}
-		if setErr := s.settingsService.SetTime(ctx, "bulk_data_last_update", time.Now()); setErr != nil {
-			slog.Warn("failed to update time setting", "key", "bulk_data_last_update", "error", setErr)
+		if setErr := s.settingsService.SetTime(cleanupCtx, "bulk_data_last_update", time.Now()); setErr != nil {
+			slog.WarnContext(ctx, "failed to update time setting", "key", "bulk_data_last_update", "error", setErr)
 		}
 		return err
 	}
@@ -172,53 +358,127 @@ func (s *BulkDataService) DownloadAndImport(ctx context.Context, jobID uint) err
 
 	// Update settings to show success
 	if setErr := s.settingsService.Set(ctx, "bulk_data_last_update_status", "success"); setErr != nil {
-		slog.Warn("failed to update status setting", "key", "bulk_data_last_update_status", "error", setErr)
+		slog.WarnContext(ctx, "failed to update status setting", "key", "bulk_data_last_update_status", "error", setErr)
 	}
 	if setErr := s.settingsService.SetTime(ctx, "bulk_data_last_update", time.Now()); setErr != nil {
-		slog.Warn("failed to update time setting", "key", "bulk_data_last_update", "error", setErr)
+		slog.WarnContext(ctx, "failed to update time setting", "key", "bulk_data_last_update", "error", setErr)
+	}
+
+	// New legalities may reflect a Standard rotation
+	if _, err := s.standardService.Recalculate(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to recalculate standard-legal sets", "error", err)
+	}
+
+	// Preview sets whose full card list has now arrived become regular sets
+	if _, err := PromotePreviewSets(ctx, s.db); err != nil {
+		slog.WarnContext(ctx, "failed to promote preview sets", "error", err)
+	}
+
+	var legalityChanges []models.LegalityChange
+	if snapshotErr == nil {
+		changes, err := s.legalityAlerts.DetectChanges(ctx, legalitiesBefore)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to detect legality changes", "error", err)
+		}
+		legalityChanges = changes
+	}
+
+	if digestErr == nil {
+		if _, err := s.importDigests.Build(ctx, jobID, digestBefore, legalityChanges); err != nil {
+			slog.WarnContext(ctx, "failed to build import digest", "job_id", jobID, "error", err)
+		}
 	}
 
 	return nil
 }
 
-func (s *BulkDataService) downloadAndImportInternal(ctx context.Context, jobID uint) error {
-	// Step 1: Fetch bulk data list
-	s.updateJobMetadata(ctx, jobID, JobMetadata{Phase: "fetching_list"})
+func (s *BulkDataService) downloadAndImportInternal(ctx context.Context, jobID uint, checkpoint JobMetadata) error {
+	mode := checkpoint.Mode
+	if mode == "" {
+		mode = s.updateMode(ctx)
+	}
+	incremental := mode == BulkDataModeIncremental
+	tuning := LoadImportTuning(ctx, s.settingsService).WithOverrides(ImportTuning{
+		BatchSize:       checkpoint.BatchSize,
+		TransactionSize: checkpoint.TransactionSize,
+	})
+
+	// Step 1: Fetch bulk data list, unless resuming a file already in progress
+	downloadURI := checkpoint.DownloadURI
+	sourceUpdatedAt := checkpoint.SourceUpdatedAt
+	if downloadURI == "" {
+		s.updateJobMetadata(ctx, jobID, JobMetadata{Phase: "fetching_list", Mode: mode, Retries: checkpoint.Retries})
+
+		bulkDataURL, err := s.settingsService.Get(ctx, "bulk_data_url")
+		if err != nil || bulkDataURL == "" {
+			bulkDataURL = "https://api.scryfall.com/bulk-data"
+			if err != nil {
+				slog.WarnContext(ctx, "failed to get bulk data URL setting, using default", "error", err, "default", bulkDataURL)
+			}
+		}
 
-	bulkDataURL, err := s.settingsService.Get(ctx, "bulk_data_url")
-	if err != nil || bulkDataURL == "" {
-		bulkDataURL = "https://api.scryfall.com/bulk-data"
+		file, err := s.fetchBulkDataFile(ctx, bulkDataURL)
 		if err != nil {
-			slog.Warn("failed to get bulk data URL setting, using default", "error", err, "default", bulkDataURL)
+			return fmt.Errorf("failed to fetch bulk data list: %w", err)
+		}
+		downloadURI = file.DownloadURI
+		if !file.UpdatedAt.IsZero() {
+			sourceUpdatedAt = file.UpdatedAt.UTC().Format(time.RFC3339)
 		}
-	}
 
-	downloadURI, err := s.fetchBulkDataDownloadURI(ctx, bulkDataURL)
-	if err != nil {
-		return fmt.Errorf("failed to fetch bulk data list: %w", err)
+		// Scryfall regenerates the file about once a day; an unchanged file has nothing new
+		if incremental && sourceUpdatedAt != "" {
+			if last, _ := s.settingsService.Get(ctx, "bulk_data_source_updated_at"); last == sourceUpdatedAt {
+				slog.InfoContext(ctx, "bulk data unchanged since last import, skipping", "updated_at", sourceUpdatedAt)
+				s.updateJobMetadata(ctx, jobID, JobMetadata{Phase: "up_to_date", Mode: mode, SourceUpdatedAt: sourceUpdatedAt, Retries: checkpoint.Retries})
+				return nil
+			}
+		}
 	}
 
 	// Step 2: Download and import bulk data file in streaming fashion (UPSERT strategy)
-	s.updateJobMetadata(ctx, jobID, JobMetadata{Phase: "downloading_and_importing"})
-
-	totalProcessed := 0
-	totalFailed := 0
+	totalProcessed := checkpoint.ProcessedCards
+	totalFailed := checkpoint.FailedCards
+	totalUnchanged := checkpoint.UnchangedCards
 	allFailureExamples := make([]string, 0, 10)
+	allFailureExamples = append(allFailureExamples, checkpoint.FailureExamples...)
+	position := checkpoint.Checkpoint
+
+	progress := func(phase string) JobMetadata {
+		return JobMetadata{
+			Phase:           phase,
+			ProcessedCards:  totalProcessed,
+			FailedCards:     totalFailed,
+			FailureExamples: allFailureExamples,
+			DownloadURI:     downloadURI,
+			Checkpoint:      position,
+			Mode:            mode,
+			UnchangedCards:  totalUnchanged,
+			SourceUpdatedAt: sourceUpdatedAt,
+			BatchSize:       tuning.BatchSize,
+			TransactionSize: tuning.TransactionSize,
+			Retries:         checkpoint.Retries,
+		}
+	}
+	s.updateJobMetadata(ctx, jobID, progress("downloading_and_importing"))
 
-	err = s.downloadBulkDataStream(ctx, downloadURI, BulkDataBatchSize, func(batch []scryfall.Card) error {
+	// Each callback batch is one transaction, and so one checkpoint
+	err := s.downloadBulkDataStream(ctx, downloadURI, tuning.TransactionSize, position, func(batch []scryfall.Card) error {
 		// Check context before processing batch
 		if err := ctx.Err(); err != nil {
 			return fmt.Errorf("import cancelled: %w", err)
 		}
 
 		// Import this batch with context
-		batchResult, err := s.importCardsBatch(ctx, batch)
+		batchResult, err := s.importCardsBatch(ctx, batch, incremental, tuning.statementSize())
 		if err != nil {
 			return err
 		}
 
 		totalProcessed += batchResult.SuccessCards
 		totalFailed += batchResult.FailedCards
+		totalUnchanged += batchResult.UnchangedCards
+		position += len(batch)
 
 		// Aggregate failure examples (keep first 10 total)
 		for _, example := range batchResult.FailureExamples {
@@ -227,15 +487,11 @@ func (s *BulkDataService) downloadAndImportInternal(ctx context.Context, jobID u
 			}
 		}
 
-		// Update progress
-		s.updateJobMetadata(ctx, jobID, JobMetadata{
-			Phase:           "downloading_and_importing",
-			ProcessedCards:  totalProcessed,
-			FailedCards:     totalFailed,
-			FailureExamples: allFailureExamples,
-		})
+		// Update progress; the batch is committed, so this is where a resume picks up, and
+		// it is recorded even if the import is being cancelled
+		s.updateJobMetadata(context.WithoutCancel(ctx), jobID, progress("downloading_and_importing"))
 
-		slog.Info("import progress", "processed", totalProcessed, "failed", totalFailed)
+		slog.InfoContext(ctx, "import progress", "processed", totalProcessed, "failed", totalFailed, "unchanged", totalUnchanged)
 		return nil
 	})
 
@@ -256,6 +512,12 @@ func (s *BulkDataService) downloadAndImportInternal(ctx context.Context, jobID u
 		ProcessedCards:  totalProcessed,
 		FailedCards:     totalFailed,
 		FailureExamples: allFailureExamples,
+		Mode:            mode,
+		UnchangedCards:  totalUnchanged,
+		SourceUpdatedAt: sourceUpdatedAt,
+		BatchSize:       tuning.BatchSize,
+		TransactionSize: tuning.TransactionSize,
+		Retries:         checkpoint.Retries,
 	})
 
 	// If failure rate exceeds threshold, return error to mark job as failed
@@ -266,56 +528,72 @@ func (s *BulkDataService) downloadAndImportInternal(ctx context.Context, jobID u
 
 	// If there were failures but below threshold, log warning
 	if totalFailed > 0 {
-		slog.Warn("bulk import completed with warnings", "failed", totalFailed, "total", totalCards, "failure_rate_pct", fmt.Sprintf("%.2f", failureRate*100))
+		slog.WarnContext(ctx, "bulk import completed with warnings", "failed", totalFailed, "total", totalCards, "failure_rate_pct", fmt.Sprintf("%.2f", failureRate*100))
+	}
+
+	// Remember which file was imported so the next incremental run can skip it if unchanged
+	if sourceUpdatedAt != "" {
+		if err := s.settingsService.Set(ctx, "bulk_data_source_updated_at", sourceUpdatedAt); err != nil {
+			slog.WarnContext(ctx, "failed to update status setting", "key", "bulk_data_source_updated_at", "error", err)
+		}
 	}
 
 	return nil
 }
 
-func (s *BulkDataService) fetchBulkDataDownloadURI(ctx context.Context, bulkDataURL string) (string, error) {
+// updateMode reads bulk_data_update_mode, defaulting to incremental
+func (s *BulkDataService) updateMode(ctx context.Context) string {
+	mode, err := s.settingsService.Get(ctx, "bulk_data_update_mode")
+	if err != nil || mode != BulkDataModeFull {
+		return BulkDataModeIncremental
+	}
+	return mode
+}
+
+func (s *BulkDataService) fetchBulkDataFile(ctx context.Context, bulkDataURL string) (BulkDataInfo, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", bulkDataURL, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return BulkDataInfo{}, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("User-Agent", version.UserAgent())
 	req.Header.Set("Accept", "application/json")
 
-	slog.Info("fetching bulk data list", "url", bulkDataURL)
+	slog.InfoContext(ctx, "fetching bulk data list", "url", bulkDataURL)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch bulk data list: %w", err)
+		return BulkDataInfo{}, fmt.Errorf("failed to fetch bulk data list: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		slog.Error("bulk data list request failed",
+		slog.ErrorContext(ctx, "bulk data list request failed",
 			"status", resp.StatusCode,
 			"url", bulkDataURL,
 			"response_body", string(body),
 		)
-		return "", fmt.Errorf("bulk data list returned status %d: %s", resp.StatusCode, string(body))
+		return BulkDataInfo{}, &httpStatusError{what: "bulk data list", statusCode: resp.StatusCode, body: string(body)}
 	}
 
 	var bulkDataList BulkDataListResponse
 	if err := json.NewDecoder(resp.Body).Decode(&bulkDataList); err != nil {
-		return "", fmt.Errorf("failed to decode bulk data list: %w", err)
+		return BulkDataInfo{}, fmt.Errorf("failed to decode bulk data list: %w", err)
 	}
 
 	// Find the "all_cards" bulk data
 	for _, bulkData := range bulkDataList.Data {
 		if bulkData.Type == BulkDataTypeAllCards {
-			return bulkData.DownloadURI, nil
+			return bulkData, nil
 		}
 	}
 
-	return "", fmt.Errorf("%s bulk data not found", BulkDataTypeAllCards)
+	return BulkDataInfo{}, fmt.Errorf("%s bulk data not found", BulkDataTypeAllCards)
 }
 
 // downloadBulkDataStream downloads and streams bulk data, calling the callback
-// for each batch of cards. This avoids loading the entire file into memory.
-func (s *BulkDataService) downloadBulkDataStream(ctx context.Context, downloadURI string, batchSize int, callback func([]scryfall.Card) error) error {
+// for each batch of cards after the first skip. This avoids loading the entire file into memory.
+func (s *BulkDataService) downloadBulkDataStream(ctx context.Context, downloadURI string, batchSize, skip int, callback func([]scryfall.Card) error) error {
 	// Create HTTP request with context
 	req, err := http.NewRequestWithContext(ctx, "GET", downloadURI, nil)
 	if err != nil {
@@ -324,7 +602,7 @@ func (s *BulkDataService) downloadBulkDataStream(ctx context.Context, downloadUR
 	req.Header.Set("User-Agent", version.UserAgent())
 	req.Header.Set("Accept", "application/json")
 
-	slog.Info("downloading bulk data", "url", downloadURI)
+	slog.InfoContext(ctx, "downloading bulk data", "url", downloadURI)
 
 	resp, err := s.downloadClient.Do(req)
 	if err != nil {
@@ -334,12 +612,12 @@ func (s *BulkDataService) downloadBulkDataStream(ctx context.Context, downloadUR
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		slog.Error("bulk data download failed",
+		slog.ErrorContext(ctx, "bulk data download failed",
 			"status", resp.StatusCode,
 			"url", downloadURI,
 			"response_body", string(body),
 		)
-		return fmt.Errorf("bulk data download returned status %d: %s", resp.StatusCode, string(body))
+		return &httpStatusError{what: "bulk data download", statusCode: resp.StatusCode, body: string(body)}
 	}
 
 	decoder := json.NewDecoder(resp.Body)
@@ -362,6 +640,16 @@ func (s *BulkDataService) downloadBulkDataStream(ctx context.Context, downloadUR
 			return fmt.Errorf("download cancelled: %w", err)
 		}
 
+		if skip > 0 {
+			// Already imported before the job was stopped
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return fmt.Errorf("failed to skip card: %w", err)
+			}
+			skip--
+			continue
+		}
+
 		var card scryfall.Card
 		if err := decoder.Decode(&card); err != nil {
 			return fmt.Errorf("failed to decode card: %w", err)
@@ -402,13 +690,14 @@ type BatchImportResult struct {
 	TotalCards      int
 	SuccessCards    int
 	FailedCards     int
+	UnchangedCards  int      // Counted in SuccessCards, but skipped because the stored card matched
 	FailureExamples []string // First 10 failures, max 100 chars each
 }
 
-// importCardsBatch imports a single batch of cards into the database
-// Uses UPSERT (ON CONFLICT) to skip unchanged records for better performance
+// importCardsBatch imports a batch of cards into the database in one transaction,
+// upserting statementSize cards per INSERT (ON CONFLICT) statement.
 // Returns statistics about the import including failure tracking
-func (s *BulkDataService) importCardsBatch(ctx context.Context, cards []scryfall.Card) (BatchImportResult, error) {
+func (s *BulkDataService) importCardsBatch(ctx context.Context, cards []scryfall.Card, incremental bool, statementSize int) (BatchImportResult, error) {
 	result := BatchImportResult{
 		TotalCards:      len(cards),
 		FailureExamples: make([]string, 0),
@@ -439,7 +728,7 @@ func (s *BulkDataService) importCardsBatch(ctx context.Context, cards []scryfall
 				result.FailureExamples = append(result.FailureExamples, failureMsg)
 			}
 
-			slog.Warn("failed to convert card", "scryfall_id", scryfallCard.ID, "name", scryfallCard.Name, "error", err)
+			slog.WarnContext(ctx, "failed to convert card", "scryfall_id", scryfallCard.ID, "name", scryfallCard.Name, "error", err)
 			continue
 		}
 		dbCards = append(dbCards, card)
@@ -448,14 +737,30 @@ func (s *BulkDataService) importCardsBatch(ctx context.Context, cards []scryfall
 	if len(dbCards) == 0 {
 		return result, fmt.Errorf("no valid cards to import in batch")
 	}
+	converted := len(dbCards)
+
+	if incremental {
+		changed, err := s.changedCards(ctx, dbCards)
+		if err != nil {
+			return result, err
+		}
+		result.UnchangedCards = converted - len(changed)
+		dbCards = changed
+		if len(dbCards) == 0 {
+			result.SuccessCards = converted
+			return result, nil
+		}
+	}
 
 	// Use UPSERT to insert or update cards
 	// SQLite syntax: INSERT ... ON CONFLICT(scryfall_id) DO UPDATE SET ...
 	// This skips unchanged records automatically (no UPDATE if values match)
-	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "scryfall_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"raw_json", "oracle_id"}),
-	}).Create(&dbCards).Error; err != nil {
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scryfall_id"}},
+			DoUpdates: clause.AssignmentColumns(cardUpsertColumns),
+		}).CreateInBatches(&dbCards, statementSize).Error
+	}); err != nil {
 		firstID := ""
 		lastName := ""
 		if len(dbCards) > 0 {
@@ -466,18 +771,52 @@ func (s *BulkDataService) importCardsBatch(ctx context.Context, cards []scryfall
 			len(dbCards), firstID, lastName, err)
 	}
 
-	result.SuccessCards = len(dbCards)
+	result.SuccessCards = converted
 	return result, nil
 }
 
+// changedCards drops cards whose stored content hash matches, leaving new and changed ones
+func (s *BulkDataService) changedCards(ctx context.Context, cards []*models.Card) ([]*models.Card, error) {
+	hashes := make(map[string]string, len(cards))
+	for batch := range slices.Chunk(cards, cardHashLookupBatchSize) {
+		ids := make([]string, len(batch))
+		for i, card := range batch {
+			ids[i] = card.ScryfallID
+		}
+
+		var stored []struct {
+			ScryfallID  string
+			ContentHash string
+		}
+		if err := s.db.WithContext(ctx).Model(&models.Card{}).
+			Select("scryfall_id", "content_hash").
+			Where("scryfall_id IN ?", ids).
+			Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("loading stored card hashes: %w", err)
+		}
+		for _, card := range stored {
+			hashes[card.ScryfallID] = card.ContentHash
+		}
+	}
+
+	changed := make([]*models.Card, 0, len(cards))
+	for _, card := range cards {
+		// Cards imported before hashes existed have an empty hash and are rewritten once
+		if hash, ok := hashes[card.ScryfallID]; !ok || hash != card.ContentHash {
+			changed = append(changed, card)
+		}
+	}
+	return changed, nil
+}
+
 func (s *BulkDataService) updateJobMetadata(ctx context.Context, jobID uint, metadata JobMetadata) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		slog.Warn("failed to marshal job metadata", "error", err)
+		slog.WarnContext(ctx, "failed to marshal job metadata", "error", err)
 		return
 	}
 
 	if err := s.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
-		slog.Warn("failed to update job metadata", "error", err)
+		slog.WarnContext(ctx, "failed to update job metadata", "error", err)
 	}
 }
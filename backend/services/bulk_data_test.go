@@ -4,8 +4,10 @@ import (
 	"backend/models"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,6 +31,7 @@ func setupBulkDataServiceTest(t *testing.T) (*BulkDataService, *JobService, *Set
 	jobService := NewJobService(db)
 	settingsService := NewSettingsService(db)
 	bulkDataService := NewBulkDataService(db, jobService, settingsService)
+	bulkDataService.retryBackoff = RetryBackoff{Base: time.Millisecond, Max: time.Millisecond}
 
 	return bulkDataService, jobService, settingsService, db
 }
@@ -203,6 +206,8 @@ func TestBulkDataService_DownloadAndImport_SuccessfulImport(t *testing.T) {
 
 	// Create job
 	job, _ := jobService.Create(context.Background(), models.JobTypeBulkDataImport, "{}")
+	completions := 0
+	service.OnImportComplete(func() { completions++ })
 
 	// Run import
 	err := service.DownloadAndImport(context.Background(), job.ID)
@@ -216,6 +221,9 @@ func TestBulkDataService_DownloadAndImport_SuccessfulImport(t *testing.T) {
 	if count != 2 {
 		t.Errorf("expected 2 cards imported, got %d", count)
 	}
+	if completions != 1 {
+		t.Errorf("expected the completion hook called once, got %d", completions)
+	}
 
 	// Verify job was completed
 	updatedJob, _ := jobService.Get(context.Background(), job.ID)
@@ -381,11 +389,16 @@ func TestBulkDataService_DownloadAndImport_HTTPError(t *testing.T) {
 
 	service.settingsService.Set(context.Background(),"bulk_data_url", server.URL)
 	job, _ := jobService.Create(context.Background(), models.JobTypeBulkDataImport, "{}")
+	completions := 0
+	service.OnImportComplete(func() { completions++ })
 
 	err := service.DownloadAndImport(context.Background(), job.ID)
 	if err == nil {
 		t.Error("expected error for HTTP failure")
 	}
+	if completions != 1 {
+		t.Errorf("expected the completion hook called after a failed import, got %d", completions)
+	}
 
 	updatedJob, _ := jobService.Get(context.Background(), job.ID)
 	if updatedJob.Status != models.JobStatusFailed {
@@ -556,3 +569,511 @@ func TestBulkDataService_DownloadAndImport_PreservesExistingData(t *testing.T) {
 		t.Errorf("expected 2 total cards, got %d", count)
 	}
 }
+
+// Cancel and resume tests
+
+func TestBulkDataService_DownloadAndImport_CancelledByUser(t *testing.T) {
+	service, jobService, settingsService, _ := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bulk-data" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"type": "all_cards", "download_uri": server.URL + "/cards.json"},
+				},
+			})
+			return
+		}
+		// Start the array, then cancel the job while the download is still open
+		w.Write([]byte(`[{"id": "card-1", "oracle_id": "oracle-1", "name": "Card One", "set": "tst"},`))
+		w.(http.Flusher).Flush()
+		if _, err := jobService.Cancel(ctx, job.ID); err != nil {
+			t.Errorf("Cancel failed: %v", err)
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	settingsService.Set(ctx, "bulk_data_url", server.URL+"/bulk-data")
+
+	if err := service.DownloadAndImport(ctx, job.ID); err == nil {
+		t.Fatal("expected error for cancelled job")
+	}
+
+	updatedJob, _ := jobService.Get(ctx, job.ID)
+	if updatedJob.Status != models.JobStatusCancelled {
+		t.Errorf("expected job status %s, got %s", models.JobStatusCancelled, updatedJob.Status)
+	}
+	if status, _ := settingsService.Get(ctx, "bulk_data_last_update_status"); status != "cancelled" {
+		t.Errorf("expected last update status cancelled, got %q", status)
+	}
+}
+
+func TestBulkDataService_ResumeImport_SkipsCheckpoint(t *testing.T) {
+	service, jobService, _, db := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	cards := []scryfall.Card{
+		{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst"},
+		{ID: "card-2", OracleID: "oracle-2", Name: "Card Two", Set: "tst"},
+		{ID: "card-3", OracleID: "oracle-3", Name: "Card Three", Set: "tst"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(cards)
+	}))
+	defer server.Close()
+
+	// A job stopped after committing the first two cards
+	metadata, _ := json.Marshal(JobMetadata{
+		Phase:          "downloading_and_importing",
+		ProcessedCards: 2,
+		DownloadURI:    server.URL + "/cards.json",
+		Checkpoint:     2,
+	})
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, string(metadata))
+	db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{"status": models.JobStatusCancelled, "error": "Cancelled by user"})
+
+	checkpoint, err := service.PrepareResume(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("PrepareResume failed: %v", err)
+	}
+	if checkpoint.Checkpoint != 2 {
+		t.Errorf("expected checkpoint 2, got %d", checkpoint.Checkpoint)
+	}
+
+	// Claimed jobs can't be resumed a second time
+	if _, err := service.PrepareResume(ctx, job.ID); !errors.Is(err, ErrJobNotResumable) {
+		t.Errorf("expected ErrJobNotResumable for a claimed job, got %v", err)
+	}
+
+	if err := service.ResumeImport(ctx, job.ID, checkpoint); err != nil {
+		t.Fatalf("ResumeImport failed: %v", err)
+	}
+
+	var imported []models.Card
+	db.Find(&imported)
+	if len(imported) != 1 || imported[0].ScryfallID != "card-3" {
+		t.Errorf("expected only card-3 to be imported, got %+v", imported)
+	}
+
+	updatedJob, _ := jobService.Get(ctx, job.ID)
+	if updatedJob.Status != models.JobStatusCompleted {
+		t.Errorf("expected job status %s, got %s", models.JobStatusCompleted, updatedJob.Status)
+	}
+	if updatedJob.Error != "" {
+		t.Errorf("expected error cleared on resume, got %q", updatedJob.Error)
+	}
+
+	var final JobMetadata
+	json.Unmarshal([]byte(updatedJob.Metadata), &final)
+	if final.ProcessedCards != 3 {
+		t.Errorf("expected 3 processed cards across both runs, got %d", final.ProcessedCards)
+	}
+}
+
+func TestBulkDataService_DownloadAndImport_InterruptedAndResumed(t *testing.T) {
+	service, jobService, settingsService, db := setupBulkDataServiceTest(t)
+	sqlDB, _ := db.DB()
+	// The test server reads the job while the import runs, so keep a single connection
+	sqlDB.SetMaxOpenConns(1)
+	ctx := context.Background()
+	appCtx, shutdown := context.WithCancel(ctx)
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+
+	card1 := `{"id": "card-1", "oracle_id": "oracle-1", "name": "Card One", "set": "tst"}`
+	card2 := `{"id": "card-2", "oracle_id": "oracle-2", "name": "Card Two", "set": "tst"}`
+	interrupting := true
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bulk-data" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"type": "all_cards", "download_uri": server.URL + "/cards.json"},
+				},
+			})
+			return
+		}
+		if !interrupting {
+			w.Write([]byte("[" + card1 + "," + card2 + "]"))
+			return
+		}
+		// Shut down once the first card's batch is committed
+		w.Write([]byte("[" + card1 + ","))
+		w.(http.Flusher).Flush()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			current, _ := jobService.Get(ctx, job.ID)
+			var metadata JobMetadata
+			json.Unmarshal([]byte(current.Metadata), &metadata)
+			if metadata.Checkpoint == 1 {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		shutdown()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	settingsService.Set(ctx, "bulk_data_url", server.URL+"/bulk-data")
+
+	if err := service.DownloadAndImportTuned(appCtx, job.ID, ImportTuning{BatchSize: 1, TransactionSize: 1}); err == nil {
+		t.Fatal("expected error for an import stopped by shutdown")
+	}
+
+	interrupted, _ := jobService.Get(ctx, job.ID)
+	if interrupted.Status != models.JobStatusInterrupted {
+		t.Fatalf("expected job status %s, got %s", models.JobStatusInterrupted, interrupted.Status)
+	}
+	var metadata JobMetadata
+	json.Unmarshal([]byte(interrupted.Metadata), &metadata)
+	if metadata.Checkpoint != 1 {
+		t.Errorf("expected checkpoint 1, got %d", metadata.Checkpoint)
+	}
+	if status, _ := settingsService.Get(ctx, "bulk_data_last_update_status"); status != "interrupted" {
+		t.Errorf("expected last update status interrupted, got %q", status)
+	}
+
+	// The next start resumes it from the checkpoint
+	interrupting = false
+	workers := NewWorkerPool(1)
+	jobService.SetWorkers(workers)
+	cancelled, err := jobService.CancelStaleJobs(ctx)
+	if err != nil {
+		t.Fatalf("CancelStaleJobs failed: %v", err)
+	}
+	if cancelled != 0 {
+		t.Errorf("expected no stale jobs cancelled, got %d", cancelled)
+	}
+	if err := workers.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	resumed, _ := jobService.Get(ctx, job.ID)
+	if resumed.Status != models.JobStatusCompleted {
+		t.Errorf("expected job status %s, got %s (%s)", models.JobStatusCompleted, resumed.Status, resumed.Error)
+	}
+	var final JobMetadata
+	json.Unmarshal([]byte(resumed.Metadata), &final)
+	if final.ProcessedCards != 2 {
+		t.Errorf("expected 2 processed cards across both runs, got %d", final.ProcessedCards)
+	}
+	var count int64
+	db.Model(&models.Card{}).Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 cards, got %d", count)
+	}
+}
+
+func TestBulkDataService_PrepareResume_NotResumable(t *testing.T) {
+	service, jobService, _, db := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	withCheckpoint, _ := json.Marshal(JobMetadata{DownloadURI: "http://example.invalid/cards.json", Checkpoint: 1000})
+
+	tests := []struct {
+		name     string
+		jobType  models.JobType
+		status   models.JobStatus
+		metadata string
+	}{
+		{"completed", models.JobTypeBulkDataImport, models.JobStatusCompleted, string(withCheckpoint)},
+		{"running", models.JobTypeBulkDataImport, models.JobStatusInProgress, string(withCheckpoint)},
+		{"no checkpoint", models.JobTypeBulkDataImport, models.JobStatusFailed, "{}"},
+		{"other job type", models.JobTypeSetDataImport, models.JobStatusFailed, string(withCheckpoint)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, _ := jobService.Create(ctx, tt.jobType, tt.metadata)
+			db.Model(&models.Job{}).Where("id = ?", job.ID).Update("status", tt.status)
+
+			if _, err := service.PrepareResume(ctx, job.ID); !errors.Is(err, ErrJobNotResumable) {
+				t.Errorf("expected ErrJobNotResumable, got %v", err)
+			}
+		})
+	}
+
+	if _, err := service.PrepareResume(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+// Incremental update tests
+
+// newIncrementalTestServer serves a bulk data list and the current contents of *cards,
+// both of which tests may change between imports
+func newIncrementalTestServer(t *testing.T, updatedAt *string, cards *[]scryfall.Card) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/bulk-data" {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"type":         "all_cards",
+						"download_uri": server.URL + "/cards.json",
+						"updated_at":   *updatedAt,
+					},
+				},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(*cards)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runBulkImport runs one import job to completion and returns its final metadata
+func runBulkImport(t *testing.T, service *BulkDataService, jobService *JobService) JobMetadata {
+	t.Helper()
+	ctx := context.Background()
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if err := service.DownloadAndImport(ctx, job.ID); err != nil {
+		t.Fatalf("DownloadAndImport failed: %v", err)
+	}
+	updated, _ := jobService.Get(ctx, job.ID)
+	var metadata JobMetadata
+	json.Unmarshal([]byte(updated.Metadata), &metadata)
+	return metadata
+}
+
+func storedPrice(t *testing.T, db *gorm.DB, scryfallID string) float64 {
+	t.Helper()
+	var card models.Card
+	if err := db.First(&card, "scryfall_id = ?", scryfallID).Error; err != nil {
+		t.Fatalf("failed to load card %s: %v", scryfallID, err)
+	}
+	if card.PriceUSD == nil {
+		return 0
+	}
+	return *card.PriceUSD
+}
+
+func TestBulkDataService_Incremental_OnlyWritesChangedCards(t *testing.T) {
+	service, jobService, settingsService, db := setupBulkDataServiceTest(t)
+
+	updatedAt := "2024-01-15T09:00:00Z"
+	cards := []scryfall.Card{
+		{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst", Prices: scryfall.Prices{USD: "1.00"}},
+		{ID: "card-2", OracleID: "oracle-2", Name: "Card Two", Set: "tst", Prices: scryfall.Prices{USD: "2.00"}},
+	}
+	server := newIncrementalTestServer(t, &updatedAt, &cards)
+	settingsService.Set(context.Background(), "bulk_data_url", server.URL+"/bulk-data")
+
+	first := runBulkImport(t, service, jobService)
+	if first.Mode != BulkDataModeIncremental || first.UnchangedCards != 0 || first.ProcessedCards != 2 {
+		t.Fatalf("unexpected first import metadata: %+v", first)
+	}
+	if stored, _ := settingsService.Get(context.Background(), "bulk_data_source_updated_at"); stored != updatedAt {
+		t.Errorf("expected source updated_at %s recorded, got %q", updatedAt, stored)
+	}
+
+	// Next day: one price moves and a card is added
+	updatedAt = "2024-01-16T09:00:00Z"
+	cards[1].Prices.USD = "2.50"
+	cards = append(cards, scryfall.Card{ID: "card-3", OracleID: "oracle-3", Name: "Card Three", Set: "tst"})
+
+	second := runBulkImport(t, service, jobService)
+	if second.ProcessedCards != 3 {
+		t.Errorf("expected 3 processed cards, got %d", second.ProcessedCards)
+	}
+	if second.UnchangedCards != 1 {
+		t.Errorf("expected 1 unchanged card, got %d", second.UnchangedCards)
+	}
+	if price := storedPrice(t, db, "card-2"); price != 2.50 {
+		t.Errorf("expected changed price 2.50, got %v", price)
+	}
+
+	var count int64
+	db.Model(&models.Card{}).Count(&count)
+	if count != 3 {
+		t.Errorf("expected 3 cards, got %d", count)
+	}
+}
+
+func TestBulkDataService_Incremental_SkipsUnchangedFile(t *testing.T) {
+	service, jobService, settingsService, db := setupBulkDataServiceTest(t)
+
+	updatedAt := "2024-01-15T09:00:00Z"
+	cards := []scryfall.Card{
+		{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst", Prices: scryfall.Prices{USD: "1.00"}},
+	}
+	server := newIncrementalTestServer(t, &updatedAt, &cards)
+	settingsService.Set(context.Background(), "bulk_data_url", server.URL+"/bulk-data")
+
+	runBulkImport(t, service, jobService)
+
+	// Same file timestamp, so the download is skipped entirely
+	cards[0].Prices.USD = "9.99"
+	metadata := runBulkImport(t, service, jobService)
+	if metadata.Phase != "up_to_date" {
+		t.Errorf("expected phase up_to_date, got %q", metadata.Phase)
+	}
+	if price := storedPrice(t, db, "card-1"); price != 1.00 {
+		t.Errorf("expected stored price untouched, got %v", price)
+	}
+}
+
+func TestBulkDataService_FullMode_RewritesEverything(t *testing.T) {
+	service, jobService, settingsService, db := setupBulkDataServiceTest(t)
+
+	updatedAt := "2024-01-15T09:00:00Z"
+	cards := []scryfall.Card{
+		{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst", Prices: scryfall.Prices{USD: "1.00"}},
+		{ID: "card-2", OracleID: "oracle-2", Name: "Card Two", Set: "tst"},
+	}
+	server := newIncrementalTestServer(t, &updatedAt, &cards)
+	settingsService.Set(context.Background(), "bulk_data_url", server.URL+"/bulk-data")
+	runBulkImport(t, service, jobService)
+
+	settingsService.Set(context.Background(), "bulk_data_update_mode", BulkDataModeFull)
+	cards[0].Prices.USD = "3.00"
+
+	metadata := runBulkImport(t, service, jobService)
+	if metadata.Mode != BulkDataModeFull || metadata.UnchangedCards != 0 || metadata.ProcessedCards != 2 {
+		t.Errorf("unexpected full import metadata: %+v", metadata)
+	}
+	if price := storedPrice(t, db, "card-1"); price != 3.00 {
+		t.Errorf("expected price 3.00, got %v", price)
+	}
+}
+
+func TestBulkDataService_DownloadAndImportTuned(t *testing.T) {
+	service, jobService, settingsService, db := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	updatedAt := "2024-01-15T09:00:00Z"
+	cards := []scryfall.Card{
+		{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst"},
+		{ID: "card-2", OracleID: "oracle-2", Name: "Card Two", Set: "tst"},
+		{ID: "card-3", OracleID: "oracle-3", Name: "Card Three", Set: "tst"},
+	}
+	server := newIncrementalTestServer(t, &updatedAt, &cards)
+	settingsService.Set(ctx, "bulk_data_url", server.URL+"/bulk-data")
+	settingsService.Set(ctx, "import_batch_size", "500")
+
+	// Two-card transactions, each written as single-card statements
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if err := service.DownloadAndImportTuned(ctx, job.ID, ImportTuning{BatchSize: 1, TransactionSize: 2}); err != nil {
+		t.Fatalf("DownloadAndImportTuned failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Card{}).Count(&count)
+	if count != 3 {
+		t.Errorf("expected 3 cards, got %d", count)
+	}
+
+	updated, _ := jobService.Get(ctx, job.ID)
+	var metadata JobMetadata
+	json.Unmarshal([]byte(updated.Metadata), &metadata)
+	if metadata.BatchSize != 1 || metadata.TransactionSize != 2 {
+		t.Errorf("expected tuning 1/2 in metadata, got %d/%d", metadata.BatchSize, metadata.TransactionSize)
+	}
+
+	// Without overrides the settings apply
+	updatedAt = "2024-01-16T09:00:00Z"
+	metadata = runBulkImport(t, service, jobService)
+	if metadata.BatchSize != 500 || metadata.TransactionSize != DefaultImportTransactionSize {
+		t.Errorf("expected settings tuning 500/%d, got %d/%d", DefaultImportTransactionSize, metadata.BatchSize, metadata.TransactionSize)
+	}
+}
+
+func TestBulkDataService_DownloadAndImport_RetriesTransientFailure(t *testing.T) {
+	service, jobService, _, db := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	cards := []scryfall.Card{{ID: "card-1", OracleID: "oracle-1", Name: "Card One", Set: "tst"}}
+	listRequests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bulk-data" {
+			listRequests++
+			if listRequests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]any{
+				"data": []any{map[string]any{"type": "all_cards", "download_uri": server.URL + "/cards.json"}},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(cards)
+	}))
+	defer server.Close()
+	service.settingsService.Set(ctx, "bulk_data_url", server.URL+"/bulk-data")
+
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if err := service.DownloadAndImport(ctx, job.ID); err != nil {
+		t.Fatalf("expected the import to succeed on retry, got %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Card{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 card imported, got %d", count)
+	}
+
+	updatedJob, _ := jobService.Get(ctx, job.ID)
+	if updatedJob.Status != models.JobStatusCompleted {
+		t.Errorf("expected job status %s, got %s", models.JobStatusCompleted, updatedJob.Status)
+	}
+	var metadata JobMetadata
+	json.Unmarshal([]byte(updatedJob.Metadata), &metadata)
+	if len(metadata.Retries) != 1 || metadata.Retries[0].Attempt != 1 || !strings.Contains(metadata.Retries[0].Error, "503") {
+		t.Errorf("expected one recorded retry of the 503, got %+v", metadata.Retries)
+	}
+}
+
+func TestBulkDataService_DownloadAndImport_GivesUpAfterMaxRetries(t *testing.T) {
+	service, jobService, settingsService, _ := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	settingsService.Set(ctx, "bulk_data_url", server.URL)
+	settingsService.Set(ctx, "job_retry_max_attempts", "2")
+
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if err := service.DownloadAndImport(ctx, job.ID); err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	if requests != 3 {
+		t.Errorf("expected the first attempt and 2 retries, got %d requests", requests)
+	}
+
+	updatedJob, _ := jobService.Get(ctx, job.ID)
+	if updatedJob.Status != models.JobStatusFailed {
+		t.Errorf("expected job status %s, got %s", models.JobStatusFailed, updatedJob.Status)
+	}
+}
+
+func TestBulkDataService_DownloadAndImport_NoRetryOnClientError(t *testing.T) {
+	service, jobService, settingsService, _ := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	settingsService.Set(ctx, "bulk_data_url", server.URL)
+
+	job, _ := jobService.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if err := service.DownloadAndImport(ctx, job.ID); err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	if requests != 1 {
+		t.Errorf("expected a 404 not to be retried, got %d requests", requests)
+	}
+}
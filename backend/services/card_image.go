@@ -0,0 +1,463 @@
+package services
+
+import (
+	"backend/models"
+	"backend/version"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultCardImageCacheMB caps the card image cache unless card_image_cache_max_mb says otherwise
+	DefaultCardImageCacheMB = 1024
+	// cardImageEvictTarget is the share of the cap an over-full cache is trimmed down to,
+	// so that eviction doesn't run again on the very next download
+	cardImageEvictTarget = 0.9
+	// cardImagePrefetchDelay spaces out prefetch downloads to stay polite to Scryfall's CDN
+	cardImagePrefetchDelay = 50 * time.Millisecond
+)
+
+var (
+	// ErrCardImageUnavailable is returned when Scryfall has no image of a card in the requested size
+	ErrCardImageUnavailable = errors.New("card has no image in this size")
+	// ErrImagePrefetchRunning is returned when a prefetch is requested while one is pending or running
+	ErrImagePrefetchRunning = errors.New("an image prefetch job is already running")
+)
+
+// cardImageIDPattern keeps cache file names to the characters Scryfall IDs use
+var cardImageIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+
+// CardImageSize is one of the Scryfall image sizes the cache serves
+// tygo:export
+type CardImageSize string
+
+const (
+	CardImageNormal CardImageSize = "normal"
+	CardImageSmall  CardImageSize = "small"
+)
+
+// Valid checks if the image size is one the cache serves
+func (s CardImageSize) Valid() bool {
+	return s == CardImageNormal || s == CardImageSmall
+}
+
+// ImagePrefetchJobMetadata is stored in job.Metadata while a prefetch runs
+type ImagePrefetchJobMetadata struct {
+	Phase       string `json:"phase"` // "downloading", "completed"
+	TotalCards  int    `json:"total_cards"`
+	Downloaded  int    `json:"downloaded"`
+	Cached      int    `json:"cached"` // Already in the cache
+	Failed      int    `json:"failed"`
+	CacheIsFull bool   `json:"cache_is_full"` // Stopped early at card_image_cache_max_mb
+}
+
+// CardImageService downloads card images from Scryfall into DATA_DIR/card-images and
+// serves them from there. The cache is capped in size and evicts the least recently
+// used images first.
+type CardImageService struct {
+	db         *gorm.DB
+	jobService *JobService
+	dir        string
+	httpClient *http.Client
+
+	mu         sync.Mutex // Guards the cache size and serialises writes into the cache
+	cacheBytes int64
+	sized      bool // Whether cacheBytes has been read from disk
+}
+
+// NewCardImageService creates a new card image service
+func NewCardImageService(db *gorm.DB, jobService *JobService, dataDir string) *CardImageService {
+	return &CardImageService{
+		db:         db,
+		jobService: jobService,
+		dir:        filepath.Join(dataDir, "card-images"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ValidCardImageID reports whether id can name a cached image
+func ValidCardImageID(id string) bool {
+	return cardImageIDPattern.MatchString(id)
+}
+
+// Path returns the local file holding a card's image in size, downloading it on a
+// cache miss. Unknown cards return gorm.ErrRecordNotFound.
+func (s *CardImageService) Path(ctx context.Context, scryfallID string, size CardImageSize) (string, error) {
+	if !ValidCardImageID(scryfallID) || !size.Valid() {
+		return "", fmt.Errorf("invalid card image %s/%s", scryfallID, size)
+	}
+
+	path := s.imagePath(scryfallID, size)
+	if _, err := os.Stat(path); err == nil {
+		// The modification time doubles as the last-used time for eviction
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			slog.WarnContext(ctx, "failed to mark card image used", "component", "card_image", "path", path, "error", err)
+		}
+		return path, nil
+	}
+
+	url, err := s.imageURL(ctx, scryfallID, size)
+	if err != nil {
+		return "", err
+	}
+	if err := s.download(ctx, url, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// CreatePrefetchJob creates a prefetch job, or returns ErrImagePrefetchRunning if one hasn't finished
+func (s *CardImageService) CreatePrefetchJob(ctx context.Context) (*models.Job, error) {
+	var running int64
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("type = ? AND status IN ?", models.JobTypeImagePrefetch,
+			[]models.JobStatus{models.JobStatusPending, models.JobStatusInProgress}).
+		Count(&running).Error; err != nil {
+		return nil, fmt.Errorf("checking for running prefetch: %w", err)
+	}
+	if running > 0 {
+		return nil, ErrImagePrefetchRunning
+	}
+
+	metadata, err := json.Marshal(ImagePrefetchJobMetadata{Phase: "pending"})
+	if err != nil {
+		return nil, err
+	}
+	return s.jobService.Create(ctx, models.JobTypeImagePrefetch, string(metadata))
+}
+
+// Prefetch downloads the normal-size image of every printing in the inventory that
+// isn't cached yet. It stops early rather than evict once the cache is full.
+func (s *CardImageService) Prefetch(ctx context.Context, jobID uint) error {
+	ctx, release := s.jobService.Cancellable(ctx, jobID)
+	defer release()
+
+	if err := s.jobService.Start(ctx, jobID); err != nil {
+		return fmt.Errorf("starting prefetch job: %w", err)
+	}
+
+	var metadata ImagePrefetchJobMetadata
+	if err := s.prefetch(ctx, jobID, &metadata); err != nil {
+		// The job's context may be cancelled, but recording the outcome still has to happen
+		cleanupCtx := context.WithoutCancel(ctx)
+		if failErr := s.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+			slog.ErrorContext(ctx, "failed to mark job as failed", "component", "card_image", "job_id", jobID, "error", failErr)
+		}
+		s.updateJobMetadata(cleanupCtx, jobID, metadata)
+		return err
+	}
+
+	metadata.Phase = "completed"
+	s.updateJobMetadata(ctx, jobID, metadata)
+	if err := s.jobService.Complete(ctx, jobID); err != nil {
+		return fmt.Errorf("completing prefetch job: %w", err)
+	}
+
+	slog.InfoContext(ctx, "card image prefetch completed", "component", "card_image", "job_id", jobID,
+		"downloaded", metadata.Downloaded, "failed", metadata.Failed, "cache_is_full", metadata.CacheIsFull)
+	return nil
+}
+
+// RunScheduledPrefetch is the scheduler's entry point: it runs a prefetch job to
+// completion unless one is already running. A failed prefetch is recorded on its job,
+// which reports it, so only failing to start one is returned.
+func (s *CardImageService) RunScheduledPrefetch(ctx context.Context) error {
+	job, err := s.CreatePrefetchJob(ctx)
+	if errors.Is(err, ErrImagePrefetchRunning) {
+		slog.InfoContext(ctx, "scheduled card image prefetch skipped", "component", "card_image", "error", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("starting card image prefetch: %w", err)
+	}
+	_ = s.Prefetch(ctx, job.ID)
+
+	settings := settingsStore(s.db)
+	if err := settings.SetTime(ctx, "card_image_prefetch_last_run", time.Now()); err != nil {
+		slog.WarnContext(ctx, "failed to persist card_image_prefetch_last_run", "component", "card_image", "error", err)
+	}
+	return nil
+}
+
+func (s *CardImageService) prefetch(ctx context.Context, jobID uint, metadata *ImagePrefetchJobMetadata) error {
+	var ids []string
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Distinct("scryfall_id").
+		Order("scryfall_id").
+		Pluck("scryfall_id", &ids).Error; err != nil {
+		return fmt.Errorf("loading inventory printings: %w", err)
+	}
+	metadata.Phase, metadata.TotalCards = "downloading", len(ids)
+	s.updateJobMetadata(ctx, jobID, *metadata)
+
+	for i, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("prefetch cancelled: %w", err)
+		}
+		if i > 0 && i%100 == 0 {
+			s.updateJobMetadata(ctx, jobID, *metadata)
+		}
+		if !ValidCardImageID(id) {
+			metadata.Failed++
+			continue
+		}
+
+		path := s.imagePath(id, CardImageNormal)
+		if _, err := os.Stat(path); err == nil {
+			metadata.Cached++
+			continue
+		}
+		full, err := s.cacheIsFull(ctx)
+		if err != nil {
+			return err
+		}
+		if full {
+			metadata.CacheIsFull = true
+			break
+		}
+
+		url, err := s.imageURL(ctx, id, CardImageNormal)
+		if err == nil {
+			err = s.download(ctx, url, path)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("prefetch cancelled: %w", ctx.Err())
+			}
+			slog.DebugContext(ctx, "failed to prefetch card image", "component", "card_image", "scryfall_id", id, "error", err)
+			metadata.Failed++
+			continue
+		}
+		metadata.Downloaded++
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(cardImagePrefetchDelay):
+		}
+	}
+	return nil
+}
+
+// imageURL returns Scryfall's URL for a card's image in size, falling back to the
+// front face for double-faced cards
+func (s *CardImageService) imageURL(ctx context.Context, scryfallID string, size CardImageSize) (string, error) {
+	var card models.Card
+	if err := s.db.WithContext(ctx).Select("scryfall_id", "raw_json").
+		Where("scryfall_id = ?", scryfallID).Take(&card).Error; err != nil {
+		return "", err
+	}
+
+	var data struct {
+		ImageURIs map[string]string `json:"image_uris"`
+		CardFaces []struct {
+			ImageURIs map[string]string `json:"image_uris"`
+		} `json:"card_faces"`
+	}
+	if err := json.Unmarshal([]byte(card.RawJSON), &data); err != nil {
+		return "", fmt.Errorf("decoding card %s: %w", scryfallID, err)
+	}
+	if url := data.ImageURIs[string(size)]; url != "" {
+		return url, nil
+	}
+	if len(data.CardFaces) > 0 {
+		if url := data.CardFaces[0].ImageURIs[string(size)]; url != "" {
+			return url, nil
+		}
+	}
+	return "", ErrCardImageUnavailable
+}
+
+// download fetches url into path, then trims the cache if it went over its cap
+func (s *CardImageService) download(ctx context.Context, url, path string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", version.UserAgent())
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("image download returned status %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create image directory: %w", err)
+	}
+	// Write beside the final path so a failed download never leaves a partial image behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
+	}
+	written, err := io.Copy(tmp, resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write image file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadCacheSize(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	// A concurrent request may have cached the same image meanwhile
+	var replaced int64
+	if info, err := os.Stat(path); err == nil {
+		replaced = info.Size()
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store image file: %w", err)
+	}
+	s.cacheBytes += written - replaced
+
+	if limit := s.cacheLimit(ctx); s.cacheBytes > limit {
+		s.evict(ctx, int64(float64(limit)*cardImageEvictTarget))
+	}
+	return nil
+}
+
+// cacheIsFull reports whether the cache has reached card_image_cache_max_mb
+func (s *CardImageService) cacheIsFull(ctx context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadCacheSize(); err != nil {
+		return false, err
+	}
+	return s.cacheBytes >= s.cacheLimit(ctx), nil
+}
+
+// cacheLimit returns the cache cap in bytes
+func (s *CardImageService) cacheLimit(ctx context.Context) int64 {
+	settings := settingsStore(s.db)
+	mb := settings.GetInt(ctx, "card_image_cache_max_mb", DefaultCardImageCacheMB)
+	if mb < 1 {
+		mb = DefaultCardImageCacheMB
+	}
+	return int64(mb) << 20
+}
+
+// loadCacheSize totals the cached images on first use. Callers hold s.mu.
+func (s *CardImageService) loadCacheSize() error {
+	if s.sized {
+		return nil
+	}
+	images, err := s.cachedImages()
+	if err != nil {
+		return err
+	}
+	s.cacheBytes = 0
+	for _, image := range images {
+		s.cacheBytes += image.size
+	}
+	s.sized = true
+	return nil
+}
+
+// cachedImage is a file in the cache
+type cachedImage struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// cachedImages lists the images in the cache, skipping in-progress downloads
+func (s *CardImageService) cachedImages() ([]cachedImage, error) {
+	var images []cachedImage
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".jpg" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // Removed since the directory was read
+		}
+		images = append(images, cachedImage{path: path, size: info.Size(), lastUse: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading card image cache: %w", err)
+	}
+	return images, nil
+}
+
+// evict removes the least recently used images until the cache holds at most target
+// bytes. Callers hold s.mu.
+func (s *CardImageService) evict(ctx context.Context, target int64) {
+	images, err := s.cachedImages()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to list card images for eviction", "component", "card_image", "error", err)
+		return
+	}
+	slices.SortFunc(images, func(a, b cachedImage) int {
+		return cmp.Compare(a.lastUse.UnixNano(), b.lastUse.UnixNano())
+	})
+
+	// Recount from disk so the running total can't drift
+	s.cacheBytes = 0
+	for _, image := range images {
+		s.cacheBytes += image.size
+	}
+
+	removed := 0
+	for _, image := range images {
+		if s.cacheBytes <= target {
+			break
+		}
+		if err := os.Remove(image.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.WarnContext(ctx, "failed to evict card image", "component", "card_image", "path", image.path, "error", err)
+			continue
+		}
+		s.cacheBytes -= image.size
+		removed++
+	}
+	slog.InfoContext(ctx, "evicted card images", "component", "card_image", "removed", removed, "cache_bytes", s.cacheBytes)
+}
+
+// imagePath is where a card's image in size is cached
+func (s *CardImageService) imagePath(scryfallID string, size CardImageSize) string {
+	return filepath.Join(s.dir, string(size), scryfallID+".jpg")
+}
+
+func (s *CardImageService) updateJobMetadata(ctx context.Context, jobID uint, metadata ImagePrefetchJobMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal job metadata", "component", "card_image", "error", err)
+		return
+	}
+	if err := s.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
+		slog.WarnContext(ctx, "failed to update job metadata", "component", "card_image", "error", err)
+	}
+}
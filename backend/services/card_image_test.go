@@ -0,0 +1,212 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// imageServer serves imageSize bytes for any path and counts the requests it gets
+func imageServer(t *testing.T, imageSize int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte(strings.Repeat("x", imageSize)))
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func setupCardImageTest(t *testing.T) (*gorm.DB, *CardImageService, string) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Card{}, &models.StorageLocation{}, &models.Inventory{}, &models.Setting{}, &models.Job{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	dataDir := t.TempDir()
+	return db, NewCardImageService(db, NewJobService(db), dataDir), dataDir
+}
+
+func createImageTestCard(t *testing.T, db *gorm.DB, scryfallID, rawJSON string) {
+	t.Helper()
+
+	if err := db.Create(&models.Card{ScryfallID: scryfallID, OracleID: "oracle-" + scryfallID, RawJSON: rawJSON}).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func TestCardImageService_Path(t *testing.T) {
+	db, service, dataDir := setupCardImageTest(t)
+	server, hits := imageServer(t, 100)
+	ctx := context.Background()
+
+	createImageTestCard(t, db, "bolt", fmt.Sprintf(`{"image_uris": {"normal": "%[1]s/bolt-normal.jpg", "small": "%[1]s/bolt-small.jpg"}}`, server.URL))
+	createImageTestCard(t, db, "delver", fmt.Sprintf(`{"card_faces": [{"image_uris": {"normal": "%s/delver-front.jpg"}}, {}]}`, server.URL))
+	createImageTestCard(t, db, "art-series", `{"image_uris": {"normal": ""}}`)
+	createImageTestCard(t, db, "gone", fmt.Sprintf(`{"image_uris": {"normal": "%s/missing.jpg"}}`, server.URL))
+
+	path, err := service.Path(ctx, "bolt", CardImageSmall)
+	if err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if want := filepath.Join(dataDir, "card-images", "small", "bolt.jpg"); path != want {
+		t.Errorf("expected %s, got %s", want, path)
+	}
+	// The second request is served from the cache
+	if _, err := service.Path(ctx, "bolt", CardImageSmall); err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("expected one download, got %d", hits.Load())
+	}
+
+	if _, err := service.Path(ctx, "delver", CardImageNormal); err != nil {
+		t.Errorf("expected the front face image for a double-faced card, got %v", err)
+	}
+	if _, err := service.Path(ctx, "art-series", CardImageNormal); !errors.Is(err, ErrCardImageUnavailable) {
+		t.Errorf("expected ErrCardImageUnavailable, got %v", err)
+	}
+	if _, err := service.Path(ctx, "unknown", CardImageNormal); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+	if _, err := service.Path(ctx, "gone", CardImageNormal); err == nil {
+		t.Error("expected an error for a failed download")
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "card-images", "normal", "gone.jpg")); !os.IsNotExist(err) {
+		t.Error("expected no file cached for a failed download")
+	}
+	if _, err := service.Path(ctx, "../etc", CardImageNormal); err == nil {
+		t.Error("expected an error for an ID that isn't a file name")
+	}
+}
+
+func TestCardImageService_EvictsLeastRecentlyUsed(t *testing.T) {
+	db, service, dataDir := setupCardImageTest(t)
+	server, _ := imageServer(t, 400<<10)
+	ctx := context.Background()
+
+	db.Create(&models.Setting{Key: "card_image_cache_max_mb", Value: "1"})
+	for _, id := range []string{"a", "b", "c"} {
+		createImageTestCard(t, db, id, fmt.Sprintf(`{"image_uris": {"normal": "%s/%s.jpg"}}`, server.URL, id))
+	}
+	cached := func(id string) bool {
+		_, err := os.Stat(filepath.Join(dataDir, "card-images", "normal", id+".jpg"))
+		return err == nil
+	}
+
+	for _, id := range []string{"a", "b"} {
+		if _, err := service.Path(ctx, id, CardImageNormal); err != nil {
+			t.Fatalf("Path failed: %v", err)
+		}
+	}
+	// Age both, then use a so that b is the least recently used
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dataDir, "card-images", "normal", "a.jpg"), old, old)
+	os.Chtimes(filepath.Join(dataDir, "card-images", "normal", "b.jpg"), old.Add(-time.Minute), old.Add(-time.Minute))
+	if _, err := service.Path(ctx, "a", CardImageNormal); err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+
+	// A third 400 KB image takes the cache past 1 MB
+	if _, err := service.Path(ctx, "c", CardImageNormal); err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if !cached("a") || cached("b") || !cached("c") {
+		t.Errorf("expected b evicted, got a=%v b=%v c=%v", cached("a"), cached("b"), cached("c"))
+	}
+}
+
+func TestCardImageService_Prefetch(t *testing.T) {
+	db, service, _ := setupCardImageTest(t)
+	server, hits := imageServer(t, 100)
+	ctx := context.Background()
+
+	for _, id := range []string{"bolt", "ring", "broken"} {
+		path := id
+		if id == "broken" {
+			path = "missing"
+		}
+		createImageTestCard(t, db, id, fmt.Sprintf(`{"image_uris": {"normal": "%s/%s.jpg"}}`, server.URL, path))
+		db.Create(&models.Inventory{ScryfallID: id, OracleID: "oracle-" + id, Treatment: "nonfoil", Quantity: 1})
+	}
+	// A second copy of a printing is only fetched once
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "foil", Quantity: 1})
+	if _, err := service.Path(ctx, "ring", CardImageNormal); err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+
+	job, err := service.CreatePrefetchJob(ctx)
+	if err != nil {
+		t.Fatalf("CreatePrefetchJob failed: %v", err)
+	}
+	if _, err := service.CreatePrefetchJob(ctx); !errors.Is(err, ErrImagePrefetchRunning) {
+		t.Errorf("expected ErrImagePrefetchRunning, got %v", err)
+	}
+	if err := service.Prefetch(ctx, job.ID); err != nil {
+		t.Fatalf("Prefetch failed: %v", err)
+	}
+
+	stored, _ := service.jobService.Get(ctx, job.ID)
+	if stored.Status != models.JobStatusCompleted {
+		t.Errorf("expected a completed job, got %s", stored.Status)
+	}
+	var metadata ImagePrefetchJobMetadata
+	json.Unmarshal([]byte(stored.Metadata), &metadata)
+	if metadata.TotalCards != 3 || metadata.Downloaded != 1 || metadata.Cached != 1 || metadata.Failed != 1 || metadata.CacheIsFull {
+		t.Errorf("unexpected metadata: %+v", metadata)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("expected 3 downloads in total, got %d", hits.Load())
+	}
+}
+
+func TestCardImageService_PrefetchStopsWhenFull(t *testing.T) {
+	db, service, _ := setupCardImageTest(t)
+	server, _ := imageServer(t, 1<<20)
+	ctx := context.Background()
+
+	db.Create(&models.Setting{Key: "card_image_cache_max_mb", Value: "1"})
+	for _, id := range []string{"a", "b"} {
+		createImageTestCard(t, db, id, fmt.Sprintf(`{"image_uris": {"normal": "%s/%s.jpg"}}`, server.URL, id))
+		db.Create(&models.Inventory{ScryfallID: id, OracleID: "oracle-" + id, Treatment: "nonfoil", Quantity: 1})
+	}
+
+	job, err := service.CreatePrefetchJob(ctx)
+	if err != nil {
+		t.Fatalf("CreatePrefetchJob failed: %v", err)
+	}
+	if err := service.Prefetch(ctx, job.ID); err != nil {
+		t.Fatalf("Prefetch failed: %v", err)
+	}
+
+	stored, _ := service.jobService.Get(ctx, job.ID)
+	var metadata ImagePrefetchJobMetadata
+	json.Unmarshal([]byte(stored.Metadata), &metadata)
+	if metadata.Downloaded != 1 || !metadata.CacheIsFull {
+		t.Errorf("expected the prefetch to stop after filling the cache, got %+v", metadata)
+	}
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrCardNotFound is returned by LookupCard when no imported card matches
+var ErrCardNotFound = errors.New("card not found")
+
+// CardLookup identifies a printing the way it appears on the physical card: by set and
+// collector number, or by name with an optional set
+type CardLookup struct {
+	SetCode         string
+	CollectorNumber string
+	Name            string
+}
+
+// LookupCard finds a single printing in the imported cards. A set and collector number
+// pick that exact printing; otherwise the name picks the newest paper printing, within
+// the set when one is given. It returns ErrCardNotFound when nothing matches.
+func LookupCard(ctx context.Context, db *gorm.DB, lookup CardLookup) (*models.Card, error) {
+	setCode := strings.ToLower(strings.TrimSpace(lookup.SetCode))
+	collectorNumber := normalizeCollectorNumber(lookup.CollectorNumber)
+	name := strings.TrimSpace(lookup.Name)
+
+	if setCode != "" && collectorNumber != "" {
+		var card models.Card
+		err := db.WithContext(ctx).
+			Where("set_code = ? AND collector_number = ?", setCode, collectorNumber).
+			Order("scryfall_id").
+			First(&card).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCardNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("looking up %s #%s: %w", setCode, collectorNumber, err)
+		}
+		return &card, nil
+	}
+
+	if name == "" {
+		return nil, ErrCardNotFound
+	}
+	// Names resolve through the case-insensitive name indexes, see cardNameMatchSQL
+	line := TextImportLine{Name: name, SetCode: setCode}
+	candidates, err := loadTextImportCandidates(ctx, db, []TextImportLine{line})
+	if err != nil {
+		return nil, err
+	}
+	candidate, ok := pickTextImportPrinting(candidates[strings.ToLower(name)], line)
+	if !ok {
+		return nil, ErrCardNotFound
+	}
+
+	var card models.Card
+	if err := db.WithContext(ctx).Where("scryfall_id = ?", candidate.ScryfallID).First(&card).Error; err != nil {
+		return nil, fmt.Errorf("loading card %s: %w", candidate.ScryfallID, err)
+	}
+	return &card, nil
+}
+
+// normalizeCollectorNumber reads a collector number as printed on modern cards, where
+// "0161/295" is number 161 of 295
+func normalizeCollectorNumber(number string) string {
+	number, _, _ = strings.Cut(strings.TrimSpace(number), "/")
+	if trimmed := strings.TrimLeft(number, "0"); trimmed != "" {
+		return trimmed
+	}
+	return number
+}
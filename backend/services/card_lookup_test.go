@@ -0,0 +1,61 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLookupCard(t *testing.T) {
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-lea", OracleID: "o-bolt", RawJSON: `{"name":"Lightning Bolt","set":"lea","collector_number":"161","released_at":"1993-08-05"}`},
+		{ScryfallID: "bolt-m10", OracleID: "o-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","collector_number":"146","released_at":"2009-07-17"}`},
+		{ScryfallID: "bolt-prm", OracleID: "o-bolt", RawJSON: `{"name":"Lightning Bolt","set":"prm","collector_number":"32196","released_at":"2020-01-01","digital":true}`},
+		{ScryfallID: "delver-isd", OracleID: "o-delver", RawJSON: `{"name":"Delver of Secrets // Insectile Aberration","set":"isd","collector_number":"51","released_at":"2011-09-30","card_faces":[{"name":"Delver of Secrets"},{"name":"Insectile Aberration"}]}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		lookup   CardLookup
+		expected string
+	}{
+		{name: "Set and number", lookup: CardLookup{SetCode: "lea", CollectorNumber: "161"}, expected: "bolt-lea"},
+		{name: "Printed number", lookup: CardLookup{SetCode: " M10 ", CollectorNumber: "0146/249"}, expected: "bolt-m10"},
+		{name: "Number wins over name", lookup: CardLookup{SetCode: "lea", CollectorNumber: "161", Name: "Delver of Secrets"}, expected: "bolt-lea"},
+		{name: "Name picks newest paper printing", lookup: CardLookup{Name: "lightning bolt"}, expected: "bolt-m10"},
+		{name: "Name within set", lookup: CardLookup{Name: "Lightning Bolt", SetCode: "lea"}, expected: "bolt-lea"},
+		{name: "Front face name", lookup: CardLookup{Name: "Delver of Secrets"}, expected: "delver-isd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card, err := LookupCard(context.Background(), db, tt.lookup)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if card.ScryfallID != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, card.ScryfallID)
+			}
+		})
+	}
+
+	notFound := []CardLookup{
+		{SetCode: "lea", CollectorNumber: "999"},
+		{Name: "Lightning Bolt", SetCode: "isd"},
+		{Name: "Black Lotus"},
+		{SetCode: "lea"},
+	}
+	for _, lookup := range notFound {
+		if _, err := LookupCard(context.Background(), db, lookup); !errors.Is(err, ErrCardNotFound) {
+			t.Errorf("%+v: expected ErrCardNotFound, got %v", lookup, err)
+		}
+	}
+}
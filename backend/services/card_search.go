@@ -0,0 +1,155 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CardSearchFilters narrows a local card search. Zero values mean "no filter".
+type CardSearchFilters struct {
+	// Query is matched against name, type line, oracle text, and set code
+	Query string
+	// SetCode limits results to one set
+	SetCode string
+	// Colors are color letters (w, u, b, r, g) the card must all have; "c" means colorless
+	Colors []string
+	// Rarities limits results to any of the given rarities
+	Rarities []string
+	CMCMin   *float64
+	CMCMax   *float64
+	// PriceMin and PriceMax compare against the nonfoil USD price; cards without one are excluded
+	PriceMin *float64
+	PriceMax *float64
+	// PriceAbove and PriceBelow are exclusive bounds on the same price
+	PriceAbove *float64
+	PriceBelow *float64
+
+	// Names, TypeLines and OracleTexts must each be contained in the card's name, type
+	// line or oracle text (either face's)
+	Names       []string
+	TypeLines   []string
+	OracleTexts []string
+	// Games are games (paper, arena, mtgo) the card must all be available in
+	Games []string
+	// UniqueCards returns one printing per card rather than every printing
+	UniqueCards bool
+}
+
+// IsEmpty reports whether no search term or filter is set
+func (f CardSearchFilters) IsEmpty() bool {
+	return f.Query == "" && f.SetCode == "" && len(f.Colors) == 0 && len(f.Rarities) == 0 &&
+		f.CMCMin == nil && f.CMCMax == nil && f.PriceMin == nil && f.PriceMax == nil &&
+		f.PriceAbove == nil && f.PriceBelow == nil &&
+		len(f.Names) == 0 && len(f.TypeLines) == 0 && len(f.OracleTexts) == 0 && len(f.Games) == 0
+}
+
+// CardSearchService searches the locally imported bulk data, so search keeps
+// working without access to Scryfall once the initial import has finished
+type CardSearchService struct {
+	db *gorm.DB
+}
+
+// NewCardSearchService creates a new card search service
+func NewCardSearchService(db *gorm.DB) *CardSearchService {
+	return &CardSearchService{db: db}
+}
+
+// Search returns a page of matching cards ordered by name then set, and the total match count
+func (s *CardSearchService) Search(ctx context.Context, filters CardSearchFilters, page, pageSize int) ([]models.Card, int64, error) {
+	query := s.apply(s.db.WithContext(ctx).Model(&models.Card{}), filters)
+	if filters.UniqueCards {
+		// The first printing by ID stands in for each card
+		printings := s.apply(s.db.Model(&models.Card{}).Select("MIN(scryfall_id)"), filters).
+			Group("COALESCE(NULLIF(oracle_id, ''), scryfall_id)")
+		query = s.db.WithContext(ctx).Model(&models.Card{}).Where("scryfall_id IN (?)", printings)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting card search results: %w", err)
+	}
+
+	var cards []models.Card
+	offset := (page - 1) * pageSize
+	if err := query.Order("name ASC, set_code ASC, scryfall_id ASC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&cards).Error; err != nil {
+		return nil, 0, fmt.Errorf("searching cards: %w", err)
+	}
+
+	return cards, total, nil
+}
+
+// apply adds the filter conditions to a cards query
+func (s *CardSearchService) apply(query *gorm.DB, filters CardSearchFilters) *gorm.DB {
+	if filters.Query != "" {
+		pattern := "%" + escapeLike(filters.Query) + "%"
+		query = query.Where(`(name LIKE ? ESCAPE '\'
+			OR set_code = ?
+			OR json_extract(raw_json, '$.type_line') LIKE ? ESCAPE '\'
+			OR json_extract(raw_json, '$.oracle_text') LIKE ? ESCAPE '\'
+			OR json_extract(raw_json, '$.card_faces[0].oracle_text') LIKE ? ESCAPE '\'
+			OR json_extract(raw_json, '$.card_faces[1].oracle_text') LIKE ? ESCAPE '\')`,
+			pattern, strings.ToLower(filters.Query), pattern, pattern, pattern, pattern)
+	}
+	if filters.SetCode != "" {
+		query = query.Where("set_code = ?", filters.SetCode)
+	}
+	for _, color := range filters.Colors {
+		if color == "c" {
+			query = query.Where("colors = ''")
+			continue
+		}
+		query = query.Where("colors LIKE ?", "%"+strings.ToUpper(color)+"%")
+	}
+	if len(filters.Rarities) > 0 {
+		query = query.Where("rarity IN ?", filters.Rarities)
+	}
+	if filters.CMCMin != nil {
+		query = query.Where("cmc >= ?", *filters.CMCMin)
+	}
+	if filters.CMCMax != nil {
+		query = query.Where("cmc <= ?", *filters.CMCMax)
+	}
+	if filters.PriceMin != nil {
+		query = query.Where("price_usd >= ?", *filters.PriceMin)
+	}
+	if filters.PriceMax != nil {
+		query = query.Where("price_usd <= ?", *filters.PriceMax)
+	}
+	if filters.PriceAbove != nil {
+		query = query.Where("price_usd > ?", *filters.PriceAbove)
+	}
+	if filters.PriceBelow != nil {
+		query = query.Where("price_usd < ?", *filters.PriceBelow)
+	}
+	for _, name := range filters.Names {
+		query = query.Where(`name LIKE ? ESCAPE '\'`, "%"+escapeLike(name)+"%")
+	}
+	// Type line stays in raw_json: a substring LIKE cannot use an index, so an extracted column would only save the JSON parse
+	for _, typeLine := range filters.TypeLines {
+		query = query.Where(`json_extract(raw_json, '$.type_line') LIKE ? ESCAPE '\'`, "%"+escapeLike(typeLine)+"%")
+	}
+	for _, text := range filters.OracleTexts {
+		pattern := "%" + escapeLike(text) + "%"
+		query = query.Where(`(json_extract(raw_json, '$.oracle_text') LIKE ? ESCAPE '\'
+			OR json_extract(raw_json, '$.card_faces[0].oracle_text') LIKE ? ESCAPE '\'
+			OR json_extract(raw_json, '$.card_faces[1].oracle_text') LIKE ? ESCAPE '\')`,
+			pattern, pattern, pattern)
+	}
+	for _, game := range filters.Games {
+		query = query.Where("EXISTS (SELECT 1 FROM json_each(raw_json, '$.games') WHERE json_each.value = ?)", game)
+	}
+	return query
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"testing"
+)
+
+func setupCardSearchTest(t *testing.T) *CardSearchService {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt", OracleID: "o-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","type_line":"Instant","oracle_text":"Lightning Bolt deals 3 damage to any target.","rarity":"common","cmc":1,"colors":["R"],"games":["paper","mtgo"],"prices":{"usd":"1.50"}}`},
+		{ScryfallID: "helix", OracleID: "o-helix", RawJSON: `{"name":"Lightning Helix","set":"rav","type_line":"Instant","oracle_text":"Lightning Helix deals 3 damage to any target and you gain 3 life.","rarity":"uncommon","cmc":2,"colors":["R","W"],"games":["paper"],"prices":{"usd":"0.50"}}`},
+		{ScryfallID: "ring", OracleID: "o-ring", RawJSON: `{"name":"Sol Ring","set":"c21","type_line":"Artifact","oracle_text":"{T}: Add {C}{C}.","rarity":"uncommon","cmc":1,"colors":[],"prices":{"usd":"2.00"}}`},
+		{ScryfallID: "delver", OracleID: "o-delver", RawJSON: `{"name":"Delver of Secrets // Insectile Aberration","set":"isd","type_line":"Creature — Human Wizard // Creature — Human Insect","rarity":"common","cmc":1,"card_faces":[{"oracle_text":"Look at the top card of your library.","colors":["U"]},{"oracle_text":"Flying","colors":["U"]}],"prices":{"usd":null}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	return NewCardSearchService(db)
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestCardSearchService_Search(t *testing.T) {
+	service := setupCardSearchTest(t)
+
+	tests := []struct {
+		name     string
+		filters  CardSearchFilters
+		expected []string
+	}{
+		{name: "Name", filters: CardSearchFilters{Query: "lightning"}, expected: []string{"bolt", "helix"}},
+		{name: "Oracle text", filters: CardSearchFilters{Query: "gain 3 life"}, expected: []string{"helix"}},
+		{name: "Back face oracle text", filters: CardSearchFilters{Query: "flying"}, expected: []string{"delver"}},
+		{name: "Type line", filters: CardSearchFilters{Query: "artifact"}, expected: []string{"ring"}},
+		{name: "Set code as query", filters: CardSearchFilters{Query: "RAV"}, expected: []string{"helix"}},
+		{name: "Set filter", filters: CardSearchFilters{SetCode: "m10"}, expected: []string{"bolt"}},
+		{name: "Colors must all match", filters: CardSearchFilters{Colors: []string{"r", "w"}}, expected: []string{"helix"}},
+		{name: "Face colors", filters: CardSearchFilters{Colors: []string{"u"}}, expected: []string{"delver"}},
+		{name: "Colorless", filters: CardSearchFilters{Colors: []string{"c"}}, expected: []string{"ring"}},
+		{name: "Rarity", filters: CardSearchFilters{Rarities: []string{"uncommon"}}, expected: []string{"helix", "ring"}},
+		{name: "CMC range", filters: CardSearchFilters{CMCMin: float64Ptr(2), CMCMax: float64Ptr(2)}, expected: []string{"helix"}},
+		{name: "Price range excludes unpriced", filters: CardSearchFilters{PriceMax: float64Ptr(1.5)}, expected: []string{"bolt", "helix"}},
+		{name: "Wildcards are literal", filters: CardSearchFilters{Query: "%"}, expected: []string{}},
+		{name: "Exclusive price bounds", filters: CardSearchFilters{PriceAbove: float64Ptr(0.5), PriceBelow: float64Ptr(2)}, expected: []string{"bolt"}},
+		{name: "Name words", filters: CardSearchFilters{Names: []string{"lightning", "helix"}}, expected: []string{"helix"}},
+		{name: "Name excludes oracle text", filters: CardSearchFilters{Names: []string{"flying"}}, expected: []string{}},
+		{name: "Type line words", filters: CardSearchFilters{TypeLines: []string{"creature", "wizard"}}, expected: []string{"delver"}},
+		{name: "Oracle text phrase", filters: CardSearchFilters{OracleTexts: []string{"3 damage"}}, expected: []string{"bolt", "helix"}},
+		{name: "Face oracle text", filters: CardSearchFilters{OracleTexts: []string{"top card"}}, expected: []string{"delver"}},
+		{name: "Games must all match", filters: CardSearchFilters{Games: []string{"paper", "mtgo"}}, expected: []string{"bolt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, total, err := service.Search(context.Background(), tt.filters, 1, 20)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			if int(total) != len(tt.expected) || len(cards) != len(tt.expected) {
+				t.Fatalf("expected %d results, got total=%d len=%d", len(tt.expected), total, len(cards))
+			}
+			for i, id := range tt.expected {
+				if cards[i].ScryfallID != id {
+					t.Errorf("result %d: expected %s, got %s", i, id, cards[i].ScryfallID)
+				}
+			}
+		})
+	}
+}
+
+func TestCardSearchService_Search_Pagination(t *testing.T) {
+	service := setupCardSearchTest(t)
+
+	cards, total, err := service.Search(context.Background(), CardSearchFilters{CMCMax: float64Ptr(5)}, 2, 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 4 {
+		t.Errorf("expected total 4, got %d", total)
+	}
+	if len(cards) != 1 || cards[0].ScryfallID != "ring" {
+		t.Errorf("expected last page to contain only Sol Ring, got %+v", cards)
+	}
+}
+
+func TestCardSearchService_Search_UniqueCards(t *testing.T) {
+	service := setupCardSearchTest(t)
+	reprint := models.Card{ScryfallID: "bolt-2", OracleID: "o-bolt", RawJSON: `{"name":"Lightning Bolt","set":"2ed","type_line":"Instant","rarity":"common","cmc":1,"colors":["R"]}`}
+	if err := service.db.Create(&reprint).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	filters := CardSearchFilters{Names: []string{"lightning"}}
+	_, total, err := service.Search(context.Background(), filters, 1, 20)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("expected every printing, got %d", total)
+	}
+
+	filters.UniqueCards = true
+	cards, total, err := service.Search(context.Background(), filters, 1, 20)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if total != 2 || len(cards) != 2 || cards[0].OracleID != "o-bolt" || cards[1].ScryfallID != "helix" {
+		t.Errorf("expected one printing each of bolt and helix, got total=%d %+v", total, cards)
+	}
+}
+
+func TestCardSearchFilters_IsEmpty(t *testing.T) {
+	if !(CardSearchFilters{}).IsEmpty() {
+		t.Error("expected zero filters to be empty")
+	}
+	if (CardSearchFilters{Rarities: []string{"rare"}}).IsEmpty() {
+		t.Error("expected rarity filter to be non-empty")
+	}
+	if (CardSearchFilters{Names: []string{"bolt"}}).IsEmpty() {
+		t.Error("expected name filter to be non-empty")
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"backend/models"
+	"backend/rules"
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// ConsolidationHolding is one location's share of a printing stored in several places
+// tygo:export
+type ConsolidationHolding struct {
+	StorageLocationID uint   `json:"storage_location_id"`
+	Name              string `json:"name"`
+	Quantity          int    `json:"quantity"`
+	InventoryIDs      []uint `json:"inventory_ids"`
+}
+
+// ConsolidationSuggestion proposes gathering every copy of a printing and treatment
+// into one location. MoveIDs are the inventory rows to move into Target.
+// tygo:export
+type ConsolidationSuggestion struct {
+	ScryfallID      string                 `json:"scryfall_id"`
+	Name            string                 `json:"name"`
+	Treatment       string                 `json:"treatment"`
+	Quantity        int                    `json:"quantity"` // Copies across every location
+	Holdings        []ConsolidationHolding `json:"holdings"` // Most copies first
+	Target          SuggestedLocation      `json:"target"`
+	MatchedRuleID   *uint                  `json:"matched_rule_id,omitempty"`
+	MatchedRuleName string                 `json:"matched_rule_name,omitempty"`
+	MoveIDs         []uint                 `json:"move_ids"`
+}
+
+// consolidationGroup is a printing and treatment stored in several locations
+type consolidationGroup struct {
+	ScryfallID string
+	Treatment  string
+}
+
+// SuggestConsolidations finds printings whose copies (same scryfall_id and treatment)
+// are spread across at least minLocations storage locations and suggests one location
+// for each, most scattered first, returning at most limit suggestions.
+//
+// The target is where auto-sort would put every copy now (matching rules, then the
+// catch-all and overflow locations), or failing that the location already holding the
+// most copies. Targets must have room for the copies moving in; suggestions account
+// for each other, and printings with no location that has room are left out. Rules
+// are evaluated without row notes, since copies in different rows may differ. Basic
+// lands are skipped when consolidation_exclude_basic_lands is on.
+func (s *AutoSortService) SuggestConsolidations(ctx context.Context, minLocations, limit int) ([]ConsolidationSuggestion, error) {
+	suggestions := []ConsolidationSuggestion{}
+
+	scattered := s.db.WithContext(ctx).Model(&models.Inventory{})
+	if BasicLandsExcluded(ctx, s.db, "consolidation_exclude_basic_lands") {
+		scattered = scattered.Scopes(models.ExcludeBasicLands)
+	}
+
+	var groups []consolidationGroup
+	if err := scattered.
+		Select("scryfall_id, treatment").
+		Where("storage_location_id IS NOT NULL").
+		Group("scryfall_id, treatment").
+		Having("COUNT(DISTINCT storage_location_id) >= ?", minLocations).
+		Order("COUNT(DISTINCT storage_location_id) DESC, SUM(quantity) DESC, scryfall_id ASC, treatment ASC").
+		Limit(limit).
+		Scan(&groups).Error; err != nil {
+		return nil, fmt.Errorf("finding scattered printings: %w", err)
+	}
+	if len(groups) == 0 {
+		return suggestions, nil
+	}
+
+	scryfallIDs := make([]string, 0, len(groups))
+	for _, group := range groups {
+		scryfallIDs = append(scryfallIDs, group.ScryfallID)
+	}
+
+	var items []models.Inventory
+	if err := s.db.WithContext(ctx).Preload("StorageLocation").
+		Where("scryfall_id IN ? AND storage_location_id IS NOT NULL", scryfallIDs).
+		Order("id ASC").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("loading scattered inventory: %w", err)
+	}
+	rowsByGroup := make(map[consolidationGroup][]models.Inventory, len(groups))
+	for _, item := range items {
+		key := consolidationGroup{ScryfallID: item.ScryfallID, Treatment: item.Treatment}
+		rowsByGroup[key] = append(rowsByGroup[key], item)
+	}
+
+	var sortingRules []models.SortingRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).
+		Order("priority ASC").
+		Preload("StorageLocation").
+		Find(&sortingRules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sorting rules: %w", err)
+	}
+
+	cards, err := models.GetCardsByIDs(s.db.WithContext(ctx), scryfallIDs)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.LocationUsage(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	overflow := s.OverflowLocation(ctx)
+	catchAll := s.CatchAllLocation(ctx)
+	evaluator := rules.NewEvaluator(s.db)
+
+	for _, group := range groups {
+		rows := rowsByGroup[group]
+		suggestion := ConsolidationSuggestion{
+			ScryfallID: group.ScryfallID,
+			Treatment:  group.Treatment,
+			MoveIDs:    []uint{},
+		}
+
+		holdings := map[uint]*ConsolidationHolding{}
+		locations := map[uint]models.StorageLocation{}
+		for _, row := range rows {
+			locationID := *row.StorageLocationID
+			holding, ok := holdings[locationID]
+			if !ok {
+				holding = &ConsolidationHolding{StorageLocationID: locationID}
+				if row.StorageLocation != nil {
+					holding.Name = row.StorageLocation.Name
+					locations[locationID] = *row.StorageLocation
+				}
+				holdings[locationID] = holding
+			}
+			holding.Quantity += row.Quantity
+			holding.InventoryIDs = append(holding.InventoryIDs, row.ID)
+			suggestion.Quantity += row.Quantity
+		}
+		for _, holding := range holdings {
+			suggestion.Holdings = append(suggestion.Holdings, *holding)
+		}
+		slices.SortFunc(suggestion.Holdings, func(a, b ConsolidationHolding) int {
+			if c := cmp.Compare(b.Quantity, a.Quantity); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.StorageLocationID, b.StorageLocationID)
+		})
+
+		// Copies already in a location don't need room there, so place the whole
+		// printing against usage without it
+		for _, holding := range suggestion.Holdings {
+			usage[holding.StorageLocationID] -= holding.Quantity
+		}
+
+		var matches []models.StorageLocation
+		keepUnassigned := false
+		ruleFor := map[uint]models.SortingRule{}
+		if card, ok := cards[group.ScryfallID]; ok {
+			cardData, err := rules.RawJSONToRuleData(card.RawJSON, group.Treatment)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to convert card for consolidation", "component", "auto_sort", "scryfall_id", group.ScryfallID, "error", err)
+			} else {
+				cardData["notes"] = ""
+				suggestion.Name, _ = cardData["name"].(string)
+				for _, trace := range evaluator.TraceCard(cardData, sortingRules) {
+					if _, seen := ruleFor[trace.Rule.StorageLocationID]; !trace.Assigns() || seen {
+						continue
+					}
+					if trace.Rule.StorageLocationID == models.UnassignedLocationID {
+						keepUnassigned = true
+						break
+					}
+					ruleFor[trace.Rule.StorageLocationID] = trace.Rule
+					matches = append(matches, trace.Rule.StorageLocation)
+				}
+			}
+		}
+
+		candidates := matches
+		if !keepUnassigned {
+			candidates = WithCatchAll(matches, catchAll)
+		}
+		target := PlaceWhole(suggestion.Quantity, WithOverflow(candidates, overflow), usage)
+		reason := ""
+		switch {
+		case target == nil:
+			// Fall back to gathering copies where most of them already are
+			for i, holding := range suggestion.Holdings {
+				location, ok := locations[holding.StorageLocationID]
+				if !ok {
+					continue
+				}
+				if target = PlaceWhole(suggestion.Quantity, []models.StorageLocation{location}, usage); target != nil {
+					reason = "already holds copies of this card"
+					if i == 0 {
+						reason = "already holds the most copies"
+					}
+					break
+				}
+			}
+		case ruleFor[target.ID].ID != 0:
+			rule := ruleFor[target.ID]
+			suggestion.MatchedRuleID = &rule.ID
+			suggestion.MatchedRuleName = rule.Name
+			reason = fmt.Sprintf("matches rule %q", rule.Name)
+		case len(matches) == 0:
+			reason = "catch-all location; no rule matches"
+		default:
+			reason = "overflow location; every matching location is full"
+		}
+
+		if target == nil {
+			for _, holding := range suggestion.Holdings {
+				usage[holding.StorageLocationID] += holding.Quantity
+			}
+			continue
+		}
+
+		// PlaceWhole has already counted this printing, so report the room it had before
+		suggestion.Target = *suggestedLocation(*target, usage[target.ID]-suggestion.Quantity, reason)
+		for _, holding := range suggestion.Holdings {
+			if holding.StorageLocationID != target.ID {
+				suggestion.MoveIDs = append(suggestion.MoveIDs, holding.InventoryIDs...)
+			}
+		}
+		slices.Sort(suggestion.MoveIDs)
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
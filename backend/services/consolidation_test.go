@@ -0,0 +1,145 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+)
+
+func TestSuggestConsolidations_RuleTarget(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, rule := setupAutoSortTestData(t, db)
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	shelf := &models.StorageLocation{Name: "Shelf", StorageType: models.Box}
+	db.Create(binder)
+	db.Create(shelf)
+
+	inRule := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &storage.ID)
+	inBinder := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 3, &binder.ID)
+	onShelf := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 2, &shelf.ID)
+	// Unassigned copies and other treatments are not part of the scattered printing
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 5, nil)
+	db.Create(&models.Inventory{ScryfallID: card.ScryfallID, OracleID: card.OracleID, Treatment: "foil", Quantity: 1, StorageLocationID: &binder.ID})
+
+	suggestions, err := NewAutoSortService(db).SuggestConsolidations(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 scattered printing, got %d", len(suggestions))
+	}
+
+	got := suggestions[0]
+	if got.Name != "Test Card" || got.Treatment != "nonfoil" || got.Quantity != 6 {
+		t.Errorf("unexpected suggestion: %+v", got)
+	}
+	if len(got.Holdings) != 3 || got.Holdings[0].StorageLocationID != binder.ID {
+		t.Errorf("expected 3 holdings with the binder first, got %+v", got.Holdings)
+	}
+	if got.Target.StorageLocationID != storage.ID || got.MatchedRuleID == nil || *got.MatchedRuleID != rule.ID {
+		t.Errorf("expected the rule's location %d as target, got %+v", storage.ID, got.Target)
+	}
+	if len(got.MoveIDs) != 2 || got.MoveIDs[0] != inBinder.ID || got.MoveIDs[1] != onShelf.ID {
+		t.Errorf("expected rows %d and %d to move, got %v (not %d)", inBinder.ID, onShelf.ID, got.MoveIDs, inRule.ID)
+	}
+}
+
+func TestSuggestConsolidations_MostCopiesFallback(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+	// The rule's location has room for the copies already there but not the rest
+	db.Model(storage).Update("capacity", 2)
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(binder)
+
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &storage.ID)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 3, &binder.ID)
+
+	suggestions, err := NewAutoSortService(db).SuggestConsolidations(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(suggestions))
+	}
+
+	got := suggestions[0]
+	if got.Target.StorageLocationID != binder.ID || got.Target.Reason != "already holds the most copies" {
+		t.Errorf("expected the binder as fallback target, got %+v", got.Target)
+	}
+	if got.MatchedRuleID != nil {
+		t.Errorf("expected no matched rule, got %d", *got.MatchedRuleID)
+	}
+}
+
+func TestSuggestConsolidations_NoRoom(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Model(storage).Update("capacity", 2)
+
+	small := &models.StorageLocation{Name: "Deck Box", StorageType: models.Box, Capacity: 2}
+	db.Create(small)
+
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 2, &storage.ID)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 2, &small.ID)
+
+	suggestions, err := NewAutoSortService(db).SuggestConsolidations(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected no suggestion without room anywhere, got %+v", suggestions)
+	}
+}
+
+func TestSuggestConsolidations_MinLocations(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(binder)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &storage.ID)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &binder.ID)
+
+	suggestions, err := NewAutoSortService(db).SuggestConsolidations(context.Background(), 3, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected two locations to fall under min_locations 3, got %d", len(suggestions))
+	}
+}
+
+func TestSuggestConsolidations_ExcludeBasicLands(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Create(&models.Card{ScryfallID: "forest", OracleID: "oracle-forest", RawJSON: `{"name":"Forest","type_line":"Basic Land — Forest"}`})
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(binder)
+	for _, id := range []string{card.ScryfallID, "forest"} {
+		oracleID := "oracle-" + id
+		createSuggestionItem(t, db, id, oracleID, 1, &storage.ID)
+		createSuggestionItem(t, db, id, oracleID, 1, &binder.ID)
+	}
+
+	service := NewAutoSortService(db)
+	suggestions, err := service.SuggestConsolidations(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 2 {
+		t.Fatalf("expected basics suggested by default, got %d suggestions", len(suggestions))
+	}
+
+	db.Create(&models.Setting{Key: "consolidation_exclude_basic_lands", Value: "true"})
+	suggestions, err = service.SuggestConsolidations(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 || suggestions[0].ScryfallID != card.ScryfallID {
+		t.Errorf("expected only the non-basic printing, got %+v", suggestions)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds how far ahead Next looks, so an expression that can never
+// match (e.g. February 30th) ends the search
+const cronSearchYears = 5
+
+// cronMacros are the shorthand expressions accepted in place of five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes one of the five fields of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names for min, min+1, ... accepted in place of numbers
+}
+
+var (
+	cronMinute = cronField{name: "minute", min: 0, max: 59}
+	cronHour   = cronField{name: "hour", min: 0, max: 23}
+	cronDay    = cronField{name: "day of month", min: 1, max: 31}
+	cronMonth  = cronField{name: "month", min: 1, max: 12,
+		names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	// Both 0 and 7 are Sunday
+	cronWeekday = cronField{name: "day of week", min: 0, max: 7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// CronSchedule is a parsed five-field cron expression: minute, hour, day of month,
+// month and day of week. Fields accept *, numbers, ranges (1-5), steps (*/15, 1-30/5),
+// comma-separated lists and month and weekday names (JAN, SUN). As in standard cron,
+// when both day fields are restricted a day matching either one matches.
+type CronSchedule struct {
+	expr                          string
+	minutes, hours, days, months  uint64 // Bit n set when value n matches
+	weekdays                      uint64
+	daysRestricted, wdsRestricted bool
+}
+
+// ParseCron parses a cron expression, or one of the @yearly, @monthly, @weekly,
+// @daily and @hourly macros
+func ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	schedule := &CronSchedule{expr: expr}
+	var err error
+	if schedule.minutes, err = cronMinute.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if schedule.hours, err = cronHour.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if schedule.days, err = cronDay.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if schedule.months, err = cronMonth.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays, err = cronWeekday.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1 // Sunday
+	}
+	schedule.daysRestricted = !strings.HasPrefix(fields[2], "*")
+	schedule.wdsRestricted = !strings.HasPrefix(fields[4], "*")
+
+	if schedule.Next(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never matches", expr)
+	}
+	return schedule, nil
+}
+
+// String returns the expression as it was parsed
+func (c *CronSchedule) String() string {
+	return c.expr
+}
+
+// Next returns the first matching minute strictly after t, in t's time zone, or the
+// zero time if none falls within the next few years
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	limit := t.AddDate(cronSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if c.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hours&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minutes&(1<<uint(t.Minute())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches checks t's day against the day of month and day of week fields
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	day := c.days&(1<<uint(t.Day())) != 0
+	weekday := c.weekdays&(1<<uint(t.Weekday())) != 0
+	if c.daysRestricted && c.wdsRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// parse turns one field into a bitset of the values it matches
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if before, after, ok := strings.Cut(part, "/"); ok {
+			n, err := strconv.Atoi(after)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q in %s field", after, f.name)
+			}
+			rangePart, step = before, n
+		}
+
+		low, high := f.min, f.max
+		switch before, after, isRange := strings.Cut(rangePart, "-"); {
+		case rangePart == "*":
+		case isRange:
+			var err error
+			if low, err = f.value(before); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(after); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		default:
+			value, err := f.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			// "5/15" means from 5 to the end in steps of 15; a lone value is just itself
+			if step == 1 {
+				high = value
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name within the field's bounds
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (%d-%d)", s, f.name, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"0 3 30 2 *", // February 30th never comes
+		"@every 5m",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Thursday
+	from := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", at(1, 15, 10, 31)},
+		{"0 3 * * 0", at(1, 18, 3, 0)},
+		{"0 3 * * SUN", at(1, 18, 3, 0)},
+		{"0 3 * * 7", at(1, 18, 3, 0)},
+		{"*/15 * * * *", at(1, 15, 10, 45)},
+		{"5/20 * * * *", at(1, 15, 10, 45)},
+		{"0 9-17/4 * * MON-FRI", at(1, 15, 13, 0)},
+		{"30 2 1,15 * *", at(2, 1, 2, 30)},
+		{"0 0 1 JAN,JUL *", at(7, 1, 0, 0)},
+		// Either day field matches when both are restricted: the 20th or a Friday
+		{"0 0 20 * 5", at(1, 16, 0, 0)},
+		{"@daily", at(1, 16, 0, 0)},
+		{"@hourly", at(1, 15, 11, 0)},
+		{"@weekly", at(1, 18, 0, 0)},
+		{"@monthly", at(2, 1, 0, 0)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) failed: %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.expected, got)
+		}
+	}
+}
+
+func TestCronSchedule_Next_StrictlyAfter(t *testing.T) {
+	schedule, err := ParseCron("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron failed: %v", err)
+	}
+	from := time.Date(2026, 1, 15, 3, 0, 0, 0, time.UTC)
+	if got, expected := schedule.Next(from), from.AddDate(0, 0, 1); !got.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCronSchedule_Next_Location(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	schedule, err := ParseCron("0 3 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron failed: %v", err)
+	}
+
+	got := schedule.Next(time.Date(2026, 1, 15, 12, 0, 0, 0, tokyo))
+	if expected := time.Date(2026, 1, 16, 3, 0, 0, 0, tokyo); !got.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got.Location() != tokyo {
+		t.Errorf("expected the result in Asia/Tokyo, got %v", got.Location())
+	}
+}
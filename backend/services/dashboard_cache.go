@@ -0,0 +1,170 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dashboardCacheSettle is how long after the last watched write the cache is
+// invalidated again. Callbacks fire before a transaction commits, so stats computed
+// between the callback and the commit could be stored against the new generation.
+const dashboardCacheSettle = time.Second
+
+// dashboardCacheTables are the tables dashboard stats are computed from
+var dashboardCacheTables = map[string]bool{
+	"inventories":       true,
+	"list_items":        true,
+	"lists":             true,
+	"storage_locations": true,
+	"cards":             true,
+}
+
+// DashboardCacheService stores computed dashboard stats in dashboard_stats_snapshots
+// and invalidates them whenever the data they are computed from changes
+type DashboardCacheService struct {
+	db         *gorm.DB
+	generation atomic.Uint64
+	settle     time.Duration
+	changed    chan struct{}
+}
+
+// NewDashboardCacheService creates a new dashboard cache service. Generations start
+// from the current time, so snapshots stored by a previous run are never served.
+func NewDashboardCacheService(db *gorm.DB) *DashboardCacheService {
+	s := &DashboardCacheService{
+		db:      db,
+		settle:  dashboardCacheSettle,
+		changed: make(chan struct{}, 1),
+	}
+	s.generation.Store(uint64(time.Now().UnixNano()))
+	return s
+}
+
+// DashboardStatsKey identifies the variant of dashboard stats computed in currency,
+// with or without basic lands
+func DashboardStatsKey(currency models.Currency, excludeBasicLands bool) string {
+	if excludeBasicLands {
+		return string(currency) + ":no_basic_lands"
+	}
+	return string(currency) + ":all"
+}
+
+// Generation returns the current cache generation. Read it before computing stats
+// and pass it to Store, so stats computed across a write are not stored as current.
+func (s *DashboardCacheService) Generation() uint64 {
+	return s.generation.Load()
+}
+
+// Invalidate marks every stored snapshot as stale
+func (s *DashboardCacheService) Invalidate() {
+	s.generation.Add(1)
+}
+
+// Load decodes the snapshot stored under key into dest and reports whether one from
+// the current generation was found
+func (s *DashboardCacheService) Load(ctx context.Context, key string, dest any) (bool, error) {
+	// Find rather than First, since a miss is expected and not worth logging
+	var snapshots []models.DashboardStatsSnapshot
+	if err := s.db.WithContext(ctx).
+		Where("key = ? AND generation = ?", key, s.Generation()).
+		Limit(1).Find(&snapshots).Error; err != nil {
+		return false, fmt.Errorf("loading dashboard stats snapshot: %w", err)
+	}
+	if len(snapshots) == 0 {
+		return false, nil
+	}
+	snapshot := snapshots[0]
+	if err := json.Unmarshal([]byte(snapshot.Stats), dest); err != nil {
+		return false, fmt.Errorf("decoding dashboard stats snapshot: %w", err)
+	}
+	return true, nil
+}
+
+// Store saves stats under key as computed in generation. Stats from a generation
+// that has since been invalidated are discarded.
+func (s *DashboardCacheService) Store(ctx context.Context, key string, generation uint64, stats any) error {
+	if generation != s.Generation() {
+		return nil
+	}
+	encoded, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("encoding dashboard stats: %w", err)
+	}
+	snapshot := models.DashboardStatsSnapshot{Key: key, Generation: generation, Stats: string(encoded)}
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"generation", "stats", "updated_at"}),
+	}).Create(&snapshot).Error
+	if err != nil {
+		return fmt.Errorf("storing dashboard stats snapshot: %w", err)
+	}
+	return nil
+}
+
+// Watch registers callbacks that invalidate the cache on every write through s.db to
+// the tables dashboard stats are computed from, then invalidates once more after
+// writes settle, until ctx is done. Raw statements can't be traced to a table, so any
+// that changes rows invalidates. It must be called at most once per database handle.
+func (s *DashboardCacheService) Watch(ctx context.Context) error {
+	invalidate := func() {
+		s.Invalidate()
+		select {
+		case s.changed <- struct{}{}:
+		default: // A settle invalidation is already pending
+		}
+	}
+	notify := func(tx *gorm.DB) {
+		if tx.Error != nil || !dashboardCacheTables[tx.Statement.Table] || tx.Statement.RowsAffected == 0 {
+			return
+		}
+		invalidate()
+	}
+	notifyRaw := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.RowsAffected == 0 {
+			return
+		}
+		invalidate()
+	}
+
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("dashboard_cache:created", notify); err != nil {
+		return fmt.Errorf("registering create callback: %w", err)
+	}
+	if err := callbacks.Update().After("gorm:update").Register("dashboard_cache:updated", notify); err != nil {
+		return fmt.Errorf("registering update callback: %w", err)
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("dashboard_cache:deleted", notify); err != nil {
+		return fmt.Errorf("registering delete callback: %w", err)
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("dashboard_cache:raw", notifyRaw); err != nil {
+		return fmt.Errorf("registering raw callback: %w", err)
+	}
+
+	go s.run(ctx)
+	return nil
+}
+
+// run invalidates the cache once no watched writes have arrived for s.settle
+func (s *DashboardCacheService) run(ctx context.Context) {
+	timer := time.NewTimer(s.settle)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.changed:
+			timer.Reset(s.settle)
+		case <-timer.C:
+			s.Invalidate()
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupDashboardCacheTest(t *testing.T) (*gorm.DB, *DashboardCacheService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.DashboardStatsSnapshot{}, &models.StorageLocation{}, &models.Inventory{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db, NewDashboardCacheService(db)
+}
+
+type cachedTestStats struct {
+	Cards int `json:"cards"`
+}
+
+func TestDashboardCacheService_LoadAndStore(t *testing.T) {
+	_, cache := setupDashboardCacheTest(t)
+	ctx := context.Background()
+	key := DashboardStatsKey(models.CurrencyUSD, false)
+
+	var stats cachedTestStats
+	if hit, err := cache.Load(ctx, key, &stats); err != nil || hit {
+		t.Fatalf("expected a miss on an empty cache, got hit=%v err=%v", hit, err)
+	}
+
+	if err := cache.Store(ctx, key, cache.Generation(), cachedTestStats{Cards: 3}); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if hit, err := cache.Load(ctx, key, &stats); err != nil || !hit || stats.Cards != 3 {
+		t.Fatalf("expected a hit with 3 cards, got hit=%v err=%v stats=%+v", hit, err, stats)
+	}
+	if hit, _ := cache.Load(ctx, DashboardStatsKey(models.CurrencyUSD, true), &stats); hit {
+		t.Error("expected other settings variants to miss")
+	}
+
+	// Storing again replaces the snapshot
+	if err := cache.Store(ctx, key, cache.Generation(), cachedTestStats{Cards: 4}); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+	if _, err := cache.Load(ctx, key, &stats); err != nil || stats.Cards != 4 {
+		t.Errorf("expected the replaced snapshot, got %+v (err %v)", stats, err)
+	}
+
+	cache.Invalidate()
+	if hit, _ := cache.Load(ctx, key, &stats); hit {
+		t.Error("expected a miss after invalidation")
+	}
+}
+
+func TestDashboardCacheService_StoreDiscardsStaleGeneration(t *testing.T) {
+	_, cache := setupDashboardCacheTest(t)
+	ctx := context.Background()
+	key := DashboardStatsKey(models.CurrencyEUR, false)
+
+	generation := cache.Generation()
+	cache.Invalidate() // A write lands while the stats are computed
+	if err := cache.Store(ctx, key, generation, cachedTestStats{Cards: 1}); err != nil {
+		t.Fatalf("store failed: %v", err)
+	}
+
+	var stats cachedTestStats
+	if hit, _ := cache.Load(ctx, key, &stats); hit {
+		t.Error("expected stats computed before the write to be discarded")
+	}
+}
+
+func TestDashboardCacheService_Watch(t *testing.T) {
+	db, cache := setupDashboardCacheTest(t)
+	cache.settle = time.Hour // Only the writes themselves invalidate here
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := cache.Watch(ctx); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		write       func() error
+		invalidates bool
+	}{
+		{"inventory create", func() error {
+			return db.Create(&models.Inventory{ScryfallID: "a", OracleID: "o", Treatment: "nonfoil", Quantity: 1}).Error
+		}, true},
+		{"inventory update", func() error {
+			return db.Model(&models.Inventory{}).Where("scryfall_id = ?", "a").UpdateColumn("quantity", 2).Error
+		}, true},
+		{"storage location create", func() error {
+			return db.Create(&models.StorageLocation{Name: "Box", StorageType: models.Box}).Error
+		}, true},
+		{"raw statement", func() error {
+			return db.Exec("UPDATE inventories SET quantity = 3").Error
+		}, true},
+		{"unwatched table", func() error {
+			return db.Create(&models.Setting{Key: "theme", Value: "dark"}).Error
+		}, false},
+		{"update matching nothing", func() error {
+			return db.Model(&models.Inventory{}).Where("scryfall_id = ?", "none").UpdateColumn("quantity", 2).Error
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := cache.Generation()
+			if err := tt.write(); err != nil {
+				t.Fatalf("write failed: %v", err)
+			}
+			if invalidated := cache.Generation() != before; invalidated != tt.invalidates {
+				t.Errorf("expected invalidated=%v, got %v", tt.invalidates, invalidated)
+			}
+		})
+	}
+
+}
+
+func TestDashboardCacheService_WatchSettle(t *testing.T) {
+	db, cache := setupDashboardCacheTest(t)
+	cache.settle = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := cache.Watch(ctx); err != nil {
+		t.Fatalf("watch failed: %v", err)
+	}
+
+	// Once writes settle the cache is invalidated again, covering stats computed
+	// before an open transaction committed
+	before := cache.Generation()
+	db.Create(&models.Inventory{ScryfallID: "b", OracleID: "o", Treatment: "nonfoil", Quantity: 1})
+	deadline := time.Now().Add(time.Second)
+	for cache.Generation() < before+2 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a settle invalidation after the write")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
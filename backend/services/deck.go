@@ -0,0 +1,384 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// deckCardInfoQuery selects one printing's rules-relevant fields per oracle ID.
+// These are the same for every printing of a card, so any row will do.
+const deckCardInfoQuery = `
+	SELECT oracle_id,
+		COALESCE(name, '') AS name,
+		COALESCE(json_extract(raw_json, '$.type_line'), '') AS type_line,
+		COALESCE(json_extract(raw_json, '$.oracle_text'), '') AS oracle_text,
+		COALESCE(json_extract(raw_json, '$.legalities'), '{}') AS legalities,
+		COALESCE(json_extract(raw_json, '$.color_identity'), '[]') AS color_identity
+	FROM cards
+	WHERE oracle_id IN ?
+	GROUP BY oracle_id`
+
+// deckFormatRules are the construction rules of a deck format
+type deckFormatRules struct {
+	MinMainboard int // Counting the commander in commander formats
+	MaxMainboard int // 0 for no maximum
+	MaxSideboard int
+	MaxCopies    int // Per card across mainboard and sideboard, basic lands excepted
+}
+
+// deckFormats holds the construction rules for each deck format. Which cards are
+// allowed at all comes from the card legalities.
+var deckFormats = map[models.DeckFormat]deckFormatRules{
+	models.DeckFormatStandard:  {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatPioneer:   {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatModern:    {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatLegacy:    {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatVintage:   {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatPauper:    {MinMainboard: 60, MaxSideboard: 15, MaxCopies: 4},
+	models.DeckFormatCommander: {MinMainboard: 100, MaxMainboard: 100, MaxSideboard: 0, MaxCopies: 1},
+}
+
+// deckCardInfo is what legality checks need to know about a card
+type deckCardInfo struct {
+	OracleID      string
+	Name          string
+	TypeLine      string
+	OracleText    string
+	Legalities    string
+	ColorIdentity string
+}
+
+// legality returns the card's status in a format, e.g. "legal" or "banned".
+// Cards without a status are treated as not legal.
+func (c deckCardInfo) legality(format models.DeckFormat) string {
+	var legalities map[string]string
+	if err := json.Unmarshal([]byte(c.Legalities), &legalities); err != nil {
+		return "not_legal"
+	}
+	if status, ok := legalities[string(format)]; ok {
+		return status
+	}
+	return "not_legal"
+}
+
+// colors returns the card's color identity letters
+func (c deckCardInfo) colors() []string {
+	var colors []string
+	if err := json.Unmarshal([]byte(c.ColorIdentity), &colors); err != nil {
+		return nil
+	}
+	return colors
+}
+
+// unlimitedCopies reports whether a deck may contain any number of the card:
+// basic lands and cards such as Relentless Rats that say so
+func (c deckCardInfo) unlimitedCopies() bool {
+	if strings.Contains(c.TypeLine, "Basic") && strings.Contains(c.TypeLine, "Land") {
+		return true
+	}
+	return strings.Contains(c.OracleText, "A deck can have any number of cards named")
+}
+
+// canBeCommander reports whether the card may be a deck's commander
+func (c deckCardInfo) canBeCommander() bool {
+	if strings.Contains(c.TypeLine, "Legendary") && strings.Contains(c.TypeLine, "Creature") {
+		return true
+	}
+	return strings.Contains(c.OracleText, "can be your commander")
+}
+
+// DeckLegalityIssue is one reason a deck is not legal in its format
+// tygo:export
+type DeckLegalityIssue struct {
+	OracleID string `json:"oracle_id,omitempty"` // Empty for issues about the whole deck
+	Name     string `json:"name,omitempty"`
+	Message  string `json:"message"`
+}
+
+// DeckLegality is the result of checking a deck against its format's rules
+// tygo:export
+type DeckLegality struct {
+	Format         models.DeckFormat   `json:"format"`
+	Legal          bool                `json:"legal"`
+	MainboardCount int                 `json:"mainboard_count"` // Includes the commander
+	SideboardCount int                 `json:"sideboard_count"`
+	Issues         []DeckLegalityIssue `json:"issues"`
+}
+
+// DeckCoverageCard is how many copies of a deck card are owned
+// tygo:export
+type DeckCoverageCard struct {
+	OracleID string          `json:"oracle_id"`
+	Name     string          `json:"name"`
+	Zone     models.DeckZone `json:"zone"` // Empty for the commander
+	Needed   int             `json:"needed"`
+	Owned    int             `json:"owned"`
+	Missing  int             `json:"missing"`
+}
+
+// DeckCoverage reports how much of a deck the inventory covers
+// tygo:export
+type DeckCoverage struct {
+	Cards        []DeckCoverageCard `json:"cards"`
+	TotalNeeded  int                `json:"total_needed"`
+	TotalOwned   int                `json:"total_owned"`
+	TotalMissing int                `json:"total_missing"`
+	Complete     bool               `json:"complete"`
+}
+
+// DeckService checks decks against format rules and the inventory
+type DeckService struct {
+	db *gorm.DB
+}
+
+// NewDeckService creates a new deck service
+func NewDeckService(db *gorm.DB) *DeckService {
+	return &DeckService{db: db}
+}
+
+// CardNames returns the names of the given cards keyed by oracle ID. Cards missing
+// from the local card data are omitted.
+func (s *DeckService) CardNames(ctx context.Context, oracleIDs []string) (map[string]string, error) {
+	info, err := s.cardInfo(ctx, oracleIDs)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(info))
+	for id, card := range info {
+		names[id] = card.Name
+	}
+	return names, nil
+}
+
+// Legality checks a deck, with its cards loaded, against the rules of its format:
+// deck and sideboard size, copy limits, card legalities from the local card data
+// and, for commander formats, the commander and its color identity
+func (s *DeckService) Legality(ctx context.Context, deck models.Deck) (DeckLegality, error) {
+	result := DeckLegality{Format: deck.Format, Issues: []DeckLegalityIssue{}}
+	rules, ok := deckFormats[deck.Format]
+	if !ok {
+		return result, fmt.Errorf("unknown deck format %q", deck.Format)
+	}
+
+	info, err := s.cardInfo(ctx, deckOracleIDs(deck))
+	if err != nil {
+		return result, err
+	}
+	formatName := FormatDisplayName(string(deck.Format))
+	addIssue := func(oracleID, message string) {
+		result.Issues = append(result.Issues, DeckLegalityIssue{
+			OracleID: oracleID,
+			Name:     info[oracleID].Name,
+			Message:  message,
+		})
+	}
+
+	// Copies per card across zones, and the order cards were first seen in
+	copies := make(map[string]int)
+	var order []string
+	count := func(oracleID string, quantity int) {
+		if _, seen := copies[oracleID]; !seen {
+			order = append(order, oracleID)
+		}
+		copies[oracleID] += quantity
+	}
+
+	if deck.CommanderOracleID != "" {
+		result.MainboardCount++
+		count(deck.CommanderOracleID, 1)
+	}
+	for _, card := range deck.Cards {
+		if card.Zone == models.DeckZoneSideboard {
+			result.SideboardCount += card.Quantity
+		} else {
+			result.MainboardCount += card.Quantity
+		}
+		count(card.OracleID, card.Quantity)
+	}
+
+	switch {
+	case rules.MaxMainboard > 0 && rules.MaxMainboard == rules.MinMainboard && result.MainboardCount != rules.MinMainboard:
+		addIssue("", fmt.Sprintf("Deck has %d cards; %s requires exactly %d", result.MainboardCount, formatName, rules.MinMainboard))
+	case result.MainboardCount < rules.MinMainboard:
+		addIssue("", fmt.Sprintf("Mainboard has %d cards; %s requires at least %d", result.MainboardCount, formatName, rules.MinMainboard))
+	case rules.MaxMainboard > 0 && result.MainboardCount > rules.MaxMainboard:
+		addIssue("", fmt.Sprintf("Mainboard has %d cards; %s allows at most %d", result.MainboardCount, formatName, rules.MaxMainboard))
+	}
+	if result.SideboardCount > rules.MaxSideboard {
+		if rules.MaxSideboard == 0 {
+			addIssue("", fmt.Sprintf("%s decks cannot have a sideboard", formatName))
+		} else {
+			addIssue("", fmt.Sprintf("Sideboard has %d cards; %s allows at most %d", result.SideboardCount, formatName, rules.MaxSideboard))
+		}
+	}
+
+	if deck.Format.HasCommander() {
+		s.checkCommander(deck, info, formatName, addIssue)
+	}
+
+	for _, oracleID := range order {
+		card, known := info[oracleID]
+		if !known {
+			addIssue(oracleID, fmt.Sprintf("Card %s is not in the local card data", oracleID))
+			continue
+		}
+
+		switch card.legality(deck.Format) {
+		case "banned":
+			addIssue(oracleID, fmt.Sprintf("%s is banned in %s", card.Name, formatName))
+			continue
+		case "restricted":
+			if copies[oracleID] > 1 {
+				addIssue(oracleID, fmt.Sprintf("%s is restricted in %s; %d copies exceed the limit of 1", card.Name, formatName, copies[oracleID]))
+			}
+			continue
+		case "legal":
+		default:
+			addIssue(oracleID, fmt.Sprintf("%s is not legal in %s", card.Name, formatName))
+			continue
+		}
+
+		if copies[oracleID] > rules.MaxCopies && !card.unlimitedCopies() {
+			addIssue(oracleID, fmt.Sprintf("%s: %d copies exceed the limit of %d", card.Name, copies[oracleID], rules.MaxCopies))
+		}
+	}
+
+	result.Legal = len(result.Issues) == 0
+	return result, nil
+}
+
+// checkCommander reports a missing or ineligible commander and cards outside the
+// commander's color identity
+func (s *DeckService) checkCommander(deck models.Deck, info map[string]deckCardInfo, formatName string, addIssue func(oracleID, message string)) {
+	if deck.CommanderOracleID == "" {
+		addIssue("", fmt.Sprintf("%s decks need a commander", formatName))
+		return
+	}
+	commander, known := info[deck.CommanderOracleID]
+	if !known {
+		// Reported with the other unknown cards
+		return
+	}
+	if !commander.canBeCommander() {
+		addIssue(deck.CommanderOracleID, fmt.Sprintf("%s cannot be a commander", commander.Name))
+	}
+
+	identity := make(map[string]bool)
+	for _, color := range commander.colors() {
+		identity[color] = true
+	}
+	for _, card := range deck.Cards {
+		cardInfo, known := info[card.OracleID]
+		if !known {
+			continue
+		}
+		for _, color := range cardInfo.colors() {
+			if !identity[color] {
+				addIssue(card.OracleID, fmt.Sprintf("%s is outside the commander's color identity", cardInfo.Name))
+				break
+			}
+		}
+	}
+}
+
+// Coverage reports how many copies of each card in a deck, with its cards loaded,
+// the inventory holds. Any printing counts, and copies out on loan still count as
+// owned. Owned copies of a card go to the commander first, then the mainboard,
+// then the sideboard.
+func (s *DeckService) Coverage(ctx context.Context, deck models.Deck) (DeckCoverage, error) {
+	coverage := DeckCoverage{Cards: []DeckCoverageCard{}}
+	oracleIDs := deckOracleIDs(deck)
+
+	info, err := s.cardInfo(ctx, oracleIDs)
+	if err != nil {
+		return coverage, err
+	}
+
+	type ownedRow struct {
+		OracleID string
+		Quantity int
+	}
+	var rows []ownedRow
+	if len(oracleIDs) > 0 {
+		if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+			Select("oracle_id, SUM(quantity) AS quantity").
+			Where("oracle_id IN ?", oracleIDs).
+			Group("oracle_id").
+			Scan(&rows).Error; err != nil {
+			return coverage, fmt.Errorf("loading owned copies for deck: %w", err)
+		}
+	}
+	available := make(map[string]int, len(rows))
+	for _, row := range rows {
+		available[row.OracleID] = row.Quantity
+	}
+
+	add := func(oracleID string, zone models.DeckZone, needed int) {
+		owned := min(needed, available[oracleID])
+		available[oracleID] -= owned
+		coverage.Cards = append(coverage.Cards, DeckCoverageCard{
+			OracleID: oracleID,
+			Name:     info[oracleID].Name,
+			Zone:     zone,
+			Needed:   needed,
+			Owned:    owned,
+			Missing:  needed - owned,
+		})
+		coverage.TotalNeeded += needed
+		coverage.TotalOwned += owned
+	}
+
+	if deck.CommanderOracleID != "" {
+		add(deck.CommanderOracleID, "", 1)
+	}
+	for _, zone := range []models.DeckZone{models.DeckZoneMainboard, models.DeckZoneSideboard} {
+		for _, card := range deck.Cards {
+			if card.Zone == zone {
+				add(card.OracleID, zone, card.Quantity)
+			}
+		}
+	}
+
+	coverage.TotalMissing = coverage.TotalNeeded - coverage.TotalOwned
+	coverage.Complete = coverage.TotalMissing == 0
+	return coverage, nil
+}
+
+// cardInfo loads the rules-relevant fields of the given cards keyed by oracle ID
+func (s *DeckService) cardInfo(ctx context.Context, oracleIDs []string) (map[string]deckCardInfo, error) {
+	info := make(map[string]deckCardInfo, len(oracleIDs))
+	if len(oracleIDs) == 0 {
+		return info, nil
+	}
+
+	var rows []deckCardInfo
+	if err := s.db.WithContext(ctx).Raw(deckCardInfoQuery, oracleIDs).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("loading card data for deck: %w", err)
+	}
+	for _, row := range rows {
+		info[row.OracleID] = row
+	}
+	return info, nil
+}
+
+// deckOracleIDs returns the distinct oracle IDs of a deck's commander and cards
+func deckOracleIDs(deck models.Deck) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	if deck.CommanderOracleID != "" {
+		seen[deck.CommanderOracleID] = true
+		ids = append(ids, deck.CommanderOracleID)
+	}
+	for _, card := range deck.Cards {
+		if !seen[card.OracleID] {
+			seen[card.OracleID] = true
+			ids = append(ids, card.OracleID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// maxDeckListOptions caps how many candidate printings are returned for an ambiguous line
+const maxDeckListOptions = 10
+
+// DeckListLineStatus is the outcome of resolving a single deck list line
+type DeckListLineStatus string
+
+const (
+	DeckListLineMatched    DeckListLineStatus = "matched"
+	DeckListLineAmbiguous  DeckListLineStatus = "ambiguous"
+	DeckListLineUnresolved DeckListLineStatus = "unresolved"
+	DeckListLineInvalid    DeckListLineStatus = "invalid"
+)
+
+// DeckListPrinting is a printing a deck list line resolved to, or might resolve to
+// tygo:export
+type DeckListPrinting struct {
+	ScryfallID      string `json:"scryfall_id"`
+	OracleID        string `json:"oracle_id"`
+	Name            string `json:"name"`
+	SetCode         string `json:"set_code"`
+	CollectorNumber string `json:"collector_number"`
+}
+
+// DeckListLineResult reports how a single deck list line resolved.
+// Matched lines carry Match; ambiguous lines carry Options for the user to choose from.
+// tygo:export
+type DeckListLineResult struct {
+	Line      int                `json:"line"`
+	Text      string             `json:"text"`
+	Status    DeckListLineStatus `json:"status"`
+	Quantity  int                `json:"quantity,omitempty"`
+	Treatment string             `json:"treatment,omitempty"`
+	Match     *DeckListPrinting  `json:"match,omitempty"`
+	Options   []DeckListPrinting `json:"options,omitempty"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// ResolveDeckList parses a plain-text deck list (e.g. an MTGA or Moxfield export) and
+// resolves each line against the local cards table without storing anything.
+//
+// A line matches when it names exactly one card: the requested printing when set and
+// collector number are given, otherwise the newest paper printing. It is ambiguous when
+// the name matches several different cards, or a set code alone matches several printings
+// in that set (e.g. alternate arts).
+func ResolveDeckList(ctx context.Context, db *gorm.DB, text string) ([]DeckListLineResult, error) {
+	lines, invalid, err := parseTextImportLines(text)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []DeckListLineResult{}
+	for _, bad := range invalid {
+		results = append(results, DeckListLineResult{
+			Line: bad.line.LineNumber, Text: bad.line.Text, Status: DeckListLineInvalid, Error: bad.err.Error(),
+		})
+	}
+
+	candidates, err := loadTextImportCandidates(ctx, db, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		results = append(results, resolveDeckListLine(line, candidates[strings.ToLower(line.Name)]))
+	}
+
+	slices.SortStableFunc(results, func(a, b DeckListLineResult) int {
+		return a.Line - b.Line
+	})
+	return results, nil
+}
+
+// resolveDeckListLine matches a parsed line against the printings sharing its name
+func resolveDeckListLine(line TextImportLine, candidates []textImportCandidate) DeckListLineResult {
+	result := DeckListLineResult{
+		Line:      line.LineNumber,
+		Text:      line.Text,
+		Quantity:  line.Quantity,
+		Treatment: line.Treatment,
+	}
+
+	var matching []textImportCandidate
+	oracleIDs := map[string]bool{}
+	for _, candidate := range candidates {
+		if candidate.OracleID == "" {
+			continue
+		}
+		if line.SetCode != "" && candidate.SetCode != line.SetCode {
+			continue
+		}
+		if line.CollectorNumber != "" && candidate.CollectorNumber != line.CollectorNumber {
+			continue
+		}
+		matching = append(matching, candidate)
+		oracleIDs[candidate.OracleID] = true
+	}
+
+	if len(matching) == 0 {
+		result.Status = DeckListLineUnresolved
+		result.Error = "no matching card found"
+		return result
+	}
+
+	slices.SortFunc(matching, func(a, b textImportCandidate) int {
+		if preferTextImportPrinting(a, b) {
+			return -1
+		}
+		if preferTextImportPrinting(b, a) {
+			return 1
+		}
+		return 0
+	})
+
+	// Without a set code any printing of a single card will do; with one, the
+	// printings left must be the same card in that set
+	ambiguous := len(oracleIDs) > 1 ||
+		(line.SetCode != "" && line.CollectorNumber == "" && len(matching) > 1)
+	if ambiguous {
+		result.Status = DeckListLineAmbiguous
+		for _, candidate := range matching[:min(len(matching), maxDeckListOptions)] {
+			result.Options = append(result.Options, newDeckListPrinting(candidate))
+		}
+		return result
+	}
+
+	match := newDeckListPrinting(matching[0])
+	result.Status = DeckListLineMatched
+	result.Match = &match
+	return result
+}
+
+func newDeckListPrinting(candidate textImportCandidate) DeckListPrinting {
+	return DeckListPrinting{
+		ScryfallID:      candidate.ScryfallID,
+		OracleID:        candidate.OracleID,
+		Name:            candidate.Name,
+		SetCode:         candidate.SetCode,
+		CollectorNumber: candidate.CollectorNumber,
+	}
+}
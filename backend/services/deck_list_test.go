@@ -0,0 +1,104 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveDeckList(t *testing.T) {
+	_, db := setupTextImportTest(t)
+
+	extra := []models.Card{
+		{ScryfallID: "bolt-2x2-alt", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"2x2","collector_number":"400","released_at":"2022-07-08"}`},
+	}
+	for _, card := range extra {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	text := strings.Join([]string{
+		"4 Lightning Bolt",
+		"1 Lightning Bolt (M10) 146",
+		"2 Lightning Bolt (2X2)",
+		"1 Delver of Secrets *F*",
+		"1 Black Lotus",
+		"0 Counterspell",
+	}, "\n")
+
+	results, err := ResolveDeckList(context.Background(), db, text)
+	if err != nil {
+		t.Fatalf("ResolveDeckList failed: %v", err)
+	}
+	if len(results) != 6 {
+		t.Fatalf("expected 6 results, got %d", len(results))
+	}
+
+	expected := []struct {
+		status     DeckListLineStatus
+		scryfallID string
+	}{
+		{DeckListLineMatched, "bolt-2x2"},
+		{DeckListLineMatched, "bolt-m10"},
+		{DeckListLineAmbiguous, ""},
+		{DeckListLineMatched, "delver"},
+		{DeckListLineUnresolved, ""},
+		{DeckListLineInvalid, ""},
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Line != i+1 {
+			t.Errorf("result %d: expected line %d, got %d", i, i+1, got.Line)
+		}
+		if got.Status != want.status {
+			t.Errorf("line %d: expected status %s, got %s", got.Line, want.status, got.Status)
+			continue
+		}
+		if want.scryfallID != "" && (got.Match == nil || got.Match.ScryfallID != want.scryfallID) {
+			t.Errorf("line %d: expected match %s, got %+v", got.Line, want.scryfallID, got.Match)
+		}
+	}
+
+	if len(results[2].Options) != 2 {
+		t.Errorf("expected 2 options for ambiguous set-only line, got %d", len(results[2].Options))
+	}
+	if results[3].Treatment != "foil" || results[0].Quantity != 4 {
+		t.Errorf("expected quantity and treatment to be carried through, got %+v / %+v", results[0], results[3])
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected resolving to create nothing, got %d inventory rows", count)
+	}
+}
+
+func TestResolveDeckList_AmbiguousName(t *testing.T) {
+	_, db := setupTextImportTest(t)
+
+	// A different card whose front face shares a pasted name
+	card := models.Card{ScryfallID: "other-delver", OracleID: "oracle-other", RawJSON: `{"name":"Delver of Secrets","set":"xyz","collector_number":"1"}`}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+
+	results, err := ResolveDeckList(context.Background(), db, "1 Delver of Secrets")
+	if err != nil {
+		t.Fatalf("ResolveDeckList failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != DeckListLineAmbiguous {
+		t.Fatalf("expected one ambiguous line, got %+v", results)
+	}
+}
+
+func TestResolveDeckList_TooManyLines(t *testing.T) {
+	_, db := setupTextImportTest(t)
+
+	text := strings.Repeat("1 Lightning Bolt\n", MaxTextImportLines+1)
+	if _, err := ResolveDeckList(context.Background(), db, text); !errors.Is(err, ErrTooManyLines) {
+		t.Errorf("expected ErrTooManyLines, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupDeckTest(t *testing.T) (*gorm.DB, *DeckService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	return db, NewDeckService(db)
+}
+
+// createDeckTestCard creates a printing with the fields deck checks read
+func createDeckTestCard(t *testing.T, db *gorm.DB, oracleID, name, typeLine, colorIdentity, legalities string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: "print-" + oracleID,
+		OracleID:   oracleID,
+		RawJSON: fmt.Sprintf(`{"name":%q,"type_line":%q,"color_identity":%s,"legalities":%s}`,
+			name, typeLine, colorIdentity, legalities),
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+// issueMessages joins a legality result's issue messages for error output
+func issueMessages(result DeckLegality) string {
+	messages := make([]string, len(result.Issues))
+	for i, issue := range result.Issues {
+		messages[i] = issue.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func hasIssue(result DeckLegality, message string) bool {
+	for _, issue := range result.Issues {
+		if issue.Message == message {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeckService_Legality_Constructed(t *testing.T) {
+	db, service := setupDeckTest(t)
+	createDeckTestCard(t, db, "bolt", "Lightning Bolt", "Instant", `["R"]`, `{"modern":"legal","vintage":"legal"}`)
+	createDeckTestCard(t, db, "mountain", "Mountain", "Basic Land — Mountain", `[]`, `{"modern":"legal","vintage":"legal"}`)
+	createDeckTestCard(t, db, "ponder", "Ponder", "Sorcery", `["U"]`, `{"modern":"banned","vintage":"restricted"}`)
+	createDeckTestCard(t, db, "wish", "Ring of Maruf", "Artifact", `[]`, `{"modern":"not_legal"}`)
+
+	legal := models.Deck{Format: models.DeckFormatModern, Cards: []models.DeckCard{
+		{OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 4},
+		{OracleID: "mountain", Zone: models.DeckZoneMainboard, Quantity: 56},
+		{OracleID: "mountain", Zone: models.DeckZoneSideboard, Quantity: 15},
+	}}
+	result, err := service.Legality(context.Background(), legal)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	if !result.Legal {
+		t.Errorf("expected deck to be legal, got issues: %s", issueMessages(result))
+	}
+	if result.MainboardCount != 60 || result.SideboardCount != 15 {
+		t.Errorf("expected 60/15 cards, got %d/%d", result.MainboardCount, result.SideboardCount)
+	}
+
+	illegal := models.Deck{Format: models.DeckFormatModern, Cards: []models.DeckCard{
+		{OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 3},
+		{OracleID: "bolt", Zone: models.DeckZoneSideboard, Quantity: 2},
+		{OracleID: "ponder", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "wish", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "unknown", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "mountain", Zone: models.DeckZoneSideboard, Quantity: 14},
+	}}
+	result, err = service.Legality(context.Background(), illegal)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	if result.Legal {
+		t.Fatal("expected deck to be illegal")
+	}
+	for _, want := range []string{
+		"Mainboard has 6 cards; Modern requires at least 60",
+		"Sideboard has 16 cards; Modern allows at most 15",
+		"Lightning Bolt: 5 copies exceed the limit of 4",
+		"Ponder is banned in Modern",
+		"Ring of Maruf is not legal in Modern",
+		"Card unknown is not in the local card data",
+	} {
+		if !hasIssue(result, want) {
+			t.Errorf("expected issue %q, got: %s", want, issueMessages(result))
+		}
+	}
+}
+
+func TestDeckService_Legality_Restricted(t *testing.T) {
+	db, service := setupDeckTest(t)
+	createDeckTestCard(t, db, "ponder", "Ponder", "Sorcery", `["U"]`, `{"vintage":"restricted"}`)
+	createDeckTestCard(t, db, "island", "Island", "Basic Land — Island", `[]`, `{"vintage":"legal"}`)
+
+	deck := models.Deck{Format: models.DeckFormatVintage, Cards: []models.DeckCard{
+		{OracleID: "ponder", Zone: models.DeckZoneMainboard, Quantity: 2},
+		{OracleID: "island", Zone: models.DeckZoneMainboard, Quantity: 58},
+	}}
+	result, err := service.Legality(context.Background(), deck)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	want := "Ponder is restricted in Vintage; 2 copies exceed the limit of 1"
+	if len(result.Issues) != 1 || result.Issues[0].Message != want {
+		t.Errorf("expected only %q, got: %s", want, issueMessages(result))
+	}
+}
+
+func TestDeckService_Legality_Commander(t *testing.T) {
+	db, service := setupDeckTest(t)
+	createDeckTestCard(t, db, "krenko", "Krenko, Mob Boss", "Legendary Creature — Goblin Warrior", `["R"]`, `{"commander":"legal"}`)
+	createDeckTestCard(t, db, "bolt", "Lightning Bolt", "Instant", `["R"]`, `{"commander":"legal"}`)
+	createDeckTestCard(t, db, "counterspell", "Counterspell", "Instant", `["U"]`, `{"commander":"legal"}`)
+	createDeckTestCard(t, db, "mountain", "Mountain", "Basic Land — Mountain", `[]`, `{"commander":"legal"}`)
+
+	deck := models.Deck{Format: models.DeckFormatCommander, CommanderOracleID: "krenko", Cards: []models.DeckCard{
+		{OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "mountain", Zone: models.DeckZoneMainboard, Quantity: 98},
+	}}
+	result, err := service.Legality(context.Background(), deck)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	if !result.Legal || result.MainboardCount != 100 {
+		t.Errorf("expected a legal 100-card deck, got %d cards and issues: %s", result.MainboardCount, issueMessages(result))
+	}
+
+	deck.CommanderOracleID = "bolt"
+	deck.Cards = []models.DeckCard{
+		{OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "counterspell", Zone: models.DeckZoneMainboard, Quantity: 1},
+		{OracleID: "mountain", Zone: models.DeckZoneSideboard, Quantity: 1},
+	}
+	result, err = service.Legality(context.Background(), deck)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	for _, want := range []string{
+		"Deck has 3 cards; Commander requires exactly 100",
+		"Commander decks cannot have a sideboard",
+		"Lightning Bolt cannot be a commander",
+		"Counterspell is outside the commander's color identity",
+		"Lightning Bolt: 2 copies exceed the limit of 1",
+	} {
+		if !hasIssue(result, want) {
+			t.Errorf("expected issue %q, got: %s", want, issueMessages(result))
+		}
+	}
+
+	deck.CommanderOracleID = ""
+	result, err = service.Legality(context.Background(), deck)
+	if err != nil {
+		t.Fatalf("legality check failed: %v", err)
+	}
+	if !hasIssue(result, "Commander decks need a commander") {
+		t.Errorf("expected a missing commander issue, got: %s", issueMessages(result))
+	}
+}
+
+func TestDeckService_Coverage(t *testing.T) {
+	db, service := setupDeckTest(t)
+	createDeckTestCard(t, db, "krenko", "Krenko, Mob Boss", "Legendary Creature — Goblin Warrior", `["R"]`, `{}`)
+	createDeckTestCard(t, db, "bolt", "Lightning Bolt", "Instant", `["R"]`, `{}`)
+
+	// Two printings of Bolt count together; trashed copies don't count
+	for _, row := range []models.Inventory{
+		{ScryfallID: "print-bolt", OracleID: "bolt", Treatment: "nonfoil", Quantity: 2},
+		{ScryfallID: "other-bolt", OracleID: "bolt", Treatment: "foil", Quantity: 1},
+		{ScryfallID: "print-krenko", OracleID: "krenko", Treatment: "nonfoil", Quantity: 1},
+	} {
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+	trashed := models.Inventory{ScryfallID: "print-bolt", OracleID: "bolt", Treatment: "nonfoil", Quantity: 5}
+	db.Create(&trashed)
+	db.Delete(&trashed)
+
+	deck := models.Deck{Format: models.DeckFormatCommander, CommanderOracleID: "krenko", Cards: []models.DeckCard{
+		{OracleID: "bolt", Zone: models.DeckZoneSideboard, Quantity: 2},
+		{OracleID: "bolt", Zone: models.DeckZoneMainboard, Quantity: 2},
+	}}
+	coverage, err := service.Coverage(context.Background(), deck)
+	if err != nil {
+		t.Fatalf("coverage failed: %v", err)
+	}
+
+	if coverage.TotalNeeded != 5 || coverage.TotalOwned != 4 || coverage.TotalMissing != 1 || coverage.Complete {
+		t.Errorf("expected 4 of 5 owned, got %+v", coverage)
+	}
+	if len(coverage.Cards) != 3 {
+		t.Fatalf("expected commander, mainboard and sideboard entries, got %+v", coverage.Cards)
+	}
+	// Owned copies go to the commander, then the mainboard, then the sideboard
+	expected := []DeckCoverageCard{
+		{OracleID: "krenko", Name: "Krenko, Mob Boss", Zone: "", Needed: 1, Owned: 1},
+		{OracleID: "bolt", Name: "Lightning Bolt", Zone: models.DeckZoneMainboard, Needed: 2, Owned: 2},
+		{OracleID: "bolt", Name: "Lightning Bolt", Zone: models.DeckZoneSideboard, Needed: 2, Owned: 1, Missing: 1},
+	}
+	for i, want := range expected {
+		if coverage.Cards[i] != want {
+			t.Errorf("card %d: expected %+v, got %+v", i, want, coverage.Cards[i])
+		}
+	}
+}
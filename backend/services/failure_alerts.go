@@ -0,0 +1,112 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// DefaultFailureAlertThreshold is the default for failure_alert_threshold
+const DefaultFailureAlertThreshold = 3
+
+// FailureAlertService raises a task_failure notification, sent to the configured
+// channels, when a scheduled task or a type of job fails failure_alert_threshold
+// times in a row. Each streak alerts once, when it reaches the threshold. A nil
+// *FailureAlertService is valid and ignores everything.
+type FailureAlertService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+
+	mu           sync.Mutex
+	taskFailures map[string]int // Consecutive failures per task since this process started
+}
+
+// NewFailureAlertService creates a new failure alert service
+func NewFailureAlertService(db *gorm.DB, notifications *NotificationService) *FailureAlertService {
+	return &FailureAlertService{db: db, notifications: notifications, taskFailures: make(map[string]int)}
+}
+
+// threshold reads the failure_alert_threshold setting
+func (s *FailureAlertService) threshold(ctx context.Context) int {
+	settings := settingsStore(s.db)
+	if threshold := settings.GetInt(ctx, "failure_alert_threshold", DefaultFailureAlertThreshold); threshold >= 1 {
+		return threshold
+	}
+	return DefaultFailureAlertThreshold
+}
+
+// TaskFinished records a scheduled task's outcome, alerting when its failure streak
+// reaches the threshold
+func (s *FailureAlertService) TaskFinished(ctx context.Context, name string, err error) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if err == nil {
+		delete(s.taskFailures, name)
+		s.mu.Unlock()
+		return
+	}
+	s.taskFailures[name]++
+	failures := s.taskFailures[name]
+	s.mu.Unlock()
+
+	if failures != s.threshold(ctx) {
+		return
+	}
+	s.alert(ctx, fmt.Sprintf("Scheduled task %s failed %d times in a row", name, failures),
+		fmt.Sprintf("The last run failed with: %v", err))
+}
+
+// JobFailed alerts when the failed job completes a streak of threshold failed jobs of
+// its type. Cancelled and unfinished jobs don't break or extend a streak.
+func (s *FailureAlertService) JobFailed(ctx context.Context, jobID uint) {
+	if s == nil {
+		return
+	}
+
+	var job models.Job
+	if err := s.db.WithContext(ctx).First(&job, jobID).Error; err != nil {
+		slog.WarnContext(ctx, "failed to load failed job", "component", "failure_alerts", "job_id", jobID, "error", err)
+		return
+	}
+
+	// The job's streak is the threshold most recent finished jobs of its type; one more
+	// tells whether the streak had already reached it before
+	threshold := s.threshold(ctx)
+	var statuses []models.JobStatus
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("type = ? AND id <= ? AND status IN ?", job.Type, job.ID,
+			[]models.JobStatus{models.JobStatusCompleted, models.JobStatusFailed}).
+		Order("id DESC").
+		Limit(threshold+1).
+		Pluck("status", &statuses).Error; err != nil {
+		slog.WarnContext(ctx, "failed to load job history", "component", "failure_alerts", "job_id", jobID, "error", err)
+		return
+	}
+
+	streak := 0
+	for _, status := range statuses {
+		if status != models.JobStatusFailed {
+			break
+		}
+		streak++
+	}
+	if streak != threshold {
+		return
+	}
+	s.alert(ctx, fmt.Sprintf("%s jobs failed %d times in a row", job.Type, threshold),
+		fmt.Sprintf("Job %d failed with: %s", job.ID, job.Error))
+}
+
+// alert records the notification and sends it to every channel
+func (s *FailureAlertService) alert(ctx context.Context, title, message string) {
+	if _, err := s.notifications.Notify(ctx, models.NotificationTypeTaskFailure, title, message); err != nil {
+		slog.ErrorContext(ctx, "failed to raise failure alert", "component", "failure_alerts", "title", title, "error", err)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordingChannel is a NotificationChannel that keeps what it was sent
+type recordingChannel struct {
+	titles []string
+	err    error
+}
+
+func (c *recordingChannel) Name() string { return "recording" }
+
+func (c *recordingChannel) Send(ctx context.Context, title, message string) error {
+	c.titles = append(c.titles, title)
+	return c.err
+}
+
+func setupFailureAlertTest(t *testing.T) (*FailureAlertService, *recordingChannel, *gorm.DB) {
+	t.Helper()
+	db := setupNotificationChannelTest(t)
+	db.Create(&models.Setting{Key: "failure_alert_threshold", Value: "2"})
+
+	notifications := NewNotificationService(db)
+	channel := &recordingChannel{}
+	notifications.AddChannel(channel)
+	return NewFailureAlertService(db, notifications), channel, db
+}
+
+func TestNotificationService_Notify(t *testing.T) {
+	service := setupNotificationServiceTest(t)
+	failing := &recordingChannel{err: errors.New("unreachable")}
+	working := &recordingChannel{}
+	service.AddChannel(failing)
+	service.AddChannel(working)
+
+	notification, err := service.Notify(context.Background(), models.NotificationTypeTaskFailure, "Backup failed", "disk full")
+	if err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if notification.ID == 0 || notification.Type != models.NotificationTypeTaskFailure {
+		t.Errorf("expected a recorded task_failure notification, got %+v", notification)
+	}
+	if len(failing.titles) != 1 || len(working.titles) != 1 || working.titles[0] != "Backup failed" {
+		t.Errorf("expected both channels to be sent the notification despite one failing")
+	}
+
+	results := service.TestChannels(context.Background())
+	if len(results) != 2 || results[0].Error != "unreachable" || results[1].Error != "" {
+		t.Errorf("unexpected test results %+v", results)
+	}
+}
+
+func TestFailureAlertService_TaskFinished(t *testing.T) {
+	service, channel, db := setupFailureAlertTest(t)
+	ctx := context.Background()
+	failure := errors.New("disk full")
+
+	service.TaskFinished(ctx, "backup", failure)
+	if len(channel.titles) != 0 {
+		t.Fatal("expected no alert below the threshold")
+	}
+	service.TaskFinished(ctx, "backup", failure)
+	service.TaskFinished(ctx, "backup", failure)
+	if len(channel.titles) != 1 || channel.titles[0] != "Scheduled task backup failed 2 times in a row" {
+		t.Fatalf("expected one alert per streak, got %v", channel.titles)
+	}
+
+	// Another task's streak is counted separately, and success starts a new streak
+	service.TaskFinished(ctx, "purge", failure)
+	service.TaskFinished(ctx, "backup", nil)
+	service.TaskFinished(ctx, "backup", failure)
+	if len(channel.titles) != 1 {
+		t.Fatalf("expected no alert after a success reset the streak, got %v", channel.titles)
+	}
+	service.TaskFinished(ctx, "backup", failure)
+	if len(channel.titles) != 2 {
+		t.Fatalf("expected a new streak to alert again, got %v", channel.titles)
+	}
+
+	var count int64
+	db.Model(&models.Notification{}).Where("type = ?", models.NotificationTypeTaskFailure).Count(&count)
+	if count != 2 {
+		t.Errorf("expected 2 recorded notifications, got %d", count)
+	}
+}
+
+func TestFailureAlertService_JobFailed(t *testing.T) {
+	service, channel, db := setupFailureAlertTest(t)
+	ctx := context.Background()
+	jobs := NewJobService(db)
+	jobs.SetFailureAlerts(service)
+
+	finish := func(jobType models.JobType, status models.JobStatus) {
+		t.Helper()
+		job, err := jobs.Create(ctx, jobType, "")
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		switch status {
+		case models.JobStatusFailed:
+			err = jobs.Fail(ctx, job.ID, "download timed out")
+		case models.JobStatusCompleted:
+			err = jobs.Complete(ctx, job.ID)
+		case models.JobStatusCancelled:
+			err = db.Model(job).Update("status", models.JobStatusCancelled).Error
+		}
+		if err != nil {
+			t.Fatalf("finishing job failed: %v", err)
+		}
+	}
+
+	finish(models.JobTypeBulkDataImport, models.JobStatusFailed)
+	finish(models.JobTypeSetDataImport, models.JobStatusFailed)     // Other types don't count
+	finish(models.JobTypeBulkDataImport, models.JobStatusCancelled) // Neither breaks nor extends a streak
+	if len(channel.titles) != 0 {
+		t.Fatalf("expected no alert below the threshold, got %v", channel.titles)
+	}
+
+	finish(models.JobTypeBulkDataImport, models.JobStatusFailed)
+	if len(channel.titles) != 1 || channel.titles[0] != "bulk_data_import jobs failed 2 times in a row" {
+		t.Fatalf("expected an alert at the threshold, got %v", channel.titles)
+	}
+	finish(models.JobTypeBulkDataImport, models.JobStatusFailed)
+	if len(channel.titles) != 1 {
+		t.Fatalf("expected one alert per streak, got %v", channel.titles)
+	}
+
+	finish(models.JobTypeBulkDataImport, models.JobStatusCompleted)
+	finish(models.JobTypeBulkDataImport, models.JobStatusFailed)
+	finish(models.JobTypeBulkDataImport, models.JobStatusFailed)
+	if len(channel.titles) != 2 {
+		t.Fatalf("expected a new streak to alert again, got %v", channel.titles)
+	}
+}
+
+func TestScheduler_CheckTask_ReportsFailures(t *testing.T) {
+	scheduler, _, _, settingsService, db := setupSchedulerTest(t)
+	db.AutoMigrate(&models.Notification{})
+	settingsService.Set(context.Background(), "failure_alert_threshold", "1")
+
+	notifications := NewNotificationService(db)
+	channel := &recordingChannel{}
+	notifications.AddChannel(channel)
+	scheduler.SetFailureAlerts(NewFailureAlertService(db, notifications))
+
+	task := ScheduledTask{
+		Name:     "test_failing",
+		Interval: 1 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			return errors.New("source unavailable")
+		},
+	}
+	scheduler.checkTask(context.Background(), task, true)
+
+	if len(channel.titles) != 1 || !strings.Contains(channel.titles[0], "test_failing") {
+		t.Errorf("expected the failure to be alerted, got %v", channel.titles)
+	}
+}
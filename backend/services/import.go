@@ -0,0 +1,505 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows caps the number of rows accepted in a single CSV upload
+const MaxImportRows = 20000
+
+// maxImportRowErrors caps how many per-row errors are kept in the job metadata
+const maxImportRowErrors = 500
+
+var (
+	// ErrUnknownImportFormat is returned when the CSV header matches no supported export format
+	ErrUnknownImportFormat = errors.New("unrecognized CSV format (expected a Moxfield, Deckbox, TCGPlayer, or Scryfall export)")
+
+	// ErrTooManyRows is returned when a CSV upload exceeds MaxImportRows
+	ErrTooManyRows = fmt.Errorf("CSV exceeds %d rows", MaxImportRows)
+)
+
+// ImportFormat identifies the collection manager a CSV was exported from
+// tygo:export
+type ImportFormat string
+
+const (
+	ImportFormatMoxfield  ImportFormat = "moxfield"
+	ImportFormatDeckbox   ImportFormat = "deckbox"
+	ImportFormatTCGPlayer ImportFormat = "tcgplayer"
+	ImportFormatScryfall  ImportFormat = "scryfall"
+)
+
+// importColumns names the header of each field in a given export format
+type importColumns struct {
+	quantity        string
+	name            string
+	setCode         string
+	collectorNumber string
+	finish          string
+	scryfallID      string // Exact printing, when the export includes it
+}
+
+var importFormatColumns = map[ImportFormat]importColumns{
+	ImportFormatMoxfield:  {quantity: "count", name: "name", setCode: "edition", collectorNumber: "collector number", finish: "foil"},
+	ImportFormatDeckbox:   {quantity: "count", name: "name", setCode: "edition code", collectorNumber: "card number", finish: "foil"},
+	ImportFormatTCGPlayer: {quantity: "quantity", name: "name", setCode: "set code", collectorNumber: "card number", finish: "printing"},
+	ImportFormatScryfall:  {quantity: "count", name: "name", setCode: "set_code", collectorNumber: "collector_number", finish: "finish", scryfallID: "scryfall_id"},
+}
+
+// ParseImportFormat validates a user-supplied format name
+func ParseImportFormat(value string) (ImportFormat, error) {
+	format := ImportFormat(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := importFormatColumns[format]; !ok {
+		return "", fmt.Errorf("invalid format %q (expected moxfield, deckbox, tcgplayer, or scryfall)", value)
+	}
+	return format, nil
+}
+
+// detectImportFormat guesses the export format from the CSV header
+func detectImportFormat(header map[string]int) (ImportFormat, bool) {
+	has := func(column string) bool {
+		_, ok := header[column]
+		return ok
+	}
+
+	switch {
+	case has("scryfall_id") && has("count"):
+		return ImportFormatScryfall, true
+	case has("edition code") && has("count"):
+		return ImportFormatDeckbox, true
+	case has("quantity") && has("set code") && has("printing"):
+		return ImportFormatTCGPlayer, true
+	case has("count") && has("edition"):
+		return ImportFormatMoxfield, true
+	default:
+		return "", false
+	}
+}
+
+// ImportRow is a single parsed CSV row
+type ImportRow struct {
+	Row             int // 1-based data row number, excluding the header
+	Quantity        int
+	Name            string
+	SetCode         string
+	CollectorNumber string
+	Treatment       string
+	ScryfallID      string // Exact printing, when the export includes it
+	Error           string // Set when the row could not be parsed
+}
+
+// ParseImportCSV reads an exported collection CSV. When format is empty it is
+// detected from the header. Rows that cannot be parsed are returned with Error set
+// so they can be reported alongside resolution failures.
+func ParseImportCSV(r io.Reader, format ImportFormat) ([]ImportRow, ImportFormat, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	headerRecord, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, "", errors.New("CSV is empty")
+		}
+		return nil, "", fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	header := make(map[string]int, len(headerRecord))
+	for i, column := range headerRecord {
+		// Strip a UTF-8 byte order mark some spreadsheet tools prepend
+		header[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+
+	if format == "" {
+		detected, ok := detectImportFormat(header)
+		if !ok {
+			return nil, "", ErrUnknownImportFormat
+		}
+		format = detected
+	}
+
+	columns := importFormatColumns[format]
+	if _, ok := header[columns.setCode]; !ok && format == ImportFormatScryfall {
+		// Some Scryfall exports carry the set code in a plain "set" column
+		if i, ok := header["set"]; ok {
+			header[columns.setCode] = i
+		}
+	}
+	for _, required := range []string{columns.quantity, columns.name} {
+		if _, ok := header[required]; !ok {
+			return nil, "", fmt.Errorf("CSV is missing the %q column for %s format", required, format)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := header[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ImportRow
+	for rowNumber := 1; ; rowNumber++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if rowNumber > MaxImportRows {
+			return nil, "", ErrTooManyRows
+		}
+		if err != nil {
+			rows = append(rows, ImportRow{Row: rowNumber, Error: "could not parse row"})
+			continue
+		}
+
+		row := ImportRow{
+			Row:             rowNumber,
+			Name:            field(record, columns.name),
+			SetCode:         strings.ToLower(field(record, columns.setCode)),
+			CollectorNumber: field(record, columns.collectorNumber),
+			Treatment:       importTreatment(field(record, columns.finish)),
+			ScryfallID:      strings.ToLower(field(record, columns.scryfallID)),
+		}
+		// TCGPlayer writes collector numbers as "146/249"
+		row.CollectorNumber, _, _ = strings.Cut(row.CollectorNumber, "/")
+
+		if row.Name == "" && row.SetCode == "" && row.ScryfallID == "" {
+			// Trailing blank lines are common in hand-edited exports
+			continue
+		}
+		if row.Name == "" && row.ScryfallID == "" {
+			row.Error = "name is required"
+		}
+
+		quantity, err := strconv.Atoi(field(record, columns.quantity))
+		if err != nil || quantity < 1 {
+			row.Error = "quantity must be at least 1"
+		}
+		row.Quantity = quantity
+
+		rows = append(rows, row)
+	}
+
+	return rows, format, nil
+}
+
+// importTreatment maps an export's foil/printing column to an inventory treatment
+func importTreatment(value string) string {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "etched"):
+		return "etched"
+	case strings.Contains(value, "foil"):
+		return "foil"
+	default:
+		return "nonfoil"
+	}
+}
+
+// ImportRowError reports why a CSV row was not imported
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
+
+// ImportJobMetadata is stored in job.Metadata while an inventory import runs
+type ImportJobMetadata struct {
+	Format        ImportFormat     `json:"format"`
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	ImportedRows  int              `json:"imported_rows"`
+	FailedRows    int              `json:"failed_rows"`
+	Errors        []ImportRowError `json:"errors"` // First maxImportRowErrors failures
+
+	BatchSize       int     `json:"batch_size"`
+	TransactionSize int     `json:"transaction_size"`
+	Benchmark       bool    `json:"benchmark,omitempty"` // Rows were rolled back; ImportedRows counts what would have been imported
+	DurationMS      int64   `json:"duration_ms"`
+	RowsPerSecond   float64 `json:"rows_per_second"`
+}
+
+// recordDuration sets the elapsed time and throughput since started
+func (m *ImportJobMetadata) recordDuration(started time.Time) {
+	elapsed := time.Since(started)
+	m.DurationMS = elapsed.Milliseconds()
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		m.RowsPerSecond = float64(m.ProcessedRows) / seconds
+	}
+}
+
+// ImportService creates inventory from collection CSV exports as a background job
+type ImportService struct {
+	db         *gorm.DB
+	jobService *JobService
+}
+
+// NewImportService creates a new CSV import service. Auto-sorting runs inside each
+// import transaction, so it has no AutoSortService of its own.
+func NewImportService(db *gorm.DB, jobService *JobService) *ImportService {
+	return &ImportService{db: db, jobService: jobService}
+}
+
+// CreateImportJob creates a pending job for a CSV import
+func (s *ImportService) CreateImportJob(ctx context.Context, format ImportFormat, totalRows int) (*models.Job, error) {
+	metadata, err := json.Marshal(ImportJobMetadata{Format: format, TotalRows: totalRows, Errors: []ImportRowError{}})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling import job metadata: %w", err)
+	}
+	return s.jobService.Create(ctx, models.JobTypeInventoryImport, string(metadata))
+}
+
+// ImportOptions are the per-job choices for a CSV import
+type ImportOptions struct {
+	// StorageLocationID puts every card in one location; when nil, sorting rules decide
+	StorageLocationID *uint
+	// Tuning overrides the import_batch_size and import_transaction_size settings where non-zero
+	Tuning ImportTuning
+	// Benchmark runs the whole import and rolls every transaction back, so batch sizes
+	// can be compared on real data without changing the collection
+	Benchmark bool
+}
+
+// errBenchmarkRollback rolls back a benchmark import's transaction after it has done its work
+var errBenchmarkRollback = errors.New("benchmark import rolled back")
+
+// Run resolves each row to a printing and creates inventory items, recording
+// progress and per-row errors in the job metadata. A failing row does not stop
+// the import; only database errors resolving printings fail the whole job, and
+// then only rows from committed transactions stay.
+func (s *ImportService) Run(ctx context.Context, jobID uint, format ImportFormat, rows []ImportRow, options ImportOptions) error {
+	ctx, release := s.jobService.Cancellable(ctx, jobID)
+	defer release()
+
+	if err := s.jobService.Start(ctx, jobID); err != nil {
+		return fmt.Errorf("starting import job: %w", err)
+	}
+
+	tuning := LoadImportTuning(ctx, settingsStore(s.db)).WithOverrides(options.Tuning)
+	metadata := ImportJobMetadata{
+		Format:          format,
+		TotalRows:       len(rows),
+		Errors:          []ImportRowError{},
+		BatchSize:       tuning.BatchSize,
+		TransactionSize: tuning.TransactionSize,
+		Benchmark:       options.Benchmark,
+	}
+	started := time.Now()
+
+	for start := 0; start < len(rows); start += tuning.TransactionSize {
+		chunk := rows[start:min(start+tuning.TransactionSize, len(rows))]
+
+		committed := metadata
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := s.importChunk(ctx, tx, chunk, tuning.statementSize(), options.StorageLocationID, &metadata); err != nil {
+				return err
+			}
+			if options.Benchmark {
+				return errBenchmarkRollback
+			}
+			return nil
+		})
+		if errors.Is(err, errBenchmarkRollback) {
+			err = nil
+		}
+		if err != nil {
+			// The chunk was rolled back; record how far the job got even if it was cancelled
+			metadata = committed
+			metadata.recordDuration(started)
+			cleanupCtx := context.WithoutCancel(ctx)
+			if failErr := s.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+				slog.ErrorContext(ctx, "failed to mark job as failed", "component", "import", "job_id", jobID, "error", failErr)
+			}
+			s.updateJobMetadata(cleanupCtx, jobID, metadata)
+			return err
+		}
+
+		s.updateJobMetadata(ctx, jobID, metadata)
+	}
+
+	metadata.recordDuration(started)
+	s.updateJobMetadata(ctx, jobID, metadata)
+	if err := s.jobService.Complete(ctx, jobID); err != nil {
+		return fmt.Errorf("completing import job: %w", err)
+	}
+
+	slog.InfoContext(ctx, "inventory import completed", "component", "import", "job_id", jobID, "format", format,
+		"imported", metadata.ImportedRows, "failed", metadata.FailedRows, "benchmark", options.Benchmark,
+		"duration_ms", metadata.DurationMS, "batch_size", tuning.BatchSize, "transaction_size", tuning.TransactionSize)
+	return nil
+}
+
+// importChunk imports the rows of one transaction, resolving batchSize rows per lookup.
+// Everything goes through tx: SQLite has a single connection, so using the service's
+// db here would wait on the transaction holding it.
+func (s *ImportService) importChunk(ctx context.Context, tx *gorm.DB, rows []ImportRow, batchSize int, storageLocationID *uint, metadata *ImportJobMetadata) error {
+	fail := func(row ImportRow, message string) {
+		metadata.FailedRows++
+		if len(metadata.Errors) < maxImportRowErrors {
+			metadata.Errors = append(metadata.Errors, ImportRowError{Row: row.Row, Name: row.Name, Error: message})
+		}
+	}
+	autoSortSvc := NewAutoSortService(tx)
+
+	for start := 0; start < len(rows); start += batchSize {
+		batch := rows[start:min(start+batchSize, len(rows))]
+
+		resolved, err := s.resolveRows(ctx, tx, batch)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, row := range batch {
+			metadata.ProcessedRows++
+			if row.Error != "" {
+				fail(row, row.Error)
+				continue
+			}
+
+			card, ok := resolved[i]
+			if !ok {
+				fail(row, "no matching card found")
+				continue
+			}
+
+			if err := createImportedInventory(ctx, tx, autoSortSvc, row, card, storageLocationID); err != nil {
+				slog.WarnContext(ctx, "failed to create inventory from CSV row", "component", "import", "row", row.Row, "error", err)
+				fail(row, "failed to create inventory item")
+				continue
+			}
+			metadata.ImportedRows++
+		}
+	}
+	return nil
+}
+
+// resolveRows matches a batch of rows to printings, keyed by index in the batch.
+// A Scryfall ID, or set code and collector number, identify a printing exactly; rows
+// without them, or whose printing is unknown, fall back to name and set.
+func (s *ImportService) resolveRows(ctx context.Context, db *gorm.DB, rows []ImportRow) (map[int]textImportCandidate, error) {
+	resolved := make(map[int]textImportCandidate)
+
+	setCodes := []string{}
+	seenSets := make(map[string]bool)
+	scryfallIDs := []string{}
+	lines := make([]TextImportLine, 0, len(rows))
+	for _, row := range rows {
+		if row.Error != "" {
+			continue
+		}
+		if row.ScryfallID != "" {
+			scryfallIDs = append(scryfallIDs, row.ScryfallID)
+		}
+		if row.SetCode != "" && row.CollectorNumber != "" && !seenSets[row.SetCode] {
+			seenSets[row.SetCode] = true
+			setCodes = append(setCodes, row.SetCode)
+		}
+		lines = append(lines, TextImportLine{Name: row.Name, SetCode: row.SetCode})
+	}
+
+	byID := make(map[string]textImportCandidate)
+	if len(scryfallIDs) > 0 {
+		cards, err := models.GetCardsByIDs(db.WithContext(ctx), scryfallIDs)
+		if err != nil {
+			return nil, fmt.Errorf("looking up printings by Scryfall ID: %w", err)
+		}
+		for id, card := range cards {
+			if candidate, ok := newTextImportCandidate(card); ok && candidate.OracleID != "" {
+				byID[id] = candidate
+			}
+		}
+	}
+
+	byNumber := make(map[string]textImportCandidate)
+	if len(setCodes) > 0 {
+		var cards []models.Card
+		if err := db.WithContext(ctx).
+			Where("set_code IN ?", setCodes).
+			Find(&cards).Error; err != nil {
+			return nil, fmt.Errorf("looking up printings by set: %w", err)
+		}
+		for _, card := range cards {
+			if candidate, ok := newTextImportCandidate(card); ok && candidate.OracleID != "" {
+				byNumber[candidate.SetCode+"|"+candidate.CollectorNumber] = candidate
+			}
+		}
+	}
+
+	byName, err := loadTextImportCandidates(ctx, db, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, row := range rows {
+		if row.Error != "" {
+			continue
+		}
+		if candidate, ok := byID[row.ScryfallID]; ok {
+			resolved[i] = candidate
+			continue
+		}
+		if candidate, ok := byNumber[row.SetCode+"|"+row.CollectorNumber]; ok && row.CollectorNumber != "" {
+			resolved[i] = candidate
+			continue
+		}
+		line := TextImportLine{Name: row.Name, SetCode: row.SetCode}
+		if candidate, ok := pickTextImportPrinting(byName[strings.ToLower(row.Name)], line); ok {
+			resolved[i] = candidate
+		}
+	}
+
+	return resolved, nil
+}
+
+// createImportedInventory stores one resolved row, auto-sorting it when no location was chosen
+func createImportedInventory(ctx context.Context, db *gorm.DB, autoSortSvc *AutoSortService, row ImportRow, card textImportCandidate, storageLocationID *uint) error {
+	locationID := storageLocationID
+	if locationID == nil {
+		assigned, err := autoSortSvc.DetermineStorageLocation(ctx, card.ScryfallID, row.Treatment, "", row.Quantity)
+		if err != nil {
+			slog.DebugContext(ctx, "auto-sort did not assign location", "component", "import", "scryfall_id", card.ScryfallID, "error", err)
+		} else {
+			locationID = assigned
+		}
+	}
+
+	item := models.Inventory{
+		ScryfallID:        card.ScryfallID,
+		OracleID:          card.OracleID,
+		Treatment:         row.Treatment,
+		Quantity:          row.Quantity,
+		StorageLocationID: locationID,
+	}
+	if err := db.WithContext(ctx).Create(&item).Error; err != nil {
+		return err
+	}
+	return models.RecordInventoryEvents(db.WithContext(ctx), []models.InventoryEvent{models.NewInventoryCreatedEvent(item)})
+}
+
+func (s *ImportService) updateJobMetadata(ctx context.Context, jobID uint, metadata ImportJobMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal job metadata", "component", "import", "error", err)
+		return
+	}
+
+	if err := s.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
+		slog.WarnContext(ctx, "failed to update job metadata", "component", "import", "error", err)
+	}
+}
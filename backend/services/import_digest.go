@@ -0,0 +1,286 @@
+package services
+
+import (
+	"backend/models"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultDigestPriceThresholdPercent is the price change, in percent, that makes an
+	// owned printing a price mover unless import_digest_price_threshold_percent says otherwise
+	DefaultDigestPriceThresholdPercent = 20
+	// digestEntryLimit caps each list in a digest; the counts still cover every entry
+	digestEntryLimit = 100
+)
+
+// ownedOraclePrintingsQuery selects every printing of a card that is owned in any printing
+const ownedOraclePrintingsQuery = `
+	SELECT scryfall_id, oracle_id,
+		COALESCE(name, '') AS name,
+		COALESCE(set_code, '') AS set_code
+	FROM cards
+	WHERE oracle_id IN (SELECT DISTINCT oracle_id FROM inventories WHERE deleted_at IS NULL)`
+
+// DigestNewPrinting is a printing that appeared for a card the collection already owns
+// tygo:export
+type DigestNewPrinting struct {
+	ScryfallID string `json:"scryfall_id"`
+	OracleID   string `json:"oracle_id"`
+	Name       string `json:"name"`
+	SetCode    string `json:"set_code"`
+}
+
+// DigestPriceMover is an owned printing whose price moved past the digest threshold
+// tygo:export
+type DigestPriceMover struct {
+	ScryfallID    string  `json:"scryfall_id"`
+	Name          string  `json:"name"`
+	Treatment     string  `json:"treatment"`
+	OldPrice      float64 `json:"old_price"`
+	NewPrice      float64 `json:"new_price"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// ImportDigestReport lists what a bulk import changed for the owned collection. Each
+// list holds at most 100 entries, biggest first; the counts cover them all.
+// tygo:export
+type ImportDigestReport struct {
+	JobID                 uint                    `json:"job_id"`
+	CreatedAt             time.Time               `json:"created_at"`
+	Currency              models.Currency         `json:"currency"`
+	PriceThresholdPercent int                     `json:"price_threshold_percent"`
+	NewPrintingsCount     int                     `json:"new_printings_count"`
+	PriceMoversCount      int                     `json:"price_movers_count"`
+	NewPrintings          []DigestNewPrinting     `json:"new_printings"`
+	PriceMovers           []DigestPriceMover      `json:"price_movers"`
+	LegalityChanges       []models.LegalityChange `json:"legality_changes"`
+}
+
+// ownedPrinting identifies an owned printing and treatment, which is what a price applies to
+type ownedPrinting struct {
+	ScryfallID string
+	Treatment  string
+}
+
+// ImportDigestSnapshot is the state of the owned collection's card data before an import
+type ImportDigestSnapshot struct {
+	currency  models.Currency
+	printings map[string]bool           // Printing IDs of owned cards
+	prices    map[ownedPrinting]float64 // Prices of owned printings in currency
+}
+
+// ImportDigestService builds and stores per-import digests of changes to owned cards
+type ImportDigestService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+}
+
+// NewImportDigestService creates a new import digest service
+func NewImportDigestService(db *gorm.DB, notifications *NotificationService) *ImportDigestService {
+	return &ImportDigestService{db: db, notifications: notifications}
+}
+
+// Snapshot captures owned cards' printings and prices so Build can compare against them
+func (s *ImportDigestService) Snapshot(ctx context.Context) (*ImportDigestSnapshot, error) {
+	snapshot := &ImportDigestSnapshot{
+		currency:  PreferredCurrency(ctx, s.db),
+		printings: make(map[string]bool),
+	}
+
+	printings, err := s.ownedOraclePrintings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, printing := range printings {
+		snapshot.printings[printing.ScryfallID] = true
+	}
+
+	if snapshot.prices, err = s.ownedPrices(ctx, snapshot.currency); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Build compares owned cards against an earlier snapshot, stores the digest for the
+// job (replacing any earlier one, e.g. from before a resume), and raises a notification
+// when import_digest_notifications is on and something changed
+func (s *ImportDigestService) Build(ctx context.Context, jobID uint, before *ImportDigestSnapshot, legalityChanges []models.LegalityChange) (*ImportDigestReport, error) {
+	settings := settingsStore(s.db)
+	threshold := settings.GetInt(ctx, "import_digest_price_threshold_percent", DefaultDigestPriceThresholdPercent)
+	if threshold < 1 {
+		threshold = DefaultDigestPriceThresholdPercent
+	}
+
+	report := &ImportDigestReport{
+		JobID:                 jobID,
+		Currency:              before.currency,
+		PriceThresholdPercent: threshold,
+		NewPrintings:          []DigestNewPrinting{},
+		PriceMovers:           []DigestPriceMover{},
+		LegalityChanges:       legalityChanges,
+	}
+	if report.LegalityChanges == nil {
+		report.LegalityChanges = []models.LegalityChange{}
+	}
+
+	printings, err := s.ownedOraclePrintings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string, len(printings))
+	for _, printing := range printings {
+		names[printing.ScryfallID] = printing.Name
+		if !before.printings[printing.ScryfallID] {
+			report.NewPrintings = append(report.NewPrintings, printing)
+		}
+	}
+	slices.SortFunc(report.NewPrintings, func(a, b DigestNewPrinting) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.SetCode, b.SetCode)
+	})
+
+	prices, err := s.ownedPrices(ctx, before.currency)
+	if err != nil {
+		return nil, err
+	}
+	for printing, newPrice := range prices {
+		oldPrice, ok := before.prices[printing]
+		if !ok || oldPrice <= 0 || newPrice <= 0 {
+			continue
+		}
+		change := (newPrice - oldPrice) / oldPrice * 100
+		if math.Abs(change) < float64(threshold) {
+			continue
+		}
+		report.PriceMovers = append(report.PriceMovers, DigestPriceMover{
+			ScryfallID:    printing.ScryfallID,
+			Name:          names[printing.ScryfallID],
+			Treatment:     printing.Treatment,
+			OldPrice:      oldPrice,
+			NewPrice:      newPrice,
+			ChangePercent: math.Round(change*10) / 10,
+		})
+	}
+	slices.SortFunc(report.PriceMovers, func(a, b DigestPriceMover) int {
+		if c := cmp.Compare(math.Abs(b.ChangePercent), math.Abs(a.ChangePercent)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ScryfallID+a.Treatment, b.ScryfallID+b.Treatment)
+	})
+
+	report.NewPrintingsCount = len(report.NewPrintings)
+	report.PriceMoversCount = len(report.PriceMovers)
+	report.NewPrintings = report.NewPrintings[:min(len(report.NewPrintings), digestEntryLimit)]
+	report.PriceMovers = report.PriceMovers[:min(len(report.PriceMovers), digestEntryLimit)]
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("encoding digest: %w", err)
+	}
+	digest := models.ImportDigest{
+		JobID:           jobID,
+		NewPrintings:    report.NewPrintingsCount,
+		PriceMovers:     report.PriceMoversCount,
+		LegalityChanges: len(report.LegalityChanges),
+		Content:         string(content),
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "new_printings", "price_movers", "legality_changes", "content"}),
+	}).Create(&digest).Error; err != nil {
+		return nil, fmt.Errorf("storing digest: %w", err)
+	}
+	report.CreatedAt = digest.CreatedAt
+
+	if settings.GetBool(ctx, "import_digest_notifications", false) {
+		s.notify(ctx, report)
+	}
+	return report, nil
+}
+
+// Get returns the digest stored for a job, or gorm.ErrRecordNotFound
+func (s *ImportDigestService) Get(ctx context.Context, jobID uint) (*ImportDigestReport, error) {
+	var digest models.ImportDigest
+	if err := s.db.WithContext(ctx).Where("job_id = ?", jobID).Take(&digest).Error; err != nil {
+		return nil, err
+	}
+
+	var report ImportDigestReport
+	if err := json.Unmarshal([]byte(digest.Content), &report); err != nil {
+		return nil, fmt.Errorf("decoding digest for job %d: %w", jobID, err)
+	}
+	report.CreatedAt = digest.CreatedAt
+	return &report, nil
+}
+
+// notify raises one notification summarising a digest, unless nothing changed
+func (s *ImportDigestService) notify(ctx context.Context, report *ImportDigestReport) {
+	var parts []string
+	if report.NewPrintingsCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d new printings of owned cards", report.NewPrintingsCount))
+	}
+	if report.PriceMoversCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d owned printings moved %d%% or more in price", report.PriceMoversCount, report.PriceThresholdPercent))
+	}
+	if len(report.LegalityChanges) > 0 {
+		parts = append(parts, fmt.Sprintf("%d legality changes", len(report.LegalityChanges)))
+	}
+	if len(parts) == 0 {
+		return
+	}
+
+	title := "Card data update digest"
+	message := strings.Join(parts, ", ") + fmt.Sprintf(". See job %d for details.", report.JobID)
+	if _, err := s.notifications.Create(ctx, models.NotificationTypeImportDigest, title, message); err != nil {
+		slog.WarnContext(ctx, "failed to create import digest notification", "component", "import_digest", "job_id", report.JobID, "error", err)
+	}
+}
+
+// ownedOraclePrintings returns every printing of the cards owned in any printing
+func (s *ImportDigestService) ownedOraclePrintings(ctx context.Context) ([]DigestNewPrinting, error) {
+	var printings []DigestNewPrinting
+	if err := s.db.WithContext(ctx).Raw(ownedOraclePrintingsQuery).Scan(&printings).Error; err != nil {
+		return nil, fmt.Errorf("loading printings of owned cards: %w", err)
+	}
+	return printings, nil
+}
+
+// ownedPrices returns the current price of each owned printing and treatment in currency
+func (s *ImportDigestService) ownedPrices(ctx context.Context, currency models.Currency) (map[ownedPrinting]float64, error) {
+	var owned []ownedPrinting
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Distinct("scryfall_id", "treatment").
+		Scan(&owned).Error; err != nil {
+		return nil, fmt.Errorf("loading owned printings: %w", err)
+	}
+
+	ids := make([]string, 0, len(owned))
+	for _, printing := range owned {
+		ids = append(ids, printing.ScryfallID)
+	}
+	cardPrices, err := models.GetCardPricesByIDs(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[ownedPrinting]float64, len(owned))
+	for _, printing := range owned {
+		if p, ok := cardPrices[printing.ScryfallID]; ok {
+			prices[printing] = p.InCurrency(printing.Treatment, currency)
+		}
+	}
+	return prices, nil
+}
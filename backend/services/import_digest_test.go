@@ -0,0 +1,149 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupImportDigestTest(t *testing.T) (*gorm.DB, *ImportDigestService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+	return db, NewImportDigestService(db, NewNotificationService(db))
+}
+
+// setDigestTestPrice changes a card's extracted price, as a bulk import would
+func setDigestTestPrice(t *testing.T, db *gorm.DB, scryfallID string, price float64) {
+	t.Helper()
+
+	if err := db.Model(&models.Card{}).Where("scryfall_id = ?", scryfallID).UpdateColumn("price_usd", price).Error; err != nil {
+		t.Fatalf("failed to update price: %v", err)
+	}
+}
+
+func createDigestTestCard(t *testing.T, db *gorm.DB, scryfallID, oracleID, name, set string, price float64) {
+	t.Helper()
+
+	card := models.Card{
+		ScryfallID: scryfallID,
+		OracleID:   oracleID,
+		RawJSON:    fmt.Sprintf(`{"id":%q,"name":%q,"set":%q,"prices":{"usd":"%.2f"}}`, scryfallID, name, set, price),
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func TestImportDigestService_Build(t *testing.T) {
+	db, service := setupImportDigestTest(t)
+	ctx := context.Background()
+
+	createDigestTestCard(t, db, "bolt-m10", "oracle-bolt", "Lightning Bolt", "m10", 1.00)
+	createDigestTestCard(t, db, "ring-c21", "oracle-ring", "Sol Ring", "c21", 2.00)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 4})
+	db.Create(&models.Inventory{ScryfallID: "ring-c21", OracleID: "oracle-ring", Treatment: "nonfoil", Quantity: 1})
+
+	before, err := service.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	// The import reprints Bolt, adds an unowned card, and moves prices
+	createDigestTestCard(t, db, "bolt-2x2", "oracle-bolt", "Lightning Bolt", "2x2", 0.50)
+	createDigestTestCard(t, db, "counterspell", "oracle-counter", "Counterspell", "mh2", 1.00)
+	setDigestTestPrice(t, db, "bolt-m10", 1.50)
+	setDigestTestPrice(t, db, "ring-c21", 2.10)
+
+	changes := []models.LegalityChange{{OracleID: "oracle-ring", CardName: "Sol Ring", Format: "commander", PreviousStatus: "legal", Status: "banned"}}
+	report, err := service.Build(ctx, 7, before, changes)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if report.NewPrintingsCount != 1 || report.NewPrintings[0].ScryfallID != "bolt-2x2" || report.NewPrintings[0].SetCode != "2x2" {
+		t.Errorf("expected the 2x2 Bolt as the only new printing, got %+v", report.NewPrintings)
+	}
+	if report.PriceMoversCount != 1 {
+		t.Fatalf("expected 1 price mover at the default threshold, got %+v", report.PriceMovers)
+	}
+	if mover := report.PriceMovers[0]; mover.ScryfallID != "bolt-m10" || mover.ChangePercent != 50 || mover.Name != "Lightning Bolt" {
+		t.Errorf("unexpected price mover: %+v", mover)
+	}
+	if len(report.LegalityChanges) != 1 {
+		t.Errorf("expected the legality change carried into the digest, got %d", len(report.LegalityChanges))
+	}
+
+	stored, err := service.Get(ctx, 7)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if stored.NewPrintingsCount != 1 || stored.PriceMoversCount != 1 || stored.PriceThresholdPercent != DefaultDigestPriceThresholdPercent {
+		t.Errorf("unexpected stored digest: %+v", stored)
+	}
+
+	// Notifications are off by default
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no notifications, got %d", count)
+	}
+}
+
+func TestImportDigestService_BuildReplacesAndNotifies(t *testing.T) {
+	db, service := setupImportDigestTest(t)
+	ctx := context.Background()
+
+	db.Create(&models.Setting{Key: "import_digest_notifications", Value: "true"})
+	db.Create(&models.Setting{Key: "import_digest_price_threshold_percent", Value: "5"})
+	createDigestTestCard(t, db, "ring-c21", "oracle-ring", "Sol Ring", "c21", 2.00)
+	db.Create(&models.Inventory{ScryfallID: "ring-c21", OracleID: "oracle-ring", Treatment: "nonfoil", Quantity: 1})
+
+	before, err := service.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if _, err := service.Build(ctx, 3, before, nil); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no notification for an empty digest, got %d", count)
+	}
+
+	// A resumed job builds again and replaces its digest
+	setDigestTestPrice(t, db, "ring-c21", 2.10)
+	report, err := service.Build(ctx, 3, before, nil)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if report.PriceMoversCount != 1 {
+		t.Errorf("expected a 5%% move to count at a 5%% threshold, got %d", report.PriceMoversCount)
+	}
+
+	db.Model(&models.ImportDigest{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected one digest per job, got %d", count)
+	}
+
+	var notifications []models.Notification
+	db.Find(&notifications)
+	if len(notifications) != 1 || notifications[0].Type != models.NotificationTypeImportDigest {
+		t.Errorf("expected one import digest notification, got %+v", notifications)
+	}
+}
+
+func TestImportDigestService_GetMissing(t *testing.T) {
+	_, service := setupImportDigestTest(t)
+
+	if _, err := service.Get(context.Background(), 99); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupImportTest(t *testing.T) (*ImportService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","collector_number":"146","released_at":"2009-07-17"}`},
+		{ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"2x2","collector_number":"117","released_at":"2022-07-08"}`},
+		{ScryfallID: "sol-ring-c21", OracleID: "oracle-sol-ring", RawJSON: `{"name":"Sol Ring","set":"c21","collector_number":"263","released_at":"2021-04-23"}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	return NewImportService(db, NewJobService(db)), db
+}
+
+func TestParseImportCSV_Formats(t *testing.T) {
+	tests := []struct {
+		name     string
+		csv      string
+		format   ImportFormat
+		expected ImportRow
+	}{
+		{
+			name:     "Moxfield",
+			csv:      "\"Count\",\"Tradelist Count\",\"Name\",\"Edition\",\"Condition\",\"Language\",\"Foil\",\"Tags\",\"Last Modified\",\"Collector Number\"\n\"2\",\"0\",\"Sol Ring\",\"c21\",\"Near Mint\",\"English\",\"foil\",\"\",\"2024-01-01\",\"263\"\n",
+			format:   ImportFormatMoxfield,
+			expected: ImportRow{Row: 1, Quantity: 2, Name: "Sol Ring", SetCode: "c21", CollectorNumber: "263", Treatment: "foil"},
+		},
+		{
+			name:     "Deckbox",
+			csv:      "Count,Tradelist Count,Name,Edition,Edition Code,Card Number,Condition,Language,Foil\n3,0,Lightning Bolt,Magic 2010,M10,146,Near Mint,English,\n",
+			format:   ImportFormatDeckbox,
+			expected: ImportRow{Row: 1, Quantity: 3, Name: "Lightning Bolt", SetCode: "m10", CollectorNumber: "146", Treatment: "nonfoil"},
+		},
+		{
+			name:     "TCGPlayer",
+			csv:      "Quantity,Name,Simple Name,Set,Card Number,Set Code,Printing,Condition,Language,Rarity,Product ID,SKU\n1,Lightning Bolt,Lightning Bolt,Magic 2010,146/249,M10,Foil,Near Mint,English,C,1,2\n",
+			format:   ImportFormatTCGPlayer,
+			expected: ImportRow{Row: 1, Quantity: 1, Name: "Lightning Bolt", SetCode: "m10", CollectorNumber: "146", Treatment: "foil"},
+		},
+		{
+			name:     "Scryfall",
+			csv:      "section,count,name,mana_cost,type,set,set_code,collector_number,lang,rarity,artist,finish,usd,eur,tix,scryfall_uri,scryfall_id\nmainboard,4,Lightning Bolt,{R},Instant,Magic 2010,m10,146,en,common,Christopher Moeller,etched,1.00,,,https://scryfall.com/card/m10/146,BOLT-M10\n",
+			format:   ImportFormatScryfall,
+			expected: ImportRow{Row: 1, Quantity: 4, Name: "Lightning Bolt", SetCode: "m10", CollectorNumber: "146", Treatment: "etched", ScryfallID: "bolt-m10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, format, err := ParseImportCSV(strings.NewReader(tt.csv), "")
+			if err != nil {
+				t.Fatalf("ParseImportCSV failed: %v", err)
+			}
+			if format != tt.format {
+				t.Errorf("expected format %s, got %s", tt.format, format)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("expected 1 row, got %d", len(rows))
+			}
+			if rows[0] != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, rows[0])
+			}
+		})
+	}
+}
+
+func TestParseImportCSV_Errors(t *testing.T) {
+	if _, _, err := ParseImportCSV(strings.NewReader("foo,bar\n1,2\n"), ""); !errors.Is(err, ErrUnknownImportFormat) {
+		t.Errorf("expected ErrUnknownImportFormat, got %v", err)
+	}
+
+	if _, _, err := ParseImportCSV(strings.NewReader("Name,Edition\nSol Ring,c21\n"), ImportFormatMoxfield); err == nil {
+		t.Error("expected error for missing count column")
+	}
+
+	rows, _, err := ParseImportCSV(strings.NewReader("Count,Name,Edition\nabc,Sol Ring,c21\n,,\n"), "")
+	if err != nil {
+		t.Fatalf("ParseImportCSV failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Error == "" {
+		t.Errorf("expected one row with a quantity error, got %+v", rows)
+	}
+}
+
+func TestImportService_Run(t *testing.T) {
+	service, db := setupImportTest(t)
+	ctx := context.Background()
+
+	csv := "Count,Name,Edition,Collector Number,Foil\n" +
+		"2,Sol Ring,c21,263,\n" + // exact set and collector number
+		"1,Lightning Bolt,m10,999,foil\n" + // unknown collector number falls back to name and set
+		"4,Lightning Bolt,,,\n" + // name only picks the newest printing
+		"1,Not A Card,,,\n" +
+		"0,Sol Ring,c21,263,\n"
+	rows, format, err := ParseImportCSV(strings.NewReader(csv), "")
+	if err != nil {
+		t.Fatalf("ParseImportCSV failed: %v", err)
+	}
+
+	job, err := service.CreateImportJob(ctx, format, len(rows))
+	if err != nil {
+		t.Fatalf("CreateImportJob failed: %v", err)
+	}
+	if err := service.Run(ctx, job.ID, format, rows, ImportOptions{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var items []models.Inventory
+	db.Order("id").Find(&items)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 inventory items, got %d", len(items))
+	}
+	expected := []struct {
+		scryfallID string
+		quantity   int
+		treatment  string
+	}{
+		{"sol-ring-c21", 2, "nonfoil"},
+		{"bolt-m10", 1, "foil"},
+		{"bolt-2x2", 4, "nonfoil"},
+	}
+	for i, e := range expected {
+		if items[i].ScryfallID != e.scryfallID || items[i].Quantity != e.quantity || items[i].Treatment != e.treatment {
+			t.Errorf("item %d: expected %+v, got %s x%d %s", i, e, items[i].ScryfallID, items[i].Quantity, items[i].Treatment)
+		}
+	}
+
+	var stored models.Job
+	db.First(&stored, job.ID)
+	if stored.Status != models.JobStatusCompleted {
+		t.Errorf("expected job to be completed, got %s", stored.Status)
+	}
+
+	var metadata ImportJobMetadata
+	if err := json.Unmarshal([]byte(stored.Metadata), &metadata); err != nil {
+		t.Fatalf("failed to decode job metadata: %v", err)
+	}
+	if metadata.ProcessedRows != 5 || metadata.ImportedRows != 3 || metadata.FailedRows != 2 {
+		t.Errorf("unexpected metadata counts: %+v", metadata)
+	}
+	if len(metadata.Errors) != 2 || metadata.Errors[0].Row != 4 || metadata.Errors[1].Row != 5 {
+		t.Errorf("expected errors for rows 4 and 5, got %+v", metadata.Errors)
+	}
+}
+
+func TestParseImportCSV_ScryfallSetColumn(t *testing.T) {
+	rows, _, err := ParseImportCSV(strings.NewReader("count,name,set,collector_number,finish,scryfall_id\n1,,c21,263,foil,sol-ring-c21\n"), "")
+	if err != nil {
+		t.Fatalf("ParseImportCSV failed: %v", err)
+	}
+	expected := ImportRow{Row: 1, Quantity: 1, SetCode: "c21", CollectorNumber: "263", Treatment: "foil", ScryfallID: "sol-ring-c21"}
+	if len(rows) != 1 || rows[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, rows)
+	}
+}
+
+func TestImportService_Run_ScryfallIDs(t *testing.T) {
+	service, db := setupImportTest(t)
+	ctx := context.Background()
+
+	csv := "count,name,set_code,collector_number,finish,scryfall_id\n" +
+		"1,Lightning Bolt,m10,146,nonfoil,bolt-2x2\n" + // the ID wins over set and collector number
+		"2,Lightning Bolt,m10,146,foil,unknown-id\n" + // unknown IDs fall back to set and collector number
+		"1,,,,nonfoil,sol-ring-c21\n" // the ID alone is enough
+	rows, format, err := ParseImportCSV(strings.NewReader(csv), "")
+	if err != nil {
+		t.Fatalf("ParseImportCSV failed: %v", err)
+	}
+	if format != ImportFormatScryfall {
+		t.Fatalf("expected scryfall format, got %s", format)
+	}
+
+	job, err := service.CreateImportJob(ctx, format, len(rows))
+	if err != nil {
+		t.Fatalf("CreateImportJob failed: %v", err)
+	}
+	if err := service.Run(ctx, job.ID, format, rows, ImportOptions{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var items []models.Inventory
+	db.Order("id").Find(&items)
+	expected := []string{"bolt-2x2", "bolt-m10", "sol-ring-c21"}
+	if len(items) != len(expected) {
+		t.Fatalf("expected %d inventory items, got %d", len(expected), len(items))
+	}
+	for i, scryfallID := range expected {
+		if items[i].ScryfallID != scryfallID {
+			t.Errorf("item %d: expected %s, got %s", i, scryfallID, items[i].ScryfallID)
+		}
+	}
+}
+
+func TestImportService_Run_Tuning(t *testing.T) {
+	service, db := setupImportTest(t)
+	ctx := context.Background()
+
+	csv := "Count,Name,Edition,Collector Number,Foil\n" +
+		"2,Sol Ring,c21,263,\n" +
+		"1,Lightning Bolt,m10,146,foil\n" +
+		"1,Not A Card,,,\n" +
+		"4,Lightning Bolt,2x2,117,\n"
+	rows, format, _ := ParseImportCSV(strings.NewReader(csv), "")
+
+	// One-row lookups in two-row transactions import the same as the defaults
+	job, _ := service.CreateImportJob(ctx, format, len(rows))
+	options := ImportOptions{Tuning: ImportTuning{BatchSize: 1, TransactionSize: 2}}
+	if err := service.Run(ctx, job.ID, format, rows, options); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 3 {
+		t.Errorf("expected 3 inventory items, got %d", count)
+	}
+
+	var stored models.Job
+	db.First(&stored, job.ID)
+	var metadata ImportJobMetadata
+	if err := json.Unmarshal([]byte(stored.Metadata), &metadata); err != nil {
+		t.Fatalf("failed to decode job metadata: %v", err)
+	}
+	if metadata.BatchSize != 1 || metadata.TransactionSize != 2 {
+		t.Errorf("expected tuning 1/2 in metadata, got %d/%d", metadata.BatchSize, metadata.TransactionSize)
+	}
+	if metadata.ProcessedRows != 4 || metadata.ImportedRows != 3 || metadata.FailedRows != 1 {
+		t.Errorf("unexpected metadata counts: %+v", metadata)
+	}
+}
+
+func TestImportService_Run_Benchmark(t *testing.T) {
+	service, db := setupImportTest(t)
+	ctx := context.Background()
+
+	rows, format, _ := ParseImportCSV(strings.NewReader("Count,Name,Edition\n2,Sol Ring,c21\n1,Lightning Bolt,m10\n"), "")
+	job, _ := service.CreateImportJob(ctx, format, len(rows))
+	if err := service.Run(ctx, job.ID, format, rows, ImportOptions{Benchmark: true}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected benchmark to roll back every item, found %d", count)
+	}
+
+	var stored models.Job
+	db.First(&stored, job.ID)
+	if stored.Status != models.JobStatusCompleted {
+		t.Errorf("expected job to be completed, got %s", stored.Status)
+	}
+	var metadata ImportJobMetadata
+	if err := json.Unmarshal([]byte(stored.Metadata), &metadata); err != nil {
+		t.Fatalf("failed to decode job metadata: %v", err)
+	}
+	if !metadata.Benchmark || metadata.ImportedRows != 2 {
+		t.Errorf("expected benchmark metadata with 2 would-be imports, got %+v", metadata)
+	}
+	if metadata.BatchSize != DefaultImportBatchSize || metadata.TransactionSize != DefaultImportTransactionSize {
+		t.Errorf("expected default tuning, got %d/%d", metadata.BatchSize, metadata.TransactionSize)
+	}
+}
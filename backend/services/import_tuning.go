@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Bounds for the import_batch_size and import_transaction_size settings and their
+// per-job overrides. Batches are capped so a card upsert stays under SQLite's
+// bound parameter limit.
+const (
+	DefaultImportBatchSize       = 1000
+	MinImportBatchSize           = 1
+	MaxImportBatchSize           = 2000
+	DefaultImportTransactionSize = 1000
+	MinImportTransactionSize     = 1
+	MaxImportTransactionSize     = 50000
+)
+
+// ImportTuning controls how bulk Scryfall imports and CSV imports group their writes.
+// BatchSize is the rows per database round trip: an INSERT statement for bulk imports,
+// a printing lookup for CSV imports. TransactionSize is the rows committed together;
+// bulk imports also checkpoint after each transaction.
+type ImportTuning struct {
+	BatchSize       int `json:"batch_size"`
+	TransactionSize int `json:"transaction_size"`
+}
+
+// LoadImportTuning reads the import tuning settings, using the defaults for values
+// that are missing or out of bounds
+func LoadImportTuning(ctx context.Context, settings *SettingsService) ImportTuning {
+	tuning := ImportTuning{
+		BatchSize:       settings.GetInt(ctx, "import_batch_size", DefaultImportBatchSize),
+		TransactionSize: settings.GetInt(ctx, "import_transaction_size", DefaultImportTransactionSize),
+	}
+	if validateImportBatchSize(tuning.BatchSize) != nil {
+		tuning.BatchSize = DefaultImportBatchSize
+	}
+	if validateImportTransactionSize(tuning.TransactionSize) != nil {
+		tuning.TransactionSize = DefaultImportTransactionSize
+	}
+	return tuning
+}
+
+// WithOverrides replaces the sizes that are set (non-zero) in overrides
+func (t ImportTuning) WithOverrides(overrides ImportTuning) ImportTuning {
+	if overrides.BatchSize != 0 {
+		t.BatchSize = overrides.BatchSize
+	}
+	if overrides.TransactionSize != 0 {
+		t.TransactionSize = overrides.TransactionSize
+	}
+	return t
+}
+
+// ParseImportTuningOverrides parses per-job batch and transaction sizes; empty values
+// are left zero so the settings apply
+func ParseImportTuningOverrides(batchSize, transactionSize string) (ImportTuning, error) {
+	var overrides ImportTuning
+	if batchSize != "" {
+		if err := validateImportSizeSetting(batchSize, validateImportBatchSize); err != nil {
+			return ImportTuning{}, fmt.Errorf("batch_size: %w", err)
+		}
+		overrides.BatchSize, _ = strconv.Atoi(batchSize)
+	}
+	if transactionSize != "" {
+		if err := validateImportSizeSetting(transactionSize, validateImportTransactionSize); err != nil {
+			return ImportTuning{}, fmt.Errorf("transaction_size: %w", err)
+		}
+		overrides.TransactionSize, _ = strconv.Atoi(transactionSize)
+	}
+	return overrides, nil
+}
+
+// statementSize is the rows per statement, which never exceeds a transaction
+func (t ImportTuning) statementSize() int {
+	return min(t.BatchSize, t.TransactionSize)
+}
+
+func validateImportBatchSize(size int) error {
+	if size < MinImportBatchSize || size > MaxImportBatchSize {
+		return fmt.Errorf("batch size must be between %d and %d", MinImportBatchSize, MaxImportBatchSize)
+	}
+	return nil
+}
+
+func validateImportTransactionSize(size int) error {
+	if size < MinImportTransactionSize || size > MaxImportTransactionSize {
+		return fmt.Errorf("transaction size must be between %d and %d", MinImportTransactionSize, MaxImportTransactionSize)
+	}
+	return nil
+}
+
+// validateImportSizeSetting checks a size setting value is a whole number within bounds
+func validateImportSizeSetting(value string, validate func(int) error) error {
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be a whole number")
+	}
+	return validate(size)
+}
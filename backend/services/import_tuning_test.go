@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLoadImportTuning(t *testing.T) {
+	service, _ := setupSettingsServiceTest(t)
+	ctx := context.Background()
+
+	expected := ImportTuning{BatchSize: DefaultImportBatchSize, TransactionSize: DefaultImportTransactionSize}
+	if got := LoadImportTuning(ctx, service); got != expected {
+		t.Errorf("expected defaults %+v, got %+v", expected, got)
+	}
+
+	service.Set(ctx, "import_batch_size", "250")
+	service.Set(ctx, "import_transaction_size", "10000")
+	expected = ImportTuning{BatchSize: 250, TransactionSize: 10000}
+	if got := LoadImportTuning(ctx, service); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	// Values written around validation fall back to the defaults
+	service.Set(ctx, "import_batch_size", "999999")
+	if got := LoadImportTuning(ctx, service); got.BatchSize != DefaultImportBatchSize {
+		t.Errorf("expected out of bounds batch size to use the default, got %d", got.BatchSize)
+	}
+}
+
+func TestImportTuning_WithOverrides(t *testing.T) {
+	base := ImportTuning{BatchSize: 1000, TransactionSize: 1000}
+
+	if got := base.WithOverrides(ImportTuning{}); got != base {
+		t.Errorf("expected no overrides to keep %+v, got %+v", base, got)
+	}
+	expected := ImportTuning{BatchSize: 1000, TransactionSize: 20000}
+	if got := base.WithOverrides(ImportTuning{TransactionSize: 20000}); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestImportTuning_StatementSize(t *testing.T) {
+	if got := (ImportTuning{BatchSize: 500, TransactionSize: 2000}).statementSize(); got != 500 {
+		t.Errorf("expected batch size 500, got %d", got)
+	}
+	if got := (ImportTuning{BatchSize: 500, TransactionSize: 100}).statementSize(); got != 100 {
+		t.Errorf("expected statements capped at the transaction size, got %d", got)
+	}
+}
+
+func TestParseImportTuningOverrides(t *testing.T) {
+	tests := []struct {
+		name            string
+		batchSize       string
+		transactionSize string
+		expected        ImportTuning
+		valid           bool
+	}{
+		{"none", "", "", ImportTuning{}, true},
+		{"both", "200", "5000", ImportTuning{BatchSize: 200, TransactionSize: 5000}, true},
+		{"batch only", "200", "", ImportTuning{BatchSize: 200}, true},
+		{"batch too large", "5000", "", ImportTuning{}, false},
+		{"transaction zero", "", "0", ImportTuning{}, false},
+		{"not a number", "abc", "", ImportTuning{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImportTuningOverrides(tt.batchSize, tt.transactionSize)
+			if (err == nil) != tt.valid {
+				t.Fatalf("expected valid=%v, got %v", tt.valid, err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
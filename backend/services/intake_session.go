@@ -0,0 +1,301 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrIntakeSessionClosed is returned when changing a session that was already committed or aborted
+var ErrIntakeSessionClosed = errors.New("intake session is already closed")
+
+// IntakeSessionLocation is how many committed cards a session sent to one storage location
+// tygo:export
+type IntakeSessionLocation struct {
+	StorageLocationID *uint  `json:"storage_location_id,omitempty"` // nil for cards left unassigned
+	Name              string `json:"name"`
+	Cards             int    `json:"cards"`
+}
+
+// IntakeSessionSummary totals an intake session's items
+// tygo:export
+type IntakeSessionSummary struct {
+	Items      int                     `json:"items"`
+	Cards      int                     `json:"cards"`
+	TotalValue float64                 `json:"total_value"`
+	Currency   models.Currency         `json:"currency"`
+	Locations  []IntakeSessionLocation `json:"locations"` // Where committed cards went; empty until the session is committed
+}
+
+// IntakeSessionService stages cards in intake sessions and writes them to inventory on commit
+type IntakeSessionService struct {
+	db *gorm.DB
+}
+
+// NewIntakeSessionService creates a new intake session service
+func NewIntakeSessionService(db *gorm.DB) *IntakeSessionService {
+	return &IntakeSessionService{db: db}
+}
+
+// Create opens a new intake session
+func (s *IntakeSessionService) Create(ctx context.Context, session *models.IntakeSession) error {
+	session.Status = models.IntakeSessionOpen
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("creating intake session: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves an intake session by ID with its items
+func (s *IntakeSessionService) Get(ctx context.Context, id uint) (*models.IntakeSession, error) {
+	var session models.IntakeSession
+	if err := s.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&session, id).Error; err != nil {
+		return nil, fmt.Errorf("getting intake session %d: %w", id, err)
+	}
+	return &session, nil
+}
+
+// List retrieves intake sessions, newest first, with pagination and an optional status filter
+func (s *IntakeSessionService) List(ctx context.Context, page, pageSize int, status *models.IntakeSessionStatus) ([]models.IntakeSession, int64, error) {
+	var sessions []models.IntakeSession
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.IntakeSession{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting intake sessions: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Limit(pageSize).Offset(offset).Find(&sessions).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing intake sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// AddItems resolves entries like a batch add and stages the matches in an open session.
+// Results report each entry in order; added entries carry their staged item's ID.
+func (s *IntakeSessionService) AddItems(ctx context.Context, id uint, entries []BatchAddEntry) ([]BatchAddResult, error) {
+	session, err := s.openSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	results, matches, err := resolveBatchAddEntries(ctx, s.db, entries)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return results, nil
+	}
+
+	items := make([]models.IntakeSessionItem, len(matches))
+	for i, match := range matches {
+		items[i] = models.IntakeSessionItem{
+			IntakeSessionID: session.ID,
+			ScryfallID:      match.card.ScryfallID,
+			OracleID:        match.card.OracleID,
+			Name:            match.card.Name,
+			SetCode:         match.card.SetCode,
+			Treatment:       match.entry.Treatment,
+			Quantity:        match.entry.Quantity,
+		}
+	}
+	if err := s.db.WithContext(ctx).Create(&items).Error; err != nil {
+		return nil, fmt.Errorf("staging intake items: %w", err)
+	}
+
+	for i, match := range matches {
+		results[match.index].Status = BatchAddStatusAdded
+		results[match.index].IntakeItemID = items[i].ID
+	}
+	return results, nil
+}
+
+// RemoveItem unstages an item from an open session
+func (s *IntakeSessionService) RemoveItem(ctx context.Context, id, itemID uint) error {
+	if _, err := s.openSession(ctx, id); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Where("intake_session_id = ?", id).Delete(&models.IntakeSessionItem{}, itemID)
+	if result.Error != nil {
+		return fmt.Errorf("removing intake item %d: %w", itemID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("removing intake item %d: %w", itemID, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// Commit writes every staged item to inventory and closes the session, all in one
+// transaction. Items go to the session's storage location, or where sorting rules place
+// them when it has none (or it was deleted meanwhile).
+func (s *IntakeSessionService) Commit(ctx context.Context, id uint) (*models.IntakeSession, error) {
+	session, err := s.openSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		locationID := session.StorageLocationID
+		if locationID != nil {
+			if err := tx.First(&models.StorageLocation{}, *locationID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				slog.WarnContext(ctx, "intake session location no longer exists, applying sorting rules", "component", "intake", "intake_session_id", id, "storage_location_id", *locationID)
+				locationID = nil
+			} else if err != nil {
+				return fmt.Errorf("loading storage location: %w", err)
+			}
+		}
+
+		items := make([]models.Inventory, len(session.Items))
+		for i, staged := range session.Items {
+			items[i] = models.Inventory{
+				ScryfallID:        staged.ScryfallID,
+				OracleID:          staged.OracleID,
+				Treatment:         staged.Treatment,
+				Quantity:          staged.Quantity,
+				StorageLocationID: locationID,
+			}
+		}
+		if err := createSortedInventory(ctx, tx, items); err != nil {
+			return fmt.Errorf("creating inventory: %w", err)
+		}
+
+		for i := range session.Items {
+			staged := &session.Items[i]
+			staged.InventoryID = &items[i].ID
+			staged.StorageLocationID = items[i].StorageLocationID
+			if err := tx.Model(staged).UpdateColumns(map[string]any{
+				"inventory_id":        staged.InventoryID,
+				"storage_location_id": staged.StorageLocationID,
+			}).Error; err != nil {
+				return fmt.Errorf("recording intake item %d: %w", staged.ID, err)
+			}
+		}
+
+		return s.close(tx, session, models.IntakeSessionCommitted)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "committed intake session", "component", "intake", "intake_session_id", id, "items", len(session.Items))
+	return session, nil
+}
+
+// Abort closes an open session without writing its items to inventory
+func (s *IntakeSessionService) Abort(ctx context.Context, id uint) (*models.IntakeSession, error) {
+	session, err := s.openSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.close(s.db.WithContext(ctx), session, models.IntakeSessionAborted); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "aborted intake session", "component", "intake", "intake_session_id", id, "items", len(session.Items))
+	return session, nil
+}
+
+// Summarize totals a session's items, valued in the preferred currency at the
+// treatment's price, with the locations committed cards went to
+func (s *IntakeSessionService) Summarize(ctx context.Context, session *models.IntakeSession) (IntakeSessionSummary, error) {
+	summary := IntakeSessionSummary{
+		Items:     len(session.Items),
+		Currency:  PreferredCurrency(ctx, s.db),
+		Locations: []IntakeSessionLocation{},
+	}
+
+	ids := make([]string, len(session.Items))
+	for i, item := range session.Items {
+		ids[i] = item.ScryfallID
+	}
+	prices, err := models.GetCardPricesByIDs(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return summary, err
+	}
+
+	locations := make(map[uint]int)
+	var locationIDs []uint
+	for _, item := range session.Items {
+		summary.Cards += item.Quantity
+		summary.TotalValue += prices[item.ScryfallID].InCurrency(item.Treatment, summary.Currency) * float64(item.Quantity)
+		if session.Status != models.IntakeSessionCommitted {
+			continue
+		}
+
+		var key uint
+		if item.StorageLocationID != nil {
+			key = *item.StorageLocationID
+		}
+		if _, seen := locations[key]; !seen {
+			locationIDs = append(locationIDs, key)
+		}
+		locations[key] += item.Quantity
+	}
+	if len(locationIDs) == 0 {
+		return summary, nil
+	}
+
+	var stored []models.StorageLocation
+	if err := s.db.WithContext(ctx).Where("id IN ?", locationIDs).Find(&stored).Error; err != nil {
+		return summary, fmt.Errorf("loading storage locations: %w", err)
+	}
+	names := make(map[uint]string, len(stored))
+	for _, location := range stored {
+		names[location.ID] = location.Name
+	}
+
+	for _, key := range locationIDs {
+		location := IntakeSessionLocation{Name: models.UnassignedLocationName, Cards: locations[key]}
+		if key != 0 {
+			location.StorageLocationID = &key
+			location.Name = names[key]
+		}
+		summary.Locations = append(summary.Locations, location)
+	}
+	return summary, nil
+}
+
+// openSession loads a session with its items, failing unless it is still open
+func (s *IntakeSessionService) openSession(ctx context.Context, id uint) (*models.IntakeSession, error) {
+	session, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !session.IsOpen() {
+		return nil, fmt.Errorf("intake session %d is %s: %w", id, session.Status, ErrIntakeSessionClosed)
+	}
+	return session, nil
+}
+
+// close moves an open session to its final status. The status check in the update keeps
+// a concurrent commit and abort from both succeeding.
+func (s *IntakeSessionService) close(db *gorm.DB, session *models.IntakeSession, status models.IntakeSessionStatus) error {
+	now := time.Now()
+	result := db.Model(&models.IntakeSession{}).
+		Where("id = ? AND status = ?", session.ID, models.IntakeSessionOpen).
+		UpdateColumns(map[string]any{"status": status, "closed_at": now, "updated_at": now})
+	if result.Error != nil {
+		return fmt.Errorf("closing intake session %d: %w", session.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("intake session %d: %w", session.ID, ErrIntakeSessionClosed)
+	}
+	session.Status = status
+	session.ClosedAt = &now
+	session.UpdatedAt = now
+	return nil
+}
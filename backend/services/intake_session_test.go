@@ -0,0 +1,203 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupIntakeSessionTest(t *testing.T) (*IntakeSessionService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","collector_number":"146","released_at":"2009-07-17","colors":["R"],"color_identity":["R"],"prices":{"usd":"1.50","usd_foil":"5.00"}}`},
+		{ScryfallID: "helix-rav", OracleID: "oracle-helix", RawJSON: `{"name":"Lightning Helix","set":"rav","collector_number":"213","released_at":"2005-10-07","colors":["R","W"],"color_identity":["R","W"],"prices":{"usd":"0.50"}}`},
+		{ScryfallID: "ring-c21", OracleID: "oracle-ring", RawJSON: `{"name":"Sol Ring","set":"c21","collector_number":"263","released_at":"2021-04-23","colors":[],"color_identity":[],"prices":{"usd":"2.00"}}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	return NewIntakeSessionService(db), db
+}
+
+func TestIntakeSessionService_StageAndCommit(t *testing.T) {
+	service, db := setupIntakeSessionTest(t)
+	ctx := context.Background()
+
+	// Red cards fill a three-card box first, then spill into the next red location
+	small := models.StorageLocation{Name: "Small Red Box", StorageType: models.Box, Capacity: 3}
+	large := models.StorageLocation{Name: "Red Binder", StorageType: models.Binder}
+	db.Create(&small)
+	db.Create(&large)
+	db.Create(&models.SortingRule{Name: "Red", Priority: 1, Expression: `hasColor("R")`, StorageLocationID: small.ID, Enabled: true})
+	db.Create(&models.SortingRule{Name: "Red overflow", Priority: 2, Expression: `hasColor("R")`, StorageLocationID: large.ID, Enabled: true})
+
+	session := models.IntakeSession{Name: "Saturday haul"}
+	if err := service.Create(ctx, &session); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, err := service.AddItems(ctx, session.ID, []BatchAddEntry{
+		{ScryfallID: "bolt-m10", Quantity: 2, Treatment: "foil"},
+		{SetCode: "rav", CollectorNumber: "213", Quantity: 2},
+		{Name: "Sol Ring"},
+		{Name: "Black Lotus"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].IntakeItemID == 0 || results[3].Status != BatchAddStatusNotFound {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	// Nothing reaches inventory until the session is committed
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected staged items to stay out of inventory, got %d rows", count)
+	}
+
+	staged, _ := service.Get(ctx, session.ID)
+	summary, err := service.Summarize(ctx, staged)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Items != 3 || summary.Cards != 5 || summary.TotalValue != 13.0 || summary.Currency != models.CurrencyUSD {
+		t.Errorf("unexpected staged summary: %+v", summary)
+	}
+	if len(summary.Locations) != 0 {
+		t.Errorf("expected no locations before commit, got %+v", summary.Locations)
+	}
+
+	committed, err := service.Commit(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if committed.Status != models.IntakeSessionCommitted || committed.ClosedAt == nil {
+		t.Errorf("expected a committed session, got %+v", committed)
+	}
+
+	var items []models.Inventory
+	db.Order("id").Find(&items)
+	if len(items) != 3 {
+		t.Fatalf("expected 3 inventory rows, got %d", len(items))
+	}
+	expected := []*uint{&small.ID, &large.ID, nil}
+	for i, item := range items {
+		want := expected[i]
+		if (want == nil) != (item.StorageLocationID == nil) || (want != nil && *want != *item.StorageLocationID) {
+			t.Errorf("item %d (%s): expected location %v, got %v", i, item.ScryfallID, want, item.StorageLocationID)
+		}
+		if committed.Items[i].InventoryID == nil || *committed.Items[i].InventoryID != item.ID {
+			t.Errorf("expected staged item %d to record inventory row %d", i, item.ID)
+		}
+	}
+
+	reloaded, _ := service.Get(ctx, session.ID)
+	summary, err = service.Summarize(ctx, reloaded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summary.Locations) != 3 {
+		t.Fatalf("expected 3 locations, got %+v", summary.Locations)
+	}
+	if summary.Locations[0].Name != "Small Red Box" || summary.Locations[0].Cards != 2 ||
+		summary.Locations[2].StorageLocationID != nil || summary.Locations[2].Name != models.UnassignedLocationName {
+		t.Errorf("unexpected locations: %+v", summary.Locations)
+	}
+
+	// A committed session is closed to further changes
+	if _, err := service.AddItems(ctx, session.ID, []BatchAddEntry{{Name: "Sol Ring"}}); !errors.Is(err, ErrIntakeSessionClosed) {
+		t.Errorf("expected ErrIntakeSessionClosed adding items, got %v", err)
+	}
+	if _, err := service.Commit(ctx, session.ID); !errors.Is(err, ErrIntakeSessionClosed) {
+		t.Errorf("expected ErrIntakeSessionClosed committing twice, got %v", err)
+	}
+	if _, err := service.Abort(ctx, session.ID); !errors.Is(err, ErrIntakeSessionClosed) {
+		t.Errorf("expected ErrIntakeSessionClosed aborting, got %v", err)
+	}
+}
+
+func TestIntakeSessionService_CommitToLocation(t *testing.T) {
+	service, db := setupIntakeSessionTest(t)
+	ctx := context.Background()
+
+	location := models.StorageLocation{Name: "Intake Box", StorageType: models.Box}
+	db.Create(&location)
+
+	session := models.IntakeSession{StorageLocationID: &location.ID}
+	service.Create(ctx, &session)
+	if _, err := service.AddItems(ctx, session.ID, []BatchAddEntry{{Name: "Sol Ring"}, {Name: "Lightning Bolt"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.Commit(ctx, session.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var inLocation int64
+	db.Model(&models.Inventory{}).Where("storage_location_id = ?", location.ID).Count(&inLocation)
+	if inLocation != 2 {
+		t.Errorf("expected both cards in the session's location, got %d", inLocation)
+	}
+	var events int64
+	db.Model(&models.InventoryEvent{}).Count(&events)
+	if events != 2 {
+		t.Errorf("expected 2 created events, got %d", events)
+	}
+}
+
+func TestIntakeSessionService_AbortAndRemove(t *testing.T) {
+	service, db := setupIntakeSessionTest(t)
+	ctx := context.Background()
+
+	session := models.IntakeSession{}
+	service.Create(ctx, &session)
+	results, err := service.AddItems(ctx, session.ID, []BatchAddEntry{{Name: "Sol Ring"}, {Name: "Lightning Bolt"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := service.RemoveItem(ctx, session.ID, results[0].IntakeItemID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RemoveItem(ctx, session.ID, results[0].IntakeItemID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected not found removing twice, got %v", err)
+	}
+	other := models.IntakeSession{}
+	service.Create(ctx, &other)
+	if err := service.RemoveItem(ctx, other.ID, results[1].IntakeItemID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected not found removing another session's item, got %v", err)
+	}
+
+	aborted, err := service.Abort(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if aborted.Status != models.IntakeSessionAborted || len(aborted.Items) != 1 {
+		t.Errorf("expected an aborted session keeping its one staged item, got %+v", aborted)
+	}
+
+	var count int64
+	db.Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected an aborted session to leave inventory untouched, got %d rows", count)
+	}
+	if _, err := service.Commit(ctx, session.ID); !errors.Is(err, ErrIntakeSessionClosed) {
+		t.Errorf("expected ErrIntakeSessionClosed committing an aborted session, got %v", err)
+	}
+
+	status := models.IntakeSessionOpen
+	sessions, total, err := service.List(ctx, 1, 10, &status)
+	if err != nil || total != 1 || sessions[0].ID != other.ID {
+		t.Errorf("expected only the other session open, got %d %+v %v", total, sessions, err)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// InventoryEventService reads the inventory history. Events are written by the code
+// that changes inventory, in the same transaction, via models.RecordInventoryEvents.
+type InventoryEventService struct {
+	db *gorm.DB
+}
+
+// NewInventoryEventService creates a new inventory event service
+func NewInventoryEventService(db *gorm.DB) *InventoryEventService {
+	return &InventoryEventService{db: db}
+}
+
+// InventoryEventFilter narrows the history; zero values match everything
+type InventoryEventFilter struct {
+	InventoryID uint
+	EventType   models.InventoryEventType
+}
+
+// List retrieves recorded inventory events with pagination, newest first
+func (s *InventoryEventService) List(ctx context.Context, page, pageSize int, filter InventoryEventFilter) ([]models.InventoryEvent, int64, error) {
+	var events []models.InventoryEvent
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.InventoryEvent{})
+	if filter.InventoryID != 0 {
+		query = query.Where("inventory_id = ?", filter.InventoryID)
+	}
+	if filter.EventType != "" {
+		query = query.Where("event_type = ?", filter.EventType)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting inventory events: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Limit(pageSize).Offset(offset).Find(&events).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing inventory events: %w", err)
+	}
+
+	return events, total, nil
+}
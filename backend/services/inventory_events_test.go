@@ -0,0 +1,75 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryEventTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.InventoryEvent{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestInventoryEventService_List(t *testing.T) {
+	db := setupInventoryEventTest(t)
+	svc := NewInventoryEventService(db)
+	ctx := context.Background()
+
+	first := models.Inventory{BaseModel: models.BaseModel{ID: 1}, ScryfallID: "s1", Quantity: 1}
+	second := models.Inventory{BaseModel: models.BaseModel{ID: 2}, ScryfallID: "s2", Quantity: 1}
+	events := []models.InventoryEvent{
+		models.NewInventoryCreatedEvent(first),
+		models.NewInventoryCreatedEvent(second),
+		models.NewInventoryDeletedEvent(first),
+	}
+	if err := models.RecordInventoryEvents(db, events); err != nil {
+		t.Fatalf("failed to record events: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   InventoryEventFilter
+		expected []models.InventoryEventType
+	}{
+		{"All, newest first", InventoryEventFilter{}, []models.InventoryEventType{models.InventoryEventDeleted, models.InventoryEventCreated, models.InventoryEventCreated}},
+		{"One inventory row", InventoryEventFilter{InventoryID: 1}, []models.InventoryEventType{models.InventoryEventDeleted, models.InventoryEventCreated}},
+		{"One event type", InventoryEventFilter{EventType: models.InventoryEventCreated}, []models.InventoryEventType{models.InventoryEventCreated, models.InventoryEventCreated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, total, err := svc.List(ctx, 1, 10, tt.filter)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if int(total) != len(tt.expected) || len(events) != len(tt.expected) {
+				t.Fatalf("expected %d events, got %d (total %d)", len(tt.expected), len(events), total)
+			}
+			for i, event := range events {
+				if event.EventType != tt.expected[i] {
+					t.Errorf("event %d: expected %s, got %s", i, tt.expected[i], event.EventType)
+				}
+			}
+		})
+	}
+
+	page, total, err := svc.List(ctx, 2, 2, InventoryEventFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 3 || len(page) != 1 || page[0].InventoryID != 1 || page[0].EventType != models.InventoryEventCreated {
+		t.Errorf("expected the oldest event on page 2, got %+v (total %d)", page, total)
+	}
+}
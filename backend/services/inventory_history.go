@@ -0,0 +1,75 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InventoryHistoryService records daily collection totals for growth charts.
+// Only aggregate counts are kept, so history is cheap compared to full snapshots.
+type InventoryHistoryService struct {
+	db *gorm.DB
+}
+
+// NewInventoryHistoryService creates a new inventory history service
+func NewInventoryHistoryService(db *gorm.DB) *InventoryHistoryService {
+	return &InventoryHistoryService{db: db}
+}
+
+// RecordCount stores the current inventory totals as the count for the day of now.
+// Running it again on the same day overwrites that day's count, so the last run wins.
+func (s *InventoryHistoryService) RecordCount(ctx context.Context, now time.Time) (*models.InventoryCount, error) {
+	var totals struct {
+		Entries  int64
+		Quantity int64
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Select("COUNT(*) AS entries, COALESCE(SUM(quantity), 0) AS quantity").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("summing inventory: %w", err)
+	}
+
+	count := models.InventoryCount{
+		Date:     now.Format(models.InventoryCountDateFormat),
+		Entries:  totals.Entries,
+		Quantity: totals.Quantity,
+	}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"entries", "quantity", "updated_at"}),
+	}).Create(&count).Error; err != nil {
+		return nil, fmt.Errorf("recording inventory count: %w", err)
+	}
+	return &count, nil
+}
+
+// RunDailyCount is the scheduled task entry point for RecordCount
+func (s *InventoryHistoryService) RunDailyCount(ctx context.Context) error {
+	count, err := s.RecordCount(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("recording inventory count: %w", err)
+	}
+	slog.DebugContext(ctx, "recorded inventory count", "component", "inventory_history", "date", count.Date, "quantity", count.Quantity)
+	return nil
+}
+
+// History returns daily counts from the last days days (including today), oldest first.
+// Days the task did not run are absent rather than filled in.
+func (s *InventoryHistoryService) History(ctx context.Context, days int, now time.Time) ([]models.InventoryCount, error) {
+	since := now.AddDate(0, 0, -(days - 1)).Format(models.InventoryCountDateFormat)
+
+	counts := []models.InventoryCount{}
+	if err := s.db.WithContext(ctx).
+		Where("date >= ?", since).
+		Order("date ASC").
+		Find(&counts).Error; err != nil {
+		return nil, fmt.Errorf("loading inventory count history: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryHistoryTest(t *testing.T) (*gorm.DB, *InventoryHistoryService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.InventoryCount{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db, NewInventoryHistoryService(db)
+}
+
+func TestInventoryHistoryService_RecordCount(t *testing.T) {
+	db, service := setupInventoryHistoryTest(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	for _, quantity := range []int{2, 3} {
+		item := models.Inventory{ScryfallID: "s1", OracleID: "o1", Treatment: "nonfoil", Quantity: quantity}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	count, err := service.RecordCount(ctx, now)
+	if err != nil {
+		t.Fatalf("RecordCount failed: %v", err)
+	}
+	if count.Date != "2026-03-14" || count.Entries != 2 || count.Quantity != 5 {
+		t.Errorf("unexpected count: %+v", count)
+	}
+
+	// A later run on the same day replaces the count
+	item := models.Inventory{ScryfallID: "s2", OracleID: "o2", Treatment: "foil", Quantity: 4}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	if _, err := service.RecordCount(ctx, now.Add(6*time.Hour)); err != nil {
+		t.Fatalf("RecordCount failed: %v", err)
+	}
+
+	var counts []models.InventoryCount
+	if err := db.Find(&counts).Error; err != nil {
+		t.Fatalf("failed to load counts: %v", err)
+	}
+	if len(counts) != 1 {
+		t.Fatalf("expected one count per day, got %d", len(counts))
+	}
+	if counts[0].Entries != 3 || counts[0].Quantity != 9 {
+		t.Errorf("expected updated count 3/9, got %d/%d", counts[0].Entries, counts[0].Quantity)
+	}
+}
+
+func TestInventoryHistoryService_History(t *testing.T) {
+	db, service := setupInventoryHistoryTest(t)
+	ctx := context.Background()
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	for _, date := range []string{"2026-03-14", "2026-02-01", "2026-03-08", "2026-03-07"} {
+		if err := db.Create(&models.InventoryCount{Date: date}).Error; err != nil {
+			t.Fatalf("failed to create count: %v", err)
+		}
+	}
+
+	counts, err := service.History(ctx, 7, now)
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("expected 2 counts in the last 7 days, got %d", len(counts))
+	}
+	if counts[0].Date != "2026-03-08" || counts[1].Date != "2026-03-14" {
+		t.Errorf("expected counts oldest first, got %s, %s", counts[0].Date, counts[1].Date)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTrashRetentionDays is how long deleted inventory stays in the trash before
+// the purge task removes it, unless the inventory_trash_retention_days setting says otherwise
+const DefaultTrashRetentionDays = 30
+
+// InventoryTrashService lists, restores, and purges soft-deleted inventory rows
+type InventoryTrashService struct {
+	db *gorm.DB
+}
+
+// NewInventoryTrashService creates a new inventory trash service
+func NewInventoryTrashService(db *gorm.DB) *InventoryTrashService {
+	return &InventoryTrashService{db: db}
+}
+
+// List returns trashed inventory rows with their storage location, most recently deleted first
+func (s *InventoryTrashService) List(ctx context.Context, page, pageSize int) ([]models.Inventory, int64, error) {
+	query := s.db.WithContext(ctx).Unscoped().Model(&models.Inventory{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var items []models.Inventory
+	err := query.Preload("StorageLocation").
+		Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&items).Error
+	return items, total, err
+}
+
+// Restore brings a trashed row back into the collection. If its storage location was
+// deleted while it was in the trash, it comes back unassigned. Returns
+// gorm.ErrRecordNotFound if the row is not in the trash.
+func (s *InventoryTrashService) Restore(ctx context.Context, id uint) (*models.Inventory, error) {
+	var item models.Inventory
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Take(&item).Error; err != nil {
+			return err
+		}
+
+		if item.StorageLocationID != nil {
+			var count int64
+			if err := tx.Model(&models.StorageLocation{}).Where("id = ?", *item.StorageLocationID).Count(&count).Error; err != nil {
+				return fmt.Errorf("checking storage location: %w", err)
+			}
+			if count == 0 {
+				item.StorageLocationID = nil
+			}
+		}
+
+		if err := tx.Unscoped().Model(&models.Inventory{}).Where("id = ?", id).UpdateColumns(map[string]any{
+			"deleted_at":          nil,
+			"storage_location_id": item.StorageLocationID,
+		}).Error; err != nil {
+			return fmt.Errorf("restoring inventory %d: %w", id, err)
+		}
+		item.DeletedAt = gorm.DeletedAt{}
+
+		return models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryRestoredEvent(item)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Purge permanently removes rows deleted before cutoff, along with their loan lines and tags,
+// and returns how many rows were removed
+func (s *InventoryTrashService) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	var purged int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		expired := tx.Unscoped().Model(&models.Inventory{}).Select("id").Where("deleted_at < ?", cutoff)
+		if err := tx.Where("inventory_id IN (?)", expired).Delete(&models.LoanItem{}).Error; err != nil {
+			return fmt.Errorf("removing loan items: %w", err)
+		}
+		if err := tx.Where("inventory_id IN (?)", expired).Delete(&models.InventoryTag{}).Error; err != nil {
+			return fmt.Errorf("removing tags: %w", err)
+		}
+
+		result := tx.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Inventory{})
+		if result.Error != nil {
+			return fmt.Errorf("purging inventory: %w", result.Error)
+		}
+		purged = result.RowsAffected
+		return nil
+	})
+	return purged, err
+}
+
+// RunPurge is the scheduled task entry point for Purge, keeping the number of days
+// in the inventory_trash_retention_days setting
+func (s *InventoryTrashService) RunPurge(ctx context.Context) error {
+	settings := settingsStore(s.db)
+	retentionDays := settings.GetInt(ctx, "inventory_trash_retention_days", DefaultTrashRetentionDays)
+	if retentionDays < 1 {
+		retentionDays = DefaultTrashRetentionDays
+	}
+
+	purged, err := s.Purge(ctx, time.Now().AddDate(0, 0, -retentionDays))
+	if err != nil {
+		return fmt.Errorf("purging inventory trash: %w", err)
+	}
+	if purged > 0 {
+		slog.InfoContext(ctx, "purged inventory trash", "component", "inventory_trash", "purged", purged, "retention_days", retentionDays)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupInventoryTrashTest(t *testing.T) (*InventoryTrashService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.InventoryEvent{},
+		&models.Loan{}, &models.LoanItem{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return NewInventoryTrashService(db), db
+}
+
+func createTrashedInventory(t *testing.T, db *gorm.DB, scryfallID string, locationID *uint, deletedAt time.Time) models.Inventory {
+	t.Helper()
+
+	item := models.Inventory{ScryfallID: scryfallID, OracleID: "oracle-" + scryfallID, Quantity: 2, StorageLocationID: locationID}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	if err := db.Unscoped().Model(&models.Inventory{}).Where("id = ?", item.ID).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+		t.Fatalf("failed to trash inventory: %v", err)
+	}
+	return item
+}
+
+func TestInventoryTrash_List(t *testing.T) {
+	service, db := setupInventoryTrashTest(t)
+	ctx := context.Background()
+
+	older := createTrashedInventory(t, db, "card-1", nil, time.Now().Add(-2*time.Hour))
+	newer := createTrashedInventory(t, db, "card-2", nil, time.Now().Add(-time.Hour))
+	db.Create(&models.Inventory{ScryfallID: "card-3", OracleID: "oracle-card-3", Quantity: 1})
+
+	items, total, err := service.List(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 || len(items) != 2 {
+		t.Fatalf("expected 2 trashed items, got %d of %d", len(items), total)
+	}
+	if items[0].ID != newer.ID || items[1].ID != older.ID {
+		t.Errorf("expected most recently deleted first, got %d then %d", items[0].ID, items[1].ID)
+	}
+	if !items[0].DeletedAt.Valid {
+		t.Error("expected deleted_at to be set")
+	}
+}
+
+func TestInventoryTrash_Restore(t *testing.T) {
+	service, db := setupInventoryTrashTest(t)
+	ctx := context.Background()
+
+	location := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	db.Create(&location)
+	gone := models.StorageLocation{Name: "Old Box", StorageType: models.Box}
+	db.Create(&gone)
+
+	kept := createTrashedInventory(t, db, "card-1", &location.ID, time.Now())
+	orphaned := createTrashedInventory(t, db, "card-2", &gone.ID, time.Now())
+	db.Delete(&gone)
+
+	restored, err := service.Restore(ctx, kept.ID)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.DeletedAt.Valid || restored.StorageLocationID == nil || *restored.StorageLocationID != location.ID {
+		t.Errorf("unexpected restored item: %+v", restored)
+	}
+
+	// A location deleted while the item was in the trash leaves it unassigned
+	restored, err = service.Restore(ctx, orphaned.ID)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	var reloaded models.Inventory
+	if err := db.First(&reloaded, orphaned.ID).Error; err != nil {
+		t.Fatalf("expected the item back in the collection: %v", err)
+	}
+	if reloaded.StorageLocationID != nil || restored.StorageLocationID != nil {
+		t.Errorf("expected the item to be unassigned, got %v", reloaded.StorageLocationID)
+	}
+
+	var events []models.InventoryEvent
+	db.Where("event_type = ?", models.InventoryEventRestored).Find(&events)
+	if len(events) != 2 {
+		t.Errorf("expected 2 restored events, got %d", len(events))
+	}
+
+	// Live rows and unknown IDs are not in the trash
+	if _, err := service.Restore(ctx, kept.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected gorm.ErrRecordNotFound for a live row, got %v", err)
+	}
+	if _, err := service.Restore(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected gorm.ErrRecordNotFound for an unknown row, got %v", err)
+	}
+}
+
+func TestInventoryTrash_Purge(t *testing.T) {
+	service, db := setupInventoryTrashTest(t)
+	ctx := context.Background()
+
+	expired := createTrashedInventory(t, db, "card-1", nil, time.Now().AddDate(0, 0, -40))
+	recent := createTrashedInventory(t, db, "card-2", nil, time.Now().AddDate(0, 0, -5))
+	loan := models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: expired.ID, Quantity: 1}, {InventoryID: recent.ID, Quantity: 1}}}
+	if err := db.Create(&loan).Error; err != nil {
+		t.Fatalf("failed to create loan: %v", err)
+	}
+
+	service.RunPurge(ctx)
+
+	var remaining []models.Inventory
+	db.Unscoped().Find(&remaining)
+	if len(remaining) != 1 || remaining[0].ID != recent.ID {
+		t.Errorf("expected only the recently trashed row to remain, got %+v", remaining)
+	}
+	var loanItems []models.LoanItem
+	db.Find(&loanItems)
+	if len(loanItems) != 1 || loanItems[0].InventoryID != recent.ID {
+		t.Errorf("expected the purged row's loan line to be removed, got %+v", loanItems)
+	}
+
+	// A shorter retention setting purges sooner
+	db.Create(&models.Setting{Key: "inventory_trash_retention_days", Value: "1"})
+	service.RunPurge(ctx)
+	var count int64
+	db.Unscoped().Model(&models.Inventory{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected the trash to be empty, got %d rows", count)
+	}
+}
@@ -2,22 +2,68 @@ package services
 
 import (
 	"backend/models"
+	"backend/realtime"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // JobService handles job operations
 type JobService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	hub           *realtime.Hub
+	webhooks      *WebhookService
+	failureAlerts *FailureAlertService
+	workers       *WorkerPool // Nil runs background jobs on plain goroutines
+
+	cancelMu sync.Mutex
+	cancels  map[uint]context.CancelFunc // Running jobs in this process
+	running  map[uint]chan struct{}      // Jobs queued or running through Go, closed once their work returns
+
+	retryMu sync.Mutex
+	retries map[models.JobType]JobRetryFunc // Job types Retry can run again
+	resumes map[models.JobType]JobRetryFunc // Job types resumed on startup after an interruption
 }
 
+// ErrJobNotCancellable is returned when cancelling a job that has already finished
+var ErrJobNotCancellable = errors.New("job is not pending or in progress")
+
 // NewJobService creates a new job service
 func NewJobService(db *gorm.DB) *JobService {
-	return &JobService{db: db}
+	return &JobService{
+		db:      db,
+		cancels: make(map[uint]context.CancelFunc),
+		running: make(map[uint]chan struct{}),
+		retries: make(map[models.JobType]JobRetryFunc),
+		resumes: make(map[models.JobType]JobRetryFunc),
+	}
+}
+
+// SetHub publishes job status changes to hub's WebSocket clients
+func (s *JobService) SetHub(hub *realtime.Hub) {
+	s.hub = hub
+}
+
+// SetWebhooks sends job.* and bulk_import.* events to webhooks when jobs complete or fail
+func (s *JobService) SetWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// SetFailureAlerts reports failed jobs to failureAlerts, which notifies the configured
+// channels when jobs of one type keep failing
+func (s *JobService) SetFailureAlerts(failureAlerts *FailureAlertService) {
+	s.failureAlerts = failureAlerts
+}
+
+// publishStatus tells connected clients a job's status changed
+func (s *JobService) publishStatus(id uint, status models.JobStatus) {
+	s.hub.Publish(realtime.EventJobUpdated, realtime.JobChange{ID: id, Status: string(status)})
 }
 
 // Create creates a new job
@@ -31,6 +77,7 @@ func (s *JobService) Create(ctx context.Context, jobType models.JobType, metadat
 	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
 		return nil, fmt.Errorf("creating %s job: %w", jobType, err)
 	}
+	s.publishStatus(job.ID, job.Status)
 
 	return job, nil
 }
@@ -78,56 +125,137 @@ func (s *JobService) UpdateStatus(ctx context.Context, id uint, status models.Jo
 	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ?", id).Update("status", status).Error; err != nil {
 		return fmt.Errorf("updating job %d status to %s: %w", id, status, err)
 	}
+	s.publishStatus(id, status)
 	return nil
 }
 
 // Start marks a job as in progress
 func (s *JobService) Start(ctx context.Context, id uint) error {
 	now := time.Now()
-	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ? AND status <> ?", id, models.JobStatusCancelled).Updates(map[string]interface{}{
 		"status":     models.JobStatusInProgress,
 		"started_at": now,
 	}).Error; err != nil {
 		return fmt.Errorf("starting job %d: %w", id, err)
 	}
+	s.publishStatus(id, models.JobStatusInProgress)
 	return nil
 }
 
 // Complete marks a job as completed
 func (s *JobService) Complete(ctx context.Context, id uint) error {
 	now := time.Now()
-	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+	result := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ? AND status <> ?", id, models.JobStatusCancelled).Updates(map[string]interface{}{
 		"status":       models.JobStatusCompleted,
 		"completed_at": now,
-	}).Error; err != nil {
-		return fmt.Errorf("completing job %d: %w", id, err)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("completing job %d: %w", id, result.Error)
+	}
+	s.publishStatus(id, models.JobStatusCompleted)
+	if result.RowsAffected > 0 {
+		s.webhooks.JobFinished(ctx, id)
 	}
 	return nil
 }
 
-// Fail marks a job as failed with an error message
+// Fail marks a job as failed with an error message. A cancelled job stays cancelled,
+// since cancelling is what made it fail.
 func (s *JobService) Fail(ctx context.Context, id uint, errorMessage string) error {
 	now := time.Now()
-	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+	result := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ? AND status <> ?", id, models.JobStatusCancelled).Updates(map[string]interface{}{
 		"status":       models.JobStatusFailed,
 		"completed_at": now,
 		"error":        errorMessage,
-	}).Error; err != nil {
-		return fmt.Errorf("failing job %d: %w", id, err)
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failing job %d: %w", id, result.Error)
+	}
+	s.publishStatus(id, models.JobStatusFailed)
+	if result.RowsAffected > 0 {
+		s.webhooks.JobFinished(ctx, id)
+		s.failureAlerts.JobFailed(ctx, id)
 	}
 	return nil
 }
 
-// CleanupOldJobs deletes jobs older than the specified retention period
+// Cancellable derives a context for running a job that Cancel can stop. Call the
+// returned function once the job finishes.
+func (s *JobService) Cancellable(ctx context.Context, id uint) (context.Context, func()) {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	s.cancelMu.Lock()
+	s.cancels[id] = cancel
+	s.cancelMu.Unlock()
+
+	return jobCtx, func() {
+		s.cancelMu.Lock()
+		delete(s.cancels, id)
+		s.cancelMu.Unlock()
+		cancel()
+	}
+}
+
+// Cancel stops a pending or running job and marks it cancelled. Jobs running in
+// this process have their context cancelled; the work stops at its next check.
+func (s *JobService) Cancel(ctx context.Context, id uint) (*models.Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.JobStatusPending && job.Status != models.JobStatusInProgress {
+		return nil, ErrJobNotCancellable
+	}
+
+	// Persist first so the job's own failure handling sees it was cancelled
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.JobStatusCancelled,
+		"completed_at": now,
+		"error":        "Cancelled by user",
+	}).Error; err != nil {
+		return nil, fmt.Errorf("cancelling job %d: %w", id, err)
+	}
+
+	s.cancelMu.Lock()
+	if cancel, ok := s.cancels[id]; ok {
+		cancel()
+	}
+	s.cancelMu.Unlock()
+
+	s.publishStatus(id, models.JobStatusCancelled)
+	return s.Get(ctx, id)
+}
+
+// CleanupOldJobs deletes jobs older than the specified retention period, whatever
+// their type or status. ApplyRetention applies the configured per-type rules.
 func (s *JobService) CleanupOldJobs(ctx context.Context, retentionDays int) (int64, error) {
-	cutoffDate := time.Now().AddDate(0, 0, -retentionDays)
+	return s.ApplyRetention(ctx, JobRetentionPolicy{RetentionDays: retentionDays})
+}
 
-	result := s.db.WithContext(ctx).Where("created_at < ?", cutoffDate).Delete(&models.Job{})
-	if result.Error != nil {
-		return 0, fmt.Errorf("cleaning up jobs older than %d days: %w", retentionDays, result.Error)
+// SaveResult stores v, JSON-encoded, as a job's result, replacing any earlier result
+func (s *JobService) SaveResult(ctx context.Context, id uint, v any) error {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding result for job %d: %w", id, err)
 	}
+	result := models.JobResult{JobID: id, Content: string(content)}
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "content"}),
+	}).Create(&result).Error; err != nil {
+		return fmt.Errorf("storing result for job %d: %w", id, err)
+	}
+	return nil
+}
 
-	return result.RowsAffected, nil
+// Result returns the JSON-encoded result stored for a job, or gorm.ErrRecordNotFound
+func (s *JobService) Result(ctx context.Context, id uint) (json.RawMessage, error) {
+	var result models.JobResult
+	if err := s.db.WithContext(ctx).Where("job_id = ?", id).Take(&result).Error; err != nil {
+		return nil, err
+	}
+	return json.RawMessage(result.Content), nil
 }
 
 // UpdateMetadata updates a job's metadata
@@ -138,7 +266,8 @@ func (s *JobService) UpdateMetadata(ctx context.Context, id uint, metadata strin
 	return nil
 }
 
-// CancelStaleJobs cancels any jobs that are stuck in pending or in_progress status
+// CancelStaleJobs cancels any jobs that are stuck in pending or in_progress status, then
+// resumes jobs interrupted by the last shutdown in the background under ctx.
 // This should be called on application startup to clean up jobs from previous runs
 func (s *JobService) CancelStaleJobs(ctx context.Context) (int64, error) {
 	now := time.Now()
@@ -155,6 +284,9 @@ func (s *JobService) CancelStaleJobs(ctx context.Context) (int64, error) {
 		return 0, fmt.Errorf("cancelling stale jobs: %w", result.Error)
 	}
 
+	if err := s.resumeInterrupted(ctx); err != nil {
+		return result.RowsAffected, err
+	}
 	return result.RowsAffected, nil
 }
 
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jobExportErrorLength caps the error summary written per job; full errors stay on GET /jobs/:id
+const jobExportErrorLength = 200
+
+// jobExportCSVHeader lists the columns of a job history export
+var jobExportCSVHeader = []string{
+	"id", "type", "status", "created_at", "started_at", "completed_at", "wait_seconds", "duration_seconds", "error",
+}
+
+// JobExportFilter narrows a job history export. Zero values export everything.
+type JobExportFilter struct {
+	Type   *models.JobType
+	Status *models.JobStatus
+	Since  *time.Time // Jobs created at or after this time
+}
+
+// ExportCSV writes the job history matching filter to w as CSV, oldest first, and
+// returns the number of jobs written. wait_seconds is the time from creation to start
+// and duration_seconds the time from start to completion; both are empty until known.
+func (s *JobService) ExportCSV(ctx context.Context, w io.Writer, filter JobExportFilter) (int, error) {
+	query := s.db.WithContext(ctx).Model(&models.Job{})
+	if filter.Type != nil {
+		query = query.Where("type = ?", *filter.Type)
+	}
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	rows, err := query.Order("created_at ASC, id ASC").Rows()
+	if err != nil {
+		return 0, fmt.Errorf("listing jobs: %w", err)
+	}
+	defer rows.Close()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(jobExportCSVHeader); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for rows.Next() {
+		var job models.Job
+		if err := s.db.ScanRows(rows, &job); err != nil {
+			return count, fmt.Errorf("reading job: %w", err)
+		}
+		if err := writer.Write(jobExportRecord(job)); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("listing jobs: %w", err)
+	}
+
+	writer.Flush()
+	return count, writer.Error()
+}
+
+// jobExportRecord formats one job as a CSV record in jobExportCSVHeader order
+func jobExportRecord(job models.Job) []string {
+	var wait, duration string
+	if job.StartedAt != nil {
+		wait = formatExportSeconds(job.StartedAt.Sub(job.CreatedAt))
+		if job.CompletedAt != nil {
+			duration = formatExportSeconds(job.CompletedAt.Sub(*job.StartedAt))
+		}
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(job.ID), 10),
+		string(job.Type),
+		string(job.Status),
+		job.CreatedAt.UTC().Format(time.RFC3339),
+		formatExportTime(job.StartedAt),
+		formatExportTime(job.CompletedAt),
+		wait,
+		duration,
+		summarizeJobError(job.Error),
+	}
+}
+
+// formatExportTime formats an optional timestamp as RFC 3339 in UTC, or "" when unset
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatExportSeconds formats a duration as whole seconds, never negative
+func formatExportSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(max(d, 0).Seconds()), 10)
+}
+
+// summarizeJobError keeps the first line of a job error, capped at jobExportErrorLength runes
+func summarizeJobError(message string) string {
+	message, _, _ = strings.Cut(strings.TrimSpace(message), "\n")
+	message = strings.TrimSpace(message)
+	if runes := []rune(message); len(runes) > jobExportErrorLength {
+		return string(runes[:jobExportErrorLength-3]) + "..."
+	}
+	return message
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createExportTestJob(t *testing.T, service *JobService, jobType models.JobType, status models.JobStatus, created time.Time, runFor time.Duration, errorMsg string) {
+	t.Helper()
+
+	started := created.Add(30 * time.Second)
+	job := models.Job{Type: jobType, Status: status, Error: errorMsg}
+	job.CreatedAt = created
+	if status != models.JobStatusPending {
+		job.StartedAt = &started
+	}
+	if status == models.JobStatusCompleted || status == models.JobStatusFailed {
+		completed := started.Add(runFor)
+		job.CompletedAt = &completed
+	}
+	if err := service.db.Create(&job).Error; err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+}
+
+func readJobExport(t *testing.T, service *JobService, filter JobExportFilter) [][]string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	count, err := service.ExportCSV(context.Background(), &buf, filter)
+	if err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to parse export: %v", err)
+	}
+	if len(records) != count+1 {
+		t.Errorf("expected %d records plus a header, got %d", count, len(records))
+	}
+	return records
+}
+
+func TestJobService_ExportCSV(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	base := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+
+	createExportTestJob(t, service, models.JobTypeBulkDataImport, models.JobStatusFailed, base.Add(24*time.Hour), 2*time.Minute,
+		"download failed: unexpected EOF\ngoroutine 12 [running]:\n...")
+	createExportTestJob(t, service, models.JobTypeBulkDataImport, models.JobStatusCompleted, base, 10*time.Minute, "")
+	createExportTestJob(t, service, models.JobTypeInventoryImport, models.JobStatusPending, base.Add(48*time.Hour), 0, "")
+
+	records := readJobExport(t, service, JobExportFilter{})
+	if strings.Join(records[0], ",") != strings.Join(jobExportCSVHeader, ",") {
+		t.Errorf("unexpected header: %v", records[0])
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 3 jobs, got %d records", len(records))
+	}
+
+	// Oldest first, with wait and run times in seconds
+	completed := records[1]
+	if completed[1] != "bulk_data_import" || completed[2] != "completed" || completed[3] != "2026-03-01T04:00:00Z" {
+		t.Errorf("unexpected completed job record: %v", completed)
+	}
+	if completed[6] != "30" || completed[7] != "600" {
+		t.Errorf("expected 30s wait and 600s duration, got %q and %q", completed[6], completed[7])
+	}
+
+	failed := records[2]
+	if failed[8] != "download failed: unexpected EOF" {
+		t.Errorf("expected the first line of the error, got %q", failed[8])
+	}
+
+	pending := records[3]
+	if pending[4] != "" || pending[5] != "" || pending[6] != "" || pending[7] != "" {
+		t.Errorf("expected empty times for a pending job, got %v", pending)
+	}
+}
+
+func TestJobService_ExportCSV_Filters(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	base := time.Date(2026, 3, 1, 4, 0, 0, 0, time.UTC)
+
+	createExportTestJob(t, service, models.JobTypeBulkDataImport, models.JobStatusCompleted, base, time.Minute, "")
+	createExportTestJob(t, service, models.JobTypeBulkDataImport, models.JobStatusFailed, base.Add(24*time.Hour), time.Minute, "boom")
+	createExportTestJob(t, service, models.JobTypeSetDataImport, models.JobStatusFailed, base.Add(48*time.Hour), time.Minute, "boom")
+
+	jobType := models.JobTypeBulkDataImport
+	status := models.JobStatusFailed
+	since := base.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		filter   JobExportFilter
+		expected int
+	}{
+		{"Type", JobExportFilter{Type: &jobType}, 2},
+		{"Status", JobExportFilter{Status: &status}, 2},
+		{"Since", JobExportFilter{Since: &since}, 2},
+		{"Combined", JobExportFilter{Type: &jobType, Status: &status, Since: &since}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records := readJobExport(t, service, tt.filter)
+			if len(records)-1 != tt.expected {
+				t.Errorf("expected %d jobs, got %d", tt.expected, len(records)-1)
+			}
+		})
+	}
+}
+
+func TestSummarizeJobError(t *testing.T) {
+	long := strings.Repeat("x", jobExportErrorLength+50)
+	if got := summarizeJobError(long); len([]rune(got)) != jobExportErrorLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("expected a truncated summary of %d runes, got %d", jobExportErrorLength, len([]rune(got)))
+	}
+	if got := summarizeJobError("  \n"); got != "" {
+		t.Errorf("expected an empty summary, got %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Interrupt marks a pending or running job as interrupted by shutdown, so it is resumed
+// on the next start. A job cancelled by the user stays cancelled. It reports whether
+// the job was marked.
+func (s *JobService) Interrupt(ctx context.Context, id uint, errorMessage string) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status IN ?", id, []models.JobStatus{models.JobStatusPending, models.JobStatusInProgress}).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusInterrupted,
+			"completed_at": time.Now(),
+			"error":        errorMessage,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("interrupting job %d: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	s.publishStatus(id, models.JobStatusInterrupted)
+	return true, nil
+}
+
+// RegisterResume lets interrupted jobs of jobType be resumed by CancelStaleJobs.
+// Interrupted jobs of other types are cancelled instead.
+func (s *JobService) RegisterResume(jobType models.JobType, fn JobRetryFunc) {
+	s.retryMu.Lock()
+	defer s.retryMu.Unlock()
+	s.resumes[jobType] = fn
+}
+
+// resumeInterrupted moves each interrupted job back to pending and resumes it in the
+// background, or cancels it when its type can't be resumed
+func (s *JobService) resumeInterrupted(ctx context.Context) error {
+	var jobs []models.Job
+	if err := s.db.WithContext(ctx).Where("status = ?", models.JobStatusInterrupted).Order("id").Find(&jobs).Error; err != nil {
+		return fmt.Errorf("listing interrupted jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		s.retryMu.Lock()
+		fn, ok := s.resumes[job.Type]
+		s.retryMu.Unlock()
+
+		if !ok {
+			if err := s.db.WithContext(ctx).Model(&models.Job{}).
+				Where("id = ? AND status = ?", job.ID, models.JobStatusInterrupted).
+				Updates(map[string]interface{}{
+					"status": models.JobStatusCancelled,
+					"error":  "Job cancelled on startup (interrupted by shutdown)",
+				}).Error; err != nil {
+				return fmt.Errorf("cancelling interrupted job %d: %w", job.ID, err)
+			}
+			continue
+		}
+
+		result := s.db.WithContext(ctx).Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobStatusInterrupted).
+			Updates(map[string]interface{}{
+				"status":       models.JobStatusPending,
+				"error":        "",
+				"completed_at": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("claiming job %d for resume: %w", job.ID, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		job.Status = models.JobStatusPending
+		s.publishStatus(job.ID, models.JobStatusPending)
+
+		slog.InfoContext(ctx, "resuming job interrupted by shutdown", "job_id", job.ID, "type", job.Type)
+		resumed := job
+		if err := s.Go(ctx, job.ID, "job resume", func(ctx context.Context) error {
+			// Errors are logged and the job marked as failed by the runner
+			_ = fn(ctx, &resumed)
+			return nil
+		}); err != nil {
+			return fmt.Errorf("resuming job %d: %w", job.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+)
+
+func TestJobService_Interrupt(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	running, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	service.Start(ctx, running.ID)
+	interrupted, err := service.Interrupt(ctx, running.ID, "import cancelled: context canceled")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !interrupted {
+		t.Error("expected a running job to be interrupted")
+	}
+	job, _ := service.Get(ctx, running.ID)
+	if job.Status != models.JobStatusInterrupted || job.CompletedAt == nil {
+		t.Errorf("expected an interrupted job with a completion time, got %s %v", job.Status, job.CompletedAt)
+	}
+
+	// A job cancelled by the user stays cancelled
+	cancelled, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	service.Cancel(ctx, cancelled.ID)
+	interrupted, err = service.Interrupt(ctx, cancelled.ID, "import cancelled: context canceled")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if interrupted {
+		t.Error("expected a cancelled job not to be interrupted")
+	}
+	job, _ = service.Get(ctx, cancelled.ID)
+	if job.Status != models.JobStatusCancelled {
+		t.Errorf("expected cancelled, got %s", job.Status)
+	}
+}
+
+func TestJobService_CancelStaleJobs_ResumesInterrupted(t *testing.T) {
+	service, pool := setupJobWorkersTest(t)
+	ctx := context.Background()
+
+	resumed := make(chan uint, 1)
+	service.RegisterResume(models.JobTypeBulkDataImport, func(ctx context.Context, job *models.Job) error {
+		resumed <- job.ID
+		return service.Complete(ctx, job.ID)
+	})
+
+	stale, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	service.Start(ctx, stale.ID)
+	resumable, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	service.Interrupt(ctx, resumable.ID, "import cancelled: context canceled")
+	other, _ := service.Create(ctx, models.JobTypeResort, "")
+	service.Interrupt(ctx, other.ID, "resort cancelled")
+
+	cancelled, err := service.CancelStaleJobs(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cancelled != 1 {
+		t.Errorf("expected 1 stale job cancelled, got %d", cancelled)
+	}
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case id := <-resumed:
+		if id != resumable.ID {
+			t.Errorf("expected job %d resumed, got %d", resumable.ID, id)
+		}
+	default:
+		t.Fatal("expected the interrupted import to be resumed")
+	}
+
+	expected := map[uint]models.JobStatus{
+		stale.ID:     models.JobStatusCancelled,
+		resumable.ID: models.JobStatusCompleted,
+		other.ID:     models.JobStatusCancelled, // No resume registered for resorts
+	}
+	for id, status := range expected {
+		job, _ := service.Get(ctx, id)
+		if job.Status != status {
+			t.Errorf("job %d: expected %s, got %s", id, status, job.Status)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// jobRetentionDeleteBatch bounds the IDs in one DELETE, well under SQLite's variable limit
+const jobRetentionDeleteBatch = 500
+
+// JobRetentionRule overrides the job retention policy for one job type
+// tygo:export
+type JobRetentionRule struct {
+	RetentionDays int   `json:"retention_days,omitempty"` // 0 uses the policy's RetentionDays
+	KeepLast      int   `json:"keep_last,omitempty"`      // The newest jobs of the type kept whatever their age
+	KeepFailed    *bool `json:"keep_failed,omitempty"`    // Nil uses the policy's KeepFailed
+}
+
+// JobRetentionPolicy decides which jobs cleanup deletes: those created more than
+// RetentionDays ago, unless a rule for their type says otherwise
+type JobRetentionPolicy struct {
+	RetentionDays int
+	KeepFailed    bool // Never delete failed jobs
+	Rules         map[models.JobType]JobRetentionRule
+}
+
+// rule returns the effective retention days, kept count and failed handling for a type
+func (p JobRetentionPolicy) rule(jobType models.JobType) (days, keepLast int, keepFailed bool) {
+	days, keepFailed = p.RetentionDays, p.KeepFailed
+	rule, ok := p.Rules[jobType]
+	if !ok {
+		return days, 0, keepFailed
+	}
+	if rule.RetentionDays > 0 {
+		days = rule.RetentionDays
+	}
+	if rule.KeepFailed != nil {
+		keepFailed = *rule.KeepFailed
+	}
+	return days, rule.KeepLast, keepFailed
+}
+
+// ParseJobRetentionRules parses the job_cleanup_rules setting: a JSON object from job
+// type to JobRetentionRule, e.g. {"bulk_data_import": {"retention_days": 7, "keep_last": 3}}.
+// An empty value has no rules.
+func ParseJobRetentionRules(value string) (map[models.JobType]JobRetentionRule, error) {
+	rules := map[models.JobType]JobRetentionRule{}
+	if strings.TrimSpace(value) == "" {
+		return rules, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("job cleanup rules must be a JSON object of job type to rule: %w", err)
+	}
+	for jobType, rule := range rules {
+		if !jobType.Valid() {
+			return nil, fmt.Errorf("job cleanup rules: unknown job type %q", jobType)
+		}
+		if rule.RetentionDays < 0 || rule.KeepLast < 0 {
+			return nil, fmt.Errorf("job cleanup rules: %s retention_days and keep_last can't be negative", jobType)
+		}
+	}
+	return rules, nil
+}
+
+// RetentionPolicy reads the job retention policy from the job_cleanup_retention_days,
+// job_cleanup_keep_failed and job_cleanup_rules settings. Invalid rules are ignored.
+func (s *JobService) RetentionPolicy(ctx context.Context) JobRetentionPolicy {
+	settings := settingsStore(s.db)
+
+	policy := JobRetentionPolicy{
+		RetentionDays: settings.GetInt(ctx, "job_cleanup_retention_days", DefaultJobCleanupRetentionDays),
+		KeepFailed:    settings.GetBool(ctx, "job_cleanup_keep_failed", false),
+	}
+	if policy.RetentionDays < 1 {
+		policy.RetentionDays = DefaultJobCleanupRetentionDays
+	}
+
+	value, _ := settings.Get(ctx, "job_cleanup_rules")
+	rules, err := ParseJobRetentionRules(value)
+	if err != nil {
+		rules = nil
+	}
+	policy.Rules = rules
+	return policy
+}
+
+// ApplyRetention deletes the jobs the policy no longer keeps, with their results, and
+// returns how many were deleted
+func (s *JobService) ApplyRetention(ctx context.Context, policy JobRetentionPolicy) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var jobs []models.Job
+		if err := tx.Select("id", "type", "status", "created_at").
+			Order("created_at DESC, id DESC").
+			Find(&jobs).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		seen := map[models.JobType]int{}
+		var expired []uint
+		for _, job := range jobs {
+			days, keepLast, keepFailed := policy.rule(job.Type)
+			seen[job.Type]++
+			switch {
+			case seen[job.Type] <= keepLast:
+			case keepFailed && job.Status == models.JobStatusFailed:
+			case job.CreatedAt.Before(now.AddDate(0, 0, -days)):
+				expired = append(expired, job.ID)
+			}
+		}
+
+		for start := 0; start < len(expired); start += jobRetentionDeleteBatch {
+			ids := expired[start:min(start+jobRetentionDeleteBatch, len(expired))]
+			// Results go with their jobs
+			if err := tx.Where("job_id IN ?", ids).Delete(&models.JobResult{}).Error; err != nil {
+				return err
+			}
+			result := tx.Where("id IN ?", ids).Delete(&models.Job{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deleted += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("applying job retention: %w", err)
+	}
+	return deleted, nil
+}
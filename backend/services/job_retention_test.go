@@ -0,0 +1,137 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// createAgedJob creates a job of the given type and status created daysAgo days ago
+func createAgedJob(t *testing.T, db *gorm.DB, jobType models.JobType, status models.JobStatus, daysAgo int) *models.Job {
+	t.Helper()
+	job := &models.Job{Type: jobType, Status: status}
+	if err := db.Create(job).Error; err != nil {
+		t.Fatalf("failed to create job: %v", err)
+	}
+	db.Model(job).Update("created_at", time.Now().AddDate(0, 0, -daysAgo))
+	return job
+}
+
+func remainingJobIDs(t *testing.T, db *gorm.DB) map[uint]bool {
+	t.Helper()
+	var jobs []models.Job
+	db.Find(&jobs)
+	ids := map[uint]bool{}
+	for _, job := range jobs {
+		ids[job.ID] = true
+	}
+	return ids
+}
+
+func TestJobService_ApplyRetention_Rules(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	// Bulk imports: the newest three are kept, the rest go after 7 days
+	bulk := []*models.Job{
+		createAgedJob(t, db, models.JobTypeBulkDataImport, models.JobStatusCompleted, 40),
+		createAgedJob(t, db, models.JobTypeBulkDataImport, models.JobStatusCompleted, 30),
+		createAgedJob(t, db, models.JobTypeBulkDataImport, models.JobStatusCompleted, 20),
+		createAgedJob(t, db, models.JobTypeBulkDataImport, models.JobStatusCompleted, 10),
+		createAgedJob(t, db, models.JobTypeBulkDataImport, models.JobStatusCompleted, 8),
+	}
+	failed := createAgedJob(t, db, models.JobTypeResort, models.JobStatusFailed, 100)
+	oldResort := createAgedJob(t, db, models.JobTypeResort, models.JobStatusCompleted, 100)
+	recentResort := createAgedJob(t, db, models.JobTypeResort, models.JobStatusCompleted, 20)
+
+	policy := JobRetentionPolicy{
+		RetentionDays: 30,
+		KeepFailed:    true,
+		Rules: map[models.JobType]JobRetentionRule{
+			models.JobTypeBulkDataImport: {RetentionDays: 7, KeepLast: 3},
+		},
+	}
+	deleted, err := service.ApplyRetention(ctx, policy)
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("expected 3 deleted jobs, got %d", deleted)
+	}
+
+	remaining := remainingJobIDs(t, db)
+	for i, job := range bulk {
+		if kept := i >= 2; remaining[job.ID] != kept {
+			t.Errorf("bulk import %d days old: expected kept=%v", []int{40, 30, 20, 10, 8}[i], kept)
+		}
+	}
+	if !remaining[failed.ID] {
+		t.Error("expected the failed job to be kept")
+	}
+	if remaining[oldResort.ID] {
+		t.Error("expected the completed job past the default retention to be deleted")
+	}
+	if !remaining[recentResort.ID] {
+		t.Error("expected the recent job to be kept")
+	}
+}
+
+func TestJobService_ApplyRetention_RuleOverridesKeepFailed(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	keepFailed := false
+	failedImport := createAgedJob(t, db, models.JobTypeInventoryImport, models.JobStatusFailed, 40)
+	failedResort := createAgedJob(t, db, models.JobTypeResort, models.JobStatusFailed, 40)
+
+	_, err := service.ApplyRetention(context.Background(), JobRetentionPolicy{
+		RetentionDays: 30,
+		KeepFailed:    true,
+		Rules: map[models.JobType]JobRetentionRule{
+			models.JobTypeInventoryImport: {KeepFailed: &keepFailed},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ApplyRetention failed: %v", err)
+	}
+
+	remaining := remainingJobIDs(t, db)
+	if remaining[failedImport.ID] {
+		t.Error("expected the rule to let the failed inventory import be deleted")
+	}
+	if !remaining[failedResort.ID] {
+		t.Error("expected the failed resort to be kept by the policy")
+	}
+}
+
+func TestJobService_RetentionPolicy(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	if err := db.AutoMigrate(&models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := context.Background()
+
+	policy := service.RetentionPolicy(ctx)
+	if policy.RetentionDays != DefaultJobCleanupRetentionDays || policy.KeepFailed || len(policy.Rules) != 0 {
+		t.Errorf("expected the default policy, got %+v", policy)
+	}
+
+	settings := settingsStore(db)
+	settings.Set(ctx, "job_cleanup_retention_days", "90")
+	settings.Set(ctx, "job_cleanup_keep_failed", "true")
+	settings.Set(ctx, "job_cleanup_rules", `{"bulk_data_import": {"retention_days": 7, "keep_last": 3}}`)
+
+	policy = service.RetentionPolicy(ctx)
+	if policy.RetentionDays != 90 || !policy.KeepFailed {
+		t.Errorf("expected 90 days keeping failed jobs, got %+v", policy)
+	}
+	if rule := policy.Rules[models.JobTypeBulkDataImport]; rule.RetentionDays != 7 || rule.KeepLast != 3 {
+		t.Errorf("expected the bulk import rule, got %+v", rule)
+	}
+
+	settings.Set(ctx, "job_cleanup_rules", "not json")
+	if policy := service.RetentionPolicy(ctx); len(policy.Rules) != 0 {
+		t.Errorf("expected invalid rules to be ignored, got %+v", policy.Rules)
+	}
+}
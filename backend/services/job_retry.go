@@ -0,0 +1,183 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	scryfall "github.com/BlueMonday/go-scryfall"
+)
+
+const (
+	// DefaultJobRetryMaxAttempts is the default for job_retry_max_attempts, the number of
+	// automatic retries after a transient failure
+	DefaultJobRetryMaxAttempts = 3
+
+	// MaxJobRetryAttempts bounds job_retry_max_attempts
+	MaxJobRetryAttempts = 10
+)
+
+// ErrJobNotRetryable is returned when retrying a job that isn't a failed import
+var ErrJobNotRetryable = errors.New("only a failed bulk data or set data import can be retried")
+
+// DefaultRetryBackoff waits 30 seconds before the first retry, doubling up to 10 minutes
+var DefaultRetryBackoff = RetryBackoff{Base: 30 * time.Second, Max: 10 * time.Minute}
+
+// RetryBackoff is a capped exponential backoff
+type RetryBackoff struct {
+	Base time.Duration // Wait before the first retry
+	Max  time.Duration // Longest wait
+}
+
+// Delay returns the wait before retry attempt (1 for the first retry)
+func (b RetryBackoff) Delay(attempt int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt && delay < b.Max; i++ {
+		delay *= 2
+	}
+	return min(delay, b.Max)
+}
+
+// JobRetry records an automatic retry of a job after a transient failure
+// tygo:export
+type JobRetry struct {
+	Attempt  int       `json:"attempt"` // 1 for the first retry
+	Error    string    `json:"error"`   // The failure that was retried
+	FailedAt time.Time `json:"failed_at"`
+	RetryAt  time.Time `json:"retry_at"`
+}
+
+// httpStatusError is an unexpected HTTP response status from a download
+type httpStatusError struct {
+	what       string
+	statusCode int
+	body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.what, e.statusCode, e.body)
+}
+
+// IsTransient reports whether err is worth retrying: a network error or timeout, a
+// download cut short, or a 5xx or 429 response. Cancellation never is.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return transientStatus(statusErr.statusCode)
+	}
+	var scryfallErr *scryfall.Error
+	if errors.As(err, &scryfallErr) {
+		return transientStatus(scryfallErr.Status)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func transientStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// retryTransient runs run, retrying it after transient failures up to the
+// job_retry_max_attempts setting with backoff between attempts. onRetry is called
+// with each retry before waiting for it.
+func retryTransient(ctx context.Context, settings *SettingsService, backoff RetryBackoff, jobID uint, run func() error, onRetry func(JobRetry)) error {
+	maxAttempts := settings.GetInt(ctx, "job_retry_max_attempts", DefaultJobRetryMaxAttempts)
+
+	err := run()
+	for attempt := 1; attempt <= maxAttempts && IsTransient(err) && ctx.Err() == nil; attempt++ {
+		delay := backoff.Delay(attempt)
+		now := time.Now()
+		message := err.Error()
+		if len(message) > 200 {
+			message = message[:197] + "..."
+		}
+		onRetry(JobRetry{Attempt: attempt, Error: message, FailedAt: now, RetryAt: now.Add(delay)})
+		slog.WarnContext(ctx, "transient job failure, retrying", "job_id", jobID, "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("retry cancelled: %w", ctx.Err())
+		case <-timer.C:
+		}
+		err = run()
+	}
+	return err
+}
+
+// JobRetryFunc runs a stopped job again under the same job, after Retry or a resume on
+// startup has moved it back to pending
+type JobRetryFunc func(ctx context.Context, job *models.Job) error
+
+// RegisterRetry lets failed jobs of jobType be retried through Retry
+func (s *JobService) RegisterRetry(jobType models.JobType, fn JobRetryFunc) {
+	s.retryMu.Lock()
+	defer s.retryMu.Unlock()
+	s.retries[jobType] = fn
+}
+
+// Retry moves a failed job back to pending and runs it again in the background under
+// runCtx, which should outlive ctx. It returns ErrJobNotRetryable unless the job failed
+// and its type registered a retry.
+func (s *JobService) Retry(ctx, runCtx context.Context, id uint) (*models.Job, error) {
+	job, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.retryMu.Lock()
+	fn, ok := s.retries[job.Type]
+	s.retryMu.Unlock()
+	if !ok || job.Status != models.JobStatusFailed {
+		return nil, ErrJobNotRetryable
+	}
+	previousError := job.Error
+
+	result := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("id = ? AND status = ?", id, models.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusPending,
+			"error":        "",
+			"started_at":   nil,
+			"completed_at": nil,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("claiming job %d for retry: %w", id, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Retried by another request in the meantime
+		return nil, ErrJobNotRetryable
+	}
+	s.publishStatus(id, models.JobStatusPending)
+
+	job, err = s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	retried := *job
+	if err := s.Go(runCtx, id, "job retry", func(ctx context.Context) error {
+		// Errors are logged and the job marked as failed by the runner
+		_ = fn(ctx, &retried)
+		return nil
+	}); err != nil {
+		// Not started, so put the job back as it was
+		if failErr := s.Fail(ctx, id, previousError); failErr != nil {
+			slog.ErrorContext(ctx, "failed to restore job after unstarted retry", "job_id", id, "error", failErr)
+		}
+		return nil, err
+	}
+	return job, nil
+}
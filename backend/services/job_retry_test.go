@@ -0,0 +1,152 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	scryfall "github.com/BlueMonday/go-scryfall"
+)
+
+func TestRetryBackoff_Delay(t *testing.T) {
+	backoff := DefaultRetryBackoff
+	expected := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, want := range expected {
+		if got := backoff.Delay(i + 1); got != want {
+			t.Errorf("retry %d: expected %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"cancelled", fmt.Errorf("import cancelled: %w", context.Canceled), false},
+		{"timeout", fmt.Errorf("failed to list sets: %w", context.DeadlineExceeded), true},
+		{"truncated download", fmt.Errorf("failed to decode card: %w", io.ErrUnexpectedEOF), true},
+		{"network", fmt.Errorf("failed to fetch: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{"5xx", &httpStatusError{what: "bulk data list", statusCode: 503}, true},
+		{"429", &httpStatusError{what: "bulk data list", statusCode: 429}, true},
+		{"404", &httpStatusError{what: "bulk data list", statusCode: 404}, false},
+		{"scryfall 5xx", fmt.Errorf("failed to list sets: %w", &scryfall.Error{Status: 500}), true},
+		{"scryfall 400", &scryfall.Error{Status: 400}, false},
+		{"other", errors.New("bulk import failed: 10/100 cards failed"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.transient {
+			t.Errorf("%s: expected transient=%v, got %v", tt.name, tt.transient, got)
+		}
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	_, _, settingsService, _ := setupBulkDataServiceTest(t)
+	ctx := context.Background()
+	backoff := RetryBackoff{Base: time.Millisecond, Max: time.Millisecond}
+	transient := &httpStatusError{what: "download", statusCode: 500}
+
+	// Succeeds on the third attempt
+	runs := 0
+	var retries []JobRetry
+	err := retryTransient(ctx, settingsService, backoff, 1, func() error {
+		runs++
+		if runs < 3 {
+			return transient
+		}
+		return nil
+	}, func(retry JobRetry) { retries = append(retries, retry) })
+	if err != nil || runs != 3 {
+		t.Errorf("expected success after 3 runs, got %v after %d", err, runs)
+	}
+	if len(retries) != 2 || retries[1].Attempt != 2 || retries[1].RetryAt.Before(retries[1].FailedAt) {
+		t.Errorf("expected 2 recorded retries, got %+v", retries)
+	}
+
+	// A permanent failure isn't retried
+	runs = 0
+	err = retryTransient(ctx, settingsService, backoff, 1, func() error {
+		runs++
+		return errors.New("invalid data")
+	}, func(JobRetry) {})
+	if err == nil || runs != 1 {
+		t.Errorf("expected one failed run, got %v after %d", err, runs)
+	}
+
+	// Retries can be turned off
+	settingsService.Set(ctx, "job_retry_max_attempts", "0")
+	runs = 0
+	retryTransient(ctx, settingsService, backoff, 1, func() error {
+		runs++
+		return transient
+	}, func(JobRetry) {})
+	if runs != 1 {
+		t.Errorf("expected no retries, got %d runs", runs)
+	}
+}
+
+func TestRetryTransient_CancelledWhileWaiting(t *testing.T) {
+	_, _, settingsService, _ := setupBulkDataServiceTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := retryTransient(ctx, settingsService, RetryBackoff{Base: time.Hour, Max: time.Hour}, 1, func() error {
+		return &httpStatusError{what: "download", statusCode: 500}
+	}, func(JobRetry) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the wait to end on cancellation, got %v", err)
+	}
+}
+
+func TestJobService_Retry(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	ran := make(chan uint, 1)
+	service.RegisterRetry(models.JobTypeBulkDataImport, func(ctx context.Context, job *models.Job) error {
+		ran <- job.ID
+		return nil
+	})
+
+	failed, _ := service.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	service.Fail(ctx, failed.ID, "bulk data list returned status 503")
+
+	job, err := service.Retry(ctx, ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	if job.Status != models.JobStatusPending || job.Error != "" || job.CompletedAt != nil {
+		t.Errorf("expected the job back to pending, got %+v", job)
+	}
+	select {
+	case id := <-ran:
+		if id != failed.ID {
+			t.Errorf("expected job %d to run, got %d", failed.ID, id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the retry to run")
+	}
+
+	// Only failed jobs of registered types
+	if _, err := service.Retry(ctx, ctx, failed.ID); !errors.Is(err, ErrJobNotRetryable) {
+		t.Errorf("expected a pending job not to be retryable, got %v", err)
+	}
+	resort, _ := service.Create(ctx, models.JobTypeResort, "{}")
+	service.Fail(ctx, resort.ID, "boom")
+	if _, err := service.Retry(ctx, ctx, resort.ID); !errors.Is(err, ErrJobNotRetryable) {
+		t.Errorf("expected a resort not to be retryable, got %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Job{}).Where("status = ?", models.JobStatusFailed).Count(&count)
+	if count != 1 {
+		t.Errorf("expected only the resort to stay failed, got %d failed jobs", count)
+	}
+}
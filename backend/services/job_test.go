@@ -3,6 +3,7 @@ package services
 import (
 	"backend/models"
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -18,7 +19,7 @@ func setupJobServiceTest(t *testing.T) (*JobService, *gorm.DB) {
 		t.Fatalf("failed to setup test db: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Job{}); err != nil {
+	if err := db.AutoMigrate(&models.Job{}, &models.JobResult{}); err != nil {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
@@ -409,6 +410,57 @@ func TestJobService_CleanupOldJobs_NoOldJobs(t *testing.T) {
 	}
 }
 
+func TestJobService_CleanupOldJobs_DeletesResults(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	oldJob, _ := service.Create(ctx, models.JobTypeResort, "{}")
+	db.Model(oldJob).Update("created_at", time.Now().AddDate(0, 0, -31))
+	recentJob, _ := service.Create(ctx, models.JobTypeResort, "{}")
+	for _, job := range []*models.Job{oldJob, recentJob} {
+		if err := service.SaveResult(ctx, job.ID, map[string]int{"processed": 1}); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+	}
+
+	if _, err := service.CleanupOldJobs(ctx, 30); err != nil {
+		t.Fatalf("CleanupOldJobs failed: %v", err)
+	}
+
+	if _, err := service.Result(ctx, oldJob.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected the old job's result to be deleted, got %v", err)
+	}
+	if _, err := service.Result(ctx, recentJob.ID); err != nil {
+		t.Errorf("expected the recent job's result to remain, got %v", err)
+	}
+}
+
+// Result tests
+
+func TestJobService_SaveResult_Replaces(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeResort, "{}")
+	if _, err := service.Result(ctx, job.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected no result before one is saved, got %v", err)
+	}
+
+	for _, processed := range []int{1, 2} {
+		if err := service.SaveResult(ctx, job.ID, map[string]int{"processed": processed}); err != nil {
+			t.Fatalf("SaveResult failed: %v", err)
+		}
+	}
+
+	result, err := service.Result(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Result failed: %v", err)
+	}
+	if string(result) != `{"processed":2}` {
+		t.Errorf("expected the latest result, got %s", result)
+	}
+}
+
 // CancelStaleJobs tests
 
 func TestJobService_CancelStaleJobs_Success(t *testing.T) {
@@ -514,3 +566,81 @@ func TestJobService_GetLastJobByType_NotFound(t *testing.T) {
 		t.Error("expected nil job when none exist")
 	}
 }
+
+// Cancel tests
+
+func TestJobService_Cancel_RunningJob(t *testing.T) {
+	service, db := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	jobCtx, release := service.Cancellable(ctx, job.ID)
+	defer release()
+	service.Start(jobCtx, job.ID)
+
+	cancelled, err := service.Cancel(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if cancelled.Status != models.JobStatusCancelled {
+		t.Errorf("expected status %s, got %s", models.JobStatusCancelled, cancelled.Status)
+	}
+	if cancelled.CompletedAt == nil {
+		t.Error("expected completed_at to be set")
+	}
+
+	select {
+	case <-jobCtx.Done():
+	default:
+		t.Fatal("expected the job's context to be cancelled")
+	}
+
+	// The job's own failure handling must not overwrite the cancellation
+	if err := service.Fail(ctx, job.ID, "context canceled"); err != nil {
+		t.Fatalf("Fail failed: %v", err)
+	}
+	var updated models.Job
+	db.First(&updated, job.ID)
+	if updated.Status != models.JobStatusCancelled || updated.Error != "Cancelled by user" {
+		t.Errorf("expected cancellation to stick, got %s (%q)", updated.Status, updated.Error)
+	}
+}
+
+func TestJobService_Cancel_PendingJobNeverStarts(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	if _, err := service.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	service.Start(ctx, job.ID)
+	service.Complete(ctx, job.ID)
+
+	updated, _ := service.Get(ctx, job.ID)
+	if updated.Status != models.JobStatusCancelled {
+		t.Errorf("expected status %s, got %s", models.JobStatusCancelled, updated.Status)
+	}
+}
+
+func TestJobService_Cancel_FinishedJob(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "{}")
+	service.Start(ctx, job.ID)
+	service.Complete(ctx, job.ID)
+
+	if _, err := service.Cancel(ctx, job.ID); !errors.Is(err, ErrJobNotCancellable) {
+		t.Errorf("expected ErrJobNotCancellable, got %v", err)
+	}
+}
+
+func TestJobService_Cancel_NotFound(t *testing.T) {
+	service, _ := setupJobServiceTest(t)
+
+	if _, err := service.Cancel(context.Background(), 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
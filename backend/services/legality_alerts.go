@@ -0,0 +1,191 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ownedLegalitiesQuery selects one printing's legalities per owned card.
+// Legalities are the same for every printing of a card, so any row per oracle ID will do.
+const ownedLegalitiesQuery = `
+	SELECT oracle_id,
+		COALESCE(name, '') AS name,
+		COALESCE(json_extract(raw_json, '$.legalities'), '{}') AS legalities
+	FROM cards
+	WHERE oracle_id IN (SELECT DISTINCT oracle_id FROM inventories WHERE deleted_at IS NULL)
+	GROUP BY oracle_id`
+
+// formatDisplayNames holds names that are not just the capitalised format key
+var formatDisplayNames = map[string]string{
+	"paupercommander": "Pauper Commander",
+	"standardbrawl":   "Standard Brawl",
+	"oldschool":       "Old School",
+	"premodern":       "Premodern",
+	"predh":           "PreDH",
+	"duel":            "Duel Commander",
+}
+
+// FormatDisplayName returns a human-readable name for a Scryfall format key
+func FormatDisplayName(format string) string {
+	if name, ok := formatDisplayNames[format]; ok {
+		return name
+	}
+	if format == "" {
+		return format
+	}
+	return strings.ToUpper(format[:1]) + format[1:]
+}
+
+// ownedCardLegalities is an owned card's name and status per format
+type ownedCardLegalities struct {
+	Name       string
+	Legalities map[string]string
+}
+
+// LegalitySnapshot maps oracle ID to the legalities of an owned card at a point in time
+type LegalitySnapshot map[string]ownedCardLegalities
+
+// LegalityAlertService detects ban and restriction changes affecting owned cards
+type LegalityAlertService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+}
+
+// NewLegalityAlertService creates a new legality alert service
+func NewLegalityAlertService(db *gorm.DB, notifications *NotificationService) *LegalityAlertService {
+	return &LegalityAlertService{db: db, notifications: notifications}
+}
+
+// Snapshot captures the current legalities of every owned card
+func (s *LegalityAlertService) Snapshot(ctx context.Context) (LegalitySnapshot, error) {
+	var rows []struct {
+		OracleID   string
+		Name       string
+		Legalities string
+	}
+	if err := s.db.WithContext(ctx).Raw(ownedLegalitiesQuery).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("loading owned card legalities: %w", err)
+	}
+
+	snapshot := make(LegalitySnapshot, len(rows))
+	for _, row := range rows {
+		var legalities map[string]string
+		if err := json.Unmarshal([]byte(row.Legalities), &legalities); err != nil {
+			slog.WarnContext(ctx, "skipping card with invalid legalities", "component", "legality_alerts", "oracle_id", row.OracleID, "error", err)
+			continue
+		}
+		snapshot[row.OracleID] = ownedCardLegalities{Name: row.Name, Legalities: legalities}
+	}
+	return snapshot, nil
+}
+
+// isBanOrRestriction reports whether a status change is worth alerting on.
+// Only bans, restrictions, and their reversals count; legal/not_legal flips
+// come from rotation or new formats and would drown out the useful alerts.
+func isBanOrRestriction(previous, current string) bool {
+	if previous == current {
+		return false
+	}
+	watched := []string{"banned", "restricted"}
+	return slices.Contains(watched, previous) || slices.Contains(watched, current)
+}
+
+// legalityChangeMessage describes a change, e.g. "Card X is now banned in Modern"
+func legalityChangeMessage(change models.LegalityChange) string {
+	format := FormatDisplayName(change.Format)
+	switch change.Status {
+	case "banned", "restricted":
+		return fmt.Sprintf("%s is now %s in %s", change.CardName, change.Status, format)
+	case "legal":
+		return fmt.Sprintf("%s is now legal in %s (was %s)", change.CardName, format, change.PreviousStatus)
+	default:
+		return fmt.Sprintf("%s is no longer %s in %s", change.CardName, change.PreviousStatus, format)
+	}
+}
+
+// DetectChanges compares owned cards' current legalities against an earlier
+// snapshot, records each ban or restriction change, and raises a notification
+// for it. Cards that were not owned at snapshot time are ignored.
+func (s *LegalityAlertService) DetectChanges(ctx context.Context, before LegalitySnapshot) ([]models.LegalityChange, error) {
+	after, err := s.Snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []models.LegalityChange{}
+	for oracleID, current := range after {
+		previous, ok := before[oracleID]
+		if !ok {
+			continue
+		}
+		for format, status := range current.Legalities {
+			previousStatus, ok := previous.Legalities[format]
+			if !ok || !isBanOrRestriction(previousStatus, status) {
+				continue
+			}
+			changes = append(changes, models.LegalityChange{
+				OracleID:       oracleID,
+				CardName:       current.Name,
+				Format:         format,
+				PreviousStatus: previousStatus,
+				Status:         status,
+			})
+		}
+	}
+
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	slices.SortFunc(changes, func(a, b models.LegalityChange) int {
+		if c := strings.Compare(a.CardName, b.CardName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Format, b.Format)
+	})
+
+	if err := s.db.WithContext(ctx).Create(&changes).Error; err != nil {
+		return nil, fmt.Errorf("recording legality changes: %w", err)
+	}
+
+	for _, change := range changes {
+		title := legalityChangeMessage(change)
+		message := fmt.Sprintf("Was %s in %s before the latest card data update.", strings.ReplaceAll(change.PreviousStatus, "_", " "), FormatDisplayName(change.Format))
+		if _, err := s.notifications.Create(ctx, models.NotificationTypeLegalityChange, title, message); err != nil {
+			slog.WarnContext(ctx, "failed to create legality notification", "component", "legality_alerts", "oracle_id", change.OracleID, "error", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "legality changes detected", "component", "legality_alerts", "count", len(changes))
+	return changes, nil
+}
+
+// List retrieves recorded legality changes with pagination, newest first,
+// optionally limited to one format
+func (s *LegalityAlertService) List(ctx context.Context, page, pageSize int, format string) ([]models.LegalityChange, int64, error) {
+	var changes []models.LegalityChange
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.LegalityChange{})
+	if format != "" {
+		query = query.Where("format = ?", format)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting legality changes: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Limit(pageSize).Offset(offset).Find(&changes).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing legality changes: %w", err)
+	}
+
+	return changes, total, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupLegalityAlertTest(t *testing.T) (*gorm.DB, *LegalityAlertService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	return db, NewLegalityAlertService(db, NewNotificationService(db))
+}
+
+// setLegalities creates or replaces a card's printing with the given legalities
+func setLegalities(t *testing.T, db *gorm.DB, scryfallID, oracleID, name, legalities string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: scryfallID,
+		OracleID:   oracleID,
+		RawJSON:    fmt.Sprintf(`{"name":%q,"legalities":%s}`, name, legalities),
+	}
+	if err := db.Save(&card).Error; err != nil {
+		t.Fatalf("failed to save card: %v", err)
+	}
+}
+
+func ownCard(t *testing.T, db *gorm.DB, scryfallID, oracleID string) {
+	t.Helper()
+	item := models.Inventory{ScryfallID: scryfallID, OracleID: oracleID, Treatment: "nonfoil", Quantity: 1}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+}
+
+func TestLegalityAlertService_DetectChanges(t *testing.T) {
+	db, service := setupLegalityAlertTest(t)
+	ctx := context.Background()
+
+	setLegalities(t, db, "bolt", "oracle-bolt", "Lightning Bolt", `{"modern":"legal","vintage":"legal","standard":"legal"}`)
+	setLegalities(t, db, "ring", "oracle-ring", "Sol Ring", `{"vintage":"restricted"}`)
+	setLegalities(t, db, "unowned", "oracle-unowned", "Unowned Card", `{"modern":"legal"}`)
+	ownCard(t, db, "bolt", "oracle-bolt")
+	ownCard(t, db, "ring", "oracle-ring")
+
+	before, err := service.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if len(before) != 2 {
+		t.Fatalf("expected 2 owned cards in snapshot, got %d", len(before))
+	}
+
+	// Bolt is banned in Modern and rotates out of Standard; Sol Ring is unrestricted
+	setLegalities(t, db, "bolt", "oracle-bolt", "Lightning Bolt", `{"modern":"banned","vintage":"legal","standard":"not_legal"}`)
+	setLegalities(t, db, "ring", "oracle-ring", "Sol Ring", `{"vintage":"legal"}`)
+	setLegalities(t, db, "unowned", "oracle-unowned", "Unowned Card", `{"modern":"banned"}`)
+
+	changes, err := service.DetectChanges(ctx, before)
+	if err != nil {
+		t.Fatalf("DetectChanges failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes (rotation and unowned cards ignored), got %d: %+v", len(changes), changes)
+	}
+	if changes[0].CardName != "Lightning Bolt" || changes[0].Format != "modern" || changes[0].Status != "banned" {
+		t.Errorf("unexpected first change: %+v", changes[0])
+	}
+	if changes[1].CardName != "Sol Ring" || changes[1].PreviousStatus != "restricted" || changes[1].Status != "legal" {
+		t.Errorf("unexpected second change: %+v", changes[1])
+	}
+
+	var notifications []models.Notification
+	if err := db.Order("id ASC").Find(&notifications).Error; err != nil {
+		t.Fatalf("failed to load notifications: %v", err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(notifications))
+	}
+	if notifications[0].Title != "Lightning Bolt is now banned in Modern" {
+		t.Errorf("unexpected notification title %q", notifications[0].Title)
+	}
+	if notifications[0].Type != models.NotificationTypeLegalityChange {
+		t.Errorf("expected type %s, got %s", models.NotificationTypeLegalityChange, notifications[0].Type)
+	}
+}
+
+func TestLegalityAlertService_DetectChanges_NoChanges(t *testing.T) {
+	db, service := setupLegalityAlertTest(t)
+	ctx := context.Background()
+
+	setLegalities(t, db, "bolt", "oracle-bolt", "Lightning Bolt", `{"modern":"legal"}`)
+	ownCard(t, db, "bolt", "oracle-bolt")
+
+	before, err := service.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	changes, err := service.DetectChanges(ctx, before)
+	if err != nil {
+		t.Fatalf("DetectChanges failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %d", len(changes))
+	}
+
+	var count int64
+	db.Model(&models.Notification{}).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no notifications, got %d", count)
+	}
+}
+
+func TestLegalityAlertService_List_FormatFilter(t *testing.T) {
+	db, service := setupLegalityAlertTest(t)
+	ctx := context.Background()
+
+	for _, format := range []string{"modern", "legacy", "modern"} {
+		change := models.LegalityChange{OracleID: "o1", CardName: "Card", Format: format, PreviousStatus: "legal", Status: "banned"}
+		if err := db.Create(&change).Error; err != nil {
+			t.Fatalf("failed to create change: %v", err)
+		}
+	}
+
+	changes, total, err := service.List(ctx, 1, 20, "modern")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 || len(changes) != 2 {
+		t.Errorf("expected 2 modern changes, got total=%d len=%d", total, len(changes))
+	}
+	if len(changes) == 2 && changes[0].ID < changes[1].ID {
+		t.Error("expected newest changes first")
+	}
+}
+
+func TestFormatDisplayName(t *testing.T) {
+	tests := map[string]string{
+		"modern":          "Modern",
+		"paupercommander": "Pauper Commander",
+		"":                "",
+	}
+	for format, expected := range tests {
+		if got := FormatDisplayName(format); got != expected {
+			t.Errorf("FormatDisplayName(%q) = %q, want %q", format, got, expected)
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package services
+
+import (
+	"backend/models"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// minTribalCreatures is how many creatures must share a subtype before it is suggested as a tribe
+const minTribalCreatures = 4
+
+// colorTagNames maps a WUBRG-ordered color set to its common name
+var colorTagNames = map[string]string{
+	"":      "colorless",
+	"W":     "mono-white",
+	"U":     "mono-blue",
+	"B":     "mono-black",
+	"R":     "mono-red",
+	"G":     "mono-green",
+	"WU":    "azorius",
+	"UB":    "dimir",
+	"BR":    "rakdos",
+	"RG":    "gruul",
+	"WG":    "selesnya",
+	"WB":    "orzhov",
+	"UR":    "izzet",
+	"BG":    "golgari",
+	"WR":    "boros",
+	"UG":    "simic",
+	"WUG":   "bant",
+	"WUB":   "esper",
+	"UBR":   "grixis",
+	"BRG":   "jund",
+	"WRG":   "naya",
+	"WBG":   "abzan",
+	"WUR":   "jeskai",
+	"UBG":   "sultai",
+	"WBR":   "mardu",
+	"URG":   "temur",
+	"WUBRG": "five-color",
+}
+
+// ArchetypeSuggestion is a tag suggested for a list, with the evidence behind it
+// tygo:export
+type ArchetypeSuggestion struct {
+	Tag    string  `json:"tag"`
+	Score  float64 `json:"score"` // 0-1, how strongly the list fits the tag
+	Reason string  `json:"reason"`
+}
+
+// ListOverlap summarises how much another list shares with the analysed list
+// tygo:export
+type ListOverlap struct {
+	ListID      uint    `json:"list_id"`
+	ListName    string  `json:"list_name"`
+	SharedCards int     `json:"shared_cards"` // Distinct cards in both lists
+	Similarity  float64 `json:"similarity"`   // Shared cards over distinct cards across both lists
+}
+
+// CardDemand is how many copies one list wants of a shared card
+// tygo:export
+type CardDemand struct {
+	ListID   uint   `json:"list_id"`
+	ListName string `json:"list_name"`
+	Quantity int    `json:"quantity"`
+}
+
+// SharedCard is a card wanted by the analysed list and at least one other list.
+// Contended cards are owned, but not in enough copies to cover every list at once.
+// tygo:export
+type SharedCard struct {
+	OracleID  string       `json:"oracle_id"`
+	Name      string       `json:"name"`
+	Owned     int          `json:"owned"`
+	Needed    int          `json:"needed"`
+	Contended bool         `json:"contended"`
+	Lists     []CardDemand `json:"lists"`
+}
+
+// ListAnalysis is the composition analysis of a single list
+// tygo:export
+type ListAnalysis struct {
+	ListID      uint                  `json:"list_id"`
+	Suggestions []ArchetypeSuggestion `json:"suggestions"`
+	Similar     []ListOverlap         `json:"similar"`
+	SharedCards []SharedCard          `json:"shared_cards"`
+}
+
+// listAnalysisCard is one list item joined with the card fields the analysis needs
+type listAnalysisCard struct {
+	OracleID        string
+	DesiredQuantity int
+	Colors          string
+	CMC             float64
+	TypeLine        string
+}
+
+// listDemandRow is one list's demand for a card, across all lists
+type listDemandRow struct {
+	ListID   uint
+	ListName string
+	OracleID string
+	Quantity int
+}
+
+// ListAnalysisService compares a list with other lists and inventory
+type ListAnalysisService struct {
+	db *gorm.DB
+}
+
+// NewListAnalysisService creates a new list analysis service
+func NewListAnalysisService(db *gorm.DB) *ListAnalysisService {
+	return &ListAnalysisService{db: db}
+}
+
+// Analyze suggests archetype tags for a list from its colors, curve, and card types,
+// ranks other lists by card overlap, and reports cards the list shares with other
+// lists along with whether the owned copies can cover all of them at once.
+func (s *ListAnalysisService) Analyze(ctx context.Context, listID uint) (ListAnalysis, error) {
+	analysis := ListAnalysis{
+		ListID:      listID,
+		Suggestions: []ArchetypeSuggestion{},
+		Similar:     []ListOverlap{},
+		SharedCards: []SharedCard{},
+	}
+
+	var cards []listAnalysisCard
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT li.oracle_id, li.desired_quantity,
+			COALESCE(c.colors, '') AS colors,
+			COALESCE(c.cmc, 0) AS cmc,
+			COALESCE(json_extract(c.raw_json, '$.type_line'), json_extract(c.raw_json, '$.card_faces[0].type_line'), '') AS type_line
+		FROM list_items li
+		LEFT JOIN cards c ON c.scryfall_id = li.scryfall_id
+		WHERE li.list_id = ?`, listID).Scan(&cards).Error; err != nil {
+		return analysis, fmt.Errorf("loading list cards: %w", err)
+	}
+	if len(cards) == 0 {
+		return analysis, nil
+	}
+
+	analysis.Suggestions = suggestArchetypes(cards)
+
+	var demand []listDemandRow
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT l.id AS list_id, l.name AS list_name, li.oracle_id, SUM(li.desired_quantity) AS quantity
+		FROM list_items li
+		JOIN lists l ON l.id = li.list_id
+		WHERE li.oracle_id IN (SELECT oracle_id FROM list_items WHERE list_id = ?)
+		GROUP BY l.id, li.oracle_id`, listID).Scan(&demand).Error; err != nil {
+		return analysis, fmt.Errorf("loading list demand: %w", err)
+	}
+
+	var otherTotals []struct {
+		ListID uint
+		Total  int
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT list_id, COUNT(DISTINCT oracle_id) AS total
+		FROM list_items
+		WHERE list_id != ?
+		GROUP BY list_id`, listID).Scan(&otherTotals).Error; err != nil {
+		return analysis, fmt.Errorf("counting list cards: %w", err)
+	}
+	distinctByList := make(map[uint]int, len(otherTotals))
+	for _, row := range otherTotals {
+		distinctByList[row.ListID] = row.Total
+	}
+
+	ownCards := map[string]bool{}
+	for _, card := range cards {
+		ownCards[card.OracleID] = true
+	}
+	analysis.Similar = rankOverlaps(listID, len(ownCards), demand, distinctByList)
+
+	shared, err := s.sharedCards(ctx, listID, demand)
+	if err != nil {
+		return analysis, err
+	}
+	analysis.SharedCards = shared
+
+	return analysis, nil
+}
+
+// rankOverlaps turns per-card demand into per-list overlap, most similar first
+func rankOverlaps(listID uint, ownDistinct int, demand []listDemandRow, distinctByList map[uint]int) []ListOverlap {
+	byList := map[uint]*ListOverlap{}
+	for _, row := range demand {
+		if row.ListID == listID {
+			continue
+		}
+		overlap, ok := byList[row.ListID]
+		if !ok {
+			overlap = &ListOverlap{ListID: row.ListID, ListName: row.ListName}
+			byList[row.ListID] = overlap
+		}
+		overlap.SharedCards++
+	}
+
+	overlaps := make([]ListOverlap, 0, len(byList))
+	for id, overlap := range byList {
+		union := ownDistinct + distinctByList[id] - overlap.SharedCards
+		if union > 0 {
+			overlap.Similarity = roundScore(float64(overlap.SharedCards) / float64(union))
+		}
+		overlaps = append(overlaps, *overlap)
+	}
+	slices.SortFunc(overlaps, func(a, b ListOverlap) int {
+		if c := cmp.Compare(b.Similarity, a.Similarity); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ListID, b.ListID)
+	})
+	return overlaps
+}
+
+// sharedCards reports cards wanted by this list and at least one other, with owned copies
+func (s *ListAnalysisService) sharedCards(ctx context.Context, listID uint, demand []listDemandRow) ([]SharedCard, error) {
+	byCard := map[string][]CardDemand{}
+	for _, row := range demand {
+		byCard[row.OracleID] = append(byCard[row.OracleID], CardDemand{ListID: row.ListID, ListName: row.ListName, Quantity: row.Quantity})
+	}
+
+	oracleIDs := []string{}
+	for oracleID, lists := range byCard {
+		if len(lists) > 1 {
+			oracleIDs = append(oracleIDs, oracleID)
+		}
+	}
+	if len(oracleIDs) == 0 {
+		return []SharedCard{}, nil
+	}
+
+	ownedByCard, err := s.ownedByOracle(ctx, oracleIDs)
+	if err != nil {
+		return nil, err
+	}
+	nameByCard, err := s.namesByOracle(ctx, oracleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	shared := make([]SharedCard, 0, len(oracleIDs))
+	for _, oracleID := range oracleIDs {
+		card := SharedCard{
+			OracleID: oracleID,
+			Name:     nameByCard[oracleID],
+			Owned:    ownedByCard[oracleID],
+			Lists:    byCard[oracleID],
+		}
+		for _, d := range card.Lists {
+			card.Needed += d.Quantity
+		}
+		card.Contended = card.Owned > 0 && card.Owned < card.Needed
+		slices.SortFunc(card.Lists, func(a, b CardDemand) int {
+			if a.ListID == listID {
+				return -1
+			}
+			if b.ListID == listID {
+				return 1
+			}
+			return cmp.Compare(a.ListID, b.ListID)
+		})
+		shared = append(shared, card)
+	}
+
+	// Contended cards first, then by name
+	slices.SortFunc(shared, func(a, b SharedCard) int {
+		if a.Contended != b.Contended {
+			if a.Contended {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.OracleID, b.OracleID))
+	})
+	return shared, nil
+}
+
+// ownedByOracle sums owned copies of each card across all printings, leaving out
+// copies on loan
+func (s *ListAnalysisService) ownedByOracle(ctx context.Context, oracleIDs []string) (map[string]int, error) {
+	var owned []struct {
+		OracleID string
+		Quantity int
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT i.oracle_id, SUM(i.quantity - COALESCE(lent.quantity, 0)) AS quantity
+		FROM inventories i
+		LEFT JOIN (?) AS lent ON lent.inventory_id = i.id
+		WHERE i.oracle_id IN ? AND i.deleted_at IS NULL
+		GROUP BY i.oracle_id`, models.ActiveLoanQuantities(s.db), oracleIDs).Scan(&owned).Error; err != nil {
+		return nil, fmt.Errorf("loading owned quantities: %w", err)
+	}
+	ownedByCard := make(map[string]int, len(owned))
+	for _, row := range owned {
+		ownedByCard[row.OracleID] = row.Quantity
+	}
+	return ownedByCard, nil
+}
+
+// namesByOracle looks up a display name for each card
+func (s *ListAnalysisService) namesByOracle(ctx context.Context, oracleIDs []string) (map[string]string, error) {
+	var names []struct {
+		OracleID string
+		Name     string
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT oracle_id, COALESCE(name, '') AS name
+		FROM cards
+		WHERE oracle_id IN ?
+		GROUP BY oracle_id`, oracleIDs).Scan(&names).Error; err != nil {
+		return nil, fmt.Errorf("loading card names: %w", err)
+	}
+	nameByCard := make(map[string]string, len(names))
+	for _, row := range names {
+		nameByCard[row.OracleID] = row.Name
+	}
+	return nameByCard, nil
+}
+
+// suggestArchetypes derives tags from a list's colors, mana curve, and card types.
+// Lands are left out, since they say little about strategy.
+func suggestArchetypes(cards []listAnalysisCard) []ArchetypeSuggestion {
+	var spells, creatures, instantsSorceries int
+	var totalCMC float64
+	colorCounts := map[rune]int{}
+	subtypes := map[string]int{}
+	// Races come before classes in type lines ("Elf Druid"), so ties go to the earlier subtype
+	subtypePositions := map[string]int{}
+
+	for _, card := range cards {
+		if front, _, _ := strings.Cut(card.TypeLine, "//"); strings.Contains(front, "Land") {
+			continue
+		}
+		qty := card.DesiredQuantity
+		spells += qty
+		totalCMC += card.CMC * float64(qty)
+		for _, color := range card.Colors {
+			colorCounts[color] += qty
+		}
+		if strings.Contains(card.TypeLine, "Creature") {
+			creatures += qty
+			for i, subtype := range creatureSubtypes(card.TypeLine) {
+				subtypes[subtype] += qty
+				if pos, ok := subtypePositions[subtype]; !ok || i < pos {
+					subtypePositions[subtype] = i
+				}
+			}
+		}
+		if strings.Contains(card.TypeLine, "Instant") || strings.Contains(card.TypeLine, "Sorcery") {
+			instantsSorceries += qty
+		}
+	}
+
+	suggestions := []ArchetypeSuggestion{}
+	if spells == 0 {
+		return suggestions
+	}
+
+	// Colors that make up at least a tenth of the colored spells; splashes below that are ignored
+	var colors strings.Builder
+	colored := 0
+	for _, count := range colorCounts {
+		colored += count
+	}
+	for _, color := range "WUBRG" {
+		if count := colorCounts[color]; count > 0 && count*10 >= colored {
+			colors.WriteRune(color)
+		}
+	}
+	if tag, ok := colorTagNames[colors.String()]; ok {
+		suggestions = append(suggestions, ArchetypeSuggestion{Tag: tag, Score: 1, Reason: fmt.Sprintf("main colors: %s", cmp.Or(colors.String(), "none"))})
+	} else {
+		suggestions = append(suggestions, ArchetypeSuggestion{Tag: "four-color", Score: 1, Reason: fmt.Sprintf("main colors: %s", colors.String())})
+	}
+
+	avgCMC := totalCMC / float64(spells)
+	creatureShare := float64(creatures) / float64(spells)
+	spellShare := float64(instantsSorceries) / float64(spells)
+
+	switch {
+	case creatureShare >= 0.5 && avgCMC <= 2.5:
+		suggestions = append(suggestions, ArchetypeSuggestion{
+			Tag: "aggro", Score: roundScore(creatureShare),
+			Reason: fmt.Sprintf("%.0f%% creatures with an average mana value of %.1f", creatureShare*100, avgCMC),
+		})
+	case spellShare >= 0.4 && creatureShare < 0.3:
+		suggestions = append(suggestions, ArchetypeSuggestion{
+			Tag: "control", Score: roundScore(spellShare),
+			Reason: fmt.Sprintf("%.0f%% instants and sorceries, %.0f%% creatures", spellShare*100, creatureShare*100),
+		})
+	case creatureShare >= 0.3:
+		suggestions = append(suggestions, ArchetypeSuggestion{
+			Tag: "midrange", Score: roundScore(creatureShare),
+			Reason: fmt.Sprintf("%.0f%% creatures with an average mana value of %.1f", creatureShare*100, avgCMC),
+		})
+	}
+	if spellShare >= 0.4 && creatureShare >= 0.3 {
+		suggestions = append(suggestions, ArchetypeSuggestion{
+			Tag: "spells", Score: roundScore(spellShare),
+			Reason: fmt.Sprintf("%.0f%% instants and sorceries", spellShare*100),
+		})
+	}
+
+	// Tribal when one creature type dominates
+	var tribe string
+	for subtype, count := range subtypes {
+		if tribe == "" || count > subtypes[tribe] ||
+			(count == subtypes[tribe] && cmp.Or(cmp.Compare(subtypePositions[subtype], subtypePositions[tribe]), strings.Compare(subtype, tribe)) < 0) {
+			tribe = subtype
+		}
+	}
+	if tribe != "" && creatures > 0 {
+		share := float64(subtypes[tribe]) / float64(creatures)
+		if subtypes[tribe] >= minTribalCreatures && share >= 0.5 {
+			suggestions = append(suggestions, ArchetypeSuggestion{
+				Tag: strings.ToLower(tribe) + " tribal", Score: roundScore(share),
+				Reason: fmt.Sprintf("%d of %d creatures are %s", subtypes[tribe], creatures, tribe),
+			})
+		}
+	}
+
+	return suggestions
+}
+
+// creatureSubtypes returns the subtypes of a creature type line, e.g. "Elf Druid"
+// from "Creature — Elf Druid". Only the front face of multi-faced cards is used.
+func creatureSubtypes(typeLine string) []string {
+	front, _, _ := strings.Cut(typeLine, "//")
+	_, subtypes, found := strings.Cut(front, "—")
+	if !found {
+		return nil
+	}
+	return strings.Fields(subtypes)
+}
+
+// roundScore rounds a 0-1 score to two decimal places
+func roundScore(score float64) float64 {
+	return float64(int(score*100+0.5)) / 100
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupListAnalysisTest(t *testing.T) (*gorm.DB, *ListAnalysisService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	return db, NewListAnalysisService(db)
+}
+
+func createAnalysisCard(t *testing.T, db *gorm.DB, id, name, typeLine string, cmc float64, colors string) {
+	t.Helper()
+	raw := fmt.Sprintf(`{"name": %q, "type_line": %q, "cmc": %v, "colors": %s, "rarity": "common"}`, name, typeLine, cmc, colors)
+	if err := db.Create(&models.Card{ScryfallID: id, OracleID: "oracle-" + id, RawJSON: raw}).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func createAnalysisList(t *testing.T, db *gorm.DB, name string, items map[string]int) models.List {
+	t.Helper()
+	list := models.List{Name: name}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	}
+	for id, qty := range items {
+		item := models.ListItem{ListID: list.ID, ScryfallID: id, OracleID: "oracle-" + id, DesiredQuantity: qty}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatalf("failed to create list item: %v", err)
+		}
+	}
+	return list
+}
+
+func TestListAnalysisService_Suggestions(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+
+	createAnalysisCard(t, db, "elf-1", "Llanowar Elves", "Creature — Elf Druid", 1, `["G"]`)
+	createAnalysisCard(t, db, "elf-2", "Elvish Mystic", "Creature — Elf Druid", 1, `["G"]`)
+	createAnalysisCard(t, db, "elf-3", "Elvish Archdruid", "Creature — Elf Druid", 3, `["G"]`)
+	createAnalysisCard(t, db, "giant-growth", "Giant Growth", "Instant", 1, `["G"]`)
+	createAnalysisCard(t, db, "forest", "Forest", "Basic Land — Forest", 0, `[]`)
+
+	list := createAnalysisList(t, db, "Elves", map[string]int{
+		"elf-1": 4, "elf-2": 4, "elf-3": 2, "giant-growth": 2, "forest": 20,
+	})
+
+	analysis, err := service.Analyze(context.Background(), list.ID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	tags := map[string]ArchetypeSuggestion{}
+	for _, s := range analysis.Suggestions {
+		tags[s.Tag] = s
+	}
+	for _, expected := range []string{"mono-green", "aggro", "elf tribal"} {
+		if _, ok := tags[expected]; !ok {
+			t.Errorf("expected %q suggestion, got %+v", expected, analysis.Suggestions)
+		}
+	}
+	if _, ok := tags["control"]; ok {
+		t.Error("did not expect a control suggestion")
+	}
+}
+
+func TestListAnalysisService_OverlapAndContention(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+
+	createAnalysisCard(t, db, "bolt", "Lightning Bolt", "Instant", 1, `["R"]`)
+	createAnalysisCard(t, db, "helix", "Lightning Helix", "Instant", 2, `["R","W"]`)
+	createAnalysisCard(t, db, "goyf", "Tarmogoyf", "Creature — Lhurgoyf", 2, `["G"]`)
+	createAnalysisCard(t, db, "sol-ring", "Sol Ring", "Artifact", 1, `[]`)
+
+	burn := createAnalysisList(t, db, "Burn", map[string]int{"bolt": 4, "helix": 4})
+	jund := createAnalysisList(t, db, "Jund", map[string]int{"bolt": 2, "goyf": 4})
+	boros := createAnalysisList(t, db, "Boros", map[string]int{"bolt": 1, "helix": 1})
+	createAnalysisList(t, db, "Commander", map[string]int{"sol-ring": 1})
+
+	// Five bolts cover Burn but not Burn, Jund, and Boros together; no helixes are owned
+	if err := db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 5}).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	analysis, err := service.Analyze(context.Background(), burn.ID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if len(analysis.Similar) != 2 {
+		t.Fatalf("expected 2 overlapping lists, got %+v", analysis.Similar)
+	}
+	if analysis.Similar[0].ListID != boros.ID || analysis.Similar[0].Similarity != 1 {
+		t.Errorf("expected Boros to be the closest match, got %+v", analysis.Similar[0])
+	}
+	if analysis.Similar[1].ListID != jund.ID || analysis.Similar[1].SharedCards != 1 {
+		t.Errorf("expected Jund to share one card, got %+v", analysis.Similar[1])
+	}
+
+	if len(analysis.SharedCards) != 2 {
+		t.Fatalf("expected 2 shared cards, got %+v", analysis.SharedCards)
+	}
+	bolt := analysis.SharedCards[0]
+	if bolt.Name != "Lightning Bolt" || !bolt.Contended || bolt.Owned != 5 || bolt.Needed != 7 {
+		t.Errorf("expected contended Lightning Bolt first, got %+v", bolt)
+	}
+	if len(bolt.Lists) != 3 || bolt.Lists[0].ListID != burn.ID {
+		t.Errorf("expected the analysed list's demand first, got %+v", bolt.Lists)
+	}
+	helix := analysis.SharedCards[1]
+	if helix.Contended {
+		t.Error("expected unowned card not to be flagged as contended")
+	}
+}
+
+func TestListAnalysisService_EmptyList(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+	list := createAnalysisList(t, db, "Empty", nil)
+
+	analysis, err := service.Analyze(context.Background(), list.ID)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(analysis.Suggestions) != 0 || len(analysis.Similar) != 0 || len(analysis.SharedCards) != 0 {
+		t.Errorf("expected an empty analysis, got %+v", analysis)
+	}
+}
+
+func TestCreatureSubtypes(t *testing.T) {
+	tests := []struct {
+		typeLine string
+		expected string
+	}{
+		{"Creature — Elf Druid", "[Elf Druid]"},
+		{"Legendary Creature — Human Wizard // Legendary Planeswalker — Jace", "[Human Wizard]"},
+		{"Instant", "[]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(creatureSubtypes(tt.typeLine)); got != tt.expected {
+			t.Errorf("creatureSubtypes(%q) = %s, expected %s", tt.typeLine, got, tt.expected)
+		}
+	}
+}
+
+func TestListAnalysisService_Contention(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+
+	createAnalysisCard(t, db, "bolt", "Lightning Bolt", "Instant", 1, `["R"]`)
+	createAnalysisCard(t, db, "thoughtseize", "Thoughtseize", "Sorcery", 1, `["B"]`)
+	createAnalysisCard(t, db, "forest", "Forest", "Basic Land — Forest", 0, `[]`)
+
+	burn := createAnalysisList(t, db, "Burn", map[string]int{"bolt": 4, "forest": 1})
+	jund := createAnalysisList(t, db, "Jund", map[string]int{"bolt": 2, "thoughtseize": 4, "forest": 1})
+	createAnalysisList(t, db, "Rakdos", map[string]int{"bolt": 1, "thoughtseize": 4})
+
+	// Burn has already claimed 3 of its Bolts
+	if err := db.Model(&models.ListItem{}).Where("list_id = ? AND oracle_id = ?", burn.ID, "oracle-bolt").
+		UpdateColumn("collected_quantity", 3).Error; err != nil {
+		t.Fatalf("failed to mark collected: %v", err)
+	}
+
+	// 5 Bolts for 7 wanted, 4 Thoughtseize for 8 wanted, Forests are plentiful
+	for id, qty := range map[string]int{"bolt": 5, "thoughtseize": 4, "forest": 10} {
+		if err := db.Create(&models.Inventory{ScryfallID: id, OracleID: "oracle-" + id, Treatment: "nonfoil", Quantity: qty}).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	cards, err := service.Contention(context.Background())
+	if err != nil {
+		t.Fatalf("Contention failed: %v", err)
+	}
+	if len(cards) != 2 {
+		t.Fatalf("expected 2 contended cards, got %+v", cards)
+	}
+
+	// Biggest shortfall first
+	seize, bolt := cards[0], cards[1]
+	if seize.Name != "Thoughtseize" || seize.Shortfall != 4 || seize.Needed != 8 || seize.Owned != 4 {
+		t.Errorf("unexpected Thoughtseize contention: %+v", seize)
+	}
+	if bolt.Name != "Lightning Bolt" || bolt.Shortfall != 2 {
+		t.Errorf("unexpected Bolt contention: %+v", bolt)
+	}
+
+	// Burn keeps its 3 collected copies, then the two remaining copies complete the
+	// smallest open demands: Burn's last copy and Rakdos's single copy
+	allocated := map[string]int{}
+	for _, list := range bolt.Lists {
+		allocated[list.ListName] = list.Allocated
+	}
+	if allocated["Burn"] != 4 || allocated["Rakdos"] != 1 || allocated["Jund"] != 0 {
+		t.Errorf("unexpected Bolt allocation: %+v", bolt.Lists)
+	}
+
+	total := 0
+	for _, list := range seize.Lists {
+		total += list.Allocated
+	}
+	if total != seize.Owned {
+		t.Errorf("expected all %d owned copies allocated, got %d", seize.Owned, total)
+	}
+	if seize.Lists[0].ListID != jund.ID {
+		t.Errorf("expected lists ordered by id, got %+v", seize.Lists)
+	}
+}
+
+func TestListAnalysisService_Contention_SkipsUnownedCards(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+
+	createAnalysisCard(t, db, "bolt", "Lightning Bolt", "Instant", 1, `["R"]`)
+	createAnalysisList(t, db, "Burn", map[string]int{"bolt": 4})
+	createAnalysisList(t, db, "Jund", map[string]int{"bolt": 2})
+
+	cards, err := service.Contention(context.Background())
+	if err != nil {
+		t.Fatalf("Contention failed: %v", err)
+	}
+	if len(cards) != 0 {
+		t.Errorf("expected no contention without owned copies, got %+v", cards)
+	}
+}
+
+func TestListAnalysisService_Contention_ExcludesLoanedCopies(t *testing.T) {
+	db, service := setupListAnalysisTest(t)
+
+	createAnalysisCard(t, db, "bolt", "Lightning Bolt", "Instant", 1, `["R"]`)
+	createAnalysisList(t, db, "Burn", map[string]int{"bolt": 4})
+	createAnalysisList(t, db, "Jund", map[string]int{"bolt": 2})
+
+	// Six Bolts would cover both lists, but two are lent out
+	item := models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 6}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	loan := models.Loan{Borrower: "Sam", Items: []models.LoanItem{{InventoryID: item.ID, Quantity: 2}}}
+	if err := db.Create(&loan).Error; err != nil {
+		t.Fatalf("failed to create loan: %v", err)
+	}
+
+	cards, err := service.Contention(context.Background())
+	if err != nil {
+		t.Fatalf("Contention failed: %v", err)
+	}
+	if len(cards) != 1 || cards[0].Owned != 4 || cards[0].Shortfall != 2 {
+		t.Errorf("expected 4 copies on hand and a shortfall of 2, got %+v", cards)
+	}
+}
+
+func TestAllocateCopies(t *testing.T) {
+	tests := []struct {
+		name     string
+		owned    int
+		lists    []ListAllocation
+		expected []int
+	}{
+		{
+			name:     "smallest demand first",
+			owned:    3,
+			lists:    []ListAllocation{{ListID: 1, Wanted: 4}, {ListID: 2, Wanted: 1}, {ListID: 3, Wanted: 2}},
+			expected: []int{0, 1, 2},
+		},
+		{
+			name:     "collected copies kept",
+			owned:    3,
+			lists:    []ListAllocation{{ListID: 1, Wanted: 4, Collected: 3}, {ListID: 2, Wanted: 1}},
+			expected: []int{3, 0},
+		},
+		{
+			name:     "collected beyond owned",
+			owned:    2,
+			lists:    []ListAllocation{{ListID: 1, Wanted: 2, Collected: 2}, {ListID: 2, Wanted: 2, Collected: 2}},
+			expected: []int{2, 0},
+		},
+		{
+			name:     "ties go to the lower list id",
+			owned:    1,
+			lists:    []ListAllocation{{ListID: 2, Wanted: 2}, {ListID: 1, Wanted: 2}},
+			expected: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocateCopies(tt.owned, tt.lists)
+			for i, list := range tt.lists {
+				if list.Allocated != tt.expected[i] {
+					t.Errorf("list %d: expected %d allocated, got %d", list.ListID, tt.expected[i], list.Allocated)
+				}
+			}
+		})
+	}
+}
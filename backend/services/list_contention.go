@@ -0,0 +1,139 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ListAllocation is one list's demand for a contended card and the copies suggested for it
+// tygo:export
+type ListAllocation struct {
+	ListID    uint   `json:"list_id"`
+	ListName  string `json:"list_name"`
+	Wanted    int    `json:"wanted"`
+	Collected int    `json:"collected"`
+	Allocated int    `json:"allocated"`
+}
+
+// ContendedCard is a card wanted by several lists where the owned copies can't cover
+// all of them at once
+// tygo:export
+type ContendedCard struct {
+	OracleID  string           `json:"oracle_id"`
+	Name      string           `json:"name"`
+	Owned     int              `json:"owned"`
+	Needed    int              `json:"needed"`
+	Shortfall int              `json:"shortfall"`
+	Lists     []ListAllocation `json:"lists"`
+}
+
+// Contention reports every card wanted by more than one list where the owned copies
+// fall short of the combined demand, with a suggested split of the owned copies.
+// Cards with no owned copies are left out; they are missing rather than contended.
+func (s *ListAnalysisService) Contention(ctx context.Context) ([]ContendedCard, error) {
+	var demand []struct {
+		ListID    uint
+		ListName  string
+		OracleID  string
+		Wanted    int
+		Collected int
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT l.id AS list_id, l.name AS list_name, li.oracle_id,
+			SUM(li.desired_quantity) AS wanted, SUM(li.collected_quantity) AS collected
+		FROM list_items li
+		JOIN lists l ON l.id = li.list_id
+		WHERE li.oracle_id IN (
+			SELECT oracle_id FROM list_items GROUP BY oracle_id HAVING COUNT(DISTINCT list_id) > 1
+		)
+		GROUP BY l.id, li.oracle_id`).Scan(&demand).Error; err != nil {
+		return nil, fmt.Errorf("loading list demand: %w", err)
+	}
+	if len(demand) == 0 {
+		return []ContendedCard{}, nil
+	}
+
+	byCard := map[string][]ListAllocation{}
+	for _, row := range demand {
+		byCard[row.OracleID] = append(byCard[row.OracleID], ListAllocation{
+			ListID: row.ListID, ListName: row.ListName, Wanted: row.Wanted, Collected: row.Collected,
+		})
+	}
+	oracleIDs := make([]string, 0, len(byCard))
+	for oracleID := range byCard {
+		oracleIDs = append(oracleIDs, oracleID)
+	}
+
+	ownedByCard, err := s.ownedByOracle(ctx, oracleIDs)
+	if err != nil {
+		return nil, err
+	}
+	nameByCard, err := s.namesByOracle(ctx, oracleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	contended := []ContendedCard{}
+	for _, oracleID := range oracleIDs {
+		card := ContendedCard{
+			OracleID: oracleID,
+			Name:     nameByCard[oracleID],
+			Owned:    ownedByCard[oracleID],
+			Lists:    byCard[oracleID],
+		}
+		for _, list := range card.Lists {
+			card.Needed += list.Wanted
+		}
+		if card.Owned == 0 || card.Owned >= card.Needed {
+			continue
+		}
+		card.Shortfall = card.Needed - card.Owned
+		allocateCopies(card.Owned, card.Lists)
+		contended = append(contended, card)
+	}
+
+	// Biggest shortfall first, then by name
+	slices.SortFunc(contended, func(a, b ContendedCard) int {
+		return cmp.Or(
+			cmp.Compare(b.Shortfall, a.Shortfall),
+			strings.Compare(a.Name, b.Name),
+			strings.Compare(a.OracleID, b.OracleID),
+		)
+	})
+	return contended, nil
+}
+
+// allocateCopies splits owned copies between lists. Copies a list has already marked
+// collected are honoured first, then the rest go to the smallest remaining demands so
+// as many lists as possible end up complete. lists is sorted by list ID and updated in place.
+func allocateCopies(owned int, lists []ListAllocation) {
+	slices.SortFunc(lists, func(a, b ListAllocation) int {
+		return cmp.Compare(a.ListID, b.ListID)
+	})
+
+	remaining := owned
+	for i := range lists {
+		take := min(lists[i].Collected, lists[i].Wanted, remaining)
+		lists[i].Allocated = take
+		remaining -= take
+	}
+
+	order := make([]int, len(lists))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(lists[a].Wanted-lists[a].Allocated, lists[b].Wanted-lists[b].Allocated)
+	})
+	for _, i := range order {
+		if remaining == 0 {
+			break
+		}
+		take := min(lists[i].Wanted-lists[i].Allocated, remaining)
+		lists[i].Allocated += take
+		remaining -= take
+	}
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ListExportFormat identifies the text format a list is exported in
+// tygo:export
+type ListExportFormat string
+
+const (
+	ListExportArena    ListExportFormat = "arena"    // "4 Lightning Bolt (M10) 146", front face names only
+	ListExportMoxfield ListExportFormat = "moxfield" // Arena lines plus *F* / *E* finish markers
+	ListExportText     ListExportFormat = "txt"      // "4 Lightning Bolt", quantities summed across printings
+	ListExportCSV      ListExportFormat = "csv"      // Scryfall-style CSV accepted by the inventory import
+)
+
+// listExportCSVHeader matches the columns the Scryfall import format reads
+var listExportCSVHeader = []string{"count", "name", "set_code", "collector_number", "finish", "scryfall_id"}
+
+// ParseListExportFormat validates a user-supplied export format name
+func ParseListExportFormat(value string) (ListExportFormat, error) {
+	format := ListExportFormat(strings.ToLower(strings.TrimSpace(value)))
+	switch format {
+	case ListExportArena, ListExportMoxfield, ListExportText, ListExportCSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid format %q (expected arena, moxfield, txt, or csv)", value)
+}
+
+// ContentType is the MIME type of an export in this format
+func (f ListExportFormat) ContentType() string {
+	if f == ListExportCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// Extension is the file extension of an export in this format
+func (f ListExportFormat) Extension() string {
+	if f == ListExportCSV {
+		return ".csv"
+	}
+	return ".txt"
+}
+
+// listExportRow is a list item with the card details its export lines need
+type listExportRow struct {
+	ScryfallID      string
+	Treatment       string
+	Quantity        int
+	Name            string
+	SetCode         string
+	CollectorNumber string
+}
+
+// ExportList writes a list's items to w in the given format, ordered by card name.
+// Card details come from the local cards table; items whose card is not loaded are
+// left out. Returns the number of items written.
+func ExportList(ctx context.Context, db *gorm.DB, listID uint, format ListExportFormat, w io.Writer) (int, error) {
+	var rows []listExportRow
+	if err := db.WithContext(ctx).Raw(`
+		SELECT li.scryfall_id, li.treatment, li.desired_quantity AS quantity,
+			COALESCE(c.name, '') AS name,
+			COALESCE(c.set_code, '') AS set_code,
+			COALESCE(c.collector_number, '') AS collector_number
+		FROM list_items li
+		JOIN cards c ON c.scryfall_id = li.scryfall_id
+		WHERE li.list_id = ?
+		ORDER BY name ASC, set_code ASC, li.id ASC`, listID).Scan(&rows).Error; err != nil {
+		return 0, fmt.Errorf("loading list items: %w", err)
+	}
+
+	switch format {
+	case ListExportCSV:
+		return len(rows), writeListExportCSV(w, rows)
+	case ListExportText:
+		return len(rows), writeListExportText(w, rows)
+	default:
+		return len(rows), writeListExportDeck(w, rows, format == ListExportMoxfield)
+	}
+}
+
+// writeListExportDeck writes one "qty name (SET) number" line per printing, the format
+// MTGA and Moxfield both import. Arena does not know finishes or split names, so its
+// lines carry only the front face and no finish marker.
+func writeListExportDeck(w io.Writer, rows []listExportRow, finishes bool) error {
+	for _, row := range rows {
+		name := row.Name
+		if !finishes {
+			name, _, _ = strings.Cut(name, " // ")
+		}
+
+		line := fmt.Sprintf("%d %s (%s)", row.Quantity, name, strings.ToUpper(row.SetCode))
+		if row.CollectorNumber != "" {
+			line += " " + row.CollectorNumber
+		}
+		if finishes {
+			switch row.Treatment {
+			case "foil":
+				line += " *F*"
+			case "etched":
+				line += " *E*"
+			}
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeListExportText writes one "qty name" line per card, summing its printings
+func writeListExportText(w io.Writer, rows []listExportRow) error {
+	quantities := make(map[string]int, len(rows))
+	var names []string
+	for _, row := range rows {
+		if _, ok := quantities[row.Name]; !ok {
+			names = append(names, row.Name)
+		}
+		quantities[row.Name] += row.Quantity
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%d %s\n", quantities[name], name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeListExportCSV writes the list as a Scryfall-style CSV
+func writeListExportCSV(w io.Writer, rows []listExportRow) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(listExportCSVHeader); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := []string{
+			strconv.Itoa(row.Quantity), row.Name, row.SetCode, row.CollectorNumber, row.Treatment, row.ScryfallID,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"context"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupListExportTest(t *testing.T) (*gorm.DB, uint) {
+	t.Helper()
+
+	_, db := setupTextImportTest(t)
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	list := models.List{Name: "Burn"}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	}
+	items := []models.ListItem{
+		{ListID: list.ID, ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", DesiredQuantity: 3},
+		{ListID: list.ID, ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", Treatment: "foil", DesiredQuantity: 1},
+		{ListID: list.ID, ScryfallID: "delver", OracleID: "oracle-delver", Treatment: "etched", DesiredQuantity: 2},
+		{ListID: list.ID, ScryfallID: "not-loaded", OracleID: "oracle-missing", Treatment: "nonfoil", DesiredQuantity: 1},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("failed to create list items: %v", err)
+	}
+	return db, list.ID
+}
+
+func TestParseListExportFormat(t *testing.T) {
+	for _, value := range []string{"arena", "Moxfield", " txt ", "CSV"} {
+		if _, err := ParseListExportFormat(value); err != nil {
+			t.Errorf("expected %q to be valid, got %v", value, err)
+		}
+	}
+	if _, err := ParseListExportFormat("mtgo"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestExportList(t *testing.T) {
+	db, listID := setupListExportTest(t)
+
+	tests := []struct {
+		format   ListExportFormat
+		expected string
+	}{
+		{ListExportArena, "2 Delver of Secrets (ISD)\n1 Lightning Bolt (2X2) 117\n3 Lightning Bolt (M10) 146\n"},
+		{ListExportMoxfield, "2 Delver of Secrets // Insectile Aberration (ISD) *E*\n1 Lightning Bolt (2X2) 117 *F*\n3 Lightning Bolt (M10) 146\n"},
+		{ListExportText, "2 Delver of Secrets // Insectile Aberration\n4 Lightning Bolt\n"},
+		{ListExportCSV, "count,name,set_code,collector_number,finish,scryfall_id\n" +
+			"2,Delver of Secrets // Insectile Aberration,isd,,etched,delver\n" +
+			"1,Lightning Bolt,2x2,117,foil,bolt-2x2\n" +
+			"3,Lightning Bolt,m10,146,nonfoil,bolt-m10\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			count, err := ExportList(context.Background(), db, listID, tt.format, &buf)
+			if err != nil {
+				t.Fatalf("ExportList failed: %v", err)
+			}
+			if count != 3 {
+				t.Errorf("expected 3 items exported without the unloaded card, got %d", count)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("unexpected export:\n%s\nexpected:\n%s", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestExportList_RoundTrip(t *testing.T) {
+	db, listID := setupListExportTest(t)
+
+	var buf bytes.Buffer
+	if _, err := ExportList(context.Background(), db, listID, ListExportMoxfield, &buf); err != nil {
+		t.Fatalf("ExportList failed: %v", err)
+	}
+
+	results, err := ResolveDeckList(context.Background(), db, buf.String())
+	if err != nil {
+		t.Fatalf("ResolveDeckList failed: %v", err)
+	}
+	for _, result := range results {
+		if result.Status != DeckListLineMatched {
+			t.Errorf("line %q did not resolve: %s", result.Text, result.Status)
+		}
+	}
+	if len(results) == 3 && (results[1].Match.ScryfallID != "bolt-2x2" || results[1].Treatment != "foil") {
+		t.Errorf("expected the foil 2X2 Bolt back, got %+v", results[1])
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ListMatchPolicy controls which inventory copies count towards a list item
+// tygo:export
+type ListMatchPolicy string
+
+const (
+	// ListMatchExactPrinting counts only copies of the same printing and treatment
+	ListMatchExactPrinting ListMatchPolicy = "exact_printing"
+	// ListMatchAnyPrinting counts copies of any printing of the same card
+	ListMatchAnyPrinting ListMatchPolicy = "any_printing"
+	// ListMatchAnyPrintingExcluding counts any printing except the excluded treatments
+	ListMatchAnyPrintingExcluding ListMatchPolicy = "any_printing_excluding"
+)
+
+// Valid checks if the list match policy is valid
+func (p ListMatchPolicy) Valid() bool {
+	switch p {
+	case ListMatchExactPrinting, ListMatchAnyPrinting, ListMatchAnyPrintingExcluding:
+		return true
+	default:
+		return false
+	}
+}
+
+// ListMatchRule is the configured policy plus the treatments it ignores
+type ListMatchRule struct {
+	Policy             ListMatchPolicy
+	ExcludedTreatments []string
+}
+
+// matches reports whether an inventory row counts towards a list item under the rule
+func (r ListMatchRule) matches(item models.ListItem, row models.Inventory) bool {
+	switch r.Policy {
+	case ListMatchAnyPrinting:
+		return row.OracleID == item.OracleID
+	case ListMatchAnyPrintingExcluding:
+		return row.OracleID == item.OracleID && !slices.Contains(r.ExcludedTreatments, row.Treatment)
+	default:
+		return row.ScryfallID == item.ScryfallID && row.Treatment == item.Treatment
+	}
+}
+
+// ListMatchService decides how owned inventory lines up with list items
+type ListMatchService struct {
+	db *gorm.DB
+}
+
+// NewListMatchService creates a new list match service
+func NewListMatchService(db *gorm.DB) *ListMatchService {
+	return &ListMatchService{db: db}
+}
+
+// Rule reads the configured match policy. Unknown policies fall back to exact printing.
+func (s *ListMatchService) Rule(ctx context.Context) ListMatchRule {
+	settings := settingsStore(s.db)
+
+	rule := ListMatchRule{Policy: ListMatchExactPrinting}
+	if value, err := settings.Get(ctx, "list_match_policy"); err == nil && value != "" {
+		if policy := ListMatchPolicy(value); policy.Valid() {
+			rule.Policy = policy
+		} else {
+			slog.WarnContext(ctx, "unknown list match policy, using exact printing", "component", "list_match", "policy", value)
+		}
+	}
+
+	if value, err := settings.Get(ctx, "list_match_excluded_treatments"); err == nil {
+		for _, treatment := range strings.Split(value, ",") {
+			if treatment = strings.TrimSpace(treatment); treatment != "" {
+				rule.ExcludedTreatments = append(rule.ExcludedTreatments, treatment)
+			}
+		}
+	}
+	return rule
+}
+
+// OwnedQuantities returns how many owned copies count towards each list item under
+// the configured policy, keyed by list item ID. Copies out on loan don't count.
+func (s *ListMatchService) OwnedQuantities(ctx context.Context, items []models.ListItem) (map[uint]int, error) {
+	owned := make(map[uint]int, len(items))
+	if len(items) == 0 {
+		return owned, nil
+	}
+
+	oracleIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		oracleIDs = append(oracleIDs, item.OracleID)
+	}
+
+	var rows []models.Inventory
+	if err := s.db.WithContext(ctx).
+		Select("inventories.scryfall_id, inventories.oracle_id, inventories.treatment, inventories.quantity - COALESCE(lent.quantity, 0) AS quantity").
+		Joins("LEFT JOIN (?) AS lent ON lent.inventory_id = inventories.id", models.ActiveLoanQuantities(s.db)).
+		Where("inventories.oracle_id IN ?", oracleIDs).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("loading inventory for list items: %w", err)
+	}
+
+	rule := s.Rule(ctx)
+	for _, item := range items {
+		for _, row := range rows {
+			if rule.matches(item, row) {
+				owned[item.ID] += row.Quantity
+			}
+		}
+	}
+	return owned, nil
+}
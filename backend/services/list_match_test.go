@@ -0,0 +1,121 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListMatchTest(t *testing.T) (*gorm.DB, *ListMatchService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Setting{}, &models.Loan{}, &models.LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	inventory := []models.Inventory{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 2},
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "foil", Quantity: 1},
+		{ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3},
+		{ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", Treatment: "etched", Quantity: 4},
+	}
+	for i := range inventory {
+		if err := db.Create(&inventory[i]).Error; err != nil {
+			t.Fatalf("failed to create inventory: %v", err)
+		}
+	}
+
+	return db, NewListMatchService(db)
+}
+
+func TestListMatchService_OwnedQuantities(t *testing.T) {
+	item := models.ListItem{BaseModel: models.BaseModel{ID: 7}, ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil"}
+
+	tests := []struct {
+		name     string
+		policy   string
+		excluded string
+		expected int
+	}{
+		{name: "Default is exact printing", expected: 2},
+		{name: "Exact printing", policy: "exact_printing", expected: 2},
+		{name: "Any printing", policy: "any_printing", expected: 10},
+		{name: "Any printing excluding treatments", policy: "any_printing_excluding", excluded: "foil, etched", expected: 5},
+		{name: "Unknown policy falls back to exact", policy: "bogus", expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := setupListMatchTest(t)
+			if tt.policy != "" {
+				db.Create(&models.Setting{Key: "list_match_policy", Value: tt.policy})
+			}
+			if tt.excluded != "" {
+				db.Create(&models.Setting{Key: "list_match_excluded_treatments", Value: tt.excluded})
+			}
+
+			owned, err := service.OwnedQuantities(context.Background(), []models.ListItem{item})
+			if err != nil {
+				t.Fatalf("OwnedQuantities failed: %v", err)
+			}
+			if owned[item.ID] != tt.expected {
+				t.Errorf("expected %d owned copies, got %d", tt.expected, owned[item.ID])
+			}
+		})
+	}
+}
+
+func TestListMatchService_OwnedQuantities_ExcludesLoanedCopies(t *testing.T) {
+	db, service := setupListMatchTest(t)
+	item := models.ListItem{BaseModel: models.BaseModel{ID: 7}, ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil"}
+
+	// Both nonfoil M10 copies are lent out; a returned loan no longer holds any back
+	returned := time.Now()
+	loans := []models.Loan{
+		{Borrower: "Sam", Items: []models.LoanItem{{InventoryID: 1, Quantity: 2}}},
+		{Borrower: "Alex", ReturnedAt: &returned, Items: []models.LoanItem{{InventoryID: 3, Quantity: 3}}},
+	}
+	for i := range loans {
+		if err := db.Create(&loans[i]).Error; err != nil {
+			t.Fatalf("failed to create loan: %v", err)
+		}
+	}
+
+	owned, err := service.OwnedQuantities(context.Background(), []models.ListItem{item})
+	if err != nil {
+		t.Fatalf("OwnedQuantities failed: %v", err)
+	}
+	if owned[item.ID] != 0 {
+		t.Errorf("expected the loaned copies not to count, got %d", owned[item.ID])
+	}
+
+	db.Create(&models.Setting{Key: "list_match_policy", Value: "any_printing"})
+	owned, err = service.OwnedQuantities(context.Background(), []models.ListItem{item})
+	if err != nil {
+		t.Fatalf("OwnedQuantities failed: %v", err)
+	}
+	if owned[item.ID] != 8 {
+		t.Errorf("expected 8 copies on hand, got %d", owned[item.ID])
+	}
+}
+
+func TestListMatchService_OwnedQuantities_NoItems(t *testing.T) {
+	_, service := setupListMatchTest(t)
+
+	owned, err := service.OwnedQuantities(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("OwnedQuantities failed: %v", err)
+	}
+	if len(owned) != 0 {
+		t.Errorf("expected no owned quantities, got %v", owned)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// listSyncDelay is how long inventory must go unchanged before auto-tracked lists are
+// re-synced, so a batch operation or import triggers one sync rather than many
+const listSyncDelay = 2 * time.Second
+
+// ListSyncService keeps list items' CollectedQuantity in step with owned inventory for
+// lists with AutoTrackInventory, counting copies under the configured list match policy
+type ListSyncService struct {
+	db      *gorm.DB
+	delay   time.Duration
+	changed chan struct{}
+}
+
+// NewListSyncService creates a new list sync service
+func NewListSyncService(db *gorm.DB) *ListSyncService {
+	return &ListSyncService{
+		db:      db,
+		delay:   listSyncDelay,
+		changed: make(chan struct{}, 1),
+	}
+}
+
+// SyncList sets each item's collected quantity to the owned copies that match it,
+// capped at the desired quantity, and returns how many items changed
+func (s *ListSyncService) SyncList(ctx context.Context, listID uint) (int, error) {
+	var items []models.ListItem
+	if err := s.db.WithContext(ctx).Where("list_id = ?", listID).Find(&items).Error; err != nil {
+		return 0, fmt.Errorf("loading list items: %w", err)
+	}
+
+	owned, err := NewListMatchService(s.db).OwnedQuantities(ctx, items)
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			collected := min(owned[item.ID], item.DesiredQuantity)
+			if collected == item.CollectedQuantity {
+				continue
+			}
+			// UpdateColumn skips the hooks; the cap above keeps the row valid
+			if err := tx.Model(&models.ListItem{}).Where("id = ?", item.ID).
+				UpdateColumn("collected_quantity", collected).Error; err != nil {
+				return fmt.Errorf("updating list item %d: %w", item.ID, err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return updated, nil
+}
+
+// SyncAutoTracked syncs every list with AutoTrackInventory enabled and returns how
+// many items changed across them
+func (s *ListSyncService) SyncAutoTracked(ctx context.Context) (int, error) {
+	var listIDs []uint
+	if err := s.db.WithContext(ctx).Model(&models.List{}).
+		Where("auto_track_inventory = ?", true).
+		Pluck("id", &listIDs).Error; err != nil {
+		return 0, fmt.Errorf("loading auto-tracked lists: %w", err)
+	}
+
+	total := 0
+	for _, listID := range listIDs {
+		updated, err := s.SyncList(ctx, listID)
+		if err != nil {
+			return total, fmt.Errorf("syncing list %d: %w", listID, err)
+		}
+		total += updated
+	}
+	return total, nil
+}
+
+// Watch registers callbacks noting every write to the inventory table through s.db,
+// then re-syncs auto-tracked lists in the background once writes settle, until ctx
+// is done. Writes made by any service or handler sharing the connection are covered.
+// It must be called at most once per database handle.
+func (s *ListSyncService) Watch(ctx context.Context) error {
+	notify := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.Table != "inventories" || tx.Statement.RowsAffected == 0 {
+			return
+		}
+		select {
+		case s.changed <- struct{}{}:
+		default: // A sync is already pending
+		}
+	}
+
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("list_sync:inventory_created", notify); err != nil {
+		return fmt.Errorf("registering create callback: %w", err)
+	}
+	if err := callbacks.Update().After("gorm:update").Register("list_sync:inventory_updated", notify); err != nil {
+		return fmt.Errorf("registering update callback: %w", err)
+	}
+	if err := callbacks.Delete().After("gorm:delete").Register("list_sync:inventory_deleted", notify); err != nil {
+		return fmt.Errorf("registering delete callback: %w", err)
+	}
+
+	go s.run(ctx)
+	return nil
+}
+
+// run waits for inventory changes and syncs once none have arrived for s.delay
+func (s *ListSyncService) run(ctx context.Context) {
+	timer := time.NewTimer(s.delay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.changed:
+			timer.Reset(s.delay)
+		case <-timer.C:
+			updated, err := s.SyncAutoTracked(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "failed to sync auto-tracked lists", "component", "list_sync", "error", err)
+				continue
+			}
+			if updated > 0 {
+				slog.InfoContext(ctx, "synced auto-tracked lists", "component", "list_sync", "updated", updated)
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupListSyncTest(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	// The watcher syncs in a goroutine, so keep a single connection to the in-memory database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(&models.List{}, &models.ListItem{}, &models.StorageLocation{}, &models.Inventory{}, &models.Setting{},
+		&models.Loan{}, &models.LoanItem{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func createSyncTestList(t *testing.T, db *gorm.DB, name string, autoTrack bool, desired int) (models.List, models.ListItem) {
+	t.Helper()
+
+	list := models.List{Name: name, AutoTrackInventory: autoTrack}
+	if err := db.Create(&list).Error; err != nil {
+		t.Fatalf("failed to create list: %v", err)
+	}
+	item := models.ListItem{ListID: list.ID, ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", DesiredQuantity: desired}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create list item: %v", err)
+	}
+	return list, item
+}
+
+func collectedQuantity(t *testing.T, db *gorm.DB, itemID uint) int {
+	t.Helper()
+
+	var item models.ListItem
+	if err := db.First(&item, itemID).Error; err != nil {
+		t.Fatalf("failed to load list item: %v", err)
+	}
+	return item.CollectedQuantity
+}
+
+func TestListSyncService_SyncList(t *testing.T) {
+	db := setupListSyncTest(t)
+	ctx := context.Background()
+	service := NewListSyncService(db)
+
+	list, item := createSyncTestList(t, db, "Burn", true, 4)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 2})
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "foil", Quantity: 5})
+
+	updated, err := service.SyncList(ctx, list.ID)
+	if err != nil {
+		t.Fatalf("SyncList failed: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("expected 1 item updated, got %d", updated)
+	}
+	if collected := collectedQuantity(t, db, item.ID); collected != 2 {
+		t.Errorf("expected 2 collected under exact printing, got %d", collected)
+	}
+
+	// Owning more than desired caps at the desired quantity
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3})
+	if _, err := service.SyncList(ctx, list.ID); err != nil {
+		t.Fatalf("SyncList failed: %v", err)
+	}
+	if collected := collectedQuantity(t, db, item.ID); collected != 4 {
+		t.Errorf("expected collected capped at 4, got %d", collected)
+	}
+
+	// Nothing changes on a second pass
+	if updated, err := service.SyncList(ctx, list.ID); err != nil || updated != 0 {
+		t.Errorf("expected no updates, got %d (err %v)", updated, err)
+	}
+}
+
+func TestListSyncService_SyncAutoTracked(t *testing.T) {
+	db := setupListSyncTest(t)
+	service := NewListSyncService(db)
+
+	_, tracked := createSyncTestList(t, db, "Tracked", true, 4)
+	_, manual := createSyncTestList(t, db, "Manual", false, 4)
+	db.Create(&models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3})
+
+	updated, err := service.SyncAutoTracked(context.Background())
+	if err != nil {
+		t.Fatalf("SyncAutoTracked failed: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("expected 1 item updated, got %d", updated)
+	}
+	if collected := collectedQuantity(t, db, tracked.ID); collected != 3 {
+		t.Errorf("expected tracked list to collect 3, got %d", collected)
+	}
+	if collected := collectedQuantity(t, db, manual.ID); collected != 0 {
+		t.Errorf("expected manual list untouched, got %d", collected)
+	}
+}
+
+func TestListSyncService_Watch(t *testing.T) {
+	db := setupListSyncTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := NewListSyncService(db)
+	service.delay = 10 * time.Millisecond
+	if err := service.Watch(ctx); err != nil {
+		t.Fatalf("Watch failed: %v", err)
+	}
+
+	_, item := createSyncTestList(t, db, "Tracked", true, 4)
+	inventory := models.Inventory{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3}
+	db.Create(&inventory)
+
+	waitForCollected := func(expected int) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if collectedQuantity(t, db, item.ID) == expected {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("expected collected to reach %d, got %d", expected, collectedQuantity(t, db, item.ID))
+	}
+
+	waitForCollected(3)
+
+	db.Delete(&inventory)
+	waitForCollected(0)
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLoanAlreadyReturned is returned when returning a loan that is already closed
+	ErrLoanAlreadyReturned = errors.New("loan has already been returned")
+
+	// ErrInsufficientQuantity is returned when lending more copies than are available
+	ErrInsufficientQuantity = errors.New("not enough copies available to lend")
+)
+
+// LoanStatus filters loans by their lifecycle state
+type LoanStatus string
+
+const (
+	LoanStatusActive   LoanStatus = "active"
+	LoanStatusReturned LoanStatus = "returned"
+	LoanStatusOverdue  LoanStatus = "overdue"
+)
+
+// Valid checks if the loan status filter is valid
+func (ls LoanStatus) Valid() bool {
+	switch ls {
+	case LoanStatusActive, LoanStatusReturned, LoanStatusOverdue:
+		return true
+	default:
+		return false
+	}
+}
+
+// LoanService handles lending inventory items and tracking their return
+type LoanService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+// NewLoanService creates a new loan service
+func NewLoanService(db *gorm.DB, notificationService *NotificationService) *LoanService {
+	return &LoanService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+// Lend records a new loan. Each item's quantity must not exceed the copies of
+// that inventory entry that are not already on loan.
+func (s *LoanService) Lend(ctx context.Context, loan *models.Loan) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		requested := make(map[uint]int)
+		ids := make([]uint, 0, len(loan.Items))
+		for _, item := range loan.Items {
+			if _, seen := requested[item.InventoryID]; !seen {
+				ids = append(ids, item.InventoryID)
+			}
+			requested[item.InventoryID] += item.Quantity
+		}
+
+		var inventory []models.Inventory
+		if err := tx.Where("id IN ?", ids).Find(&inventory).Error; err != nil {
+			return fmt.Errorf("loading inventory: %w", err)
+		}
+		if len(inventory) != len(ids) {
+			return fmt.Errorf("loading inventory: %w", gorm.ErrRecordNotFound)
+		}
+
+		onLoan, err := models.GetOnLoanQuantities(tx, ids)
+		if err != nil {
+			return fmt.Errorf("loading on-loan quantities: %w", err)
+		}
+
+		for _, inv := range inventory {
+			available := inv.Quantity - onLoan[inv.ID]
+			if requested[inv.ID] > available {
+				return fmt.Errorf("inventory item %d has %d available: %w", inv.ID, available, ErrInsufficientQuantity)
+			}
+		}
+
+		if err := tx.Create(loan).Error; err != nil {
+			return fmt.Errorf("creating loan: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "recorded loan", "component", "loans", "loan_id", loan.ID, "borrower", loan.Borrower, "items", len(loan.Items))
+	return nil
+}
+
+// Get retrieves a loan by ID with its items and their inventory entries
+func (s *LoanService) Get(ctx context.Context, id uint) (*models.Loan, error) {
+	var loan models.Loan
+	if err := s.db.WithContext(ctx).Preload("Items.Inventory").First(&loan, id).Error; err != nil {
+		return nil, fmt.Errorf("getting loan %d: %w", id, err)
+	}
+	return &loan, nil
+}
+
+// List retrieves loans with pagination and an optional status filter
+func (s *LoanService) List(ctx context.Context, page, pageSize int, status *LoanStatus) ([]models.Loan, int64, error) {
+	var loans []models.Loan
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.Loan{})
+	if status != nil {
+		switch *status {
+		case LoanStatusActive:
+			query = query.Where("returned_at IS NULL")
+		case LoanStatusReturned:
+			query = query.Where("returned_at IS NOT NULL")
+		case LoanStatusOverdue:
+			query = query.Where("returned_at IS NULL AND due_date IS NOT NULL AND due_date < ?", time.Now())
+		}
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting loans: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Items.Inventory").Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&loans).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing loans: %w", err)
+	}
+
+	return loans, total, nil
+}
+
+// OverdueLoan is an active loan past its due date, with the copies still out
+// tygo:export
+type OverdueLoan struct {
+	models.Loan `tstype:",extends"`
+	DaysOverdue int `json:"days_overdue"` // Whole days since the due date
+	Cards       int `json:"cards"`        // Copies lent out
+}
+
+// Overdue retrieves every active loan past its due date at now, most overdue first
+func (s *LoanService) Overdue(ctx context.Context, now time.Time) ([]OverdueLoan, error) {
+	var loans []models.Loan
+	if err := s.db.WithContext(ctx).Preload("Items.Inventory").
+		Where("returned_at IS NULL AND due_date IS NOT NULL AND due_date < ?", now).
+		Order("due_date ASC, id ASC").
+		Find(&loans).Error; err != nil {
+		return nil, fmt.Errorf("finding overdue loans: %w", err)
+	}
+
+	overdue := make([]OverdueLoan, len(loans))
+	for i, loan := range loans {
+		overdue[i] = OverdueLoan{Loan: loan, DaysOverdue: int(now.Sub(*loan.DueDate).Hours() / 24)}
+		for _, item := range loan.Items {
+			overdue[i].Cards += item.Quantity
+		}
+	}
+	return overdue, nil
+}
+
+// Return marks a loan as returned, making its items available again
+func (s *LoanService) Return(ctx context.Context, id uint) (*models.Loan, error) {
+	loan, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !loan.IsActive() {
+		return nil, fmt.Errorf("returning loan %d: %w", id, ErrLoanAlreadyReturned)
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(&models.Loan{}).Where("id = ?", id).UpdateColumn("returned_at", now).Error; err != nil {
+		return nil, fmt.Errorf("returning loan %d: %w", id, err)
+	}
+	loan.ReturnedAt = &now
+
+	slog.InfoContext(ctx, "loan returned", "component", "loans", "loan_id", id, "borrower", loan.Borrower)
+	return loan, nil
+}
+
+// NotifyOverdue raises a notification for each active loan past its due date
+// that has not been notified yet. It returns the number of notifications created.
+func (s *LoanService) NotifyOverdue(ctx context.Context, now time.Time) (int, error) {
+	var loans []models.Loan
+	if err := s.db.WithContext(ctx).Preload("Items").
+		Where("returned_at IS NULL AND due_date IS NOT NULL AND due_date < ? AND overdue_notified_at IS NULL", now).
+		Find(&loans).Error; err != nil {
+		return 0, fmt.Errorf("finding overdue loans: %w", err)
+	}
+
+	notified := 0
+	for _, loan := range loans {
+		copies := 0
+		for _, item := range loan.Items {
+			copies += item.Quantity
+		}
+
+		title := fmt.Sprintf("Loan to %s is overdue", loan.Borrower)
+		message := fmt.Sprintf("%d card(s) lent to %s were due back on %s.", copies, loan.Borrower, loan.DueDate.Format("2006-01-02"))
+		if _, err := s.notificationService.Create(ctx, models.NotificationTypeLoanOverdue, title, message); err != nil {
+			return notified, err
+		}
+
+		if err := s.db.WithContext(ctx).Model(&models.Loan{}).Where("id = ?", loan.ID).UpdateColumn("overdue_notified_at", now).Error; err != nil {
+			return notified, fmt.Errorf("marking loan %d notified: %w", loan.ID, err)
+		}
+		notified++
+	}
+
+	return notified, nil
+}
+
+// RunOverdueCheck is the scheduled task entry point for overdue loan notifications
+func (s *LoanService) RunOverdueCheck(ctx context.Context) error {
+	count, err := s.NotifyOverdue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("checking overdue loans: %w", err)
+	}
+	if count > 0 {
+		slog.InfoContext(ctx, "raised overdue loan notifications", "component", "loans", "count", count)
+	}
+	return nil
+}
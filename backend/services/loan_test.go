@@ -0,0 +1,228 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupLoanServiceTest(t *testing.T) (*LoanService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Loan{}, &models.LoanItem{}, &models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return NewLoanService(db, NewNotificationService(db)), db
+}
+
+func createLoanTestInventory(t *testing.T, db *gorm.DB, quantity int) models.Inventory {
+	t.Helper()
+
+	item := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: quantity}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	return item
+}
+
+// Lend tests
+
+func TestLoanService_Lend_Success(t *testing.T) {
+	service, _ := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, service.db, 4)
+
+	loan := &models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 3}}}
+	if err := service.Lend(context.Background(), loan); err != nil {
+		t.Fatalf("Lend failed: %v", err)
+	}
+
+	if loan.ID == 0 {
+		t.Error("expected loan ID to be set")
+	}
+}
+
+func TestLoanService_Lend_InsufficientQuantity(t *testing.T) {
+	service, _ := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, service.db, 2)
+	ctx := context.Background()
+
+	first := &models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 2}}}
+	if err := service.Lend(ctx, first); err != nil {
+		t.Fatalf("Lend failed: %v", err)
+	}
+
+	second := &models.Loan{Borrower: "Sam", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}}
+	err := service.Lend(ctx, second)
+	if !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+}
+
+func TestLoanService_Lend_DuplicateItemsCombined(t *testing.T) {
+	service, _ := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, service.db, 2)
+
+	loan := &models.Loan{Borrower: "Alex", Items: []models.LoanItem{
+		{InventoryID: inv.ID, Quantity: 2},
+		{InventoryID: inv.ID, Quantity: 1},
+	}}
+	err := service.Lend(context.Background(), loan)
+	if !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+}
+
+func TestLoanService_Lend_InventoryNotFound(t *testing.T) {
+	service, _ := setupLoanServiceTest(t)
+
+	loan := &models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: 999, Quantity: 1}}}
+	err := service.Lend(context.Background(), loan)
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+// Return tests
+
+func TestLoanService_Return(t *testing.T) {
+	service, _ := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, service.db, 1)
+	ctx := context.Background()
+
+	loan := &models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}}
+	if err := service.Lend(ctx, loan); err != nil {
+		t.Fatalf("Lend failed: %v", err)
+	}
+
+	returned, err := service.Return(ctx, loan.ID)
+	if err != nil {
+		t.Fatalf("Return failed: %v", err)
+	}
+	if returned.ReturnedAt == nil {
+		t.Error("expected returned_at to be set")
+	}
+
+	// The copy is available to lend again
+	again := &models.Loan{Borrower: "Sam", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}}
+	if err := service.Lend(ctx, again); err != nil {
+		t.Errorf("expected lend after return to succeed, got %v", err)
+	}
+
+	if _, err := service.Return(ctx, loan.ID); !errors.Is(err, ErrLoanAlreadyReturned) {
+		t.Errorf("expected ErrLoanAlreadyReturned, got %v", err)
+	}
+}
+
+// List tests
+
+func TestLoanService_List_StatusFilter(t *testing.T) {
+	service, db := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, db, 10)
+	ctx := context.Background()
+
+	past := time.Now().Add(-48 * time.Hour)
+	future := time.Now().Add(48 * time.Hour)
+	returnedAt := time.Now()
+
+	db.Create(&models.Loan{Borrower: "Overdue", DueDate: &past, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+	db.Create(&models.Loan{Borrower: "Active", DueDate: &future, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+	db.Create(&models.Loan{Borrower: "Returned", ReturnedAt: &returnedAt, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+
+	tests := []struct {
+		status   LoanStatus
+		expected int64
+	}{
+		{status: "", expected: 3},
+		{status: LoanStatusActive, expected: 2},
+		{status: LoanStatusReturned, expected: 1},
+		{status: LoanStatusOverdue, expected: 1},
+	}
+
+	for _, tt := range tests {
+		var filter *LoanStatus
+		if tt.status != "" {
+			filter = &tt.status
+		}
+		_, total, err := service.List(ctx, 1, 20, filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if total != tt.expected {
+			t.Errorf("status %q: expected %d loans, got %d", tt.status, tt.expected, total)
+		}
+	}
+}
+
+// NotifyOverdue tests
+
+func TestLoanService_NotifyOverdue(t *testing.T) {
+	service, db := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, db, 5)
+	ctx := context.Background()
+
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	db.Create(&models.Loan{Borrower: "Late", DueDate: &past, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 2}}})
+	db.Create(&models.Loan{Borrower: "OnTime", DueDate: &future, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+
+	count, err := service.NotifyOverdue(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("NotifyOverdue failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 notification, got %d", count)
+	}
+
+	// A second run must not notify the same loan again
+	count, err = service.NotifyOverdue(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("NotifyOverdue failed: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("expected 0 notifications on second run, got %d", count)
+	}
+
+	var notifications []models.Notification
+	db.Find(&notifications)
+	if len(notifications) != 1 {
+		t.Fatalf("expected 1 stored notification, got %d", len(notifications))
+	}
+	if notifications[0].Type != models.NotificationTypeLoanOverdue {
+		t.Errorf("expected type %s, got %s", models.NotificationTypeLoanOverdue, notifications[0].Type)
+	}
+}
+
+func TestLoanService_Overdue(t *testing.T) {
+	service, db := setupLoanServiceTest(t)
+	inv := createLoanTestInventory(t, db, 5)
+	now := time.Now()
+
+	past := now.Add(-50 * time.Hour)
+	future := now.Add(24 * time.Hour)
+	returned := now.Add(-time.Hour)
+	db.Create(&models.Loan{Borrower: "Late", DueDate: &past, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}, {InventoryID: inv.ID, Quantity: 2}}})
+	db.Create(&models.Loan{Borrower: "OnTime", DueDate: &future, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+	db.Create(&models.Loan{Borrower: "Back", DueDate: &past, ReturnedAt: &returned, Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}})
+
+	overdue, err := service.Overdue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("Overdue failed: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].Borrower != "Late" {
+		t.Fatalf("expected only the late loan, got %+v", overdue)
+	}
+	if overdue[0].DaysOverdue != 2 || overdue[0].Cards != 3 {
+		t.Errorf("expected 2 days overdue with 3 cards, got %d days and %d cards", overdue[0].DaysOverdue, overdue[0].Cards)
+	}
+}
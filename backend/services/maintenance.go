@@ -0,0 +1,333 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// reindexBatchSize is how many cards are re-derived per transaction during a reindex
+const reindexBatchSize = 1000
+
+// ErrReindexRunning is returned when a reindex is requested while one is pending or running
+var ErrReindexRunning = errors.New("a reindex job is already running")
+
+// ReindexJobMetadata is stored in job.Metadata while a reindex runs
+type ReindexJobMetadata struct {
+	Phase          string   `json:"phase"` // "card_columns", "aggregates", "indexes", "completed"
+	Step           int      `json:"step"`
+	TotalSteps     int      `json:"total_steps"`
+	TotalCards     int      `json:"total_cards"`
+	ProcessedCards int      `json:"processed_cards"`
+	FailedCards    int      `json:"failed_cards"`
+	StandardSets   []string `json:"standard_sets,omitempty"`
+}
+
+// reindexSteps is the number of phases before "completed"
+const reindexSteps = 3
+
+// MaintenanceService rebuilds data derived from the stored cards, for databases
+// that predate a feature and would otherwise need a fresh bulk import, and runs
+// SQLite's VACUUM, ANALYZE and integrity check
+type MaintenanceService struct {
+	db              *gorm.DB
+	jobService      *JobService
+	standardService *StandardLegalityService
+}
+
+// NewMaintenanceService creates a new maintenance service
+func NewMaintenanceService(db *gorm.DB, jobService *JobService) *MaintenanceService {
+	return &MaintenanceService{
+		db:              db,
+		jobService:      jobService,
+		standardService: NewStandardLegalityService(db),
+	}
+}
+
+// CreateReindexJob creates a reindex job, or returns ErrReindexRunning if one hasn't finished
+func (s *MaintenanceService) CreateReindexJob(ctx context.Context) (*models.Job, error) {
+	var running int64
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("type = ? AND status IN ?", models.JobTypeReindex,
+			[]models.JobStatus{models.JobStatusPending, models.JobStatusInProgress}).
+		Count(&running).Error; err != nil {
+		return nil, fmt.Errorf("checking for running reindex: %w", err)
+	}
+	if running > 0 {
+		return nil, ErrReindexRunning
+	}
+
+	metadata, err := json.Marshal(ReindexJobMetadata{Phase: "pending", TotalSteps: reindexSteps})
+	if err != nil {
+		return nil, err
+	}
+	return s.jobService.Create(ctx, models.JobTypeReindex, string(metadata))
+}
+
+// Reindex re-derives the extracted card columns and content hashes from RawJSON,
+// recalculates Standard-legal sets, then rebuilds indexes and query planner statistics
+func (s *MaintenanceService) Reindex(ctx context.Context, jobID uint) error {
+	ctx, release := s.jobService.Cancellable(ctx, jobID)
+	defer release()
+
+	if err := s.jobService.Start(ctx, jobID); err != nil {
+		return fmt.Errorf("starting reindex job: %w", err)
+	}
+
+	metadata := ReindexJobMetadata{TotalSteps: reindexSteps}
+	if err := s.reindex(ctx, jobID, &metadata); err != nil {
+		// The job's context may be cancelled, but recording the outcome still has to happen
+		cleanupCtx := context.WithoutCancel(ctx)
+		if failErr := s.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+			slog.ErrorContext(ctx, "failed to mark job as failed", "component", "maintenance", "job_id", jobID, "error", failErr)
+		}
+		s.updateJobMetadata(cleanupCtx, jobID, metadata)
+		return err
+	}
+
+	metadata.Phase = "completed"
+	s.updateJobMetadata(ctx, jobID, metadata)
+	if err := s.jobService.Complete(ctx, jobID); err != nil {
+		return fmt.Errorf("completing reindex job: %w", err)
+	}
+
+	slog.InfoContext(ctx, "reindex completed", "component", "maintenance", "job_id", jobID,
+		"cards", metadata.ProcessedCards, "failed", metadata.FailedCards)
+	return nil
+}
+
+func (s *MaintenanceService) reindex(ctx context.Context, jobID uint, metadata *ReindexJobMetadata) error {
+	// Step 1: extracted columns and content hashes
+	var total int64
+	if err := s.db.WithContext(ctx).Model(&models.Card{}).Count(&total).Error; err != nil {
+		return fmt.Errorf("counting cards: %w", err)
+	}
+	metadata.Phase, metadata.Step, metadata.TotalCards = "card_columns", 1, int(total)
+	s.updateJobMetadata(ctx, jobID, *metadata)
+
+	lastID := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("reindex cancelled: %w", err)
+		}
+
+		var cards []models.Card
+		if err := s.db.WithContext(ctx).Select("scryfall_id", "raw_json").
+			Where("scryfall_id > ?", lastID).
+			Order("scryfall_id").
+			Limit(reindexBatchSize).
+			Find(&cards).Error; err != nil {
+			return fmt.Errorf("loading cards: %w", err)
+		}
+		if len(cards) == 0 {
+			break
+		}
+		lastID = cards[len(cards)-1].ScryfallID
+
+		failed, err := s.rederiveCards(ctx, cards)
+		if err != nil {
+			return err
+		}
+		metadata.ProcessedCards += len(cards) - failed
+		metadata.FailedCards += failed
+		s.updateJobMetadata(ctx, jobID, *metadata)
+	}
+
+	// Step 2: aggregates derived from card legalities
+	metadata.Phase, metadata.Step = "aggregates", 2
+	s.updateJobMetadata(ctx, jobID, *metadata)
+	codes, err := s.standardService.Recalculate(ctx)
+	if err != nil {
+		return err
+	}
+	metadata.StandardSets = codes
+
+	// Step 3: indexes and planner statistics
+	metadata.Phase, metadata.Step = "indexes", 3
+	s.updateJobMetadata(ctx, jobID, *metadata)
+	for _, statement := range []string{"REINDEX", "ANALYZE"} {
+		if err := s.db.WithContext(ctx).Exec(statement).Error; err != nil {
+			return fmt.Errorf("running %s: %w", statement, err)
+		}
+	}
+
+	return nil
+}
+
+// rederiveCards rewrites one batch's extracted columns from RawJSON and returns how
+// many cards could not be parsed; those keep their current values
+func (s *MaintenanceService) rederiveCards(ctx context.Context, cards []models.Card) (int, error) {
+	failed := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range cards {
+			card := &cards[i]
+			if err := card.PopulateColumns(); err != nil {
+				slog.WarnContext(ctx, "failed to re-derive card columns", "component", "maintenance", "scryfall_id", card.ScryfallID, "error", err)
+				failed++
+				continue
+			}
+			// UpdateColumns skips the hooks, which would only re-validate unchanged RawJSON
+			if err := tx.Model(&models.Card{}).Where("scryfall_id = ?", card.ScryfallID).UpdateColumns(map[string]interface{}{
+				"collector_number": card.CollectorNumber,
+				"rarity":           card.Rarity,
+				"cmc":              card.CMC,
+				"colors":           card.Colors,
+				"color_identity":   card.ColorIdentity,
+				"card_type":        card.CardType,
+				"price_usd":        card.PriceUSD,
+				"price_usd_foil":   card.PriceUSDFoil,
+				"price_usd_etched": card.PriceUSDEtched,
+				"price_eur":        card.PriceEUR,
+				"price_eur_foil":   card.PriceEURFoil,
+				"price_tix":        card.PriceTix,
+				"content_hash":     models.CardContentHash(card.RawJSON),
+			}).Error; err != nil {
+				return fmt.Errorf("updating card %s: %w", card.ScryfallID, err)
+			}
+		}
+		return nil
+	})
+	return failed, err
+}
+
+func (s *MaintenanceService) updateJobMetadata(ctx context.Context, jobID uint, metadata ReindexJobMetadata) {
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to marshal job metadata", "component", "maintenance", "error", err)
+		return
+	}
+	if err := s.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
+		slog.WarnContext(ctx, "failed to update job metadata", "component", "maintenance", "error", err)
+	}
+}
+
+// DatabaseMaintenanceResult reports a VACUUM or ANALYZE run
+// tygo:export
+type DatabaseMaintenanceResult struct {
+	Operation  string `json:"operation"` // "vacuum" or "analyze"
+	DurationMs int64  `json:"duration_ms"`
+	SizeBefore int64  `json:"size_before"` // Bytes
+	SizeAfter  int64  `json:"size_after"`  // Bytes
+}
+
+// IntegrityCheckResult reports the outcome of PRAGMA integrity_check
+// tygo:export
+type IntegrityCheckResult struct {
+	OK         bool     `json:"ok"`
+	Problems   []string `json:"problems"` // At most 100, as reported by SQLite
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// Vacuum rebuilds the database file, returning the space freed pages hold to the
+// filesystem. It fails with ErrJobsActive while any job is pending or running. The
+// database is locked for the whole rebuild, so every request waits on it.
+func (s *MaintenanceService) Vacuum(ctx context.Context) (*DatabaseMaintenanceResult, error) {
+	return s.runStatement(ctx, "vacuum", "VACUUM")
+}
+
+// Analyze refreshes the query planner's statistics. It fails with ErrJobsActive while
+// any job is pending or running.
+func (s *MaintenanceService) Analyze(ctx context.Context) (*DatabaseMaintenanceResult, error) {
+	return s.runStatement(ctx, "analyze", "ANALYZE")
+}
+
+// runStatement runs a maintenance statement once no jobs are active, measuring the
+// database size around it
+func (s *MaintenanceService) runStatement(ctx context.Context, operation, statement string) (*DatabaseMaintenanceResult, error) {
+	if err := s.ensureNoActiveJobs(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &DatabaseMaintenanceResult{Operation: operation}
+	sizeBefore, err := s.databaseSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.SizeBefore = sizeBefore
+
+	started := time.Now()
+	if err := s.db.WithContext(ctx).Exec(statement).Error; err != nil {
+		return nil, fmt.Errorf("running %s: %w", statement, err)
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+
+	if result.SizeAfter, err = s.databaseSize(ctx); err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "database maintenance finished", "component", "maintenance", "operation", operation,
+		"duration_ms", result.DurationMs, "size_before", result.SizeBefore, "size_after", result.SizeAfter)
+	return result, nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check. It fails with ErrJobsActive while any
+// job is pending or running.
+func (s *MaintenanceService) IntegrityCheck(ctx context.Context) (*IntegrityCheckResult, error) {
+	if err := s.ensureNoActiveJobs(ctx); err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	var rows []string
+	if err := s.db.WithContext(ctx).Raw("PRAGMA integrity_check").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("running integrity check: %w", err)
+	}
+
+	// A healthy database reports a single "ok" row; anything else lists the problems
+	result := &IntegrityCheckResult{OK: len(rows) == 1 && rows[0] == "ok", Problems: []string{}}
+	if !result.OK {
+		result.Problems = rows
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	return result, nil
+}
+
+// RunScheduledVacuum is the scheduled task entry point for Vacuum. Active jobs skip
+// the run until the next interval rather than failing it.
+func (s *MaintenanceService) RunScheduledVacuum(ctx context.Context) error {
+	if _, err := s.Vacuum(ctx); err != nil {
+		if errors.Is(err, ErrJobsActive) {
+			slog.InfoContext(ctx, "skipping scheduled vacuum while jobs are active", "component", "maintenance")
+			return nil
+		}
+		return fmt.Errorf("scheduled vacuum: %w", err)
+	}
+
+	settings := settingsStore(s.db)
+	if err := settings.SetTime(ctx, "db_vacuum_last_run", time.Now()); err != nil {
+		slog.WarnContext(ctx, "failed to persist db_vacuum_last_run", "component", "maintenance", "error", err)
+	}
+	return nil
+}
+
+// ensureNoActiveJobs returns ErrJobsActive when any job is pending or running
+func (s *MaintenanceService) ensureNoActiveJobs(ctx context.Context) error {
+	var active int64
+	if err := s.db.WithContext(ctx).Model(&models.Job{}).
+		Where("status IN ?", []models.JobStatus{models.JobStatusPending, models.JobStatusInProgress}).
+		Count(&active).Error; err != nil {
+		return fmt.Errorf("checking for active jobs: %w", err)
+	}
+	if active > 0 {
+		return ErrJobsActive
+	}
+	return nil
+}
+
+// databaseSize returns the size of the database in bytes, from its page count
+func (s *MaintenanceService) databaseSize(ctx context.Context) (int64, error) {
+	var pageCount, pageSize int64
+	if err := s.db.WithContext(ctx).Raw("PRAGMA page_count").Scan(&pageCount).Error; err != nil {
+		return 0, fmt.Errorf("reading page count: %w", err)
+	}
+	if err := s.db.WithContext(ctx).Raw("PRAGMA page_size").Scan(&pageSize).Error; err != nil {
+		return 0, fmt.Errorf("reading page size: %w", err)
+	}
+	return pageCount * pageSize, nil
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationService records and retrieves user-facing notifications, and delivers
+// the ones raised with Notify to external channels
+type NotificationService struct {
+	db       *gorm.DB
+	channels []NotificationChannel // Added with AddChannel, on top of those in settings
+}
+
+// ChannelTestResult is the outcome of sending a test message to one channel
+// tygo:export
+type ChannelTestResult struct {
+	Channel string `json:"channel"`
+	Error   string `json:"error,omitempty"` // Empty when the message was sent
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// Create records a new notification
+func (s *NotificationService) Create(ctx context.Context, notificationType models.NotificationType, title, message string) (*models.Notification, error) {
+	notification := &models.Notification{
+		Type:    notificationType,
+		Title:   title,
+		Message: message,
+	}
+
+	if err := s.db.WithContext(ctx).Create(notification).Error; err != nil {
+		return nil, fmt.Errorf("creating %s notification: %w", notificationType, err)
+	}
+
+	return notification, nil
+}
+
+// AddChannel registers a channel Notify delivers to besides those configured in settings
+func (s *NotificationService) AddChannel(channel NotificationChannel) {
+	s.channels = append(s.channels, channel)
+}
+
+// Channels returns the channels configured in settings followed by added ones
+func (s *NotificationService) Channels(ctx context.Context) []NotificationChannel {
+	return append(NotificationChannelsFromSettings(ctx, s.db), s.channels...)
+}
+
+// Notify records a notification and sends it to every channel. Channel failures are
+// logged rather than returned, since the notification itself was recorded.
+func (s *NotificationService) Notify(ctx context.Context, notificationType models.NotificationType, title, message string) (*models.Notification, error) {
+	notification, err := s.Create(ctx, notificationType, title, message)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range s.Channels(ctx) {
+		if err := channel.Send(ctx, title, message); err != nil {
+			slog.WarnContext(ctx, "failed to send notification", "component", "notifications",
+				"channel", channel.Name(), "notification_id", notification.ID, "error", err)
+		}
+	}
+	return notification, nil
+}
+
+// TestChannels sends a test message to every channel without recording a notification
+func (s *NotificationService) TestChannels(ctx context.Context) []ChannelTestResult {
+	results := []ChannelTestResult{}
+	for _, channel := range s.Channels(ctx) {
+		result := ChannelTestResult{Channel: channel.Name()}
+		if err := channel.Send(ctx, "ShowMyCards test notification", "Notifications from ShowMyCards will arrive here."); err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// List retrieves notifications with pagination, newest first
+func (s *NotificationService) List(ctx context.Context, page, pageSize int, unreadOnly bool) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.Notification{})
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting notifications: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// MarkRead marks a notification as read
+func (s *NotificationService) MarkRead(ctx context.Context, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.WithContext(ctx).First(&notification, id).Error; err != nil {
+		return nil, fmt.Errorf("getting notification %d: %w", id, err)
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.WithContext(ctx).Model(&notification).UpdateColumn("read_at", now).Error; err != nil {
+			return nil, fmt.Errorf("marking notification %d read: %w", id, err)
+		}
+		notification.ReadAt = &now
+	}
+
+	return &notification, nil
+}
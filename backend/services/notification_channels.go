@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultSMTPPort is the default for notify_smtp_port: SMTP submission with STARTTLS
+const DefaultSMTPPort = 587
+
+// NotificationChannel delivers notifications outside the app
+type NotificationChannel interface {
+	// Name identifies the channel in logs and test results
+	Name() string
+	// Send delivers one notification
+	Send(ctx context.Context, title, message string) error
+}
+
+// channelHTTPClient is shared by the HTTP-based channels
+var channelHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// NotificationChannelsFromSettings returns a channel for each one configured in settings:
+// email when notify_smtp_host and notify_email_to are set, ntfy when notify_ntfy_url
+// is, and Discord when notify_discord_webhook_url is
+func NotificationChannelsFromSettings(ctx context.Context, db *gorm.DB) []NotificationChannel {
+	settings := settingsStore(db)
+	get := func(key string) string {
+		value, _ := settings.Get(ctx, key)
+		return strings.TrimSpace(value)
+	}
+
+	var channels []NotificationChannel
+	if host, to := get("notify_smtp_host"), get("notify_email_to"); host != "" && to != "" {
+		channels = append(channels, &EmailChannel{
+			Host:     host,
+			Port:     settings.GetInt(ctx, "notify_smtp_port", DefaultSMTPPort),
+			Username: get("notify_smtp_username"),
+			Password: get("notify_smtp_password"),
+			From:     get("notify_email_from"),
+			To:       splitAddresses(to),
+		})
+	}
+	if url := get("notify_ntfy_url"); url != "" {
+		channels = append(channels, &NtfyChannel{URL: url, Token: get("notify_ntfy_token")})
+	}
+	if url := get("notify_discord_webhook_url"); url != "" {
+		channels = append(channels, &DiscordChannel{WebhookURL: url})
+	}
+	return channels
+}
+
+// splitAddresses splits a comma-separated address list, dropping empty entries
+func splitAddresses(list string) []string {
+	var addresses []string
+	for _, address := range strings.Split(list, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// EmailChannel sends notifications by SMTP. STARTTLS is used when the server offers
+// it; authentication needs it unless the server is on localhost.
+type EmailChannel struct {
+	Host     string
+	Port     int
+	Username string // Authenticates with PLAIN when set
+	Password string
+	From     string // Defaults to Username
+	To       []string
+}
+
+// Name identifies the channel
+func (c *EmailChannel) Name() string { return "email" }
+
+// Send emails the notification to every recipient
+func (c *EmailChannel) Send(ctx context.Context, title, message string) error {
+	from := c.From
+	if from == "" {
+		from = c.Username
+	}
+	if from == "" {
+		return fmt.Errorf("email channel needs notify_email_from or notify_smtp_username")
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(c.To, ", "))
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", title))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	body.WriteString(strings.ReplaceAll(message, "\n", "\r\n"))
+	body.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+	envelopeFrom := envelopeAddress(from)
+	recipients := make([]string, len(c.To))
+	for i, to := range c.To {
+		recipients[i] = envelopeAddress(to)
+	}
+
+	addr := net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+	// smtp.SendMail has no context; run it so a stalled server can't outlast ctx
+	done := make(chan error, 1)
+	go func() { done <- smtp.SendMail(addr, auth, envelopeFrom, recipients, body.Bytes()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("sending email via %s: %w", addr, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// envelopeAddress returns the bare address of "Name <address>", or address unchanged
+func envelopeAddress(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
+
+// NtfyChannel publishes notifications to an ntfy topic, e.g. https://ntfy.sh/my-topic
+type NtfyChannel struct {
+	URL   string
+	Token string // Access token for protected topics
+}
+
+// Name identifies the channel
+func (c *NtfyChannel) Name() string { return "ntfy" }
+
+// Send publishes the notification with its title
+func (c *NtfyChannel) Send(ctx context.Context, title, message string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, strings.NewReader(message))
+	if err != nil {
+		return fmt.Errorf("building ntfy request: %w", err)
+	}
+	req.Header.Set("Title", title)
+	req.Header.Set("Tags", "warning")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return doChannelRequest(req, "ntfy")
+}
+
+// DiscordChannel posts notifications to a Discord channel webhook
+type DiscordChannel struct {
+	WebhookURL string
+}
+
+// discordMaxContent is Discord's limit on a message's content
+const discordMaxContent = 2000
+
+// Name identifies the channel
+func (c *DiscordChannel) Name() string { return "discord" }
+
+// Send posts the notification as a message with a bold title
+func (c *DiscordChannel) Send(ctx context.Context, title, message string) error {
+	content := "**" + title + "**\n" + message
+	if runes := []rune(content); len(runes) > discordMaxContent {
+		content = string(runes[:discordMaxContent-1]) + "…"
+	}
+	body, err := json.Marshal(map[string]string{"content": content})
+	if err != nil {
+		return fmt.Errorf("encoding discord message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doChannelRequest(req, "discord")
+}
+
+// doChannelRequest sends a channel's request, treating any non-2xx response as failure
+func doChannelRequest(req *http.Request, channel string) error {
+	resp, err := channelHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", channel, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", channel, resp.StatusCode)
+	}
+	return nil
+}
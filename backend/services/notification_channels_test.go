@@ -0,0 +1,189 @@
+package services
+
+import (
+	"backend/models"
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupNotificationChannelTest(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Setting{}, &models.Notification{}, &models.Job{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestNotificationChannelsFromSettings(t *testing.T) {
+	db := setupNotificationChannelTest(t)
+	ctx := context.Background()
+
+	if channels := NotificationChannelsFromSettings(ctx, db); len(channels) != 0 {
+		t.Fatalf("expected no channels without settings, got %d", len(channels))
+	}
+
+	for key, value := range map[string]string{
+		"notify_smtp_host":           "smtp.example.com",
+		"notify_email_to":            "me@example.com, Alerts <alerts@example.com>",
+		"notify_ntfy_url":            "https://ntfy.sh/cards",
+		"notify_discord_webhook_url": "https://discord.com/api/webhooks/1/x",
+	} {
+		db.Create(&models.Setting{Key: key, Value: value})
+	}
+
+	channels := NotificationChannelsFromSettings(ctx, db)
+	if len(channels) != 3 {
+		t.Fatalf("expected email, ntfy and discord, got %d channels", len(channels))
+	}
+	email, ok := channels[0].(*EmailChannel)
+	if !ok || email.Port != DefaultSMTPPort || len(email.To) != 2 || email.To[1] != "Alerts <alerts@example.com>" {
+		t.Errorf("unexpected email channel %+v", channels[0])
+	}
+	if channels[1].Name() != "ntfy" || channels[2].Name() != "discord" {
+		t.Errorf("unexpected channels %s, %s", channels[1].Name(), channels[2].Name())
+	}
+}
+
+func TestNtfyChannel_Send(t *testing.T) {
+	var title, auth, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		title, auth = r.Header.Get("Title"), r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	channel := &NtfyChannel{URL: server.URL + "/cards", Token: "tk_123"}
+	if err := channel.Send(context.Background(), "Import failed", "Job 3 failed"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if title != "Import failed" || auth != "Bearer tk_123" || body != "Job 3 failed" {
+		t.Errorf("unexpected request: title %q, auth %q, body %q", title, auth, body)
+	}
+}
+
+func TestDiscordChannel_Send(t *testing.T) {
+	var message struct {
+		Content string `json:"content"`
+	}
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	channel := &DiscordChannel{WebhookURL: server.URL}
+	if err := channel.Send(context.Background(), "Import failed", "Job 3 failed"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if message.Content != "**Import failed**\nJob 3 failed" {
+		t.Errorf("unexpected content %q", message.Content)
+	}
+
+	status = http.StatusNotFound
+	if err := channel.Send(context.Background(), "x", "y"); err == nil || err.Error() != "discord returned status 404" {
+		t.Errorf("expected a status error, got %v", err)
+	}
+}
+
+// fakeSMTPServer accepts one message and returns the envelope and data it received
+func fakeSMTPServer(t *testing.T) (host string, port int, received chan []string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received = make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var lines []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			switch command := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); command {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				for {
+					data, err := reader.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					lines = append(lines, strings.TrimRight(data, "\r\n"))
+				}
+				reply("250 Queued")
+			case "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestEmailChannel_Send(t *testing.T) {
+	host, port, received := fakeSMTPServer(t)
+	channel := &EmailChannel{Host: host, Port: port, From: "ShowMyCards <cards@example.com>", To: []string{"Me <me@example.com>"}}
+
+	if err := channel.Send(context.Background(), "Import failed", "Job 3 failed\nTry again"); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	lines := <-received
+	joined := strings.Join(lines, "\n")
+	for _, want := range []string{
+		"MAIL FROM:<cards@example.com>",
+		"RCPT TO:<me@example.com>",
+		"Subject: Import failed",
+		"To: Me <me@example.com>",
+		"Job 3 failed\nTry again",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in the message, got:\n%s", want, joined)
+		}
+	}
+}
+
+func TestEmailChannel_Send_NeedsSender(t *testing.T) {
+	channel := &EmailChannel{Host: "localhost", Port: 25, To: []string{"me@example.com"}}
+	if err := channel.Send(context.Background(), "x", "y"); err == nil {
+		t.Error("expected an error without a sender")
+	}
+}
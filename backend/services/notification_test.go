@@ -0,0 +1,86 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupNotificationServiceTest(t *testing.T) *NotificationService {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Notification{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return NewNotificationService(db)
+}
+
+func TestNotificationService_CreateAndList(t *testing.T) {
+	service := setupNotificationServiceTest(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"first", "second"} {
+		if _, err := service.Create(ctx, models.NotificationTypeLoanOverdue, title, "message"); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	notifications, total, err := service.List(ctx, 1, 20, false)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("expected 2 notifications, got %d", total)
+	}
+	if len(notifications) != 2 {
+		t.Errorf("expected 2 notifications returned, got %d", len(notifications))
+	}
+}
+
+func TestNotificationService_Create_InvalidType(t *testing.T) {
+	service := setupNotificationServiceTest(t)
+
+	if _, err := service.Create(context.Background(), "bogus", "title", ""); err == nil {
+		t.Error("expected error for invalid notification type")
+	}
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	service := setupNotificationServiceTest(t)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, models.NotificationTypeLoanOverdue, "title", "message")
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	read, err := service.MarkRead(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+	if read.ReadAt == nil {
+		t.Error("expected read_at to be set")
+	}
+
+	_, total, err := service.List(ctx, 1, 20, true)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 0 {
+		t.Errorf("expected 0 unread notifications, got %d", total)
+	}
+
+	if _, err := service.MarkRead(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
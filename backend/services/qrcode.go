@@ -0,0 +1,499 @@
+package services
+
+import (
+	"errors"
+)
+
+// ErrQRCodeTooLong is returned when text does not fit the largest supported QR code
+var ErrQRCodeTooLong = errors.New("text is too long for a QR code")
+
+// qrVersion describes the error correction blocks of one QR code version at level M,
+// the level used for every code: it survives about 15% damage, enough for a scuffed
+// label on a box, while keeping codes for short URLs small
+type qrVersion struct {
+	ecPerBlock int
+	blocks     []int // Data codewords in each block
+	alignment  []int // Alignment pattern centre coordinates
+}
+
+// qrVersions lists versions 1 to 10, which hold up to 213 bytes at level M
+var qrVersions = []qrVersion{
+	{10, []int{16}, nil},
+	{16, []int{28}, []int{6, 18}},
+	{26, []int{44}, []int{6, 22}},
+	{18, []int{32, 32}, []int{6, 26}},
+	{24, []int{43, 43}, []int{6, 30}},
+	{16, []int{27, 27, 27, 27}, []int{6, 34}},
+	{18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	{22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	{22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	{26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// dataCodewords is the number of data codewords the version holds
+func (v qrVersion) dataCodewords() int {
+	total := 0
+	for _, n := range v.blocks {
+		total += n
+	}
+	return total
+}
+
+// qrCode is an encoded QR code: a square of dark (true) and light modules, without
+// the quiet zone renderers add around it
+type qrCode struct {
+	size    int
+	modules [][]bool
+}
+
+// dark reports whether the module at column x, row y is dark
+func (q *qrCode) dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// encodeQR encodes text in byte mode at error correction level M, in the smallest
+// version it fits, with the mask that scores the lowest penalty
+func encodeQR(text string) (*qrCode, error) {
+	data := []byte(text)
+	number := 0
+	for i, v := range qrVersions {
+		countBits := 8
+		if i+1 >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) <= 8*v.dataCodewords() {
+			number = i + 1
+			break
+		}
+	}
+	if number == 0 {
+		return nil, ErrQRCodeTooLong
+	}
+	version := qrVersions[number-1]
+
+	codewords := interleaveQRBlocks(version, qrDataCodewords(data, number, version.dataCodewords()))
+
+	builder := newQRBuilder(number, version)
+	builder.drawFunctionPatterns()
+	builder.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := range 8 {
+		builder.applyMask(mask)
+		builder.drawFormatBits(mask)
+		if penalty := builder.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		builder.applyMask(mask) // Masking is its own inverse
+	}
+	builder.applyMask(bestMask)
+	builder.drawFormatBits(bestMask)
+
+	return &qrCode{size: builder.size, modules: builder.modules}, nil
+}
+
+// qrDataCodewords builds the byte mode segment for data, then pads it to capacity codewords
+func qrDataCodewords(data []byte, number, capacity int) []byte {
+	var bits qrBitBuffer
+	bits.append(0b0100, 4) // Byte mode
+	if number >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	// Terminator, then zero bits up to a byte boundary
+	bits.append(0, min(4, capacity*8-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := 0; len(codewords) < capacity; pad++ {
+		codewords = append(codewords, [2]byte{0xEC, 0x11}[pad%2])
+	}
+	return codewords
+}
+
+// interleaveQRBlocks splits data into the version's blocks, appends each block's error
+// correction codewords, and interleaves the blocks as the code stores them
+func interleaveQRBlocks(version qrVersion, data []byte) []byte {
+	divisor := reedSolomonDivisor(version.ecPerBlock)
+	dataBlocks := make([][]byte, len(version.blocks))
+	ecBlocks := make([][]byte, len(version.blocks))
+	offset, longest := 0, 0
+	for i, n := range version.blocks {
+		dataBlocks[i] = data[offset : offset+n]
+		ecBlocks[i] = reedSolomonRemainder(dataBlocks[i], divisor)
+		offset += n
+		longest = max(longest, n)
+	}
+
+	result := make([]byte, 0, len(data)+version.ecPerBlock*len(version.blocks))
+	for i := range longest {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := range version.ecPerBlock {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// qrBitBuffer accumulates bits, most significant first
+type qrBitBuffer []bool
+
+// append adds the low n bits of value
+func (b *qrBitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+// bytes packs the bits into bytes; the buffer must hold whole bytes
+func (b qrBitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo the QR code polynomial x^8+x^4+x^3+x^2+1
+func gfMultiply(x, y byte) byte {
+	var z byte
+	for i := 7; i >= 0; i-- {
+		carry := z & 0x80
+		z <<= 1
+		if carry != 0 {
+			z ^= 0x1D
+		}
+		if (y>>i)&1 == 1 {
+			z ^= x
+		}
+	}
+	return z
+}
+
+// reedSolomonDivisor returns the coefficients of the generator polynomial of the
+// given degree, highest power first with the leading 1 omitted
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// reedSolomonRemainder computes the error correction codewords for data
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// qrBuilder draws a QR code, tracking which modules belong to function patterns so
+// data and masking leave them alone
+type qrBuilder struct {
+	number     int
+	version    qrVersion
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// newQRBuilder starts an all-light code of the given version
+func newQRBuilder(number int, version qrVersion) *qrBuilder {
+	size := number*4 + 17
+	b := &qrBuilder{number: number, version: version, size: size}
+	b.modules = make([][]bool, size)
+	b.isFunction = make([][]bool, size)
+	for y := range size {
+		b.modules[y] = make([]bool, size)
+		b.isFunction[y] = make([]bool, size)
+	}
+	return b
+}
+
+// setFunction sets a function pattern module at column x, row y
+func (b *qrBuilder) setFunction(x, y int, dark bool) {
+	b.modules[y][x] = dark
+	b.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the timing, finder, alignment and version patterns and
+// reserves the format areas, drawn per mask later
+func (b *qrBuilder) drawFunctionPatterns() {
+	for i := range b.size {
+		b.setFunction(6, i, i%2 == 0)
+		b.setFunction(i, 6, i%2 == 0)
+	}
+
+	b.drawFinder(3, 3)
+	b.drawFinder(b.size-4, 3)
+	b.drawFinder(3, b.size-4)
+
+	positions := b.version.alignment
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the three corners taken by finder patterns
+			last := len(positions) - 1
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			b.drawAlignment(x, y)
+		}
+	}
+
+	b.drawFormatBits(0)
+	b.drawVersionBits()
+}
+
+// drawFinder draws a finder pattern and its light separator centred on (x, y)
+func (b *qrBuilder) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= b.size || yy < 0 || yy >= b.size {
+				continue
+			}
+			distance := max(abs(dx), abs(dy))
+			b.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawAlignment draws an alignment pattern centred on (x, y)
+func (b *qrBuilder) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			b.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// qrFormatBits returns the 15-bit format information for level M and mask
+func qrFormatBits(mask int) int {
+	data := 0b00<<3 | mask // Level M
+	remainder := data
+	for range 10 {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information, and the dark module
+func (b *qrBuilder) drawFormatBits(mask int) {
+	bits := qrFormatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		b.setFunction(8, i, bit(i))
+	}
+	b.setFunction(8, 7, bit(6))
+	b.setFunction(8, 8, bit(7))
+	b.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		b.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		b.setFunction(b.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		b.setFunction(8, b.size-15+i, bit(i))
+	}
+	b.setFunction(8, b.size-8, true)
+}
+
+// qrVersionBits returns the 18-bit version information carried by versions 7 and up
+func qrVersionBits(number int) int {
+	remainder := number
+	for range 12 {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	return number<<12 | remainder
+}
+
+// drawVersionBits draws both copies of the version information, for versions 7 and up
+func (b *qrBuilder) drawVersionBits() {
+	if b.number < 7 {
+		return
+	}
+	bits := qrVersionBits(b.number)
+	for i := range 18 {
+		dark := (bits>>i)&1 == 1
+		a, c := b.size-11+i%3, i/3
+		b.setFunction(a, c, dark)
+		b.setFunction(c, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order of two-module columns,
+// right to left, skipping the vertical timing pattern
+func (b *qrBuilder) drawCodewords(codewords []byte) {
+	i := 0
+	for right := b.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := range b.size {
+			for j := range 2 {
+				x := right - j
+				y := vertical
+				if (right+1)&2 == 0 {
+					y = b.size - 1 - vertical // Upward column
+				}
+				if b.isFunction[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				b.modules[y][x] = (codewords[i>>3]>>(7-(i&7)))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by mask
+func (b *qrBuilder) applyMask(mask int) {
+	for y := range b.size {
+		for x := range b.size {
+			if b.isFunction[y][x] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			b.modules[y][x] = b.modules[y][x] != flip
+		}
+	}
+}
+
+// penalty scores the code with the four rules of the QR specification: long runs,
+// 2x2 blocks, finder-like patterns, and an unbalanced dark proportion
+func (b *qrBuilder) penalty() int {
+	score := 0
+	line := make([]bool, b.size)
+	for _, horizontal := range []bool{true, false} {
+		for i := range b.size {
+			for j := range b.size {
+				if horizontal {
+					line[j] = b.modules[i][j]
+				} else {
+					line[j] = b.modules[j][i]
+				}
+			}
+			score += qrLinePenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := range b.size {
+		for x := range b.size {
+			if b.modules[y][x] {
+				dark++
+			}
+			if x+1 < b.size && y+1 < b.size {
+				c := b.modules[y][x]
+				if c == b.modules[y][x+1] && c == b.modules[y+1][x] && c == b.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+
+	total := b.size * b.size
+	deviation := abs(dark*20-total*10) / total // Steps of 5% away from half dark
+	score += deviation * 10
+	return score
+}
+
+// qrLinePenalty scores one row or column for runs of five or more same-coloured
+// modules and for 1:1:3:1:1 finder-like patterns with four light modules on a side
+func qrLinePenalty(line []bool) int {
+	score := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			score += 3 + run - 5
+		}
+		run = 1
+	}
+
+	pattern := []bool{true, false, true, true, true, false, true}
+	for i := 0; i+len(pattern) <= len(line); i++ {
+		matches := true
+		for j, dark := range pattern {
+			if line[i+j] != dark {
+				matches = false
+				break
+			}
+		}
+		if matches && (qrLightRun(line, i-4, i) || qrLightRun(line, i+len(pattern), i+len(pattern)+4)) {
+			score += 40
+		}
+	}
+	return score
+}
+
+// qrLightRun reports whether line[from:to] is light, treating modules beyond the
+// edges as the light quiet zone
+func qrLightRun(line []bool, from, to int) bool {
+	for i := from; i < to; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD" at version 1-M, the worked example of the QR specification tutorials
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := reedSolomonRemainder(data, reedSolomonDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestQRFormatAndVersionBits(t *testing.T) {
+	if got := qrFormatBits(0); got != 0b101010000010010 {
+		t.Errorf("mask 0: got %015b", got)
+	}
+	if got := qrFormatBits(4); got != 0b100010111111001 {
+		t.Errorf("mask 4: got %015b", got)
+	}
+	if got := qrVersionBits(7); got != 0b000111110010010100 {
+		t.Errorf("version 7: got %018b", got)
+	}
+}
+
+func TestQRDataCodewords(t *testing.T) {
+	got := qrDataCodewords([]byte("a"), 1, 16)
+	want := []byte{0x40, 0x16, 0x10, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC, 0x11, 0xEC}
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected %x, got %x", want, got)
+	}
+}
+
+func TestEncodeQR(t *testing.T) {
+	text := "https://cards.example.com/storage/12"
+	code, err := encodeQR(text)
+	if err != nil {
+		t.Fatalf("encodeQR failed: %v", err)
+	}
+	// 36 bytes need version 3 (29 modules); version 2 holds 28 bytes of data
+	if code.size != 29 {
+		t.Fatalf("expected size 29, got %d", code.size)
+	}
+
+	// Finder pattern rings in three corners, timing pattern, and the dark module
+	for _, corner := range [][2]int{{0, 0}, {code.size - 7, 0}, {0, code.size - 7}} {
+		x, y := corner[0], corner[1]
+		if !code.dark(x, y) || !code.dark(x+6, y+6) || code.dark(x+1, y+1) || !code.dark(x+3, y+3) {
+			t.Errorf("expected a finder pattern at %v", corner)
+		}
+	}
+	for i := 8; i < code.size-8; i++ {
+		if code.dark(i, 6) != (i%2 == 0) || code.dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern broken at %d", i)
+		}
+	}
+	if !code.dark(8, code.size-8) {
+		t.Error("expected the dark module")
+	}
+
+	// Reading the code back gives the encoded codewords
+	version := qrVersions[2]
+	want := interleaveQRBlocks(version, qrDataCodewords([]byte(text), 3, version.dataCodewords()))
+	if got := readQRCodewords(t, code, version); !bytes.Equal(got, want) {
+		t.Errorf("codewords read back differ:\nwant %v\ngot  %v", want, got)
+	}
+}
+
+// TestEncodeQR_MatchesReferenceEncoder compares whole module matrices with ones from
+// Kazuhiko Arase's reference QRCode encoder (the JavaScript port bundled with npm as
+// qrcode-terminal), at level M with each mask forced. testdata/qr_reference.txt holds
+// all eight masks of a version 3 code, plus version 8 and 10 codes, which carry
+// version information and, at 10, a 16-bit length. Mask choice isn't compared: the
+// reference scores masks with its own variant of the penalty rules.
+func TestEncodeQR_MatchesReferenceEncoder(t *testing.T) {
+	for _, ref := range readQRReference(t) {
+		code, err := encodeQR(ref.text)
+		if err != nil {
+			t.Fatalf("encodeQR(%q) failed: %v", ref.text, err)
+		}
+		number := (code.size - 17) / 4
+		if number != ref.number {
+			t.Errorf("%q: expected version %d, got %d", ref.text, ref.number, number)
+			continue
+		}
+
+		version := qrVersions[number-1]
+		builder := newQRBuilder(number, version)
+		builder.drawFunctionPatterns()
+		builder.drawCodewords(interleaveQRBlocks(version, qrDataCodewords([]byte(ref.text), number, version.dataCodewords())))
+		builder.applyMask(ref.mask)
+		builder.drawFormatBits(ref.mask)
+
+		for y := range builder.size {
+			for x := range builder.size {
+				if builder.modules[y][x] != ref.modules[y][x] {
+					t.Fatalf("version %d mask %d: module (%d, %d) differs from the reference", number, ref.mask, x, y)
+				}
+			}
+		}
+	}
+}
+
+// qrReference is one code from testdata/qr_reference.txt
+type qrReference struct {
+	text    string
+	number  int
+	mask    int
+	modules [][]bool
+}
+
+// readQRReference parses testdata/qr_reference.txt: blocks of a "text" line, a
+// "version N mask M" line, then one row of # (dark) and . (light) per line
+func readQRReference(t *testing.T) []qrReference {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join("testdata", "qr_reference.txt"))
+	if err != nil {
+		t.Fatalf("failed to read reference codes: %v", err)
+	}
+
+	var refs []qrReference
+	for _, block := range strings.Split(strings.TrimSpace(string(content)), "\n\n") {
+		lines := strings.Split(block, "\n")
+		var ref qrReference
+		ref.text = strings.TrimPrefix(lines[0], "text ")
+		if _, err := fmt.Sscanf(lines[1], "version %d mask %d", &ref.number, &ref.mask); err != nil {
+			t.Fatalf("bad reference header %q: %v", lines[1], err)
+		}
+		for _, line := range lines[2:] {
+			row := make([]bool, len(line))
+			for x, module := range line {
+				row[x] = module == '#'
+			}
+			ref.modules = append(ref.modules, row)
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) == 0 {
+		t.Fatal("no reference codes")
+	}
+	return refs
+}
+
+func TestEncodeQR_TooLong(t *testing.T) {
+	if _, err := encodeQR(strings.Repeat("a", 214)); !errors.Is(err, ErrQRCodeTooLong) {
+		t.Errorf("expected ErrQRCodeTooLong, got %v", err)
+	}
+	code, err := encodeQR(strings.Repeat("a", 213))
+	if err != nil || code.size != 57 {
+		t.Errorf("expected 213 bytes to fit version 10, got %v", err)
+	}
+}
+
+// readQRCodewords decodes the format information to find the mask, then reads the
+// data modules back in placement order
+func readQRCodewords(t *testing.T, code *qrCode, version qrVersion) []byte {
+	t.Helper()
+
+	format := 0
+	positions := [][2]int{{8, 0}, {8, 1}, {8, 2}, {8, 3}, {8, 4}, {8, 5}, {8, 7}, {8, 8}, {7, 8}, {5, 8}, {4, 8}, {3, 8}, {2, 8}, {1, 8}, {0, 8}}
+	for i, p := range positions {
+		if code.dark(p[0], p[1]) {
+			format |= 1 << i
+		}
+	}
+	mask := -1
+	for m := range 8 {
+		if qrFormatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format bits %015b match no mask", format)
+	}
+
+	builder := newQRBuilder((code.size-17)/4, version)
+	builder.drawFunctionPatterns()
+	for y := range code.size {
+		copy(builder.modules[y], code.modules[y])
+	}
+	builder.applyMask(mask)
+
+	var bits qrBitBuffer
+	for right := code.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := range code.size {
+			for j := range 2 {
+				x, y := right-j, vertical
+				if (right+1)&2 == 0 {
+					y = code.size - 1 - vertical
+				}
+				if !builder.isFunction[y][x] {
+					bits = append(bits, builder.modules[y][x])
+				}
+			}
+		}
+	}
+	total := version.dataCodewords() + version.ecPerBlock*len(version.blocks)
+	return bits[:total*8].bytes()
+}
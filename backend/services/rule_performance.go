@@ -0,0 +1,155 @@
+package services
+
+import (
+	"backend/models"
+	"backend/rules"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultSlowRuleThresholdMicros is the average evaluation time per card above which
+// a rule is reported as slow, used when the setting is missing or invalid
+const defaultSlowRuleThresholdMicros = 1000
+
+// RulePerformanceEntry is one rule's evaluation cost during the most recent resort
+// tygo:export
+type RulePerformanceEntry struct {
+	RuleID        uint    `json:"rule_id"`
+	Name          string  `json:"name"`
+	Priority      int     `json:"priority"`
+	Evaluations   int64   `json:"evaluations"`
+	TotalMs       float64 `json:"total_ms"`
+	AverageMicros float64 `json:"average_micros"`
+	MaxMicros     int64   `json:"max_micros"`
+	Share         float64 `json:"share"` // Fraction of all rule evaluation time, 0-1
+	Slow          bool    `json:"slow"`
+}
+
+// RulePerformanceReport lists rules by evaluation cost, most expensive first
+// tygo:export
+type RulePerformanceReport struct {
+	RecordedAt          *time.Time             `json:"recorded_at,omitempty"` // When the figures were taken; nil before the first resort
+	SlowThresholdMicros int                    `json:"slow_threshold_micros"`
+	TotalMs             float64                `json:"total_ms"`
+	Rules               []RulePerformanceEntry `json:"rules"`
+	Warnings            []string               `json:"warnings"`
+}
+
+// RulePerformanceService stores and reports per-rule evaluation times from resorts
+type RulePerformanceService struct {
+	db *gorm.DB
+}
+
+// NewRulePerformanceService creates a new rule performance service
+func NewRulePerformanceService(db *gorm.DB) *RulePerformanceService {
+	return &RulePerformanceService{db: db}
+}
+
+// Record replaces the stored figures with the timings from a resort. Rules that
+// were not evaluated this time (deleted or disabled since) are dropped.
+func (s *RulePerformanceService) Record(ctx context.Context, timings map[uint]rules.RuleTiming) error {
+	if len(timings) == 0 {
+		return nil
+	}
+
+	records := make([]models.RulePerformance, 0, len(timings))
+	ruleIDs := make([]uint, 0, len(timings))
+	for ruleID, timing := range timings {
+		records = append(records, models.RulePerformance{
+			SortingRuleID: ruleID,
+			Evaluations:   int64(timing.Evaluations),
+			TotalMicros:   timing.Total.Microseconds(),
+			MaxMicros:     timing.Max.Microseconds(),
+		})
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sorting_rule_id NOT IN ?", ruleIDs).Delete(&models.RulePerformance{}).Error; err != nil {
+			return fmt.Errorf("removing stale rule performance: %w", err)
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "sorting_rule_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"evaluations", "total_micros", "max_micros", "updated_at"}),
+		}).Create(&records).Error; err != nil {
+			return fmt.Errorf("recording rule performance: %w", err)
+		}
+		return nil
+	})
+}
+
+// Report returns the most recent figures for rules that still exist, flagging rules
+// whose average evaluation time reaches the slow_rule_threshold_micros setting
+func (s *RulePerformanceService) Report(ctx context.Context) (RulePerformanceReport, error) {
+	settings := settingsStore(s.db)
+	threshold := settings.GetInt(ctx, "slow_rule_threshold_micros", defaultSlowRuleThresholdMicros)
+	if threshold <= 0 {
+		threshold = defaultSlowRuleThresholdMicros
+	}
+
+	report := RulePerformanceReport{
+		SlowThresholdMicros: threshold,
+		Rules:               []RulePerformanceEntry{},
+		Warnings:            []string{},
+	}
+
+	var rows []struct {
+		models.RulePerformance
+		Name     string
+		Priority int
+	}
+	if err := s.db.WithContext(ctx).Table("rule_performances rp").
+		Select("rp.*, sr.name, sr.priority").
+		Joins("JOIN sorting_rules sr ON sr.id = rp.sorting_rule_id").
+		Scan(&rows).Error; err != nil {
+		return report, fmt.Errorf("loading rule performance: %w", err)
+	}
+
+	var totalMicros int64
+	for _, row := range rows {
+		totalMicros += row.TotalMicros
+		if report.RecordedAt == nil || row.UpdatedAt.After(*report.RecordedAt) {
+			recordedAt := row.UpdatedAt
+			report.RecordedAt = &recordedAt
+		}
+	}
+	report.TotalMs = float64(totalMicros) / 1000
+
+	for _, row := range rows {
+		entry := RulePerformanceEntry{
+			RuleID:      row.SortingRuleID,
+			Name:        row.Name,
+			Priority:    row.Priority,
+			Evaluations: row.Evaluations,
+			TotalMs:     float64(row.TotalMicros) / 1000,
+			MaxMicros:   row.MaxMicros,
+		}
+		if row.Evaluations > 0 {
+			entry.AverageMicros = float64(row.TotalMicros) / float64(row.Evaluations)
+		}
+		if totalMicros > 0 {
+			entry.Share = float64(row.TotalMicros) / float64(totalMicros)
+		}
+		entry.Slow = entry.AverageMicros >= float64(threshold)
+		report.Rules = append(report.Rules, entry)
+	}
+
+	slices.SortFunc(report.Rules, func(a, b RulePerformanceEntry) int {
+		return cmp.Or(cmp.Compare(b.TotalMs, a.TotalMs), cmp.Compare(a.RuleID, b.RuleID))
+	})
+	for _, entry := range report.Rules {
+		if entry.Slow {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"rule %q averages %.0fµs per card (%.0f%% of evaluation time); simplify its expression or move it later so fewer cards reach it",
+				entry.Name, entry.AverageMicros, entry.Share*100))
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"backend/models"
+	"backend/rules"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRulePerformanceTest(t *testing.T) (*gorm.DB, *RulePerformanceService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.SortingRule{}, &models.RulePerformance{}, &models.Setting{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return db, NewRulePerformanceService(db)
+}
+
+func createPerformanceRules(t *testing.T, db *gorm.DB, names ...string) []models.SortingRule {
+	t.Helper()
+	location := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	created := make([]models.SortingRule, 0, len(names))
+	for i, name := range names {
+		rule := models.SortingRule{Name: name, Priority: i * 10, Expression: "true", StorageLocationID: location.ID, Enabled: true}
+		if err := db.Create(&rule).Error; err != nil {
+			t.Fatalf("failed to create rule: %v", err)
+		}
+		created = append(created, rule)
+	}
+	return created
+}
+
+func TestRulePerformanceService_Report(t *testing.T) {
+	db, service := setupRulePerformanceTest(t)
+	ctx := context.Background()
+	sortingRules := createPerformanceRules(t, db, "Cheap", "Expensive")
+
+	err := service.Record(ctx, map[uint]rules.RuleTiming{
+		sortingRules[0].ID: {Evaluations: 100, Total: 10 * time.Millisecond, Max: time.Millisecond},
+		sortingRules[1].ID: {Evaluations: 40, Total: 90 * time.Millisecond, Max: 5 * time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	report, err := service.Report(ctx)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.RecordedAt == nil {
+		t.Error("expected a recorded time")
+	}
+	if report.SlowThresholdMicros != defaultSlowRuleThresholdMicros || report.TotalMs != 100 {
+		t.Errorf("unexpected report totals: %+v", report)
+	}
+	if len(report.Rules) != 2 {
+		t.Fatalf("expected 2 rules, got %+v", report.Rules)
+	}
+
+	// Most expensive first
+	expensive, cheap := report.Rules[0], report.Rules[1]
+	if expensive.Name != "Expensive" || expensive.AverageMicros != 2250 || expensive.Share != 0.9 || !expensive.Slow {
+		t.Errorf("unexpected entry for the expensive rule: %+v", expensive)
+	}
+	if cheap.Name != "Cheap" || cheap.AverageMicros != 100 || cheap.Slow {
+		t.Errorf("unexpected entry for the cheap rule: %+v", cheap)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], `"Expensive"`) {
+		t.Errorf("expected one warning about the expensive rule, got %v", report.Warnings)
+	}
+}
+
+func TestRulePerformanceService_RecordReplacesPreviousResort(t *testing.T) {
+	db, service := setupRulePerformanceTest(t)
+	ctx := context.Background()
+	sortingRules := createPerformanceRules(t, db, "Kept", "Dropped")
+
+	if err := service.Record(ctx, map[uint]rules.RuleTiming{
+		sortingRules[0].ID: {Evaluations: 5, Total: time.Millisecond},
+		sortingRules[1].ID: {Evaluations: 5, Total: time.Millisecond},
+	}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := service.Record(ctx, map[uint]rules.RuleTiming{
+		sortingRules[0].ID: {Evaluations: 8, Total: 2 * time.Millisecond},
+	}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	var records []models.RulePerformance
+	if err := db.Find(&records).Error; err != nil {
+		t.Fatalf("failed to load records: %v", err)
+	}
+	if len(records) != 1 || records[0].SortingRuleID != sortingRules[0].ID || records[0].Evaluations != 8 {
+		t.Errorf("expected only the latest figures for the kept rule, got %+v", records)
+	}
+
+	// An empty resort keeps the previous figures
+	if err := service.Record(ctx, nil); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	report, err := service.Report(ctx)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if len(report.Rules) != 1 {
+		t.Errorf("expected previous figures kept, got %+v", report.Rules)
+	}
+}
+
+func TestRulePerformanceService_ThresholdSetting(t *testing.T) {
+	db, service := setupRulePerformanceTest(t)
+	ctx := context.Background()
+	sortingRules := createPerformanceRules(t, db, "Rule")
+
+	if err := db.Create(&models.Setting{Key: "slow_rule_threshold_micros", Value: "50"}).Error; err != nil {
+		t.Fatalf("failed to create setting: %v", err)
+	}
+	if err := service.Record(ctx, map[uint]rules.RuleTiming{
+		sortingRules[0].ID: {Evaluations: 10, Total: time.Millisecond},
+	}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	report, err := service.Report(ctx)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.SlowThresholdMicros != 50 || !report.Rules[0].Slow {
+		t.Errorf("expected the rule flagged slow under a 50µs threshold, got %+v", report)
+	}
+}
+
+func TestRulePerformanceService_Empty(t *testing.T) {
+	_, service := setupRulePerformanceTest(t)
+
+	report, err := service.Report(context.Background())
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if report.RecordedAt != nil || len(report.Rules) != 0 || len(report.Warnings) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"backend/models"
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,11 +25,14 @@ type ScheduledTask struct {
 	// Name is the unique identifier for this task
 	Name string
 
+	// Label is the human-readable name shown by the API. Defaults to Name
+	Label string
+
 	// Interval is how often the task should run (e.g., 24*time.Hour for daily)
 	Interval time.Duration
 
-	// TimeOfDay is the preferred time to run (format: "HH:MM", e.g., "03:00")
-	// If empty, runs whenever interval has elapsed
+	// TimeOfDay is the preferred time to run (format: "HH:MM", e.g., "03:00"),
+	// in the scheduler_timezone setting's zone. If empty, runs whenever interval has elapsed
 	TimeOfDay string
 
 	// EnabledSettingKey is the settings key to check if task is enabled (optional)
@@ -35,8 +42,37 @@ type ScheduledTask struct {
 	// LastRunSettingKey is the settings key where last run time is persisted
 	LastRunSettingKey string
 
-	// Run is the function to execute when the task should run
-	Run func(ctx context.Context)
+	// CronSettingKey is the settings key holding an optional cron expression, read in
+	// the scheduler_timezone setting's zone. When it is set the task runs at the times
+	// it matches instead of by Interval and TimeOfDay.
+	CronSettingKey string
+
+	// JobType is the type of job the task starts, if any; its latest job is reported
+	// with the task
+	JobType models.JobType
+
+	// Run is the function to execute when the task should run. Errors are logged and
+	// counted towards failure alerts; work that runs as a job reports failures there instead.
+	Run func(ctx context.Context) error
+}
+
+var (
+	// ErrTaskNotFound is returned when no task has the requested name
+	ErrTaskNotFound = errors.New("scheduled task not found")
+	// ErrTaskRunning is returned when a task is triggered while it is already running
+	ErrTaskRunning = errors.New("scheduled task is already running")
+)
+
+// TaskStatus describes a registered task's schedule and recent runs
+type TaskStatus struct {
+	Task      ScheduledTask
+	Enabled   bool
+	TimeOfDay string // Resolved "HH:MM", or empty when the task runs whenever its interval has elapsed
+	Cron      string // Cron expression replacing Interval and TimeOfDay, if one is set
+	Running   bool
+	LastRun   *time.Time
+	NextRun   time.Time // In the scheduler time zone
+	Location  *time.Location
 }
 
 // Scheduler handles scheduled tasks
@@ -45,6 +81,7 @@ type Scheduler struct {
 	setDataService  *SetDataService
 	jobService      *JobService
 	settingsService *SettingsService
+	failureAlerts   *FailureAlertService
 	ticker          *time.Ticker
 	done            chan bool
 	started         atomic.Bool
@@ -69,22 +106,29 @@ func NewScheduler(bulkDataService *BulkDataService, setDataService *SetDataServi
 	s.tasks = []ScheduledTask{
 		{
 			Name:              "bulk_data_update",
+			Label:             "Bulk Data Auto-Update",
 			Interval:          24 * time.Hour,
 			TimeOfDay:         "bulk_data_update_time",
 			EnabledSettingKey: "bulk_data_auto_update",
 			LastRunSettingKey: "bulk_data_last_update",
+			CronSettingKey:    "bulk_data_update_cron",
+			JobType:           models.JobTypeBulkDataImport,
 			Run:               s.runBulkDataUpdate,
 		},
 		{
 			Name:              "set_data_update",
+			Label:             "Set Data Auto-Update",
 			Interval:          14 * 24 * time.Hour, // Every 2 weeks
 			TimeOfDay:         "set_data_update_time",
 			EnabledSettingKey: "set_data_auto_update",
 			LastRunSettingKey: "set_data_last_update",
+			CronSettingKey:    "set_data_update_cron",
+			JobType:           models.JobTypeSetDataImport,
 			Run:               s.runSetDataUpdate,
 		},
 		{
 			Name:              "job_cleanup",
+			Label:             "Job History Cleanup",
 			Interval:          24 * time.Hour,
 			TimeOfDay:         "00:00", // Midnight
 			LastRunSettingKey: "job_cleanup_last_run",
@@ -95,6 +139,18 @@ func NewScheduler(bulkDataService *BulkDataService, setDataService *SetDataServi
 	return s
 }
 
+// SetFailureAlerts reports task results to failureAlerts, which notifies the configured
+// channels when a task keeps failing
+func (s *Scheduler) SetFailureAlerts(failureAlerts *FailureAlertService) {
+	s.failureAlerts = failureAlerts
+}
+
+// AddTask registers an additional task owned by another service.
+// Tasks must be added before Start is called.
+func (s *Scheduler) AddTask(task ScheduledTask) {
+	s.tasks = append(s.tasks, task)
+}
+
 // Start begins the scheduler loop
 func (s *Scheduler) Start(ctx context.Context) {
 	s.started.Store(true)
@@ -102,7 +158,7 @@ func (s *Scheduler) Start(ctx context.Context) {
 	checkInterval := time.Duration(s.settingsService.GetInt(ctx, "scheduler_check_interval_minutes", int(DefaultSchedulerCheckInterval.Minutes()))) * time.Minute
 	s.ticker = time.NewTicker(checkInterval)
 
-	slog.Info("scheduler started", "component", "scheduler", "check_interval", checkInterval)
+	slog.InfoContext(ctx, "scheduler started", "component", "scheduler", "check_interval", checkInterval)
 
 	// Run initial checks immediately on startup
 	go s.checkAndRunTasks(ctx)
@@ -114,7 +170,7 @@ func (s *Scheduler) Start(ctx context.Context) {
 		for {
 			select {
 			case <-ctx.Done():
-				slog.Info("scheduler stopping", "component", "scheduler")
+				slog.InfoContext(ctx, "scheduler stopping", "component", "scheduler")
 				s.ticker.Stop()
 				s.done <- true
 				return
@@ -161,16 +217,22 @@ func (s *Scheduler) checkTask(ctx context.Context, task ScheduledTask, isCatchup
 
 	now := time.Now()
 
-	// For scheduled runs (not catchup), check if we're in the time window
-	if !isCatchup {
-		if !s.isInTimeWindow(ctx, task.TimeOfDay, now) {
+	if schedule := s.cronSchedule(ctx, task); schedule != nil {
+		if !s.cronDue(ctx, task, schedule, now, isCatchup) {
 			return
 		}
-	}
+	} else {
+		// For scheduled runs (not catchup), check if we're in the time window
+		if !isCatchup {
+			if !s.isInTimeWindow(ctx, task.TimeOfDay, now) {
+				return
+			}
+		}
 
-	// Check if task should run based on interval
-	if !s.shouldRunTask(ctx, task, now) {
-		return
+		// Check if task should run based on interval
+		if !s.shouldRunTask(ctx, task, now) {
+			return
+		}
 	}
 
 	// Set lastRun before execution to prevent re-entry during long-running tasks.
@@ -182,49 +244,235 @@ func (s *Scheduler) checkTask(ctx context.Context, task ScheduledTask, isCatchup
 
 	// Log and run
 	if isCatchup {
-		slog.Info("running catch-up task", "component", "scheduler", "task", task.Name, "interval", task.Interval)
+		slog.InfoContext(ctx, "running catch-up task", "component", "scheduler", "task", task.Name, "interval", task.Interval)
 	} else {
-		slog.Info("running scheduled task", "component", "scheduler", "task", task.Name, "interval", task.Interval)
+		slog.InfoContext(ctx, "running scheduled task", "component", "scheduler", "task", task.Name, "interval", task.Interval)
+	}
+	s.execute(ctx, task)
+}
+
+// execute runs a task, reporting its outcome
+func (s *Scheduler) execute(ctx context.Context, task ScheduledTask) {
+	err := task.Run(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduled task failed", "component", "scheduler", "task", task.Name, "error", err)
+	}
+	s.failureAlerts.TaskFinished(ctx, task.Name, err)
+}
+
+// RunTask starts the named task in the background now, whether or not it is enabled
+// or due. ctx must outlive the run. It fails with ErrTaskNotFound or ErrTaskRunning.
+func (s *Scheduler) RunTask(ctx context.Context, name string) error {
+	task, ok := s.task(name)
+	if !ok {
+		return ErrTaskNotFound
+	}
+	if _, loaded := s.runningTasks.LoadOrStore(task.Name, true); loaded {
+		return ErrTaskRunning
 	}
 
-	task.Run(ctx)
+	// Counts as the task's run for its interval, as a scheduled run would
+	s.lastRunMu.Lock()
+	s.lastRun[task.Name] = time.Now()
+	s.lastRunMu.Unlock()
+
+	slog.InfoContext(ctx, "running task on request", "component", "scheduler", "task", task.Name)
+	err := s.jobService.Workers().Go(ctx, task.Name, func(ctx context.Context) error {
+		defer s.runningTasks.Delete(task.Name)
+		s.execute(ctx, task)
+		return nil
+	})
+	if err != nil {
+		s.runningTasks.Delete(task.Name)
+		return err
+	}
+	return nil
+}
+
+// task returns the registered task with the given name
+func (s *Scheduler) task(name string) (ScheduledTask, bool) {
+	for _, task := range s.tasks {
+		if task.Name == name {
+			return task, true
+		}
+	}
+	return ScheduledTask{}, false
 }
 
-// isInTimeWindow checks if we're within 5 minutes of the configured time
+// Tasks reports every registered task's schedule and recent runs, in registration order
+func (s *Scheduler) Tasks(ctx context.Context) []TaskStatus {
+	location := s.settingsService.GetLocation(ctx, "scheduler_timezone")
+	now := time.Now().In(location)
+
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if task.Label == "" {
+			task.Label = task.Name
+		}
+		status := TaskStatus{Task: task, Enabled: true, Location: location}
+		if task.EnabledSettingKey != "" {
+			status.Enabled = s.settingsService.GetBool(ctx, task.EnabledSettingKey, false)
+		}
+		if timeOfDay, err := s.resolveTimeOfDay(ctx, task.TimeOfDay); err == nil {
+			status.TimeOfDay = timeOfDay
+		}
+		_, status.Running = s.runningTasks.Load(task.Name)
+		status.LastRun = s.lastRunOf(ctx, task)
+		if schedule := s.cronSchedule(ctx, task); schedule != nil {
+			status.Cron = schedule.String()
+			status.NextRun = nextCronRun(schedule, status.LastRun, now)
+		} else {
+			status.NextRun = nextTaskRun(task.Interval, status.TimeOfDay, status.LastRun, now)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// lastRunOf returns when a task last started, from memory or its persisted setting,
+// whichever is later
+func (s *Scheduler) lastRunOf(ctx context.Context, task ScheduledTask) *time.Time {
+	s.lastRunMu.RLock()
+	lastRun, ok := s.lastRun[task.Name]
+	s.lastRunMu.RUnlock()
+
+	var latest *time.Time
+	if ok {
+		latest = &lastRun
+	}
+	if task.LastRunSettingKey != "" {
+		if persisted, err := s.settingsService.GetTime(ctx, task.LastRunSettingKey); err == nil && persisted != nil {
+			if latest == nil || persisted.After(*latest) {
+				latest = persisted
+			}
+		}
+	}
+	return latest
+}
+
+// nextTaskRun estimates when the scheduler will next run a task: once its interval
+// has elapsed since lastRun, at the next check or, with a time of day, in the next
+// 5-minute window at that time. Times are read in now's zone.
+func nextTaskRun(interval time.Duration, timeOfDay string, lastRun *time.Time, now time.Time) time.Time {
+	earliest := now
+	if lastRun != nil && lastRun.Add(interval).After(now) {
+		earliest = lastRun.Add(interval).In(now.Location())
+	}
+	if timeOfDay == "" {
+		return earliest
+	}
+
+	target, err := time.Parse("15:04", timeOfDay)
+	if err != nil {
+		return earliest
+	}
+	next := time.Date(earliest.Year(), earliest.Month(), earliest.Day(),
+		target.Hour(), target.Minute(), 0, 0, earliest.Location())
+	if !next.Add(timeWindow).After(earliest) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if next.Before(earliest) {
+		return earliest // Already inside today's window
+	}
+	return next
+}
+
+// isInTimeWindow checks if we're within 5 minutes of the configured time, read as a
+// wall-clock time in the scheduler_timezone setting's zone
 func (s *Scheduler) isInTimeWindow(ctx context.Context, timeOfDaySetting string, now time.Time) bool {
-	// Get the time string - either from settings or use as literal
-	var timeStr string
 	if timeOfDaySetting == "" {
 		return true // No specific time required
 	}
 
-	// Check if it's a settings key or a literal time
-	if len(timeOfDaySetting) == 5 && timeOfDaySetting[2] == ':' {
-		// Looks like a literal time (e.g., "00:00")
-		timeStr = timeOfDaySetting
-	} else {
-		// It's a settings key
-		var err error
-		timeStr, err = s.settingsService.Get(ctx, timeOfDaySetting)
-		if err != nil {
-			slog.Warn("failed to get time setting", "component", "scheduler", "setting", timeOfDaySetting, "error", err)
-			return false
-		}
+	timeStr, err := s.resolveTimeOfDay(ctx, timeOfDaySetting)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get time setting", "component", "scheduler", "setting", timeOfDaySetting, "error", err)
+		return false
 	}
 
 	// Parse the time
 	targetTime, err := time.Parse("15:04", timeStr)
 	if err != nil {
-		slog.Warn("invalid time format", "component", "scheduler", "setting", timeOfDaySetting, "error", err)
+		slog.WarnContext(ctx, "invalid time format", "component", "scheduler", "setting", timeOfDaySetting, "error", err)
 		return false
 	}
 
 	// Convert to minutes since midnight for comparison
+	now = now.In(s.settingsService.GetLocation(ctx, "scheduler_timezone"))
 	currentMinutes := now.Hour()*60 + now.Minute()
 	targetMinutes := targetTime.Hour()*60 + targetTime.Minute()
 
 	// Check if we're within the 5-minute window
-	return currentMinutes >= targetMinutes && currentMinutes < targetMinutes+5
+	return currentMinutes >= targetMinutes && currentMinutes < targetMinutes+int(timeWindow.Minutes())
+}
+
+// timeWindow is how long after its time of day, or a time its cron expression
+// matches, a task may still start
+const timeWindow = 5 * time.Minute
+
+// cronSchedule returns the task's cron schedule, or nil when it has none. An invalid
+// expression is logged and the task falls back to its interval.
+func (s *Scheduler) cronSchedule(ctx context.Context, task ScheduledTask) *CronSchedule {
+	if task.CronSettingKey == "" {
+		return nil
+	}
+	expr, err := s.settingsService.Get(ctx, task.CronSettingKey)
+	if err != nil || strings.TrimSpace(expr) == "" {
+		return nil
+	}
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid cron expression, using the task's interval", "component", "scheduler",
+			"task", task.Name, "setting", task.CronSettingKey, "error", err)
+		return nil
+	}
+	return schedule
+}
+
+// cronDue checks whether a time the schedule matches has come since the task last
+// ran. Scheduled checks only start runs matched in the last timeWindow; catch-up runs
+// one that was missed while the server was down.
+func (s *Scheduler) cronDue(ctx context.Context, task ScheduledTask, schedule *CronSchedule, now time.Time, isCatchup bool) bool {
+	lastRun := s.lastRunOf(ctx, task)
+	var from time.Time
+	switch {
+	case isCatchup && lastRun == nil:
+		return false // Nothing was missed
+	case isCatchup:
+		from = *lastRun
+	default:
+		from = now.Add(-timeWindow)
+		if lastRun != nil && lastRun.After(from) {
+			from = *lastRun
+		}
+	}
+
+	next := schedule.Next(from.In(s.settingsService.GetLocation(ctx, "scheduler_timezone")))
+	return !next.IsZero() && !next.After(now)
+}
+
+// nextCronRun estimates when the scheduler will next run a cron task: at the next
+// matching time, or at the next check when one in the current window hasn't run yet
+func nextCronRun(schedule *CronSchedule, lastRun *time.Time, now time.Time) time.Time {
+	from := now.Add(-timeWindow)
+	if lastRun != nil && lastRun.After(from) {
+		from = lastRun.In(now.Location())
+	}
+	next := schedule.Next(from)
+	if next.Before(now) {
+		return now
+	}
+	return next
+}
+
+// resolveTimeOfDay returns a task's TimeOfDay as "HH:MM", reading it from settings
+// when it names a settings key rather than being a literal time
+func (s *Scheduler) resolveTimeOfDay(ctx context.Context, timeOfDay string) (string, error) {
+	// A literal time looks like "00:00"
+	if timeOfDay == "" || (len(timeOfDay) == 5 && timeOfDay[2] == ':') {
+		return timeOfDay, nil
+	}
+	return s.settingsService.Get(ctx, timeOfDay)
 }
 
 // shouldRunTask checks if a task should run based on its interval and last run time
@@ -257,14 +505,14 @@ func (s *Scheduler) shouldRunTask(ctx context.Context, task ScheduledTask, now t
 // runCatchupTasks checks for overdue tasks after startup
 func (s *Scheduler) runCatchupTasks(ctx context.Context) {
 	if !s.settingsService.GetBool(ctx, "scheduler_catchup_enabled", true) {
-		slog.Info("scheduler catch-up disabled", "component", "scheduler")
+		slog.InfoContext(ctx, "scheduler catch-up disabled", "component", "scheduler")
 		return
 	}
 
 	delaySeconds := s.settingsService.GetInt(ctx, "scheduler_catchup_delay_seconds", 60)
 	delay := time.Duration(delaySeconds) * time.Second
 
-	slog.Info("scheduler catch-up will check for overdue tasks", "component", "scheduler", "delay", delay)
+	slog.InfoContext(ctx, "scheduler catch-up will check for overdue tasks", "component", "scheduler", "delay", delay)
 
 	select {
 	case <-ctx.Done():
@@ -272,7 +520,7 @@ func (s *Scheduler) runCatchupTasks(ctx context.Context) {
 	case <-time.After(delay):
 	}
 
-	slog.Info("checking for overdue scheduled tasks", "component", "scheduler")
+	slog.InfoContext(ctx, "checking for overdue scheduled tasks", "component", "scheduler")
 
 	for _, task := range s.tasks {
 		s.checkTask(ctx, task, true)
@@ -281,56 +529,39 @@ func (s *Scheduler) runCatchupTasks(ctx context.Context) {
 
 // Task execution functions
 
-func (s *Scheduler) runBulkDataUpdate(ctx context.Context) {
+func (s *Scheduler) runBulkDataUpdate(ctx context.Context) error {
 	job, err := s.bulkDataService.CreateImportJob(ctx)
 	if err != nil {
-		slog.Error("error creating bulk data import job", "component", "scheduler", "error", err)
-		return
+		return fmt.Errorf("creating bulk data import job: %w", err)
 	}
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("panic in bulk data import", "component", "scheduler", "panic", r)
-			}
-		}()
-		if err := s.bulkDataService.DownloadAndImport(ctx, job.ID); err != nil {
-			slog.Error("error in bulk data import", "component", "scheduler", "error", err)
-		}
-	}()
+	return s.jobService.Go(ctx, job.ID, "bulk data import", func(ctx context.Context) error {
+		return s.bulkDataService.DownloadAndImport(ctx, job.ID)
+	})
 }
 
-func (s *Scheduler) runSetDataUpdate(ctx context.Context) {
+func (s *Scheduler) runSetDataUpdate(ctx context.Context) error {
 	job, err := s.setDataService.CreateImportJob(ctx)
 	if err != nil {
-		slog.Error("error creating set data import job", "component", "scheduler", "error", err)
-		return
+		return fmt.Errorf("creating set data import job: %w", err)
 	}
 
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				slog.Error("panic in set data import", "component", "scheduler", "panic", r)
-			}
-		}()
-		if err := s.setDataService.DownloadAndImport(ctx, job.ID); err != nil {
-			slog.Error("error in set data import", "component", "scheduler", "error", err)
-		}
-	}()
+	return s.jobService.Go(ctx, job.ID, "set data import", func(ctx context.Context) error {
+		return s.setDataService.DownloadAndImport(ctx, job.ID)
+	})
 }
 
-func (s *Scheduler) runJobCleanup(ctx context.Context) {
-	retentionDays := s.settingsService.GetInt(ctx, "job_cleanup_retention_days", DefaultJobCleanupRetentionDays)
-	deletedCount, err := s.jobService.CleanupOldJobs(ctx, retentionDays)
+func (s *Scheduler) runJobCleanup(ctx context.Context) error {
+	deletedCount, err := s.jobService.ApplyRetention(ctx, s.jobService.RetentionPolicy(ctx))
 	if err != nil {
-		slog.Error("error cleaning up jobs", "component", "scheduler", "error", err)
-		return
+		return fmt.Errorf("cleaning up jobs: %w", err)
 	}
 
 	// Persist completion time
 	if err := s.settingsService.SetTime(ctx, "job_cleanup_last_run", time.Now()); err != nil {
-		slog.Warn("failed to persist job_cleanup_last_run", "component", "scheduler", "error", err)
+		slog.WarnContext(ctx, "failed to persist job_cleanup_last_run", "component", "scheduler", "error", err)
 	}
 
-	slog.Info("cleaned up old jobs", "component", "scheduler", "deleted_count", deletedCount)
+	slog.InfoContext(ctx, "cleaned up old jobs", "component", "scheduler", "deleted_count", deletedCount)
+	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"backend/models"
 	"backend/scryfall"
 	"context"
+	"errors"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -32,6 +33,8 @@ func setupSchedulerTest(t *testing.T) (*Scheduler, *BulkDataService, *JobService
 		t.Fatalf("failed to create scryfall client: %v", err)
 	}
 	setDataService := NewSetDataService(db, jobService, settingsService, scryfallClient, t.TempDir())
+	bulkDataService.retryBackoff = RetryBackoff{Base: time.Millisecond, Max: time.Millisecond}
+	setDataService.retryBackoff = bulkDataService.retryBackoff
 	scheduler := NewScheduler(bulkDataService, setDataService, jobService, settingsService)
 
 	return scheduler, bulkDataService, jobService, settingsService, db
@@ -419,8 +422,9 @@ func TestScheduler_CheckTask_DisabledBySettings(t *testing.T) {
 		Name:              "test_disabled",
 		Interval:          1 * time.Millisecond,
 		EnabledSettingKey: "test_disabled_setting",
-		Run: func(ctx context.Context) {
+		Run: func(ctx context.Context) error {
 			ran = true
+			return nil
 		},
 	}
 
@@ -443,8 +447,9 @@ func TestScheduler_CheckTask_EnabledBySettings(t *testing.T) {
 		Interval:          1 * time.Millisecond,
 		TimeOfDay:         "",
 		EnabledSettingKey: "test_enabled_setting",
-		Run: func(ctx context.Context) {
+		Run: func(ctx context.Context) error {
 			ran = true
+			return nil
 		},
 	}
 
@@ -467,8 +472,9 @@ func TestScheduler_CheckTask_CatchupSkipsTimeWindow(t *testing.T) {
 		Interval:          1 * time.Millisecond,
 		TimeOfDay:         "03:00", // Specific time that is not now
 		EnabledSettingKey: "test_catchup_enabled",
-		Run: func(ctx context.Context) {
+		Run: func(ctx context.Context) error {
 			ran = true
+			return nil
 		},
 	}
 
@@ -490,9 +496,10 @@ func TestScheduler_CheckTask_PreventsDuplicateExecution(t *testing.T) {
 	task := ScheduledTask{
 		Name:     "test_dedup",
 		Interval: 1 * time.Millisecond,
-		Run: func(ctx context.Context) {
+		Run: func(ctx context.Context) error {
 			runCount.Add(1)
 			<-blocker // Block until released
+			return nil
 		},
 	}
 
@@ -537,3 +544,248 @@ func TestScheduler_Integration_DisabledTasks(t *testing.T) {
 		t.Errorf("expected 0 jobs with disabled tasks, got %d", total)
 	}
 }
+
+func TestScheduler_AddTask(t *testing.T) {
+	scheduler, _, _, _, _ := setupSchedulerTest(t)
+	before := len(scheduler.tasks)
+
+	scheduler.AddTask(ScheduledTask{
+		Name:     "extra_task",
+		Interval: time.Hour,
+		Run:      func(ctx context.Context) error { return nil },
+	})
+
+	if len(scheduler.tasks) != before+1 {
+		t.Fatalf("expected %d tasks, got %d", before+1, len(scheduler.tasks))
+	}
+	if scheduler.tasks[len(scheduler.tasks)-1].Name != "extra_task" {
+		t.Errorf("expected extra_task to be registered last, got %s", scheduler.tasks[len(scheduler.tasks)-1].Name)
+	}
+}
+
+func TestScheduler_IsInTimeWindow_Timezone(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+
+	// 03:00 UTC is noon in Tokyo
+	now := time.Date(2026, 1, 15, 3, 0, 0, 0, time.UTC)
+	settingsService.Set(ctx, "scheduler_timezone", "Asia/Tokyo")
+
+	if !scheduler.isInTimeWindow(ctx, "12:00", now) {
+		t.Error("expected 12:00 Tokyo time to be in window")
+	}
+	if scheduler.isInTimeWindow(ctx, "03:00", now) {
+		t.Error("expected 03:00 to be read as Tokyo time, not UTC")
+	}
+}
+
+// Task listing and manual runs
+
+func TestNextTaskRun(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, tokyo)
+	at := func(day, hour, minute int) *time.Time {
+		t := time.Date(2026, 1, day, hour, minute, 0, 0, tokyo)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		timeOfDay string
+		lastRun   *time.Time
+		expected  time.Time
+	}{
+		{"never run, later today", 24 * time.Hour, "12:00", nil, *at(15, 12, 0)},
+		{"never run, passed today", 24 * time.Hour, "03:00", nil, *at(16, 3, 0)},
+		{"inside the window", 24 * time.Hour, "09:58", nil, now},
+		{"ran today", 24 * time.Hour, "03:00", at(15, 3, 1), *at(16, 3, 1)},
+		{"every two weeks", 14 * 24 * time.Hour, "03:00", at(10, 3, 0), *at(24, 3, 0)},
+		{"interval only", 6 * time.Hour, "", at(15, 8, 0), *at(15, 14, 0)},
+		{"overdue", 6 * time.Hour, "", at(14, 8, 0), now},
+	}
+	for _, tt := range tests {
+		if got := nextTaskRun(tt.interval, tt.timeOfDay, tt.lastRun, now); !got.Equal(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestScheduler_Tasks(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+	settingsService.Set(ctx, "bulk_data_auto_update", "false")
+	settingsService.Set(ctx, "bulk_data_update_time", "05:30")
+	scheduler.AddTask(ScheduledTask{Name: "test_task", Interval: time.Hour, Run: func(ctx context.Context) error { return nil }})
+
+	tasks := scheduler.Tasks(ctx)
+	if len(tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %d", len(tasks))
+	}
+	bulk := tasks[0]
+	if bulk.Enabled || bulk.TimeOfDay != "05:30" || bulk.Task.Label != "Bulk Data Auto-Update" {
+		t.Errorf("unexpected bulk data task status %+v", bulk)
+	}
+	if cleanup := tasks[2]; !cleanup.Enabled || cleanup.TimeOfDay != "00:00" {
+		t.Errorf("expected job cleanup at midnight, got %+v", cleanup)
+	}
+	if added := tasks[3]; added.Task.Label != "test_task" || !added.Enabled || added.LastRun != nil {
+		t.Errorf("expected the added task to default its label and be enabled, got %+v", added)
+	}
+}
+
+func TestScheduler_RunTask(t *testing.T) {
+	scheduler, _, _, _, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	scheduler.AddTask(ScheduledTask{
+		Name:              "test_task",
+		Interval:          time.Hour,
+		EnabledSettingKey: "test_task_enabled", // Disabled, but runs on request anyway
+		Run: func(ctx context.Context) error {
+			close(done)
+			return nil
+		},
+	})
+
+	if err := scheduler.RunTask(ctx, "missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("expected ErrTaskNotFound, got %v", err)
+	}
+	if err := scheduler.RunTask(ctx, "test_task"); err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the task to run")
+	}
+
+	// The manual run counts as the interval's run
+	if scheduler.shouldRunTask(ctx, scheduler.tasks[len(scheduler.tasks)-1], time.Now()) {
+		t.Error("expected the task not to be due right after running")
+	}
+}
+
+func TestScheduler_CheckTask_Cron(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+
+	var runs atomic.Int32
+	task := ScheduledTask{
+		Name:              "test_cron",
+		Interval:          time.Hour,
+		TimeOfDay:         "03:00", // Ignored while the cron expression is set
+		LastRunSettingKey: "test_cron_last_run",
+		CronSettingKey:    "test_cron_expr",
+		Run: func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		},
+	}
+	settingsService.Set(ctx, "test_cron_expr", "* * * * *")
+
+	// A task that has never run has missed nothing
+	scheduler.checkTask(ctx, task, true)
+	if runs.Load() != 0 {
+		t.Fatal("expected catch-up not to run a task that has never run")
+	}
+
+	scheduler.checkTask(ctx, task, false)
+	if runs.Load() != 1 {
+		t.Fatalf("expected the task to run at a matching minute, got %d runs", runs.Load())
+	}
+
+	// It just ran, so the next match is a minute away
+	scheduler.checkTask(ctx, task, false)
+	if runs.Load() != 1 {
+		t.Errorf("expected no second run within the same minute, got %d runs", runs.Load())
+	}
+}
+
+func TestScheduler_CheckTask_CronCatchup(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+
+	ran := false
+	task := ScheduledTask{
+		Name:              "test_cron",
+		Interval:          time.Hour,
+		LastRunSettingKey: "test_cron_last_run",
+		CronSettingKey:    "test_cron_expr",
+		Run: func(ctx context.Context) error {
+			ran = true
+			return nil
+		},
+	}
+	settingsService.Set(ctx, "test_cron_expr", "0 3 * * 0")
+	settingsService.Set(ctx, "test_cron_last_run", time.Now().AddDate(0, 0, -8).Format(time.RFC3339))
+
+	// Outside the window, a scheduled check leaves the missed Sunday alone
+	if next := mustParseCron(t, "0 3 * * 0").Next(time.Now().Add(-timeWindow)); next.After(time.Now()) {
+		scheduler.checkTask(ctx, task, false)
+		if ran {
+			t.Fatal("expected the scheduled check not to run a missed time")
+		}
+	}
+
+	scheduler.checkTask(ctx, task, true)
+	if !ran {
+		t.Error("expected catch-up to run the Sunday missed since the last run")
+	}
+}
+
+func TestScheduler_CronSchedule_InvalidFallsBack(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+	task := ScheduledTask{Name: "test_cron", CronSettingKey: "test_cron_expr"}
+
+	if scheduler.cronSchedule(ctx, task) != nil {
+		t.Error("expected no schedule when the setting is unset")
+	}
+	settingsService.Set(ctx, "test_cron_expr", "not cron")
+	if scheduler.cronSchedule(ctx, task) != nil {
+		t.Error("expected an invalid expression to fall back to the interval")
+	}
+}
+
+func TestScheduler_Tasks_Cron(t *testing.T) {
+	scheduler, _, _, settingsService, _ := setupSchedulerTest(t)
+	ctx := context.Background()
+	settingsService.Set(ctx, "scheduler_timezone", "UTC")
+	settingsService.Set(ctx, "set_data_update_cron", "0 3 * * 0")
+
+	status := scheduler.Tasks(ctx)[1]
+	if status.Cron != "0 3 * * 0" {
+		t.Errorf("expected cron '0 3 * * 0', got %q", status.Cron)
+	}
+	next := status.NextRun.UTC()
+	if next.Weekday() != time.Sunday || next.Hour() != 3 || next.Minute() != 0 {
+		t.Errorf("expected the next run on a Sunday at 03:00, got %v", next)
+	}
+}
+
+func TestNextCronRun(t *testing.T) {
+	schedule := mustParseCron(t, "0 3 * * *")
+	now := time.Date(2026, 1, 15, 3, 2, 0, 0, time.UTC)
+
+	if got := nextCronRun(schedule, nil, now); !got.Equal(now) {
+		t.Errorf("expected a match in the current window to run at the next check, got %v", got)
+	}
+	ran := time.Date(2026, 1, 15, 3, 0, 30, 0, time.UTC)
+	if got, expected := nextCronRun(schedule, &ran, now), now.AddDate(0, 0, 1).Add(-2*time.Minute); !got.Equal(expected) {
+		t.Errorf("expected %v once the match has run, got %v", expected, got)
+	}
+}
+
+func mustParseCron(t *testing.T, expr string) *CronSchedule {
+	t.Helper()
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		t.Fatalf("ParseCron(%q) failed: %v", expr, err)
+	}
+	return schedule
+}
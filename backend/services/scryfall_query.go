@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrUnsupportedSearchSyntax is returned for a Scryfall query using syntax that can't be
+// answered from the local cards, which is then sent to Scryfall instead
+var ErrUnsupportedSearchSyntax = errors.New("search syntax not supported locally")
+
+// scryfallTermPattern splits a keyword term such as usd>=2 or o:"draw a card"
+var scryfallTermPattern = regexp.MustCompile(`^([a-zA-Z]+)(:|=|>=|<=|>|<|!=)(.+)$`)
+
+// scryfallColorNames are the color words Scryfall accepts in place of letters
+var scryfallColorNames = map[string]string{
+	"white": "w", "blue": "u", "black": "b", "red": "r", "green": "g", "colorless": "c",
+}
+
+// scryfallRarities maps Scryfall's rarity names and abbreviations to stored rarities
+var scryfallRarities = map[string]string{
+	"c": "common", "common": "common",
+	"u": "uncommon", "uncommon": "uncommon",
+	"r": "rare", "rare": "rare",
+	"m": "mythic", "mythic": "mythic",
+}
+
+// scryfallGames are the values game: accepts
+var scryfallGames = []string{"paper", "arena", "mtgo"}
+
+// ParseScryfallQuery translates a query in Scryfall's search syntax into local search
+// filters. It understands bare and quoted name words, and the c:, t:, r:, set:, usd,
+// o: and game: keywords with their long forms; terms are ANDed as on Scryfall. Any
+// other keyword, negation, OR or parentheses returns an error wrapping
+// ErrUnsupportedSearchSyntax.
+func ParseScryfallQuery(query string) (CardSearchFilters, error) {
+	var filters CardSearchFilters
+	terms, err := splitScryfallQuery(query)
+	if err != nil {
+		return filters, err
+	}
+	if len(terms) == 0 {
+		return filters, fmt.Errorf("%w: empty query", ErrUnsupportedSearchSyntax)
+	}
+
+	for _, term := range terms {
+		if strings.EqualFold(term, "or") || strings.HasPrefix(term, "-") || strings.ContainsAny(term, "()") || strings.HasPrefix(term, "!") {
+			return filters, fmt.Errorf("%w: %s", ErrUnsupportedSearchSyntax, term)
+		}
+
+		match := scryfallTermPattern.FindStringSubmatch(term)
+		if match == nil {
+			if strings.ContainsAny(term, ":=<>") {
+				return filters, fmt.Errorf("%w: %s", ErrUnsupportedSearchSyntax, term)
+			}
+			filters.Names = append(filters.Names, unquote(term))
+			continue
+		}
+		if err := applyScryfallTerm(&filters, strings.ToLower(match[1]), match[2], unquote(match[3])); err != nil {
+			return filters, fmt.Errorf("%w: %s", err, term)
+		}
+	}
+	return filters, nil
+}
+
+// applyScryfallTerm adds one keyword term to the filters
+func applyScryfallTerm(filters *CardSearchFilters, keyword, operator, value string) error {
+	lower := strings.ToLower(value)
+
+	switch keyword {
+	case "c", "color", "colors":
+		// On Scryfall c: means "at least these colors", as does the Colors filter
+		if operator != ":" && operator != ">=" {
+			return ErrUnsupportedSearchSyntax
+		}
+		if letter, ok := scryfallColorNames[lower]; ok {
+			lower = letter
+		}
+		if lower == "c" {
+			filters.Colors = append(filters.Colors, "c")
+			return nil
+		}
+		for _, letter := range lower {
+			if !strings.ContainsRune("wubrg", letter) {
+				return ErrUnsupportedSearchSyntax
+			}
+			filters.Colors = append(filters.Colors, string(letter))
+		}
+
+	case "t", "type":
+		if operator != ":" {
+			return ErrUnsupportedSearchSyntax
+		}
+		filters.TypeLines = append(filters.TypeLines, value)
+
+	case "o", "oracle":
+		if operator != ":" {
+			return ErrUnsupportedSearchSyntax
+		}
+		filters.OracleTexts = append(filters.OracleTexts, value)
+
+	case "r", "rarity":
+		rarity, ok := scryfallRarities[lower]
+		if !ok || (operator != ":" && operator != "=") || len(filters.Rarities) > 0 {
+			return ErrUnsupportedSearchSyntax
+		}
+		filters.Rarities = []string{rarity}
+
+	case "s", "set", "e", "edition":
+		if (operator != ":" && operator != "=") || (filters.SetCode != "" && filters.SetCode != lower) {
+			return ErrUnsupportedSearchSyntax
+		}
+		filters.SetCode = lower
+
+	case "game":
+		if (operator != ":" && operator != "=") || !slices.Contains(scryfallGames, lower) {
+			return ErrUnsupportedSearchSyntax
+		}
+		filters.Games = append(filters.Games, lower)
+
+	case "usd":
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return ErrUnsupportedSearchSyntax
+		}
+		switch operator {
+		case ":", "=":
+			filters.PriceMin, filters.PriceMax = &price, &price
+		case ">=":
+			filters.PriceMin = &price
+		case "<=":
+			filters.PriceMax = &price
+		case ">":
+			filters.PriceAbove = &price
+		case "<":
+			filters.PriceBelow = &price
+		default:
+			return ErrUnsupportedSearchSyntax
+		}
+
+	default:
+		return ErrUnsupportedSearchSyntax
+	}
+	return nil
+}
+
+// splitScryfallQuery splits a query on whitespace outside double quotes
+func splitScryfallQuery(query string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unbalanced quotes", ErrUnsupportedSearchSyntax)
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// unquote strips the double quotes around a quoted value
+func unquote(value string) string {
+	return strings.ReplaceAll(value, `"`, "")
+}
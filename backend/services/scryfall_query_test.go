@@ -0,0 +1,72 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseScryfallQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected CardSearchFilters
+	}{
+		{"lightning bolt", CardSearchFilters{Names: []string{"lightning", "bolt"}}},
+		{`"lightning bolt"`, CardSearchFilters{Names: []string{"lightning bolt"}}},
+		{"c:rw", CardSearchFilters{Colors: []string{"r", "w"}}},
+		{"color>=blue", CardSearchFilters{Colors: []string{"u"}}},
+		{"c:c", CardSearchFilters{Colors: []string{"c"}}},
+		{"t:creature type:goblin", CardSearchFilters{TypeLines: []string{"creature", "goblin"}}},
+		{"r:m", CardSearchFilters{Rarities: []string{"mythic"}}},
+		{"rarity=Uncommon", CardSearchFilters{Rarities: []string{"uncommon"}}},
+		{"set:M10", CardSearchFilters{SetCode: "m10"}},
+		{"e:m10 s:m10", CardSearchFilters{SetCode: "m10"}},
+		{`o:"draw a card"`, CardSearchFilters{OracleTexts: []string{"draw a card"}}},
+		{"game:paper", CardSearchFilters{Games: []string{"paper"}}},
+		{"usd>=1.5 usd<=3", CardSearchFilters{PriceMin: float64Ptr(1.5), PriceMax: float64Ptr(3)}},
+		{"usd>1 usd<3", CardSearchFilters{PriceAbove: float64Ptr(1), PriceBelow: float64Ptr(3)}},
+		{"usd:2", CardSearchFilters{PriceMin: float64Ptr(2), PriceMax: float64Ptr(2)}},
+		{"goblin t:creature c:r game:paper", CardSearchFilters{
+			Names: []string{"goblin"}, TypeLines: []string{"creature"}, Colors: []string{"r"}, Games: []string{"paper"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filters, err := ParseScryfallQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(filters, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, filters)
+			}
+		})
+	}
+}
+
+func TestParseScryfallQuery_Unsupported(t *testing.T) {
+	queries := []string{
+		"",
+		"bolt or helix",
+		"-t:creature",
+		"(t:goblin)",
+		"!\"lightning bolt\"",
+		"cmc=3",
+		"is:commander",
+		"c=rg",
+		"c:purple",
+		"r>=rare",
+		"r:c r:u",
+		"set:m10 set:rav",
+		"usd>cheap",
+		"usd!=1",
+		"game:gameboy",
+		"o=draw",
+		`"unbalanced`,
+		"foo:bar",
+	}
+	for _, query := range queries {
+		if _, err := ParseScryfallQuery(query); !errors.Is(err, ErrUnsupportedSearchSyntax) {
+			t.Errorf("%q: expected ErrUnsupportedSearchSyntax, got %v", query, err)
+		}
+	}
+}
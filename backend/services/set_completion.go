@@ -0,0 +1,297 @@
+package services
+
+import (
+	"backend/models"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoMissingCards is returned when a set list would be empty because every matching printing is owned
+var ErrNoMissingCards = errors.New("no missing cards match")
+
+// rarityOrder lists Scryfall's rarities from most to least common; others sort after them
+var rarityOrder = []string{"common", "uncommon", "rare", "mythic", "special", "bonus"}
+
+// ownedPrintingExpr is 1 for a card row with at least one copy in the inventory
+const ownedPrintingExpr = `CASE WHEN EXISTS (
+	SELECT 1 FROM inventories WHERE inventories.scryfall_id = cards.scryfall_id AND inventories.deleted_at IS NULL
+) THEN 1 ELSE 0 END`
+
+// SetRarityCompletion counts the owned printings of one rarity in a set
+// tygo:export
+type SetRarityCompletion struct {
+	Rarity string `json:"rarity"`
+	Total  int    `json:"total"`
+	Owned  int    `json:"owned"`
+}
+
+// SetCompletion reports how many of a set's printings the inventory holds at least one
+// copy of, in total and by rarity. Totals count the set's printings in the local cards
+// table, so they cover variants that Set.CardCount leaves out.
+// tygo:export
+type SetCompletion struct {
+	SetCode            string                `json:"set_code"`
+	SetName            string                `json:"set_name"`
+	TotalCards         int                   `json:"total_cards"`
+	OwnedCards         int                   `json:"owned_cards"`
+	Percentage         float64               `json:"percentage"` // Rounded to one decimal
+	Rarities           []SetRarityCompletion `json:"rarities"`
+	ExcludesBasicLands bool                  `json:"excludes_basic_lands"`
+}
+
+// setRarityCount is one row of the completion query
+type setRarityCount struct {
+	SetCode string
+	Rarity  string
+	Total   int
+	Owned   int
+}
+
+// Completion returns a set's completion, or gorm.ErrRecordNotFound for an unknown set.
+// Basic lands are left out when set_completion_exclude_basic_lands is on.
+func (s *SetDataService) Completion(ctx context.Context, code string) (*SetCompletion, error) {
+	var set models.Set
+	if err := s.db.WithContext(ctx).Where("code = ?", code).Take(&set).Error; err != nil {
+		return nil, err
+	}
+
+	counts, excludeBasics, err := s.completionCounts(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("set_code = ?", set.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	completion := newSetCompletion(set.Code, set.Name, counts, excludeBasics)
+	return &completion, nil
+}
+
+// CompletionSummary returns the completion of every set the inventory holds a card
+// from, most complete first
+func (s *SetDataService) CompletionSummary(ctx context.Context) ([]SetCompletion, error) {
+	counts, excludeBasics, err := s.completionCounts(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where(`set_code IN (
+			SELECT owned.set_code FROM cards AS owned
+			JOIN inventories ON inventories.scryfall_id = owned.scryfall_id AND inventories.deleted_at IS NULL)`)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bySet := make(map[string][]setRarityCount)
+	for _, count := range counts {
+		bySet[count.SetCode] = append(bySet[count.SetCode], count)
+	}
+
+	var sets []models.Set
+	if err := s.db.WithContext(ctx).Select("code", "name").
+		Where("code IN ?", slices.Collect(maps.Keys(bySet))).
+		Find(&sets).Error; err != nil {
+		return nil, fmt.Errorf("loading set names: %w", err)
+	}
+	names := make(map[string]string, len(sets))
+	for _, set := range sets {
+		names[set.Code] = set.Name
+	}
+
+	summary := make([]SetCompletion, 0, len(bySet))
+	for code, setCounts := range bySet {
+		completion := newSetCompletion(code, names[code], setCounts, excludeBasics)
+		// Basic lands can be the only owned cards of a set
+		if completion.OwnedCards > 0 {
+			summary = append(summary, completion)
+		}
+	}
+	slices.SortFunc(summary, func(a, b SetCompletion) int {
+		if c := cmp.Compare(b.Percentage, a.Percentage); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.SetCode, b.SetCode)
+	})
+	return summary, nil
+}
+
+// completionCounts counts total and owned printings by set and rarity for the cards
+// the filter selects, and reports whether basic lands were left out
+func (s *SetDataService) completionCounts(ctx context.Context, filter func(*gorm.DB) *gorm.DB) ([]setRarityCount, bool, error) {
+	excludeBasics := BasicLandsExcluded(ctx, s.db, "set_completion_exclude_basic_lands")
+
+	query := s.db.WithContext(ctx).Model(&models.Card{}).
+		Select("set_code, COALESCE(rarity, '') AS rarity, COUNT(*) AS total, SUM(" + ownedPrintingExpr + ") AS owned").
+		Scopes(filter).
+		Group("set_code, COALESCE(rarity, '')")
+	if excludeBasics {
+		query = query.Scopes(models.ExcludeBasicLands)
+	}
+
+	var counts []setRarityCount
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, false, fmt.Errorf("counting set completion: %w", err)
+	}
+	return counts, excludeBasics, nil
+}
+
+// newSetCompletion totals a set's per-rarity counts
+func newSetCompletion(code, name string, counts []setRarityCount, excludeBasics bool) SetCompletion {
+	completion := SetCompletion{
+		SetCode:            code,
+		SetName:            name,
+		Rarities:           make([]SetRarityCompletion, 0, len(counts)),
+		ExcludesBasicLands: excludeBasics,
+	}
+	for _, count := range counts {
+		completion.TotalCards += count.Total
+		completion.OwnedCards += count.Owned
+		completion.Rarities = append(completion.Rarities, SetRarityCompletion{
+			Rarity: count.Rarity,
+			Total:  count.Total,
+			Owned:  count.Owned,
+		})
+	}
+	slices.SortFunc(completion.Rarities, func(a, b SetRarityCompletion) int {
+		return compareRarity(a.Rarity, b.Rarity)
+	})
+	if completion.TotalCards > 0 {
+		completion.Percentage = math.Round(float64(completion.OwnedCards)/float64(completion.TotalCards)*1000) / 10
+	}
+	return completion
+}
+
+// compareRarity orders rarities as in rarityOrder, then alphabetically
+func compareRarity(a, b string) int {
+	rankA, rankB := slices.Index(rarityOrder, a), slices.Index(rarityOrder, b)
+	if rankA == -1 {
+		rankA = len(rarityOrder)
+	}
+	if rankB == -1 {
+		rankB = len(rarityOrder)
+	}
+	if c := cmp.Compare(rankA, rankB); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
+
+// ValidRarity reports whether r is one of Scryfall's rarities
+func ValidRarity(r string) bool {
+	return slices.Contains(rarityOrder, r)
+}
+
+// SetListOptions controls which missing printings GenerateMissingList puts on the new list
+type SetListOptions struct {
+	Name               string // Defaults to "<set name> (missing)"
+	Description        string
+	Rarities           []string // Only these rarities; all when empty
+	Treatment          string   // Only printings available in this finish and not owned in it; any finish when empty
+	AutoTrackInventory bool
+}
+
+// setListCard is a printing considered for a generated set list
+type setListCard struct {
+	ScryfallID string
+	OracleID   string
+	Finishes   string // JSON array from the card data
+}
+
+// GenerateMissingList creates a list of every printing in a set that the inventory
+// lacks, one copy each, in collector number order. Without a treatment a printing is
+// missing when no copy is owned in any finish, and is listed in its first finish.
+// Basic lands are left out when set_completion_exclude_basic_lands is on. Returns
+// gorm.ErrRecordNotFound for an unknown set and ErrNoMissingCards when nothing is missing.
+func (s *SetDataService) GenerateMissingList(ctx context.Context, code string, opts SetListOptions) (*models.List, error) {
+	var set models.Set
+	if err := s.db.WithContext(ctx).Where("code = ?", code).Take(&set).Error; err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Card{}).
+		Select("scryfall_id, oracle_id, COALESCE(json_extract(raw_json, '$.finishes'), '[]') AS finishes").
+		Where("set_code = ? AND oracle_id <> ''", set.Code).
+		Order("CAST(collector_number AS INTEGER), collector_number, scryfall_id")
+	if len(opts.Rarities) > 0 {
+		query = query.Where("rarity IN ?", opts.Rarities)
+	}
+	if BasicLandsExcluded(ctx, s.db, "set_completion_exclude_basic_lands") {
+		query = query.Scopes(models.ExcludeBasicLands)
+	}
+	var cards []setListCard
+	if err := query.Scan(&cards).Error; err != nil {
+		return nil, fmt.Errorf("loading set cards: %w", err)
+	}
+
+	var owned []ownedPrinting
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Distinct("scryfall_id", "treatment").
+		Where("scryfall_id IN (SELECT scryfall_id FROM cards WHERE set_code = ?)", set.Code).
+		Scan(&owned).Error; err != nil {
+		return nil, fmt.Errorf("loading owned printings: %w", err)
+	}
+	ownedAny := make(map[string]bool, len(owned))
+	ownedIn := make(map[ownedPrinting]bool, len(owned))
+	for _, printing := range owned {
+		ownedAny[printing.ScryfallID] = true
+		ownedIn[printing] = true
+	}
+
+	var items []models.ListItem
+	for _, card := range cards {
+		var finishes []string
+		if err := json.Unmarshal([]byte(card.Finishes), &finishes); err != nil {
+			return nil, fmt.Errorf("decoding finishes of %s: %w", card.ScryfallID, err)
+		}
+
+		treatment := opts.Treatment
+		if treatment == "" {
+			if ownedAny[card.ScryfallID] {
+				continue
+			}
+			treatment = "nonfoil"
+			if len(finishes) > 0 {
+				treatment = finishes[0]
+			}
+		} else if !slices.Contains(finishes, treatment) || ownedIn[ownedPrinting{ScryfallID: card.ScryfallID, Treatment: treatment}] {
+			continue
+		}
+
+		items = append(items, models.ListItem{
+			ScryfallID:      card.ScryfallID,
+			OracleID:        card.OracleID,
+			Treatment:       treatment,
+			DesiredQuantity: 1,
+		})
+	}
+	if len(items) == 0 {
+		return nil, ErrNoMissingCards
+	}
+
+	list := models.List{
+		Name:               opts.Name,
+		Description:        opts.Description,
+		AutoTrackInventory: opts.AutoTrackInventory,
+	}
+	if list.Name == "" {
+		list.Name = set.Name + " (missing)"
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].ListID = list.ID
+		}
+		return tx.CreateInBatches(&items, 500).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating set list: %w", err)
+	}
+	list.Items = items
+	return &list, nil
+}
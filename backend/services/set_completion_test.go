@@ -0,0 +1,187 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupSetCompletionTest(t *testing.T) (*gorm.DB, *SetDataService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	db.Create(&models.Set{ScryfallID: "set-dom", Code: "dom", Name: "Dominaria"})
+	db.Create(&models.Set{ScryfallID: "set-m10", Code: "m10", Name: "Magic 2010"})
+	return db, &SetDataService{db: db}
+}
+
+func createCompletionTestCard(t *testing.T, db *gorm.DB, scryfallID, set, rarity, typeLine string, owned bool) {
+	t.Helper()
+
+	_, number, _ := strings.Cut(scryfallID, "-")
+	card := models.Card{
+		ScryfallID:      scryfallID,
+		OracleID:        "oracle-" + scryfallID,
+		RawJSON:         fmt.Sprintf(`{"name": %q, "set": %q, "type_line": %q, "finishes": ["nonfoil", "foil"]}`, scryfallID, set, typeLine),
+		Rarity:          rarity,
+		CollectorNumber: number,
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+	if owned {
+		db.Create(&models.Inventory{ScryfallID: scryfallID, OracleID: card.OracleID, Treatment: "nonfoil", Quantity: 1})
+	}
+}
+
+func TestSetDataService_Completion(t *testing.T) {
+	db, service := setupSetCompletionTest(t)
+	ctx := context.Background()
+
+	createCompletionTestCard(t, db, "dom-1", "dom", "common", "Creature — Elf", true)
+	createCompletionTestCard(t, db, "dom-2", "dom", "common", "Instant", false)
+	createCompletionTestCard(t, db, "dom-3", "dom", "mythic", "Legendary Planeswalker — Teferi", true)
+	createCompletionTestCard(t, db, "dom-4", "dom", "uncommon", "Sorcery", false)
+	createCompletionTestCard(t, db, "dom-plains", "dom", "common", "Basic Land — Plains", true)
+	createCompletionTestCard(t, db, "m10-1", "m10", "rare", "Instant", false)
+	// A second copy and a deleted row don't change what is owned
+	db.Create(&models.Inventory{ScryfallID: "dom-1", OracleID: "oracle-dom-1", Treatment: "foil", Quantity: 2})
+	deleted := models.Inventory{ScryfallID: "dom-4", OracleID: "oracle-dom-4", Treatment: "nonfoil", Quantity: 1}
+	db.Create(&deleted)
+	db.Delete(&deleted)
+
+	completion, err := service.Completion(ctx, "dom")
+	if err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+	if completion.SetName != "Dominaria" || completion.TotalCards != 5 || completion.OwnedCards != 3 || completion.Percentage != 60 {
+		t.Errorf("unexpected completion: %+v", completion)
+	}
+	want := []SetRarityCompletion{
+		{Rarity: "common", Total: 3, Owned: 2},
+		{Rarity: "uncommon", Total: 1, Owned: 0},
+		{Rarity: "mythic", Total: 1, Owned: 1},
+	}
+	if len(completion.Rarities) != len(want) {
+		t.Fatalf("expected %d rarities, got %+v", len(want), completion.Rarities)
+	}
+	for i := range want {
+		if completion.Rarities[i] != want[i] {
+			t.Errorf("rarity %d: expected %+v, got %+v", i, want[i], completion.Rarities[i])
+		}
+	}
+
+	// Leaving basics out drops the owned Plains from both sides
+	db.Create(&models.Setting{Key: "set_completion_exclude_basic_lands", Value: "true"})
+	completion, err = service.Completion(ctx, "dom")
+	if err != nil {
+		t.Fatalf("Completion failed: %v", err)
+	}
+	if completion.TotalCards != 4 || completion.OwnedCards != 2 || completion.Percentage != 50 || !completion.ExcludesBasicLands {
+		t.Errorf("unexpected completion without basics: %+v", completion)
+	}
+
+	if _, err := service.Completion(ctx, "zzz"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestSetDataService_CompletionSummary(t *testing.T) {
+	db, service := setupSetCompletionTest(t)
+	ctx := context.Background()
+
+	createCompletionTestCard(t, db, "dom-1", "dom", "common", "Instant", true)
+	createCompletionTestCard(t, db, "dom-2", "dom", "common", "Instant", false)
+	createCompletionTestCard(t, db, "m10-1", "m10", "rare", "Instant", true)
+	createCompletionTestCard(t, db, "m10-forest", "m10", "common", "Basic Land — Forest", false)
+	createCompletionTestCard(t, db, "unl-forest", "unl", "common", "Basic Land — Forest", true)
+	createCompletionTestCard(t, db, "akh-1", "akh", "common", "Instant", false)
+
+	summary, err := service.CompletionSummary(ctx)
+	if err != nil {
+		t.Fatalf("CompletionSummary failed: %v", err)
+	}
+	// unl has no Set row but its card is owned; akh has nothing owned
+	if len(summary) != 3 || summary[0].SetCode != "unl" || summary[1].SetCode != "dom" || summary[2].SetCode != "m10" {
+		t.Fatalf("expected unl, dom and m10 in order of completion, got %+v", summary)
+	}
+	if summary[1].SetName != "Dominaria" || summary[1].Percentage != 50 {
+		t.Errorf("unexpected dom completion: %+v", summary[1])
+	}
+
+	// Without basics, unl has nothing owned left and m10 is complete
+	db.Create(&models.Setting{Key: "set_completion_exclude_basic_lands", Value: "true"})
+	summary, err = service.CompletionSummary(ctx)
+	if err != nil {
+		t.Fatalf("CompletionSummary failed: %v", err)
+	}
+	if len(summary) != 2 || summary[0].SetCode != "m10" || summary[0].Percentage != 100 {
+		t.Errorf("expected m10 complete and unl left out, got %+v", summary)
+	}
+}
+
+func listItemIDs(items []models.ListItem) string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ScryfallID + ":" + item.Treatment
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSetDataService_GenerateMissingList(t *testing.T) {
+	db, service := setupSetCompletionTest(t)
+	ctx := context.Background()
+
+	createCompletionTestCard(t, db, "dom-10", "dom", "rare", "Instant", false)
+	createCompletionTestCard(t, db, "dom-2", "dom", "common", "Instant", false)
+	createCompletionTestCard(t, db, "dom-3", "dom", "common", "Instant", true)
+	createCompletionTestCard(t, db, "dom-250", "dom", "common", "Basic Land — Plains", false)
+	createCompletionTestCard(t, db, "m10-1", "m10", "common", "Instant", false)
+	// Only owned in foil
+	db.Create(&models.Inventory{ScryfallID: "dom-2", OracleID: "oracle-dom-2", Treatment: "foil", Quantity: 1})
+	db.Create(&models.Card{ScryfallID: "dom-11", OracleID: "oracle-dom-11", Rarity: "rare", CollectorNumber: "11",
+		RawJSON: `{"set": "dom", "finishes": ["etched"]}`})
+
+	list, err := service.GenerateMissingList(ctx, "dom", SetListOptions{})
+	if err != nil {
+		t.Fatalf("GenerateMissingList failed: %v", err)
+	}
+	if list.Name != "Dominaria (missing)" || list.ID == 0 {
+		t.Errorf("unexpected list: %+v", list)
+	}
+	// Collector number order, each in its first finish
+	if got := listItemIDs(list.Items); got != "dom-10:nonfoil,dom-11:etched,dom-250:nonfoil" {
+		t.Errorf("unexpected items: %s", got)
+	}
+	var stored int64
+	db.Model(&models.ListItem{}).Where("list_id = ?", list.ID).Count(&stored)
+	if stored != 3 {
+		t.Errorf("expected 3 stored items, got %d", stored)
+	}
+
+	// In nonfoil, the foil-only dom-2 is missing too and the etched-only card can't be
+	list, err = service.GenerateMissingList(ctx, "dom", SetListOptions{Name: "Nonfoil commons", Rarities: []string{"common"}, Treatment: "nonfoil"})
+	if err != nil {
+		t.Fatalf("GenerateMissingList failed: %v", err)
+	}
+	if got := listItemIDs(list.Items); list.Name != "Nonfoil commons" || got != "dom-2:nonfoil,dom-250:nonfoil" {
+		t.Errorf("unexpected list %q with items %s", list.Name, got)
+	}
+
+	db.Create(&models.Setting{Key: "set_completion_exclude_basic_lands", Value: "true"})
+	list, err = service.GenerateMissingList(ctx, "dom", SetListOptions{Rarities: []string{"common"}})
+	if !errors.Is(err, ErrNoMissingCards) {
+		t.Errorf("expected ErrNoMissingCards once basics are left out, got %v (%+v)", err, list)
+	}
+
+	if _, err := service.GenerateMissingList(ctx, "zzz", SetListOptions{}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
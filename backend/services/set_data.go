@@ -6,6 +6,7 @@ import (
 	"backend/version"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -19,26 +20,47 @@ import (
 	"gorm.io/gorm/clause"
 )
 
+// ErrNotPreviewCard is returned when an on-demand card fetch targets a card outside the preview sets
+var ErrNotPreviewCard = errors.New("card is not from a preview set")
+
+// setDataScryfallAPI is the part of the Scryfall client the set data service uses
+type setDataScryfallAPI interface {
+	ListSets(ctx context.Context) ([]scryfall.Set, error)
+	GetByID(ctx context.Context, id string) (scryfall.Card, error)
+	Wait(ctx context.Context) error
+}
+
 // SetDataService handles set data download and import
 type SetDataService struct {
 	db              *gorm.DB
 	jobService      *JobService
 	settingsService *SettingsService
-	scryfallClient  *scryfallclient.Client
+	standardService *StandardLegalityService
+	scryfallClient  setDataScryfallAPI
 	dataDir         string
 	httpClient      *http.Client
+	retryBackoff    RetryBackoff // Wait between automatic retries of a transient failure
 }
 
 // NewSetDataService creates a new set data service
 func NewSetDataService(db *gorm.DB, jobService *JobService, settingsService *SettingsService, scryfallClient *scryfallclient.Client, dataDir string) *SetDataService {
-	return &SetDataService{
+	service := &SetDataService{
 		db:              db,
 		jobService:      jobService,
 		settingsService: settingsService,
+		standardService: NewStandardLegalityService(db),
 		scryfallClient:  scryfallClient,
 		dataDir:         dataDir,
 		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		retryBackoff:    DefaultRetryBackoff,
+	}
+	if jobService != nil {
+		jobService.RegisterRetry(models.JobTypeSetDataImport, func(ctx context.Context, job *models.Job) error {
+			slog.InfoContext(ctx, "retrying set data import", "job_id", job.ID)
+			return service.DownloadAndImport(ctx, job.ID)
+		})
 	}
+	return service
 }
 
 // SetJobMetadata represents the metadata stored in job.Metadata field for set imports
@@ -50,6 +72,9 @@ type SetJobMetadata struct {
 	IconsSkipped    int      `json:"icons_skipped"`
 	FailedSets      int      `json:"failed_sets"`
 	FailureExamples []string `json:"failure_examples"`
+
+	// Automatic retries after transient failures, oldest first
+	Retries []JobRetry `json:"retries,omitempty"`
 }
 
 // CreateImportJob creates a new job for set data import
@@ -74,55 +99,72 @@ func (s *SetDataService) TriggerInitialImport(ctx context.Context) error {
 	}
 
 	if hasData {
-		slog.Info("set data already exists, skipping initial import")
+		slog.InfoContext(ctx, "set data already exists, skipping initial import")
 		return nil
 	}
 
-	slog.Info("no set data found, triggering initial import")
+	slog.InfoContext(ctx, "no set data found, triggering initial import")
 
 	job, err := s.CreateImportJob(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to create initial set import job: %w", err)
 	}
 
-	slog.Info("initial set import job created", "job_id", job.ID)
+	slog.InfoContext(ctx, "initial set import job created", "job_id", job.ID)
 
 	// Record that an import was just triggered so the scheduler's catch-up
 	// doesn't create a duplicate job before this one finishes.
 	if err := s.settingsService.SetTime(ctx, "set_data_last_update", time.Now()); err != nil {
-		slog.Warn("failed to record initial set import time", "error", err)
+		slog.WarnContext(ctx, "failed to record initial set import time", "error", err)
 	}
 
-	go func() {
+	// Run import in background with context
+	return s.jobService.Go(ctx, job.ID, "initial set data import", func(ctx context.Context) error {
 		if err := s.DownloadAndImport(ctx, job.ID); err != nil {
-			slog.Error("initial set data import failed", "error", err)
-		} else {
-			slog.Info("initial set data import completed successfully")
+			return err
 		}
-	}()
-
-	return nil
+		slog.InfoContext(ctx, "initial set data import completed successfully")
+		return nil
+	})
 }
 
 // DownloadAndImport downloads and imports set data from Scryfall
 func (s *SetDataService) DownloadAndImport(ctx context.Context, jobID uint) error {
+	ctx, release := s.jobService.Cancellable(ctx, jobID)
+	defer release()
+
 	if err := s.jobService.Start(ctx, jobID); err != nil {
 		return fmt.Errorf("failed to start job: %w", err)
 	}
 
 	if err := s.settingsService.Set(ctx, "set_data_last_update_status", "in_progress"); err != nil {
-		slog.Warn("failed to update status setting", "error", err)
+		slog.WarnContext(ctx, "failed to update status setting", "error", err)
 	}
 
-	if err := s.downloadAndImportInternal(ctx, jobID); err != nil {
-		if failErr := s.jobService.Fail(ctx, jobID, err.Error()); failErr != nil {
-			slog.Error("failed to mark job as failed", "job_id", jobID, "error", failErr)
+	// Retry transient failures, such as Scryfall timeouts or 5xx responses
+	var retries []JobRetry
+	err := retryTransient(ctx, s.settingsService, s.retryBackoff, jobID, func() error {
+		return s.downloadAndImportInternal(ctx, jobID, retries)
+	}, func(retry JobRetry) {
+		retries = append(retries, retry)
+		s.updateJobMetadata(ctx, jobID, SetJobMetadata{Phase: "waiting_to_retry", Retries: retries})
+	})
+	if err != nil {
+		// The job's context may be cancelled, but recording the outcome still has to happen
+		cleanupCtx := context.WithoutCancel(ctx)
+		status := "failed"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+
+		if failErr := s.jobService.Fail(cleanupCtx, jobID, err.Error()); failErr != nil {
+			slog.ErrorContext(ctx, "failed to mark job as failed", "job_id", jobID, "error", failErr)
 		}
-		if setErr := s.settingsService.Set(ctx, "set_data_last_update_status", "failed"); setErr != nil {
-			slog.Warn("failed to update status setting", "key", "set_data_last_update_status", "error", setErr)
+		if setErr := s.settingsService.Set(cleanupCtx, "set_data_last_update_status", status); setErr != nil {
+			slog.WarnContext(ctx, "failed to update status setting", "key", "set_data_last_update_status", "error", setErr)
 		}
-		if setErr := s.settingsService.SetTime(ctx, "set_data_last_update", time.Now()); setErr != nil {
-			slog.Warn("failed to update time setting", "key", "set_data_last_update", "error", setErr)
+		if setErr := s.settingsService.SetTime(cleanupCtx, "set_data_last_update", time.Now()); setErr != nil {
+			slog.WarnContext(ctx, "failed to update time setting", "key", "set_data_last_update", "error", setErr)
 		}
 		return err
 	}
@@ -132,25 +174,35 @@ func (s *SetDataService) DownloadAndImport(ctx context.Context, jobID uint) erro
 	}
 
 	if setErr := s.settingsService.Set(ctx, "set_data_last_update_status", "success"); setErr != nil {
-		slog.Warn("failed to update status setting", "key", "set_data_last_update_status", "error", setErr)
+		slog.WarnContext(ctx, "failed to update status setting", "key", "set_data_last_update_status", "error", setErr)
 	}
 	if setErr := s.settingsService.SetTime(ctx, "set_data_last_update", time.Now()); setErr != nil {
-		slog.Warn("failed to update time setting", "key", "set_data_last_update", "error", setErr)
+		slog.WarnContext(ctx, "failed to update time setting", "key", "set_data_last_update", "error", setErr)
+	}
+
+	// Newly imported sets start unflagged; derive their Standard status from card data
+	if _, err := s.standardService.Recalculate(ctx); err != nil {
+		slog.WarnContext(ctx, "failed to recalculate standard-legal sets", "error", err)
+	}
+
+	// Upcoming sets are imported as previews; keep those already complete in bulk data promoted
+	if _, err := PromotePreviewSets(ctx, s.db); err != nil {
+		slog.WarnContext(ctx, "failed to promote preview sets", "error", err)
 	}
 
 	return nil
 }
 
-func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID uint) error {
+func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID uint, retries []JobRetry) error {
 	// Step 1: Fetch sets from Scryfall
-	s.updateJobMetadata(ctx, jobID, SetJobMetadata{Phase: "fetching"})
+	s.updateJobMetadata(ctx, jobID, SetJobMetadata{Phase: "fetching", Retries: retries})
 
 	sets, err := s.downloadSets(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch sets: %w", err)
 	}
 
-	slog.Info("downloaded sets from scryfall", "count", len(sets))
+	slog.InfoContext(ctx, "downloaded sets from scryfall", "count", len(sets))
 
 	// Step 2: Ensure icon directory exists
 	iconDir := filepath.Join(s.dataDir, "set-icons")
@@ -162,12 +214,14 @@ func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID ui
 	s.updateJobMetadata(ctx, jobID, SetJobMetadata{
 		Phase:     "downloading_icons",
 		TotalSets: len(sets),
+		Retries:   retries,
 	})
 
 	metadata := SetJobMetadata{
 		Phase:           "importing",
 		TotalSets:       len(sets),
 		FailureExamples: make([]string, 0),
+		Retries:         retries,
 	}
 
 	dbSets := make([]*models.Set, 0, len(sets))
@@ -188,7 +242,7 @@ func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID ui
 				}
 				metadata.FailureExamples = append(metadata.FailureExamples, failureMsg)
 			}
-			slog.Warn("failed to download icon for set", "set_code", set.Code, "error", err)
+			slog.WarnContext(ctx, "failed to download icon for set", "set_code", set.Code, "error", err)
 			iconFilename = "" // Continue without icon
 		}
 
@@ -206,7 +260,7 @@ func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID ui
 		// Update progress every 50 sets
 		if (i+1)%50 == 0 {
 			s.updateJobMetadata(ctx, jobID, metadata)
-			slog.Info("set import progress", "processed", i+1, "total", len(sets))
+			slog.InfoContext(ctx, "set import progress", "processed", i+1, "total", len(sets))
 		}
 	}
 
@@ -219,6 +273,7 @@ func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID ui
 		IconsSkipped:    metadata.IconsSkipped,
 		FailedSets:      metadata.FailedSets,
 		FailureExamples: metadata.FailureExamples,
+		Retries:         retries,
 	})
 
 	if err := s.upsertSets(ctx, dbSets); err != nil {
@@ -229,7 +284,7 @@ func (s *SetDataService) downloadAndImportInternal(ctx context.Context, jobID ui
 	metadata.Phase = "completed"
 	s.updateJobMetadata(ctx, jobID, metadata)
 
-	slog.Info("set import completed", "total_sets", len(sets), "icons_downloaded", metadata.IconsDownloaded, "icons_skipped", metadata.IconsSkipped, "failures", metadata.FailedSets)
+	slog.InfoContext(ctx, "set import completed", "total_sets", len(sets), "icons_downloaded", metadata.IconsDownloaded, "icons_skipped", metadata.IconsSkipped, "failures", metadata.FailedSets)
 
 	return nil
 }
@@ -256,7 +311,10 @@ func (s *SetDataService) downloadIconIfNeeded(ctx context.Context, iconURL, setC
 		return filename, false, nil // Already exists
 	}
 
-	// Download the icon
+	// Download the icon, paced with the client's other Scryfall requests
+	if err := s.scryfallClient.Wait(ctx); err != nil {
+		return "", false, fmt.Errorf("failed to download icon: %w", err)
+	}
 	req, err := http.NewRequestWithContext(ctx, "GET", iconURL, nil)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to create request: %w", err)
@@ -289,9 +347,12 @@ func (s *SetDataService) downloadIconIfNeeded(ctx context.Context, iconURL, setC
 
 func (s *SetDataService) scryfallSetToModel(set scryfall.Set, iconFilename string) *models.Set {
 	var releasedAt *string
+	preview := false
 	if set.ReleasedAt != nil {
 		dateStr := set.ReleasedAt.String()
 		releasedAt = &dateStr
+		// Sets released in the future only have spoiled cards so far
+		preview = set.ReleasedAt.After(time.Now())
 	}
 
 	return &models.Set{
@@ -304,6 +365,7 @@ func (s *SetDataService) scryfallSetToModel(set scryfall.Set, iconFilename strin
 		Digital:       set.Digital,
 		IconFilename:  iconFilename,
 		ParentSetCode: set.ParentSetCode,
+		Preview:       preview,
 	}
 }
 
@@ -315,18 +377,108 @@ func (s *SetDataService) upsertSets(ctx context.Context, sets []*models.Set) err
 	// Use UPSERT (ON CONFLICT) to handle updates
 	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "scryfall_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"code", "name", "set_type", "released_at", "card_count", "digital", "icon_filename", "parent_set_code"}),
+		DoUpdates: clause.AssignmentColumns([]string{"code", "name", "set_type", "released_at", "card_count", "digital", "icon_filename", "parent_set_code", "preview"}),
 	}).Create(&sets).Error
 }
 
+// previewSetCardCountsQuery counts the imported cards of each preview set
+const previewSetCardCountsQuery = `
+	SELECT set_code AS code, COUNT(*) AS cards
+	FROM cards
+	WHERE set_code IN ?
+	GROUP BY set_code`
+
+// PromotePreviewSets clears the preview flag on sets whose cards are now all in bulk
+// data and returns the codes of the promoted sets
+func PromotePreviewSets(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var previews []models.Set
+	if err := db.WithContext(ctx).Where("preview = ?", true).Find(&previews).Error; err != nil {
+		return nil, fmt.Errorf("loading preview sets: %w", err)
+	}
+	if len(previews) == 0 {
+		return nil, nil
+	}
+
+	codes := make([]string, len(previews))
+	for i, set := range previews {
+		codes[i] = set.Code
+	}
+
+	var counts []struct {
+		Code  string
+		Cards int
+	}
+	if err := db.WithContext(ctx).Raw(previewSetCardCountsQuery, codes).Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("counting preview set cards: %w", err)
+	}
+	imported := make(map[string]int, len(counts))
+	for _, count := range counts {
+		imported[count.Code] = count.Cards
+	}
+
+	var promoted []string
+	for _, set := range previews {
+		if set.CardCount > 0 && imported[set.Code] >= set.CardCount {
+			promoted = append(promoted, set.Code)
+		}
+	}
+	if len(promoted) == 0 {
+		return nil, nil
+	}
+
+	if err := db.WithContext(ctx).Model(&models.Set{}).Where("code IN ?", promoted).
+		Update("preview", false).Error; err != nil {
+		return nil, fmt.Errorf("promoting preview sets: %w", err)
+	}
+
+	slog.InfoContext(ctx, "preview sets promoted", "component", "set_data", "sets", promoted)
+	return promoted, nil
+}
+
+// FetchPreviewCard fetches a spoiled card from Scryfall and stores it so lists can
+// reference it before bulk data includes its set. Returns ErrNotPreviewCard when
+// the card's set is not a preview set.
+func (s *SetDataService) FetchPreviewCard(ctx context.Context, scryfallID string) (*models.Card, error) {
+	scryfallCard, err := s.scryfallClient.GetByID(ctx, scryfallID)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Set{}).
+		Where("code = ? AND preview = ?", scryfallCard.Set, true).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("checking preview set: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrNotPreviewCard
+	}
+
+	card, err := models.FromScryfallCard(scryfallCard)
+	if err != nil {
+		return nil, fmt.Errorf("converting card %s: %w", scryfallID, err)
+	}
+	card.Name = scryfallCard.Name
+	card.SetCode = scryfallCard.Set
+
+	// Spoiled cards change until release, so a repeat fetch refreshes the stored copy
+	if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "scryfall_id"}},
+		DoUpdates: clause.AssignmentColumns(cardUpsertColumns),
+	}).Create(card).Error; err != nil {
+		return nil, fmt.Errorf("saving preview card %s: %w", scryfallID, err)
+	}
+
+	return card, nil
+}
+
 func (s *SetDataService) updateJobMetadata(ctx context.Context, jobID uint, metadata SetJobMetadata) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		slog.Warn("failed to marshal job metadata", "error", err)
+		slog.WarnContext(ctx, "failed to marshal job metadata", "error", err)
 		return
 	}
 
 	if err := s.jobService.UpdateMetadata(ctx, jobID, string(metadataJSON)); err != nil {
-		slog.Warn("failed to update job metadata", "error", err)
+		slog.WarnContext(ctx, "failed to update job metadata", "error", err)
 	}
 }
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	scryfall "github.com/BlueMonday/go-scryfall"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeSetDataScryfall serves cards from a map instead of the Scryfall API
+type fakeSetDataScryfall struct {
+	cards map[string]scryfall.Card
+	waits int // Requests paced by the rate limiter
+}
+
+func (f *fakeSetDataScryfall) Wait(ctx context.Context) error {
+	f.waits++
+	return ctx.Err()
+}
+
+func (f *fakeSetDataScryfall) ListSets(ctx context.Context) ([]scryfall.Set, error) {
+	return nil, nil
+}
+
+func (f *fakeSetDataScryfall) GetByID(ctx context.Context, id string) (scryfall.Card, error) {
+	card, ok := f.cards[id]
+	if !ok {
+		return scryfall.Card{}, &scryfall.Error{Status: 404, Code: "not_found"}
+	}
+	return card, nil
+}
+
+func setupSetDataTest(t *testing.T, cards ...scryfall.Card) (*SetDataService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	fake := &fakeSetDataScryfall{cards: make(map[string]scryfall.Card)}
+	for _, card := range cards {
+		fake.cards[card.ID] = card
+	}
+
+	return &SetDataService{db: db, scryfallClient: fake, dataDir: t.TempDir()}, db
+}
+
+func createPreviewTestSet(t *testing.T, db *gorm.DB, code string, cardCount int, preview bool) {
+	t.Helper()
+
+	set := models.Set{ScryfallID: "set-" + code, Code: code, Name: code, CardCount: cardCount, Preview: preview}
+	if err := db.Create(&set).Error; err != nil {
+		t.Fatalf("failed to create set: %v", err)
+	}
+}
+
+func createPreviewTestCards(t *testing.T, db *gorm.DB, set string, count int) {
+	t.Helper()
+
+	for i := range count {
+		id := fmt.Sprintf("%s-%d", set, i)
+		rawJSON := fmt.Sprintf(`{"id":%q,"set":%q,"collector_number":"%d"}`, id, set, i)
+		if err := db.Create(&models.Card{ScryfallID: id, RawJSON: rawJSON}).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+}
+
+func TestSetDataService_ScryfallSetToModel_Preview(t *testing.T) {
+	service := &SetDataService{}
+
+	past := scryfall.Date{Time: time.Now().AddDate(0, -1, 0)}
+	future := scryfall.Date{Time: time.Now().AddDate(0, 1, 0)}
+
+	tests := []struct {
+		name       string
+		releasedAt *scryfall.Date
+		expected   bool
+	}{
+		{"Released", &past, false},
+		{"Upcoming", &future, true},
+		{"No release date", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := service.scryfallSetToModel(scryfall.Set{ID: "id", Code: "tst", ReleasedAt: tt.releasedAt}, "")
+			if set.Preview != tt.expected {
+				t.Errorf("expected preview %v, got %v", tt.expected, set.Preview)
+			}
+		})
+	}
+}
+
+func TestPromotePreviewSets(t *testing.T) {
+	_, db := setupSetDataTest(t)
+	ctx := context.Background()
+
+	createPreviewTestSet(t, db, "cmp", 3, true) // Bulk data has every card
+	createPreviewTestSet(t, db, "prt", 3, true) // Only some cards spoiled so far
+	createPreviewTestSet(t, db, "unk", 0, true) // Card count not announced yet
+	createPreviewTestSet(t, db, "old", 2, false)
+	createPreviewTestCards(t, db, "cmp", 3)
+	createPreviewTestCards(t, db, "prt", 1)
+	createPreviewTestCards(t, db, "old", 2)
+
+	promoted, err := PromotePreviewSets(ctx, db)
+	if err != nil {
+		t.Fatalf("PromotePreviewSets failed: %v", err)
+	}
+	if !slices.Equal(promoted, []string{"cmp"}) {
+		t.Errorf("expected [cmp] promoted, got %v", promoted)
+	}
+
+	var previews []string
+	db.Model(&models.Set{}).Where("preview = ?", true).Order("code").Pluck("code", &previews)
+	if !slices.Equal(previews, []string{"prt", "unk"}) {
+		t.Errorf("expected prt and unk to stay previews, got %v", previews)
+	}
+
+	// Nothing left to promote
+	promoted, err = PromotePreviewSets(ctx, db)
+	if err != nil {
+		t.Fatalf("PromotePreviewSets failed: %v", err)
+	}
+	if len(promoted) != 0 {
+		t.Errorf("expected nothing promoted on the second run, got %v", promoted)
+	}
+}
+
+func TestSetDataService_FetchPreviewCard(t *testing.T) {
+	spoiler := scryfall.Card{ID: "spoiled-1", OracleID: "oracle-1", Name: "Spoiled Dragon", Set: "upc", CollectorNumber: "7", Rarity: "mythic"}
+	released := scryfall.Card{ID: "released-1", OracleID: "oracle-2", Name: "Old Card", Set: "old"}
+	service, db := setupSetDataTest(t, spoiler, released)
+	ctx := context.Background()
+
+	createPreviewTestSet(t, db, "upc", 250, true)
+	createPreviewTestSet(t, db, "old", 250, false)
+
+	card, err := service.FetchPreviewCard(ctx, "spoiled-1")
+	if err != nil {
+		t.Fatalf("FetchPreviewCard failed: %v", err)
+	}
+	if card.Name != "Spoiled Dragon" || card.SetCode != "upc" || card.Rarity != "mythic" {
+		t.Errorf("unexpected card: %+v", card)
+	}
+
+	var stored models.Card
+	if err := db.First(&stored, "scryfall_id = ?", "spoiled-1").Error; err != nil {
+		t.Fatalf("expected the preview card to be stored: %v", err)
+	}
+	if stored.CollectorNumber != "7" {
+		t.Errorf("expected collector number 7, got %q", stored.CollectorNumber)
+	}
+
+	// Fetching again refreshes the stored card rather than failing on the primary key
+	service.scryfallClient.(*fakeSetDataScryfall).cards["spoiled-1"] = scryfall.Card{
+		ID: "spoiled-1", OracleID: "oracle-1", Name: "Spoiled Dragon", Set: "upc", CollectorNumber: "7", Rarity: "rare",
+	}
+	if _, err := service.FetchPreviewCard(ctx, "spoiled-1"); err != nil {
+		t.Fatalf("second FetchPreviewCard failed: %v", err)
+	}
+	var refreshed models.Card
+	db.First(&refreshed, "scryfall_id = ?", "spoiled-1")
+	if refreshed.Rarity != "rare" {
+		t.Errorf("expected refreshed rarity rare, got %q", refreshed.Rarity)
+	}
+
+	if _, err := service.FetchPreviewCard(ctx, "released-1"); !errors.Is(err, ErrNotPreviewCard) {
+		t.Errorf("expected ErrNotPreviewCard for a released set, got %v", err)
+	}
+
+	var scryfallErr *scryfall.Error
+	if _, err := service.FetchPreviewCard(ctx, "missing"); !errors.As(err, &scryfallErr) {
+		t.Errorf("expected the Scryfall error for an unknown card, got %v", err)
+	}
+}
+
+// flakySetDataScryfall fails ListSets with a 503 failures times before succeeding
+type flakySetDataScryfall struct {
+	fakeSetDataScryfall
+	failures int
+	calls    int
+}
+
+func (f *flakySetDataScryfall) ListSets(ctx context.Context) ([]scryfall.Set, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, &scryfall.Error{Status: 503, Code: "unavailable"}
+	}
+	return []scryfall.Set{{ID: "set-tst", Code: "tst", Name: "Test Set"}}, nil
+}
+
+func TestSetDataService_DownloadAndImport_RetriesTransientFailure(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Job{}, &models.Setting{}, &models.Card{}, &models.Set{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	ctx := context.Background()
+
+	jobService := NewJobService(db)
+	service := NewSetDataService(db, jobService, NewSettingsService(db), nil, t.TempDir())
+	fake := &flakySetDataScryfall{failures: 1}
+	service.scryfallClient = fake
+	service.retryBackoff = RetryBackoff{Base: time.Millisecond, Max: time.Millisecond}
+
+	job, _ := jobService.Create(ctx, models.JobTypeSetDataImport, "{}")
+	if err := service.DownloadAndImport(ctx, job.ID); err != nil {
+		t.Fatalf("expected the import to succeed on retry, got %v", err)
+	}
+	if fake.calls != 2 {
+		t.Errorf("expected 2 ListSets calls, got %d", fake.calls)
+	}
+
+	var count int64
+	db.Model(&models.Set{}).Count(&count)
+	if count != 1 {
+		t.Errorf("expected 1 set imported, got %d", count)
+	}
+	updated, _ := jobService.Get(ctx, job.ID)
+	var metadata SetJobMetadata
+	json.Unmarshal([]byte(updated.Metadata), &metadata)
+	if updated.Status != models.JobStatusCompleted || len(metadata.Retries) != 1 {
+		t.Errorf("expected a completed job with one recorded retry, got %s with %+v", updated.Status, metadata.Retries)
+	}
+}
+
+func TestSetDataService_DownloadIcon_WaitsForRateLimiter(t *testing.T) {
+	service, _ := setupSetDataTest(t)
+	fake := &fakeSetDataScryfall{}
+	service.scryfallClient = fake
+	service.httpClient = http.DefaultClient
+	if err := os.MkdirAll(filepath.Join(service.dataDir, "set-icons"), 0755); err != nil {
+		t.Fatalf("failed to create icon dir: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<svg></svg>"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if _, downloaded, err := service.downloadIconIfNeeded(ctx, server.URL+"/tst.svg", "tst"); err != nil || !downloaded {
+		t.Fatalf("expected the icon to be downloaded, got %v, %v", downloaded, err)
+	}
+	// An icon already on disk makes no request
+	if _, downloaded, err := service.downloadIconIfNeeded(ctx, server.URL+"/tst.svg", "tst"); err != nil || downloaded {
+		t.Fatalf("expected the icon to be skipped, got %v, %v", downloaded, err)
+	}
+	if fake.waits != 1 {
+		t.Errorf("expected 1 paced request, got %d", fake.waits)
+	}
+}
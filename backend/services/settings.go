@@ -4,8 +4,12 @@ import (
 	"backend/models"
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/mail"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -18,7 +22,7 @@ type SettingsService struct {
 
 // NewSettingsService creates a new settings service
 func NewSettingsService(db *gorm.DB) *SettingsService {
-	service := &SettingsService{db: db}
+	service := settingsStore(db)
 
 	// Initialize default settings on first run
 	service.initializeDefaults(context.Background())
@@ -26,29 +30,86 @@ func NewSettingsService(db *gorm.DB) *SettingsService {
 	return service
 }
 
+// settingsStore reads and writes settings on db without seeding the defaults. Code that
+// touches a setting on every call or scheduled run uses it, since NewSettingsService
+// would re-seed the defaults each time; unset keys fall back to the caller's default.
+func settingsStore(db *gorm.DB) *SettingsService {
+	return &SettingsService{db: db}
+}
+
 // initializeDefaults creates default settings if they don't exist
 func (s *SettingsService) initializeDefaults(ctx context.Context) {
 	defaults := map[string]string{
-		"bulk_data_auto_update":           "true",
-		"bulk_data_update_time":           "03:00",
-		"bulk_data_url":                   "https://api.scryfall.com/bulk-data",
-		"bulk_data_last_update":           "",
-		"bulk_data_last_update_status":    "",
-		"set_data_auto_update":            "true",
-		"set_data_update_time":            "02:30",
-		"set_data_last_update":            "",
-		"set_data_last_update_status":     "",
-		"scryfall_default_search":         "game:paper",
-		"scryfall_unique_mode":            "cards",
-		"job_cleanup_last_run":            "",
-		"scheduler_catchup_enabled":       "true",
-		"scheduler_catchup_delay_seconds": "60",
+		"bulk_data_auto_update":                 "true",
+		"bulk_data_update_time":                 "03:00",
+		"bulk_data_update_cron":                 "",
+		"bulk_data_url":                         "https://api.scryfall.com/bulk-data",
+		"bulk_data_last_update":                 "",
+		"bulk_data_last_update_status":          "",
+		"bulk_data_update_mode":                 "incremental",
+		"bulk_data_source_updated_at":           "",
+		"set_data_auto_update":                  "true",
+		"set_data_update_time":                  "02:30",
+		"set_data_update_cron":                  "",
+		"set_data_last_update":                  "",
+		"set_data_last_update_status":           "",
+		"scryfall_default_search":               "game:paper",
+		"scryfall_unique_mode":                  "cards",
+		"job_cleanup_last_run":                  "",
+		"job_cleanup_retention_days":            strconv.Itoa(DefaultJobCleanupRetentionDays),
+		"job_cleanup_keep_failed":               "false",
+		"job_cleanup_rules":                     "",
+		"job_retry_max_attempts":                strconv.Itoa(DefaultJobRetryMaxAttempts),
+		"scheduler_catchup_enabled":             "true",
+		"scheduler_catchup_delay_seconds":       "60",
+		"scheduler_timezone":                    "",
+		"auto_sort_split_enabled":               "false",
+		"auto_sort_overflow_location_id":        "",
+		"auto_sort_catch_all_location_id":       "",
+		"list_match_policy":                     "exact_printing",
+		"list_match_excluded_treatments":        "",
+		"slow_rule_threshold_micros":            "1000",
+		"inventory_trash_retention_days":        strconv.Itoa(DefaultTrashRetentionDays),
+		"import_digest_price_threshold_percent": strconv.Itoa(DefaultDigestPriceThresholdPercent),
+		"import_digest_notifications":           "false",
+		"preferred_currency":                    "usd",
+		"card_external_links":                   "true",
+		"dashboard_exclude_basic_lands":         "false",
+		"consolidation_exclude_basic_lands":     "false",
+		"duplicates_threshold":                  strconv.Itoa(DefaultDuplicatesThreshold),
+		"duplicates_exclude_basic_lands":        "false",
+		"set_completion_exclude_basic_lands":    "false",
+		"backup_auto_enabled":                   "false",
+		"backup_time":                           "04:00",
+		"backup_cron":                           "",
+		"backup_last_run":                       "",
+		"backup_retention_count":                strconv.Itoa(DefaultBackupRetentionCount),
+		"card_image_cache_max_mb":               strconv.Itoa(DefaultCardImageCacheMB),
+		"card_image_prefetch_enabled":           "false",
+		"card_image_prefetch_last_run":          "",
+		"db_vacuum_auto_enabled":                "false",
+		"db_vacuum_last_run":                    "",
+		"db_vacuum_cron":                        "",
+		"import_batch_size":                     strconv.Itoa(DefaultImportBatchSize),
+		"import_transaction_size":               strconv.Itoa(DefaultImportTransactionSize),
+		"webhook_inventory_change_threshold":    strconv.Itoa(DefaultWebhookInventoryChangeThreshold),
+		"failure_alert_threshold":               strconv.Itoa(DefaultFailureAlertThreshold),
+		"notify_smtp_host":                      "",
+		"notify_smtp_port":                      strconv.Itoa(DefaultSMTPPort),
+		"notify_smtp_username":                  "",
+		"notify_smtp_password":                  "",
+		"notify_email_from":                     "",
+		"notify_email_to":                       "",
+		"notify_ntfy_url":                       "",
+		"notify_ntfy_token":                     "",
+		"notify_discord_webhook_url":            "",
+		"app_base_url":                          "",
 	}
 
 	for key, value := range defaults {
 		var count int64
 		if err := s.db.WithContext(ctx).Model(&models.Setting{}).Where("key = ?", key).Count(&count).Error; err != nil {
-			slog.Warn("failed to check setting existence", "key", key, "error", err)
+			slog.WarnContext(ctx, "failed to check setting existence", "key", key, "error", err)
 			continue
 		}
 
@@ -56,7 +117,7 @@ func (s *SettingsService) initializeDefaults(ctx context.Context) {
 			// Setting doesn't exist, create it
 			setting := models.Setting{Key: key, Value: value}
 			if err := s.db.WithContext(ctx).Create(&setting).Error; err != nil {
-				slog.Warn("failed to create default setting", "key", key, "error", err)
+				slog.WarnContext(ctx, "failed to create default setting", "key", key, "error", err)
 			}
 		}
 	}
@@ -153,6 +214,94 @@ func (s *SettingsService) GetTime(ctx context.Context, key string) (*time.Time,
 	return &parsed, nil
 }
 
+// GetLocation retrieves a setting as an IANA time zone (e.g. "America/New_York").
+// An empty or unknown zone falls back to the server's local time zone.
+func (s *SettingsService) GetLocation(ctx context.Context, key string) *time.Location {
+	value, err := s.Get(ctx, key)
+	if err != nil || value == "" {
+		return time.Local
+	}
+
+	location, err := time.LoadLocation(value)
+	if err != nil {
+		slog.WarnContext(ctx, "invalid time zone setting, using server local time", "key", key, "value", value, "error", err)
+		return time.Local
+	}
+	return location
+}
+
+// GetCurrency retrieves a setting as a price currency, falling back to USD when unset or unknown
+func (s *SettingsService) GetCurrency(ctx context.Context, key string) models.Currency {
+	value, err := s.Get(ctx, key)
+	if err != nil || value == "" {
+		return models.CurrencyUSD
+	}
+
+	currency := models.Currency(value)
+	if !currency.Valid() {
+		slog.WarnContext(ctx, "invalid currency setting, using usd", "key", key, "value", value)
+		return models.CurrencyUSD
+	}
+	return currency
+}
+
+// PreferredCurrency reads the preferred_currency setting that valuations are reported in
+func PreferredCurrency(ctx context.Context, db *gorm.DB) models.Currency {
+	settings := settingsStore(db)
+	return settings.GetCurrency(ctx, "preferred_currency")
+}
+
+// ExternalLinksEnabled reports whether card results and list items include the
+// marketplace and reference links from their Scryfall data (card_external_links)
+func ExternalLinksEnabled(ctx context.Context, db *gorm.DB) bool {
+	settings := settingsStore(db)
+	return settings.GetBool(ctx, "card_external_links", true)
+}
+
+// AppBaseURL reads the app_base_url setting: where the web app is reached, for links
+// printed on storage labels. It is empty when not configured.
+func AppBaseURL(ctx context.Context, db *gorm.DB) string {
+	settings := settingsStore(db)
+	value, err := settings.Get(ctx, "app_base_url")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(strings.TrimSpace(value), "/")
+}
+
+// DefaultDuplicatesThreshold is the default for duplicates_threshold: a playset of four
+const DefaultDuplicatesThreshold = 4
+
+// DuplicatesThreshold reads the duplicates_threshold setting: the number of copies of a
+// card above which the duplicates report lists it
+func DuplicatesThreshold(ctx context.Context, db *gorm.DB) int {
+	settings := settingsStore(db)
+	if threshold := settings.GetInt(ctx, "duplicates_threshold", DefaultDuplicatesThreshold); threshold >= 1 {
+		return threshold
+	}
+	return DefaultDuplicatesThreshold
+}
+
+// DefaultWebhookInventoryChangeThreshold is the default for webhook_inventory_change_threshold
+const DefaultWebhookInventoryChangeThreshold = 50
+
+// WebhookInventoryChangeThreshold reads the webhook_inventory_change_threshold setting:
+// the number of items a batch operation must change to send inventory.bulk_change
+func WebhookInventoryChangeThreshold(ctx context.Context, db *gorm.DB) int {
+	settings := settingsStore(db)
+	if threshold := settings.GetInt(ctx, "webhook_inventory_change_threshold", DefaultWebhookInventoryChangeThreshold); threshold >= 1 {
+		return threshold
+	}
+	return DefaultWebhookInventoryChangeThreshold
+}
+
+// BasicLandsExcluded reports whether a boolean setting such as dashboard_exclude_basic_lands
+// leaves basic lands out of the counts and values it covers
+func BasicLandsExcluded(ctx context.Context, db *gorm.DB, key string) bool {
+	settings := settingsStore(db)
+	return settings.GetBool(ctx, key, false)
+}
+
 // SetTime stores a time.Time as a setting
 func (s *SettingsService) SetTime(ctx context.Context, key string, value time.Time) error {
 	return s.Set(ctx, key, value.Format(time.RFC3339))
@@ -161,21 +310,167 @@ func (s *SettingsService) SetTime(ctx context.Context, key string, value time.Ti
 // ValidSettingKeys returns the set of valid setting keys
 func ValidSettingKeys() map[string]bool {
 	return map[string]bool{
-		"bulk_data_auto_update":           true,
-		"bulk_data_update_time":           true,
-		"bulk_data_url":                   true,
-		"bulk_data_last_update":           true,
-		"bulk_data_last_update_status":    true,
-		"set_data_auto_update":            true,
-		"set_data_update_time":            true,
-		"set_data_last_update":            true,
-		"set_data_last_update_status":     true,
-		"scryfall_default_search":         true,
-		"scryfall_unique_mode":            true,
-		"job_cleanup_last_run":            true,
-		"scheduler_catchup_enabled":       true,
-		"scheduler_catchup_delay_seconds": true,
+		"bulk_data_auto_update":                 true,
+		"bulk_data_update_time":                 true,
+		"bulk_data_update_cron":                 true,
+		"bulk_data_url":                         true,
+		"bulk_data_last_update":                 true,
+		"bulk_data_last_update_status":          true,
+		"bulk_data_update_mode":                 true,
+		"bulk_data_source_updated_at":           true,
+		"set_data_auto_update":                  true,
+		"set_data_update_time":                  true,
+		"set_data_update_cron":                  true,
+		"set_data_last_update":                  true,
+		"set_data_last_update_status":           true,
+		"scryfall_default_search":               true,
+		"scryfall_unique_mode":                  true,
+		"job_cleanup_last_run":                  true,
+		"job_cleanup_retention_days":            true,
+		"job_cleanup_keep_failed":               true,
+		"job_cleanup_rules":                     true,
+		"job_retry_max_attempts":                true,
+		"scheduler_catchup_enabled":             true,
+		"scheduler_catchup_delay_seconds":       true,
+		"scheduler_timezone":                    true,
+		"auto_sort_split_enabled":               true,
+		"auto_sort_overflow_location_id":        true,
+		"auto_sort_catch_all_location_id":       true,
+		"list_match_policy":                     true,
+		"list_match_excluded_treatments":        true,
+		"slow_rule_threshold_micros":            true,
+		"inventory_trash_retention_days":        true,
+		"import_digest_price_threshold_percent": true,
+		"import_digest_notifications":           true,
+		"preferred_currency":                    true,
+		"card_external_links":                   true,
+		"dashboard_exclude_basic_lands":         true,
+		"consolidation_exclude_basic_lands":     true,
+		"duplicates_threshold":                  true,
+		"duplicates_exclude_basic_lands":        true,
+		"set_completion_exclude_basic_lands":    true,
+		"backup_auto_enabled":                   true,
+		"backup_time":                           true,
+		"backup_cron":                           true,
+		"backup_last_run":                       true,
+		"backup_retention_count":                true,
+		"card_image_cache_max_mb":               true,
+		"card_image_prefetch_enabled":           true,
+		"card_image_prefetch_last_run":          true,
+		"db_vacuum_auto_enabled":                true,
+		"db_vacuum_last_run":                    true,
+		"db_vacuum_cron":                        true,
+		"import_batch_size":                     true,
+		"import_transaction_size":               true,
+		"webhook_inventory_change_threshold":    true,
+		"failure_alert_threshold":               true,
+		"notify_smtp_host":                      true,
+		"notify_smtp_port":                      true,
+		"notify_smtp_username":                  true,
+		"notify_smtp_password":                  true,
+		"notify_email_from":                     true,
+		"notify_email_to":                       true,
+		"notify_ntfy_url":                       true,
+		"notify_ntfy_token":                     true,
+		"notify_discord_webhook_url":            true,
+		"app_base_url":                          true,
+	}
+}
+
+// ValidateSettingValue checks a value for settings that only accept specific formats
+func ValidateSettingValue(key, value string) error {
+	switch key {
+	case "bulk_data_update_cron", "set_data_update_cron", "backup_cron", "db_vacuum_cron":
+		if strings.TrimSpace(value) != "" {
+			if _, err := ParseCron(value); err != nil {
+				return err
+			}
+		}
+	case "bulk_data_update_mode":
+		if value != "incremental" && value != "full" {
+			return fmt.Errorf("bulk data update mode must be incremental or full")
+		}
+	case "import_batch_size":
+		return validateImportSizeSetting(value, validateImportBatchSize)
+	case "import_transaction_size":
+		return validateImportSizeSetting(value, validateImportTransactionSize)
+	case "import_digest_price_threshold_percent":
+		if percent, err := strconv.Atoi(value); err != nil || percent < 1 || percent > 1000 {
+			return fmt.Errorf("import digest price threshold must be a whole percentage between 1 and 1000")
+		}
+	case "job_cleanup_retention_days":
+		if days, err := strconv.Atoi(value); err != nil || days < 1 {
+			return fmt.Errorf("job retention must be a whole number of days, at least 1")
+		}
+	case "job_retry_max_attempts":
+		if attempts, err := strconv.Atoi(value); err != nil || attempts < 0 || attempts > MaxJobRetryAttempts {
+			return fmt.Errorf("job retry attempts must be a whole number from 0 to %d", MaxJobRetryAttempts)
+		}
+	case "job_cleanup_rules":
+		_, err := ParseJobRetentionRules(value)
+		return err
+	case "backup_retention_count":
+		if count, err := strconv.Atoi(value); err != nil || count < 1 {
+			return fmt.Errorf("backup retention must be a whole number of backups, at least 1")
+		}
+	case "card_image_cache_max_mb":
+		if mb, err := strconv.Atoi(value); err != nil || mb < 1 {
+			return fmt.Errorf("card image cache size must be a whole number of megabytes, at least 1")
+		}
+	case "duplicates_threshold":
+		if threshold, err := strconv.Atoi(value); err != nil || threshold < 1 {
+			return fmt.Errorf("duplicates threshold must be a whole number of copies, at least 1")
+		}
+	case "webhook_inventory_change_threshold":
+		if threshold, err := strconv.Atoi(value); err != nil || threshold < 1 {
+			return fmt.Errorf("webhook inventory change threshold must be a whole number of items, at least 1")
+		}
+	case "failure_alert_threshold":
+		if threshold, err := strconv.Atoi(value); err != nil || threshold < 1 {
+			return fmt.Errorf("failure alert threshold must be a whole number of failures, at least 1")
+		}
+	case "notify_smtp_port":
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("SMTP port must be a number from 1 to 65535")
+		}
+	case "notify_email_from", "notify_email_to":
+		if strings.TrimSpace(value) == "" {
+			return nil
+		}
+		if _, err := mail.ParseAddressList(value); err != nil {
+			return fmt.Errorf("%s must be a comma-separated list of email addresses", key)
+		}
+	case "notify_ntfy_url", "notify_discord_webhook_url", "app_base_url":
+		if value == "" {
+			return nil
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s must be an http or https URL, or empty to disable it", key)
+		}
+	case "inventory_trash_retention_days":
+		if days, err := strconv.Atoi(value); err != nil || days < 1 {
+			return fmt.Errorf("inventory trash retention must be a whole number of days, at least 1")
+		}
+	case "preferred_currency":
+		if !models.Currency(value).Valid() {
+			return fmt.Errorf("preferred currency must be usd, eur or tix")
+		}
+	case "auto_sort_overflow_location_id", "auto_sort_catch_all_location_id":
+		if value == "" {
+			return nil
+		}
+		if id, err := strconv.Atoi(value); err != nil || id < 0 {
+			return fmt.Errorf("%s must be a storage location ID, or empty for none", key)
+		}
+	case "scheduler_timezone":
+		if value == "" {
+			return nil
+		}
+		if _, err := time.LoadLocation(value); err != nil {
+			return fmt.Errorf("unknown time zone %q", value)
+		}
 	}
+	return nil
 }
 
 // SetBulk updates multiple settings in a single transaction
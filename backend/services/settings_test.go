@@ -34,18 +34,68 @@ func TestSettingsService_InitializeDefaults(t *testing.T) {
 	expectedDefaults := map[string]string{
 		"bulk_data_auto_update":           "true",
 		"bulk_data_update_time":           "03:00",
+		"bulk_data_update_cron":           "",
 		"bulk_data_url":                   "https://api.scryfall.com/bulk-data",
 		"bulk_data_last_update":           "",
 		"bulk_data_last_update_status":    "",
+		"bulk_data_update_mode":           "incremental",
+		"bulk_data_source_updated_at":     "",
 		"set_data_auto_update":            "true",
 		"set_data_update_time":            "02:30",
+		"set_data_update_cron":            "",
 		"set_data_last_update":            "",
 		"set_data_last_update_status":     "",
 		"scryfall_default_search":         "game:paper",
 		"scryfall_unique_mode":            "cards",
 		"job_cleanup_last_run":            "",
+		"job_cleanup_retention_days":      "30",
+		"job_cleanup_keep_failed":         "false",
+		"job_cleanup_rules":               "",
+		"job_retry_max_attempts":          "3",
 		"scheduler_catchup_enabled":       "true",
 		"scheduler_catchup_delay_seconds": "60",
+		"scheduler_timezone":              "",
+		"auto_sort_split_enabled":         "false",
+		"auto_sort_overflow_location_id":  "",
+		"auto_sort_catch_all_location_id": "",
+		"list_match_policy":               "exact_printing",
+		"list_match_excluded_treatments":  "",
+		"slow_rule_threshold_micros":      "1000",
+		"inventory_trash_retention_days":  "30",
+		"import_digest_price_threshold_percent": "20",
+		"import_digest_notifications":           "false",
+		"preferred_currency":              "usd",
+		"card_external_links":             "true",
+		"dashboard_exclude_basic_lands":   "false",
+		"consolidation_exclude_basic_lands": "false",
+		"duplicates_threshold":              "4",
+		"duplicates_exclude_basic_lands":    "false",
+		"set_completion_exclude_basic_lands": "false",
+		"backup_auto_enabled":             "false",
+		"backup_time":                     "04:00",
+		"backup_cron":                     "",
+		"backup_last_run":                 "",
+		"backup_retention_count":          "7",
+		"card_image_cache_max_mb":         "1024",
+		"card_image_prefetch_enabled":     "false",
+		"card_image_prefetch_last_run":    "",
+		"db_vacuum_auto_enabled":          "false",
+		"db_vacuum_last_run":              "",
+		"db_vacuum_cron":                  "",
+		"import_batch_size":               "1000",
+		"import_transaction_size":         "1000",
+		"webhook_inventory_change_threshold": "50",
+		"failure_alert_threshold":            "3",
+		"notify_smtp_host":                   "",
+		"notify_smtp_port":                   "587",
+		"notify_smtp_username":               "",
+		"notify_smtp_password":               "",
+		"notify_email_from":                  "",
+		"notify_email_to":                    "",
+		"notify_ntfy_url":                    "",
+		"notify_ntfy_token":                  "",
+		"notify_discord_webhook_url":         "",
+		"app_base_url":                       "",
 	}
 
 	for key, expectedValue := range expectedDefaults {
@@ -194,7 +244,7 @@ func TestSettingsService_GetAll_EmptyDatabase(t *testing.T) {
 		t.Fatalf("failed to migrate: %v", err)
 	}
 
-	service := &SettingsService{db: db}
+	service := settingsStore(db)
 
 	settings, err := service.GetAll(context.Background())
 	if err != nil {
@@ -404,3 +454,121 @@ func TestSettingsService_SetTime_UpdateExisting(t *testing.T) {
 		t.Errorf("expected %v, got %v", time2, retrieved)
 	}
 }
+
+// GetCurrency tests
+
+func TestSettingsService_GetCurrency(t *testing.T) {
+	service, _ := setupSettingsServiceTest(t)
+	ctx := context.Background()
+
+	if got := service.GetCurrency(ctx, "preferred_currency"); got != models.CurrencyUSD {
+		t.Errorf("expected default usd, got %q", got)
+	}
+
+	service.Set(ctx, "preferred_currency", "eur")
+	if got := service.GetCurrency(ctx, "preferred_currency"); got != models.CurrencyEUR {
+		t.Errorf("expected eur, got %q", got)
+	}
+
+	service.Set(ctx, "preferred_currency", "gbp")
+	if got := service.GetCurrency(ctx, "preferred_currency"); got != models.CurrencyUSD {
+		t.Errorf("expected unknown currency to fall back to usd, got %q", got)
+	}
+}
+
+// GetLocation tests
+
+func TestSettingsService_GetLocation(t *testing.T) {
+	service, _ := setupSettingsServiceTest(t)
+	ctx := context.Background()
+
+	if got := service.GetLocation(ctx, "scheduler_timezone"); got != time.Local {
+		t.Errorf("expected empty setting to use server local time, got %v", got)
+	}
+
+	service.Set(ctx, "scheduler_timezone", "Asia/Tokyo")
+	if got := service.GetLocation(ctx, "scheduler_timezone"); got.String() != "Asia/Tokyo" {
+		t.Errorf("expected Asia/Tokyo, got %v", got)
+	}
+
+	service.Set(ctx, "scheduler_timezone", "Mars/Olympus_Mons")
+	if got := service.GetLocation(ctx, "scheduler_timezone"); got != time.Local {
+		t.Errorf("expected unknown zone to use server local time, got %v", got)
+	}
+}
+
+func TestValidateSettingValue(t *testing.T) {
+	tests := []struct {
+		key, value string
+		valid      bool
+	}{
+		{"scheduler_timezone", "", true},
+		{"scheduler_timezone", "America/New_York", true},
+		{"scheduler_timezone", "UTC", true},
+		{"scheduler_timezone", "Eastern", false},
+		{"bulk_data_update_mode", "incremental", true},
+		{"bulk_data_update_mode", "full", true},
+		{"bulk_data_update_mode", "partial", false},
+		{"preferred_currency", "eur", true},
+		{"preferred_currency", "tix", true},
+		{"preferred_currency", "gbp", false},
+		{"inventory_trash_retention_days", "7", true},
+		{"inventory_trash_retention_days", "0", false},
+		{"inventory_trash_retention_days", "week", false},
+		{"import_digest_price_threshold_percent", "15", true},
+		{"import_digest_price_threshold_percent", "0", false},
+		{"import_digest_price_threshold_percent", "5000", false},
+		{"import_batch_size", "500", true},
+		{"import_batch_size", "5000", false},
+		{"import_batch_size", "lots", false},
+		{"import_transaction_size", "20000", true},
+		{"import_transaction_size", "0", false},
+		{"card_image_cache_max_mb", "256", true},
+		{"card_image_cache_max_mb", "0", false},
+		{"duplicates_threshold", "8", true},
+		{"duplicates_threshold", "0", false},
+		{"duplicates_threshold", "four", false},
+		{"webhook_inventory_change_threshold", "10", true},
+		{"webhook_inventory_change_threshold", "0", false},
+		{"failure_alert_threshold", "1", true},
+		{"failure_alert_threshold", "0", false},
+		{"notify_smtp_port", "465", true},
+		{"notify_smtp_port", "70000", false},
+		{"notify_email_to", "me@example.com, Alerts <alerts@example.com>", true},
+		{"notify_email_to", "not an address", false},
+		{"notify_email_to", "", true},
+		{"notify_ntfy_url", "https://ntfy.sh/showmycards", true},
+		{"notify_ntfy_url", "ntfy.sh/showmycards", false},
+		{"notify_discord_webhook_url", "", true},
+		{"app_base_url", "http://192.168.1.20:5173", true},
+		{"app_base_url", "cards.local", false},
+		{"auto_sort_catch_all_location_id", "", true},
+		{"auto_sort_catch_all_location_id", "12", true},
+		{"auto_sort_catch_all_location_id", "Box Z", false},
+		{"auto_sort_overflow_location_id", "-1", false},
+		{"bulk_data_url", "anything", true},
+		{"job_cleanup_retention_days", "90", true},
+		{"job_retry_max_attempts", "0", true},
+		{"job_retry_max_attempts", "5", true},
+		{"job_retry_max_attempts", "11", false},
+		{"job_retry_max_attempts", "-1", false},
+		{"job_cleanup_retention_days", "0", false},
+		{"job_cleanup_rules", "", true},
+		{"job_cleanup_rules", `{"bulk_data_import": {"retention_days": 7, "keep_last": 3, "keep_failed": true}}`, true},
+		{"job_cleanup_rules", `{"nightly_sync": {"keep_last": 3}}`, false},
+		{"job_cleanup_rules", `{"bulk_data_import": {"keep_last": -1}}`, false},
+		{"job_cleanup_rules", `{"bulk_data_import": {"keep": 3}}`, false},
+		{"job_cleanup_rules", "keep 3", false},
+		{"backup_cron", "", true},
+		{"backup_cron", "30 4 * * SUN", true},
+		{"bulk_data_update_cron", "@daily", true},
+		{"set_data_update_cron", "0 3 31 2 *", false},
+		{"db_vacuum_cron", "every sunday", false},
+	}
+	for _, tt := range tests {
+		err := ValidateSettingValue(tt.key, tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateSettingValue(%q, %q): expected valid=%v, got %v", tt.key, tt.value, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// standardLegalSetsQuery selects the codes of sets whose printings are in Standard.
+// A set counts as Standard-legal when at least one of its cards is legal (or banned)
+// in Standard and none are not_legal; banned cards do not remove their set from rotation.
+const standardLegalSetsQuery = `
+	SELECT set_code AS code
+	FROM cards
+	GROUP BY code
+	HAVING SUM(CASE WHEN json_extract(raw_json, '$.legalities.standard') IN ('legal', 'banned', 'restricted') THEN 1 ELSE 0 END) > 0
+		AND SUM(CASE WHEN json_extract(raw_json, '$.legalities.standard') = 'not_legal' THEN 1 ELSE 0 END) = 0`
+
+// StandardLegalityService derives which sets are currently in Standard from card legalities
+type StandardLegalityService struct {
+	db *gorm.DB
+}
+
+// NewStandardLegalityService creates a new standard legality service
+func NewStandardLegalityService(db *gorm.DB) *StandardLegalityService {
+	return &StandardLegalityService{db: db}
+}
+
+// Recalculate re-derives the Standard-legal flag on every set from the imported
+// card legalities and returns the codes of the sets now in Standard
+func (s *StandardLegalityService) Recalculate(ctx context.Context) ([]string, error) {
+	var codes []string
+	if err := s.db.WithContext(ctx).Raw(standardLegalSetsQuery).Scan(&codes).Error; err != nil {
+		return nil, fmt.Errorf("deriving standard-legal sets: %w", err)
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Set{}).Where("standard_legal = ?", true).
+			Update("standard_legal", false).Error; err != nil {
+			return err
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Set{}).Where("code IN ?", codes).
+			Update("standard_legal", true).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("updating standard-legal sets: %w", err)
+	}
+
+	slog.InfoContext(ctx, "standard-legal sets recalculated", "component", "standard_legality", "count", len(codes), "sets", codes)
+	return codes, nil
+}
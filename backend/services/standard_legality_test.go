@@ -0,0 +1,94 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupStandardLegalityTest(t *testing.T) (*StandardLegalityService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	return NewStandardLegalityService(db), db
+}
+
+func createLegalityTestCard(t *testing.T, db *gorm.DB, scryfallID, set, standard string) {
+	t.Helper()
+
+	rawJSON := fmt.Sprintf(`{"id":%q,"set":%q,"legalities":{"standard":%q}}`, scryfallID, set, standard)
+	if err := db.Create(&models.Card{ScryfallID: scryfallID, RawJSON: rawJSON}).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func TestStandardLegalityService_Recalculate(t *testing.T) {
+	service, db := setupStandardLegalityTest(t)
+	ctx := context.Background()
+
+	for _, code := range []string{"new", "old", "ban"} {
+		if err := db.Create(&models.Set{ScryfallID: "set-" + code, Code: code, Name: code}).Error; err != nil {
+			t.Fatalf("failed to create set: %v", err)
+		}
+	}
+
+	createLegalityTestCard(t, db, "card-1", "new", "legal")
+	createLegalityTestCard(t, db, "card-2", "new", "legal")
+	createLegalityTestCard(t, db, "card-3", "old", "not_legal")
+	// A banned card does not take its set out of Standard
+	createLegalityTestCard(t, db, "card-4", "ban", "legal")
+	createLegalityTestCard(t, db, "card-5", "ban", "banned")
+
+	codes, err := service.Recalculate(ctx)
+	if err != nil {
+		t.Fatalf("Recalculate failed: %v", err)
+	}
+	slices.Sort(codes)
+	if !slices.Equal(codes, []string{"ban", "new"}) {
+		t.Errorf("expected [ban new], got %v", codes)
+	}
+
+	var flagged []string
+	db.Model(&models.Set{}).Where("standard_legal = ?", true).Order("code").Pluck("code", &flagged)
+	if !slices.Equal(flagged, []string{"ban", "new"}) {
+		t.Errorf("expected sets [ban new] flagged, got %v", flagged)
+	}
+}
+
+func TestStandardLegalityService_Recalculate_Rotation(t *testing.T) {
+	service, db := setupStandardLegalityTest(t)
+	ctx := context.Background()
+
+	db.Create(&models.Set{ScryfallID: "set-rot", Code: "rot", Name: "Rotating"})
+	createLegalityTestCard(t, db, "card-1", "rot", "legal")
+
+	if _, err := service.Recalculate(ctx); err != nil {
+		t.Fatalf("Recalculate failed: %v", err)
+	}
+
+	// Simulate a bulk import after rotation
+	rotated := models.Card{ScryfallID: "card-1", RawJSON: `{"id":"card-1","set":"rot","legalities":{"standard":"not_legal"}}`}
+	if err := db.Save(&rotated).Error; err != nil {
+		t.Fatalf("failed to update card: %v", err)
+	}
+
+	codes, err := service.Recalculate(ctx)
+	if err != nil {
+		t.Fatalf("Recalculate failed: %v", err)
+	}
+	if len(codes) != 0 {
+		t.Errorf("expected no standard sets after rotation, got %v", codes)
+	}
+
+	var set models.Set
+	db.Where("code = ?", "rot").First(&set)
+	if set.StandardLegal {
+		t.Error("expected rotated set to no longer be standard legal")
+	}
+}
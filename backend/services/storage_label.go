@@ -0,0 +1,211 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnrecognizedLabelCode is returned when a scanned code does not name a storage location
+var ErrUnrecognizedLabelCode = errors.New("code is not a storage location label")
+
+// Label geometry: a 4x2 inch PDF label, and a PNG at 200 pixels per inch
+const (
+	labelPDFWidth     = 288
+	labelPDFHeight    = 144
+	labelPNGWidth     = 800
+	labelPNGHeight    = 400
+	labelQuietModules = 4 // Light border the QR specification requires around the code
+)
+
+// StorageLabel is a printable label for a box or binder: its name, summary stats,
+// and a QR code linking to its contents
+type StorageLabel struct {
+	StorageLocationID   uint
+	Name                string
+	StorageType         models.StorageType
+	PhysicalDescription string
+	Capacity            int
+	Cards               int
+	Items               int // Inventory rows, one per printing and treatment
+	TotalValue          float64
+	Currency            models.Currency
+	URL                 string // What the QR code encodes
+}
+
+// BuildStorageLabel gathers a location's label contents. The QR code links to the
+// location's contents view under baseURL; values are in the preferred currency.
+func BuildStorageLabel(ctx context.Context, db *gorm.DB, locationID uint, baseURL string) (*StorageLabel, error) {
+	var location models.StorageLocation
+	if err := db.WithContext(ctx).First(&location, locationID).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ScryfallID string
+		Treatment  string
+		Quantity   int
+	}
+	if err := db.WithContext(ctx).Model(&models.Inventory{}).
+		Select("scryfall_id, treatment, quantity").
+		Where("storage_location_id = ?", location.ID).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("loading location contents: %w", err)
+	}
+
+	scryfallIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		scryfallIDs = append(scryfallIDs, row.ScryfallID)
+	}
+	prices, err := models.GetCardPricesByIDs(db.WithContext(ctx), scryfallIDs)
+	if err != nil {
+		return nil, fmt.Errorf("loading location prices: %w", err)
+	}
+
+	label := &StorageLabel{
+		StorageLocationID:   location.ID,
+		Name:                location.Name,
+		StorageType:         location.StorageType,
+		PhysicalDescription: location.PhysicalDescription,
+		Capacity:            location.Capacity,
+		Items:               len(rows),
+		Currency:            PreferredCurrency(ctx, db),
+		URL:                 fmt.Sprintf("%s/inventory/%d", strings.TrimRight(baseURL, "/"), location.ID),
+	}
+	for _, row := range rows {
+		label.Cards += row.Quantity
+		label.TotalValue += prices[row.ScryfallID].InCurrency(row.Treatment, label.Currency) * float64(row.Quantity)
+	}
+	return label, nil
+}
+
+// ResolveStorageLabelCode returns the storage location ID a scanned label names. It
+// accepts the label URL, its path, or a bare location ID.
+func ResolveStorageLabelCode(code string) (uint, error) {
+	code = strings.TrimSpace(code)
+	if id, err := strconv.ParseUint(code, 10, 32); err == nil && id > 0 {
+		return uint(id), nil
+	}
+
+	parsed, err := url.Parse(code)
+	if err != nil {
+		return 0, ErrUnrecognizedLabelCode
+	}
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 2 {
+		return 0, ErrUnrecognizedLabelCode
+	}
+	// Labels link to the /inventory contents view; /storage paths name the same location
+	switch segments[len(segments)-2] {
+	case "inventory", "storage":
+	default:
+		return 0, ErrUnrecognizedLabelCode
+	}
+	id, err := strconv.ParseUint(segments[len(segments)-1], 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrUnrecognizedLabelCode
+	}
+	return uint(id), nil
+}
+
+// details are the summary lines printed under the label name
+func (l *StorageLabel) details() []string {
+	cards := fmt.Sprintf("%s - %d cards", l.StorageType, l.Cards)
+	if l.Capacity > 0 {
+		cards = fmt.Sprintf("%s - %d of %d cards", l.StorageType, l.Cards, l.Capacity)
+	}
+	lines := []string{
+		cards,
+		fmt.Sprintf("%d printings, worth %s", l.Items, formatAlertAmount(l.TotalValue, l.Currency)),
+	}
+	if l.PhysicalDescription != "" {
+		lines = append(lines, l.PhysicalDescription)
+	}
+	return lines
+}
+
+// PDF renders the label as a single 4x2 inch page: the QR code on the left, and the
+// name, summary lines, and link on the right
+func (l *StorageLabel) PDF() ([]byte, error) {
+	code, err := encodeQR(l.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	module := float64(labelPDFHeight) / float64(code.size+2*labelQuietModules)
+	b.WriteString("0 g\n")
+	for y := range code.size {
+		for x := range code.size {
+			if code.dark(x, y) {
+				fmt.Fprintf(&b, "%.2f %.2f %.2f %.2f re f\n",
+					float64(x+labelQuietModules)*module, labelPDFHeight-float64(y+labelQuietModules+1)*module, module, module)
+			}
+		}
+	}
+
+	textX := float64(labelPDFHeight + 6)
+	textWidth := labelPDFWidth - textX - 8
+	// Helvetica averages about half an em per character
+	writePDFText(&b, textX, labelPDFHeight-30, 14, truncatePDFText(l.Name, int(textWidth/7)))
+	for i, line := range l.details() {
+		writePDFText(&b, textX, labelPDFHeight-50-float64(i)*12, 8, truncatePDFText(line, int(textWidth/4)))
+	}
+	writePDFText(&b, textX, 16, 6, truncatePDFText(l.URL, int(textWidth/3)))
+
+	content := b.String()
+	return assemblePDF([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents 5 0 R >>",
+			labelPDFWidth, labelPDFHeight),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}), nil
+}
+
+// PNG renders the label as an 800x400 grayscale image with the same layout as the PDF
+func (l *StorageLabel) PNG() ([]byte, error) {
+	code, err := encodeQR(l.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewGray(image.Rect(0, 0, labelPNGWidth, labelPNGHeight))
+	fillGray(img, 0, 0, labelPNGWidth, labelPNGHeight, color.Gray{Y: 0xFF})
+
+	// Whole pixels per module keep the code sharp; the spare pixels are split around it
+	module := labelPNGHeight / (code.size + 2*labelQuietModules)
+	offset := (labelPNGHeight - module*code.size) / 2
+	for y := range code.size {
+		for x := range code.size {
+			if code.dark(x, y) {
+				fillGray(img, offset+x*module, offset+y*module, module, module, color.Gray{})
+			}
+		}
+	}
+
+	textX := labelPNGHeight + 16
+	textWidth := labelPNGWidth - textX - 16
+	drawLabelText(img, textX, 40, 4, truncatePDFText(l.Name, textWidth/(labelGlyphAdvance*4)))
+	for i, line := range l.details() {
+		drawLabelText(img, textX, 100+i*36, 3, truncatePDFText(line, textWidth/(labelGlyphAdvance*3)))
+	}
+	drawLabelText(img, textX, labelPNGHeight-40, 2, truncatePDFText(l.URL, textWidth/(labelGlyphAdvance*2)))
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding label image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
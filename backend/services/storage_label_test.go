@@ -0,0 +1,114 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestBuildStorageLabel(t *testing.T) {
+	db, binder := setupBinderLayoutTest(t)
+	db.Model(&binder).Updates(map[string]any{"capacity": 360, "physical_description": "shelf by the desk"})
+
+	createBinderTestCard(t, db, binder.ID, "a", "Sol Ring", "c21", "263", `[]`, "1.50", 2)
+	createBinderTestCard(t, db, binder.ID, "b", "Counterspell", "mh2", "267", `["U"]`, "0.25", 4)
+
+	label, err := BuildStorageLabel(context.Background(), db, binder.ID, "http://cards.local/")
+	if err != nil {
+		t.Fatalf("BuildStorageLabel failed: %v", err)
+	}
+	if label.Cards != 6 || label.Items != 2 || label.TotalValue != 4 || label.Currency != models.CurrencyUSD {
+		t.Errorf("unexpected stats %+v", label)
+	}
+	if want := "http://cards.local/inventory/1"; label.URL != want {
+		t.Errorf("expected URL %q, got %q", want, label.URL)
+	}
+	if got := label.details(); got[0] != "Binder - 6 of 360 cards" || got[1] != "2 printings, worth 4.00 USD" || got[2] != "shelf by the desk" {
+		t.Errorf("unexpected details %q", got)
+	}
+
+	if _, err := BuildStorageLabel(context.Background(), db, 99, ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestStorageLabel_PDF(t *testing.T) {
+	label := &StorageLabel{Name: "Bulk (Red)", StorageType: models.Box, Currency: models.CurrencyUSD, URL: "http://cards.local/inventory/3"}
+
+	pdf, err := label.PDF()
+	if err != nil {
+		t.Fatalf("PDF failed: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("expected a complete PDF document")
+	}
+	if !bytes.Contains(pdf, []byte(`(Bulk \(Red\)) Tj`)) || !bytes.Contains(pdf, []byte("(Box - 0 cards) Tj")) {
+		t.Error("expected the name and card count")
+	}
+	if !bytes.Contains(pdf, []byte(" re f\n")) {
+		t.Error("expected the QR code modules")
+	}
+}
+
+func TestStorageLabel_PNG(t *testing.T) {
+	label := &StorageLabel{Name: "Bulk", StorageType: models.Box, Currency: models.CurrencyUSD, URL: "http://cards.local/inventory/3"}
+
+	data, err := label.PNG()
+	if err != nil {
+		t.Fatalf("PNG failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode PNG: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != labelPNGWidth || bounds.Dy() != labelPNGHeight {
+		t.Errorf("unexpected size %v", bounds)
+	}
+
+	// 30 bytes need version 3: 29 modules at 10 pixels each, centred in the left 400 pixels
+	if r, _, _, _ := img.At(55, 55).RGBA(); r != 0 {
+		t.Error("expected the top-left finder pattern to be dark")
+	}
+	if r, _, _, _ := img.At(54, 54).RGBA(); r == 0 {
+		t.Error("expected the quiet zone to be light")
+	}
+}
+
+func TestStorageLabel_TooLong(t *testing.T) {
+	label := &StorageLabel{URL: "http://" + strings.Repeat("a", 250)}
+	if _, err := label.PDF(); !errors.Is(err, ErrQRCodeTooLong) {
+		t.Errorf("expected ErrQRCodeTooLong, got %v", err)
+	}
+}
+
+func TestResolveStorageLabelCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want uint
+		ok   bool
+	}{
+		{"http://cards.local/inventory/12", 12, true},
+		{"https://cards.local/app/inventory/7/?ref=label", 7, true},
+		{"/storage/3", 3, true},
+		{" 42 ", 42, true},
+		{"0", 0, false},
+		{"http://cards.local/cards/12", 0, false},
+		{"http://cards.local/inventory/abc", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := ResolveStorageLabelCode(tt.code)
+		if tt.ok && (err != nil || got != tt.want) {
+			t.Errorf("%q: expected %d, got %d (%v)", tt.code, tt.want, got, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrUnrecognizedLabelCode) {
+			t.Errorf("%q: expected ErrUnrecognizedLabelCode, got %v", tt.code, err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"backend/models"
+	"backend/rules"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// maxSuggestionAlternatives caps the alternative locations offered per item
+const maxSuggestionAlternatives = 3
+
+// SuggestedLocation is a storage location offered for an unassigned item
+// tygo:export
+type SuggestedLocation struct {
+	StorageLocationID uint   `json:"storage_location_id"`
+	Name              string `json:"name"`
+	FreeCapacity      *int   `json:"free_capacity,omitempty"` // Room before this item is added, after earlier suggestions; nil when unlimited
+	Reason            string `json:"reason"`
+}
+
+// StorageSuggestion is where an unassigned inventory item could go. Suggested is
+// where auto-sort would put it right now; Alternatives are other locations with room.
+// tygo:export
+type StorageSuggestion struct {
+	InventoryID     uint                `json:"inventory_id"`
+	ScryfallID      string              `json:"scryfall_id"`
+	Name            string              `json:"name"`
+	Treatment       string              `json:"treatment"`
+	Quantity        int                 `json:"quantity"`
+	MatchedRuleID   *uint               `json:"matched_rule_id,omitempty"`
+	MatchedRuleName string              `json:"matched_rule_name,omitempty"`
+	Suggested       *SuggestedLocation  `json:"suggested,omitempty"`
+	Alternatives    []SuggestedLocation `json:"alternatives"`
+}
+
+// SuggestLocations works out where each item could be stored. Suggestions account for
+// each other, so a box with room for one more row is only suggested for the first.
+// Alternatives are, in order: locations already holding copies of the card, other
+// locations the card's rules match, and locations next to the suggested one.
+func (s *AutoSortService) SuggestLocations(ctx context.Context, items []models.Inventory) ([]StorageSuggestion, error) {
+	suggestions := make([]StorageSuggestion, 0, len(items))
+	if len(items) == 0 {
+		return suggestions, nil
+	}
+
+	var sortingRules []models.SortingRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).
+		Order("priority ASC").
+		Preload("StorageLocation").
+		Find(&sortingRules).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sorting rules: %w", err)
+	}
+
+	var locations []models.StorageLocation
+	if err := s.db.WithContext(ctx).Order("id ASC").Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch storage locations: %w", err)
+	}
+
+	usage, err := s.LocationUsage(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	overflow := s.OverflowLocation(ctx)
+	catchAll := s.CatchAllLocation(ctx)
+
+	scryfallIDs := make([]string, 0, len(items))
+	oracleIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		scryfallIDs = append(scryfallIDs, item.ScryfallID)
+		oracleIDs = append(oracleIDs, item.OracleID)
+	}
+	cards, err := models.GetCardsByIDs(s.db.WithContext(ctx), scryfallIDs)
+	if err != nil {
+		return nil, err
+	}
+	itemIDs := make([]uint, len(items))
+	for i, item := range items {
+		itemIDs[i] = item.ID
+	}
+	tags, err := models.GetInventoryTagNames(s.db.WithContext(ctx), itemIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	var held []struct {
+		OracleID          string
+		StorageLocationID uint
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Distinct("oracle_id", "storage_location_id").
+		Where("oracle_id IN ? AND storage_location_id IS NOT NULL", oracleIDs).
+		Order("storage_location_id ASC").
+		Scan(&held).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch locations holding copies: %w", err)
+	}
+	heldBy := map[string][]uint{}
+	for _, row := range held {
+		heldBy[row.OracleID] = append(heldBy[row.OracleID], row.StorageLocationID)
+	}
+
+	evaluator := rules.NewEvaluator(s.db)
+	for _, item := range items {
+		suggestion := StorageSuggestion{
+			InventoryID:  item.ID,
+			ScryfallID:   item.ScryfallID,
+			Treatment:    item.Treatment,
+			Quantity:     item.Quantity,
+			Alternatives: []SuggestedLocation{},
+		}
+
+		// Matching rules in priority order, keeping the first rule per location
+		var matches []models.StorageLocation
+		keepUnassigned := false
+		ruleFor := map[uint]models.SortingRule{}
+		if card, ok := cards[item.ScryfallID]; ok {
+			cardData, err := rules.RawJSONToRuleData(card.RawJSON, item.Treatment)
+			if err != nil {
+				slog.WarnContext(ctx, "failed to convert card for suggestions", "component", "auto_sort", "scryfall_id", item.ScryfallID, "error", err)
+			} else {
+				cardData["notes"] = item.Notes
+				if itemTags := tags[item.ID]; len(itemTags) > 0 {
+					cardData["tags"] = itemTags
+				}
+				suggestion.Name, _ = cardData["name"].(string)
+				for _, trace := range evaluator.TraceCard(cardData, sortingRules) {
+					if _, seen := ruleFor[trace.Rule.StorageLocationID]; !trace.Assigns() || seen {
+						continue
+					}
+					if trace.Rule.StorageLocationID == models.UnassignedLocationID {
+						// A rule keeps the card unassigned, so lower-priority rules don't apply
+						keepUnassigned = true
+						break
+					}
+					ruleFor[trace.Rule.StorageLocationID] = trace.Rule
+					matches = append(matches, trace.Rule.StorageLocation)
+				}
+			}
+		}
+
+		quantity := max(item.Quantity, 1)
+		candidates := matches
+		if !keepUnassigned {
+			candidates = WithCatchAll(matches, catchAll)
+		}
+		if location := PlaceWhole(quantity, WithOverflow(candidates, overflow), usage); location != nil {
+			// PlaceWhole has already counted this item, so report the room it had before
+			suggestion.Suggested = suggestedLocation(*location, usage[location.ID]-quantity, "")
+			if rule, ok := ruleFor[location.ID]; ok {
+				suggestion.MatchedRuleID = &rule.ID
+				suggestion.MatchedRuleName = rule.Name
+				suggestion.Suggested.Reason = fmt.Sprintf("matches rule %q", rule.Name)
+			} else if len(matches) == 0 {
+				suggestion.Suggested.Reason = "catch-all location; no rule matches"
+			} else {
+				suggestion.Suggested.Reason = "overflow location; every matching location is full"
+			}
+		}
+
+		suggestion.Alternatives = alternativeLocations(quantity, suggestion.Suggested, locations, heldBy[item.OracleID], matches, ruleFor, usage)
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// alternativeLocations picks up to maxSuggestionAlternatives other locations with room for the item
+func alternativeLocations(quantity int, suggested *SuggestedLocation, locations []models.StorageLocation,
+	holding []uint, matches []models.StorageLocation, ruleFor map[uint]models.SortingRule, usage map[uint]int) []SuggestedLocation {
+	byID := make(map[uint]models.StorageLocation, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	alternatives := []SuggestedLocation{}
+	offered := map[uint]bool{}
+	if suggested != nil {
+		offered[suggested.StorageLocationID] = true
+	}
+	offer := func(location models.StorageLocation, reason string) {
+		if len(alternatives) >= maxSuggestionAlternatives || offered[location.ID] {
+			return
+		}
+		if location.Capacity > 0 && location.Capacity-usage[location.ID] < quantity {
+			return
+		}
+		offered[location.ID] = true
+		alternatives = append(alternatives, *suggestedLocation(location, usage[location.ID], reason))
+	}
+
+	for _, id := range holding {
+		if location, ok := byID[id]; ok {
+			offer(location, "already holds copies of this card")
+		}
+	}
+	for _, location := range matches {
+		offer(location, fmt.Sprintf("matches rule %q", ruleFor[location.ID].Name))
+	}
+	if suggested != nil {
+		if parentID := byID[suggested.StorageLocationID].ParentID; parentID != nil {
+			for _, location := range locations {
+				if location.ParentID != nil && *location.ParentID == *parentID {
+					offer(location, fmt.Sprintf("next to %s", suggested.Name))
+				}
+			}
+		}
+	}
+	return alternatives
+}
+
+// suggestedLocation describes a location along with how much room it has left given used cards
+func suggestedLocation(location models.StorageLocation, used int, reason string) *SuggestedLocation {
+	suggested := &SuggestedLocation{StorageLocationID: location.ID, Name: location.Name, Reason: reason}
+	if location.Capacity > 0 {
+		free := max(location.Capacity-used, 0)
+		suggested.FreeCapacity = &free
+	}
+	return suggested
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func createSuggestionItem(t *testing.T, db *gorm.DB, scryfallID, oracleID string, quantity int, locationID *uint) models.Inventory {
+	t.Helper()
+	item := models.Inventory{
+		ScryfallID:        scryfallID,
+		OracleID:          oracleID,
+		Treatment:         "nonfoil",
+		Quantity:          quantity,
+		StorageLocationID: locationID,
+	}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory item: %v", err)
+	}
+	return item
+}
+
+func TestSuggestLocations_MatchedRule(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, rule := setupAutoSortTestData(t, db)
+	item := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 2, nil)
+
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{item})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatalf("expected 1 suggestion, got %d", len(suggestions))
+	}
+
+	got := suggestions[0]
+	if got.Name != "Test Card" {
+		t.Errorf("expected name Test Card, got %q", got.Name)
+	}
+	if got.Suggested == nil || got.Suggested.StorageLocationID != storage.ID {
+		t.Fatalf("expected suggestion of location %d, got %+v", storage.ID, got.Suggested)
+	}
+	if got.MatchedRuleID == nil || *got.MatchedRuleID != rule.ID {
+		t.Errorf("expected matched rule %d, got %v", rule.ID, got.MatchedRuleID)
+	}
+	if got.Suggested.Reason != `matches rule "White Cards Rule"` {
+		t.Errorf("unexpected reason %q", got.Suggested.Reason)
+	}
+	if got.Suggested.FreeCapacity != nil {
+		t.Errorf("expected unlimited location to have no free capacity, got %d", *got.Suggested.FreeCapacity)
+	}
+}
+
+func TestSuggestLocations_CapacityAcrossItems(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Model(storage).Update("capacity", 5)
+
+	fallback := &models.StorageLocation{Name: "Fallback", StorageType: models.Box}
+	db.Create(fallback)
+	db.Create(&models.SortingRule{Name: "Everything", Priority: 2, Expression: "true", StorageLocationID: fallback.ID, Enabled: true})
+
+	first := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 3, nil)
+	second := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 3, nil)
+
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{first, second})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if suggestions[0].Suggested == nil || suggestions[0].Suggested.StorageLocationID != storage.ID {
+		t.Fatalf("expected first item in location %d, got %+v", storage.ID, suggestions[0].Suggested)
+	}
+	if free := suggestions[0].Suggested.FreeCapacity; free == nil || *free != 5 {
+		t.Errorf("expected 5 free before the first item, got %v", free)
+	}
+
+	// The first suggestion leaves room for only 2, so the second item falls through
+	if suggestions[1].Suggested == nil || suggestions[1].Suggested.StorageLocationID != fallback.ID {
+		t.Fatalf("expected second item in fallback %d, got %+v", fallback.ID, suggestions[1].Suggested)
+	}
+	if len(suggestions[1].Alternatives) != 0 {
+		t.Errorf("expected full location not offered as an alternative, got %+v", suggestions[1].Alternatives)
+	}
+}
+
+func TestSuggestLocations_Overflow(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+	db.Model(storage).Update("capacity", 1)
+
+	overflow := &models.StorageLocation{Name: "Overflow", StorageType: models.Box}
+	db.Create(overflow)
+	db.Create(&models.Setting{Key: "auto_sort_overflow_location_id", Value: fmt.Sprint(overflow.ID)})
+
+	item := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 4, nil)
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{item})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := suggestions[0]
+	if got.Suggested == nil || got.Suggested.StorageLocationID != overflow.ID {
+		t.Fatalf("expected overflow location %d, got %+v", overflow.ID, got.Suggested)
+	}
+	if got.MatchedRuleID != nil {
+		t.Errorf("expected no matched rule for overflow, got %d", *got.MatchedRuleID)
+	}
+	if got.Suggested.Reason != "overflow location; every matching location is full" {
+		t.Errorf("unexpected reason %q", got.Suggested.Reason)
+	}
+}
+
+func TestSuggestLocations_Alternatives(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, storage, _ := setupAutoSortTestData(t, db)
+
+	shelf := &models.StorageLocation{Name: "Shelf", StorageType: models.Box}
+	db.Create(shelf)
+	db.Model(storage).UpdateColumn("parent_id", shelf.ID)
+	sibling := &models.StorageLocation{Name: "Sibling Box", StorageType: models.Box, ParentID: &shelf.ID}
+	db.Create(sibling)
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(binder)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &binder.ID)
+
+	second := &models.StorageLocation{Name: "Second Match", StorageType: models.Box}
+	db.Create(second)
+	db.Create(&models.SortingRule{Name: "Flyers", Priority: 2, Expression: `"Flying" in keywords`, StorageLocationID: second.ID, Enabled: true})
+
+	item := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, nil)
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{item})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := suggestions[0]
+	if got.Suggested == nil || got.Suggested.StorageLocationID != storage.ID {
+		t.Fatalf("expected suggestion of location %d, got %+v", storage.ID, got.Suggested)
+	}
+
+	expected := []struct {
+		id     uint
+		reason string
+	}{
+		{binder.ID, "already holds copies of this card"},
+		{second.ID, `matches rule "Flyers"`},
+		{sibling.ID, "next to White Cards Box"},
+	}
+	if len(got.Alternatives) != len(expected) {
+		t.Fatalf("expected %d alternatives, got %+v", len(expected), got.Alternatives)
+	}
+	for i, want := range expected {
+		if got.Alternatives[i].StorageLocationID != want.id || got.Alternatives[i].Reason != want.reason {
+			t.Errorf("alternative %d: expected %d (%s), got %+v", i, want.id, want.reason, got.Alternatives[i])
+		}
+	}
+}
+
+func TestSuggestLocations_NoMatch(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, _, rule := setupAutoSortTestData(t, db)
+	db.Model(rule).UpdateColumn("enabled", false)
+
+	binder := &models.StorageLocation{Name: "Binder", StorageType: models.Binder}
+	db.Create(binder)
+	createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, &binder.ID)
+
+	item := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, nil)
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{item})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := suggestions[0]
+	if got.Suggested != nil {
+		t.Errorf("expected no suggestion without a matching rule, got %+v", got.Suggested)
+	}
+	if len(got.Alternatives) != 1 || got.Alternatives[0].StorageLocationID != binder.ID {
+		t.Errorf("expected the binder holding a copy as the only alternative, got %+v", got.Alternatives)
+	}
+}
+
+func TestSuggestLocations_CatchAll(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+	card, _, rule := setupAutoSortTestData(t, db)
+	db.Model(rule).UpdateColumn("enabled", false)
+
+	catchAll := &models.StorageLocation{Name: "Box Z", StorageType: models.Box}
+	db.Create(catchAll)
+	db.Create(&models.Setting{Key: "auto_sort_catch_all_location_id", Value: fmt.Sprint(catchAll.ID)})
+
+	item := createSuggestionItem(t, db, card.ScryfallID, card.OracleID, 1, nil)
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), []models.Inventory{item})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := suggestions[0]
+	if got.Suggested == nil || got.Suggested.StorageLocationID != catchAll.ID {
+		t.Fatalf("expected catch-all location %d, got %+v", catchAll.ID, got.Suggested)
+	}
+	if got.Suggested.Reason != "catch-all location; no rule matches" {
+		t.Errorf("unexpected reason %q", got.Suggested.Reason)
+	}
+}
+
+func TestSuggestLocations_Empty(t *testing.T) {
+	db := setupAutoSortTestDB(t)
+
+	suggestions, err := NewAutoSortService(db).SuggestLocations(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if suggestions == nil || len(suggestions) != 0 {
+		t.Errorf("expected empty slice, got %v", suggestions)
+	}
+}
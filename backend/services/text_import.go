@@ -0,0 +1,352 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxTextImportLines caps the number of lines accepted in a single pasted list
+const MaxTextImportLines = 1000
+
+// ErrTooManyLines is returned when a pasted list exceeds MaxTextImportLines
+var ErrTooManyLines = fmt.Errorf("pasted list exceeds %d lines", MaxTextImportLines)
+
+// TextImportStatus is the outcome of importing a single pasted line
+type TextImportStatus string
+
+const (
+	TextImportStatusImported TextImportStatus = "imported"
+	TextImportStatusNotFound TextImportStatus = "not_found"
+	TextImportStatusInvalid  TextImportStatus = "invalid"
+	TextImportStatusFailed   TextImportStatus = "failed"
+)
+
+// textImportLinePattern matches "[qty[x]] name [(SET) [collector]] [*F*|*E*]"
+// e.g. "4 Lightning Bolt", "2x Counterspell (MH2)", "1 Sol Ring (C21) 263 *F*"
+var textImportLinePattern = regexp.MustCompile(
+	`^(?:(\d+)\s*[xX]?\s+)?(.+?)(?:\s+[(\[]([A-Za-z0-9]{2,6})[)\]](?:\s+([A-Za-z0-9★-]+))?)?(?:\s+\*([FfEe])\*)?$`)
+
+// TextImportLine is a single parsed line of a pasted list
+type TextImportLine struct {
+	LineNumber      int
+	Text            string
+	Quantity        int
+	Name            string
+	SetCode         string
+	CollectorNumber string
+	Treatment       string
+}
+
+// TextImportLineResult reports what happened to a single pasted line
+// tygo:export
+type TextImportLineResult struct {
+	Line        int              `json:"line"`
+	Text        string           `json:"text"`
+	Status      TextImportStatus `json:"status"`
+	Quantity    int              `json:"quantity,omitempty"`
+	Name        string           `json:"name,omitempty"`
+	SetCode     string           `json:"set_code,omitempty"`
+	ScryfallID  string           `json:"scryfall_id,omitempty"`
+	InventoryID uint             `json:"inventory_id,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// ParseTextImportLine parses one line of a pasted list.
+// Returns ok=false for blank lines and comments (starting with # or //).
+func ParseTextImportLine(lineNumber int, text string) (TextImportLine, bool, error) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+		return TextImportLine{}, false, nil
+	}
+
+	line := TextImportLine{LineNumber: lineNumber, Text: trimmed, Quantity: 1}
+
+	groups := textImportLinePattern.FindStringSubmatch(trimmed)
+	if groups == nil {
+		return line, true, errors.New("could not parse line")
+	}
+
+	if groups[1] != "" {
+		quantity, err := strconv.Atoi(groups[1])
+		if err != nil || quantity < 1 {
+			return line, true, errors.New("quantity must be at least 1")
+		}
+		line.Quantity = quantity
+	}
+
+	line.Name = strings.TrimSpace(groups[2])
+	line.SetCode = strings.ToLower(groups[3])
+	line.CollectorNumber = groups[4]
+
+	switch strings.ToUpper(groups[5]) {
+	case "F":
+		line.Treatment = "foil"
+	case "E":
+		line.Treatment = "etched"
+	default:
+		line.Treatment = "nonfoil"
+	}
+
+	return line, true, nil
+}
+
+// invalidTextImportLine is a pasted line that could not be parsed
+type invalidTextImportLine struct {
+	line TextImportLine
+	err  error
+}
+
+// parseTextImportLines splits pasted text into parsed lines and lines that failed to parse,
+// skipping blanks and comments
+func parseTextImportLines(text string) ([]TextImportLine, []invalidTextImportLine, error) {
+	rawLines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if len(rawLines) > MaxTextImportLines {
+		return nil, nil, ErrTooManyLines
+	}
+
+	var lines []TextImportLine
+	var invalid []invalidTextImportLine
+	for i, raw := range rawLines {
+		line, ok, err := ParseTextImportLine(i+1, raw)
+		if !ok {
+			continue
+		}
+		if err != nil {
+			invalid = append(invalid, invalidTextImportLine{line: line, err: err})
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, invalid, nil
+}
+
+// TextImportService creates inventory from pasted plain-text card lists
+type TextImportService struct {
+	db          *gorm.DB
+	autoSortSvc *AutoSortService
+}
+
+// NewTextImportService creates a new text import service
+func NewTextImportService(db *gorm.DB, autoSortSvc *AutoSortService) *TextImportService {
+	return &TextImportService{db: db, autoSortSvc: autoSortSvc}
+}
+
+// textImportCandidate is a printing that may satisfy a pasted line
+type textImportCandidate struct {
+	ScryfallID      string
+	OracleID        string
+	Name            string
+	FrontFaceName   string
+	SetCode         string
+	CollectorNumber string
+	ReleasedAt      string
+	Digital         bool
+}
+
+// Import parses the pasted text, resolves each line against the local card
+// database, and creates inventory items. Each line is reported independently,
+// so a bad line does not prevent the rest of the list from being imported.
+// When storageLocationID is nil, sorting rules decide where each card goes.
+func (s *TextImportService) Import(ctx context.Context, text string, storageLocationID *uint) ([]TextImportLineResult, error) {
+	lines, invalid, err := parseTextImportLines(text)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []TextImportLineResult{}
+	for _, bad := range invalid {
+		results = append(results, TextImportLineResult{
+			Line: bad.line.LineNumber, Text: bad.line.Text, Status: TextImportStatusInvalid, Error: bad.err.Error(),
+		})
+	}
+
+	candidates, err := loadTextImportCandidates(ctx, s.db, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines {
+		results = append(results, s.importLine(ctx, line, candidates, storageLocationID))
+	}
+
+	// Report in the order the lines were pasted
+	slices.SortStableFunc(results, func(a, b TextImportLineResult) int {
+		return a.Line - b.Line
+	})
+	return results, nil
+}
+
+// importLine resolves and stores a single parsed line
+func (s *TextImportService) importLine(ctx context.Context, line TextImportLine, candidates map[string][]textImportCandidate, storageLocationID *uint) TextImportLineResult {
+	result := TextImportLineResult{
+		Line:     line.LineNumber,
+		Text:     line.Text,
+		Quantity: line.Quantity,
+		Name:     line.Name,
+		SetCode:  line.SetCode,
+	}
+
+	card, ok := pickTextImportPrinting(candidates[strings.ToLower(line.Name)], line)
+	if !ok {
+		result.Status = TextImportStatusNotFound
+		result.Error = "no matching card found"
+		return result
+	}
+	result.Name = card.Name
+	result.SetCode = card.SetCode
+	result.ScryfallID = card.ScryfallID
+
+	locationID := storageLocationID
+	if locationID == nil {
+		assigned, err := s.autoSortSvc.DetermineStorageLocation(ctx, card.ScryfallID, line.Treatment, "", line.Quantity)
+		if err != nil {
+			slog.DebugContext(ctx, "auto-sort did not assign location", "component", "text_import", "scryfall_id", card.ScryfallID, "error", err)
+		} else {
+			locationID = assigned
+		}
+	}
+
+	item := models.Inventory{
+		ScryfallID:        card.ScryfallID,
+		OracleID:          card.OracleID,
+		Treatment:         line.Treatment,
+		Quantity:          line.Quantity,
+		StorageLocationID: locationID,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return models.RecordInventoryEvents(tx, []models.InventoryEvent{models.NewInventoryCreatedEvent(item)})
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to create inventory from pasted line", "component", "text_import", "line", line.LineNumber, "error", err)
+		result.Status = TextImportStatusFailed
+		result.Error = "failed to create inventory item"
+		return result
+	}
+
+	result.Status = TextImportStatusImported
+	result.InventoryID = item.ID
+	return result
+}
+
+// cardNameMatchSQL matches cards whose name, or front face name for multi-faced cards,
+// is in a list of names, ignoring case. It takes the list twice; both sides are backed
+// by case-insensitive indexes.
+const cardNameMatchSQL = "name COLLATE NOCASE IN ? OR json_extract(raw_json, '$.card_faces[0].name') COLLATE NOCASE IN ?"
+
+// loadTextImportCandidates fetches every printing whose name (or front face name) matches
+// one of the pasted names, keyed by lowercase pasted name. All names are resolved
+// in a single query so large lists do not scan the cards table once per line.
+func loadTextImportCandidates(ctx context.Context, db *gorm.DB, lines []TextImportLine) (map[string][]textImportCandidate, error) {
+	candidates := make(map[string][]textImportCandidate)
+	if len(lines) == 0 {
+		return candidates, nil
+	}
+
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		names = append(names, strings.ToLower(line.Name))
+	}
+
+	var cards []models.Card
+	if err := db.WithContext(ctx).
+		Where(cardNameMatchSQL, names, names).
+		Find(&cards).Error; err != nil {
+		return nil, fmt.Errorf("looking up pasted card names: %w", err)
+	}
+
+	for _, card := range cards {
+		candidate, ok := newTextImportCandidate(card)
+		if !ok {
+			continue
+		}
+		for _, key := range []string{strings.ToLower(candidate.Name), strings.ToLower(candidate.FrontFaceName)} {
+			if key != "" {
+				candidates[key] = append(candidates[key], candidate)
+			}
+		}
+	}
+
+	return candidates, nil
+}
+
+// newTextImportCandidate extracts the fields used to match a printing from a card's raw JSON
+func newTextImportCandidate(card models.Card) (textImportCandidate, bool) {
+	var data struct {
+		Name            string `json:"name"`
+		Set             string `json:"set"`
+		CollectorNumber string `json:"collector_number"`
+		ReleasedAt      string `json:"released_at"`
+		Digital         bool   `json:"digital"`
+		CardFaces       []struct {
+			Name string `json:"name"`
+		} `json:"card_faces"`
+	}
+	if err := json.Unmarshal([]byte(card.RawJSON), &data); err != nil {
+		slog.Warn("skipping card with invalid JSON", "component", "text_import", "scryfall_id", card.ScryfallID, "error", err)
+		return textImportCandidate{}, false
+	}
+
+	candidate := textImportCandidate{
+		ScryfallID:      card.ScryfallID,
+		OracleID:        card.OracleID,
+		Name:            data.Name,
+		SetCode:         data.Set,
+		CollectorNumber: data.CollectorNumber,
+		ReleasedAt:      data.ReleasedAt,
+		Digital:         data.Digital,
+	}
+	if len(data.CardFaces) > 0 {
+		candidate.FrontFaceName = data.CardFaces[0].Name
+	}
+	return candidate, true
+}
+
+// pickTextImportPrinting chooses the printing for a line: the requested set and
+// collector number when given, otherwise the newest paper printing
+func pickTextImportPrinting(candidates []textImportCandidate, line TextImportLine) (textImportCandidate, bool) {
+	var best textImportCandidate
+	found := false
+
+	for _, candidate := range candidates {
+		if candidate.OracleID == "" {
+			continue
+		}
+		if line.SetCode != "" && candidate.SetCode != line.SetCode {
+			continue
+		}
+		if line.CollectorNumber != "" && candidate.CollectorNumber != line.CollectorNumber {
+			continue
+		}
+
+		if !found || preferTextImportPrinting(candidate, best) {
+			best = candidate
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// preferTextImportPrinting reports whether a is a better default printing than b
+func preferTextImportPrinting(a, b textImportCandidate) bool {
+	if a.Digital != b.Digital {
+		return !a.Digital
+	}
+	if a.ReleasedAt != b.ReleasedAt {
+		return a.ReleasedAt > b.ReleasedAt
+	}
+	return a.ScryfallID < b.ScryfallID
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func setupTextImportTest(t *testing.T) (*TextImportService, *gorm.DB) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	cards := []models.Card{
+		{ScryfallID: "bolt-m10", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"m10","collector_number":"146","released_at":"2009-07-17"}`},
+		{ScryfallID: "bolt-2x2", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"2x2","collector_number":"117","released_at":"2022-07-08"}`},
+		{ScryfallID: "bolt-digital", OracleID: "oracle-bolt", RawJSON: `{"name":"Lightning Bolt","set":"prm","collector_number":"1","released_at":"2024-01-01","digital":true}`},
+		{ScryfallID: "delver", OracleID: "oracle-delver", RawJSON: `{"name":"Delver of Secrets // Insectile Aberration","set":"isd","card_faces":[{"name":"Delver of Secrets"},{"name":"Insectile Aberration"}]}`},
+	}
+	for _, card := range cards {
+		if err := db.Create(&card).Error; err != nil {
+			t.Fatalf("failed to create card: %v", err)
+		}
+	}
+
+	return NewTextImportService(db, NewAutoSortService(db)), db
+}
+
+func TestParseTextImportLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected TextImportLine
+		skip     bool
+		hasError bool
+	}{
+		{name: "Name only", text: "Lightning Bolt", expected: TextImportLine{Quantity: 1, Name: "Lightning Bolt", Treatment: "nonfoil"}},
+		{name: "Quantity and name", text: "4 Lightning Bolt", expected: TextImportLine{Quantity: 4, Name: "Lightning Bolt", Treatment: "nonfoil"}},
+		{name: "Quantity with x", text: "2x Counterspell", expected: TextImportLine{Quantity: 2, Name: "Counterspell", Treatment: "nonfoil"}},
+		{name: "With set", text: "3 Lightning Bolt (M10)", expected: TextImportLine{Quantity: 3, Name: "Lightning Bolt", SetCode: "m10", Treatment: "nonfoil"}},
+		{name: "With brackets", text: "1 Sol Ring [C21]", expected: TextImportLine{Quantity: 1, Name: "Sol Ring", SetCode: "c21", Treatment: "nonfoil"}},
+		{name: "Set, collector number and foil", text: "1 Sol Ring (C21) 263 *F*", expected: TextImportLine{Quantity: 1, Name: "Sol Ring", SetCode: "c21", CollectorNumber: "263", Treatment: "foil"}},
+		{name: "Split card name", text: "1 Fire // Ice", expected: TextImportLine{Quantity: 1, Name: "Fire // Ice", Treatment: "nonfoil"}},
+		{name: "Blank line", text: "   ", skip: true},
+		{name: "Comment", text: "# sideboard", skip: true},
+		{name: "Zero quantity", text: "0 Lightning Bolt", hasError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, ok, err := ParseTextImportLine(1, tt.text)
+			if tt.skip {
+				if ok {
+					t.Errorf("expected line to be skipped, got %+v", line)
+				}
+				return
+			}
+			if tt.hasError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			tt.expected.LineNumber = 1
+			tt.expected.Text = strings.TrimSpace(tt.text)
+			if line != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, line)
+			}
+		})
+	}
+}
+
+func TestTextImportService_Import(t *testing.T) {
+	service, db := setupTextImportTest(t)
+
+	text := "4 Lightning Bolt (M10)\n\nlightning bolt\n1 Delver of Secrets\n2 Nonexistent Card\n0 Bad Line"
+	results, err := service.Import(context.Background(), text, nil)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(results))
+	}
+
+	expected := []struct {
+		line       int
+		status     TextImportStatus
+		scryfallID string
+	}{
+		{line: 1, status: TextImportStatusImported, scryfallID: "bolt-m10"},
+		{line: 3, status: TextImportStatusImported, scryfallID: "bolt-2x2"}, // newest paper printing
+		{line: 4, status: TextImportStatusImported, scryfallID: "delver"},
+		{line: 5, status: TextImportStatusNotFound},
+		{line: 6, status: TextImportStatusInvalid},
+	}
+	for i, want := range expected {
+		got := results[i]
+		if got.Line != want.line || got.Status != want.status || got.ScryfallID != want.scryfallID {
+			t.Errorf("result %d: expected line %d %s %q, got line %d %s %q",
+				i, want.line, want.status, want.scryfallID, got.Line, got.Status, got.ScryfallID)
+		}
+	}
+
+	var total int64
+	db.Model(&models.Inventory{}).Select("SUM(quantity)").Scan(&total)
+	if total != 6 {
+		t.Errorf("expected 6 copies imported, got %d", total)
+	}
+}
+
+func TestTextImportService_Import_StorageLocation(t *testing.T) {
+	service, db := setupTextImportTest(t)
+
+	location := models.StorageLocation{Name: "Trade Binder", StorageType: models.Binder}
+	db.Create(&location)
+
+	results, err := service.Import(context.Background(), "Lightning Bolt (2X2) *F*", &location.ID)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Status != TextImportStatusImported {
+		t.Fatalf("expected 1 imported result, got %+v", results)
+	}
+
+	var item models.Inventory
+	db.First(&item, results[0].InventoryID)
+	if item.StorageLocationID == nil || *item.StorageLocationID != location.ID {
+		t.Errorf("expected storage location %d, got %v", location.ID, item.StorageLocationID)
+	}
+	if item.Treatment != "foil" {
+		t.Errorf("expected treatment foil, got %s", item.Treatment)
+	}
+}
+
+func TestTextImportService_Import_TooManyLines(t *testing.T) {
+	service, _ := setupTextImportTest(t)
+
+	text := strings.Repeat("Lightning Bolt\n", MaxTextImportLines+1)
+	if _, err := service.Import(context.Background(), text, nil); !errors.Is(err, ErrTooManyLines) {
+		t.Errorf("expected ErrTooManyLines, got %v", err)
+	}
+}
+
+func TestCardNameMatchSQL_UsesIndexes(t *testing.T) {
+	_, db := setupTextImportTest(t)
+
+	var plan []struct{ Detail string }
+	if err := db.Raw("EXPLAIN QUERY PLAN SELECT scryfall_id FROM cards WHERE "+cardNameMatchSQL,
+		[]string{"lightning bolt"}, []string{"lightning bolt"}).Scan(&plan).Error; err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	for _, step := range plan {
+		if strings.HasPrefix(step.Detail, "SCAN") {
+			t.Errorf("expected name lookups to use an index, got %q", step.Detail)
+		}
+	}
+}
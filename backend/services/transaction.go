@@ -0,0 +1,222 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInsufficientSaleQuantity is returned when selling or trading more copies than are available
+var ErrInsufficientSaleQuantity = errors.New("not enough copies available to sell")
+
+// TransactionFilter narrows transactions by type, counterparty and date range
+type TransactionFilter struct {
+	Type         *models.TransactionType
+	Counterparty string     // Case-insensitive substring
+	From         *time.Time // Inclusive
+	To           *time.Time // Exclusive
+}
+
+// apply adds the filter's conditions on the transactions table to query
+func (f TransactionFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.Type != nil {
+		query = query.Where("transactions.type = ?", *f.Type)
+	}
+	if f.Counterparty != "" {
+		query = query.Where("lower(transactions.counterparty) LIKE ?", "%"+strings.ToLower(f.Counterparty)+"%")
+	}
+	if f.From != nil {
+		query = query.Where("transactions.date >= ?", *f.From)
+	}
+	if f.To != nil {
+		query = query.Where("transactions.date < ?", *f.To)
+	}
+	return query
+}
+
+// TransactionReport totals the sales and trades recorded in one currency. Profit/loss
+// only covers cards whose acquisition price is known; the rest are counted separately.
+// tygo:export
+type TransactionReport struct {
+	Currency         models.Currency `json:"currency"`
+	Transactions     int             `json:"transactions"`
+	Cards            int             `json:"cards"`
+	Proceeds         float64         `json:"proceeds"`           // Unit price times quantity, over every card
+	CostBasis        float64         `json:"cost_basis"`         // Acquisition price times quantity, over cards with one
+	ProfitLoss       float64         `json:"profit_loss"`        // Proceeds minus cost basis, over cards with an acquisition price
+	CardsWithoutCost int             `json:"cards_without_cost"` // Cards with no acquisition price, left out of profit/loss
+}
+
+// SoldInventory lists the inventory rows a transaction changed
+type SoldInventory struct {
+	Updated []uint // Rows with copies left
+	Removed []uint // Rows that left entirely
+}
+
+// TransactionService records sales and trades, taking the copies out of inventory
+type TransactionService struct {
+	db *gorm.DB
+}
+
+// NewTransactionService creates a new transaction service
+func NewTransactionService(db *gorm.DB) *TransactionService {
+	return &TransactionService{db: db}
+}
+
+// Record stores a sale or trade and takes its copies out of inventory, in one
+// transaction. Each item's quantity must not exceed the copies of its inventory row
+// that are not on loan. Items get the row's printing, location and acquisition price
+// copied in; rows left with no copies are removed along with their tags and loan lines.
+// The transaction's currency defaults to the preferred currency and its date to now.
+func (s *TransactionService) Record(ctx context.Context, transaction *models.Transaction) (SoldInventory, error) {
+	var sold SoldInventory
+	if transaction.Currency == "" {
+		transaction.Currency = PreferredCurrency(ctx, s.db)
+	}
+	if transaction.Date.IsZero() {
+		transaction.Date = time.Now()
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		requested := make(map[uint]int)
+		ids := make([]uint, 0, len(transaction.Items))
+		for _, item := range transaction.Items {
+			if _, seen := requested[item.InventoryID]; !seen {
+				ids = append(ids, item.InventoryID)
+			}
+			requested[item.InventoryID] += item.Quantity
+		}
+
+		var inventory []models.Inventory
+		if err := tx.Where("id IN ?", ids).Find(&inventory).Error; err != nil {
+			return fmt.Errorf("loading inventory: %w", err)
+		}
+		if len(inventory) != len(ids) {
+			return fmt.Errorf("loading inventory: %w", gorm.ErrRecordNotFound)
+		}
+		byID := make(map[uint]models.Inventory, len(inventory))
+		for _, inv := range inventory {
+			byID[inv.ID] = inv
+		}
+
+		onLoan, err := models.GetOnLoanQuantities(tx, ids)
+		if err != nil {
+			return fmt.Errorf("loading on-loan quantities: %w", err)
+		}
+		for _, inv := range inventory {
+			available := inv.Quantity - onLoan[inv.ID]
+			if requested[inv.ID] > available {
+				return fmt.Errorf("inventory item %d has %d available: %w", inv.ID, available, ErrInsufficientSaleQuantity)
+			}
+		}
+
+		for i := range transaction.Items {
+			item := &transaction.Items[i]
+			inv := byID[item.InventoryID]
+			item.ScryfallID = inv.ScryfallID
+			item.OracleID = inv.OracleID
+			item.Treatment = inv.Treatment
+			item.StorageLocationID = inv.StorageLocationID
+			item.AcquiredPrice = inv.AcquiredPrice
+		}
+		if err := tx.Create(transaction).Error; err != nil {
+			return fmt.Errorf("creating transaction: %w", err)
+		}
+
+		events := make([]models.InventoryEvent, 0, len(inventory))
+		for _, id := range ids {
+			inv := byID[id]
+			remaining := inv.Quantity - requested[id]
+			events = append(events, models.NewInventorySoldEvent(inv, remaining))
+			if remaining > 0 {
+				if err := tx.Model(&models.Inventory{}).Where("id = ?", id).UpdateColumn("quantity", remaining).Error; err != nil {
+					return fmt.Errorf("updating inventory item %d: %w", id, err)
+				}
+				sold.Updated = append(sold.Updated, id)
+				continue
+			}
+			sold.Removed = append(sold.Removed, id)
+		}
+
+		// Sold rows are removed outright rather than trashed: restoring them would
+		// bring back cards the transaction says are gone
+		if len(sold.Removed) > 0 {
+			if err := tx.Where("inventory_id IN ?", sold.Removed).Delete(&models.LoanItem{}).Error; err != nil {
+				return fmt.Errorf("removing loan items: %w", err)
+			}
+			if err := tx.Where("inventory_id IN ?", sold.Removed).Delete(&models.InventoryTag{}).Error; err != nil {
+				return fmt.Errorf("removing tags: %w", err)
+			}
+			if err := tx.Unscoped().Delete(&models.Inventory{}, sold.Removed).Error; err != nil {
+				return fmt.Errorf("removing inventory: %w", err)
+			}
+		}
+		return models.RecordInventoryEvents(tx, events)
+	})
+	if err != nil {
+		return SoldInventory{}, err
+	}
+
+	slog.InfoContext(ctx, "recorded transaction", "component", "transactions", "transaction_id", transaction.ID,
+		"type", transaction.Type, "items", len(transaction.Items), "removed", len(sold.Removed))
+	return sold, nil
+}
+
+// Get retrieves a transaction by ID with its items
+func (s *TransactionService) Get(ctx context.Context, id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := s.db.WithContext(ctx).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&transaction, id).Error; err != nil {
+		return nil, fmt.Errorf("getting transaction %d: %w", id, err)
+	}
+	return &transaction, nil
+}
+
+// List retrieves transactions, most recent first, with pagination and a filter
+func (s *TransactionService) List(ctx context.Context, page, pageSize int, filter TransactionFilter) ([]models.Transaction, int64, error) {
+	var transactions []models.Transaction
+	var total int64
+
+	query := filter.apply(s.db.WithContext(ctx).Model(&models.Transaction{}))
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting transactions: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Items").Order("date DESC, id DESC").Limit(pageSize).Offset(offset).Find(&transactions).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing transactions: %w", err)
+	}
+
+	return transactions, total, nil
+}
+
+// Report totals proceeds and profit/loss against acquisition price for the
+// transactions matching filter, one report per currency they were recorded in
+func (s *TransactionService) Report(ctx context.Context, filter TransactionFilter) ([]TransactionReport, error) {
+	reports := []TransactionReport{}
+	query := filter.apply(s.db.WithContext(ctx).Table("transaction_items").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id"))
+	if err := query.Select(`transactions.currency AS currency,
+			COUNT(DISTINCT transactions.id) AS transactions,
+			SUM(transaction_items.quantity) AS cards,
+			SUM(transaction_items.unit_price * transaction_items.quantity) AS proceeds,
+			SUM(CASE WHEN transaction_items.acquired_price IS NOT NULL
+				THEN transaction_items.acquired_price * transaction_items.quantity ELSE 0 END) AS cost_basis,
+			SUM(CASE WHEN transaction_items.acquired_price IS NOT NULL
+				THEN (transaction_items.unit_price - transaction_items.acquired_price) * transaction_items.quantity ELSE 0 END) AS profit_loss,
+			SUM(CASE WHEN transaction_items.acquired_price IS NULL
+				THEN transaction_items.quantity ELSE 0 END) AS cards_without_cost`).
+		Group("transactions.currency").
+		Order("transactions.currency").
+		Scan(&reports).Error; err != nil {
+		return nil, fmt.Errorf("reporting transactions: %w", err)
+	}
+	return reports, nil
+}
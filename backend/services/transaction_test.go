@@ -0,0 +1,196 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupTransactionServiceTest(t *testing.T) (*TransactionService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.InventoryEvent{}, &models.Tag{}, &models.InventoryTag{},
+		&models.Loan{}, &models.LoanItem{}, &models.Setting{}, &models.Transaction{}, &models.TransactionItem{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return NewTransactionService(db), db
+}
+
+func createTransactionTestInventory(t *testing.T, db *gorm.DB, scryfallID string, quantity int, acquiredPrice *float64) models.Inventory {
+	t.Helper()
+
+	item := models.Inventory{ScryfallID: scryfallID, OracleID: "oracle-" + scryfallID, Treatment: "nonfoil", Quantity: quantity, AcquiredPrice: acquiredPrice}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+	return item
+}
+
+func TestTransactionService_Record(t *testing.T) {
+	service, db := setupTransactionServiceTest(t)
+	ctx := context.Background()
+	paid := 2.0
+	partial := createTransactionTestInventory(t, db, "card-1", 4, &paid)
+	whole := createTransactionTestInventory(t, db, "card-2", 1, nil)
+
+	tag := models.Tag{Name: "trade-bait"}
+	if err := db.Create(&tag).Error; err != nil {
+		t.Fatalf("failed to create tag: %v", err)
+	}
+	if err := db.Create(&models.InventoryTag{InventoryID: whole.ID, TagID: tag.ID}).Error; err != nil {
+		t.Fatalf("failed to tag inventory: %v", err)
+	}
+
+	transaction := &models.Transaction{Type: models.TransactionSale, Counterparty: "LGS", Items: []models.TransactionItem{
+		{InventoryID: partial.ID, Quantity: 3, UnitPrice: 5},
+		{InventoryID: whole.ID, Quantity: 1, UnitPrice: 10},
+	}}
+	sold, err := service.Record(ctx, transaction)
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	if transaction.Currency != models.CurrencyUSD || transaction.Date.IsZero() {
+		t.Errorf("expected preferred currency and a date, got %q %v", transaction.Currency, transaction.Date)
+	}
+	if len(sold.Updated) != 1 || sold.Updated[0] != partial.ID || len(sold.Removed) != 1 || sold.Removed[0] != whole.ID {
+		t.Errorf("unexpected sold inventory %+v", sold)
+	}
+
+	var remaining models.Inventory
+	if err := db.First(&remaining, partial.ID).Error; err != nil || remaining.Quantity != 1 {
+		t.Errorf("expected 1 copy left, got %d (%v)", remaining.Quantity, err)
+	}
+	var count int64
+	db.Unscoped().Model(&models.Inventory{}).Where("id = ?", whole.ID).Count(&count)
+	if count != 0 {
+		t.Error("expected the sold-out row to be removed, not trashed")
+	}
+	db.Model(&models.InventoryTag{}).Count(&count)
+	if count != 0 {
+		t.Error("expected the sold-out row's tags to be removed")
+	}
+
+	stored, err := service.Get(ctx, transaction.ID)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if len(stored.Items) != 2 || stored.Items[1].ScryfallID != "card-2" || stored.Items[0].AcquiredPrice == nil || *stored.Items[0].AcquiredPrice != 2 {
+		t.Errorf("expected items to keep the printing and acquisition price, got %+v", stored.Items)
+	}
+
+	var events []models.InventoryEvent
+	db.Where("event_type = ?", models.InventoryEventSold).Order("inventory_id").Find(&events)
+	if len(events) != 2 || *events[0].NewQuantity != 1 || *events[1].NewQuantity != 0 {
+		t.Errorf("expected sold events for both rows, got %+v", events)
+	}
+}
+
+func TestTransactionService_Record_OnLoanCopiesUnavailable(t *testing.T) {
+	service, db := setupTransactionServiceTest(t)
+	ctx := context.Background()
+	inv := createTransactionTestInventory(t, db, "card-1", 2, nil)
+
+	if err := NewLoanService(db, nil).Lend(ctx, &models.Loan{Borrower: "Alex", Items: []models.LoanItem{{InventoryID: inv.ID, Quantity: 1}}}); err != nil {
+		t.Fatalf("Lend failed: %v", err)
+	}
+
+	_, err := service.Record(ctx, &models.Transaction{Type: models.TransactionTrade, Items: []models.TransactionItem{{InventoryID: inv.ID, Quantity: 2}}})
+	if !errors.Is(err, ErrInsufficientSaleQuantity) {
+		t.Fatalf("expected ErrInsufficientSaleQuantity, got %v", err)
+	}
+
+	var count int64
+	db.Model(&models.Transaction{}).Count(&count)
+	if count != 0 {
+		t.Error("expected no transaction to be recorded")
+	}
+	var unchanged models.Inventory
+	db.First(&unchanged, inv.ID)
+	if unchanged.Quantity != 2 {
+		t.Errorf("expected inventory to be untouched, got %d", unchanged.Quantity)
+	}
+}
+
+func TestTransactionService_Record_MissingInventory(t *testing.T) {
+	service, _ := setupTransactionServiceTest(t)
+
+	_, err := service.Record(context.Background(), &models.Transaction{Type: models.TransactionSale, Items: []models.TransactionItem{{InventoryID: 99, Quantity: 1}}})
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestTransactionService_ListAndReport(t *testing.T) {
+	service, db := setupTransactionServiceTest(t)
+	ctx := context.Background()
+	paid := 1.5
+	first := createTransactionTestInventory(t, db, "card-1", 4, &paid)
+	second := createTransactionTestInventory(t, db, "card-2", 4, nil)
+
+	earlier := time.Now().Add(-48 * time.Hour)
+	record := func(transaction *models.Transaction) {
+		t.Helper()
+		if _, err := service.Record(ctx, transaction); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	record(&models.Transaction{Type: models.TransactionSale, Counterparty: "Buyer One", Date: earlier, Items: []models.TransactionItem{
+		{InventoryID: first.ID, Quantity: 2, UnitPrice: 4},
+	}})
+	record(&models.Transaction{Type: models.TransactionTrade, Counterparty: "Trader", Items: []models.TransactionItem{
+		{InventoryID: first.ID, Quantity: 1, UnitPrice: 1},
+		{InventoryID: second.ID, Quantity: 3, UnitPrice: 2},
+	}})
+	record(&models.Transaction{Type: models.TransactionSale, Currency: models.CurrencyEUR, Items: []models.TransactionItem{
+		{InventoryID: second.ID, Quantity: 1, UnitPrice: 3},
+	}})
+
+	transactions, total, err := service.List(ctx, 1, 10, TransactionFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 3 || len(transactions) != 3 || transactions[2].Counterparty != "Buyer One" {
+		t.Errorf("expected 3 transactions, oldest last, got %d", total)
+	}
+
+	sale := models.TransactionSale
+	_, total, _ = service.List(ctx, 1, 10, TransactionFilter{Type: &sale, Counterparty: "buyer"})
+	if total != 1 {
+		t.Errorf("expected 1 sale to a buyer, got %d", total)
+	}
+
+	reports, err := service.Report(ctx, TransactionFilter{})
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	if len(reports) != 2 || reports[0].Currency != models.CurrencyEUR || reports[1].Currency != models.CurrencyUSD {
+		t.Fatalf("expected a report per currency, got %+v", reports)
+	}
+	usd := reports[1]
+	if usd.Transactions != 2 || usd.Cards != 6 || usd.CardsWithoutCost != 3 {
+		t.Errorf("unexpected counts %+v", usd)
+	}
+	// Proceeds 2*4 + 1*1 + 3*2; cost 3*1.5 over the cards with a price paid
+	if math.Abs(usd.Proceeds-15) > 1e-9 || math.Abs(usd.CostBasis-4.5) > 1e-9 || math.Abs(usd.ProfitLoss-4.5) > 1e-9 {
+		t.Errorf("unexpected totals %+v", usd)
+	}
+
+	from := time.Now().Add(-24 * time.Hour)
+	reports, _ = service.Report(ctx, TransactionFilter{From: &from, Type: &sale})
+	if len(reports) != 1 || reports[0].Currency != models.CurrencyEUR || reports[0].Cards != 1 {
+		t.Errorf("expected only the recent sale, got %+v", reports)
+	}
+}
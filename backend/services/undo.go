@@ -0,0 +1,251 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/TwiN/gocache/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UndoTokenTTL is how long an undo token can be redeemed after the operation
+const UndoTokenTTL = 10 * time.Minute
+
+// maxUndoTokens bounds how many pending undo snapshots are held in memory
+const maxUndoTokens = 100
+
+// ErrUndoTokenNotFound is returned when a token is unknown, expired, or already redeemed
+var ErrUndoTokenNotFound = errors.New("undo token not found or expired")
+
+// ErrOperationAlreadyUndone is returned when undoing an operation that was already reverted
+var ErrOperationAlreadyUndone = errors.New("operation has already been undone")
+
+// UndoOperation identifies the destructive operation an undo token reverts
+type UndoOperation string
+
+const (
+	UndoOperationBatchDelete UndoOperation = "batch_delete"
+	UndoOperationBatchMove   UndoOperation = "batch_move"
+	UndoOperationResort      UndoOperation = "resort"
+)
+
+// UndoSnapshot is the state recorded before an operation, sufficient to revert it
+type UndoSnapshot struct {
+	Operation UndoOperation `json:"operation"`
+	// Rows are the inventory rows as they were before the operation;
+	// deleted rows are re-created with their original IDs
+	Rows []models.Inventory `json:"rows"`
+	// LoanItems are loan lines removed along with deleted inventory rows
+	LoanItems []models.LoanItem `json:"loan_items,omitempty"`
+	// CreatedIDs are inventory rows the operation added (e.g. resort splits)
+	CreatedIDs []uint `json:"created_ids,omitempty"`
+}
+
+// UndoResult reports what redeeming an undo token reverted
+// tygo:export
+type UndoResult struct {
+	OperationID uint          `json:"operation_id"`
+	Operation   UndoOperation `json:"operation"`
+	Restored    int           `json:"restored"`
+	Removed     int           `json:"removed"`
+}
+
+// UndoReceipt identifies a recorded operation and the short-lived token that reverts it
+type UndoReceipt struct {
+	OperationID uint
+	Token       string
+	ExpiresAt   time.Time
+}
+
+// UndoService records batch inventory operations so they can be reverted.
+// Snapshots are persisted as InventoryOperation rows and can be undone by ID at any
+// time; tokens are a short-lived in-memory handle on an operation and do not survive
+// a restart.
+type UndoService struct {
+	db       *gorm.DB
+	cache    *gocache.Cache
+	ttl      time.Duration
+	webhooks *WebhookService
+}
+
+// NewUndoService creates a new undo service
+func NewUndoService(db *gorm.DB) *UndoService {
+	return &UndoService{
+		db:    db,
+		cache: gocache.NewCache().WithMaxSize(maxUndoTokens),
+		ttl:   UndoTokenTTL,
+	}
+}
+
+// SetWebhooks sends inventory.bulk_change to webhooks for large recorded operations
+func (s *UndoService) SetWebhooks(webhooks *WebhookService) {
+	s.webhooks = webhooks
+}
+
+// Record persists a snapshot as an operation and returns its ID and the token that
+// reverts it until the token expires
+func (s *UndoService) Record(ctx context.Context, snapshot UndoSnapshot) (*UndoReceipt, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("generating undo token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	// Associations are restored by foreign key, not through the preloaded structs
+	for i := range snapshot.Rows {
+		snapshot.Rows[i].StorageLocation = nil
+	}
+	for i := range snapshot.LoanItems {
+		snapshot.LoanItems[i].Inventory = nil
+	}
+
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("encoding undo snapshot: %w", err)
+	}
+	operation := models.InventoryOperation{
+		Operation: string(snapshot.Operation),
+		ItemCount: len(snapshot.Rows) + len(snapshot.CreatedIDs),
+		Snapshot:  string(encoded),
+	}
+	if err := s.db.WithContext(ctx).Create(&operation).Error; err != nil {
+		return nil, fmt.Errorf("saving operation: %w", err)
+	}
+	s.webhooks.InventoryChanged(ctx, &operation)
+
+	s.cache.SetWithTTL(token, operation.ID, s.ttl)
+	return &UndoReceipt{OperationID: operation.ID, Token: token, ExpiresAt: time.Now().Add(s.ttl)}, nil
+}
+
+// Redeem reverts the operation recorded under token. A token can only be redeemed once.
+func (s *UndoService) Redeem(ctx context.Context, token string) (*UndoResult, error) {
+	value, ok := s.cache.Get(token)
+	// Deleting claims the token so concurrent redemptions cannot both apply it
+	if !ok || !s.cache.Delete(token) {
+		return nil, ErrUndoTokenNotFound
+	}
+
+	result, err := s.Undo(ctx, value.(uint))
+	// The operation may have been undone by ID, or pruned, since the token was issued
+	if errors.Is(err, ErrOperationAlreadyUndone) || errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUndoTokenNotFound
+	}
+	return result, err
+}
+
+// List returns recorded operations, newest first
+func (s *UndoService) List(ctx context.Context, page, pageSize int) ([]models.InventoryOperation, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.InventoryOperation{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var operations []models.InventoryOperation
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&operations).Error
+	return operations, total, err
+}
+
+// Undo reverts the operation with the given ID. Rows are restored to their state
+// before the operation, overwriting any changes made to them since. Returns
+// gorm.ErrRecordNotFound for unknown operations and ErrOperationAlreadyUndone if the
+// operation was already reverted.
+func (s *UndoService) Undo(ctx context.Context, id uint) (*UndoResult, error) {
+	var operation models.InventoryOperation
+	if err := s.db.WithContext(ctx).First(&operation, id).Error; err != nil {
+		return nil, err
+	}
+	if operation.UndoneAt != nil {
+		return nil, ErrOperationAlreadyUndone
+	}
+
+	var snapshot UndoSnapshot
+	if err := json.Unmarshal([]byte(operation.Snapshot), &snapshot); err != nil {
+		return nil, fmt.Errorf("decoding operation %d: %w", id, err)
+	}
+
+	result := &UndoResult{OperationID: operation.ID, Operation: snapshot.Operation}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claiming the operation first means concurrent undos cannot both apply it
+		claimed := tx.Model(&models.InventoryOperation{}).
+			Where("id = ? AND undone_at IS NULL", operation.ID).
+			UpdateColumn("undone_at", time.Now())
+		if claimed.Error != nil {
+			return fmt.Errorf("marking operation undone: %w", claimed.Error)
+		}
+		if claimed.RowsAffected == 0 {
+			return ErrOperationAlreadyUndone
+		}
+
+		var events []models.InventoryEvent
+		if len(snapshot.CreatedIDs) > 0 {
+			var created []models.Inventory
+			if err := tx.Unscoped().Where("id IN ?", snapshot.CreatedIDs).Find(&created).Error; err != nil {
+				return fmt.Errorf("loading created inventory: %w", err)
+			}
+			for _, item := range created {
+				events = append(events, models.NewInventoryDeletedEvent(item))
+			}
+
+			// Rows the operation added are removed outright rather than sent to the trash
+			if err := tx.Where("inventory_id IN ?", snapshot.CreatedIDs).Delete(&models.InventoryTag{}).Error; err != nil {
+				return fmt.Errorf("removing tags of created inventory: %w", err)
+			}
+			deleted := tx.Unscoped().Delete(&models.Inventory{}, snapshot.CreatedIDs)
+			if deleted.Error != nil {
+				return fmt.Errorf("removing created inventory: %w", deleted.Error)
+			}
+			result.Removed = int(deleted.RowsAffected)
+		}
+
+		for i := range snapshot.Rows {
+			restored := &snapshot.Rows[i]
+			var current models.Inventory
+			err := tx.Unscoped().Where("id = ?", restored.ID).Take(&current).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				events = append(events, models.NewInventoryCreatedEvent(*restored))
+			case err != nil:
+				return fmt.Errorf("loading inventory %d: %w", restored.ID, err)
+			case current.DeletedAt.Valid:
+				events = append(events, models.NewInventoryRestoredEvent(*restored))
+			default:
+				events = append(events, models.InventoryChangeEvents(current, *restored)...)
+			}
+
+			// Saving unscoped brings deleted rows back out of the trash
+			if err := tx.Unscoped().Omit(clause.Associations).Save(restored).Error; err != nil {
+				return fmt.Errorf("restoring inventory %d: %w", restored.ID, err)
+			}
+			result.Restored++
+		}
+		if err := models.RecordInventoryEvents(tx, events); err != nil {
+			return fmt.Errorf("recording inventory history: %w", err)
+		}
+
+		for i := range snapshot.LoanItems {
+			if err := tx.Omit(clause.Associations).Save(&snapshot.LoanItems[i]).Error; err != nil {
+				return fmt.Errorf("restoring loan item %d: %w", snapshot.LoanItems[i].ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "undo applied", "component", "undo", "operation_id", operation.ID, "operation", snapshot.Operation, "restored", result.Restored, "removed", result.Removed)
+	return result, nil
+}
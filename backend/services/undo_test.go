@@ -0,0 +1,171 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupUndoServiceTest(t *testing.T) (*UndoService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.StorageLocation{}, &models.Inventory{}, &models.Tag{}, &models.InventoryTag{}, &models.InventoryEvent{}, &models.Loan{}, &models.LoanItem{}, &models.InventoryOperation{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	return NewUndoService(db), db
+}
+
+func TestUndoService_Redeem_RevertsSplit(t *testing.T) {
+	service, db := setupUndoServiceTest(t)
+	ctx := context.Background()
+
+	original := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 4}
+	if err := db.Create(&original).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	// Simulate a split: the original row shrinks and a new row takes the rest
+	db.Model(&models.Inventory{}).Where("id = ?", original.ID).UpdateColumn("quantity", 1)
+	extra := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Treatment: "nonfoil", Quantity: 3}
+	if err := db.Create(&extra).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	receipt, err := service.Record(ctx, UndoSnapshot{
+		Operation:  UndoOperationResort,
+		Rows:       []models.Inventory{original},
+		CreatedIDs: []uint{extra.ID},
+	})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if !receipt.ExpiresAt.After(time.Now()) {
+		t.Errorf("expected expiry in the future, got %v", receipt.ExpiresAt)
+	}
+
+	result, err := service.Redeem(ctx, receipt.Token)
+	if err != nil {
+		t.Fatalf("Redeem failed: %v", err)
+	}
+	if result.Restored != 1 || result.Removed != 1 {
+		t.Errorf("expected 1 restored and 1 removed, got %+v", result)
+	}
+
+	var items []models.Inventory
+	db.Find(&items)
+	if len(items) != 1 || items[0].Quantity != 4 {
+		t.Errorf("expected only the original row with quantity 4, got %+v", items)
+	}
+
+	// Undoing records the reverted changes in the history
+	var events []models.InventoryEvent
+	db.Order("id").Find(&events)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 history events, got %d", len(events))
+	}
+	if events[0].EventType != models.InventoryEventDeleted || events[0].InventoryID != extra.ID {
+		t.Errorf("expected the split row's deletion first, got %+v", events[0])
+	}
+	if events[1].EventType != models.InventoryEventQuantityChanged || *events[1].OldQuantity != 1 || *events[1].NewQuantity != 4 {
+		t.Errorf("expected quantity 1 -> 4 for the original row, got %+v", events[1])
+	}
+}
+
+func TestUndoService_Redeem_Expired(t *testing.T) {
+	service, _ := setupUndoServiceTest(t)
+	service.ttl = time.Millisecond
+
+	receipt, err := service.Record(context.Background(), UndoSnapshot{Operation: UndoOperationBatchMove})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := service.Redeem(context.Background(), receipt.Token); !errors.Is(err, ErrUndoTokenNotFound) {
+		t.Errorf("expected ErrUndoTokenNotFound, got %v", err)
+	}
+}
+
+func TestUndoService_Undo_ByID(t *testing.T) {
+	service, db := setupUndoServiceTest(t)
+	ctx := context.Background()
+
+	location := models.StorageLocation{Name: "Box", StorageType: models.Box}
+	if err := db.Create(&location).Error; err != nil {
+		t.Fatalf("failed to create location: %v", err)
+	}
+	item := models.Inventory{ScryfallID: "card-1", OracleID: "oracle-1", Quantity: 2, StorageLocationID: &location.ID}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("failed to create inventory: %v", err)
+	}
+
+	// Simulate a batch delete, then let the token expire
+	service.ttl = time.Millisecond
+	receipt, err := service.Record(ctx, UndoSnapshot{Operation: UndoOperationBatchDelete, Rows: []models.Inventory{item}})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	db.Delete(&models.Inventory{}, item.ID)
+	time.Sleep(5 * time.Millisecond)
+
+	// Operations are persisted, so a fresh service (e.g. after a restart) can still undo them
+	restarted := NewUndoService(db)
+	result, err := restarted.Undo(ctx, receipt.OperationID)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if result.OperationID != receipt.OperationID || result.Operation != UndoOperationBatchDelete || result.Restored != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	var restored models.Inventory
+	if err := db.First(&restored, item.ID).Error; err != nil {
+		t.Fatalf("expected the row to be restored: %v", err)
+	}
+	if restored.Quantity != 2 || restored.StorageLocationID == nil || *restored.StorageLocationID != location.ID {
+		t.Errorf("unexpected restored row: %+v", restored)
+	}
+
+	if _, err := restarted.Undo(ctx, receipt.OperationID); !errors.Is(err, ErrOperationAlreadyUndone) {
+		t.Errorf("expected ErrOperationAlreadyUndone, got %v", err)
+	}
+	if _, err := restarted.Undo(ctx, 999); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected gorm.ErrRecordNotFound, got %v", err)
+	}
+
+	operations, total, err := restarted.List(ctx, 1, 10)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if total != 1 || operations[0].UndoneAt == nil || operations[0].ItemCount != 1 {
+		t.Errorf("expected one undone operation, got %d: %+v", total, operations)
+	}
+}
+
+func TestUndoService_Redeem_AfterUndoByID(t *testing.T) {
+	service, _ := setupUndoServiceTest(t)
+	ctx := context.Background()
+
+	receipt, err := service.Record(ctx, UndoSnapshot{Operation: UndoOperationBatchMove})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := service.Undo(ctx, receipt.OperationID); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if _, err := service.Redeem(ctx, receipt.Token); !errors.Is(err, ErrUndoTokenNotFound) {
+		t.Errorf("expected ErrUndoTokenNotFound, got %v", err)
+	}
+}
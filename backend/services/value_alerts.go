@@ -0,0 +1,362 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// priceSnapshotRetentionDays is how long owned price snapshots are kept; price change
+// alerts can't look back further than this
+const priceSnapshotRetentionDays = models.MaxValueAlertWindowDays
+
+// valueAlertListedCards caps how many cards a price change notification names
+const valueAlertListedCards = 5
+
+// priceChangesQuery pairs each owned printing's price on a day with its earliest
+// snapshot since a cutoff, naming the card from its cards row
+const priceChangesQuery = `
+	SELECT cur.scryfall_id, cur.treatment,
+		COALESCE(cards.name, cur.scryfall_id) AS name,
+		base.price AS start_price, cur.price AS current_price
+	FROM price_snapshots cur
+	JOIN price_snapshots base ON base.scryfall_id = cur.scryfall_id
+		AND base.treatment = cur.treatment
+		AND base.currency = cur.currency
+		AND base.date = (
+			SELECT MIN(date) FROM price_snapshots earliest
+			WHERE earliest.scryfall_id = cur.scryfall_id
+				AND earliest.treatment = cur.treatment
+				AND earliest.currency = cur.currency
+				AND earliest.date >= ? AND earliest.date < cur.date)
+	LEFT JOIN cards ON cards.scryfall_id = cur.scryfall_id
+	WHERE cur.date = ? AND cur.currency = ? AND base.price > 0`
+
+// ValueAlertWebhookPayload is the JSON body posted to an alert's webhook when it fires
+// tygo:export
+type ValueAlertWebhookPayload struct {
+	AlertID        uint                       `json:"alert_id"`
+	AlertName      string                     `json:"alert_name"`
+	Condition      models.ValueAlertCondition `json:"condition"`
+	NotificationID uint                       `json:"notification_id"`
+	Title          string                     `json:"title"`
+	Message        string                     `json:"message"`
+	TriggeredAt    time.Time                  `json:"triggered_at"`
+}
+
+// priceChange is an owned printing's price at the start of a window and now
+type priceChange struct {
+	ScryfallID   string
+	Treatment    string
+	Name         string
+	StartPrice   float64
+	CurrentPrice float64
+}
+
+// Percent is the change relative to the starting price
+func (c priceChange) Percent() float64 {
+	return (c.CurrentPrice - c.StartPrice) / c.StartPrice * 100
+}
+
+// ValueAlertService records owned card prices and checks value alerts against them
+type ValueAlertService struct {
+	db            *gorm.DB
+	notifications *NotificationService
+	httpClient    *http.Client // webhook delivery
+}
+
+// NewValueAlertService creates a new value alert service
+func NewValueAlertService(db *gorm.DB, notifications *NotificationService) *ValueAlertService {
+	return &ValueAlertService{
+		db:            db,
+		notifications: notifications,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// RecordPrices stores the current price in currency of every owned printing as its
+// price for the day of now, overwriting earlier snapshots from that day, and drops
+// snapshots past the retention period. Unpriced printings are skipped. It returns
+// how many prices were recorded.
+func (s *ValueAlertService) RecordPrices(ctx context.Context, now time.Time, currency models.Currency) (int, error) {
+	var owned []struct {
+		ScryfallID string
+		Treatment  string
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Distinct("scryfall_id", "treatment").
+		Scan(&owned).Error; err != nil {
+		return 0, fmt.Errorf("loading owned printings: %w", err)
+	}
+
+	ids := make([]string, 0, len(owned))
+	for _, printing := range owned {
+		ids = append(ids, printing.ScryfallID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids) // Foil and nonfoil copies share a card
+	prices, err := models.GetCardPricesByIDs(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return 0, fmt.Errorf("loading owned card prices: %w", err)
+	}
+
+	date := now.Format(models.PriceSnapshotDateFormat)
+	snapshots := make([]models.PriceSnapshot, 0, len(owned))
+	for _, printing := range owned {
+		cardPrices, ok := prices[printing.ScryfallID]
+		if !ok {
+			continue
+		}
+		price := cardPrices.InCurrency(printing.Treatment, currency)
+		if price <= 0 {
+			continue
+		}
+		snapshots = append(snapshots, models.PriceSnapshot{
+			Date:       date,
+			ScryfallID: printing.ScryfallID,
+			Treatment:  printing.Treatment,
+			Currency:   currency,
+			Price:      price,
+		})
+	}
+
+	if len(snapshots) > 0 {
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "date"}, {Name: "scryfall_id"}, {Name: "treatment"}, {Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"price", "updated_at"}),
+		}).CreateInBatches(&snapshots, 500).Error; err != nil {
+			return 0, fmt.Errorf("recording price snapshots: %w", err)
+		}
+	}
+
+	cutoff := now.AddDate(0, 0, -priceSnapshotRetentionDays).Format(models.PriceSnapshotDateFormat)
+	if err := s.db.WithContext(ctx).Where("date < ?", cutoff).Delete(&models.PriceSnapshot{}).Error; err != nil {
+		return len(snapshots), fmt.Errorf("pruning price snapshots: %w", err)
+	}
+	return len(snapshots), nil
+}
+
+// CollectionValue returns the current value in currency of inventory outside the trash
+func (s *ValueAlertService) CollectionValue(ctx context.Context, currency models.Currency) (float64, error) {
+	var items []struct {
+		ScryfallID string
+		Treatment  string
+		Quantity   int
+	}
+	if err := s.db.WithContext(ctx).Model(&models.Inventory{}).
+		Select("scryfall_id", "treatment", "quantity").
+		Scan(&items).Error; err != nil {
+		return 0, fmt.Errorf("loading inventory: %w", err)
+	}
+
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ScryfallID)
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	prices, err := models.GetCardPricesByIDs(s.db.WithContext(ctx), ids)
+	if err != nil {
+		return 0, fmt.Errorf("loading inventory prices: %w", err)
+	}
+
+	var value float64
+	for _, item := range items {
+		if cardPrices, ok := prices[item.ScryfallID]; ok {
+			value += cardPrices.InCurrency(item.Treatment, currency) * float64(item.Quantity)
+		}
+	}
+	return value, nil
+}
+
+// Check records today's owned prices, then checks every enabled alert against them,
+// raising a value_alert notification for each one that fires and posting it to the
+// alert's webhook, if any. Webhook failures are logged, not returned. It returns how
+// many alerts fired.
+func (s *ValueAlertService) Check(ctx context.Context, now time.Time) (int, error) {
+	currency := PreferredCurrency(ctx, s.db)
+	if _, err := s.RecordPrices(ctx, now, currency); err != nil {
+		return 0, err
+	}
+
+	var alerts []models.ValueAlert
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("id ASC").Find(&alerts).Error; err != nil {
+		return 0, fmt.Errorf("loading value alerts: %w", err)
+	}
+
+	var collectionValue *float64 // Computed once, on first use
+	fired := 0
+	for _, alert := range alerts {
+		var title, message string
+		if alert.Condition.IsCollectionValue() {
+			if collectionValue == nil {
+				value, err := s.CollectionValue(ctx, currency)
+				if err != nil {
+					return fired, err
+				}
+				collectionValue = &value
+			}
+			title, message = collectionValueAlert(alert, *collectionValue, currency)
+			if err := s.db.WithContext(ctx).Model(&models.ValueAlert{}).Where("id = ?", alert.ID).
+				UpdateColumn("last_value", *collectionValue).Error; err != nil {
+				return fired, fmt.Errorf("recording value of alert %d: %w", alert.ID, err)
+			}
+		} else {
+			var err error
+			title, message, err = s.priceChangeAlert(ctx, alert, now, currency)
+			if err != nil {
+				return fired, err
+			}
+		}
+		if title == "" {
+			continue
+		}
+
+		notification, err := s.notifications.Create(ctx, models.NotificationTypeValueAlert, title, message)
+		if err != nil {
+			return fired, err
+		}
+		if err := s.db.WithContext(ctx).Model(&models.ValueAlert{}).Where("id = ?", alert.ID).
+			UpdateColumn("last_triggered_at", now).Error; err != nil {
+			return fired, fmt.Errorf("marking alert %d triggered: %w", alert.ID, err)
+		}
+		fired++
+
+		if alert.WebhookURL != "" {
+			if err := s.deliver(ctx, alert, notification, now); err != nil {
+				slog.WarnContext(ctx, "failed to deliver value alert webhook", "component", "value_alerts", "alert_id", alert.ID, "error", err)
+			}
+		}
+	}
+	return fired, nil
+}
+
+// collectionValueAlert returns the notification for a collection value alert when
+// value crossed its threshold since the last check, or empty strings otherwise.
+// The first check only records the value, since no crossing can be seen yet.
+func collectionValueAlert(alert models.ValueAlert, value float64, currency models.Currency) (string, string) {
+	if alert.LastValue == nil {
+		return "", ""
+	}
+	previous := *alert.LastValue
+	threshold := formatAlertAmount(alert.Threshold, currency)
+
+	switch {
+	case alert.Condition == models.ValueAlertCollectionValueAbove && previous < alert.Threshold && value >= alert.Threshold:
+		return fmt.Sprintf("Collection value rose above %s", threshold),
+			fmt.Sprintf("%s: your collection is now worth %s (was %s).", alert.Name, formatAlertAmount(value, currency), formatAlertAmount(previous, currency))
+	case alert.Condition == models.ValueAlertCollectionValueBelow && previous >= alert.Threshold && value < alert.Threshold:
+		return fmt.Sprintf("Collection value fell below %s", threshold),
+			fmt.Sprintf("%s: your collection is now worth %s (was %s).", alert.Name, formatAlertAmount(value, currency), formatAlertAmount(previous, currency))
+	default:
+		return "", ""
+	}
+}
+
+// priceChangeAlert returns the notification for a price change alert when owned
+// printings moved past its threshold over its window, or empty strings otherwise.
+// An alert that fired fires again only once its window has passed, so the same
+// movement isn't reported on every check.
+func (s *ValueAlertService) priceChangeAlert(ctx context.Context, alert models.ValueAlert, now time.Time, currency models.Currency) (string, string, error) {
+	if alert.LastTriggeredAt != nil && now.Before(alert.LastTriggeredAt.AddDate(0, 0, alert.WindowDays)) {
+		return "", "", nil
+	}
+
+	since := now.AddDate(0, 0, -alert.WindowDays).Format(models.PriceSnapshotDateFormat)
+	today := now.Format(models.PriceSnapshotDateFormat)
+	var changes []priceChange
+	if err := s.db.WithContext(ctx).Raw(priceChangesQuery, since, today, currency).Scan(&changes).Error; err != nil {
+		return "", "", fmt.Errorf("loading price changes: %w", err)
+	}
+
+	matched := slices.DeleteFunc(changes, func(change priceChange) bool {
+		if alert.Threshold > 0 {
+			return change.Percent() < alert.Threshold
+		}
+		return change.Percent() > alert.Threshold
+	})
+	if len(matched) == 0 {
+		return "", "", nil
+	}
+	slices.SortFunc(matched, func(a, b priceChange) int {
+		return cmp.Or(cmp.Compare(math.Abs(b.Percent()), math.Abs(a.Percent())), strings.Compare(a.Name, b.Name))
+	})
+
+	direction := "gained"
+	if alert.Threshold < 0 {
+		direction = "dropped"
+	}
+	title := fmt.Sprintf("%d owned printing(s) %s %g%% or more in %d day(s)", len(matched), direction, math.Abs(alert.Threshold), alert.WindowDays)
+
+	lines := make([]string, 0, valueAlertListedCards)
+	for _, change := range matched[:min(len(matched), valueAlertListedCards)] {
+		lines = append(lines, fmt.Sprintf("%s (%s): %s to %s (%+.0f%%)", change.Name, change.Treatment,
+			formatAlertAmount(change.StartPrice, currency), formatAlertAmount(change.CurrentPrice, currency), change.Percent()))
+	}
+	message := alert.Name + ": " + strings.Join(lines, "; ")
+	if more := len(matched) - len(lines); more > 0 {
+		message += fmt.Sprintf("; and %d more", more)
+	}
+	return title, message + ".", nil
+}
+
+// formatAlertAmount renders an amount with its currency code, e.g. "12.50 USD"
+func formatAlertAmount(amount float64, currency models.Currency) string {
+	return fmt.Sprintf("%.2f %s", amount, strings.ToUpper(string(currency)))
+}
+
+// deliver posts a fired alert's notification to its webhook
+func (s *ValueAlertService) deliver(ctx context.Context, alert models.ValueAlert, notification *models.Notification, now time.Time) error {
+	body, err := json.Marshal(ValueAlertWebhookPayload{
+		AlertID:        alert.ID,
+		AlertName:      alert.Name,
+		Condition:      alert.Condition,
+		NotificationID: notification.ID,
+		Title:          notification.Title,
+		Message:        notification.Message,
+		TriggeredAt:    now,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, alert.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RunCheck is the scheduled task entry point for Check
+func (s *ValueAlertService) RunCheck(ctx context.Context) error {
+	fired, err := s.Check(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("checking value alerts: %w", err)
+	}
+	if fired > 0 {
+		slog.InfoContext(ctx, "raised value alert notifications", "component", "value_alerts", "count", fired)
+	}
+	return nil
+}
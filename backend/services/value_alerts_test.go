@@ -0,0 +1,211 @@
+package services
+
+import (
+	"backend/database/databasetest"
+	"backend/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func setupValueAlertTest(t *testing.T) (*gorm.DB, *ValueAlertService) {
+	t.Helper()
+
+	db := databasetest.Open(t)
+
+	return db, NewValueAlertService(db, NewNotificationService(db))
+}
+
+// createPricedCard stores a card with nonfoil and foil USD prices
+func createPricedCard(t *testing.T, db *gorm.DB, id, name, usd, usdFoil string) {
+	t.Helper()
+	card := models.Card{
+		ScryfallID: id,
+		OracleID:   "oracle-" + id,
+		RawJSON:    `{"id": "` + id + `", "name": "` + name + `", "prices": {"usd": "` + usd + `", "usd_foil": "` + usdFoil + `"}}`,
+	}
+	if err := db.Create(&card).Error; err != nil {
+		t.Fatalf("failed to create card: %v", err)
+	}
+}
+
+func valueAlertNotifications(t *testing.T, db *gorm.DB) []models.Notification {
+	t.Helper()
+	var notifications []models.Notification
+	if err := db.Where("type = ?", models.NotificationTypeValueAlert).Order("id ASC").Find(&notifications).Error; err != nil {
+		t.Fatalf("failed to load notifications: %v", err)
+	}
+	return notifications
+}
+
+func TestValueAlertService_RecordPrices(t *testing.T) {
+	db, service := setupValueAlertTest(t)
+	createPricedCard(t, db, "bolt", "Lightning Bolt", "1.00", "3.00")
+	createPricedCard(t, db, "token", "Goblin Token", "", "")
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 4})
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "foil", Quantity: 1})
+	db.Create(&models.Inventory{ScryfallID: "token", OracleID: "oracle-token", Treatment: "nonfoil", Quantity: 1})
+
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	db.Create(&models.PriceSnapshot{Date: "2024-01-01", ScryfallID: "bolt", Treatment: "nonfoil", Currency: models.CurrencyUSD, Price: 0.5})
+
+	recorded, err := service.RecordPrices(context.Background(), now, models.CurrencyUSD)
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if recorded != 2 {
+		t.Errorf("expected the two priced printings recorded, got %d", recorded)
+	}
+
+	// Recording again the same day overwrites rather than adding rows
+	if _, err := service.RecordPrices(context.Background(), now, models.CurrencyUSD); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	var snapshots []models.PriceSnapshot
+	db.Order("treatment ASC").Find(&snapshots)
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots with the old one pruned, got %+v", snapshots)
+	}
+	if snapshots[0].Treatment != "foil" || snapshots[0].Price != 3 || snapshots[0].Date != "2024-05-20" {
+		t.Errorf("expected today's foil price 3.00, got %+v", snapshots[0])
+	}
+}
+
+func TestValueAlertService_Check_PriceChange(t *testing.T) {
+	db, service := setupValueAlertTest(t)
+	createPricedCard(t, db, "bolt", "Lightning Bolt", "1.50", "3.00")
+	createPricedCard(t, db, "helix", "Lightning Helix", "0.90", "")
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 4})
+	db.Create(&models.Inventory{ScryfallID: "helix", OracleID: "oracle-helix", Treatment: "nonfoil", Quantity: 2})
+
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+	for _, snapshot := range []models.PriceSnapshot{
+		{Date: "2024-05-01", ScryfallID: "bolt", Treatment: "nonfoil", Currency: models.CurrencyUSD, Price: 0.10}, // Outside the window
+		{Date: "2024-05-15", ScryfallID: "bolt", Treatment: "nonfoil", Currency: models.CurrencyUSD, Price: 1.00},
+		{Date: "2024-05-17", ScryfallID: "bolt", Treatment: "nonfoil", Currency: models.CurrencyUSD, Price: 1.40},
+		{Date: "2024-05-15", ScryfallID: "helix", Treatment: "nonfoil", Currency: models.CurrencyUSD, Price: 1.00},
+	} {
+		if err := db.Create(&snapshot).Error; err != nil {
+			t.Fatalf("failed to create snapshot: %v", err)
+		}
+	}
+
+	db.Create(&models.ValueAlert{Name: "Spikes", Condition: models.ValueAlertCardPriceChange, Threshold: 20, WindowDays: 7, Enabled: true})
+	db.Create(&models.ValueAlert{Name: "Crashes", Condition: models.ValueAlertCardPriceChange, Threshold: -20, WindowDays: 7, Enabled: true})
+	db.Create(&models.ValueAlert{Name: "Disabled", Condition: models.ValueAlertCardPriceChange, Threshold: 5, WindowDays: 7})
+
+	fired, err := service.Check(context.Background(), now)
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if fired != 1 {
+		t.Fatalf("expected only the gain alert to fire, got %d", fired)
+	}
+
+	notifications := valueAlertNotifications(t, db)
+	if len(notifications) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifications))
+	}
+	if want := "1 owned printing(s) gained 20% or more in 7 day(s)"; notifications[0].Title != want {
+		t.Errorf("expected title %q, got %q", want, notifications[0].Title)
+	}
+	// Measured from the earliest snapshot in the window, 1.00 on the 15th
+	if want := "Spikes: Lightning Bolt (nonfoil): 1.00 USD to 1.50 USD (+50%)."; notifications[0].Message != want {
+		t.Errorf("expected message %q, got %q", want, notifications[0].Message)
+	}
+
+	// A fired alert stays quiet for the rest of its window
+	fired, err = service.Check(context.Background(), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if fired != 0 {
+		t.Errorf("expected no repeat within the window, got %d", fired)
+	}
+}
+
+func TestValueAlertService_Check_CollectionValue(t *testing.T) {
+	db, service := setupValueAlertTest(t)
+	createPricedCard(t, db, "bolt", "Lightning Bolt", "2.00", "")
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3})
+
+	var payloads []ValueAlertWebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload ValueAlertWebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode webhook payload: %v", err)
+		}
+		payloads = append(payloads, payload)
+	}))
+	defer server.Close()
+
+	above := models.ValueAlert{Name: "Milestone", Condition: models.ValueAlertCollectionValueAbove, Threshold: 10, Enabled: true, WebhookURL: server.URL}
+	db.Create(&above)
+	below := models.ValueAlert{Name: "Floor", Condition: models.ValueAlertCollectionValueBelow, Threshold: 5, Enabled: true}
+	db.Create(&below)
+
+	ctx := context.Background()
+	now := time.Date(2024, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	// The first check only records the value, 6.00
+	if fired, err := service.Check(ctx, now); err != nil || fired != 0 {
+		t.Fatalf("expected nothing fired on the first check, got %d (err %v)", fired, err)
+	}
+	db.First(&above, above.ID)
+	if above.LastValue == nil || *above.LastValue != 6 {
+		t.Fatalf("expected last value 6, got %v", above.LastValue)
+	}
+
+	// Rising to 12.00 crosses the upper threshold once
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 3})
+	if fired, err := service.Check(ctx, now.Add(time.Hour)); err != nil || fired != 1 {
+		t.Fatalf("expected the milestone to fire, got %d (err %v)", fired, err)
+	}
+	if fired, err := service.Check(ctx, now.Add(2*time.Hour)); err != nil || fired != 0 {
+		t.Errorf("expected no repeat while the value stays above, got %d (err %v)", fired, err)
+	}
+
+	notifications := valueAlertNotifications(t, db)
+	if len(notifications) != 1 || notifications[0].Title != "Collection value rose above 10.00 USD" {
+		t.Fatalf("expected one milestone notification, got %+v", notifications)
+	}
+	if !strings.Contains(notifications[0].Message, "now worth 12.00 USD (was 6.00 USD)") {
+		t.Errorf("unexpected message %q", notifications[0].Message)
+	}
+
+	if len(payloads) != 1 {
+		t.Fatalf("expected one webhook delivery, got %d", len(payloads))
+	}
+	if payloads[0].AlertID != above.ID || payloads[0].NotificationID != notifications[0].ID || payloads[0].Title != notifications[0].Title {
+		t.Errorf("unexpected webhook payload %+v", payloads[0])
+	}
+}
+
+func TestValueAlertService_Check_WebhookFailureIsNotFatal(t *testing.T) {
+	db, service := setupValueAlertTest(t)
+	createPricedCard(t, db, "bolt", "Lightning Bolt", "2.00", "")
+	db.Create(&models.Inventory{ScryfallID: "bolt", OracleID: "oracle-bolt", Treatment: "nonfoil", Quantity: 1})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	lastValue := 20.0
+	db.Create(&models.ValueAlert{Name: "Floor", Condition: models.ValueAlertCollectionValueBelow, Threshold: 5,
+		Enabled: true, WebhookURL: server.URL, LastValue: &lastValue})
+
+	fired, err := service.Check(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("expected webhook failures to be logged only, got %v", err)
+	}
+	if fired != 1 || len(valueAlertNotifications(t, db)) != 1 {
+		t.Errorf("expected the notification recorded despite the webhook failing, got %d fired", fired)
+	}
+}
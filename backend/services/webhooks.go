@@ -0,0 +1,278 @@
+package services
+
+import (
+	"backend/models"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-ShowMyCards-Event"
+	WebhookDeliveryHeader  = "X-ShowMyCards-Delivery"
+	WebhookSignatureHeader = "X-ShowMyCards-Signature" // sha256=<hex HMAC of the body>, when the webhook has a secret
+)
+
+// maxWebhookDeliveries is how many deliveries are kept in each webhook's log
+const maxWebhookDeliveries = 100
+
+// webhookRetryDelays are the waits before each retry of a failed delivery
+var webhookRetryDelays = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// WebhookPayload is the JSON body posted to a webhook
+// tygo:export
+type WebhookPayload struct {
+	Event models.WebhookEvent `json:"event"`
+	At    time.Time           `json:"at"`
+	Data  any                 `json:"data,omitempty"`
+}
+
+// WebhookJobData describes the finished job of a job.* or bulk_import.* event
+// tygo:export
+type WebhookJobData struct {
+	JobID    uint             `json:"job_id"`
+	Type     models.JobType   `json:"type"`
+	Status   models.JobStatus `json:"status"`
+	Error    string           `json:"error,omitempty"`
+	Metadata json.RawMessage  `json:"metadata,omitempty"`
+}
+
+// WebhookInventoryChangeData describes the batch operation of an inventory.bulk_change event
+// tygo:export
+type WebhookInventoryChangeData struct {
+	OperationID uint   `json:"operation_id"`
+	Operation   string `json:"operation"`
+	ItemCount   int    `json:"item_count"`
+}
+
+// WebhookService sends events to subscribed webhooks and keeps their delivery log.
+// Deliveries run in the background and are retried with backoff. A nil
+// *WebhookService is valid and drops everything, so services can notify it
+// unconditionally.
+type WebhookService struct {
+	db          *gorm.DB
+	httpClient  *http.Client
+	retryDelays []time.Duration
+	wg          sync.WaitGroup // Deliveries in flight
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db:          db,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		retryDelays: webhookRetryDelays,
+	}
+}
+
+// SignWebhookPayload returns the signature header value for body under secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatch sends event to every enabled webhook subscribed to it
+func (s *WebhookService) Dispatch(ctx context.Context, event models.WebhookEvent, data any) {
+	if s == nil {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Find(&webhooks).Error; err != nil {
+		slog.WarnContext(ctx, "failed to load webhooks", "component", "webhooks", "event", event, "error", err)
+		return
+	}
+	for i := range webhooks {
+		if !webhooks[i].Subscribed(event) {
+			continue
+		}
+		if _, err := s.Send(ctx, &webhooks[i], event, data); err != nil {
+			slog.WarnContext(ctx, "failed to queue webhook delivery", "component", "webhooks",
+				"webhook_id", webhooks[i].ID, "event", event, "error", err)
+		}
+	}
+}
+
+// Send records a delivery of event to webhook and starts attempting it in the
+// background, whether or not the webhook is enabled or subscribed
+func (s *WebhookService) Send(ctx context.Context, webhook *models.Webhook, event models.WebhookEvent, data any) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(WebhookPayload{Event: event, At: time.Now(), Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s payload: %w", event, err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     event,
+		Payload:   string(body),
+		Status:    models.WebhookDeliveryPending,
+	}
+	if err := s.db.WithContext(ctx).Create(delivery).Error; err != nil {
+		return nil, fmt.Errorf("recording %s delivery: %w", event, err)
+	}
+
+	// Delivery outlives the request or job that raised the event. A request's context
+	// is recycled once it returns, so none of it is kept.
+	target := *webhook
+	record := *delivery
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.deliver(context.Background(), &target, &record)
+	}()
+
+	return delivery, nil
+}
+
+// Wait blocks until every delivery in flight has succeeded or run out of retries
+func (s *WebhookService) Wait() {
+	s.wg.Wait()
+}
+
+// deliver attempts a delivery until it succeeds or its retries run out, recording the
+// outcome of each attempt
+func (s *WebhookService) deliver(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	for attempt := 0; ; attempt++ {
+		statusCode, err := s.post(ctx, webhook, delivery)
+		delivery.Attempts = attempt + 1
+		delivery.StatusCode = statusCode
+		delivery.Error = ""
+		if err == nil {
+			now := time.Now()
+			delivery.Status = models.WebhookDeliveryDelivered
+			delivery.DeliveredAt = &now
+		} else {
+			delivery.Error = err.Error()
+			if attempt >= len(s.retryDelays) {
+				delivery.Status = models.WebhookDeliveryFailed
+				slog.WarnContext(ctx, "webhook delivery failed", "component", "webhooks",
+					"webhook_id", webhook.ID, "delivery_id", delivery.ID, "attempts", delivery.Attempts, "error", err)
+			}
+		}
+
+		if saveErr := s.db.WithContext(ctx).Save(delivery).Error; saveErr != nil {
+			slog.WarnContext(ctx, "failed to record webhook delivery", "component", "webhooks",
+				"delivery_id", delivery.ID, "error", saveErr)
+		}
+		if delivery.Status != models.WebhookDeliveryPending {
+			break
+		}
+		time.Sleep(s.retryDelays[attempt])
+	}
+
+	s.prune(ctx, webhook.ID)
+}
+
+// post sends one attempt of a delivery, returning the response status if there was one
+func (s *WebhookService) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	if webhook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// prune keeps only the newest maxWebhookDeliveries deliveries of a webhook
+func (s *WebhookService) prune(ctx context.Context, webhookID uint) {
+	keep := s.db.Model(&models.WebhookDelivery{}).Select("id").
+		Where("webhook_id = ?", webhookID).Order("id DESC").Limit(maxWebhookDeliveries)
+	if err := s.db.WithContext(ctx).
+		Where("webhook_id = ? AND id NOT IN (?)", webhookID, keep).
+		Delete(&models.WebhookDelivery{}).Error; err != nil {
+		slog.WarnContext(ctx, "failed to prune webhook deliveries", "component", "webhooks",
+			"webhook_id", webhookID, "error", err)
+	}
+}
+
+// Deliveries lists a webhook's deliveries with pagination, newest first
+func (s *WebhookService) Deliveries(ctx context.Context, webhookID uint, page, pageSize int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("counting webhook deliveries: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id DESC").Limit(pageSize).Offset(offset).Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("listing webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+// JobFinished sends job.completed or job.failed for a job that just finished, and
+// bulk_import.completed or bulk_import.failed when it is a bulk data import
+func (s *WebhookService) JobFinished(ctx context.Context, jobID uint) {
+	if s == nil {
+		return
+	}
+
+	var job models.Job
+	if err := s.db.WithContext(ctx).First(&job, jobID).Error; err != nil {
+		slog.WarnContext(ctx, "failed to load finished job for webhooks", "component", "webhooks", "job_id", jobID, "error", err)
+		return
+	}
+
+	var jobEvent, importEvent models.WebhookEvent
+	switch job.Status {
+	case models.JobStatusCompleted:
+		jobEvent, importEvent = models.WebhookEventJobCompleted, models.WebhookEventBulkImportCompleted
+	case models.JobStatusFailed:
+		jobEvent, importEvent = models.WebhookEventJobFailed, models.WebhookEventBulkImportFailed
+	default:
+		return
+	}
+
+	data := WebhookJobData{JobID: job.ID, Type: job.Type, Status: job.Status, Error: job.Error}
+	if json.Valid([]byte(job.Metadata)) {
+		data.Metadata = json.RawMessage(job.Metadata)
+	}
+	s.Dispatch(ctx, jobEvent, data)
+	if job.Type == models.JobTypeBulkDataImport {
+		s.Dispatch(ctx, importEvent, data)
+	}
+}
+
+// InventoryChanged sends inventory.bulk_change for a recorded batch operation that
+// changed at least webhook_inventory_change_threshold items
+func (s *WebhookService) InventoryChanged(ctx context.Context, operation *models.InventoryOperation) {
+	if s == nil || operation.ItemCount < WebhookInventoryChangeThreshold(ctx, s.db) {
+		return
+	}
+	s.Dispatch(ctx, models.WebhookEventInventoryBulkChange, WebhookInventoryChangeData{
+		OperationID: operation.ID,
+		Operation:   operation.Operation,
+		ItemCount:   operation.ItemCount,
+	})
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupWebhookTest(t *testing.T) (*gorm.DB, *WebhookService) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to setup test db: %v", err)
+	}
+	// Deliveries run on other goroutines; every connection to :memory: is a separate database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get sql db: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&models.Setting{}, &models.Job{}, &models.InventoryOperation{},
+		&models.Webhook{}, &models.WebhookDelivery{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	service := NewWebhookService(db)
+	service.retryDelays = []time.Duration{time.Millisecond}
+	return db, service
+}
+
+// webhookReceiver records the requests a test webhook endpoint receives
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) received() ([]*http.Request, [][]byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests, r.bodies
+}
+
+func newWebhookReceiver(t *testing.T, status int) (*webhookReceiver, string) {
+	t.Helper()
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.requests = append(receiver.requests, r)
+		receiver.bodies = append(receiver.bodies, body)
+		receiver.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server.URL
+}
+
+func createWebhook(t *testing.T, db *gorm.DB, url, secret string, events ...models.WebhookEvent) models.Webhook {
+	t.Helper()
+	webhook := models.Webhook{Name: "Test", URL: url, Secret: secret, Events: events, Enabled: true}
+	if err := db.Create(&webhook).Error; err != nil {
+		t.Fatalf("failed to create webhook: %v", err)
+	}
+	return webhook
+}
+
+func TestWebhookService_Dispatch_SignedDelivery(t *testing.T) {
+	db, service := setupWebhookTest(t)
+	receiver, url := newWebhookReceiver(t, http.StatusNoContent)
+
+	webhook := createWebhook(t, db, url, "s3cret", models.WebhookEventJobFailed)
+	createWebhook(t, db, url, "", models.WebhookEventJobCompleted) // Not subscribed
+	disabled := createWebhook(t, db, url, "", models.WebhookEventJobFailed)
+	db.Model(&disabled).UpdateColumn("enabled", false)
+
+	service.Dispatch(context.Background(), models.WebhookEventJobFailed, WebhookJobData{JobID: 7, Status: models.JobStatusFailed})
+	service.Wait()
+
+	requests, bodies := receiver.received()
+	if len(requests) != 1 {
+		t.Fatalf("expected one delivery, got %d", len(requests))
+	}
+	if got := requests[0].Header.Get(WebhookEventHeader); got != "job.failed" {
+		t.Errorf("expected event header job.failed, got %q", got)
+	}
+	if got, want := requests[0].Header.Get(WebhookSignatureHeader), SignWebhookPayload("s3cret", bodies[0]); got != want {
+		t.Errorf("expected signature %q, got %q", want, got)
+	}
+
+	var payload struct {
+		Event models.WebhookEvent `json:"event"`
+		Data  WebhookJobData      `json:"data"`
+	}
+	if err := json.Unmarshal(bodies[0], &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if payload.Event != models.WebhookEventJobFailed || payload.Data.JobID != 7 {
+		t.Errorf("unexpected payload %s", bodies[0])
+	}
+
+	deliveries, total, err := service.Deliveries(context.Background(), webhook.ID, 1, 10)
+	if err != nil {
+		t.Fatalf("listing deliveries failed: %v", err)
+	}
+	if total != 1 || deliveries[0].Status != models.WebhookDeliveryDelivered || deliveries[0].Attempts != 1 ||
+		deliveries[0].StatusCode != http.StatusNoContent || deliveries[0].DeliveredAt == nil {
+		t.Errorf("expected one delivered attempt logged, got %+v", deliveries)
+	}
+	if got := requests[0].Header.Get(WebhookDeliveryHeader); got != "1" {
+		t.Errorf("expected delivery header 1, got %q", got)
+	}
+}
+
+func TestWebhookService_RetriesThenFails(t *testing.T) {
+	db, service := setupWebhookTest(t)
+	receiver, url := newWebhookReceiver(t, http.StatusBadGateway)
+	webhook := createWebhook(t, db, url, "", models.WebhookEventJobCompleted)
+
+	if _, err := service.Send(context.Background(), &webhook, models.WebhookEventPing, nil); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	service.Wait()
+
+	if requests, _ := receiver.received(); len(requests) != 2 {
+		t.Errorf("expected the first attempt and one retry, got %d requests", len(requests))
+	}
+	var delivery models.WebhookDelivery
+	db.First(&delivery)
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.Attempts != 2 || delivery.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected a failed delivery after 2 attempts, got %+v", delivery)
+	}
+	if delivery.Error != "webhook returned status 502" {
+		t.Errorf("unexpected error %q", delivery.Error)
+	}
+}
+
+func TestWebhookService_JobFinished(t *testing.T) {
+	db, service := setupWebhookTest(t)
+	receiver, url := newWebhookReceiver(t, http.StatusOK)
+	createWebhook(t, db, url, "", models.WebhookEventJobFailed, models.WebhookEventBulkImportFailed)
+
+	jobs := NewJobService(db)
+	jobs.SetWebhooks(service)
+	ctx := context.Background()
+
+	imported, _ := jobs.Create(ctx, models.JobTypeBulkDataImport, `{"processed_cards": 10}`)
+	if err := jobs.Fail(ctx, imported.ID, "download failed"); err != nil {
+		t.Fatalf("fail failed: %v", err)
+	}
+	// Completions aren't subscribed, and cancelled jobs don't count as failed
+	resort, _ := jobs.Create(ctx, models.JobTypeResort, "")
+	jobs.Complete(ctx, resort.ID)
+	cancelled, _ := jobs.Create(ctx, models.JobTypeReindex, "")
+	jobs.Cancel(ctx, cancelled.ID)
+	jobs.Fail(ctx, cancelled.ID, "context canceled")
+	service.Wait()
+
+	requests, bodies := receiver.received()
+	if len(requests) != 2 {
+		t.Fatalf("expected job.failed and bulk_import.failed, got %d deliveries", len(requests))
+	}
+	events := map[string]bool{}
+	for i, request := range requests {
+		events[request.Header.Get(WebhookEventHeader)] = true
+		var payload WebhookPayload
+		var data WebhookJobData
+		payload.Data = &data
+		if err := json.Unmarshal(bodies[i], &payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if data.JobID != imported.ID || data.Error != "download failed" || string(data.Metadata) != `{"processed_cards":10}` {
+			t.Errorf("unexpected job data %+v", data)
+		}
+	}
+	if !events["job.failed"] || !events["bulk_import.failed"] {
+		t.Errorf("unexpected events %v", events)
+	}
+}
+
+func TestWebhookService_InventoryChanged(t *testing.T) {
+	db, service := setupWebhookTest(t)
+	receiver, url := newWebhookReceiver(t, http.StatusOK)
+	createWebhook(t, db, url, "", models.WebhookEventInventoryBulkChange)
+	db.Create(&models.Setting{Key: "webhook_inventory_change_threshold", Value: "10"})
+
+	undo := NewUndoService(db)
+	undo.SetWebhooks(service)
+	ctx := context.Background()
+
+	small := make([]models.Inventory, 9)
+	large := make([]models.Inventory, 10)
+	if _, err := undo.Record(ctx, UndoSnapshot{Operation: UndoOperationBatchMove, Rows: small}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	receipt, err := undo.Record(ctx, UndoSnapshot{Operation: UndoOperationBatchDelete, Rows: large})
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	service.Wait()
+
+	_, bodies := receiver.received()
+	if len(bodies) != 1 {
+		t.Fatalf("expected only the operation at the threshold sent, got %d", len(bodies))
+	}
+	var payload WebhookPayload
+	var data WebhookInventoryChangeData
+	payload.Data = &data
+	if err := json.Unmarshal(bodies[0], &payload); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if data.OperationID != receipt.OperationID || data.Operation != "batch_delete" || data.ItemCount != 10 {
+		t.Errorf("unexpected change data %+v", data)
+	}
+}
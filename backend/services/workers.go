@@ -0,0 +1,198 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+)
+
+// DefaultWorkerPoolSize is how many background jobs run at once. The database has a
+// single connection, so more workers would mostly wait on each other.
+const DefaultWorkerPoolSize = 4
+
+// ErrWorkersStopped is returned when submitting work after Shutdown has begun
+var ErrWorkersStopped = errors.New("background workers are shutting down")
+
+// PanicError is the error a panicking task is recovered as
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// WorkerPool runs background work with bounded concurrency. Work submitted while every
+// worker is busy waits its turn; panics are recovered and logged with their stack, and
+// Shutdown waits for running and queued work to finish.
+//
+// A nil *WorkerPool is valid and runs each task on its own goroutine, with the same
+// panic recovery but no limit and nothing to drain.
+type WorkerPool struct {
+	slots chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewWorkerPool creates a pool running at most size tasks at once
+func NewWorkerPool(size int) *WorkerPool {
+	return &WorkerPool{slots: make(chan struct{}, max(size, 1))}
+}
+
+// Go runs fn in the background under ctx, which should outlive any request that
+// submits it. name and attrs identify the work in logs; errors fn returns are logged
+// there too. It returns ErrWorkersStopped once Shutdown has begun.
+func (p *WorkerPool) Go(ctx context.Context, name string, fn func(ctx context.Context) error, attrs ...any) error {
+	if p == nil {
+		go runTask(ctx, name, fn, attrs)
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return ErrWorkersStopped
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+		runTask(ctx, name, fn, attrs)
+	}()
+	return nil
+}
+
+// Shutdown stops accepting work and waits for running and queued work to finish, or
+// for ctx to end. Cancel the context work was submitted under first, so running jobs
+// stop at their next batch and record their outcome rather than being cut off.
+func (p *WorkerPool) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for background work: %w", ctx.Err())
+	}
+}
+
+// runTask runs fn, logging its error or recovered panic
+func runTask(ctx context.Context, name string, fn func(ctx context.Context) error, attrs []any) {
+	err := runRecovered(ctx, fn)
+	if err == nil {
+		return
+	}
+
+	attrs = append([]any{"component", "workers", "task", name, "error", err}, attrs...)
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		slog.ErrorContext(ctx, "background task panicked", append(attrs, "stack", string(panicErr.Stack))...)
+		return
+	}
+	slog.ErrorContext(ctx, "background task failed", attrs...)
+}
+
+// runRecovered calls fn, returning a panic as a *PanicError
+func runRecovered(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+// SetWorkers runs background jobs started through Go on workers
+func (s *JobService) SetWorkers(workers *WorkerPool) {
+	s.workers = workers
+}
+
+// Workers returns the pool background work runs on; nil runs it on plain goroutines
+func (s *JobService) Workers() *WorkerPool {
+	return s.workers
+}
+
+// Go runs a job's work on the worker pool. A job cancelled while it waited for a
+// worker doesn't run, and one whose work panics is marked failed, since it never got
+// to record its outcome.
+func (s *JobService) Go(ctx context.Context, jobID uint, name string, fn func(ctx context.Context) error) error {
+	done := s.track(jobID)
+	err := s.workers.Go(ctx, name, func(ctx context.Context) error {
+		defer done()
+		if job, err := s.Get(ctx, jobID); err == nil && job.Status == models.JobStatusCancelled {
+			return nil
+		}
+
+		err := runRecovered(ctx, fn)
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			cleanupCtx := context.WithoutCancel(ctx)
+			if job, getErr := s.Get(cleanupCtx, jobID); getErr == nil &&
+				(job.Status == models.JobStatusPending || job.Status == models.JobStatusInProgress) {
+				if failErr := s.Fail(cleanupCtx, jobID, panicErr.Error()); failErr != nil {
+					slog.ErrorContext(ctx, "failed to mark job as failed", "job_id", jobID, "error", failErr)
+				}
+			}
+		}
+		return err
+	}, "job_id", jobID)
+	if err != nil {
+		done()
+	}
+	return err
+}
+
+// track records a job's work as queued or running until the returned function is called
+func (s *JobService) track(jobID uint) func() {
+	done := make(chan struct{})
+	s.cancelMu.Lock()
+	s.running[jobID] = done
+	s.cancelMu.Unlock()
+
+	return sync.OnceFunc(func() {
+		s.cancelMu.Lock()
+		if s.running[jobID] == done {
+			delete(s.running, jobID)
+		}
+		s.cancelMu.Unlock()
+		close(done)
+	})
+}
+
+// WaitStopped waits for the work of the given jobs started through Go to return, or
+// for ctx to end. Jobs with no work queued or running in this process don't wait.
+func (s *JobService) WaitStopped(ctx context.Context, jobIDs ...uint) error {
+	for _, jobID := range jobIDs {
+		s.cancelMu.Lock()
+		done, ok := s.running[jobID]
+		s.cancelMu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for job %d to stop: %w", jobID, ctx.Err())
+		}
+	}
+	return nil
+}
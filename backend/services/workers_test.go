@@ -0,0 +1,226 @@
+package services
+
+import (
+	"backend/models"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_BoundsConcurrency(t *testing.T) {
+	pool := NewWorkerPool(2)
+	ctx := context.Background()
+
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		err := pool.Go(ctx, "test", func(ctx context.Context) error {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			<-release
+			running.Add(-1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := running.Load(); got != 2 {
+		t.Errorf("expected 2 tasks running, got %d", got)
+	}
+	close(release)
+
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("expected at most 2 tasks at once, got %d", got)
+	}
+}
+
+func TestWorkerPool_RecoversPanic(t *testing.T) {
+	pool := NewWorkerPool(1)
+	ctx := context.Background()
+
+	if err := pool.Go(ctx, "test", func(ctx context.Context) error { panic("boom") }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The worker survives to run the next task
+	ran := make(chan struct{})
+	if err := pool.Go(ctx, "test", func(ctx context.Context) error { close(ran); return nil }); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("task after a panic never ran")
+	}
+}
+
+func TestWorkerPool_ShutdownDrains(t *testing.T) {
+	pool := NewWorkerPool(1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var finished atomic.Int32
+	for i := 0; i < 3; i++ {
+		err := pool.Go(ctx, "test", func(ctx context.Context) error {
+			<-ctx.Done()
+			finished.Add(1)
+			return ctx.Err()
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	cancel()
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := finished.Load(); got != 3 {
+		t.Errorf("expected every queued task to finish, got %d", got)
+	}
+
+	err := pool.Go(context.Background(), "test", func(ctx context.Context) error { return nil })
+	if !errors.Is(err, ErrWorkersStopped) {
+		t.Errorf("expected ErrWorkersStopped after shutdown, got %v", err)
+	}
+}
+
+func TestWorkerPool_ShutdownTimeout(t *testing.T) {
+	pool := NewWorkerPool(1)
+	release := make(chan struct{})
+	defer close(release)
+
+	if err := pool.Go(context.Background(), "test", func(ctx context.Context) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestWorkerPool_Nil(t *testing.T) {
+	var pool *WorkerPool
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	if err := pool.Go(context.Background(), "test", func(ctx context.Context) error {
+		defer wg.Done()
+		panic("boom")
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pool.Go(context.Background(), "test", func(ctx context.Context) error {
+		wg.Done()
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wg.Wait()
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func setupJobWorkersTest(t *testing.T) (*JobService, *WorkerPool) {
+	t.Helper()
+
+	service, db := setupJobServiceTest(t)
+	sqlDB, _ := db.DB()
+	// Jobs run on other goroutines, so keep a single connection to the in-memory database
+	sqlDB.SetMaxOpenConns(1)
+
+	pool := NewWorkerPool(1)
+	service.SetWorkers(pool)
+	return service, pool
+}
+
+func TestJobService_Go_FailsJobOnPanic(t *testing.T) {
+	service, pool := setupJobWorkersTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	if err := service.Go(ctx, job.ID, "test", func(ctx context.Context) error {
+		if err := service.Start(ctx, job.ID); err != nil {
+			return err
+		}
+		panic("boom")
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, _ := service.Get(ctx, job.ID)
+	if got.Status != models.JobStatusFailed {
+		t.Errorf("expected failed, got %s", got.Status)
+	}
+	if got.Error != "panic: boom" {
+		t.Errorf("expected the panic as the error, got %q", got.Error)
+	}
+}
+
+func TestJobService_Go_SkipsCancelledJob(t *testing.T) {
+	service, pool := setupJobWorkersTest(t)
+	ctx := context.Background()
+
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	if _, err := service.Cancel(ctx, job.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ran := false
+	if err := service.Go(ctx, job.ID, "test", func(ctx context.Context) error {
+		ran = true
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ran {
+		t.Error("expected a job cancelled while queued not to run")
+	}
+}
+
+func TestJobService_Retry_WorkersStopped(t *testing.T) {
+	service, pool := setupJobWorkersTest(t)
+	ctx := context.Background()
+
+	service.RegisterRetry(models.JobTypeBulkDataImport, func(ctx context.Context, job *models.Job) error { return nil })
+	job, _ := service.Create(ctx, models.JobTypeBulkDataImport, "")
+	service.Fail(ctx, job.ID, "download failed")
+	if err := pool.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.Retry(ctx, ctx, job.ID); !errors.Is(err, ErrWorkersStopped) {
+		t.Fatalf("expected ErrWorkersStopped, got %v", err)
+	}
+	got, _ := service.Get(ctx, job.ID)
+	if got.Status != models.JobStatusFailed || got.Error != "download failed" {
+		t.Errorf("expected the job left failed with its error, got %s %q", got.Status, got.Error)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package utils
+
+import "errors"
+
+// ErrDiskSpaceUnsupported is returned by DiskSpace on platforms it can't measure
+var ErrDiskSpaceUnsupported = errors.New("disk space is not available on this platform")
+
+// DiskSpace returns the free and total bytes of the filesystem holding path
+func DiskSpace(path string) (free, total uint64, err error) {
+	return 0, 0, ErrDiskSpaceUnsupported
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package utils
+
+import "syscall"
+
+// DiskSpace returns the free and total bytes of the filesystem holding path. Free
+// counts only the space available to unprivileged users.
+func DiskSpace(path string) (free, total uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), stat.Blocks * uint64(stat.Bsize), nil
+}
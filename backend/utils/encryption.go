@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// Encrypted payload layout: magic | salt | nonce | AES-256-GCM ciphertext.
+// The key is derived from the passphrase with PBKDF2-SHA256.
+const (
+	encryptionSaltSize   = 16
+	encryptionKeySize    = 32
+	encryptionIterations = 600_000
+)
+
+// encryptionMagic identifies (and versions) data produced by EncryptWithPassphrase
+var encryptionMagic = []byte("SMCENC1\n")
+
+var (
+	// ErrDecryptionFailed is returned when the passphrase is wrong or the data was tampered with
+	ErrDecryptionFailed = errors.New("decryption failed: wrong passphrase or corrupted data")
+
+	// ErrNotEncrypted is returned when decrypting data that lacks the encryption header
+	ErrNotEncrypted = errors.New("data is not encrypted")
+)
+
+// IsEncrypted reports whether data carries the EncryptWithPassphrase header
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptionMagic)
+}
+
+// EncryptWithPassphrase encrypts plaintext with a key derived from the passphrase
+func EncryptWithPassphrase(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase cannot be empty")
+	}
+
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+
+	gcm, err := newPassphraseGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(encryptionMagic)+len(salt)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, encryptionMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	// The header is authenticated so it cannot be swapped onto another payload
+	return gcm.Seal(out, nonce, plaintext, out), nil
+}
+
+// DecryptWithPassphrase reverses EncryptWithPassphrase
+func DecryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, ErrNotEncrypted
+	}
+
+	header := len(encryptionMagic) + encryptionSaltSize
+	if len(data) < header {
+		return nil, ErrDecryptionFailed
+	}
+	salt := data[len(encryptionMagic):header]
+
+	gcm, err := newPassphraseGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < header+gcm.NonceSize() {
+		return nil, ErrDecryptionFailed
+	}
+	nonce := data[header : header+gcm.NonceSize()]
+	aad := data[:header+gcm.NonceSize()]
+
+	plaintext, err := gcm.Open(nil, nonce, data[header+gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+// newPassphraseGCM derives an AES-256 key from the passphrase and salt
+func newPassphraseGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, encryptionIterations, encryptionKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("deriving key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating GCM: %w", err)
+	}
+	return gcm, nil
+}
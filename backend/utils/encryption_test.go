@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptWithPassphrase_RoundTrip(t *testing.T) {
+	plaintext := []byte(`{"version":1,"inventory":[]}`)
+
+	encrypted, err := EncryptWithPassphrase(plaintext, "correct horse")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Error("expected encrypted data to carry the header")
+	}
+	if bytes.Contains(encrypted, plaintext) {
+		t.Error("expected plaintext not to appear in encrypted output")
+	}
+
+	decrypted, err := DecryptWithPassphrase(encrypted, "correct horse")
+	if err != nil {
+		t.Fatalf("decrypt failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %q, got %q", plaintext, decrypted)
+	}
+}
+
+func TestDecryptWithPassphrase_WrongPassphrase(t *testing.T) {
+	encrypted, err := EncryptWithPassphrase([]byte("secret"), "correct horse")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	if _, err := DecryptWithPassphrase(encrypted, "battery staple"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected ErrDecryptionFailed, got %v", err)
+	}
+}
+
+func TestDecryptWithPassphrase_Tampered(t *testing.T) {
+	encrypted, err := EncryptWithPassphrase([]byte("secret"), "correct horse")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	encrypted[len(encrypted)-1] ^= 0xff
+
+	if _, err := DecryptWithPassphrase(encrypted, "correct horse"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("expected ErrDecryptionFailed, got %v", err)
+	}
+}
+
+func TestDecryptWithPassphrase_NotEncrypted(t *testing.T) {
+	if _, err := DecryptWithPassphrase([]byte(`{"version":1}`), "pass"); !errors.Is(err, ErrNotEncrypted) {
+		t.Errorf("expected ErrNotEncrypted, got %v", err)
+	}
+}
+
+func TestEncryptWithPassphrase_EmptyPassphrase(t *testing.T) {
+	if _, err := EncryptWithPassphrase([]byte("data"), ""); err == nil {
+		t.Error("expected error for empty passphrase")
+	}
+}
@@ -3,7 +3,9 @@
 package utils
 
 import (
+	"backend/database"
 	"log/slog"
+	"strconv"
 
 	scryfall "github.com/BlueMonday/go-scryfall"
 	"github.com/gofiber/fiber/v3"
@@ -14,12 +16,22 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-// LogAndReturnError logs server-side and returns user-friendly error
+// DatabaseBusyMessage is returned when a write keeps colliding with another writer
+const DatabaseBusyMessage = "The database is busy, please try again shortly"
+
+// LogAndReturnError logs server-side and returns user-friendly error.
+// Persistent database contention is reported as 503 with a Retry-After header
+// instead of the given status.
 func LogAndReturnError(c fiber.Ctx, statusCode int, userMsg, logMsg string, err error) error {
+	if database.IsBusy(err) {
+		slog.WarnContext(c.RequestCtx(), "request failed: database busy", "method", c.Method(), "path", c.Path(), "message", logMsg, "error", err)
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(database.BusyRetryAfterSeconds))
+		return c.Status(fiber.StatusServiceUnavailable).JSON(ErrorResponse{Error: DatabaseBusyMessage})
+	}
 	if err != nil {
-		slog.Error("request failed", "method", c.Method(), "path", c.Path(), "message", logMsg, "error", err)
+		slog.ErrorContext(c.RequestCtx(), "request failed", "method", c.Method(), "path", c.Path(), "message", logMsg, "error", err)
 	} else {
-		slog.Error("request failed", "method", c.Method(), "path", c.Path(), "message", logMsg)
+		slog.ErrorContext(c.RequestCtx(), "request failed", "method", c.Method(), "path", c.Path(), "message", logMsg)
 	}
 	return c.Status(statusCode).JSON(ErrorResponse{Error: userMsg})
 }
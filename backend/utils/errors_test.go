@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"backend/database"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http/httptest"
 	"testing"
@@ -256,3 +258,52 @@ func TestMapScryfallStatus(t *testing.T) {
 	}
 }
 
+func TestLogAndReturnError_DatabaseBusy(t *testing.T) {
+	app := fiber.New()
+
+	app.Post("/test", func(c fiber.Ctx) error {
+		busyErr := fmt.Errorf("failed to create inventory: %w", database.ErrDatabaseBusy)
+		return LogAndReturnError(c, fiber.StatusInternalServerError, "Failed to create inventory", "create failed", busyErr)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != 503 {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != fmt.Sprint(database.BusyRetryAfterSeconds) {
+		t.Errorf("expected Retry-After %d, got '%s'", database.BusyRetryAfterSeconds, retryAfter)
+	}
+
+	var result ErrorResponse
+	body, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if result.Error != DatabaseBusyMessage {
+		t.Errorf("expected error '%s', got '%s'", DatabaseBusyMessage, result.Error)
+	}
+}
+
+func TestLogAndReturnError_GenericError(t *testing.T) {
+	app := fiber.New()
+
+	app.Post("/test", func(c fiber.Ctx) error {
+		return LogAndReturnError(c, fiber.StatusInternalServerError, "Failed to create inventory", "create failed", errors.New("disk full"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/test", nil))
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		t.Errorf("expected no Retry-After header, got '%s'", retryAfter)
+	}
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"math"
+	"strconv"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -80,3 +81,63 @@ func NewPaginatedResponse[T any](data []T, page, pageSize int, totalItems int64)
 		TotalPages: CalculateTotalPages(totalItems, pageSize),
 	}
 }
+
+// API versions select the response shape of paginated endpoints
+const (
+	// APIVersionLegacy keeps each endpoint's original shape: PaginatedResponse, or an
+	// endpoint-specific struct such as InventoryCardsResponse or ListItemsResponse
+	APIVersionLegacy = 1
+	// APIVersionEnvelope returns Envelope from every paginated endpoint
+	APIVersionEnvelope = 2
+)
+
+// APIVersionHeader is the request header selecting the API version; the api_version
+// query parameter is accepted too. Requests without either get the legacy shapes.
+const APIVersionHeader = "X-API-Version"
+
+// RequestAPIVersion returns the API version the request asked for
+func RequestAPIVersion(c fiber.Ctx) int {
+	raw := c.Get(APIVersionHeader)
+	if raw == "" {
+		raw = c.Query("api_version")
+	}
+	if version, err := strconv.Atoi(raw); err == nil && version == APIVersionEnvelope {
+		return APIVersionEnvelope
+	}
+	return APIVersionLegacy
+}
+
+// Envelope is the paginated response shape shared by every endpoint from API version 2.
+// Aggregates holds endpoint-specific totals computed across all pages, if any.
+type Envelope[T any] struct {
+	Items      []T            `json:"items"`
+	Page       int            `json:"page"`
+	PageSize   int            `json:"page_size"`
+	TotalItems int64          `json:"total_items"`
+	TotalPages int            `json:"total_pages"`
+	Aggregates map[string]any `json:"aggregates,omitempty"`
+}
+
+// NewEnvelope creates an envelope; items is never encoded as null
+func NewEnvelope[T any](items []T, page, pageSize int, totalItems int64, aggregates map[string]any) Envelope[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Envelope[T]{
+		Items:      items,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: CalculateTotalPages(totalItems, pageSize),
+		Aggregates: aggregates,
+	}
+}
+
+// SendPaginated writes a page of data as an Envelope for API version 2 requests and
+// as a PaginatedResponse otherwise
+func SendPaginated[T any](c fiber.Ctx, data []T, page, pageSize int, totalItems int64) error {
+	if RequestAPIVersion(c) == APIVersionEnvelope {
+		return c.JSON(NewEnvelope(data, page, pageSize, totalItems, nil))
+	}
+	return c.JSON(NewPaginatedResponse(data, page, pageSize, totalItems))
+}
@@ -1,8 +1,12 @@
 package utils
 
 import (
+	"encoding/json"
 	"math"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/gofiber/fiber/v3"
 )
 
 func TestCalculateTotalPages(t *testing.T) {
@@ -93,3 +97,71 @@ func TestNewPaginatedResponse(t *testing.T) {
 		t.Errorf("expected 3 items in Data, got %d", len(resp.Data))
 	}
 }
+
+func TestNewEnvelope(t *testing.T) {
+	resp := NewEnvelope([]string{"a"}, 1, 10, 11, map[string]any{"total_wanted": 4})
+	if resp.TotalPages != 2 || len(resp.Items) != 1 || resp.Aggregates["total_wanted"] != 4 {
+		t.Errorf("unexpected envelope: %+v", resp)
+	}
+
+	// An empty page encodes items as [] rather than null
+	body, err := json.Marshal(NewEnvelope[string](nil, 1, 10, 0, nil))
+	if err != nil {
+		t.Fatalf("failed to encode envelope: %v", err)
+	}
+	expected := `{"items":[],"page":1,"page_size":10,"total_items":0,"total_pages":0}`
+	if string(body) != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+}
+
+func TestSendPaginated_APIVersion(t *testing.T) {
+	app := fiber.New()
+	app.Get("/test", func(c fiber.Ctx) error {
+		return SendPaginated(c, []int{1, 2}, 1, 2, 5)
+	})
+
+	tests := []struct {
+		name      string
+		url       string
+		header    string
+		itemsKey  string
+		otherKeys []string
+	}{
+		{"Default is legacy", "/test", "", "data", []string{"items"}},
+		{"Header selects envelope", "/test", "2", "items", []string{"data"}},
+		{"Query selects envelope", "/test?api_version=2", "", "items", []string{"data"}},
+		{"Explicit legacy", "/test", "1", "data", []string{"items"}},
+		{"Unknown version is legacy", "/test", "9", "data", []string{"items"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.header != "" {
+				req.Header.Set(APIVersionHeader, tt.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			var body map[string]any
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if items, ok := body[tt.itemsKey].([]any); !ok || len(items) != 2 {
+				t.Errorf("expected 2 items under %q, got %v", tt.itemsKey, body)
+			}
+			for _, key := range tt.otherKeys {
+				if _, ok := body[key]; ok {
+					t.Errorf("did not expect %q in %v", key, body)
+				}
+			}
+			if body["total_pages"] != 3.0 {
+				t.Errorf("expected total_pages 3, got %v", body["total_pages"])
+			}
+		})
+	}
+}
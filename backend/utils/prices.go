@@ -9,18 +9,35 @@ import (
 // ParsePriceFromScryfall extracts the USD price for a specific treatment from scryfall.Prices.
 // It maps card treatments to Scryfall price fields and falls back to nonfoil price if unavailable.
 func ParsePriceFromScryfall(prices scryfall.Prices, treatment string) float64 {
+	return ParsePriceInCurrency(prices, treatment, "usd")
+}
+
+// ParsePriceInCurrency extracts the price for a treatment in a currency ("usd", "eur" or "tix").
+// Scryfall has no etched EUR price and one tix price per card, so those use the nonfoil price.
+// Unknown currencies are treated as USD.
+func ParsePriceInCurrency(prices scryfall.Prices, treatment, currency string) float64 {
+	var nonfoil, foil, etched string
+	switch currency {
+	case "eur":
+		nonfoil, foil = prices.EUR, prices.EURFoil
+	case "tix":
+		nonfoil = prices.Tix
+	default:
+		nonfoil, foil, etched = prices.USD, prices.USDFoil, prices.USDEtched
+	}
+
 	// Map treatment to Scryfall price field
 	var priceStr string
 	switch treatment {
 	case "foil":
-		priceStr = prices.USDFoil
+		priceStr = foil
 	case "etched":
-		priceStr = prices.USDEtched
+		priceStr = etched
 	case "nonfoil":
-		priceStr = prices.USD
+		priceStr = nonfoil
 	default:
 		// For other treatments (glossy, etc.), try foil first
-		priceStr = prices.USDFoil
+		priceStr = foil
 	}
 
 	// Parse the price string to float64
@@ -31,8 +48,8 @@ func ParsePriceFromScryfall(prices scryfall.Prices, treatment string) float64 {
 	}
 
 	// Fallback to nonfoil price if treatment-specific price not available
-	if treatment != "nonfoil" && prices.USD != "" {
-		if price, err := strconv.ParseFloat(prices.USD, 64); err == nil {
+	if treatment != "nonfoil" && nonfoil != "" {
+		if price, err := strconv.ParseFloat(nonfoil, 64); err == nil {
 			return price
 		}
 	}
@@ -108,3 +108,37 @@ func TestParsePriceFromScryfall(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePriceInCurrency(t *testing.T) {
+	prices := scryfall.Prices{
+		USD: "2.00", USDFoil: "8.00", USDEtched: "6.00",
+		EUR: "1.80", EURFoil: "7.50",
+		Tix: "0.05",
+	}
+
+	tests := []struct {
+		name      string
+		prices    scryfall.Prices
+		treatment string
+		currency  string
+		expected  float64
+	}{
+		{"usd nonfoil", prices, "nonfoil", "usd", 2.0},
+		{"eur nonfoil", prices, "nonfoil", "eur", 1.8},
+		{"eur foil", prices, "foil", "eur", 7.5},
+		{"eur etched falls back to nonfoil", prices, "etched", "eur", 1.8},
+		{"eur foil falls back to nonfoil", scryfall.Prices{EUR: "1.80"}, "foil", "eur", 1.8},
+		{"eur missing is zero", scryfall.Prices{USD: "2.00"}, "nonfoil", "eur", 0.0},
+		{"tix ignores treatment", prices, "foil", "tix", 0.05},
+		{"unknown currency uses usd", prices, "foil", "gbp", 8.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ParsePriceInCurrency(tt.prices, tt.treatment, tt.currency)
+			if result != tt.expected {
+				t.Errorf("expected %f, got %f", tt.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RequestIDHeader carries a request's correlation ID, both ways: a valid incoming
+// value is kept, and every response echoes the ID that was used
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey stores the request ID in the request context. Handlers pass
+// c.RequestCtx() to services, whose Value method reads the same user values.
+type requestIDKey struct{}
+
+// SetRequestID attaches a request ID to the request, so logs written with
+// c.RequestCtx() (or any context derived from it) carry it
+func SetRequestID(c fiber.Ctx, id string) {
+	c.RequestCtx().SetUserValue(requestIDKey{}, id)
+}
+
+// WithRequestID returns a context carrying a request ID, for work started outside
+// a request handler that should still be correlated with one
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" outside a request
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDHandler adds a request_id attribute to records logged with a request context
+type requestIDHandler struct {
+	slog.Handler
+}
+
+// NewRequestIDLogHandler wraps a slog handler so records logged through the Context
+// variants (slog.InfoContext, slog.ErrorContext, ...) include the request ID
+func NewRequestIDLogHandler(next slog.Handler) slog.Handler {
+	return requestIDHandler{Handler: next}
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewRequestIDLogHandler(slog.NewTextHandler(&buf, nil))).With("component", "test")
+
+	logger.InfoContext(WithRequestID(context.Background(), "abc123"), "with id")
+	logger.InfoContext(context.Background(), "without id")
+	logger.Info("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 log lines, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], "request_id=abc123") || !strings.Contains(lines[0], "component=test") {
+		t.Errorf("expected the request ID and logger attributes, got %q", lines[0])
+	}
+	for _, line := range lines[1:] {
+		if strings.Contains(line, "request_id") {
+			t.Errorf("expected no request ID, got %q", line)
+		}
+	}
+}
+
+func TestRequestID_Empty(t *testing.T) {
+	if id := RequestID(context.Background()); id != "" {
+		t.Errorf("expected no request ID, got %q", id)
+	}
+}